@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/worker"
+)
+
+// poolStatsSource is the part of the worker pool the diagnostics listener reads
+type poolStatsSource interface {
+	Stats() worker.PoolStats
+}
+
+// runtimeSnapshot is returned by /admin/runtime on the diagnostics listener
+type runtimeSnapshot struct {
+	Timestamp  time.Time        `json:"timestamp"`
+	Uptime     string           `json:"uptime"`
+	GoVersion  string           `json:"go_version"`
+	NumCPU     int              `json:"num_cpu"`
+	GOMAXPROCS int              `json:"gomaxprocs"`
+	Goroutines int              `json:"goroutines"`
+	Heap       heapSnapshot     `json:"heap"`
+	GC         gcSnapshot       `json:"gc"`
+	Pool       worker.PoolStats `json:"pool"`
+}
+
+type heapSnapshot struct {
+	AllocBytes    uint64 `json:"alloc_bytes"`
+	InuseBytes    uint64 `json:"inuse_bytes"`
+	IdleBytes     uint64 `json:"idle_bytes"`
+	ReleasedBytes uint64 `json:"released_bytes"`
+	SysBytes      uint64 `json:"sys_bytes"`
+	Objects       uint64 `json:"objects"`
+	TotalAlloc    uint64 `json:"total_alloc_bytes"`
+	Mallocs       uint64 `json:"mallocs"`
+	Frees         uint64 `json:"frees"`
+}
+
+type gcSnapshot struct {
+	NumGC        uint32    `json:"num_gc"`
+	NextGCBytes  uint64    `json:"next_gc_bytes"`
+	LastGC       time.Time `json:"last_gc"`
+	PauseTotalMs float64   `json:"pause_total_ms"`
+	LastPauseMs  float64   `json:"last_pause_ms"`
+	CPUFraction  float64   `json:"cpu_fraction"`
+}
+
+// newDiagnosticsHandler serves net/http/pprof under /debug/pprof/ and a
+// runtime snapshot under /admin/runtime. It has no authentication and is
+// meant for a listener bound to a private interface (DIAGNOSTICS_ADDR).
+func newDiagnosticsHandler(pool poolStatsSource, started time.Time) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	mux.HandleFunc("/admin/runtime", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(takeRuntimeSnapshot(pool, started))
+	})
+	return mux
+}
+
+func takeRuntimeSnapshot(pool poolStatsSource, started time.Time) runtimeSnapshot {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	snap := runtimeSnapshot{
+		Timestamp:  time.Now(),
+		Uptime:     time.Since(started).Round(time.Second).String(),
+		GoVersion:  runtime.Version(),
+		NumCPU:     runtime.NumCPU(),
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		Goroutines: runtime.NumGoroutine(),
+		Heap: heapSnapshot{
+			AllocBytes:    m.HeapAlloc,
+			InuseBytes:    m.HeapInuse,
+			IdleBytes:     m.HeapIdle,
+			ReleasedBytes: m.HeapReleased,
+			SysBytes:      m.HeapSys,
+			Objects:       m.HeapObjects,
+			TotalAlloc:    m.TotalAlloc,
+			Mallocs:       m.Mallocs,
+			Frees:         m.Frees,
+		},
+		GC: gcSnapshot{
+			NumGC:        m.NumGC,
+			NextGCBytes:  m.NextGC,
+			PauseTotalMs: float64(m.PauseTotalNs) / 1e6,
+			CPUFraction:  m.GCCPUFraction,
+		},
+	}
+	if m.NumGC > 0 {
+		snap.GC.LastGC = time.Unix(0, int64(m.LastGC))
+		snap.GC.LastPauseMs = float64(m.PauseNs[(m.NumGC+255)%256]) / 1e6
+	}
+	if pool != nil {
+		snap.Pool = pool.Stats()
+	}
+	return snap
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/worker"
+)
+
+type fakePool struct{ stats worker.PoolStats }
+
+func (f fakePool) Stats() worker.PoolStats { return f.stats }
+
+func TestDiagnosticsRuntimeSnapshot(t *testing.T) {
+	pool := fakePool{worker.PoolStats{Workers: 4, QueueDepth: 12, QueueCapacity: 100, ShardDepths: []int{3, 9, 0, 0}}}
+	h := newDiagnosticsHandler(pool, time.Now().Add(-time.Minute))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/runtime", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+
+	var snap runtimeSnapshot
+	if err := json.NewDecoder(rec.Body).Decode(&snap); err != nil {
+		t.Fatal(err)
+	}
+	if snap.Goroutines == 0 || snap.Heap.SysBytes == 0 {
+		t.Errorf("runtime stats missing: %+v", snap)
+	}
+	if snap.Pool.QueueDepth != 12 || len(snap.Pool.ShardDepths) != 4 {
+		t.Errorf("pool stats = %+v", snap.Pool)
+	}
+}
+
+func TestDiagnosticsPprof(t *testing.T) {
+	h := newDiagnosticsHandler(nil, time.Now())
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/pprof/goroutine?debug=1", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if rec.Body.Len() == 0 {
+		t.Error("empty goroutine profile")
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderUnit(t *testing.T) {
+	unit, err := renderUnit(unitData{
+		installOptions: installOptions{
+			InstallDir: "/opt/opm-stats",
+			DataDir:    "/var/lib/opm-stats",
+			ConfigPath: "/etc/opm-stats/api.env",
+			User:       "opmstats",
+			Mode:       "lite",
+		},
+		Group:  "opmstats",
+		Binary: "/usr/local/bin/api",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"User=opmstats",
+		"WorkingDirectory=/opt/opm-stats",
+		"EnvironmentFile=/etc/opm-stats/api.env",
+		"Environment=CONFIG_FILE=/etc/opm-stats/api.env",
+		"ExecStart=/usr/local/bin/api",
+		"ReadWritePaths=/var/lib/opm-stats",
+		"After=network-online.target\n",
+	} {
+		if !strings.Contains(unit, line) {
+			t.Errorf("unit missing %q:\n%s", line, unit)
+		}
+	}
+}
+
+func TestWriteIfMissingKeepsExisting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "file")
+	if wrote, err := writeIfMissing(path, []byte("first"), 0o600, false); err != nil || !wrote {
+		t.Fatalf("first write: wrote=%v err=%v", wrote, err)
+	}
+	if wrote, err := writeIfMissing(path, []byte("second"), 0o600, false); err != nil || wrote {
+		t.Fatalf("second write: wrote=%v err=%v", wrote, err)
+	}
+	if b, _ := os.ReadFile(path); string(b) != "first" {
+		t.Errorf("content = %q, want first", b)
+	}
+	if wrote, err := writeIfMissing(path, []byte("third"), 0o600, true); err != nil || !wrote {
+		t.Fatalf("forced write: wrote=%v err=%v", wrote, err)
+	}
+}
@@ -5,12 +5,16 @@
 // - Load shedding via backpressure when queue is full
 // - Dual-store: ClickHouse (OLAP) + PostgreSQL (OLTP)
 // - Redis for caching, rate limiting, and real-time match state
+// - Lite mode (MODE=lite) runs both databases as child processes and keeps
+//   live state in memory
 
 package main
 
 import (
 	"context"
 	"fmt"
+	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -22,24 +26,62 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
-	"go.uber.org/zap"
+	"google.golang.org/grpc"
 
+	"github.com/openmohaa/stats-api/internal/bus"
 	"github.com/openmohaa/stats-api/internal/config"
 	"github.com/openmohaa/stats-api/internal/db"
+	"github.com/openmohaa/stats-api/internal/grpcapi"
 	"github.com/openmohaa/stats-api/internal/handlers"
+	"github.com/openmohaa/stats-api/internal/i18n"
+	"github.com/openmohaa/stats-api/internal/jobs"
+	"github.com/openmohaa/stats-api/internal/lite"
+	"github.com/openmohaa/stats-api/internal/logging"
 	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/notify"
+	"github.com/openmohaa/stats-api/internal/smf"
 	"github.com/openmohaa/stats-api/internal/worker"
 )
 
 func main() {
-	// Initialize structured logger
-	logger, _ := zap.NewProduction()
-	if os.Getenv("ENV") == "development" {
-		logger, _ = zap.NewDevelopment()
+	started := time.Now()
+
+	// Load configuration
+	cfg := config.Load()
+
+	// Initialize structured logger with runtime-adjustable levels
+	logSettings, err := loggingSettings(cfg)
+	if err != nil {
+		log.Fatalf("Invalid logging configuration: %v", err)
+	}
+	logger, logLevels, err := logging.New(logging.Config{
+		Development: os.Getenv("ENV") == "development",
+		Settings:    logSettings,
+		Initial:     cfg.LogSampleInitial,
+		Thereafter:  cfg.LogSampleThereafter,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
 	}
 	defer logger.Sync()
 	sugar := logger.Sugar()
 
+	// `api install` sets up a systemd service instead of serving
+	if len(os.Args) > 1 && os.Args[1] == "install" {
+		if err := runInstall(os.Args[2:], logger); err != nil {
+			sugar.Fatalw("Install failed", "error", err)
+		}
+		return
+	}
+
+	// `api tenant create` provisions a hosted community and prints its API key
+	if len(os.Args) > 1 && os.Args[1] == "tenant" {
+		if err := runTenant(os.Args[2:]); err != nil {
+			sugar.Fatalw("Tenant command failed", "error", err)
+		}
+		return
+	}
+
 	sugar.Info("OpenMOHAA Stats API starting up...")
 
 	// @title           OpenMOHAA Stats API
@@ -65,17 +107,36 @@ func main() {
 	// @in header
 	// @name Authorization
 
-	// Load configuration
-	cfg := config.Load()
 	sugar.Infow("Configuration loaded",
 		"port", cfg.Port,
 		"workers", cfg.WorkerCount,
 		"queueSize", cfg.QueueSize,
 	)
 
+	// Snapshot before lite mode rewrites the URLs so reloads compare like with like
+	reloader := config.NewReloader(cfg)
+
 	// Initialize database connections
 	ctx := context.Background()
 
+	// Lite mode: run the databases ourselves and keep live state in memory
+	if cfg.Mode == "lite" {
+		stack, err := lite.Start(ctx, lite.Config{
+			DataDir:          cfg.LiteDataDir,
+			ClickHouseBinary: cfg.ClickHouseBinary,
+			PostgresPort:     cfg.LitePostgresPort,
+			ClickHousePort:   cfg.LiteClickHousePort,
+		}, logger)
+		if err != nil {
+			sugar.Fatalw("Failed to start lite mode databases", "error", err)
+		}
+		defer stack.Stop()
+		cfg.PostgresURL = stack.PostgresURL
+		cfg.ClickHouseURL = stack.ClickHouseURL
+		cfg.LiveStateBackend = "memory"
+		sugar.Infow("Lite mode: local databases running", "dataDir", cfg.LiteDataDir)
+	}
+
 	// PostgreSQL (OLTP - auth tokens, user mappings)
 	pgPool, err := db.NewPostgresPool(ctx, cfg.PostgresURL)
 	if err != nil {
@@ -85,22 +146,152 @@ func main() {
 	sugar.Info("PostgreSQL connection established")
 
 	// ClickHouse (OLAP - telemetry events)
-	chConn, err := db.NewClickHouseConn(ctx, cfg.ClickHouseURL)
+	chConn, err := db.NewClickHouseConn(ctx, cfg.ClickHouseURL, db.ClickHouseOptions{
+		MaxOpenConns:    cfg.ClickHouseMaxOpenConns,
+		MaxIdleConns:    cfg.ClickHouseMaxIdleConns,
+		ConnMaxLifetime: cfg.ClickHouseConnMaxLifetime,
+		DialTimeout:     cfg.ClickHouseDialTimeout,
+		ReadTimeout:     cfg.ClickHouseReadTimeout,
+		Compression:     cfg.ClickHouseCompression,
+	})
 	if err != nil {
 		sugar.Fatalw("Failed to connect to ClickHouse", "error", err)
 	}
 	defer chConn.Close()
-	sugar.Info("ClickHouse connection established")
+	db.RegisterClickHouseMetrics(chConn)
+	sugar.Infow("ClickHouse connection established",
+		"maxOpenConns", cfg.ClickHouseMaxOpenConns,
+		"maxIdleConns", cfg.ClickHouseMaxIdleConns,
+		"compression", cfg.ClickHouseCompression,
+	)
+
+	if cfg.Mode == "lite" {
+		if _, err := db.Migrate(ctx, pgPool, chConn, cfg.MigrationsDir, logger); err != nil {
+			sugar.Fatalw("Failed to apply migrations", "error", err)
+		}
+	}
+
+	// Time every ClickHouse call and keep the slow ones for /admin/queries/slow
+	queryLog := db.NewQueryLog(cfg.SlowQueryThreshold, cfg.SlowQueryLogSize, logger)
+	chConn = db.Instrument(chConn, queryLog)
+
+	// Live state (caching, rate limiting, real-time state)
+	var liveState db.LiveStateStore
+	switch cfg.LiveStateBackend {
+	case "memory":
+		liveState = db.NewMemoryLiveState()
+		sugar.Info("Using in-memory live state (single node)")
+	case "redis":
+		redisClient := db.NewRedisClient(cfg.RedisURL)
+		if err := redisClient.Ping(ctx).Err(); err != nil {
+			sugar.Fatalw("Failed to connect to Redis", "error", err)
+		}
+		liveState = db.NewRedisLiveState(redisClient)
+		sugar.Info("Redis connection established")
+	default:
+		sugar.Fatalw("Unknown LIVE_STATE_BACKEND", "backend", cfg.LiveStateBackend)
+	}
+	defer liveState.Close()
+
+	// Per-type sampling for high-volume events (EVENT_SAMPLE_RATES)
+	sampler, err := worker.NewSampler(cfg.EventSampleRates)
+	if err != nil {
+		sugar.Fatalw("Invalid EVENT_SAMPLE_RATES", "error", err)
+	}
+	omitRawJSON, err := worker.ParseRawJSONOmit(cfg.RawJSONOmitTypes, cfg.MovementTable)
+	if err != nil {
+		sugar.Fatalw("Invalid RAW_JSON_OMIT_TYPES", "error", err)
+	}
+	insertMode, err := worker.ParseInsertMode(cfg.ClickHouseInsertMode)
+	if err != nil {
+		sugar.Fatalw("Invalid CLICKHOUSE_INSERT_MODE", "error", err)
+	}
+	contentFilterConfig, err := logic.ParseContentFilterConfig(cfg.ContentFilterMode, cfg.ContentFilterWords, cfg.ContentFilterProtectedNames)
+	if err != nil {
+		sugar.Fatalw("Invalid CONTENT_FILTER_MODE", "error", err)
+	}
+	predictionModels, err := logic.ParsePredictionModelConfig(cfg.PredictionModel, cfg.PredictionShadowModels)
+	if err != nil {
+		sugar.Fatalw("Invalid PREDICTION_MODEL", "error", err)
+	}
+
+	// Webhook alerts for players reaching TEAMKILL_ALERT_THRESHOLD in a match
+	teamkillAlerts := worker.NewTeamkillAlerter(worker.TeamkillAlertConfig{
+		Threshold:  cfg.TeamkillAlertThreshold,
+		WebhookURL: cfg.TeamkillWebhookURL,
+	}, logLevels.Logger("worker"))
 
-	// Redis (caching, rate limiting, real-time state)
-	redisClient := db.NewRedisClient(cfg.RedisURL)
-	defer redisClient.Close()
-	if err := redisClient.Ping(ctx).Err(); err != nil {
-		sugar.Fatalw("Failed to connect to Redis", "error", err)
+	// Purge notifications for the SMF frontend / CDN when profiles or
+	// leaderboards change
+	cachePurges := worker.NewCachePurger(worker.CachePurgeConfig{
+		URLs:  worker.ParsePurgeURLs(cfg.CachePurgeURLs),
+		Delay: cfg.CachePurgeDelay,
+	}, logLevels.Logger("worker"))
+	cachePurges.Start(ctx)
+
+	// Inbox behind the profile bell icon, filled by the worker with
+	// achievements, records, rivalries and challenges of linked players
+	notifications := logic.NewNotificationService(pgPool)
+	inbox := worker.NewInbox(notifications, logLevels.Logger("worker"))
+
+	// Chat announcements game servers poll from /integrations/announce
+	announcer := worker.NewAnnouncer(liveState, cfg.RedisMatchKeyTTL, logLevels.Logger("worker"))
+	announcer.SetInbox(inbox)
+
+	// Weekly challenges, rotated in each Monday, and the new player quest
+	// chain; progress is counted by the worker
+	challenges := logic.NewChallengesService(chConn, pgPool)
+	challengeEngine := worker.NewChallengeEngine(challenges, cfg.ChallengesPerWeek, cfg.ChallengesWebhookURL, logLevels.Logger("worker"))
+	challengeEngine.SetInbox(inbox)
+	challengeEngine.Start(ctx)
+
+	// Hashed client IPs of connect events, correlated into alt accounts of
+	// banned players; servers send them only when they opt in
+	altAccounts := logic.NewAltAccountService(pgPool)
+	var connections *worker.ConnectionTracker
+	if cfg.ConnectionAnalytics {
+		connections = worker.NewConnectionTracker(altAccounts, logLevels.Logger("worker"))
+		connections.Start(ctx)
+	}
+
+	// Match lifecycles, advanced by the worker and changed by admins
+	matchStates := logic.NewMatchStateService(pgPool)
+
+	// Player ratings and opponent-adjusted K/D, updated by the worker
+	ratings := logic.NewRatingService(pgPool)
+
+	// Full player profiles kept in live state, rebuilt after the player's
+	// events; PROFILE_CACHE_TTL=0 computes every request instead
+	playerStats := logic.NewPlayerStatsService(chConn)
+	var profiles *worker.ProfileCache
+	if cfg.ProfileCacheTTL > 0 {
+		profiles = worker.NewProfileCache(playerStats, liveState, worker.ProfileCacheConfig{
+			TTL:      cfg.ProfileCacheTTL,
+			Debounce: cfg.ProfileCacheDebounce,
+		}, logLevels.Logger("worker"))
+		profiles.Start(ctx)
+	}
+
+	// Fault injection for resilience tests, never in production
+	var faults *worker.Faults
+	if worker.FaultInjectionAllowed(cfg.FaultInjection, cfg.Env) {
+		faults = worker.NewFaults()
+		logger.Warn("Fault injection enabled at /api/v1/admin/faults")
 	}
-	sugar.Info("Redis connection established")
 
 	// Initialize worker pool for async event processing
+	// Accepted events mirrored onto a message bus for external consumers
+	var eventBus *worker.EventBus
+	if cfg.EventBus != "" {
+		pub, err := bus.Open(cfg.EventBus, cfg.EventBusURL, "mohaa-stats-api", liveState)
+		if err != nil {
+			sugar.Fatalw("Failed to open event bus", "bus", cfg.EventBus, "error", err)
+		}
+		eventBus = worker.NewEventBus(pub, cfg.EventBusPrefix, logLevels.Logger("worker"))
+		eventBus.Start(ctx)
+		sugar.Infow("Event bus enabled", "bus", cfg.EventBus, "prefix", cfg.EventBusPrefix)
+	}
+
 	workerPool := worker.NewPool(worker.PoolConfig{
 		WorkerCount:   cfg.WorkerCount,
 		QueueSize:     cfg.QueueSize,
@@ -108,8 +299,37 @@ func main() {
 		FlushInterval: cfg.FlushInterval,
 		ClickHouse:    chConn,
 		Postgres:      pgPool,
-		Redis:         redisClient,
-		Logger:        logger,
+		LiveState:     liveState,
+		RedisTTL: worker.RedisTTLConfig{
+			MatchKeys:      cfg.RedisMatchKeyTTL,
+			PlayerCounters: cfg.RedisPlayerCounterTTL,
+			Claims:         cfg.RedisClaimTTL,
+		},
+		LiveStateBreaker: worker.LiveStateBreakerConfig{
+			Threshold:  cfg.RedisBreakerThreshold,
+			Cooldown:   cfg.RedisBreakerCooldown,
+			BufferSize: cfg.RedisOutageBuffer,
+		},
+		Logger:           logLevels.Logger("worker"),
+		Sampler:          sampler,
+		MovementTable:    cfg.MovementTable,
+		OmitRawJSON:      omitRawJSON,
+		KillDedupeWindow: cfg.KillDedupeWindow,
+		InsertMode:       insertMode,
+		SmallBatch:       cfg.ClickHouseSmallBatch,
+		ShardByMatch:     cfg.ShardByMatch,
+		MatchStates:      matchStates,
+		Ratings:          ratings,
+		Faults:           faults,
+
+		TeamkillAlerts: teamkillAlerts,
+		CachePurges:    cachePurges,
+		Announcer:      announcer,
+		Inbox:          inbox,
+		Connections:    connections,
+		Challenges:     challengeEngine,
+		Profiles:       profiles,
+		EventBus:       eventBus,
 	})
 	workerPool.Start(ctx)
 	sugar.Infow("Worker pool started",
@@ -117,10 +337,61 @@ func main() {
 		"queueSize", cfg.QueueSize,
 	)
 
+	// Split ingestion: HTTP nodes publish to a shared stream, worker nodes
+	// consume it into their pool
+	var ingestQueue handlers.IngestQueue
+	var streamPublisher *worker.StreamPublisher
+	var streamConsumer *worker.StreamConsumer
+	switch cfg.IngestMode {
+	case "local":
+	case "publish", "consume":
+		client, ok := db.RedisClientOf(liveState)
+		if !ok {
+			sugar.Fatalw("INGEST_MODE needs LIVE_STATE_BACKEND=redis", "mode", cfg.IngestMode)
+		}
+		if cfg.IngestStreamPartitions < 1 {
+			sugar.Fatalw("INGEST_STREAM_PARTITIONS must be at least 1", "partitions", cfg.IngestStreamPartitions)
+		}
+		streamCfg := worker.IngestStreamConfig{
+			Stream:     cfg.IngestStream,
+			Partitions: cfg.IngestStreamPartitions,
+			MaxLen:     cfg.IngestStreamMaxLen,
+		}
+		streamPublisher = worker.NewStreamPublisher(client, streamCfg, logLevels.Logger("ingest"))
+		streamPublisher.Start(ctx)
+		ingestQueue = streamPublisher
+		if cfg.IngestMode == "consume" {
+			streamConsumer = worker.NewStreamConsumer(client, liveState, workerPool, streamCfg, cfg.LeaderLeaseTTL, logLevels.Logger("worker"))
+			if err := streamConsumer.Start(ctx); err != nil {
+				sugar.Fatalw("Failed to start ingest stream consumer", "error", err)
+			}
+		}
+		sugar.Infow("Split ingestion enabled", "mode", cfg.IngestMode, "stream", cfg.IngestStream, "partitions", cfg.IngestStreamPartitions)
+	default:
+		sugar.Fatalw("Unknown INGEST_MODE", "mode", cfg.IngestMode)
+	}
+
+	// Expire abandoned live matches/servers and report Redis memory by prefix
+	redisJanitor := worker.NewRedisJanitor(liveState, worker.JanitorConfig{
+		Interval:    cfg.RedisJanitorInterval,
+		LiveIdleTTL: cfg.RedisLiveIdleTTL,
+	}, logger)
+	redisJanitor.Start(ctx)
+
+	// End live matches left behind by dead servers or a restart
+	matchReconciler := worker.NewMatchReconciler(liveState, workerPool, worker.ReconcilerConfig{
+		Interval:   cfg.LiveMatchReconcileInterval,
+		StaleAfter: cfg.LiveMatchStaleAfter,
+	}, logLevels.Logger("worker"))
+	// One replica reconciles; the others stand by for its lease to lapse
+	reconcilerLeader := db.NewLeader(liveState, "match_reconciler", cfg.LeaderLeaseTTL, logLevels.Logger("worker"))
+	reconcilerLeader.Start(ctx)
+	matchReconciler.SetLeader(reconcilerLeader)
+	matchReconciler.Start(ctx)
+
 	// Achievement worker is now integrated into worker pool (no separate instance needed)
 
 	// Initialize services
-	playerStats := logic.NewPlayerStatsService(chConn)
 	serverStats := logic.NewServerStatsService(chConn)
 	gamification := logic.NewGamificationService(chConn)
 	matchReport := logic.NewMatchReportService(chConn)
@@ -128,15 +399,159 @@ func main() {
 	teamStats := logic.NewTeamStatsService(chConn)
 	tournament := logic.NewTournamentService(chConn)
 	achievements := logic.NewAchievementsService(chConn, pgPool)
-	prediction := logic.NewPredictionService(chConn)
+	prediction := logic.NewPredictionService(chConn, pgPool, predictionModels)
+	aggregates := logic.NewAggregateService(chConn, pgPool)
+	tenants := logic.NewTenantService(pgPool)
+	highlights := logic.NewHighlightsService(chConn, pgPool)
+	snapshots := logic.NewLeaderboardSnapshotService(chConn, pgPool)
+	mailer := notify.NewMailer(notify.SMTPConfig{
+		Addr:     cfg.SMTPAddr,
+		From:     cfg.SMTPFrom,
+		Username: cfg.SMTPUsername,
+		Password: cfg.SMTPPassword,
+	})
+	serverReports := logic.NewServerReportService(chConn, pgPool, mailer.Enabled())
+	timeline := logic.NewTimelineService(chConn, pgPool)
+	titles := logic.NewTitlesService(chConn, pgPool)
+	trophies := logic.NewTrophiesService(chConn, pgPool, titles)
+	registration := logic.NewServerRegistrationService(pgPool)
+	playerNames := logic.NewPlayerNamesService(pgPool, liveState)
+	contentFilter := logic.NewContentFilterService(pgPool, contentFilterConfig)
+
+	// Nightly check that MV-fed aggregates still agree with raw_events
+	aggregateChecker := worker.NewAggregateChecker(aggregates, worker.AggregateCheckConfig{
+		Hour:       cfg.AggregateCheckHour,
+		Days:       cfg.AggregateCheckDays,
+		SampleSize: cfg.AggregateCheckSample,
+		Tolerance:  cfg.AggregateDriftTolerance,
+	}, logger)
+	aggregateChecker.Start(ctx)
+
+	// Players of the day and week, picked once each period is over
+	highlightsScheduler := worker.NewHighlightsScheduler(highlights, cfg.HighlightsWebhookURL, logger)
+	highlightsScheduler.SetLocale(i18n.Default().Negotiate(cfg.DigestLocale))
+	highlightsScheduler.Start(ctx)
+
+	// Daily leaderboard standings behind the rank changes endpoint
+	snapshotScheduler := worker.NewLeaderboardSnapshotScheduler(snapshots, logger)
+	snapshotScheduler.Start(ctx)
+
+	// Weekly reports to the server owners who asked for them
+	serverReportScheduler := worker.NewServerReportScheduler(serverReports, mailer, logger)
+	serverReportScheduler.SetLocale(i18n.Default().Negotiate(cfg.DigestLocale))
+	serverReportScheduler.Start(ctx)
+
+	// Periodic jobs, each run on one instance at a time under a live state lock
+	jobScheduler := jobs.NewScheduler(liveState, pgPool, logLevels.Logger("jobs"))
+	for _, job := range []jobs.Job{
+		// Rule titles and badges for players who reached them
+		worker.NewTitleRuleSweeper(titles, logger).Job(),
+		// Score served player forecasts once the players' next match has ended
+		worker.NewPredictionResolver(prediction, logger).Job(),
+		{
+			Name:        "job_runs_prune",
+			Description: "Fails abandoned job runs and drops run history older than 30 days",
+			Schedule:    "@daily",
+			Run: func(ctx context.Context) error {
+				return jobScheduler.PruneRuns(ctx, 30*24*time.Hour)
+			},
+		},
+		{
+			Name:        "notifications_prune",
+			Description: "Drops notifications older than 90 days",
+			Schedule:    "@daily",
+			Run: func(ctx context.Context) error {
+				_, err := notifications.Prune(ctx, time.Now().Add(-90*24*time.Hour))
+				return err
+			},
+		},
+		{
+			Name:        "player_ip_hashes_prune",
+			Description: "Drops client IP hashes not seen within CONNECTION_RETENTION_DAYS",
+			Schedule:    "@daily",
+			Run: func(ctx context.Context) error {
+				days := reloader.Current().ConnectionRetentionDays
+				_, err := altAccounts.Prune(ctx, time.Now().AddDate(0, 0, -days))
+				return err
+			},
+		},
+	} {
+		if err := jobScheduler.Register(job); err != nil {
+			sugar.Fatalw("Failed to register job", "error", err)
+		}
+	}
+	// Precise positions of old events, stripped down to heatmap grid cells.
+	// Registered with no retention too, so a reload can turn it on.
+	positionMinimizer := worker.NewPositionMinimizer(logic.NewPositionRetentionService(chConn), cfg.PositionRetentionDays, logger)
+	if err := jobScheduler.Register(positionMinimizer.Job()); err != nil {
+		sugar.Fatalw("Failed to register job", "error", err)
+	}
+	// Forum threads for featured matches, and their post counts
+	viewership := logic.NewViewershipService(chConn)
+	discussions := logic.NewDiscussionService(pgPool)
+	var discussionSync *worker.DiscussionSync
+	if cfg.SMFAPIURL != "" && cfg.SMFDiscussionBoard > 0 {
+		discussionSync = worker.NewDiscussionSync(discussions, viewership, smf.NewClient(cfg.SMFAPIURL, cfg.SMFAPIKey), worker.DiscussionConfig{
+			Board:         cfg.SMFDiscussionBoard,
+			MinSpectators: cfg.SMFFeaturedMinSpectators,
+			MatchPageURL:  cfg.MatchPageURL,
+		}, logger)
+		discussionSync.SetLocale(i18n.Default().Negotiate(cfg.DigestLocale))
+		if err := jobScheduler.Register(discussionSync.Job()); err != nil {
+			sugar.Fatalw("Failed to register job", "error", err)
+		}
+	}
+	jobScheduler.Start(ctx)
+
+	// Background rebuilds of the days touched by voided matches or bans
+	aggregateRebuilder := worker.NewAggregateRebuilder(ctx, aggregates, logger)
+	aggregateRebuilder.SetCachePurger(cachePurges)
+
+	// Per-server ingest lag gauges for alerting on silent event streams
+	serverTracking := logic.NewServerTrackingService(chConn, pgPool, liveState)
+	ingestLag := worker.NewIngestLagReporter(serverTracking, cfg.IngestLagInterval, cfg.IngestStallThreshold, logger)
+	ingestLag.Start(ctx)
+
+	// Operator alert rules over ingest, queue and ClickHouse health
+	alerts := worker.NewAlertEngine(worker.AlertConfig{
+		WebhookURL:       cfg.AlertWebhookURL,
+		Interval:         cfg.AlertInterval,
+		IngestLag:        cfg.AlertIngestLag,
+		QueueDepth:       cfg.AlertQueueDepth,
+		ServerOffline:    cfg.AlertServerOffline,
+		ClickHouseErrors: cfg.AlertClickHouseErrors,
+	}, worker.AlertSources{
+		Ingest:        serverTracking,
+		QueueDepth:    workerPool.QueueDepth,
+		FailedBatches: workerPool.FailedBatches,
+	}, logger)
+	alerts.Start(ctx)
 
 	// Initialize handlers
+	// Read-only SQL over the aggregate tables, only when the operator opts in
+	var querySandbox logic.QuerySandboxService
+	if cfg.QuerySandbox {
+		querySandbox = logic.NewQuerySandboxService(chConn, cfg.QuerySandboxMaxRows, cfg.QuerySandboxTimeout)
+		sugar.Infow("Query sandbox enabled", "maxRows", cfg.QuerySandboxMaxRows, "timeout", cfg.QuerySandboxTimeout)
+	}
+
+	var matchResults logic.MatchResultService
+	if cfg.ResultSigningKey != "" {
+		key, err := logic.ParseResultSigningKey(cfg.ResultSigningKey)
+		if err != nil {
+			sugar.Fatalw("Invalid RESULT_SIGNING_KEY", "error", err)
+		}
+		matchResults = logic.NewMatchResultService(chConn, pgPool, key)
+		sugar.Infow("Tournament result signing enabled", "keyID", matchResults.SigningKey().KeyID)
+	}
+
 	h := handlers.New(handlers.Config{
 		WorkerPool:    workerPool,
+		Ingest:        ingestQueue,
 		Postgres:      pgPool,
 		ClickHouse:    chConn,
-		Redis:         redisClient,
-		Logger:        logger,
+		LiveState:     liveState,
+		Logger:        logLevels.Logger("api"),
 		PlayerStats:   playerStats,
 		ServerStats:   serverStats,
 		Gamification:  gamification,
@@ -146,8 +561,180 @@ func main() {
 		Tournament:    tournament,
 		Achievements:  achievements,
 		Prediction:    prediction,
+		Aggregates:    aggregates,
+		Rebuilds:      aggregateRebuilder,
+		Tenants:       tenants,
+		Highlights:    highlights,
+		Snapshots:     snapshots,
+		ServerReports: serverReports,
+		Timeline:      timeline,
+		Titles:        titles,
+		Trophies:      trophies,
+		Registration:  registration,
+		PlayerNames:   playerNames,
+		ContentFilter: contentFilter,
+		Challenges:    challenges,
+		MatchStates:   matchStates,
+		MatchAdmin:    logic.NewMatchAdminService(chConn, pgPool),
+		ServerMerge:   logic.NewServerMergeService(chConn, pgPool),
+		Ratings:       ratings,
+		QuerySandbox:  querySandbox,
+		Announcer:     announcer,
+		Profiles:      profiles,
+		Alerts:        alerts,
+		Faults:        faults,
+		Jobs:          jobScheduler,
+		QueryLog:      queryLog,
+		Reloader:      reloader,
+		Privacy:       logic.NewPrivacyService(pgPool),
+		MatchResults:  matchResults,
+		Disputes:      logic.NewMatchDisputeService(pgPool),
+		MapVetoes:     logic.NewMapVetoService(pgPool),
+		Viewership:    viewership,
+		Demos:         logic.NewDemoService(pgPool, chConn),
+		Media:         logic.NewMatchMediaService(pgPool, chConn),
+		Discussions:   discussions,
+		Notifications: notifications,
+		Reports:       logic.NewReportService(pgPool, chConn),
+		AltAccounts:   altAccounts,
+		Logging:       logLevels,
+
+		IngestStallThreshold: cfg.IngestStallThreshold,
+		IngestMaxLineBytes:   cfg.IngestMaxLineBytes,
+		IngestMaxBodyBytes:   cfg.IngestMaxBodyBytes,
+		RateLimitPerSecond:   cfg.RateLimitPerSecond,
+		RateLimitBurst:       cfg.RateLimitBurst,
+		RequireTenant:        cfg.MultiTenant,
+		PublicURL:            cfg.PublicURL,
+		MatchPageURL:         cfg.MatchPageURL,
 	})
 
+	// Settings applied in place on SIGHUP or POST /admin/config/reload
+	reloader.OnReload("redis_ttl", func(c *config.Config) error {
+		workerPool.SetRedisTTL(worker.RedisTTLConfig{
+			MatchKeys:      c.RedisMatchKeyTTL,
+			PlayerCounters: c.RedisPlayerCounterTTL,
+			Claims:         c.RedisClaimTTL,
+		})
+		redisJanitor.SetLiveIdleTTL(c.RedisLiveIdleTTL)
+		return nil
+	})
+	reloader.OnReload("slow_query_threshold", func(c *config.Config) error {
+		queryLog.SetThreshold(c.SlowQueryThreshold)
+		return nil
+	})
+	reloader.OnReload("ingest_stall_threshold", func(c *config.Config) error {
+		ingestLag.SetThreshold(c.IngestStallThreshold)
+		h.SetIngestStallThreshold(c.IngestStallThreshold)
+		return nil
+	})
+	reloader.OnReload("event_sample_rates", func(c *config.Config) error {
+		return sampler.SetRates(c.EventSampleRates)
+	})
+	reloader.OnReload("alerts", func(c *config.Config) error {
+		alerts.SetConfig(worker.AlertConfig{
+			WebhookURL:       c.AlertWebhookURL,
+			Interval:         c.AlertInterval,
+			IngestLag:        c.AlertIngestLag,
+			QueueDepth:       c.AlertQueueDepth,
+			ServerOffline:    c.AlertServerOffline,
+			ClickHouseErrors: c.AlertClickHouseErrors,
+		})
+		return nil
+	})
+	reloader.OnReload("teamkill_alerts", func(c *config.Config) error {
+		teamkillAlerts.SetConfig(worker.TeamkillAlertConfig{
+			Threshold:  c.TeamkillAlertThreshold,
+			WebhookURL: c.TeamkillWebhookURL,
+		})
+		return nil
+	})
+	reloader.OnReload("cache_purges", func(c *config.Config) error {
+		cachePurges.SetConfig(worker.CachePurgeConfig{
+			URLs:  worker.ParsePurgeURLs(c.CachePurgeURLs),
+			Delay: c.CachePurgeDelay,
+		})
+		return nil
+	})
+	reloader.OnReload("profile_cache", func(c *config.Config) error {
+		if profiles == nil {
+			if c.ProfileCacheTTL > 0 {
+				return fmt.Errorf("enabling the profile cache requires a restart")
+			}
+			return nil
+		}
+		profiles.SetConfig(worker.ProfileCacheConfig{
+			TTL:      c.ProfileCacheTTL,
+			Debounce: c.ProfileCacheDebounce,
+		})
+		return nil
+	})
+	reloader.OnReload("highlights_webhook", func(c *config.Config) error {
+		highlightsScheduler.SetWebhookURL(c.HighlightsWebhookURL)
+		highlightsScheduler.SetLocale(i18n.Default().Negotiate(c.DigestLocale))
+		serverReportScheduler.SetLocale(i18n.Default().Negotiate(c.DigestLocale))
+		if discussionSync != nil {
+			discussionSync.SetLocale(i18n.Default().Negotiate(c.DigestLocale))
+		}
+		return nil
+	})
+	reloader.OnReload("challenges_webhook", func(c *config.Config) error {
+		challengeEngine.SetWebhookURL(c.ChallengesWebhookURL)
+		return nil
+	})
+	reloader.OnReload("content_filter", func(c *config.Config) error {
+		cfg, err := logic.ParseContentFilterConfig(c.ContentFilterMode, c.ContentFilterWords, c.ContentFilterProtectedNames)
+		if err != nil {
+			return err
+		}
+		contentFilter.SetConfig(cfg)
+		return nil
+	})
+	reloader.OnReload("prediction_models", func(c *config.Config) error {
+		cfg, err := logic.ParsePredictionModelConfig(c.PredictionModel, c.PredictionShadowModels)
+		if err != nil {
+			return err
+		}
+		prediction.SetModels(cfg)
+		return nil
+	})
+	reloader.OnReload("log_levels", func(c *config.Config) error {
+		settings, err := loggingSettings(c)
+		if err != nil {
+			return err
+		}
+		return logLevels.Apply(settings)
+	})
+	reloader.OnReload("rate_limit", func(c *config.Config) error {
+		h.SetRateLimit(c.RateLimitPerSecond, c.RateLimitBurst)
+		return nil
+	})
+	reloader.OnReload("position_retention", func(c *config.Config) error {
+		positionMinimizer.SetRetentionDays(c.PositionRetentionDays)
+		return nil
+	})
+	reloader.OnReload("achievement_definitions", func(*config.Config) error {
+		return workerPool.ReloadAchievements()
+	})
+
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	go func() {
+		for range reload {
+			result, err := reloader.Reload()
+			if err != nil {
+				sugar.Errorw("Config reload failed", "error", err)
+				continue
+			}
+			sugar.Infow("Configuration reloaded",
+				"source", result.Source,
+				"changed", result.Changed,
+				"restartRequired", result.RestartRequired,
+				"errors", result.Errors,
+			)
+		}
+	}()
+
 	// Setup router
 	r := chi.NewRouter()
 
@@ -158,13 +745,14 @@ func main() {
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Compress(5))
 	r.Use(middleware.Timeout(30 * time.Second))
+	r.Use(h.LocaleMiddleware)
 
 	// CORS for frontend
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-Server-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-Server-Token", "X-API-Key", "X-Ingest-Version"},
+		ExposedHeaders:   []string{"Link", "X-Ingest-Version", "X-Ingest-Capabilities"},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
@@ -176,89 +764,232 @@ func main() {
 
 	// API v1 Routes
 	r.Route("/api/v1", func(r chi.Router) {
+		r.Use(h.RateLimitMiddleware)
+
 		// Ingestion endpoints (from game servers)
 		r.Route("/ingest", func(r chi.Router) {
-			r.Use(h.ServerAuthMiddleware)
+			r.Use(h.SandboxAuthMiddleware)
 			r.Post("/events", h.IngestEvents)
 			r.Post("/match-result", h.IngestMatchResult)
 		})
 
-		r.Post("/servers/register", h.RegisterServer)
+		r.With(h.TenantMiddleware).Post("/servers/register", h.RegisterServer)
+		r.Get("/servers/register/status", h.GetRegistrationStatus)
 
 		// System endpoints
 		r.Route("/system", func(r chi.Router) {
-			r.Use(h.ServerAuthMiddleware)
+			r.Use(h.ServerAuthMiddleware, h.AdminMiddleware)
 			r.Post("/install", h.InstallDatabase)
 			r.Post("/reset", h.ResetDatabase)
 		})
 
-		// Stats endpoints (for frontend)
+		r.Route("/integrations", func(r chi.Router) {
+			// Game server integrations
+			r.Group(func(r chi.Router) {
+				r.Use(h.ServerAuthMiddleware)
+				r.Get("/announce", h.GetAnnouncements)
+				r.Get("/killfeed", h.GetKillfeedContext)
+				r.Get("/report", h.GetServerReportSettings)
+				r.Put("/report", h.SetServerReportSettings)
+				r.Delete("/report", h.DeleteServerReportSettings)
+				r.Get("/report/preview", h.PreviewServerReport)
+				r.Post("/demos", h.RegisterDemo)
+			})
+
+			// Launcher home screen, public like the player stats it collects
+			r.With(h.TenantMiddleware, h.UnscopedMiddleware, h.PlayerVisibilityMiddleware).Get("/launcher/home/{guid}", h.GetLauncherHome)
+		})
+
+		// Admin endpoints (operational tooling)
+		r.Route("/admin", func(r chi.Router) {
+			// Sandbox tokens read back their own events, and reach nothing
+			// else under /admin
+			r.With(h.SandboxAuthMiddleware).Get("/events/search", h.SearchEvents)
+
+			// Everything else takes an admin server's token
+			r.Group(func(r chi.Router) {
+				r.Use(h.ServerAuthMiddleware, h.AdminMiddleware)
+				r.Get("/aggregates/check", h.CheckAggregates)
+				r.Post("/aggregates/rebuild", h.RebuildAggregates)
+				r.Post("/aggregates/rebuild/targeted", h.RebuildAggregatesTargeted)
+				r.Get("/aggregates/jobs", h.GetRebuildJobs)
+				r.Get("/aggregates/jobs/{jobId}", h.GetRebuildJob)
+				r.Get("/views", h.GetAnalyticsViews)
+				r.Get("/ingest/health", h.GetIngestHealth)
+				r.Get("/alerts", h.GetAlerts)
+				r.Post("/alerts/silences", h.CreateAlertSilence)
+				r.Delete("/alerts/silences/{id}", h.DeleteAlertSilence)
+				r.Get("/jobs", h.ListJobs)
+				r.Get("/jobs/{name}/runs", h.GetJobRuns)
+				r.Post("/jobs/{name}/run", h.RunJob)
+				r.Get("/faults", h.GetFaults)
+				r.Put("/faults", h.SetFaults)
+				r.Delete("/faults", h.ClearFaults)
+				r.Get("/queries/slow", h.GetSlowQueries)
+				r.Post("/query", h.RunSandboxQuery)
+				r.Post("/config/reload", h.ReloadConfig)
+				r.Get("/logging", h.GetLogging)
+				r.Put("/logging", h.SetLogging)
+				r.Get("/matches/{matchId}/state", h.GetMatchState)
+				r.Put("/matches/{matchId}/state", h.SetMatchState)
+				r.Post("/matches/{matchId}/void", h.VoidMatch)
+				r.Post("/matches/{matchId}/recompute", h.RecomputeMatch)
+				r.Get("/matches/{matchId}/disputes", h.ListMatchDisputes)
+				r.Post("/matches/{matchId}/disputes", h.OpenMatchDispute)
+				r.Post("/matches/{matchId}/correction", h.CorrectMatch)
+				r.Get("/disputes/{id}", h.GetDispute)
+				r.Post("/disputes/{id}/notes", h.AddDisputeNote)
+				r.Post("/disputes/{id}/reject", h.RejectDispute)
+				r.Put("/tournaments/{id}/vetoes/{seriesId}", h.RecordMapVeto)
+				r.Delete("/demos/{id}", h.DeleteDemo)
+				r.Get("/media", h.ListMediaQueue)
+				r.Post("/media/{id}/approve", h.ApproveMedia)
+				r.Post("/media/{id}/reject", h.RejectMedia)
+				r.Get("/reports", h.ListReports)
+				r.Get("/reports/{id}", h.GetReport)
+				r.Post("/reports/{id}/triage", h.TriageReport)
+				r.Get("/identity/alts", h.GetBanEvasion)
+				r.Get("/identity/players/{guid}/alts", h.GetPlayerAlts)
+				r.Put("/discussions/{type}/{id}", h.LinkDiscussion)
+				r.Delete("/discussions/{type}/{id}", h.UnlinkDiscussion)
+				r.Put("/titles/{code}", h.DefineTitle)
+				r.Post("/titles/{code}/players", h.GrantTitle)
+				r.Delete("/titles/{code}/players/{guid}", h.RevokeTitle)
+				r.Get("/content-filter/overrides", h.ListContentOverrides)
+				r.Put("/content-filter/overrides/{guid}", h.SetContentOverride)
+				r.Delete("/content-filter/overrides/{guid}", h.DeleteContentOverride)
+				r.Get("/challenges", h.ListChallenges)
+				r.Put("/challenges/{code}", h.DefineChallenge)
+				r.Get("/server-invites", h.ListServerInvites)
+				r.Post("/server-invites", h.CreateServerInvite)
+				r.Delete("/server-invites/{id}", h.RevokeServerInvite)
+				r.Post("/servers/merge", h.MergeServers)
+				r.Get("/servers/merges", h.ListServerMerges)
+				r.Get("/servers/pending", h.ListPendingServers)
+				r.Post("/servers/{id}/approve", h.ApproveServer)
+				r.Post("/servers/{id}/reject", h.RejectServer)
+			})
+		})
+
+		// Stats endpoints (for frontend). Tenant API keys reach only the
+		// routes whose queries filter on the tenant or its servers, or are
+		// scoped to them by ServerScopeMiddleware
 		r.Route("/stats", func(r chi.Router) {
-			r.Get("/global", h.GetGlobalStats)
-			r.Get("/global/activity", h.GetServerActivity)
-			r.Get("/server/pulse", h.GetServerPulse)
-			r.Get("/server/maps", h.GetServerMaps)
-			r.Get("/teams/performance", h.GetFactionPerformance) // [NEW]
+			r.Use(h.TenantMiddleware)
+			r.Use(h.RoundPhaseMiddleware)
 			r.Get("/matches", h.GetMatches)
 			r.Get("/weapons", h.GetGlobalWeaponStats)
-			r.Get("/weapons/list", h.GetWeaponsList)     // [NEW] Simple list for dropdowns
-			r.Get("/weapon/{weapon}", h.GetWeaponDetail) // [NEW] Single weapon details
+			r.Get("/weapons/matrix", h.GetWeaponKillMatrix)
+			r.Get("/maps/environment", h.GetMapEnvironmentDeaths)
+			r.Get("/maps/{id}/objectives", h.GetMapObjectives)
+			r.Get("/leaderboard", h.GetLeaderboard)
+			r.Get("/leaderboard/{stat}", h.GetLeaderboard)
+			r.Get("/leaderboard/{stat}/changes", h.GetLeaderboardChanges)
+			r.Get("/leaderboard/vehicle/{vehicle}", h.GetVehicleLeaderboard)
+			r.Get("/highlights/potd", h.GetPlayerHighlights)
+			r.Get("/match/{matchId}/viewership", h.GetMatchViewership)
+			r.Get("/matches/featured", h.GetFeaturedMatches)
+			r.Get("/matches/demos", h.GetDemoMatches)
+			r.With(h.TenantServerMiddleware("serverId")).Get("/server/{serverId}/stats", h.GetServerStats)
 
-			// Map statistics endpoints
-			r.Get("/maps", h.GetMapStats)      // All maps with stats
-			r.Get("/maps/list", h.GetMapsList) // Simple maps list
-			r.Get("/maps/popularity", h.GetMapPopularity)
-			r.Get("/map/{mapId}", h.GetMapDetail) // Single map details
+			// Player profile endpoints; hidden profiles answer 404
+			r.Route("/player/{guid}", func(r chi.Router) {
+				r.Use(h.PlayerVisibilityMiddleware)
+				r.Get("/weapons/matrix", h.GetWeaponKillMatrix)
+				r.Get("/team-damage", h.GetPlayerTeamDamage)
+				r.Get("/ping", h.GetPlayerPingHistory)
 
-			// Game type statistics endpoints (derived from map prefixes)
-			r.Get("/gametypes", h.GetGameTypeStats)            // All game types with stats
-			r.Get("/gametypes/list", h.GetGameTypesList)       // Simple list for dropdowns
-			r.Get("/gametype/{gameType}", h.GetGameTypeDetail) // Single game type details
-			r.Get("/leaderboard/gametype/{gameType}", h.GetGameTypeLeaderboard)
+				r.Group(func(r chi.Router) {
+					r.Use(h.ServerScopeMiddleware)
+					r.Get("/", h.GetPlayerStats)
+					r.Get("/deep", h.GetPlayerDeepStats)
+					r.Get("/combat", h.GetPlayerCombatStats)     // Subset of deep stats
+					r.Get("/movement", h.GetPlayerMovementStats) // Subset of deep stats
+					r.Get("/stance", h.GetPlayerStanceStats)     // Subset of deep stats
+					r.Get("/matches", h.GetPlayerMatches)
+					r.Get("/weapons", h.GetPlayerWeaponStats)
+					r.Get("/gametypes", h.GetPlayerStatsByGametype)
+					r.Get("/maps", h.GetPlayerStatsByMap)
+					r.Get("/pacing", h.GetPlayerPacing)
+					r.Get("/heatmap/{map}", h.GetPlayerHeatmap)
+					r.Get("/deaths/{map}", h.GetPlayerDeathHeatmap)
+					r.Get("/heatmap/body", h.GetPlayerBodyHeatmap)
+					r.Get("/performance", h.GetPlayerPerformanceHistory)
+					r.Get("/playstyle", h.GetPlayerPlaystyle) // [NEW]
+					r.Get("/predictions", h.GetPlayerPredictions)
+					r.Get("/timeline", h.GetPlayerTimeline)
+					r.Get("/titles", h.GetPlayerTitles)
+					r.Get("/trophies", h.GetPlayerTrophies)
+					r.Get("/challenges", h.GetPlayerChallenges)
+					r.Get("/quests", h.GetPlayerQuest)
 
-			r.Get("/leaderboard", h.GetLeaderboard)
-			r.Get("/leaderboard/{stat}", h.GetLeaderboard)
-			r.Get("/leaderboard/cards", h.GetLeaderboardCards)
-			r.Get("/leaderboard/weapon/{weapon}", h.GetWeaponLeaderboard)
-			r.Get("/leaderboard/map/{map}", h.GetMapLeaderboard)
-			r.Get("/member/{memberId}", h.GetPlayerStatsBySMFID) // Fetch stats using SMF Member ID from tracker.scr
-			r.Get("/player/name/{name}", h.GetPlayerStatsByName)
-			r.Get("/player/{guid}", h.GetPlayerStats)
-			r.Get("/player/{guid}/deep", h.GetPlayerDeepStats)
-			r.Get("/player/{guid}/combat", h.GetPlayerCombatStats)     // Subset of deep stats
-			r.Get("/player/{guid}/movement", h.GetPlayerMovementStats) // Subset of deep stats
-			r.Get("/player/{guid}/stance", h.GetPlayerStanceStats)     // Subset of deep stats
-			r.Get("/player/{guid}/matches", h.GetPlayerMatches)
-			r.Get("/player/{guid}/weapons", h.GetPlayerWeaponStats)
-			r.Get("/player/{guid}/gametypes", h.GetPlayerStatsByGametype)
-			r.Get("/player/{guid}/maps", h.GetPlayerStatsByMap)
-			r.Get("/player/{guid}/heatmap/{map}", h.GetPlayerHeatmap)
-			r.Get("/player/{guid}/deaths/{map}", h.GetPlayerDeathHeatmap)
-			r.Get("/player/{guid}/heatmap/body", h.GetPlayerBodyHeatmap)
-			r.Get("/player/{guid}/performance", h.GetPlayerPerformanceHistory)
-			r.Get("/player/{guid}/playstyle", h.GetPlayerPlaystyle) // [NEW]
-			r.Get("/player/{guid}/predictions", h.GetPlayerPredictions)
-
-			// Advanced Stats endpoints - "When" analysis, drill-down, combinations
-			r.Get("/player/{guid}/peak-performance", h.GetPlayerPeakPerformance)
-			r.Get("/player/{guid}/combos", h.GetPlayerComboMetrics)
-			r.Get("/player/{guid}/drilldown", h.GetPlayerDrillDown)
-			r.Get("/player/{guid}/vehicles", h.GetPlayerVehicleStats)
-			r.Get("/player/{guid}/game-flow", h.GetPlayerGameFlowStats)
-			r.Get("/player/{guid}/world", h.GetPlayerWorldStats)
-			r.Get("/player/{guid}/bots", h.GetPlayerBotStats)
-
-			r.Get("/map/{map}/heatmap", h.GetMapHeatmap)
-
-			r.Get("/match/{matchId}", h.GetMatchDetails)
-			r.Get("/match/{matchId}/advanced", h.GetMatchAdvancedDetails) // [NEW]
-			r.Get("/match/{matchId}/timeline", h.GetMatchTimeline)
-			r.Get("/match/{matchId}/heatmap", h.GetMatchHeatmap)
-			r.Get("/match/{matchId}/predictions", h.GetMatchPredictions)
-
-			r.Get("/query", h.GetDynamicStats)
-			r.Get("/server/{serverId}/stats", h.GetServerStats)
-			r.Get("/live/matches", h.GetLiveMatches)
+					// Advanced Stats endpoints - "When" analysis, drill-down, combinations
+					r.Get("/peak-performance", h.GetPlayerPeakPerformance)
+					r.Get("/combos", h.GetPlayerComboMetrics)
+					r.Get("/drilldown", h.GetPlayerDrillDown)
+					r.Get("/vehicles", h.GetPlayerVehicleStats)
+					r.Get("/game-flow", h.GetPlayerGameFlowStats)
+					r.Get("/world", h.GetPlayerWorldStats)
+					r.Get("/bots", h.GetPlayerBotStats)
+				})
+			})
+
+			// Totals and matches, scoped to the tenant's servers
+			r.Group(func(r chi.Router) {
+				r.Use(h.ServerScopeMiddleware)
+				r.Get("/global", h.GetGlobalStats)
+				r.Get("/match/{matchId}", h.GetMatchDetails)
+				r.Get("/match/{matchId}/advanced", h.GetMatchAdvancedDetails) // [NEW]
+				r.Get("/match/{matchId}/timeline", h.GetMatchTimeline)
+				r.Get("/match/{matchId}/heatmap", h.GetMatchHeatmap)
+				r.Get("/match/{matchId}/predictions", h.GetMatchPredictions)
+				r.Get("/match/{matchId}/card", h.GetMatchCard)
+				r.Get("/match/{matchId}/card.svg", h.GetMatchCardImage)
+			})
+
+			// The rest read every community's data
+			r.Group(func(r chi.Router) {
+				r.Use(h.UnscopedMiddleware)
+				r.Get("/global/activity", h.GetServerActivity)
+				r.Get("/server/pulse", h.GetServerPulse)
+				r.Get("/server/maps", h.GetServerMaps)
+				r.Get("/teams/performance", h.GetFactionPerformance) // [NEW]
+				r.Get("/weapons/list", h.GetWeaponsList)             // [NEW] Simple list for dropdowns
+				r.Get("/weapon/{weapon}", h.GetWeaponDetail)         // [NEW] Single weapon details
+
+				// Map statistics endpoints
+				r.Get("/maps", h.GetMapStats)      // All maps with stats
+				r.Get("/maps/list", h.GetMapsList) // Simple maps list
+				r.Get("/maps/popularity", h.GetMapPopularity)
+				r.Get("/map/{mapId}", h.GetMapDetail) // Single map details
+
+				// Game type statistics endpoints (derived from map prefixes)
+				r.Get("/gametypes", h.GetGameTypeStats)            // All game types with stats
+				r.Get("/gametypes/list", h.GetGameTypesList)       // Simple list for dropdowns
+				r.Get("/gametype/{gameType}", h.GetGameTypeDetail) // Single game type details
+				r.Get("/leaderboard/gametype/{gameType}", h.GetGameTypeLeaderboard)
+
+				r.Get("/leaderboard/cards", h.GetLeaderboardCards)
+				r.Get("/leaderboard/adjusted-kd", h.GetAdjustedKDLeaderboard)
+				r.Get("/leaderboard/weapon/{weapon}", h.GetWeaponLeaderboard)
+				r.Get("/leaderboard/map/{map}", h.GetMapLeaderboard)
+				r.Get("/member/{memberId}", h.GetPlayerStatsBySMFID) // Fetch stats using SMF Member ID from tracker.scr
+				r.Get("/player/name/{name}", h.GetPlayerStatsByName)
+				r.Get("/titles", h.ListTitles)
+				r.Get("/challenges", h.GetWeeklyChallenges)
+				r.Get("/quests", h.GetQuestChain)
+
+				r.Get("/map/{map}/heatmap", h.GetMapHeatmap)
+
+				r.Get("/predict/accuracy", h.GetPredictionAccuracy)
+				r.Get("/match/{matchId}/demos", h.GetMatchDemos)
+				r.Get("/match/{matchId}/media", h.GetMatchMedia)
+				r.Get("/discussions/{type}", h.LookupDiscussions)
+				r.Get("/discussions/{type}/{id}", h.GetDiscussion)
+
+				r.Get("/query", h.GetDynamicStats)
+				r.Get("/live/matches", h.GetLiveMatches)
+			})
 		})
 
 		// Tournament endpoints
@@ -266,29 +997,52 @@ func main() {
 			r.Get("/", h.GetTournaments)
 			r.Get("/{id}", h.GetTournament)
 			r.Get("/{id}/stats", h.GetTournamentStats)
+			r.Get("/{id}/matches/{mid}/result", h.GetTournamentMatchResult)
+			r.Get("/{id}/vetoes", h.ListMapVetoes)
+			r.Get("/{id}/vetoes/{seriesId}", h.GetMapVeto)
+			r.Get("/{id}/veto-stats", h.GetVetoAnalytics)
+			r.Get("/signing-key", h.GetResultSigningKey)
 		})
 
-		// Server tracking endpoints (New Dashboard System)
+		// Server tracking endpoints (New Dashboard System). Tenant API keys
+		// see their own servers and nothing across servers
 		r.Route("/servers", func(r chi.Router) {
-			r.Get("/", h.GetAllServers)                                   // List all servers with live status
-			r.Get("/stats", h.GetServersGlobalStats)                      // Aggregate stats across all servers
-			r.Get("/rankings", h.GetServerRankings)                       // Ranked server list
-			r.Get("/favorites", h.GetUserFavoriteServers)                 // User's favorite servers
-			r.Get("/{id}", h.GetServerDetail)                             // Full server details
-			r.Get("/{id}/live", h.GetServerLiveStatus)                    // Real-time server status
-			r.Get("/{id}/player-history", h.GetServerPlayerHistory)       // Player count history
-			r.Get("/{id}/peak-hours", h.GetServerPeakHours)               // Peak hours heatmap
-			r.Get("/{id}/top-players", h.GetServerTopPlayers)             // Top players on server
-			r.Get("/{id}/players", h.GetServerHistoricalPlayers)          // All players historical data
-			r.Get("/{id}/maps", h.GetServerMapStats)                      // Map statistics
-			r.Get("/{id}/map-rotation", h.GetServerMapRotation)           // Map rotation analysis
-			r.Get("/{id}/weapons", h.GetServerWeaponStats)                // Weapon statistics
-			r.Get("/{id}/matches", h.GetServerRecentMatches)              // Recent matches
-			r.Get("/{id}/activity-timeline", h.GetServerActivityTimeline) // Activity over time
-			r.Get("/{id}/countries", h.GetServerCountryStats)             // Player country distribution
-			r.Get("/{id}/favorite", h.CheckServerFavorite)                // Check if favorited
-			r.Post("/{id}/favorite", h.AddServerFavorite)                 // Add to favorites
-			r.Delete("/{id}/favorite", h.RemoveServerFavorite)            // Remove from favorites
+			r.Use(h.TenantMiddleware)
+			r.Get("/", h.GetAllServers)           // List all servers with live status
+			r.Get("/browser", h.GetServerBrowser) // In-game server browser listing
+
+			r.Group(func(r chi.Router) {
+				r.Use(h.UnscopedMiddleware)
+				r.Get("/stats", h.GetServersGlobalStats)      // Aggregate stats across all servers
+				r.Get("/rankings", h.GetServerRankings)       // Ranked server list
+				r.Get("/favorites", h.GetUserFavoriteServers) // User's favorite servers
+			})
+
+			r.Route("/{id}", func(r chi.Router) {
+				r.Use(h.TenantServerMiddleware("id"))
+				r.Get("/", h.GetServerDetail)                            // Full server details
+				r.Get("/live", h.GetServerLiveStatus)                    // Real-time server status
+				r.Get("/player-history", h.GetServerPlayerHistory)       // Player count history
+				r.Get("/peak-hours", h.GetServerPeakHours)               // Peak hours heatmap
+				r.Get("/top-players", h.GetServerTopPlayers)             // Top players on server
+				r.Get("/players", h.GetServerHistoricalPlayers)          // All players historical data
+				r.Get("/maps", h.GetServerMapStats)                      // Map statistics
+				r.Get("/map-rotation", h.GetServerMapRotation)           // Map rotation analysis
+				r.Get("/weapons", h.GetServerWeaponStats)                // Weapon statistics
+				r.Get("/griefing", h.GetServerGriefingReport)            // Top team-killers/damagers
+				r.Get("/vehicles", h.GetServerVehicleUsage)              // Vehicle usage per type
+				r.Get("/latency", h.GetServerLatency)                    // Player ping distribution
+				r.Get("/performance", h.GetServerPerformance)            // Server FPS and frame times
+				r.Get("/matches", h.GetServerRecentMatches)              // Recent matches
+				r.Get("/activity-timeline", h.GetServerActivityTimeline) // Activity over time
+				r.Get("/countries", h.GetServerCountryStats)             // Player country distribution
+				r.Get("/favorite", h.CheckServerFavorite)                // Check if favorited
+				r.Post("/favorite", h.AddServerFavorite)                 // Add to favorites
+				r.Delete("/favorite", h.RemoveServerFavorite)            // Remove from favorites
+
+				// Single player's stats on this server; hidden profiles answer 404
+				r.With(h.PlayerVisibilityMiddleware).Get("/players/{guid}", h.GetServerPlayerProfile)
+			})
 		})
 
 		// Achievement endpoints - match/tournament specific
@@ -325,6 +1079,22 @@ func main() {
 			r.Put("/me", h.UpdateCurrentUser)
 			r.Get("/me/identities", h.GetUserIdentities)
 			r.Delete("/me/identities/{id}", h.UnlinkIdentity)
+			r.Get("/me/titles", h.GetUserTitles)
+			r.Post("/me/titles/{code}/equip", h.EquipTitle)
+			r.Delete("/me/titles/{code}/equip", h.UnequipTitle)
+			r.Get("/me/privacy", h.GetUserPrivacy)
+			r.Put("/me/privacy", h.SetUserPrivacy)
+			r.Get("/me/media", h.GetUserMedia)
+			r.Post("/me/media", h.SubmitMatchMedia)
+			r.Get("/me/notifications", h.GetNotifications)
+			r.Get("/me/notifications/unread", h.GetUnreadNotifications)
+			r.Post("/me/notifications/read", h.MarkNotificationsRead)
+		})
+
+		// Abuse reports against players, triaged under /admin/reports
+		r.Route("/reports", func(r chi.Router) {
+			r.Get("/", h.GetUserReports)
+			r.Post("/", h.SubmitReport)
 		})
 
 		// Achievement endpoints
@@ -333,19 +1103,29 @@ func main() {
 			r.Get("/recent", h.GetRecentAchievements)
 			r.Get("/leaderboard", h.GetAchievementLeaderboard)
 			r.Get("/{id}", h.GetAchievement)
-			r.Get("/player/{guid}", h.GetPlayerAchievements)
+			r.With(h.PlayerVisibilityMiddleware).Get("/player/{guid}", h.GetPlayerAchievements)
 			r.Get("/match/{match_id}", h.GetMatchAchievements)
 			r.Get("/tournament/{tournament_id}", h.GetTournamentAchievements)
 		})
 	})
 
+	// API v2 Routes
+	r.Route("/api/v2", func(r chi.Router) {
+		r.Use(h.RateLimitMiddleware)
+
+		r.Route("/ingest", func(r chi.Router) {
+			r.Use(h.SandboxAuthMiddleware)
+			r.Post("/events", h.IngestEventsV2)
+		})
+	})
+
 	// HTMX partial endpoints (for frontend SSR)
 	r.Route("/partials", func(r chi.Router) {
 		r.Get("/live-matches", h.PartialLiveMatches)
 		r.Get("/leaderboard", h.PartialLeaderboard)
 		r.Get("/recent-matches", h.PartialRecentMatches)
-		r.Get("/player-card/{guid}", h.PartialPlayerCard)
-		r.Get("/player/{guid}/matches", h.PartialPlayerMatches)
+		r.With(h.PlayerVisibilityMiddleware).Get("/player-card/{guid}", h.PartialPlayerCard)
+		r.With(h.PlayerVisibilityMiddleware).Get("/player/{guid}/matches", h.PartialPlayerMatches)
 	})
 
 	// Frontend routes (SSR HTML pages)
@@ -372,6 +1152,37 @@ func main() {
 		IdleTimeout:  120 * time.Second,
 	}
 
+	// pprof and runtime snapshots on a separate, unauthenticated listener
+	var diagServer *http.Server
+	if cfg.DiagnosticsAddr != "" {
+		diagServer = &http.Server{
+			Addr:    cfg.DiagnosticsAddr,
+			Handler: newDiagnosticsHandler(workerPool, started),
+		}
+		go func() {
+			sugar.Infow("Diagnostics listening", "addr", cfg.DiagnosticsAddr)
+			if err := diagServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				sugar.Errorw("Diagnostics listener failed", "error", err)
+			}
+		}()
+	}
+
+	// gRPC for companion services, answered by the REST routes above
+	var grpcServer *grpc.Server
+	if cfg.GRPCAddr != "" {
+		lis, err := net.Listen("tcp", cfg.GRPCAddr)
+		if err != nil {
+			sugar.Fatalw("gRPC listener failed", "addr", cfg.GRPCAddr, "error", err)
+		}
+		grpcServer = grpcapi.NewServer(r, logger)
+		go func() {
+			sugar.Infow("gRPC listening", "addr", cfg.GRPCAddr)
+			if err := grpcServer.Serve(lis); err != nil {
+				sugar.Errorw("gRPC listener failed", "error", err)
+			}
+		}()
+	}
+
 	// Graceful shutdown
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
@@ -393,8 +1204,66 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	signal.Stop(reload)
+	ingestLag.Stop()
+	alerts.Stop()
+	redisJanitor.Stop()
+	cachePurges.Stop()
+	matchReconciler.Stop()
+	reconcilerLeader.Stop()
+	aggregateChecker.Stop()
+	highlightsScheduler.Stop()
+	snapshotScheduler.Stop()
+	serverReportScheduler.Stop()
+	jobScheduler.Stop()
+	challengeEngine.Stop()
+	if profiles != nil {
+		profiles.Stop()
+	}
+	aggregateRebuilder.Stop()
+	if streamPublisher != nil {
+		streamPublisher.Stop()
+	}
+	if streamConsumer != nil {
+		streamConsumer.Stop()
+	}
 	workerPool.Stop()
+	if connections != nil {
+		connections.Stop()
+	}
+	if eventBus != nil {
+		eventBus.Stop()
+	}
 	server.Shutdown(ctx)
+	if grpcServer != nil {
+		// Let calls in flight finish, up to the shutdown deadline
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			grpcServer.Stop()
+		}
+	}
+	if diagServer != nil {
+		diagServer.Shutdown(ctx)
+	}
 
 	sugar.Info("Server stopped")
 }
+
+// loggingSettings collects the reloadable log settings from the config
+func loggingSettings(c *config.Config) (logging.Settings, error) {
+	components, err := logging.ParseComponentLevels(c.LogComponentLevels)
+	if err != nil {
+		return logging.Settings{}, err
+	}
+	return logging.Settings{
+		Level:      c.LogLevel,
+		Components: components,
+		Sampled:    logging.ParseList(c.LogSampled),
+	}, nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+
+	"github.com/openmohaa/stats-api/internal/config"
+	"github.com/openmohaa/stats-api/internal/db"
+	"github.com/openmohaa/stats-api/internal/logic"
+)
+
+// runTenant implements `api tenant create -slug <slug> -name <name>`. The API
+// key is printed once; only its hash is stored.
+func runTenant(args []string) error {
+	if len(args) == 0 || args[0] != "create" {
+		return fmt.Errorf("usage: api tenant create -slug <slug> -name <name>")
+	}
+
+	fs := flag.NewFlagSet("tenant create", flag.ContinueOnError)
+	slug := fs.String("slug", "", "short unique identifier, e.g. my-clan")
+	name := fs.String("name", "", "display name")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *slug == "" || *name == "" {
+		return fmt.Errorf("-slug and -name are required")
+	}
+
+	cfg := config.Load()
+	if cfg.ConfigFile != "" {
+		if err := config.ApplyEnvFile(cfg.ConfigFile); err != nil {
+			return fmt.Errorf("read config: %w", err)
+		}
+		cfg = config.Load()
+	}
+
+	ctx := context.Background()
+	pgPool, err := db.NewPostgresPool(ctx, cfg.PostgresURL)
+	if err != nil {
+		return fmt.Errorf("connect to PostgreSQL: %w", err)
+	}
+	defer pgPool.Close()
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return err
+	}
+	key := hex.EncodeToString(raw)
+	sum := sha256.Sum256([]byte(key))
+
+	tenant, err := logic.NewTenantService(pgPool).Create(ctx, *slug, *name, hex.EncodeToString(sum[:]))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Tenant:  %s (%s)\n", tenant.Slug, tenant.ID)
+	fmt.Printf("API key: %s\n", key)
+	fmt.Println("Send it as X-API-Key on stats requests and when registering the tenant's servers.")
+	return nil
+}
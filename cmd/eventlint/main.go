@@ -0,0 +1,113 @@
+// eventlint - checks NDJSON event captures from game-script mods against the
+// event contract of the ingest API
+//
+// Point a mod's event output at a file (one JSON event per line), then run
+//
+//	eventlint [-json] [-strict] [-q] events.ndjson ...
+//
+// Reads stdin when no file (or "-") is given. Each issue is printed as
+// file:line: severity: type: field: message. Exits 1 when any file has
+// errors (or warnings, with -strict), 2 when a file cannot be read.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/openmohaa/stats-api/internal/eventlint"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// fileResult is the -json output for one file
+type fileResult struct {
+	File string `json:"file"`
+	*eventlint.Result
+}
+
+func main() {
+	asJSON := flag.Bool("json", false, "print results as JSON")
+	strict := flag.Bool("strict", false, "fail on warnings too")
+	quiet := flag.Bool("q", false, "print errors only, no warnings or summary")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: eventlint [-json] [-strict] [-q] [file.ndjson ...]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	files := flag.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	failed := false
+	var results []fileResult
+	for _, name := range files {
+		res, err := lintFile(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "eventlint: %v\n", err)
+			os.Exit(2)
+		}
+		if res.Errors() > 0 || (*strict && res.Warnings() > 0) {
+			failed = true
+		}
+		if *asJSON {
+			results = append(results, fileResult{File: name, Result: res})
+			continue
+		}
+		printResult(os.Stdout, name, res, *quiet)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(results)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// lintFile lints a file, or stdin for "-"
+func lintFile(name string) (*eventlint.Result, error) {
+	if name == "-" {
+		return eventlint.Lint(os.Stdin)
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	res, err := eventlint.Lint(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return res, nil
+}
+
+// printResult writes the issues and a summary with the type counts
+func printResult(w io.Writer, name string, res *eventlint.Result, quiet bool) {
+	for _, issue := range res.Issues {
+		if quiet && issue.Severity != eventlint.SeverityError {
+			continue
+		}
+		fmt.Fprintf(w, "%s:%s\n", name, issue)
+	}
+	if quiet {
+		return
+	}
+
+	types := make([]models.EventType, 0, len(res.Types))
+	for typ := range res.Types {
+		types = append(types, typ)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	fmt.Fprintf(w, "%s: %d events, %d errors, %d warnings\n", name, res.Events, res.Errors(), res.Warnings())
+	for _, typ := range types {
+		fmt.Fprintf(w, "  %-28s %d\n", typ, res.Types[typ])
+	}
+}
@@ -0,0 +1,95 @@
+// statsctl - operator CLI for a Stats API deployment
+//
+// Connection settings come from the same environment (and CONFIG_FILE) as the
+// API itself, so the tool talks to whatever databases the service uses.
+//
+//	statsctl query [-pg] <sql>      run an ad-hoc query and print the rows
+//	statsctl player <guid>          summarize a player's stored events
+//	statsctl token -server <name>   show, check, rotate, sandbox or grant admin to a server token
+//	statsctl migrate                apply pending migrations
+//	statsctl seed -token <token>    post a synthetic match to the ingest API
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/openmohaa/stats-api/internal/config"
+	"github.com/openmohaa/stats-api/internal/db"
+)
+
+const usage = `usage: statsctl <command> [flags]
+
+commands:
+  query    run an ad-hoc ClickHouse (or -pg Postgres) query
+  player   summarize a player's stored events
+  token    show, check, rotate, sandbox or grant admin to a server token
+  migrate  apply pending Postgres and ClickHouse migrations
+  seed     post a synthetic match to the ingest API`
+
+// commands maps each subcommand to its implementation
+var commands = map[string]func(args []string) error{
+	"query":   runQuery,
+	"player":  runPlayer,
+	"token":   runToken,
+	"migrate": runMigrate,
+	"seed":    runSeed,
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	run, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s\n", os.Args[1], usage)
+		os.Exit(2)
+	}
+	if err := run(os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "statsctl %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+// loadConfig reads the API configuration, including CONFIG_FILE when set
+func loadConfig() (*config.Config, error) {
+	cfg := config.Load()
+	if cfg.ConfigFile != "" {
+		if err := config.ApplyEnvFile(cfg.ConfigFile); err != nil {
+			return nil, fmt.Errorf("read config: %w", err)
+		}
+		cfg = config.Load()
+	}
+	return cfg, nil
+}
+
+// connectPostgres opens the configured Postgres database
+func connectPostgres(ctx context.Context, cfg *config.Config) (*pgxpool.Pool, error) {
+	pool, err := db.NewPostgresPool(ctx, cfg.PostgresURL)
+	if err != nil {
+		return nil, fmt.Errorf("connect to PostgreSQL: %w", err)
+	}
+	return pool, nil
+}
+
+// connectClickHouse opens the configured ClickHouse database with a small
+// pool, since the CLI runs one query at a time
+func connectClickHouse(ctx context.Context, cfg *config.Config) (driver.Conn, error) {
+	conn, err := db.NewClickHouseConn(ctx, cfg.ClickHouseURL, db.ClickHouseOptions{
+		MaxOpenConns: 2,
+		MaxIdleConns: 1,
+		DialTimeout:  cfg.ClickHouseDialTimeout,
+		ReadTimeout:  cfg.ClickHouseReadTimeout,
+		Compression:  cfg.ClickHouseCompression,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("connect to ClickHouse: %w", err)
+	}
+	return conn, nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/db"
+)
+
+// runMigrate implements `statsctl migrate [-dir <path>]`, applying the
+// migrations not yet recorded in schema_migrations
+func runMigrate(args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	dir := fs.String("dir", cfg.MigrationsDir, "migrations directory")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	pg, err := connectPostgres(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer pg.Close()
+
+	ch, err := connectClickHouse(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	applied, err := db.Migrate(ctx, pg, ch, *dir, zap.NewNop())
+	for _, name := range applied {
+		fmt.Printf("applied %s\n", name)
+	}
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		fmt.Println("Nothing to apply, schema is up to date.")
+	}
+	return nil
+}
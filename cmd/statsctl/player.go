@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+)
+
+// runPlayer implements `statsctl player <guid>`: a quick check of what
+// raw_events holds for one player, independent of the aggregate tables
+func runPlayer(args []string) error {
+	fs := flag.NewFlagSet("player", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: statsctl player <guid>")
+	}
+	guid := fs.Arg(0)
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	ch, err := connectClickHouse(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	var (
+		name                  string
+		events, kills, deaths uint64
+		headshots, matches    uint64
+		firstSeen, lastSeen   time.Time
+	)
+	if err := ch.QueryRow(ctx, `
+		SELECT
+			argMaxIf(actor_name, timestamp, actor_id = ?) AS name,
+			count() AS events,
+			countIf(event_type = 'player_kill' AND actor_id = ?) AS kills,
+			countIf(event_type = 'player_kill' AND target_id = ?) AS deaths,
+			countIf(event_type = 'player_kill' AND actor_id = ? AND hitloc IN ('head', 'helmet')) AS headshots,
+			uniqExact(match_id) AS matches,
+			min(timestamp) AS first_seen,
+			max(timestamp) AS last_seen
+		FROM mohaa_stats.raw_events
+		WHERE actor_id = ? OR target_id = ?
+	`, guid, guid, guid, guid, guid, guid).Scan(
+		&name, &events, &kills, &deaths, &headshots, &matches, &firstSeen, &lastSeen,
+	); err != nil {
+		return err
+	}
+	if events == 0 {
+		return fmt.Errorf("no events stored for %s", guid)
+	}
+
+	fmt.Printf("GUID:       %s\n", guid)
+	fmt.Printf("Name:       %s\n", name)
+	fmt.Printf("Events:     %d in %d matches\n", events, matches)
+	fmt.Printf("Kills:      %d (%d headshots)\n", kills, headshots)
+	fmt.Printf("Deaths:     %d\n", deaths)
+	fmt.Printf("First seen: %s\n", firstSeen.Format(time.RFC3339))
+	fmt.Printf("Last seen:  %s\n", lastSeen.Format(time.RFC3339))
+	return nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/config"
+)
+
+// runQuery implements `statsctl query [-pg] <sql>`: the rows are printed as
+// tab-aligned columns under a header line
+func runQuery(args []string) error {
+	fs := flag.NewFlagSet("query", flag.ContinueOnError)
+	usePG := fs.Bool("pg", false, "query Postgres instead of ClickHouse")
+	timeout := fs.Duration("timeout", 30*time.Second, "query timeout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	sql := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if sql == "" {
+		return fmt.Errorf("usage: statsctl query [-pg] <sql>")
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	defer tw.Flush()
+
+	if *usePG {
+		return queryPostgres(ctx, cfg, sql, tw)
+	}
+
+	ch, err := connectClickHouse(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	rows, err := ch.Query(ctx, sql)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	writeRow(tw, rows.Columns())
+	types := rows.ColumnTypes()
+	for rows.Next() {
+		dest := make([]interface{}, len(types))
+		for i, t := range types {
+			dest[i] = reflect.New(t.ScanType()).Interface()
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		values := make([]interface{}, len(dest))
+		for i, d := range dest {
+			values[i] = reflect.ValueOf(d).Elem().Interface()
+		}
+		writeRow(tw, formatValues(values))
+	}
+	return rows.Err()
+}
+
+// queryPostgres runs sql against Postgres and writes the rows to w
+func queryPostgres(ctx context.Context, cfg *config.Config, sql string, w io.Writer) error {
+	pg, err := connectPostgres(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer pg.Close()
+
+	rows, err := pg.Query(ctx, sql)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var header []string
+	for _, f := range rows.FieldDescriptions() {
+		header = append(header, f.Name)
+	}
+	writeRow(w, header)
+	for rows.Next() {
+		values, err := rows.Values()
+		if err != nil {
+			return err
+		}
+		writeRow(w, formatValues(values))
+	}
+	return rows.Err()
+}
+
+// formatValues renders one row for display; NULLs print as NULL and times in
+// RFC 3339
+func formatValues(values []interface{}) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		rv := reflect.ValueOf(v)
+		for rv.IsValid() && rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				rv = reflect.Value{}
+				break
+			}
+			rv = rv.Elem()
+		}
+		switch {
+		case !rv.IsValid():
+			out[i] = "NULL"
+		case rv.Type() == reflect.TypeOf(time.Time{}):
+			out[i] = rv.Interface().(time.Time).Format(time.RFC3339)
+		case rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8:
+			out[i] = string(rv.Bytes())
+		default:
+			out[i] = fmt.Sprint(rv.Interface())
+		}
+	}
+	return out
+}
+
+func writeRow(w io.Writer, cols []string) {
+	fmt.Fprintln(w, strings.Join(cols, "\t"))
+}
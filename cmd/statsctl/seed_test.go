@@ -0,0 +1,40 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestSeedMatch(t *testing.T) {
+	events := seedMatch(rand.New(rand.NewSource(1)), 4, 20, time.Unix(1700000000, 0))
+
+	if got := len(events); got != 1+4*2+20+1 {
+		t.Fatalf("len(events) = %d, want %d", got, 1+4*2+20+1)
+	}
+	if events[0].Type != models.EventMatchStart || events[len(events)-1].Type != models.EventMatchEnd {
+		t.Errorf("match not bracketed by start/end: %s ... %s", events[0].Type, events[len(events)-1].Type)
+	}
+	for _, e := range events {
+		if e.MatchID != events[0].MatchID {
+			t.Fatalf("event %s has match %s, want %s", e.Type, e.MatchID, events[0].MatchID)
+		}
+		if e.Type == models.EventPlayerKill && e.AttackerTeam == e.VictimTeam {
+			t.Errorf("team kill generated: %+v", e)
+		}
+	}
+}
+
+func TestFormatValues(t *testing.T) {
+	s := "x"
+	var nilStr *string
+	got := formatValues([]interface{}{nil, &s, nilStr, []byte("raw"), uint64(7), time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)})
+	want := []string{"NULL", "x", "NULL", "raw", "7", "2026-01-02T03:04:05Z"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("formatValues[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// runToken implements `statsctl token -server <name|id> [-check <token>] [-rotate] [-sandbox on|off] [-admin on|off]`.
+// Server tokens are stored as SHA-256 hashes, so a lost token cannot be read
+// back; -rotate issues a new one and prints it once. A sandbox token's events
+// are stored apart and never reach the aggregates or leaderboards. Only admin
+// servers' tokens reach /admin and /system.
+func runToken(args []string) error {
+	fs := flag.NewFlagSet("token", flag.ContinueOnError)
+	server := fs.String("server", "", "server name or ID")
+	check := fs.String("check", "", "report whether this token authenticates the server")
+	rotate := fs.Bool("rotate", false, "replace the server's token and print the new one")
+	sandbox := fs.String("sandbox", "", "on to store the server's events apart from the live data, off to go live")
+	admin := fs.String("admin", "", "on to let the server's token use the admin endpoints, off to revoke")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *server == "" {
+		return fmt.Errorf("usage: statsctl token -server <name|id> [-check <token>] [-rotate] [-sandbox on|off] [-admin on|off]")
+	}
+	if *sandbox != "" && *sandbox != "on" && *sandbox != "off" {
+		return fmt.Errorf("-sandbox must be on or off")
+	}
+	if *admin != "" && *admin != "on" && *admin != "off" {
+		return fmt.Errorf("-admin must be on or off")
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	pg, err := connectPostgres(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer pg.Close()
+
+	var id, name, hash string
+	var active, isSandbox, isAdmin bool
+	err = pg.QueryRow(ctx, `
+		SELECT id::text, name, token, COALESCE(is_active, false), sandbox, admin
+		FROM servers
+		WHERE id::text = $1 OR name = $1
+		ORDER BY (id::text = $1) DESC
+		LIMIT 1
+	`, *server).Scan(&id, &name, &hash, &active, &isSandbox, &isAdmin)
+	if err != nil {
+		return fmt.Errorf("server %q not found: %w", *server, err)
+	}
+
+	fmt.Printf("Server: %s (%s)\n", name, id)
+	fmt.Printf("Active: %v\n", active)
+	fmt.Printf("Hash:   %s\n", hash)
+
+	if *sandbox != "" {
+		isSandbox = *sandbox == "on"
+		if _, err := pg.Exec(ctx, "UPDATE servers SET sandbox = $1 WHERE id::text = $2", isSandbox, id); err != nil {
+			return fmt.Errorf("set sandbox: %w", err)
+		}
+	}
+	fmt.Printf("Sandbox: %v\n", isSandbox)
+
+	if *admin != "" {
+		isAdmin = *admin == "on"
+		if _, err := pg.Exec(ctx, "UPDATE servers SET admin = $1 WHERE id::text = $2", isAdmin, id); err != nil {
+			return fmt.Errorf("set admin: %w", err)
+		}
+	}
+	fmt.Printf("Admin:  %v\n", isAdmin)
+
+	if *check != "" {
+		fmt.Printf("Check:  %v\n", hashToken(*check) == hash)
+	}
+	if *rotate {
+		token := uuid.New().String()
+		if _, err := pg.Exec(ctx, "UPDATE servers SET token = $1 WHERE id::text = $2", hashToken(token), id); err != nil {
+			return fmt.Errorf("store new token: %w", err)
+		}
+		fmt.Printf("Token:  %s\n", token)
+		fmt.Println("The previous token no longer authenticates; update the server's config.")
+	}
+	return nil
+}
+
+// hashToken matches the hashing the API applies to X-Server-Token
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,48 @@
+// Package bus publishes messages to a message bus for consumers outside the
+// API: a NATS server, or Redis pub/sub on the live state Redis
+package bus
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openmohaa/stats-api/internal/db"
+)
+
+// Publisher sends a message on a subject. Subjects are dot separated, e.g.
+// mohaa.events.player_kill, so subscribers can filter with wildcards.
+type Publisher interface {
+	Publish(ctx context.Context, subject string, data []byte) error
+	Close() error
+}
+
+// Open returns the publisher of a bus kind: "nats" connects to url, "redis"
+// publishes on the live state Redis, where subjects are channel names
+func Open(kind, url, name string, store db.LiveStateStore) (Publisher, error) {
+	switch kind {
+	case "nats":
+		return NewNATS(url, name)
+	case "redis":
+		if _, ok := db.RedisClientOf(store); !ok {
+			return nil, fmt.Errorf("redis event bus needs LIVE_STATE_BACKEND=redis")
+		}
+		return liveStatePublisher{store: store}, nil
+	default:
+		return nil, fmt.Errorf("unknown event bus %q, want nats or redis", kind)
+	}
+}
+
+// liveStatePublisher publishes with Redis PUBLISH. Nothing is kept for
+// subscribers that are not connected.
+type liveStatePublisher struct {
+	store db.LiveStateStore
+}
+
+func (p liveStatePublisher) Publish(ctx context.Context, subject string, data []byte) error {
+	return p.store.Publish(ctx, subject, data)
+}
+
+// Close leaves the live state store to its owner
+func (p liveStatePublisher) Close() error {
+	return nil
+}
@@ -0,0 +1,227 @@
+package bus
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	natsDefaultPort  = "4222"
+	natsDialTimeout  = 5 * time.Second
+	natsWriteTimeout = 5 * time.Second
+)
+
+// NATS publishes on a NATS server with the core protocol: fire and forget,
+// no JetStream acknowledgements. It connects on the first publish and again
+// after a failure; publishes while disconnected fail.
+type NATS struct {
+	addr  string
+	tls   bool
+	name  string
+	user  string
+	pass  string
+	token string
+
+	mu         sync.Mutex
+	conn       net.Conn
+	w          *bufio.Writer
+	maxPayload int
+}
+
+// NewNATS parses a server URL: nats://[user:pass@|token@]host[:port], or
+// tls:// for a TLS connection. name identifies the connection on the server.
+func NewNATS(rawURL, name string) (*NATS, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NATS URL: %w", err)
+	}
+	if u.Scheme != "nats" && u.Scheme != "tls" {
+		return nil, fmt.Errorf("invalid NATS URL scheme %q, want nats or tls", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("NATS URL has no host")
+	}
+	port := u.Port()
+	if port == "" {
+		port = natsDefaultPort
+	}
+	n := &NATS{addr: net.JoinHostPort(u.Hostname(), port), tls: u.Scheme == "tls", name: name}
+	if u.User != nil {
+		if pass, ok := u.User.Password(); ok {
+			n.user, n.pass = u.User.Username(), pass
+		} else {
+			n.token = u.User.Username()
+		}
+	}
+	return n, nil
+}
+
+// natsInfo is the part of the server's INFO the publisher uses
+type natsInfo struct {
+	MaxPayload  int  `json:"max_payload"`
+	TLSRequired bool `json:"tls_required"`
+}
+
+// natsConnect is the CONNECT options the publisher sends
+type natsConnect struct {
+	Verbose  bool   `json:"verbose"`
+	Pedantic bool   `json:"pedantic"`
+	Lang     string `json:"lang"`
+	Version  string `json:"version"`
+	Name     string `json:"name,omitempty"`
+	User     string `json:"user,omitempty"`
+	Pass     string `json:"pass,omitempty"`
+	Token    string `json:"auth_token,omitempty"`
+}
+
+// Publish sends data on subject
+func (n *NATS) Publish(ctx context.Context, subject string, data []byte) error {
+	if subject == "" || strings.ContainsAny(subject, " \t\r\n") {
+		return fmt.Errorf("invalid NATS subject %q", subject)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.conn == nil {
+		if err := n.connect(ctx); err != nil {
+			return fmt.Errorf("failed to connect to NATS: %w", err)
+		}
+	}
+	if n.maxPayload > 0 && len(data) > n.maxPayload {
+		return fmt.Errorf("message of %d bytes exceeds the NATS max payload of %d", len(data), n.maxPayload)
+	}
+
+	n.conn.SetWriteDeadline(time.Now().Add(natsWriteTimeout))
+	fmt.Fprintf(n.w, "PUB %s %d\r\n", subject, len(data))
+	n.w.Write(data)
+	n.w.WriteString("\r\n")
+	if err := n.w.Flush(); err != nil {
+		n.closeLocked()
+		return fmt.Errorf("failed to publish to NATS: %w", err)
+	}
+	return nil
+}
+
+// Close disconnects from the server
+func (n *NATS) Close() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.closeLocked()
+	return nil
+}
+
+func (n *NATS) closeLocked() {
+	if n.conn != nil {
+		n.conn.Close()
+		n.conn = nil
+		n.w = nil
+	}
+}
+
+// connect dials the server, reads its INFO, sends CONNECT and waits for the
+// PONG to a PING, which follows an authorization error if there is one.
+// Caller holds mu.
+func (n *NATS) connect(ctx context.Context) error {
+	dialer := &net.Dialer{Timeout: natsDialTimeout}
+	var conn net.Conn
+	var err error
+	if n.tls {
+		host, _, _ := net.SplitHostPort(n.addr)
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host}}).DialContext(ctx, "tcp", n.addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", n.addr)
+	}
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(natsDialTimeout))
+	r := bufio.NewReader(conn)
+
+	line, err := r.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	infoJSON, ok := strings.CutPrefix(strings.TrimSpace(line), "INFO ")
+	if !ok {
+		conn.Close()
+		return fmt.Errorf("unexpected greeting %q", strings.TrimSpace(line))
+	}
+	var info natsInfo
+	if err := json.Unmarshal([]byte(infoJSON), &info); err != nil {
+		conn.Close()
+		return fmt.Errorf("invalid INFO: %w", err)
+	}
+	if info.TLSRequired && !n.tls {
+		conn.Close()
+		return errors.New("server requires TLS, use a tls:// URL")
+	}
+
+	options, _ := json.Marshal(natsConnect{
+		Lang: "go", Version: "1.0.0", Name: n.name,
+		User: n.user, Pass: n.pass, Token: n.token,
+	})
+	w := bufio.NewWriter(conn)
+	fmt.Fprintf(w, "CONNECT %s\r\nPING\r\n", options)
+	if err := w.Flush(); err != nil {
+		conn.Close()
+		return err
+	}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			conn.Close()
+			return err
+		}
+		line = strings.TrimSpace(line)
+		if line == "PONG" {
+			break
+		}
+		if msg, ok := strings.CutPrefix(line, "-ERR "); ok {
+			conn.Close()
+			return fmt.Errorf("server error: %s", strings.Trim(msg, "'"))
+		}
+	}
+	conn.SetDeadline(time.Time{})
+
+	n.conn, n.w, n.maxPayload = conn, w, info.MaxPayload
+	go n.serve(conn, r)
+	return nil
+}
+
+// serve answers the server's keepalive PINGs on conn until it closes, and
+// drops the connection on a server error so the next publish reconnects
+func (n *NATS) serve(conn net.Conn, r *bufio.Reader) {
+	for {
+		line, err := r.ReadString('\n')
+		if err == nil && strings.HasPrefix(line, "PING") {
+			n.mu.Lock()
+			if n.conn == conn {
+				conn.SetWriteDeadline(time.Now().Add(natsWriteTimeout))
+				n.w.WriteString("PONG\r\n")
+				err = n.w.Flush()
+			}
+			n.mu.Unlock()
+		}
+		if err == nil && strings.HasPrefix(line, "-ERR") {
+			err = errors.New(strings.TrimSpace(line))
+		}
+		if err != nil {
+			n.mu.Lock()
+			if n.conn == conn {
+				n.closeLocked()
+			}
+			n.mu.Unlock()
+			return
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package bus
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+// fakeNATS accepts one connection, checks the handshake and returns the
+// subject and payload of the first PUB
+func fakeNATS(t *testing.T, ln net.Listener, got chan<- string) {
+	conn, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	io.WriteString(conn, `INFO {"server_id":"test","max_payload":64}`+"\r\n")
+
+	connect, _ := r.ReadString('\n')
+	if !strings.HasPrefix(connect, "CONNECT ") || !strings.Contains(connect, `"auth_token":"secret"`) {
+		got <- "bad connect: " + connect
+		return
+	}
+	if ping, _ := r.ReadString('\n'); ping != "PING\r\n" {
+		got <- "bad ping: " + ping
+		return
+	}
+	io.WriteString(conn, "PONG\r\n")
+
+	pub, _ := r.ReadString('\n')
+	payload, _ := r.ReadString('\n')
+	got <- strings.TrimSpace(pub) + "|" + strings.TrimSpace(payload)
+}
+
+func TestNATSPublish(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("no loopback listener: %v", err)
+	}
+	defer ln.Close()
+	got := make(chan string, 1)
+	go fakeNATS(t, ln, got)
+
+	n, err := NewNATS("nats://secret@"+ln.Addr().String(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer n.Close()
+
+	if err := n.Publish(context.Background(), "mohaa.events.player_kill", []byte(`{"a":1}`)); err != nil {
+		t.Fatal(err)
+	}
+	if msg := <-got; msg != `PUB mohaa.events.player_kill 7|{"a":1}` {
+		t.Errorf("server got %q", msg)
+	}
+	if err := n.Publish(context.Background(), "big", make([]byte, 65)); err == nil {
+		t.Error("published past max_payload")
+	}
+	if err := n.Publish(context.Background(), "bad subject", nil); err == nil {
+		t.Error("published on a subject with a space")
+	}
+}
+
+func TestNewNATS(t *testing.T) {
+	n, err := NewNATS("nats://user:pw@bus.example", "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.addr != "bus.example:4222" || n.user != "user" || n.pass != "pw" || n.token != "" || n.tls {
+		t.Errorf("parsed %+v", n)
+	}
+	if n, _ := NewNATS("tls://tok@bus.example:4443", "api"); n == nil || !n.tls || n.token != "tok" {
+		t.Errorf("parsed tls URL as %+v", n)
+	}
+	for _, bad := range []string{"kafka://broker:9092", "nats://", "::"} {
+		if _, err := NewNATS(bad, "api"); err == nil {
+			t.Errorf("NewNATS(%q) accepted", bad)
+		}
+	}
+}
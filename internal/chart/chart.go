@@ -0,0 +1,149 @@
+// Package chart draws the SVG charts the API and its tools serve: bar charts
+// and share cards that embed one
+package chart
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+const (
+	background = "#1a1a1a"
+	foreground = "white"
+	muted      = "#aaaaaa"
+	font       = "Arial"
+)
+
+// BarChart is a titled bar chart with a label under and the value over
+// each bar
+type BarChart struct {
+	Title  string
+	Labels []string
+	Values []uint64
+	Color  string
+	Width  int // 600 when zero
+	Height int // 400 when zero
+}
+
+// SVG renders the chart as a standalone SVG document
+func (c BarChart) SVG() string {
+	return c.render("")
+}
+
+// Embed renders the chart as an SVG element placed at x, y inside another
+func (c BarChart) Embed(x, y int) string {
+	return c.render(fmt.Sprintf(` x="%d" y="%d"`, x, y))
+}
+
+func (c BarChart) render(position string) string {
+	width, height := c.Width, c.Height
+	if width == 0 {
+		width = 600
+	}
+	if height == 0 {
+		height = 400
+	}
+	padding := 50
+	maxBarHeight := height - 2*padding
+
+	var maxVal uint64
+	for _, v := range c.Values {
+		maxVal = max(maxVal, v)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg%s width="%d" height="%d" viewBox="0 0 %d %d" xmlns="http://www.w3.org/2000/svg">`, position, width, height, width, height)
+
+	// Background
+	fmt.Fprintf(&sb, `<rect width="100%%" height="100%%" fill="%s" />`, background)
+
+	// Title
+	text(&sb, width/2, 30, 20, "middle", foreground, c.Title)
+
+	if len(c.Values) > 0 {
+		barWidth := (width - 2*padding) / len(c.Values)
+		for i, val := range c.Values {
+			barHeight := 0
+			if maxVal > 0 {
+				barHeight = int((val * uint64(maxBarHeight)) / maxVal)
+			}
+			x := padding + i*barWidth
+			y := height - padding - barHeight
+
+			// Bar
+			fmt.Fprintf(&sb, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s" rx="4" />`, x+5, y, barWidth-10, barHeight, escape(c.Color))
+
+			// Label (rotated)
+			if i < len(c.Labels) {
+				lx, ly := x+barWidth/2, height-padding+20
+				fmt.Fprintf(&sb, `<text x="%d" y="%d" fill="%s" font-family="%s" font-size="12" text-anchor="end" transform="rotate(-45 %d %d)">%s</text>`,
+					lx, ly, foreground, font, lx, ly, escape(c.Labels[i]))
+			}
+
+			// Value on top
+			text(&sb, x+barWidth/2, y-5, 10, "middle", foreground, fmt.Sprint(val))
+		}
+	}
+
+	// X-axis
+	fmt.Fprintf(&sb, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="%s" stroke-width="2" />`, padding, height-padding, width-padding, height-padding, foreground)
+
+	sb.WriteString(`</svg>`)
+	return sb.String()
+}
+
+// Card is a share image in the 1200x630 size OpenGraph and Twitter cards
+// use: a title, a subtitle and a headline on the left, lines of detail under
+// them and a bar chart on the right
+type Card struct {
+	Title    string
+	Subtitle string
+	Headline string
+	Lines    []string
+	Footer   string
+	Chart    *BarChart
+}
+
+const (
+	CardWidth  = 1200
+	CardHeight = 630
+)
+
+// SVG renders the card as a standalone SVG document
+func (c Card) SVG() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg width="%d" height="%d" viewBox="0 0 %d %d" xmlns="http://www.w3.org/2000/svg">`, CardWidth, CardHeight, CardWidth, CardHeight)
+	fmt.Fprintf(&sb, `<rect width="100%%" height="100%%" fill="%s" />`, background)
+
+	text(&sb, 60, 100, 52, "start", foreground, c.Title)
+	text(&sb, 60, 150, 28, "start", muted, c.Subtitle)
+	text(&sb, 60, 260, 72, "start", foreground, c.Headline)
+	for i, line := range c.Lines {
+		text(&sb, 60, 340+i*44, 28, "start", foreground, line)
+	}
+	text(&sb, 60, CardHeight-40, 22, "start", muted, c.Footer)
+
+	if c.Chart != nil {
+		bars := *c.Chart
+		bars.Width, bars.Height = 520, 470
+		sb.WriteString(bars.Embed(CardWidth-bars.Width-40, 80))
+	}
+
+	sb.WriteString(`</svg>`)
+	return sb.String()
+}
+
+func text(sb *strings.Builder, x, y, size int, anchor, color, s string) {
+	if s == "" {
+		return
+	}
+	fmt.Fprintf(sb, `<text x="%d" y="%d" fill="%s" font-family="%s" font-size="%d" text-anchor="%s">%s</text>`,
+		x, y, color, font, size, anchor, escape(s))
+}
+
+func escape(s string) string {
+	var sb strings.Builder
+	_ = xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
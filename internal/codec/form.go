@@ -0,0 +1,537 @@
+// Package codec decodes the URL-encoded event lines legacy game scripts post
+// to the v1 ingest endpoint.
+package codec
+
+import (
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// formFields maps every form key to the RawEvent field it fills. It mirrors
+// RawEvent's JSON tags one for one (TestFormFieldsCoverRawEvent enforces it),
+// so a field added to the event must be added here too or form payloads drop it.
+var formFields = map[string]func(e *models.RawEvent) interface{}{
+	"type":         func(e *models.RawEvent) interface{} { return (*string)(&e.Type) },
+	"match_id":     func(e *models.RawEvent) interface{} { return &e.MatchID },
+	"session_id":   func(e *models.RawEvent) interface{} { return &e.SessionID },
+	"server_id":    func(e *models.RawEvent) interface{} { return &e.ServerID },
+	"server_token": func(e *models.RawEvent) interface{} { return &e.ServerToken },
+	"timestamp":    func(e *models.RawEvent) interface{} { return &e.Timestamp },
+	"map_name":     func(e *models.RawEvent) interface{} { return &e.MapName },
+
+	// Player info (primary actor for single-player events)
+	"player_name":   func(e *models.RawEvent) interface{} { return &e.PlayerName },
+	"player_guid":   func(e *models.RawEvent) interface{} { return &e.PlayerGUID },
+	"player_team":   func(e *models.RawEvent) interface{} { return &e.PlayerTeam },
+	"player_smf_id": func(e *models.RawEvent) interface{} { return &e.PlayerSMFID },
+	"pos_x":         func(e *models.RawEvent) interface{} { return &e.PosX },
+	"pos_y":         func(e *models.RawEvent) interface{} { return &e.PosY },
+	"pos_z":         func(e *models.RawEvent) interface{} { return &e.PosZ },
+	"player_stance": func(e *models.RawEvent) interface{} { return &e.PlayerStance },
+
+	// Attacker info (for kill/damage events)
+	"attacker_name":   func(e *models.RawEvent) interface{} { return &e.AttackerName },
+	"attacker_guid":   func(e *models.RawEvent) interface{} { return &e.AttackerGUID },
+	"attacker_team":   func(e *models.RawEvent) interface{} { return &e.AttackerTeam },
+	"attacker_smf_id": func(e *models.RawEvent) interface{} { return &e.AttackerSMFID },
+	"attacker_x":      func(e *models.RawEvent) interface{} { return &e.AttackerX },
+	"attacker_y":      func(e *models.RawEvent) interface{} { return &e.AttackerY },
+	"attacker_z":      func(e *models.RawEvent) interface{} { return &e.AttackerZ },
+	"attacker_pitch":  func(e *models.RawEvent) interface{} { return &e.AttackerPitch },
+	"attacker_yaw":    func(e *models.RawEvent) interface{} { return &e.AttackerYaw },
+	"attacker_stance": func(e *models.RawEvent) interface{} { return &e.AttackerStance },
+
+	// Victim info
+	"victim_name":   func(e *models.RawEvent) interface{} { return &e.VictimName },
+	"victim_guid":   func(e *models.RawEvent) interface{} { return &e.VictimGUID },
+	"victim_team":   func(e *models.RawEvent) interface{} { return &e.VictimTeam },
+	"victim_smf_id": func(e *models.RawEvent) interface{} { return &e.VictimSMFID },
+	"victim_x":      func(e *models.RawEvent) interface{} { return &e.VictimX },
+	"victim_y":      func(e *models.RawEvent) interface{} { return &e.VictimY },
+	"victim_z":      func(e *models.RawEvent) interface{} { return &e.VictimZ },
+	"victim_pitch":  func(e *models.RawEvent) interface{} { return &e.VictimPitch },
+	"victim_yaw":    func(e *models.RawEvent) interface{} { return &e.VictimYaw },
+	"victim_stance": func(e *models.RawEvent) interface{} { return &e.VictimStance },
+
+	// Weapon/damage info
+	"weapon":         func(e *models.RawEvent) interface{} { return &e.Weapon },
+	"old_weapon":     func(e *models.RawEvent) interface{} { return &e.OldWeapon },
+	"new_weapon":     func(e *models.RawEvent) interface{} { return &e.NewWeapon },
+	"hitloc":         func(e *models.RawEvent) interface{} { return &e.Hitloc },
+	"mod":            func(e *models.RawEvent) interface{} { return &e.Mod },
+	"means_of_death": func(e *models.RawEvent) interface{} { return &e.MeansOfDeath },
+	"inflictor":      func(e *models.RawEvent) interface{} { return &e.Inflictor },
+	"damage":         func(e *models.RawEvent) interface{} { return &e.Damage },
+	"ammo_remaining": func(e *models.RawEvent) interface{} { return &e.AmmoRemaining },
+	"ammo_type":      func(e *models.RawEvent) interface{} { return &e.AmmoType },
+	"amount":         func(e *models.RawEvent) interface{} { return &e.Amount },
+
+	// Movement
+	"fall_height": func(e *models.RawEvent) interface{} { return &e.FallHeight },
+	"walked":      func(e *models.RawEvent) interface{} { return &e.Walked },
+	"sprinted":    func(e *models.RawEvent) interface{} { return &e.Sprinted },
+	"swam":        func(e *models.RawEvent) interface{} { return &e.Swam },
+	"driven":      func(e *models.RawEvent) interface{} { return &e.Driven },
+	"distance":    func(e *models.RawEvent) interface{} { return &e.Distance },
+
+	// Aim angles
+	"aim_pitch": func(e *models.RawEvent) interface{} { return &e.AimPitch },
+	"aim_yaw":   func(e *models.RawEvent) interface{} { return &e.AimYaw },
+
+	// Items & Pickups
+	"item":            func(e *models.RawEvent) interface{} { return &e.Item },
+	"count":           func(e *models.RawEvent) interface{} { return &e.Count },
+	"health_restored": func(e *models.RawEvent) interface{} { return &e.HealthRestored },
+	"armor_amount":    func(e *models.RawEvent) interface{} { return &e.ArmorAmount },
+	"location":        func(e *models.RawEvent) interface{} { return &e.Location },
+
+	// Target info (for hits)
+	"target_name":     func(e *models.RawEvent) interface{} { return &e.TargetName },
+	"target_guid":     func(e *models.RawEvent) interface{} { return &e.TargetGUID },
+	"target_smf_id":   func(e *models.RawEvent) interface{} { return &e.TargetSMFID },
+	"target_stance":   func(e *models.RawEvent) interface{} { return &e.TargetStance },
+	"target_location": func(e *models.RawEvent) interface{} { return &e.TargetLocation },
+
+	// Team change
+	"old_team": func(e *models.RawEvent) interface{} { return &e.OldTeam },
+	"new_team": func(e *models.RawEvent) interface{} { return &e.NewTeam },
+	"team":     func(e *models.RawEvent) interface{} { return &e.Team },
+
+	// Chat
+	"message":   func(e *models.RawEvent) interface{} { return &e.Message },
+	"team_only": func(e *models.RawEvent) interface{} { return &e.TeamOnly },
+
+	// Match lifecycle
+	"gametype":     func(e *models.RawEvent) interface{} { return &e.Gametype },
+	"game_type":    func(e *models.RawEvent) interface{} { return &e.GameType },
+	"timelimit":    func(e *models.RawEvent) interface{} { return &e.Timelimit },
+	"fraglimit":    func(e *models.RawEvent) interface{} { return &e.Fraglimit },
+	"maxclients":   func(e *models.RawEvent) interface{} { return &e.Maxclients },
+	"max_players":  func(e *models.RawEvent) interface{} { return &e.MaxPlayers },
+	"duration":     func(e *models.RawEvent) interface{} { return &e.Duration },
+	"winning_team": func(e *models.RawEvent) interface{} { return &e.WinningTeam },
+	"winner":       func(e *models.RawEvent) interface{} { return &e.Winner },
+	"allies_score": func(e *models.RawEvent) interface{} { return &e.AlliesScore },
+	"allied_score": func(e *models.RawEvent) interface{} { return &e.AlliedScore },
+	"axis_score":   func(e *models.RawEvent) interface{} { return &e.AxisScore },
+	"round_number": func(e *models.RawEvent) interface{} { return &e.RoundNumber },
+	"total_rounds": func(e *models.RawEvent) interface{} { return &e.TotalRounds },
+	"player_count": func(e *models.RawEvent) interface{} { return &e.PlayerCount },
+	"players":      func(e *models.RawEvent) interface{} { return &e.Players },
+	"client_num":   func(e *models.RawEvent) interface{} { return &e.ClientNum },
+
+	// Identity claim & Auth
+	"code":       func(e *models.RawEvent) interface{} { return &e.Code },
+	"claimed_id": func(e *models.RawEvent) interface{} { return &e.ClaimedID },
+	"smf_id":     func(e *models.RawEvent) interface{} { return &e.SMFID },
+	"auth_token": func(e *models.RawEvent) interface{} { return &e.AuthToken },
+
+	// Entity
+	"entity":     func(e *models.RawEvent) interface{} { return &e.Entity },
+	"projectile": func(e *models.RawEvent) interface{} { return &e.Projectile },
+
+	// Actor/Bot Events
+	"actor_id":   func(e *models.RawEvent) interface{} { return &e.ActorID },
+	"actor_type": func(e *models.RawEvent) interface{} { return &e.ActorType },
+
+	// Kill Events
+	"killer_guid": func(e *models.RawEvent) interface{} { return &e.KillerGUID },
+
+	// Objectives
+	"objective":        func(e *models.RawEvent) interface{} { return &e.Objective },
+	"objective_id":     func(e *models.RawEvent) interface{} { return &e.ObjectiveID },
+	"objective_status": func(e *models.RawEvent) interface{} { return &e.ObjectiveStatus },
+	"status":           func(e *models.RawEvent) interface{} { return &e.Status },
+	"progress":         func(e *models.RawEvent) interface{} { return &e.Progress },
+	"capturing_team":   func(e *models.RawEvent) interface{} { return &e.CapturingTeam },
+
+	// Vehicle/Turret
+	"bot_id": func(e *models.RawEvent) interface{} { return &e.BotID },
+	"seat":   func(e *models.RawEvent) interface{} { return &e.Seat },
+
+	// Door Events
+	"door":        func(e *models.RawEvent) interface{} { return &e.Door },
+	"opener_guid": func(e *models.RawEvent) interface{} { return &e.OpenerGUID },
+
+	// Explosion
+	"radius": func(e *models.RawEvent) interface{} { return &e.Radius },
+
+	// Map Events
+	"from_map":  func(e *models.RawEvent) interface{} { return &e.FromMap },
+	"to_map":    func(e *models.RawEvent) interface{} { return &e.ToMap },
+	"load_time": func(e *models.RawEvent) interface{} { return &e.LoadTime },
+
+	// Connection Events
+	"ip":        func(e *models.RawEvent) interface{} { return &e.IP },
+	"name":      func(e *models.RawEvent) interface{} { return &e.Name },
+	"reason":    func(e *models.RawEvent) interface{} { return &e.Reason },
+	"idle_time": func(e *models.RawEvent) interface{} { return &e.IdleTime },
+
+	"client_ip_hash": func(e *models.RawEvent) interface{} { return &e.ClientIPHash },
+
+	// Server Info
+	"version":  func(e *models.RawEvent) interface{} { return &e.Version },
+	"protocol": func(e *models.RawEvent) interface{} { return &e.Protocol },
+
+	// Server Metrics
+	"cpu_usage":       func(e *models.RawEvent) interface{} { return &e.CPUUsage },
+	"sv_fps":          func(e *models.RawEvent) interface{} { return &e.SvFPS },
+	"frame_time":      func(e *models.RawEvent) interface{} { return &e.FrameTime },
+	"pings":           func(e *models.RawEvent) interface{} { return &e.Pings },
+	"roster":          func(e *models.RawEvent) interface{} { return &e.Roster },
+	"spectator_count": func(e *models.RawEvent) interface{} { return &e.SpectatorCount },
+
+	// Server Commands
+	"command":  func(e *models.RawEvent) interface{} { return &e.Command },
+	"executor": func(e *models.RawEvent) interface{} { return &e.Executor },
+
+	// Score Events
+	"score_delta": func(e *models.RawEvent) interface{} { return &e.ScoreDelta },
+	"new_score":   func(e *models.RawEvent) interface{} { return &e.NewScore },
+	"score":       func(e *models.RawEvent) interface{} { return &e.Score },
+
+	// Team Events
+	"from_team":      func(e *models.RawEvent) interface{} { return &e.FromTeam },
+	"to_team":        func(e *models.RawEvent) interface{} { return &e.ToTeam },
+	"teamkill_count": func(e *models.RawEvent) interface{} { return &e.TeamkillCount },
+
+	// Vehicle Events
+	"vehicle":        func(e *models.RawEvent) interface{} { return &e.Vehicle },
+	"from_vehicle":   func(e *models.RawEvent) interface{} { return &e.FromVehicle },
+	"to_vehicle":     func(e *models.RawEvent) interface{} { return &e.ToVehicle },
+	"position":       func(e *models.RawEvent) interface{} { return &e.Position },
+	"driver_guid":    func(e *models.RawEvent) interface{} { return &e.DriverGUID },
+	"destroyer_guid": func(e *models.RawEvent) interface{} { return &e.DestroyerGUID },
+	"speed":          func(e *models.RawEvent) interface{} { return &e.Speed },
+
+	// Turret Events
+	"turret": func(e *models.RawEvent) interface{} { return &e.Turret },
+
+	// Vote Events
+	"vote_type":   func(e *models.RawEvent) interface{} { return &e.VoteType },
+	"vote_target": func(e *models.RawEvent) interface{} { return &e.VoteTarget },
+	"yes_votes":   func(e *models.RawEvent) interface{} { return &e.YesVotes },
+	"no_votes":    func(e *models.RawEvent) interface{} { return &e.NoVotes },
+
+	// Weapon Events
+	"ammo_count": func(e *models.RawEvent) interface{} { return &e.AmmoCount },
+	"method":     func(e *models.RawEvent) interface{} { return &e.Method },
+
+	// Object Interaction
+	"object": func(e *models.RawEvent) interface{} { return &e.Object },
+
+	// Accuracy Stats
+	"shots_fired": func(e *models.RawEvent) interface{} { return &e.ShotsFired },
+	"shots_hit":   func(e *models.RawEvent) interface{} { return &e.ShotsHit },
+	"accuracy":    func(e *models.RawEvent) interface{} { return &e.Accuracy },
+
+	// Match Outcome (1 = Win, 0 = Loss)
+	"match_outcome": func(e *models.RawEvent) interface{} { return &e.MatchOutcome },
+}
+
+// formAliases maps keys seen in game script output to their canonical key.
+// The canonical key wins when both are present.
+var formAliases = map[string]string{
+	"objective_index": "objective",
+}
+
+// Report lists the parts of a form line that did not make it into the event
+type Report struct {
+	Unknown   []string `json:"unknown,omitempty"`   // keys with no RawEvent field
+	Invalid   []string `json:"invalid,omitempty"`   // values that did not parse as the field's type
+	Conflicts []string `json:"conflicts,omitempty"` // keys repeated with different values; the first won
+}
+
+// Empty reports whether every key was decoded cleanly
+func (r Report) Empty() bool {
+	return len(r.Unknown) == 0 && len(r.Invalid) == 0 && len(r.Conflicts) == 0
+}
+
+// ParseLine decodes one URL-encoded event line. Unlike url.ParseQuery it never
+// discards the whole line: malformed escapes (e.g. "100%" in chat) are kept
+// literally and semicolons are treated as part of the value.
+func ParseLine(line string) (models.RawEvent, Report) {
+	return Decode(parseQuery(line))
+}
+
+// Decode converts form values to a RawEvent. Keys are matched
+// case-insensitively and may carry an array suffix ("weapon[]", "weapon[2]");
+// repeated keys keep the first non-empty value.
+func Decode(form url.Values) (models.RawEvent, Report) {
+	var event models.RawEvent
+	var report Report
+
+	values := normalize(form, &report)
+
+	// Canonical keys before aliases so they take precedence
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		_, ai := formAliases[keys[i]]
+		_, aj := formAliases[keys[j]]
+		if ai != aj {
+			return !ai
+		}
+		return keys[i] < keys[j]
+	})
+
+	set := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		target := key
+		if canonical, ok := formAliases[key]; ok {
+			target = canonical
+		}
+		field, ok := formFields[target]
+		if !ok {
+			report.Unknown = append(report.Unknown, key)
+			continue
+		}
+		if set[target] {
+			continue
+		}
+		if roster, ok := field(&event).(*[]models.RosterEntry); ok {
+			// One player per value: roster[]=...&roster[]=...
+			invalid := false
+			for _, v := range values[key] {
+				if v = strings.TrimSpace(v); v == "" {
+					continue
+				}
+				entry, ok := parseRosterEntry(v)
+				if !ok {
+					invalid = true
+					continue
+				}
+				*roster = append(*roster, entry)
+			}
+			if invalid {
+				report.Invalid = append(report.Invalid, key)
+			}
+			set[target] = len(*roster) > 0
+			continue
+		}
+
+		value, conflict := firstNonEmpty(values[key])
+		if conflict {
+			report.Conflicts = append(report.Conflicts, key)
+		}
+		if value == "" {
+			continue
+		}
+		if !setField(field(&event), value) {
+			report.Invalid = append(report.Invalid, key)
+			continue
+		}
+		set[target] = true
+	}
+
+	return event, report
+}
+
+// normalize lowercases keys and folds array suffixes into repeated values,
+// ordering indexed entries ("k[1]", "k[0]") by index
+func normalize(form url.Values, report *Report) map[string][]string {
+	type indexed struct {
+		index int
+		value string
+	}
+	grouped := make(map[string][]indexed, len(form))
+
+	for rawKey, vals := range form {
+		key := strings.ToLower(strings.TrimSpace(rawKey))
+		index := math.MaxInt
+		if open := strings.IndexByte(key, '['); open > 0 && strings.HasSuffix(key, "]") {
+			if n, err := strconv.Atoi(key[open+1 : len(key)-1]); err == nil && n >= 0 {
+				index = n
+			}
+			key = key[:open]
+		}
+		if key == "" {
+			report.Unknown = append(report.Unknown, rawKey)
+			continue
+		}
+		for _, v := range vals {
+			grouped[key] = append(grouped[key], indexed{index: index, value: v})
+		}
+	}
+
+	values := make(map[string][]string, len(grouped))
+	for key, entries := range grouped {
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].index < entries[j].index })
+		for _, e := range entries {
+			values[key] = append(values[key], e.value)
+		}
+	}
+	return values
+}
+
+// firstNonEmpty returns the first non-blank value, and whether a later value
+// disagreed with it
+func firstNonEmpty(vals []string) (string, bool) {
+	first := ""
+	for _, v := range vals {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if first == "" {
+			first = v
+			continue
+		}
+		if v != first {
+			return first, true
+		}
+	}
+	return first, false
+}
+
+// setField parses s into the field ptr points at. Numbers are parsed leniently
+// the way the JSON decoder coerces strings: "28.5" into an int truncates.
+func setField(ptr interface{}, s string) bool {
+	switch p := ptr.(type) {
+	case *string:
+		*p = s
+	case *float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return false
+		}
+		*p = f
+	case *float32:
+		f, err := strconv.ParseFloat(s, 32)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return false
+		}
+		*p = float32(f)
+	case *int:
+		n, ok := parseInteger(s, math.MinInt, math.MaxInt)
+		if !ok {
+			return false
+		}
+		*p = int(n)
+	case **int:
+		n, ok := parseInteger(s, math.MinInt, math.MaxInt)
+		if !ok {
+			return false
+		}
+		v := int(n)
+		*p = &v
+	case *int64:
+		n, ok := parseInteger(s, math.MinInt64, math.MaxInt64)
+		if !ok {
+			return false
+		}
+		*p = n
+	case *uint8:
+		n, ok := parseInteger(s, 0, math.MaxUint8)
+		if !ok {
+			return false
+		}
+		*p = uint8(n)
+	case *bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return false
+		}
+		*p = b
+	case *map[string]int:
+		m, ok := parsePings(s)
+		if !ok {
+			return false
+		}
+		*p = m
+	default:
+		return false
+	}
+	return true
+}
+
+// parsePings parses "guid:ms" pairs separated by commas, the form encoding of
+// the heartbeat pings object. GUIDs may themselves contain colons.
+func parsePings(s string) (map[string]int, bool) {
+	pings := make(map[string]int)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		sep := strings.LastIndexByte(pair, ':')
+		if sep <= 0 {
+			return nil, false
+		}
+		ms, ok := parseInteger(strings.TrimSpace(pair[sep+1:]), 0, math.MaxInt32)
+		if !ok {
+			return nil, false
+		}
+		pings[strings.TrimSpace(pair[:sep])] = int(ms)
+	}
+	return pings, len(pings) > 0
+}
+
+// parseRosterEntry parses "guid|name|team|score|ping", the form encoding of
+// one heartbeat roster entry. The name may itself contain '|'.
+func parseRosterEntry(s string) (models.RosterEntry, bool) {
+	parts := strings.Split(s, "|")
+	if len(parts) < 5 || strings.TrimSpace(parts[0]) == "" {
+		return models.RosterEntry{}, false
+	}
+	n := len(parts)
+	score, ok := parseInteger(strings.TrimSpace(parts[n-2]), math.MinInt32, math.MaxInt32)
+	if !ok {
+		return models.RosterEntry{}, false
+	}
+	ping, ok := parseInteger(strings.TrimSpace(parts[n-1]), 0, math.MaxInt32)
+	if !ok {
+		return models.RosterEntry{}, false
+	}
+	return models.RosterEntry{
+		GUID:  strings.TrimSpace(parts[0]),
+		Name:  strings.Join(parts[1:n-3], "|"),
+		Team:  strings.TrimSpace(parts[n-3]),
+		Score: int(score),
+		Ping:  int(ping),
+	}, true
+}
+
+// parseInteger accepts plain integers and decimal forms like "12.0"
+func parseInteger(s string, lo, hi int64) (int64, bool) {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n, n >= lo && n <= hi
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(f) || f < float64(lo) || f >= float64(hi)+1 {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+// parseQuery splits a query string like url.ParseQuery but keeps every pair:
+// a value with a bad escape is used as-is rather than failing the line
+func parseQuery(line string) url.Values {
+	values := make(url.Values)
+	for _, pair := range strings.Split(line, "&") {
+		if pair == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(pair, "=")
+		key = unescape(key)
+		if key == "" {
+			continue
+		}
+		values[key] = append(values[key], unescape(value))
+	}
+	return values
+}
+
+func unescape(s string) string {
+	if u, err := url.QueryUnescape(s); err == nil {
+		return u
+	}
+	return strings.ReplaceAll(s, "+", " ")
+}
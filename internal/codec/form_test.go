@@ -0,0 +1,220 @@
+package codec
+
+import (
+	"math"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestFormFieldsCoverRawEvent(t *testing.T) {
+	typ := reflect.TypeOf(models.RawEvent{})
+	tags := make(map[string]bool)
+	for i := 0; i < typ.NumField(); i++ {
+		tag := strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+		if tag == "" || tag == "-" {
+			continue
+		}
+		tags[tag] = true
+		if _, ok := formFields[tag]; !ok {
+			t.Errorf("RawEvent.%s (%q) has no form mapping", typ.Field(i).Name, tag)
+		}
+	}
+	for key := range formFields {
+		if !tags[key] {
+			t.Errorf("form key %q does not match a RawEvent JSON tag", key)
+		}
+	}
+	for alias, canonical := range formAliases {
+		if _, ok := formFields[canonical]; !ok {
+			t.Errorf("alias %q targets unknown key %q", alias, canonical)
+		}
+	}
+}
+
+func TestFormFieldsTargetTaggedField(t *testing.T) {
+	// Each setter must write the field carrying its own JSON tag
+	typ := reflect.TypeOf(models.RawEvent{})
+	for i := 0; i < typ.NumField(); i++ {
+		tag := strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+		field, ok := formFields[tag]
+		if !ok {
+			continue
+		}
+		var e models.RawEvent
+		got := reflect.ValueOf(field(&e)).Pointer()
+		want := reflect.ValueOf(&e).Elem().Field(i).Addr().Pointer()
+		if got != want {
+			t.Errorf("form key %q writes the wrong field", tag)
+		}
+	}
+}
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		check  func(models.RawEvent) bool
+		report Report
+	}{
+		{
+			name: "Previously Dropped Fields",
+			line: "type=player_kill&mod=MOD_RIFLE&victim_pitch=12.5&shots_fired=30&team_only=1&match_outcome=1",
+			check: func(e models.RawEvent) bool {
+				return e.Mod == "MOD_RIFLE" && e.VictimPitch == 12.5 && e.ShotsFired == 30 && e.TeamOnly && e.MatchOutcome == 1
+			},
+		},
+		{
+			name:  "Decimal Integers",
+			line:  "type=score_change&allies_score=12.0&player_smf_id=9007199254740993",
+			check: func(e models.RawEvent) bool { return e.AlliesScore == 12 && e.PlayerSMFID == 9007199254740993 },
+		},
+		{
+			name:  "Bad Escape Keeps Line",
+			line:  "type=chat&message=100%+sure;+gg",
+			check: func(e models.RawEvent) bool { return e.Type == "chat" && e.Message == "100% sure; gg" },
+		},
+		{
+			name:   "Repeated Keys",
+			line:   "type=item_pickup&item=&item=Thompson&item=Colt",
+			check:  func(e models.RawEvent) bool { return e.Item == "Thompson" },
+			report: Report{Conflicts: []string{"item"}},
+		},
+		{
+			name:   "Indexed Array Keys",
+			line:   "type=weapon_change&weapon[1]=Colt&weapon[0]=Thompson&Player_Name[]=Bob",
+			check:  func(e models.RawEvent) bool { return e.Weapon == "Thompson" && e.PlayerName == "Bob" },
+			report: Report{Conflicts: []string{"weapon"}},
+		},
+		{
+			name:  "Alias Loses To Canonical",
+			line:  "type=objective_update&objective_index=2&objective=bridge",
+			check: func(e models.RawEvent) bool { return e.Objective == "bridge" },
+		},
+		{
+			name:  "Alias Alone",
+			line:  "type=objective_update&objective_index=2",
+			check: func(e models.RawEvent) bool { return e.Objective == "2" },
+		},
+		{
+			name:   "Unknown And Invalid",
+			line:   "type=damage&damage=lots&hp=100",
+			check:  func(e models.RawEvent) bool { return e.Type == "damage" && e.Damage == 0 },
+			report: Report{Unknown: []string{"hp"}, Invalid: []string{"damage"}},
+		},
+		{
+			name: "Heartbeat Pings",
+			line: "type=heartbeat&pings=guid-a:45,+bot:1:120,",
+			check: func(e models.RawEvent) bool {
+				return len(e.Pings) == 2 && e.Pings["guid-a"] == 45 && e.Pings["bot:1"] == 120
+			},
+		},
+		{
+			name:  "Heartbeat Without Spectators",
+			line:  "type=heartbeat&spectator_count=0",
+			check: func(e models.RawEvent) bool { return e.SpectatorCount != nil && *e.SpectatorCount == 0 },
+		},
+		{
+			name:   "Bad Pings",
+			line:   "type=heartbeat&pings=guid-a:fast",
+			check:  func(e models.RawEvent) bool { return e.Pings == nil },
+			report: Report{Invalid: []string{"pings"}},
+		},
+		{
+			name: "Heartbeat Roster",
+			line: "type=heartbeat&roster[1]=guid-b|B|ob|axis|-2|80&roster[0]=guid-a|Alice|allies|12|45",
+			check: func(e models.RawEvent) bool {
+				return len(e.Roster) == 2 &&
+					e.Roster[0] == models.RosterEntry{GUID: "guid-a", Name: "Alice", Team: "allies", Score: 12, Ping: 45} &&
+					e.Roster[1] == models.RosterEntry{GUID: "guid-b", Name: "B|ob", Team: "axis", Score: -2, Ping: 80}
+			},
+		},
+		{
+			name:   "Bad Roster Entry",
+			line:   "type=heartbeat&roster[]=guid-a|Alice|allies|12|45&roster[]=guid-b|Bob|axis|lots|80&roster[]=short",
+			check:  func(e models.RawEvent) bool { return len(e.Roster) == 1 && e.Roster[0].GUID == "guid-a" },
+			report: Report{Invalid: []string{"roster"}},
+		},
+		{
+			name:   "Out Of Range",
+			line:   "type=match_outcome&match_outcome=300",
+			check:  func(e models.RawEvent) bool { return e.MatchOutcome == 0 },
+			report: Report{Invalid: []string{"match_outcome"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, report := ParseLine(tt.line)
+			if !tt.check(event) {
+				t.Errorf("unexpected event %+v", event)
+			}
+			if !reflect.DeepEqual(report, tt.report) {
+				t.Errorf("Report = %+v, want %+v", report, tt.report)
+			}
+		})
+	}
+}
+
+func FuzzParseLine(f *testing.F) {
+	f.Add("type=player_kill&attacker_name=Bob&damage=100&pos_x=1.5")
+	f.Add("type=chat&message=100%&message=again")
+	f.Add("weapon[0]=a&weapon[x]=b&[]=c&=d&&")
+	f.Add("player_smf_id=1e300&match_outcome=-1&team_only=maybe")
+
+	f.Fuzz(func(t *testing.T, line string) {
+		_, report := ParseLine(line)
+		for _, key := range report.Invalid {
+			if _, ok := formFields[key]; !ok {
+				if _, ok := formAliases[key]; !ok {
+					t.Errorf("invalid key %q is not a known field", key)
+				}
+			}
+		}
+	})
+}
+
+func FuzzRoundTrip(f *testing.F) {
+	f.Add("Bob", "gg & wp = 100%", 42, 12.5, int64(123))
+	f.Add("", "", 0, 0.0, int64(0))
+	f.Add("ünï[0]", "a+b;c", -7, -0.25, int64(-9007199254740993))
+
+	f.Fuzz(func(t *testing.T, name, message string, score int, damage float64, smfID int64) {
+		name, message = strings.TrimSpace(name), strings.TrimSpace(message)
+		form := url.Values{}
+		form.Set("type", "player_kill")
+		form.Set("player_name", name)
+		form.Set("message", message)
+		form.Set("score", strconv.Itoa(score))
+		form.Set("damage", strconv.FormatFloat(damage, 'g', -1, 64))
+		form.Set("player_smf_id", strconv.FormatInt(smfID, 10))
+
+		event, report := ParseLine(form.Encode())
+		if event.PlayerName != name || event.Message != message || event.Score != score || event.PlayerSMFID != smfID {
+			t.Fatalf("round trip mismatch: %+v", event)
+		}
+		// NaN and Inf are rejected as invalid rather than stored
+		if !math.IsNaN(damage) && !math.IsInf(damage, 0) && event.Damage != damage {
+			t.Fatalf("Damage = %v, want %v", event.Damage, damage)
+		}
+		if len(report.Unknown) != 0 || len(report.Conflicts) != 0 {
+			t.Fatalf("unexpected report %+v", report)
+		}
+	})
+}
+
+func BenchmarkParseLine(b *testing.B) {
+	line := "type=player_kill&match_id=4f1c2a9e-7a1b-4c7e-9a55-0c8d7b3f2e11&map_name=obj/obj_team2" +
+		"&attacker_guid=guid-1&attacker_name=%5E1Killer&attacker_x=100.5&attacker_y=200&victim_guid=guid-2" +
+		"&victim_name=Victim&weapon=Kar98K&hitloc=head&timestamp=1700000000"
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(line)))
+	for i := 0; i < b.N; i++ {
+		ParseLine(line)
+	}
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// ReadEnvFile parses the KEY=VALUE format systemd's EnvironmentFile accepts,
+// skipping blank lines and comments and stripping matching quotes
+func ReadEnvFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	env := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		env[strings.TrimSpace(key)] = value
+	}
+	return env, scanner.Err()
+}
+
+// ApplyEnvFile copies every setting in an env file into the process
+// environment so the next Load sees it
+func ApplyEnvFile(path string) error {
+	env, err := ReadEnvFile(path)
+	if err != nil {
+		return err
+	}
+	for k, v := range env {
+		if err := os.Setenv(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.env")
+	content := "# comment\n\nPORT=9090\nMODE = lite\nJWT_SECRET=\"quoted\"\nCLICKHOUSE_URL=clickhouse://h:9000/db?username=a&password=b\nbroken line\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	env, err := ReadEnvFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"PORT":           "9090",
+		"MODE":           "lite",
+		"JWT_SECRET":     "quoted",
+		"CLICKHOUSE_URL": "clickhouse://h:9000/db?username=a&password=b",
+	}
+	if len(env) != len(want) {
+		t.Fatalf("got %d keys, want %d: %v", len(env), len(want), env)
+	}
+	for k, v := range want {
+		if env[k] != v {
+			t.Errorf("%s = %q, want %q", k, env[k], v)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// reloadable lists the settings components pick up on a reload. Everything
+// else sizes pools, opens listeners or selects backends at startup, so a
+// changed value is only reported as needing a restart.
+var reloadable = map[string]bool{
+	"RedisMatchKeyTTL":            true,
+	"RedisPlayerCounterTTL":       true,
+	"RedisClaimTTL":               true,
+	"RedisLiveIdleTTL":            true,
+	"SlowQueryThreshold":          true,
+	"IngestStallThreshold":        true,
+	"EventSampleRates":            true,
+	"CachePurgeURLs":              true,
+	"CachePurgeDelay":             true,
+	"ProfileCacheTTL":             true,
+	"ProfileCacheDebounce":        true,
+	"AlertWebhookURL":             true,
+	"AlertInterval":               true,
+	"AlertIngestLag":              true,
+	"AlertQueueDepth":             true,
+	"AlertServerOffline":          true,
+	"AlertClickHouseErrors":       true,
+	"TeamkillAlertThreshold":      true,
+	"TeamkillWebhookURL":          true,
+	"HighlightsWebhookURL":        true,
+	"DigestLocale":                true,
+	"ChallengesWebhookURL":        true,
+	"ContentFilterMode":           true,
+	"ContentFilterWords":          true,
+	"ContentFilterProtectedNames": true,
+	"LogLevel":                    true,
+	"LogComponentLevels":          true,
+	"LogSampled":                  true,
+	"PredictionModel":             true,
+	"PredictionShadowModels":      true,
+	"RateLimitPerSecond":          true,
+	"RateLimitBurst":              true,
+	"PositionRetentionDays":       true,
+	"ConnectionRetentionDays":     true,
+}
+
+// ReloadResult describes what a reload changed
+type ReloadResult struct {
+	Source          string            `json:"source"`
+	Changed         []string          `json:"changed"`
+	RestartRequired []string          `json:"restart_required"`
+	Applied         []string          `json:"applied"`
+	Errors          map[string]string `json:"errors,omitempty"`
+}
+
+type reloadHook struct {
+	name  string
+	apply func(*Config) error
+}
+
+// Reloader re-reads the configuration on SIGHUP or from the admin API and
+// hands the new values to the components registered with OnReload, without
+// restarting or draining the worker pool
+type Reloader struct {
+	mu      sync.Mutex
+	current *Config
+	hooks   []reloadHook
+}
+
+// NewReloader starts from cfg as loaded from the environment. Pass it before
+// applying runtime overrides (e.g. lite mode URLs) so those are not reported
+// as changes.
+func NewReloader(cfg *Config) *Reloader {
+	c := *cfg
+	return &Reloader{current: &c}
+}
+
+// OnReload registers a component to receive the new config after each reload.
+// Hooks run in registration order; a failing hook does not stop the others.
+func (r *Reloader) OnReload(name string, apply func(*Config) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks = append(r.hooks, reloadHook{name: name, apply: apply})
+}
+
+// Current returns the most recently loaded config
+func (r *Reloader) Current() *Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := *r.current
+	return &c
+}
+
+// Reload re-reads CONFIG_FILE (when set) over the process environment, then
+// runs every hook. Values removed from the file keep their previous setting.
+func (r *Reloader) Reload() (*ReloadResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := &ReloadResult{Source: "environment"}
+	if r.current.ConfigFile != "" {
+		if err := ApplyEnvFile(r.current.ConfigFile); err != nil {
+			return nil, fmt.Errorf("read %s: %w", r.current.ConfigFile, err)
+		}
+		result.Source = r.current.ConfigFile
+	}
+
+	next := Load()
+	for _, name := range changedFields(r.current, next) {
+		result.Changed = append(result.Changed, name)
+		if !reloadable[name] {
+			result.RestartRequired = append(result.RestartRequired, name)
+		}
+	}
+	r.current = next
+
+	for _, hook := range r.hooks {
+		if err := hook.apply(next); err != nil {
+			if result.Errors == nil {
+				result.Errors = make(map[string]string)
+			}
+			result.Errors[hook.name] = err.Error()
+			continue
+		}
+		result.Applied = append(result.Applied, hook.name)
+	}
+	return result, nil
+}
+
+func changedFields(a, b *Config) []string {
+	va, vb := reflect.ValueOf(a).Elem(), reflect.ValueOf(b).Elem()
+	var changed []string
+	for i := 0; i < va.NumField(); i++ {
+		if !reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			changed = append(changed, va.Type().Field(i).Name)
+		}
+	}
+	return changed
+}
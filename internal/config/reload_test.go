@@ -0,0 +1,62 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReloaderAppliesFileAndReportsRestartRequired(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.env")
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("SLOW_QUERY_THRESHOLD", "500ms")
+	t.Setenv("WORKER_COUNT", "8")
+
+	r := NewReloader(Load())
+
+	var got time.Duration
+	r.OnReload("slow", func(c *Config) error {
+		got = c.SlowQueryThreshold
+		return nil
+	})
+	r.OnReload("broken", func(*Config) error {
+		return errors.New("boom")
+	})
+
+	if err := os.WriteFile(path, []byte("SLOW_QUERY_THRESHOLD=2s\nWORKER_COUNT=16\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	result, err := r.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got != 2*time.Second {
+		t.Errorf("hook saw threshold %v, want 2s", got)
+	}
+	if r.Current().WorkerCount != 16 {
+		t.Errorf("Current().WorkerCount = %d, want 16", r.Current().WorkerCount)
+	}
+	if len(result.Changed) != 2 {
+		t.Errorf("Changed = %v, want SlowQueryThreshold and WorkerCount", result.Changed)
+	}
+	if len(result.RestartRequired) != 1 || result.RestartRequired[0] != "WorkerCount" {
+		t.Errorf("RestartRequired = %v, want [WorkerCount]", result.RestartRequired)
+	}
+	if len(result.Applied) != 1 || result.Applied[0] != "slow" {
+		t.Errorf("Applied = %v, want [slow]", result.Applied)
+	}
+	if result.Errors["broken"] != "boom" {
+		t.Errorf("Errors = %v, want broken: boom", result.Errors)
+	}
+}
+
+func TestReloaderMissingFile(t *testing.T) {
+	t.Setenv("CONFIG_FILE", filepath.Join(t.TempDir(), "missing.env"))
+	r := NewReloader(Load())
+	if _, err := r.Reload(); err == nil {
+		t.Fatal("expected error for missing config file")
+	}
+}
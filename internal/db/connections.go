@@ -2,11 +2,14 @@ package db
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
 	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -37,8 +40,19 @@ func NewPostgresPool(ctx context.Context, connString string) (*pgxpool.Pool, err
 	return pool, nil
 }
 
+// ClickHouseOptions tunes the ClickHouse connection pool. Zero values fall
+// back to the defaults below.
+type ClickHouseOptions struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	DialTimeout     time.Duration
+	ReadTimeout     time.Duration
+	Compression     string // lz4, zstd or none
+}
+
 // NewClickHouseConn creates a connection to ClickHouse
-func NewClickHouseConn(ctx context.Context, connString string) (driver.Conn, error) {
+func NewClickHouseConn(ctx context.Context, connString string, pool ClickHouseOptions) (driver.Conn, error) {
 	opts, err := clickhouse.ParseDSN(connString)
 	if err != nil {
 		return nil, err
@@ -53,6 +67,31 @@ func NewClickHouseConn(ctx context.Context, connString string) (driver.Conn, err
 		Method: clickhouse.CompressionLZ4,
 	}
 
+	if pool.MaxOpenConns > 0 {
+		opts.MaxOpenConns = pool.MaxOpenConns
+	}
+	if pool.MaxIdleConns > 0 {
+		opts.MaxIdleConns = pool.MaxIdleConns
+	}
+	if pool.ConnMaxLifetime > 0 {
+		opts.ConnMaxLifetime = pool.ConnMaxLifetime
+	}
+	if pool.DialTimeout > 0 {
+		opts.DialTimeout = pool.DialTimeout
+	}
+	if pool.ReadTimeout > 0 {
+		opts.ReadTimeout = pool.ReadTimeout
+	}
+	switch pool.Compression {
+	case "", "lz4":
+	case "zstd":
+		opts.Compression.Method = clickhouse.CompressionZSTD
+	case "none":
+		opts.Compression = nil
+	default:
+		return nil, fmt.Errorf("unsupported ClickHouse compression %q", pool.Compression)
+	}
+
 	conn, err := clickhouse.Open(opts)
 	if err != nil {
 		return nil, err
@@ -66,6 +105,31 @@ func NewClickHouseConn(ctx context.Context, connString string) (driver.Conn, err
 	return conn, nil
 }
 
+// RegisterClickHouseMetrics exports the connection pool counters of conn as
+// Prometheus gauges. Call once per process.
+func RegisterClickHouseMetrics(conn driver.Conn) {
+	stat := func(f func(driver.Stats) int) func() float64 {
+		return func() float64 { return float64(f(conn.Stats())) }
+	}
+
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "mohaa_clickhouse_conns_max_open",
+		Help: "Configured maximum open ClickHouse connections",
+	}, stat(func(s driver.Stats) int { return s.MaxOpenConns }))
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "mohaa_clickhouse_conns_open",
+		Help: "Open ClickHouse connections",
+	}, stat(func(s driver.Stats) int { return s.Open }))
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "mohaa_clickhouse_conns_idle",
+		Help: "Idle ClickHouse connections",
+	}, stat(func(s driver.Stats) int { return s.Idle }))
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "mohaa_clickhouse_conns_in_use",
+		Help: "ClickHouse connections currently checked out",
+	}, stat(func(s driver.Stats) int { return s.Open - s.Idle }))
+}
+
 // NewRedisClient creates a Redis client
 func NewRedisClient(connString string) *redis.Client {
 	opt, _ := redis.ParseURL(connString)
@@ -0,0 +1,320 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrNotFound is returned by LiveStateStore reads when the key or field does not exist
+var ErrNotFound = errors.New("live state: not found")
+
+// LiveStateWriter is the write subset shared by stores and pipelines.
+// Pipelined writes always return nil; their errors surface from Exec.
+type LiveStateWriter interface {
+	HSet(ctx context.Context, key string, values ...any) error
+	HDel(ctx context.Context, key string, fields ...string) error
+	SAdd(ctx context.Context, key string, members ...any) error
+	SRem(ctx context.Context, key string, members ...any) error
+	Del(ctx context.Context, keys ...string) error
+	Expire(ctx context.Context, key string, ttl time.Duration) error
+}
+
+// LiveStateStore holds real-time state (live matches, counters, short-lived
+// tokens). Redis backs it in production; the in-memory implementation lets a
+// single node run without Redis.
+type LiveStateStore interface {
+	LiveStateWriter
+
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value any, ttl time.Duration) error
+	// SetNX sets key only if it does not exist, reporting whether it did
+	SetNX(ctx context.Context, key string, value any, ttl time.Duration) (bool, error)
+	// CompareAndDelete deletes key only if it holds value, reporting whether it did
+	CompareAndDelete(ctx context.Context, key, value string) (bool, error)
+	// CompareAndExpire sets key's TTL only if it holds value, reporting whether it did
+	CompareAndExpire(ctx context.Context, key, value string, ttl time.Duration) (bool, error)
+	Incr(ctx context.Context, key string) (int64, error)
+	IncrByFloat(ctx context.Context, key string, value float64) (float64, error)
+
+	HGet(ctx context.Context, key, field string) (string, error)
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+	HKeys(ctx context.Context, key string) ([]string, error)
+	HVals(ctx context.Context, key string) ([]string, error)
+	HLen(ctx context.Context, key string) (int64, error)
+	HExists(ctx context.Context, key, field string) (bool, error)
+
+	SMembers(ctx context.Context, key string) ([]string, error)
+	SIsMember(ctx context.Context, key string, member any) (bool, error)
+
+	Publish(ctx context.Context, channel string, message any) error
+	Pipeline() LiveStatePipeline
+	Ping(ctx context.Context) error
+	Close() error
+}
+
+// LiveStatePipeline batches commands and runs them on Exec
+type LiveStatePipeline interface {
+	LiveStateWriter
+	Incr(ctx context.Context, key string) *IntResult
+	Get(ctx context.Context, key string) *StringResult
+	HGet(ctx context.Context, key, field string) *StringResult
+	SIsMember(ctx context.Context, key string, member any) *BoolResult
+	Exec(ctx context.Context) error
+}
+
+// IntResult is a pipelined integer reply, valid after Exec
+type IntResult struct {
+	val     int64
+	err     error
+	resolve func() (int64, error)
+}
+
+func (r *IntResult) Result() (int64, error) {
+	if r.resolve != nil {
+		return r.resolve()
+	}
+	return r.val, r.err
+}
+
+// StringResult is a pipelined string reply, valid after Exec
+type StringResult struct {
+	val     string
+	err     error
+	resolve func() (string, error)
+}
+
+func (r *StringResult) Result() (string, error) {
+	if r.resolve != nil {
+		v, err := r.resolve()
+		return v, notFound(err)
+	}
+	return r.val, r.err
+}
+
+// BoolResult is a pipelined boolean reply, valid after Exec
+type BoolResult struct {
+	val     bool
+	err     error
+	resolve func() (bool, error)
+}
+
+func (r *BoolResult) Result() (bool, error) {
+	if r.resolve != nil {
+		return r.resolve()
+	}
+	return r.val, r.err
+}
+
+// Val returns the reply, or false on error
+func (r *BoolResult) Val() bool {
+	v, _ := r.Result()
+	return v
+}
+
+// =============================================================================
+// REDIS IMPLEMENTATION
+// =============================================================================
+
+type redisLiveState struct {
+	client *redis.Client
+}
+
+// NewRedisLiveState wraps a Redis client as a LiveStateStore
+func NewRedisLiveState(client *redis.Client) LiveStateStore {
+	return &redisLiveState{client: client}
+}
+
+// RedisClientOf returns the underlying client when store is Redis-backed, for
+// the few Redis-only operations (SCAN, MEMORY USAGE, INFO)
+func RedisClientOf(store LiveStateStore) (*redis.Client, bool) {
+	if r, ok := store.(*redisLiveState); ok {
+		return r.client, true
+	}
+	return nil, false
+}
+
+func notFound(err error) error {
+	if err == redis.Nil {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (s *redisLiveState) Get(ctx context.Context, key string) (string, error) {
+	v, err := s.client.Get(ctx, key).Result()
+	return v, notFound(err)
+}
+
+func (s *redisLiveState) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	return s.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (s *redisLiveState) SetNX(ctx context.Context, key string, value any, ttl time.Duration) (bool, error) {
+	return s.client.SetNX(ctx, key, value, ttl).Result()
+}
+
+// The compare-and-* scripts check and change a key in one step, so a lock
+// taken over by another owner in between is never touched
+var (
+	compareAndDeleteScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+	compareAndExpireScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+)
+
+func (s *redisLiveState) CompareAndDelete(ctx context.Context, key, value string) (bool, error) {
+	n, err := compareAndDeleteScript.Run(ctx, s.client, []string{key}, value).Int()
+	return n == 1, err
+}
+
+func (s *redisLiveState) CompareAndExpire(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	n, err := compareAndExpireScript.Run(ctx, s.client, []string{key}, value, ttl.Milliseconds()).Int()
+	return n == 1, err
+}
+
+func (s *redisLiveState) Del(ctx context.Context, keys ...string) error {
+	return s.client.Del(ctx, keys...).Err()
+}
+
+func (s *redisLiveState) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	return s.client.Expire(ctx, key, ttl).Err()
+}
+
+func (s *redisLiveState) Incr(ctx context.Context, key string) (int64, error) {
+	return s.client.Incr(ctx, key).Result()
+}
+
+func (s *redisLiveState) IncrByFloat(ctx context.Context, key string, value float64) (float64, error) {
+	return s.client.IncrByFloat(ctx, key, value).Result()
+}
+
+func (s *redisLiveState) HGet(ctx context.Context, key, field string) (string, error) {
+	v, err := s.client.HGet(ctx, key, field).Result()
+	return v, notFound(err)
+}
+
+func (s *redisLiveState) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	return s.client.HGetAll(ctx, key).Result()
+}
+
+func (s *redisLiveState) HSet(ctx context.Context, key string, values ...any) error {
+	return s.client.HSet(ctx, key, values...).Err()
+}
+
+func (s *redisLiveState) HDel(ctx context.Context, key string, fields ...string) error {
+	return s.client.HDel(ctx, key, fields...).Err()
+}
+
+func (s *redisLiveState) HKeys(ctx context.Context, key string) ([]string, error) {
+	return s.client.HKeys(ctx, key).Result()
+}
+
+func (s *redisLiveState) HVals(ctx context.Context, key string) ([]string, error) {
+	return s.client.HVals(ctx, key).Result()
+}
+
+func (s *redisLiveState) HLen(ctx context.Context, key string) (int64, error) {
+	return s.client.HLen(ctx, key).Result()
+}
+
+func (s *redisLiveState) HExists(ctx context.Context, key, field string) (bool, error) {
+	return s.client.HExists(ctx, key, field).Result()
+}
+
+func (s *redisLiveState) SAdd(ctx context.Context, key string, members ...any) error {
+	return s.client.SAdd(ctx, key, members...).Err()
+}
+
+func (s *redisLiveState) SRem(ctx context.Context, key string, members ...any) error {
+	return s.client.SRem(ctx, key, members...).Err()
+}
+
+func (s *redisLiveState) SMembers(ctx context.Context, key string) ([]string, error) {
+	return s.client.SMembers(ctx, key).Result()
+}
+
+func (s *redisLiveState) SIsMember(ctx context.Context, key string, member any) (bool, error) {
+	return s.client.SIsMember(ctx, key, member).Result()
+}
+
+func (s *redisLiveState) Publish(ctx context.Context, channel string, message any) error {
+	return s.client.Publish(ctx, channel, message).Err()
+}
+
+func (s *redisLiveState) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx).Err()
+}
+
+func (s *redisLiveState) Close() error {
+	return s.client.Close()
+}
+
+func (s *redisLiveState) Pipeline() LiveStatePipeline {
+	return &redisPipeline{pipe: s.client.Pipeline()}
+}
+
+type redisPipeline struct {
+	pipe redis.Pipeliner
+}
+
+func (p *redisPipeline) HSet(ctx context.Context, key string, values ...any) error {
+	p.pipe.HSet(ctx, key, values...)
+	return nil
+}
+
+func (p *redisPipeline) HDel(ctx context.Context, key string, fields ...string) error {
+	p.pipe.HDel(ctx, key, fields...)
+	return nil
+}
+
+func (p *redisPipeline) SAdd(ctx context.Context, key string, members ...any) error {
+	p.pipe.SAdd(ctx, key, members...)
+	return nil
+}
+
+func (p *redisPipeline) SRem(ctx context.Context, key string, members ...any) error {
+	p.pipe.SRem(ctx, key, members...)
+	return nil
+}
+
+func (p *redisPipeline) Del(ctx context.Context, keys ...string) error {
+	p.pipe.Del(ctx, keys...)
+	return nil
+}
+
+func (p *redisPipeline) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	p.pipe.Expire(ctx, key, ttl)
+	return nil
+}
+
+func (p *redisPipeline) Incr(ctx context.Context, key string) *IntResult {
+	return &IntResult{resolve: p.pipe.Incr(ctx, key).Result}
+}
+
+func (p *redisPipeline) Get(ctx context.Context, key string) *StringResult {
+	return &StringResult{resolve: p.pipe.Get(ctx, key).Result}
+}
+
+func (p *redisPipeline) HGet(ctx context.Context, key, field string) *StringResult {
+	return &StringResult{resolve: p.pipe.HGet(ctx, key, field).Result}
+}
+
+func (p *redisPipeline) SIsMember(ctx context.Context, key string, member any) *BoolResult {
+	return &BoolResult{resolve: p.pipe.SIsMember(ctx, key, member).Result}
+}
+
+func (p *redisPipeline) Exec(ctx context.Context) error {
+	if _, err := p.pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,439 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// memoryEntry holds one key. Exactly one of str/hash/set is in use.
+type memoryEntry struct {
+	str     string
+	hash    map[string]string
+	set     map[string]struct{}
+	expires time.Time
+}
+
+func (e *memoryEntry) expired(now time.Time) bool {
+	return !e.expires.IsZero() && now.After(e.expires)
+}
+
+// MemoryLiveState is a process-local LiveStateStore for single-node installs.
+// State is lost on restart and is not shared between API instances.
+type MemoryLiveState struct {
+	mu   sync.Mutex
+	data map[string]*memoryEntry
+}
+
+func NewMemoryLiveState() *MemoryLiveState {
+	return &MemoryLiveState{data: make(map[string]*memoryEntry)}
+}
+
+// entry returns the live entry for key, dropping it if expired. Caller holds mu.
+func (m *MemoryLiveState) entry(key string) *memoryEntry {
+	e, ok := m.data[key]
+	if !ok {
+		return nil
+	}
+	if e.expired(time.Now()) {
+		delete(m.data, key)
+		return nil
+	}
+	return e
+}
+
+func (m *MemoryLiveState) entryOrCreate(key string) *memoryEntry {
+	if e := m.entry(key); e != nil {
+		return e
+	}
+	e := &memoryEntry{}
+	m.data[key] = e
+	return e
+}
+
+// PurgeExpired removes every expired key and returns how many were dropped
+func (m *MemoryLiveState) PurgeExpired() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	n := 0
+	for k, e := range m.data {
+		if e.expired(now) {
+			delete(m.data, k)
+			n++
+		}
+	}
+	return n
+}
+
+// Len returns the number of keys currently held
+func (m *MemoryLiveState) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.data)
+}
+
+func liveStateString(v any) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case []byte:
+		return string(t)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (m *MemoryLiveState) Get(ctx context.Context, key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := m.entry(key)
+	if e == nil || e.hash != nil || e.set != nil {
+		return "", ErrNotFound
+	}
+	return e.str, nil
+}
+
+func (m *MemoryLiveState) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := &memoryEntry{str: liveStateString(value)}
+	if ttl > 0 {
+		e.expires = time.Now().Add(ttl)
+	}
+	m.data[key] = e
+	return nil
+}
+
+func (m *MemoryLiveState) SetNX(ctx context.Context, key string, value any, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.entry(key) != nil {
+		return false, nil
+	}
+	e := &memoryEntry{str: liveStateString(value)}
+	if ttl > 0 {
+		e.expires = time.Now().Add(ttl)
+	}
+	m.data[key] = e
+	return true, nil
+}
+
+func (m *MemoryLiveState) CompareAndDelete(ctx context.Context, key, value string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := m.entry(key)
+	if e == nil || e.hash != nil || e.set != nil || e.str != value {
+		return false, nil
+	}
+	delete(m.data, key)
+	return true, nil
+}
+
+func (m *MemoryLiveState) CompareAndExpire(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := m.entry(key)
+	if e == nil || e.hash != nil || e.set != nil || e.str != value {
+		return false, nil
+	}
+	e.expires = time.Now().Add(ttl)
+	return true, nil
+}
+
+func (m *MemoryLiveState) Del(ctx context.Context, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, k := range keys {
+		delete(m.data, k)
+	}
+	return nil
+}
+
+func (m *MemoryLiveState) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e := m.entry(key); e != nil {
+		e.expires = time.Now().Add(ttl)
+	}
+	return nil
+}
+
+func (m *MemoryLiveState) Incr(ctx context.Context, key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.incr(key)
+}
+
+func (m *MemoryLiveState) incr(key string) (int64, error) {
+	e := m.entryOrCreate(key)
+	var n int64
+	if e.str != "" {
+		v, err := strconv.ParseInt(e.str, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("live state: value at %s is not an integer", key)
+		}
+		n = v
+	}
+	n++
+	e.str = strconv.FormatInt(n, 10)
+	return n, nil
+}
+
+func (m *MemoryLiveState) IncrByFloat(ctx context.Context, key string, value float64) (float64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := m.entryOrCreate(key)
+	var f float64
+	if e.str != "" {
+		v, err := strconv.ParseFloat(e.str, 64)
+		if err != nil {
+			return 0, fmt.Errorf("live state: value at %s is not a float", key)
+		}
+		f = v
+	}
+	f += value
+	e.str = strconv.FormatFloat(f, 'f', -1, 64)
+	return f, nil
+}
+
+func (m *MemoryLiveState) HGet(ctx context.Context, key, field string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := m.entry(key)
+	if e == nil || e.hash == nil {
+		return "", ErrNotFound
+	}
+	v, ok := e.hash[field]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return v, nil
+}
+
+func (m *MemoryLiveState) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make(map[string]string)
+	if e := m.entry(key); e != nil {
+		for k, v := range e.hash {
+			out[k] = v
+		}
+	}
+	return out, nil
+}
+
+// HSet accepts field/value pairs or a single map, like go-redis
+func (m *MemoryLiveState) HSet(ctx context.Context, key string, values ...any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pairs := make(map[string]string)
+	if len(values) == 1 {
+		switch t := values[0].(type) {
+		case map[string]string:
+			for k, v := range t {
+				pairs[k] = v
+			}
+		case map[string]any:
+			for k, v := range t {
+				pairs[k] = liveStateString(v)
+			}
+		default:
+			return fmt.Errorf("live state: HSet needs field/value pairs")
+		}
+	} else {
+		if len(values)%2 != 0 {
+			return fmt.Errorf("live state: HSet needs field/value pairs")
+		}
+		for i := 0; i < len(values); i += 2 {
+			pairs[liveStateString(values[i])] = liveStateString(values[i+1])
+		}
+	}
+
+	e := m.entryOrCreate(key)
+	if e.hash == nil {
+		e.hash = make(map[string]string)
+	}
+	for k, v := range pairs {
+		e.hash[k] = v
+	}
+	return nil
+}
+
+func (m *MemoryLiveState) HDel(ctx context.Context, key string, fields ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := m.entry(key)
+	if e == nil || e.hash == nil {
+		return nil
+	}
+	for _, f := range fields {
+		delete(e.hash, f)
+	}
+	if len(e.hash) == 0 {
+		delete(m.data, key)
+	}
+	return nil
+}
+
+func (m *MemoryLiveState) HKeys(ctx context.Context, key string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []string
+	if e := m.entry(key); e != nil {
+		for k := range e.hash {
+			out = append(out, k)
+		}
+	}
+	return out, nil
+}
+
+func (m *MemoryLiveState) HVals(ctx context.Context, key string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []string
+	if e := m.entry(key); e != nil {
+		for _, v := range e.hash {
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+func (m *MemoryLiveState) HLen(ctx context.Context, key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e := m.entry(key); e != nil {
+		return int64(len(e.hash)), nil
+	}
+	return 0, nil
+}
+
+func (m *MemoryLiveState) HExists(ctx context.Context, key, field string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e := m.entry(key); e != nil && e.hash != nil {
+		_, ok := e.hash[field]
+		return ok, nil
+	}
+	return false, nil
+}
+
+func (m *MemoryLiveState) SAdd(ctx context.Context, key string, members ...any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := m.entryOrCreate(key)
+	if e.set == nil {
+		e.set = make(map[string]struct{})
+	}
+	for _, mem := range members {
+		e.set[liveStateString(mem)] = struct{}{}
+	}
+	return nil
+}
+
+func (m *MemoryLiveState) SRem(ctx context.Context, key string, members ...any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := m.entry(key)
+	if e == nil || e.set == nil {
+		return nil
+	}
+	for _, mem := range members {
+		delete(e.set, liveStateString(mem))
+	}
+	if len(e.set) == 0 {
+		delete(m.data, key)
+	}
+	return nil
+}
+
+func (m *MemoryLiveState) SMembers(ctx context.Context, key string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []string
+	if e := m.entry(key); e != nil {
+		for k := range e.set {
+			out = append(out, k)
+		}
+	}
+	return out, nil
+}
+
+func (m *MemoryLiveState) SIsMember(ctx context.Context, key string, member any) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e := m.entry(key); e != nil && e.set != nil {
+		_, ok := e.set[liveStateString(member)]
+		return ok, nil
+	}
+	return false, nil
+}
+
+// Publish is a no-op: nothing subscribes in single-node mode
+func (m *MemoryLiveState) Publish(ctx context.Context, channel string, message any) error {
+	return nil
+}
+
+func (m *MemoryLiveState) Ping(ctx context.Context) error { return nil }
+
+func (m *MemoryLiveState) Close() error { return nil }
+
+// Pipeline runs each command immediately; Exec is a no-op
+func (m *MemoryLiveState) Pipeline() LiveStatePipeline {
+	return &memoryPipeline{m: m}
+}
+
+type memoryPipeline struct {
+	m *MemoryLiveState
+}
+
+func (p *memoryPipeline) HSet(ctx context.Context, key string, values ...any) error {
+	return p.m.HSet(ctx, key, values...)
+}
+
+func (p *memoryPipeline) HDel(ctx context.Context, key string, fields ...string) error {
+	return p.m.HDel(ctx, key, fields...)
+}
+
+func (p *memoryPipeline) SAdd(ctx context.Context, key string, members ...any) error {
+	return p.m.SAdd(ctx, key, members...)
+}
+
+func (p *memoryPipeline) SRem(ctx context.Context, key string, members ...any) error {
+	return p.m.SRem(ctx, key, members...)
+}
+
+func (p *memoryPipeline) Del(ctx context.Context, keys ...string) error {
+	return p.m.Del(ctx, keys...)
+}
+
+func (p *memoryPipeline) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	return p.m.Expire(ctx, key, ttl)
+}
+
+func (p *memoryPipeline) Incr(ctx context.Context, key string) *IntResult {
+	v, err := p.m.Incr(ctx, key)
+	return &IntResult{val: v, err: err}
+}
+
+func (p *memoryPipeline) Get(ctx context.Context, key string) *StringResult {
+	v, err := p.m.Get(ctx, key)
+	return &StringResult{val: v, err: err}
+}
+
+func (p *memoryPipeline) HGet(ctx context.Context, key, field string) *StringResult {
+	v, err := p.m.HGet(ctx, key, field)
+	return &StringResult{val: v, err: err}
+}
+
+func (p *memoryPipeline) SIsMember(ctx context.Context, key string, member any) *BoolResult {
+	v, err := p.m.SIsMember(ctx, key, member)
+	return &BoolResult{val: v, err: err}
+}
+
+func (p *memoryPipeline) Exec(ctx context.Context) error { return nil }
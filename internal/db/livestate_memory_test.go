@@ -0,0 +1,127 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryLiveState_StringsAndCounters(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryLiveState()
+
+	if _, err := m.Get(ctx, "missing"); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	m.Set(ctx, "claim:abc", []byte(`{"user_id":1}`), 0)
+	if v, err := m.Get(ctx, "claim:abc"); err != nil || v != `{"user_id":1}` {
+		t.Fatalf("unexpected Get: %q, %v", v, err)
+	}
+
+	for i := int64(1); i <= 3; i++ {
+		n, err := m.Incr(ctx, "player:g1:kills")
+		if err != nil || n != i {
+			t.Fatalf("Incr #%d = %d, %v", i, n, err)
+		}
+	}
+
+	m.Set(ctx, "name", "not a number", 0)
+	if _, err := m.Incr(ctx, "name"); err == nil {
+		t.Error("expected Incr on a non-integer to fail")
+	}
+}
+
+func TestMemoryLiveState_Hashes(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryLiveState()
+
+	m.HSet(ctx, "live_matches", "m1", "{}", "m2", "{}")
+	m.HSet(ctx, "match:m1:teams", map[string]interface{}{"g1": "allies", "g2": "axis"})
+
+	if n, _ := m.HLen(ctx, "live_matches"); n != 2 {
+		t.Errorf("HLen = %d, want 2", n)
+	}
+	if v, err := m.HGet(ctx, "match:m1:teams", "g2"); err != nil || v != "axis" {
+		t.Errorf("HGet = %q, %v", v, err)
+	}
+	if _, err := m.HGet(ctx, "match:m1:teams", "g3"); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound for missing field, got %v", err)
+	}
+	if err := m.HSet(ctx, "bad", "only-field"); err == nil {
+		t.Error("expected odd HSet args to fail")
+	}
+
+	m.HDel(ctx, "live_matches", "m1", "m2")
+	if ok, _ := m.HExists(ctx, "live_matches", "m1"); ok {
+		t.Error("m1 should be gone")
+	}
+	if m.Len() != 1 {
+		t.Errorf("empty hash should be removed, have %d keys", m.Len())
+	}
+}
+
+func TestMemoryLiveState_Expiry(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryLiveState()
+
+	m.Set(ctx, "login_token:x", "1", time.Millisecond)
+	m.SAdd(ctx, "player:g1:achievements", "first_blood")
+	m.Expire(ctx, "player:g1:achievements", time.Millisecond)
+	m.Set(ctx, "keep", "1", 0)
+
+	time.Sleep(5 * time.Millisecond)
+
+	if _, err := m.Get(ctx, "login_token:x"); err != ErrNotFound {
+		t.Errorf("expired key still readable: %v", err)
+	}
+	if n := m.PurgeExpired(); n != 1 {
+		t.Errorf("PurgeExpired = %d, want 1", n)
+	}
+	if m.Len() != 1 {
+		t.Errorf("Len = %d, want 1", m.Len())
+	}
+}
+
+func TestMemoryLiveState_SetNX(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryLiveState()
+
+	if ok, err := m.SetNX(ctx, "jobs:lock:a", "node-1", time.Millisecond); err != nil || !ok {
+		t.Fatalf("first SetNX = %v, %v", ok, err)
+	}
+	if ok, _ := m.SetNX(ctx, "jobs:lock:a", "node-2", time.Minute); ok {
+		t.Error("SetNX replaced a held key")
+	}
+	time.Sleep(5 * time.Millisecond)
+	if ok, _ := m.SetNX(ctx, "jobs:lock:a", "node-2", time.Minute); !ok {
+		t.Error("SetNX failed on an expired key")
+	}
+	if v, _ := m.Get(ctx, "jobs:lock:a"); v != "node-2" {
+		t.Errorf("Get = %q, want node-2", v)
+	}
+}
+
+func TestMemoryLiveState_Pipeline(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryLiveState()
+	m.SAdd(ctx, "player:g1:achievements", "first_blood")
+
+	pipe := m.Pipeline()
+	kills := pipe.Incr(ctx, "player:g1:kills")
+	has := pipe.SIsMember(ctx, "player:g1:achievements", "first_blood")
+	name := pipe.HGet(ctx, "match:m1:players", "g1")
+	if err := pipe.Exec(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if n, _ := kills.Result(); n != 1 {
+		t.Errorf("kills = %d, want 1", n)
+	}
+	if !has.Val() {
+		t.Error("expected achievement membership")
+	}
+	if _, err := name.Result(); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
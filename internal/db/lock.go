@@ -0,0 +1,174 @@
+package db
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+var leaderGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "mohaa_leader",
+	Help: "1 while this instance holds the named leadership, 0 otherwise",
+}, []string{"name"})
+
+// lockPrefix namespaces lock keys in live state
+const lockPrefix = "lock:"
+
+// InstanceID names this API process as a lock owner: host, pid and a random
+// suffix, so a restarted process never mistakes its predecessor's locks for
+// its own
+var InstanceID = newInstanceID()
+
+func newInstanceID() string {
+	host, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
+
+// Lock is a named lease in live state, held by one owner at a time until it
+// is released or its TTL runs out. With Redis behind it the lock holds across
+// API instances; the in-memory store only excludes within one process.
+type Lock struct {
+	store LiveStateStore
+	key   string
+	owner string
+	ttl   time.Duration
+}
+
+// NewLock returns the lock of name, taken as owner for ttl at a time
+func NewLock(store LiveStateStore, name, owner string, ttl time.Duration) *Lock {
+	return &Lock{store: store, key: lockPrefix + name, owner: owner, ttl: ttl}
+}
+
+// TryAcquire takes the lock if nobody holds it, reporting whether it did.
+// It is not reentrant: an owner already holding the lock does not get it
+// again.
+func (l *Lock) TryAcquire(ctx context.Context) (bool, error) {
+	return l.store.SetNX(ctx, l.key, l.owner, l.ttl)
+}
+
+// Refresh restarts the TTL of a lock this owner holds, reporting false when
+// the lock expired or another owner holds it
+func (l *Lock) Refresh(ctx context.Context) (bool, error) {
+	return l.store.CompareAndExpire(ctx, l.key, l.owner, l.ttl)
+}
+
+// Release drops the lock if this owner still holds it
+func (l *Lock) Release(ctx context.Context) error {
+	_, err := l.store.CompareAndDelete(ctx, l.key, l.owner)
+	return err
+}
+
+// Holder returns the owner holding the lock, or "" if nobody does
+func (l *Lock) Holder(ctx context.Context) (string, error) {
+	owner, err := l.store.Get(ctx, l.key)
+	if errors.Is(err, ErrNotFound) {
+		return "", nil
+	}
+	return owner, err
+}
+
+// Leader elects one API instance to do work that must not run on several at
+// once. Every instance campaigns for the same lock; the holder renews it at
+// a third of its TTL, and when it stops or dies another instance takes over
+// within a TTL.
+type Leader struct {
+	name   string
+	lock   *Lock
+	logger *zap.SugaredLogger
+	leader atomic.Bool
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewLeader(store LiveStateStore, name string, ttl time.Duration, logger *zap.Logger) *Leader {
+	return &Leader{
+		name:   name,
+		lock:   NewLock(store, "leader:"+name, InstanceID, ttl),
+		logger: logger.Sugar(),
+		done:   make(chan struct{}),
+	}
+}
+
+// Start campaigns once before returning, so IsLeader is settled for work
+// started right after it, then every third of the TTL
+func (l *Leader) Start(ctx context.Context) {
+	ctx, l.cancel = context.WithCancel(ctx)
+	l.campaign(ctx)
+	go func() {
+		defer close(l.done)
+
+		ticker := time.NewTicker(l.lock.ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				l.campaign(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops campaigning and hands leadership over by releasing the lock
+func (l *Leader) Stop() {
+	if l.cancel == nil {
+		return
+	}
+	l.cancel()
+	<-l.done
+	if l.leader.Load() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := l.lock.Release(ctx); err != nil {
+			l.logger.Warnw("Failed to release leadership", "name", l.name, "error", err)
+		}
+		l.set(false)
+	}
+}
+
+// IsLeader reports whether this instance led as of the last campaign
+func (l *Leader) IsLeader() bool {
+	return l.leader.Load()
+}
+
+// campaign renews the lock if this instance holds it, or tries to take it.
+// A failed call to live state gives up leadership, as the lock may expire
+// before the next try; the renewal then picks it back up if it did not.
+func (l *Leader) campaign(ctx context.Context) {
+	ok, err := l.lock.Refresh(ctx)
+	if !ok && err == nil {
+		ok, err = l.lock.TryAcquire(ctx)
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			l.logger.Warnw("Leader election failed", "name", l.name, "error", err)
+		}
+		ok = false
+	}
+	l.set(ok)
+}
+
+func (l *Leader) set(leader bool) {
+	if l.leader.Swap(leader) == leader {
+		return
+	}
+	if leader {
+		leaderGauge.WithLabelValues(l.name).Set(1)
+		l.logger.Infow("Became leader", "name", l.name, "instance", InstanceID)
+	} else {
+		leaderGauge.WithLabelValues(l.name).Set(0)
+		l.logger.Infow("Lost leadership", "name", l.name, "instance", InstanceID)
+	}
+}
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestLock(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryLiveState()
+	a := NewLock(store, "job:x", "node-a", time.Minute)
+	b := NewLock(store, "job:x", "node-b", time.Minute)
+
+	if ok, err := a.TryAcquire(ctx); err != nil || !ok {
+		t.Fatalf("a.TryAcquire = %v, %v", ok, err)
+	}
+	if ok, _ := a.TryAcquire(ctx); ok {
+		t.Error("lock is reentrant")
+	}
+	if ok, _ := b.TryAcquire(ctx); ok {
+		t.Error("b took a held lock")
+	}
+	if ok, _ := b.Refresh(ctx); ok {
+		t.Error("b refreshed a's lock")
+	}
+	if err := b.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if holder, _ := a.Holder(ctx); holder != "node-a" {
+		t.Errorf("b released a's lock, holder %q", holder)
+	}
+	if ok, _ := a.Refresh(ctx); !ok {
+		t.Error("a could not refresh its lock")
+	}
+
+	a.Release(ctx)
+	if holder, _ := a.Holder(ctx); holder != "" {
+		t.Errorf("holder after release = %q", holder)
+	}
+	if ok, _ := b.TryAcquire(ctx); !ok {
+		t.Error("b could not take the released lock")
+	}
+}
+
+func TestLeader(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryLiveState()
+	first := NewLeader(store, "reconciler", time.Minute, zap.NewNop())
+	first.Start(ctx)
+	if !first.IsLeader() {
+		t.Fatal("first instance did not lead")
+	}
+
+	// A second instance, as another process would be
+	second := NewLeader(store, "reconciler", time.Minute, zap.NewNop())
+	second.lock.owner = "other-instance"
+	second.campaign(ctx)
+	if second.IsLeader() {
+		t.Fatal("two leaders")
+	}
+
+	first.Stop()
+	if first.IsLeader() {
+		t.Error("stopped instance still leads")
+	}
+	second.campaign(ctx)
+	if !second.IsLeader() {
+		t.Error("second instance did not take over")
+	}
+	second.campaign(ctx)
+	if !second.IsLeader() {
+		t.Error("leader lost its lease on renewal")
+	}
+}
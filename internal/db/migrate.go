@@ -0,0 +1,151 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// Migrate applies every .sql file under dir/postgres and dir/clickhouse that is
+// not yet recorded in the Postgres schema_migrations table, in file name
+// order, Postgres first. It returns the names of the files it applied.
+func Migrate(ctx context.Context, pg *pgxpool.Pool, ch driver.Conn, dir string, logger *zap.Logger) ([]string, error) {
+	sugar := logger.Sugar()
+
+	if _, err := pg.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			name       TEXT PRIMARY KEY,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)`); err != nil {
+		return nil, fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	applied := make(map[string]bool)
+	rows, err := pg.Query(ctx, "SELECT name FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("read schema_migrations: %w", err)
+	}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		applied[name] = true
+	}
+	rows.Close()
+
+	var done []string
+	for _, engine := range []string{"postgres", "clickhouse"} {
+		files, err := filepath.Glob(filepath.Join(dir, engine, "*.sql"))
+		if err != nil {
+			return done, err
+		}
+		sort.Strings(files)
+
+		for _, path := range files {
+			name := engine + "/" + filepath.Base(path)
+			if applied[name] {
+				continue
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return done, fmt.Errorf("read %s: %w", name, err)
+			}
+
+			if engine == "postgres" {
+				_, err = pg.Exec(ctx, string(content))
+			} else {
+				err = execClickHouseScript(ctx, ch, string(content))
+			}
+			if err != nil {
+				return done, fmt.Errorf("apply %s: %w", name, err)
+			}
+
+			if _, err := pg.Exec(ctx, "INSERT INTO schema_migrations (name) VALUES ($1)", name); err != nil {
+				return done, fmt.Errorf("record %s: %w", name, err)
+			}
+			sugar.Infow("Applied migration", "name", name)
+			done = append(done, name)
+		}
+	}
+	return done, nil
+}
+
+// execClickHouseScript runs a multi-statement script one statement at a time,
+// since the native protocol accepts a single statement per query
+func execClickHouseScript(ctx context.Context, ch driver.Conn, script string) error {
+	for _, stmt := range splitStatements(script) {
+		if err := ch.Exec(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitStatements splits a script on the semicolons between statements.
+// Comments are dropped, so a semicolon in one does not end a statement, and
+// quoted strings and identifiers are kept whole.
+func splitStatements(script string) []string {
+	var (
+		stmts []string
+		cur   strings.Builder
+	)
+	flush := func() {
+		if stmt := strings.TrimSpace(cur.String()); stmt != "" {
+			stmts = append(stmts, stmt)
+		}
+		cur.Reset()
+	}
+
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case c == '-' && strings.HasPrefix(script[i:], "--"):
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				i = len(script)
+				continue
+			}
+			i += end
+			cur.WriteByte('\n')
+		case c == '/' && strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				i = len(script)
+				continue
+			}
+			i += end + 3
+			cur.WriteByte(' ')
+		case c == '\'' || c == '"' || c == '`':
+			// Copy through the closing quote; a backslash escapes the next
+			// character and a doubled quote stands for itself
+			start := i
+			for i++; i < len(script); i++ {
+				if script[i] == '\\' {
+					i++
+				} else if script[i] == c {
+					if i+1 < len(script) && script[i+1] == c {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			cur.WriteString(script[start:min(i+1, len(script))])
+		case c == ';':
+			flush()
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	flush()
+	return stmts
+}
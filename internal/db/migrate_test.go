@@ -0,0 +1,61 @@
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	cases := map[string][]string{
+		"":                                  nil,
+		"\n  -- trailing note\n\n":          nil,
+		"-- create db\nCREATE DATABASE x":   {"CREATE DATABASE x"},
+		"  OPTIMIZE TABLE t FINAL  ":        {"OPTIMIZE TABLE t FINAL"},
+		"--a\n--b\n   SELECT 1 -- inline\n": {"SELECT 1"},
+		"-- drop the table; refill it\nSELECT 1;\nSELECT 2;": {"SELECT 1", "SELECT 2"},
+		"SELECT 1 /* a; b */ + 2;":                           {"SELECT 1   + 2"},
+		"SELECT 'a;b', 'it''s; -- not a comment';SELECT `x;y`": {
+			"SELECT 'a;b', 'it''s; -- not a comment'",
+			"SELECT `x;y`",
+		},
+		`SELECT 'back\'slash;'`: {`SELECT 'back\'slash;'`},
+	}
+	for script, want := range cases {
+		if got := splitStatements(script); !reflect.DeepEqual(got, want) {
+			t.Errorf("splitStatements(%q) = %q, want %q", script, got, want)
+		}
+	}
+}
+
+// TestClickHouseMigrationsSplit checks that every ClickHouse migration splits
+// into statements that start with SQL, not with the tail of a comment
+func TestClickHouseMigrationsSplit(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("..", "..", "migrations", "clickhouse", "*.sql"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no ClickHouse migrations found: %v", err)
+	}
+	keywords := []string{"CREATE", "ALTER", "INSERT", "DROP", "OPTIMIZE", "SYSTEM", "RENAME", "TRUNCATE", "SET", "SELECT", "WITH", "DETACH", "ATTACH"}
+
+	for _, path := range files {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, stmt := range splitStatements(string(content)) {
+			word := strings.ToUpper(strings.Fields(stmt)[0])
+			found := false
+			for _, kw := range keywords {
+				if word == kw {
+					found = true
+					break
+				}
+			}
+			if !found {
+				t.Errorf("%s: statement %d starts with %q: %.60q", filepath.Base(path), i, word, stmt)
+			}
+		}
+	}
+}
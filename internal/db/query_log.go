@@ -0,0 +1,277 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+var clickhouseQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "mohaa_clickhouse_query_duration_seconds",
+	Help:    "Duration of ClickHouse queries by query name",
+	Buckets: prometheus.DefBuckets,
+}, []string{"query"})
+
+const (
+	maxLoggedQueryLen = 2000
+	maxLoggedArgLen   = 200
+)
+
+type queryNameKey struct{}
+
+// WithQueryName overrides the name recorded for ClickHouse calls made with ctx.
+// Without it the calling function's name is used.
+func WithQueryName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, queryNameKey{}, name)
+}
+
+// QueryLog keeps per-name timings for every ClickHouse call and a ring of the
+// most recent calls that exceeded the slow threshold
+type QueryLog struct {
+	threshold atomic.Int64
+	logger    *zap.SugaredLogger
+
+	mu    sync.Mutex
+	slow  []models.SlowQuery
+	next  int
+	full  bool
+	stats map[string]*models.QueryStat
+}
+
+func NewQueryLog(threshold time.Duration, size int, logger *zap.Logger) *QueryLog {
+	if size <= 0 {
+		size = 200
+	}
+	l := &QueryLog{
+		logger: logger.Sugar(),
+		slow:   make([]models.SlowQuery, size),
+		stats:  make(map[string]*models.QueryStat),
+	}
+	l.threshold.Store(int64(threshold))
+	return l
+}
+
+// Threshold returns the duration above which a call is considered slow
+func (l *QueryLog) Threshold() time.Duration {
+	return time.Duration(l.threshold.Load())
+}
+
+// SetThreshold changes the slow threshold for subsequent calls
+func (l *QueryLog) SetThreshold(threshold time.Duration) {
+	l.threshold.Store(int64(threshold))
+}
+
+// Record registers one call. Slow calls are logged with their parameters.
+func (l *QueryLog) Record(name, query string, args []any, d time.Duration, err error) {
+	ms := float64(d.Microseconds()) / 1000
+	clickhouseQueryDuration.WithLabelValues(name).Observe(d.Seconds())
+
+	threshold := l.Threshold()
+	slow := threshold > 0 && d >= threshold
+
+	l.mu.Lock()
+	st, ok := l.stats[name]
+	if !ok {
+		st = &models.QueryStat{Name: name}
+		l.stats[name] = st
+	}
+	st.Count++
+	st.TotalMs += ms
+	if ms > st.MaxMs {
+		st.MaxMs = ms
+	}
+	if err != nil {
+		st.Errors++
+	}
+
+	var entry models.SlowQuery
+	if slow {
+		st.SlowCount++
+		entry = models.SlowQuery{
+			Name:       name,
+			Query:      compactQuery(query),
+			Args:       formatArgs(args),
+			DurationMs: ms,
+			At:         time.Now().UTC(),
+		}
+		if err != nil {
+			entry.Error = err.Error()
+		}
+		l.slow[l.next] = entry
+		l.next = (l.next + 1) % len(l.slow)
+		if l.next == 0 {
+			l.full = true
+		}
+	}
+	l.mu.Unlock()
+
+	if slow {
+		l.logger.Warnw("Slow ClickHouse query",
+			"name", name,
+			"duration_ms", ms,
+			"args", entry.Args,
+			"query", entry.Query,
+		)
+	}
+}
+
+// Slow returns up to limit slow calls, newest first
+func (l *QueryLog) Slow(limit int) []models.SlowQuery {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	n := l.next
+	if l.full {
+		n = len(l.slow)
+	}
+	if limit <= 0 || limit > n {
+		limit = n
+	}
+
+	out := make([]models.SlowQuery, 0, limit)
+	for i := 0; i < limit; i++ {
+		idx := (l.next - 1 - i + len(l.slow)) % len(l.slow)
+		out = append(out, l.slow[idx])
+	}
+	return out
+}
+
+// Registry returns the timing stats of every named query, slowest total first
+func (l *QueryLog) Registry() []models.QueryStat {
+	l.mu.Lock()
+	out := make([]models.QueryStat, 0, len(l.stats))
+	for _, st := range l.stats {
+		s := *st
+		if s.Count > 0 {
+			s.AvgMs = s.TotalMs / float64(s.Count)
+		}
+		out = append(out, s)
+	}
+	l.mu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool { return out[i].TotalMs > out[j].TotalMs })
+	return out
+}
+
+// Instrument wraps conn so that Query, QueryRow, Select and Exec calls are
+// timed and recorded in log. Batches and async inserts pass through untouched.
+func Instrument(conn driver.Conn, log *QueryLog) driver.Conn {
+	return &instrumentedConn{Conn: conn, log: log}
+}
+
+type instrumentedConn struct {
+	driver.Conn
+	log *QueryLog
+}
+
+func (c *instrumentedConn) Query(ctx context.Context, query string, args ...any) (driver.Rows, error) {
+	name := queryName(ctx)
+	start := time.Now()
+	rows, err := c.Conn.Query(ctx, query, args...)
+	if err != nil {
+		c.log.Record(name, query, args, time.Since(start), err)
+		return nil, err
+	}
+	// Streaming reads count towards the query, so record when rows close
+	return &instrumentedRows{Rows: rows, record: func() {
+		c.log.Record(name, query, args, time.Since(start), rows.Err())
+	}}, nil
+}
+
+func (c *instrumentedConn) QueryRow(ctx context.Context, query string, args ...any) driver.Row {
+	name := queryName(ctx)
+	start := time.Now()
+	row := c.Conn.QueryRow(ctx, query, args...)
+	c.log.Record(name, query, args, time.Since(start), row.Err())
+	return row
+}
+
+func (c *instrumentedConn) Select(ctx context.Context, dest any, query string, args ...any) error {
+	name := queryName(ctx)
+	start := time.Now()
+	err := c.Conn.Select(ctx, dest, query, args...)
+	c.log.Record(name, query, args, time.Since(start), err)
+	return err
+}
+
+func (c *instrumentedConn) Exec(ctx context.Context, query string, args ...any) error {
+	name := queryName(ctx)
+	start := time.Now()
+	err := c.Conn.Exec(ctx, query, args...)
+	c.log.Record(name, query, args, time.Since(start), err)
+	return err
+}
+
+type instrumentedRows struct {
+	driver.Rows
+	once   sync.Once
+	record func()
+}
+
+func (r *instrumentedRows) Close() error {
+	err := r.Rows.Close()
+	r.once.Do(r.record)
+	return err
+}
+
+// queryName resolves the name for a call: an explicit WithQueryName value,
+// otherwise the function two frames up (the caller of the wrapped method)
+func queryName(ctx context.Context) string {
+	if name, ok := ctx.Value(queryNameKey{}).(string); ok && name != "" {
+		return name
+	}
+	pc, _, _, ok := runtime.Caller(2)
+	if !ok {
+		return "unknown"
+	}
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return "unknown"
+	}
+	return shortFuncName(fn.Name())
+}
+
+// shortFuncName trims the module path, e.g.
+// "github.com/openmohaa/stats-api/internal/logic.(*serverStatsService).GetGlobalStats"
+// becomes "logic.(*serverStatsService).GetGlobalStats"
+func shortFuncName(name string) string {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+func compactQuery(query string) string {
+	query = strings.Join(strings.Fields(query), " ")
+	if len(query) > maxLoggedQueryLen {
+		query = query[:maxLoggedQueryLen] + "..."
+	}
+	return query
+}
+
+func formatArgs(args []any) []string {
+	if len(args) == 0 {
+		return nil
+	}
+	out := make([]string, len(args))
+	for i, a := range args {
+		s := fmt.Sprint(a)
+		if len(s) > maxLoggedArgLen {
+			s = s[:maxLoggedArgLen] + "..."
+		}
+		out[i] = s
+	}
+	return out
+}
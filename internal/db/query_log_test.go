@@ -0,0 +1,52 @@
+package db
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestQueryLogRecordsSlowQueries(t *testing.T) {
+	log := NewQueryLog(100*time.Millisecond, 2, zap.NewNop())
+
+	log.Record("fast", "SELECT 1", nil, 10*time.Millisecond, nil)
+	log.Record("slow", "SELECT\n\t  2", []any{"srv", 7}, 150*time.Millisecond, nil)
+	log.Record("slow", "SELECT 3", nil, 300*time.Millisecond, errors.New("boom"))
+	log.Record("slower", "SELECT 4", nil, time.Second, nil)
+
+	slow := log.Slow(10)
+	if len(slow) != 2 {
+		t.Fatalf("expected ring to hold 2 entries, got %d", len(slow))
+	}
+	if slow[0].Name != "slower" || slow[1].Error != "boom" {
+		t.Errorf("expected newest first, got %+v", slow)
+	}
+
+	reg := log.Registry()
+	if reg[0].Name != "slower" {
+		t.Errorf("expected registry ordered by total time, got %s first", reg[0].Name)
+	}
+	for _, st := range reg {
+		if st.Name == "slow" && (st.Count != 2 || st.SlowCount != 2 || st.Errors != 1 || st.MaxMs != 300) {
+			t.Errorf("unexpected stats for slow: %+v", st)
+		}
+		if st.Name == "fast" && st.SlowCount != 0 {
+			t.Errorf("fast query marked slow: %+v", st)
+		}
+	}
+}
+
+func TestCompactQuery(t *testing.T) {
+	if got := compactQuery("\n\t\tSELECT  a,\n\t\t\tb FROM t\n"); got != "SELECT a, b FROM t" {
+		t.Errorf("compactQuery = %q", got)
+	}
+}
+
+func TestShortFuncName(t *testing.T) {
+	got := shortFuncName("github.com/openmohaa/stats-api/internal/logic.(*serverStatsService).GetGlobalStats")
+	if got != "logic.(*serverStatsService).GetGlobalStats" {
+		t.Errorf("shortFuncName = %q", got)
+	}
+}
@@ -0,0 +1,289 @@
+// Package eventlint checks NDJSON event files written by game-script mods
+// against the event contract the ingest API expects: known types, their
+// required fields, and sane values. Mod authors run it (cmd/eventlint) on a
+// capture of their script's output before pointing a server at production.
+package eventlint
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// maxLineSize is the longest event line read; the ingest API takes whole
+// batches of this size, so a single event never gets near it
+const maxLineSize = 1 << 20
+
+// Severity says whether an issue breaks ingestion or only weakens the stats
+type Severity string
+
+const (
+	// SeverityError marks events the API rejects or stores without the
+	// player or match they belong to
+	SeverityError Severity = "error"
+	// SeverityWarning marks events the API accepts but may read differently
+	// than the script intended
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is one problem found on one line
+type Issue struct {
+	Line     int              `json:"line"`
+	Severity Severity         `json:"severity"`
+	Type     models.EventType `json:"type,omitempty"`
+	Field    string           `json:"field,omitempty"`
+	Message  string           `json:"message"`
+}
+
+func (i Issue) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d: %s: ", i.Line, i.Severity)
+	if i.Type != "" {
+		fmt.Fprintf(&b, "%s: ", i.Type)
+	}
+	if i.Field != "" {
+		fmt.Fprintf(&b, "%s: ", i.Field)
+	}
+	b.WriteString(i.Message)
+	return b.String()
+}
+
+// Result is the outcome of linting one file
+type Result struct {
+	Events int                      `json:"events"`
+	Types  map[models.EventType]int `json:"types"`
+	Issues []Issue                  `json:"issues"`
+}
+
+// Errors counts the issues of error severity
+func (r *Result) Errors() int {
+	n := 0
+	for _, issue := range r.Issues {
+		if issue.Severity == SeverityError {
+			n++
+		}
+	}
+	return n
+}
+
+// Warnings counts the issues of warning severity
+func (r *Result) Warnings() int {
+	return len(r.Issues) - r.Errors()
+}
+
+// Lint checks every line of an NDJSON stream. Blank lines are skipped; the
+// error is only set when the stream itself cannot be read.
+func Lint(r io.Reader) (*Result, error) {
+	res := &Result{Types: make(map[models.EventType]int), Issues: []Issue{}}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	for line := 1; scanner.Scan(); line++ {
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		res.Events++
+		typ, issues := CheckEvent(raw)
+		if typ != "" {
+			res.Types[typ]++
+		}
+		for _, issue := range issues {
+			issue.Line = line
+			res.Issues = append(res.Issues, issue)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return res, fmt.Errorf("read events: %w", err)
+	}
+	return res, nil
+}
+
+// strictEvent decodes like POST /api/v2/ingest: no lenient coercion and no
+// unknown fields
+type strictEvent models.RawEvent
+
+// CheckEvent checks one JSON event and returns its type and the issues
+// found, without line numbers
+func CheckEvent(raw []byte) (models.EventType, []Issue) {
+	if raw[0] != '{' {
+		return "", []Issue{{Severity: SeverityError,
+			Message: "not a JSON object; send one JSON event per line (URL-encoded lines are only accepted by the legacy v1 endpoint)"}}
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return "", []Issue{{Severity: SeverityError, Message: "invalid JSON: " + strings.TrimPrefix(err.Error(), "json: ")}}
+	}
+
+	var issues []Issue
+	var event models.RawEvent
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode((*strictEvent)(&event)); err != nil {
+		// The lenient decoder v1 uses coerces quoted numbers and drops
+		// unknown fields, so the event still gets through there
+		event = models.RawEvent{}
+		if lenientErr := json.Unmarshal(raw, &event); lenientErr != nil {
+			return "", []Issue{{Severity: SeverityError, Message: "invalid event: " + strings.TrimPrefix(lenientErr.Error(), "json: ")}}
+		}
+		issues = append(issues, strictIssue(err))
+	}
+
+	if event.Type == "" {
+		return "", append(issues, Issue{Severity: SeverityError, Field: "type", Message: "type is required"})
+	}
+	for i := range issues {
+		issues[i].Type = event.Type
+	}
+	spec, ok := Registry[event.Type]
+	if !ok {
+		return event.Type, append(issues, Issue{Severity: SeverityError, Type: event.Type, Field: "type",
+			Message: fmt.Sprintf("unknown event type %q; it would be stored but no stat reads it", event.Type)})
+	}
+	if spec.Internal {
+		return event.Type, append(issues, Issue{Severity: SeverityError, Type: event.Type, Field: "type",
+			Message: "this type is produced by the API from other events; scripts must not send it"})
+	}
+
+	add := func(severity Severity, field, format string, args ...any) {
+		issues = append(issues, Issue{Severity: severity, Type: event.Type, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if event.ServerToken != "" {
+		add(SeverityError, "server_token", "send the token in the X-Server-Token header, never in the event; v2 rejects the event and the token ends up in logs")
+	}
+	for _, field := range spec.Required {
+		if isEmpty(fields[field]) {
+			add(SeverityError, field, "required for %s events", event.Type)
+		}
+	}
+	if spec.isGameplay() && isEmpty(fields["match_id"]) {
+		add(SeverityWarning, "match_id", "missing; the event is not tied to a match and is left out of match stats")
+	}
+
+	checkValues(&event, add)
+	return event.Type, issues
+}
+
+// isGameplay reports whether events of this spec are a player's doing,
+// which only count towards stats inside a match
+func (s Spec) isGameplay() bool {
+	for _, field := range s.Required {
+		if strings.HasSuffix(field, "_guid") {
+			return true
+		}
+	}
+	return false
+}
+
+// strictIssue turns a strict decode error into the warning for it
+func strictIssue(err error) Issue {
+	msg := strings.TrimPrefix(err.Error(), "json: ")
+	issue := Issue{Severity: SeverityWarning}
+	if name, ok := strings.CutPrefix(msg, "unknown field "); ok {
+		issue.Field = strings.Trim(name, `"`)
+		issue.Message = "unknown field; v1 ignores it but /api/v2/ingest rejects the event"
+		return issue
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		issue.Field = typeErr.Field
+		issue.Message = fmt.Sprintf("%s where %s is expected; v1 coerces it but /api/v2/ingest rejects the event", typeErr.Value, typeErr.Type)
+		return issue
+	}
+	issue.Message = msg + "; /api/v2/ingest rejects the event"
+	return issue
+}
+
+// isEmpty reports whether a JSON value is absent, null, "" or 0
+func isEmpty(v json.RawMessage) bool {
+	switch string(v) {
+	case "", "null", `""`, "0":
+		return true
+	}
+	return false
+}
+
+// knownTeams are the team names the game sends
+var knownTeams = map[string]bool{
+	"": true, "allies": true, "axis": true, "spectator": true, "freeforall": true,
+}
+
+// checkValues reports fields outside the ranges the game can produce
+func checkValues(e *models.RawEvent, add func(Severity, string, string, ...any)) {
+	switch {
+	case e.Timestamp < 0:
+		add(SeverityError, "timestamp", "negative timestamp %g", e.Timestamp)
+	case e.Timestamp > float64(time.Now().Add(24*time.Hour).Unix()):
+		add(SeverityWarning, "timestamp", "%g is in the future; send Unix seconds or level.time", e.Timestamp)
+	}
+
+	nonNegative := map[string]float64{
+		"damage":       e.Damage,
+		"allies_score": float64(e.AlliesScore),
+		"axis_score":   float64(e.AxisScore),
+		"round_number": float64(e.RoundNumber),
+		"player_count": float64(e.PlayerCount),
+		"shots_fired":  float64(e.ShotsFired),
+		"shots_hit":    float64(e.ShotsHit),
+		"distance":     float64(e.Distance),
+		"duration":     e.Duration,
+	}
+	for _, field := range sortedKeys(nonNegative) {
+		if nonNegative[field] < 0 {
+			add(SeverityError, field, "must not be negative, got %g", nonNegative[field])
+		}
+	}
+
+	pitches := map[string]float32{"aim_pitch": e.AimPitch, "attacker_pitch": e.AttackerPitch, "victim_pitch": e.VictimPitch}
+	for _, field := range sortedKeys(pitches) {
+		if math.Abs(float64(pitches[field])) > 90 {
+			add(SeverityWarning, field, "%g is outside -90..90 degrees", pitches[field])
+		}
+	}
+	yaws := map[string]float32{"aim_yaw": e.AimYaw, "attacker_yaw": e.AttackerYaw, "victim_yaw": e.VictimYaw}
+	for _, field := range sortedKeys(yaws) {
+		if math.Abs(float64(yaws[field])) > 360 {
+			add(SeverityWarning, field, "%g is outside -360..360 degrees", yaws[field])
+		}
+	}
+
+	if e.ShotsHit > e.ShotsFired {
+		add(SeverityError, "shots_hit", "%d hits from %d shots fired", e.ShotsHit, e.ShotsFired)
+	}
+	if e.Accuracy < 0 || e.Accuracy > 100 {
+		add(SeverityError, "accuracy", "must be a percentage, got %g", e.Accuracy)
+	}
+	if e.Progress < 0 || e.Progress > 100 {
+		add(SeverityError, "progress", "must be a percentage, got %d", e.Progress)
+	}
+
+	teams := map[string]string{
+		"player_team": e.PlayerTeam, "attacker_team": e.AttackerTeam, "victim_team": e.VictimTeam,
+		"team": e.Team, "old_team": e.OldTeam, "new_team": e.NewTeam,
+	}
+	for _, field := range sortedKeys(teams) {
+		if !knownTeams[strings.ToLower(teams[field])] {
+			add(SeverityWarning, field, "unknown team %q; expected allies, axis, spectator or freeforall", teams[field])
+		}
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,91 @@
+package eventlint
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestRegistryCoversGeneratedTypes(t *testing.T) {
+	// The generated constants are the API's event types; each needs a contract
+	file, err := parser.ParseFile(token.NewFileSet(), "../models/event_types_generated.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := 0
+	ast.Inspect(file, func(n ast.Node) bool {
+		spec, ok := n.(*ast.ValueSpec)
+		if !ok || len(spec.Values) != 1 {
+			return true
+		}
+		lit, ok := spec.Values[0].(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return true
+		}
+		typ, _ := strconv.Unquote(lit.Value)
+		seen++
+		if _, ok := Registry[models.EventType(typ)]; !ok {
+			t.Errorf("%s (%q) has no entry in Registry", spec.Names[0].Name, typ)
+		}
+		return true
+	})
+	if seen != len(Registry) {
+		t.Errorf("Registry has %d types, the generated file %d", len(Registry), seen)
+	}
+}
+
+func TestLint(t *testing.T) {
+	input := strings.Join([]string{
+		`{"type":"match_start","match_id":"m1","map_name":"obj/obj_team2","timestamp":1700000000}`,
+		``,
+		`{"type":"player_kill","match_id":"m1","attacker_guid":"a","victim_guid":"v","weapon":"kar98","attacker_team":"allies"}`,
+		`{"type":"player_kill","match_id":"m1","attacker_guid":"a","timestamp":"12.5"}`,
+		`{"type":"weapon_fire","player_guid":"p","weapon":"mp40","aim_pitch":120,"bogus":1}`,
+		`type=player_kill&attacker_guid=a`,
+		`{"type":"match_outcome","match_id":"m1"}`,
+		`{"type":"player_jetpack","match_id":"m1"}`,
+		`{"match_id":"m1"}`,
+		`{"type":"accuracy_summary","match_id":"m1","player_guid":"p","shots_fired":3,"shots_hit":5,"damage":-1}`,
+		`{"type":"heartbeat","server_token":"secret"}`,
+		`{"type":"chat"`,
+	}, "\n")
+
+	res, err := Lint(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Events != 11 || res.Types[models.EventPlayerKill] != 2 {
+		t.Errorf("events = %d, types = %v", res.Events, res.Types)
+	}
+
+	var got []string
+	for _, issue := range res.Issues {
+		got = append(got, issue.String())
+	}
+	want := []string{
+		`4: warning: player_kill: timestamp: string where float64 is expected; v1 coerces it but /api/v2/ingest rejects the event`,
+		`4: error: player_kill: victim_guid: required for player_kill events`,
+		`5: warning: weapon_fire: bogus: unknown field; v1 ignores it but /api/v2/ingest rejects the event`,
+		`5: warning: weapon_fire: match_id: missing; the event is not tied to a match and is left out of match stats`,
+		`5: warning: weapon_fire: aim_pitch: 120 is outside -90..90 degrees`,
+		`6: error: not a JSON object; send one JSON event per line (URL-encoded lines are only accepted by the legacy v1 endpoint)`,
+		`7: error: match_outcome: type: this type is produced by the API from other events; scripts must not send it`,
+		`8: error: player_jetpack: type: unknown event type "player_jetpack"; it would be stored but no stat reads it`,
+		`9: error: type: type is required`,
+		`10: error: accuracy_summary: damage: must not be negative, got -1`,
+		`10: error: accuracy_summary: shots_hit: 5 hits from 3 shots fired`,
+		`11: error: heartbeat: server_token: send the token in the X-Server-Token header, never in the event; v2 rejects the event and the token ends up in logs`,
+		`12: error: invalid JSON: unexpected end of JSON input`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("issues:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if res.Errors() != 9 || res.Warnings() != 4 {
+		t.Errorf("errors = %d, warnings = %d", res.Errors(), res.Warnings())
+	}
+}
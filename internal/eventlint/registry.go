@@ -0,0 +1,140 @@
+package eventlint
+
+import "github.com/openmohaa/stats-api/internal/models"
+
+// Spec is the contract of one event type: the JSON fields the API reads it by
+type Spec struct {
+	// Required fields must be present and non-empty, or the event is stored
+	// without the player or match it belongs to
+	Required []string
+	// Internal types are produced by the API itself; scripts must not send them
+	Internal bool
+}
+
+var (
+	server   = Spec{}
+	match    = Spec{Required: []string{"match_id"}}
+	player   = Spec{Required: []string{"player_guid"}}
+	kill     = Spec{Required: []string{"attacker_guid", "victim_guid"}}
+	attacker = Spec{Required: []string{"attacker_guid"}}
+	victim   = Spec{Required: []string{"victim_guid"}}
+	weapon   = Spec{Required: []string{"player_guid", "weapon"}}
+	internal = Spec{Internal: true}
+)
+
+// Registry maps every event type the API knows to its contract. The actor
+// fields follow how the worker pool reads each type: kills and damage by
+// attacker and victim, everything else by player.
+var Registry = map[models.EventType]Spec{
+	models.EventGameInit:          server,
+	models.EventGameStart:         server,
+	models.EventGameEnd:           server,
+	models.EventMatchStart:        {Required: []string{"match_id", "map_name"}},
+	models.EventMatchEnd:          match,
+	models.EventRoundStart:        match,
+	models.EventRoundEnd:          match,
+	models.EventWarmupStart:       server,
+	models.EventWarmupEnd:         server,
+	models.EventIntermissionStart: server,
+
+	models.EventPlayerKill:        kill,
+	models.EventDeath:             player,
+	models.EventDamage:            {Required: []string{"victim_guid", "damage"}},
+	models.EventPlayerPain:        {Required: []string{"victim_guid", "damage"}},
+	models.EventPlayerSuicide:     attacker,
+	models.EventPlayerCrushed:     victim,
+	models.EventPlayerTelefragged: kill,
+	models.EventPlayerRoadkill:    kill,
+	models.EventPlayerBash:        kill,
+	models.EventPlayerTeamkill:    kill,
+
+	models.EventWeaponFire:       weapon,
+	models.EventWeaponHit:        {Required: []string{"player_guid", "target_guid"}},
+	models.EventWeaponChange:     player,
+	models.EventReload:           weapon,
+	models.EventWeaponReloadDone: player,
+	models.EventWeaponReady:      player,
+	models.EventWeaponNoAmmo:     player,
+	models.EventWeaponHolster:    player,
+	models.EventWeaponRaise:      player,
+	models.EventWeaponDrop:       player,
+	models.EventGrenadeThrow:     player,
+	models.EventGrenadeExplode:   player,
+
+	models.EventJump:                  player,
+	models.EventLand:                  player,
+	models.EventCrouch:                player,
+	models.EventProne:                 player,
+	models.EventPlayerStand:           player,
+	models.EventPlayerSpawn:           player,
+	models.EventPlayerRespawn:         player,
+	models.EventDistance:              player,
+	models.EventPlayerMovement:        player,
+	models.EventLadderMount:           player,
+	models.EventLadderDismount:        player,
+	models.EventUse:                   player,
+	models.EventPlayerUseObjectStart:  player,
+	models.EventPlayerUseObjectFinish: player,
+	models.EventPlayerSpectate:        player,
+	models.EventPlayerFreeze:          player,
+	models.EventSpectatorJoin:         {Required: []string{"match_id", "player_guid"}},
+	models.EventSpectatorLeave:        {Required: []string{"match_id", "player_guid"}},
+	models.EventChat:                  {Required: []string{"player_guid", "message"}},
+
+	models.EventItemPickup:   player,
+	models.EventItemDrop:     player,
+	models.EventItemRespawn:  server,
+	models.EventHealthPickup: player,
+	models.EventAmmoPickup:   player,
+	models.EventArmorPickup:  player,
+
+	models.EventVehicleEnter:  player,
+	models.EventVehicleExit:   player,
+	models.EventVehicleCrash:  player,
+	models.EventVehicleChange: player,
+	models.EventTurretEnter:   player,
+	models.EventTurretExit:    player,
+
+	models.EventServerConsoleCommand: {Required: []string{"command"}},
+	models.EventHeartbeat:            server,
+	models.EventMapInit:              server,
+	models.EventMapStart:             server,
+	models.EventMapReady:             server,
+	models.EventMapShutdown:          server,
+	models.EventMapLoadStart:         server,
+	models.EventMapLoadEnd:           server,
+	models.EventMapChangeStart:       server,
+	models.EventMapRestart:           server,
+
+	models.EventTeamJoin:              player,
+	models.EventTeamWin:               match,
+	models.EventVoteStart:             server,
+	models.EventVotePassed:            server,
+	models.EventVoteFailed:            server,
+	models.EventConnect:               player,
+	models.EventDisconnect:            player,
+	models.EventClientBegin:           player,
+	models.EventClientUserinfoChanged: player,
+	models.EventPlayerInactivityDrop:  player,
+
+	models.EventDoorOpen:    server,
+	models.EventDoorClose:   server,
+	models.EventExplosion:   server,
+	models.EventActorSpawn:  server,
+	models.EventActorKilled: server,
+	models.EventBotSpawn:    server,
+	models.EventBotKilled:   attacker,
+	models.EventBotRoam:     server,
+	models.EventBotCurious:  server,
+	models.EventBotAttack:   server,
+
+	models.EventObjectiveUpdate:  server,
+	models.EventObjectiveCapture: player,
+	models.EventScoreChange:      player,
+	models.EventTeamkillKick:     player,
+	models.EventPlayerAuth:       player,
+	models.EventAccuracySummary:  player,
+
+	models.EventMatchOutcome: internal,
+	models.EventRoundOutcome: internal,
+}
@@ -0,0 +1,132 @@
+package grpcapi
+
+import (
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/models"
+	statsv1 "github.com/openmohaa/stats-api/proto/stats/v1"
+)
+
+// Conversions from the REST responses to the generated messages
+
+func playerMessage(p *models.PlayerStats) *statsv1.Player {
+	name := p.Name
+	if name == "" {
+		name = p.PlayerName
+	}
+	msg := &statsv1.Player{
+		Guid:            p.GUID,
+		Name:            name,
+		Kills:           p.Kills,
+		Deaths:          p.Deaths,
+		KdRatio:         p.KDRatio,
+		Headshots:       p.Headshots,
+		Accuracy:        p.Accuracy,
+		MatchesPlayed:   p.MatchesPlayed,
+		MatchesWon:      p.MatchesWon,
+		WinRate:         p.WinRate,
+		PlaytimeSeconds: p.PlaytimeSeconds,
+	}
+	if p.Rating != nil {
+		msg.Rating = p.Rating.Rating
+	}
+	return msg
+}
+
+// matchDetails is the REST match response, as far as Match carries it
+type matchDetails struct {
+	MatchID string `json:"match_id"`
+	Summary struct {
+		MapName       string    `json:"map_name"`
+		StartedAt     time.Time `json:"started_at"`
+		EndedAt       time.Time `json:"ended_at"`
+		TotalKills    uint64    `json:"total_kills"`
+		UniquePlayers uint64    `json:"unique_players"`
+	} `json:"summary"`
+	Scoreboard []struct {
+		PlayerID   string `json:"player_id"`
+		PlayerName string `json:"player_name"`
+		Kills      uint64 `json:"kills"`
+		Deaths     uint64 `json:"deaths"`
+		Headshots  uint64 `json:"headshots"`
+	} `json:"scoreboard"`
+	State models.MatchState `json:"state"`
+}
+
+func matchMessage(d *matchDetails) *statsv1.Match {
+	msg := &statsv1.Match{
+		MatchId:       d.MatchID,
+		MapName:       d.Summary.MapName,
+		StartedAt:     unixOrZero(d.Summary.StartedAt),
+		EndedAt:       unixOrZero(d.Summary.EndedAt),
+		TotalKills:    d.Summary.TotalKills,
+		UniquePlayers: d.Summary.UniquePlayers,
+		State:         string(d.State),
+	}
+	for _, p := range d.Scoreboard {
+		msg.Scoreboard = append(msg.Scoreboard, &statsv1.MatchPlayer{
+			PlayerId:   p.PlayerID,
+			PlayerName: p.PlayerName,
+			Kills:      p.Kills,
+			Deaths:     p.Deaths,
+			Headshots:  p.Headshots,
+		})
+	}
+	return msg
+}
+
+// unixOrZero is t in Unix seconds, or 0 for the zero time
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+// leaderboard is the REST leaderboard response
+type leaderboard struct {
+	Players  []models.LeaderboardEntry `json:"players"`
+	Total    int64                     `json:"total"`
+	Page     int32                     `json:"page"`
+	Stat     string                    `json:"stat"`
+	StatName string                    `json:"stat_name"`
+}
+
+func leaderboardMessage(l *leaderboard) *statsv1.Leaderboard {
+	msg := &statsv1.Leaderboard{
+		Stat:     l.Stat,
+		StatName: l.StatName,
+		Page:     l.Page,
+		Total:    l.Total,
+	}
+	for _, e := range l.Players {
+		// Value decodes from JSON as a number, whatever type the stat has
+		value, _ := e.Value.(float64)
+		msg.Players = append(msg.Players, &statsv1.LeaderboardEntry{
+			Rank:       int32(e.Rank),
+			PlayerId:   e.PlayerID,
+			PlayerName: e.PlayerName,
+			Value:      value,
+			Kills:      e.Kills,
+			Deaths:     e.Deaths,
+			Headshots:  e.Headshots,
+			Accuracy:   e.Accuracy,
+			Wins:       e.Wins,
+		})
+	}
+	return msg
+}
+
+func ingestResponseMessage(r *models.IngestResponse) *statsv1.IngestResponse {
+	msg := &statsv1.IngestResponse{
+		Accepted: int32(r.Accepted),
+		Dropped:  int32(r.Dropped),
+	}
+	for _, rej := range r.Rejected {
+		msg.Rejected = append(msg.Rejected, &statsv1.IngestRejection{
+			Index: int32(rej.Index),
+			Error: rej.Error,
+		})
+	}
+	return msg
+}
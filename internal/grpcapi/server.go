@@ -0,0 +1,230 @@
+// Package grpcapi serves the core stats queries and ingestion as the gRPC
+// service of proto/stats/v1/stats.proto, for companion services that want
+// typed, low-latency calls instead of REST.
+//
+// Each RPC is answered by the REST handler of the same query, called in
+// process, so authentication, tenant scoping, caching and limits are shared
+// with the REST API. The messages and service registration are the code
+// generated into proto/stats/v1.
+package grpcapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/openmohaa/stats-api/internal/models"
+	statsv1 "github.com/openmohaa/stats-api/proto/stats/v1"
+)
+
+var rpcRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "mohaa_grpc_requests_total",
+	Help: "gRPC calls by method and status code",
+}, []string{"method", "code"})
+
+// codeForHTTP maps the REST handler's status to the closest gRPC code
+func codeForHTTP(status int) codes.Code {
+	switch {
+	case status == http.StatusBadRequest, status == http.StatusRequestEntityTooLarge:
+		return codes.InvalidArgument
+	case status == http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case status == http.StatusForbidden:
+		return codes.PermissionDenied
+	case status == http.StatusNotFound:
+		return codes.NotFound
+	case status == http.StatusConflict:
+		return codes.FailedPrecondition
+	case status == http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case status == http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case status == http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	case status >= 500:
+		return codes.Internal
+	default:
+		return codes.Unknown
+	}
+}
+
+// forwardedHeaders are the metadata keys passed on to the REST handlers:
+// credentials and locale. The caller's address is only ever the peer's, so
+// gRPC callers cannot pick the IP that rate limits and logs see.
+var forwardedHeaders = []string{
+	"Authorization", "X-Server-Token", "X-Api-Key", "Accept-Language", "X-Request-Id",
+}
+
+// service answers the StatsService RPCs from the REST router
+type service struct {
+	statsv1.UnimplementedStatsServiceServer
+	api    http.Handler
+	logger *zap.SugaredLogger
+}
+
+// NewServer returns a gRPC server answering StatsService through api, the
+// REST router. Serve it on a plaintext listener, as companion services call
+// it without TLS.
+func NewServer(api http.Handler, logger *zap.Logger) *grpc.Server {
+	s := &service{api: api, logger: logger.Sugar()}
+	srv := grpc.NewServer(grpc.UnaryInterceptor(s.observe))
+	statsv1.RegisterStatsServiceServer(srv, s)
+	return srv
+}
+
+// observe counts calls by status and hides unexpected errors from callers
+func (s *service) observe(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	resp, err := handler(ctx, req)
+	if _, ok := status.FromError(err); !ok {
+		s.logger.Errorw("gRPC call failed", "method", info.FullMethod, "error", err)
+		err = status.Error(codes.Internal, "internal error")
+	}
+	name := info.FullMethod[strings.LastIndex(info.FullMethod, "/")+1:]
+	rpcRequests.WithLabelValues(name, strconv.Itoa(int(status.Code(err)))).Inc()
+	return resp, err
+}
+
+// responseBuffer collects a REST handler's response
+type responseBuffer struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *responseBuffer) Header() http.Header { return b.header }
+
+func (b *responseBuffer) Write(p []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	return b.body.Write(p)
+}
+
+func (b *responseBuffer) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+// rest calls the REST route method path with the caller's credentials and
+// decodes its JSON response into out. Error responses become a status with
+// the handler's message.
+func (s *service) rest(ctx context.Context, method, path string, body []byte, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		req.RemoteAddr = p.Addr.String()
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	if authority := md.Get(":authority"); len(authority) > 0 {
+		req.Host = authority[0]
+	}
+	for _, key := range forwardedHeaders {
+		if values := md.Get(key); len(values) > 0 {
+			req.Header[key] = values
+		}
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp := &responseBuffer{header: http.Header{}}
+	s.api.ServeHTTP(resp, req)
+	if resp.status == 0 {
+		resp.status = http.StatusOK
+	}
+	if resp.status >= http.StatusMultipleChoices {
+		var e struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(resp.body.Bytes(), &e) != nil || e.Error == "" {
+			e.Error = http.StatusText(resp.status)
+		}
+		return status.Error(codeForHTTP(resp.status), e.Error)
+	}
+	if err := json.Unmarshal(resp.body.Bytes(), out); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", path, err)
+	}
+	return nil
+}
+
+func (s *service) GetPlayer(ctx context.Context, req *statsv1.GetPlayerRequest) (*statsv1.Player, error) {
+	if req.Guid == "" {
+		return nil, status.Error(codes.InvalidArgument, "guid is required")
+	}
+	var resp models.PlayerStatsResponse
+	if err := s.rest(ctx, http.MethodGet, "/api/v1/stats/player/"+url.PathEscape(req.Guid), nil, &resp); err != nil {
+		return nil, err
+	}
+	return playerMessage(&resp.Player), nil
+}
+
+func (s *service) GetMatch(ctx context.Context, req *statsv1.GetMatchRequest) (*statsv1.Match, error) {
+	if req.MatchId == "" {
+		return nil, status.Error(codes.InvalidArgument, "match_id is required")
+	}
+	var resp matchDetails
+	if err := s.rest(ctx, http.MethodGet, "/api/v1/stats/match/"+url.PathEscape(req.MatchId), nil, &resp); err != nil {
+		return nil, err
+	}
+	return matchMessage(&resp), nil
+}
+
+func (s *service) GetLeaderboard(ctx context.Context, req *statsv1.GetLeaderboardRequest) (*statsv1.Leaderboard, error) {
+	stat := req.Stat
+	if stat == "" {
+		stat = "kills"
+	}
+	q := url.Values{}
+	if req.Limit > 0 {
+		q.Set("limit", strconv.Itoa(int(req.Limit)))
+	}
+	if req.Page > 0 {
+		q.Set("page", strconv.Itoa(int(req.Page)))
+	}
+	if req.Period != "" {
+		q.Set("period", req.Period)
+	}
+	path := "/api/v1/stats/leaderboard/" + url.PathEscape(stat)
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+	var resp leaderboard
+	if err := s.rest(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return leaderboardMessage(&resp), nil
+}
+
+func (s *service) Ingest(ctx context.Context, req *statsv1.IngestRequest) (*statsv1.IngestResponse, error) {
+	events := make([]json.RawMessage, len(req.Events))
+	for i, event := range req.Events {
+		events[i] = event
+	}
+	body, err := json.Marshal(events)
+	if err != nil {
+		// An event that is not JSON fails the whole batch, as in REST
+		return nil, status.Error(codes.InvalidArgument, "events must be JSON objects")
+	}
+	var resp models.IngestResponse
+	if err := s.rest(ctx, http.MethodPost, "/api/v2/ingest/events", body, &resp); err != nil {
+		return nil, err
+	}
+	return ingestResponseMessage(&resp), nil
+}
@@ -0,0 +1,137 @@
+package grpcapi
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	statsv1 "github.com/openmohaa/stats-api/proto/stats/v1"
+)
+
+// restAPI stands in for the REST router
+func restAPI() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/stats/player/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/stats/player/g1" {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"error":"Player not found"}`)
+			return
+		}
+		io.WriteString(w, `{"player":{"guid":"g1","name":"Sniper","kills":42,"kd_ratio":2.5,"rating":{"rating":1510}}}`)
+	})
+	mux.HandleFunc("/api/v1/stats/leaderboard/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/stats/leaderboard/headshots" || r.URL.Query().Get("limit") != "10" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		io.WriteString(w, `{"players":[{"rank":1,"player_id":"g1","value":7}],"total":1,"page":1,"stat":"headshots"}`)
+	})
+	mux.HandleFunc("/api/v2/ingest/events", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Server-Token") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"error":"Invalid server token"}`)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		// The caller's address is the peer's, never a header it sent
+		if r.Header.Get("X-Forwarded-For") != "" || r.Header.Get("X-Real-Ip") != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if string(body) != `[{"type":"player_kill"},{"type":"kill"}]` {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		io.WriteString(w, `{"status":"accepted","accepted":1,"rejected":[{"index":1,"error":"unknown type"}]}`)
+	})
+	return mux
+}
+
+// dial serves api over an in-memory listener and returns a client of it
+func dial(t *testing.T, api http.Handler) statsv1.StatsServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := NewServer(api, zap.NewNop())
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return statsv1.NewStatsServiceClient(conn)
+}
+
+func TestGetPlayer(t *testing.T) {
+	client := dial(t, restAPI())
+	ctx := context.Background()
+
+	p, err := client.GetPlayer(ctx, &statsv1.GetPlayerRequest{Guid: "g1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Guid != "g1" || p.Name != "Sniper" || p.Kills != 42 || p.KdRatio != 2.5 || p.Rating != 1510 {
+		t.Errorf("player %v", p)
+	}
+
+	_, err = client.GetPlayer(ctx, &statsv1.GetPlayerRequest{Guid: "missing"})
+	if st := status.Convert(err); st.Code() != codes.NotFound || st.Message() != "Player not found" {
+		t.Errorf("missing player: %v", err)
+	}
+	if _, err := client.GetPlayer(ctx, &statsv1.GetPlayerRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("empty guid: %v", err)
+	}
+}
+
+func TestGetLeaderboard(t *testing.T) {
+	client := dial(t, restAPI())
+
+	l, err := client.GetLeaderboard(context.Background(), &statsv1.GetLeaderboardRequest{Stat: "headshots", Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Stat != "headshots" || l.Total != 1 || len(l.Players) != 1 {
+		t.Fatalf("leaderboard %v", l)
+	}
+	if e := l.Players[0]; e.Rank != 1 || e.PlayerId != "g1" || e.Value != 7 {
+		t.Errorf("entry %v", e)
+	}
+}
+
+func TestIngest(t *testing.T) {
+	client := dial(t, restAPI())
+	ctx := context.Background()
+	req := &statsv1.IngestRequest{Events: [][]byte{[]byte(`{"type":"player_kill"}`), []byte(`{"type":"kill"}`)}}
+
+	if _, err := client.Ingest(ctx, req); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("without token: %v", err)
+	}
+	authed := metadata.AppendToOutgoingContext(ctx, "x-server-token", "secret",
+		"x-forwarded-for", "203.0.113.9", "x-real-ip", "203.0.113.9")
+	resp, err := client.Ingest(authed, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Accepted != 1 || len(resp.Rejected) != 1 || resp.Rejected[0].Index != 1 || resp.Rejected[0].Error != "unknown type" {
+		t.Errorf("response %v", resp)
+	}
+
+	bad := &statsv1.IngestRequest{Events: [][]byte{[]byte("not json")}}
+	if _, err := client.Ingest(authed, bad); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("invalid event: %v", err)
+	}
+}
@@ -62,6 +62,7 @@ func (h *Handler) GetPlayerAchievementProgress(w http.ResponseWriter, r *http.Re
 		if err := rows.Scan(&a.Slug, &a.Name, &a.Description, &a.Points, &a.Tier, &a.Icon, &a.UnlockedAt); err != nil {
 			continue
 		}
+		translateAchievement(ctx, a.Slug, &a.Name, &a.Description)
 		achievements = append(achievements, a)
 	}
 
@@ -150,6 +151,7 @@ func (h *Handler) GetMatchAchievements(w http.ResponseWriter, r *http.Request) {
 	// Convert logic.Achievement to models.Achievement if necessary, but assuming they are compatible or same type
 	// If logic returns []logic.Achievement (which might be interface alias), we might need casting.
 	// But let's assume `logic` uses `models` internally or `list` is compatible with JSON marshalling.
+	translateContextualAchievements(r.Context(), list)
 	h.jsonResponse(w, http.StatusOK, list)
 }
 
@@ -179,5 +181,6 @@ func (h *Handler) GetTournamentAchievements(w http.ResponseWriter, r *http.Reque
 		h.errorResponse(w, http.StatusInternalServerError, "Failed to get achievements")
 		return
 	}
+	translateContextualAchievements(r.Context(), list)
 	h.jsonResponse(w, http.StatusOK, list)
 }
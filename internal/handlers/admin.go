@@ -0,0 +1,755 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/openmohaa/stats-api/internal/logging"
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+	"github.com/openmohaa/stats-api/internal/worker"
+)
+
+// CheckAggregates compares a sample of player aggregates against raw_events
+// @Summary Check Aggregate Consistency
+// @Description Recomputes kills/deaths/headshots for a random sample of players from raw_events and reports rows that drift from the materialized aggregate
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Param table query string false "Aggregate table (player_stats_daily, player_server_stats_daily)" default(player_stats_daily)
+// @Param days query int false "Closed days to check" default(7)
+// @Param sample query int false "Players to sample" default(200)
+// @Param tolerance query number false "Allowed relative drift (0.01 = 1%)" default(0.01)
+// @Success 200 {object} models.AggregateCheckReport
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/aggregates/check [get]
+func (h *Handler) CheckAggregates(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	table := q.Get("table")
+	if table == "" {
+		table = "player_stats_daily"
+	}
+	if !logic.IsPlayerAggregateTable(table) {
+		h.errorResponse(w, http.StatusBadRequest, "Unknown aggregate table")
+		return
+	}
+
+	days := 7
+	if d, err := strconv.Atoi(q.Get("days")); err == nil && d > 0 && d <= 90 {
+		days = d
+	}
+	sample := 200
+	if s, err := strconv.Atoi(q.Get("sample")); err == nil && s > 0 && s <= 5000 {
+		sample = s
+	}
+	tolerance := 0.01
+	if t, err := strconv.ParseFloat(q.Get("tolerance"), 64); err == nil && t >= 0 {
+		tolerance = t
+	}
+
+	report, err := h.aggregates.CheckConsistency(r.Context(), table, days, sample, tolerance)
+	if err != nil {
+		h.logger.Errorw("Failed to check aggregates", "table", table, "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Aggregate check failed")
+		return
+	}
+
+	h.jsonResponse(w, http.StatusOK, report)
+}
+
+// RebuildAggregates truncates an aggregate table and replays its views over raw_events
+// @Summary Rebuild Aggregate Table
+// @Description Truncates the table and repopulates it from raw_events using the deployed materialized view definitions. Pause ingest while this runs.
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Param table query string false "Aggregate table (player_stats_daily, player_server_stats_daily)" default(player_stats_daily)
+// @Success 200 {object} models.AggregateRebuildResult
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/aggregates/rebuild [post]
+func (h *Handler) RebuildAggregates(w http.ResponseWriter, r *http.Request) {
+	table := r.URL.Query().Get("table")
+	if table == "" {
+		table = "player_stats_daily"
+	}
+	if !logic.IsPlayerAggregateTable(table) {
+		h.errorResponse(w, http.StatusBadRequest, "Unknown aggregate table")
+		return
+	}
+
+	h.logger.Warnw("Rebuilding aggregate table", "table", table)
+	result, err := h.aggregates.Rebuild(r.Context(), table)
+	if err != nil {
+		h.logger.Errorw("Failed to rebuild aggregates", "table", table, "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Aggregate rebuild failed")
+		return
+	}
+
+	h.logger.Infow("Aggregate table rebuilt", "table", table, "views", result.Views, "duration", result.Duration)
+	h.jsonResponse(w, http.StatusOK, result)
+}
+
+// GetAnalyticsViews lists the stable ClickHouse views for BI tools
+// @Summary Analytical Views
+// @Description The v_* views meant for Grafana, Metabase and other BI tools connecting to ClickHouse directly, with their grain and documented columns. Column types come from the deployed schema; views whose migration has not run are listed with available=false.
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Success 200 {array} models.AnalyticsView
+// @Failure 500 {object} map[string]string
+// @Router /admin/views [get]
+func (h *Handler) GetAnalyticsViews(w http.ResponseWriter, r *http.Request) {
+	views, err := h.aggregates.AnalyticsViews(r.Context())
+	if err != nil {
+		h.logger.Errorw("Failed to list analytics views", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to list analytics views")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, views)
+}
+
+// RebuildAggregatesTargeted starts a background rebuild of the days touched by
+// the given matches or players
+// @Summary Targeted Aggregate Rebuild
+// @Description Rebuilds only the days on which the listed matches were played or the listed players had events, in every player aggregate table, leaving out voided matches and banned players, then refreshes the leaderboard_global snapshot. Runs in the background; poll GET /admin/aggregates/jobs/{jobId} for progress.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ServerToken
+// @Param body body models.AggregateRebuildRequest true "Matches and players"
+// @Success 202 {object} models.AggregateRebuildJob
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /admin/aggregates/rebuild/targeted [post]
+func (h *Handler) RebuildAggregatesTargeted(w http.ResponseWriter, r *http.Request) {
+	if h.rebuilds == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Targeted rebuilds not enabled")
+		return
+	}
+	var req models.AggregateRebuildRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	job, err := h.rebuilds.Submit(req)
+	if err != nil {
+		h.rebuildError(w, err)
+		return
+	}
+	h.jsonResponse(w, http.StatusAccepted, job)
+}
+
+// GetRebuildJobs lists recent targeted rebuilds
+// @Summary List Aggregate Rebuild Jobs
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Success 200 {array} models.AggregateRebuildJob
+// @Failure 503 {object} map[string]string
+// @Router /admin/aggregates/jobs [get]
+func (h *Handler) GetRebuildJobs(w http.ResponseWriter, r *http.Request) {
+	if h.rebuilds == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Targeted rebuilds not enabled")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, h.rebuilds.Jobs())
+}
+
+// GetRebuildJob returns the progress of a targeted rebuild
+// @Summary Get Aggregate Rebuild Job
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Param jobId path string true "Job ID"
+// @Success 200 {object} models.AggregateRebuildJob
+// @Failure 404 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /admin/aggregates/jobs/{jobId} [get]
+func (h *Handler) GetRebuildJob(w http.ResponseWriter, r *http.Request) {
+	if h.rebuilds == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Targeted rebuilds not enabled")
+		return
+	}
+	job := h.rebuilds.Job(chi.URLParam(r, "jobId"))
+	if job == nil {
+		h.errorResponse(w, http.StatusNotFound, "Rebuild job not found")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, job)
+}
+
+// rebuildError maps AggregateRebuilder.Submit errors to responses
+func (h *Handler) rebuildError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, worker.ErrEmptyRebuild):
+		h.errorResponse(w, http.StatusBadRequest, "No matches or players given")
+	case errors.Is(err, worker.ErrRebuildRunning):
+		h.errorResponse(w, http.StatusConflict, "An aggregate rebuild is already running")
+	default:
+		h.logger.Errorw("Failed to start aggregate rebuild", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to start aggregate rebuild")
+	}
+}
+
+// GetIngestHealth reports per-server ingest lag against heartbeats
+// @Summary Ingest Health
+// @Description Seconds since each active server's newest non-heartbeat event. Servers that still heartbeat but whose lag exceeds the threshold are marked stalled and the endpoint returns 503.
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Param threshold query string false "Stall threshold as a Go duration (e.g. 10m)"
+// @Success 200 {object} models.IngestHealth
+// @Failure 503 {object} models.IngestHealth
+// @Failure 500 {object} map[string]string
+// @Router /admin/ingest/health [get]
+func (h *Handler) GetIngestHealth(w http.ResponseWriter, r *http.Request) {
+	threshold := time.Duration(h.ingestStallThreshold.Load())
+	if t, err := time.ParseDuration(r.URL.Query().Get("threshold")); err == nil && t > 0 {
+		threshold = t
+	}
+	if threshold <= 0 {
+		threshold = 10 * time.Minute
+	}
+
+	health, err := h.getServerTracking().GetIngestHealth(r.Context(), threshold)
+	if err != nil {
+		h.logger.Errorw("Failed to get ingest health", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to get ingest health")
+		return
+	}
+
+	status := http.StatusOK
+	if health.Stalled > 0 {
+		status = http.StatusServiceUnavailable
+	}
+	h.jsonResponse(w, status, health)
+}
+
+// SearchEvents returns stored raw events matching the filters, newest first
+// @Summary Event Explorer
+// @Description Raw events as stored, raw_json included, filtered by server, match, player (actor or target), event types and time range. Without a match or time range the last 24 hours are searched. sandbox=true searches the events of sandbox servers instead; a sandbox server's token only ever sees its own sandbox events.
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Param server_id query string false "Server ID"
+// @Param match_id query string false "Match ID"
+// @Param player query string false "Player GUID, as actor or target"
+// @Param type query string false "Comma-separated event types"
+// @Param from query string false "Start time, RFC 3339 or Unix seconds"
+// @Param to query string false "End time (exclusive), RFC 3339 or Unix seconds"
+// @Param limit query int false "Events per page" default(100)
+// @Param offset query int false "Events to skip"
+// @Param sandbox query bool false "Search sandbox server events"
+// @Success 200 {object} models.EventSearchResult
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/events/search [get]
+func (h *Handler) SearchEvents(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	search := models.EventSearch{
+		ServerID: q.Get("server_id"),
+		MatchID:  q.Get("match_id"),
+		PlayerID: q.Get("player"),
+		Sandbox:  q.Get("sandbox") == "true",
+		Limit:    100,
+	}
+	if logic.SandboxFromContext(r.Context()) {
+		// Mod developers testing with a sandbox token read back their own events
+		search.Sandbox = true
+		search.ServerID, _ = r.Context().Value("server_id").(string)
+	} else if !logic.AdminFromContext(r.Context()) {
+		h.errorResponse(w, http.StatusForbidden, "Admin server token required")
+		return
+	}
+	for _, t := range strings.Split(q.Get("type"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			search.Types = append(search.Types, t)
+		}
+	}
+
+	var err error
+	if search.From, err = parseEventTime(q.Get("from")); err != nil {
+		h.errorResponse(w, http.StatusBadRequest, "Invalid from time")
+		return
+	}
+	if search.To, err = parseEventTime(q.Get("to")); err != nil {
+		h.errorResponse(w, http.StatusBadRequest, "Invalid to time")
+		return
+	}
+	if l, err := strconv.Atoi(q.Get("limit")); err == nil && l > 0 && l <= 1000 {
+		search.Limit = l
+	}
+	if o, err := strconv.Atoi(q.Get("offset")); err == nil && o >= 0 && o <= 100000 {
+		search.Offset = o
+	}
+
+	result, err := h.getServerTracking().SearchEvents(r.Context(), search)
+	if err != nil {
+		h.logger.Errorw("Failed to search events", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Event search failed")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, result)
+}
+
+// parseEventTime reads an RFC 3339 time or Unix seconds; empty is the zero time
+func parseEventTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if secs, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Unix(0, int64(secs*1e9)).UTC(), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// GetSlowQueries returns recent slow ClickHouse calls and per-query timings
+// @Summary Slow Query Log
+// @Description Most recent ClickHouse calls over the slow threshold (with parameters) plus a registry of every named query ordered by total time
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Param limit query int false "Max slow entries" default(50)
+// @Success 200 {object} models.SlowQueryReport
+// @Failure 503 {object} map[string]string
+// @Router /admin/queries/slow [get]
+func (h *Handler) GetSlowQueries(w http.ResponseWriter, r *http.Request) {
+	if h.queryLog == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Query log not enabled")
+		return
+	}
+
+	limit := 50
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 1000 {
+		limit = l
+	}
+
+	h.jsonResponse(w, http.StatusOK, models.SlowQueryReport{
+		ThresholdMs: float64(h.queryLog.Threshold().Milliseconds()),
+		Slow:        h.queryLog.Slow(limit),
+		Registry:    h.queryLog.Registry(),
+	})
+}
+
+// RunSandboxQuery runs a single read-only SELECT over the aggregate tables
+// @Summary Query Sandbox
+// @Description Runs one SELECT (or WITH ... SELECT) against whitelisted aggregate tables with readonly ClickHouse settings, a row cap and a time limit. Disabled unless QUERY_SANDBOX=true; refused for tenant servers.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ServerToken
+// @Param body body models.SandboxQueryRequest true "Query"
+// @Success 200 {object} models.SandboxQueryResult
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /admin/query [post]
+func (h *Handler) RunSandboxQuery(w http.ResponseWriter, r *http.Request) {
+	if h.querySandbox == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Query sandbox not enabled")
+		return
+	}
+	// The sandbox reads across tenants, so it is for the operator's own servers
+	if logic.TenantFromContext(r.Context()) != "" {
+		h.errorResponse(w, http.StatusForbidden, "Query sandbox is not available to tenant servers")
+		return
+	}
+
+	var req models.SandboxQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.errorResponse(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	result, err := h.querySandbox.Run(r.Context(), req.SQL)
+	if err != nil {
+		if errors.Is(err, logic.ErrQueryNotAllowed) {
+			h.errorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.logger.Errorw("Sandbox query failed", "sql", req.SQL, "error", err)
+		h.errorResponse(w, http.StatusBadRequest, "Query failed: "+err.Error())
+		return
+	}
+	h.logger.Infow("Sandbox query", "sql", req.SQL, "rows", result.RowCount, "elapsedMs", result.ElapsedMS)
+	h.jsonResponse(w, http.StatusOK, result)
+}
+
+// ReloadConfig re-reads the configuration without restarting
+// @Summary Reload Configuration
+// @Description Re-reads CONFIG_FILE and applies Redis key TTLs, the live idle TTL, the slow query and ingest stall thresholds, event sample rates, log levels and achievement definitions in place. Other changed settings are listed under restart_required.
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Success 200 {object} config.ReloadResult
+// @Failure 500 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /admin/config/reload [post]
+func (h *Handler) ReloadConfig(w http.ResponseWriter, r *http.Request) {
+	if h.reloader == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Config reload not enabled")
+		return
+	}
+
+	result, err := h.reloader.Reload()
+	if err != nil {
+		h.logger.Errorw("Failed to reload config", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Config reload failed")
+		return
+	}
+
+	h.logger.Infow("Configuration reloaded",
+		"source", result.Source,
+		"changed", result.Changed,
+		"restartRequired", result.RestartRequired,
+		"errors", result.Errors,
+	)
+	h.jsonResponse(w, http.StatusOK, result)
+}
+
+// GetLogging returns the active log levels and sampled components
+// @Summary Get Log Levels
+// @Description Base log level, per-component overrides (api, ingest, worker, ...) and the components whose info/debug logs are sampled
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Success 200 {object} logging.Settings
+// @Failure 503 {object} map[string]string
+// @Router /admin/logging [get]
+func (h *Handler) GetLogging(w http.ResponseWriter, r *http.Request) {
+	if h.logging == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Runtime log levels not enabled")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, h.logging.Settings())
+}
+
+// SetLogging replaces the log levels and sampled components
+// @Summary Set Log Levels
+// @Description Applies immediately to every component. The settings hold until the next config reload, which reapplies LOG_LEVEL, LOG_LEVELS and LOG_SAMPLED.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ServerToken
+// @Param body body logging.Settings true "Levels"
+// @Success 200 {object} logging.Settings
+// @Failure 400 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /admin/logging [put]
+func (h *Handler) SetLogging(w http.ResponseWriter, r *http.Request) {
+	if h.logging == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Runtime log levels not enabled")
+		return
+	}
+
+	var req logging.Settings
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.Level == "" {
+		req.Level = h.logging.Settings().Level
+	}
+	if err := h.logging.Apply(req); err != nil {
+		h.errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	settings := h.logging.Settings()
+	h.logger.Infow("Log levels changed", "level", settings.Level, "components", settings.Components, "sampled", settings.Sampled)
+	h.jsonResponse(w, http.StatusOK, settings)
+}
+
+// GetMatchState returns a match's lifecycle record
+// @Summary Get Match State
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Param matchId path string true "Match ID"
+// @Success 200 {object} models.MatchStateRecord
+// @Failure 404 {object} map[string]string
+// @Router /admin/matches/{matchId}/state [get]
+func (h *Handler) GetMatchState(w http.ResponseWriter, r *http.Request) {
+	if h.matchStates == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Match states not enabled")
+		return
+	}
+	record, err := h.matchStateRecord(r.Context(), chi.URLParam(r, "matchId"))
+	if errors.Is(err, logic.ErrUnknownMatch) {
+		h.errorResponse(w, http.StatusNotFound, "Match not found")
+		return
+	}
+	if err != nil {
+		h.logger.Errorw("Failed to get match state", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to get match state")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, record)
+}
+
+// SetMatchState moves a match to another lifecycle state
+// @Summary Change Match State
+// @Description Finalize, void, reopen (finalized -> ended) or restore (voided -> ended) a match. Finalized tournament matches are locked.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ServerToken
+// @Param matchId path string true "Match ID"
+// @Param body body models.MatchStateChange true "Target state and reason"
+// @Success 200 {object} models.MatchStateRecord
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /admin/matches/{matchId}/state [put]
+func (h *Handler) SetMatchState(w http.ResponseWriter, r *http.Request) {
+	if h.matchStates == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Match states not enabled")
+		return
+	}
+	var req models.MatchStateChange
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if !req.State.Valid() {
+		h.errorResponse(w, http.StatusBadRequest, "Unknown match state")
+		return
+	}
+
+	record, _, err := h.transitionMatch(r, chi.URLParam(r, "matchId"), req.State, req.Reason)
+	if err != nil {
+		h.matchStateError(w, err)
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, record)
+}
+
+// transitionMatch applies an admin state change on behalf of the calling
+// server. Voiding a match also excludes it from aggregate rebuilds and revokes
+// its achievements; leaving voided counts it again. Finalizing a tournament
+// match signs its result.
+func (h *Handler) transitionMatch(r *http.Request, matchID string, to models.MatchState, reason string) (*models.MatchStateRecord, int64, error) {
+	ctx := r.Context()
+	current, err := h.matchStateRecord(ctx, matchID)
+	if err != nil {
+		return nil, 0, err
+	}
+	actor, _ := ctx.Value("server_id").(string)
+	record, err := h.matchStates.Transition(ctx, matchID, to, actor, reason)
+	if err != nil {
+		return nil, 0, err
+	}
+	h.logger.Infow("Match state changed", "match", matchID, "from", current.State, "state", to, "by", actor, "reason", reason)
+
+	var revoked int64
+	if h.matchAdmin != nil {
+		switch {
+		case to == models.MatchStateVoided:
+			revoked, err = h.matchAdmin.Void(ctx, matchID, reason)
+		case current.State == models.MatchStateVoided:
+			err = h.matchAdmin.Restore(ctx, matchID)
+		}
+	}
+	// The result is signed as it is locked; the result endpoint signs it
+	// later if this fails
+	if h.matchResults != nil && record.Locked() {
+		if _, serr := h.matchResults.Sign(ctx, record); serr != nil {
+			h.logger.Errorw("Failed to sign match result", "match", matchID, "tournament", record.TournamentID, "error", serr)
+		}
+	}
+	return record, revoked, err
+}
+
+// matchStateRecord returns a match's lifecycle record. Matches stored before
+// states were tracked get an ended record if ClickHouse has their events.
+func (h *Handler) matchStateRecord(ctx context.Context, matchID string) (*models.MatchStateRecord, error) {
+	record, err := h.matchStates.Get(ctx, matchID)
+	if !errors.Is(err, logic.ErrUnknownMatch) {
+		return record, err
+	}
+
+	var serverID string
+	var events uint64
+	if err := h.ch.QueryRow(ctx, `
+		SELECT any(server_id), count()
+		FROM mohaa_stats.raw_events
+		WHERE match_id = toUUIDOrZero(?)
+	`, matchID).Scan(&serverID, &events); err != nil {
+		return nil, err
+	}
+	if events == 0 {
+		return nil, logic.ErrUnknownMatch
+	}
+	if err := h.matchStates.Adopt(ctx, matchID, serverID); err != nil {
+		return nil, err
+	}
+	return h.matchStates.Get(ctx, matchID)
+}
+
+// matchStateError maps match state errors to responses
+func (h *Handler) matchStateError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, logic.ErrUnknownMatch):
+		h.errorResponse(w, http.StatusNotFound, "Match not found")
+	case errors.Is(err, logic.ErrMatchLocked):
+		h.errorResponse(w, http.StatusConflict, "Match result is finalized")
+	case errors.Is(err, logic.ErrMatchState):
+		h.errorResponse(w, http.StatusConflict, err.Error())
+	default:
+		h.logger.Errorw("Failed to change match state", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to change match state")
+	}
+}
+
+// VoidMatch excludes a match from all aggregates
+// @Summary Void Match
+// @Description Marks the match voided, revokes the achievements unlocked in it and leaves it out of aggregate rebuilds. The days the match was played on are then rebuilt in the background (see rebuild_job). Without targeted rebuilds every player aggregate is rebuilt right away with rebuild=true (pause ingest first); otherwise the aggregates keep counting the match until rebuilt, as note says. Undo with PUT /admin/matches/{matchId}/state {"state":"ended"}.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ServerToken
+// @Param matchId path string true "Match ID"
+// @Param rebuild query bool false "Rebuild every player aggregate now when targeted rebuilds are not enabled"
+// @Param body body models.MatchVoidRequest false "Reason"
+// @Success 200 {object} models.MatchVoidResult
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /admin/matches/{matchId}/void [post]
+func (h *Handler) VoidMatch(w http.ResponseWriter, r *http.Request) {
+	if h.matchStates == nil || h.matchAdmin == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Match administration not enabled")
+		return
+	}
+	var req models.MatchVoidRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	record, revoked, err := h.transitionMatch(r, chi.URLParam(r, "matchId"), models.MatchStateVoided, req.Reason)
+	if err != nil {
+		h.matchStateError(w, err)
+		return
+	}
+	result := &models.MatchVoidResult{Match: record, AchievementsRevoked: revoked}
+
+	switch {
+	case h.rebuilds != nil:
+		result.RebuildJob, err = h.rebuilds.Submit(models.AggregateRebuildRequest{
+			MatchIDs: []string{record.MatchID},
+			Reason:   "void: " + req.Reason,
+		})
+		if err != nil {
+			h.logger.Warnw("Failed to start aggregate rebuild after void", "match", record.MatchID, "error", err)
+			h.errorResponse(w, http.StatusConflict, "Match voided, aggregate rebuild not started: "+err.Error())
+			return
+		}
+	case r.URL.Query().Get("rebuild") == "true":
+		if result.AggregatesRebuilt, err = h.rebuildPlayerAggregates(r); err != nil {
+			h.logger.Errorw("Failed to rebuild aggregates after void", "match", record.MatchID, "error", err)
+			h.errorResponse(w, http.StatusInternalServerError, "Match voided, aggregate rebuild failed")
+			return
+		}
+	default:
+		result.Note = "Aggregates still count the match until they are rebuilt (rebuild=true)"
+	}
+	h.jsonResponse(w, http.StatusOK, result)
+}
+
+// RecomputeMatch rebuilds a match's outcomes and achievements from its events
+// @Summary Recompute Match
+// @Description Derives the match's win/loss outcomes again from its stored events (a correction's winner supersedes the derived one), revokes the achievements unlocked in it and replays the events through the achievement checks (not for voided matches). Live and finalized matches are refused. The aggregates still count the old outcomes, so the days the match was played on are then rebuilt in the background (see rebuild_job). Without targeted rebuilds every player aggregate is rebuilt right away with rebuild=true (pause ingest first); otherwise note says the aggregates are stale.
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Param matchId path string true "Match ID"
+// @Param rebuild query bool false "Rebuild every player aggregate now when targeted rebuilds are not enabled"
+// @Success 200 {object} models.MatchRecomputeResult
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /admin/matches/{matchId}/recompute [post]
+func (h *Handler) RecomputeMatch(w http.ResponseWriter, r *http.Request) {
+	if h.matchStates == nil || h.matchAdmin == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Match administration not enabled")
+		return
+	}
+	ctx := r.Context()
+	matchID := chi.URLParam(r, "matchId")
+
+	record, err := h.matchStateRecord(ctx, matchID)
+	if err == nil {
+		err = h.matchStates.CheckRecompute(ctx, matchID)
+	}
+	if err != nil {
+		h.matchStateError(w, err)
+		return
+	}
+
+	recompute, err := h.matchAdmin.Recompute(ctx, matchID)
+	if err != nil {
+		h.matchStateError(w, err)
+		return
+	}
+	result := recompute.Result
+	result.State = record.State
+
+	if replayer, ok := h.pool.(achievementReplayer); ok && record.State != models.MatchStateVoided {
+		result.AchievementsReplayed = replayer.ReplayAchievements(recompute.Events)
+	}
+	h.logger.Infow("Match recomputed", "match", matchID, "events", result.Events, "outcomes", result.Outcomes, "achievementsRevoked", result.AchievementsRevoked)
+
+	switch {
+	case h.rebuilds != nil:
+		result.RebuildJob, err = h.rebuilds.Submit(models.AggregateRebuildRequest{
+			MatchIDs: []string{matchID},
+			Reason:   "recompute",
+		})
+		if err != nil {
+			h.logger.Warnw("Failed to start aggregate rebuild after recompute", "match", matchID, "error", err)
+			h.errorResponse(w, http.StatusConflict, "Match recomputed, aggregate rebuild not started: "+err.Error())
+			return
+		}
+	case r.URL.Query().Get("rebuild") == "true":
+		if result.AggregatesRebuilt, err = h.rebuildPlayerAggregates(r); err != nil {
+			h.logger.Errorw("Failed to rebuild aggregates after recompute", "match", matchID, "error", err)
+			h.errorResponse(w, http.StatusInternalServerError, "Match recomputed, aggregate rebuild failed")
+			return
+		}
+	default:
+		result.Note = "Aggregates still count the old outcomes until they are rebuilt (rebuild=true)"
+	}
+	h.jsonResponse(w, http.StatusOK, result)
+}
+
+// achievementReplayer is implemented by queues that can re-run achievement
+// checks over stored events (worker.Pool)
+type achievementReplayer interface {
+	ReplayAchievements(events []*models.RawEvent) bool
+}
+
+// rebuildPlayerAggregates rebuilds every player aggregate table
+func (h *Handler) rebuildPlayerAggregates(r *http.Request) ([]string, error) {
+	var rebuilt []string
+	for _, table := range logic.PlayerAggregateTables() {
+		h.logger.Warnw("Rebuilding aggregate table", "table", table)
+		if _, err := h.aggregates.Rebuild(r.Context(), table); err != nil {
+			return rebuilt, err
+		}
+		rebuilt = append(rebuilt, table)
+	}
+	return rebuilt, nil
+}
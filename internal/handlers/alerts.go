@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/openmohaa/stats-api/internal/worker"
+)
+
+// maxAlertSilence caps how long one silence lasts
+const maxAlertSilence = 30 * 24 * time.Hour
+
+// CreateAlertSilenceRequest mutes a rule, for one server or all of them
+type CreateAlertSilenceRequest struct {
+	Rule     string `json:"rule"`
+	Key      string `json:"key"`      // server ID; empty silences every key
+	Duration string `json:"duration"` // Go duration, e.g. 2h
+	Reason   string `json:"reason"`
+}
+
+// GetAlerts returns the operator alert rules, firing alerts and silences
+// @Summary Operator Alerts
+// @Description The configured alert rules (ingest lag, queue depth, server offline, ClickHouse errors), the alerts firing on this API instance as of the last evaluation, and active silences.
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Success 200 {object} models.AlertState
+// @Failure 503 {object} map[string]string
+// @Router /admin/alerts [get]
+func (h *Handler) GetAlerts(w http.ResponseWriter, r *http.Request) {
+	if h.alerts == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Alerts not enabled")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, h.alerts.State(time.Now()))
+}
+
+// CreateAlertSilence mutes an alert rule's notifications for a while
+// @Summary Silence Alert
+// @Description Stops webhook posts for a rule, for one key (server ID) or all, until the duration runs out. Alerts keep firing in the state; silences last until restart at most.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ServerToken
+// @Param body body CreateAlertSilenceRequest true "Rule, key, duration and reason"
+// @Success 201 {object} models.AlertSilence
+// @Failure 400 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /admin/alerts/silences [post]
+func (h *Handler) CreateAlertSilence(w http.ResponseWriter, r *http.Request) {
+	if h.alerts == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Alerts not enabled")
+		return
+	}
+	var req CreateAlertSilenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	duration, err := time.ParseDuration(req.Duration)
+	if err != nil || duration <= 0 || duration > maxAlertSilence {
+		h.errorResponse(w, http.StatusBadRequest, "duration must be a positive Go duration up to 720h")
+		return
+	}
+
+	silence, err := h.alerts.Silence(req.Rule, req.Key, req.Reason, duration, time.Now())
+	if errors.Is(err, worker.ErrUnknownAlertRule) {
+		h.errorResponse(w, http.StatusBadRequest, "Unknown alert rule")
+		return
+	}
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to silence alert")
+		return
+	}
+	h.logger.Infow("Alert silenced", "rule", silence.Rule, "key", silence.Key, "until", silence.Until, "reason", silence.Reason)
+	h.jsonResponse(w, http.StatusCreated, silence)
+}
+
+// DeleteAlertSilence lifts a silence before it runs out
+// @Summary Lift Alert Silence
+// @Tags Admin
+// @Security ServerToken
+// @Param id path string true "Silence ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /admin/alerts/silences/{id} [delete]
+func (h *Handler) DeleteAlertSilence(w http.ResponseWriter, r *http.Request) {
+	if h.alerts == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Alerts not enabled")
+		return
+	}
+	if err := h.alerts.Unsilence(chi.URLParam(r, "id")); errors.Is(err, worker.ErrSilenceNotFound) {
+		h.errorResponse(w, http.StatusNotFound, "Silence not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
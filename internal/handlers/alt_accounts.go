@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GetBanEvasion returns the players still connecting from the client IPs of banned players
+// @Summary Ban Evasion Report
+// @Description GUIDs seen recently on a hashed client IP shared with a player under an active ban, those sharing the most hashes first. Hashes shared by more than 10 GUIDs (cafes, LANs, carrier NAT) are left out. Servers only send IP hashes when they opt in.
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Param days query int false "Only GUIDs seen in the last N days" default(30)
+// @Param limit query int false "Max suspects" default(50)
+// @Success 200 {array} models.BanEvasionSuspect
+// @Router /admin/identity/alts [get]
+func (h *Handler) GetBanEvasion(w http.ResponseWriter, r *http.Request) {
+	if h.altAccounts == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Alt account detection not enabled")
+		return
+	}
+	q := r.URL.Query()
+	days, limit := 30, 50
+	if v, err := strconv.Atoi(q.Get("days")); err == nil && v > 0 && v <= 365 {
+		days = v
+	}
+	if v, err := strconv.Atoi(q.Get("limit")); err == nil && v > 0 && v <= 200 {
+		limit = v
+	}
+
+	suspects, err := h.altAccounts.BanEvasion(r.Context(), time.Now().AddDate(0, 0, -days), limit)
+	if err != nil {
+		h.logger.Errorw("Failed to list ban evasion suspects", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to list ban evasion suspects")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, suspects)
+}
+
+// GetPlayerAlts returns the GUIDs that connected from the client IPs of a player
+// @Summary Player Alt Accounts
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Param guid path string true "Player GUID"
+// @Param limit query int false "Max accounts" default(50)
+// @Success 200 {array} models.AltAccount
+// @Router /admin/identity/players/{guid}/alts [get]
+func (h *Handler) GetPlayerAlts(w http.ResponseWriter, r *http.Request) {
+	if h.altAccounts == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Alt account detection not enabled")
+		return
+	}
+	limit := 50
+	if v, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && v > 0 && v <= 200 {
+		limit = v
+	}
+
+	alts, err := h.altAccounts.Alts(r.Context(), chi.URLParam(r, "guid"), limit)
+	if err != nil {
+		h.logger.Errorw("Failed to list alt accounts", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to list alt accounts")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, alts)
+}
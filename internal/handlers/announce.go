@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// announcePollInterval is how often a long poll re-checks the queue
+const announcePollInterval = 500 * time.Millisecond
+
+// announceMaxWait caps the wait parameter below common proxy idle timeouts
+const announceMaxWait = 25 * time.Second
+
+// GetAnnouncements returns pending chat announcements for a match
+// @Summary Poll In-Game Announcements
+// @Description Returns achievement unlocks, server record breaks and rank-ups from a match run by the calling server, oldest first. Pass the returned cursor as `after` on the next poll to acknowledge them. With `wait`, the request is held until an announcement arrives or the wait runs out (long polling).
+// @Tags Integrations
+// @Produce json
+// @Security ServerToken
+// @Param match_id query string true "Match ID"
+// @Param after query int false "Cursor from the previous poll" default(0)
+// @Param wait query int false "Seconds to wait for an announcement (max 25)" default(0)
+// @Success 200 {object} models.AnnouncementBatch
+// @Failure 400 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /integrations/announce [get]
+func (h *Handler) GetAnnouncements(w http.ResponseWriter, r *http.Request) {
+	if h.announcer == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Announcements not enabled")
+		return
+	}
+
+	q := r.URL.Query()
+	matchID := q.Get("match_id")
+	if matchID == "" {
+		h.errorResponse(w, http.StatusBadRequest, "match_id is required")
+		return
+	}
+	var after int64
+	if s := q.Get("after"); s != "" {
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || v < 0 {
+			h.errorResponse(w, http.StatusBadRequest, "after must be a non-negative integer")
+			return
+		}
+		after = v
+	}
+	var wait time.Duration
+	if s := q.Get("wait"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 0 {
+			h.errorResponse(w, http.StatusBadRequest, "wait must be a non-negative number of seconds")
+			return
+		}
+		wait = time.Duration(v) * time.Second
+		if wait > announceMaxWait {
+			wait = announceMaxWait
+		}
+	}
+
+	ctx := r.Context()
+	serverID, _ := ctx.Value("server_id").(string)
+	deadline := time.Now().Add(wait)
+	for {
+		batch, err := h.announcer.Fetch(ctx, matchID, serverID, after)
+		if err != nil {
+			h.logger.Errorw("Failed to fetch announcements", "match", matchID, "error", err)
+			h.errorResponse(w, http.StatusInternalServerError, "Failed to fetch announcements")
+			return
+		}
+		if len(batch.Announcements) > 0 || !time.Now().Before(deadline) {
+			h.jsonResponse(w, http.StatusOK, batch)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(announcePollInterval):
+		}
+	}
+}
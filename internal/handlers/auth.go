@@ -140,14 +140,14 @@ func (h *Handler) PollDeviceToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	data, err := h.redis.Get(ctx, "device:"+req.DeviceCode).Bytes()
+	data, err := h.redis.Get(ctx, "device:"+req.DeviceCode)
 	if err != nil {
 		h.errorResponse(w, http.StatusNotFound, "Invalid or expired device code")
 		return
 	}
 
 	var state models.DeviceAuthState
-	json.Unmarshal(data, &state)
+	json.Unmarshal([]byte(data), &state)
 
 	switch state.Status {
 	case "pending":
@@ -1049,14 +1049,20 @@ func (h *Handler) VerifyIdentityClaim(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	data, err := h.redis.Get(ctx, "claim:"+req.Code).Bytes()
+	// The worker tags GUIDs seen with a bot_id
+	if bot, _ := h.redis.SIsMember(ctx, "bot_guids", req.PlayerGUID); bot {
+		h.errorResponse(w, http.StatusBadRequest, "Bots cannot claim an identity")
+		return
+	}
+
+	data, err := h.redis.Get(ctx, "claim:"+req.Code)
 	if err != nil {
 		h.errorResponse(w, http.StatusNotFound, "Invalid or expired code")
 		return
 	}
 
 	var claim models.IdentityClaim
-	json.Unmarshal(data, &claim)
+	json.Unmarshal([]byte(data), &claim)
 
 	// Link identity in Postgres
 	_, err = h.pg.Exec(ctx, `
@@ -18,6 +18,22 @@ func (h *Handler) GetLeaderboardCards(w http.ResponseWriter, r *http.Request) {
 			FROM mohaa_stats.raw_events
 			WHERE event_type IN ('player_kill', 'bot_killed') AND target_id != '' AND target_id != 'world'
 			GROUP BY target_id
+		),
+		movement_cte AS (
+			SELECT
+				actor_id as player_id,
+				sumIf(walked * sample_weight, event_type = 'distance') as walked,
+				sumIf(sprinted * sample_weight, event_type = 'distance') as sprinted,
+				sumIf(swam * sample_weight, event_type = 'distance') as swam,
+				sumIf(driven * sample_weight, event_type = 'distance') as driven,
+				sumIf(sample_weight, event_type = 'jump') as jumps,
+				sumIf(sample_weight, event_type = 'crouch') as crouch_events,
+				sumIf(sample_weight, event_type = 'prone') as prone_events,
+				sumIf(sample_weight, event_type = 'ladder_mount') as ladders,
+				sumIf(distance * sample_weight, event_type = 'distance') as total_distance
+			FROM mohaa_stats.movement_events_all
+			WHERE actor_id != '' AND actor_id != 'world'
+			GROUP BY actor_id
 		)
 		SELECT 
 			a.actor_id,
@@ -27,9 +43,9 @@ func (h *Handler) GetLeaderboardCards(w http.ResponseWriter, r *http.Request) {
 			countIf(a.event_type IN ('player_kill', 'bot_killed')) as kills,
 			ifNull(max(d.death_count), 0) as deaths,
 			countIf(a.event_type IN ('player_kill', 'bot_killed') AND a.hitloc IN ('head', 'helmet')) as headshots,
-			countIf(a.event_type = 'weapon_fire') as shots_fired,
-			countIf(a.event_type = 'weapon_hit') as shots_hit,
-			sumIf(a.damage, a.event_type = 'damage') as total_damage,
+			sumIf(a.sample_weight, a.event_type = 'weapon_fire') as shots_fired,
+			sumIf(a.sample_weight, a.event_type = 'weapon_hit') as shots_hit,
+			sumIf(a.damage * a.sample_weight, a.event_type = 'damage') as total_damage,
 			countIf(a.event_type IN ('player_bash', 'bash')) as bash_kills,
 			countIf(a.event_type IN ('grenade_throw', 'explosion', 'grenade_explode')) as grenade_kills,
 			countIf(a.event_type IN ('player_roadkill', 'roadkill')) as roadkills,
@@ -46,14 +62,14 @@ func (h *Handler) GetLeaderboardCards(w http.ResponseWriter, r *http.Request) {
 			countIf(a.event_type = 'item_pickup') as looter,
 
 			-- C. Movement
-			sumIf(JSONExtractFloat(a.raw_json, 'walked'), a.event_type = 'distance') as walked,
-			sumIf(JSONExtractFloat(a.raw_json, 'sprinted'), a.event_type = 'distance') as sprinted,
-			sumIf(JSONExtractFloat(a.raw_json, 'swam'), a.event_type = 'distance') as swam,
-			sumIf(JSONExtractFloat(a.raw_json, 'driven'), a.event_type = 'distance') as driven,
-			countIf(a.event_type = 'jump') as jumps,
-			countIf(a.event_type = 'crouch') as crouch_events,
-			countIf(a.event_type = 'prone') as prone_events,
-			countIf(a.event_type = 'ladder_mount') as ladders,
+			ifNull(max(m.walked), 0) as walked,
+			ifNull(max(m.sprinted), 0) as sprinted,
+			ifNull(max(m.swam), 0) as swam,
+			ifNull(max(m.driven), 0) as driven,
+			ifNull(max(m.jumps), 0) as jumps,
+			ifNull(max(m.crouch_events), 0) as crouch_events,
+			ifNull(max(m.prone_events), 0) as prone_events,
+			ifNull(max(m.ladders), 0) as ladders,
 
 			-- D. Survival & Items
 			countIf(a.event_type = 'health_pickup') as health_picked,
@@ -62,9 +78,9 @@ func (h *Handler) GetLeaderboardCards(w http.ResponseWriter, r *http.Request) {
 			countIf(a.event_type = 'item_pickup') as items_picked,
 
 			-- E. Objectives & Game Flow
-			countIf(a.event_type = 'match_outcome' AND a.damage = 1) as wins,
-			countIf(a.event_type = 'match_outcome' AND a.damage = 1 AND a.actor_weapon = 'dm') as ffa_wins,
-			countIf(a.event_type = 'match_outcome' AND a.damage = 1 AND a.actor_weapon != 'dm') as team_wins,
+			countIf(a.event_type = 'match_outcome' AND a.match_outcome = 1) as wins,
+			countIf(a.event_type = 'match_outcome' AND a.match_outcome = 1 AND lower(a.actor_weapon) IN ('dm', 'ffa')) as ffa_wins,
+			countIf(a.event_type = 'match_outcome' AND a.match_outcome = 1 AND lower(a.actor_weapon) NOT IN ('dm', 'ffa')) as team_wins,
 			countIf(a.event_type IN ('objective_update', 'objective_capture')) as objectives_done,
 			countIf(a.event_type IN ('round_end', 'round_start')) as rounds_played,
 			countIf(a.event_type = 'match_outcome') as games_finished,
@@ -80,23 +96,24 @@ func (h *Handler) GetLeaderboardCards(w http.ResponseWriter, r *http.Request) {
 			countIf(a.event_type = 'door_open') as doors_opened,
 			
 			-- H. Creative Stats
-			countIf(a.event_type IN ('ladder_mount', 'jump')) as verticality,
+			ifNull(max(m.ladders) + max(m.jumps), 0) as verticality,
 			uniqIf(a.actor_weapon, a.event_type IN ('player_kill', 'player_bash', 'bash')) as unique_weapon_kills,
 			countIf(a.event_type = 'item_drop') as items_dropped,
 			countIf(a.event_type = 'vehicle_collision') as vehicle_collisions,
 			countIf(a.event_type = 'bot_killed') as bot_kills,
 
             -- Movement specific
-            sumIf(a.distance, a.event_type = 'distance') as total_distance,
+            ifNull(max(m.total_distance), 0) as total_distance,
             countIf(a.event_type = 'reload') as reload_count,
-            countIf(a.event_type = 'ladder_mount') as ladder_mounts,
-            countIf(a.event_type = 'crouch') as manual_crouches
+            ifNull(max(m.ladders), 0) as ladder_mounts,
+            ifNull(max(m.crouch_events), 0) as manual_crouches
 
 		FROM mohaa_stats.raw_events a
 		LEFT JOIN deaths_cte d ON a.actor_id = d.player_id
+		LEFT JOIN movement_cte m ON a.actor_id = m.player_id
 		WHERE a.actor_id != 'world' AND a.actor_id != ''
 		GROUP BY a.actor_id
-		HAVING countIf(a.event_type IN ('player_kill', 'bot_killed')) > 0 OR max(d.death_count) > 0 OR countIf(a.event_type = 'weapon_fire') > 0
+		HAVING countIf(a.event_type IN ('player_kill', 'bot_killed')) > 0 OR max(d.death_count) > 0 OR sumIf(a.sample_weight, a.event_type = 'weapon_fire') > 0
 	`
 
 	rows, err := h.ch.Query(ctx, query)
@@ -109,9 +126,10 @@ func (h *Handler) GetLeaderboardCards(w http.ResponseWriter, r *http.Request) {
 
 	// 2. Scan Results
 	type PlayerAgg struct {
-		ID      string
-		Name    string
-		Metrics map[string]float64
+		ID          string
+		Name        string
+		NameFlagged bool
+		Metrics     map[string]float64
 	}
 
 	players := []PlayerAgg{}
@@ -254,6 +272,11 @@ func (h *Handler) GetLeaderboardCards(w http.ResponseWriter, r *http.Request) {
 		players = append(players, p)
 	}
 
+	// Hidden players never make a card; the rest get their display names
+	players = applyDisplayNames(ctx, h, players, func(p *PlayerAgg) (string, *string, *bool) {
+		return p.ID, &p.Name, &p.NameFlagged
+	})
+
 	// 3. Process Top 3 for each category
 	categories := []string{
 		"kills", "deaths", "kd", "headshots", "accuracy", "headshot_ratio",
@@ -274,9 +297,10 @@ func (h *Handler) GetLeaderboardCards(w http.ResponseWriter, r *http.Request) {
 
 	for _, cat := range categories {
 		type entry struct {
-			Name  string
-			Value float64
-			ID    string
+			Name    string
+			Flagged bool
+			Value   float64
+			ID      string
 		}
 
 		var best [3]entry
@@ -289,7 +313,7 @@ func (h *Handler) GetLeaderboardCards(w http.ResponseWriter, r *http.Request) {
 			}
 
 			if count < 3 {
-				best[count] = entry{Name: p.Name, Value: val, ID: p.ID}
+				best[count] = entry{Name: p.Name, Flagged: p.NameFlagged, Value: val, ID: p.ID}
 				count++
 				// Keep sorted descending
 				for k := count - 1; k > 0; k-- {
@@ -299,7 +323,7 @@ func (h *Handler) GetLeaderboardCards(w http.ResponseWriter, r *http.Request) {
 				}
 			} else if val > best[2].Value {
 				// Replace last one and shift up if needed
-				best[2] = entry{Name: p.Name, Value: val, ID: p.ID}
+				best[2] = entry{Name: p.Name, Flagged: p.NameFlagged, Value: val, ID: p.ID}
 				if best[2].Value > best[1].Value {
 					best[2], best[1] = best[1], best[2]
 					if best[1].Value > best[0].Value {
@@ -327,11 +351,15 @@ func (h *Handler) GetLeaderboardCards(w http.ResponseWriter, r *http.Request) {
 				// valStr = fmt.Sprintf("%.1fh", best[i].Value / 3600)
 			}
 
-			top3 = append(top3, map[string]interface{}{
+			card := map[string]interface{}{
 				"player_name": best[i].Name,
 				"value":       valStr,
 				"player_id":   best[i].ID,
-			})
+			}
+			if best[i].Flagged {
+				card["name_flagged"] = true
+			}
+			top3 = append(top3, card)
 		}
 		result[cat] = top3
 	}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+	"github.com/openmohaa/stats-api/internal/worker"
+)
+
+// GetWeeklyChallenges returns the challenges running this week
+// @Summary Weekly Challenges
+// @Description The objectives rotated in this week (Monday to Monday, UTC).
+// @Tags Stats
+// @Produce json
+// @Success 200 {object} models.WeeklyChallenges
+// @Failure 500 {object} map[string]string
+// @Router /stats/challenges [get]
+func (h *Handler) GetWeeklyChallenges(w http.ResponseWriter, r *http.Request) {
+	week := logic.ChallengeWeek(time.Now())
+	challenges, err := h.challenges.Week(r.Context(), week)
+	if err != nil {
+		h.logger.Errorw("Failed to get weekly challenges", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to get challenges")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, models.WeeklyChallenges{
+		WeekStart:  week,
+		EndsAt:     week.AddDate(0, 0, 7),
+		Challenges: challenges,
+	})
+}
+
+// GetPlayerChallenges returns a player's progress on this week's challenges
+// @Summary Player Challenge Progress
+// @Tags Player
+// @Produce json
+// @Param guid path string true "Player GUID"
+// @Success 200 {object} models.PlayerChallenges
+// @Failure 500 {object} map[string]string
+// @Router /stats/player/{guid}/challenges [get]
+func (h *Handler) GetPlayerChallenges(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.playerChallenges(r.Context(), chi.URLParam(r, "guid"))
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to get challenges")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, resp)
+}
+
+// playerChallenges returns a player's progress on this week's challenges
+func (h *Handler) playerChallenges(ctx context.Context, guid string) (*models.PlayerChallenges, error) {
+	week := logic.ChallengeWeek(time.Now())
+
+	challenges, err := h.challenges.Week(ctx, week)
+	if err != nil {
+		h.logger.Errorw("Failed to get weekly challenges", "error", err)
+		return nil, err
+	}
+	completed, err := h.challenges.Completions(ctx, guid, week)
+	if err != nil {
+		h.logger.Errorw("Failed to get challenge completions", "guid", guid, "error", err)
+		return nil, err
+	}
+	progress, err := worker.ReadChallengeProgress(ctx, h.redis, week, challenges, guid)
+	if err != nil {
+		// Completions still show; counters are back once Redis is
+		h.logger.Warnw("Failed to read challenge progress", "guid", guid, "error", err)
+		progress = make([]int64, len(challenges))
+	}
+
+	resp := &models.PlayerChallenges{
+		PlayerGUID: guid,
+		WeekStart:  week,
+		EndsAt:     week.AddDate(0, 0, 7),
+		Challenges: make([]models.ChallengeProgress, len(challenges)),
+	}
+	for i, c := range challenges {
+		resp.Challenges[i] = challengeProgress(c, progress[i], completed)
+	}
+	return resp, nil
+}
+
+// challengeProgress combines a counter with the stored completion; progress
+// is capped at the target
+func challengeProgress(c models.Challenge, count int64, completed map[string]time.Time) models.ChallengeProgress {
+	p := models.ChallengeProgress{Challenge: c, Progress: count}
+	if at, ok := completed[c.Code]; ok {
+		p.Completed, p.CompletedAt = true, &at
+	}
+	if p.Completed || p.Progress > c.Target {
+		p.Progress = c.Target
+	}
+	return p
+}
+
+// ListChallenges returns the whole challenge pool
+// @Summary List Challenge Pool
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Success 200 {array} models.Challenge
+// @Failure 500 {object} map[string]string
+// @Router /admin/challenges [get]
+func (h *Handler) ListChallenges(w http.ResponseWriter, r *http.Request) {
+	challenges, err := h.challenges.List(r.Context())
+	if err != nil {
+		h.logger.Errorw("Failed to list challenges", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to list challenges")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, challenges)
+}
+
+// DefineChallenge creates or replaces a challenge in the pool
+// @Summary Define Challenge
+// @Description Metrics: kills, headshots, bash_kills, roadkills, wins, matches. weapons are weapon name fragments and map_prefix a game type prefix (dm, obj...). Disabled challenges are not rotated in; a running week picks up changes within a minute.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ServerToken
+// @Param code path string true "Challenge code"
+// @Param body body models.Challenge true "Challenge; the code in the body is ignored"
+// @Success 200 {object} models.Challenge
+// @Failure 400 {object} map[string]string
+// @Router /admin/challenges/{code} [put]
+func (h *Handler) DefineChallenge(w http.ResponseWriter, r *http.Request) {
+	c := models.Challenge{Enabled: true}
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		h.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	c.Code = chi.URLParam(r, "code")
+	if err := h.challenges.Define(r.Context(), &c); err != nil {
+		if errors.Is(err, logic.ErrInvalidChallenge) {
+			h.errorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.logger.Errorw("Failed to define challenge", "code", c.Code, "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to define challenge")
+		return
+	}
+	h.logger.Infow("Challenge defined", "code", c.Code, "metric", c.Metric, "target", c.Target, "enabled", c.Enabled)
+	h.jsonResponse(w, http.StatusOK, c)
+}
+
+// GetQuestChain returns the steps of the new player quest chain
+// @Summary New Player Quest Chain
+// @Description Players are enrolled on their first connect when they have no earlier stats, and have 14 days to finish the steps.
+// @Tags Stats
+// @Produce json
+// @Success 200 {array} models.Challenge
+// @Failure 500 {object} map[string]string
+// @Router /stats/quests [get]
+func (h *Handler) GetQuestChain(w http.ResponseWriter, r *http.Request) {
+	steps, err := h.challenges.QuestChain(r.Context(), models.QuestChainOnboarding)
+	if err != nil {
+		h.logger.Errorw("Failed to get quest chain", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to get quests")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, steps)
+}
+
+// GetPlayerQuest returns a new player's progress on the quest chain
+// @Summary Player Quest Progress
+// @Tags Player
+// @Produce json
+// @Param guid path string true "Player GUID"
+// @Success 200 {object} models.PlayerQuest
+// @Failure 404 {object} map[string]string "Not enrolled (not a new player)"
+// @Failure 500 {object} map[string]string
+// @Router /stats/player/{guid}/quests [get]
+func (h *Handler) GetPlayerQuest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	guid := chi.URLParam(r, "guid")
+
+	quest, done, err := h.challenges.QuestEnrollment(ctx, guid, models.QuestChainOnboarding)
+	if errors.Is(err, logic.ErrNotEnrolled) {
+		h.errorResponse(w, http.StatusNotFound, "Player is not on a quest chain")
+		return
+	}
+	if err != nil {
+		h.logger.Errorw("Failed to get quest enrollment", "guid", guid, "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to get quests")
+		return
+	}
+	steps, err := h.challenges.QuestChain(ctx, models.QuestChainOnboarding)
+	if err != nil {
+		h.logger.Errorw("Failed to get quest chain", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to get quests")
+		return
+	}
+	progress, err := worker.ReadQuestProgress(ctx, h.redis, models.QuestChainOnboarding, steps, guid)
+	if err != nil {
+		h.logger.Warnw("Failed to read quest progress", "guid", guid, "error", err)
+		progress = make([]int64, len(steps))
+	}
+
+	quest.Steps = make([]models.ChallengeProgress, len(steps))
+	quest.CurrentStep = len(steps)
+	for i, c := range steps {
+		quest.Steps[i] = challengeProgress(c, progress[i], done)
+		if !quest.Steps[i].Completed && quest.CurrentStep == len(steps) {
+			quest.CurrentStep = i
+		}
+	}
+	h.jsonResponse(w, http.StatusOK, quest)
+}
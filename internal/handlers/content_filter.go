@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// ListContentOverrides returns the admin overrides of the content filter
+// @Summary List Content Filter Overrides
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Success 200 {array} models.ContentFilterOverride
+// @Failure 500 {object} map[string]string
+// @Router /admin/content-filter/overrides [get]
+func (h *Handler) ListContentOverrides(w http.ResponseWriter, r *http.Request) {
+	overrides, err := h.contentFilter.ListOverrides(r.Context())
+	if err != nil {
+		h.contentOverrideError(w, err)
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, overrides)
+}
+
+// SetContentOverride allows or blocks a player's name regardless of the
+// configured filter
+// @Summary Set Content Filter Override
+// @Description "allow" serves the player's name as is, "block" always flags or masks it.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ServerToken
+// @Param guid path string true "Player GUID"
+// @Param body body models.ContentFilterOverride true "Action and note; the GUID in the body is ignored"
+// @Success 200 {object} models.ContentFilterOverride
+// @Failure 400 {object} map[string]string
+// @Router /admin/content-filter/overrides/{guid} [put]
+func (h *Handler) SetContentOverride(w http.ResponseWriter, r *http.Request) {
+	var o models.ContentFilterOverride
+	if err := json.NewDecoder(r.Body).Decode(&o); err != nil {
+		h.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	o.PlayerGUID = chi.URLParam(r, "guid")
+	o.CreatedBy, _ = r.Context().Value("server_id").(string)
+	if err := h.contentFilter.SetOverride(r.Context(), &o); err != nil {
+		h.contentOverrideError(w, err)
+		return
+	}
+	h.logger.Infow("Content filter override set", "player", o.PlayerGUID, "action", o.Action, "by", o.CreatedBy)
+	h.jsonResponse(w, http.StatusOK, o)
+}
+
+// DeleteContentOverride puts a player's name back under the configured filter
+// @Summary Delete Content Filter Override
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Param guid path string true "Player GUID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/content-filter/overrides/{guid} [delete]
+func (h *Handler) DeleteContentOverride(w http.ResponseWriter, r *http.Request) {
+	guid := chi.URLParam(r, "guid")
+	if err := h.contentFilter.DeleteOverride(r.Context(), guid); err != nil {
+		h.contentOverrideError(w, err)
+		return
+	}
+	actor, _ := r.Context().Value("server_id").(string)
+	h.logger.Infow("Content filter override deleted", "player", guid, "by", actor)
+	h.jsonResponse(w, http.StatusOK, map[string]string{"status": "deleted", "player_guid": guid})
+}
+
+// contentOverrideError maps content filter override errors to responses
+func (h *Handler) contentOverrideError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, logic.ErrInvalidOverride):
+		h.errorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, logic.ErrOverrideNotFound):
+		h.errorResponse(w, http.StatusNotFound, "Override not found")
+	default:
+		h.logger.Errorw("Content filter override operation failed", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Content filter override operation failed")
+	}
+}
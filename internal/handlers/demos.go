@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// RegisterDemo records a demo recording of one of the calling server's matches
+// @Summary Register Match Demo
+// @Description Links a demo kept in a demo archive to the match it recorded: its file name, SHA-256 checksum, size and download URL. Only the server that played the match can register its demos; registering the same checksum again updates the file name and URL.
+// @Tags Integrations
+// @Accept json
+// @Produce json
+// @Security ServerToken
+// @Param body body models.MatchDemoRequest true "Demo"
+// @Success 200 {object} models.MatchDemo
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /integrations/demos [post]
+func (h *Handler) RegisterDemo(w http.ResponseWriter, r *http.Request) {
+	if h.demos == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Demos not enabled")
+		return
+	}
+	var req models.MatchDemoRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	serverID, _ := r.Context().Value("server_id").(string)
+	demo, err := h.demos.Register(r.Context(), serverID, &req)
+	if err != nil {
+		h.demoError(w, err)
+		return
+	}
+	h.logger.Infow("Demo registered", "match", demo.MatchID, "server", serverID, "file", demo.Filename)
+	h.jsonResponse(w, http.StatusOK, demo)
+}
+
+// DeleteDemo removes a registered demo
+// @Summary Delete Match Demo
+// @Tags Admin
+// @Security ServerToken
+// @Param id path int true "Demo ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /admin/demos/{id} [delete]
+func (h *Handler) DeleteDemo(w http.ResponseWriter, r *http.Request) {
+	if h.demos == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Demos not enabled")
+		return
+	}
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.errorResponse(w, http.StatusNotFound, "Demo not found")
+		return
+	}
+	if err := h.demos.Delete(r.Context(), id); err != nil {
+		h.demoError(w, err)
+		return
+	}
+	actor, _ := r.Context().Value("server_id").(string)
+	h.logger.Infow("Demo deleted", "id", id, "by", actor)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetMatchDemos returns the demo recordings of a match
+// @Summary Get Match Demos
+// @Tags Match
+// @Produce json
+// @Param matchId path string true "Match ID"
+// @Success 200 {array} models.MatchDemo
+// @Router /stats/match/{matchId}/demos [get]
+func (h *Handler) GetMatchDemos(w http.ResponseWriter, r *http.Request) {
+	if h.demos == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Demos not enabled")
+		return
+	}
+	demos, err := h.demos.ForMatch(r.Context(), chi.URLParam(r, "matchId"))
+	if err != nil {
+		h.demoError(w, err)
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, demos)
+}
+
+// GetDemoMatches lists the matches with demos available
+// @Summary List Matches With Demos
+// @Tags Match
+// @Produce json
+// @Param server_id query string false "Only this server's matches"
+// @Param map query string false "Only matches on this map"
+// @Param limit query int false "Max matches" default(20)
+// @Param offset query int false "Matches to skip" default(0)
+// @Success 200 {array} models.DemoMatch
+// @Router /stats/matches/demos [get]
+func (h *Handler) GetDemoMatches(w http.ResponseWriter, r *http.Request) {
+	if h.demos == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Demos not enabled")
+		return
+	}
+	q := r.URL.Query()
+	limit, offset := 20, 0
+	if v, err := strconv.Atoi(q.Get("limit")); err == nil && v > 0 && v <= 100 {
+		limit = v
+	}
+	if v, err := strconv.Atoi(q.Get("offset")); err == nil && v >= 0 {
+		offset = v
+	}
+
+	matches, err := h.demos.Matches(r.Context(), q.Get("server_id"), q.Get("map"), limit, offset)
+	if err != nil {
+		h.demoError(w, err)
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, matches)
+}
+
+func (h *Handler) demoError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, logic.ErrInvalidDemo):
+		h.errorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, logic.ErrDemoNotFound):
+		h.errorResponse(w, http.StatusNotFound, "Demo not found")
+	case errors.Is(err, logic.ErrMatchNotFound):
+		h.errorResponse(w, http.StatusNotFound, "Match not found")
+	case errors.Is(err, logic.ErrDemoServer):
+		h.errorResponse(w, http.StatusForbidden, err.Error())
+	default:
+		h.logger.Errorw("Failed to handle demo", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to handle demo")
+	}
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// discussionSubject reads the {type} of a discussion route
+func discussionSubject(r *http.Request) (models.DiscussionSubject, bool) {
+	subject := models.DiscussionSubject(chi.URLParam(r, "type"))
+	return subject, logic.ValidDiscussionSubject(subject)
+}
+
+// GetDiscussion returns the forum thread of a match or player
+// @Summary Get Discussion Thread
+// @Description The SMF topic a match or player profile is discussed in, with its post count for "discuss (12)" links. Featured matches get a thread automatically.
+// @Tags Match
+// @Produce json
+// @Param type path string true "match or player"
+// @Param id path string true "Match ID or player GUID"
+// @Success 200 {object} models.DiscussionThread
+// @Failure 404 {object} map[string]string
+// @Router /stats/discussions/{type}/{id} [get]
+func (h *Handler) GetDiscussion(w http.ResponseWriter, r *http.Request) {
+	if h.discussions == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Discussions not enabled")
+		return
+	}
+	subject, ok := discussionSubject(r)
+	if !ok {
+		h.errorResponse(w, http.StatusNotFound, "Discussion thread not found")
+		return
+	}
+	thread, err := h.discussions.Get(r.Context(), subject, chi.URLParam(r, "id"))
+	if err != nil {
+		h.discussionError(w, err)
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, thread)
+}
+
+// LookupDiscussions returns the forum threads of several matches or players
+// @Summary Lookup Discussion Threads
+// @Description For lists of matches or players: the threads of up to 100 IDs, keyed by ID. IDs without a thread are left out.
+// @Tags Match
+// @Produce json
+// @Param type path string true "match or player"
+// @Param ids query string true "Comma-separated match IDs or player GUIDs"
+// @Success 200 {object} map[string]models.DiscussionThread
+// @Failure 400 {object} map[string]string
+// @Router /stats/discussions/{type} [get]
+func (h *Handler) LookupDiscussions(w http.ResponseWriter, r *http.Request) {
+	if h.discussions == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Discussions not enabled")
+		return
+	}
+	subject, ok := discussionSubject(r)
+	if !ok {
+		h.errorResponse(w, http.StatusBadRequest, "type must be match or player")
+		return
+	}
+	var ids []string
+	for _, id := range strings.Split(r.URL.Query().Get("ids"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	threads, err := h.discussions.Lookup(r.Context(), subject, ids)
+	if err != nil {
+		h.discussionError(w, err)
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, threads)
+}
+
+// LinkDiscussion maps a match or player to an existing forum thread
+// @Summary Link Discussion Thread
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ServerToken
+// @Param type path string true "match or player"
+// @Param id path string true "Match ID or player GUID"
+// @Param body body models.DiscussionLinkRequest true "Forum topic"
+// @Success 200 {object} models.DiscussionThread
+// @Failure 400 {object} map[string]string
+// @Router /admin/discussions/{type}/{id} [put]
+func (h *Handler) LinkDiscussion(w http.ResponseWriter, r *http.Request) {
+	if h.discussions == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Discussions not enabled")
+		return
+	}
+	var req models.DiscussionLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	subject, id := models.DiscussionSubject(chi.URLParam(r, "type")), chi.URLParam(r, "id")
+	actor, _ := r.Context().Value("server_id").(string)
+	thread, err := h.discussions.Link(r.Context(), subject, id, &req, actor)
+	if err != nil {
+		h.discussionError(w, err)
+		return
+	}
+	h.logger.Infow("Discussion thread linked", "type", subject, "id", id, "topic", thread.TopicID, "by", actor)
+	h.jsonResponse(w, http.StatusOK, thread)
+}
+
+// UnlinkDiscussion removes the thread of a match or player; the forum topic stays
+// @Summary Unlink Discussion Thread
+// @Tags Admin
+// @Security ServerToken
+// @Param type path string true "match or player"
+// @Param id path string true "Match ID or player GUID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /admin/discussions/{type}/{id} [delete]
+func (h *Handler) UnlinkDiscussion(w http.ResponseWriter, r *http.Request) {
+	if h.discussions == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Discussions not enabled")
+		return
+	}
+	subject, ok := discussionSubject(r)
+	if !ok {
+		h.errorResponse(w, http.StatusNotFound, "Discussion thread not found")
+		return
+	}
+	id := chi.URLParam(r, "id")
+	if err := h.discussions.Unlink(r.Context(), subject, id); err != nil {
+		h.discussionError(w, err)
+		return
+	}
+	actor, _ := r.Context().Value("server_id").(string)
+	h.logger.Infow("Discussion thread unlinked", "type", subject, "id", id, "by", actor)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) discussionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, logic.ErrInvalidDiscussion):
+		h.errorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, logic.ErrDiscussionNotFound):
+		h.errorResponse(w, http.StatusNotFound, "Discussion thread not found")
+	default:
+		h.logger.Errorw("Failed to handle discussion thread", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to handle discussion thread")
+	}
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/openmohaa/stats-api/internal/models"
+	"github.com/openmohaa/stats-api/internal/sanitize"
+)
+
+// displayNames resolves display names for GUID -> fallback name and runs them
+// through the content filter, returning the names and the GUIDs whose names
+// were flagged. Like cosmetics, names never fail a ranking: errors are logged
+// and whatever was resolved, at least the cleaned fallbacks, is used.
+func (h *Handler) displayNames(ctx context.Context, fallbacks map[string]string) (map[string]string, map[string]bool) {
+	var names map[string]string
+	if h.playerNames == nil {
+		names = make(map[string]string, len(fallbacks))
+		for guid, name := range fallbacks {
+			names[guid] = sanitize.Name(name)
+		}
+	} else {
+		var err error
+		names, err = h.playerNames.DisplayNames(ctx, fallbacks)
+		if err != nil {
+			h.logger.Warnw("Failed to resolve display names", "players", len(fallbacks), "error", err)
+		}
+	}
+
+	if h.contentFilter == nil {
+		return names, nil
+	}
+	flagged, err := h.contentFilter.FilterNames(ctx, names)
+	if err != nil {
+		h.logger.Warnw("Content filter ran without overrides", "players", len(names), "error", err)
+	}
+	return names, flagged
+}
+
+// filterText runs a chat line through the content filter
+func (h *Handler) filterText(text string) (string, bool) {
+	if h.contentFilter == nil || text == "" {
+		return text, false
+	}
+	return h.contentFilter.FilterText(text)
+}
+
+// applyDisplayNames replaces the name of every ranked entry with its display
+// name; fields returns an entry's GUID and pointers to its name and its
+// flagged mark. Players who asked to be hidden from leaderboards are dropped,
+// leaving the others' ranks as they are so pages stay stable, and anonymized
+// ones get models.AnonymousName. Privacy that cannot be read is logged and
+// the list served without it.
+func applyDisplayNames[T any](ctx context.Context, h *Handler, entries []T, fields func(*T) (string, *string, *bool)) []T {
+	if len(entries) == 0 {
+		return entries
+	}
+	fallbacks := make(map[string]string, len(entries))
+	guids := make([]string, 0, len(entries))
+	for i := range entries {
+		guid, name, _ := fields(&entries[i])
+		fallbacks[guid] = *name
+		guids = append(guids, guid)
+	}
+	visibility, err := h.playerVisibility(ctx, guids)
+	if err != nil {
+		h.logger.Warnw("Failed to read player privacy", "players", len(guids), "error", err)
+	}
+	names, flagged := h.displayNames(ctx, fallbacks)
+
+	visible := entries[:0]
+	for i := range entries {
+		guid, name, nameFlagged := fields(&entries[i])
+		privacy := visibility[guid]
+		if privacy.HideFromLeaderboards {
+			continue
+		}
+		if display, ok := names[guid]; ok {
+			*name = display
+		}
+		*nameFlagged = flagged[guid]
+		if privacy.AnonymizeName {
+			*name, *nameFlagged = models.AnonymousName, false
+		}
+		visible = append(visible, entries[i])
+	}
+	return visible
+}
+
+func leaderboardEntryName(e *models.LeaderboardEntry) (string, *string, *bool) {
+	return e.PlayerID, &e.PlayerName, &e.NameFlagged
+}
+
+func statLeaderboardEntryName(e *models.StatLeaderboardEntry) (string, *string, *bool) {
+	return e.PlayerID, &e.PlayerName, &e.NameFlagged
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/worker"
+)
+
+// FaultsBody is the fault injection state of the worker pool
+type FaultsBody struct {
+	DropBatchPercent int    `json:"drop_batch_percent"`
+	ClickHouseDelay  string `json:"clickhouse_delay"` // Go duration, e.g. 2s
+	RedisDown        bool   `json:"redis_down"`
+}
+
+func faultsBody(cfg worker.FaultConfig) FaultsBody {
+	return FaultsBody{
+		DropBatchPercent: cfg.DropBatchPercent,
+		ClickHouseDelay:  cfg.ClickHouseDelay.String(),
+		RedisDown:        cfg.RedisDown,
+	}
+}
+
+// GetFaults returns the faults injected into the worker pool
+// @Summary Get Injected Faults
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Success 200 {object} FaultsBody
+// @Failure 403 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /admin/faults [get]
+func (h *Handler) GetFaults(w http.ResponseWriter, r *http.Request) {
+	if !h.faultsAllowed(w, r) {
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, faultsBody(h.faults.Config()))
+}
+
+// SetFaults replaces the faults injected into the worker pool
+// @Summary Inject Faults
+// @Description Makes the worker pool fail a share of batches before their ClickHouse insert, hold each batch before its insert, or fail every Redis side effect pipeline, to watch load shedding, failed batch metrics and the live state breaker. Only with FAULT_INJECTION outside production, or in chaos builds.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ServerToken
+// @Param body body FaultsBody true "Faults to inject"
+// @Success 200 {object} FaultsBody
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /admin/faults [put]
+func (h *Handler) SetFaults(w http.ResponseWriter, r *http.Request) {
+	if !h.faultsAllowed(w, r) {
+		return
+	}
+	var body FaultsBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		h.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	cfg := worker.FaultConfig{DropBatchPercent: body.DropBatchPercent, RedisDown: body.RedisDown}
+	if body.ClickHouseDelay != "" {
+		delay, err := time.ParseDuration(body.ClickHouseDelay)
+		if err != nil {
+			h.errorResponse(w, http.StatusBadRequest, "clickhouse_delay must be a Go duration")
+			return
+		}
+		cfg.ClickHouseDelay = delay
+	}
+	if err := cfg.Validate(); err != nil {
+		h.errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.faults.Set(cfg)
+	h.logger.Warnw("Injected faults changed", "drop_batch_percent", cfg.DropBatchPercent,
+		"clickhouse_delay", cfg.ClickHouseDelay, "redis_down", cfg.RedisDown)
+	h.jsonResponse(w, http.StatusOK, faultsBody(cfg))
+}
+
+// ClearFaults stops injecting faults
+// @Summary Clear Injected Faults
+// @Tags Admin
+// @Security ServerToken
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /admin/faults [delete]
+func (h *Handler) ClearFaults(w http.ResponseWriter, r *http.Request) {
+	if !h.faultsAllowed(w, r) {
+		return
+	}
+	h.faults.Set(worker.FaultConfig{})
+	h.logger.Infow("Injected faults cleared")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// faultsAllowed answers 503 without fault injection and 403 to tenant
+// servers, whose faults would hit every tenant
+func (h *Handler) faultsAllowed(w http.ResponseWriter, r *http.Request) bool {
+	if h.faults == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Fault injection not enabled")
+		return false
+	}
+	if logic.TenantFromContext(r.Context()) != "" {
+		h.errorResponse(w, http.StatusForbidden, "Fault injection is not available to tenant servers")
+		return false
+	}
+	return true
+}
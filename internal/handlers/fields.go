@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// fieldSet is a parsed ?fields= list: each key is a JSON field, mapped to the
+// fields selected under it (nil selects the whole value)
+type fieldSet map[string]fieldSet
+
+// parseFields reads a comma-separated list of dotted JSON paths, e.g.
+// "combat.kills,combat.deaths,weapons". Selecting a field and one of its
+// children keeps the whole field.
+func parseFields(s string) fieldSet {
+	var set fieldSet
+	for _, path := range strings.Split(s, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		if set == nil {
+			set = fieldSet{}
+		}
+		node := set
+		parts := strings.Split(path, ".")
+		for i, part := range parts {
+			child, seen := node[part]
+			if seen && child == nil {
+				break // already selected whole
+			}
+			if i == len(parts)-1 {
+				node[part] = nil
+				break
+			}
+			if child == nil {
+				child = fieldSet{}
+				node[part] = child
+			}
+			node = child
+		}
+	}
+	return set
+}
+
+// project keeps the selected fields of a decoded JSON value. Arrays are
+// projected element by element; fields that do not exist are skipped.
+func (f fieldSet) project(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(f))
+		for name, sub := range f {
+			val, ok := v[name]
+			if !ok {
+				continue
+			}
+			if sub != nil {
+				val = sub.project(val)
+			}
+			out[name] = val
+		}
+		return out
+	case []any:
+		for i := range v {
+			v[i] = f.project(v[i])
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// projectFields returns data cut down to the fields, by way of its JSON form
+func projectFields(data any, fields fieldSet) (any, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber() // keep int64 counters exact
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return fields.project(v), nil
+}
+
+// fieldsResponse writes data like jsonResponse, keeping only the fields named
+// by ?fields= when it is set (sparse fieldsets for clients that only need a
+// few numbers of a heavy response)
+func (h *Handler) fieldsResponse(w http.ResponseWriter, r *http.Request, status int, data any) {
+	fields := parseFields(r.URL.Query().Get("fields"))
+	if fields == nil {
+		h.jsonResponse(w, status, data)
+		return
+	}
+	projected, err := projectFields(data, fields)
+	if err != nil {
+		h.logger.Errorw("Failed to project response fields", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to select fields")
+		return
+	}
+	h.jsonResponse(w, status, projected)
+}
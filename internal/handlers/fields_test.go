@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestProjectFields(t *testing.T) {
+	data := map[string]any{
+		"combat": map[string]any{"kills": int64(9007199254740993), "deaths": 12, "headshots": 40},
+		"weapons": []map[string]any{
+			{"weapon": "kar98", "kills": 10, "accuracy": 0.4},
+			{"weapon": "thompson", "kills": 3, "accuracy": 0.2},
+		},
+		"movement": map[string]any{"jumps": 5},
+	}
+
+	got, err := projectFields(data, parseFields(" combat.kills,weapons.weapon,weapons.kills,missing.field,"))
+	if err != nil {
+		t.Fatalf("projectFields: %v", err)
+	}
+	raw, _ := json.Marshal(got)
+	want := `{"combat":{"kills":9007199254740993},"weapons":[{"kills":10,"weapon":"kar98"},{"kills":3,"weapon":"thompson"}]}`
+	if string(raw) != want {
+		t.Errorf("projected = %s\nwant %s", raw, want)
+	}
+}
+
+func TestParseFieldsWholeFieldWins(t *testing.T) {
+	for _, s := range []string{"combat,combat.kills", "combat.kills,combat"} {
+		fields := parseFields(s)
+		if sub, ok := fields["combat"]; !ok || sub != nil {
+			t.Errorf("%q: combat = %v, want the whole field", s, sub)
+		}
+	}
+	if parseFields("") != nil || parseFields(" , ") != nil {
+		t.Error("an empty list should select everything")
+	}
+}
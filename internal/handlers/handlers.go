@@ -1,27 +1,31 @@
 package handlers
 
 import (
-	"bytes"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
-	"net/url"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
 	"github.com/go-chi/chi/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 
+	"github.com/openmohaa/stats-api/internal/config"
+	"github.com/openmohaa/stats-api/internal/db"
+	"github.com/openmohaa/stats-api/internal/i18n"
+	"github.com/openmohaa/stats-api/internal/jobs"
+	"github.com/openmohaa/stats-api/internal/logging"
 	"github.com/openmohaa/stats-api/internal/logic"
 	"github.com/openmohaa/stats-api/internal/models"
+	"github.com/openmohaa/stats-api/internal/worker"
 )
 
 // MaxBodySize limits the size of request bodies to 1MB
@@ -33,6 +37,12 @@ type IngestQueue interface {
 	QueueDepth() int
 }
 
+// liveStateReporter is implemented by queues whose Redis side effects can run
+// degraded (worker.Pool)
+type liveStateReporter interface {
+	LiveStateStatus() worker.LiveStateStatus
+}
+
 // hashToken creates a SHA256 hash of a token for secure storage lookup
 func hashToken(token string) string {
 	h := sha256.New()
@@ -42,9 +52,12 @@ func hashToken(token string) string {
 
 type Config struct {
 	WorkerPool IngestQueue
+	// Ingest receives events posted to the ingest endpoints; nil queues
+	// them on WorkerPool
+	Ingest     IngestQueue
 	Postgres   *pgxpool.Pool
 	ClickHouse driver.Conn
-	Redis      *redis.Client
+	LiveState  db.LiveStateStore
 	Logger     *zap.Logger
 	// Services
 	PlayerStats   logic.PlayerStatsService
@@ -56,13 +69,93 @@ type Config struct {
 	Tournament    logic.TournamentService
 	Achievements  logic.AchievementsService
 	Prediction    logic.PredictionService
+	Aggregates    logic.AggregateService
+	Rebuilds      *worker.AggregateRebuilder
+	Tenants       logic.TenantService
+	Highlights    logic.HighlightsService
+	Snapshots     logic.LeaderboardSnapshotService
+	ServerReports logic.ServerReportService
+	Timeline      logic.TimelineService
+	Titles        logic.TitlesService
+	Trophies      logic.TrophiesService
+	Registration  logic.ServerRegistrationService
+	PlayerNames   logic.PlayerNamesService
+	// ContentFilter flags or masks offensive names and chat; nil serves them as is
+	ContentFilter logic.ContentFilterService
+	Challenges    logic.ChallengesService
+	MatchStates   logic.MatchStateService
+	MatchAdmin    logic.MatchAdminService
+	ServerMerge   logic.ServerMergeService
+	Ratings       logic.RatingService
+	QueryLog      *db.QueryLog
+	Reloader      *config.Reloader
+	// Privacy applies players' privacy preferences to read endpoints; nil
+	// shows every player
+	Privacy logic.PrivacyService
+	// MatchResults signs finalized tournament matches; nil disables signed
+	// results
+	MatchResults logic.MatchResultService
+	// Disputes serves the match dispute and correction endpoints; nil
+	// disables them
+	Disputes logic.MatchDisputeService
+	// MapVetoes records tournament map vetoes; nil disables the endpoints
+	MapVetoes logic.MapVetoService
+	// Viewership serves match spectator timelines and featured matches
+	Viewership logic.ViewershipService
+	// Demos links demo recordings to matches; nil disables the endpoints
+	Demos logic.DemoService
+	// Media queues user screenshots and clips of matches for moderation;
+	// nil disables the endpoints
+	Media logic.MatchMediaService
+	// Discussions maps matches and players to SMF forum threads; nil
+	// disables the endpoints
+	Discussions logic.DiscussionService
+	// Notifications is the signed-in user's inbox; nil disables the endpoints
+	Notifications logic.NotificationService
+	// Reports files abuse reports against players for admin triage; nil
+	// disables the endpoints
+	Reports logic.ReportService
+	// AltAccounts reports GUIDs sharing hashed client IPs, banned players'
+	// alts first; nil disables the endpoints
+	AltAccounts logic.AltAccountService
+	// Logging exposes log levels on /admin/logging; nil disables the endpoint
+	Logging *logging.Levels
+	// QuerySandbox serves /admin/query; nil disables the endpoint
+	QuerySandbox logic.QuerySandboxService
+	// Announcer serves /integrations/announce; nil disables the endpoint
+	Announcer *worker.Announcer
+	// Profiles keeps precomputed player profiles; nil computes each request
+	Profiles *worker.ProfileCache
+	// Alerts serves /admin/alerts; nil disables the endpoints
+	Alerts *worker.AlertEngine
+	// Faults serves /admin/faults; nil disables the endpoints
+	Faults *worker.Faults
+	// Jobs serves /admin/jobs; nil disables the endpoints
+	Jobs *jobs.Scheduler
+	// Settings
+	IngestStallThreshold time.Duration
+	// IngestMaxLineBytes bounds one line or JSON array element of a v1
+	// ingest batch (0 for 64KB); IngestMaxBodyBytes bounds the whole
+	// decompressed batch (0 for no limit)
+	IngestMaxLineBytes int
+	IngestMaxBodyBytes int64
+	// RateLimitPerSecond and RateLimitBurst bound each client's requests
+	// through RateLimitMiddleware (a rate of 0 for no limit)
+	RateLimitPerSecond int
+	RateLimitBurst     int
+	// RequireTenant rejects stats requests without a tenant API key
+	RequireTenant bool
+	// PublicURL and MatchPageURL set the links of match share cards
+	PublicURL    string
+	MatchPageURL string
 }
 
 type Handler struct {
 	pool          IngestQueue
+	ingest        IngestQueue
 	pg            *pgxpool.Pool
 	ch            driver.Conn
-	redis         *redis.Client
+	redis         db.LiveStateStore
 	logger        *zap.SugaredLogger
 	playerStats   logic.PlayerStatsService
 	serverStats   logic.ServerStatsService
@@ -73,14 +166,61 @@ type Handler struct {
 	tournament    logic.TournamentService
 	achievements  logic.AchievementsService
 	prediction    logic.PredictionService
+	aggregates    logic.AggregateService
+	rebuilds      *worker.AggregateRebuilder
+	tenants       logic.TenantService
+	highlights    logic.HighlightsService
+	snapshots     logic.LeaderboardSnapshotService
+	serverReports logic.ServerReportService
+	timeline      logic.TimelineService
+	titles        logic.TitlesService
+	trophies      logic.TrophiesService
+	registration  logic.ServerRegistrationService
+	playerNames   logic.PlayerNamesService
+	contentFilter logic.ContentFilterService
+	challenges    logic.ChallengesService
+	matchStates   logic.MatchStateService
+	matchAdmin    logic.MatchAdminService
+	serverMerge   logic.ServerMergeService
+	ratings       logic.RatingService
+	privacy       logic.PrivacyService
+	matchResults  logic.MatchResultService
+	disputes      logic.MatchDisputeService
+	mapVetoes     logic.MapVetoService
+	viewership    logic.ViewershipService
+	demos         logic.DemoService
+	media         logic.MatchMediaService
+	discussions   logic.DiscussionService
+	notifications logic.NotificationService
+	reports       logic.ReportService
+	altAccounts   logic.AltAccountService
+	querySandbox  logic.QuerySandboxService
+	announcer     *worker.Announcer
+	profiles      *worker.ProfileCache
+	alerts        *worker.AlertEngine
+	faults        *worker.Faults
+	jobs          *jobs.Scheduler
+	queryLog      *db.QueryLog
+	reloader      *config.Reloader
+	logging       *logging.Levels
+	ingestLog     *zap.SugaredLogger // hot path: sampled and leveled as "ingest"
+	requireTenant bool
+	publicURL     string
+	matchPageURL  string
+	ingestMaxLine int
+	ingestMaxBody int64
+	rateLimit     *rateLimiter
+
+	ingestStallThreshold atomic.Int64
 }
 
 func New(cfg Config) *Handler {
-	return &Handler{
+	h := &Handler{
 		pool:          cfg.WorkerPool,
+		ingest:        cfg.Ingest,
 		pg:            cfg.Postgres,
 		ch:            cfg.ClickHouse,
-		redis:         cfg.Redis,
+		redis:         cfg.LiveState,
 		logger:        cfg.Logger.Sugar(),
 		playerStats:   cfg.PlayerStats,
 		serverStats:   cfg.ServerStats,
@@ -91,7 +231,70 @@ func New(cfg Config) *Handler {
 		tournament:    cfg.Tournament,
 		achievements:  cfg.Achievements,
 		prediction:    cfg.Prediction,
+		aggregates:    cfg.Aggregates,
+		rebuilds:      cfg.Rebuilds,
+		tenants:       cfg.Tenants,
+		highlights:    cfg.Highlights,
+		snapshots:     cfg.Snapshots,
+		serverReports: cfg.ServerReports,
+		timeline:      cfg.Timeline,
+		titles:        cfg.Titles,
+		trophies:      cfg.Trophies,
+		registration:  cfg.Registration,
+		playerNames:   cfg.PlayerNames,
+		contentFilter: cfg.ContentFilter,
+		challenges:    cfg.Challenges,
+		matchStates:   cfg.MatchStates,
+		matchAdmin:    cfg.MatchAdmin,
+		serverMerge:   cfg.ServerMerge,
+		ratings:       cfg.Ratings,
+		privacy:       cfg.Privacy,
+		matchResults:  cfg.MatchResults,
+		disputes:      cfg.Disputes,
+		mapVetoes:     cfg.MapVetoes,
+		viewership:    cfg.Viewership,
+		demos:         cfg.Demos,
+		media:         cfg.Media,
+		discussions:   cfg.Discussions,
+		notifications: cfg.Notifications,
+		reports:       cfg.Reports,
+		altAccounts:   cfg.AltAccounts,
+		querySandbox:  cfg.QuerySandbox,
+		announcer:     cfg.Announcer,
+		profiles:      cfg.Profiles,
+		alerts:        cfg.Alerts,
+		faults:        cfg.Faults,
+		jobs:          cfg.Jobs,
+		queryLog:      cfg.QueryLog,
+		reloader:      cfg.Reloader,
+		logging:       cfg.Logging,
+		requireTenant: cfg.RequireTenant,
+		publicURL:     cfg.PublicURL,
+		matchPageURL:  cfg.MatchPageURL,
+		ingestMaxLine: cfg.IngestMaxLineBytes,
+		ingestMaxBody: cfg.IngestMaxBodyBytes,
+		rateLimit:     newRateLimiter(cfg.RateLimitPerSecond, cfg.RateLimitBurst),
+	}
+	if cfg.Logging != nil {
+		h.ingestLog = cfg.Logging.Logger("ingest").Sugar()
+	} else {
+		h.ingestLog = h.logger.Named("ingest")
+	}
+	h.ingestStallThreshold.Store(int64(cfg.IngestStallThreshold))
+	return h
+}
+
+// ingestLogger is the logger for per-request ingest logs
+func (h *Handler) ingestLogger() *zap.SugaredLogger {
+	if h.ingestLog != nil {
+		return h.ingestLog
 	}
+	return h.logger
+}
+
+// SetIngestStallThreshold changes the default threshold of /admin/ingest/health
+func (h *Handler) SetIngestStallThreshold(threshold time.Duration) {
+	h.ingestStallThreshold.Store(int64(threshold))
 }
 
 // ============================================================================
@@ -106,6 +309,9 @@ func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// Ready reports whether the API can serve traffic. Postgres and ClickHouse are
+// required; without Redis ingestion keeps working with live state degraded,
+// which is reported under "degraded" without failing the check.
 func (h *Handler) Ready(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -113,237 +319,123 @@ func (h *Handler) Ready(w http.ResponseWriter, r *http.Request) {
 	checks := map[string]bool{
 		"postgres":   h.pg.Ping(ctx) == nil,
 		"clickhouse": h.ch.Ping(ctx) == nil,
-		"redis":      h.redis.Ping(ctx).Err() == nil,
+		"redis":      h.redis.Ping(ctx) == nil,
 	}
 
-	allHealthy := true
-	for _, ok := range checks {
-		if !ok {
-			allHealthy = false
-			break
+	ready := checks["postgres"] && checks["clickhouse"]
+	degraded := map[string]interface{}{}
+	if !checks["redis"] {
+		degraded["redis"] = "unreachable"
+	}
+	if reporter, ok := h.pool.(liveStateReporter); ok {
+		if status := reporter.LiveStateStatus(); status.Degraded || status.Buffered > 0 {
+			degraded["live_state"] = status
 		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	if !allHealthy {
+	if !ready {
 		w.WriteHeader(http.StatusServiceUnavailable)
 	}
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"ready":      allHealthy,
+	body := map[string]interface{}{
+		"ready":      ready,
 		"checks":     checks,
+		"degraded":   degraded,
 		"queueDepth": h.pool.QueueDepth(),
-	})
+	}
+	if h.ingest != nil {
+		body["ingestQueueDepth"] = h.ingest.QueueDepth()
+	}
+	json.NewEncoder(w).Encode(body)
 }
 
 // ============================================================================
 // INGESTION ENDPOINTS
 // ============================================================================
 
-// IngestEvents handles POST /api/v1/ingest/events
+// IngestEvents handles POST /api/v1/ingest/events. Batches are parsed
+// while they are read, so their size is bounded by IngestMaxBodyBytes, if
+// set, rather than held in memory.
 // @Summary Ingest Game Events
-// @Description Accepts JSON array of events from game servers
+// @Description Accepts a JSON array, NDJSON or URL-encoded lines, streamed: batches have no size limit unless one is configured. A line or array element over the line limit (64KB by default), one that cannot be parsed or one without a type is skipped and reported in rejected, by index and, for lines, line number. Send X-Ingest-Version: 2 for strict v2 parsing, which reads batches of up to 1MB whole.
 // @Tags Ingestion
 // @Accept json
 // @Produce json
 // @Security ServerToken
 // @Param body body []models.RawEvent true "Events"
-// @Success 202 {object} map[string]string "Accepted"
-// @Failure 400 {object} map[string]string "Bad Request"
+// @Success 202 {object} map[string]interface{} "Accepted; status is incomplete, with an error, if the batch ended early after processed events were queued"
+// @Failure 400 {object} map[string]interface{} "Bad Request, nothing queued"
+// @Failure 413 {object} map[string]interface{} "Batch over the size limit, nothing queued"
 // @Router /ingest/events [post]
 func (h *Handler) IngestEvents(w http.ResponseWriter, r *http.Request) {
-	// Limit request body to 1MB to prevent DoS
-	r.Body = http.MaxBytesReader(w, r.Body, MaxBodySize)
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		h.errorResponse(w, http.StatusRequestEntityTooLarge, "Request body too large")
-		return
-	}
-	defer r.Body.Close()
+	version := negotiateIngestVersion(r, ingestVersionLegacy)
+	setIngestHeaders(w, version)
 
-	// Sanitize body: strip null bytes and trim whitespace (game engines may embed C-string artifacts)
-	body = bytes.ReplaceAll(body, []byte{0}, []byte{})
-	body = bytes.TrimSpace(body)
-
-	h.logger.Infow("IngestEvents called", "bodyLength", len(body), "preview", string(body[:min(len(body), 200)]))
-
-	var events []models.RawEvent
-	processed := 0
-
-	// Try parsing as JSON array first (modern format)
-	if len(body) > 0 && body[0] == '[' {
-		if err := json.Unmarshal(body, &events); err != nil {
-			h.logger.Warnw("Failed to unmarshal JSON array", "error", err, "bodyHex", fmt.Sprintf("%x", body[:min(len(body), 100)]))
-			h.errorResponse(w, http.StatusBadRequest, fmt.Sprintf("Invalid JSON array: %v", err))
+	// Scripts that negotiated v2 keep their URL but get strict parsing
+	if version >= ingestVersionLatest {
+		body, err := readIngestBody(w, r)
+		if err != nil {
+			h.ingestBodyError(w, err)
 			return
 		}
-		h.logger.Infow("Parsed as JSON array", "eventCount", len(events))
-	} else {
-		// Fallback: newline-delimited format (legacy game scripts)
-		h.logger.Infow("Parsing as newline-delimited (legacy format)")
-		lines := strings.Split(string(body), "\n")
-
-		for _, line := range lines {
-			line = strings.TrimSpace(line)
-			if line == "" {
-				continue
-			}
-
-			var event models.RawEvent
-			// Support both JSON objects and URL-encoded
-			if strings.HasPrefix(line, "{") {
-				if err := json.Unmarshal([]byte(line), &event); err != nil {
-					h.logger.Warnw("Failed to unmarshal JSON line", "error", err, "line", line)
-					continue
-				}
-			} else {
-				values, err := url.ParseQuery(line)
-				if err != nil {
-					h.logger.Warnw("Failed to parse URL-encoded line", "error", err, "line", line)
-					continue
-				}
-				event = h.parseFormToEvent(values)
-			}
-			events = append(events, event)
-		}
-		h.logger.Infow("Parsed legacy format", "lineCount", len(lines), "parsedEvents", len(events))
+		h.ingestStrict(w, r, body)
+		return
 	}
 
-	// Process all events
-	for i, event := range events {
-		// Inject ServerID from context if authenticated
-		if sid, ok := r.Context().Value("server_id").(string); ok && sid != "" {
-			if event.ServerID == "" {
-				event.ServerID = sid
-			}
-		}
-
-		if event.Type == "" {
-			h.logger.Warnw("Event has empty type, skipping", "index", i)
-			continue
-		}
+	if h.ingestMaxBody > 0 && r.ContentLength > h.ingestMaxBody {
+		h.errorResponse(w, http.StatusRequestEntityTooLarge, "Request body too large")
+		return
+	}
+	defer r.Body.Close()
+	rd, closeBody, err := openIngestStream(r, h.ingestMaxBody)
+	if err != nil {
+		h.errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer closeBody()
 
-		h.logger.Infow("Enqueueing event", "index", i, "type", event.Type, "match_id", event.MatchID)
-		if !h.pool.Enqueue(&event) {
-			h.logger.Warn("Worker pool queue full, dropping remaining events in batch")
-			break
+	target := h.ingestTarget(r)
+	processed, full := 0, false
+	rejected, err := h.streamV1Payload(rd, h.ingestMaxLine, func(event models.RawEvent) bool {
+		if !target.enqueue(event) {
+			full = true
+			return false
 		}
 		processed++
+		return true
+	})
+	if full {
+		h.ingestLogger().Warn("Ingest queue full, dropping remaining events in batch")
 	}
+	h.ingestLogger().Debugw("IngestEvents streamed", "processed", processed, "rejected", len(rejected), "error", err)
 
-	w.WriteHeader(http.StatusAccepted)
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	resp := map[string]interface{}{
 		"status":    "accepted",
 		"processed": processed,
-	})
-}
+		"rejected":  rejected,
+	}
+	if err == nil {
+		h.jsonResponse(w, http.StatusAccepted, resp)
+		return
+	}
 
-// parseFormToEvent converts URL-encoded form data to RawEvent
-func (h *Handler) parseFormToEvent(form url.Values) models.RawEvent {
-	event := models.RawEvent{
-		Type:        models.EventType(form.Get("type")),
-		MatchID:     form.Get("match_id"),
-		SessionID:   form.Get("session_id"),
-		ServerID:    form.Get("server_id"),
-		ServerToken: form.Get("server_token"),
-		MapName:     form.Get("map_name"),
-
-		PlayerName: form.Get("player_name"),
-		PlayerGUID: form.Get("player_guid"),
-		PlayerTeam: form.Get("player_team"),
-
-		AttackerName: form.Get("attacker_name"),
-		AttackerGUID: form.Get("attacker_guid"),
-		AttackerTeam: form.Get("attacker_team"),
-
-		VictimName: form.Get("victim_name"),
-		VictimGUID: form.Get("victim_guid"),
-		VictimTeam: form.Get("victim_team"),
-
-		Weapon:    form.Get("weapon"),
-		OldWeapon: form.Get("old_weapon"),
-		NewWeapon: form.Get("new_weapon"),
-		Hitloc:    form.Get("hitloc"),
-		Inflictor: form.Get("inflictor"),
-
-		TargetName: form.Get("target_name"),
-		TargetGUID: form.Get("target_guid"),
-
-		OldTeam: form.Get("old_team"),
-		NewTeam: form.Get("new_team"),
-		Message: form.Get("message"),
-
-		Gametype:    form.Get("gametype"),
-		Timelimit:   form.Get("timelimit"),
-		Fraglimit:   form.Get("fraglimit"),
-		Maxclients:  form.Get("maxclients"),
-		WinningTeam: form.Get("winning_team"),
-
-		Item:       form.Get("item"),
-		Entity:     form.Get("entity"),
-		Projectile: form.Get("projectile"),
-		Code:       form.Get("code"),
-
-		Objective:       form.Get("objective"), // Also check objective_index if needed
-		ObjectiveStatus: form.Get("objective_status"),
-		BotID:           form.Get("bot_id"),
-		Seat:            form.Get("seat"),
-	}
-
-	// Parse numeric fields
-	event.Timestamp, _ = strconv.ParseFloat(form.Get("timestamp"), 64)
-	event.Damage, _ = strconv.ParseFloat(form.Get("damage"), 64)
-	event.AmmoRemaining, _ = strconv.Atoi(form.Get("ammo_remaining"))
-	event.AlliesScore, _ = strconv.Atoi(form.Get("allies_score"))
-	event.AxisScore, _ = strconv.Atoi(form.Get("axis_score"))
-	event.RoundNumber, _ = strconv.Atoi(form.Get("round_number"))
-	event.TotalRounds, _ = strconv.Atoi(form.Get("total_rounds"))
-	event.PlayerCount, _ = strconv.Atoi(form.Get("player_count"))
-	event.ClientNum, _ = strconv.Atoi(form.Get("client_num"))
-	event.Count, _ = strconv.Atoi(form.Get("count"))
-	event.Duration, _ = strconv.ParseFloat(form.Get("duration"), 64)
-
-	// Parse SMF ID fields (Int64 for member IDs)
-	event.PlayerSMFID = parseInt64(form.Get("player_smf_id"))
-	event.AttackerSMFID = parseInt64(form.Get("attacker_smf_id"))
-	event.VictimSMFID = parseInt64(form.Get("victim_smf_id"))
-	event.TargetSMFID = parseInt64(form.Get("target_smf_id"))
-
-	// Parse float fields (positions)
-	event.PosX = parseFloat32(form.Get("pos_x"))
-	event.PosY = parseFloat32(form.Get("pos_y"))
-	event.PosZ = parseFloat32(form.Get("pos_z"))
-	event.AttackerX = parseFloat32(form.Get("attacker_x"))
-	event.AttackerY = parseFloat32(form.Get("attacker_y"))
-	event.AttackerZ = parseFloat32(form.Get("attacker_z"))
-	event.AttackerPitch = parseFloat32(form.Get("attacker_pitch"))
-	event.AttackerYaw = parseFloat32(form.Get("attacker_yaw"))
-	event.AttackerStance = form.Get("attacker_stance")
-	event.VictimX = parseFloat32(form.Get("victim_x"))
-	event.VictimY = parseFloat32(form.Get("victim_y"))
-	event.VictimZ = parseFloat32(form.Get("victim_z"))
-	event.VictimStance = form.Get("victim_stance")
-	event.PlayerStance = form.Get("player_stance")
-	event.TargetStance = form.Get("target_stance")
-	event.AimPitch = parseFloat32(form.Get("aim_pitch"))
-	event.AimYaw = parseFloat32(form.Get("aim_yaw"))
-	event.FallHeight = parseFloat32(form.Get("fall_height"))
-	event.Walked = parseFloat32(form.Get("walked"))
-	event.Sprinted = parseFloat32(form.Get("sprinted"))
-	event.Swam = parseFloat32(form.Get("swam"))
-	event.Driven = parseFloat32(form.Get("driven"))
-	event.Distance = parseFloat32(form.Get("distance"))
-
-	return event
-}
-
-func parseInt64(s string) int64 {
-	i, _ := strconv.ParseInt(s, 10, 64)
-	return i
-}
-
-func parseFloat32(s string) float32 {
-	f, _ := strconv.ParseFloat(s, 32)
-	return float32(f)
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, errBodyTooLarge):
+		status, resp["error"] = http.StatusRequestEntityTooLarge, "Request body too large"
+	case errors.Is(err, errInvalidIngestArray):
+		resp["error"] = err.Error()
+	default:
+		h.ingestLogger().Warnw("Failed to read ingest body", "processed", processed, "error", err)
+		resp["error"] = "Failed to read request body"
+	}
+	// Events before the error are already queued and must not be resent, so
+	// the batch is still accepted, as far as it was read
+	resp["status"] = "incomplete"
+	if processed > 0 {
+		status = http.StatusAccepted
+	}
+	h.jsonResponse(w, status, resp)
 }
 
 // IngestMatchResult handles POST /api/v1/ingest/match-result
@@ -356,6 +448,8 @@ func parseFloat32(s string) float32 {
 // @Param body body models.MatchResult true "Match Result"
 // @Success 200 {object} map[string]string "Processed"
 // @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 403 {object} map[string]string "Match of another server, or a sandbox token"
+// @Failure 409 {object} map[string]string "Finalized tournament match"
 // @Router /ingest/match-result [post]
 func (h *Handler) IngestMatchResult(w http.ResponseWriter, r *http.Request) {
 	var result models.MatchResult
@@ -364,9 +458,25 @@ func (h *Handler) IngestMatchResult(w http.ResponseWriter, r *http.Request) {
 		h.errorResponse(w, http.StatusBadRequest, "Invalid JSON")
 		return
 	}
+	// The result is always the authenticated server's, never the payload's
+	result.ServerID, _ = r.Context().Value("server_id").(string)
 
 	// Tournament match results are handled by SMF plugin
 	// See: smf-plugins/mohaa_tournaments/ for bracket management
+	// Here the result only ends the match and ties it to its tournament.
+	if h.matchStates != nil && result.MatchID != "" {
+		if err := h.matchStates.RecordResult(r.Context(), &result); err != nil {
+			if errors.Is(err, logic.ErrMatchLocked) {
+				h.errorResponse(w, http.StatusConflict, "Match result is finalized")
+				return
+			}
+			if errors.Is(err, logic.ErrMatchNotOwned) {
+				h.errorResponse(w, http.StatusForbidden, "Match was not played on this server")
+				return
+			}
+			h.logger.Errorw("Failed to record match result", "match", result.MatchID, "error", err)
+		}
+	}
 
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{
@@ -425,6 +535,7 @@ func (h *Handler) GetMatches(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Fetch matches
+	tenantID := logic.TenantFromContext(ctx)
 	rows, err := h.ch.Query(ctx, `
 		SELECT 
 			toString(match_id) as match_id,
@@ -435,10 +546,11 @@ func (h *Handler) GetMatches(w http.ResponseWriter, r *http.Request) {
 			uniq(actor_id) as player_count,
 			countIf(event_type IN ('player_kill', 'bot_killed')) as kills
 		FROM mohaa_stats.raw_events
+		WHERE ? = '' OR tenant_id = ?
 		GROUP BY match_id, map_name
 		ORDER BY start_time DESC
 		LIMIT ? OFFSET ?
-	`, limit, offset)
+	`, tenantID, tenantID, limit, offset)
 
 	if err != nil {
 		h.logger.Errorw("Failed to fetch matches", "error", err)
@@ -472,6 +584,37 @@ func (h *Handler) GetMatches(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Lifecycle states from PostgreSQL; matches recorded before states were
+	// tracked have none
+	if h.matchStates != nil {
+		ids := make([]string, len(matches))
+		for i := range matches {
+			ids[i] = matches[i].ID
+		}
+		if states, err := h.matchStates.States(ctx, ids); err == nil {
+			for i := range matches {
+				matches[i].State = states[matches[i].ID]
+			}
+		} else {
+			h.logger.Warnw("Failed to load match states", "error", err)
+		}
+	}
+
+	// Demo recordings from PostgreSQL
+	if h.demos != nil && len(matches) > 0 {
+		ids := make([]string, len(matches))
+		for i := range matches {
+			ids[i] = matches[i].ID
+		}
+		if counts, err := h.demos.Counts(ctx, ids); err == nil {
+			for i := range matches {
+				matches[i].Demos = counts[matches[i].ID]
+			}
+		} else {
+			h.logger.Warnw("Failed to count match demos", "error", err)
+		}
+	}
+
 	// Apply server names to matches
 	for i := range matches {
 		if name, ok := serverNames[matches[i].ServerID]; ok {
@@ -488,11 +631,13 @@ func (h *Handler) GetMatches(w http.ResponseWriter, r *http.Request) {
 // @Summary Get Global Weapon Stats
 // @Tags Server
 // @Produce json
+// @Param phases query string false "all to include warmup and intermission kills"
 // @Success 200 {array} models.WeaponStats "Weapon Stats"
 // @Failure 500 {object} map[string]string "Internal Error"
 // @Router /stats/weapons [get]
 func (h *Handler) GetGlobalWeaponStats(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	tenantID := logic.TenantFromContext(ctx)
 
 	rows, err := h.ch.Query(ctx, `
 		SELECT 
@@ -500,11 +645,11 @@ func (h *Handler) GetGlobalWeaponStats(w http.ResponseWriter, r *http.Request) {
 			countIf(event_type IN ('player_kill', 'bot_killed')) as kills,
 			countIf(event_type IN ('player_kill', 'bot_killed') AND hitloc IN ('head', 'helmet')) as headshots
 		FROM mohaa_stats.raw_events
-		WHERE actor_weapon != '' 
+		WHERE actor_weapon != '' AND (? = '' OR tenant_id = ?)`+logic.RoundPhaseFilter(ctx, "")+`
 		GROUP BY actor_weapon
 		ORDER BY kills DESC
 		LIMIT 10
-	`)
+	`, tenantID, tenantID)
 	if err != nil {
 		h.logger.Errorw("Failed to query weapon stats", "error", err)
 		h.errorResponse(w, http.StatusInternalServerError, "Query failed")
@@ -545,10 +690,14 @@ func (h *Handler) GetGlobalWeaponStats(w http.ResponseWriter, r *http.Request) {
 // @Tags Stats
 // @Produce json
 // @Param stat path string false "Stat to sort by (e.g. kills, headshots, distance)" default(kills)
-// @Param period query string false "Period (all, week, month)" default(all)
+// @Param period query string false "Period (all, day, week, month, year, 7d, 30d, 365d)" default(all)
+// @Param tz query string false "IANA time zone days and weeks are counted in" default(UTC)
+// @Param from query string false "Start (RFC 3339 or YYYY-MM-DD in tz), replaces period"
+// @Param to query string false "End (RFC 3339, or YYYY-MM-DD to include that day)"
 // @Param limit query int false "Limit" default(25)
 // @Param page query int false "Page" default(1)
 // @Success 200 {object} map[string]interface{} "Leaderboard Data"
+// @Failure 400 {object} map[string]string "Invalid period"
 // @Failure 500 {object} map[string]string "Internal Error"
 // @Router /stats/leaderboard/{stat} [get]
 func (h *Handler) GetLeaderboard(w http.ResponseWriter, r *http.Request) {
@@ -565,9 +714,9 @@ func (h *Handler) GetLeaderboard(w http.ResponseWriter, r *http.Request) {
 
 	limit := 25
 	page := 1
-	period := r.URL.Query().Get("period")
-	if period == "" {
-		period = "all"
+	period, ok := h.parsePeriod(w, r)
+	if !ok {
+		return
 	}
 	if l := r.URL.Query().Get("limit"); l != "" {
 		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
@@ -582,105 +731,34 @@ func (h *Handler) GetLeaderboard(w http.ResponseWriter, r *http.Request) {
 	offset := (page - 1) * limit
 
 	// Map stat name to ClickHouse column/expression
-	orderExpr := "kills"
-	havingExpr := "kills > 0"
-
-	switch stat {
-	case "kills":
-		orderExpr = "kills"
-	case "bot_kills":
-		orderExpr = "bot_kills"
-		havingExpr = "bot_kills > 0"
-	case "total_kills":
-		orderExpr = "kills + bot_kills"
-	case "deaths":
-		orderExpr = "deaths"
-		havingExpr = "deaths > 0"
-	case "kd_ratio", "kd":
-		orderExpr = "kills / nullIf(deaths, 0)"
-	case "headshots":
-		orderExpr = "headshots"
-	case "accuracy":
-		orderExpr = "shots_hit / nullIf(shots_fired, 0)"
-	case "shots_fired":
-		orderExpr = "shots_fired"
-	case "damage":
-		orderExpr = "total_damage"
-	case "bash_kills":
-		orderExpr = "bash_kills"
-	case "grenade_kills":
-		orderExpr = "grenade_kills"
-	case "roadkills":
-		orderExpr = "roadkills"
-	case "telefrags":
-		orderExpr = "telefrags"
-	case "crushed":
-		orderExpr = "crushed"
-	case "teamkills":
-		orderExpr = "teamkills"
-	case "suicides":
-		orderExpr = "suicides"
-	case "reloads":
-		orderExpr = "reloads"
-	case "weapon_swaps":
-		orderExpr = "weapon_swaps"
-	case "no_ammo":
-		orderExpr = "no_ammo"
-	case "looter":
-		orderExpr = "items_picked"
-	case "distance":
-		orderExpr = "distance_units"
-	case "sprinted":
-		orderExpr = "sprinted"
-	case "swam":
-		orderExpr = "swam"
-	case "driven":
-		orderExpr = "driven"
-	case "jumps":
-		orderExpr = "jumps"
-	case "crouch_time":
-		orderExpr = "crouch_events"
-	case "prone_time":
-		orderExpr = "prone_events"
-	case "ladders":
-		orderExpr = "ladders"
-	case "health_picked":
-		orderExpr = "health_picked"
-	case "ammo_picked":
-		orderExpr = "ammo_picked"
-	case "armor_picked":
-		orderExpr = "armor_picked"
-	case "items_picked":
-		orderExpr = "items_picked"
-	case "wins":
-		orderExpr = "matches_won"
-	case "team_wins":
-		orderExpr = "matches_won" // Simplify for now
-	case "ffa_wins":
-		orderExpr = "matches_won"
-	case "losses":
-		orderExpr = "matches_played - matches_won"
-	case "objectives":
-		orderExpr = "objectives"
-	case "rounds":
-		orderExpr = "matches_played"
-	case "playtime":
-		orderExpr = "playtime_seconds"
-	case "games":
-		orderExpr = "games_finished"
-	default:
-		orderExpr = "kills"
-	}
+	orderExpr, havingExpr := logic.LeaderboardStatExpr(stat)
+	periodExpr, args := period.DayFilter("day")
+	whereExpr := "player_id != ''" + periodExpr
 
-	whereExpr := "player_id != ''"
-	switch period {
-	case "week":
-		whereExpr += " AND day >= now() - INTERVAL 7 DAY"
-	case "month":
-		whereExpr += " AND day >= now() - INTERVAL 30 DAY"
-	case "year":
-		whereExpr += " AND day >= now() - INTERVAL 365 DAY"
+	// Tenants rank over their own servers via the per-server aggregate
+	table := "player_stats_daily"
+	serverIDs, scoped, err := h.tenantServerIDs(ctx)
+	if err != nil {
+		h.logger.Errorw("Failed to resolve tenant servers", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Query failed")
+		return
+	}
+	if scoped {
+		if len(serverIDs) == 0 {
+			h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+				"players":   []models.LeaderboardEntry{},
+				"total":     0,
+				"page":      page,
+				"stat":      stat,
+				"stat_name": statName(ctx, stat),
+			})
+			return
+		}
+		table = "player_server_stats_daily"
+		whereExpr += " AND server_id IN ?"
+		args = append(args, serverIDs)
 	}
+	args = append(args, limit, offset)
 
 	// Query the unified Aggregation Table
 	query := fmt.Sprintf(`
@@ -717,19 +795,20 @@ func (h *Handler) GetLeaderboard(w http.ResponseWriter, r *http.Request) {
 			sum(armor_picked) AS armor_picked,
 			sum(items_picked) AS items_picked,
 			sum(matches_won) AS wins,
+			sum(ffa_wins) AS ffa_wins,
 			uniqExactMerge(matches_played) AS rounds,
 			sum(games_finished) AS games,
 			toUInt64(0) AS playtime,
 			max(last_active) AS max_last_active
-		FROM mohaa_stats.player_stats_daily
+		FROM mohaa_stats.%s
 		WHERE player_id != '' AND %s
 		GROUP BY player_id
 		HAVING %s
 		ORDER BY %s DESC
 		LIMIT ? OFFSET ?
-	`, whereExpr, havingExpr, orderExpr)
+	`, table, whereExpr, havingExpr, orderExpr)
 
-	rows, err := h.ch.Query(ctx, query, limit, offset)
+	rows, err := h.ch.Query(ctx, query, args...)
 	if err != nil {
 		h.logger.Errorw("Failed to query leaderboard", "stat", stat, "error", err)
 		h.errorResponse(w, http.StatusInternalServerError, "Query failed")
@@ -750,7 +829,7 @@ func (h *Handler) GetLeaderboard(w http.ResponseWriter, r *http.Request) {
 			&entry.WeaponSwaps, &entry.NoAmmo, &entry.Distance, &entry.Sprinted,
 			&entry.Swam, &entry.Driven, &entry.Jumps, &entry.Crouches,
 			&entry.Prone, &entry.Ladders, &entry.HealthPicked, &entry.AmmoPicked,
-			&entry.ArmorPicked, &entry.ItemsPicked, &entry.Wins, &entry.Rounds,
+			&entry.ArmorPicked, &entry.ItemsPicked, &entry.Wins, &entry.FFAWins, &entry.Rounds,
 			&entry.GamesFinished, &entry.Playtime, &lastActive,
 		); err != nil {
 			h.logger.Warnw("Failed to scan leaderboard row", "error", err)
@@ -758,6 +837,7 @@ func (h *Handler) GetLeaderboard(w http.ResponseWriter, r *http.Request) {
 		}
 
 		entry.TotalKills = entry.Kills + entry.BotKills
+		entry.TeamWins = entry.Wins - entry.FFAWins
 
 		if entry.ShotsFired > 0 {
 			entry.Accuracy = (float64(entry.ShotsHit) / float64(entry.ShotsFired)) * 100.0
@@ -781,6 +861,10 @@ func (h *Handler) GetLeaderboard(w http.ResponseWriter, r *http.Request) {
 			entry.Value = entry.Damage
 		case "wins":
 			entry.Value = entry.Wins
+		case "ffa_wins":
+			entry.Value = entry.FFAWins
+		case "team_wins":
+			entry.Value = entry.TeamWins
 		case "rounds":
 			entry.Value = entry.Rounds
 		case "looter":
@@ -796,16 +880,33 @@ func (h *Handler) GetLeaderboard(w http.ResponseWriter, r *http.Request) {
 		rank++
 	}
 
+	guids := make([]string, len(entries))
+	for i := range entries {
+		guids[i] = entries[i].PlayerID
+	}
+	cosmetics := h.playerCosmetics(ctx, guids)
+	for i := range entries {
+		entries[i].Cosmetics = cosmetics[entries[i].PlayerID]
+	}
+	entries = applyDisplayNames(ctx, h, entries, leaderboardEntryName)
+
 	var total uint64
-	if err := h.ch.QueryRow(ctx, "SELECT uniq(player_id) FROM mohaa_stats.player_stats_daily").Scan(&total); err != nil {
+	totalQuery := "SELECT uniq(player_id) FROM mohaa_stats.player_stats_daily"
+	var totalArgs []any
+	if scoped {
+		totalQuery = "SELECT uniq(player_id) FROM mohaa_stats.player_server_stats_daily WHERE server_id IN ?"
+		totalArgs = append(totalArgs, serverIDs)
+	}
+	if err := h.ch.QueryRow(ctx, totalQuery, totalArgs...).Scan(&total); err != nil {
 		h.logger.Errorw("Failed to scan total leaderboard count", "error", err)
 	}
 
 	h.jsonResponse(w, http.StatusOK, map[string]interface{}{
-		"players": entries,
-		"total":   total,
-		"page":    page,
-		"stat":    stat,
+		"players":   entries,
+		"total":     total,
+		"page":      page,
+		"stat":      stat,
+		"stat_name": statName(ctx, stat),
 	})
 }
 
@@ -847,6 +948,17 @@ func (h *Handler) GetPlayerStatsBySMFID(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
+	privacy, err := h.playerPrivacy(ctx, guid)
+	if err != nil {
+		h.logger.Errorw("Failed to read player privacy", "guid", guid, "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Internal Service Error")
+		return
+	}
+	if privacy.HideProfile {
+		h.errorResponse(w, http.StatusNotFound, "No player profile linked to this forum account")
+		return
+	}
+
 	// 2. Fetch stats for the resolved GUID
 	// Reuse existing service logic by creating a new request context for the GUID
 	// Or simpler: just call the service method directly since we have the GUID.
@@ -900,6 +1012,7 @@ func (h *Handler) GetWeeklyLeaderboard(w http.ResponseWriter, r *http.Request) {
 		entries = append(entries, entry)
 		rank++
 	}
+	entries = applyDisplayNames(ctx, h, entries, leaderboardEntryName)
 
 	h.jsonResponse(w, http.StatusOK, entries)
 }
@@ -941,6 +1054,7 @@ func (h *Handler) GetWeaponLeaderboard(w http.ResponseWriter, r *http.Request) {
 		entries = append(entries, entry)
 		rank++
 	}
+	entries = applyDisplayNames(ctx, h, entries, leaderboardEntryName)
 
 	h.jsonResponse(w, http.StatusOK, map[string]interface{}{
 		"weapon":      weapon,
@@ -948,6 +1062,39 @@ func (h *Handler) GetWeaponLeaderboard(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// GetVehicleLeaderboard returns top players in a specific vehicle type
+// @Summary Vehicle Leaderboard
+// @Description Players ranked by kills made from one vehicle type, counting roadkills and passenger-seat kills
+// @Tags Leaderboard
+// @Produce json
+// @Param vehicle path string true "Vehicle type"
+// @Param limit query int false "Limit" default(100)
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]string "Internal Error"
+// @Router /stats/leaderboard/vehicle/{vehicle} [get]
+func (h *Handler) GetVehicleLeaderboard(w http.ResponseWriter, r *http.Request) {
+	vehicle := chi.URLParam(r, "vehicle")
+	limit := 100
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+
+	entries, err := h.advancedStats.GetVehicleLeaderboard(r.Context(), vehicle, limit)
+	if err != nil {
+		h.logger.Errorw("Failed to get vehicle leaderboard", "vehicle", vehicle, "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Query failed")
+		return
+	}
+	entries = applyDisplayNames(r.Context(), h, entries, func(e *models.VehicleLeaderboardEntry) (string, *string, *bool) {
+		return e.PlayerID, &e.PlayerName, &e.NameFlagged
+	})
+
+	h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"vehicle":     vehicle,
+		"leaderboard": entries,
+	})
+}
+
 // GetMapLeaderboard returns top players on a specific map
 func (h *Handler) GetMapLeaderboard(w http.ResponseWriter, r *http.Request) {
 	mapName := chi.URLParam(r, "map")
@@ -985,6 +1132,7 @@ func (h *Handler) GetMapLeaderboard(w http.ResponseWriter, r *http.Request) {
 		entries = append(entries, entry)
 		rank++
 	}
+	entries = applyDisplayNames(ctx, h, entries, leaderboardEntryName)
 
 	h.jsonResponse(w, http.StatusOK, map[string]interface{}{
 		"map":         mapName,
@@ -998,185 +1146,48 @@ func (h *Handler) GetMapLeaderboard(w http.ResponseWriter, r *http.Request) {
 // @Tags Player
 // @Produce json
 // @Param guid path string true "Player GUID"
+// @Param sections query string false "Comma-separated sections to compute (combat, weapons, movement, accuracy, session, stance, rivals, interaction, deaths_by_cause, survival, form, maps, performance, recent_matches); all by default"
+// @Param fields query string false "Comma-separated dotted fields to return, e.g. player.kills,player.deaths"
 // @Success 200 {object} models.PlayerStatsResponse "Player Stats"
+// @Failure 400 {object} map[string]string "Unknown section"
 // @Failure 404 {object} map[string]string "Not Found"
 // @Router /stats/player/{guid} [get]
 func (h *Handler) GetPlayerStats(w http.ResponseWriter, r *http.Request) {
 	guid := chi.URLParam(r, "guid")
 	ctx := r.Context()
 
-	// 1. Get Deep Stats (Combines Combat, Weapons, Movement, Stance, etc.)
-	deepStats, err := h.playerStats.GetDeepStats(ctx, guid)
+	sections, err := logic.ParseStatSections(r.URL.Query().Get("sections"))
 	if err != nil {
-		h.logger.Errorw("Failed to get deep stats", "guid", guid, "error", err)
-		// Fallback to empty if failed, but try to proceed
-		deepStats = &models.DeepStats{}
+		h.errorResponse(w, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	// 2. Get Performance History (Trend)
-	// We re-implement the query here to ensure data flow
-	perfRows, err := h.ch.Query(ctx, `
-		SELECT 
-			toString(match_id) as match_id,
-			countIf(event_type IN ('player_kill', 'bot_killed') AND actor_id = ?) as kills,
-			countIf(event_type IN ('player_kill', 'bot_killed') AND target_id = ?) as deaths,
-			min(timestamp) as played_at
-		FROM mohaa_stats.raw_events
-		WHERE match_id IN (
-			SELECT match_id FROM mohaa_stats.raw_events 
-			WHERE actor_id = ? OR target_id = ?
-			GROUP BY match_id 
-			ORDER BY max(timestamp) DESC 
-			LIMIT 20
-		)
-		GROUP BY match_id
-		ORDER BY played_at ASC
-	`, guid, guid, guid, guid)
-
-	performance := make([]models.PerformancePoint, 0)
-	if err == nil {
-		defer perfRows.Close()
-		for perfRows.Next() {
-			var mid string
-			var k, d uint64
-			var t time.Time
-			if err := perfRows.Scan(&mid, &k, &d, &t); err == nil {
-				kd := float64(k)
-				if d > 0 {
-					kd = float64(k) / float64(d)
-				}
-				performance = append(performance, models.PerformancePoint{
-					MatchID:  mid,
-					Kills:    k,
-					Deaths:   d,
-					KD:       kd,
-					PlayedAt: t.Unix(),
-				})
-			}
-		}
+	// The full profile is served from the precomputed blob when there is one
+	var player *models.PlayerStats
+	if sections == nil {
+		player = h.profiles.Read(ctx, guid)
 	}
-
-	// 3. Get Map Stats (Summary for dashboard)
-	mapRows, err := h.ch.Query(ctx, `
-		SELECT 
-			map_name,
-			countIf(event_type IN ('player_kill', 'bot_killed') AND actor_id = ?) as kills,
-			countIf(event_type IN ('player_kill', 'bot_killed') AND target_id = ?) as deaths,
-			count(DISTINCT match_id) as matches,
-			0 as wins
-		FROM mohaa_stats.raw_events
-		WHERE (actor_id = ? OR target_id = ?) AND map_name != ''
-		GROUP BY map_name
-		ORDER BY matches DESC
-		LIMIT 5
-	`, guid, guid, guid, guid) // Fixed params for OR clause
-
-	maps := make([]models.PlayerMapStats, 0)
-	if err == nil {
-		defer mapRows.Close()
-		for mapRows.Next() {
-			var name string
-			var k, d, m, w uint64
-			if err := mapRows.Scan(&name, &k, &d, &m, &w); err == nil {
-				maps = append(maps, models.PlayerMapStats{
-					MapName:       name,
-					Kills:         k,
-					Deaths:        d,
-					MatchesPlayed: m,
-					MatchesWon:    w,
-				})
-			}
+	if player == nil {
+		full := sections == nil
+		if full {
+			sections = logic.AllStatSections()
 		}
-	}
-
-	// 4. Get Matches List (Recent)
-	matchRows, err := h.ch.Query(ctx, `
-		SELECT 
-			toString(match_id) as match_id,
-			map_name,
-			countIf(event_type IN ('player_kill', 'bot_killed') AND actor_id = ?) as kills,
-			countIf(event_type IN ('player_kill', 'bot_killed') AND target_id = ?) as deaths,
-			min(timestamp) as started
-		FROM mohaa_stats.raw_events
-		WHERE actor_id = ? OR target_id = ?
-		GROUP BY match_id, map_name
-		ORDER BY started DESC
-		LIMIT 10
-	`, guid, guid, guid, guid)
-
-	matches := make([]models.RecentMatch, 0)
-	if err == nil {
-		defer matchRows.Close()
-		for matchRows.Next() {
-			var mid, mn string
-			var k, d uint64
-			var t time.Time
-			if err := matchRows.Scan(&mid, &mn, &k, &d, &t); err == nil {
-				matches = append(matches, models.RecentMatch{
-					MatchID: mid,
-					MapName: mn,
-					Kills:   k,
-					Deaths:  d,
-					Date:    t.Unix(),
-				})
-			}
+		// Only the requested sections are computed; the rest stay zero
+		if player, err = h.playerStats.GetProfile(ctx, guid, sections); err != nil {
+			h.logger.Errorw("Failed to get player profile", "guid", guid, "sections", sections, "error", err)
+			h.errorResponse(w, http.StatusInternalServerError, "Failed to get player stats")
+			return
+		}
+		if full {
+			h.profiles.Store(ctx, player)
 		}
 	}
+	player.Cosmetics = h.playerCosmetics(ctx, []string{guid})[guid]
+	player.Rating = h.playerRating(ctx, guid)
+	player.Name = h.profileName(ctx, guid, player.Name)
 
-	// Construct Flat Player Object
-	player := models.PlayerStats{
-		GUID:       guid,
-		Name:       "Unknown Soldier",
-		PlayerName: "Unknown Soldier",
-
-		// Combat
-		Kills:       deepStats.Combat.Kills,
-		Deaths:      deepStats.Combat.Deaths,
-		KDRatio:     deepStats.Combat.KDRatio,
-		Headshots:   deepStats.Combat.Headshots,
-		Accuracy:    deepStats.Accuracy.Overall,
-		DamageDealt: deepStats.Combat.DamageDealt,
-		DamageTaken: deepStats.Combat.DamageTaken,
-		Suicides:    deepStats.Combat.Suicides,
-		TeamKills:   deepStats.Combat.TeamKills,
-		BashKills:   deepStats.Combat.BashKills,
-
-		// Body Parts
-		TorsoKills: deepStats.Combat.TorsoKills,
-		LimbKills:  deepStats.Combat.LimbKills,
-
-		// Session
-		MatchesPlayed:   deepStats.Session.MatchesPlayed,
-		MatchesWon:      deepStats.Session.Wins,
-		WinRate:         deepStats.Session.WinRate,
-		PlaytimeSeconds: deepStats.Session.PlaytimeHours * 3600,
-
-		// Movement
-		DistanceMeters: deepStats.Movement.TotalDistanceKm * 1000, // Return meters
-		Jumps:          deepStats.Movement.JumpCount,
-
-		// Stance
-		StandingKills:  deepStats.Stance.StandingKills,
-		CrouchingKills: deepStats.Stance.CrouchKills,
-		ProneKills:     deepStats.Stance.ProneKills,
-
-		// Lists
-		Weapons:       deepStats.Weapons,
-		Maps:          maps,
-		Performance:   performance,
-		RecentMatches: matches,
-		Achievements:  []string{},
-	}
-
-	// Try to get name (most recent)
-	var name string
-	if err := h.ch.QueryRow(ctx, "SELECT argMax(actor_name, timestamp) FROM mohaa_stats.raw_events WHERE actor_id = ?", guid).Scan(&name); err == nil && name != "" {
-		player.Name = name
-		player.PlayerName = name
-	}
-
-	h.jsonResponse(w, http.StatusOK, models.PlayerStatsResponse{
-		Player: player,
+	h.fieldsResponse(w, r, http.StatusOK, models.PlayerStatsResponse{
+		Player: *player,
 	})
 }
 
@@ -1286,7 +1297,7 @@ func (h *Handler) GetPlayerDeepStats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.jsonResponse(w, http.StatusOK, stats)
+	h.fieldsResponse(w, r, http.StatusOK, stats)
 }
 
 // GetPlayerCombatStats returns only combat subset of deep stats
@@ -1294,7 +1305,7 @@ func (h *Handler) GetPlayerCombatStats(w http.ResponseWriter, r *http.Request) {
 	guid := chi.URLParam(r, "guid")
 	ctx := r.Context()
 
-	stats, err := h.playerStats.GetDeepStats(ctx, guid)
+	stats, err := h.playerStats.GetStatsSections(ctx, guid, []string{models.SectionCombat})
 	if err != nil {
 		h.logger.Errorw("Failed to get combat stats", "guid", guid, "error", err)
 		h.errorResponse(w, http.StatusInternalServerError, "Failed to calculate combat stats")
@@ -1310,7 +1321,7 @@ func (h *Handler) GetPlayerMovementStats(w http.ResponseWriter, r *http.Request)
 	guid := chi.URLParam(r, "guid")
 	ctx := r.Context()
 
-	stats, err := h.playerStats.GetDeepStats(ctx, guid)
+	stats, err := h.playerStats.GetStatsSections(ctx, guid, []string{models.SectionMovement})
 	if err != nil {
 		h.logger.Errorw("Failed to get movement stats", "guid", guid, "error", err)
 		h.errorResponse(w, http.StatusInternalServerError, "Failed to calculate movement stats")
@@ -1326,7 +1337,7 @@ func (h *Handler) GetPlayerStanceStats(w http.ResponseWriter, r *http.Request) {
 	guid := chi.URLParam(r, "guid")
 	ctx := r.Context()
 
-	stats, err := h.playerStats.GetDeepStats(ctx, guid)
+	stats, err := h.playerStats.GetStatsSections(ctx, guid, []string{models.SectionStance})
 	if err != nil {
 		h.logger.Errorw("Failed to get stance stats", "guid", guid, "error", err)
 		h.errorResponse(w, http.StatusInternalServerError, "Failed to calculate stance stats")
@@ -1442,18 +1453,15 @@ func (h *Handler) GetPlayerHeatmap(w http.ResponseWriter, r *http.Request) {
 
 	rows, err := h.ch.Query(ctx, `
 		SELECT 
-			actor_pos_x,
-			actor_pos_y,
-			count() as kills
-		FROM mohaa_stats.raw_events
-		WHERE event_type IN ('player_kill', 'bot_killed') 
-		  AND actor_id = ? 
-		  AND map_name = ?
-		  AND actor_pos_x != 0
-		GROUP BY 
-			round(actor_pos_x / 100) * 100 as actor_pos_x,
-			round(actor_pos_y / 100) * 100 as actor_pos_y
-	`, guid, mapName)
+			round(cell_x / 2) * 100 as x,
+			round(cell_y / 2) * 100 as y,
+			sum(samples) as kills
+		FROM `+logic.PositionCellsTable(ctx)+`
+		WHERE map_name = ?
+		  AND `+heatmapFilter("kills")+`
+		  AND player_id = ?
+		GROUP BY x, y
+	`, mapName, guid)
 	if err != nil {
 		h.errorResponse(w, http.StatusInternalServerError, "Query failed")
 		return
@@ -1462,11 +1470,12 @@ func (h *Handler) GetPlayerHeatmap(w http.ResponseWriter, r *http.Request) {
 
 	var points []models.HeatmapPoint
 	for rows.Next() {
-		var p models.HeatmapPoint
-		if err := rows.Scan(&p.X, &p.Y, &p.Count); err != nil {
+		var x, y float64
+		var count uint64
+		if err := rows.Scan(&x, &y, &count); err != nil {
 			continue
 		}
-		points = append(points, p)
+		points = append(points, models.HeatmapPoint{X: float32(x), Y: float32(y), Count: int(count)})
 	}
 
 	h.jsonResponse(w, http.StatusOK, models.HeatmapData{
@@ -1483,18 +1492,15 @@ func (h *Handler) GetPlayerDeathHeatmap(w http.ResponseWriter, r *http.Request)
 
 	rows, err := h.ch.Query(ctx, `
 		SELECT 
-			target_pos_x,
-			target_pos_y,
-			count() as deaths
-		FROM mohaa_stats.raw_events
-		WHERE event_type IN ('player_kill', 'bot_killed') 
-		  AND target_id = ? 
-		  AND map_name = ?
-		  AND target_pos_x != 0
-		GROUP BY 
-			round(target_pos_x / 100) * 100 as target_pos_x,
-			round(target_pos_y / 100) * 100 as target_pos_y
-	`, guid, mapName)
+			round(cell_x / 2) * 100 as x,
+			round(cell_y / 2) * 100 as y,
+			sum(samples) as deaths
+		FROM `+logic.PositionCellsTable(ctx)+`
+		WHERE map_name = ?
+		  AND `+heatmapFilter("deaths")+`
+		  AND player_id = ?
+		GROUP BY x, y
+	`, mapName, guid)
 	if err != nil {
 		h.errorResponse(w, http.StatusInternalServerError, "Query failed")
 		return
@@ -1503,11 +1509,12 @@ func (h *Handler) GetPlayerDeathHeatmap(w http.ResponseWriter, r *http.Request)
 
 	var points []models.HeatmapPoint
 	for rows.Next() {
-		var p models.HeatmapPoint
-		if err := rows.Scan(&p.X, &p.Y, &p.Count); err != nil {
+		var x, y float64
+		var count uint64
+		if err := rows.Scan(&x, &y, &count); err != nil {
 			continue
 		}
-		points = append(points, p)
+		points = append(points, models.HeatmapPoint{X: float32(x), Y: float32(y), Count: int(count)})
 	}
 
 	h.jsonResponse(w, http.StatusOK, models.HeatmapData{
@@ -1576,16 +1583,45 @@ func (h *Handler) GetPlayerPerformanceHistory(w http.ResponseWriter, r *http.Req
 }
 
 // GetPlayerBodyHeatmap returns hit location distribution
-// GetPlayerBodyHeatmap returns hit location distribution
+// @Summary Player Body Heatmap
+// @Description Hits received per body part as a flat map. With direction (dealt, taken or both), returns hits, damage and kills per body part dealt to opponents and/or taken from them.
+// @Tags Player
+// @Produce json
+// @Param guid path string true "Player GUID"
+// @Param direction query string false "dealt, taken or both for the per-part breakdown"
+// @Success 200 {object} models.BodyHeatmap
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 500 {object} map[string]string "Internal Error"
+// @Router /stats/player/{guid}/heatmap/body [get]
 func (h *Handler) GetPlayerBodyHeatmap(w http.ResponseWriter, r *http.Request) {
 	guid := chi.URLParam(r, "guid")
 	ctx := r.Context()
 
+	if direction := r.URL.Query().Get("direction"); direction != "" {
+		if direction != "dealt" && direction != "taken" && direction != "both" {
+			h.errorResponse(w, http.StatusBadRequest, "direction must be dealt, taken or both")
+			return
+		}
+		heatmap, err := h.serverStats.GetBodyHeatmap(ctx, guid)
+		if err != nil {
+			h.logger.Errorw("Failed to get body heatmap", "guid", guid, "error", err)
+			h.errorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+		if direction == "dealt" {
+			heatmap.Taken = []models.BodyPartStats{}
+		} else if direction == "taken" {
+			heatmap.Dealt = []models.BodyPartStats{}
+		}
+		h.jsonResponse(w, http.StatusOK, heatmap)
+		return
+	}
+
 	// Query breakdown of hit locations where this player was the TARGET (victim)
 	rows, err := h.ch.Query(ctx, `
 		SELECT 
 			hitloc as body_part,
-			count() as hits
+			sum(sample_weight) as hits
 		FROM mohaa_stats.raw_events
 		WHERE event_type IN ('weapon_hit', 'player_kill') 
 		  AND target_id = ? 
@@ -1675,11 +1711,12 @@ func (h *Handler) GetMatchDetails(w http.ResponseWriter, r *http.Request) {
 	defer rows.Close()
 
 	type PlayerScore struct {
-		PlayerID   string `json:"player_id"`
-		PlayerName string `json:"player_name"`
-		Kills      uint64 `json:"kills"`
-		Deaths     uint64 `json:"deaths"`
-		Headshots  uint64 `json:"headshots"`
+		PlayerID    string `json:"player_id"`
+		PlayerName  string `json:"player_name"`
+		NameFlagged bool   `json:"name_flagged,omitempty"`
+		Kills       uint64 `json:"kills"`
+		Deaths      uint64 `json:"deaths"`
+		Headshots   uint64 `json:"headshots"`
 	}
 
 	var scoreboard []PlayerScore
@@ -1690,12 +1727,36 @@ func (h *Handler) GetMatchDetails(w http.ResponseWriter, r *http.Request) {
 		}
 		scoreboard = append(scoreboard, p)
 	}
+	scoreboard = applyDisplayNames(ctx, h, scoreboard, func(p *PlayerScore) (string, *string, *bool) {
+		return p.PlayerID, &p.PlayerName, &p.NameFlagged
+	})
 
-	h.jsonResponse(w, http.StatusOK, map[string]interface{}{
+	response := map[string]interface{}{
 		"match_id":   matchID,
 		"summary":    summary,
 		"scoreboard": scoreboard,
-	})
+	}
+	if h.matchStates != nil {
+		if record, err := h.matchStates.Get(ctx, matchID); err == nil {
+			response["state"] = record.State
+			response["lifecycle"] = record
+		}
+	}
+	if h.demos != nil {
+		if demos, err := h.demos.ForMatch(ctx, matchID); err == nil {
+			response["demos"] = demos
+		} else {
+			h.logger.Warnw("Failed to load match demos", "error", err, "matchID", matchID)
+		}
+	}
+	if h.media != nil {
+		if media, err := h.media.ForMatch(ctx, matchID); err == nil {
+			response["media"] = media
+		} else {
+			h.logger.Warnw("Failed to load match media", "error", err, "matchID", matchID)
+		}
+	}
+	h.fieldsResponse(w, r, http.StatusOK, response)
 }
 
 // GetMatchHeatmap returns kill/death locations for a specific match
@@ -1703,20 +1764,18 @@ func (h *Handler) GetMatchHeatmap(w http.ResponseWriter, r *http.Request) {
 	matchID := chi.URLParam(r, "matchId")
 	ctx := r.Context()
 
-	// Query individual kill events with coordinates
+	// Query killer and victim positions of the match's kills
 	rows, err := h.ch.Query(ctx, `
 		SELECT 
-			actor_id,
-			target_id,
-			actor_pos_x,
-			actor_pos_y,
-			target_pos_x,
-			target_pos_y
-		FROM mohaa_stats.raw_events
+			role,
+			player_id,
+			toFloat64(pos_x),
+			toFloat64(pos_y)
+		FROM mohaa_stats.positions
 		WHERE match_id = ? 
 		  AND event_type IN ('player_kill', 'bot_killed')
-		  AND actor_pos_x != 0 AND target_pos_x != 0
-		LIMIT 2000
+		ORDER BY timestamp, role
+		LIMIT 4000
 	`, matchID)
 	if err != nil {
 		h.logger.Errorw("Failed to query match heatmap", "error", err)
@@ -1737,37 +1796,39 @@ func (h *Handler) GetMatchHeatmap(w http.ResponseWriter, r *http.Request) {
 	id := 0
 
 	for rows.Next() {
-		var actorID, targetID string
-		var ax, ay, tx, ty float64
-		if err := rows.Scan(&actorID, &targetID, &ax, &ay, &tx, &ty); err != nil {
+		var role, playerID string
+		var x, y float64
+		if err := rows.Scan(&role, &playerID, &x, &y); err != nil {
 			continue
 		}
 
-		// Killer position (green)
-		points = append(points, Point{
-			ID:    id,
-			Type:  "kill",
-			X:     ax,
-			Y:     ay,
-			Label: "Killer: " + actorID,
-		})
-		id++
-
-		// Victim position (red)
-		points = append(points, Point{
-			ID:    id,
-			Type:  "death",
-			X:     tx,
-			Y:     ty,
-			Label: "Victim: " + targetID,
-		})
+		if role == "actor" {
+			// Killer position (green)
+			points = append(points, Point{
+				ID:    id,
+				Type:  "kill",
+				X:     x,
+				Y:     y,
+				Label: "Killer: " + playerID,
+			})
+		} else {
+			// Victim position (red)
+			points = append(points, Point{
+				ID:    id,
+				Type:  "death",
+				X:     x,
+				Y:     y,
+				Label: "Victim: " + playerID,
+			})
+		}
 		id++
 	}
 
 	h.jsonResponse(w, http.StatusOK, points)
 }
 
-// GetMatchTimeline returns chronological events for match replay
+// GetMatchTimeline returns chronological events for match replay. Public chat
+// is included, run through the content filter like the names.
 func (h *Handler) GetMatchTimeline(w http.ResponseWriter, r *http.Request) {
 	matchID := chi.URLParam(r, "matchId")
 	ctx := r.Context()
@@ -1779,9 +1840,11 @@ func (h *Handler) GetMatchTimeline(w http.ResponseWriter, r *http.Request) {
 			actor_name,
 			target_name,
 			actor_weapon,
-			hitloc
+			hitloc,
+			if(event_type = 'chat', JSONExtractString(raw_json, 'message'), '') AS message
 		FROM mohaa_stats.raw_events
-		WHERE match_id = ? AND event_type IN ('player_kill', 'round_start', 'round_end')
+		WHERE match_id = ? AND (event_type IN ('player_kill', 'round_start', 'round_end')
+			OR (event_type = 'chat' AND NOT JSONExtractBool(raw_json, 'team_only')))
 		ORDER BY timestamp
 		LIMIT 1000
 	`, matchID)
@@ -1798,14 +1861,25 @@ func (h *Handler) GetMatchTimeline(w http.ResponseWriter, r *http.Request) {
 		TargetName string    `json:"target_name"`
 		Weapon     string    `json:"weapon"`
 		Hitloc     string    `json:"hitloc"`
+		Message    string    `json:"message,omitempty"`
+		Flagged    bool      `json:"flagged,omitempty"`
 	}
 
 	var events []TimelineEvent
 	for rows.Next() {
 		var e TimelineEvent
-		if err := rows.Scan(&e.Timestamp, &e.EventType, &e.ActorName, &e.TargetName, &e.Weapon, &e.Hitloc); err != nil {
+		if err := rows.Scan(&e.Timestamp, &e.EventType, &e.ActorName, &e.TargetName, &e.Weapon, &e.Hitloc, &e.Message); err != nil {
 			continue
 		}
+		// Chat stored without raw_json has nothing to show
+		if e.EventType == string(models.EventChat) && e.Message == "" {
+			continue
+		}
+		var actorFlagged, targetFlagged, messageFlagged bool
+		e.ActorName, actorFlagged = h.filterText(e.ActorName)
+		e.TargetName, targetFlagged = h.filterText(e.TargetName)
+		e.Message, messageFlagged = h.filterText(e.Message)
+		e.Flagged = actorFlagged || targetFlagged || messageFlagged
 		events = append(events, e)
 	}
 
@@ -1963,7 +2037,7 @@ func (h *Handler) GetLiveMatches(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	// Get all live matches from Redis
-	matchData, err := h.redis.HGetAll(ctx, "live_matches").Result()
+	matchData, err := h.redis.HGetAll(ctx, "live_matches")
 	if err != nil {
 		h.errorResponse(w, http.StatusInternalServerError, "Failed to fetch live matches")
 		return
@@ -1984,8 +2058,19 @@ func (h *Handler) GetLiveMatches(w http.ResponseWriter, r *http.Request) {
 // MIDDLEWARE
 // ============================================================================
 
-// ServerAuthMiddleware validates server tokens
+// ServerAuthMiddleware validates server tokens. Sandbox tokens are refused,
+// they only pass SandboxAuthMiddleware.
 func (h *Handler) ServerAuthMiddleware(next http.Handler) http.Handler {
+	return h.serverAuth(next, false)
+}
+
+// SandboxAuthMiddleware validates server tokens, sandbox ones included, for
+// ingestion and the sandbox event readback
+func (h *Handler) SandboxAuthMiddleware(next http.Handler) http.Handler {
+	return h.serverAuth(next, true)
+}
+
+func (h *Handler) serverAuth(next http.Handler, allowSandbox bool) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		token := r.Header.Get("X-Server-Token")
 		if token == "" {
@@ -2005,13 +2090,14 @@ func (h *Handler) ServerAuthMiddleware(next http.Handler) http.Handler {
 
 		// Validate token against database - lookup server by token hash
 		ctx := r.Context()
-		var serverID string
+		var serverID, tenantID string
+		var sandbox, admin bool
 		hashedToken := hashToken(token)
 		h.logger.Infow("Auth Debug", "received_token", token, "computed_hash", hashedToken)
 
 		err := h.pg.QueryRow(ctx,
-			"SELECT id FROM servers WHERE token = $1 AND is_active = true",
-			hashedToken).Scan(&serverID)
+			"SELECT id, COALESCE(tenant_id::text, ''), sandbox, admin FROM servers WHERE token = $1 AND is_active = true",
+			hashedToken).Scan(&serverID, &tenantID, &sandbox, &admin)
 
 		if err != nil {
 			h.logger.Errorw("Auth Database Error", "error", err, "hash", hashedToken)
@@ -2021,21 +2107,74 @@ func (h *Handler) ServerAuthMiddleware(next http.Handler) http.Handler {
 			h.errorResponse(w, http.StatusUnauthorized, "Invalid server token")
 			return
 		}
+		if sandbox && !allowSandbox {
+			h.errorResponse(w, http.StatusForbidden, "Sandbox tokens can only ingest events and read them back")
+			return
+		}
 
 		// Add server ID to context for handlers
 		ctx = context.WithValue(ctx, "server_id", serverID)
+		ctx = logic.WithTenant(ctx, tenantID)
+		ctx = logic.WithSandbox(ctx, sandbox)
+		ctx = logic.WithAdmin(ctx, admin && !sandbox)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// AdminMiddleware lets through requests authenticated by an admin server's
+// token; it runs after ServerAuthMiddleware
+func (h *Handler) AdminMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !logic.AdminFromContext(r.Context()) {
+			h.errorResponse(w, http.StatusForbidden, "Admin server token required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 // getUserIDFromContext extracts user ID from request context (currently unused since JWT removal)
 func (h *Handler) getUserIDFromContext(ctx context.Context) int {
 	return 0
 }
 
+// GetWeaponKillMatrix returns weapon x hitloc and weapon x stance kill grids
+// @Summary Weapon Kill Matrix
+// @Description Kills per weapon broken down by the victim's hit location and by the killer's stance, for the whole network or one player (player query param or /stats/player/{guid}/weapons/matrix)
+// @Tags Server
+// @Produce json
+// @Param player query string false "Player GUID"
+// @Param limit query int false "Weapons to include, most kills first" default(20)
+// @Param phases query string false "all to include warmup and intermission kills"
+// @Success 200 {object} models.WeaponKillMatrix
+// @Failure 500 {object} map[string]string "Internal Error"
+// @Router /stats/weapons/matrix [get]
+func (h *Handler) GetWeaponKillMatrix(w http.ResponseWriter, r *http.Request) {
+	playerID := chi.URLParam(r, "guid")
+	if playerID == "" {
+		playerID = r.URL.Query().Get("player")
+	}
+	limit := 20
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+
+	matrix, err := h.serverStats.GetWeaponKillMatrix(r.Context(), playerID, limit)
+	if err != nil {
+		h.logger.Errorw("Failed to get weapon kill matrix", "player", playerID, "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, matrix)
+}
+
 // GetGlobalActivity returns heat map data for server activity
 func (h *Handler) GetGlobalActivity(w http.ResponseWriter, r *http.Request) {
-	activity, err := h.serverStats.GetGlobalActivity(r.Context())
+	period, ok := h.parsePeriod(w, r)
+	if !ok {
+		return
+	}
+	activity, err := h.serverStats.GetGlobalActivity(r.Context(), period)
 	if err != nil {
 		h.logger.Errorw("Failed to get global activity", "error", err)
 		h.errorResponse(w, http.StatusInternalServerError, "Internal server error")
@@ -2055,6 +2194,60 @@ func (h *Handler) GetMapPopularity(w http.ResponseWriter, r *http.Request) {
 	h.jsonResponse(w, http.StatusOK, stats)
 }
 
+// GetMapEnvironmentDeaths returns the maps with the most environmental deaths
+// @Summary Most Lethal Maps by Environment
+// @Description Maps ranked by deaths from falling, drowning, crushing, telefrags and other world damage, with each map's share of such deaths
+// @Tags Server
+// @Produce json
+// @Param limit query int false "Maps to return" default(20)
+// @Param phases query string false "all to include warmup and intermission deaths"
+// @Success 200 {array} models.MapEnvironmentDeaths
+// @Failure 500 {object} map[string]string "Internal Error"
+// @Router /stats/maps/environment [get]
+func (h *Handler) GetMapEnvironmentDeaths(w http.ResponseWriter, r *http.Request) {
+	limit := 20
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+
+	maps, err := h.serverStats.GetEnvironmentalMapDeaths(r.Context(), limit)
+	if err != nil {
+		h.logger.Errorw("Failed to get environmental map deaths", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, maps)
+}
+
+// GetMapObjectives returns objective timing for an objective map
+// @Summary Map Objective Timing
+// @Description Average time from round start to the first objective, bomb plant success and defuse rates, and how often each objective is captured
+// @Tags Server
+// @Produce json
+// @Param id path string true "Map name"
+// @Param days query int false "Days to look back" default(30)
+// @Param phases query string false "all to include warmup and intermission events"
+// @Success 200 {object} models.MapObjectiveStats
+// @Failure 500 {object} map[string]string "Internal Error"
+// @Router /stats/maps/{id}/objectives [get]
+func (h *Handler) GetMapObjectives(w http.ResponseWriter, r *http.Request) {
+	mapName := chi.URLParam(r, "id")
+	days := 30
+	if d := r.URL.Query().Get("days"); d != "" {
+		if parsed, _ := strconv.Atoi(d); parsed > 0 && parsed <= 365 {
+			days = parsed
+		}
+	}
+
+	stats, err := h.serverStats.GetMapObjectives(r.Context(), mapName, days)
+	if err != nil {
+		h.logger.Errorw("Failed to get map objectives", "error", err, "map", mapName)
+		h.errorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, stats)
+}
+
 // GetPlayerPlaystyle returns the calculated playstyle badge
 func (h *Handler) GetPlayerPlaystyle(w http.ResponseWriter, r *http.Request) {
 	guid := chi.URLParam(r, "guid")
@@ -2064,6 +2257,7 @@ func (h *Handler) GetPlayerPlaystyle(w http.ResponseWriter, r *http.Request) {
 		h.errorResponse(w, http.StatusInternalServerError, "Internal error")
 		return
 	}
+	translatePlaystyle(r.Context(), badge)
 	h.jsonResponse(w, http.StatusOK, badge)
 }
 
@@ -2076,7 +2270,7 @@ func (h *Handler) GetMatchAdvancedDetails(w http.ResponseWriter, r *http.Request
 		h.errorResponse(w, http.StatusInternalServerError, "Internal error")
 		return
 	}
-	h.jsonResponse(w, http.StatusOK, details)
+	h.fieldsResponse(w, r, http.StatusOK, details)
 }
 
 // GetLeaderboardCards was moved to cards.go to support the massive dashboard
@@ -2215,24 +2409,19 @@ func formatMapName(name string) string {
 
 // getMapHeatmapData returns heatmap coordinates for a map
 func (h *Handler) getMapHeatmapData(ctx context.Context, mapID, heatmapType string) ([]map[string]interface{}, error) {
-	eventType := "kill"
-	if heatmapType == "deaths" {
-		eventType = "death"
-	}
-
 	rows, err := h.ch.Query(ctx, `
 		SELECT 
-			toFloat64OrZero(raw_json->>'pos_x') as x,
-			toFloat64OrZero(raw_json->>'pos_y') as y,
-			count() as intensity
-		FROM mohaa_stats.raw_events
-		WHERE map_name = ? AND event_type = ?
-			AND raw_json->>'pos_x' != '' AND raw_json->>'pos_y' != ''
-		GROUP BY x, y
+			toFloat64(cell_x * 50) as x,
+			toFloat64(cell_y * 50) as y,
+			sum(samples) as intensity
+		FROM mohaa_stats.position_cells
+		WHERE map_name = ?
+		  AND `+heatmapFilter(heatmapType)+`
+		GROUP BY cell_x, cell_y
 		HAVING intensity > 0
 		ORDER BY intensity DESC
 		LIMIT 500
-	`, mapID, eventType)
+	`, mapID)
 	if err != nil {
 		return nil, err
 	}
@@ -2241,7 +2430,7 @@ func (h *Handler) getMapHeatmapData(ctx context.Context, mapID, heatmapType stri
 	var result []map[string]interface{}
 	for rows.Next() {
 		var x, y float64
-		var intensity int64
+		var intensity uint64
 		if err := rows.Scan(&x, &y, &intensity); err == nil {
 			result = append(result, map[string]interface{}{
 				"x":     x,
@@ -2295,12 +2484,11 @@ func (h *Handler) GetGameTypeStats(w http.ResponseWriter, r *http.Request) {
 		var gameType string
 		var matches, kills, deaths, players, mapCount uint64
 		if err := rows.Scan(&gameType, &matches, &kills, &deaths, &players, &mapCount); err == nil {
-			info := gameTypeInfo[gameType]
 			result = append(result, map[string]interface{}{
 				"id":             gameType,
-				"name":           formatGameTypeName(gameType),
-				"description":    info.Description,
-				"icon":           info.Icon,
+				"name":           gameTypeName(ctx, gameType),
+				"description":    gameTypeDescription(ctx, gameType),
+				"icon":           gameTypeIcons[gameType],
 				"total_matches":  matches,
 				"total_kills":    kills,
 				"total_deaths":   deaths,
@@ -2345,8 +2533,8 @@ func (h *Handler) GetGameTypesList(w http.ResponseWriter, r *http.Request) {
 		if err := rows.Scan(&gameType); err == nil {
 			result = append(result, map[string]string{
 				"id":           gameType,
-				"name":         formatGameTypeName(gameType),
-				"display_name": formatGameTypeName(gameType),
+				"name":         gameTypeName(ctx, gameType),
+				"display_name": gameTypeName(ctx, gameType),
 			})
 		}
 	}
@@ -2411,12 +2599,11 @@ func (h *Handler) GetGameTypeDetail(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	info := gameTypeInfo[gameType]
 	response := map[string]interface{}{
 		"id":             gameType,
-		"name":           formatGameTypeName(gameType),
-		"description":    info.Description,
-		"icon":           info.Icon,
+		"name":           gameTypeName(ctx, gameType),
+		"description":    gameTypeDescription(ctx, gameType),
+		"icon":           gameTypeIcons[gameType],
 		"total_matches":  totalMatches,
 		"total_kills":    totalKills,
 		"total_deaths":   totalDeaths,
@@ -2472,21 +2659,35 @@ func (h *Handler) GetGameTypeLeaderboard(w http.ResponseWriter, r *http.Request)
 	}
 	defer rows.Close()
 
-	var leaderboard []map[string]interface{}
-	rank := 1
+	type gameTypeLeader struct {
+		id, name      string
+		kills, deaths uint64
+		flagged       bool
+	}
+	var leaders []gameTypeLeader
 	for rows.Next() {
-		var id, name string
-		var kills, deaths uint64
-		if err := rows.Scan(&id, &name, &kills, &deaths); err == nil {
-			leaderboard = append(leaderboard, map[string]interface{}{
-				"rank":   rank,
-				"id":     id,
-				"name":   name,
-				"kills":  kills,
-				"deaths": deaths,
-			})
-			rank++
+		var l gameTypeLeader
+		if err := rows.Scan(&l.id, &l.name, &l.kills, &l.deaths); err == nil {
+			leaders = append(leaders, l)
+		}
+	}
+	leaders = applyDisplayNames(ctx, h, leaders, func(l *gameTypeLeader) (string, *string, *bool) {
+		return l.id, &l.name, &l.flagged
+	})
+
+	var leaderboard []map[string]interface{}
+	for i, l := range leaders {
+		entry := map[string]interface{}{
+			"rank":   i + 1,
+			"id":     l.id,
+			"name":   l.name,
+			"kills":  l.kills,
+			"deaths": l.deaths,
+		}
+		if l.flagged {
+			entry["name_flagged"] = true
 		}
+		leaderboard = append(leaderboard, entry)
 	}
 
 	h.jsonResponse(w, http.StatusOK, map[string]interface{}{
@@ -2499,18 +2700,15 @@ func (h *Handler) GetGameTypeLeaderboard(w http.ResponseWriter, r *http.Request)
 // HELPERS
 // ============================================================================
 
-// Game type metadata - maps prefix to display info
-var gameTypeInfo = map[string]struct {
-	Name        string
-	Description string
-	Icon        string
-}{
-	"dm":  {"Deathmatch", "Free-for-all combat", "💀"},
-	"tdm": {"Team Deathmatch", "Team-based combat", "⚔️"},
-	"obj": {"Objective", "Mission-based gameplay", "🎯"},
-	"lib": {"Liberation", "Territory control", "🏴"},
-	"ctf": {"Capture the Flag", "Flag-based objectives", "🚩"},
-	"ffa": {"Free For All", "Every player for themselves", "🔥"},
+// Game type icons by prefix. Names and descriptions are translated under
+// gametype.<prefix>.name and gametype.<prefix>.description.
+var gameTypeIcons = map[string]string{
+	"dm":  "💀",
+	"tdm": "⚔️",
+	"obj": "🎯",
+	"lib": "🏴",
+	"ctf": "🚩",
+	"ffa": "🔥",
 }
 
 // extractGameType derives game type from map name prefix
@@ -2541,12 +2739,19 @@ func extractGameType(mapName string) string {
 	return "unknown"
 }
 
-// formatGameTypeName converts prefix to display name
-func formatGameTypeName(prefix string) string {
-	if info, ok := gameTypeInfo[prefix]; ok {
-		return info.Name
-	}
-	return strings.ToUpper(prefix)
+// gameTypeName converts prefix to display name in the request's locale
+func gameTypeName(ctx context.Context, prefix string) string {
+	return i18n.T(ctx, "gametype."+prefix+".name", strings.ToUpper(prefix))
+}
+
+// gameTypeDescription describes a game type in the request's locale
+func gameTypeDescription(ctx context.Context, prefix string) string {
+	return i18n.T(ctx, "gametype."+prefix+".description", "")
+}
+
+// statName is the display name of a leaderboard stat in the request's locale
+func statName(ctx context.Context, stat string) string {
+	return i18n.T(ctx, "stat."+stat, stat)
 }
 
 // ============================================================================
@@ -2603,13 +2808,13 @@ func (h *Handler) GetWeaponDetail(w http.ResponseWriter, r *http.Request) {
 		SELECT 
 			countIf(event_type IN ('player_kill', 'bot_killed')) as total_kills,
 			countIf(event_type IN ('player_kill', 'bot_killed') AND hitloc IN ('head', 'helmet')) as total_headshots,
-			countIf(event_type = 'weapon_fire') as shots_fired,
-			countIf(event_type = 'weapon_hit') as shots_hit,
+			sumIf(sample_weight, event_type = 'weapon_fire') as shots_fired,
+			sumIf(sample_weight, event_type = 'weapon_hit') as shots_hit,
 			uniq(actor_id) as unique_users,
 			max(timestamp) as last_used,
 			avgIf(distance, event_type='player_kill') as avg_kill_distance
 		FROM mohaa_stats.raw_events
-		WHERE actor_weapon = ?
+		WHERE actor_weapon = ?`+logic.RoundPhaseFilter(ctx, "")+`
 	`, weapon)
 
 	var stats struct {
@@ -2654,7 +2859,7 @@ func (h *Handler) GetWeaponDetail(w http.ResponseWriter, r *http.Request) {
 			countIf(hitloc IN ('head', 'helmet')) as headshots,
 			if(count() > 0, toFloat64(countIf(hitloc IN ('head', 'helmet')))/count()*100, 0) as hs_ratio
 		FROM mohaa_stats.raw_events
-		WHERE event_type IN ('player_kill', 'bot_killed') AND actor_weapon = ? AND actor_id != ''
+		WHERE event_type IN ('player_kill', 'bot_killed') AND actor_weapon = ? AND actor_id != ''`+logic.RoundPhaseFilter(ctx, "")+`
 		GROUP BY actor_id
 		ORDER BY kills DESC
 		LIMIT 10
@@ -2679,9 +2884,17 @@ func (h *Handler) GetWeaponDetail(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Hits per opponent body part, from the hit location aggregate
+	bodyParts, err := h.serverStats.GetWeaponBodyParts(ctx, weapon, stats.ShotsFired)
+	if err != nil {
+		h.logger.Warnw("Failed to get weapon body parts", "error", err, "weapon", weapon)
+		bodyParts = []models.WeaponBodyPart{}
+	}
+
 	response := map[string]interface{}{
 		"stats":       stats,
 		"top_players": topUsers,
+		"body_parts":  bodyParts,
 	}
 
 	h.jsonResponse(w, http.StatusOK, response)
@@ -2701,6 +2914,18 @@ func (h *Handler) GetPlayerStatsByName(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Names of anonymized players must not lead back to them
+	privacy, err := h.playerPrivacy(r.Context(), guid)
+	if err != nil {
+		h.logger.Errorw("Failed to read player privacy", "guid", guid, "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to resolve player")
+		return
+	}
+	if privacy.HideProfile || privacy.AnonymizeName {
+		h.errorResponse(w, http.StatusNotFound, "Player not found")
+		return
+	}
+
 	h.jsonResponse(w, http.StatusOK, map[string]string{
 		"guid": guid,
 		"name": name,
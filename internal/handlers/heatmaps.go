@@ -20,47 +20,45 @@ func (h *Handler) GetMapHeatmap(w http.ResponseWriter, r *http.Request) {
 		mapName = "dm/mohdm1" // Default
 	}
 
-	heatmapType := r.URL.Query().Get("type") // "kills" or "deaths"
+	heatmapType := r.URL.Query().Get("type") // "kills", "deaths", "turret_usage" or "turret_kills"
 	if heatmapType == "" {
 		heatmapType = "kills"
 	}
 
 	ctx := r.Context()
 
-	var query string
-	// We aggregate by grid cells (50 units) to reduce data volume
-	if heatmapType == "deaths" {
+	// position_cells is sorted by map and 50-unit grid cell, so grouping by
+	// cell reads a contiguous range, and outlives the precise positions
+	query := `
+		SELECT 
+			toFloat64(cell_x * 50) as x,
+			toFloat64(cell_y * 50) as y,
+			sum(samples) as intensity
+		FROM mohaa_stats.position_cells
+		WHERE map_name = ?
+		  AND ` + heatmapFilter(heatmapType) + `
+		GROUP BY cell_x, cell_y
+		HAVING intensity > 0
+		LIMIT 3000
+	`
+	if heatmapType == "turret_kills" {
+		// Where turret victims fell. positions doesn't keep the weapon, so
+		// kills from mounted guns are read from raw_events
 		query = `
-			SELECT 
-				round(pos_x / 50) * 50 as x,
-				round(pos_y / 50) * 50 as y,
-				count() as intensity
+			SELECT
+				toFloat64(toInt32(round(target_pos_x / 50)) * 50) as x,
+				toFloat64(toInt32(round(target_pos_y / 50)) * 50) as y,
+				sum(sample_weight) as intensity
 			FROM mohaa_stats.raw_events
-			WHERE (event_type = 'death' OR event_type IN ('player_kill', 'bot_killed'))
-			  AND map_name = ?
-			  AND pos_x != 0 AND pos_y != 0
-			GROUP BY x, y
-			HAVING intensity > 0
-			LIMIT 3000
-		`
-	} else {
-		// Kills - use actor position (from raw_json)
-		query = `
-			SELECT 
-				round(JSONExtractFloat(raw_json, 'actor_x') / 50) * 50 as x,
-				round(JSONExtractFloat(raw_json, 'actor_y') / 50) * 50 as y,
-				count() as intensity
-			FROM mohaa_stats.raw_events
-			WHERE event_type IN ('player_kill', 'bot_killed')
-			  AND map_name = ?
-			  AND JSONExtractFloat(raw_json, 'actor_x') != 0 
-			  AND JSONExtractFloat(raw_json, 'actor_y') != 0
+			WHERE map_name = ?
+			  AND event_type IN ('player_kill', 'bot_killed')
+			  AND actor_weapon LIKE '%turret%'
+			  AND (target_pos_x != 0 OR target_pos_y != 0)
 			GROUP BY x, y
 			HAVING intensity > 0
 			LIMIT 3000
 		`
 	}
-
 	rows, err := h.ch.Query(ctx, query, mapName)
 	if err != nil {
 		h.logger.Errorw("Failed to query heatmap data", "error", err)
@@ -80,3 +78,16 @@ func (h *Handler) GetMapHeatmap(w http.ResponseWriter, r *http.Request) {
 
 	h.jsonResponse(w, http.StatusOK, points)
 }
+
+// heatmapFilter selects the positions rows for a heatmap type: where killers
+// stood for "kills", where victims fell for "deaths", where players mounted
+// turrets for "turret_usage"
+func heatmapFilter(heatmapType string) string {
+	switch heatmapType {
+	case "deaths":
+		return "event_type IN ('player_kill', 'bot_killed') AND role = 'target'"
+	case "turret_usage":
+		return "event_type = 'turret_enter' AND role = 'actor'"
+	}
+	return "event_type IN ('player_kill', 'bot_killed') AND role = 'actor'"
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// GetPlayerHighlights returns the players of the day or week
+// @Summary Player of the Day/Week
+// @Description Players of past days or weeks, newest first, network-wide or for one server. Picks are made an hour after each period ends by a composite score (kills, headshots, won matches, deaths).
+// @Tags Stats
+// @Produce json
+// @Param period query string false "day or week" default(day)
+// @Param server_id query string false "Server ID; network-wide when empty"
+// @Param tz query string false "IANA time zone of from and to dates" default(UTC)
+// @Param from query string false "Only periods starting at or after (RFC 3339 or YYYY-MM-DD in tz)"
+// @Param to query string false "Only periods starting before (RFC 3339, or YYYY-MM-DD to include that day)"
+// @Param limit query int false "Periods to return (max 100)" default(7)
+// @Success 200 {object} models.PlayerHighlightsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /stats/highlights/potd [get]
+func (h *Handler) GetPlayerHighlights(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	period := models.HighlightPeriod(q.Get("period"))
+	switch period {
+	case "":
+		period = models.HighlightDay
+	case models.HighlightDay, models.HighlightWeek:
+	default:
+		h.errorResponse(w, http.StatusBadRequest, "period must be day or week")
+		return
+	}
+	limit := 7
+	if l, err := strconv.Atoi(q.Get("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+	serverID := q.Get("server_id")
+	// period names the pick period here, so only from and to narrow the list
+	between, err := logic.ParsePeriod("", q.Get("tz"), q.Get("from"), q.Get("to"), time.Now())
+	if err != nil {
+		h.errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	highlights, err := h.highlights.History(r.Context(), period, serverID, between, limit)
+	if err != nil {
+		h.logger.Errorw("Failed to get player highlights", "period", period, "server", serverID, "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to get player highlights")
+		return
+	}
+	highlights = applyDisplayNames(r.Context(), h, highlights, func(p *models.PlayerHighlight) (string, *string, *bool) {
+		return p.PlayerGUID, &p.PlayerName, &p.NameFlagged
+	})
+	h.jsonResponse(w, http.StatusOK, models.PlayerHighlightsResponse{
+		Period:     period,
+		ServerID:   serverID,
+		Highlights: highlights,
+	})
+}
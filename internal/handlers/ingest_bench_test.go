@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// benchBody builds an ingest payload of n events in the given format
+func benchBody(b *testing.B, format string, n int) []byte {
+	b.Helper()
+	events := make([]models.RawEvent, n)
+	for i := range events {
+		events[i] = models.RawEvent{
+			Type:         models.EventPlayerKill,
+			MatchID:      "4f1c2a9e-7a1b-4c7e-9a55-0c8d7b3f2e11",
+			MapName:      "obj/obj_team2",
+			AttackerGUID: fmt.Sprintf("guid-%d", i%32),
+			AttackerName: "^1Killer",
+			VictimGUID:   fmt.Sprintf("guid-%d", (i+1)%32),
+			VictimName:   "Victim",
+			Weapon:       "Kar98K",
+			Hitloc:       "head",
+			Timestamp:    1700000000 + float64(i),
+		}
+	}
+
+	var buf bytes.Buffer
+	switch format {
+	case "json":
+		json.NewEncoder(&buf).Encode(events)
+	case "ndjson":
+		for _, e := range events {
+			json.NewEncoder(&buf).Encode(e)
+		}
+	case "form":
+		for _, e := range events {
+			fmt.Fprintf(&buf, "type=%s&match_id=%s&map_name=%s&attacker_guid=%s&attacker_name=%%5E1Killer&victim_guid=%s&victim_name=Victim&weapon=%s&hitloc=%s&timestamp=%.0f\n",
+				e.Type, e.MatchID, e.MapName, e.AttackerGUID, e.VictimGUID, e.Weapon, e.Hitloc, e.Timestamp)
+		}
+	}
+	return buf.Bytes()
+}
+
+// BenchmarkStreamV1Payload measures parsing of the v1 ingest formats
+func BenchmarkStreamV1Payload(b *testing.B) {
+	h := &Handler{logger: zap.NewNop().Sugar(), pool: &MockIngestQueue{}}
+
+	for _, format := range []string{"json", "ndjson", "form"} {
+		b.Run(format, func(b *testing.B) {
+			body := benchBody(b, format, 500)
+
+			b.ReportAllocs()
+			b.SetBytes(int64(len(body)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				events := 0
+				_, err := h.streamV1Payload(bytes.NewReader(body), 0, func(models.RawEvent) bool {
+					events++
+					return true
+				})
+				if err != nil || events != 500 {
+					b.Fatalf("parsed %d events, err %v", events, err)
+				}
+			}
+		})
+	}
+}
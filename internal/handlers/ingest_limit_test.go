@@ -28,15 +28,23 @@ func TestIngestEvents(t *testing.T) {
 	tests := []struct {
 		name        string
 		body        string
+		maxBody     int64
 		mockEnqueue func(*models.RawEvent) bool
 		wantStatus  int
 	}{
 		{
 			name:        "Oversized Payload",
-			body:        strings.Repeat("a", MaxBodySize+1),
+			body:        strings.Repeat("a", 1024),
+			maxBody:     512,
 			mockEnqueue: nil, // Should not be called
 			wantStatus:  http.StatusRequestEntityTooLarge,
 		},
+		{
+			name:        "Large Batch Without Limit",
+			body:        strings.Repeat("type=kill\n", MaxBodySize/10+1),
+			mockEnqueue: func(e *models.RawEvent) bool { return true },
+			wantStatus:  http.StatusAccepted,
+		},
 		{
 			name:        "Valid Payload",
 			body:        "type=kill",
@@ -56,8 +64,9 @@ func TestIngestEvents(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			h := &Handler{
-				logger: logger.Sugar(),
-				pool:   &MockIngestQueue{EnqueueFunc: tt.mockEnqueue},
+				logger:        logger.Sugar(),
+				pool:          &MockIngestQueue{EnqueueFunc: tt.mockEnqueue},
+				ingestMaxBody: tt.maxBody,
 			}
 
 			req := httptest.NewRequest("POST", "/api/v1/ingest/events", strings.NewReader(tt.body))
@@ -0,0 +1,303 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/openmohaa/stats-api/internal/codec"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// defaultIngestMaxLine bounds one line, or one JSON array element, of a v1
+// batch when no limit is configured
+const defaultIngestMaxLine = 64 << 10
+
+// errInvalidIngestArray ends a v1 stream whose JSON array is not well-formed
+var errInvalidIngestArray = errors.New("Invalid JSON array")
+
+// openIngestStream returns a v1 body for streaming: gzip undone, the
+// decompressed size capped at maxBody (0 for no cap) and the NUL bytes game
+// engines leave behind dropped
+func openIngestStream(r *http.Request, maxBody int64) (io.Reader, func(), error) {
+	var rd io.Reader = r.Body
+	closer := func() {}
+	if strings.EqualFold(r.Header.Get("Content-Encoding"), "gzip") {
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid gzip body: %w", err)
+		}
+		rd, closer = zr, func() { zr.Close() }
+	}
+	if maxBody > 0 {
+		rd = &cappedReader{r: rd, left: maxBody}
+	}
+	return nulStripper{rd}, closer, nil
+}
+
+// cappedReader fails with errBodyTooLarge once more than left bytes are read
+type cappedReader struct {
+	r    io.Reader
+	left int64
+}
+
+func (c *cappedReader) Read(p []byte) (int, error) {
+	// Read one byte past the cap to tell a body of exactly the cap from a
+	// longer one
+	if int64(len(p)) > c.left+1 {
+		p = p[:c.left+1]
+	}
+	n, err := c.r.Read(p)
+	if int64(n) > c.left {
+		n, c.left = int(c.left), 0
+		return n, errBodyTooLarge
+	}
+	c.left -= int64(n)
+	return n, err
+}
+
+// nulStripper drops NUL bytes from a stream
+type nulStripper struct {
+	r io.Reader
+}
+
+func (s nulStripper) Read(p []byte) (int, error) {
+	for {
+		n, err := s.r.Read(p)
+		kept := 0
+		for _, b := range p[:n] {
+			if b != 0 {
+				p[kept] = b
+				kept++
+			}
+		}
+		if kept > 0 || n == 0 || err != nil {
+			return kept, err
+		}
+	}
+}
+
+// streamV1Payload is the compatibility shim for legacy game scripts: it
+// parses a JSON array, or newline-delimited JSON objects and URL-encoded
+// lines, an event at a time and hands each to emit until emit returns false.
+// Elements and lines that cannot be parsed, have no type or exceed maxLine
+// bytes are skipped and returned as rejections. The error, if any, ended the
+// stream early: errInvalidIngestArray, errBodyTooLarge or a read error.
+func (h *Handler) streamV1Payload(rd io.Reader, maxLine int, emit func(models.RawEvent) bool) ([]models.IngestRejection, error) {
+	if maxLine <= 0 {
+		maxLine = defaultIngestMaxLine
+	}
+	// A line fills the buffer along with its newline
+	br := bufio.NewReaderSize(rd, maxLine+1)
+
+	// Leading blank lines still count towards line numbers
+	lineNo := 1
+	for {
+		b, err := br.ReadByte()
+		if err == io.EOF {
+			return []models.IngestRejection{}, nil
+		}
+		if err != nil {
+			return []models.IngestRejection{}, err
+		}
+		if b == '\n' {
+			lineNo++
+		}
+		if b == ' ' || b == '\t' || b == '\r' || b == '\n' {
+			continue
+		}
+		br.UnreadByte()
+		if b == '[' {
+			return h.streamJSONArray(br, maxLine, emit)
+		}
+		return h.streamLines(br, lineNo, maxLine, emit)
+	}
+}
+
+// streamJSONArray reads the elements of a JSON array one by one. Only the
+// first maxLine bytes of an element are kept: the rest of a longer one is read
+// and dropped, and the element rejected alone.
+func (h *Handler) streamJSONArray(br *bufio.Reader, maxLine int, emit func(models.RawEvent) bool) ([]models.IngestRejection, error) {
+	rejected := []models.IngestRejection{}
+	// The '[' streamV1Payload found
+	br.ReadByte()
+
+	for i := 0; ; i++ {
+		b, err := nextJSONByte(br)
+		if err != nil {
+			return rejected, arrayError(err)
+		}
+		if b == ']' && i == 0 {
+			return rejected, nil
+		}
+		br.UnreadByte()
+
+		raw, tooLong, err := readJSONElement(br, maxLine)
+		if err != nil {
+			h.ingestLogger().Warnw("Failed to read JSON array element", "error", err, "index", i)
+			return rejected, arrayError(err)
+		}
+		var event models.RawEvent
+		if tooLong {
+			rejected = append(rejected, models.IngestRejection{Index: i, Error: fmt.Sprintf("event exceeds %d bytes", maxLine)})
+		} else if err := json.Unmarshal(raw, &event); err != nil {
+			h.ingestLogger().Warnw("Failed to unmarshal JSON array element", "error", err, "index", i)
+			rejected = append(rejected, models.IngestRejection{Index: i, Error: err.Error()})
+		} else if event.Type == "" {
+			rejected = append(rejected, models.IngestRejection{Index: i, Error: "type is required"})
+		} else if !emit(event) {
+			return rejected, nil
+		}
+
+		b, err = nextJSONByte(br)
+		if err != nil {
+			return rejected, arrayError(err)
+		}
+		if b == ']' {
+			return rejected, nil
+		}
+		if b != ',' {
+			return rejected, fmt.Errorf("%w: unexpected %q after element %d", errInvalidIngestArray, b, i)
+		}
+	}
+}
+
+// nextJSONByte returns the next byte that is not JSON whitespace
+func nextJSONByte(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.ReadByte()
+		if err != nil || !isJSONSpace(b) {
+			return b, err
+		}
+	}
+}
+
+func isJSONSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\r' || b == '\n'
+}
+
+// readJSONElement reads one JSON value of an array, keeping at most maxLen bytes
+// of it; tooLong reports that the rest was dropped. Only strings and nesting
+// are followed to find where the value ends: its syntax is checked when it is
+// unmarshalled.
+func readJSONElement(br *bufio.Reader, maxLen int) (raw []byte, tooLong bool, err error) {
+	depth, inString, escaped := 0, false, false
+	for n := 0; ; n++ {
+		b, err := br.ReadByte()
+		if err == io.EOF {
+			return nil, false, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		// Numbers, true, false and null end at the next separator
+		if n > 0 && depth == 0 && !inString && (b == ',' || b == ']' || isJSONSpace(b)) {
+			br.UnreadByte()
+			return raw, tooLong, nil
+		}
+		if n < maxLen {
+			raw = append(raw, b)
+		} else {
+			tooLong = true
+		}
+
+		switch {
+		case inString:
+			switch {
+			case escaped:
+				escaped = false
+			case b == '\\':
+				escaped = true
+			case b == '"':
+				inString = false
+				if depth == 0 {
+					return raw, tooLong, nil
+				}
+			}
+		case b == '"':
+			inString = true
+		case b == '{' || b == '[':
+			depth++
+		case b == '}' || b == ']':
+			if depth--; depth <= 0 {
+				return raw, tooLong, nil
+			}
+		}
+	}
+}
+
+// arrayError keeps read errors as they are and reports anything else as a
+// malformed array
+func arrayError(err error) error {
+	var syntaxErr *json.SyntaxError
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.As(err, &syntaxErr) {
+		return fmt.Errorf("%w: %v", errInvalidIngestArray, err)
+	}
+	return err
+}
+
+// streamLines parses newline-delimited JSON objects and URL-encoded lines,
+// the first of which is line startLine of the body. Rejections carry the
+// 1-based line number and the index of the event among the non-blank lines.
+func (h *Handler) streamLines(br *bufio.Reader, startLine, maxLine int, emit func(models.RawEvent) bool) ([]models.IngestRejection, error) {
+	rejected := []models.IngestRejection{}
+	index := 0
+	for lineNo := startLine; ; lineNo++ {
+		line, err := br.ReadSlice('\n')
+		tooLong := false
+		for err == bufio.ErrBufferFull {
+			// Skip the rest of an oversized line
+			tooLong = true
+			_, err = br.ReadSlice('\n')
+		}
+		if err != nil && err != io.EOF {
+			return rejected, err
+		}
+
+		if tooLong {
+			rejected = append(rejected, models.IngestRejection{Index: index, Line: lineNo, Error: fmt.Sprintf("line exceeds %d bytes", maxLine)})
+			index++
+		} else if line = bytes.TrimSpace(line); len(line) > 0 {
+			event, perr := h.parseV1Line(line)
+			switch {
+			case perr != nil:
+				rejected = append(rejected, models.IngestRejection{Index: index, Line: lineNo, Error: perr.Error()})
+			case event.Type == "":
+				rejected = append(rejected, models.IngestRejection{Index: index, Line: lineNo, Error: "type is required"})
+			default:
+				if !emit(event) {
+					return rejected, nil
+				}
+			}
+			index++
+		}
+
+		if err == io.EOF {
+			return rejected, nil
+		}
+	}
+}
+
+// parseV1Line parses a JSON object or URL-encoded line
+func (h *Handler) parseV1Line(line []byte) (models.RawEvent, error) {
+	var event models.RawEvent
+	if line[0] == '{' {
+		if err := json.Unmarshal(line, &event); err != nil {
+			h.ingestLogger().Warnw("Failed to unmarshal JSON line", "error", err, "line", string(line))
+			return event, err
+		}
+		return event, nil
+	}
+
+	event, report := codec.ParseLine(string(line))
+	if !report.Empty() {
+		h.ingestLogger().Warnw("URL-encoded line partially decoded", "unknown", report.Unknown, "invalid", report.Invalid, "conflicts", report.Conflicts, "line", string(line))
+	}
+	return event, nil
+}
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestStreamV1Payload(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		maxLine      int
+		stopAfter    int // emit refuses events past this many; 0 takes all
+		wantTypes    []string
+		wantRejected []models.IngestRejection
+		wantErr      error
+	}{
+		{
+			name:      "NDJSON And Form Lines",
+			body:      "{\"type\":\"player_kill\"}\r\n\n  type=death&match_id=m1\n",
+			wantTypes: []string{"player_kill", "death"},
+		},
+		{
+			name:      "Engine NULs",
+			body:      "type=kill\x00\ntype=death\x00",
+			wantTypes: []string{"kill", "death"},
+		},
+		{
+			name:      "Per Line Rejections",
+			body:      "{\"type\":\"player_kill\"}\n{\"type\":\n\nmatch_id=m1\n" + strings.Repeat("a", 40) + "\ntype=death",
+			maxLine:   32,
+			wantTypes: []string{"player_kill", "death"},
+			wantRejected: []models.IngestRejection{
+				{Index: 1, Line: 2},
+				{Index: 2, Line: 4, Error: "type is required"},
+				{Index: 3, Line: 5, Error: "line exceeds 32 bytes"},
+			},
+		},
+		{
+			name:      "Leading Blank Lines",
+			body:      "\r\n\n  \n{\"type\":\n\ntype=death",
+			wantTypes: []string{"death"},
+			wantRejected: []models.IngestRejection{
+				{Index: 0, Line: 4},
+			},
+		},
+		{
+			name:      "Line At The Limit",
+			body:      "type=kill&weapon=" + strings.Repeat("k", 15),
+			maxLine:   32,
+			wantTypes: []string{"kill"},
+		},
+		{
+			name:      "JSON Array",
+			body:      ` [{"type":"player_kill"},{"match_id":"m1"},{"type":"death","weapon":"` + strings.Repeat("k", 40) + `"},{"type":"death"}]`,
+			maxLine:   48,
+			wantTypes: []string{"player_kill", "death"},
+			wantRejected: []models.IngestRejection{
+				{Index: 1, Error: "type is required"},
+				{Index: 2, Error: "event exceeds 48 bytes"},
+			},
+		},
+		{
+			name:      "Array Element Over The Buffer",
+			body:      `[{"type":"chat","message":"` + strings.Repeat(`}]\"`, 64<<10) + `"}, 7, {"type":"death"} ]`,
+			maxLine:   32,
+			wantTypes: []string{"death"},
+			wantRejected: []models.IngestRejection{
+				{Index: 0, Error: "event exceeds 32 bytes"},
+				{Index: 1},
+			},
+		},
+		{
+			name: "Empty Array",
+			body: "[ ]",
+		},
+		{
+			name:      "Malformed Array",
+			body:      `[{"type":"player_kill"},{"type":`,
+			wantTypes: []string{"player_kill"},
+			wantErr:   errInvalidIngestArray,
+		},
+		{
+			name:      "Queue Full",
+			body:      "type=kill\ntype=death\ntype=spawn",
+			stopAfter: 1,
+			wantTypes: []string{"kill"},
+		},
+		{
+			name: "Empty",
+			body: " \n ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{logger: zap.NewNop().Sugar()}
+			var types []string
+			rejected, err := h.streamV1Payload(nulStripper{strings.NewReader(tt.body)}, tt.maxLine, func(e models.RawEvent) bool {
+				if tt.stopAfter > 0 && len(types) == tt.stopAfter {
+					return false
+				}
+				types = append(types, string(e.Type))
+				return true
+			})
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if strings.Join(types, ",") != strings.Join(tt.wantTypes, ",") {
+				t.Errorf("events = %v, want %v", types, tt.wantTypes)
+			}
+			if len(rejected) != len(tt.wantRejected) {
+				t.Fatalf("rejected = %+v, want %+v", rejected, tt.wantRejected)
+			}
+			for i, want := range tt.wantRejected {
+				got := rejected[i]
+				if got.Index != want.Index || got.Line != want.Line || (want.Error != "" && got.Error != want.Error) {
+					t.Errorf("rejected[%d] = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCappedReader(t *testing.T) {
+	h := &Handler{logger: zap.NewNop().Sugar()}
+	events := 0
+	body := &cappedReader{r: strings.NewReader("type=kill\ntype=death\ntype=spawn\n"), left: 20}
+	_, err := h.streamV1Payload(body, 0, func(models.RawEvent) bool {
+		events++
+		return true
+	})
+	if !errors.Is(err, errBodyTooLarge) {
+		t.Fatalf("err = %v, want errBodyTooLarge", err)
+	}
+	if events != 1 {
+		t.Errorf("events = %d, want the one before the cap", events)
+	}
+
+	exact := &cappedReader{r: strings.NewReader("type=kill\n"), left: 10}
+	if _, err := h.streamV1Payload(exact, 0, func(models.RawEvent) bool { return true }); err != nil {
+		t.Errorf("body of exactly the cap: err = %v", err)
+	}
+}
+
+func TestIngestEventsRejections(t *testing.T) {
+	h := &Handler{logger: zap.NewNop().Sugar(), pool: &MockIngestQueue{}, ingestMaxBody: 1 << 20}
+	req := httptest.NewRequest("POST", "/api/v1/ingest/events", strings.NewReader("type=kill\n{bad json}\ntype=death\n"))
+	w := httptest.NewRecorder()
+	h.IngestEvents(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("StatusCode = %d, want %d (%s)", w.Code, http.StatusAccepted, w.Body.String())
+	}
+	var resp struct {
+		Processed int                      `json:"processed"`
+		Rejected  []models.IngestRejection `json:"rejected"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Processed != 2 || len(resp.Rejected) != 1 || resp.Rejected[0].Line != 2 {
+		t.Errorf("response = %+v, want 2 processed and line 2 rejected", resp)
+	}
+}
+
+func TestIngestEventsEndedEarly(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		// Queued events must not be resent, so the batch is still accepted
+		{"Too Large After Events", "type=kill\ntype=death\n" + strings.Repeat("a", 64), http.StatusAccepted},
+		{"Malformed Array After Events", `[{"type":"player_kill"},{"type":`, http.StatusAccepted},
+		{"Too Large Before Events", strings.Repeat("a", 64), http.StatusRequestEntityTooLarge},
+		{"Malformed Array Before Events", `[{"type":`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{logger: zap.NewNop().Sugar(), pool: &MockIngestQueue{}, ingestMaxBody: 32}
+			// No Content-Length, so the cap is only hit while streaming
+			req := httptest.NewRequest("POST", "/api/v1/ingest/events", io.MultiReader(strings.NewReader(tt.body)))
+			w := httptest.NewRecorder()
+			h.IngestEvents(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("StatusCode = %d, want %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			var resp struct {
+				Status string `json:"status"`
+				Error  string `json:"error"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if resp.Status != "incomplete" || resp.Error == "" {
+				t.Errorf("response = %+v, want incomplete with an error", resp)
+			}
+		})
+	}
+}
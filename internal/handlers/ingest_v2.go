@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+const (
+	// IngestVersionHeader carries the payload version a game server speaks on
+	// requests, and the version the API parsed the payload with on responses
+	IngestVersionHeader = "X-Ingest-Version"
+	// IngestCapabilitiesHeader lists the optional ingest features supported
+	IngestCapabilitiesHeader = "X-Ingest-Capabilities"
+
+	ingestVersionLegacy = 1
+	ingestVersionLatest = 2
+)
+
+// ingestCapabilities are advertised on every ingest response so game scripts
+// can feature-detect before switching payload formats
+var ingestCapabilities = []string{"json-array", "strict-schema", "event-results", "gzip"}
+
+var errBodyTooLarge = errors.New("request body too large")
+
+// negotiateIngestVersion picks the payload version for a request. A client
+// asking for a newer version than we know gets the newest we support.
+func negotiateIngestVersion(r *http.Request, fallback int) int {
+	v, err := strconv.Atoi(strings.TrimSpace(r.Header.Get(IngestVersionHeader)))
+	if err != nil || v < ingestVersionLegacy {
+		return fallback
+	}
+	if v > ingestVersionLatest {
+		return ingestVersionLatest
+	}
+	return v
+}
+
+func setIngestHeaders(w http.ResponseWriter, version int) {
+	w.Header().Set(IngestVersionHeader, strconv.Itoa(version))
+	w.Header().Set(IngestCapabilitiesHeader, strings.Join(ingestCapabilities, ", "))
+}
+
+// readIngestBody reads a size-limited, optionally gzipped body and strips the
+// C-string artifacts game engines leave behind
+func readIngestBody(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodySize)
+	defer r.Body.Close()
+
+	var rd io.Reader = r.Body
+	if strings.EqualFold(r.Header.Get("Content-Encoding"), "gzip") {
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip body: %w", err)
+		}
+		defer zr.Close()
+		// Bound the decompressed size too, not just the wire size
+		rd = io.LimitReader(zr, MaxBodySize+1)
+	}
+
+	body, err := io.ReadAll(rd)
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) || len(body) > MaxBodySize {
+		return nil, errBodyTooLarge
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	body = bytes.ReplaceAll(body, []byte{0}, []byte{})
+	return bytes.TrimSpace(body), nil
+}
+
+// IngestEventsV2 handles POST /api/v2/ingest/events. The body must be a
+// typed JSON array; unknown fields and string-encoded numbers reject that
+// event (reported by index) instead of being guessed at. Swagger's base path
+// is /api/v1, so this endpoint is documented in openapi.yaml only.
+func (h *Handler) IngestEventsV2(w http.ResponseWriter, r *http.Request) {
+	setIngestHeaders(w, ingestVersionLatest)
+
+	if ct := r.Header.Get("Content-Type"); ct != "" && !strings.HasPrefix(ct, "application/json") {
+		h.errorResponse(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+		return
+	}
+
+	body, err := readIngestBody(w, r)
+	if err != nil {
+		h.ingestBodyError(w, err)
+		return
+	}
+
+	h.ingestStrict(w, r, body)
+}
+
+// ingestBodyError answers a readIngestBody error: 413 over the size limit,
+// 400 for anything else (e.g. an invalid gzip body)
+func (h *Handler) ingestBodyError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errBodyTooLarge) {
+		h.errorResponse(w, http.StatusRequestEntityTooLarge, "Request body too large")
+		return
+	}
+	h.errorResponse(w, http.StatusBadRequest, err.Error())
+}
+
+// ingestStrict parses a v2 body and responds with per-event results
+func (h *Handler) ingestStrict(w http.ResponseWriter, r *http.Request, body []byte) {
+	var items []json.RawMessage
+	if len(body) == 0 || body[0] != '[' {
+		h.errorResponse(w, http.StatusBadRequest, "Body must be a JSON array of events")
+		return
+	}
+	if err := json.Unmarshal(body, &items); err != nil {
+		h.errorResponse(w, http.StatusBadRequest, fmt.Sprintf("Invalid JSON array: %v", err))
+		return
+	}
+
+	resp := models.IngestResponse{
+		Status:   "accepted",
+		Version:  ingestVersionLatest,
+		Rejected: []models.IngestRejection{},
+	}
+
+	events := make([]models.RawEvent, 0, len(items))
+	for i, item := range items {
+		event, err := decodeStrictEvent(item)
+		if err != nil {
+			resp.Rejected = append(resp.Rejected, models.IngestRejection{Index: i, Error: err.Error()})
+			continue
+		}
+		events = append(events, event)
+	}
+
+	resp.Accepted = h.enqueueEvents(r, events)
+	resp.Dropped = len(events) - resp.Accepted
+
+	h.jsonResponse(w, http.StatusAccepted, resp)
+}
+
+// strictEvent has RawEvent's fields without its lenient UnmarshalJSON, so v2
+// payloads must use native JSON types
+type strictEvent models.RawEvent
+
+func decodeStrictEvent(raw json.RawMessage) (models.RawEvent, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+
+	var e strictEvent
+	if err := dec.Decode(&e); err != nil {
+		return models.RawEvent{}, err
+	}
+	event := models.RawEvent(e)
+	if event.Type == "" {
+		return models.RawEvent{}, errors.New("type is required")
+	}
+	if event.ServerToken != "" {
+		return models.RawEvent{}, errors.New("server_token must be sent in the X-Server-Token header")
+	}
+	return event, nil
+}
+
+// ingestTarget stamps server, tenant and sandbox identity on the events of
+// a request and hands them to the ingest queue
+type ingestTarget struct {
+	queue    IngestQueue
+	serverID string
+	tenantID string
+	sandbox  bool
+}
+
+func (h *Handler) ingestTarget(r *http.Request) ingestTarget {
+	t := ingestTarget{
+		queue: h.pool,
+		// Events always carry the authenticated server's tenant, never the payload's
+		tenantID: logic.TenantFromContext(r.Context()),
+		sandbox:  logic.SandboxFromContext(r.Context()),
+	}
+	t.serverID, _ = r.Context().Value("server_id").(string)
+	if h.ingest != nil {
+		t.queue = h.ingest
+	}
+	return t
+}
+
+// enqueue reports whether the queue took the event
+func (t ingestTarget) enqueue(event models.RawEvent) bool {
+	// Events always come from the authenticated server, whatever the payload
+	// names, so a token cannot write into another server's stats
+	event.ServerID = t.serverID
+	event.TenantID = t.tenantID
+	event.Sandbox = t.sandbox
+	return t.queue.Enqueue(&event)
+}
+
+// enqueueEvents hands events to the ingest queue, returning how many were
+// queued. It stops at the first event the full queue refuses.
+func (h *Handler) enqueueEvents(r *http.Request, events []models.RawEvent) int {
+	target := h.ingestTarget(r)
+	processed := 0
+	for i := range events {
+		if events[i].Type == "" {
+			h.ingestLogger().Warnw("Event has empty type, skipping", "index", i)
+			continue
+		}
+
+		h.ingestLogger().Debugw("Enqueueing event", "index", i, "type", events[i].Type, "match_id", events[i].MatchID)
+		if !target.enqueue(events[i]) {
+			h.ingestLogger().Warn("Ingest queue full, dropping remaining events in batch")
+			break
+		}
+		processed++
+	}
+	return processed
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestIngestEventsV2(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		contentType  string
+		wantStatus   int
+		wantAccepted int
+		wantRejected []int
+	}{
+		{
+			name:         "Typed Array",
+			body:         `[{"type":"player_kill","match_id":"m1","timestamp":12.5}]`,
+			wantStatus:   http.StatusAccepted,
+			wantAccepted: 1,
+		},
+		{
+			name:         "Per Event Rejections",
+			body:         `[{"type":"player_kill"},{"type":"damage","timestamp":"12.5"},{"type":"death","bogus":1},{"match_id":"m1"}]`,
+			wantStatus:   http.StatusAccepted,
+			wantAccepted: 1,
+			wantRejected: []int{1, 2, 3},
+		},
+		{
+			name:         "Token In Payload",
+			body:         `[{"type":"player_kill","server_token":"secret"}]`,
+			wantStatus:   http.StatusAccepted,
+			wantRejected: []int{0},
+		},
+		{
+			name:       "NDJSON Refused",
+			body:       "{\"type\":\"player_kill\"}\n{\"type\":\"death\"}",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "Wrong Content Type",
+			body:        "type=player_kill",
+			contentType: "application/x-www-form-urlencoded",
+			wantStatus:  http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{logger: zap.NewNop().Sugar(), pool: &MockIngestQueue{}}
+
+			req := httptest.NewRequest("POST", "/api/v2/ingest/events", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+			h.IngestEventsV2(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("StatusCode = %d, want %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if got := w.Header().Get(IngestVersionHeader); got != "2" {
+				t.Errorf("%s = %q, want 2", IngestVersionHeader, got)
+			}
+			if w.Code != http.StatusAccepted {
+				return
+			}
+
+			var resp models.IngestResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if resp.Accepted != tt.wantAccepted {
+				t.Errorf("Accepted = %d, want %d", resp.Accepted, tt.wantAccepted)
+			}
+			if len(resp.Rejected) != len(tt.wantRejected) {
+				t.Fatalf("Rejected = %+v, want indexes %v", resp.Rejected, tt.wantRejected)
+			}
+			for i, rej := range resp.Rejected {
+				if rej.Index != tt.wantRejected[i] {
+					t.Errorf("Rejected[%d].Index = %d, want %d", i, rej.Index, tt.wantRejected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestIngestEventsV2Gzip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(`[{"type":"player_kill"},{"type":"death"}]`))
+	zw.Close()
+
+	h := &Handler{logger: zap.NewNop().Sugar(), pool: &MockIngestQueue{}}
+	req := httptest.NewRequest("POST", "/api/v2/ingest/events", &buf)
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	h.IngestEventsV2(w, req)
+
+	if w.Code != http.StatusAccepted || !strings.Contains(w.Body.String(), `"accepted":2`) {
+		t.Fatalf("got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestIngestEventsServerID(t *testing.T) {
+	var got []string
+	h := &Handler{logger: zap.NewNop().Sugar(), pool: &MockIngestQueue{EnqueueFunc: func(event *models.RawEvent) bool {
+		got = append(got, event.ServerID)
+		return true
+	}}}
+	req := httptest.NewRequest("POST", "/api/v2/ingest/events", strings.NewReader(`[{"type":"player_kill","server_id":"other"},{"type":"death"}]`))
+	req = req.WithContext(context.WithValue(req.Context(), "server_id", "mine"))
+	h.IngestEventsV2(httptest.NewRecorder(), req)
+
+	// The payload never names another server
+	if len(got) != 2 || got[0] != "mine" || got[1] != "mine" {
+		t.Errorf("server IDs = %v, want the authenticated server's", got)
+	}
+}
+
+func TestIngestEventsVersionNegotiation(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      string
+		encoding    string
+		body        string
+		wantVersion string
+		wantStatus  int
+	}{
+		{name: "Legacy Default", body: "type=player_kill", wantVersion: "1", wantStatus: http.StatusAccepted},
+		{name: "Negotiated V2", header: "2", body: "type=player_kill", wantVersion: "2", wantStatus: http.StatusBadRequest},
+		{name: "Future Version", header: "7", body: `[{"type":"player_kill"}]`, wantVersion: "2", wantStatus: http.StatusAccepted},
+		{name: "Negotiated V2 Invalid Gzip", header: "2", encoding: "gzip", body: "not gzip", wantVersion: "2", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{logger: zap.NewNop().Sugar(), pool: &MockIngestQueue{}}
+			req := httptest.NewRequest("POST", "/api/v1/ingest/events", strings.NewReader(tt.body))
+			if tt.header != "" {
+				req.Header.Set(IngestVersionHeader, tt.header)
+			}
+			if tt.encoding != "" {
+				req.Header.Set("Content-Encoding", tt.encoding)
+			}
+			w := httptest.NewRecorder()
+			h.IngestEvents(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("StatusCode = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get(IngestVersionHeader); got != tt.wantVersion {
+				t.Errorf("%s = %q, want %q", IngestVersionHeader, got, tt.wantVersion)
+			}
+			if w.Header().Get(IngestCapabilitiesHeader) == "" {
+				t.Errorf("missing %s", IngestCapabilitiesHeader)
+			}
+		})
+	}
+}
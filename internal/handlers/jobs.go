@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/openmohaa/stats-api/internal/jobs"
+)
+
+// ListJobs returns the periodic jobs with their schedule and latest run
+// @Summary List Scheduled Jobs
+// @Description The periodic jobs of the internal scheduler: schedule (cron in UTC or @every), timeout, next run on this instance, the instance holding the job's lock if it is running anywhere, and the latest run.
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Success 200 {array} models.JobInfo
+// @Failure 503 {object} map[string]string
+// @Router /admin/jobs [get]
+func (h *Handler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	if h.jobs == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Job scheduler not enabled")
+		return
+	}
+	infos, err := h.jobs.Jobs(r.Context())
+	if err != nil {
+		h.logger.Errorw("Failed to list jobs", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to list jobs")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, infos)
+}
+
+// GetJobRuns returns the run history of a job
+// @Summary Get Job Runs
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Param name path string true "Job name"
+// @Param limit query int false "Runs to return, newest first" default(50)
+// @Success 200 {array} models.JobRun
+// @Failure 404 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /admin/jobs/{name}/runs [get]
+func (h *Handler) GetJobRuns(w http.ResponseWriter, r *http.Request) {
+	if h.jobs == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Job scheduler not enabled")
+		return
+	}
+	limit := 50
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 500 {
+		limit = l
+	}
+	runs, err := h.jobs.Runs(r.Context(), chi.URLParam(r, "name"), limit)
+	if errors.Is(err, jobs.ErrUnknownJob) {
+		h.errorResponse(w, http.StatusNotFound, "Job not found")
+		return
+	}
+	if err != nil {
+		h.logger.Errorw("Failed to get job runs", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to get job runs")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, runs)
+}
+
+// RunJob starts a job now, out of schedule
+// @Summary Run Job
+// @Description Starts a run on this instance and returns it as started; follow it on /admin/jobs/{name}/runs. Fails with 409 while the job runs on any instance.
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Param name path string true "Job name"
+// @Success 202 {object} models.JobRun
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /admin/jobs/{name}/run [post]
+func (h *Handler) RunJob(w http.ResponseWriter, r *http.Request) {
+	if h.jobs == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Job scheduler not enabled")
+		return
+	}
+	name := chi.URLParam(r, "name")
+	run, err := h.jobs.Trigger(r.Context(), name)
+	switch {
+	case errors.Is(err, jobs.ErrUnknownJob):
+		h.errorResponse(w, http.StatusNotFound, "Job not found")
+		return
+	case errors.Is(err, jobs.ErrJobRunning):
+		h.errorResponse(w, http.StatusConflict, "Job is already running")
+		return
+	case errors.Is(err, jobs.ErrNotStarted):
+		h.errorResponse(w, http.StatusServiceUnavailable, "Job scheduler not started")
+		return
+	case err != nil:
+		h.logger.Errorw("Failed to start job", "job", name, "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to start job")
+		return
+	}
+	h.logger.Infow("Job started by admin", "job", name, "run", run.ID)
+	h.jsonResponse(w, http.StatusAccepted, run)
+}
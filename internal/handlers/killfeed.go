@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/openmohaa/stats-api/internal/models"
+	"github.com/openmohaa/stats-api/internal/worker"
+)
+
+// GetKillfeedContext returns context for a killfeed line
+// @Summary Killfeed Context
+// @Description Current kill streaks, head-to-head record and kill rank progress for a kill, read from live state only so mods can call it on every kill. Counts cover the events processed so far; the kill being reported is usually not in them yet.
+// @Tags Integrations
+// @Produce json
+// @Security ServerToken
+// @Param attacker query string true "Attacker GUID"
+// @Param victim query string false "Victim GUID"
+// @Param match_id query string false "Match ID, for streaks"
+// @Success 200 {object} models.KillfeedContext
+// @Failure 400 {object} map[string]string
+// @Router /integrations/killfeed [get]
+func (h *Handler) GetKillfeedContext(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	attacker := q.Get("attacker")
+	if attacker == "" || attacker == "world" {
+		h.errorResponse(w, http.StatusBadRequest, "attacker is required")
+		return
+	}
+
+	kc, err := worker.ReadKillfeed(r.Context(), h.redis, q.Get("match_id"), attacker, q.Get("victim"))
+	if err != nil {
+		h.logger.Errorw("Failed to read killfeed context", "attacker", attacker, "error", err)
+		h.errorResponse(w, http.StatusServiceUnavailable, "Live state unavailable")
+		return
+	}
+
+	// Lifetime counts belong to the profile, so a hidden one keeps them out
+	// of the feed too. Privacy that cannot be read hides them as well.
+	visibility, err := h.playerVisibility(r.Context(), []string{kc.AttackerGUID, kc.VictimGUID})
+	if err != nil {
+		h.logger.Warnw("Failed to read player privacy", "attacker", attacker, "error", err)
+	}
+	if err != nil || visibility[kc.AttackerGUID].HideProfile {
+		kc.AttackerKills, kc.Rank, kc.NextRank, kc.KillsToNextRank = 0, "", "", 0
+	}
+	if err != nil || visibility[kc.AttackerGUID].HideProfile || visibility[kc.VictimGUID].HideProfile {
+		kc.HeadToHead = models.HeadToHead{}
+	}
+	h.jsonResponse(w, http.StatusOK, kc)
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// launcherRecentMatches is how many recent matches the home screen lists
+const launcherRecentMatches = 5
+
+// GetLauncherHome returns a player's launcher home screen
+// @Summary Launcher Home Screen
+// @Description Rank, recent matches, this week's challenges and favorite servers with live status, for the openmohaa launcher's home screen in one call. Parts that fail to load are left empty rather than failing the request.
+// @Tags Integrations
+// @Produce json
+// @Param guid path string true "Player GUID"
+// @Success 200 {object} models.LauncherHome
+// @Failure 500 {object} map[string]string
+// @Router /integrations/launcher/home/{guid} [get]
+func (h *Handler) GetLauncherHome(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	guid := chi.URLParam(r, "guid")
+
+	// The precomputed profile has everything; otherwise only what is shown
+	player := h.profiles.Read(ctx, guid)
+	if player == nil {
+		var err error
+		player, err = h.playerStats.GetProfile(ctx, guid, []string{models.SectionCombat, models.SectionRecentMatches})
+		if err != nil {
+			h.logger.Errorw("Failed to get launcher profile", "guid", guid, "error", err)
+			h.errorResponse(w, http.StatusInternalServerError, "Failed to get player stats")
+			return
+		}
+	}
+
+	home := models.LauncherHome{
+		GUID:            guid,
+		Name:            h.profileName(ctx, guid, player.Name),
+		Kills:           player.Kills,
+		Deaths:          player.Deaths,
+		KDRatio:         player.KDRatio,
+		RecentMatches:   player.RecentMatches,
+		Challenges:      []models.LauncherChallenge{},
+		FavoriteServers: []models.LauncherServer{},
+	}
+	if len(home.RecentMatches) > launcherRecentMatches {
+		home.RecentMatches = home.RecentMatches[:launcherRecentMatches]
+	}
+	if home.RecentMatches == nil {
+		home.RecentMatches = []models.RecentMatch{}
+	}
+
+	if h.ratings != nil {
+		rank, err := h.ratings.Rank(ctx, guid)
+		if err != nil {
+			h.logger.Warnw("Failed to get launcher rank", "guid", guid, "error", err)
+		}
+		home.Rank = rank
+	}
+
+	if challenges, err := h.playerChallenges(ctx, guid); err == nil {
+		home.ChallengesEndAt = challenges.EndsAt
+		for _, c := range challenges.Challenges {
+			home.Challenges = append(home.Challenges, models.LauncherChallenge{
+				Code:      c.Code,
+				Title:     c.Title,
+				Progress:  c.Progress,
+				Target:    c.Target,
+				Completed: c.Completed,
+			})
+		}
+	}
+
+	favorites, err := h.getServerTracking().GetPlayerFavoriteServers(ctx, guid)
+	if err != nil {
+		h.logger.Warnw("Failed to get launcher favorites", "guid", guid, "error", err)
+	}
+	for _, srv := range favorites {
+		home.FavoriteServers = append(home.FavoriteServers, models.LauncherServer{
+			ID:         srv.ID,
+			Name:       srv.DisplayName,
+			Address:    srv.Address,
+			Port:       srv.Port,
+			Online:     srv.IsOnline,
+			Players:    srv.CurrentPlayers,
+			MaxPlayers: srv.MaxPlayers,
+			Map:        srv.CurrentMap,
+			Gametype:   srv.Gametype,
+		})
+	}
+
+	h.jsonResponse(w, http.StatusOK, home)
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// GetLeaderboardChanges returns the biggest rank movements of a leaderboard
+// @Summary Leaderboard Rank Changes
+// @Description Biggest climbers and fallers of an all-time leaderboard (kills, kd, headshots, wins, damage) between the daily snapshot taken at the end of the last finished UTC day and the one a day, week or month before it. Players entering or leaving the top 100 move from or to rank 101.
+// @Tags Stats
+// @Produce json
+// @Param stat path string true "kills, kd, headshots, wins or damage"
+// @Param period query string false "day, week or month" default(day)
+// @Param limit query int false "Players per list (max 50)" default(10)
+// @Success 200 {object} models.LeaderboardChanges
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /stats/leaderboard/{stat}/changes [get]
+func (h *Handler) GetLeaderboardChanges(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	q := r.URL.Query()
+	stat := chi.URLParam(r, "stat")
+
+	period := q.Get("period")
+	if period == "" {
+		period = "day"
+	}
+	days, ok := logic.LeaderboardChangePeriods[period]
+	if !ok {
+		h.errorResponse(w, http.StatusBadRequest, "period must be day, week or month")
+		return
+	}
+	limit := 10
+	if l, err := strconv.Atoi(q.Get("limit")); err == nil && l > 0 && l <= 50 {
+		limit = l
+	}
+
+	changes, err := h.snapshots.Changes(ctx, stat, days, limit)
+	if errors.Is(err, logic.ErrUnknownSnapshotStat) {
+		h.errorResponse(w, http.StatusNotFound, "No rank changes are kept for this leaderboard")
+		return
+	}
+	if err != nil {
+		h.logger.Errorw("Failed to get leaderboard changes", "stat", stat, "period", period, "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to get leaderboard changes")
+		return
+	}
+	changes.Period = period
+
+	moveName := func(m *models.LeaderboardMove) (string, *string, *bool) {
+		return m.PlayerID, &m.PlayerName, &m.NameFlagged
+	}
+	changes.Climbers = applyDisplayNames(ctx, h, changes.Climbers, moveName)
+	changes.Fallers = applyDisplayNames(ctx, h, changes.Fallers, moveName)
+	h.jsonResponse(w, http.StatusOK, changes)
+}
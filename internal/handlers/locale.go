@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/openmohaa/stats-api/internal/i18n"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// LocaleMiddleware picks the locale display strings are served in from the
+// request's Accept-Language and announces it in Content-Language
+func (h *Handler) LocaleMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		locale := i18n.Default().Negotiate(r.Header.Get("Accept-Language"))
+		w.Header().Set("Content-Language", locale)
+		w.Header().Add("Vary", "Accept-Language")
+		next.ServeHTTP(w, r.WithContext(i18n.WithLocale(r.Context(), locale)))
+	})
+}
+
+// translateAchievement translates an achievement's name and description by
+// its code, keeping the stored text for codes without translations
+func translateAchievement(ctx context.Context, code string, name, description *string) {
+	*name = i18n.T(ctx, "achievement."+code+".name", *name)
+	*description = i18n.T(ctx, "achievement."+code+".description", *description)
+}
+
+func translateContextualAchievements(ctx context.Context, list []models.ContextualAchievement) {
+	for i := range list {
+		translateAchievement(ctx, list[i].ID, &list[i].Name, &list[i].Description)
+	}
+}
+
+// translatePlaystyle translates a playstyle badge by its English name
+func translatePlaystyle(ctx context.Context, badge *models.PlaystyleBadge) {
+	if badge == nil {
+		return
+	}
+	key := "playstyle." + strings.ToLower(badge.Name)
+	badge.Name, badge.Description = i18n.T(ctx, key+".name", badge.Name), i18n.T(ctx, key+".description", badge.Description)
+}
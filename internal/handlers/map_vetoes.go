@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// RecordMapVeto stores the map veto of a scheduled tournament series
+// @Summary Record Map Veto
+// @Description Stores the ordered bans and picks of a series, replacing the veto recorded before. Bans and picks are taken by team a or b, the leftover decider by neither. Record it again after the series with match_id and team_a_side on the played maps so their winners count towards the veto analytics.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ServerToken
+// @Param id path string true "Tournament ID"
+// @Param seriesId path string true "Scheduled series ID"
+// @Param body body models.MapVetoRequest true "Veto sequence"
+// @Success 200 {object} models.MapVeto
+// @Failure 400 {object} map[string]string
+// @Router /admin/tournaments/{id}/vetoes/{seriesId} [put]
+func (h *Handler) RecordMapVeto(w http.ResponseWriter, r *http.Request) {
+	if h.mapVetoes == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Map vetoes not enabled")
+		return
+	}
+	var req models.MapVetoRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	ctx := r.Context()
+	tournamentID, seriesID := chi.URLParam(r, "id"), chi.URLParam(r, "seriesId")
+	actor, _ := ctx.Value("server_id").(string)
+	veto, err := h.mapVetoes.Record(ctx, tournamentID, seriesID, &req, actor)
+	if err != nil {
+		h.mapVetoError(w, err)
+		return
+	}
+	h.logger.Infow("Map veto recorded", "tournament", tournamentID, "series", seriesID, "steps", len(veto.Steps), "by", actor)
+	h.jsonResponse(w, http.StatusOK, veto)
+}
+
+// ListMapVetoes returns the map vetoes of a tournament
+// @Summary List Map Vetoes
+// @Tags Tournaments
+// @Produce json
+// @Param id path string true "Tournament ID"
+// @Success 200 {array} models.MapVeto
+// @Router /tournaments/{id}/vetoes [get]
+func (h *Handler) ListMapVetoes(w http.ResponseWriter, r *http.Request) {
+	if h.mapVetoes == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Map vetoes not enabled")
+		return
+	}
+	vetoes, err := h.mapVetoes.List(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		h.mapVetoError(w, err)
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, vetoes)
+}
+
+// GetMapVeto returns the map veto of a series
+// @Summary Get Map Veto
+// @Description Played maps carry the winning team (a, b or draw) once their match is finalized.
+// @Tags Tournaments
+// @Produce json
+// @Param id path string true "Tournament ID"
+// @Param seriesId path string true "Scheduled series ID"
+// @Success 200 {object} models.MapVeto
+// @Failure 404 {object} map[string]string
+// @Router /tournaments/{id}/vetoes/{seriesId} [get]
+func (h *Handler) GetMapVeto(w http.ResponseWriter, r *http.Request) {
+	if h.mapVetoes == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Map vetoes not enabled")
+		return
+	}
+	veto, err := h.mapVetoes.Get(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "seriesId"))
+	if err != nil {
+		h.mapVetoError(w, err)
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, veto)
+}
+
+// GetVetoAnalytics returns the veto analytics of a tournament
+// @Summary Get Veto Analytics
+// @Description Per map bans, first bans, picks and deciders, most banned first, and how often the team that picked a map won it. Only maps whose match is finalized count towards the win rates.
+// @Tags Tournaments
+// @Produce json
+// @Param id path string true "Tournament ID"
+// @Success 200 {object} models.VetoAnalytics
+// @Router /tournaments/{id}/veto-stats [get]
+func (h *Handler) GetVetoAnalytics(w http.ResponseWriter, r *http.Request) {
+	if h.mapVetoes == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Map vetoes not enabled")
+		return
+	}
+	analytics, err := h.mapVetoes.Analytics(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		h.mapVetoError(w, err)
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, analytics)
+}
+
+// mapVetoError maps map veto errors to responses
+func (h *Handler) mapVetoError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, logic.ErrInvalidVeto):
+		h.errorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, logic.ErrVetoNotFound):
+		h.errorResponse(w, http.StatusNotFound, "Map veto not found")
+	default:
+		h.logger.Errorw("Failed to handle map veto", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to handle map veto")
+	}
+}
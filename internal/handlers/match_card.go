@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/openmohaa/stats-api/internal/chart"
+	"github.com/openmohaa/stats-api/internal/i18n"
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+const shareSiteName = "OpenMOHAA Stats"
+
+// GetMatchCard returns the share card metadata of a match
+// @Summary Match Share Card
+// @Description OpenGraph and Twitter card metadata for a match link: title, description, page URL and a 1200x630 image (card.svg). With format=html, a page carrying the tags that sends browsers on to MATCH_PAGE_URL, to post as the match link itself.
+// @Tags Match
+// @Produce json,html
+// @Param matchId path string true "Match ID"
+// @Param format query string false "json or html" default(json)
+// @Success 200 {object} models.MatchCard
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /stats/match/{matchId}/card [get]
+func (h *Handler) GetMatchCard(w http.ResponseWriter, r *http.Request) {
+	stats, ok := h.matchCardStats(w, r)
+	if !ok {
+		return
+	}
+
+	base := h.shareBaseURL(r)
+	cardURL := base + r.URL.Path
+	card := &models.MatchCard{
+		MatchID:     stats.MatchID,
+		Title:       matchCardTitle(r.Context(), h, stats),
+		Description: strings.Join(matchCardLines(r.Context(), stats), " · "),
+		URL:         cardURL + "?format=html",
+		Image:       cardURL + ".svg",
+		ImageWidth:  chart.CardWidth,
+		ImageHeight: chart.CardHeight,
+	}
+	if h.matchPageURL != "" {
+		card.URL = strings.ReplaceAll(h.matchPageURL, "{id}", stats.MatchID)
+	}
+	card.Meta = map[string]string{
+		"og:type":             "website",
+		"og:site_name":        shareSiteName,
+		"og:title":            card.Title,
+		"og:description":      card.Description,
+		"og:url":              card.URL,
+		"og:image":            card.Image,
+		"og:image:type":       "image/svg+xml",
+		"og:image:width":      "1200",
+		"og:image:height":     "630",
+		"twitter:card":        "summary_large_image",
+		"twitter:title":       card.Title,
+		"twitter:description": card.Description,
+		"twitter:image":       card.Image,
+	}
+
+	if r.URL.Query().Get("format") != "html" {
+		h.jsonResponse(w, http.StatusOK, card)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := matchCardPage.Execute(w, map[string]any{
+		"Card":     card,
+		"Redirect": h.matchPageURL != "",
+	}); err != nil {
+		h.logger.Errorw("Failed to render match card page", "match", stats.MatchID, "error", err)
+	}
+}
+
+// GetMatchCardImage returns the share image of a match
+// @Summary Match Share Image
+// @Description 1200x630 SVG with the map, score, match details and a chart of the top fraggers' kills
+// @Tags Match
+// @Produce image/svg+xml
+// @Param matchId path string true "Match ID"
+// @Success 200 {string} string "SVG image"
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /stats/match/{matchId}/card.svg [get]
+func (h *Handler) GetMatchCardImage(w http.ResponseWriter, r *http.Request) {
+	stats, ok := h.matchCardStats(w, r)
+	if !ok {
+		return
+	}
+	ctx := r.Context()
+
+	bars := &chart.BarChart{Title: statName(ctx, "kills"), Color: "#e74c3c"}
+	for _, p := range stats.TopPlayers {
+		bars.Labels = append(bars.Labels, p.Name)
+		bars.Values = append(bars.Values, uint64(p.Kills))
+	}
+	lines := matchCardLines(ctx, stats)
+	card := chart.Card{
+		Title:    matchCardTitle(ctx, h, stats),
+		Subtitle: gameTypeName(ctx, stats.Gametype),
+		Headline: lines[0],
+		Lines:    lines[1:],
+		Footer:   shareSiteName + " · " + stats.StartedAt.UTC().Format("2006-01-02 15:04 UTC"),
+	}
+	if len(bars.Values) > 0 {
+		card.Chart = bars
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Write([]byte(card.SVG()))
+}
+
+// matchCardStats reads the match of the request with display names for its
+// top players, answering 404 for unknown matches
+func (h *Handler) matchCardStats(w http.ResponseWriter, r *http.Request) (*models.MatchCardStats, bool) {
+	matchID := chi.URLParam(r, "matchId")
+	if _, err := uuid.Parse(matchID); err != nil {
+		h.errorResponse(w, http.StatusNotFound, "Match not found")
+		return nil, false
+	}
+	stats, err := h.matchReport.GetMatchCard(r.Context(), matchID)
+	if errors.Is(err, logic.ErrMatchNotFound) {
+		h.errorResponse(w, http.StatusNotFound, "Match not found")
+		return nil, false
+	}
+	if err != nil {
+		h.logger.Errorw("Failed to get match card", "match", matchID, "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to get match card")
+		return nil, false
+	}
+	stats.TopPlayers = applyDisplayNames(r.Context(), h, stats.TopPlayers, func(p *models.MatchCardPlayer) (string, *string, *bool) {
+		return p.GUID, &p.Name, &p.NameFlagged
+	})
+	return stats, true
+}
+
+// matchCardTitle names the map and, when registered, the server
+func matchCardTitle(ctx context.Context, h *Handler, stats *models.MatchCardStats) string {
+	var server string
+	if stats.ServerID != "" && h.pg != nil {
+		_ = h.pg.QueryRow(ctx, "SELECT name FROM servers WHERE id = $1", stats.ServerID).Scan(&server)
+	}
+	if server == "" {
+		return stats.MapName
+	}
+	return i18n.Sprintf(ctx, "card.match.title", "%s on %s", stats.MapName, server)
+}
+
+// matchCardLines are the score, the match details and the top fragger
+func matchCardLines(ctx context.Context, stats *models.MatchCardStats) []string {
+	lines := []string{
+		i18n.Sprintf(ctx, "card.match.score", "Allies %d – %d Axis", stats.AlliesScore, stats.AxisScore),
+		i18n.Sprintf(ctx, "card.match.details", "%d players · %d kills · %d min",
+			stats.Players, stats.Kills, int(stats.Duration.Minutes())),
+	}
+	if len(stats.TopPlayers) > 0 {
+		top := stats.TopPlayers[0]
+		lines = append(lines, i18n.Sprintf(ctx, "card.match.top", "Top fragger: %s (%d kills)", top.Name, top.Kills))
+	}
+	return lines
+}
+
+// shareBaseURL is PublicURL, or the scheme and host the request came in on
+func (h *Handler) shareBaseURL(r *http.Request) string {
+	if h.publicURL != "" {
+		return strings.TrimRight(h.publicURL, "/")
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+		scheme = proto
+	}
+	return scheme + "://" + r.Host
+}
+
+var matchCardPage = template.Must(template.New("card").Parse(`<!doctype html>
+<html>
+  <head>
+    <meta charset="utf-8" />
+    <title>{{.Card.Title}}</title>
+    <meta name="description" content="{{.Card.Description}}" />
+    {{range $key, $value := .Card.Meta}}<meta property="{{$key}}" name="{{$key}}" content="{{$value}}" />
+    {{end}}{{if .Redirect}}<meta http-equiv="refresh" content="0; url={{.Card.URL}}" />{{end}}
+  </head>
+  <body>
+    <h1>{{.Card.Title}}</h1>
+    <p>{{.Card.Description}}</p>
+    <img src="{{.Card.Image}}" width="{{.Card.ImageWidth}}" height="{{.Card.ImageHeight}}" alt="{{.Card.Title}}" />
+    {{if .Redirect}}<p><a href="{{.Card.URL}}">{{.Card.URL}}</a></p>{{end}}
+  </body>
+</html>
+`))
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+const cardMatchID = "0b7e4a4e-2f6a-4d55-9a3b-1c2d3e4f5a6b"
+
+// mockMatchReport serves one match card
+type mockMatchReport struct {
+	logic.MatchReportService
+}
+
+func (m *mockMatchReport) GetMatchCard(ctx context.Context, matchID string) (*models.MatchCardStats, error) {
+	if matchID != cardMatchID {
+		return nil, logic.ErrMatchNotFound
+	}
+	return &models.MatchCardStats{
+		MatchID:     matchID,
+		MapName:     "obj/obj_team2",
+		Gametype:    "obj",
+		AlliesScore: 5,
+		AxisScore:   3,
+		Players:     12,
+		Kills:       140,
+		StartedAt:   time.Date(2026, 10, 15, 20, 0, 0, 0, time.UTC),
+		Duration:    23 * time.Minute,
+		TopPlayers: []models.MatchCardPlayer{
+			{GUID: "a", Name: "^1Sarge<b>", Kills: 31, Deaths: 12},
+			{GUID: "b", Name: "Medic", Kills: 20, Deaths: 15},
+		},
+	}, nil
+}
+
+func serveMatchCard(h *Handler, path string) *httptest.ResponseRecorder {
+	r := chi.NewRouter()
+	r.Get("/stats/match/{matchId}/card", h.GetMatchCard)
+	r.Get("/stats/match/{matchId}/card.svg", h.GetMatchCardImage)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://stats.example.com"+path, nil))
+	return w
+}
+
+func TestGetMatchCard(t *testing.T) {
+	h := &Handler{logger: zap.NewNop().Sugar(), matchReport: &mockMatchReport{}}
+
+	w := serveMatchCard(h, "/stats/match/"+cardMatchID+"/card")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	var card models.MatchCard
+	if err := json.NewDecoder(w.Body).Decode(&card); err != nil {
+		t.Fatal(err)
+	}
+	if want := "http://stats.example.com/stats/match/" + cardMatchID + "/card.svg"; card.Image != want || card.Meta["og:image"] != want {
+		t.Errorf("image = %q, want %q", card.Image, want)
+	}
+	if card.Meta["twitter:card"] != "summary_large_image" || card.Title != "obj/obj_team2" {
+		t.Errorf("card = %+v", card)
+	}
+	if want := "Allies 5 – 3 Axis · 12 players · 140 kills · 23 min · Top fragger: Sarge<b> (31 kills)"; card.Description != want {
+		t.Errorf("description = %q", card.Description)
+	}
+
+	h.publicURL, h.matchPageURL = "https://api.example.com/", "https://forum.example.com/match?id={id}"
+	w = serveMatchCard(h, "/stats/match/"+cardMatchID+"/card?format=html")
+	page := w.Body.String()
+	if !strings.Contains(page, `content="https://api.example.com/stats/match/`+cardMatchID+`/card.svg"`) ||
+		!strings.Contains(page, `url=https://forum.example.com/match?id=`+cardMatchID) {
+		t.Errorf("page lacks the image or redirect:\n%s", page)
+	}
+	if strings.Contains(page, "Sarge<b>") {
+		t.Error("page does not escape names")
+	}
+
+	if w := serveMatchCard(h, "/stats/match/not-a-match/card"); w.Code != http.StatusNotFound {
+		t.Errorf("invalid ID status = %d", w.Code)
+	}
+	if w := serveMatchCard(h, "/stats/match/00000000-0000-0000-0000-000000000000/card.svg"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown match status = %d", w.Code)
+	}
+}
+
+func TestGetMatchCardImage(t *testing.T) {
+	h := &Handler{logger: zap.NewNop().Sugar(), matchReport: &mockMatchReport{}}
+
+	w := serveMatchCard(h, "/stats/match/"+cardMatchID+"/card.svg")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/svg+xml" {
+		t.Fatalf("status = %d, type %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	svg := w.Body.String()
+	for _, want := range []string{`width="1200" height="630"`, "Allies 5 – 3 Axis", "Sarge&lt;b&gt;", ">31</text>"} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("image lacks %q", want)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// OpenMatchDispute starts a dispute on a tournament match
+// @Summary Open Match Dispute
+// @Description Opens a dispute on a tournament match's result. A match has one open dispute at a time; attach evidence with POST /admin/disputes/{id}/notes and close it with a correction or POST /admin/disputes/{id}/reject.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ServerToken
+// @Param matchId path string true "Match ID"
+// @Param body body models.DisputeOpenRequest true "Reason"
+// @Success 201 {object} models.MatchDispute
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /admin/matches/{matchId}/disputes [post]
+func (h *Handler) OpenMatchDispute(w http.ResponseWriter, r *http.Request) {
+	if h.disputes == nil || h.matchStates == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Match disputes not enabled")
+		return
+	}
+	var req models.DisputeOpenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		h.errorResponse(w, http.StatusBadRequest, "A reason is required")
+		return
+	}
+
+	ctx := r.Context()
+	record, err := h.matchStateRecord(ctx, chi.URLParam(r, "matchId"))
+	if err != nil {
+		h.disputeError(w, err)
+		return
+	}
+	actor, _ := ctx.Value("server_id").(string)
+	dispute, err := h.disputes.Open(ctx, record, req.Reason, actor)
+	if err != nil {
+		h.disputeError(w, err)
+		return
+	}
+	h.logger.Infow("Match dispute opened", "dispute", dispute.ID, "match", record.MatchID, "tournament", record.TournamentID, "by", actor)
+	h.jsonResponse(w, http.StatusCreated, dispute)
+}
+
+// ListMatchDisputes returns a match's disputes
+// @Summary List Match Disputes
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Param matchId path string true "Match ID"
+// @Success 200 {array} models.MatchDispute
+// @Router /admin/matches/{matchId}/disputes [get]
+func (h *Handler) ListMatchDisputes(w http.ResponseWriter, r *http.Request) {
+	if h.disputes == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Match disputes not enabled")
+		return
+	}
+	disputes, err := h.disputes.List(r.Context(), chi.URLParam(r, "matchId"))
+	if err != nil {
+		h.disputeError(w, err)
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, disputes)
+}
+
+// GetDispute returns a dispute with its evidence and correction
+// @Summary Get Dispute
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Param id path int true "Dispute ID"
+// @Success 200 {object} models.MatchDispute
+// @Failure 404 {object} map[string]string
+// @Router /admin/disputes/{id} [get]
+func (h *Handler) GetDispute(w http.ResponseWriter, r *http.Request) {
+	if h.disputes == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Match disputes not enabled")
+		return
+	}
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.errorResponse(w, http.StatusNotFound, "Dispute not found")
+		return
+	}
+	dispute, err := h.disputes.Get(r.Context(), id)
+	if err != nil {
+		h.disputeError(w, err)
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, dispute)
+}
+
+// AddDisputeNote attaches evidence to an open dispute
+// @Summary Add Dispute Evidence
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ServerToken
+// @Param id path int true "Dispute ID"
+// @Param body body models.DisputeNoteRequest true "Note and optional link to evidence (demo, screenshot)"
+// @Success 201 {object} models.DisputeNote
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /admin/disputes/{id}/notes [post]
+func (h *Handler) AddDisputeNote(w http.ResponseWriter, r *http.Request) {
+	if h.disputes == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Match disputes not enabled")
+		return
+	}
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.errorResponse(w, http.StatusNotFound, "Dispute not found")
+		return
+	}
+	var req models.DisputeNoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if strings.TrimSpace(req.Note) == "" {
+		h.errorResponse(w, http.StatusBadRequest, "A note is required")
+		return
+	}
+
+	actor, _ := r.Context().Value("server_id").(string)
+	note := &models.DisputeNote{Note: req.Note, EvidenceURL: req.EvidenceURL, AddedBy: actor}
+	if err := h.disputes.AddNote(r.Context(), id, note); err != nil {
+		h.disputeError(w, err)
+		return
+	}
+	h.jsonResponse(w, http.StatusCreated, note)
+}
+
+// RejectDispute closes a dispute without changing the match's result
+// @Summary Reject Dispute
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ServerToken
+// @Param id path int true "Dispute ID"
+// @Param body body models.DisputeRejectRequest true "Resolution"
+// @Success 200 {object} models.MatchDispute
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /admin/disputes/{id}/reject [post]
+func (h *Handler) RejectDispute(w http.ResponseWriter, r *http.Request) {
+	if h.disputes == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Match disputes not enabled")
+		return
+	}
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.errorResponse(w, http.StatusNotFound, "Dispute not found")
+		return
+	}
+	var req models.DisputeRejectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	actor, _ := r.Context().Value("server_id").(string)
+	dispute, err := h.disputes.Reject(r.Context(), id, req.Resolution, actor)
+	if err != nil {
+		h.disputeError(w, err)
+		return
+	}
+	h.logger.Infow("Match dispute rejected", "dispute", id, "match", dispute.MatchID, "by", actor)
+	h.jsonResponse(w, http.StatusOK, dispute)
+}
+
+// CorrectMatch applies a manual score to a tournament match
+// @Summary Correct Match Result
+// @Description Stores a score and winner that supersede the ones derived from the match's events, finalized or not. The match's win/loss outcomes are derived again with the corrected winner (recomputes keep it), its signed result is signed again and, with dispute_id, that open dispute is closed as corrected. With rebuild=true the days the match was played on are rebuilt in the background so player aggregates count the new outcomes (see rebuild_job).
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ServerToken
+// @Param matchId path string true "Match ID"
+// @Param rebuild query bool false "Rebuild player aggregates"
+// @Param body body models.MatchCorrectionRequest true "Corrected score"
+// @Success 200 {object} models.MatchCorrectionResult
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /admin/matches/{matchId}/correction [post]
+func (h *Handler) CorrectMatch(w http.ResponseWriter, r *http.Request) {
+	if h.disputes == nil || h.matchStates == nil || h.matchAdmin == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Match disputes not enabled")
+		return
+	}
+	var req models.MatchCorrectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	switch {
+	case req.Winner != models.CorrectionWinnerAllies && req.Winner != models.CorrectionWinnerAxis && req.Winner != models.CorrectionWinnerDraw:
+		h.errorResponse(w, http.StatusBadRequest, "winner must be allies, axis or draw")
+		return
+	case req.AlliesScore < 0 || req.AxisScore < 0:
+		h.errorResponse(w, http.StatusBadRequest, "Scores cannot be negative")
+		return
+	case strings.TrimSpace(req.Reason) == "":
+		h.errorResponse(w, http.StatusBadRequest, "A reason is required")
+		return
+	}
+
+	ctx := r.Context()
+	record, err := h.matchStateRecord(ctx, chi.URLParam(r, "matchId"))
+	if err != nil {
+		h.disputeError(w, err)
+		return
+	}
+	actor, _ := ctx.Value("server_id").(string)
+	correction, err := h.disputes.Correct(ctx, record, &req, actor)
+	if err != nil {
+		h.disputeError(w, err)
+		return
+	}
+	h.logger.Infow("Match result corrected", "match", record.MatchID, "tournament", record.TournamentID,
+		"winner", correction.Winner, "allies", correction.AlliesScore, "axis", correction.AxisScore, "by", actor)
+	result := &models.MatchCorrectionResult{Correction: correction}
+
+	recompute, err := h.matchAdmin.ReplaceOutcomes(ctx, record.MatchID)
+	if err != nil {
+		h.logger.Errorw("Failed to replace outcomes after correction", "match", record.MatchID, "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Correction saved, outcomes not replaced: recompute the match")
+		return
+	}
+	result.Outcomes = len(recompute.Outcomes)
+
+	if h.matchResults != nil && record.Locked() {
+		if _, err := h.matchResults.Sign(ctx, record); err != nil {
+			h.logger.Errorw("Failed to sign corrected match result", "match", record.MatchID, "error", err)
+		} else {
+			result.Resigned = true
+		}
+	}
+
+	if r.URL.Query().Get("rebuild") == "true" && h.rebuilds != nil {
+		result.RebuildJob, err = h.rebuilds.Submit(models.AggregateRebuildRequest{
+			MatchIDs: []string{record.MatchID},
+			Reason:   "correction: " + req.Reason,
+		})
+		if err != nil {
+			h.logger.Warnw("Failed to start aggregate rebuild after correction", "match", record.MatchID, "error", err)
+			h.errorResponse(w, http.StatusConflict, "Match corrected, aggregate rebuild not started: "+err.Error())
+			return
+		}
+	}
+	h.jsonResponse(w, http.StatusOK, result)
+}
+
+// disputeError maps dispute and match state errors to responses
+func (h *Handler) disputeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, logic.ErrDisputeNotFound):
+		h.errorResponse(w, http.StatusNotFound, "Dispute not found")
+	case errors.Is(err, logic.ErrDisputeOpen), errors.Is(err, logic.ErrDisputeClosed):
+		h.errorResponse(w, http.StatusConflict, err.Error())
+	case errors.Is(err, logic.ErrUnknownMatch):
+		h.errorResponse(w, http.StatusNotFound, "Match not found")
+	case errors.Is(err, logic.ErrMatchState):
+		h.errorResponse(w, http.StatusConflict, err.Error())
+	default:
+		h.logger.Errorw("Failed to handle match dispute", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to handle match dispute")
+	}
+}
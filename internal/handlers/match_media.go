@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// SubmitMatchMedia queues a screenshot or clip for a match
+// @Summary Attach Media To Match
+// @Description Queues a screenshot or clip URL for moderation; it shows on the match once an admin approves it. A user can have 10 attachments awaiting moderation.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param body body models.MatchMediaRequest true "Attachment"
+// @Success 201 {object} models.MatchMedia
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Router /users/me/media [post]
+func (h *Handler) SubmitMatchMedia(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	forumUserID, ok := ctx.Value("forum_user_id").(int)
+	if !ok || forumUserID == 0 {
+		h.errorResponse(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+	if h.media == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Match media not enabled")
+		return
+	}
+
+	var req models.MatchMediaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	media, err := h.media.Submit(ctx, forumUserID, &req)
+	if err != nil {
+		h.mediaError(w, err)
+		return
+	}
+	h.logger.Infow("Match media submitted", "id", media.ID, "match", media.MatchID, "user", forumUserID)
+	h.jsonResponse(w, http.StatusCreated, media)
+}
+
+// GetUserMedia returns the signed-in user's attachments and their moderation state
+// @Summary Get My Match Media
+// @Tags Auth
+// @Produce json
+// @Success 200 {array} models.MatchMedia
+// @Failure 401 {object} map[string]string
+// @Router /users/me/media [get]
+func (h *Handler) GetUserMedia(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	forumUserID, ok := ctx.Value("forum_user_id").(int)
+	if !ok || forumUserID == 0 {
+		h.errorResponse(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+	if h.media == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Match media not enabled")
+		return
+	}
+
+	media, err := h.media.ForUser(ctx, forumUserID)
+	if err != nil {
+		h.mediaError(w, err)
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, media)
+}
+
+// GetMatchMedia returns the approved screenshots and clips of a match
+// @Summary Get Match Media
+// @Tags Match
+// @Produce json
+// @Param matchId path string true "Match ID"
+// @Success 200 {array} models.MatchMedia
+// @Router /stats/match/{matchId}/media [get]
+func (h *Handler) GetMatchMedia(w http.ResponseWriter, r *http.Request) {
+	if h.media == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Match media not enabled")
+		return
+	}
+	media, err := h.media.ForMatch(r.Context(), chi.URLParam(r, "matchId"))
+	if err != nil {
+		h.mediaError(w, err)
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, media)
+}
+
+// ListMediaQueue returns the attachments awaiting moderation
+// @Summary List Media Queue
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Param status query string false "pending, approved or rejected" default(pending)
+// @Param limit query int false "Max attachments" default(50)
+// @Success 200 {array} models.MatchMedia
+// @Failure 400 {object} map[string]string
+// @Router /admin/media [get]
+func (h *Handler) ListMediaQueue(w http.ResponseWriter, r *http.Request) {
+	if h.media == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Match media not enabled")
+		return
+	}
+	status := models.MediaStatus(r.URL.Query().Get("status"))
+	switch status {
+	case "":
+		status = models.MediaPending
+	case models.MediaPending, models.MediaApproved, models.MediaRejected:
+	default:
+		h.errorResponse(w, http.StatusBadRequest, "status must be pending, approved or rejected")
+		return
+	}
+	limit := 50
+	if v, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && v > 0 && v <= 200 {
+		limit = v
+	}
+
+	media, err := h.media.Queue(r.Context(), status, limit)
+	if err != nil {
+		h.mediaError(w, err)
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, media)
+}
+
+// ApproveMedia shows an attachment on its match
+// @Summary Approve Match Media
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Param id path int true "Attachment ID"
+// @Success 200 {object} models.MatchMedia
+// @Failure 404 {object} map[string]string
+// @Router /admin/media/{id}/approve [post]
+func (h *Handler) ApproveMedia(w http.ResponseWriter, r *http.Request) {
+	h.reviewMedia(w, r, true)
+}
+
+// RejectMedia keeps an attachment off its match, or takes an approved one down
+// @Summary Reject Match Media
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ServerToken
+// @Param id path int true "Attachment ID"
+// @Param body body models.MediaReviewRequest false "Why it was rejected"
+// @Success 200 {object} models.MatchMedia
+// @Failure 404 {object} map[string]string
+// @Router /admin/media/{id}/reject [post]
+func (h *Handler) RejectMedia(w http.ResponseWriter, r *http.Request) {
+	h.reviewMedia(w, r, false)
+}
+
+func (h *Handler) reviewMedia(w http.ResponseWriter, r *http.Request, approve bool) {
+	if h.media == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Match media not enabled")
+		return
+	}
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.errorResponse(w, http.StatusNotFound, "Match media not found")
+		return
+	}
+	// The body is optional
+	var req models.MediaReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	actor, _ := r.Context().Value("server_id").(string)
+	media, err := h.media.Review(r.Context(), id, approve, req.Note, actor)
+	if err != nil {
+		h.mediaError(w, err)
+		return
+	}
+	h.logger.Infow("Match media reviewed", "id", id, "match", media.MatchID, "status", media.Status, "by", actor)
+	h.jsonResponse(w, http.StatusOK, media)
+}
+
+func (h *Handler) mediaError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, logic.ErrInvalidMedia):
+		h.errorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, logic.ErrMediaNotFound):
+		h.errorResponse(w, http.StatusNotFound, "Match media not found")
+	case errors.Is(err, logic.ErrMatchNotFound):
+		h.errorResponse(w, http.StatusNotFound, "Match not found")
+	case errors.Is(err, logic.ErrMediaExists):
+		h.errorResponse(w, http.StatusConflict, err.Error())
+	case errors.Is(err, logic.ErrMediaQueueFull):
+		h.errorResponse(w, http.StatusTooManyRequests, err.Error())
+	default:
+		h.logger.Errorw("Failed to handle match media", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to handle match media")
+	}
+}
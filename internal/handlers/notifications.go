@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// GetNotifications returns a page of the signed-in user's inbox
+// @Summary Get My Notifications
+// @Description Achievement unlocks, broken server records, rivalries and weekly challenge completions of the user's verified players, newest first. unread counts the whole inbox for the bell badge; pass cursor as before for the next page.
+// @Tags Auth
+// @Produce json
+// @Param unread query bool false "Only unread notifications"
+// @Param before query int false "Notification ID to page below"
+// @Param limit query int false "Max notifications" default(20)
+// @Success 200 {object} models.NotificationList
+// @Failure 401 {object} map[string]string
+// @Router /users/me/notifications [get]
+func (h *Handler) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	forumUserID, ok := ctx.Value("forum_user_id").(int)
+	if !ok || forumUserID == 0 {
+		h.errorResponse(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+	if h.notifications == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Notifications not enabled")
+		return
+	}
+
+	q := r.URL.Query()
+	unread, _ := strconv.ParseBool(q.Get("unread"))
+	before, _ := strconv.ParseInt(q.Get("before"), 10, 64)
+	limit := 20
+	if v, err := strconv.Atoi(q.Get("limit")); err == nil && v > 0 && v <= 100 {
+		limit = v
+	}
+
+	list, err := h.notifications.List(ctx, forumUserID, unread, before, limit)
+	if err != nil {
+		h.notificationError(w, err)
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, list)
+}
+
+// GetUnreadNotifications returns how many notifications the signed-in user has not read
+// @Summary Get My Unread Notification Count
+// @Description The number on the bell icon, without loading the inbox.
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} map[string]int
+// @Failure 401 {object} map[string]string
+// @Router /users/me/notifications/unread [get]
+func (h *Handler) GetUnreadNotifications(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	forumUserID, ok := ctx.Value("forum_user_id").(int)
+	if !ok || forumUserID == 0 {
+		h.errorResponse(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+	if h.notifications == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Notifications not enabled")
+		return
+	}
+
+	unread, err := h.notifications.Unread(ctx, forumUserID)
+	if err != nil {
+		h.notificationError(w, err)
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, map[string]int{"unread": unread})
+}
+
+// MarkNotificationsRead marks some or all of the signed-in user's notifications read
+// @Summary Mark My Notifications Read
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param body body models.NotificationReadRequest true "IDs, or all"
+// @Success 200 {object} map[string]int
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /users/me/notifications/read [post]
+func (h *Handler) MarkNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	forumUserID, ok := ctx.Value("forum_user_id").(int)
+	if !ok || forumUserID == 0 {
+		h.errorResponse(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+	if h.notifications == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Notifications not enabled")
+		return
+	}
+
+	var req models.NotificationReadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	marked, err := h.notifications.MarkRead(ctx, forumUserID, &req)
+	if err != nil {
+		h.notificationError(w, err)
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, map[string]int{"marked": marked})
+}
+
+func (h *Handler) notificationError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, logic.ErrInvalidNotification):
+		h.errorResponse(w, http.StatusBadRequest, err.Error())
+	default:
+		h.logger.Errorw("Failed to handle notifications", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to handle notifications")
+	}
+}
@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/logic"
+)
+
+// parsePeriod reads the period, tz, from and to query parameters (see
+// logic.ParsePeriod), answering 400 when they cannot be read
+func (h *Handler) parsePeriod(w http.ResponseWriter, r *http.Request) (logic.Period, bool) {
+	q := r.URL.Query()
+	p, err := logic.ParsePeriod(q.Get("period"), q.Get("tz"), q.Get("from"), q.Get("to"), time.Now())
+	if err != nil {
+		h.errorResponse(w, http.StatusBadRequest, err.Error())
+		return p, false
+	}
+	return p, true
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -23,11 +24,15 @@ func (h *Handler) GetPlayerPredictions(w http.ResponseWriter, r *http.Request) {
 	}
 
 	pred, err := h.prediction.GetPlayerPredictions(r.Context(), guid)
-	if err != nil {
+	if err != nil && pred == nil {
 		h.logger.Errorw("Failed to get player predictions", "error", err, "guid", guid)
 		h.errorResponse(w, http.StatusInternalServerError, "Failed to get predictions")
 		return
 	}
+	if err != nil {
+		// Served all the same; only its accuracy goes untracked
+		h.logger.Warnw("Failed to record player predictions", "error", err, "guid", guid)
+	}
 
 	h.jsonResponse(w, http.StatusOK, pred)
 }
@@ -56,3 +61,28 @@ func (h *Handler) GetMatchPredictions(w http.ResponseWriter, r *http.Request) {
 
 	h.jsonResponse(w, http.StatusOK, pred)
 }
+
+// GetPredictionAccuracy scores the player forecasts against the matches played
+// @Summary Get Prediction Accuracy
+// @Description Every served player forecast is stored with the forecasts of the shadow models (PREDICTION_SHADOW_MODELS) and scored against the player's next finished match. Per model: mean absolute error of kills and deaths, kills bias, hit rate (kills within 25% or 2), mean confidence, Brier score of the confidence, and calibration by confidence decile.
+// @Tags AI
+// @Produce json
+// @Param days query int false "Forecasts of the last N days" default(30)
+// @Success 200 {object} models.PredictionAccuracy
+// @Failure 500 {object} map[string]string
+// @Router /stats/predict/accuracy [get]
+func (h *Handler) GetPredictionAccuracy(w http.ResponseWriter, r *http.Request) {
+	days := 30
+	if d, err := strconv.Atoi(r.URL.Query().Get("days")); err == nil && d > 0 && d <= 365 {
+		days = d
+	}
+
+	acc, err := h.prediction.GetPredictionAccuracy(r.Context(), days)
+	if err != nil {
+		h.logger.Errorw("Failed to get prediction accuracy", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to get prediction accuracy")
+		return
+	}
+
+	h.jsonResponse(w, http.StatusOK, acc)
+}
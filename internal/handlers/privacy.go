@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// errPlayerHidden is returned for a player who hid their profile
+var errPlayerHidden = errors.New("player profile is hidden")
+
+// playerPrivacyKey carries the preferences PlayerVisibilityMiddleware read
+type playerPrivacyKey struct{}
+
+type guidPrivacy struct {
+	guid    string
+	privacy models.PlayerPrivacy
+}
+
+// GetUserPrivacy returns the signed-in user's privacy preferences
+// @Summary Get My Privacy Settings
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} models.PlayerPrivacy
+// @Failure 401 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /users/me/privacy [get]
+func (h *Handler) GetUserPrivacy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	forumUserID, ok := ctx.Value("forum_user_id").(int)
+	if !ok || forumUserID == 0 {
+		h.errorResponse(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+	if h.privacy == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Privacy settings not enabled")
+		return
+	}
+
+	privacy, err := h.privacy.Get(ctx, forumUserID)
+	if err != nil {
+		h.logger.Errorw("Failed to get privacy settings", "user", forumUserID, "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to get privacy settings")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, privacy)
+}
+
+// SetUserPrivacy replaces the signed-in user's privacy preferences
+// @Summary Set My Privacy Settings
+// @Description Applies to every GUID verified for the account: hide_profile answers its profile routes with 404, hide_from_leaderboards leaves it out of ranked lists and anonymize_name shows "Anonymous Soldier" instead of its name.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param body body models.PlayerPrivacy true "Preferences; updated_at is ignored"
+// @Success 200 {object} models.PlayerPrivacy
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /users/me/privacy [put]
+func (h *Handler) SetUserPrivacy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	forumUserID, ok := ctx.Value("forum_user_id").(int)
+	if !ok || forumUserID == 0 {
+		h.errorResponse(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+	if h.privacy == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Privacy settings not enabled")
+		return
+	}
+
+	var privacy models.PlayerPrivacy
+	if err := json.NewDecoder(r.Body).Decode(&privacy); err != nil {
+		h.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if err := h.privacy.Set(ctx, forumUserID, &privacy); err != nil {
+		h.logger.Errorw("Failed to save privacy settings", "user", forumUserID, "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to save privacy settings")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, privacy)
+}
+
+// PlayerVisibilityMiddleware answers the routes of a {guid} whose player hid
+// their profile with 404. Privacy that cannot be read fails the request
+// rather than show a hidden profile.
+func (h *Handler) PlayerVisibilityMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		guid := chi.URLParam(r, "guid")
+		privacy, err := h.playerPrivacy(r.Context(), guid)
+		if err != nil {
+			h.logger.Errorw("Failed to read player privacy", "guid", guid, "error", err)
+			h.errorResponse(w, http.StatusInternalServerError, "Failed to get player stats")
+			return
+		}
+		if privacy.HideProfile {
+			h.errorResponse(w, http.StatusNotFound, "Player not found")
+			return
+		}
+		ctx := context.WithValue(r.Context(), playerPrivacyKey{}, guidPrivacy{guid: guid, privacy: privacy})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// playerPrivacy returns the privacy preferences that apply to a GUID, the
+// ones PlayerVisibilityMiddleware read when it ran for it
+func (h *Handler) playerPrivacy(ctx context.Context, guid string) (models.PlayerPrivacy, error) {
+	if read, ok := ctx.Value(playerPrivacyKey{}).(guidPrivacy); ok && read.guid == guid {
+		return read.privacy, nil
+	}
+	visibility, err := h.playerVisibility(ctx, []string{guid})
+	if err != nil {
+		return models.PlayerPrivacy{}, err
+	}
+	return visibility[guid], nil
+}
+
+// playerVisibility returns the privacy preferences of the GUIDs that hide or
+// anonymize something; the rest are left out. Without the privacy service
+// every player is visible.
+func (h *Handler) playerVisibility(ctx context.Context, guids []string) (map[string]models.PlayerPrivacy, error) {
+	if h.privacy == nil || len(guids) == 0 {
+		return nil, nil
+	}
+	return h.privacy.Visibility(ctx, guids)
+}
+
+// profileName returns the name a profile shows for a GUID. When privacy
+// cannot be read the name is anonymized rather than risk showing it.
+func (h *Handler) profileName(ctx context.Context, guid, name string) string {
+	privacy, err := h.playerPrivacy(ctx, guid)
+	if err != nil {
+		h.logger.Warnw("Failed to read player privacy", "guid", guid, "error", err)
+		return models.AnonymousName
+	}
+	if privacy.AnonymizeName {
+		return models.AnonymousName
+	}
+	return name
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// mockPrivacy serves fixed preferences per GUID
+type mockPrivacy struct {
+	logic.PrivacyService
+	byGUID map[string]models.PlayerPrivacy
+	err    error
+}
+
+func (m *mockPrivacy) Visibility(ctx context.Context, guids []string) (map[string]models.PlayerPrivacy, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	visibility := make(map[string]models.PlayerPrivacy)
+	for _, guid := range guids {
+		if p, ok := m.byGUID[guid]; ok {
+			visibility[guid] = p
+		}
+	}
+	return visibility, nil
+}
+
+func TestApplyDisplayNamesPrivacy(t *testing.T) {
+	h := &Handler{logger: zap.NewNop().Sugar(), privacy: &mockPrivacy{byGUID: map[string]models.PlayerPrivacy{
+		"hidden": {HideFromLeaderboards: true},
+		"anon":   {AnonymizeName: true},
+	}}}
+
+	entries := []models.LeaderboardEntry{
+		{Rank: 1, PlayerID: "hidden", PlayerName: "Ghost"},
+		{Rank: 2, PlayerID: "anon", PlayerName: "Sarge"},
+		{Rank: 3, PlayerID: "open", PlayerName: "Medic"},
+	}
+	entries = applyDisplayNames(context.Background(), h, entries, leaderboardEntryName)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want the hidden player dropped: %+v", len(entries), entries)
+	}
+	if entries[0].PlayerName != models.AnonymousName || entries[0].Rank != 2 {
+		t.Errorf("anonymized entry %+v", entries[0])
+	}
+	if entries[1].PlayerName != "Medic" || entries[1].Rank != 3 {
+		t.Errorf("visible entry %+v", entries[1])
+	}
+
+	// Lists are served without privacy when it cannot be read
+	h.privacy = &mockPrivacy{err: errors.New("postgres down")}
+	entries = applyDisplayNames(context.Background(), h, []models.LeaderboardEntry{{PlayerID: "hidden", PlayerName: "Ghost"}}, leaderboardEntryName)
+	if len(entries) != 1 {
+		t.Errorf("got %d entries on error, want 1", len(entries))
+	}
+}
+
+func TestPlayerVisibilityMiddleware(t *testing.T) {
+	privacy := &mockPrivacy{byGUID: map[string]models.PlayerPrivacy{
+		"hidden": {HideProfile: true},
+		"anon":   {AnonymizeName: true},
+	}}
+	h := &Handler{logger: zap.NewNop().Sugar(), privacy: privacy}
+
+	r := chi.NewRouter()
+	r.With(h.PlayerVisibilityMiddleware).Get("/player/{guid}", func(w http.ResponseWriter, r *http.Request) {
+		guid := chi.URLParam(r, "guid")
+		w.Write([]byte(h.profileName(r.Context(), guid, "Sarge")))
+	})
+	serve := func(guid string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/player/"+guid, nil))
+		return w
+	}
+
+	if w := serve("hidden"); w.Code != http.StatusNotFound {
+		t.Errorf("hidden profile: status %d", w.Code)
+	}
+	if w := serve("anon"); w.Code != http.StatusOK || w.Body.String() != models.AnonymousName {
+		t.Errorf("anonymized profile: status %d, name %q", w.Code, w.Body.String())
+	}
+	if w := serve("open"); w.Code != http.StatusOK || w.Body.String() != "Sarge" {
+		t.Errorf("visible profile: status %d, name %q", w.Code, w.Body.String())
+	}
+
+	// Profiles fail closed
+	privacy.err = errors.New("postgres down")
+	if w := serve("open"); w.Code != http.StatusInternalServerError {
+		t.Errorf("privacy error: status %d", w.Code)
+	}
+}
+
+// mockHighlights serves fixed picks
+type mockHighlights struct {
+	logic.HighlightsService
+	picks []models.PlayerHighlight
+}
+
+func (m *mockHighlights) History(ctx context.Context, period models.HighlightPeriod, serverID string, between logic.Period, limit int) ([]models.PlayerHighlight, error) {
+	return m.picks, nil
+}
+
+func TestPlayerHighlightsPrivacy(t *testing.T) {
+	h := &Handler{
+		logger: zap.NewNop().Sugar(),
+		privacy: &mockPrivacy{byGUID: map[string]models.PlayerPrivacy{
+			"hidden": {HideFromLeaderboards: true},
+			"anon":   {AnonymizeName: true},
+		}},
+		highlights: &mockHighlights{picks: []models.PlayerHighlight{
+			{PlayerGUID: "hidden", PlayerName: "Ghost"},
+			{PlayerGUID: "anon", PlayerName: "Sarge"},
+		}},
+	}
+
+	rec := httptest.NewRecorder()
+	h.GetPlayerHighlights(rec, httptest.NewRequest(http.MethodGet, "/stats/highlights/potd", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var resp models.PlayerHighlightsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Highlights) != 1 || resp.Highlights[0].PlayerName != models.AnonymousName {
+		t.Errorf("highlights = %+v, want only the anonymized pick", resp.Highlights)
+	}
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rateLimitIdle is how long a client without requests keeps its bucket
+const rateLimitIdle = 10 * time.Minute
+
+// rateLimiter gives each client address a token bucket refilled at
+// perSecond up to burst. The limits change in place on a config reload.
+type rateLimiter struct {
+	mu        sync.Mutex
+	perSecond float64
+	burst     float64
+	clients   map[string]*rateBucket
+	lastSweep time.Time
+}
+
+type rateBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(perSecond, burst int) *rateLimiter {
+	l := &rateLimiter{clients: make(map[string]*rateBucket), lastSweep: time.Now()}
+	l.setLimits(perSecond, burst)
+	return l
+}
+
+// setLimits changes the rate and burst; a rate of 0 or less disables limiting
+func (l *rateLimiter) setLimits(perSecond, burst int) {
+	if burst < perSecond {
+		burst = perSecond
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.perSecond, l.burst = float64(perSecond), float64(burst)
+	if perSecond <= 0 {
+		clear(l.clients)
+		return
+	}
+	// Buckets holding more than the new burst are cut down to it
+	for _, b := range l.clients {
+		b.tokens = math.Min(b.tokens, l.burst)
+	}
+}
+
+// allow takes a token from the client's bucket. When it is empty, it returns
+// false and how long until the next token.
+func (l *rateLimiter) allow(client string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.perSecond <= 0 {
+		return true, 0
+	}
+	if now.Sub(l.lastSweep) >= rateLimitIdle {
+		l.lastSweep = now
+		for key, b := range l.clients {
+			if now.Sub(b.last) > rateLimitIdle {
+				delete(l.clients, key)
+			}
+		}
+	}
+
+	b, ok := l.clients[client]
+	if !ok {
+		b = &rateBucket{tokens: l.burst, last: now}
+		l.clients[client] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.perSecond)
+	b.last = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.perSecond * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// SetRateLimit changes the per-client request rate and burst of
+// RateLimitMiddleware; a rate of 0 or less disables limiting
+func (h *Handler) SetRateLimit(perSecond, burst int) {
+	h.rateLimit.setLimits(perSecond, burst)
+}
+
+// RateLimitMiddleware answers 429 to clients over RATE_LIMIT_PER_SECOND
+// (with bursts of RATE_LIMIT_BURST), by client address. Run it after
+// middleware.RealIP so proxied clients are told apart.
+func (h *Handler) RateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client := r.RemoteAddr
+		if host, _, err := net.SplitHostPort(client); err == nil {
+			client = host
+		}
+		if ok, wait := h.rateLimit.allow(client, time.Now()); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			h.errorResponse(w, http.StatusTooManyRequests, "Rate limit exceeded")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestRateLimiter(t *testing.T) {
+	l := newRateLimiter(2, 3)
+	now := time.Unix(1700000000, 0)
+
+	// The burst goes through at once, then the client waits for tokens
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.allow("10.0.0.1", now); !ok {
+			t.Fatalf("request %d of the burst refused", i+1)
+		}
+	}
+	ok, wait := l.allow("10.0.0.1", now)
+	if ok || wait != 500*time.Millisecond {
+		t.Fatalf("over the burst: ok = %v, wait = %v, want refused for 500ms", ok, wait)
+	}
+	if ok, _ := l.allow("10.0.0.2", now); !ok {
+		t.Error("another client was limited")
+	}
+	if ok, _ := l.allow("10.0.0.1", now.Add(500*time.Millisecond)); !ok {
+		t.Error("refilled token refused")
+	}
+
+	// A reload applies to existing clients; 0 lifts the limit
+	l.setLimits(1, 1)
+	if ok, _ := l.allow("10.0.0.2", now); !ok {
+		t.Error("bucket cut to the new burst refused its token")
+	}
+	if ok, _ := l.allow("10.0.0.2", now); ok {
+		t.Error("new burst not applied")
+	}
+	l.setLimits(0, 0)
+	for i := 0; i < 10; i++ {
+		if ok, _ := l.allow("10.0.0.1", now); !ok {
+			t.Fatal("refused with limiting disabled")
+		}
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	h := &Handler{logger: zap.NewNop().Sugar(), rateLimit: newRateLimiter(1, 1)}
+	handler := h.RateLimitMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	codes := []int{}
+	for _, addr := range []string{"10.0.0.1:1000", "10.0.0.1:2000", "10.0.0.2:1000"} {
+		req := httptest.NewRequest("GET", "/api/v1/stats/global", nil)
+		req.RemoteAddr = addr
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		codes = append(codes, w.Code)
+		if w.Code == http.StatusTooManyRequests && w.Header().Get("Retry-After") != "1" {
+			t.Errorf("Retry-After = %q, want 1", w.Header().Get("Retry-After"))
+		}
+	}
+	// Ports do not make another client
+	want := []int{http.StatusOK, http.StatusTooManyRequests, http.StatusOK}
+	for i := range want {
+		if codes[i] != want[i] {
+			t.Errorf("status codes = %v, want %v", codes, want)
+			break
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// GetAdjustedKDLeaderboard ranks players by opponent-adjusted K/D
+// @Summary Adjusted K/D Leaderboard
+// @Description Players ranked by K/D with each kill weighted by the victim's rating and each death by the inverse of the killer's, as they stood at the time. Only player kills between two players count (no bots, suicides or team kills), and players need 100 of them, kills and deaths together, to be ranked.
+// @Tags Stats
+// @Produce json
+// @Param limit query int false "Players to return (max 100)" default(25)
+// @Success 200 {array} models.AdjustedKDEntry
+// @Failure 503 {object} map[string]string
+// @Router /stats/leaderboard/adjusted-kd [get]
+func (h *Handler) GetAdjustedKDLeaderboard(w http.ResponseWriter, r *http.Request) {
+	if h.ratings == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Ratings not enabled")
+		return
+	}
+	limit := 25
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+
+	entries, err := h.ratings.AdjustedKDLeaderboard(r.Context(), limit)
+	if err != nil {
+		h.logger.Errorw("Failed to get adjusted K/D leaderboard", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to get leaderboard")
+		return
+	}
+	entries = applyDisplayNames(r.Context(), h, entries, func(e *models.AdjustedKDEntry) (string, *string, *bool) {
+		return e.PlayerID, &e.PlayerName, &e.NameFlagged
+	})
+	h.jsonResponse(w, http.StatusOK, entries)
+}
+
+// playerRating returns a player's rating for their profile, or nil
+func (h *Handler) playerRating(ctx context.Context, guid string) *models.PlayerRating {
+	if h.ratings == nil {
+		return nil
+	}
+	rating, err := h.ratings.Get(ctx, guid)
+	if err != nil {
+		h.logger.Warnw("Failed to read player rating", "guid", guid, "error", err)
+		return nil
+	}
+	return rating
+}
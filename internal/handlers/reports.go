@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// SubmitReport files an abuse report against a player
+// @Summary Report Player
+// @Description Reports a player for cheating, an abusive name or chat, or griefing. With match_id the player must have played in the match. A user can have 20 reports waiting for triage and one per player and reason.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param body body models.PlayerReportRequest true "Report"
+// @Success 201 {object} models.PlayerReport
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Router /reports [post]
+func (h *Handler) SubmitReport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	forumUserID, ok := ctx.Value("forum_user_id").(int)
+	if !ok || forumUserID == 0 {
+		h.errorResponse(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+	if h.reports == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Reports not enabled")
+		return
+	}
+
+	var req models.PlayerReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	report, err := h.reports.Submit(ctx, forumUserID, &req)
+	if err != nil {
+		h.reportError(w, err)
+		return
+	}
+	h.logger.Infow("Player reported", "id", report.ID, "player", report.PlayerGUID, "reason", report.Reason, "user", forumUserID)
+	h.jsonResponse(w, http.StatusCreated, report)
+}
+
+// GetUserReports returns the reports the signed-in user filed and their status
+// @Summary Get My Reports
+// @Tags Auth
+// @Produce json
+// @Success 200 {array} models.PlayerReport
+// @Failure 401 {object} map[string]string
+// @Router /reports [get]
+func (h *Handler) GetUserReports(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	forumUserID, ok := ctx.Value("forum_user_id").(int)
+	if !ok || forumUserID == 0 {
+		h.errorResponse(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+	if h.reports == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Reports not enabled")
+		return
+	}
+
+	reports, err := h.reports.ForUser(ctx, forumUserID)
+	if err != nil {
+		h.reportError(w, err)
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, reports)
+}
+
+// ListReports returns the report queue
+// @Summary List Player Reports
+// @Description Reports by status, player or linked anticheat flag, each with the open report count of its player. Open reports come oldest first.
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Param status query string false "open, reviewing, actioned or dismissed; empty for all" default(open)
+// @Param player query string false "Reported player GUID"
+// @Param flag query string false "Linked anticheat flag ID"
+// @Param limit query int false "Max reports" default(50)
+// @Success 200 {array} models.PlayerReport
+// @Failure 400 {object} map[string]string
+// @Router /admin/reports [get]
+func (h *Handler) ListReports(w http.ResponseWriter, r *http.Request) {
+	if h.reports == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Reports not enabled")
+		return
+	}
+	q := r.URL.Query()
+	f := models.ReportFilter{
+		Status:        models.ReportOpen,
+		PlayerGUID:    q.Get("player"),
+		AnticheatFlag: q.Get("flag"),
+		Limit:         50,
+	}
+	if q.Has("status") {
+		f.Status = models.ReportStatus(q.Get("status"))
+	}
+	switch f.Status {
+	case "", models.ReportOpen, models.ReportReviewing, models.ReportActioned, models.ReportDismissed:
+	default:
+		h.errorResponse(w, http.StatusBadRequest, "status must be open, reviewing, actioned or dismissed")
+		return
+	}
+	if v, err := strconv.Atoi(q.Get("limit")); err == nil && v > 0 && v <= 200 {
+		f.Limit = v
+	}
+
+	reports, err := h.reports.Queue(r.Context(), f)
+	if err != nil {
+		h.reportError(w, err)
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, reports)
+}
+
+// GetReport returns a player report
+// @Summary Get Player Report
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Param id path int true "Report ID"
+// @Success 200 {object} models.PlayerReport
+// @Failure 404 {object} map[string]string
+// @Router /admin/reports/{id} [get]
+func (h *Handler) GetReport(w http.ResponseWriter, r *http.Request) {
+	if h.reports == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Reports not enabled")
+		return
+	}
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.errorResponse(w, http.StatusNotFound, "Player report not found")
+		return
+	}
+	report, err := h.reports.Get(r.Context(), id)
+	if err != nil {
+		h.reportError(w, err)
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, report)
+}
+
+// TriageReport moves a report through triage and links it to an anticheat flag
+// @Summary Triage Player Report
+// @Description Sets the status of a report (reviewing, or closed as actioned or dismissed; open to reopen), with a note, and links the anticheat flag behind it. Omit anticheat_flag to keep the linked flag; send "" to unlink it.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ServerToken
+// @Param id path int true "Report ID"
+// @Param body body models.ReportTriageRequest true "Triage"
+// @Success 200 {object} models.PlayerReport
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /admin/reports/{id}/triage [post]
+func (h *Handler) TriageReport(w http.ResponseWriter, r *http.Request) {
+	if h.reports == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Reports not enabled")
+		return
+	}
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		h.errorResponse(w, http.StatusNotFound, "Player report not found")
+		return
+	}
+	var req models.ReportTriageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	actor, _ := r.Context().Value("server_id").(string)
+	report, err := h.reports.Triage(r.Context(), id, &req, actor)
+	if err != nil {
+		h.reportError(w, err)
+		return
+	}
+	h.logger.Infow("Player report triaged", "id", id, "player", report.PlayerGUID, "status", report.Status,
+		"flag", report.AnticheatFlag, "by", actor)
+	h.jsonResponse(w, http.StatusOK, report)
+}
+
+func (h *Handler) reportError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, logic.ErrInvalidReport):
+		h.errorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, logic.ErrReportNotFound):
+		h.errorResponse(w, http.StatusNotFound, "Player report not found")
+	case errors.Is(err, logic.ErrMatchNotFound):
+		h.errorResponse(w, http.StatusNotFound, "Match not found")
+	case errors.Is(err, logic.ErrReportExists):
+		h.errorResponse(w, http.StatusConflict, err.Error())
+	case errors.Is(err, logic.ErrTooManyReports):
+		h.errorResponse(w, http.StatusTooManyRequests, err.Error())
+	default:
+		h.logger.Errorw("Failed to handle player report", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to handle player report")
+	}
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// MergeServers moves a server's history to the server that replaced it
+// @Summary Merge Server History
+// @Description When a community moves a server to a new box or ID its stats fragment. This re-attributes the old server's events, per-server aggregates, pings, performance samples, match states, milestones and highlights to the new server, and records the merge. With dry_run only the rows that would move are counted. Stop the old server first: events still in insert buffers are not moved.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ServerToken
+// @Param body body models.ServerMergeRequest true "Old and new server IDs"
+// @Success 200 {object} models.ServerMerge
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /admin/servers/merge [post]
+func (h *Handler) MergeServers(w http.ResponseWriter, r *http.Request) {
+	if h.serverMerge == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Server merge not enabled")
+		return
+	}
+	var req models.ServerMergeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	var merge *models.ServerMerge
+	var err error
+	if req.DryRun {
+		merge, err = h.serverMerge.Plan(r.Context(), req)
+	} else {
+		requestedBy, _ := r.Context().Value("server_id").(string)
+		merge, err = h.serverMerge.Merge(r.Context(), req, requestedBy)
+	}
+	switch {
+	case errors.Is(err, logic.ErrInvalidServerMerge):
+		h.errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, logic.ErrUnknownServer):
+		h.errorResponse(w, http.StatusNotFound, "Server not found")
+		return
+	case err != nil:
+		h.logger.Errorw("Failed to merge servers", "from", req.FromServerID, "to", req.ToServerID, "dryRun", req.DryRun, "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Server merge failed")
+		return
+	}
+
+	if !merge.DryRun {
+		h.logger.Infow("Server history merged", "id", merge.ID, "from", merge.FromServerID, "to", merge.ToServerID,
+			"rows", merge.Rows, "by", merge.RequestedBy, "reason", merge.Reason)
+	}
+	h.jsonResponse(w, http.StatusOK, merge)
+}
+
+// ListServerMerges returns the audit trail of server merges
+// @Summary Server Merge History
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Param limit query int false "Merges to return, newest first" default(50)
+// @Success 200 {array} models.ServerMerge
+// @Failure 503 {object} map[string]string
+// @Router /admin/servers/merges [get]
+func (h *Handler) ListServerMerges(w http.ResponseWriter, r *http.Request) {
+	if h.serverMerge == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Server merge not enabled")
+		return
+	}
+	limit := 50
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 500 {
+		limit = l
+	}
+	merges, err := h.serverMerge.History(r.Context(), limit)
+	if err != nil {
+		h.logger.Errorw("Failed to list server merges", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to list server merges")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, merges)
+}
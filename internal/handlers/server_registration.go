@@ -2,21 +2,30 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/openmohaa/stats-api/internal/logic"
 	"github.com/openmohaa/stats-api/internal/models"
 )
 
 // RegisterServer handles new server registration
 // @Summary Register Server
-// @Description Registers a new game server
+// @Description Registers a new game server. With an invite_code the server joins the invite's community as pending; without one it is pending too, unless the request carries an admin server's token (X-Server-Token). A pending server's token is returned right away but only authenticates once an admin approves the server (poll /servers/register/status). Registering an address that is already taken needs that server's current token, and replaces it.
 // @Tags Server
 // @Accept json
 // @Produce json
 // @Param body body models.RegisterServerRequest true "Server Info"
 // @Success 200 {object} models.RegisterServerResponse "Server Credentials"
 // @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 401 {object} map[string]string "Invalid server token"
+// @Failure 403 {object} map[string]string "Invite not usable"
+// @Failure 409 {object} map[string]string "Address already registered"
 // @Failure 500 {object} map[string]string "Internal Error"
 // @Router /servers/register [post]
 func (h *Handler) RegisterServer(w http.ResponseWriter, r *http.Request) {
@@ -31,33 +40,294 @@ func (h *Handler) RegisterServer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.InviteCode != "" {
+		h.registerWithInvite(w, r, req)
+		return
+	}
+
+	// The token a request carries is either an admin's, which registers the
+	// server approved, or the current one of the server at the address
+	callerHash, admin, callerTenant, err := h.registrationCaller(r)
+	if errors.Is(err, logic.ErrUnknownRegistration) {
+		h.errorResponse(w, http.StatusUnauthorized, "Invalid server token")
+		return
+	}
+	if err != nil {
+		h.logger.Errorw("Failed to check registration token", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to register server")
+		return
+	}
+
 	// Generate ID and Token
 	serverID := uuid.New().String()
 	token := uuid.New().String()
 	tokenHash := hashToken(token) // Reuse existing hashToken function
 
-	// Store in Postgres
-	_, err := h.pg.Exec(r.Context(), `
-		INSERT INTO servers (id, name, ip_address, port, token, is_active, last_seen)
-		VALUES ($1, $2, $3, $4, $5, true, NOW())
+	// Registering with a tenant API key, or an admin token of a tenant,
+	// assigns the server to that tenant
+	var tenantID *string
+	if t := logic.TenantFromContext(r.Context()); t != "" {
+		tenantID = &t
+	} else if admin && callerTenant != "" {
+		tenantID = &callerTenant
+	}
+	status := models.ServerPending
+	if admin {
+		status = models.ServerApproved
+	}
+
+	// Store in Postgres. An address already registered keeps its token
+	// unless the caller holds it.
+	err = h.pg.QueryRow(r.Context(), `
+		INSERT INTO servers (id, name, ip_address, port, token, is_active, last_seen, tenant_id, approval_status)
+		VALUES ($1, $2, $3, $4, $5, $9, NOW(), $6, $7)
 		ON CONFLICT (ip_address, port) 
 		DO UPDATE SET 
 			name = EXCLUDED.name,
 			token = EXCLUDED.token,
 			is_active = true,
 			last_seen = NOW()
-		RETURNING id
-	`, serverID, req.Name, req.IPAddress, string(req.Port), tokenHash)
+		WHERE servers.token = $8
+			AND servers.approval_status = 'approved'
+		RETURNING id::text, approval_status
+	`, serverID, req.Name, req.IPAddress, string(req.Port), tokenHash, tenantID, status, callerHash, admin).Scan(&serverID, &status)
 
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.errorResponse(w, http.StatusConflict, "Address is already registered; re-register with the server's current token")
+		return
+	}
 	if err != nil {
 		h.logger.Errorw("Failed to register server", "error", err)
 		h.errorResponse(w, http.StatusInternalServerError, "Failed to register server")
 		return
 	}
+	if status == models.ServerPending {
+		h.logger.Infow("Server registered, pending approval", "server_id", serverID, "name", req.Name,
+			"address", req.IPAddress, "port", string(req.Port))
+	}
 
 	// Return credentials
 	h.jsonResponse(w, http.StatusOK, models.RegisterServerResponse{
 		ServerID: serverID,
 		Token:    token,
+		Status:   status,
 	})
 }
+
+// registrationCaller hashes the server token a registration carries, if any,
+// and reports whether it is an active admin server's, with that server's
+// tenant. A token of no server is logic.ErrUnknownRegistration.
+func (h *Handler) registrationCaller(r *http.Request) (string, bool, string, error) {
+	token := r.Header.Get("X-Server-Token")
+	if token == "" {
+		token = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+	if token == "" {
+		return "", false, "", nil
+	}
+	hash := hashToken(token)
+	var admin bool
+	var tenantID string
+	err := h.pg.QueryRow(r.Context(), `
+		SELECT admin AND is_active AND NOT sandbox, COALESCE(tenant_id::text, '')
+		FROM servers WHERE token = $1
+	`, hash).Scan(&admin, &tenantID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", false, "", logic.ErrUnknownRegistration
+	}
+	if err != nil {
+		return "", false, "", err
+	}
+	return hash, admin, tenantID, nil
+}
+
+// registerWithInvite stores a self-registered server as pending in the
+// invite's community
+func (h *Handler) registerWithInvite(w http.ResponseWriter, r *http.Request, req models.RegisterServerRequest) {
+	port, err := strconv.Atoi(strings.TrimSpace(req.Port.String()))
+	if err != nil || port < 1 || port > 65535 {
+		h.errorResponse(w, http.StatusBadRequest, "port must be 1-65535")
+		return
+	}
+	if len(req.Name) > 128 {
+		h.errorResponse(w, http.StatusBadRequest, "name must be at most 128 characters")
+		return
+	}
+
+	resp, err := h.registration.Register(r.Context(), req, port)
+	if err != nil {
+		h.registrationError(w, err)
+		return
+	}
+	h.logger.Infow("Server registered, pending approval", "server_id", resp.ServerID, "name", req.Name,
+		"address", req.IPAddress, "port", port)
+	h.jsonResponse(w, http.StatusOK, resp)
+}
+
+// GetRegistrationStatus reports whether the calling server has been approved
+// @Summary Registration Status
+// @Description Approval state of the server the token belongs to. Works while the server is pending or rejected, when the token does not authenticate anything else.
+// @Tags Server
+// @Produce json
+// @Param X-Server-Token header string true "Token returned by registration"
+// @Success 200 {object} models.ServerRegistration
+// @Failure 401 {object} map[string]string
+// @Router /servers/register/status [get]
+func (h *Handler) GetRegistrationStatus(w http.ResponseWriter, r *http.Request) {
+	token := r.Header.Get("X-Server-Token")
+	if token == "" {
+		token = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+	if token == "" {
+		h.errorResponse(w, http.StatusUnauthorized, "Missing server token")
+		return
+	}
+	reg, err := h.registration.Status(r.Context(), token)
+	if errors.Is(err, logic.ErrUnknownRegistration) {
+		h.errorResponse(w, http.StatusUnauthorized, "Invalid server token")
+		return
+	}
+	if err != nil {
+		h.registrationError(w, err)
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, reg)
+}
+
+// ListServerInvites returns the community's server invites
+// @Summary List Server Invites
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Success 200 {array} models.ServerInvite
+// @Router /admin/server-invites [get]
+func (h *Handler) ListServerInvites(w http.ResponseWriter, r *http.Request) {
+	invites, err := h.registration.ListInvites(r.Context())
+	if err != nil {
+		h.registrationError(w, err)
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, invites)
+}
+
+// CreateServerInvite issues an invite code new hosts register servers with
+// @Summary Create Server Invite
+// @Description Issues an invite for the caller's community. The code is only returned here; it is stored hashed.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ServerToken
+// @Param body body models.CreateServerInviteRequest false "Uses (default 1), expiry and note"
+// @Success 201 {object} models.ServerInvite
+// @Failure 400 {object} map[string]string
+// @Router /admin/server-invites [post]
+func (h *Handler) CreateServerInvite(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateServerInviteRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+	actor, _ := r.Context().Value("server_id").(string)
+	invite, err := h.registration.CreateInvite(r.Context(), req, actor)
+	if err != nil {
+		h.registrationError(w, err)
+		return
+	}
+	h.logger.Infow("Server invite created", "invite", invite.ID, "max_uses", invite.MaxUses, "by", actor)
+	h.jsonResponse(w, http.StatusCreated, invite)
+}
+
+// RevokeServerInvite stops an invite from registering more servers
+// @Summary Revoke Server Invite
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Param id path string true "Invite ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/server-invites/{id} [delete]
+func (h *Handler) RevokeServerInvite(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if err := h.registration.RevokeInvite(r.Context(), id); err != nil {
+		h.registrationError(w, err)
+		return
+	}
+	h.logger.Infow("Server invite revoked", "invite", id)
+	h.jsonResponse(w, http.StatusOK, map[string]string{"status": "revoked", "id": id})
+}
+
+// ListPendingServers returns self-registered servers awaiting approval
+// @Summary List Pending Servers
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Success 200 {array} models.ServerRegistration
+// @Router /admin/servers/pending [get]
+func (h *Handler) ListPendingServers(w http.ResponseWriter, r *http.Request) {
+	pending, err := h.registration.ListPending(r.Context())
+	if err != nil {
+		h.registrationError(w, err)
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, pending)
+}
+
+// ApproveServer activates a pending server's token
+// @Summary Approve Server
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Param id path string true "Server ID"
+// @Success 200 {object} models.ServerRegistration
+// @Failure 404 {object} map[string]string
+// @Router /admin/servers/{id}/approve [post]
+func (h *Handler) ApproveServer(w http.ResponseWriter, r *http.Request) {
+	h.reviewServer(w, r, true)
+}
+
+// RejectServer turns down a pending server; its token never authenticates
+// @Summary Reject Server
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Param id path string true "Server ID"
+// @Success 200 {object} models.ServerRegistration
+// @Failure 404 {object} map[string]string
+// @Router /admin/servers/{id}/reject [post]
+func (h *Handler) RejectServer(w http.ResponseWriter, r *http.Request) {
+	h.reviewServer(w, r, false)
+}
+
+func (h *Handler) reviewServer(w http.ResponseWriter, r *http.Request, approve bool) {
+	id := chi.URLParam(r, "id")
+	actor, _ := r.Context().Value("server_id").(string)
+	reg, err := h.registration.Review(r.Context(), id, approve, actor)
+	if err != nil {
+		h.registrationError(w, err)
+		return
+	}
+	h.logger.Infow("Server registration reviewed", "server_id", id, "status", reg.Status, "by", actor)
+	h.jsonResponse(w, http.StatusOK, reg)
+}
+
+func (h *Handler) registrationError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, logic.ErrInvalidInvite):
+		h.errorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, logic.ErrInviteNotUsable):
+		h.errorResponse(w, http.StatusForbidden, "Invite code is unknown, revoked, expired or used up")
+	case errors.Is(err, logic.ErrAddressRegistered):
+		h.errorResponse(w, http.StatusConflict, "Address is already registered")
+	case errors.Is(err, logic.ErrUnknownInvite):
+		h.errorResponse(w, http.StatusNotFound, "Invite not found")
+	case errors.Is(err, logic.ErrNotReviewer):
+		h.errorResponse(w, http.StatusForbidden, "Admin server token required")
+	case errors.Is(err, logic.ErrUnknownRegistration):
+		h.errorResponse(w, http.StatusNotFound, "No pending registration for this server")
+	default:
+		h.logger.Errorw("Server registration operation failed", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Server registration operation failed")
+	}
+}
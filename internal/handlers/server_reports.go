@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/i18n"
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+	"github.com/openmohaa/stats-api/internal/worker"
+)
+
+// ServerReportPreview is a report as built and as its text would be sent
+type ServerReportPreview struct {
+	Subject string               `json:"subject"`
+	Text    string               `json:"text"`
+	Report  *models.ServerReport `json:"report"`
+}
+
+// GetServerReportSettings returns where the calling server's weekly report goes
+// @Summary Get Weekly Report Settings
+// @Description Where the calling server's weekly report (top players, busiest hours, maps, notable matches) is delivered.
+// @Tags Integrations
+// @Produce json
+// @Security ServerToken
+// @Success 200 {object} models.ServerReportSettings
+// @Failure 404 {object} map[string]string
+// @Router /integrations/report [get]
+func (h *Handler) GetServerReportSettings(w http.ResponseWriter, r *http.Request) {
+	serverID, _ := r.Context().Value("server_id").(string)
+	settings, err := h.serverReports.GetSettings(r.Context(), serverID)
+	if errors.Is(err, logic.ErrReportSettingsNotFound) {
+		h.errorResponse(w, http.StatusNotFound, "No weekly report set up")
+		return
+	}
+	if err != nil {
+		h.logger.Errorw("Failed to get report settings", "server", serverID, "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to get report settings")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, settings)
+}
+
+// SetServerReportSettings sets where the calling server's weekly report goes
+// @Summary Set Weekly Report Settings
+// @Description Sends the calling server's weekly report to a webhook (JSON with a text `content` chat webhooks show), an email address (when the API has SMTP configured) or both, each Monday for the week before (UTC).
+// @Tags Integrations
+// @Accept json
+// @Produce json
+// @Security ServerToken
+// @Param body body models.ServerReportSettings true "enabled, webhook_url and/or email"
+// @Success 200 {object} models.ServerReportSettings
+// @Failure 400 {object} map[string]string
+// @Router /integrations/report [put]
+func (h *Handler) SetServerReportSettings(w http.ResponseWriter, r *http.Request) {
+	var settings models.ServerReportSettings
+	if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+		h.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	settings.ServerID, _ = r.Context().Value("server_id").(string)
+
+	err := h.serverReports.SetSettings(r.Context(), &settings)
+	if errors.Is(err, logic.ErrInvalidReportSettings) {
+		h.errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		h.logger.Errorw("Failed to set report settings", "server", settings.ServerID, "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to set report settings")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, settings)
+}
+
+// DeleteServerReportSettings stops the calling server's weekly report
+// @Summary Stop Weekly Report
+// @Tags Integrations
+// @Security ServerToken
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /integrations/report [delete]
+func (h *Handler) DeleteServerReportSettings(w http.ResponseWriter, r *http.Request) {
+	serverID, _ := r.Context().Value("server_id").(string)
+	err := h.serverReports.DeleteSettings(r.Context(), serverID)
+	if errors.Is(err, logic.ErrReportSettingsNotFound) {
+		h.errorResponse(w, http.StatusNotFound, "No weekly report set up")
+		return
+	}
+	if err != nil {
+		h.logger.Errorw("Failed to delete report settings", "server", serverID, "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to delete report settings")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PreviewServerReport builds the calling server's report without sending it
+// @Summary Preview Weekly Report
+// @Description The calling server's report for a week as it would be sent, in the request's language. Defaults to the last finished week.
+// @Tags Integrations
+// @Produce json
+// @Security ServerToken
+// @Param week query string false "Any day of the week (YYYY-MM-DD)"
+// @Success 200 {object} ServerReportPreview
+// @Failure 400 {object} map[string]string
+// @Router /integrations/report/preview [get]
+func (h *Handler) PreviewServerReport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	serverID, _ := ctx.Value("server_id").(string)
+
+	day := time.Now().UTC().AddDate(0, 0, -7)
+	if s := r.URL.Query().Get("week"); s != "" {
+		parsed, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			h.errorResponse(w, http.StatusBadRequest, "week must be a date (YYYY-MM-DD)")
+			return
+		}
+		day = parsed
+	}
+	week := logic.HighlightPeriodStart(models.HighlightWeek, day)
+
+	report, err := h.serverReports.Build(ctx, serverID, week)
+	if err != nil {
+		h.logger.Errorw("Failed to build server report", "server", serverID, "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to build report")
+		return
+	}
+	subject, text := worker.RenderServerReport(i18n.LocaleFromContext(ctx), report)
+	h.jsonResponse(w, http.StatusOK, &ServerReportPreview{Subject: subject, Text: text, Report: report})
+}
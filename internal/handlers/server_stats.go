@@ -1,11 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
 )
 
 // ============================================================================
@@ -32,13 +35,23 @@ func (h *Handler) GetServerPulse(w http.ResponseWriter, r *http.Request) {
 
 // GetServerActivity returns a heatmap of activity
 // @Summary Global Server Activity
+// @Description Events by day of week and hour, counted in tz
 // @Tags Server
 // @Produce json
+// @Param period query string false "Period (all, day, week, month, year, 7d, 30d, 365d)" default(all)
+// @Param tz query string false "IANA time zone days and hours are counted in" default(UTC)
+// @Param from query string false "Start (RFC 3339 or YYYY-MM-DD in tz), replaces period"
+// @Param to query string false "End (RFC 3339, or YYYY-MM-DD to include that day)"
 // @Success 200 {object} map[string]interface{} "Activity Data"
+// @Failure 400 {object} map[string]string "Invalid period"
 // @Failure 500 {object} map[string]string "Internal Error"
 // @Router /stats/server/activity [get]
 func (h *Handler) GetServerActivity(w http.ResponseWriter, r *http.Request) {
-	activity, err := h.serverStats.GetGlobalActivity(r.Context())
+	period, ok := h.parsePeriod(w, r)
+	if !ok {
+		return
+	}
+	activity, err := h.serverStats.GetGlobalActivity(r.Context(), period)
 	if err != nil {
 		h.logger.Errorw("Failed to get server activity", "error", err)
 		h.errorResponse(w, http.StatusInternalServerError, "Failed to get server activity")
@@ -111,6 +124,53 @@ func (h *Handler) GetServersGlobalStats(w http.ResponseWriter, r *http.Request)
 	h.jsonResponse(w, http.StatusOK, stats)
 }
 
+// GetServerBrowser lists servers formatted for in-game browsers and launchers
+// @Summary Server Browser
+// @Description Active servers with connect address, players, map, gametype and a ping hint (median player ping over the last hour), from heartbeats. Only servers with a live heartbeat are listed unless include_offline is set.
+// @Tags Server
+// @Produce json
+// @Param map query string false "Only servers on this map"
+// @Param gametype query string false "Only servers running this gametype"
+// @Param region query string false "Only servers in this region"
+// @Param q query string false "Name contains (case-insensitive)"
+// @Param not_empty query bool false "Hide empty servers"
+// @Param not_full query bool false "Hide full servers"
+// @Param include_offline query bool false "List servers without a live heartbeat last"
+// @Param sort query string false "players, name, map or ping" default(players)
+// @Success 200 {array} models.BrowserServer "Server Browser"
+// @Failure 400 {object} map[string]string "Bad Request"
+// @Failure 500 {object} map[string]string "Internal Error"
+// @Router /servers/browser [get]
+func (h *Handler) GetServerBrowser(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := models.ServerBrowserFilter{
+		Map:            q.Get("map"),
+		Gametype:       q.Get("gametype"),
+		Region:         q.Get("region"),
+		Search:         q.Get("q"),
+		NotEmpty:       q.Get("not_empty") == "true",
+		NotFull:        q.Get("not_full") == "true",
+		IncludeOffline: q.Get("include_offline") == "true",
+		Sort:           q.Get("sort"),
+	}
+	if filter.Sort == "" {
+		filter.Sort = logic.BrowserSortPlayers
+	}
+	if !logic.ValidBrowserSort(filter.Sort) {
+		h.errorResponse(w, http.StatusBadRequest, "sort must be players, name, map or ping")
+		return
+	}
+
+	svc := h.getServerTracking()
+	servers, err := svc.GetServerBrowser(r.Context(), filter)
+	if err != nil {
+		h.logger.Errorw("Failed to get server browser", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to get servers")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, servers)
+}
+
 // GetServerRankings returns ranked list of servers
 // @Summary Get Server Rankings
 // @Tags Server
@@ -188,6 +248,9 @@ func (h *Handler) GetServerLiveStatus(w http.ResponseWriter, r *http.Request) {
 		h.errorResponse(w, http.StatusInternalServerError, "Failed to get live status")
 		return
 	}
+	status.Players = applyDisplayNames(r.Context(), h, status.Players, func(p *models.RosterEntry) (string, *string, *bool) {
+		return p.GUID, &p.Name, &p.NameFlagged
+	})
 	h.jsonResponse(w, http.StatusOK, status)
 }
 
@@ -252,8 +315,11 @@ func (h *Handler) GetServerPeakHours(w http.ResponseWriter, r *http.Request) {
 // @Tags Server
 // @Produce json
 // @Param id path string true "Server ID"
+// @Param stat query string false "Stat to rank by (same values as /stats/leaderboard, except objectives and playtime)" default(kills)
+// @Param period query string false "Time period (all, week, month, year)" default(all)
 // @Param limit query int false "Limit" default(25)
-// @Success 200 {array} models.PlayerStats "Top Players"
+// @Success 200 {array} models.ServerTopPlayer "Top Players"
+// @Failure 400 {object} map[string]string "Stat not tracked per server"
 // @Failure 500 {object} map[string]string "Internal Error"
 // @Router /servers/{id}/top-players [get]
 func (h *Handler) GetServerTopPlayers(w http.ResponseWriter, r *http.Request) {
@@ -265,13 +331,29 @@ func (h *Handler) GetServerTopPlayers(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	stat := r.URL.Query().Get("stat")
+	if stat == "" {
+		stat = "kills"
+	}
+	period, ok := h.parsePeriod(w, r)
+	if !ok {
+		return
+	}
+
 	svc := h.getServerTracking()
-	players, err := svc.GetServerTopPlayers(r.Context(), serverID, limit)
+	players, err := svc.GetServerTopPlayers(r.Context(), serverID, stat, period, limit)
+	if errors.Is(err, logic.ErrUnsupportedServerStat) {
+		h.errorResponse(w, http.StatusBadRequest, "Stat is not tracked per server")
+		return
+	}
 	if err != nil {
-		h.logger.Errorw("Failed to get top players", "server_id", serverID, "error", err)
+		h.logger.Errorw("Failed to get top players", "server_id", serverID, "stat", stat, "error", err)
 		h.errorResponse(w, http.StatusInternalServerError, "Failed to get top players")
 		return
 	}
+	players = applyDisplayNames(r.Context(), h, players, func(p *models.ServerTopPlayer) (string, *string, *bool) {
+		return p.GUID, &p.Name, &p.NameFlagged
+	})
 	h.jsonResponse(w, http.StatusOK, players)
 }
 
@@ -317,6 +399,71 @@ func (h *Handler) GetServerWeaponStats(w http.ResponseWriter, r *http.Request) {
 	h.jsonResponse(w, http.StatusOK, weapons)
 }
 
+// GetServerGriefingReport returns the server's top team-killers and team-damagers
+// @Summary Server Griefing Report
+// @Description Players ranked by team kills and by damage dealt to teammates on this server
+// @Tags Server
+// @Produce json
+// @Param id path string true "Server ID"
+// @Param days query int false "Days to look back" default(7)
+// @Param limit query int false "Players per list" default(10)
+// @Success 200 {object} models.GriefingReport "Griefing Report"
+// @Failure 500 {object} map[string]string "Internal Error"
+// @Router /servers/{id}/griefing [get]
+func (h *Handler) GetServerGriefingReport(w http.ResponseWriter, r *http.Request) {
+	serverID := chi.URLParam(r, "id")
+	days := 7
+	if d := r.URL.Query().Get("days"); d != "" {
+		if parsed, _ := strconv.Atoi(d); parsed > 0 && parsed <= 90 {
+			days = parsed
+		}
+	}
+	limit := 10
+	if l := r.URL.Query().Get("limit"); l != "" {
+		if parsed, _ := strconv.Atoi(l); parsed > 0 && parsed <= 100 {
+			limit = parsed
+		}
+	}
+
+	svc := h.getServerTracking()
+	report, err := svc.GetServerGriefingReport(r.Context(), serverID, days, limit)
+	if err != nil {
+		h.logger.Errorw("Failed to get griefing report", "server_id", serverID, "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to get griefing report")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, report)
+}
+
+// GetServerVehicleUsage returns how each vehicle type is used on a server
+// @Summary Server Vehicle Usage
+// @Description Uses, users, kills, roadkills, passenger kills, crashes and deaths per vehicle type
+// @Tags Server
+// @Produce json
+// @Param id path string true "Server ID"
+// @Param days query int false "Days to look back" default(30)
+// @Success 200 {object} models.ServerVehicleReport "Vehicle Usage"
+// @Failure 500 {object} map[string]string "Internal Error"
+// @Router /servers/{id}/vehicles [get]
+func (h *Handler) GetServerVehicleUsage(w http.ResponseWriter, r *http.Request) {
+	serverID := chi.URLParam(r, "id")
+	days := 30
+	if d := r.URL.Query().Get("days"); d != "" {
+		if parsed, _ := strconv.Atoi(d); parsed > 0 && parsed <= 365 {
+			days = parsed
+		}
+	}
+
+	svc := h.getServerTracking()
+	report, err := svc.GetServerVehicleUsage(r.Context(), serverID, days)
+	if err != nil {
+		h.logger.Errorw("Failed to get server vehicle usage", "server_id", serverID, "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to get vehicle usage")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, report)
+}
+
 // GetServerRecentMatches returns recent matches for a server
 // @Summary Server Recent Matches
 // @Tags Server
@@ -350,21 +497,32 @@ func (h *Handler) GetServerRecentMatches(w http.ResponseWriter, r *http.Request)
 // @Tags Server
 // @Produce json
 // @Param id path string true "Server ID"
-// @Param days query int false "Days" default(7)
+// @Param days query int false "Days, when no period, from or to is given" default(7)
+// @Param period query string false "Period (all, day, week, month, year, 7d, 30d, 365d)"
+// @Param tz query string false "IANA time zone of the hours and timestamps" default(UTC)
+// @Param from query string false "Start (RFC 3339 or YYYY-MM-DD in tz), replaces period"
+// @Param to query string false "End (RFC 3339, or YYYY-MM-DD to include that day)"
 // @Success 200 {array} models.ActivityTimelinePoint "Timeline"
+// @Failure 400 {object} map[string]string "Invalid period"
 // @Failure 500 {object} map[string]string "Internal Error"
 // @Router /servers/{id}/activity-timeline [get]
 func (h *Handler) GetServerActivityTimeline(w http.ResponseWriter, r *http.Request) {
 	serverID := chi.URLParam(r, "id")
-	days := 7
-	if d := r.URL.Query().Get("days"); d != "" {
-		if parsed, _ := strconv.Atoi(d); parsed > 0 {
+	period, ok := h.parsePeriod(w, r)
+	if !ok {
+		return
+	}
+	q := r.URL.Query()
+	if q.Get("period") == "" && period.IsAll() {
+		days := 7
+		if parsed, _ := strconv.Atoi(q.Get("days")); parsed > 0 {
 			days = parsed
 		}
+		period.From = time.Now().AddDate(0, 0, -days)
 	}
 
 	svc := h.getServerTracking()
-	timeline, err := svc.GetServerActivityTimeline(r.Context(), serverID, days)
+	timeline, err := svc.GetServerActivityTimeline(r.Context(), serverID, period)
 	if err != nil {
 		h.logger.Errorw("Failed to get activity timeline", "server_id", serverID, "error", err)
 		h.errorResponse(w, http.StatusInternalServerError, "Failed to get timeline")
@@ -527,6 +685,36 @@ func (h *Handler) GetServerHistoricalPlayers(w http.ResponseWriter, r *http.Requ
 	})
 }
 
+// GetServerPlayerProfile returns a player's stats scoped to a single server
+// @Summary Server Player Profile
+// @Description Player stats, ranks and first/last seen for one server
+// @Tags Server
+// @Produce json
+// @Param id path string true "Server ID"
+// @Param guid path string true "Player GUID"
+// @Success 200 {object} models.ServerPlayerProfile "Player Profile"
+// @Failure 400 {object} map[string]string "Missing ID"
+// @Failure 404 {object} map[string]string "Not Found"
+// @Router /servers/{id}/players/{guid} [get]
+func (h *Handler) GetServerPlayerProfile(w http.ResponseWriter, r *http.Request) {
+	serverID := chi.URLParam(r, "id")
+	guid := chi.URLParam(r, "guid")
+	if serverID == "" || guid == "" {
+		h.errorResponse(w, http.StatusBadRequest, "Missing server ID or player GUID")
+		return
+	}
+
+	svc := h.getServerTracking()
+	profile, err := svc.GetServerPlayerProfile(r.Context(), serverID, guid)
+	if err != nil {
+		h.logger.Errorw("Failed to get server player profile", "server_id", serverID, "guid", guid, "error", err)
+		h.errorResponse(w, http.StatusNotFound, "Player not found on this server")
+		return
+	}
+	profile.Name = h.profileName(r.Context(), guid, profile.Name)
+	h.jsonResponse(w, http.StatusOK, profile)
+}
+
 // ============================================================================
 // MAP ROTATION ANALYSIS
 // ============================================================================
@@ -583,3 +771,61 @@ func (h *Handler) GetServerCountryStats(w http.ResponseWriter, r *http.Request)
 	}
 	h.jsonResponse(w, http.StatusOK, countries)
 }
+
+// GetServerLatency returns the distribution of player pings on a server
+// @Summary Server Latency Distribution
+// @Description Average, median, p90 and p99 player ping and ping samples per range, from the pings heartbeats carry
+// @Tags Server
+// @Produce json
+// @Param id path string true "Server ID"
+// @Param days query int false "Days to look back" default(7)
+// @Success 200 {object} models.ServerLatencyReport "Latency Report"
+// @Failure 500 {object} map[string]string "Internal Error"
+// @Router /servers/{id}/latency [get]
+func (h *Handler) GetServerLatency(w http.ResponseWriter, r *http.Request) {
+	serverID := chi.URLParam(r, "id")
+	days := 7
+	if d := r.URL.Query().Get("days"); d != "" {
+		if parsed, _ := strconv.Atoi(d); parsed > 0 && parsed <= 90 {
+			days = parsed
+		}
+	}
+
+	svc := h.getServerTracking()
+	report, err := svc.GetServerLatency(r.Context(), serverID, days)
+	if err != nil {
+		h.logger.Errorw("Failed to get server latency", "server_id", serverID, "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to get server latency")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, report)
+}
+
+// GetServerPerformance returns the server's frame rate, frame times and CPU usage over time
+// @Summary Server Performance
+// @Description Server frame rate (sv_fps), frame times, CPU usage and lagging samples from heartbeats, overall and per interval
+// @Tags Server
+// @Produce json
+// @Param id path string true "Server ID"
+// @Param hours query int false "Hours to look back" default(24)
+// @Success 200 {object} models.ServerPerformanceReport "Performance Report"
+// @Failure 500 {object} map[string]string "Internal Error"
+// @Router /servers/{id}/performance [get]
+func (h *Handler) GetServerPerformance(w http.ResponseWriter, r *http.Request) {
+	serverID := chi.URLParam(r, "id")
+	hours := 24
+	if v := r.URL.Query().Get("hours"); v != "" {
+		if parsed, _ := strconv.Atoi(v); parsed > 0 && parsed <= 90*24 {
+			hours = parsed
+		}
+	}
+
+	svc := h.getServerTracking()
+	report, err := svc.GetServerPerformance(r.Context(), serverID, hours)
+	if err != nil {
+		h.logger.Errorw("Failed to get server performance", "server_id", serverID, "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to get server performance")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, report)
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/openmohaa/stats-api/internal/models"
@@ -56,3 +57,80 @@ func (h *Handler) GetPlayerStatsByMap(w http.ResponseWriter, r *http.Request) {
 
 	h.jsonResponse(w, http.StatusOK, stats)
 }
+
+// GetPlayerPacing returns the player's K/D in the first, middle and last third of their matches
+// @Summary Get Player Pacing
+// @Description Kills, deaths and headshots in each third of the player's matches, timed from match_start to match_end, with a fast_starter / strong_finisher / steady trend
+// @Tags Player
+// @Produce json
+// @Param guid path string true "Player GUID"
+// @Param phases query string false "all to include warmup and intermission kills"
+// @Success 200 {object} models.PlayerPacing "Pacing"
+// @Failure 500 {object} map[string]string "Server Error"
+// @Router /stats/player/{guid}/pacing [get]
+func (h *Handler) GetPlayerPacing(w http.ResponseWriter, r *http.Request) {
+	guid := chi.URLParam(r, "guid")
+
+	pacing, err := h.playerStats.GetPlayerPacing(r.Context(), guid)
+	if err != nil {
+		h.logger.Errorw("Failed to get pacing", "guid", guid, "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to get pacing")
+		return
+	}
+
+	h.jsonResponse(w, http.StatusOK, pacing)
+}
+
+// GetPlayerTeamDamage returns the player's team kills and damage dealt to teammates
+// @Summary Get Player Team Damage
+// @Description Team kills and damage dealt to teammates across all servers
+// @Tags Player
+// @Produce json
+// @Param guid path string true "Player GUID"
+// @Param days query int false "Days to look back" default(30)
+// @Success 200 {object} models.TeamDamageStats "Team Damage"
+// @Failure 500 {object} map[string]string "Server Error"
+// @Router /stats/player/{guid}/team-damage [get]
+func (h *Handler) GetPlayerTeamDamage(w http.ResponseWriter, r *http.Request) {
+	guid := chi.URLParam(r, "guid")
+	days := 30
+	if d, err := strconv.Atoi(r.URL.Query().Get("days")); err == nil && d > 0 && d <= 365 {
+		days = d
+	}
+
+	stats, err := h.getServerTracking().GetPlayerTeamDamage(r.Context(), guid, days)
+	if err != nil {
+		h.logger.Errorw("Failed to get team damage", "guid", guid, "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to get team damage")
+		return
+	}
+
+	h.jsonResponse(w, http.StatusOK, stats)
+}
+
+// GetPlayerPingHistory returns the player's average ping per day
+// @Summary Get Player Ping History
+// @Description Daily average, minimum and maximum ping across all servers, from the pings heartbeats carry
+// @Tags Player
+// @Produce json
+// @Param guid path string true "Player GUID"
+// @Param days query int false "Days to look back" default(30)
+// @Success 200 {object} models.PlayerPingHistory "Ping History"
+// @Failure 500 {object} map[string]string "Server Error"
+// @Router /stats/player/{guid}/ping [get]
+func (h *Handler) GetPlayerPingHistory(w http.ResponseWriter, r *http.Request) {
+	guid := chi.URLParam(r, "guid")
+	days := 30
+	if d, err := strconv.Atoi(r.URL.Query().Get("days")); err == nil && d > 0 && d <= 90 {
+		days = d
+	}
+
+	history, err := h.getServerTracking().GetPlayerPingHistory(r.Context(), guid, days)
+	if err != nil {
+		h.logger.Errorw("Failed to get ping history", "guid", guid, "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to get ping history")
+		return
+	}
+
+	h.jsonResponse(w, http.StatusOK, history)
+}
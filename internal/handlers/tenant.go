@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/openmohaa/stats-api/internal/logic"
+)
+
+// TenantMiddleware scopes a request to the tenant owning the X-API-Key header.
+// Without a key the request sees the whole instance, unless MULTI_TENANT is
+// set, in which case a key is required. The key is only read from the header,
+// never the query string, so it stays out of proxy and access logs.
+func (h *Handler) TenantMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("X-API-Key")
+
+		if key == "" || h.tenants == nil {
+			if h.requireTenant {
+				h.errorResponse(w, http.StatusUnauthorized, "Missing API key")
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		tenant, err := h.tenants.ResolveAPIKey(r.Context(), hashToken(key))
+		if errors.Is(err, logic.ErrUnknownTenant) {
+			h.errorResponse(w, http.StatusUnauthorized, "Invalid API key")
+			return
+		}
+		if err != nil {
+			h.logger.Errorw("Failed to resolve tenant", "error", err)
+			h.errorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(logic.WithTenant(r.Context(), tenant.ID)))
+	})
+}
+
+// UnscopedMiddleware refuses tenant requests on routes whose queries read
+// every community's data. It runs after TenantMiddleware.
+func (h *Handler) UnscopedMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if logic.TenantFromContext(r.Context()) != "" {
+			h.errorResponse(w, http.StatusForbidden, "Not available with a tenant API key")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ServerScopeMiddleware limits the ClickHouse reads of tenant requests to the
+// tenant's servers, on routes whose queries read every server (a player's
+// profile, a match, the global totals). It runs after TenantMiddleware.
+func (h *Handler) ServerScopeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids, scoped, err := h.tenantServerIDs(r.Context())
+		if err != nil {
+			h.logger.Errorw("Failed to list tenant servers", "error", err)
+			h.errorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+		if scoped {
+			r = r.WithContext(logic.WithServerScope(r.Context(), logic.TenantFromContext(r.Context()), ids))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// TenantServerMiddleware answers 404 for a server, named by the URL param,
+// outside the request's tenant. Routes about one server are scoped by it,
+// since their queries filter on the server. It runs after TenantMiddleware.
+func (h *Handler) TenantServerMiddleware(param string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ids, scoped, err := h.tenantServerIDs(r.Context())
+			if err != nil {
+				h.logger.Errorw("Failed to list tenant servers", "error", err)
+				h.errorResponse(w, http.StatusInternalServerError, "Internal server error")
+				return
+			}
+			if scoped && !slices.Contains(ids, chi.URLParam(r, param)) {
+				h.errorResponse(w, http.StatusNotFound, "Server not found")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// tenantServerIDs returns the servers visible to the request's tenant.
+// scoped is false for the default community, which sees every server.
+func (h *Handler) tenantServerIDs(ctx context.Context) (ids []string, scoped bool, err error) {
+	tenantID := logic.TenantFromContext(ctx)
+	if tenantID == "" || h.tenants == nil {
+		return nil, false, nil
+	}
+	ids, err = h.tenants.ServerIDs(ctx, tenantID)
+	return ids, true, err
+}
+
+// RoundPhaseMiddleware lets raw event views include warmup and intermission
+// events with ?phases=all
+func (h *Handler) RoundPhaseMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("phases") == "all" {
+			r = r.WithContext(logic.WithAllRoundPhases(r.Context()))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
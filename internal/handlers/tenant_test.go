@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// mockTenantService resolves a single known key
+type mockTenantService struct {
+	keyHash string
+	tenant  models.Tenant
+}
+
+func (m *mockTenantService) ResolveAPIKey(ctx context.Context, keyHash string) (*models.Tenant, error) {
+	if keyHash != m.keyHash {
+		return nil, logic.ErrUnknownTenant
+	}
+	return &m.tenant, nil
+}
+
+func (m *mockTenantService) ServerIDs(ctx context.Context, tenantID string) ([]string, error) {
+	return []string{"srv-1"}, nil
+}
+
+func (m *mockTenantService) Create(ctx context.Context, slug, name, keyHash string) (*models.Tenant, error) {
+	return nil, nil
+}
+
+func TestTenantMiddleware(t *testing.T) {
+	tenants := &mockTenantService{keyHash: hashToken("good-key"), tenant: models.Tenant{ID: "t-1"}}
+
+	tests := []struct {
+		name          string
+		key           string
+		query         string
+		requireTenant bool
+		wantStatus    int
+		wantTenant    string
+	}{
+		{name: "No key, single tenant", wantStatus: http.StatusOK, wantTenant: ""},
+		{name: "No key, multi tenant", requireTenant: true, wantStatus: http.StatusUnauthorized},
+		{name: "Valid key", key: "good-key", requireTenant: true, wantStatus: http.StatusOK, wantTenant: "t-1"},
+		{name: "Invalid key", key: "bad-key", wantStatus: http.StatusUnauthorized},
+		{name: "Query key ignored", query: "good-key", requireTenant: true, wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{
+				logger:        zap.NewNop().Sugar(),
+				tenants:       tenants,
+				requireTenant: tt.requireTenant,
+			}
+
+			var gotTenant string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotTenant = logic.TenantFromContext(r.Context())
+			})
+
+			req := httptest.NewRequest("GET", "/api/v1/stats/leaderboard?api_key="+tt.query, nil)
+			if tt.key != "" {
+				req.Header.Set("X-API-Key", tt.key)
+			}
+			w := httptest.NewRecorder()
+			h.TenantMiddleware(next).ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("StatusCode = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if gotTenant != tt.wantTenant {
+				t.Errorf("tenant = %q, want %q", gotTenant, tt.wantTenant)
+			}
+		})
+	}
+}
+
+func TestTenantScopeMiddlewares(t *testing.T) {
+	h := &Handler{logger: zap.NewNop().Sugar(), tenants: &mockTenantService{}}
+
+	r := chi.NewRouter()
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+	scoped := func(w http.ResponseWriter, r *http.Request) {
+		if logic.ServerScoped(r.Context()) != (logic.TenantFromContext(r.Context()) != "") {
+			w.WriteHeader(http.StatusTeapot)
+		}
+	}
+	r.With(h.UnscopedMiddleware).Get("/global", ok)
+	r.With(h.ServerScopeMiddleware).Get("/match/{id}", scoped)
+	r.With(h.TenantServerMiddleware("id")).Get("/servers/{id}", ok)
+
+	tests := []struct {
+		name       string
+		path       string
+		tenant     string
+		wantStatus int
+	}{
+		{name: "Unscoped, no tenant", path: "/global", wantStatus: http.StatusOK},
+		{name: "Unscoped, tenant", path: "/global", tenant: "t-1", wantStatus: http.StatusForbidden},
+		{name: "Scoped, no tenant", path: "/match/m-1", wantStatus: http.StatusOK},
+		{name: "Scoped, tenant", path: "/match/m-1", tenant: "t-1", wantStatus: http.StatusOK},
+		{name: "Own server", path: "/servers/srv-1", tenant: "t-1", wantStatus: http.StatusOK},
+		{name: "Other server", path: "/servers/srv-2", tenant: "t-1", wantStatus: http.StatusNotFound},
+		{name: "Any server, no tenant", path: "/servers/srv-2", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req = req.WithContext(logic.WithTenant(req.Context(), tt.tenant))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("StatusCode = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestIngestEventsStampsTenant(t *testing.T) {
+	var got *models.RawEvent
+	h := &Handler{
+		logger: zap.NewNop().Sugar(),
+		pool: &MockIngestQueue{EnqueueFunc: func(e *models.RawEvent) bool {
+			got = e
+			return true
+		}},
+	}
+
+	// A payload cannot pick its own tenant
+	body := `[{"type":"player_kill","tenant_id":"spoofed"}]`
+	req := httptest.NewRequest("POST", "/api/v1/ingest/events", strings.NewReader(body))
+	req = req.WithContext(logic.WithTenant(req.Context(), "t-1"))
+	h.IngestEvents(httptest.NewRecorder(), req)
+
+	if got == nil || got.TenantID != "t-1" {
+		t.Fatalf("TenantID = %v, want t-1", got)
+	}
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GetPlayerTimeline returns a player's milestones, achievements and notable matches
+// @Summary Get Player Timeline
+// @Description Personal milestones (Nth kill, Nth win, first headshot with each weapon), unlocked achievements of the linked SMF account and the player's best matches, newest first.
+// @Tags Player
+// @Produce json
+// @Param guid path string true "Player GUID"
+// @Param limit query int false "Max entries (max 200)" default(50)
+// @Success 200 {object} models.PlayerTimeline
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /stats/player/{guid}/timeline [get]
+func (h *Handler) GetPlayerTimeline(w http.ResponseWriter, r *http.Request) {
+	guid := chi.URLParam(r, "guid")
+	if guid == "" {
+		h.errorResponse(w, http.StatusBadRequest, "GUID is required")
+		return
+	}
+	limit := 50
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 200 {
+		limit = l
+	}
+
+	timeline, err := h.timeline.GetPlayerTimeline(r.Context(), guid, limit)
+	if err != nil {
+		h.logger.Errorw("Failed to get player timeline", "error", err, "guid", guid)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to get timeline")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, timeline)
+}
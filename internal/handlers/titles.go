@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// ListTitles returns every title and badge definition
+// @Summary List Titles and Badges
+// @Description Cosmetic titles and badges. Ones with a rule_stat are granted hourly to players whose career stat reaches rule_min; the rest only by admins.
+// @Tags Player
+// @Produce json
+// @Success 200 {array} models.Title
+// @Failure 500 {object} map[string]string
+// @Router /stats/titles [get]
+func (h *Handler) ListTitles(w http.ResponseWriter, r *http.Request) {
+	titles, err := h.titles.List(r.Context())
+	if err != nil {
+		h.logger.Errorw("Failed to list titles", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to list titles")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, titles)
+}
+
+// GetPlayerTitles returns the titles and badges a player holds
+// @Summary Get Player Titles
+// @Tags Player
+// @Produce json
+// @Param guid path string true "Player GUID"
+// @Success 200 {array} models.PlayerTitle
+// @Failure 500 {object} map[string]string
+// @Router /stats/player/{guid}/titles [get]
+func (h *Handler) GetPlayerTitles(w http.ResponseWriter, r *http.Request) {
+	guid := chi.URLParam(r, "guid")
+	titles, err := h.titles.PlayerTitles(r.Context(), guid)
+	if err != nil {
+		h.logger.Errorw("Failed to get player titles", "guid", guid, "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to get titles")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, titles)
+}
+
+// GetUserTitles returns the titles and badges of the signed-in user's GUIDs
+// @Summary Get My Titles
+// @Tags Auth
+// @Produce json
+// @Success 200 {array} models.PlayerTitle
+// @Failure 401 {object} map[string]string
+// @Router /users/me/titles [get]
+func (h *Handler) GetUserTitles(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	forumUserID, ok := ctx.Value("forum_user_id").(int)
+	if !ok || forumUserID == 0 {
+		h.errorResponse(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	titles, err := h.titles.UserTitles(ctx, forumUserID)
+	if err != nil {
+		h.logger.Errorw("Failed to get user titles", "user", forumUserID, "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to get titles")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, titles)
+}
+
+// EquipTitle shows a held title or badge on the signed-in user's GUIDs
+// @Summary Equip Title
+// @Description Equipping a title replaces the equipped one; up to 3 badges can be equipped.
+// @Tags Auth
+// @Produce json
+// @Param code path string true "Title code"
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /users/me/titles/{code}/equip [post]
+func (h *Handler) EquipTitle(w http.ResponseWriter, r *http.Request) {
+	h.setTitleEquipped(w, r, true)
+}
+
+// UnequipTitle hides a title or badge on the signed-in user's GUIDs
+// @Summary Unequip Title
+// @Tags Auth
+// @Produce json
+// @Param code path string true "Title code"
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /users/me/titles/{code}/equip [delete]
+func (h *Handler) UnequipTitle(w http.ResponseWriter, r *http.Request) {
+	h.setTitleEquipped(w, r, false)
+}
+
+func (h *Handler) setTitleEquipped(w http.ResponseWriter, r *http.Request, equip bool) {
+	ctx := r.Context()
+	forumUserID, ok := ctx.Value("forum_user_id").(int)
+	if !ok || forumUserID == 0 {
+		h.errorResponse(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	code := chi.URLParam(r, "code")
+	status := "unequipped"
+	var err error
+	if equip {
+		status = "equipped"
+		err = h.titles.Equip(ctx, forumUserID, code)
+	} else {
+		err = h.titles.Unequip(ctx, forumUserID, code)
+	}
+	if err != nil {
+		h.titleError(w, err)
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, map[string]string{"status": status, "code": code})
+}
+
+// DefineTitle creates or replaces a title or badge definition
+// @Summary Define Title
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ServerToken
+// @Param code path string true "Title code"
+// @Param body body models.Title true "Definition; the code in the body is ignored"
+// @Success 200 {object} models.Title
+// @Failure 400 {object} map[string]string
+// @Router /admin/titles/{code} [put]
+func (h *Handler) DefineTitle(w http.ResponseWriter, r *http.Request) {
+	var t models.Title
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		h.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	t.Code = chi.URLParam(r, "code")
+	if err := h.titles.Define(r.Context(), &t); err != nil {
+		h.titleError(w, err)
+		return
+	}
+	h.logger.Infow("Title defined", "code", t.Code, "kind", t.Kind, "rule", t.RuleStat, "min", t.RuleMin)
+	h.jsonResponse(w, http.StatusOK, t)
+}
+
+// GrantTitle gives a player a title or badge
+// @Summary Grant Title
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ServerToken
+// @Param code path string true "Title code"
+// @Param body body models.TitleGrantRequest true "Player"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/titles/{code}/players [post]
+func (h *Handler) GrantTitle(w http.ResponseWriter, r *http.Request) {
+	var req models.TitleGrantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.PlayerGUID == "" {
+		h.errorResponse(w, http.StatusBadRequest, "player_guid is required")
+		return
+	}
+	code := chi.URLParam(r, "code")
+	actor, _ := r.Context().Value("server_id").(string)
+	if err := h.titles.Grant(r.Context(), req.PlayerGUID, code, actor); err != nil {
+		h.titleError(w, err)
+		return
+	}
+	h.logger.Infow("Title granted", "code", code, "player", req.PlayerGUID, "by", actor)
+	h.jsonResponse(w, http.StatusOK, map[string]string{"status": "granted", "code": code, "player_guid": req.PlayerGUID})
+}
+
+// RevokeTitle takes a title or badge from a player
+// @Summary Revoke Title
+// @Description Rule titles are granted again on the next hourly sweep while the player still qualifies.
+// @Tags Admin
+// @Produce json
+// @Security ServerToken
+// @Param code path string true "Title code"
+// @Param guid path string true "Player GUID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/titles/{code}/players/{guid} [delete]
+func (h *Handler) RevokeTitle(w http.ResponseWriter, r *http.Request) {
+	code, guid := chi.URLParam(r, "code"), chi.URLParam(r, "guid")
+	if err := h.titles.Revoke(r.Context(), guid, code); err != nil {
+		h.titleError(w, err)
+		return
+	}
+	actor, _ := r.Context().Value("server_id").(string)
+	h.logger.Infow("Title revoked", "code", code, "player", guid, "by", actor)
+	h.jsonResponse(w, http.StatusOK, map[string]string{"status": "revoked", "code": code, "player_guid": guid})
+}
+
+// titleError maps title errors to responses
+func (h *Handler) titleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, logic.ErrUnknownTitle):
+		h.errorResponse(w, http.StatusNotFound, "Title not found")
+	case errors.Is(err, logic.ErrTitleNotHeld):
+		h.errorResponse(w, http.StatusNotFound, "Title not held")
+	case errors.Is(err, logic.ErrInvalidTitle):
+		h.errorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, logic.ErrBadgeLimit):
+		h.errorResponse(w, http.StatusConflict, fmt.Sprintf("At most %d badges can be equipped", models.MaxEquippedBadges))
+	default:
+		h.logger.Errorw("Title operation failed", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Title operation failed")
+	}
+}
+
+// playerCosmetics returns what the GUIDs have equipped. Cosmetics never fail
+// a stats response: errors are logged and nothing is shown.
+func (h *Handler) playerCosmetics(ctx context.Context, guids []string) map[string]*models.PlayerCosmetics {
+	if h.titles == nil || len(guids) == 0 {
+		return nil
+	}
+	cosmetics, err := h.titles.Equipped(ctx, guids)
+	if err != nil {
+		h.logger.Warnw("Failed to read equipped titles", "players", len(guids), "error", err)
+		return nil
+	}
+	return cosmetics
+}
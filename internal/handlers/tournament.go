@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
+
+	"github.com/openmohaa/stats-api/internal/logic"
 )
 
 // ============================================================================
@@ -74,3 +77,68 @@ func (h *Handler) GetTournamentStats(w http.ResponseWriter, r *http.Request) {
 	}
 	h.jsonResponse(w, http.StatusOK, stats)
 }
+
+// GetTournamentMatchResult returns the signed result of a finalized
+// tournament match
+// @Summary Get Signed Match Result
+// @Description The result document is canonical JSON (sorted keys, no whitespace) signed with ed25519 when the match was finalized, and again if its result is corrected (see correction). Verify signature over the base64-decoded payload with public_key; result is the same document, decoded.
+// @Tags Tournaments
+// @Produce json
+// @Param id path string true "Tournament ID"
+// @Param mid path string true "Match ID"
+// @Success 200 {object} models.SignedMatchResult
+// @Failure 404 {object} map[string]string "Not Found"
+// @Failure 503 {object} map[string]string
+// @Router /tournaments/{id}/matches/{mid}/result [get]
+func (h *Handler) GetTournamentMatchResult(w http.ResponseWriter, r *http.Request) {
+	if h.matchResults == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Result signing not enabled")
+		return
+	}
+	ctx := r.Context()
+	tournamentID, matchID := chi.URLParam(r, "id"), chi.URLParam(r, "mid")
+
+	result, err := h.matchResults.Get(ctx, tournamentID, matchID)
+	if errors.Is(err, logic.ErrResultNotSigned) {
+		// Finalized while signing was off or failed: sign it now, as a
+		// locked match cannot be finalized again
+		record, serr := h.matchStates.Get(ctx, matchID)
+		switch {
+		case errors.Is(serr, logic.ErrUnknownMatch):
+			h.errorResponse(w, http.StatusNotFound, "Match not found")
+			return
+		case serr != nil:
+			err = serr
+		case record.TournamentID != tournamentID || !record.Locked():
+			h.errorResponse(w, http.StatusNotFound, "Match result not finalized")
+			return
+		default:
+			result, err = h.matchResults.Sign(ctx, record)
+		}
+	}
+	if errors.Is(err, logic.ErrMatchNotFound) {
+		h.errorResponse(w, http.StatusNotFound, "Match not found")
+		return
+	}
+	if err != nil {
+		h.logger.Errorw("Failed to get match result", "tournament", tournamentID, "match", matchID, "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to get match result")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, result)
+}
+
+// GetResultSigningKey returns the public key match results are signed with
+// @Summary Get Result Signing Key
+// @Tags Tournaments
+// @Produce json
+// @Success 200 {object} models.ResultSigningKey
+// @Failure 503 {object} map[string]string
+// @Router /tournaments/signing-key [get]
+func (h *Handler) GetResultSigningKey(w http.ResponseWriter, r *http.Request) {
+	if h.matchResults == nil {
+		h.errorResponse(w, http.StatusServiceUnavailable, "Result signing not enabled")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, h.matchResults.SigningKey())
+}
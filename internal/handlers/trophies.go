@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// trophyCacheTTL is how long a trophy case is served from live state
+const trophyCacheTTL = 5 * time.Minute
+
+// GetPlayerTrophies returns everything a player has earned in one response
+// @Summary Get Player Trophy Case
+// @Description Achievements of the linked SMF account, titles and badges, personal records (milestones), player of the day/week placements and tournament results. Cached for 5 minutes.
+// @Tags Player
+// @Produce json
+// @Param guid path string true "Player GUID"
+// @Success 200 {object} models.TrophyCase
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /stats/player/{guid}/trophies [get]
+func (h *Handler) GetPlayerTrophies(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	guid := chi.URLParam(r, "guid")
+	if guid == "" {
+		h.errorResponse(w, http.StatusBadRequest, "GUID is required")
+		return
+	}
+
+	key := "trophies:" + logic.TenantFromContext(ctx) + ":" + guid
+	if data, err := h.redis.Get(ctx, key); err == nil {
+		var tc models.TrophyCase
+		if err := json.Unmarshal([]byte(data), &tc); err == nil {
+			h.jsonResponse(w, http.StatusOK, &tc)
+			return
+		}
+	}
+
+	tc, err := h.trophies.GetTrophyCase(ctx, guid)
+	if err != nil {
+		h.logger.Errorw("Failed to get trophy case", "error", err, "guid", guid)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to get trophies")
+		return
+	}
+	if data, err := json.Marshal(tc); err == nil {
+		if err := h.redis.Set(ctx, key, data, trophyCacheTTL); err != nil {
+			h.logger.Warnw("Failed to cache trophy case", "error", err, "guid", guid)
+		}
+	}
+	h.jsonResponse(w, http.StatusOK, tc)
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GetMatchViewership returns how many watched a match over time
+// @Summary Get Match Viewership
+// @Description Spectators over the match in 1, 5 or 15 minute intervals, with peak, average and unique viewers. Counts come from heartbeat spectator_count when the server reports it, otherwise from spectator_join and spectator_leave events.
+// @Tags Match
+// @Produce json
+// @Param matchId path string true "Match ID"
+// @Success 200 {object} models.MatchViewership
+// @Failure 500 {object} map[string]string
+// @Router /stats/match/{matchId}/viewership [get]
+func (h *Handler) GetMatchViewership(w http.ResponseWriter, r *http.Request) {
+	matchID := chi.URLParam(r, "matchId")
+	if matchID == "" {
+		h.errorResponse(w, http.StatusBadRequest, "Match ID is required")
+		return
+	}
+
+	viewership, err := h.viewership.Match(r.Context(), matchID)
+	if err != nil {
+		h.logger.Errorw("Failed to get match viewership", "error", err, "matchID", matchID)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to get viewership")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, viewership)
+}
+
+// GetFeaturedMatches returns the most watched recent matches
+// @Summary Get Featured Matches
+// @Description The matches of the last N hours with the most spectators at peak, live ones included.
+// @Tags Match
+// @Produce json
+// @Param hours query int false "Matches of the last N hours" default(24)
+// @Param limit query int false "Max matches" default(10)
+// @Success 200 {array} models.FeaturedMatch
+// @Failure 500 {object} map[string]string
+// @Router /stats/matches/featured [get]
+func (h *Handler) GetFeaturedMatches(w http.ResponseWriter, r *http.Request) {
+	hours, limit := 24, 10
+	if v, err := strconv.Atoi(r.URL.Query().Get("hours")); err == nil && v > 0 && v <= 24*7 {
+		hours = v
+	}
+	if v, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && v > 0 && v <= 50 {
+		limit = v
+	}
+
+	featured, err := h.viewership.Featured(r.Context(), hours, limit)
+	if err != nil {
+		h.logger.Errorw("Failed to get featured matches", "error", err)
+		h.errorResponse(w, http.StatusInternalServerError, "Failed to get featured matches")
+		return
+	}
+	h.jsonResponse(w, http.StatusOK, featured)
+}
@@ -218,6 +218,7 @@ func (h *Handler) GetContextualLeaderboard(w http.ResponseWriter, r *http.Reques
 		h.errorResponse(w, http.StatusInternalServerError, "Failed to get leaderboard")
 		return
 	}
+	leaders = applyDisplayNames(r.Context(), h, leaders, statLeaderboardEntryName)
 
 	// ...
 	h.jsonResponse(w, http.StatusOK, models.ContextualLeaderboardResponse{
@@ -302,6 +303,7 @@ func (h *Handler) GetPlayerWarRoomData(w http.ResponseWriter, r *http.Request) {
 	// 5. Playstyle badge
 	badge, err := h.gamification.GetPlaystyle(ctx, guid)
 	if err == nil {
+		translatePlaystyle(ctx, badge)
 		response["playstyle"] = badge
 	}
 
@@ -452,6 +454,7 @@ func (h *Handler) GetComboLeaderboard(w http.ResponseWriter, r *http.Request) {
 		entries = append(entries, e)
 		rank++
 	}
+	entries = applyDisplayNames(ctx, h, entries, statLeaderboardEntryName)
 
 
 	h.jsonResponse(w, http.StatusOK, models.ComboLeaderboardResponse{
@@ -528,6 +531,9 @@ func (h *Handler) GetPeakPerformanceLeaderboard(w http.ResponseWriter, r *http.R
 		entries = append(entries, e)
 		rank++
 	}
+	entries = applyDisplayNames(ctx, h, entries, func(e *models.PeakLeaderboardEntry) (string, *string, *bool) {
+		return e.PlayerID, &e.PlayerName, &e.NameFlagged
+	})
 
 	// ...
 	h.jsonResponse(w, http.StatusOK, models.PeakLeaderboardResponse{
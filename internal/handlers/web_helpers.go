@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"time"
+
+	"github.com/openmohaa/stats-api/internal/models"
 )
 
 // ============================================================================
@@ -27,14 +29,14 @@ func (h *Handler) getDashboardStats(ctx context.Context) (*DashboardStats, error
 	}
 
 	// Live matches from Redis
-	vals, _ := h.redis.HVals(ctx, "live_matches").Result()
+	vals, _ := h.redis.HVals(ctx, "live_matches")
 	stats.LiveMatches = len(vals)
 
 	return &stats, nil
 }
 
 func (h *Handler) getLiveMatchCount(ctx context.Context) int {
-	count, _ := h.redis.HLen(ctx, "live_matches").Result()
+	count, _ := h.redis.HLen(ctx, "live_matches")
 	return int(count)
 }
 
@@ -45,16 +47,27 @@ func (h *Handler) getUserFromSession(r *http.Request) (interface{}, error) {
 }
 
 func (h *Handler) getPlayerProfile(ctx context.Context, guid string) (*PlayerProfile, error) {
+	privacy, err := h.playerPrivacy(ctx, guid)
+	if err != nil {
+		return nil, err
+	}
+	if privacy.HideProfile {
+		return nil, errPlayerHidden
+	}
+
 	// Try to get name and last activity from ClickHouse
 	var name string
 	var lastActive time.Time
-	err := h.ch.QueryRow(ctx, `
+	err = h.ch.QueryRow(ctx, `
 		SELECT any(actor_name), max(timestamp) FROM mohaa_stats.raw_events WHERE actor_id = ?
 	`, guid).Scan(&name, &lastActive)
 
 	if err != nil || name == "" {
 		name = "Unknown Soldier"
 	}
+	if privacy.AnonymizeName {
+		name = models.AnonymousName
+	}
 	if lastActive.IsZero() {
 		lastActive = time.Time{} // Keep as zero time if no activity
 	}
@@ -76,8 +89,8 @@ func (h *Handler) getPlayerStats(ctx context.Context, guid string) (*PlayerStats
 			countIf(event_type IN ('player_kill', 'bot_killed') AND actor_id = ?) as kills,
 			countIf(event_type IN ('player_kill', 'bot_killed') AND target_id = ?) as deaths,
 			countIf(event_type IN ('player_kill', 'bot_killed') AND hitloc IN ('head', 'helmet') AND actor_id = ?) as headshots,
-			countIf(event_type = 'weapon_fire' AND actor_id = ?) as shots,
-			countIf(event_type = 'weapon_hit' AND actor_id = ?) as hits,
+			sumIf(sample_weight, event_type = 'weapon_fire' AND actor_id = ?) as shots,
+			sumIf(sample_weight, event_type = 'weapon_hit' AND actor_id = ?) as hits,
 			uniqIf(match_id, actor_id = ?) as matches
 		FROM mohaa_stats.raw_events
 		WHERE actor_id = ? OR target_id = ?
@@ -249,7 +262,7 @@ func (h *Handler) getMapDetails(ctx context.Context, mapID string) (*MapInfo, er
 // See: smf-plugins/mohaa_tournaments/ for tournament management
 
 func (h *Handler) getLiveMatches(ctx context.Context) ([]interface{}, error) {
-	matchData, err := h.redis.HGetAll(ctx, "live_matches").Result()
+	matchData, err := h.redis.HGetAll(ctx, "live_matches")
 	if err != nil {
 		return nil, err
 	}
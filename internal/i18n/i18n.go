@@ -0,0 +1,154 @@
+// Package i18n translates the display strings the API writes itself (stat,
+// gametype, achievement and playstyle names, digest text) using the
+// translation files embedded from locales/. A key missing from a locale falls
+// back to English, and a key missing from English to the text the caller
+// already has, so untranslated strings still show.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+//go:embed locales/*.json
+var files embed.FS
+
+// DefaultLocale is the language the strings are written in and the fallback
+// for every key
+const DefaultLocale = "en"
+
+// Catalog holds the translations of a set of locales
+type Catalog struct {
+	messages map[string]map[string]string // locale -> key -> text
+	locales  []string                     // DefaultLocale first
+	matcher  language.Matcher
+}
+
+var defaultCatalog = mustLoad(files)
+
+// Default returns the catalog of the embedded translation files
+func Default() *Catalog {
+	return defaultCatalog
+}
+
+// Load reads every locales/<locale>.json in fsys, each a flat object of
+// key -> text. DefaultLocale must be among them.
+func Load(fsys fs.FS) (*Catalog, error) {
+	paths, err := fs.Glob(fsys, "locales/*.json")
+	if err != nil {
+		return nil, err
+	}
+	c := &Catalog{messages: make(map[string]map[string]string, len(paths))}
+	for _, p := range paths {
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return nil, err
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		locale := strings.TrimSuffix(path.Base(p), ".json")
+		if _, err := language.Parse(locale); err != nil {
+			return nil, fmt.Errorf("%s: invalid locale: %w", p, err)
+		}
+		c.messages[locale] = messages
+		c.locales = append(c.locales, locale)
+	}
+	if c.messages[DefaultLocale] == nil {
+		return nil, fmt.Errorf("no %s translations", DefaultLocale)
+	}
+
+	sort.Slice(c.locales, func(i, j int) bool {
+		if (c.locales[i] == DefaultLocale) != (c.locales[j] == DefaultLocale) {
+			return c.locales[i] == DefaultLocale
+		}
+		return c.locales[i] < c.locales[j]
+	})
+	tags := make([]language.Tag, len(c.locales))
+	for i, locale := range c.locales {
+		tags[i] = language.Make(locale)
+	}
+	c.matcher = language.NewMatcher(tags)
+	return c, nil
+}
+
+func mustLoad(fsys fs.FS) *Catalog {
+	c, err := Load(fsys)
+	if err != nil {
+		panic("i18n: " + err.Error())
+	}
+	return c
+}
+
+// Locales lists the locales with translations, DefaultLocale first
+func (c *Catalog) Locales() []string {
+	return append([]string(nil), c.locales...)
+}
+
+// Negotiate picks the locale that best serves an Accept-Language header:
+// "de-AT,de;q=0.9" gets "de". Headers without a supported language, or that
+// do not parse, get DefaultLocale.
+func (c *Catalog) Negotiate(acceptLanguage string) string {
+	if acceptLanguage == "" {
+		return DefaultLocale
+	}
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return DefaultLocale
+	}
+	_, index, confidence := c.matcher.Match(tags...)
+	if confidence == language.No {
+		return DefaultLocale
+	}
+	return c.locales[index]
+}
+
+// T returns the text for key in locale, else in DefaultLocale, else fallback
+func (c *Catalog) T(locale, key, fallback string) string {
+	if text, ok := c.messages[locale][key]; ok && text != "" {
+		return text
+	}
+	if text, ok := c.messages[DefaultLocale][key]; ok && text != "" {
+		return text
+	}
+	return fallback
+}
+
+// Sprintf formats the text T finds for key with args
+func (c *Catalog) Sprintf(locale, key, fallback string, args ...any) string {
+	return fmt.Sprintf(c.T(locale, key, fallback), args...)
+}
+
+type localeKey struct{}
+
+// WithLocale sets the locale display strings are translated into
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// LocaleFromContext returns the locale set by WithLocale, or DefaultLocale
+func LocaleFromContext(ctx context.Context) string {
+	if locale, ok := ctx.Value(localeKey{}).(string); ok && locale != "" {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// T translates key into the context's locale with the embedded catalog
+func T(ctx context.Context, key, fallback string) string {
+	return defaultCatalog.T(LocaleFromContext(ctx), key, fallback)
+}
+
+// Sprintf formats the translation of key into the context's locale with args
+func Sprintf(ctx context.Context, key, fallback string, args ...any) string {
+	return defaultCatalog.Sprintf(LocaleFromContext(ctx), key, fallback, args...)
+}
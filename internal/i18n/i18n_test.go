@@ -0,0 +1,96 @@
+package i18n
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestNegotiate(t *testing.T) {
+	c := Default()
+	tests := []struct {
+		header, want string
+	}{
+		{"", "en"},
+		{"de", "de"},
+		{"de-AT,de;q=0.9,en;q=0.8", "de"},
+		{"fr-CA", "fr"},
+		{"ja,es;q=0.5", "es"},
+		{"en-US,fr;q=0.3", "en"},
+		{"ja", "en"},
+		{"*", "en"},
+		{"not a header;;", "en"},
+	}
+	for _, tt := range tests {
+		if got := c.Negotiate(tt.header); got != tt.want {
+			t.Errorf("Negotiate(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestFallbacks(t *testing.T) {
+	c, err := Load(fstest.MapFS{
+		"locales/en.json": {Data: []byte(`{"a": "A", "b": "B"}`)},
+		"locales/de.json": {Data: []byte(`{"a": "A-de", "b": ""}`)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.T("de", "a", "x"); got != "A-de" {
+		t.Errorf("translated = %q", got)
+	}
+	if got := c.T("de", "b", "x"); got != "B" {
+		t.Errorf("empty translation = %q, want the English text", got)
+	}
+	if got := c.T("de", "c", "x"); got != "x" {
+		t.Errorf("unknown key = %q, want the fallback", got)
+	}
+	if got := c.T("xx", "a", "x"); got != "A" {
+		t.Errorf("unknown locale = %q, want the English text", got)
+	}
+
+	if _, err := Load(fstest.MapFS{"locales/de.json": {Data: []byte(`{}`)}}); err == nil {
+		t.Error("expected an error without English translations")
+	}
+}
+
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+	if got := T(ctx, "stat.kills", "kills"); got != "Kills" {
+		t.Errorf("default locale = %q", got)
+	}
+	if got := T(WithLocale(ctx, "de"), "stat.deaths", "deaths"); got != "Tode" {
+		t.Errorf("de = %q", got)
+	}
+}
+
+var verbs = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z]`)
+
+// Every locale translates every English key, and format strings (card.*,
+// digest.*) keep their verbs
+func TestLocalesComplete(t *testing.T) {
+	c := Default()
+	en := c.messages[DefaultLocale]
+	for _, locale := range c.Locales()[1:] {
+		for key, text := range en {
+			translated, ok := c.messages[locale][key]
+			if !ok {
+				t.Errorf("%s: missing %s", locale, key)
+				continue
+			}
+			if !strings.HasPrefix(key, "card.") && !strings.HasPrefix(key, "digest.") {
+				continue
+			}
+			if want, got := strings.Join(verbs.FindAllString(text, -1), " "), strings.Join(verbs.FindAllString(translated, -1), " "); got != want {
+				t.Errorf("%s: %s has verbs %q, want %q", locale, key, got, want)
+			}
+		}
+		for key := range c.messages[locale] {
+			if _, ok := en[key]; !ok {
+				t.Errorf("%s: %s has no English text", locale, key)
+			}
+		}
+	}
+}
@@ -0,0 +1,434 @@
+// Package jobs runs the API's periodic jobs on a cron-like schedule. A job
+// runs on one API instance at a time, under a db.Lock, and every run is
+// recorded in the job_runs table.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/db"
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+var (
+	jobRuns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "mohaa_job_runs_total",
+		Help: "Scheduled job runs on this instance, by job and status",
+	}, []string{"job", "status"})
+	jobDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mohaa_job_run_duration_seconds",
+		Help:    "Duration of scheduled job runs on this instance",
+		Buckets: prometheus.ExponentialBuckets(0.1, 4, 8),
+	}, []string{"job"})
+)
+
+var (
+	ErrUnknownJob = errors.New("jobs: unknown job")
+	ErrJobRunning = errors.New("jobs: job is already running")
+	ErrNotStarted = errors.New("jobs: scheduler not started")
+)
+
+const (
+	// DefaultTimeout bounds a run of a job without its own Timeout
+	DefaultTimeout = 30 * time.Minute
+
+	// Trigger values of models.JobRun
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+
+	slotPrefix = "jobs:slot:"
+
+	// maxIdle is the longest the scheduler sleeps, so a wall clock step is
+	// noticed within it
+	maxIdle = time.Minute
+)
+
+// Job is a periodic task
+type Job struct {
+	Name        string
+	Description string
+	// Schedule is when the job runs, see ParseSchedule
+	Schedule string
+	// Timeout bounds one run, and is how long its lock is held at most
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+}
+
+type entry struct {
+	job      Job
+	schedule Schedule
+	next     time.Time
+}
+
+// Scheduler runs registered jobs when their schedule comes due, or when
+// triggered. Every API instance runs the same jobs: each scheduled run time
+// is claimed by the first instance to reach it, and a job's lock keeps a
+// second run from starting, on any instance, while one is in progress.
+type Scheduler struct {
+	locks    db.LiveStateStore
+	pg       logic.PgPool
+	logger   *zap.SugaredLogger
+	instance string
+
+	mu      sync.Mutex
+	entries map[string]*entry
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	runs   sync.WaitGroup
+}
+
+func NewScheduler(locks db.LiveStateStore, pg logic.PgPool, logger *zap.Logger) *Scheduler {
+	return &Scheduler{
+		locks:    locks,
+		pg:       pg,
+		logger:   logger.Sugar(),
+		instance: db.InstanceID,
+		entries:  make(map[string]*entry),
+		done:     make(chan struct{}),
+	}
+}
+
+// Register adds a job. Its first run is the first schedule time after now.
+func (s *Scheduler) Register(job Job) error {
+	if job.Name == "" || job.Run == nil {
+		return fmt.Errorf("job needs a name and a run function")
+	}
+	schedule, err := ParseSchedule(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", job.Name, err)
+	}
+	if job.Timeout <= 0 {
+		job.Timeout = DefaultTimeout
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[job.Name]; ok {
+		return fmt.Errorf("job %s is already registered", job.Name)
+	}
+	s.entries[job.Name] = &entry{job: job, schedule: schedule, next: schedule.Next(time.Now())}
+	return nil
+}
+
+// Start runs jobs as they come due until Stop
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	ctx = s.ctx
+	s.mu.Unlock()
+
+	go func() {
+		defer close(s.done)
+		for {
+			timer := time.NewTimer(s.runDue(time.Now()))
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops scheduling and waits for running jobs, whose contexts are
+// cancelled, to return
+func (s *Scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+		<-s.done
+	}
+	s.runs.Wait()
+}
+
+// runDue starts the jobs due at now and returns how long to sleep until the
+// next one is
+func (s *Scheduler) runDue(now time.Time) time.Duration {
+	type due struct {
+		e    *entry
+		slot time.Time
+	}
+	var dues []due
+	wait := maxIdle
+
+	s.mu.Lock()
+	for _, e := range s.entries {
+		if e.next.IsZero() {
+			continue
+		}
+		if !now.Before(e.next) {
+			dues = append(dues, due{e, e.next})
+			e.next = e.schedule.Next(now)
+			if e.next.IsZero() {
+				continue
+			}
+		}
+		if d := e.next.Sub(now); d < wait {
+			wait = d
+		}
+	}
+	s.mu.Unlock()
+
+	for _, d := range dues {
+		s.runScheduled(d.e, d.slot)
+	}
+	return wait
+}
+
+// runScheduled claims a scheduled run time for this instance and starts the
+// job, unless another instance claimed it first or the job is still running
+func (s *Scheduler) runScheduled(e *entry, slot time.Time) {
+	ctx := s.ctx
+	key := fmt.Sprintf("%s%s:%d", slotPrefix, e.job.Name, slot.Unix())
+	claimed, err := s.locks.SetNX(ctx, key, s.instance, e.job.Timeout)
+	if err != nil {
+		s.logger.Warnw("Failed to claim job run", "job", e.job.Name, "error", err)
+		return
+	}
+	if !claimed {
+		return
+	}
+	if _, err := s.start(ctx, e, TriggerSchedule); err != nil {
+		if errors.Is(err, ErrJobRunning) {
+			s.logger.Infow("Skipped job run, previous run still in progress", "job", e.job.Name)
+			return
+		}
+		s.logger.Warnw("Failed to start job", "job", e.job.Name, "error", err)
+	}
+}
+
+// Trigger starts a run of a job now, out of schedule. The run continues in
+// the background; the returned record is as it started.
+func (s *Scheduler) Trigger(ctx context.Context, name string) (*models.JobRun, error) {
+	s.mu.Lock()
+	e, ok := s.entries[name]
+	started := s.ctx != nil
+	s.mu.Unlock()
+	if !ok {
+		return nil, ErrUnknownJob
+	}
+	if !started {
+		return nil, ErrNotStarted
+	}
+	return s.start(ctx, e, TriggerManual)
+}
+
+// start takes the job's lock, records the run and runs it in the background.
+// A run whose record could not be written still runs.
+func (s *Scheduler) start(ctx context.Context, e *entry, trigger string) (*models.JobRun, error) {
+	locked, err := s.lock(e.job.Name, e.job.Timeout).TryAcquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock job: %w", err)
+	}
+	if !locked {
+		return nil, ErrJobRunning
+	}
+
+	run := models.JobRun{
+		Job:       e.job.Name,
+		Trigger:   trigger,
+		Instance:  s.instance,
+		Status:    models.JobRunRunning,
+		StartedAt: time.Now().UTC(),
+	}
+	if err := s.pg.QueryRow(ctx, `
+		INSERT INTO job_runs (job, trigger, instance, status, started_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, run.Job, run.Trigger, run.Instance, string(run.Status), run.StartedAt).Scan(&run.ID); err != nil {
+		s.logger.Warnw("Failed to record job run", "job", run.Job, "error", err)
+	}
+
+	s.runs.Add(1)
+	go s.run(e, run)
+	return &run, nil
+}
+
+func (s *Scheduler) run(e *entry, run models.JobRun) {
+	defer s.runs.Done()
+	ctx, cancel := context.WithTimeout(s.ctx, e.job.Timeout)
+	defer cancel()
+
+	err := runJob(ctx, e.job)
+	finished := time.Now().UTC()
+	run.FinishedAt = &finished
+	run.DurationMs = finished.Sub(run.StartedAt).Milliseconds()
+	run.Status = models.JobRunSucceeded
+	if err != nil {
+		run.Status = models.JobRunFailed
+		run.Error = err.Error()
+	}
+	jobRuns.WithLabelValues(run.Job, string(run.Status)).Inc()
+	jobDuration.WithLabelValues(run.Job).Observe(finished.Sub(run.StartedAt).Seconds())
+
+	// The job's context may be done; the bookkeeping still has to happen
+	bg, cancelBg := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelBg()
+	if run.ID != 0 {
+		if _, err := s.pg.Exec(bg, `
+			UPDATE job_runs SET status = $2, error = $3, finished_at = $4 WHERE id = $1
+		`, run.ID, string(run.Status), run.Error, finished); err != nil {
+			s.logger.Warnw("Failed to record job run", "job", run.Job, "error", err)
+		}
+	}
+	if err := s.lock(e.job.Name, e.job.Timeout).Release(bg); err != nil {
+		s.logger.Warnw("Failed to release job lock", "job", run.Job, "error", err)
+	}
+
+	if err != nil {
+		s.logger.Errorw("Job failed", "job", run.Job, "trigger", run.Trigger, "duration_ms", run.DurationMs, "error", err)
+		return
+	}
+	s.logger.Debugw("Job finished", "job", run.Job, "trigger", run.Trigger, "duration_ms", run.DurationMs)
+}
+
+// runJob runs the job, turning a panic into an error
+func runJob(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return job.Run(ctx)
+}
+
+// lock is a job's lock, held for at most its timeout
+func (s *Scheduler) lock(name string, timeout time.Duration) *db.Lock {
+	return db.NewLock(s.locks, "job:"+name, s.instance, timeout)
+}
+
+// Jobs lists the registered jobs by name, with their latest run and the
+// instance running them now
+func (s *Scheduler) Jobs(ctx context.Context) ([]models.JobInfo, error) {
+	s.mu.Lock()
+	infos := make([]models.JobInfo, 0, len(s.entries))
+	for _, e := range s.entries {
+		info := models.JobInfo{
+			Name:        e.job.Name,
+			Description: e.job.Description,
+			Schedule:    e.job.Schedule,
+			Timeout:     e.job.Timeout.String(),
+		}
+		if !e.next.IsZero() {
+			next := e.next
+			info.NextRun = &next
+		}
+		infos = append(infos, info)
+	}
+	s.mu.Unlock()
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+
+	names := make([]string, len(infos))
+	for i := range infos {
+		names[i] = infos[i].Name
+		holder, err := s.lock(infos[i].Name, 0).Holder(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read job lock: %w", err)
+		}
+		infos[i].LockedBy = holder
+	}
+
+	rows, err := s.pg.Query(ctx, `
+		SELECT DISTINCT ON (job) id, job, trigger, instance, status, error, started_at, finished_at
+		FROM job_runs
+		WHERE job = ANY($1)
+		ORDER BY job, started_at DESC
+	`, names)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query job runs: %w", err)
+	}
+	last, err := scanRuns(rows)
+	if err != nil {
+		return nil, err
+	}
+	byJob := make(map[string]*models.JobRun, len(last))
+	for i := range last {
+		byJob[last[i].Job] = &last[i]
+	}
+	for i := range infos {
+		infos[i].LastRun = byJob[infos[i].Name]
+	}
+	return infos, nil
+}
+
+// Runs returns the latest runs of a job, newest first
+func (s *Scheduler) Runs(ctx context.Context, name string, limit int) ([]models.JobRun, error) {
+	s.mu.Lock()
+	_, ok := s.entries[name]
+	s.mu.Unlock()
+	if !ok {
+		return nil, ErrUnknownJob
+	}
+
+	rows, err := s.pg.Query(ctx, `
+		SELECT id, job, trigger, instance, status, error, started_at, finished_at
+		FROM job_runs
+		WHERE job = $1
+		ORDER BY started_at DESC
+		LIMIT $2
+	`, name, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query job runs: %w", err)
+	}
+	return scanRuns(rows)
+}
+
+func scanRuns(rows pgx.Rows) ([]models.JobRun, error) {
+	defer rows.Close()
+	runs := make([]models.JobRun, 0)
+	for rows.Next() {
+		var r models.JobRun
+		var status string
+		if err := rows.Scan(&r.ID, &r.Job, &r.Trigger, &r.Instance, &status, &r.Error, &r.StartedAt, &r.FinishedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan job run: %w", err)
+		}
+		r.Status = models.JobRunStatus(status)
+		if r.FinishedAt != nil {
+			r.DurationMs = r.FinishedAt.Sub(r.StartedAt).Milliseconds()
+		}
+		runs = append(runs, r)
+	}
+	return runs, rows.Err()
+}
+
+// PruneRuns marks runs still "running" past their job's timeout as failed,
+// as the instance running them stopped before recording the end, and deletes
+// runs that started more than keep ago
+func (s *Scheduler) PruneRuns(ctx context.Context, keep time.Duration) error {
+	s.mu.Lock()
+	names := make([]string, 0, len(s.entries))
+	timeouts := make([]float64, 0, len(s.entries))
+	for _, e := range s.entries {
+		names = append(names, e.job.Name)
+		timeouts = append(timeouts, e.job.Timeout.Seconds())
+	}
+	s.mu.Unlock()
+
+	if _, err := s.pg.Exec(ctx, `
+		UPDATE job_runs r SET status = 'failed', error = 'abandoned: instance stopped during the run', finished_at = NOW()
+		FROM unnest($1::text[], $2::float8[]) AS t(job, timeout)
+		WHERE r.job = t.job AND r.status = 'running'
+			AND r.started_at < NOW() - make_interval(secs => t.timeout)
+	`, names, timeouts); err != nil {
+		return fmt.Errorf("failed to mark abandoned job runs: %w", err)
+	}
+	if _, err := s.pg.Exec(ctx, `
+		DELETE FROM job_runs WHERE started_at < $1
+	`, time.Now().Add(-keep)); err != nil {
+		return fmt.Errorf("failed to delete old job runs: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+// Package logging builds the process logger with a base level, per-component
+// level overrides and sampling for hot-path components, all of which can be
+// changed while the server runs.
+package logging
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Settings is the runtime-adjustable part of the logging setup
+type Settings struct {
+	// Level applies to every component without an override
+	Level string `json:"level"`
+	// Components overrides the level per component, e.g. {"worker": "warn"}
+	Components map[string]string `json:"components,omitempty"`
+	// Sampled components log the first Initial entries of each message per
+	// second, then every Thereafter-th. Warnings and errors are never sampled.
+	Sampled []string `json:"sampled,omitempty"`
+}
+
+// Config sets up the logger at startup
+type Config struct {
+	Development bool
+	Settings    Settings
+	// Sampling budget per message and second
+	Initial    int
+	Thereafter int
+}
+
+// state is an immutable snapshot swapped in by Apply
+type state struct {
+	base       zapcore.Level
+	components map[string]zapcore.Level
+	sampled    map[string]bool
+}
+
+// Levels hands out component loggers and holds their levels
+type Levels struct {
+	base    *zap.Logger  // built at debug level; componentCore filters
+	sampled zapcore.Core // base core behind a sampler shared by all component loggers
+	state   atomic.Pointer[state]
+}
+
+// New builds the root logger and the Levels controlling it
+func New(cfg Config) (*zap.Logger, *Levels, error) {
+	zcfg := zap.NewProductionConfig()
+	if cfg.Development {
+		zcfg = zap.NewDevelopmentConfig()
+	}
+	zcfg.Level = zap.NewAtomicLevelAt(zapcore.DebugLevel)
+	zcfg.Sampling = nil
+
+	base, err := zcfg.Build()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	l := newLevels(base, cfg.Initial, cfg.Thereafter)
+	if cfg.Settings.Level == "" {
+		cfg.Settings.Level = "info"
+		if cfg.Development {
+			cfg.Settings.Level = "debug"
+		}
+	}
+	if err := l.Apply(cfg.Settings); err != nil {
+		return nil, nil, err
+	}
+	return l.Logger(""), l, nil
+}
+
+func newLevels(base *zap.Logger, initial, thereafter int) *Levels {
+	if initial <= 0 {
+		initial = 100
+	}
+	if thereafter <= 0 {
+		thereafter = 100
+	}
+	return &Levels{
+		base:    base,
+		sampled: zapcore.NewSamplerWithOptions(base.Core(), time.Second, initial, thereafter),
+	}
+}
+
+// Logger returns the logger for a component. Its entries carry the component
+// as logger name and follow that component's level and sampling.
+func (l *Levels) Logger(component string) *zap.Logger {
+	logger := l.base.WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		return &componentCore{plain: c, sampled: l.sampled, component: component, levels: l}
+	}))
+	if component != "" {
+		logger = logger.Named(component)
+	}
+	return logger
+}
+
+// Apply replaces the levels and sampled components. On error nothing changes.
+func (l *Levels) Apply(s Settings) error {
+	next := &state{
+		components: make(map[string]zapcore.Level, len(s.Components)),
+		sampled:    make(map[string]bool, len(s.Sampled)),
+	}
+	if err := next.base.UnmarshalText([]byte(s.Level)); err != nil {
+		return fmt.Errorf("log level %q: %w", s.Level, err)
+	}
+	for component, level := range s.Components {
+		var lvl zapcore.Level
+		if err := lvl.UnmarshalText([]byte(level)); err != nil {
+			return fmt.Errorf("log level %q for %s: %w", level, component, err)
+		}
+		next.components[component] = lvl
+	}
+	for _, component := range s.Sampled {
+		next.sampled[component] = true
+	}
+	l.state.Store(next)
+	return nil
+}
+
+// Settings returns the active levels and sampled components
+func (l *Levels) Settings() Settings {
+	cur := l.state.Load()
+	s := Settings{
+		Level:      cur.base.String(),
+		Components: make(map[string]string, len(cur.components)),
+		Sampled:    make([]string, 0, len(cur.sampled)),
+	}
+	for component, level := range cur.components {
+		s.Components[component] = level.String()
+	}
+	for component := range cur.sampled {
+		s.Sampled = append(s.Sampled, component)
+	}
+	sort.Strings(s.Sampled)
+	return s
+}
+
+func (l *Levels) enabled(component string, level zapcore.Level) bool {
+	cur := l.state.Load()
+	if lvl, ok := cur.components[component]; ok {
+		return level >= lvl
+	}
+	return level >= cur.base
+}
+
+func (l *Levels) isSampled(component string) bool {
+	return l.state.Load().sampled[component]
+}
+
+// ParseComponentLevels parses "worker=warn,ingest=debug"
+func ParseComponentLevels(spec string) (map[string]string, error) {
+	levels := make(map[string]string)
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		component, level, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("component level %q: want component=level", pair)
+		}
+		levels[strings.TrimSpace(component)] = strings.TrimSpace(level)
+	}
+	return levels, nil
+}
+
+// ParseList splits a comma-separated list of component names
+func ParseList(spec string) []string {
+	var out []string
+	for _, name := range strings.Split(spec, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// componentCore filters entries by its component's current level and routes
+// them through the sampler while the component is sampled
+type componentCore struct {
+	plain     zapcore.Core
+	sampled   zapcore.Core
+	component string
+	levels    *Levels
+}
+
+func (c *componentCore) Enabled(level zapcore.Level) bool {
+	return c.levels.enabled(c.component, level)
+}
+
+func (c *componentCore) With(fields []zapcore.Field) zapcore.Core {
+	return &componentCore{
+		plain:     c.plain.With(fields),
+		sampled:   c.sampled.With(fields),
+		component: c.component,
+		levels:    c.levels,
+	}
+}
+
+func (c *componentCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(entry.Level) {
+		return ce
+	}
+	if entry.Level < zapcore.WarnLevel && c.levels.isSampled(c.component) {
+		return c.sampled.Check(entry, ce)
+	}
+	return c.plain.Check(entry, ce)
+}
+
+func (c *componentCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.plain.Write(entry, fields)
+}
+
+func (c *componentCore) Sync() error {
+	return c.plain.Sync()
+}
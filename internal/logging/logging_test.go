@@ -0,0 +1,105 @@
+package logging
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func newObserved(t *testing.T, s Settings) (*Levels, *observer.ObservedLogs) {
+	t.Helper()
+	core, logs := observer.New(zapcore.DebugLevel)
+	l := newLevels(zap.New(core), 2, 10)
+	if err := l.Apply(s); err != nil {
+		t.Fatal(err)
+	}
+	return l, logs
+}
+
+func TestComponentLevels(t *testing.T) {
+	l, logs := newObserved(t, Settings{Level: "info", Components: map[string]string{"worker": "warn", "ingest": "debug"}})
+
+	l.Logger("").Debug("root debug")
+	l.Logger("").Info("root info")
+	l.Logger("worker").Info("worker info")
+	l.Logger("worker").Warn("worker warn")
+	l.Logger("ingest").Debug("ingest debug")
+
+	var got []string
+	for _, e := range logs.All() {
+		got = append(got, e.Message)
+	}
+	want := []string{"root info", "worker warn", "ingest debug"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entry %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+	if name := logs.All()[1].LoggerName; name != "worker" {
+		t.Errorf("LoggerName = %q, want worker", name)
+	}
+}
+
+func TestApplyAtRuntime(t *testing.T) {
+	l, logs := newObserved(t, Settings{Level: "info"})
+	worker := l.Logger("worker").With(zap.String("id", "1"))
+
+	worker.Debug("hidden")
+	if err := l.Apply(Settings{Level: "info", Components: map[string]string{"worker": "debug"}}); err != nil {
+		t.Fatal(err)
+	}
+	worker.Debug("shown")
+
+	if n := logs.FilterMessage("hidden").Len(); n != 0 {
+		t.Errorf("debug entry logged before the level change")
+	}
+	if n := logs.FilterMessage("shown").Len(); n != 1 {
+		t.Errorf("debug entry not logged after the level change")
+	}
+
+	if err := l.Apply(Settings{Level: "loud"}); err == nil {
+		t.Error("expected error for unknown level")
+	}
+	if got := l.Settings().Components["worker"]; got != "debug" {
+		t.Errorf("failed Apply changed settings: worker = %q", got)
+	}
+}
+
+func TestSampling(t *testing.T) {
+	l, logs := newObserved(t, Settings{Level: "info", Sampled: []string{"worker"}})
+
+	for i := 0; i < 50; i++ {
+		l.Logger("worker").Info("received job")
+		l.Logger("worker").Error("insert failed")
+		l.Logger("api").Info("request")
+	}
+
+	// First 2, then every 10th of the remaining 48
+	if n := logs.FilterMessage("received job").Len(); n != 6 {
+		t.Errorf("sampled info entries = %d, want 6", n)
+	}
+	if n := logs.FilterMessage("insert failed").Len(); n != 50 {
+		t.Errorf("errors must not be sampled, got %d", n)
+	}
+	if n := logs.FilterMessage("request").Len(); n != 50 {
+		t.Errorf("unsampled component logged %d, want 50", n)
+	}
+}
+
+func TestParseComponentLevels(t *testing.T) {
+	got, err := ParseComponentLevels(" worker=warn, ingest = debug ,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["worker"] != "warn" || got["ingest"] != "debug" || len(got) != 2 {
+		t.Errorf("got %v", got)
+	}
+	if _, err := ParseComponentLevels("worker"); err == nil {
+		t.Error("expected error for missing level")
+	}
+}
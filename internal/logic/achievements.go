@@ -54,8 +54,8 @@ func (s *achievementsService) getMatchAchievements(ctx context.Context, matchID,
 		SELECT 
 			countIf(event_type IN ('player_kill', 'bot_killed') AND actor_id = ?) as kills,
 			countIf(event_type IN ('player_kill', 'bot_killed') AND target_id = ?) as deaths,
-			countIf(event_type = 'weapon_fire' AND actor_id = ?) as shots,
-			countIf(event_type = 'weapon_hit' AND actor_id = ?) as hits,
+			sumIf(sample_weight, event_type = 'weapon_fire' AND actor_id = ?) as shots,
+			sumIf(sample_weight, event_type = 'weapon_hit' AND actor_id = ?) as hits,
 			countIf(event_type = 'match_outcome' AND match_outcome = 1 AND actor_id = ?) as wins
 		FROM raw_events 
 		WHERE match_id = ? AND (actor_id = ? OR target_id = ?)
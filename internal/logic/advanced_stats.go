@@ -29,8 +29,8 @@ func (s *advancedStatsService) GetPeakPerformance(ctx context.Context, guid stri
 			toInt64(countIf(event_type = 'player_kill' AND actor_id = ?)) as player_kills,
 			toInt64(countIf(event_type = 'bot_killed' AND actor_id = ?)) as bot_kills,
 			toInt64(countIf((event_type IN ('player_kill', 'bot_killed') OR event_type = 'death') AND target_id = ?)) as deaths,
-			toInt64(countIf(event_type = 'weapon_fire' AND actor_id = ?)) as shots,
-			toInt64(countIf(event_type = 'weapon_hit' AND actor_id = ?)) as hits,
+			toInt64(sumIf(sample_weight, event_type = 'weapon_fire' AND actor_id = ?)) as shots,
+			toInt64(sumIf(sample_weight, event_type = 'weapon_hit' AND actor_id = ?)) as hits,
 			toInt64(countIf(event_type = 'team_win' AND actor_id = ?)) as wins
 		FROM raw_events
 		WHERE actor_id = ? OR target_id = ?
@@ -99,8 +99,8 @@ func (s *advancedStatsService) GetPeakPerformance(ctx context.Context, guid stri
 			toInt64(countIf(event_type = 'player_kill' AND actor_id = ?)) as player_kills,
 			toInt64(countIf(event_type = 'bot_killed' AND actor_id = ?)) as bot_kills,
 			toInt64(countIf((event_type IN ('player_kill', 'bot_killed') OR event_type = 'death') AND target_id = ?)) as deaths,
-			toInt64(countIf(event_type = 'weapon_fire' AND actor_id = ?)) as shots,
-			toInt64(countIf(event_type = 'weapon_hit' AND actor_id = ?)) as hits
+			toInt64(sumIf(sample_weight, event_type = 'weapon_fire' AND actor_id = ?)) as shots,
+			toInt64(sumIf(sample_weight, event_type = 'weapon_hit' AND actor_id = ?)) as hits
 		FROM raw_events
 		WHERE actor_id = ? OR target_id = ?
 		GROUP BY dow
@@ -352,7 +352,7 @@ func (s *advancedStatsService) GetDrillDown(ctx context.Context, guid string, st
 	query = fmt.Sprintf(`
 		SELECT 
 			%s as dim_value,
-			toInt64(count()) as count
+			toInt64(sum(sample_weight)) as count
 		FROM raw_events
 		WHERE event_type = ? AND %s AND %s != ''
 		GROUP BY dim_value
@@ -603,10 +603,10 @@ func (s *advancedStatsService) GetComboMetrics(ctx context.Context, guid string)
 	var jumps, kills float64
 	s.ch.QueryRow(ctx, `
 		SELECT 
-			countIf(event_type = 'jump'),
+			(SELECT sumIf(sample_weight, event_type = 'jump') FROM movement_events_all WHERE actor_id = ?),
 			countIf(event_type IN ('player_kill', 'bot_killed'))
 		FROM raw_events WHERE actor_id = ?
-	`, guid).Scan(&jumps, &kills)
+	`, guid, guid).Scan(&jumps, &kills)
 
 	if kills > 0 {
 		combo.MovementCombat.BunnyHopEfficiency = min(100, (jumps/kills)*20) // Arbitrary scaling
@@ -623,15 +623,17 @@ func (s *advancedStatsService) GetVehicleStats(ctx context.Context, guid string)
 	err := s.ch.QueryRow(ctx, `
 		SELECT 
 			toInt64(countIf(event_type = 'vehicle_enter' AND actor_id = ?)) as uses,
-			toInt64(countIf(event_type = 'player_roadkill' AND actor_id = ?)) as kills,
-			toInt64(countIf(event_type = 'vehicle_death' AND actor_id = ?)) as deaths,
-			sumIf(JSONExtractFloat(raw_json, 'driven', 'Float64'), event_type = 'distance' AND actor_id = ?) / 100000.0 as driven_km
+			toInt64(countIf(`+vehicleKillSQL+` AND actor_id = ?)) as kills,
+			toInt64(countIf(`+vehicleKillSQL+` AND actor_id = ? AND NOT `+driverSeatSQL+`)) as passenger_kills,
+			toInt64(countIf(event_type IN (`+vehicleKillTypes+`) AND target_id = ? AND target_vehicle != '')) as deaths,
+			(SELECT sumIf(driven * sample_weight, event_type = 'distance') FROM movement_events_all WHERE actor_id = ?) / 100000.0 as driven_km
 		FROM raw_events
-		WHERE actor_id = ?
-	`, guid, guid, guid, guid, guid).Scan(&stats.VehicleUses, &stats.VehicleKills, &stats.VehicleDeaths, &stats.TotalDriven)
+		WHERE actor_id = ? OR target_id = ?
+	`, guid, guid, guid, guid, guid, guid, guid).Scan(&stats.VehicleUses, &stats.VehicleKills, &stats.PassengerKills, &stats.VehicleDeaths, &stats.TotalDriven)
 	if err != nil {
 		return nil, err
 	}
+	stats.DriverKills = stats.VehicleKills - stats.PassengerKills
 
 	// Turret stats
 	s.ch.QueryRow(ctx, `
@@ -643,22 +645,48 @@ func (s *advancedStatsService) GetVehicleStats(ctx context.Context, guid string)
 		WHERE actor_id = ? OR target_id = ?
 	`, guid, guid, guid, guid, guid).Scan(&stats.TurretStats.TurretUses, &stats.TurretStats.TurretKills, &stats.TurretStats.TurretDeaths)
 
-	// Vehicle breakdown by type
+	// Turret uptime and kills per turret
+	stats.TurretStats.Turrets = []models.TurretUsage{}
+	if turrets, err := s.playerTurrets(ctx, guid); err == nil {
+		stats.TurretStats.Turrets = turrets
+		for _, t := range turrets {
+			stats.TurretStats.UptimeSeconds += t.UptimeSeconds
+		}
+	}
+
+	// Vehicle breakdown by type; deaths are counted under the victim's vehicle
 	rows, err := s.ch.Query(ctx, `
-		SELECT 
-			JSONExtractString(raw_json, 'vehicle') as vehicle,
-			count() as uses
-		FROM raw_events
-		WHERE event_type = 'vehicle_enter' AND actor_id = ? AND JSONExtractString(raw_json, 'vehicle') != ''
-		GROUP BY vehicle
-		ORDER BY uses DESC
+		SELECT
+			v,
+			toInt64(sum(uses)) AS uses,
+			toInt64(sum(kills)) AS kills,
+			toInt64(sum(passenger_kills)) AS passenger_kills,
+			toInt64(sum(deaths)) AS deaths
+		FROM (
+			SELECT
+				vehicle AS v,
+				countIf(event_type = 'vehicle_enter') AS uses,
+				countIf(`+vehicleKillSQL+`) AS kills,
+				countIf(`+vehicleKillSQL+` AND NOT `+driverSeatSQL+`) AS passenger_kills,
+				0 AS deaths
+			FROM raw_events
+			WHERE actor_id = ? AND vehicle != ''
+			GROUP BY v
+			UNION ALL
+			SELECT target_vehicle AS v, 0, 0, 0, count()
+			FROM raw_events
+			WHERE target_id = ? AND target_vehicle != '' AND event_type IN (`+vehicleKillTypes+`)
+			GROUP BY v
+		)
+		GROUP BY v
+		ORDER BY uses DESC, kills DESC
 		LIMIT 10
-	`, guid)
+	`, guid, guid)
 	if err == nil {
 		defer rows.Close()
 		for rows.Next() {
 			var vt models.VehicleType
-			if err := rows.Scan(&vt.VehicleName, &vt.Uses); err != nil {
+			if err := rows.Scan(&vt.VehicleName, &vt.Uses, &vt.Kills, &vt.PassengerKills, &vt.Deaths); err != nil {
 				continue
 			}
 			stats.VehicleTypes = append(stats.VehicleTypes, vt)
@@ -672,15 +700,29 @@ func (s *advancedStatsService) GetVehicleStats(ctx context.Context, guid string)
 func (s *advancedStatsService) GetGameFlowStats(ctx context.Context, guid string) (*models.GameFlowStats, error) {
 	stats := &models.GameFlowStats{}
 
-	// Basic round stats
+	// Round stats from the worker's per-player round_outcome events, which
+	// carry the team scores after the round: a won round was a comeback when
+	// the team's score before it (one less) was below the opponent's
 	err := s.ch.QueryRow(ctx, `
 		SELECT 
-			toInt64(countIf(event_type = 'round_end' AND actor_id = ?)) as rounds,
-			toInt64(countIf(event_type = 'team_win' AND actor_id = ?)) as wins,
-			toInt64(countIf(event_type = 'objective_update' AND actor_id = ?)) as objectives
+			toInt64(countIf(event_type = 'round_outcome')) as rounds,
+			toInt64(countIf(event_type = 'round_outcome' AND match_outcome = 1)) as wins,
+			toInt64(countIf(event_type = 'round_outcome' AND round_number = 1)) as opening_rounds,
+			toInt64(countIf(event_type = 'round_outcome' AND round_number = 1 AND match_outcome = 1)) as opening_wins,
+			toInt64(countIf(event_type = 'round_outcome' AND match_outcome = 1 AND
+				if(actor_team = 'allies', JSONExtractInt(raw_json, 'allies_score'), JSONExtractInt(raw_json, 'axis_score')) - 1 <
+				if(actor_team = 'allies', JSONExtractInt(raw_json, 'axis_score'), JSONExtractInt(raw_json, 'allies_score')))) as comebacks,
+			toInt64(countIf(event_type = 'round_outcome' AND match_outcome = 1 AND actor_team = 'allies')) as allies_wins,
+			toInt64(countIf(event_type = 'round_outcome' AND match_outcome = 1 AND actor_team = 'axis')) as axis_wins,
+			toInt64(countIf(event_type = 'objective_update')) as objectives
 		FROM raw_events
-		WHERE actor_id = ?
-	`, guid, guid, guid, guid).Scan(&stats.RoundsPlayed, &stats.RoundsWon, &stats.ObjectivesTotal)
+		WHERE actor_id = ? AND event_type IN ('round_outcome', 'objective_update')
+	`, guid).Scan(
+		&stats.RoundsPlayed, &stats.RoundsWon,
+		&stats.OpeningRoundsPlayed, &stats.OpeningRoundsWon, &stats.ComebackRounds,
+		&stats.TeamStats.AlliesWins, &stats.TeamStats.AxisWins,
+		&stats.ObjectivesTotal,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -689,6 +731,9 @@ func (s *advancedStatsService) GetGameFlowStats(ctx context.Context, guid string
 	if stats.RoundsPlayed > 0 {
 		stats.RoundWinRate = (float64(stats.RoundsWon) / float64(stats.RoundsPlayed)) * 100
 	}
+	if stats.OpeningRoundsPlayed > 0 {
+		stats.OpeningRoundWinRate = (float64(stats.OpeningRoundsWon) / float64(stats.OpeningRoundsPlayed)) * 100
+	}
 
 	// Objectives by type
 	rows, err := s.ch.Query(ctx, `
@@ -729,7 +774,7 @@ func (s *advancedStatsService) GetWorldStats(ctx context.Context, guid string) (
 
 	err := s.ch.QueryRow(ctx, `
 		SELECT 
-			toInt64(countIf(event_type = 'ladder_mount')) as ladder_mounts,
+			toInt64((SELECT sumIf(sample_weight, event_type = 'ladder_mount') FROM movement_events_all WHERE actor_id = ?)) as ladder_mounts,
 			sumIf(JSONExtractFloat(raw_json, 'height_climbed', 'Float64'), event_type = 'ladder_dismount') as ladder_dist,
 			toInt64(countIf(event_type = 'door_open')) as doors_opened,
 			toInt64(countIf(event_type = 'door_close')) as doors_closed,
@@ -741,7 +786,7 @@ func (s *advancedStatsService) GetWorldStats(ctx context.Context, guid string) (
 			toInt64(countIf(event_type = 'death' AND JSONExtractString(raw_json, 'mod') = 'MOD_FALLING')) as fall_deaths
 		FROM raw_events
 		WHERE actor_id = ?
-	`, guid).Scan(
+	`, guid, guid).Scan(
 		&stats.LadderMounts, &stats.LadderDistance,
 		&stats.DoorsOpened, &stats.DoorsClosed,
 		&stats.ItemsPickedUp, &stats.ItemsDropped,
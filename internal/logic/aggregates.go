@@ -0,0 +1,574 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// playerAggregateTables are the MV-fed player tables that can be checked and
+// rebuilt. Both share the player_stats_daily column layout.
+var playerAggregateTables = map[string]bool{
+	"player_stats_daily":        true,
+	"player_server_stats_daily": true,
+}
+
+type aggregateService struct {
+	ch driver.Conn
+	pg PgPool
+}
+
+// NewAggregateService returns the aggregate service. pg is used to look up
+// banned players; with nil, rebuilds exclude only voided matches.
+func NewAggregateService(ch driver.Conn, pg PgPool) AggregateService {
+	return &aggregateService{ch: ch, pg: pg}
+}
+
+// IsPlayerAggregateTable reports whether table can be passed to the
+// aggregate check/rebuild methods
+func IsPlayerAggregateTable(table string) bool {
+	return playerAggregateTables[table]
+}
+
+// PlayerAggregateTables lists the tables IsPlayerAggregateTable accepts
+func PlayerAggregateTables() []string {
+	tables := make([]string, 0, len(playerAggregateTables))
+	for table := range playerAggregateTables {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+type aggregateKey struct {
+	playerID string
+	day      time.Time
+}
+
+type aggregateCounts struct {
+	kills, deaths, headshots uint64
+}
+
+// CheckConsistency samples players active in the last `days` closed days and
+// compares their kills/deaths/headshots in the aggregate table against a
+// recomputation from raw_events. Rows whose relative drift exceeds tolerance
+// (0.01 = 1%) are reported.
+func (s *aggregateService) CheckConsistency(ctx context.Context, table string, days, sampleSize int, tolerance float64) (*models.AggregateCheckReport, error) {
+	if !IsPlayerAggregateTable(table) {
+		return nil, fmt.Errorf("unknown aggregate table %q", table)
+	}
+	if days <= 0 {
+		days = 7
+	}
+	if sampleSize <= 0 {
+		sampleSize = 200
+	}
+
+	report := &models.AggregateCheckReport{
+		Table:     table,
+		CheckedAt: time.Now().UTC(),
+		Days:      days,
+		Tolerance: tolerance,
+		Drifted:   []models.AggregateDrift{},
+	}
+
+	// Only closed days are compared so in-flight inserts can't show up as drift
+	sampleQuery := fmt.Sprintf(`
+		SELECT player_id
+		FROM mohaa_stats.%s
+		WHERE day >= toStartOfDay(now()) - INTERVAL ? DAY AND day < toStartOfDay(now())
+		  AND player_id != ''
+		GROUP BY player_id
+		ORDER BY rand()
+		LIMIT ?
+	`, table)
+
+	rows, err := s.ch.Query(ctx, sampleQuery, days, sampleSize)
+	if err != nil {
+		return nil, fmt.Errorf("sample players: %w", err)
+	}
+	var players []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			continue
+		}
+		players = append(players, id)
+	}
+	rows.Close()
+
+	report.PlayersSampled = len(players)
+	if len(players) == 0 {
+		report.Healthy = true
+		return report, nil
+	}
+
+	aggQuery := fmt.Sprintf(`
+		SELECT player_id, day, sum(kills), sum(deaths), sum(headshots)
+		FROM mohaa_stats.%s
+		WHERE player_id IN ?
+		  AND day >= toStartOfDay(now()) - INTERVAL ? DAY AND day < toStartOfDay(now())
+		GROUP BY player_id, day
+	`, table)
+	aggregated, err := s.scanCounts(ctx, aggQuery, players, days)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate side: %w", err)
+	}
+
+	// Same expressions and round filter as mv_feed_actor_stats /
+	// mv_feed_target_stats (migration 011)
+	rawQuery := `
+		SELECT player_id, day, sum(k), sum(d), sum(h)
+		FROM (
+			SELECT
+				actor_id AS player_id,
+				toStartOfDay(timestamp) AS day,
+				countIf(event_type = 'player_kill') AS k,
+				toUInt64(0) AS d,
+				countIf(event_type = 'player_kill' AND hitloc IN ('head', 'helmet')) AS h
+			FROM mohaa_stats.raw_events
+			WHERE actor_id IN ? AND round_phase = 'round'
+			  AND timestamp >= toStartOfDay(now()) - INTERVAL ? DAY AND timestamp < toStartOfDay(now())
+			GROUP BY player_id, day
+
+			UNION ALL
+
+			SELECT
+				target_id AS player_id,
+				toStartOfDay(timestamp) AS day,
+				toUInt64(0) AS k,
+				count() AS d,
+				toUInt64(0) AS h
+			FROM mohaa_stats.raw_events
+			WHERE event_type = 'player_kill' AND target_id IN ? AND round_phase = 'round'
+			  AND timestamp >= toStartOfDay(now()) - INTERVAL ? DAY AND timestamp < toStartOfDay(now())
+			GROUP BY player_id, day
+		)
+		GROUP BY player_id, day
+	`
+	raw, err := s.scanCounts(ctx, rawQuery, players, days, players, days)
+	if err != nil {
+		return nil, fmt.Errorf("raw side: %w", err)
+	}
+
+	// Walk the union of keys so rows missing on either side are caught too
+	keys := make(map[aggregateKey]struct{}, len(raw))
+	for k := range raw {
+		keys[k] = struct{}{}
+	}
+	for k := range aggregated {
+		keys[k] = struct{}{}
+	}
+
+	for k := range keys {
+		report.RowsCompared++
+		a, r := aggregated[k], raw[k]
+		for _, c := range []struct {
+			stat     string
+			agg, raw uint64
+		}{
+			{"kills", a.kills, r.kills},
+			{"deaths", a.deaths, r.deaths},
+			{"headshots", a.headshots, r.headshots},
+		} {
+			if drift := aggregateDriftPct(c.agg, c.raw); drift > tolerance {
+				report.Drifted = append(report.Drifted, models.AggregateDrift{
+					PlayerID:  k.playerID,
+					Day:       k.day,
+					Stat:      c.stat,
+					Aggregate: c.agg,
+					Raw:       c.raw,
+					DriftPct:  drift * 100,
+				})
+			}
+		}
+	}
+
+	report.Healthy = len(report.Drifted) == 0
+	return report, nil
+}
+
+func (s *aggregateService) scanCounts(ctx context.Context, query string, args ...any) (map[aggregateKey]aggregateCounts, error) {
+	rows, err := s.ch.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[aggregateKey]aggregateCounts)
+	for rows.Next() {
+		var k aggregateKey
+		var c aggregateCounts
+		if err := rows.Scan(&k.playerID, &k.day, &c.kills, &c.deaths, &c.headshots); err != nil {
+			return nil, err
+		}
+		out[k] = c
+	}
+	return out, rows.Err()
+}
+
+// aggregateDriftPct returns the relative difference between the aggregate and
+// raw counts as a fraction of the larger value
+func aggregateDriftPct(agg, raw uint64) float64 {
+	if agg == raw {
+		return 0
+	}
+	hi := math.Max(float64(agg), float64(raw))
+	return math.Abs(float64(agg)-float64(raw)) / hi
+}
+
+// AggregateExclusions are left out of every aggregate rebuild: matches voided
+// by an admin and players with an active ban
+type AggregateExclusions struct {
+	Matches []string
+	Players []string
+}
+
+// Exclusions reads the voided matches and banned players rebuilds leave out
+func (s *aggregateService) Exclusions(ctx context.Context) (*AggregateExclusions, error) {
+	matches, err := s.voidedMatches(ctx)
+	if err != nil {
+		return nil, err
+	}
+	players, err := s.bannedPlayers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &AggregateExclusions{Matches: matches, Players: players}, nil
+}
+
+// Rebuild truncates an aggregate table and repopulates it by replaying the
+// SELECT of every materialized view that feeds it over raw_events, leaving out
+// voided matches and banned players. The view definitions are read back from
+// system.tables so a rebuild always matches the schema that is actually
+// deployed. Ingest should be paused while this runs, otherwise events inserted
+// mid-rebuild may be counted twice.
+func (s *aggregateService) Rebuild(ctx context.Context, table string) (*models.AggregateRebuildResult, error) {
+	if !IsPlayerAggregateTable(table) {
+		return nil, fmt.Errorf("unknown aggregate table %q", table)
+	}
+	start := time.Now()
+
+	feeders, err := s.feeders(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := s.Exclusions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ch.Exec(ctx, fmt.Sprintf("TRUNCATE TABLE mohaa_stats.%s", table)); err != nil {
+		return nil, fmt.Errorf("truncate %s: %w", table, err)
+	}
+
+	result := &models.AggregateRebuildResult{Table: table}
+	for _, f := range feeders {
+		if err := s.replay(ctx, table, f, exclude, nil); err != nil {
+			return nil, err
+		}
+		result.Views = append(result.Views, f.name)
+	}
+
+	result.Duration = time.Since(start).Round(time.Millisecond).String()
+	return result, nil
+}
+
+// RebuildDay deletes one day of an aggregate table and replays that day's
+// events into it. Only events of that day are read, so ingest can keep
+// running unless the day is still receiving events.
+func (s *aggregateService) RebuildDay(ctx context.Context, table string, day time.Time, exclude *AggregateExclusions) error {
+	if !IsPlayerAggregateTable(table) {
+		return fmt.Errorf("unknown aggregate table %q", table)
+	}
+	feeders, err := s.feeders(ctx, table)
+	if err != nil {
+		return err
+	}
+
+	// Wait for the delete so it cannot remove the replayed rows
+	syncCtx := clickhouse.Context(ctx, clickhouse.WithSettings(clickhouse.Settings{"mutations_sync": 2}))
+	if err := s.ch.Exec(syncCtx, fmt.Sprintf("ALTER TABLE mohaa_stats.%s DELETE WHERE day = toDateTime(?)", table), day.Unix()); err != nil {
+		return fmt.Errorf("clear %s for %s: %w", table, day.Format("2006-01-02"), err)
+	}
+
+	for _, f := range feeders {
+		if err := s.replay(ctx, table, f, exclude, &day); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AffectedDays returns the days on which the given matches were played or the
+// given players had events, oldest first
+func (s *aggregateService) AffectedDays(ctx context.Context, matchIDs, playerIDs []string) ([]time.Time, error) {
+	seen := make(map[uint32]bool)
+	for _, source := range []struct {
+		table   string
+		players []string // columns holding player IDs
+	}{
+		{"raw_events", []string{"actor_id", "target_id"}},
+		{"movement_events", []string{"actor_id"}},
+	} {
+		var conds []string
+		var args []any
+		if len(matchIDs) > 0 {
+			conds = append(conds, "toString(match_id) IN ?")
+			args = append(args, matchIDs)
+		}
+		if len(playerIDs) > 0 {
+			for _, col := range source.players {
+				conds = append(conds, col+" IN ?")
+				args = append(args, playerIDs)
+			}
+		}
+		if len(conds) == 0 {
+			return nil, nil
+		}
+
+		rows, err := s.ch.Query(ctx, fmt.Sprintf(`
+			SELECT DISTINCT toUnixTimestamp(toStartOfDay(timestamp))
+			FROM mohaa_stats.%s
+			WHERE %s
+		`, source.table, strings.Join(conds, " OR ")), args...)
+		if err != nil {
+			return nil, fmt.Errorf("affected days in %s: %w", source.table, err)
+		}
+		for rows.Next() {
+			var day uint32
+			if err := rows.Scan(&day); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("affected days in %s: %w", source.table, err)
+			}
+			seen[day] = true
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("affected days in %s: %w", source.table, err)
+		}
+	}
+
+	days := make([]time.Time, 0, len(seen))
+	for day := range seen {
+		days = append(days, time.Unix(int64(day), 0).UTC())
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return days, nil
+}
+
+// RefreshLeaderboard replaces the leaderboard_global snapshot with totals
+// from player_stats_daily, ranked by kills, and returns the players ranked
+func (s *aggregateService) RefreshLeaderboard(ctx context.Context, exclude *AggregateExclusions) (int, error) {
+	where := "player_id != ''"
+	if exclude != nil && len(exclude.Players) > 0 {
+		where += " AND player_id NOT IN (" + quoteList(exclude.Players, `'`) + ")"
+	}
+
+	if err := s.ch.Exec(ctx, "TRUNCATE TABLE mohaa_stats.leaderboard_global"); err != nil {
+		return 0, fmt.Errorf("truncate leaderboard_global: %w", err)
+	}
+	if err := s.ch.Exec(ctx, fmt.Sprintf(`
+		INSERT INTO mohaa_stats.leaderboard_global
+			(player_id, player_name, total_kills, total_deaths, total_headshots, total_damage,
+			 matches_played, kd_ratio, hs_percent, last_active, rank)
+		SELECT
+			player_id, player_name, total_kills, total_deaths, total_headshots, total_damage, matches_played,
+			if(total_deaths = 0, toFloat64(total_kills), total_kills / total_deaths),
+			if(total_kills = 0, 0, total_headshots * 100 / total_kills),
+			last_active,
+			row_number() OVER (ORDER BY total_kills DESC, player_id)
+		FROM (
+			SELECT
+				player_id,
+				argMax(player_name, last_active) AS player_name,
+				sum(kills) AS total_kills,
+				sum(deaths) AS total_deaths,
+				sum(headshots) AS total_headshots,
+				sum(total_damage) AS total_damage,
+				uniqExactMerge(matches_played) AS matches_played,
+				max(last_active) AS last_active
+			FROM mohaa_stats.player_stats_daily
+			WHERE %s
+			GROUP BY player_id
+		)
+	`, where)); err != nil {
+		return 0, fmt.Errorf("fill leaderboard_global: %w", err)
+	}
+
+	var ranked uint64
+	if err := s.ch.QueryRow(ctx, "SELECT count() FROM mohaa_stats.leaderboard_global").Scan(&ranked); err != nil {
+		return 0, fmt.Errorf("count leaderboard_global: %w", err)
+	}
+	return int(ranked), nil
+}
+
+// feeder is a materialized view writing into an aggregate table
+type feeder struct{ name, selectSQL string }
+
+func (s *aggregateService) feeders(ctx context.Context, table string) ([]feeder, error) {
+	rows, err := s.ch.Query(ctx, `
+		SELECT name, as_select
+		FROM system.tables
+		WHERE database = 'mohaa_stats'
+		  AND engine = 'MaterializedView'
+		  AND position(create_table_query, ?) > 0
+		ORDER BY name
+	`, "TO mohaa_stats."+table+" ")
+	if err != nil {
+		return nil, fmt.Errorf("list feeding views: %w", err)
+	}
+	defer rows.Close()
+
+	var feeders []feeder
+	for rows.Next() {
+		var f feeder
+		if err := rows.Scan(&f.name, &f.selectSQL); err != nil {
+			return nil, fmt.Errorf("scan feeding view: %w", err)
+		}
+		feeders = append(feeders, f)
+	}
+	if len(feeders) == 0 {
+		return nil, fmt.Errorf("no materialized views feed %s", table)
+	}
+	return feeders, nil
+}
+
+// replay inserts a view's SELECT over raw events into its aggregate table,
+// optionally for a single day
+func (s *aggregateService) replay(ctx context.Context, table string, f feeder, exclude *AggregateExclusions, day *time.Time) error {
+	// Views don't always select every column, so insert by name
+	cols, err := s.viewColumns(ctx, f.name)
+	if err != nil {
+		return err
+	}
+	if err := s.ch.Exec(ctx, replayQuery(table, cols, f.selectSQL, exclude, day)); err != nil {
+		return fmt.Errorf("replay %s: %w", f.name, err)
+	}
+	return nil
+}
+
+// replayQuery builds the INSERT replaying a view's SELECT. Banned players are
+// dropped from its output; voided matches and other days are hidden from the
+// event tables the view reads.
+func replayQuery(table string, cols []string, selectSQL string, exclude *AggregateExclusions, day *time.Time) string {
+	query := selectSQL
+	var filters []string
+	if exclude != nil {
+		if len(exclude.Players) > 0 {
+			query = fmt.Sprintf("SELECT %s FROM (%s) WHERE player_id NOT IN (%s)",
+				strings.Join(cols, ", "), selectSQL, quoteList(exclude.Players, `'`))
+		}
+		if len(exclude.Matches) > 0 {
+			// Quoted twice: once in the filter, once as the setting's string
+			filters = append(filters, "match_id NOT IN ("+quoteList(exclude.Matches, `\'`)+")")
+		}
+	}
+	if day != nil {
+		filters = append(filters, fmt.Sprintf("toStartOfDay(timestamp) = toDateTime(%d)", day.Unix()))
+	}
+
+	insert := fmt.Sprintf("INSERT INTO mohaa_stats.%s (%s) %s", table, strings.Join(cols, ", "), query)
+	if len(filters) > 0 {
+		filter := strings.Join(filters, " AND ")
+		insert += fmt.Sprintf(" SETTINGS additional_table_filters = {'mohaa_stats.raw_events': '%s', 'mohaa_stats.movement_events': '%s'}", filter, filter)
+	}
+	return insert
+}
+
+// quoteList joins values as SQL string literals using quote, escaping
+// backslashes and quotes inside them
+func quoteList(values []string, quote string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		v = strings.ReplaceAll(v, `\`, `\\`)
+		v = strings.ReplaceAll(v, `'`, `\'`)
+		quoted[i] = quote + v + quote
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func (s *aggregateService) voidedMatches(ctx context.Context) ([]string, error) {
+	rows, err := s.ch.Query(ctx, `
+		SELECT toString(match_id)
+		FROM mohaa_stats.voided_matches FINAL
+		WHERE voided = 1
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("list voided matches: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("list voided matches: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list voided matches: %w", err)
+	}
+	return ids, nil
+}
+
+// bannedPlayers returns the game GUIDs linked to accounts with an active ban
+func (s *aggregateService) bannedPlayers(ctx context.Context) ([]string, error) {
+	if s.pg == nil {
+		return nil, nil
+	}
+	rows, err := s.pg.Query(ctx, `
+		SELECT DISTINCT ui.player_guid
+		FROM user_identities ui
+		JOIN users u ON u.id = ui.user_id
+		WHERE u.is_banned AND (u.banned_until IS NULL OR u.banned_until > NOW())
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("list banned players: %w", err)
+	}
+	defer rows.Close()
+
+	var guids []string
+	for rows.Next() {
+		var guid string
+		if err := rows.Scan(&guid); err != nil {
+			return nil, fmt.Errorf("list banned players: %w", err)
+		}
+		guids = append(guids, guid)
+	}
+	return guids, rows.Err()
+}
+
+func (s *aggregateService) viewColumns(ctx context.Context, view string) ([]string, error) {
+	rows, err := s.ch.Query(ctx, `
+		SELECT name FROM system.columns
+		WHERE database = 'mohaa_stats' AND table = ?
+		ORDER BY position
+	`, view)
+	if err != nil {
+		return nil, fmt.Errorf("columns of %s: %w", view, err)
+	}
+	defer rows.Close()
+
+	var cols []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("columns of %s: %w", view, err)
+		}
+		cols = append(cols, "`"+name+"`")
+	}
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("view %s has no columns", view)
+	}
+	return cols, nil
+}
@@ -0,0 +1,67 @@
+package logic
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAggregateDriftPct(t *testing.T) {
+	tests := []struct {
+		agg, raw uint64
+		want     float64
+	}{
+		{0, 0, 0},
+		{100, 100, 0},
+		{99, 100, 0.01},
+		{100, 99, 0.01},
+		{0, 5, 1},
+		{5, 0, 1},
+	}
+
+	for _, tt := range tests {
+		if got := aggregateDriftPct(tt.agg, tt.raw); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("aggregateDriftPct(%d, %d) = %v, want %v", tt.agg, tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestAggregateServiceRejectsUnknownTable(t *testing.T) {
+	svc := NewAggregateService(&MockConn{}, nil)
+
+	if _, err := svc.CheckConsistency(context.Background(), "raw_events", 7, 10, 0.01); err == nil {
+		t.Error("CheckConsistency accepted a non-aggregate table")
+	}
+	if _, err := svc.Rebuild(context.Background(), "raw_events; DROP TABLE x"); err == nil {
+		t.Error("Rebuild accepted a non-aggregate table")
+	}
+}
+
+func TestReplayQuery(t *testing.T) {
+	cols := []string{"`day`", "`player_id`", "`kills`"}
+	view := "SELECT toStartOfDay(timestamp) AS day, actor_id AS player_id, count() AS kills FROM mohaa_stats.raw_events GROUP BY day, player_id"
+
+	plain := replayQuery("player_stats_daily", cols, view, &AggregateExclusions{}, nil)
+	if want := "INSERT INTO mohaa_stats.player_stats_daily (`day`, `player_id`, `kills`) " + view; plain != want {
+		t.Errorf("without exclusions:\n got %s\nwant %s", plain, want)
+	}
+
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	q := replayQuery("player_stats_daily", cols, view, &AggregateExclusions{
+		Matches: []string{"7c9e6679-7425-40de-944b-e07fc1f90ae7"},
+		Players: []string{"cheater", "o'brien"},
+	}, &day)
+	for _, want := range []string{
+		"SELECT `day`, `player_id`, `kills` FROM (" + view + ") WHERE player_id NOT IN ('cheater', 'o\\'brien')",
+		`match_id NOT IN (\'7c9e6679-7425-40de-944b-e07fc1f90ae7\')`,
+		"toStartOfDay(timestamp) = toDateTime(1772323200)",
+		"'mohaa_stats.raw_events': '",
+		"'mohaa_stats.movement_events': '",
+	} {
+		if !strings.Contains(q, want) {
+			t.Errorf("replay query is missing %q:\n%s", want, q)
+		}
+	}
+}
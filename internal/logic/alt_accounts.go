@@ -0,0 +1,217 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// maxGUIDsPerHash leaves out IP hashes shared by more GUIDs than this: an
+// internet cafe, a LAN party or carrier-grade NAT, not one player's alts
+const maxGUIDsPerHash = 10
+
+// activeBanGUIDs are the GUIDs of accounts with an active ban, the same ones
+// aggregate rebuilds leave out
+const activeBanGUIDs = `
+	SELECT ui.player_guid, ui.user_id, COALESCE(u.banned_reason, '') AS reason, u.banned_until
+	FROM user_identities ui
+	JOIN users u ON u.id = ui.user_id
+	WHERE u.is_banned AND (u.banned_until IS NULL OR u.banned_until > NOW())`
+
+// NormalizeIPHash lowercases a client IP hash and reports whether it is a
+// hex SHA-256. Anything else, a raw address included, is refused.
+func NormalizeIPHash(hash string) (string, bool) {
+	hash = strings.ToLower(strings.TrimSpace(hash))
+	if len(hash) != 64 {
+		return "", false
+	}
+	for _, c := range hash {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return "", false
+		}
+	}
+	return hash, true
+}
+
+// connectionRow is the upsert of one (hash, GUID) pair
+type connectionRow struct {
+	ipHash, guid, serverID string
+	connections            int
+	first, last            time.Time
+}
+
+// foldConnections merges the connections of each (hash, GUID) pair, as one
+// upsert cannot touch a row twice. The server is the one of the latest
+// connection. Rows come out sorted so concurrent flushes lock in one order.
+func foldConnections(conns []models.PlayerConnection) []connectionRow {
+	type key struct{ ipHash, guid string }
+	rows := make(map[key]*connectionRow, len(conns))
+	for _, c := range conns {
+		k := key{c.IPHash, c.PlayerGUID}
+		row, ok := rows[k]
+		if !ok {
+			rows[k] = &connectionRow{ipHash: c.IPHash, guid: c.PlayerGUID, serverID: c.ServerID, connections: 1, first: c.At, last: c.At}
+			continue
+		}
+		row.connections++
+		if c.At.Before(row.first) {
+			row.first = c.At
+		}
+		if !c.At.Before(row.last) {
+			row.last, row.serverID = c.At, c.ServerID
+		}
+	}
+
+	out := make([]connectionRow, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, *row)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].ipHash != out[j].ipHash {
+			return out[i].ipHash < out[j].ipHash
+		}
+		return out[i].guid < out[j].guid
+	})
+	return out
+}
+
+type altAccountService struct {
+	pg PgPool
+}
+
+func NewAltAccountService(pg PgPool) AltAccountService {
+	return &altAccountService{pg: pg}
+}
+
+// Record stores connections from hashed client IPs
+func (s *altAccountService) Record(ctx context.Context, conns []models.PlayerConnection) error {
+	rows := foldConnections(conns)
+	if len(rows) == 0 {
+		return nil
+	}
+	hashes := make([]string, len(rows))
+	guids := make([]string, len(rows))
+	servers := make([]string, len(rows))
+	counts := make([]int32, len(rows))
+	firsts := make([]time.Time, len(rows))
+	lasts := make([]time.Time, len(rows))
+	for i, r := range rows {
+		hashes[i], guids[i], servers[i] = r.ipHash, r.guid, r.serverID
+		counts[i], firsts[i], lasts[i] = int32(r.connections), r.first, r.last
+	}
+
+	if _, err := s.pg.Exec(ctx, `
+		INSERT INTO player_ip_hashes (ip_hash, player_guid, last_server_id, connections, first_seen, last_seen)
+		SELECT * FROM unnest($1::text[], $2::text[], $3::text[], $4::int[], $5::timestamptz[], $6::timestamptz[])
+		ON CONFLICT (ip_hash, player_guid) DO UPDATE SET
+			connections = player_ip_hashes.connections + EXCLUDED.connections,
+			first_seen = LEAST(player_ip_hashes.first_seen, EXCLUDED.first_seen),
+			last_server_id = CASE WHEN EXCLUDED.last_seen >= player_ip_hashes.last_seen
+				THEN EXCLUDED.last_server_id ELSE player_ip_hashes.last_server_id END,
+			last_seen = GREATEST(player_ip_hashes.last_seen, EXCLUDED.last_seen)
+	`, hashes, guids, servers, counts, firsts, lasts); err != nil {
+		return fmt.Errorf("failed to store player connections: %w", err)
+	}
+	return nil
+}
+
+// Alts returns the GUIDs that connected from the client IPs of a player,
+// those sharing the most hashes first
+func (s *altAccountService) Alts(ctx context.Context, guid string, limit int) ([]models.AltAccount, error) {
+	rows, err := s.pg.Query(ctx, `
+		WITH bans AS (`+activeBanGUIDs+`),
+		shared AS (
+			SELECT ip_hash FROM player_ip_hashes
+			WHERE ip_hash IN (SELECT ip_hash FROM player_ip_hashes WHERE player_guid = $1)
+			GROUP BY ip_hash
+			HAVING count(*) <= $2
+		)
+		SELECT a.player_guid, COALESCE(max(r.last_known_name), ''), count(*), sum(a.connections),
+			min(a.first_seen), max(a.last_seen),
+			EXISTS (SELECT 1 FROM bans b WHERE b.player_guid = a.player_guid)
+		FROM player_ip_hashes a
+		JOIN shared s ON s.ip_hash = a.ip_hash
+		LEFT JOIN player_guid_registry r ON r.player_guid = a.player_guid
+		WHERE a.player_guid <> $1
+		GROUP BY a.player_guid
+		ORDER BY count(*) DESC, max(a.last_seen) DESC
+		LIMIT $3
+	`, guid, maxGUIDsPerHash, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alt accounts: %w", err)
+	}
+	defer rows.Close()
+
+	alts := []models.AltAccount{}
+	for rows.Next() {
+		var a models.AltAccount
+		if err := rows.Scan(&a.PlayerGUID, &a.PlayerName, &a.SharedHashes, &a.Connections,
+			&a.FirstSeen, &a.LastSeen, &a.Banned); err != nil {
+			return nil, fmt.Errorf("failed to read alt account: %w", err)
+		}
+		alts = append(alts, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list alt accounts: %w", err)
+	}
+	return alts, nil
+}
+
+// BanEvasion returns the GUIDs seen since `since` on the client IPs of a
+// player with an active ban. Other GUIDs of the banned account and GUIDs
+// banned themselves are left out.
+func (s *altAccountService) BanEvasion(ctx context.Context, since time.Time, limit int) ([]models.BanEvasionSuspect, error) {
+	rows, err := s.pg.Query(ctx, `
+		WITH bans AS (`+activeBanGUIDs+`),
+		shared AS (
+			SELECT ip_hash FROM player_ip_hashes
+			GROUP BY ip_hash
+			HAVING count(*) BETWEEN 2 AND $2
+		)
+		SELECT b.player_guid, b.reason, b.banned_until,
+			a.player_guid, COALESCE(max(r.last_known_name), ''), count(*), sum(a.connections),
+			min(a.first_seen), max(a.last_seen)
+		FROM bans b
+		JOIN player_ip_hashes bh ON bh.player_guid = b.player_guid
+		JOIN shared s ON s.ip_hash = bh.ip_hash
+		JOIN player_ip_hashes a ON a.ip_hash = bh.ip_hash AND a.player_guid <> b.player_guid
+		LEFT JOIN player_guid_registry r ON r.player_guid = a.player_guid
+		WHERE a.last_seen >= $1
+		  AND NOT EXISTS (SELECT 1 FROM user_identities ui WHERE ui.user_id = b.user_id AND ui.player_guid = a.player_guid)
+		  AND NOT EXISTS (SELECT 1 FROM bans b2 WHERE b2.player_guid = a.player_guid)
+		GROUP BY b.player_guid, b.reason, b.banned_until, a.player_guid
+		ORDER BY count(*) DESC, max(a.last_seen) DESC
+		LIMIT $3
+	`, since, maxGUIDsPerHash, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ban evasion suspects: %w", err)
+	}
+	defer rows.Close()
+
+	suspects := []models.BanEvasionSuspect{}
+	for rows.Next() {
+		var b models.BanEvasionSuspect
+		if err := rows.Scan(&b.BannedGUID, &b.BanReason, &b.BannedUntil,
+			&b.PlayerGUID, &b.PlayerName, &b.SharedHashes, &b.Connections, &b.FirstSeen, &b.LastSeen); err != nil {
+			return nil, fmt.Errorf("failed to read ban evasion suspect: %w", err)
+		}
+		suspects = append(suspects, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list ban evasion suspects: %w", err)
+	}
+	return suspects, nil
+}
+
+// Prune drops the (hash, GUID) pairs last seen before `before`
+func (s *altAccountService) Prune(ctx context.Context, before time.Time) (int, error) {
+	tag, err := s.pg.Exec(ctx, `DELETE FROM player_ip_hashes WHERE last_seen < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune player connections: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,45 @@
+package logic
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestNormalizeIPHash(t *testing.T) {
+	valid := strings.Repeat("ab12", 16)
+	if got, ok := NormalizeIPHash(" " + strings.ToUpper(valid) + " "); !ok || got != valid {
+		t.Errorf("NormalizeIPHash(upper) = %q, %v", got, ok)
+	}
+	for _, bad := range []string{"", "203.0.113.7", "2001:db8::1", strings.Repeat("a", 63), strings.Repeat("g", 64)} {
+		if _, ok := NormalizeIPHash(bad); ok {
+			t.Errorf("NormalizeIPHash(%q) accepted", bad)
+		}
+	}
+}
+
+func TestFoldConnections(t *testing.T) {
+	t0 := time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)
+	rows := foldConnections([]models.PlayerConnection{
+		{IPHash: "h2", PlayerGUID: "a", ServerID: "s1", At: t0.Add(time.Hour)},
+		{IPHash: "h1", PlayerGUID: "a", ServerID: "s1", At: t0},
+		{IPHash: "h2", PlayerGUID: "a", ServerID: "s2", At: t0.Add(2 * time.Hour)},
+		{IPHash: "h2", PlayerGUID: "a", ServerID: "s3", At: t0},
+		{IPHash: "h2", PlayerGUID: "b", ServerID: "s1", At: t0},
+	})
+	if len(rows) != 3 {
+		t.Fatalf("folded into %d rows, want 3", len(rows))
+	}
+	if rows[0].ipHash != "h1" || rows[1].ipHash != "h2" || rows[1].guid != "a" || rows[2].guid != "b" {
+		t.Errorf("rows out of order: %+v", rows)
+	}
+	r := rows[1]
+	if r.connections != 3 || !r.first.Equal(t0) || !r.last.Equal(t0.Add(2*time.Hour)) || r.serverID != "s2" {
+		t.Errorf("h2/a = %+v, want 3 connections from t0 to t0+2h, last on s2", r)
+	}
+	if rows := foldConnections(nil); len(rows) != 0 {
+		t.Errorf("no connections folded into %d rows", len(rows))
+	}
+}
@@ -0,0 +1,117 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// analyticsViews documents the BI views created by 017_bi_views.sql. Column
+// types are read from ClickHouse; names and meanings here are the contract.
+var analyticsViews = []models.AnalyticsView{
+	{
+		Name:        "v_player_daily",
+		Description: "Per-player daily totals from the player aggregates, in-round events only",
+		Grain:       "day, player_id",
+		Columns: []models.AnalyticsViewColumn{
+			{Name: "day", Description: "UTC day"},
+			{Name: "player_id", Description: "Player GUID"},
+			{Name: "player_name", Description: "Latest name seen that day"},
+			{Name: "kills", Description: "Kills of human players"},
+			{Name: "deaths", Description: "Deaths"},
+			{Name: "headshots", Description: "Kills to the head or helmet"},
+			{Name: "bot_kills", Description: "Kills of bots"},
+			{Name: "teamkills", Description: "Team kills"},
+			{Name: "suicides", Description: "Suicides"},
+			{Name: "shots_fired", Description: "Shots fired"},
+			{Name: "shots_hit", Description: "Shots that hit a player"},
+			{Name: "damage", Description: "Damage dealt"},
+			{Name: "matches_played", Description: "Distinct matches with an event by the player"},
+			{Name: "matches_won", Description: "Matches won"},
+			{Name: "kd_ratio", Description: "kills / deaths (deaths floored at 1)"},
+			{Name: "accuracy_pct", Description: "100 * shots_hit / shots_fired"},
+			{Name: "last_active", Description: "Time of the player's last event that day"},
+		},
+	},
+	{
+		Name:        "v_match_summary",
+		Description: "One row per match from raw events; filter on started_at or server_id",
+		Grain:       "match_id",
+		Columns: []models.AnalyticsViewColumn{
+			{Name: "match_id", Description: "Match UUID"},
+			{Name: "tenant_id", Description: "Owning tenant, empty on single-community installs"},
+			{Name: "server_id", Description: "Server that hosted the match"},
+			{Name: "map_name", Description: "Map"},
+			{Name: "started_at", Description: "First event"},
+			{Name: "ended_at", Description: "Last event"},
+			{Name: "duration_seconds", Description: "ended_at - started_at"},
+			{Name: "players", Description: "Distinct players with an event"},
+			{Name: "kills", Description: "Kills of players and bots"},
+			{Name: "headshots", Description: "Kills to the head or helmet"},
+			{Name: "rounds", Description: "Highest round number reported"},
+			{Name: "finished", Description: "1 if a match_end was received"},
+			{Name: "voided", Description: "1 if an admin voided the match"},
+		},
+	},
+	{
+		Name:        "v_weapon_daily",
+		Description: "Per-weapon daily totals",
+		Grain:       "day, weapon",
+		Columns: []models.AnalyticsViewColumn{
+			{Name: "day", Description: "UTC day"},
+			{Name: "weapon", Description: "Weapon name as reported by the game"},
+			{Name: "kills", Description: "Kills"},
+			{Name: "headshots", Description: "Kills to the head or helmet"},
+			{Name: "shots_fired", Description: "Shots fired"},
+			{Name: "shots_hit", Description: "Shots that hit a player"},
+			{Name: "players", Description: "Distinct players who used the weapon"},
+			{Name: "accuracy_pct", Description: "100 * shots_hit / shots_fired"},
+		},
+	},
+}
+
+// AnalyticsViews lists the BI views with their column types as deployed. A
+// view whose migration has not run is returned with Available false.
+func (s *aggregateService) AnalyticsViews(ctx context.Context) ([]models.AnalyticsView, error) {
+	names := make([]string, len(analyticsViews))
+	for i, v := range analyticsViews {
+		names[i] = v.Name
+	}
+
+	rows, err := s.ch.Query(ctx, `
+		SELECT table, name, type
+		FROM system.columns
+		WHERE database = 'mohaa_stats' AND table IN ?
+	`, names)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read view columns: %w", err)
+	}
+	defer rows.Close()
+
+	types := make(map[string]map[string]string)
+	for rows.Next() {
+		var table, column, typ string
+		if err := rows.Scan(&table, &column, &typ); err != nil {
+			return nil, fmt.Errorf("failed to scan view columns: %w", err)
+		}
+		if types[table] == nil {
+			types[table] = make(map[string]string)
+		}
+		types[table][column] = typ
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read view columns: %w", err)
+	}
+
+	views := make([]models.AnalyticsView, len(analyticsViews))
+	for i, v := range analyticsViews {
+		v.Available = types[v.Name] != nil
+		v.Columns = append([]models.AnalyticsViewColumn(nil), v.Columns...)
+		for j := range v.Columns {
+			v.Columns[j].Type = types[v.Name][v.Columns[j].Name]
+		}
+		views[i] = v
+	}
+	return views, nil
+}
@@ -0,0 +1,28 @@
+package logic
+
+import (
+	"os"
+	"regexp"
+	"testing"
+)
+
+// Every documented view and column must exist in the migration, so the
+// listing never advertises something dashboards cannot query
+func TestAnalyticsViewsMatchMigration(t *testing.T) {
+	sql, err := os.ReadFile("../../migrations/clickhouse/017_bi_views.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range analyticsViews {
+		body := regexp.MustCompile(`(?s)VIEW mohaa_stats\.` + v.Name + ` AS\s+SELECT(.*?)\nFROM`).FindSubmatch(sql)
+		if body == nil {
+			t.Errorf("view %s not created by the migration", v.Name)
+			continue
+		}
+		for _, c := range v.Columns {
+			if !regexp.MustCompile(`(?m)(AS |^\s+)` + c.Name + `,?$`).Match(body[1]) {
+				t.Errorf("view %s: column %s not selected by the migration", v.Name, c.Name)
+			}
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// ErrInvalidChallenge is returned for a challenge that cannot be stored
+var ErrInvalidChallenge = errors.New("invalid challenge")
+
+// challengeMetrics are the metrics the worker counts
+var challengeMetrics = map[string]bool{
+	models.ChallengeKills:     true,
+	models.ChallengeHeadshots: true,
+	models.ChallengeBashKills: true,
+	models.ChallengeRoadkills: true,
+	models.ChallengeWins:      true,
+	models.ChallengeMatches:   true,
+	models.ChallengeWeapons:   true,
+}
+
+// ChallengeWeek returns the start of the challenge week containing t; weeks
+// start on Monday, UTC, like the player of the week
+func ChallengeWeek(t time.Time) time.Time {
+	return HighlightPeriodStart(models.HighlightWeek, t)
+}
+
+// ValidateChallenge checks a challenge before it is stored and normalizes
+// its weapon fragments and map prefix to lowercase
+func ValidateChallenge(c *models.Challenge) error {
+	if !codePattern.MatchString(c.Code) {
+		return fmt.Errorf("%w: code must be 1-64 lowercase letters, digits or underscores", ErrInvalidChallenge)
+	}
+	if c.Title == "" || len(c.Title) > 100 {
+		return fmt.Errorf("%w: title must be 1-100 characters", ErrInvalidChallenge)
+	}
+	if !challengeMetrics[c.Metric] {
+		return fmt.Errorf("%w: unknown metric %q", ErrInvalidChallenge, c.Metric)
+	}
+	if c.Target <= 0 {
+		return fmt.Errorf("%w: target must be positive", ErrInvalidChallenge)
+	}
+	weapons := make([]string, 0, len(c.Weapons))
+	for _, w := range c.Weapons {
+		if w = strings.ToLower(strings.TrimSpace(w)); w != "" {
+			weapons = append(weapons, w)
+		}
+	}
+	c.Weapons = weapons
+	c.MapPrefix = strings.ToLower(strings.TrimSpace(c.MapPrefix))
+	return nil
+}
+
+type challengesService struct {
+	ch driver.Conn
+	pg PgPool
+}
+
+func NewChallengesService(ch driver.Conn, pg PgPool) ChallengesService {
+	return &challengesService{ch: ch, pg: pg}
+}
+
+const challengeColumns = `c.code, c.title, c.description, c.metric, c.weapons, c.map_prefix, c.target, c.enabled`
+
+func (s *challengesService) queryChallenges(ctx context.Context, query string, args ...any) ([]models.Challenge, error) {
+	rows, err := s.pg.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read challenges: %w", err)
+	}
+	defer rows.Close()
+
+	challenges := []models.Challenge{}
+	for rows.Next() {
+		var c models.Challenge
+		if err := rows.Scan(&c.Code, &c.Title, &c.Description, &c.Metric, &c.Weapons, &c.MapPrefix, &c.Target, &c.Enabled); err != nil {
+			return nil, fmt.Errorf("failed to read challenges: %w", err)
+		}
+		challenges = append(challenges, c)
+	}
+	return challenges, rows.Err()
+}
+
+// List returns the whole challenge pool
+func (s *challengesService) List(ctx context.Context) ([]models.Challenge, error) {
+	return s.queryChallenges(ctx, `SELECT `+challengeColumns+` FROM challenges c ORDER BY c.code`)
+}
+
+// Define creates or replaces a challenge in the pool. A running week keeps
+// counting against the new target.
+func (s *challengesService) Define(ctx context.Context, c *models.Challenge) error {
+	if err := ValidateChallenge(c); err != nil {
+		return err
+	}
+	if _, err := s.pg.Exec(ctx, `
+		INSERT INTO challenges (code, title, description, metric, weapons, map_prefix, target, enabled)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (code) DO UPDATE SET
+			title = EXCLUDED.title, description = EXCLUDED.description, metric = EXCLUDED.metric,
+			weapons = EXCLUDED.weapons, map_prefix = EXCLUDED.map_prefix, target = EXCLUDED.target,
+			enabled = EXCLUDED.enabled
+	`, c.Code, c.Title, c.Description, c.Metric, c.Weapons, c.MapPrefix, c.Target, c.Enabled); err != nil {
+		return fmt.Errorf("failed to store challenge: %w", err)
+	}
+	return nil
+}
+
+// Week returns the challenges running in the week starting at week
+func (s *challengesService) Week(ctx context.Context, week time.Time) ([]models.Challenge, error) {
+	return s.queryChallenges(ctx, `
+		SELECT `+challengeColumns+`
+		FROM weekly_challenges w
+		JOIN challenges c ON c.code = w.code
+		WHERE w.week_start = $1
+		ORDER BY c.code
+	`, week)
+}
+
+// Rotate picks the week's challenges if it has none yet and returns them.
+// Challenges that ran the week before go last; the rest are ordered by a
+// hash of code and week, so every instance picks the same set.
+func (s *challengesService) Rotate(ctx context.Context, week time.Time, count int) ([]models.Challenge, error) {
+	if _, err := s.pg.Exec(ctx, `
+		INSERT INTO weekly_challenges (week_start, code)
+		SELECT $1::date, code FROM challenges
+		WHERE enabled AND NOT EXISTS (SELECT 1 FROM weekly_challenges WHERE week_start = $1::date)
+		ORDER BY code IN (SELECT code FROM weekly_challenges WHERE week_start = $1::date - 7),
+			md5(code || $1::date::text)
+		LIMIT $2
+		ON CONFLICT DO NOTHING
+	`, week, count); err != nil {
+		return nil, fmt.Errorf("failed to rotate challenges: %w", err)
+	}
+	return s.Week(ctx, week)
+}
+
+// Complete records a completion; false when the player had already completed it
+func (s *challengesService) Complete(ctx context.Context, c *models.ChallengeCompletion) (bool, error) {
+	tag, err := s.pg.Exec(ctx, `
+		INSERT INTO challenge_completions (week_start, code, player_guid, player_name, completed_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (week_start, code, player_guid) DO NOTHING
+	`, c.WeekStart, c.Code, c.PlayerGUID, c.PlayerName, c.CompletedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to store challenge completion: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// Completions returns when the player completed each of the week's challenges
+func (s *challengesService) Completions(ctx context.Context, guid string, week time.Time) (map[string]time.Time, error) {
+	rows, err := s.pg.Query(ctx, `
+		SELECT code, completed_at FROM challenge_completions
+		WHERE player_guid = $1 AND week_start = $2
+	`, guid, week)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read challenge completions: %w", err)
+	}
+	defer rows.Close()
+
+	completed := make(map[string]time.Time)
+	for rows.Next() {
+		var code string
+		var at time.Time
+		if err := rows.Scan(&code, &at); err != nil {
+			return nil, fmt.Errorf("failed to read challenge completions: %w", err)
+		}
+		completed[code] = at
+	}
+	return completed, rows.Err()
+}
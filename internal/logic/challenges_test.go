@@ -0,0 +1,37 @@
+package logic
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestValidateChallenge(t *testing.T) {
+	c := models.Challenge{Code: "smg_dm", Title: "Spray and Pray", Metric: models.ChallengeKills,
+		Weapons: []string{" Thompson", "", "MP40"}, MapPrefix: "DM", Target: 200}
+	if err := ValidateChallenge(&c); err != nil {
+		t.Fatalf("valid challenge rejected: %v", err)
+	}
+	if len(c.Weapons) != 2 || c.Weapons[0] != "thompson" || c.Weapons[1] != "mp40" || c.MapPrefix != "dm" {
+		t.Errorf("not normalized: %+v", c)
+	}
+
+	for name, bad := range map[string]models.Challenge{
+		"unknown metric": {Code: "x", Title: "X", Metric: "distance", Target: 1},
+		"no target":      {Code: "x", Title: "X", Metric: models.ChallengeKills},
+		"bad code":       {Code: "X Y", Title: "X", Metric: models.ChallengeKills, Target: 1},
+	} {
+		if err := ValidateChallenge(&bad); !errors.Is(err, ErrInvalidChallenge) {
+			t.Errorf("%s: err = %v", name, err)
+		}
+	}
+}
+
+func TestChallengeWeek(t *testing.T) {
+	sunday := time.Date(2026, 10, 18, 23, 59, 0, 0, time.UTC)
+	if got := ChallengeWeek(sunday); !got.Equal(time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("week of %v = %v", sunday, got)
+	}
+}
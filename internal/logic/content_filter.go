@@ -0,0 +1,362 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+var (
+	// ErrInvalidOverride is returned for an override that cannot be stored
+	ErrInvalidOverride = errors.New("invalid content filter override")
+	// ErrOverrideNotFound is returned when a player has no override
+	ErrOverrideNotFound = errors.New("content filter override not found")
+)
+
+// ContentFilterConfig sets what the content filter catches. Words are blocked
+// anywhere in a name or chat line; ProtectedNames (e.g. "admin") may not be a
+// word of a player name. Both match case-insensitively and see through
+// lookalikes ("4dm1n") and separators ("a.d.m.i.n").
+type ContentFilterConfig struct {
+	Mode           models.ContentFilterMode
+	Words          []string
+	ProtectedNames []string
+}
+
+// ParseContentFilterConfig reads the mode ("" is off) and the comma-separated
+// word and protected name lists
+func ParseContentFilterConfig(mode, words, protected string) (ContentFilterConfig, error) {
+	cfg := ContentFilterConfig{
+		Mode:           models.ContentFilterMode(strings.ToLower(strings.TrimSpace(mode))),
+		Words:          splitTerms(words),
+		ProtectedNames: splitTerms(protected),
+	}
+	switch cfg.Mode {
+	case "":
+		cfg.Mode = models.ContentFilterOff
+	case models.ContentFilterOff, models.ContentFilterFlag, models.ContentFilterMask:
+	default:
+		return cfg, fmt.Errorf("unknown content filter mode %q (want off, flag or mask)", mode)
+	}
+	return cfg, nil
+}
+
+func splitTerms(s string) []string {
+	var terms []string
+	for _, t := range strings.Split(s, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			terms = append(terms, t)
+		}
+	}
+	return terms
+}
+
+// lookalikes maps the digits and symbols players swap in for letters
+var lookalikes = map[rune]rune{
+	'0': 'o', '1': 'i', '3': 'e', '4': 'a', '5': 's', '7': 't', '8': 'b',
+	'@': 'a', '$': 's', '!': 'i', '|': 'l',
+}
+
+// foldRune returns the lowercase letter r stands for, or 0 for a separator
+func foldRune(r rune) rune {
+	if l, ok := lookalikes[r]; ok {
+		return l
+	}
+	if unicode.IsLetter(r) || unicode.IsDigit(r) {
+		return unicode.ToLower(r)
+	}
+	return 0
+}
+
+// fold returns the letters of runes as foldRune sees them, and for each the
+// index of the rune it came from
+func fold(runes []rune) (folded []rune, pos []int) {
+	for i, r := range runes {
+		if f := foldRune(r); f != 0 {
+			folded = append(folded, f)
+			pos = append(pos, i)
+		}
+	}
+	return folded, pos
+}
+
+func foldTerm(term string) string {
+	folded, _ := fold([]rune(term))
+	return string(folded)
+}
+
+// maskTerms stars out every blocked word in s, separators inside a match
+// included, and reports whether it found any
+func maskTerms(s string, words [][]rune) (string, bool) {
+	if len(words) == 0 {
+		return s, false
+	}
+	runes := []rune(s)
+	folded, pos := fold(runes)
+	found := false
+	for _, word := range words {
+		for i := 0; i+len(word) <= len(folded); i++ {
+			if !hasPrefix(folded[i:], word) {
+				continue
+			}
+			found = true
+			for j := pos[i]; j <= pos[i+len(word)-1]; j++ {
+				if !unicode.IsSpace(runes[j]) {
+					runes[j] = '*'
+				}
+			}
+		}
+	}
+	if !found {
+		return s, false
+	}
+	return string(runes), true
+}
+
+func hasPrefix(s, prefix []rune) bool {
+	for i, r := range prefix {
+		if s[i] != r {
+			return false
+		}
+	}
+	return true
+}
+
+// impersonates reports whether the name, or a word of it, is a protected name
+func impersonates(name string, protected map[string]bool) bool {
+	if len(protected) == 0 {
+		return false
+	}
+	if protected[foldTerm(name)] {
+		return true
+	}
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool { return foldRune(r) == 0 }) {
+		if protected[foldTerm(word)] {
+			return true
+		}
+	}
+	return false
+}
+
+// maskedName replaces a name that is offensive as a whole. The suffix keeps
+// masked players apart on one leaderboard.
+func maskedName(guid string) string {
+	return fmt.Sprintf("Player#%04x", crc32.ChecksumIEEE([]byte(guid))&0xffff)
+}
+
+type contentFilterService struct {
+	pg PgPool
+
+	mu        sync.RWMutex
+	mode      models.ContentFilterMode
+	words     [][]rune
+	protected map[string]bool
+}
+
+func NewContentFilterService(pg PgPool, cfg ContentFilterConfig) ContentFilterService {
+	s := &contentFilterService{pg: pg}
+	s.SetConfig(cfg)
+	return s
+}
+
+// SetConfig replaces the mode and term lists, e.g. after a config reload
+func (s *contentFilterService) SetConfig(cfg ContentFilterConfig) {
+	words := make([][]rune, 0, len(cfg.Words))
+	for _, w := range cfg.Words {
+		if f := foldTerm(w); f != "" {
+			words = append(words, []rune(f))
+		}
+	}
+	protected := make(map[string]bool, len(cfg.ProtectedNames))
+	for _, n := range cfg.ProtectedNames {
+		if f := foldTerm(n); f != "" {
+			protected[f] = true
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mode, s.words, s.protected = cfg.Mode, words, protected
+}
+
+func (s *contentFilterService) settings() (models.ContentFilterMode, [][]rune, map[string]bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.mode, s.words, s.protected
+}
+
+// FilterNames checks names (GUID -> display name) and returns the GUIDs whose
+// names are offensive. In mask mode those names are replaced in the map:
+// blocked words are starred out, and names that impersonate staff or a linked
+// forum account, or that an admin blocked, are replaced whole. Players with
+// an allow override are never flagged. When the overrides or forum names
+// cannot be read the names are still filtered without them, and the error is
+// returned with the result.
+func (s *contentFilterService) FilterNames(ctx context.Context, names map[string]string) (map[string]bool, error) {
+	mode, words, protected := s.settings()
+	if mode == models.ContentFilterOff || len(names) == 0 {
+		return nil, nil
+	}
+
+	overrides, err := s.overrides(ctx, names)
+	owners, ownersErr := s.forumNameOwners(ctx, names)
+	err = errors.Join(err, ownersErr)
+
+	flagged := make(map[string]bool)
+	for guid, name := range names {
+		action := overrides[guid]
+		if action == models.ContentOverrideAllow {
+			continue
+		}
+		masked, offensive := maskTerms(name, words)
+		forumOwners := owners[strings.ToLower(name)]
+		if action == models.ContentOverrideBlock || impersonates(name, protected) ||
+			(forumOwners != nil && !forumOwners[guid]) {
+			masked, offensive = maskedName(guid), true
+		}
+		if !offensive {
+			continue
+		}
+		flagged[guid] = true
+		if mode == models.ContentFilterMask {
+			names[guid] = masked
+		}
+	}
+	return flagged, err
+}
+
+// FilterText checks a chat line for blocked words. It returns the text to
+// serve (starred out in mask mode) and whether it was offensive.
+func (s *contentFilterService) FilterText(text string) (string, bool) {
+	mode, words, _ := s.settings()
+	if mode == models.ContentFilterOff {
+		return text, false
+	}
+	masked, offensive := maskTerms(text, words)
+	if mode == models.ContentFilterMask {
+		return masked, offensive
+	}
+	return text, offensive
+}
+
+func (s *contentFilterService) overrides(ctx context.Context, names map[string]string) (map[string]models.ContentOverrideAction, error) {
+	guids := make([]string, 0, len(names))
+	for guid := range names {
+		guids = append(guids, guid)
+	}
+	rows, err := s.pg.Query(ctx, `
+		SELECT player_guid, action FROM content_filter_overrides WHERE player_guid = ANY($1)
+	`, guids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read content filter overrides: %w", err)
+	}
+	defer rows.Close()
+
+	overrides := make(map[string]models.ContentOverrideAction)
+	for rows.Next() {
+		var guid string
+		var action models.ContentOverrideAction
+		if err := rows.Scan(&guid, &action); err != nil {
+			return overrides, fmt.Errorf("failed to read content filter overrides: %w", err)
+		}
+		overrides[guid] = action
+	}
+	return overrides, rows.Err()
+}
+
+// forumNameOwners maps each name that is the forum name of a linked account
+// (lowercased) to the GUIDs linked to that account
+func (s *contentFilterService) forumNameOwners(ctx context.Context, names map[string]string) (map[string]map[string]bool, error) {
+	lowered := make([]string, 0, len(names))
+	for _, name := range names {
+		lowered = append(lowered, strings.ToLower(name))
+	}
+	rows, err := s.pg.Query(ctx, `
+		SELECT lower(m.smf_username), r.player_guid
+		FROM smf_user_mappings m
+		JOIN player_guid_registry r ON r.smf_member_id = m.smf_member_id
+		WHERE lower(m.smf_username) = ANY($1)
+	`, lowered)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read forum names: %w", err)
+	}
+	defer rows.Close()
+
+	owners := make(map[string]map[string]bool)
+	for rows.Next() {
+		var name, guid string
+		if err := rows.Scan(&name, &guid); err != nil {
+			return owners, fmt.Errorf("failed to read forum names: %w", err)
+		}
+		if owners[name] == nil {
+			owners[name] = make(map[string]bool)
+		}
+		owners[name][guid] = true
+	}
+	return owners, rows.Err()
+}
+
+// ListOverrides returns every override, newest first
+func (s *contentFilterService) ListOverrides(ctx context.Context) ([]models.ContentFilterOverride, error) {
+	rows, err := s.pg.Query(ctx, `
+		SELECT player_guid, action, note, created_by, created_at
+		FROM content_filter_overrides
+		ORDER BY created_at DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read content filter overrides: %w", err)
+	}
+	defer rows.Close()
+
+	overrides := []models.ContentFilterOverride{}
+	for rows.Next() {
+		var o models.ContentFilterOverride
+		if err := rows.Scan(&o.PlayerGUID, &o.Action, &o.Note, &o.CreatedBy, &o.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to read content filter overrides: %w", err)
+		}
+		overrides = append(overrides, o)
+	}
+	return overrides, rows.Err()
+}
+
+// SetOverride creates or replaces a player's override
+func (s *contentFilterService) SetOverride(ctx context.Context, o *models.ContentFilterOverride) error {
+	if o.PlayerGUID == "" || len(o.PlayerGUID) > 64 {
+		return fmt.Errorf("%w: player_guid must be 1-64 characters", ErrInvalidOverride)
+	}
+	if o.Action != models.ContentOverrideAllow && o.Action != models.ContentOverrideBlock {
+		return fmt.Errorf("%w: action must be allow or block", ErrInvalidOverride)
+	}
+	if len(o.Note) > 255 {
+		return fmt.Errorf("%w: note must be at most 255 characters", ErrInvalidOverride)
+	}
+	if err := s.pg.QueryRow(ctx, `
+		INSERT INTO content_filter_overrides (player_guid, action, note, created_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (player_guid) DO UPDATE SET
+			action = EXCLUDED.action, note = EXCLUDED.note,
+			created_by = EXCLUDED.created_by, created_at = NOW()
+		RETURNING created_at
+	`, o.PlayerGUID, o.Action, o.Note, o.CreatedBy).Scan(&o.CreatedAt); err != nil {
+		return fmt.Errorf("failed to store content filter override: %w", err)
+	}
+	return nil
+}
+
+// DeleteOverride removes a player's override
+func (s *contentFilterService) DeleteOverride(ctx context.Context, guid string) error {
+	tag, err := s.pg.Exec(ctx, `DELETE FROM content_filter_overrides WHERE player_guid = $1`, guid)
+	if err != nil {
+		return fmt.Errorf("failed to delete content filter override: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrOverrideNotFound
+	}
+	return nil
+}
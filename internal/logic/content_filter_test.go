@@ -0,0 +1,137 @@
+package logic
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestParseContentFilterConfig(t *testing.T) {
+	cfg, err := ParseContentFilterConfig(" Mask ", "foo, bar ,,", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Mode != models.ContentFilterMask || len(cfg.Words) != 2 || cfg.Words[1] != "bar" {
+		t.Errorf("config = %+v", cfg)
+	}
+	if cfg, _ := ParseContentFilterConfig("", "", ""); cfg.Mode != models.ContentFilterOff {
+		t.Errorf("empty mode = %q, want off", cfg.Mode)
+	}
+	if _, err := ParseContentFilterConfig("hide", "", ""); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
+
+func TestFilterText(t *testing.T) {
+	svc := NewContentFilterService(nil, ContentFilterConfig{Mode: models.ContentFilterMask, Words: []string{"noob"}})
+	tests := []struct {
+		input, want string
+		flagged     bool
+	}{
+		{"gg wp", "gg wp", false},
+		{"you NOOB", "you ****", true},
+		{"n00b team", "**** team", true},
+		{"n.o.o.b", "*******", true},
+		{"noob and noob", "**** and ****", true},
+	}
+	for _, tt := range tests {
+		got, flagged := svc.FilterText(tt.input)
+		if got != tt.want || flagged != tt.flagged {
+			t.Errorf("FilterText(%q) = %q, %v; want %q, %v", tt.input, got, flagged, tt.want, tt.flagged)
+		}
+	}
+
+	svc.SetConfig(ContentFilterConfig{Mode: models.ContentFilterFlag, Words: []string{"noob"}})
+	if got, flagged := svc.FilterText("noob"); got != "noob" || !flagged {
+		t.Errorf("flag mode: FilterText = %q, %v", got, flagged)
+	}
+}
+
+// pairRows serves rows of two strings
+type pairRows struct {
+	MockPgRows
+	pairs [][2]string
+}
+
+func (r *pairRows) Next() bool {
+	r.curr++
+	return r.curr <= len(r.pairs)
+}
+
+func (r *pairRows) Scan(dest ...any) error {
+	pair := r.pairs[r.curr-1]
+	*dest[0].(*string) = pair[0]
+	switch d := dest[1].(type) {
+	case *string:
+		*d = pair[1]
+	case *models.ContentOverrideAction:
+		*d = models.ContentOverrideAction(pair[1])
+	}
+	return nil
+}
+
+func TestFilterNames(t *testing.T) {
+	pg := &MockPgPool{QueryFunc: func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+		if strings.Contains(sql, "content_filter_overrides") {
+			return &pairRows{pairs: [][2]string{{"staff", "allow"}, {"banned", "block"}}}, nil
+		}
+		// "sarge" is the forum name of the account linked to GUID sarge
+		return &pairRows{pairs: [][2]string{{"sarge", "sarge"}}}, nil
+	}}
+	svc := NewContentFilterService(pg, ContentFilterConfig{
+		Mode:           models.ContentFilterMask,
+		Words:          []string{"noob"},
+		ProtectedNames: []string{"admin"},
+	})
+
+	names := map[string]string{
+		"clean":  "Rambo",
+		"rude":   "NoobSlayer",
+		"fake":   "[4DM1N] Bob",
+		"staff":  "Admin",
+		"banned": "Nice Name",
+		"sarge":  "Sarge",
+		"copy":   "sarge",
+		"tennis": "Badminton",
+	}
+	flagged, err := svc.FilterNames(context.Background(), names)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"clean":  "Rambo",
+		"rude":   "****Slayer",
+		"fake":   maskedName("fake"),
+		"staff":  "Admin",
+		"banned": maskedName("banned"),
+		"sarge":  "Sarge",
+		"copy":   maskedName("copy"),
+		"tennis": "Badminton",
+	}
+	for guid, name := range want {
+		if names[guid] != name {
+			t.Errorf("name of %s = %q, want %q", guid, names[guid], name)
+		}
+	}
+	for _, guid := range []string{"rude", "fake", "banned", "copy"} {
+		if !flagged[guid] {
+			t.Errorf("%s not flagged", guid)
+		}
+	}
+	if len(flagged) != 4 {
+		t.Errorf("flagged = %v, want 4 players", flagged)
+	}
+}
+
+func TestFilterNamesOff(t *testing.T) {
+	svc := NewContentFilterService(nil, ContentFilterConfig{Mode: models.ContentFilterOff, Words: []string{"noob"}})
+	names := map[string]string{"a": "noob"}
+	if flagged, err := svc.FilterNames(context.Background(), names); err != nil || len(flagged) != 0 || names["a"] != "noob" {
+		t.Errorf("off mode changed names: %v, %v, %v", names, flagged, err)
+	}
+}
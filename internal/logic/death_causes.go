@@ -0,0 +1,204 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// Death causes, in the order deaths_by_cause lists them
+var deathCauses = []string{
+	"combat", "explosives", "falling", "drowning", "crushed", "telefrag", "suicide", "environment",
+}
+
+// environmentalCauses are the causes not dealt by another player's weapon
+var environmentalCauses = map[string]bool{
+	"falling":     true,
+	"drowning":    true,
+	"crushed":     true,
+	"telefrag":    true,
+	"environment": true,
+}
+
+// explosiveHints match means-of-death and inflictor names of explosives
+var explosiveHints = []string{
+	"grenade", "explo", "rocket", "bazooka", "panzer", "mortar", "mine", "artillery", "satchel", "shrapnel",
+}
+
+// deathEventTypes are the events whose target died
+const deathEventTypes = `'player_kill', 'player_bash', 'player_roadkill', 'player_teamkill',
+	'player_suicide', 'player_crushed', 'player_telefragged'`
+
+// deathCause classifies a death from its event type, means of death (mod),
+// inflictor and whether no player dealt it
+func deathCause(eventType, mod, inflictor string, world bool) string {
+	mod, inflictor = strings.ToLower(mod), strings.ToLower(inflictor)
+	switch {
+	case eventType == "player_crushed" || strings.Contains(mod, "crush"):
+		return "crushed"
+	case eventType == "player_telefragged" || strings.Contains(mod, "telefrag"):
+		return "telefrag"
+	case strings.Contains(mod, "fall"):
+		return "falling"
+	case strings.Contains(mod, "drown") || strings.Contains(mod, "water"):
+		return "drowning"
+	}
+	for _, hint := range explosiveHints {
+		if strings.Contains(mod, hint) || strings.Contains(inflictor, hint) {
+			return "explosives"
+		}
+	}
+	switch {
+	case eventType == "player_suicide":
+		return "suicide"
+	case world:
+		return "environment"
+	}
+	return "combat"
+}
+
+// deathRow is the weighted death count of one event type/mod/inflictor mix
+type deathRow struct {
+	mapName, eventType, mod, inflictor string
+	world                              bool
+	deaths                             uint64
+}
+
+// fillDeathCauses splits the player's deaths by cause
+func (s *playerStatsService) fillDeathCauses(ctx context.Context, guid string, out *[]models.DeathCauseStat) error {
+	rows, err := s.ch.Query(ctx, `
+		SELECT
+			event_type,
+			JSONExtractString(raw_json, 'mod') AS mod,
+			JSONExtractString(raw_json, 'inflictor') AS inflictor,
+			actor_id IN ('', 'world') AS world,
+			sum(sample_weight) AS deaths
+		FROM mohaa_stats.raw_events
+		WHERE target_id = ? AND event_type IN (`+deathEventTypes+`)`+RoundPhaseFilter(ctx, "")+`
+		GROUP BY event_type, mod, inflictor, world
+	`, guid)
+	if err != nil {
+		return fmt.Errorf("failed to query deaths by cause: %w", err)
+	}
+	defer rows.Close()
+
+	var deaths []deathRow
+	for rows.Next() {
+		var d deathRow
+		var world uint8
+		if err := rows.Scan(&d.eventType, &d.mod, &d.inflictor, &world, &d.deaths); err != nil {
+			return fmt.Errorf("failed to scan deaths by cause: %w", err)
+		}
+		d.world = world == 1
+		deaths = append(deaths, d)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to scan deaths by cause: %w", err)
+	}
+
+	*out = deathsByCause(deaths)
+	return nil
+}
+
+// GetEnvironmentalMapDeaths ranks maps by deaths from the environment rather
+// than other players
+func (s *serverStatsService) GetEnvironmentalMapDeaths(ctx context.Context, limit int) ([]models.MapEnvironmentDeaths, error) {
+	tenantID := TenantFromContext(ctx)
+	rows, err := s.ch.Query(ctx, `
+		SELECT
+			map_name,
+			event_type,
+			JSONExtractString(raw_json, 'mod') AS mod,
+			JSONExtractString(raw_json, 'inflictor') AS inflictor,
+			actor_id IN ('', 'world') AS world,
+			sum(sample_weight) AS deaths
+		FROM mohaa_stats.raw_events
+		WHERE event_type IN (`+deathEventTypes+`) AND map_name != ''
+		  AND (? = '' OR tenant_id = ?)`+RoundPhaseFilter(ctx, "")+`
+		GROUP BY map_name, event_type, mod, inflictor, world
+	`, tenantID, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query environmental deaths: %w", err)
+	}
+	defer rows.Close()
+
+	var deaths []deathRow
+	for rows.Next() {
+		var d deathRow
+		var world uint8
+		if err := rows.Scan(&d.mapName, &d.eventType, &d.mod, &d.inflictor, &world, &d.deaths); err != nil {
+			return nil, fmt.Errorf("failed to scan environmental deaths: %w", err)
+		}
+		d.world = world == 1
+		deaths = append(deaths, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan environmental deaths: %w", err)
+	}
+
+	return environmentalMapDeaths(deaths, limit), nil
+}
+
+// deathsByCause totals deaths per cause in deathCauses order, leaving out
+// causes without deaths
+func deathsByCause(deaths []deathRow) []models.DeathCauseStat {
+	counts := make(map[string]uint64)
+	var total uint64
+	for _, d := range deaths {
+		counts[deathCause(d.eventType, d.mod, d.inflictor, d.world)] += d.deaths
+		total += d.deaths
+	}
+
+	result := []models.DeathCauseStat{}
+	for _, cause := range deathCauses {
+		if counts[cause] == 0 {
+			continue
+		}
+		result = append(result, models.DeathCauseStat{
+			Cause:  cause,
+			Deaths: counts[cause],
+			Share:  float64(counts[cause]) / float64(total) * 100,
+		})
+	}
+	return result
+}
+
+// environmentalMapDeaths totals deaths per map and ranks the maps with
+// environmental deaths, most first
+func environmentalMapDeaths(deaths []deathRow, limit int) []models.MapEnvironmentDeaths {
+	byMap := make(map[string]*models.MapEnvironmentDeaths)
+	for _, d := range deaths {
+		m, ok := byMap[d.mapName]
+		if !ok {
+			m = &models.MapEnvironmentDeaths{MapName: d.mapName, Causes: make(map[string]uint64)}
+			byMap[d.mapName] = m
+		}
+		m.Deaths += d.deaths
+		if cause := deathCause(d.eventType, d.mod, d.inflictor, d.world); environmentalCauses[cause] {
+			m.Causes[cause] += d.deaths
+			m.EnvironmentDeaths += d.deaths
+		}
+	}
+
+	result := []models.MapEnvironmentDeaths{}
+	for _, m := range byMap {
+		if m.EnvironmentDeaths == 0 {
+			continue
+		}
+		m.EnvironmentShare = float64(m.EnvironmentDeaths) / float64(m.Deaths) * 100
+		result = append(result, *m)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].EnvironmentDeaths != result[j].EnvironmentDeaths {
+			return result[i].EnvironmentDeaths > result[j].EnvironmentDeaths
+		}
+		return result[i].MapName < result[j].MapName
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
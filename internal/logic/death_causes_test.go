@@ -0,0 +1,59 @@
+package logic
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDeathCause(t *testing.T) {
+	cases := []struct {
+		eventType, mod, inflictor string
+		world                     bool
+		want                      string
+	}{
+		{"player_kill", "MOD_RIFLE", "", false, "combat"},
+		{"player_kill", "MOD_GRENADE", "", false, "explosives"},
+		{"player_kill", "", "projectile_rocket", false, "explosives"},
+		{"player_kill", "MOD_FALLING", "", true, "falling"},
+		{"player_kill", "MOD_WATER", "", true, "drowning"},
+		{"player_kill", "MOD_LAVA", "", true, "environment"},
+		{"player_crushed", "", "", true, "crushed"},
+		{"player_telefragged", "", "", false, "telefrag"},
+		{"player_suicide", "MOD_SUICIDE", "", false, "suicide"},
+		{"player_suicide", "MOD_EXPLOSION", "", false, "explosives"},
+	}
+	for _, c := range cases {
+		if got := deathCause(c.eventType, c.mod, c.inflictor, c.world); got != c.want {
+			t.Errorf("deathCause(%q, %q, %q, %v) = %q, want %q", c.eventType, c.mod, c.inflictor, c.world, got, c.want)
+		}
+	}
+}
+
+func TestDeathsByCauseAndMaps(t *testing.T) {
+	deaths := []deathRow{
+		{mapName: "dm/mohdm1", eventType: "player_kill", mod: "MOD_RIFLE", deaths: 6},
+		{mapName: "dm/mohdm1", eventType: "player_kill", mod: "MOD_FALLING", world: true, deaths: 2},
+		{mapName: "obj/obj_team2", eventType: "player_crushed", world: true, deaths: 3},
+		{mapName: "obj/obj_team2", eventType: "player_kill", mod: "MOD_FALLING", world: true, deaths: 1},
+		{mapName: "dm/mohdm2", eventType: "player_kill", mod: "MOD_SMG", deaths: 9},
+	}
+
+	causes := deathsByCause(deaths)
+	if len(causes) != 3 || causes[0].Cause != "combat" || causes[0].Deaths != 15 || causes[1].Cause != "falling" || causes[2].Cause != "crushed" {
+		t.Fatalf("causes = %+v, want combat, falling, crushed", causes)
+	}
+	if math.Abs(causes[1].Share-100.0/7) > 1e-9 {
+		t.Errorf("falling share = %v", causes[1].Share)
+	}
+
+	maps := environmentalMapDeaths(deaths, 10)
+	if len(maps) != 2 || maps[0].MapName != "obj/obj_team2" || maps[1].MapName != "dm/mohdm1" {
+		t.Fatalf("maps = %+v, want obj_team2 then mohdm1, without the map lacking environmental deaths", maps)
+	}
+	if m := maps[1]; m.Deaths != 8 || m.EnvironmentDeaths != 2 || m.EnvironmentShare != 25 || m.Causes["falling"] != 2 {
+		t.Errorf("mohdm1 = %+v", m)
+	}
+	if got := environmentalMapDeaths(deaths, 1); len(got) != 1 {
+		t.Errorf("limit not applied: %d maps", len(got))
+	}
+}
@@ -0,0 +1,214 @@
+package logic
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+var (
+	// ErrInvalidDemo is returned for a demo that cannot be registered
+	ErrInvalidDemo = errors.New("invalid demo")
+	// ErrDemoNotFound is returned for an unknown demo ID
+	ErrDemoNotFound = errors.New("demo not found")
+	// ErrDemoServer is returned when a server registers a demo of a match
+	// another server played
+	ErrDemoServer = errors.New("match was played on another server")
+)
+
+// ValidateMatchDemo checks a demo before it is registered and normalizes its
+// match ID and checksum to lowercase. The filename is a bare file name, the checksum a
+// SHA-256 in hex and the download URL absolute http or https.
+func ValidateMatchDemo(req *models.MatchDemoRequest) error {
+	req.MatchID = strings.ToLower(strings.TrimSpace(req.MatchID))
+	req.Filename = strings.TrimSpace(req.Filename)
+	req.Checksum = strings.ToLower(strings.TrimSpace(req.Checksum))
+	req.DownloadURL = strings.TrimSpace(req.DownloadURL)
+
+	if _, err := uuid.Parse(req.MatchID); err != nil {
+		return fmt.Errorf("%w: match_id must be a match UUID", ErrInvalidDemo)
+	}
+	if req.Filename == "" || len(req.Filename) > 255 || strings.ContainsAny(req.Filename, `/\`) || strings.Trim(req.Filename, ".") == "" {
+		return fmt.Errorf("%w: filename must be a file name of 1-255 characters", ErrInvalidDemo)
+	}
+	if b, err := hex.DecodeString(req.Checksum); err != nil || len(b) != 32 {
+		return fmt.Errorf("%w: checksum must be a SHA-256 in hex", ErrInvalidDemo)
+	}
+	if req.SizeBytes < 0 {
+		return fmt.Errorf("%w: size_bytes must not be negative", ErrInvalidDemo)
+	}
+	u, err := url.Parse(req.DownloadURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(req.DownloadURL) > 2048 {
+		return fmt.Errorf("%w: download_url must be an http or https URL", ErrInvalidDemo)
+	}
+	return nil
+}
+
+type demoService struct {
+	pg PgPool
+	ch driver.Conn
+}
+
+func NewDemoService(pg PgPool, ch driver.Conn) DemoService {
+	return &demoService{pg: pg, ch: ch}
+}
+
+const demoColumns = `id, match_id, server_id, filename, checksum, size_bytes, download_url, registered_by, created_at, updated_at`
+
+func scanDemo(row pgx.Row) (*models.MatchDemo, error) {
+	d := &models.MatchDemo{}
+	err := row.Scan(&d.ID, &d.MatchID, &d.ServerID, &d.Filename, &d.Checksum, &d.SizeBytes,
+		&d.DownloadURL, &d.RegisteredBy, &d.CreatedAt, &d.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrDemoNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read demo: %w", err)
+	}
+	return d, nil
+}
+
+// Register records a demo of one of the server's matches. Registering the
+// same recording again, by checksum, updates its file name and URL.
+func (s *demoService) Register(ctx context.Context, serverID string, req *models.MatchDemoRequest) (*models.MatchDemo, error) {
+	if err := ValidateMatchDemo(req); err != nil {
+		return nil, err
+	}
+
+	var events uint64
+	var matchServer, mapName string
+	var startedAt time.Time
+	if err := s.ch.QueryRow(ctx, `
+		SELECT count(), any(server_id), any(map_name), min(timestamp)
+		FROM mohaa_stats.raw_events
+		WHERE match_id = toUUID(?)
+	`, req.MatchID).Scan(&events, &matchServer, &mapName, &startedAt); err != nil {
+		return nil, fmt.Errorf("failed to read match: %w", err)
+	}
+	if events == 0 {
+		return nil, ErrMatchNotFound
+	}
+	if matchServer != serverID {
+		return nil, ErrDemoServer
+	}
+
+	return scanDemo(s.pg.QueryRow(ctx, `
+		INSERT INTO match_demos
+			(match_id, server_id, map_name, started_at, filename, checksum, size_bytes, download_url, registered_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $2)
+		ON CONFLICT (match_id, checksum) DO UPDATE SET
+			filename = EXCLUDED.filename, size_bytes = EXCLUDED.size_bytes,
+			download_url = EXCLUDED.download_url, updated_at = NOW()
+		RETURNING `+demoColumns,
+		req.MatchID, serverID, mapName, startedAt, req.Filename, req.Checksum, req.SizeBytes, req.DownloadURL))
+}
+
+// ForMatch returns the demos of a match, oldest first
+func (s *demoService) ForMatch(ctx context.Context, matchID string) ([]models.MatchDemo, error) {
+	rows, err := s.pg.Query(ctx, `
+		SELECT `+demoColumns+` FROM match_demos
+		WHERE match_id = $1
+		ORDER BY id
+	`, matchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list demos: %w", err)
+	}
+	defer rows.Close()
+
+	demos := []models.MatchDemo{}
+	for rows.Next() {
+		d, err := scanDemo(rows)
+		if err != nil {
+			return nil, err
+		}
+		demos = append(demos, *d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list demos: %w", err)
+	}
+	return demos, nil
+}
+
+// Counts returns how many demos each of the matches has; matches without
+// any are left out
+func (s *demoService) Counts(ctx context.Context, matchIDs []string) (map[string]int, error) {
+	counts := make(map[string]int)
+	if len(matchIDs) == 0 {
+		return counts, nil
+	}
+	rows, err := s.pg.Query(ctx, `
+		SELECT match_id, count(*) FROM match_demos
+		WHERE match_id = ANY($1)
+		GROUP BY match_id
+	`, matchIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count demos: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var matchID string
+		var n int
+		if err := rows.Scan(&matchID, &n); err != nil {
+			return nil, fmt.Errorf("failed to count demos: %w", err)
+		}
+		counts[matchID] = n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to count demos: %w", err)
+	}
+	return counts, nil
+}
+
+// Matches lists the matches with demos, newest first, optionally of one
+// server or map, limited to the tenant's servers
+func (s *demoService) Matches(ctx context.Context, serverID, mapName string, limit, offset int) ([]models.DemoMatch, error) {
+	rows, err := s.pg.Query(ctx, `
+		SELECT match_id, server_id, map_name, started_at, count(*), max(created_at)
+		FROM match_demos
+		WHERE ($1 = '' OR server_id = $1)
+		  AND ($2 = '' OR map_name = $2)
+		  AND ($3 = '' OR server_id IN (SELECT id::text FROM servers WHERE tenant_id::text = $3))
+		GROUP BY match_id, server_id, map_name, started_at
+		ORDER BY started_at DESC, match_id
+		LIMIT $4 OFFSET $5
+	`, serverID, mapName, TenantFromContext(ctx), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list demo matches: %w", err)
+	}
+	defer rows.Close()
+
+	matches := []models.DemoMatch{}
+	for rows.Next() {
+		var m models.DemoMatch
+		if err := rows.Scan(&m.MatchID, &m.ServerID, &m.MapName, &m.StartedAt, &m.Demos, &m.LastDemoAt); err != nil {
+			return nil, fmt.Errorf("failed to list demo matches: %w", err)
+		}
+		matches = append(matches, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list demo matches: %w", err)
+	}
+	return matches, nil
+}
+
+// Delete removes a demo, e.g. once the archive no longer has it
+func (s *demoService) Delete(ctx context.Context, id int64) error {
+	tag, err := s.pg.Exec(ctx, `DELETE FROM match_demos WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete demo: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrDemoNotFound
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package logic
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestValidateMatchDemo(t *testing.T) {
+	checksum := strings.Repeat("AB", 32)
+	valid := func() models.MatchDemoRequest {
+		return models.MatchDemoRequest{
+			MatchID:     "4f1c2a9e-7a1b-4c7e-9a55-0c8d7b3f2e11",
+			Filename:    " final_obj_team2.dm_3 ",
+			Checksum:    checksum,
+			SizeBytes:   1 << 20,
+			DownloadURL: "https://demos.example.org/2026/final_obj_team2.dm_3",
+		}
+	}
+
+	req := valid()
+	if err := ValidateMatchDemo(&req); err != nil {
+		t.Fatalf("valid demo rejected: %v", err)
+	}
+	if req.Filename != "final_obj_team2.dm_3" || req.Checksum != strings.ToLower(checksum) {
+		t.Errorf("not normalized: %+v", req)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*models.MatchDemoRequest)
+	}{
+		{"Bad Match ID", func(r *models.MatchDemoRequest) { r.MatchID = "match-1" }},
+		{"No Filename", func(r *models.MatchDemoRequest) { r.Filename = "  " }},
+		{"Path", func(r *models.MatchDemoRequest) { r.Filename = "../secret.dm_3" }},
+		{"Windows Path", func(r *models.MatchDemoRequest) { r.Filename = `demos\final.dm_3` }},
+		{"Dot", func(r *models.MatchDemoRequest) { r.Filename = ".." }},
+		{"Short Checksum", func(r *models.MatchDemoRequest) { r.Checksum = "abcdef" }},
+		{"Not Hex", func(r *models.MatchDemoRequest) { r.Checksum = strings.Repeat("zz", 32) }},
+		{"Negative Size", func(r *models.MatchDemoRequest) { r.SizeBytes = -1 }},
+		{"Relative URL", func(r *models.MatchDemoRequest) { r.DownloadURL = "/demos/final.dm_3" }},
+		{"FTP URL", func(r *models.MatchDemoRequest) { r.DownloadURL = "ftp://demos.example.org/final.dm_3" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid()
+			tt.modify(&req)
+			if err := ValidateMatchDemo(&req); !errors.Is(err, ErrInvalidDemo) {
+				t.Errorf("err = %v, want ErrInvalidDemo", err)
+			}
+		})
+	}
+}
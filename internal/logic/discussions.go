@@ -0,0 +1,203 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+var (
+	// ErrInvalidDiscussion is returned for a thread link that cannot be stored
+	ErrInvalidDiscussion = errors.New("invalid discussion thread")
+	// ErrDiscussionNotFound is returned for a match or player without a thread
+	ErrDiscussionNotFound = errors.New("discussion thread not found")
+)
+
+// maxDiscussionLookup bounds the IDs of one batch lookup
+const maxDiscussionLookup = 100
+
+// ValidDiscussionSubject reports whether threads can be about subject
+func ValidDiscussionSubject(subject models.DiscussionSubject) bool {
+	return subject == models.DiscussMatch || subject == models.DiscussPlayer
+}
+
+// ValidateDiscussionLink checks a thread link before it is stored. The URL
+// is optional; when set it must be absolute http or https.
+func ValidateDiscussionLink(req *models.DiscussionLinkRequest) error {
+	req.URL = strings.TrimSpace(req.URL)
+	if req.TopicID <= 0 {
+		return fmt.Errorf("%w: topic_id must be a forum topic ID", ErrInvalidDiscussion)
+	}
+	if req.URL != "" {
+		u, err := url.Parse(req.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(req.URL) > 2048 {
+			return fmt.Errorf("%w: url must be an http or https URL", ErrInvalidDiscussion)
+		}
+	}
+	return nil
+}
+
+type discussionService struct {
+	pg PgPool
+}
+
+func NewDiscussionService(pg PgPool) DiscussionService {
+	return &discussionService{pg: pg}
+}
+
+const discussionColumns = `subject_type, subject_id, topic_id, url, posts, linked_by, created_at, posts_checked_at`
+
+func scanDiscussion(row pgx.Row) (*models.DiscussionThread, error) {
+	d := &models.DiscussionThread{}
+	err := row.Scan(&d.SubjectType, &d.SubjectID, &d.TopicID, &d.URL, &d.Posts, &d.LinkedBy, &d.CreatedAt, &d.PostsCheckedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrDiscussionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read discussion thread: %w", err)
+	}
+	return d, nil
+}
+
+func (s *discussionService) list(ctx context.Context, query string, args ...any) ([]models.DiscussionThread, error) {
+	rows, err := s.pg.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list discussion threads: %w", err)
+	}
+	defer rows.Close()
+
+	var threads []models.DiscussionThread
+	for rows.Next() {
+		d, err := scanDiscussion(rows)
+		if err != nil {
+			return nil, err
+		}
+		threads = append(threads, *d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list discussion threads: %w", err)
+	}
+	return threads, nil
+}
+
+// Get returns the thread of a match or player
+func (s *discussionService) Get(ctx context.Context, subject models.DiscussionSubject, id string) (*models.DiscussionThread, error) {
+	return scanDiscussion(s.pg.QueryRow(ctx, `
+		SELECT `+discussionColumns+` FROM discussion_threads
+		WHERE subject_type = $1 AND subject_id = $2
+	`, subject, id))
+}
+
+// Lookup returns the threads of several matches or players, keyed by ID;
+// those without one are left out
+func (s *discussionService) Lookup(ctx context.Context, subject models.DiscussionSubject, ids []string) (map[string]models.DiscussionThread, error) {
+	if len(ids) > maxDiscussionLookup {
+		return nil, fmt.Errorf("%w: at most %d IDs at once", ErrInvalidDiscussion, maxDiscussionLookup)
+	}
+	threads := make(map[string]models.DiscussionThread, len(ids))
+	if len(ids) == 0 {
+		return threads, nil
+	}
+	list, err := s.list(ctx, `
+		SELECT `+discussionColumns+` FROM discussion_threads
+		WHERE subject_type = $1 AND subject_id = ANY($2)
+	`, subject, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range list {
+		threads[d.SubjectID] = d
+	}
+	return threads, nil
+}
+
+// Link maps a match or player to a forum topic, replacing its thread. An
+// empty actor is the featured match job.
+func (s *discussionService) Link(ctx context.Context, subject models.DiscussionSubject, id string, req *models.DiscussionLinkRequest, actor string) (*models.DiscussionThread, error) {
+	if !ValidDiscussionSubject(subject) {
+		return nil, fmt.Errorf("%w: threads are about a match or a player", ErrInvalidDiscussion)
+	}
+	if id = strings.TrimSpace(id); id == "" || len(id) > 64 {
+		return nil, fmt.Errorf("%w: the %s ID must be 1-64 characters", ErrInvalidDiscussion, subject)
+	}
+	if err := ValidateDiscussionLink(req); err != nil {
+		return nil, err
+	}
+	return scanDiscussion(s.pg.QueryRow(ctx, `
+		INSERT INTO discussion_threads (subject_type, subject_id, topic_id, url, linked_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (subject_type, subject_id) DO UPDATE SET
+			topic_id = EXCLUDED.topic_id, url = EXCLUDED.url, linked_by = EXCLUDED.linked_by,
+			posts = CASE WHEN discussion_threads.topic_id = EXCLUDED.topic_id THEN discussion_threads.posts ELSE 0 END,
+			posts_checked_at = CASE WHEN discussion_threads.topic_id = EXCLUDED.topic_id THEN discussion_threads.posts_checked_at END
+		RETURNING `+discussionColumns,
+		subject, id, req.TopicID, req.URL, actor))
+}
+
+// Unlink removes the thread of a match or player; the forum topic stays
+func (s *discussionService) Unlink(ctx context.Context, subject models.DiscussionSubject, id string) error {
+	tag, err := s.pg.Exec(ctx, `
+		DELETE FROM discussion_threads WHERE subject_type = $1 AND subject_id = $2
+	`, subject, id)
+	if err != nil {
+		return fmt.Errorf("failed to unlink discussion thread: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrDiscussionNotFound
+	}
+	return nil
+}
+
+// Missing returns the IDs without a thread, in the order given
+func (s *discussionService) Missing(ctx context.Context, subject models.DiscussionSubject, ids []string) ([]string, error) {
+	threads, err := s.Lookup(ctx, subject, ids)
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for _, id := range ids {
+		if _, ok := threads[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	return missing, nil
+}
+
+// Recent returns the threads linked since `since`, whose post counts are
+// still worth refreshing
+func (s *discussionService) Recent(ctx context.Context, since time.Time) ([]models.DiscussionThread, error) {
+	return s.list(ctx, `
+		SELECT `+discussionColumns+` FROM discussion_threads
+		WHERE created_at >= $1
+		ORDER BY created_at DESC
+	`, since)
+}
+
+// SetPosts stores the post counts read from the forum, by topic ID
+func (s *discussionService) SetPosts(ctx context.Context, posts map[int]int) error {
+	if len(posts) == 0 {
+		return nil
+	}
+	topics := make([]int32, 0, len(posts))
+	counts := make([]int32, 0, len(posts))
+	for topic, n := range posts {
+		topics = append(topics, int32(topic))
+		counts = append(counts, int32(n))
+	}
+	if _, err := s.pg.Exec(ctx, `
+		UPDATE discussion_threads d
+		SET posts = p.posts, posts_checked_at = NOW()
+		FROM unnest($1::int[], $2::int[]) AS p(topic_id, posts)
+		WHERE d.topic_id = p.topic_id
+	`, topics, counts); err != nil {
+		return fmt.Errorf("failed to store discussion post counts: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package logic
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestValidateDiscussionLink(t *testing.T) {
+	tests := []struct {
+		name  string
+		req   models.DiscussionLinkRequest
+		valid bool
+	}{
+		{"Topic Only", models.DiscussionLinkRequest{TopicID: 12}, true},
+		{"With URL", models.DiscussionLinkRequest{TopicID: 12, URL: " https://forum.example.com/index.php?topic=12.0 "}, true},
+		{"No Topic", models.DiscussionLinkRequest{URL: "https://forum.example.com/index.php?topic=12.0"}, false},
+		{"Negative Topic", models.DiscussionLinkRequest{TopicID: -1}, false},
+		{"Relative URL", models.DiscussionLinkRequest{TopicID: 12, URL: "index.php?topic=12.0"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			err := ValidateDiscussionLink(&req)
+			if tt.valid && err != nil {
+				t.Errorf("rejected: %v", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidDiscussion) {
+				t.Errorf("err = %v, want ErrInvalidDiscussion", err)
+			}
+		})
+	}
+
+	for subject, want := range map[models.DiscussionSubject]bool{
+		models.DiscussMatch: true, models.DiscussPlayer: true, "server": false, "": false,
+	} {
+		if got := ValidDiscussionSubject(subject); got != want {
+			t.Errorf("ValidDiscussionSubject(%q) = %v", subject, got)
+		}
+	}
+}
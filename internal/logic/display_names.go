@@ -0,0 +1,73 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openmohaa/stats-api/internal/db"
+	"github.com/openmohaa/stats-api/internal/sanitize"
+)
+
+type playerNamesService struct {
+	pg    PgPool
+	redis db.LiveStateStore
+}
+
+func NewPlayerNamesService(pg PgPool, redis db.LiveStateStore) PlayerNamesService {
+	return &playerNamesService{pg: pg, redis: redis}
+}
+
+// DisplayNames resolves the name ranked endpoints show for each GUID in
+// fallbacks (GUID -> the name the stats query found). The forum name of a
+// linked SMF account wins, then the last name the worker saw the player use
+// (player_names), then the fallback. Every name is cleaned. On error the
+// names resolved so far are returned with it.
+func (s *playerNamesService) DisplayNames(ctx context.Context, fallbacks map[string]string) (map[string]string, error) {
+	names := make(map[string]string, len(fallbacks))
+	guids := make([]string, 0, len(fallbacks))
+	for guid, name := range fallbacks {
+		names[guid] = sanitize.Name(name)
+		guids = append(guids, guid)
+	}
+	if len(guids) == 0 {
+		return names, nil
+	}
+
+	if s.redis != nil {
+		pipe := s.redis.Pipeline()
+		lookups := make(map[string]*db.StringResult, len(guids))
+		for _, guid := range guids {
+			lookups[guid] = pipe.HGet(ctx, "player_names", guid)
+		}
+		// Misses fail the pipeline too; each lookup is checked below
+		pipe.Exec(ctx)
+		for guid, cmd := range lookups {
+			if name, err := cmd.Result(); err == nil {
+				if name = sanitize.Name(name); name != "" {
+					names[guid] = name
+				}
+			}
+		}
+	}
+
+	rows, err := s.pg.Query(ctx, `
+		SELECT r.player_guid, m.smf_username
+		FROM player_guid_registry r
+		JOIN smf_user_mappings m ON m.smf_member_id = r.smf_member_id
+		WHERE r.player_guid = ANY($1) AND COALESCE(m.smf_username, '') <> ''
+	`, guids)
+	if err != nil {
+		return names, fmt.Errorf("failed to read forum names: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var guid, name string
+		if err := rows.Scan(&guid, &name); err != nil {
+			return names, fmt.Errorf("failed to read forum names: %w", err)
+		}
+		if name = sanitize.Name(name); name != "" {
+			names[guid] = name
+		}
+	}
+	return names, rows.Err()
+}
@@ -0,0 +1,54 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/openmohaa/stats-api/internal/db"
+)
+
+// forumNameRows serves (guid, smf_username) pairs
+type forumNameRows struct {
+	MockPgRows
+	pairs [][2]string
+}
+
+func (r *forumNameRows) Next() bool {
+	r.curr++
+	return r.curr <= len(r.pairs)
+}
+
+func (r *forumNameRows) Scan(dest ...any) error {
+	pair := r.pairs[r.curr-1]
+	*dest[0].(*string), *dest[1].(*string) = pair[0], pair[1]
+	return nil
+}
+
+func TestDisplayNames(t *testing.T) {
+	ctx := context.Background()
+	state := db.NewMemoryLiveState()
+	state.HSet(ctx, "player_names", "b", "^1Bravo^7 ", "c", "^3")
+
+	pg := &MockPgPool{QueryFunc: func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+		return &forumNameRows{pairs: [][2]string{{"a", "ForumAlpha"}}}, nil
+	}}
+	svc := NewPlayerNamesService(pg, state)
+
+	names, err := svc.DisplayNames(ctx, map[string]string{
+		"a": "^2alpha",   // linked forum account
+		"b": "old bravo", // renamed since
+		"c": "^5Charlie", // live name is only color codes
+		"d": " ^4Delta ",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"a": "ForumAlpha", "b": "Bravo", "c": "Charlie", "d": "Delta"}
+	for guid, name := range want {
+		if names[guid] != name {
+			t.Errorf("name of %s = %q, want %q", guid, names[guid], name)
+		}
+	}
+}
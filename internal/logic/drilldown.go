@@ -140,8 +140,8 @@ func (s *DrilldownService) getBaseStat(ctx context.Context, guid string, stat st
 				shots as sample
 			FROM (
 				SELECT 
-					countIf(event_type = 'weapon_fire' AND actor_id = ?) as shots,
-					countIf(event_type = 'weapon_hit' AND actor_id = ?) as hits
+					sumIf(sample_weight, event_type = 'weapon_fire' AND actor_id = ?) as shots,
+					sumIf(sample_weight, event_type = 'weapon_hit' AND actor_id = ?) as hits
 				FROM raw_events
 				WHERE actor_id = ?
 			)
@@ -311,9 +311,9 @@ func (s *DrilldownService) getStatExpression(stat, guid string) string {
 		)`, guid, guid, guid, guid)
 	case "accuracy":
 		return fmt.Sprintf(`if(
-			countIf(event_type = 'weapon_fire' AND actor_id = '%s') > 0,
-			countIf(event_type = 'weapon_hit' AND actor_id = '%s') / 
-			countIf(event_type = 'weapon_fire' AND actor_id = '%s') * 100,
+			sumIf(sample_weight, event_type = 'weapon_fire' AND actor_id = '%s') > 0,
+			sumIf(sample_weight, event_type = 'weapon_hit' AND actor_id = '%s') / 
+			sumIf(sample_weight, event_type = 'weapon_fire' AND actor_id = '%s') * 100,
 			0
 		)`, guid, guid, guid)
 	case "kills":
@@ -397,8 +397,8 @@ func (s *DrilldownService) GetStatLeaders(ctx context.Context, stat, dimension,
 			any(actor_name) as player_name,
 			countIf(event_type IN ('player_kill', 'bot_killed')) as kills,
 			countIf(event_type = 'death') as deaths,
-			countIf(event_type = 'weapon_fire') as shots,
-			countIf(event_type = 'weapon_hit') as hits,
+			sumIf(sample_weight, event_type = 'weapon_fire') as shots,
+			sumIf(sample_weight, event_type = 'weapon_hit') as hits,
 			%s
 		FROM raw_events
 		WHERE %s = ? AND actor_id != ''
@@ -0,0 +1,112 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// eventSearchWindow is how far back a search without a match or a time range
+// looks, so an unfiltered search doesn't scan every partition
+const eventSearchWindow = 24 * time.Hour
+
+// SearchEvents returns one page of stored events matching the filters, newest
+// first, for the admin event explorer
+func (s *ServerTrackingService) SearchEvents(ctx context.Context, search models.EventSearch) (*models.EventSearchResult, error) {
+	if search.From.IsZero() && search.To.IsZero() && search.MatchID == "" {
+		search.From = time.Now().UTC().Add(-eventSearchWindow)
+	}
+	where, args := eventSearchWhere(search, TenantFromContext(ctx))
+	table := "mohaa_stats.raw_events"
+	if search.Sandbox {
+		table = "mohaa_stats.raw_events_sandbox"
+	}
+
+	// One extra row tells whether there is a next page
+	rows, err := s.ch.Query(ctx, `
+		SELECT
+			timestamp, toString(match_id), server_id, tenant_id, map_name, event_type,
+			round_phase, sample_weight,
+			actor_id, actor_name, actor_team, actor_weapon,
+			target_id, target_name, target_team,
+			damage, hitloc, raw_json
+		FROM `+table+`
+		WHERE `+where+`
+		ORDER BY timestamp DESC
+		LIMIT ? OFFSET ?
+	`, append(args, search.Limit+1, search.Offset)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search events: %w", err)
+	}
+	defer rows.Close()
+
+	result := &models.EventSearchResult{
+		Events:  []models.StoredEvent{},
+		From:    search.From,
+		To:      search.To,
+		Limit:   search.Limit,
+		Offset:  search.Offset,
+		Sandbox: search.Sandbox,
+	}
+	for rows.Next() {
+		var e models.StoredEvent
+		var rawJSON string
+		if err := rows.Scan(
+			&e.Timestamp, &e.MatchID, &e.ServerID, &e.TenantID, &e.MapName, &e.EventType,
+			&e.RoundPhase, &e.SampleWeight,
+			&e.ActorID, &e.ActorName, &e.ActorTeam, &e.ActorWeapon,
+			&e.TargetID, &e.TargetName, &e.TargetTeam,
+			&e.Damage, &e.Hitloc, &rawJSON,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan events: %w", err)
+		}
+		if json.Valid([]byte(rawJSON)) {
+			e.RawJSON = json.RawMessage(rawJSON)
+		}
+		if len(result.Events) == search.Limit {
+			result.HasMore = true
+			break
+		}
+		result.Events = append(result.Events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan events: %w", err)
+	}
+	return result, nil
+}
+
+// eventSearchWhere builds the WHERE clause and its arguments for a search
+func eventSearchWhere(search models.EventSearch, tenantID string) (string, []interface{}) {
+	conds := []string{"(? = '' OR tenant_id = ?)"}
+	args := []interface{}{tenantID, tenantID}
+
+	if search.ServerID != "" {
+		conds = append(conds, "server_id = ?")
+		args = append(args, search.ServerID)
+	}
+	if search.MatchID != "" {
+		conds = append(conds, "match_id = toUUIDOrZero(?)")
+		args = append(args, search.MatchID)
+	}
+	if search.PlayerID != "" {
+		conds = append(conds, "(actor_id = ? OR target_id = ?)")
+		args = append(args, search.PlayerID, search.PlayerID)
+	}
+	if len(search.Types) > 0 {
+		conds = append(conds, "event_type IN ?")
+		args = append(args, search.Types)
+	}
+	if !search.From.IsZero() {
+		conds = append(conds, "timestamp >= ?")
+		args = append(args, search.From)
+	}
+	if !search.To.IsZero() {
+		conds = append(conds, "timestamp < ?")
+		args = append(args, search.To)
+	}
+	return strings.Join(conds, " AND "), args
+}
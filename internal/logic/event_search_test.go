@@ -0,0 +1,33 @@
+package logic
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestEventSearchWhere(t *testing.T) {
+	where, args := eventSearchWhere(models.EventSearch{}, "")
+	if where != "(? = '' OR tenant_id = ?)" || len(args) != 2 {
+		t.Errorf("empty search = %q %v, want only the tenant filter", where, args)
+	}
+
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	where, args = eventSearchWhere(models.EventSearch{
+		ServerID: "srv",
+		MatchID:  "m1",
+		PlayerID: "p1",
+		Types:    []string{"player_kill", "damage"},
+		From:     from,
+	}, "t1")
+	wantWhere := "(? = '' OR tenant_id = ?) AND server_id = ? AND match_id = toUUIDOrZero(?) AND (actor_id = ? OR target_id = ?) AND event_type IN ? AND timestamp >= ?"
+	if where != wantWhere {
+		t.Errorf("where = %q\nwant  %q", where, wantWhere)
+	}
+	wantArgs := []interface{}{"t1", "t1", "srv", "m1", "p1", "p1", []string{"player_kill", "damage"}, from}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args = %v, want %v", args, wantArgs)
+	}
+}
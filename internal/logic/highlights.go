@@ -0,0 +1,282 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// Minimum kills for a player of the period, so a lucky single round does not win
+var highlightMinKills = map[models.HighlightPeriod]int64{
+	models.HighlightDay:  10,
+	models.HighlightWeek: 50,
+}
+
+// HighlightScore is the composite performance score players of the day and
+// week are picked by: kills, with headshots and won matches on top, minus
+// half a point per death so a long session alone does not win
+func HighlightScore(kills, deaths, headshots, matchesWon int64) float64 {
+	score := float64(kills) + 0.5*float64(headshots) + 5*float64(matchesWon) - 0.5*float64(deaths)
+	return math.Round(score*10) / 10
+}
+
+// HighlightPeriodStart returns the start (UTC midnight) of the period containing t
+func HighlightPeriodStart(period models.HighlightPeriod, t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if period == models.HighlightWeek {
+		// Monday starts the week
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	}
+	return day
+}
+
+// HighlightPeriodEnd returns the start of the period after the one at start
+func HighlightPeriodEnd(period models.HighlightPeriod, start time.Time) time.Time {
+	if period == models.HighlightWeek {
+		return start.AddDate(0, 0, 7)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+type highlightsService struct {
+	ch driver.Conn
+	pg PgPool
+}
+
+func NewHighlightsService(ch driver.Conn, pg PgPool) HighlightsService {
+	return &highlightsService{ch: ch, pg: pg}
+}
+
+// highlightRow is one player's totals on one server for a period
+type highlightRow struct {
+	serverID, guid, name                  string
+	kills, deaths, headshots, played, won int64
+}
+
+// Compute picks the players of the period starting at start, per server and
+// per tenant across its servers, and stores them. It returns the stored picks.
+func (s *highlightsService) Compute(ctx context.Context, period models.HighlightPeriod, start time.Time) ([]models.PlayerHighlight, error) {
+	minKills, ok := highlightMinKills[period]
+	if !ok {
+		return nil, fmt.Errorf("unknown highlight period %q", period)
+	}
+	end := HighlightPeriodEnd(period, start)
+
+	rows, err := s.ch.Query(ctx, `
+		SELECT
+			server_id,
+			player_id,
+			anyLast(player_name) AS name,
+			toInt64(sum(kills)) AS kills,
+			toInt64(sum(deaths)) AS deaths,
+			toInt64(sum(headshots)) AS headshots,
+			toInt64(uniqExactMerge(matches_played)) AS played,
+			toInt64(sum(matches_won)) AS won
+		FROM mohaa_stats.player_server_stats_daily
+		WHERE day >= ? AND day < ? AND player_id != '' AND player_id != 'world'
+		GROUP BY server_id, player_id
+		HAVING kills > 0
+	`, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read period stats: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []highlightRow
+	for rows.Next() {
+		var r highlightRow
+		if err := rows.Scan(&r.serverID, &r.guid, &r.name, &r.kills, &r.deaths, &r.headshots, &r.played, &r.won); err != nil {
+			return nil, fmt.Errorf("failed to scan period stats: %w", err)
+		}
+		stats = append(stats, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read period stats: %w", err)
+	}
+
+	tenants, err := s.serverTenants(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	picks := pickHighlights(stats, tenants, minKills)
+	now := time.Now().UTC()
+	for i := range picks {
+		p := &picks[i]
+		p.Period, p.PeriodStart, p.ComputedAt = period, start, now
+		if _, err := s.pg.Exec(ctx, `
+			INSERT INTO player_highlights
+			(period, period_start, tenant_id, server_id, player_guid, player_name, score,
+			 kills, deaths, headshots, matches_played, matches_won, computed_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			ON CONFLICT (period, period_start, tenant_id, server_id) DO UPDATE SET
+				player_guid = EXCLUDED.player_guid, player_name = EXCLUDED.player_name,
+				score = EXCLUDED.score, kills = EXCLUDED.kills, deaths = EXCLUDED.deaths,
+				headshots = EXCLUDED.headshots, matches_played = EXCLUDED.matches_played,
+				matches_won = EXCLUDED.matches_won, computed_at = EXCLUDED.computed_at
+		`, period, start, p.TenantID, p.ServerID, p.PlayerGUID, p.PlayerName, p.Score,
+			p.Kills, p.Deaths, p.Headshots, p.MatchesPlayed, p.MatchesWon, now); err != nil {
+			return nil, fmt.Errorf("failed to store player highlight: %w", err)
+		}
+	}
+	return picks, nil
+}
+
+// serverTenants maps each registered server to its tenant ('' = default)
+func (s *highlightsService) serverTenants(ctx context.Context) (map[string]string, error) {
+	rows, err := s.pg.Query(ctx, `SELECT id, COALESCE(tenant_id::text, '') FROM servers`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read server tenants: %w", err)
+	}
+	defer rows.Close()
+	tenants := make(map[string]string)
+	for rows.Next() {
+		var id, tenant string
+		if err := rows.Scan(&id, &tenant); err != nil {
+			return nil, fmt.Errorf("failed to read server tenants: %w", err)
+		}
+		tenants[id] = tenant
+	}
+	return tenants, rows.Err()
+}
+
+// pickHighlights returns the best scoring eligible player of each server and
+// of each tenant's servers combined (ServerID ""), so a tenant's network-wide
+// pick never counts other tenants' servers
+func pickHighlights(stats []highlightRow, tenants map[string]string, minKills int64) []models.PlayerHighlight {
+	better := func(a, b *models.PlayerHighlight) bool {
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Kills != b.Kills {
+			return a.Kills > b.Kills
+		}
+		return a.PlayerGUID < b.PlayerGUID
+	}
+
+	perServer := make(map[string]*models.PlayerHighlight)
+	type tenantPlayer struct{ tenant, guid string }
+	network := make(map[tenantPlayer]*models.PlayerHighlight)
+	for _, r := range stats {
+		if r.kills >= minKills {
+			h := highlightFrom(r.guid, r.name, r.kills, r.deaths, r.headshots, r.played, r.won)
+			h.ServerID, h.TenantID = r.serverID, tenants[r.serverID]
+			if cur := perServer[r.serverID]; cur == nil || better(h, cur) {
+				perServer[r.serverID] = h
+			}
+		}
+
+		key := tenantPlayer{tenants[r.serverID], r.guid}
+		if t := network[key]; t != nil {
+			network[key] = highlightFrom(r.guid, t.PlayerName, t.Kills+r.kills, t.Deaths+r.deaths,
+				t.Headshots+r.headshots, t.MatchesPlayed+r.played, t.MatchesWon+r.won)
+		} else {
+			network[key] = highlightFrom(r.guid, r.name, r.kills, r.deaths, r.headshots, r.played, r.won)
+		}
+	}
+
+	perTenant := make(map[string]*models.PlayerHighlight)
+	for key, h := range network {
+		if h.Kills < minKills {
+			continue
+		}
+		if cur := perTenant[key.tenant]; cur == nil || better(h, cur) {
+			perTenant[key.tenant] = h
+		}
+	}
+
+	picks := make([]models.PlayerHighlight, 0, len(perServer)+len(perTenant))
+	for tenant, h := range perTenant {
+		h.TenantID = tenant
+		picks = append(picks, *h)
+	}
+	for _, h := range perServer {
+		picks = append(picks, *h)
+	}
+	sort.Slice(picks, func(i, j int) bool {
+		if picks[i].TenantID != picks[j].TenantID {
+			return picks[i].TenantID < picks[j].TenantID
+		}
+		return picks[i].ServerID < picks[j].ServerID
+	})
+	return picks
+}
+
+func highlightFrom(guid, name string, kills, deaths, headshots, played, won int64) *models.PlayerHighlight {
+	return &models.PlayerHighlight{
+		PlayerGUID:    guid,
+		PlayerName:    name,
+		Score:         HighlightScore(kills, deaths, headshots, won),
+		Kills:         kills,
+		Deaths:        deaths,
+		Headshots:     headshots,
+		MatchesPlayed: played,
+		MatchesWon:    won,
+	}
+}
+
+// Computed reports whether the period starting at start has stored picks
+func (s *highlightsService) Computed(ctx context.Context, period models.HighlightPeriod, start time.Time) (bool, error) {
+	var exists bool
+	err := s.pg.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM player_highlights WHERE period = $1 AND period_start = $2)`,
+		period, start).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check player highlights: %w", err)
+	}
+	return exists, nil
+}
+
+// History returns the stored picks of a server ("" = network-wide) for the
+// tenant in ctx whose periods start between between.From and between.To,
+// newest period first
+func (s *highlightsService) History(ctx context.Context, period models.HighlightPeriod, serverID string, between Period, limit int) ([]models.PlayerHighlight, error) {
+	// Periods start at UTC midnight: a period starts in the range when its
+	// date is on or after the first midnight in it
+	var from, to *string
+	if !between.From.IsZero() {
+		d := ceilUTCDate(between.From)
+		from = &d
+	}
+	if !between.To.IsZero() {
+		d := ceilUTCDate(between.To)
+		to = &d
+	}
+	rows, err := s.pg.Query(ctx, `
+		SELECT period, period_start, server_id, player_guid, player_name, score,
+			kills, deaths, headshots, matches_played, matches_won, computed_at
+		FROM player_highlights
+		WHERE period = $1 AND tenant_id = $2 AND server_id = $3
+			AND ($4::date IS NULL OR period_start >= $4::date)
+			AND ($5::date IS NULL OR period_start < $5::date)
+		ORDER BY period_start DESC
+		LIMIT $6
+	`, period, TenantFromContext(ctx), serverID, from, to, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read player highlights: %w", err)
+	}
+	defer rows.Close()
+
+	highlights := []models.PlayerHighlight{}
+	for rows.Next() {
+		var h models.PlayerHighlight
+		if err := rows.Scan(&h.Period, &h.PeriodStart, &h.ServerID, &h.PlayerGUID, &h.PlayerName, &h.Score,
+			&h.Kills, &h.Deaths, &h.Headshots, &h.MatchesPlayed, &h.MatchesWon, &h.ComputedAt); err != nil {
+			return nil, fmt.Errorf("failed to read player highlights: %w", err)
+		}
+		highlights = append(highlights, h)
+	}
+	return highlights, rows.Err()
+}
+
+// ceilUTCDate returns the date of the first UTC midnight at or after t
+func ceilUTCDate(t time.Time) string {
+	return Period{Location: time.UTC}.localDate(t, true)
+}
@@ -0,0 +1,69 @@
+package logic
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestHighlightPeriodStart(t *testing.T) {
+	// Thursday afternoon
+	ts := time.Date(2026, 10, 15, 15, 30, 0, 0, time.UTC)
+	if got := HighlightPeriodStart(models.HighlightDay, ts); !got.Equal(time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("day start = %v", got)
+	}
+	monday := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	if got := HighlightPeriodStart(models.HighlightWeek, ts); !got.Equal(monday) {
+		t.Errorf("week start = %v, want %v", got, monday)
+	}
+	// Sunday still belongs to the week that began on Monday
+	if got := HighlightPeriodStart(models.HighlightWeek, time.Date(2026, 10, 18, 23, 0, 0, 0, time.UTC)); !got.Equal(monday) {
+		t.Errorf("Sunday week start = %v, want %v", got, monday)
+	}
+	if got := HighlightPeriodEnd(models.HighlightWeek, monday); !got.Equal(monday.AddDate(0, 0, 7)) {
+		t.Errorf("week end = %v", got)
+	}
+}
+
+func TestPickHighlights(t *testing.T) {
+	tenants := map[string]string{"s1": "", "s2": "", "t1": "clan"}
+	stats := []highlightRow{
+		{serverID: "s1", guid: "a", name: "Alpha", kills: 30, deaths: 10, headshots: 10},
+		{serverID: "s1", guid: "b", name: "Bravo", kills: 25, deaths: 5, won: 2},
+		{serverID: "s2", guid: "a", name: "Alpha", kills: 8, deaths: 2},
+		{serverID: "s2", guid: "c", name: "Charlie", kills: 12, deaths: 12},
+		{serverID: "t1", guid: "d", name: "Delta", kills: 200},
+		{serverID: "t1", guid: "e", name: "Echo", kills: 5},
+	}
+
+	picks := pickHighlights(stats, tenants, 10)
+	got := map[[2]string]string{}
+	for _, p := range picks {
+		got[[2]string{p.TenantID, p.ServerID}] = p.PlayerGUID
+	}
+	want := map[[2]string]string{
+		{"", ""}:       "a", // 38 kills across s1 and s2
+		{"", "s1"}:     "b", // 25 + 10 - 2.5 = 32.5 beats 30 + 5 - 5 = 30
+		{"", "s2"}:     "c", // a is below the minimum on s2 alone
+		{"clan", ""}:   "d",
+		{"clan", "t1"}: "d",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("picks = %v, want %v", got, want)
+	}
+	for k, guid := range want {
+		if got[k] != guid {
+			t.Errorf("pick %v = %q, want %q", k, got[k], guid)
+		}
+	}
+	for _, p := range picks {
+		if p.TenantID == "" && p.ServerID == "" && (p.Kills != 38 || p.Score != HighlightScore(38, 12, 10, 0)) {
+			t.Errorf("network pick = %+v, want totals across servers", p)
+		}
+	}
+
+	if picks := pickHighlights(stats, tenants, 1000); len(picks) != 0 {
+		t.Errorf("picks below the minimum: %+v", picks)
+	}
+}
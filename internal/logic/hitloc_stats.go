@@ -0,0 +1,128 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// GetBodyHeatmap returns a player's hits, damage and kills per body part, as
+// the attacker and as the victim, from hitloc_stats_daily (in-round events
+// only)
+func (s *serverStatsService) GetBodyHeatmap(ctx context.Context, playerID string) (*models.BodyHeatmap, error) {
+	tenantID := TenantFromContext(ctx)
+	rows, err := s.ch.Query(ctx, `
+		SELECT direction, hitloc, sum(hits), sum(damage), sum(kills)
+		FROM mohaa_stats.hitloc_stats_daily
+		WHERE player_id = ? AND (? = '' OR tenant_id = ?)
+		GROUP BY direction, hitloc
+	`, playerID, tenantID, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query body heatmap: %w", err)
+	}
+	defer rows.Close()
+
+	var dealt, taken []models.BodyPartStats
+	for rows.Next() {
+		var direction string
+		var p models.BodyPartStats
+		if err := rows.Scan(&direction, &p.Hitloc, &p.Hits, &p.Damage, &p.Kills); err != nil {
+			return nil, fmt.Errorf("failed to scan body heatmap: %w", err)
+		}
+		if direction == "dealt" {
+			dealt = append(dealt, p)
+		} else {
+			taken = append(taken, p)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan body heatmap: %w", err)
+	}
+
+	return &models.BodyHeatmap{
+		GUID:  playerID,
+		Dealt: orderBodyParts(dealt),
+		Taken: orderBodyParts(taken),
+	}, nil
+}
+
+// GetWeaponBodyParts returns a weapon's hits on each opponent body part.
+// shotsFired is the weapon's shot count used for per-part accuracy.
+func (s *serverStatsService) GetWeaponBodyParts(ctx context.Context, weapon string, shotsFired uint64) ([]models.WeaponBodyPart, error) {
+	tenantID := TenantFromContext(ctx)
+	rows, err := s.ch.Query(ctx, `
+		SELECT hitloc, sum(hits), sum(damage), sum(kills)
+		FROM mohaa_stats.hitloc_stats_daily
+		WHERE direction = 'dealt' AND weapon = ? AND (? = '' OR tenant_id = ?)
+		GROUP BY hitloc
+	`, weapon, tenantID, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query weapon body parts: %w", err)
+	}
+	defer rows.Close()
+
+	var parts []models.BodyPartStats
+	for rows.Next() {
+		var p models.BodyPartStats
+		if err := rows.Scan(&p.Hitloc, &p.Hits, &p.Damage, &p.Kills); err != nil {
+			return nil, fmt.Errorf("failed to scan weapon body parts: %w", err)
+		}
+		parts = append(parts, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan weapon body parts: %w", err)
+	}
+
+	return weaponBodyParts(orderBodyParts(parts), shotsFired), nil
+}
+
+// orderBodyParts sorts parts from head to feet (unlisted locations last,
+// alphabetically) and sets each part's share of the hits
+func orderBodyParts(parts []models.BodyPartStats) []models.BodyPartStats {
+	rank := make(map[string]int, len(matrixHitlocs))
+	for i, loc := range matrixHitlocs {
+		rank[loc] = i
+	}
+	order := func(loc string) int {
+		if r, ok := rank[loc]; ok {
+			return r
+		}
+		return len(matrixHitlocs)
+	}
+
+	ordered := make([]models.BodyPartStats, len(parts))
+	copy(ordered, parts)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		ri, rj := order(ordered[i].Hitloc), order(ordered[j].Hitloc)
+		if ri != rj {
+			return ri < rj
+		}
+		return ordered[i].Hitloc < ordered[j].Hitloc
+	})
+
+	var hits uint64
+	for _, p := range ordered {
+		hits += p.Hits
+	}
+	if hits > 0 {
+		for i := range ordered {
+			ordered[i].HitShare = float64(ordered[i].Hits) / float64(hits) * 100
+		}
+	}
+	return ordered
+}
+
+// weaponBodyParts adds per-part accuracy against the weapon's shots fired
+func weaponBodyParts(parts []models.BodyPartStats, shotsFired uint64) []models.WeaponBodyPart {
+	result := make([]models.WeaponBodyPart, 0, len(parts))
+	for _, p := range parts {
+		wp := models.WeaponBodyPart{BodyPartStats: p}
+		if shotsFired > 0 {
+			wp.Accuracy = float64(p.Hits) / float64(shotsFired) * 100
+		}
+		result = append(result, wp)
+	}
+	return result
+}
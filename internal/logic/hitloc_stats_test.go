@@ -0,0 +1,42 @@
+package logic
+
+import (
+	"testing"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestOrderBodyParts(t *testing.T) {
+	parts := []models.BodyPartStats{
+		{Hitloc: "left_foot", Hits: 1},
+		{Hitloc: "mystery", Hits: 1},
+		{Hitloc: "torso_upper", Hits: 6, Damage: 200},
+		{Hitloc: "head", Hits: 2, Kills: 2},
+	}
+
+	got := orderBodyParts(parts)
+
+	want := []string{"head", "torso_upper", "left_foot", "mystery"}
+	for i, p := range got {
+		if p.Hitloc != want[i] {
+			t.Fatalf("order = %v, want body order then unknown locations", got)
+		}
+	}
+	if got[1].HitShare != 60 || got[0].HitShare != 20 {
+		t.Errorf("hit shares = %v, %v, want 20, 60", got[0].HitShare, got[1].HitShare)
+	}
+	if parts[0].Hitloc != "left_foot" {
+		t.Error("input slice was reordered")
+	}
+
+	weapon := weaponBodyParts(got, 40)
+	if weapon[1].Accuracy != 15 || weapon[1].Damage != 200 {
+		t.Errorf("torso_upper = %+v, want 15%% accuracy and 200 damage", weapon[1])
+	}
+	if none := weaponBodyParts(got, 0); none[0].Accuracy != 0 {
+		t.Errorf("accuracy without shots = %v, want 0", none[0].Accuracy)
+	}
+	if empty := orderBodyParts(nil); empty == nil {
+		t.Error("empty parts should be a non-nil slice")
+	}
+}
@@ -3,19 +3,20 @@ package logic
 
 import (
 	"context"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/redis/go-redis/v9"
+	"github.com/openmohaa/stats-api/internal/db"
 )
 
 // IdentityResolver resolves player GUIDs to SMF member IDs.
 // It uses a multi-layer cache to minimize database lookups.
 type IdentityResolver struct {
 	postgres    *pgxpool.Pool
-	redis       *redis.Client
+	redis       db.LiveStateStore
 	localCache  map[string]int64 // GUID -> SMF ID
 	cacheMu     sync.RWMutex
 	cacheExpiry time.Duration
@@ -32,10 +33,10 @@ type IdentityInfo struct {
 }
 
 // NewIdentityResolver creates a new identity resolver with caching.
-func NewIdentityResolver(postgres *pgxpool.Pool, redis *redis.Client) *IdentityResolver {
+func NewIdentityResolver(postgres *pgxpool.Pool, state db.LiveStateStore) *IdentityResolver {
 	return &IdentityResolver{
 		postgres:    postgres,
-		redis:       redis,
+		redis:       state,
 		localCache:  make(map[string]int64),
 		cacheExpiry: 5 * time.Minute,
 	}
@@ -64,12 +65,14 @@ func (ir *IdentityResolver) ResolveSMFID(ctx context.Context, guid string) (int6
 	// 2. Check Redis cache
 	if ir.redis != nil {
 		key := "guid:" + guid + ":smf_id"
-		val, err := ir.redis.Get(ctx, key).Int64()
+		s, err := ir.redis.Get(ctx, key)
 		if err == nil {
-			ir.cacheMu.Lock()
-			ir.localCache[guid] = val
-			ir.cacheMu.Unlock()
-			return val, nil
+			if val, err := strconv.ParseInt(s, 10, 64); err == nil {
+				ir.cacheMu.Lock()
+				ir.localCache[guid] = val
+				ir.cacheMu.Unlock()
+				return val, nil
+			}
 		}
 	}
 
@@ -0,0 +1,101 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// ingestLagWindow bounds the raw_events scan. Servers with no gameplay events
+// inside the window report the full window as their lag.
+const ingestLagWindow = 7 * 24 * time.Hour
+
+// GetIngestHealth compares each active server's newest gameplay event with
+// its last heartbeat. Heartbeats are excluded from the event side, so a
+// server whose game script keeps pinging but whose events stop flowing is
+// flagged as stalled once its lag exceeds stallThreshold.
+func (s *ServerTrackingService) GetIngestHealth(ctx context.Context, stallThreshold time.Duration) (*models.IngestHealth, error) {
+	now := time.Now().UTC()
+	health := &models.IngestHealth{
+		Status:           "ok",
+		ThresholdSeconds: stallThreshold.Seconds(),
+		CheckedAt:        now,
+		Servers:          []models.ServerIngestLag{},
+	}
+
+	rows, err := s.pg.Query(ctx, `
+		SELECT id::text, name, last_seen
+		FROM servers
+		WHERE is_active = true
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get servers: %w", err)
+	}
+	var servers []models.ServerIngestLag
+	var serverIDs []string
+	for rows.Next() {
+		var srv models.ServerIngestLag
+		if err := rows.Scan(&srv.ServerID, &srv.Name, &srv.LastHeartbeatAt); err != nil {
+			continue
+		}
+		servers = append(servers, srv)
+		serverIDs = append(serverIDs, srv.ServerID)
+	}
+	rows.Close()
+
+	if len(servers) == 0 {
+		return health, nil
+	}
+
+	lastEvents := make(map[string]time.Time, len(servers))
+	chRows, err := s.ch.Query(ctx, `
+		SELECT server_id, max(timestamp)
+		FROM raw_events
+		WHERE server_id IN (?)
+		  AND event_type != 'heartbeat'
+		  AND timestamp > now() - INTERVAL ? HOUR
+		GROUP BY server_id
+	`, serverIDs, int(ingestLagWindow.Hours()))
+	if err != nil {
+		return nil, fmt.Errorf("last event query: %w", err)
+	}
+	defer chRows.Close()
+	for chRows.Next() {
+		var sid string
+		var ts time.Time
+		if err := chRows.Scan(&sid, &ts); err == nil {
+			lastEvents[sid] = ts
+		}
+	}
+
+	for i := range servers {
+		srv := &servers[i]
+
+		srv.IngestLagSeconds = ingestLagWindow.Seconds()
+		if ts, ok := lastEvents[srv.ServerID]; ok {
+			srv.LastEventAt = &ts
+			srv.IngestLagSeconds = now.Sub(ts).Seconds()
+		}
+
+		// Without a recent heartbeat the server is simply offline, not stalled
+		heartbeatFresh := false
+		if srv.LastHeartbeatAt != nil {
+			srv.HeartbeatAgeSeconds = now.Sub(*srv.LastHeartbeatAt).Seconds()
+			heartbeatFresh = srv.HeartbeatAgeSeconds < stallThreshold.Seconds()
+		}
+
+		if heartbeatFresh && srv.IngestLagSeconds > stallThreshold.Seconds() {
+			srv.Stalled = true
+			health.Stalled++
+		}
+	}
+
+	if health.Stalled > 0 {
+		health.Status = "degraded"
+	}
+	health.Servers = servers
+	return health, nil
+}
@@ -2,11 +2,11 @@ package logic
 
 import (
 	"context"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/openmohaa/stats-api/internal/models"
-	"github.com/redis/go-redis/v9"
 )
 
 // PgPool defines the interface for PostgreSQL connection pool
@@ -16,24 +16,26 @@ type PgPool interface {
 	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
 }
 
-// RedisClient defines the interface for Redis client
-type RedisClient interface {
-	HGet(ctx context.Context, key string, field string) *redis.StringCmd
-	HGetAll(ctx context.Context, key string) *redis.MapStringStringCmd
-}
-
 type PlayerStatsService interface {
 	GetDeepStats(ctx context.Context, guid string) (*models.DeepStats, error)
+	GetStatsSections(ctx context.Context, guid string, sections []string) (*PlayerStatsSections, error)
+	GetProfile(ctx context.Context, guid string, sections []string) (*models.PlayerStats, error)
 	ResolvePlayerGUID(ctx context.Context, name string) (string, error)
 	GetPlayerStatsByGametype(ctx context.Context, guid string) ([]models.GametypeStats, error)
 	GetPlayerStatsByMap(ctx context.Context, guid string) ([]models.PlayerMapStats, error)
+	GetPlayerPacing(ctx context.Context, guid string) (*models.PlayerPacing, error)
 }
 
 type ServerStatsService interface {
-	GetGlobalActivity(ctx context.Context) ([]map[string]interface{}, error)
+	GetGlobalActivity(ctx context.Context, period Period) ([]map[string]interface{}, error)
 	GetMapPopularity(ctx context.Context) ([]models.MapStats, error)
 	GetServerPulse(ctx context.Context) (*models.ServerPulse, error)
 	GetGlobalStats(ctx context.Context) (map[string]interface{}, error)
+	GetWeaponKillMatrix(ctx context.Context, playerID string, limit int) (*models.WeaponKillMatrix, error)
+	GetBodyHeatmap(ctx context.Context, playerID string) (*models.BodyHeatmap, error)
+	GetWeaponBodyParts(ctx context.Context, weapon string, shotsFired uint64) ([]models.WeaponBodyPart, error)
+	GetEnvironmentalMapDeaths(ctx context.Context, limit int) ([]models.MapEnvironmentDeaths, error)
+	GetMapObjectives(ctx context.Context, mapName string, days int) (*models.MapObjectiveStats, error)
 }
 
 type GamificationService interface {
@@ -42,6 +44,7 @@ type GamificationService interface {
 
 type MatchReportService interface {
 	GetMatchDetails(ctx context.Context, matchID string) (*MatchDetail, error)
+	GetMatchCard(ctx context.Context, matchID string) (*models.MatchCardStats, error)
 }
 
 type AdvancedStatsService interface {
@@ -49,6 +52,7 @@ type AdvancedStatsService interface {
 	GetDrillDown(ctx context.Context, guid string, stat string, dimension string, limit int) (*models.DrillDownResult, error)
 	GetComboMetrics(ctx context.Context, guid string) (*models.ComboMetrics, error)
 	GetVehicleStats(ctx context.Context, guid string) (*models.VehicleStats, error)
+	GetVehicleLeaderboard(ctx context.Context, vehicle string, limit int) ([]models.VehicleLeaderboardEntry, error)
 	GetGameFlowStats(ctx context.Context, guid string) (*models.GameFlowStats, error)
 	GetWorldStats(ctx context.Context, guid string) (*models.WorldStats, error)
 	GetBotStats(ctx context.Context, guid string) (*models.BotStats, error)
@@ -75,4 +79,225 @@ type AchievementsService interface {
 type PredictionService interface {
 	GetPlayerPredictions(ctx context.Context, guid string) (*models.PlayerPredictions, error)
 	GetMatchPredictions(ctx context.Context, matchID string) (*models.MatchPredictions, error)
+	GetPredictionAccuracy(ctx context.Context, days int) (*models.PredictionAccuracy, error)
+	ResolvePredictions(ctx context.Context) (int, error)
+	SetModels(cfg PredictionModelConfig)
+}
+
+type AggregateService interface {
+	CheckConsistency(ctx context.Context, table string, days, sampleSize int, tolerance float64) (*models.AggregateCheckReport, error)
+	Rebuild(ctx context.Context, table string) (*models.AggregateRebuildResult, error)
+	Exclusions(ctx context.Context) (*AggregateExclusions, error)
+	AffectedDays(ctx context.Context, matchIDs, playerIDs []string) ([]time.Time, error)
+	RebuildDay(ctx context.Context, table string, day time.Time, exclude *AggregateExclusions) error
+	RefreshLeaderboard(ctx context.Context, exclude *AggregateExclusions) (int, error)
+	AnalyticsViews(ctx context.Context) ([]models.AnalyticsView, error)
+}
+
+type TenantService interface {
+	ResolveAPIKey(ctx context.Context, keyHash string) (*models.Tenant, error)
+	ServerIDs(ctx context.Context, tenantID string) ([]string, error)
+	Create(ctx context.Context, slug, name, keyHash string) (*models.Tenant, error)
+}
+
+type MatchStateService interface {
+	Get(ctx context.Context, matchID string) (*models.MatchStateRecord, error)
+	States(ctx context.Context, ids []string) (map[string]models.MatchState, error)
+	ApplyEvents(ctx context.Context, events []*models.RawEvent) error
+	RecordResult(ctx context.Context, result *models.MatchResult) error
+	Adopt(ctx context.Context, matchID, serverID string) error
+	Transition(ctx context.Context, matchID string, to models.MatchState, actor, reason string) (*models.MatchStateRecord, error)
+	CheckRecompute(ctx context.Context, matchID string) error
+}
+
+type MatchAdminService interface {
+	Void(ctx context.Context, matchID, reason string) (int64, error)
+	Restore(ctx context.Context, matchID string) error
+	RevokeAchievements(ctx context.Context, matchID string) (int64, error)
+	Recompute(ctx context.Context, matchID string) (*MatchRecompute, error)
+	ReplaceOutcomes(ctx context.Context, matchID string) (*MatchRecompute, error)
+}
+
+type QuerySandboxService interface {
+	Run(ctx context.Context, sql string) (*models.SandboxQueryResult, error)
+}
+
+type HighlightsService interface {
+	Compute(ctx context.Context, period models.HighlightPeriod, start time.Time) ([]models.PlayerHighlight, error)
+	Computed(ctx context.Context, period models.HighlightPeriod, start time.Time) (bool, error)
+	History(ctx context.Context, period models.HighlightPeriod, serverID string, between Period, limit int) ([]models.PlayerHighlight, error)
+}
+
+type LeaderboardSnapshotService interface {
+	Snapshot(ctx context.Context, day time.Time) error
+	Taken(ctx context.Context, day time.Time) (bool, error)
+	Changes(ctx context.Context, stat string, days, limit int) (*models.LeaderboardChanges, error)
+}
+
+type ServerReportService interface {
+	GetSettings(ctx context.Context, serverID string) (*models.ServerReportSettings, error)
+	SetSettings(ctx context.Context, settings *models.ServerReportSettings) error
+	DeleteSettings(ctx context.Context, serverID string) error
+	Due(ctx context.Context, weekStart time.Time) ([]models.ServerReportSettings, error)
+	MarkSent(ctx context.Context, serverID string, weekStart time.Time) error
+	Build(ctx context.Context, serverID string, weekStart time.Time) (*models.ServerReport, error)
+}
+
+type TimelineService interface {
+	GetPlayerTimeline(ctx context.Context, guid string, limit int) (*models.PlayerTimeline, error)
+}
+
+type TitlesService interface {
+	List(ctx context.Context) ([]models.Title, error)
+	Define(ctx context.Context, t *models.Title) error
+	PlayerTitles(ctx context.Context, guid string) ([]models.PlayerTitle, error)
+	UserTitles(ctx context.Context, forumUserID int) ([]models.PlayerTitle, error)
+	Grant(ctx context.Context, guid, code, grantedBy string) error
+	Revoke(ctx context.Context, guid, code string) error
+	Equip(ctx context.Context, forumUserID int, code string) error
+	Unequip(ctx context.Context, forumUserID int, code string) error
+	Equipped(ctx context.Context, guids []string) (map[string]*models.PlayerCosmetics, error)
+	GrantByRules(ctx context.Context) (int64, error)
+}
+
+type ChallengesService interface {
+	List(ctx context.Context) ([]models.Challenge, error)
+	Define(ctx context.Context, c *models.Challenge) error
+	Week(ctx context.Context, week time.Time) ([]models.Challenge, error)
+	Rotate(ctx context.Context, week time.Time, count int) ([]models.Challenge, error)
+	Complete(ctx context.Context, c *models.ChallengeCompletion) (bool, error)
+	Completions(ctx context.Context, guid string, week time.Time) (map[string]time.Time, error)
+
+	QuestChain(ctx context.Context, chain string) ([]models.Challenge, error)
+	Enroll(ctx context.Context, guid, chain string, now time.Time) (bool, error)
+	Enrolled(ctx context.Context, chain string, now time.Time) ([]string, error)
+	CompleteQuestStep(ctx context.Context, guid, chain, code string, at time.Time) (bool, error)
+	QuestEnrollment(ctx context.Context, guid, chain string) (*models.PlayerQuest, map[string]time.Time, error)
+}
+
+type TrophiesService interface {
+	GetTrophyCase(ctx context.Context, guid string) (*models.TrophyCase, error)
+}
+
+type ServerRegistrationService interface {
+	CreateInvite(ctx context.Context, req models.CreateServerInviteRequest, createdBy string) (*models.ServerInvite, error)
+	ListInvites(ctx context.Context) ([]models.ServerInvite, error)
+	RevokeInvite(ctx context.Context, id string) error
+	Register(ctx context.Context, req models.RegisterServerRequest, port int) (*models.RegisterServerResponse, error)
+	Status(ctx context.Context, token string) (*models.ServerRegistration, error)
+	ListPending(ctx context.Context) ([]models.ServerRegistration, error)
+	Review(ctx context.Context, serverID string, approve bool, reviewer string) (*models.ServerRegistration, error)
+}
+
+type PlayerNamesService interface {
+	DisplayNames(ctx context.Context, fallbacks map[string]string) (map[string]string, error)
+}
+
+type ContentFilterService interface {
+	SetConfig(cfg ContentFilterConfig)
+	FilterNames(ctx context.Context, names map[string]string) (map[string]bool, error)
+	FilterText(text string) (string, bool)
+	ListOverrides(ctx context.Context) ([]models.ContentFilterOverride, error)
+	SetOverride(ctx context.Context, o *models.ContentFilterOverride) error
+	DeleteOverride(ctx context.Context, guid string) error
+}
+
+type ServerMergeService interface {
+	Plan(ctx context.Context, req models.ServerMergeRequest) (*models.ServerMerge, error)
+	Merge(ctx context.Context, req models.ServerMergeRequest, requestedBy string) (*models.ServerMerge, error)
+	History(ctx context.Context, limit int) ([]models.ServerMerge, error)
+}
+
+type RatingService interface {
+	ApplyKills(ctx context.Context, events []*models.RawEvent) error
+	Get(ctx context.Context, guid string) (*models.PlayerRating, error)
+	Rank(ctx context.Context, guid string) (*models.PlayerRank, error)
+	AdjustedKDLeaderboard(ctx context.Context, limit int) ([]models.AdjustedKDEntry, error)
+}
+
+type PrivacyService interface {
+	Get(ctx context.Context, forumUserID int) (*models.PlayerPrivacy, error)
+	Set(ctx context.Context, forumUserID int, p *models.PlayerPrivacy) error
+	Visibility(ctx context.Context, guids []string) (map[string]models.PlayerPrivacy, error)
+}
+
+type PositionRetentionService interface {
+	StripPositions(ctx context.Context, before time.Time) error
+}
+
+type MatchResultService interface {
+	Sign(ctx context.Context, record *models.MatchStateRecord) (*models.SignedMatchResult, error)
+	Get(ctx context.Context, tournamentID, matchID string) (*models.SignedMatchResult, error)
+	SigningKey() models.ResultSigningKey
+}
+
+type MatchDisputeService interface {
+	Open(ctx context.Context, record *models.MatchStateRecord, reason, actor string) (*models.MatchDispute, error)
+	Get(ctx context.Context, id int64) (*models.MatchDispute, error)
+	List(ctx context.Context, matchID string) ([]*models.MatchDispute, error)
+	AddNote(ctx context.Context, disputeID int64, note *models.DisputeNote) error
+	Reject(ctx context.Context, disputeID int64, resolution, actor string) (*models.MatchDispute, error)
+	Correct(ctx context.Context, record *models.MatchStateRecord, req *models.MatchCorrectionRequest, actor string) (*models.MatchCorrection, error)
+	Correction(ctx context.Context, matchID string) (*models.MatchCorrection, error)
+}
+
+type MapVetoService interface {
+	Record(ctx context.Context, tournamentID, seriesID string, req *models.MapVetoRequest, actor string) (*models.MapVeto, error)
+	Get(ctx context.Context, tournamentID, seriesID string) (*models.MapVeto, error)
+	List(ctx context.Context, tournamentID string) ([]*models.MapVeto, error)
+	Analytics(ctx context.Context, tournamentID string) (*models.VetoAnalytics, error)
+}
+
+type ViewershipService interface {
+	Match(ctx context.Context, matchID string) (*models.MatchViewership, error)
+	Featured(ctx context.Context, hours, limit int) ([]models.FeaturedMatch, error)
+}
+
+type DemoService interface {
+	Register(ctx context.Context, serverID string, req *models.MatchDemoRequest) (*models.MatchDemo, error)
+	ForMatch(ctx context.Context, matchID string) ([]models.MatchDemo, error)
+	Counts(ctx context.Context, matchIDs []string) (map[string]int, error)
+	Matches(ctx context.Context, serverID, mapName string, limit, offset int) ([]models.DemoMatch, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+type MatchMediaService interface {
+	Submit(ctx context.Context, forumUserID int, req *models.MatchMediaRequest) (*models.MatchMedia, error)
+	ForMatch(ctx context.Context, matchID string) ([]models.MatchMedia, error)
+	ForUser(ctx context.Context, forumUserID int) ([]models.MatchMedia, error)
+	Queue(ctx context.Context, status models.MediaStatus, limit int) ([]models.MatchMedia, error)
+	Review(ctx context.Context, id int64, approve bool, note, reviewer string) (*models.MatchMedia, error)
+}
+
+type DiscussionService interface {
+	Get(ctx context.Context, subject models.DiscussionSubject, id string) (*models.DiscussionThread, error)
+	Lookup(ctx context.Context, subject models.DiscussionSubject, ids []string) (map[string]models.DiscussionThread, error)
+	Link(ctx context.Context, subject models.DiscussionSubject, id string, req *models.DiscussionLinkRequest, actor string) (*models.DiscussionThread, error)
+	Unlink(ctx context.Context, subject models.DiscussionSubject, id string) error
+	Missing(ctx context.Context, subject models.DiscussionSubject, ids []string) ([]string, error)
+	Recent(ctx context.Context, since time.Time) ([]models.DiscussionThread, error)
+	SetPosts(ctx context.Context, posts map[int]int) error
+}
+
+type NotificationService interface {
+	Notify(ctx context.Context, n *models.Notification) (int, error)
+	List(ctx context.Context, forumUserID int, unreadOnly bool, before int64, limit int) (*models.NotificationList, error)
+	Unread(ctx context.Context, forumUserID int) (int, error)
+	MarkRead(ctx context.Context, forumUserID int, req *models.NotificationReadRequest) (int, error)
+	Prune(ctx context.Context, before time.Time) (int, error)
+}
+
+type ReportService interface {
+	Submit(ctx context.Context, forumUserID int, req *models.PlayerReportRequest) (*models.PlayerReport, error)
+	ForUser(ctx context.Context, forumUserID int) ([]models.PlayerReport, error)
+	Queue(ctx context.Context, f models.ReportFilter) ([]models.PlayerReport, error)
+	Get(ctx context.Context, id int64) (*models.PlayerReport, error)
+	Triage(ctx context.Context, id int64, req *models.ReportTriageRequest, reviewer string) (*models.PlayerReport, error)
+}
+
+type AltAccountService interface {
+	Record(ctx context.Context, conns []models.PlayerConnection) error
+	Alts(ctx context.Context, guid string, limit int) ([]models.AltAccount, error)
+	BanEvasion(ctx context.Context, since time.Time, limit int) ([]models.BanEvasionSuspect, error)
+	Prune(ctx context.Context, before time.Time) (int, error)
 }
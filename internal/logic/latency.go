@@ -0,0 +1,127 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// latencyBounds are the lower bounds, in ms, of the ping distribution buckets
+var latencyBounds = []int{0, 50, 100, 150, 250, 400}
+
+// GetServerLatency returns the distribution of player pings on a server over
+// the last `days` days
+func (s *ServerTrackingService) GetServerLatency(ctx context.Context, serverID string, days int) (*models.ServerLatencyReport, error) {
+	since := time.Now().UTC().AddDate(0, 0, -days)
+	report := &models.ServerLatencyReport{ServerID: serverID, Days: days}
+
+	var quantiles []float64
+	if err := s.ch.QueryRow(ctx, `
+		SELECT
+			count() AS samples,
+			uniqExact(player_id) AS players,
+			ifNotFinite(avg(ping), 0) AS avg_ping,
+			quantilesTDigest(0.5, 0.9, 0.99)(ping) AS q
+		FROM mohaa_stats.player_pings
+		WHERE server_id = ? AND timestamp >= ?
+	`, serverID, since).Scan(&report.Samples, &report.Players, &report.AvgPing, &quantiles); err != nil {
+		return nil, fmt.Errorf("failed to query server latency: %w", err)
+	}
+	if report.Samples > 0 && len(quantiles) == 3 {
+		report.P50, report.P90, report.P99 = quantiles[0], quantiles[1], quantiles[2]
+	}
+
+	rows, err := s.ch.Query(ctx, `
+		SELECT toInt64(roundDown(ping, ?)) AS bucket, count() AS samples
+		FROM mohaa_stats.player_pings
+		WHERE server_id = ? AND timestamp >= ?
+		GROUP BY bucket
+	`, latencyBounds, serverID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query latency buckets: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[int]uint64)
+	for rows.Next() {
+		var bucket int64
+		var samples uint64
+		if err := rows.Scan(&bucket, &samples); err != nil {
+			return nil, fmt.Errorf("failed to scan latency buckets: %w", err)
+		}
+		counts[int(bucket)] += samples
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan latency buckets: %w", err)
+	}
+
+	report.Buckets = latencyBuckets(counts)
+	return report, nil
+}
+
+// latencyBuckets lays out the sample counts per bucket lower bound over
+// latencyBounds, including empty buckets
+func latencyBuckets(counts map[int]uint64) []models.LatencyBucket {
+	var total uint64
+	for _, n := range counts {
+		total += n
+	}
+
+	buckets := make([]models.LatencyBucket, len(latencyBounds))
+	for i, lo := range latencyBounds {
+		buckets[i] = models.LatencyBucket{MinMs: lo, Samples: counts[lo]}
+		if i+1 < len(latencyBounds) {
+			buckets[i].MaxMs = latencyBounds[i+1]
+		}
+		if total > 0 {
+			buckets[i].Share = float64(counts[lo]) / float64(total) * 100
+		}
+	}
+	return buckets
+}
+
+// GetPlayerPingHistory returns a player's average ping per day over the last
+// `days` days, across all servers
+func (s *ServerTrackingService) GetPlayerPingHistory(ctx context.Context, playerID string, days int) (*models.PlayerPingHistory, error) {
+	tenantID := TenantFromContext(ctx)
+	since := time.Now().UTC().AddDate(0, 0, -days)
+	rows, err := s.ch.Query(ctx, `
+		SELECT
+			toStartOfDay(timestamp) AS day,
+			count() AS samples,
+			avg(ping) AS avg_ping,
+			min(ping) AS min_ping,
+			max(ping) AS max_ping
+		FROM mohaa_stats.player_pings
+		WHERE player_id = ? AND timestamp >= ?
+		  AND (? = '' OR tenant_id = ?)
+		GROUP BY day
+		ORDER BY day
+	`, playerID, since, tenantID, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ping history: %w", err)
+	}
+	defer rows.Close()
+
+	history := &models.PlayerPingHistory{PlayerID: playerID, Days: days, History: []models.DailyPing{}}
+	var samples uint64
+	var weighted float64
+	for rows.Next() {
+		var d models.DailyPing
+		if err := rows.Scan(&d.Day, &d.Samples, &d.AvgPing, &d.MinPing, &d.MaxPing); err != nil {
+			return nil, fmt.Errorf("failed to scan ping history: %w", err)
+		}
+		history.History = append(history.History, d)
+		samples += d.Samples
+		weighted += d.AvgPing * float64(d.Samples)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan ping history: %w", err)
+	}
+	if samples > 0 {
+		history.AvgPing = weighted / float64(samples)
+	}
+	return history, nil
+}
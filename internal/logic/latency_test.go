@@ -0,0 +1,25 @@
+package logic
+
+import "testing"
+
+func TestLatencyBuckets(t *testing.T) {
+	buckets := latencyBuckets(map[int]uint64{0: 30, 100: 60, 400: 10})
+	if len(buckets) != len(latencyBounds) {
+		t.Fatalf("%d buckets, want %d", len(buckets), len(latencyBounds))
+	}
+	if b := buckets[0]; b.MinMs != 0 || b.MaxMs != 50 || b.Samples != 30 || b.Share != 30 {
+		t.Errorf("first bucket = %+v", b)
+	}
+	if b := buckets[1]; b.Samples != 0 || b.Share != 0 {
+		t.Errorf("empty bucket = %+v", b)
+	}
+	if b := buckets[len(buckets)-1]; b.MinMs != 400 || b.MaxMs != 0 || b.Share != 10 {
+		t.Errorf("last bucket = %+v, want 400ms and up", b)
+	}
+
+	for _, b := range latencyBuckets(nil) {
+		if b.Samples != 0 || b.Share != 0 {
+			t.Errorf("no samples gave %+v", b)
+		}
+	}
+}
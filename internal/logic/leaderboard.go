@@ -0,0 +1,96 @@
+package logic
+
+// LeaderboardStatExpr maps a public leaderboard stat name to the ORDER BY and
+// HAVING expressions used against player_stats_daily style aggregates.
+// Unknown stats fall back to kills so callers never interpolate user input.
+func LeaderboardStatExpr(stat string) (orderExpr string, havingExpr string) {
+	orderExpr = "kills"
+	havingExpr = "kills > 0"
+
+	switch stat {
+	case "kills":
+		orderExpr = "kills"
+	case "bot_kills":
+		orderExpr = "bot_kills"
+		havingExpr = "bot_kills > 0"
+	case "total_kills":
+		orderExpr = "kills + bot_kills"
+	case "deaths":
+		orderExpr = "deaths"
+		havingExpr = "deaths > 0"
+	case "kd_ratio", "kd":
+		orderExpr = "kills / nullIf(deaths, 0)"
+	case "headshots":
+		orderExpr = "headshots"
+	case "accuracy":
+		orderExpr = "shots_hit / nullIf(shots_fired, 0)"
+	case "shots_fired":
+		orderExpr = "shots_fired"
+	case "damage":
+		orderExpr = "total_damage"
+	case "bash_kills":
+		orderExpr = "bash_kills"
+	case "grenade_kills":
+		orderExpr = "grenade_kills"
+	case "roadkills":
+		orderExpr = "roadkills"
+	case "telefrags":
+		orderExpr = "telefrags"
+	case "crushed":
+		orderExpr = "crushed"
+	case "teamkills":
+		orderExpr = "teamkills"
+	case "suicides":
+		orderExpr = "suicides"
+	case "reloads":
+		orderExpr = "reloads"
+	case "weapon_swaps":
+		orderExpr = "weapon_swaps"
+	case "no_ammo":
+		orderExpr = "no_ammo"
+	case "looter":
+		orderExpr = "items_picked"
+	case "distance":
+		orderExpr = "distance_units"
+	case "sprinted":
+		orderExpr = "sprinted"
+	case "swam":
+		orderExpr = "swam"
+	case "driven":
+		orderExpr = "driven"
+	case "jumps":
+		orderExpr = "jumps"
+	case "crouch_time":
+		orderExpr = "crouch_events"
+	case "prone_time":
+		orderExpr = "prone_events"
+	case "ladders":
+		orderExpr = "ladders"
+	case "health_picked":
+		orderExpr = "health_picked"
+	case "ammo_picked":
+		orderExpr = "ammo_picked"
+	case "armor_picked":
+		orderExpr = "armor_picked"
+	case "items_picked":
+		orderExpr = "items_picked"
+	case "wins":
+		orderExpr = "matches_won"
+	case "team_wins":
+		orderExpr = "matches_won - ffa_wins"
+	case "ffa_wins":
+		orderExpr = "ffa_wins"
+	case "losses":
+		orderExpr = "matches_played - matches_won"
+	case "objectives":
+		orderExpr = "objectives"
+	case "rounds":
+		orderExpr = "matches_played"
+	case "playtime":
+		orderExpr = "playtime_seconds"
+	case "games":
+		orderExpr = "games_finished"
+	}
+
+	return orderExpr, havingExpr
+}
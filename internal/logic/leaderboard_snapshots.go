@@ -0,0 +1,304 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// ErrUnknownSnapshotStat is returned for a leaderboard without snapshots
+var ErrUnknownSnapshotStat = errors.New("leaderboard has no snapshots")
+
+// LeaderboardSnapshotStats are the leaderboards whose daily standings are kept
+var LeaderboardSnapshotStats = []string{"kills", "kd", "headshots", "wins", "damage"}
+
+// LeaderboardChangePeriods maps the periods rank changes can span to days
+var LeaderboardChangePeriods = map[string]int{"day": 1, "week": 7, "month": 30}
+
+const (
+	// leaderboardSnapshotSize is how many ranks a snapshot keeps; players
+	// entering or leaving it move from or to the rank after
+	leaderboardSnapshotSize = 100
+	// LeaderboardSnapshotRetention is how long snapshots are kept, a little
+	// over the longest change period
+	LeaderboardSnapshotRetention = 35 * 24 * time.Hour
+)
+
+type leaderboardSnapshotService struct {
+	ch driver.Conn
+	pg PgPool
+}
+
+func NewLeaderboardSnapshotService(ch driver.Conn, pg PgPool) LeaderboardSnapshotService {
+	return &leaderboardSnapshotService{ch: ch, pg: pg}
+}
+
+// snapshotRow is one rank of a stored snapshot
+type snapshotRow struct {
+	guid, name string
+	rank       int
+	value      float64
+}
+
+// Taken reports whether the snapshots of day are stored
+func (s *leaderboardSnapshotService) Taken(ctx context.Context, day time.Time) (bool, error) {
+	var exists bool
+	if err := s.pg.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM leaderboard_snapshots WHERE taken_on = $1)`,
+		day.Format("2006-01-02")).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check leaderboard snapshots: %w", err)
+	}
+	return exists, nil
+}
+
+// Snapshot stores the all-time standings of every LeaderboardSnapshotStats
+// leaderboard at the end of the UTC day, network-wide and for each tenant
+// over its servers, and prunes snapshots past retention
+func (s *leaderboardSnapshotService) Snapshot(ctx context.Context, day time.Time) error {
+	tenants, err := s.tenantServers(ctx)
+	if err != nil {
+		return err
+	}
+	tenants[""] = nil
+
+	end := day.AddDate(0, 0, 1).Format("2006-01-02")
+	for tenantID, serverIDs := range tenants {
+		for _, stat := range LeaderboardSnapshotStats {
+			rows, err := s.standings(ctx, stat, end, tenantID != "", serverIDs)
+			if err != nil {
+				return err
+			}
+			if err := s.store(ctx, stat, day, tenantID, rows); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := s.pg.Exec(ctx, `DELETE FROM leaderboard_snapshots WHERE taken_on < $1`,
+		day.Add(-LeaderboardSnapshotRetention).Format("2006-01-02")); err != nil {
+		return fmt.Errorf("failed to prune leaderboard snapshots: %w", err)
+	}
+	return nil
+}
+
+// tenantServers maps each tenant owning servers to their IDs
+func (s *leaderboardSnapshotService) tenantServers(ctx context.Context) (map[string][]string, error) {
+	rows, err := s.pg.Query(ctx, `SELECT tenant_id::text, id::text FROM servers WHERE tenant_id IS NOT NULL`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tenant servers: %w", err)
+	}
+	defer rows.Close()
+	tenants := make(map[string][]string)
+	for rows.Next() {
+		var tenant, id string
+		if err := rows.Scan(&tenant, &id); err != nil {
+			return nil, fmt.Errorf("failed to read tenant servers: %w", err)
+		}
+		tenants[tenant] = append(tenants[tenant], id)
+	}
+	return tenants, rows.Err()
+}
+
+// standings reads the top of a leaderboard over the days before end, from
+// the per-server aggregate when scoped to serverIDs
+func (s *leaderboardSnapshotService) standings(ctx context.Context, stat, end string, scoped bool, serverIDs []string) ([]snapshotRow, error) {
+	orderExpr, havingExpr := LeaderboardStatExpr(stat)
+	table, whereExpr := "player_stats_daily", "player_id != '' AND day < toDate(?)"
+	args := []any{end}
+	if scoped {
+		table = "player_server_stats_daily"
+		whereExpr += " AND server_id IN ?"
+		args = append(args, serverIDs)
+	}
+
+	// Aggregates keep their column names so the shared stat expressions resolve
+	query := fmt.Sprintf(`
+		SELECT player_id, name, toFloat64(ifNull(%s, 0)) AS value
+		FROM (
+			SELECT
+				player_id,
+				argMax(player_name, last_active) AS name,
+				sum(kills) AS kills,
+				sum(deaths) AS deaths,
+				sum(headshots) AS headshots,
+				sum(matches_won) AS matches_won,
+				sum(total_damage) AS total_damage
+			FROM mohaa_stats.%s
+			WHERE %s
+			GROUP BY player_id
+			HAVING %s
+		)
+		ORDER BY value DESC, player_id
+		LIMIT ?
+	`, orderExpr, table, whereExpr, havingExpr)
+
+	rows, err := s.ch.Query(ctx, query, append(args, leaderboardSnapshotSize)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s standings: %w", stat, err)
+	}
+	defer rows.Close()
+
+	var standings []snapshotRow
+	for rows.Next() {
+		r := snapshotRow{rank: len(standings) + 1}
+		if err := rows.Scan(&r.guid, &r.name, &r.value); err != nil {
+			return nil, fmt.Errorf("failed to scan %s standings: %w", stat, err)
+		}
+		standings = append(standings, r)
+	}
+	return standings, rows.Err()
+}
+
+func (s *leaderboardSnapshotService) store(ctx context.Context, stat string, day time.Time, tenantID string, rows []snapshotRow) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	guids := make([]string, len(rows))
+	names := make([]string, len(rows))
+	ranks := make([]int32, len(rows))
+	values := make([]float64, len(rows))
+	for i, r := range rows {
+		guids[i], names[i], ranks[i], values[i] = r.guid, r.name, int32(r.rank), r.value
+	}
+	if _, err := s.pg.Exec(ctx, `
+		INSERT INTO leaderboard_snapshots (stat, taken_on, tenant_id, player_guid, player_name, rank, value)
+		SELECT $1, $2, $3, * FROM unnest($4::text[], $5::text[], $6::int[], $7::float8[])
+		ON CONFLICT (stat, tenant_id, taken_on, player_guid) DO UPDATE SET
+			player_name = EXCLUDED.player_name, rank = EXCLUDED.rank, value = EXCLUDED.value
+	`, stat, day.Format("2006-01-02"), tenantID, guids, names, ranks, values); err != nil {
+		return fmt.Errorf("failed to store %s snapshot: %w", stat, err)
+	}
+	return nil
+}
+
+// Changes diffs the latest snapshot of a leaderboard for the tenant in ctx
+// with the one taken days earlier (or the latest before that) and returns
+// the limit biggest climbers and fallers. Without two snapshots both lists
+// are empty.
+func (s *leaderboardSnapshotService) Changes(ctx context.Context, stat string, days, limit int) (*models.LeaderboardChanges, error) {
+	known := false
+	for _, st := range LeaderboardSnapshotStats {
+		known = known || st == stat
+	}
+	if !known {
+		return nil, ErrUnknownSnapshotStat
+	}
+
+	changes := &models.LeaderboardChanges{
+		Stat:     stat,
+		Climbers: []models.LeaderboardMove{},
+		Fallers:  []models.LeaderboardMove{},
+	}
+	tenantID := TenantFromContext(ctx)
+
+	// Both are NULL until snapshots old enough exist
+	var to, from *time.Time
+	if err := s.pg.QueryRow(ctx, `
+		SELECT max(taken_on) FROM leaderboard_snapshots WHERE stat = $1 AND tenant_id = $2
+	`, stat, tenantID).Scan(&to); err != nil {
+		return nil, fmt.Errorf("failed to read leaderboard snapshots: %w", err)
+	}
+	if to == nil {
+		return changes, nil
+	}
+	if err := s.pg.QueryRow(ctx, `
+		SELECT max(taken_on) FROM leaderboard_snapshots
+		WHERE stat = $1 AND tenant_id = $2 AND taken_on <= $3
+	`, stat, tenantID, to.AddDate(0, 0, -days)).Scan(&from); err != nil {
+		return nil, fmt.Errorf("failed to read leaderboard snapshots: %w", err)
+	}
+	if from == nil {
+		return changes, nil
+	}
+	changes.From, changes.To = from.Format("2006-01-02"), to.Format("2006-01-02")
+
+	rows, err := s.pg.Query(ctx, `
+		SELECT taken_on, player_guid, player_name, rank, value
+		FROM leaderboard_snapshots
+		WHERE stat = $1 AND tenant_id = $2 AND taken_on IN ($3, $4)
+	`, stat, tenantID, *from, *to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read leaderboard snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	var prev, cur []snapshotRow
+	for rows.Next() {
+		var takenOn time.Time
+		var r snapshotRow
+		if err := rows.Scan(&takenOn, &r.guid, &r.name, &r.rank, &r.value); err != nil {
+			return nil, fmt.Errorf("failed to read leaderboard snapshots: %w", err)
+		}
+		if takenOn.Equal(*to) {
+			cur = append(cur, r)
+		} else {
+			prev = append(prev, r)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read leaderboard snapshots: %w", err)
+	}
+
+	changes.Climbers, changes.Fallers = diffSnapshots(prev, cur, limit)
+	return changes, nil
+}
+
+// diffSnapshots returns the limit players who climbed and fell the most from
+// prev to cur, biggest move first
+func diffSnapshots(prev, cur []snapshotRow, limit int) (climbers, fallers []models.LeaderboardMove) {
+	outside := leaderboardSnapshotSize + 1
+	before := make(map[string]snapshotRow, len(prev))
+	for _, r := range prev {
+		before[r.guid] = r
+	}
+
+	climbers, fallers = []models.LeaderboardMove{}, []models.LeaderboardMove{}
+	add := func(m models.LeaderboardMove) {
+		switch {
+		case m.Change > 0:
+			climbers = append(climbers, m)
+		case m.Change < 0:
+			fallers = append(fallers, m)
+		}
+	}
+	for _, r := range cur {
+		rank := r.rank
+		m := models.LeaderboardMove{PlayerID: r.guid, PlayerName: r.name, Rank: &rank, Value: r.value, Change: outside - r.rank}
+		if p, ok := before[r.guid]; ok {
+			prevRank := p.rank
+			m.PreviousRank, m.PreviousValue, m.Change = &prevRank, p.value, p.rank-r.rank
+			delete(before, r.guid)
+		}
+		add(m)
+	}
+	for _, p := range prev {
+		if _, dropped := before[p.guid]; !dropped {
+			continue
+		}
+		prevRank := p.rank
+		add(models.LeaderboardMove{PlayerID: p.guid, PlayerName: p.name, PreviousRank: &prevRank,
+			PreviousValue: p.value, Change: p.rank - outside})
+	}
+
+	sortMoves := func(moves []models.LeaderboardMove, bigger func(a, b int) bool) []models.LeaderboardMove {
+		sort.SliceStable(moves, func(i, j int) bool {
+			if moves[i].Change != moves[j].Change {
+				return bigger(moves[i].Change, moves[j].Change)
+			}
+			return moves[i].PlayerID < moves[j].PlayerID
+		})
+		if len(moves) > limit {
+			moves = moves[:limit]
+		}
+		return moves
+	}
+	climbers = sortMoves(climbers, func(a, b int) bool { return a > b })
+	fallers = sortMoves(fallers, func(a, b int) bool { return a < b })
+	return climbers, fallers
+}
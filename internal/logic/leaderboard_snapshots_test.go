@@ -0,0 +1,49 @@
+package logic
+
+import "testing"
+
+func TestDiffSnapshots(t *testing.T) {
+	prev := []snapshotRow{
+		{guid: "a", rank: 1, value: 100},
+		{guid: "b", rank: 2, value: 90},
+		{guid: "c", rank: 3, value: 80},
+		{guid: "d", rank: 4, value: 70},
+		{guid: "gone", name: "Gone", rank: 99, value: 10},
+	}
+	cur := []snapshotRow{
+		{guid: "c", rank: 1, value: 120},
+		{guid: "a", rank: 2, value: 110},
+		{guid: "b", rank: 3, value: 95},
+		{guid: "d", rank: 4, value: 75},
+		{guid: "new", rank: 90, value: 20},
+	}
+
+	climbers, fallers := diffSnapshots(prev, cur, 10)
+	if len(climbers) != 2 || climbers[0].PlayerID != "new" || climbers[0].Change != 11 || climbers[0].PreviousRank != nil {
+		t.Fatalf("climbers = %+v, want new (+11) first", climbers)
+	}
+	if c := climbers[1]; c.PlayerID != "c" || c.Change != 2 || *c.PreviousRank != 3 || c.PreviousValue != 80 {
+		t.Errorf("second climber = %+v, want c up 2", c)
+	}
+
+	// d did not move; gone dropped out from 99 to 101
+	want := []struct {
+		guid   string
+		change int
+	}{{"gone", -2}, {"a", -1}, {"b", -1}}
+	if len(fallers) != len(want) {
+		t.Fatalf("fallers = %+v", fallers)
+	}
+	for i, w := range want {
+		if fallers[i].PlayerID != w.guid || fallers[i].Change != w.change {
+			t.Errorf("faller %d = %s %d, want %s %d", i, fallers[i].PlayerID, fallers[i].Change, w.guid, w.change)
+		}
+	}
+	if fallers[0].Rank != nil || fallers[0].PlayerName != "Gone" {
+		t.Errorf("dropped player = %+v", fallers[0])
+	}
+
+	if climbers, _ := diffSnapshots(prev, cur, 1); len(climbers) != 1 {
+		t.Errorf("limit ignored: %d climbers", len(climbers))
+	}
+}
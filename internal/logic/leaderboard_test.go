@@ -0,0 +1,35 @@
+package logic
+
+import "testing"
+
+func TestLeaderboardStatExpr(t *testing.T) {
+	tests := []struct {
+		stat       string
+		wantOrder  string
+		wantHaving string
+	}{
+		{"kills", "kills", "kills > 0"},
+		{"bot_kills", "bot_kills", "bot_kills > 0"},
+		{"deaths", "deaths", "deaths > 0"},
+		{"accuracy", "shots_hit / nullIf(shots_fired, 0)", "kills > 0"},
+		{"distance", "distance_units", "kills > 0"},
+		{"wins", "matches_won", "kills > 0"},
+		{"ffa_wins", "ffa_wins", "kills > 0"},
+		{"team_wins", "matches_won - ffa_wins", "kills > 0"},
+		// Unknown stats must never reach the query text
+		{"kills; DROP TABLE raw_events", "kills", "kills > 0"},
+		{"", "kills", "kills > 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.stat, func(t *testing.T) {
+			order, having := LeaderboardStatExpr(tt.stat)
+			if order != tt.wantOrder {
+				t.Errorf("order = %q, want %q", order, tt.wantOrder)
+			}
+			if having != tt.wantHaving {
+				t.Errorf("having = %q, want %q", having, tt.wantHaving)
+			}
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+var (
+	// ErrInvalidVeto is returned for a veto sequence that cannot be stored
+	ErrInvalidVeto = errors.New("invalid map veto")
+	// ErrVetoNotFound is returned for a series without a recorded veto
+	ErrVetoNotFound = errors.New("map veto not found")
+)
+
+// maxVetoSteps bounds a veto; MOHAA map pools are far smaller
+const maxVetoSteps = 32
+
+// ValidateMapVeto checks a veto sequence before it is stored, numbers its
+// steps and normalizes its maps and sides to lowercase. Bans and picks are
+// taken by team a or b, the decider by neither; a map appears once, and
+// only picks and deciders link to the match they were played in.
+func ValidateMapVeto(req *models.MapVetoRequest) error {
+	req.TeamA, req.TeamB = strings.TrimSpace(req.TeamA), strings.TrimSpace(req.TeamB)
+	if req.TeamA == "" || req.TeamB == "" || len(req.TeamA) > 64 || len(req.TeamB) > 64 {
+		return fmt.Errorf("%w: team_a and team_b must be 1-64 characters", ErrInvalidVeto)
+	}
+	if strings.EqualFold(req.TeamA, req.TeamB) {
+		return fmt.Errorf("%w: team_a and team_b must differ", ErrInvalidVeto)
+	}
+	if len(req.Steps) == 0 || len(req.Steps) > maxVetoSteps {
+		return fmt.Errorf("%w: a veto has 1-%d steps", ErrInvalidVeto, maxVetoSteps)
+	}
+
+	seen := make(map[string]bool, len(req.Steps))
+	for i := range req.Steps {
+		st := &req.Steps[i]
+		st.Step = i + 1
+		st.Winner = ""
+		st.Map = strings.ToLower(strings.TrimSpace(st.Map))
+		st.TeamASide = strings.ToLower(strings.TrimSpace(st.TeamASide))
+		st.MatchID = strings.TrimSpace(st.MatchID)
+
+		switch st.Action {
+		case models.VetoBan, models.VetoPick:
+			if st.Team != models.VetoTeamA && st.Team != models.VetoTeamB {
+				return fmt.Errorf("%w: step %d: team must be a or b", ErrInvalidVeto, st.Step)
+			}
+		case models.VetoDecider:
+			if st.Team != "" {
+				return fmt.Errorf("%w: step %d: the decider has no team", ErrInvalidVeto, st.Step)
+			}
+		default:
+			return fmt.Errorf("%w: step %d: action must be ban, pick or decider", ErrInvalidVeto, st.Step)
+		}
+		if st.Map == "" || len(st.Map) > 64 {
+			return fmt.Errorf("%w: step %d: map must be 1-64 characters", ErrInvalidVeto, st.Step)
+		}
+		if seen[st.Map] {
+			return fmt.Errorf("%w: step %d: %s is already vetoed", ErrInvalidVeto, st.Step, st.Map)
+		}
+		seen[st.Map] = true
+
+		switch {
+		case st.MatchID == "" && st.TeamASide != "":
+			return fmt.Errorf("%w: step %d: team_a_side needs a match_id", ErrInvalidVeto, st.Step)
+		case st.MatchID == "":
+		case st.Action == models.VetoBan:
+			return fmt.Errorf("%w: step %d: banned maps are not played", ErrInvalidVeto, st.Step)
+		case st.TeamASide != "allies" && st.TeamASide != "axis":
+			return fmt.Errorf("%w: step %d: team_a_side must be allies or axis", ErrInvalidVeto, st.Step)
+		}
+	}
+	return nil
+}
+
+// vetoWinner turns a match's winning side into the veto team that won it:
+// a, b, draw, or empty when the match has no result
+func vetoWinner(teamASide, winner string) string {
+	switch winner {
+	case "":
+		return ""
+	case models.CorrectionWinnerDraw:
+		return models.CorrectionWinnerDraw
+	case teamASide:
+		return models.VetoTeamA
+	default:
+		return models.VetoTeamB
+	}
+}
+
+// vetoAnalytics counts bans, picks and picker wins per map over vetoes
+// whose winners are resolved
+func vetoAnalytics(tournamentID string, vetoes []*models.MapVeto) *models.VetoAnalytics {
+	a := &models.VetoAnalytics{TournamentID: tournamentID, Series: len(vetoes), Maps: []models.VetoMapStats{}}
+	byMap := map[string]*models.VetoMapStats{}
+	decidedPicks := map[string]int{}
+	for _, v := range vetoes {
+		for _, st := range v.Steps {
+			m := byMap[st.Map]
+			if m == nil {
+				m = &models.VetoMapStats{Map: st.Map}
+				byMap[st.Map] = m
+			}
+			switch st.Action {
+			case models.VetoBan:
+				m.Bans++
+				if st.Step == 1 {
+					m.FirstBans++
+				}
+				continue
+			case models.VetoPick:
+				m.Picks++
+			case models.VetoDecider:
+				m.Deciders++
+			}
+			if st.Winner == "" {
+				continue
+			}
+			m.Decided++
+			if st.Action == models.VetoPick {
+				decidedPicks[st.Map]++
+				a.DecidedPicks++
+				if st.Winner == st.Team {
+					m.PickerWins++
+					a.PickerWins++
+				}
+			}
+		}
+	}
+
+	for _, m := range byMap {
+		if picked := decidedPicks[m.Map]; picked > 0 {
+			m.PickerWinRate = float64(m.PickerWins) / float64(picked)
+		}
+		a.Maps = append(a.Maps, *m)
+	}
+	sort.Slice(a.Maps, func(i, j int) bool {
+		if a.Maps[i].Bans != a.Maps[j].Bans {
+			return a.Maps[i].Bans > a.Maps[j].Bans
+		}
+		return a.Maps[i].Map < a.Maps[j].Map
+	})
+	if a.DecidedPicks > 0 {
+		a.PickerWinRate = float64(a.PickerWins) / float64(a.DecidedPicks)
+	}
+	return a
+}
+
+type mapVetoService struct {
+	pg PgPool
+}
+
+func NewMapVetoService(pg PgPool) MapVetoService {
+	return &mapVetoService{pg: pg}
+}
+
+const vetoColumns = `tournament_id, series_id, team_a, team_b, steps, recorded_by, updated_at`
+
+func scanVeto(row pgx.Row) (*models.MapVeto, error) {
+	v := &models.MapVeto{}
+	err := row.Scan(&v.TournamentID, &v.SeriesID, &v.TeamA, &v.TeamB, &v.Steps, &v.RecordedBy, &v.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrVetoNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read map veto: %w", err)
+	}
+	return v, nil
+}
+
+// Record stores the veto of a series, replacing the one recorded before.
+// Recording it again once maps are played links them to their matches.
+func (s *mapVetoService) Record(ctx context.Context, tournamentID, seriesID string, req *models.MapVetoRequest, actor string) (*models.MapVeto, error) {
+	if err := ValidateMapVeto(req); err != nil {
+		return nil, err
+	}
+	v, err := scanVeto(s.pg.QueryRow(ctx, `
+		INSERT INTO map_vetoes (tournament_id, series_id, team_a, team_b, steps, recorded_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (tournament_id, series_id) DO UPDATE SET
+			team_a = EXCLUDED.team_a, team_b = EXCLUDED.team_b, steps = EXCLUDED.steps,
+			recorded_by = EXCLUDED.recorded_by, updated_at = NOW()
+		RETURNING `+vetoColumns,
+		tournamentID, seriesID, req.TeamA, req.TeamB, req.Steps, actor))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.resolveWinners(ctx, tournamentID, []*models.MapVeto{v}); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Get returns the veto of a series with the winners of its played maps
+func (s *mapVetoService) Get(ctx context.Context, tournamentID, seriesID string) (*models.MapVeto, error) {
+	v, err := scanVeto(s.pg.QueryRow(ctx, `
+		SELECT `+vetoColumns+` FROM map_vetoes
+		WHERE tournament_id = $1 AND series_id = $2
+	`, tournamentID, seriesID))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.resolveWinners(ctx, tournamentID, []*models.MapVeto{v}); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// List returns the vetoes of a tournament, oldest series first
+func (s *mapVetoService) List(ctx context.Context, tournamentID string) ([]*models.MapVeto, error) {
+	rows, err := s.pg.Query(ctx, `
+		SELECT `+vetoColumns+` FROM map_vetoes
+		WHERE tournament_id = $1
+		ORDER BY created_at, series_id
+	`, tournamentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list map vetoes: %w", err)
+	}
+	defer rows.Close()
+
+	vetoes := []*models.MapVeto{}
+	for rows.Next() {
+		v, err := scanVeto(rows)
+		if err != nil {
+			return nil, err
+		}
+		vetoes = append(vetoes, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list map vetoes: %w", err)
+	}
+	if err := s.resolveWinners(ctx, tournamentID, vetoes); err != nil {
+		return nil, err
+	}
+	return vetoes, nil
+}
+
+// Analytics returns the most banned maps of a tournament and how often a
+// picked map was won by the team that picked it
+func (s *mapVetoService) Analytics(ctx context.Context, tournamentID string) (*models.VetoAnalytics, error) {
+	vetoes, err := s.List(ctx, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	return vetoAnalytics(tournamentID, vetoes), nil
+}
+
+// resolveWinners sets the winner of each played map from its match's signed
+// result, which carries any correction of the match
+func (s *mapVetoService) resolveWinners(ctx context.Context, tournamentID string, vetoes []*models.MapVeto) error {
+	var matchIDs []string
+	for _, v := range vetoes {
+		for _, st := range v.Steps {
+			if st.MatchID != "" {
+				matchIDs = append(matchIDs, st.MatchID)
+			}
+		}
+	}
+	if len(matchIDs) == 0 {
+		return nil
+	}
+
+	rows, err := s.pg.Query(ctx, `
+		SELECT match_id, COALESCE(convert_from(payload, 'UTF8')::jsonb->>'winner', '')
+		FROM tournament_match_results
+		WHERE tournament_id = $1 AND match_id = ANY($2)
+	`, tournamentID, matchIDs)
+	if err != nil {
+		return fmt.Errorf("failed to read veto match results: %w", err)
+	}
+	defer rows.Close()
+	winners := make(map[string]string, len(matchIDs))
+	for rows.Next() {
+		var matchID, winner string
+		if err := rows.Scan(&matchID, &winner); err != nil {
+			return fmt.Errorf("failed to read veto match results: %w", err)
+		}
+		winners[matchID] = winner
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read veto match results: %w", err)
+	}
+
+	for _, v := range vetoes {
+		for i := range v.Steps {
+			st := &v.Steps[i]
+			if st.MatchID != "" {
+				st.Winner = vetoWinner(st.TeamASide, winners[st.MatchID])
+			}
+		}
+	}
+	return nil
+}
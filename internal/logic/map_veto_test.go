@@ -0,0 +1,102 @@
+package logic
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestValidateMapVeto(t *testing.T) {
+	req := &models.MapVetoRequest{
+		TeamA: " Band of Brothers ",
+		TeamB: "Easy Company",
+		Steps: []models.MapVetoStep{
+			{Team: "a", Action: models.VetoBan, Map: "OBJ/obj_team1"},
+			{Team: "b", Action: models.VetoBan, Map: "obj/obj_team2"},
+			{Team: "a", Action: models.VetoPick, Map: "obj/obj_team3", MatchID: "m1", TeamASide: "Axis", Winner: "a"},
+			{Action: models.VetoDecider, Map: "obj/obj_team4"},
+		},
+	}
+	if err := ValidateMapVeto(req); err != nil {
+		t.Fatal(err)
+	}
+	if req.TeamA != "Band of Brothers" || req.Steps[0].Map != "obj/obj_team1" || req.Steps[2].TeamASide != "axis" {
+		t.Errorf("not normalized: %+v", req)
+	}
+	if req.Steps[3].Step != 4 || req.Steps[2].Winner != "" {
+		t.Errorf("steps not numbered or winner kept: %+v", req.Steps)
+	}
+
+	bad := map[string]models.MapVetoRequest{
+		"same teams": {TeamA: "x", TeamB: "X", Steps: []models.MapVetoStep{{Team: "a", Action: models.VetoBan, Map: "m"}}},
+		"no steps":   {TeamA: "x", TeamB: "y"},
+		"ban without team": {TeamA: "x", TeamB: "y", Steps: []models.MapVetoStep{
+			{Action: models.VetoBan, Map: "m"}}},
+		"decider with team": {TeamA: "x", TeamB: "y", Steps: []models.MapVetoStep{
+			{Team: "a", Action: models.VetoDecider, Map: "m"}}},
+		"repeated map": {TeamA: "x", TeamB: "y", Steps: []models.MapVetoStep{
+			{Team: "a", Action: models.VetoBan, Map: "m"}, {Team: "b", Action: models.VetoPick, Map: "M"}}},
+		"played ban": {TeamA: "x", TeamB: "y", Steps: []models.MapVetoStep{
+			{Team: "a", Action: models.VetoBan, Map: "m", MatchID: "m1", TeamASide: "allies"}}},
+		"match without side": {TeamA: "x", TeamB: "y", Steps: []models.MapVetoStep{
+			{Team: "a", Action: models.VetoPick, Map: "m", MatchID: "m1"}}},
+		"unknown action": {TeamA: "x", TeamB: "y", Steps: []models.MapVetoStep{
+			{Team: "a", Action: "protect", Map: "m"}}},
+	}
+	for name, req := range bad {
+		if err := ValidateMapVeto(&req); !errors.Is(err, ErrInvalidVeto) {
+			t.Errorf("%s: got %v", name, err)
+		}
+	}
+}
+
+func TestVetoAnalytics(t *testing.T) {
+	vetoes := []*models.MapVeto{
+		{Steps: []models.MapVetoStep{
+			{Step: 1, Team: "a", Action: models.VetoBan, Map: "v2"},
+			{Step: 2, Team: "b", Action: models.VetoBan, Map: "bridge"},
+			{Step: 3, Team: "a", Action: models.VetoPick, Map: "stalingrad", Winner: "a"},
+			{Step: 4, Team: "b", Action: models.VetoPick, Map: "flughafen", Winner: "a"},
+			{Step: 5, Action: models.VetoDecider, Map: "brest", Winner: "b"},
+		}},
+		{Steps: []models.MapVetoStep{
+			{Step: 1, Team: "b", Action: models.VetoBan, Map: "v2"},
+			{Step: 2, Team: "a", Action: models.VetoPick, Map: "stalingrad", Winner: "draw"},
+			{Step: 3, Team: "b", Action: models.VetoPick, Map: "bridge"},
+		}},
+	}
+	a := vetoAnalytics("t1", vetoes)
+	if a.Series != 2 || a.DecidedPicks != 3 || a.PickerWins != 1 {
+		t.Fatalf("totals: %+v", a)
+	}
+	if a.Maps[0].Map != "v2" || a.Maps[0].Bans != 2 || a.Maps[0].FirstBans != 2 {
+		t.Errorf("most banned: %+v", a.Maps[0])
+	}
+	for _, m := range a.Maps {
+		switch m.Map {
+		case "stalingrad":
+			if m.Picks != 2 || m.Decided != 2 || m.PickerWins != 1 || m.PickerWinRate != 0.5 {
+				t.Errorf("stalingrad: %+v", m)
+			}
+		case "bridge":
+			if m.Bans != 1 || m.Picks != 1 || m.Decided != 0 || m.PickerWinRate != 0 {
+				t.Errorf("bridge: %+v", m)
+			}
+		case "brest":
+			if m.Deciders != 1 || m.Decided != 1 || m.PickerWins != 0 {
+				t.Errorf("brest: %+v", m)
+			}
+		}
+	}
+
+	if got := vetoWinner("axis", "axis"); got != "a" {
+		t.Errorf("team a on axis, axis won: %q", got)
+	}
+	if got := vetoWinner("axis", "allies"); got != "b" {
+		t.Errorf("team a on axis, allies won: %q", got)
+	}
+	if got := vetoWinner("allies", ""); got != "" {
+		t.Errorf("no result: %q", got)
+	}
+}
@@ -0,0 +1,342 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/google/uuid"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+type matchAdminService struct {
+	ch driver.Conn
+	pg PgPool
+}
+
+func NewMatchAdminService(ch driver.Conn, pg PgPool) MatchAdminService {
+	return &matchAdminService{ch: ch, pg: pg}
+}
+
+// MatchRecompute is a match rebuilt from its stored events
+type MatchRecompute struct {
+	Result *models.MatchRecomputeResult
+	// Events are the match's stored events in order, for replaying achievements
+	Events []*models.RawEvent
+	// Outcomes are the match_outcome events stored in place of the old ones
+	Outcomes []*models.RawEvent
+}
+
+// Void excludes a match from aggregate rebuilds and revokes the achievements
+// unlocked in it. Its events are kept so Restore can undo it.
+func (s *matchAdminService) Void(ctx context.Context, matchID, reason string) (int64, error) {
+	if err := s.ch.Exec(ctx, `
+		INSERT INTO mohaa_stats.voided_matches (match_id, voided, reason)
+		VALUES (toUUID(?), 1, ?)
+	`, matchID, reason); err != nil {
+		return 0, fmt.Errorf("failed to void match: %w", err)
+	}
+	return s.RevokeAchievements(ctx, matchID)
+}
+
+// Restore counts a voided match again in aggregate rebuilds
+func (s *matchAdminService) Restore(ctx context.Context, matchID string) error {
+	if err := s.ch.Exec(ctx, `
+		INSERT INTO mohaa_stats.voided_matches (match_id, voided)
+		VALUES (toUUID(?), 0)
+	`, matchID); err != nil {
+		return fmt.Errorf("failed to restore match: %w", err)
+	}
+	return nil
+}
+
+// RevokeAchievements locks again the achievements unlocked in a match
+func (s *matchAdminService) RevokeAchievements(ctx context.Context, matchID string) (int64, error) {
+	tag, err := s.pg.Exec(ctx, `
+		UPDATE mohaa_player_achievements
+		SET unlocked = false, unlocked_at = NULL, progress = 0, match_id = NULL, updated_at = NOW()
+		WHERE match_id = $1 AND unlocked
+	`, matchID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke achievements: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// Recompute reloads a match's events and derives its outcomes again. The old
+// match_outcome rows are replaced; the caller replays the events through the
+// achievement checks and rebuilds the aggregates, which still count the old
+// outcomes.
+func (s *matchAdminService) Recompute(ctx context.Context, matchID string) (*MatchRecompute, error) {
+	events, endedAt, err := s.matchEvents(ctx, matchID)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, ErrUnknownMatch
+	}
+
+	revoked, err := s.RevokeAchievements(ctx, matchID)
+	if err != nil {
+		return nil, err
+	}
+	recompute, err := s.replaceOutcomes(ctx, matchID, events, endedAt)
+	if err != nil {
+		return nil, err
+	}
+	recompute.Result.AchievementsRevoked = revoked
+	return recompute, nil
+}
+
+// ReplaceOutcomes derives a match's outcomes again, as after a correction,
+// leaving its achievements alone. The old match_outcome rows are replaced.
+func (s *matchAdminService) ReplaceOutcomes(ctx context.Context, matchID string) (*MatchRecompute, error) {
+	events, endedAt, err := s.matchEvents(ctx, matchID)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, ErrUnknownMatch
+	}
+	return s.replaceOutcomes(ctx, matchID, events, endedAt)
+}
+
+// replaceOutcomes deletes a match's match_outcome rows and inserts new ones
+// derived from its events, stamped with the time of its last stored event.
+// The match's correction, if any, supersedes the derived winner.
+func (s *matchAdminService) replaceOutcomes(ctx context.Context, matchID string, events []*models.RawEvent, endedAt time.Time) (*MatchRecompute, error) {
+	correction, err := matchCorrection(ctx, s.pg, matchID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Wait for the delete so the new outcomes are not removed with the old
+	syncCtx := clickhouse.Context(ctx, clickhouse.WithSettings(clickhouse.Settings{"mutations_sync": 2}))
+	if err := s.ch.Exec(syncCtx, `
+		ALTER TABLE mohaa_stats.raw_events
+		DELETE WHERE match_id = toUUID(?) AND event_type = 'match_outcome'
+	`, matchID); err != nil {
+		return nil, fmt.Errorf("failed to delete match outcomes: %w", err)
+	}
+
+	outcomes, winner := DeriveMatchOutcomes(events)
+	if correction != nil {
+		applyCorrectedWinner(outcomes, correction.Winner)
+		winner = correction.Winner
+	}
+	if err := s.insertOutcomes(ctx, matchID, endedAt, outcomes); err != nil {
+		return nil, err
+	}
+	return &MatchRecompute{
+		Result: &models.MatchRecomputeResult{
+			MatchID:     matchID,
+			Events:      len(events),
+			Players:     len(outcomes),
+			WinningTeam: winner,
+			Outcomes:    len(outcomes),
+		},
+		Events:   events,
+		Outcomes: outcomes,
+	}, nil
+}
+
+// insertOutcomes writes match_outcome rows straight to raw_events, with the
+// gametype in actor_weapon as the worker stores them. Unlike the ingest queue
+// this skips the live side effects (inbox, announcer, event bus), which
+// already fired when the match ended.
+func (s *matchAdminService) insertOutcomes(ctx context.Context, matchID string, endedAt time.Time, outcomes []*models.RawEvent) error {
+	if len(outcomes) == 0 {
+		return nil
+	}
+	id, err := uuid.Parse(matchID)
+	if err != nil {
+		return fmt.Errorf("failed to insert match outcomes: %w", err)
+	}
+	batch, err := s.ch.PrepareBatch(ctx, `
+		INSERT INTO mohaa_stats.raw_events (
+			timestamp, match_id, server_id, tenant_id, map_name, event_type,
+			actor_id, actor_name, actor_team, actor_weapon, actor_smf_id, match_outcome, raw_json
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to insert match outcomes: %w", err)
+	}
+	for _, o := range outcomes {
+		raw, _ := json.Marshal(o)
+		if err := batch.Append(
+			endedAt, id, o.ServerID, o.TenantID, o.MapName, string(o.Type),
+			o.PlayerGUID, o.PlayerName, o.PlayerTeam, o.Gametype, o.PlayerSMFID, o.MatchOutcome, string(raw),
+		); err != nil {
+			return fmt.Errorf("failed to insert match outcomes: %w", err)
+		}
+	}
+	if err := batch.Send(); err != nil {
+		return fmt.Errorf("failed to insert match outcomes: %w", err)
+	}
+	return nil
+}
+
+// matchEvents decodes the stored events of a match in order and returns the
+// time of the last. Derived match_outcome rows are skipped, as are events
+// stored without raw_json.
+func (s *matchAdminService) matchEvents(ctx context.Context, matchID string) ([]*models.RawEvent, time.Time, error) {
+	var endedAt time.Time
+	rows, err := s.ch.Query(ctx, `
+		SELECT raw_json, tenant_id, timestamp
+		FROM mohaa_stats.raw_events
+		WHERE match_id = toUUID(?) AND event_type != 'match_outcome' AND raw_json != ''
+		ORDER BY timestamp
+	`, matchID)
+	if err != nil {
+		return nil, endedAt, fmt.Errorf("failed to load match events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*models.RawEvent
+	for rows.Next() {
+		var raw, tenantID string
+		if err := rows.Scan(&raw, &tenantID, &endedAt); err != nil {
+			return nil, endedAt, fmt.Errorf("failed to load match events: %w", err)
+		}
+		var event models.RawEvent
+		if err := json.Unmarshal([]byte(raw), &event); err != nil {
+			continue
+		}
+		event.MatchID = matchID
+		event.TenantID = tenantID
+		events = append(events, &event)
+	}
+	return events, endedAt, rows.Err()
+}
+
+// DeriveMatchOutcomes rebuilds the win/loss events match_end produces from a
+// match's events by replaying what the worker keeps in live state: teams from
+// team_join, spawns and heartbeat rosters, the winner from match_end, else
+// the last team_win, else the last team scores, and in a free-for-all the
+// top scorer of the last roster. Bots and spectators get no outcome.
+func DeriveMatchOutcomes(events []*models.RawEvent) ([]*models.RawEvent, string) {
+	teams := make(map[string]string)
+	names := make(map[string]string)
+	smfIDs := make(map[string]int64)
+	bots := make(map[string]bool)
+	var board map[string]models.RosterEntry
+	var order []string
+	seen := func(guid string) {
+		if _, ok := names[guid]; !ok {
+			order = append(order, guid)
+			names[guid] = ""
+		}
+	}
+
+	var endWinner, recordedWinner, gametype, serverID, mapName, tenantID string
+	var allies, axis int
+	var endedAt float64
+	for _, e := range events {
+		if e.ServerID != "" {
+			serverID = e.ServerID
+		}
+		if e.MapName != "" {
+			mapName = e.MapName
+		}
+		tenantID = e.TenantID
+		endedAt = e.Timestamp
+		if e.PlayerGUID != "" {
+			if e.BotID != "" {
+				bots[e.PlayerGUID] = true
+			}
+			seen(e.PlayerGUID)
+			if e.PlayerName != "" {
+				names[e.PlayerGUID] = e.PlayerName
+			}
+			if e.PlayerSMFID > 0 {
+				smfIDs[e.PlayerGUID] = e.PlayerSMFID
+			}
+		}
+		if a, x := EventScores(e); a != 0 || x != 0 {
+			switch e.Type {
+			case models.EventHeartbeat, models.EventTeamWin, models.EventRoundEnd, models.EventMatchEnd:
+				allies, axis = a, x
+			}
+		}
+
+		switch e.Type {
+		case models.EventMatchStart:
+			gametype = e.Gametype
+		case models.EventTeamWin:
+			if winner := EventWinner(e); winner != "" {
+				recordedWinner = winner
+			}
+		case models.EventMatchEnd:
+			endWinner = EventWinner(e)
+			if gametype == "" {
+				gametype = e.Gametype
+			}
+		case models.EventTeamJoin:
+			if e.PlayerGUID != "" && e.NewTeam != "" {
+				teams[e.PlayerGUID] = e.NewTeam
+			}
+		case models.EventPlayerSpawn:
+			if e.PlayerGUID != "" && e.PlayerTeam != "" {
+				teams[e.PlayerGUID] = e.PlayerTeam
+			}
+		case models.EventHeartbeat:
+			if len(e.Roster) == 0 {
+				break
+			}
+			board = make(map[string]models.RosterEntry, len(e.Roster))
+			for _, entry := range e.Roster {
+				if entry.GUID == "" {
+					continue
+				}
+				seen(entry.GUID)
+				board[entry.GUID] = entry
+				if entry.Name != "" {
+					names[entry.GUID] = entry.Name
+				}
+				if entry.Team != "" {
+					teams[entry.GUID] = entry.Team
+				}
+			}
+		}
+	}
+	if endedAt == 0 {
+		endedAt = float64(time.Now().Unix())
+	}
+
+	for guid := range bots {
+		delete(teams, guid)
+		delete(board, guid)
+	}
+	winner := MatchWinner(endWinner, recordedWinner, allies, axis)
+	results := TeamOutcomes(teams, winner)
+	if IsFFAGametype(gametype) {
+		results = FFAOutcomes(board)
+	}
+
+	outcomes := make([]*models.RawEvent, 0, len(results))
+	for _, guid := range order {
+		won, ok := results[guid]
+		if !ok {
+			continue
+		}
+		outcomes = append(outcomes, &models.RawEvent{
+			Type:         models.EventMatchOutcome,
+			MatchID:      events[0].MatchID,
+			ServerID:     serverID,
+			TenantID:     tenantID,
+			MapName:      mapName,
+			Timestamp:    endedAt,
+			PlayerGUID:   guid,
+			PlayerName:   names[guid],
+			PlayerTeam:   NormalizeTeam(teams[guid]),
+			Gametype:     gametype,
+			MatchOutcome: won,
+			PlayerSMFID:  smfIDs[guid],
+		})
+	}
+	return outcomes, winner
+}
@@ -0,0 +1,103 @@
+package logic
+
+import (
+	"testing"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestDeriveMatchOutcomes(t *testing.T) {
+	events := []*models.RawEvent{
+		{Type: models.EventMatchStart, MatchID: "m1", ServerID: "s1", MapName: "obj_team2", Gametype: "obj", Timestamp: 100},
+		{Type: models.EventConnect, PlayerGUID: "a", PlayerName: "Alice", PlayerSMFID: 7},
+		{Type: models.EventConnect, PlayerGUID: "b", PlayerName: "Bob"},
+		{Type: models.EventConnect, PlayerGUID: "spec", PlayerName: "Spectator"},
+		{Type: models.EventTeamJoin, PlayerGUID: "a", NewTeam: "axis"},
+		{Type: models.EventPlayerSpawn, PlayerGUID: "b", PlayerTeam: "axis"},
+		{Type: models.EventTeamJoin, PlayerGUID: "b", NewTeam: "allies"}, // switched sides
+		{Type: models.EventTeamWin, WinningTeam: "axis"},
+		{Type: models.EventTeamWin, WinningTeam: "allies"},
+		{Type: models.EventMatchEnd, Timestamp: 900},
+	}
+
+	outcomes, winner := DeriveMatchOutcomes(events)
+	if winner != "allies" {
+		t.Errorf("winner = %q, want the last team_win (allies)", winner)
+	}
+	if len(outcomes) != 2 {
+		t.Fatalf("%d outcomes, want 2 (spectators have no team)", len(outcomes))
+	}
+
+	a, b := outcomes[0], outcomes[1]
+	if a.PlayerGUID != "a" || a.PlayerTeam != "axis" || a.MatchOutcome != 0 || a.PlayerSMFID != 7 || a.PlayerName != "Alice" {
+		t.Errorf("outcome a = %+v", a)
+	}
+	if b.PlayerGUID != "b" || b.PlayerTeam != "allies" || b.MatchOutcome != 1 {
+		t.Errorf("outcome b = %+v", b)
+	}
+	if a.Type != models.EventMatchOutcome || a.MatchID != "m1" || a.ServerID != "s1" || a.Gametype != "obj" || a.Timestamp != 900 {
+		t.Errorf("outcome context = %+v", a)
+	}
+
+	// A winner on match_end overrides team_win
+	events[len(events)-1].WinningTeam = "axis"
+	if _, winner := DeriveMatchOutcomes(events); winner != "axis" {
+		t.Errorf("winner = %q, want match_end's axis", winner)
+	}
+}
+
+func TestDeriveMatchOutcomesLikeWorker(t *testing.T) {
+	// Team names are normalized, bots are left out and, with nobody named
+	// the winner, the last scores decide
+	events := []*models.RawEvent{
+		{Type: models.EventMatchStart, MatchID: "m1", Gametype: "tdm"},
+		{Type: models.EventTeamJoin, PlayerGUID: "a", NewTeam: "American"},
+		{Type: models.EventTeamJoin, PlayerGUID: "b", NewTeam: "german"},
+		{Type: models.EventPlayerSpawn, PlayerGUID: "bot", PlayerTeam: "axis", BotID: "1"},
+		{Type: models.EventHeartbeat, AlliesScore: 3, AxisScore: 5},
+		{Type: models.EventMatchEnd},
+	}
+	outcomes, winner := DeriveMatchOutcomes(events)
+	if winner != "axis" {
+		t.Errorf("winner = %q, want axis on score", winner)
+	}
+	if len(outcomes) != 2 || outcomes[0].PlayerTeam != "allies" || outcomes[0].MatchOutcome != 0 ||
+		outcomes[1].PlayerTeam != "axis" || outcomes[1].MatchOutcome != 1 {
+		t.Errorf("outcomes = %+v %+v", outcomes[0], outcomes[len(outcomes)-1])
+	}
+
+	// A free-for-all is won by the top scorer of the last roster
+	events = []*models.RawEvent{
+		{Type: models.EventMatchStart, MatchID: "m2", Gametype: "dm"},
+		{Type: models.EventHeartbeat, Roster: []models.RosterEntry{
+			{GUID: "a", Name: "Alice", Score: 20},
+			{GUID: "b", Name: "Bob", Score: 12},
+			{GUID: "spec", Team: "spectator", Score: 0},
+		}},
+		{Type: models.EventMatchEnd},
+	}
+	outcomes, _ = DeriveMatchOutcomes(events)
+	if len(outcomes) != 2 {
+		t.Fatalf("%d outcomes, want 2 (spectators have none)", len(outcomes))
+	}
+	for _, o := range outcomes {
+		if want := map[string]uint8{"a": 1, "b": 0}[o.PlayerGUID]; o.MatchOutcome != want || o.Gametype != "dm" {
+			t.Errorf("outcome %+v, want %d", o, want)
+		}
+	}
+}
+
+func TestApplyCorrectedWinner(t *testing.T) {
+	outcomes := []*models.RawEvent{
+		{PlayerGUID: "a", PlayerTeam: "axis", MatchOutcome: 1},
+		{PlayerGUID: "b", PlayerTeam: "allies"},
+	}
+	applyCorrectedWinner(outcomes, models.CorrectionWinnerAllies)
+	if outcomes[0].MatchOutcome != 0 || outcomes[1].MatchOutcome != 1 {
+		t.Errorf("allies correction: a=%d b=%d", outcomes[0].MatchOutcome, outcomes[1].MatchOutcome)
+	}
+	applyCorrectedWinner(outcomes, models.CorrectionWinnerDraw)
+	if outcomes[0].MatchOutcome != 0 || outcomes[1].MatchOutcome != 0 {
+		t.Errorf("draw: a=%d b=%d", outcomes[0].MatchOutcome, outcomes[1].MatchOutcome)
+	}
+}
@@ -0,0 +1,86 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// ErrMatchNotFound is returned for a match without events
+var ErrMatchNotFound = errors.New("match not found")
+
+// matchCardTopPlayers is how many fraggers a match card lists
+const matchCardTopPlayers = 5
+
+// GetMatchCard reads what the share card of a match shows: where and what
+// was played, the final score and the top fraggers
+func (s *matchReportService) GetMatchCard(ctx context.Context, matchID string) (*models.MatchCardStats, error) {
+	c := &models.MatchCardStats{MatchID: matchID}
+	var events uint64
+	var ended time.Time
+	var alliesScore, axisScore int32
+	var players, kills uint64
+	if err := s.ch.QueryRow(ctx, `
+		SELECT
+			count(),
+			any(server_id),
+			any(map_name),
+			anyIf(JSONExtractString(raw_json, 'gametype'), event_type = 'match_start'),
+			toInt32(maxIf(JSONExtractInt(raw_json, 'allies_score'), event_type IN ('match_end', 'heartbeat'))),
+			toInt32(maxIf(JSONExtractInt(raw_json, 'axis_score'), event_type IN ('match_end', 'heartbeat'))),
+			uniqIf(actor_id, actor_id != '' AND actor_id != 'world'),
+			countIf(event_type IN ('player_kill', 'bot_killed')),
+			min(timestamp),
+			max(timestamp)
+		FROM mohaa_stats.raw_events
+		WHERE match_id = toUUID(?)
+	`, matchID).Scan(&events, &c.ServerID, &c.MapName, &c.Gametype, &alliesScore, &axisScore,
+		&players, &kills, &c.StartedAt, &ended); err != nil {
+		return nil, fmt.Errorf("failed to read match: %w", err)
+	}
+	if events == 0 {
+		return nil, ErrMatchNotFound
+	}
+	c.AlliesScore, c.AxisScore = int(alliesScore), int(axisScore)
+	c.Players, c.Kills = int(players), int(kills)
+	c.Duration = ended.Sub(c.StartedAt)
+
+	rows, err := s.ch.Query(ctx, `
+		SELECT
+			player_id,
+			argMax(name, ts) AS name,
+			toInt64(sum(kill)) AS kills,
+			toInt64(sum(death)) AS deaths
+		FROM (
+			SELECT actor_id AS player_id, actor_name AS name, timestamp AS ts, 1 AS kill, 0 AS death
+			FROM mohaa_stats.raw_events
+			WHERE match_id = toUUID(?) AND event_type IN ('player_kill', 'bot_killed')
+			UNION ALL
+			SELECT target_id, target_name, timestamp, 0, 1
+			FROM mohaa_stats.raw_events
+			WHERE match_id = toUUID(?) AND event_type = 'player_kill'
+		)
+		WHERE player_id != '' AND player_id != 'world'
+		GROUP BY player_id
+		HAVING kills > 0
+		ORDER BY kills DESC, deaths ASC, player_id
+		LIMIT ?
+	`, matchID, matchID, matchCardTopPlayers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read match players: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p models.MatchCardPlayer
+		var k, d int64
+		if err := rows.Scan(&p.GUID, &p.Name, &k, &d); err != nil {
+			return nil, fmt.Errorf("failed to read match players: %w", err)
+		}
+		p.Kills, p.Deaths = int(k), int(d)
+		c.TopPlayers = append(c.TopPlayers, p)
+	}
+	return c, rows.Err()
+}
@@ -0,0 +1,280 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+var (
+	// ErrDisputeNotFound is returned for an unknown dispute
+	ErrDisputeNotFound = errors.New("dispute not found")
+	// ErrDisputeOpen is returned when a match already has an open dispute
+	ErrDisputeOpen = errors.New("match already has an open dispute")
+	// ErrDisputeClosed is returned for changes to a corrected or rejected dispute
+	ErrDisputeClosed = errors.New("dispute is closed")
+)
+
+const disputeColumns = `id, match_id, tournament_id, status, reason, opened_by, resolution, resolved_by, created_at, resolved_at`
+
+const correctionColumns = `id, match_id, dispute_id, allies_score, axis_score, winner, reason, corrected_by, created_at`
+
+type matchDisputeService struct {
+	pg PgPool
+}
+
+func NewMatchDisputeService(pg PgPool) MatchDisputeService {
+	return &matchDisputeService{pg: pg}
+}
+
+func scanDispute(row pgx.Row) (*models.MatchDispute, error) {
+	d := &models.MatchDispute{Notes: []models.DisputeNote{}}
+	err := row.Scan(&d.ID, &d.MatchID, &d.TournamentID, &d.Status, &d.Reason, &d.OpenedBy,
+		&d.Resolution, &d.ResolvedBy, &d.CreatedAt, &d.ResolvedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrDisputeNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dispute: %w", err)
+	}
+	return d, nil
+}
+
+func scanCorrection(row pgx.Row) (*models.MatchCorrection, error) {
+	var c models.MatchCorrection
+	if err := row.Scan(&c.ID, &c.MatchID, &c.DisputeID, &c.AlliesScore, &c.AxisScore, &c.Winner,
+		&c.Reason, &c.CorrectedBy, &c.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Open starts a dispute on a tournament match. A match has one open dispute
+// at a time.
+func (s *matchDisputeService) Open(ctx context.Context, record *models.MatchStateRecord, reason, actor string) (*models.MatchDispute, error) {
+	if record.TournamentID == "" {
+		return nil, fmt.Errorf("%w: only tournament matches can be disputed", ErrMatchState)
+	}
+	d, err := scanDispute(s.pg.QueryRow(ctx, `
+		INSERT INTO match_disputes (match_id, tournament_id, reason, opened_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (match_id) WHERE status = 'open' DO NOTHING
+		RETURNING `+disputeColumns,
+		record.MatchID, record.TournamentID, reason, actor))
+	if errors.Is(err, ErrDisputeNotFound) {
+		return nil, ErrDisputeOpen
+	}
+	return d, err
+}
+
+// Get returns a dispute with its notes and correction
+func (s *matchDisputeService) Get(ctx context.Context, id int64) (*models.MatchDispute, error) {
+	d, err := scanDispute(s.pg.QueryRow(ctx, `SELECT `+disputeColumns+` FROM match_disputes WHERE id = $1`, id))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attach(ctx, []*models.MatchDispute{d}); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// List returns a match's disputes, newest first, with their notes and
+// corrections
+func (s *matchDisputeService) List(ctx context.Context, matchID string) ([]*models.MatchDispute, error) {
+	rows, err := s.pg.Query(ctx, `
+		SELECT `+disputeColumns+` FROM match_disputes
+		WHERE match_id = $1
+		ORDER BY id DESC
+	`, matchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list disputes: %w", err)
+	}
+	defer rows.Close()
+
+	disputes := []*models.MatchDispute{}
+	for rows.Next() {
+		d, err := scanDispute(rows)
+		if err != nil {
+			return nil, err
+		}
+		disputes = append(disputes, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list disputes: %w", err)
+	}
+	if err := s.attach(ctx, disputes); err != nil {
+		return nil, err
+	}
+	return disputes, nil
+}
+
+// attach loads the notes and corrections of disputes
+func (s *matchDisputeService) attach(ctx context.Context, disputes []*models.MatchDispute) error {
+	if len(disputes) == 0 {
+		return nil
+	}
+	byID := make(map[int64]*models.MatchDispute, len(disputes))
+	ids := make([]int64, 0, len(disputes))
+	for _, d := range disputes {
+		byID[d.ID] = d
+		ids = append(ids, d.ID)
+	}
+
+	rows, err := s.pg.Query(ctx, `
+		SELECT id, dispute_id, note, evidence_url, added_by, created_at
+		FROM match_dispute_notes
+		WHERE dispute_id = ANY($1)
+		ORDER BY id
+	`, ids)
+	if err != nil {
+		return fmt.Errorf("failed to read dispute notes: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var n models.DisputeNote
+		if err := rows.Scan(&n.ID, &n.DisputeID, &n.Note, &n.EvidenceURL, &n.AddedBy, &n.CreatedAt); err != nil {
+			return fmt.Errorf("failed to read dispute notes: %w", err)
+		}
+		byID[n.DisputeID].Notes = append(byID[n.DisputeID].Notes, n)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read dispute notes: %w", err)
+	}
+
+	corrections, err := s.pg.Query(ctx, `SELECT `+correctionColumns+` FROM match_corrections WHERE dispute_id = ANY($1)`, ids)
+	if err != nil {
+		return fmt.Errorf("failed to read corrections: %w", err)
+	}
+	defer corrections.Close()
+	for corrections.Next() {
+		c, err := scanCorrection(corrections)
+		if err != nil {
+			return fmt.Errorf("failed to read corrections: %w", err)
+		}
+		byID[*c.DisputeID].Correction = c
+	}
+	return corrections.Err()
+}
+
+// AddNote attaches evidence to an open dispute
+func (s *matchDisputeService) AddNote(ctx context.Context, disputeID int64, note *models.DisputeNote) error {
+	err := s.pg.QueryRow(ctx, `
+		INSERT INTO match_dispute_notes (dispute_id, note, evidence_url, added_by)
+		SELECT id, $2, $3, $4 FROM match_disputes WHERE id = $1 AND status = 'open'
+		RETURNING id, dispute_id, created_at
+	`, disputeID, note.Note, note.EvidenceURL, note.AddedBy).Scan(&note.ID, &note.DisputeID, &note.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return s.closedOrMissing(ctx, disputeID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to add dispute note: %w", err)
+	}
+	return nil
+}
+
+// Reject closes an open dispute and leaves the match's result as it is
+func (s *matchDisputeService) Reject(ctx context.Context, disputeID int64, resolution, actor string) (*models.MatchDispute, error) {
+	_, err := scanDispute(s.pg.QueryRow(ctx, `
+		UPDATE match_disputes SET
+			status = 'rejected', resolution = $2, resolved_by = $3, resolved_at = NOW()
+		WHERE id = $1 AND status = 'open'
+		RETURNING `+disputeColumns,
+		disputeID, resolution, actor))
+	if errors.Is(err, ErrDisputeNotFound) {
+		return nil, s.closedOrMissing(ctx, disputeID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, disputeID)
+}
+
+// Correct stores a correction of an ended or finalized tournament match's
+// result, locked or not. With a dispute ID the correction also closes that
+// open dispute of the match, in the same statement.
+func (s *matchDisputeService) Correct(ctx context.Context, record *models.MatchStateRecord, req *models.MatchCorrectionRequest, actor string) (*models.MatchCorrection, error) {
+	if record.TournamentID == "" {
+		return nil, fmt.Errorf("%w: only tournament matches can be corrected", ErrMatchState)
+	}
+	if record.State != models.MatchStateEnded && record.State != models.MatchStateFinalized {
+		return nil, fmt.Errorf("%w: match is %s", ErrMatchState, record.State)
+	}
+	c, err := scanCorrection(s.pg.QueryRow(ctx, `
+		WITH dispute AS (
+			UPDATE match_disputes SET
+				status = 'corrected', resolution = $5, resolved_by = $6, resolved_at = NOW()
+			WHERE id = $7 AND match_id = $1 AND status = 'open'
+			RETURNING id
+		)
+		INSERT INTO match_corrections (match_id, dispute_id, allies_score, axis_score, winner, reason, corrected_by)
+		SELECT $1, (SELECT id FROM dispute), $2, $3, $4, $5, $6
+		WHERE $7::BIGINT IS NULL OR EXISTS (SELECT 1 FROM dispute)
+		RETURNING `+correctionColumns,
+		record.MatchID, req.AlliesScore, req.AxisScore, req.Winner, req.Reason, actor, req.DisputeID))
+	if errors.Is(err, pgx.ErrNoRows) && req.DisputeID != nil {
+		d, gerr := s.Get(ctx, *req.DisputeID)
+		if gerr != nil {
+			return nil, gerr
+		}
+		if d.MatchID != record.MatchID {
+			return nil, ErrDisputeNotFound
+		}
+		return nil, ErrDisputeClosed
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to store correction: %w", err)
+	}
+	return c, nil
+}
+
+// Correction returns the correction that applies to a match, nil if none
+func (s *matchDisputeService) Correction(ctx context.Context, matchID string) (*models.MatchCorrection, error) {
+	return matchCorrection(ctx, s.pg, matchID)
+}
+
+// closedOrMissing tells apart a dispute that does not exist from one that
+// is no longer open
+func (s *matchDisputeService) closedOrMissing(ctx context.Context, disputeID int64) error {
+	var exists bool
+	if err := s.pg.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM match_disputes WHERE id = $1)`, disputeID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to read dispute: %w", err)
+	}
+	if !exists {
+		return ErrDisputeNotFound
+	}
+	return ErrDisputeClosed
+}
+
+// matchCorrection returns the latest correction of a match, nil if none.
+// It supersedes the score and winner derived from the match's events.
+func matchCorrection(ctx context.Context, pg PgPool, matchID string) (*models.MatchCorrection, error) {
+	c, err := scanCorrection(pg.QueryRow(ctx, `
+		SELECT `+correctionColumns+` FROM match_corrections
+		WHERE match_id = $1
+		ORDER BY id DESC
+		LIMIT 1
+	`, matchID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read correction: %w", err)
+	}
+	return c, nil
+}
+
+// applyCorrectedWinner sets each outcome's win against a corrected winner;
+// nobody wins a draw
+func applyCorrectedWinner(outcomes []*models.RawEvent, winner string) {
+	for _, o := range outcomes {
+		o.MatchOutcome = 0
+		if winner != models.CorrectionWinnerDraw && o.PlayerTeam == winner {
+			o.MatchOutcome = 1
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+var (
+	// ErrInvalidMedia is returned for an attachment that cannot be submitted
+	ErrInvalidMedia = errors.New("invalid match media")
+	// ErrMediaNotFound is returned for an unknown attachment ID
+	ErrMediaNotFound = errors.New("match media not found")
+	// ErrMediaQueueFull is returned when a user already has
+	// maxPendingMedia attachments awaiting moderation
+	ErrMediaQueueFull = errors.New("too many attachments awaiting moderation")
+	// ErrMediaExists is returned for a URL already attached to the match
+	ErrMediaExists = errors.New("media already attached to this match")
+)
+
+const (
+	// maxPendingMedia is how many attachments a user may have in the queue
+	maxPendingMedia = 10
+	// maxMediaCaption bounds a caption, in characters
+	maxMediaCaption = 200
+)
+
+// ValidateMatchMedia checks an attachment before it is queued and trims its
+// fields. The URL must be absolute http or https; it is shown, never fetched.
+func ValidateMatchMedia(req *models.MatchMediaRequest) error {
+	req.MatchID = strings.ToLower(strings.TrimSpace(req.MatchID))
+	req.URL = strings.TrimSpace(req.URL)
+	req.Caption = strings.TrimSpace(req.Caption)
+
+	if _, err := uuid.Parse(req.MatchID); err != nil {
+		return fmt.Errorf("%w: match_id must be a match UUID", ErrInvalidMedia)
+	}
+	if req.Kind != models.MediaScreenshot && req.Kind != models.MediaClip {
+		return fmt.Errorf("%w: kind must be screenshot or clip", ErrInvalidMedia)
+	}
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(req.URL) > 2048 {
+		return fmt.Errorf("%w: url must be an http or https URL", ErrInvalidMedia)
+	}
+	if utf8.RuneCountInString(req.Caption) > maxMediaCaption {
+		return fmt.Errorf("%w: caption is at most %d characters", ErrInvalidMedia, maxMediaCaption)
+	}
+	return nil
+}
+
+type matchMediaService struct {
+	pg PgPool
+	ch driver.Conn
+}
+
+func NewMatchMediaService(pg PgPool, ch driver.Conn) MatchMediaService {
+	return &matchMediaService{pg: pg, ch: ch}
+}
+
+const mediaColumns = `id, match_id, kind, url, caption, submitted_by, status, review_note, reviewed_by, reviewed_at, created_at`
+
+func scanMedia(row pgx.Row) (*models.MatchMedia, error) {
+	m := &models.MatchMedia{}
+	err := row.Scan(&m.ID, &m.MatchID, &m.Kind, &m.URL, &m.Caption, &m.SubmittedBy, &m.Status,
+		&m.ReviewNote, &m.ReviewedBy, &m.ReviewedAt, &m.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrMediaNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read match media: %w", err)
+	}
+	return m, nil
+}
+
+func (s *matchMediaService) list(ctx context.Context, query string, args ...any) ([]models.MatchMedia, error) {
+	rows, err := s.pg.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list match media: %w", err)
+	}
+	defer rows.Close()
+
+	media := []models.MatchMedia{}
+	for rows.Next() {
+		m, err := scanMedia(rows)
+		if err != nil {
+			return nil, err
+		}
+		media = append(media, *m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list match media: %w", err)
+	}
+	return media, nil
+}
+
+// Submit queues a user's attachment for moderation
+func (s *matchMediaService) Submit(ctx context.Context, forumUserID int, req *models.MatchMediaRequest) (*models.MatchMedia, error) {
+	if err := ValidateMatchMedia(req); err != nil {
+		return nil, err
+	}
+
+	var events uint64
+	if err := s.ch.QueryRow(ctx, `
+		SELECT count() FROM mohaa_stats.raw_events WHERE match_id = toUUID(?)
+	`, req.MatchID).Scan(&events); err != nil {
+		return nil, fmt.Errorf("failed to read match: %w", err)
+	}
+	if events == 0 {
+		return nil, ErrMatchNotFound
+	}
+
+	// The queue limit is checked by the insert itself, so two submissions at
+	// once cannot both slip past it
+	m, err := scanMedia(s.pg.QueryRow(ctx, `
+		INSERT INTO match_media (match_id, kind, url, caption, submitted_by)
+		SELECT $1, $2, $3, $4, $5
+		WHERE (SELECT count(*) FROM match_media WHERE submitted_by = $5 AND status = 'pending') < $6
+		ON CONFLICT (match_id, url) DO NOTHING
+		RETURNING `+mediaColumns,
+		req.MatchID, req.Kind, req.URL, req.Caption, forumUserID, maxPendingMedia))
+	if !errors.Is(err, ErrMediaNotFound) {
+		return m, err
+	}
+
+	// Nothing inserted: tell a duplicate from a full queue
+	var exists bool
+	if err := s.pg.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM match_media WHERE match_id = $1 AND url = $2)
+	`, req.MatchID, req.URL).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to read match media: %w", err)
+	}
+	if exists {
+		return nil, ErrMediaExists
+	}
+	return nil, ErrMediaQueueFull
+}
+
+// ForMatch returns the approved attachments of a match, oldest first
+func (s *matchMediaService) ForMatch(ctx context.Context, matchID string) ([]models.MatchMedia, error) {
+	return s.list(ctx, `
+		SELECT `+mediaColumns+` FROM match_media
+		WHERE match_id = $1 AND status = 'approved'
+		ORDER BY id
+	`, matchID)
+}
+
+// ForUser returns a user's attachments in any state, newest first
+func (s *matchMediaService) ForUser(ctx context.Context, forumUserID int) ([]models.MatchMedia, error) {
+	return s.list(ctx, `
+		SELECT `+mediaColumns+` FROM match_media
+		WHERE submitted_by = $1
+		ORDER BY id DESC
+		LIMIT 100
+	`, forumUserID)
+}
+
+// Queue returns the attachments in a state; pending ones oldest first so
+// the queue is worked in order, the others newest first
+func (s *matchMediaService) Queue(ctx context.Context, status models.MediaStatus, limit int) ([]models.MatchMedia, error) {
+	order := "DESC"
+	if status == models.MediaPending {
+		order = "ASC"
+	}
+	return s.list(ctx, `
+		SELECT `+mediaColumns+` FROM match_media
+		WHERE status = $1
+		ORDER BY id `+order+`
+		LIMIT $2
+	`, status, limit)
+}
+
+// Review approves or rejects an attachment. Approved media can be rejected
+// later to take them down, and rejected ones approved on a second look.
+func (s *matchMediaService) Review(ctx context.Context, id int64, approve bool, note, reviewer string) (*models.MatchMedia, error) {
+	status := models.MediaRejected
+	if approve {
+		status = models.MediaApproved
+	}
+	return scanMedia(s.pg.QueryRow(ctx, `
+		UPDATE match_media
+		SET status = $2, review_note = $3, reviewed_by = $4, reviewed_at = NOW()
+		WHERE id = $1
+		RETURNING `+mediaColumns,
+		id, status, strings.TrimSpace(note), reviewer))
+}
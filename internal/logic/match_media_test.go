@@ -0,0 +1,57 @@
+package logic
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestValidateMatchMedia(t *testing.T) {
+	valid := func() models.MatchMediaRequest {
+		return models.MatchMediaRequest{
+			MatchID: " 4F1C2A9E-7A1B-4C7E-9A55-0C8D7B3F2E11 ",
+			Kind:    models.MediaClip,
+			URL:     " https://clips.example.org/v/ace-on-the-bridge ",
+			Caption: " Ace on the bridge ",
+		}
+	}
+
+	req := valid()
+	if err := ValidateMatchMedia(&req); err != nil {
+		t.Fatalf("valid media rejected: %v", err)
+	}
+	if req.MatchID != "4f1c2a9e-7a1b-4c7e-9a55-0c8d7b3f2e11" || req.URL != "https://clips.example.org/v/ace-on-the-bridge" || req.Caption != "Ace on the bridge" {
+		t.Errorf("not normalized: %+v", req)
+	}
+
+	// Captions are counted in characters, not bytes
+	req = valid()
+	req.Caption = strings.Repeat("ü", maxMediaCaption)
+	if err := ValidateMatchMedia(&req); err != nil {
+		t.Errorf("caption of %d characters rejected: %v", maxMediaCaption, err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*models.MatchMediaRequest)
+	}{
+		{"Bad Match ID", func(r *models.MatchMediaRequest) { r.MatchID = "latest" }},
+		{"Unknown Kind", func(r *models.MatchMediaRequest) { r.Kind = "video" }},
+		{"No Kind", func(r *models.MatchMediaRequest) { r.Kind = "" }},
+		{"No URL", func(r *models.MatchMediaRequest) { r.URL = "" }},
+		{"Script URL", func(r *models.MatchMediaRequest) { r.URL = "javascript:alert(1)" }},
+		{"Relative URL", func(r *models.MatchMediaRequest) { r.URL = "/uploads/shot.png" }},
+		{"Long Caption", func(r *models.MatchMediaRequest) { r.Caption = strings.Repeat("a", maxMediaCaption+1) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid()
+			tt.modify(&req)
+			if err := ValidateMatchMedia(&req); !errors.Is(err, ErrInvalidMedia) {
+				t.Errorf("err = %v, want ErrInvalidMedia", err)
+			}
+		})
+	}
+}
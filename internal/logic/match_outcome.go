@@ -0,0 +1,110 @@
+package logic
+
+import (
+	"strings"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// Match outcome rules, shared by the worker at match_end and by Recompute,
+// which derives the same outcomes again from a match's stored events
+
+// NormalizeTeam maps the team names game scripts use to allies/axis; other
+// values (spectator, freeforall, "") are lowercased as they are
+func NormalizeTeam(team string) string {
+	team = strings.ToLower(strings.TrimSpace(team))
+	switch team {
+	case "allies", "allied", "american", "americans", "british", "russian", "russians":
+		return string(models.TeamAllies)
+	case "axis", "german", "germans", "italian":
+		return string(models.TeamAxis)
+	}
+	return team
+}
+
+// EventWinner is the winning team an event names, under either key
+func EventWinner(event *models.RawEvent) string {
+	if event.WinningTeam != "" {
+		return NormalizeTeam(event.WinningTeam)
+	}
+	return NormalizeTeam(event.Winner)
+}
+
+// EventScores are the team scores an event carries, under either allies key
+func EventScores(event *models.RawEvent) (allies, axis int) {
+	allies = event.AlliesScore
+	if allies == 0 {
+		allies = event.AlliedScore
+	}
+	return allies, event.AxisScore
+}
+
+// MatchWinner decides the winning team of a match, trusting in order the
+// match_end event, the team_win recorded during the match, and the higher
+// final team score. It returns "" for a draw or when nothing is known.
+func MatchWinner(endWinner, recordedWinner string, allies, axis int) string {
+	for _, team := range []string{endWinner, recordedWinner} {
+		if team == string(models.TeamAllies) || team == string(models.TeamAxis) {
+			return team
+		}
+	}
+	switch {
+	case allies > axis:
+		return string(models.TeamAllies)
+	case axis > allies:
+		return string(models.TeamAxis)
+	}
+	return ""
+}
+
+// TeamOutcomes gives every player on a team 1 (won) or 0 (did not win).
+// Spectators and players without a team finished nothing and get no outcome.
+func TeamOutcomes(teams map[string]string, winner string) map[string]uint8 {
+	outcomes := make(map[string]uint8, len(teams))
+	for guid, team := range teams {
+		team = NormalizeTeam(team)
+		if team != string(models.TeamAllies) && team != string(models.TeamAxis) {
+			continue
+		}
+		if winner != "" && team == winner {
+			outcomes[guid] = 1
+		} else {
+			outcomes[guid] = 0
+		}
+	}
+	return outcomes
+}
+
+// IsFFAGametype reports whether a gametype has no teams to win, only players
+func IsFFAGametype(gametype string) bool {
+	switch strings.ToLower(strings.TrimSpace(gametype)) {
+	case "dm", "ffa":
+		return true
+	}
+	return false
+}
+
+// FFAOutcomes gives the top scorer of a free-for-all scoreboard 1 and every
+// other player 0. Players sharing the top score drew and none of them won.
+// Spectators get no outcome.
+func FFAOutcomes(board map[string]models.RosterEntry) map[string]uint8 {
+	outcomes := make(map[string]uint8, len(board))
+	var leaders []string
+	top := 0
+	for guid, entry := range board {
+		if NormalizeTeam(entry.Team) == string(models.TeamSpectator) {
+			continue
+		}
+		outcomes[guid] = 0
+		switch {
+		case len(leaders) == 0 || entry.Score > top:
+			top, leaders = entry.Score, []string{guid}
+		case entry.Score == top:
+			leaders = append(leaders, guid)
+		}
+	}
+	if len(leaders) == 1 {
+		outcomes[leaders[0]] = 1
+	}
+	return outcomes
+}
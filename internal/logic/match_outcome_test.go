@@ -0,0 +1,61 @@
+package logic
+
+import "testing"
+
+func TestMatchWinner(t *testing.T) {
+	tests := []struct {
+		name               string
+		endWinner, winTeam string
+		allies, axis       int
+		want               string
+	}{
+		{"match_end names the winner", "axis", "allies", 10, 0, "axis"},
+		{"team_win when match_end does not", "", "allies", 0, 10, "allies"},
+		{"scores when nobody named a winner", "", "", 3, 7, "axis"},
+		{"draw", "", "", 4, 4, ""},
+		{"nothing known", "", "", 0, 0, ""},
+		{"non-team winner ignored", "draw", "", 5, 2, "allies"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchWinner(tt.endWinner, tt.winTeam, tt.allies, tt.axis); got != tt.want {
+				t.Errorf("MatchWinner() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTeamOutcomes(t *testing.T) {
+	teams := map[string]string{
+		"a": "american",
+		"b": "allies",
+		"c": "German",
+		"d": "spectator",
+		"e": "",
+	}
+	got := TeamOutcomes(teams, "allies")
+	want := map[string]uint8{"a": 1, "b": 1, "c": 0}
+	if len(got) != len(want) {
+		t.Fatalf("TeamOutcomes() = %v, want %v", got, want)
+	}
+	for guid, outcome := range want {
+		if got[guid] != outcome {
+			t.Errorf("outcome of %s = %d, want %d", guid, got[guid], outcome)
+		}
+	}
+
+	// A draw finishes the match for both teams without a win
+	for guid, outcome := range TeamOutcomes(teams, "") {
+		if outcome != 0 {
+			t.Errorf("draw outcome of %s = %d, want 0", guid, outcome)
+		}
+	}
+}
+
+func TestIsFFAGametype(t *testing.T) {
+	for gametype, want := range map[string]bool{"dm": true, "FFA": true, "tdm": false, "obj": false, "": false} {
+		if got := IsFFAGametype(gametype); got != want {
+			t.Errorf("IsFFAGametype(%q) = %v, want %v", gametype, got, want)
+		}
+	}
+}
@@ -35,6 +35,7 @@ type MatchDetail struct {
 	Timeline   []MatchTimelineEvent   `json:"timeline"`
 	Versus     map[string][]VersusRow `json:"versus"` // map[PlayerID] -> []VersusRow
 	TopWeapons []models.WeaponStats   `json:"top_weapons"`
+	Turrets    []models.TurretUsage   `json:"turrets,omitempty"` // Maps with mounted guns only
 }
 
 // GetMatchDetails fetches comprehensive match report
@@ -57,10 +58,18 @@ func (s *matchReportService) GetMatchDetails(ctx context.Context, matchID string
 		// Log error
 	}
 
+	// 4. Turret uptime and kills
+	turrets, err := s.getTurrets(ctx, matchID)
+	if err != nil {
+		// Report the match without turrets
+		turrets = nil
+	}
+
 	return &MatchDetail{
 		Info:     *info,
 		Timeline: timeline,
 		Versus:   versus,
+		Turrets:  turrets,
 	}, nil
 }
 
@@ -0,0 +1,241 @@
+package logic
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// ErrResultNotSigned is returned for a match without a signed result
+var ErrResultNotSigned = errors.New("match result not signed")
+
+type matchResultService struct {
+	ch    driver.Conn
+	pg    PgPool
+	key   ed25519.PrivateKey
+	keyID string
+}
+
+func NewMatchResultService(ch driver.Conn, pg PgPool, key ed25519.PrivateKey) MatchResultService {
+	return &matchResultService{ch: ch, pg: pg, key: key, keyID: resultKeyID(key.Public().(ed25519.PublicKey))}
+}
+
+// ParseResultSigningKey decodes RESULT_SIGNING_KEY: a base64 ed25519 seed
+// (32 bytes) or private key (64 bytes)
+func ParseResultSigningKey(s string) (ed25519.PrivateKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("result signing key is not base64: %w", err)
+	}
+	switch len(raw) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(raw), nil
+	case ed25519.PrivateKeySize:
+		key := ed25519.PrivateKey(raw)
+		if !bytes.Equal(key[ed25519.SeedSize:], ed25519.NewKeyFromSeed(key.Seed()).Public().(ed25519.PublicKey)) {
+			return nil, errors.New("result signing key does not match its public half")
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("result signing key is %d bytes, want a %d byte seed or %d byte private key",
+			len(raw), ed25519.SeedSize, ed25519.PrivateKeySize)
+	}
+}
+
+// resultKeyID names a public key by the start of its SHA-256, so a verifier
+// can tell which key signed a result after a key rotation
+func resultKeyID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
+
+// SigningKey returns the public key results are signed with
+func (s *matchResultService) SigningKey() models.ResultSigningKey {
+	return models.ResultSigningKey{
+		Algorithm: models.MatchResultAlgorithm,
+		KeyID:     s.keyID,
+		PublicKey: s.key.Public().(ed25519.PublicKey),
+	}
+}
+
+// Sign builds the result document of a finalized tournament match from its
+// events and correction, signs it and stores it in place of any earlier
+// signed result of the match.
+func (s *matchResultService) Sign(ctx context.Context, record *models.MatchStateRecord) (*models.SignedMatchResult, error) {
+	if !record.Locked() {
+		return nil, fmt.Errorf("%w: only finalized tournament matches are signed", ErrMatchState)
+	}
+	result, err := s.buildResult(ctx, record)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := canonicalJSON(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode match result: %w", err)
+	}
+	signature := ed25519.Sign(s.key, payload)
+
+	if _, err := s.pg.Exec(ctx, `
+		INSERT INTO tournament_match_results (match_id, tournament_id, payload, signature, key_id, public_key)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (match_id) DO UPDATE SET
+			payload = EXCLUDED.payload,
+			signature = EXCLUDED.signature,
+			key_id = EXCLUDED.key_id,
+			public_key = EXCLUDED.public_key,
+			signed_at = NOW()
+	`, record.MatchID, record.TournamentID, payload, signature, s.keyID, []byte(s.key.Public().(ed25519.PublicKey))); err != nil {
+		return nil, fmt.Errorf("failed to store match result: %w", err)
+	}
+	return s.Get(ctx, record.TournamentID, record.MatchID)
+}
+
+// Get returns the stored signed result of a tournament's match
+func (s *matchResultService) Get(ctx context.Context, tournamentID, matchID string) (*models.SignedMatchResult, error) {
+	r := &models.SignedMatchResult{Algorithm: models.MatchResultAlgorithm}
+	err := s.pg.QueryRow(ctx, `
+		SELECT payload, signature, key_id, public_key, signed_at
+		FROM tournament_match_results
+		WHERE match_id = $1 AND tournament_id = $2
+	`, matchID, tournamentID).Scan(&r.Payload, &r.Signature, &r.KeyID, &r.PublicKey, &r.SignedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrResultNotSigned
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read match result: %w", err)
+	}
+	r.Result = json.RawMessage(r.Payload)
+	return r, nil
+}
+
+// buildResult reads a match's score and scoreboard. The winner and each
+// player's team come from the match_outcome events of match_end, unless a
+// correction supersedes the score and winner.
+func (s *matchResultService) buildResult(ctx context.Context, record *models.MatchStateRecord) (*models.TournamentMatchResult, error) {
+	result := &models.TournamentMatchResult{
+		MatchID:      record.MatchID,
+		TournamentID: record.TournamentID,
+		FinalizedAt:  record.UpdatedAt.UTC().Truncate(time.Second),
+		FinalizedBy:  record.UpdatedBy,
+		Players:      []models.MatchResultPlayer{},
+	}
+	var events uint64
+	var alliesScore, axisScore int32
+	if err := s.ch.QueryRow(ctx, `
+		SELECT
+			count(),
+			any(server_id),
+			any(map_name),
+			anyIf(JSONExtractString(raw_json, 'gametype'), event_type = 'match_start'),
+			toInt32(maxIf(JSONExtractInt(raw_json, 'allies_score'), event_type IN ('match_end', 'heartbeat'))),
+			toInt32(maxIf(JSONExtractInt(raw_json, 'axis_score'), event_type IN ('match_end', 'heartbeat'))),
+			anyIf(actor_team, event_type = 'match_outcome' AND match_outcome = 1),
+			min(timestamp),
+			max(timestamp)
+		FROM mohaa_stats.raw_events
+		WHERE match_id = toUUID(?)
+	`, record.MatchID).Scan(&events, &result.ServerID, &result.MapName, &result.Gametype, &alliesScore, &axisScore,
+		&result.Winner, &result.StartedAt, &result.EndedAt); err != nil {
+		return nil, fmt.Errorf("failed to read match: %w", err)
+	}
+	if events == 0 {
+		return nil, ErrMatchNotFound
+	}
+	result.AlliesScore, result.AxisScore = int(alliesScore), int(axisScore)
+	result.StartedAt = result.StartedAt.UTC()
+	result.EndedAt = result.EndedAt.UTC()
+
+	rows, err := s.ch.Query(ctx, `
+		SELECT
+			player_id,
+			argMax(name, ts) AS name,
+			argMaxIf(team, ts, team != '') AS team,
+			toInt64(sum(kill)) AS kills,
+			toInt64(sum(death)) AS deaths,
+			max(won) AS won
+		FROM (
+			SELECT actor_id AS player_id, actor_name AS name, actor_team AS team, timestamp AS ts,
+				1 AS kill, 0 AS death, 0 AS won
+			FROM mohaa_stats.raw_events
+			WHERE match_id = toUUID(?) AND event_type IN ('player_kill', 'bot_killed')
+			UNION ALL
+			SELECT target_id, target_name, '', timestamp, 0, 1, 0
+			FROM mohaa_stats.raw_events
+			WHERE match_id = toUUID(?) AND event_type = 'player_kill'
+			UNION ALL
+			SELECT actor_id, actor_name, actor_team, timestamp, 0, 0, match_outcome
+			FROM mohaa_stats.raw_events
+			WHERE match_id = toUUID(?) AND event_type = 'match_outcome'
+		)
+		WHERE player_id != '' AND player_id != 'world'
+		GROUP BY player_id
+		ORDER BY kills DESC, deaths ASC, player_id
+	`, record.MatchID, record.MatchID, record.MatchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read match players: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p models.MatchResultPlayer
+		var k, d int64
+		var won uint8
+		if err := rows.Scan(&p.GUID, &p.Name, &p.Team, &k, &d, &won); err != nil {
+			return nil, fmt.Errorf("failed to read match players: %w", err)
+		}
+		p.Kills, p.Deaths, p.Won = int(k), int(d), won == 1
+		result.Players = append(result.Players, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read match players: %w", err)
+	}
+
+	correction, err := matchCorrection(ctx, s.pg, record.MatchID)
+	if err != nil || correction == nil {
+		return result, err
+	}
+	correction.CreatedAt = correction.CreatedAt.UTC().Truncate(time.Second)
+	result.Correction = correction
+	result.AlliesScore, result.AxisScore, result.Winner = correction.AlliesScore, correction.AxisScore, correction.Winner
+	for i := range result.Players {
+		p := &result.Players[i]
+		p.Won = correction.Winner != models.CorrectionWinnerDraw && p.Team == correction.Winner
+	}
+	return result, nil
+}
+
+// canonicalJSON encodes v with sorted keys, no insignificant whitespace and
+// no HTML escaping, so any verifier can reproduce the signed bytes from the
+// document
+func canonicalJSON(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	// Decoded into maps, which encoding/json writes with sorted keys
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var generic any
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(generic); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
@@ -0,0 +1,67 @@
+package logic
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	got, err := canonicalJSON(&models.TournamentMatchResult{
+		MatchID:     "m1",
+		Winner:      "allies",
+		AlliesScore: 3,
+		StartedAt:   time.Date(2026, 5, 1, 20, 0, 0, 0, time.UTC),
+		Players:     []models.MatchResultPlayer{{GUID: "g1", Name: "<Sarge>", Kills: 12, Won: true}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"allies_score":3,"axis_score":0,"ended_at":"0001-01-01T00:00:00Z","finalized_at":"0001-01-01T00:00:00Z",` +
+		`"finalized_by":"","gametype":"","map_name":"","match_id":"m1",` +
+		`"players":[{"deaths":0,"guid":"g1","kills":12,"name":"<Sarge>","team":"","won":true}],` +
+		`"server_id":"","started_at":"2026-05-01T20:00:00Z","tournament_id":"","winner":"allies"}`
+	if string(got) != want {
+		t.Errorf("canonical JSON\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestParseResultSigningKey(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	for i := range seed {
+		seed[i] = byte(i)
+	}
+	fromSeed, err := ParseResultSigningKey(base64.StdEncoding.EncodeToString(seed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromKey, err := ParseResultSigningKey(base64.StdEncoding.EncodeToString(fromSeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fromSeed.Equal(fromKey) {
+		t.Error("seed and private key forms parsed to different keys")
+	}
+
+	// A signature made with the parsed key verifies with the published one
+	s := NewMatchResultService(nil, nil, fromSeed).(*matchResultService)
+	payload := []byte(`{"match_id":"m1"}`)
+	pub := s.SigningKey()
+	if !ed25519.Verify(pub.PublicKey, payload, ed25519.Sign(s.key, payload)) {
+		t.Error("signature does not verify with the signing key")
+	}
+	if len(pub.KeyID) != 16 {
+		t.Errorf("key ID %q", pub.KeyID)
+	}
+
+	tampered := append([]byte(nil), fromSeed...)
+	tampered[40] ^= 1
+	for _, bad := range []string{"not base64!", base64.StdEncoding.EncodeToString(seed[:16]), base64.StdEncoding.EncodeToString(tampered)} {
+		if _, err := ParseResultSigningKey(bad); err == nil {
+			t.Errorf("accepted key %q", bad)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+var (
+	// ErrUnknownMatch is returned for a match without a lifecycle record
+	ErrUnknownMatch = errors.New("unknown match")
+	// ErrMatchState is returned when a match's state does not allow the change
+	ErrMatchState = errors.New("not allowed in the match's state")
+	// ErrMatchLocked is returned for finalized tournament matches
+	ErrMatchLocked = errors.New("match result is locked")
+	// ErrMatchNotOwned is returned when a server reports the result of a
+	// match another server played, or a sandbox server reports one
+	ErrMatchNotOwned = errors.New("match belongs to another server")
+)
+
+type matchStateService struct {
+	pg PgPool
+}
+
+func NewMatchStateService(pg PgPool) MatchStateService {
+	return &matchStateService{pg: pg}
+}
+
+const matchStateColumns = `match_id, server_id, tournament_id, state, reason, updated_by, started_at, ended_at, updated_at`
+
+func scanMatchState(row pgx.Row) (*models.MatchStateRecord, error) {
+	var r models.MatchStateRecord
+	err := row.Scan(&r.MatchID, &r.ServerID, &r.TournamentID, &r.State, &r.Reason, &r.UpdatedBy, &r.StartedAt, &r.EndedAt, &r.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrUnknownMatch
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read match state: %w", err)
+	}
+	return &r, nil
+}
+
+// Get returns the lifecycle record of a match
+func (s *matchStateService) Get(ctx context.Context, matchID string) (*models.MatchStateRecord, error) {
+	return scanMatchState(s.pg.QueryRow(ctx, `SELECT `+matchStateColumns+` FROM match_states WHERE match_id = $1`, matchID))
+}
+
+// States returns the state of each known match in ids
+func (s *matchStateService) States(ctx context.Context, ids []string) (map[string]models.MatchState, error) {
+	states := make(map[string]models.MatchState, len(ids))
+	if len(ids) == 0 {
+		return states, nil
+	}
+	rows, err := s.pg.Query(ctx, `SELECT match_id, state FROM match_states WHERE match_id = ANY($1)`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read match states: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		var state models.MatchState
+		if err := rows.Scan(&id, &state); err != nil {
+			return nil, fmt.Errorf("failed to read match states: %w", err)
+		}
+		states[id] = state
+	}
+	return states, rows.Err()
+}
+
+// ApplyEvents moves the matches of a batch of game events forward: any event
+// creates a pending match, match_start makes it live and match_end ends it.
+// Events never move a match backwards or out of finalized/voided.
+func (s *matchStateService) ApplyEvents(ctx context.Context, events []*models.RawEvent) error {
+	type change struct {
+		serverID string
+		state    models.MatchState
+		started  *time.Time
+		ended    *time.Time
+	}
+	rank := map[models.MatchState]int{models.MatchStatePending: 0, models.MatchStateLive: 1, models.MatchStateEnded: 2}
+
+	now := time.Now()
+	changes := make(map[string]*change)
+	var order []string
+	for _, event := range events {
+		if event.MatchID == "" {
+			continue
+		}
+		c, ok := changes[event.MatchID]
+		if !ok {
+			c = &change{serverID: event.ServerID, state: models.MatchStatePending}
+			changes[event.MatchID] = c
+			order = append(order, event.MatchID)
+		}
+		state := models.MatchStateForEvent(event.Type)
+		if rank[state] > rank[c.state] {
+			c.state = state
+		}
+		switch state {
+		case models.MatchStateLive:
+			c.started = &now
+		case models.MatchStateEnded:
+			c.ended = &now
+		}
+	}
+	if len(order) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(order))
+	servers := make([]string, len(order))
+	states := make([]string, len(order))
+	started := make([]*time.Time, len(order))
+	ended := make([]*time.Time, len(order))
+	for i, id := range order {
+		c := changes[id]
+		ids[i], servers[i], states[i], started[i], ended[i] = id, c.serverID, string(c.state), c.started, c.ended
+	}
+
+	_, err := s.pg.Exec(ctx, `
+		INSERT INTO match_states (match_id, server_id, state, started_at, ended_at)
+		SELECT * FROM unnest($1::text[], $2::text[], $3::text[], $4::timestamptz[], $5::timestamptz[])
+		ON CONFLICT (match_id) DO UPDATE SET
+			state = EXCLUDED.state,
+			started_at = COALESCE(match_states.started_at, EXCLUDED.started_at),
+			ended_at = COALESCE(EXCLUDED.ended_at, match_states.ended_at),
+			updated_at = NOW()
+		WHERE (match_states.state = 'pending' AND EXCLUDED.state IN ('live', 'ended'))
+		   OR (match_states.state = 'live' AND EXCLUDED.state = 'ended')
+	`, ids, servers, states, started, ended)
+	if err != nil {
+		return fmt.Errorf("failed to apply match states: %w", err)
+	}
+	return nil
+}
+
+// RecordResult ends a match from its reported result and attaches its
+// tournament. result.ServerID must be the reporting server's: a match another
+// server played is left alone with ErrMatchNotOwned, as are results from
+// sandbox servers. Returns ErrMatchLocked for finalized tournament matches.
+func (s *matchStateService) RecordResult(ctx context.Context, result *models.MatchResult) error {
+	if SandboxFromContext(ctx) || result.ServerID == "" {
+		return ErrMatchNotOwned
+	}
+	var state string
+	err := s.pg.QueryRow(ctx, `
+		INSERT INTO match_states (match_id, server_id, tournament_id, state, ended_at)
+		VALUES ($1, $2, $3, 'ended', NOW())
+		ON CONFLICT (match_id) DO UPDATE SET
+			server_id = EXCLUDED.server_id,
+			tournament_id = CASE WHEN EXCLUDED.tournament_id != '' THEN EXCLUDED.tournament_id ELSE match_states.tournament_id END,
+			state = CASE WHEN match_states.state IN ('pending', 'live') THEN 'ended' ELSE match_states.state END,
+			ended_at = COALESCE(match_states.ended_at, EXCLUDED.ended_at),
+			updated_at = NOW()
+		WHERE NOT (match_states.tournament_id != '' AND match_states.state = 'finalized')
+		  AND match_states.server_id IN ('', EXCLUDED.server_id)
+		RETURNING state
+	`, result.MatchID, result.ServerID, result.TournamentID).Scan(&state)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Tell a finalized match from another server's
+		var owner string
+		if err := s.pg.QueryRow(ctx, `SELECT server_id FROM match_states WHERE match_id = $1`, result.MatchID).Scan(&owner); err != nil {
+			return fmt.Errorf("failed to record match result: %w", err)
+		}
+		if owner != "" && owner != result.ServerID {
+			return ErrMatchNotOwned
+		}
+		return ErrMatchLocked
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record match result: %w", err)
+	}
+	return nil
+}
+
+// Adopt creates an ended record for a match played before match states were
+// tracked. It does nothing if the match already has one.
+func (s *matchStateService) Adopt(ctx context.Context, matchID, serverID string) error {
+	_, err := s.pg.Exec(ctx, `
+		INSERT INTO match_states (match_id, server_id, state)
+		VALUES ($1, $2, 'ended')
+		ON CONFLICT (match_id) DO NOTHING
+	`, matchID, serverID)
+	if err != nil {
+		return fmt.Errorf("failed to adopt match: %w", err)
+	}
+	return nil
+}
+
+// Transition moves a match to a new state on an admin's behalf and records it
+// in match_state_history
+func (s *matchStateService) Transition(ctx context.Context, matchID string, to models.MatchState, actor, reason string) (*models.MatchStateRecord, error) {
+	current, err := s.Get(ctx, matchID)
+	if err != nil {
+		return nil, err
+	}
+	if current.Locked() {
+		return nil, ErrMatchLocked
+	}
+	if !current.State.CanTransition(to) {
+		return nil, fmt.Errorf("%w: %s -> %s", ErrMatchState, current.State, to)
+	}
+
+	// Conditional on the state read above, so a concurrent change fails
+	// instead of being overwritten
+	updated, err := scanMatchState(s.pg.QueryRow(ctx, `
+		WITH updated AS (
+			UPDATE match_states SET
+				state = $3,
+				reason = $4,
+				updated_by = $5,
+				ended_at = CASE WHEN $3 = 'ended' THEN COALESCE(ended_at, NOW()) ELSE ended_at END,
+				updated_at = NOW()
+			WHERE match_id = $1 AND state = $2
+			RETURNING `+matchStateColumns+`
+		), history AS (
+			INSERT INTO match_state_history (match_id, from_state, to_state, reason, changed_by)
+			SELECT match_id, $2, $3, $4, $5 FROM updated
+		)
+		SELECT `+matchStateColumns+` FROM updated
+	`, matchID, string(current.State), string(to), reason, actor))
+	if errors.Is(err, ErrUnknownMatch) {
+		return nil, fmt.Errorf("%w: changed concurrently", ErrMatchState)
+	}
+	return updated, err
+}
+
+// CheckRecompute returns nil if the match's derived results may be rebuilt.
+// Unknown matches predate lifecycle tracking and are treated as ended.
+func (s *matchStateService) CheckRecompute(ctx context.Context, matchID string) error {
+	record, err := s.Get(ctx, matchID)
+	if errors.Is(err, ErrUnknownMatch) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if record.Locked() {
+		return ErrMatchLocked
+	}
+	if !record.State.AllowsRecompute() {
+		return fmt.Errorf("%w: match is %s", ErrMatchState, record.State)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+type execRecorder struct {
+	MockPgPool
+	calls [][]any
+}
+
+func (m *execRecorder) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	m.calls = append(m.calls, args)
+	return pgconn.CommandTag{}, nil
+}
+
+func TestApplyEventsCollapsesPerMatch(t *testing.T) {
+	pg := &execRecorder{}
+	svc := NewMatchStateService(pg)
+
+	err := svc.ApplyEvents(context.Background(), []*models.RawEvent{
+		{Type: models.EventConnect, MatchID: "a", ServerID: "s1"},
+		{Type: models.EventMatchStart, MatchID: "a", ServerID: "s1"},
+		{Type: models.EventPlayerKill, MatchID: "b", ServerID: "s2"},
+		{Type: models.EventMatchEnd, MatchID: "a", ServerID: "s1"},
+		{Type: models.EventHeartbeat, ServerID: "s3"}, // no match
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pg.calls) != 1 {
+		t.Fatalf("%d statements, want one per batch", len(pg.calls))
+	}
+
+	args := pg.calls[0]
+	ids, states := args[0].([]string), args[2].([]string)
+	started, ended := args[3].([]*time.Time), args[4].([]*time.Time)
+	if len(ids) != 2 || ids[0] != "a" || ids[1] != "b" {
+		t.Fatalf("ids = %v, want [a b]", ids)
+	}
+	if states[0] != "ended" || states[1] != "pending" {
+		t.Errorf("states = %v, want [ended pending]", states)
+	}
+	if started[0] == nil || ended[0] == nil || started[1] != nil || ended[1] != nil {
+		t.Errorf("start/end times set wrong: started=%v ended=%v", started, ended)
+	}
+
+	// Batches without match events do not touch Postgres
+	pg.calls = nil
+	svc.ApplyEvents(context.Background(), []*models.RawEvent{{Type: models.EventHeartbeat}})
+	if len(pg.calls) != 0 {
+		t.Error("empty batch ran a statement")
+	}
+}
+
+// matchOwner refuses the result upsert and answers the owner lookup
+type matchOwner struct {
+	MockPgPool
+	owner string
+}
+
+func (m *matchOwner) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return scanRow(func(dest ...any) error {
+		if len(args) > 1 {
+			return pgx.ErrNoRows
+		}
+		*dest[0].(*string) = m.owner
+		return nil
+	})
+}
+
+func TestRecordResultOwnership(t *testing.T) {
+	ctx := context.Background()
+	result := &models.MatchResult{MatchID: "m1", ServerID: "s1"}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		owner   string
+		wantErr error
+	}{
+		{"Other Server", ctx, "s2", ErrMatchNotOwned},
+		{"Finalized", ctx, "s1", ErrMatchLocked},
+		{"Sandbox", WithSandbox(ctx, true), "s1", ErrMatchNotOwned},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewMatchStateService(&matchOwner{owner: tt.owner})
+			if err := svc.RecordResult(tt.ctx, result); !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	svc := NewMatchStateService(&matchOwner{})
+	if err := svc.RecordResult(ctx, &models.MatchResult{MatchID: "m1"}); !errors.Is(err, ErrMatchNotOwned) {
+		t.Errorf("result without a server: err = %v, want ErrMatchNotOwned", err)
+	}
+}
@@ -0,0 +1,188 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// ErrInvalidNotification is returned for a notification or read request
+// that cannot be stored
+var ErrInvalidNotification = errors.New("invalid notification")
+
+const (
+	// maxNotificationTitle bounds a title, in characters
+	maxNotificationTitle = 200
+	// maxNotificationRead bounds the IDs marked read at once
+	maxNotificationRead = 100
+)
+
+// ValidateNotification checks a notification before it is stored and trims
+// its fields. It goes to ForumUserID when set, else to every forum user who
+// verified PlayerGUID.
+func ValidateNotification(n *models.Notification) error {
+	n.Title = strings.TrimSpace(n.Title)
+	n.Body = strings.TrimSpace(n.Body)
+	n.PlayerGUID = strings.TrimSpace(n.PlayerGUID)
+
+	switch n.Kind {
+	case models.NotifyAchievement, models.NotifyRecord, models.NotifyRivalry, models.NotifyChallenge:
+	default:
+		return fmt.Errorf("%w: unknown kind %q", ErrInvalidNotification, n.Kind)
+	}
+	if n.ForumUserID <= 0 && n.PlayerGUID == "" {
+		return fmt.Errorf("%w: no forum user or player to notify", ErrInvalidNotification)
+	}
+	if n.Title == "" {
+		return fmt.Errorf("%w: title is required", ErrInvalidNotification)
+	}
+	if utf8.RuneCountInString(n.Title) > maxNotificationTitle {
+		n.Title = string([]rune(n.Title)[:maxNotificationTitle])
+	}
+	return nil
+}
+
+// ValidateNotificationRead checks a mark-read request
+func ValidateNotificationRead(req *models.NotificationReadRequest) error {
+	if req.All {
+		return nil
+	}
+	if len(req.IDs) == 0 {
+		return fmt.Errorf("%w: give ids or all", ErrInvalidNotification)
+	}
+	if len(req.IDs) > maxNotificationRead {
+		return fmt.Errorf("%w: at most %d ids at once", ErrInvalidNotification, maxNotificationRead)
+	}
+	return nil
+}
+
+// pageNotifications cuts the limit+1 rows read for a page down to limit and
+// returns the cursor of the next page, zero when there is none
+func pageNotifications(rows []models.Notification, limit int) ([]models.Notification, int64) {
+	if limit <= 0 || len(rows) <= limit {
+		return rows, 0
+	}
+	rows = rows[:limit]
+	return rows, rows[limit-1].ID
+}
+
+type notificationService struct {
+	pg PgPool
+}
+
+func NewNotificationService(pg PgPool) NotificationService {
+	return &notificationService{pg: pg}
+}
+
+const notificationColumns = `id, forum_user_id, player_guid, kind, title, body, subject, value, match_id, read_at, created_at`
+
+func scanNotification(row pgx.Row) (*models.Notification, error) {
+	n := &models.Notification{}
+	err := row.Scan(&n.ID, &n.ForumUserID, &n.PlayerGUID, &n.Kind, &n.Title, &n.Body, &n.Subject,
+		&n.Value, &n.MatchID, &n.ReadAt, &n.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notification: %w", err)
+	}
+	return n, nil
+}
+
+// Notify stores a notification in the inbox of its forum user, or of every
+// forum user who verified its player, and returns how many inboxes got it.
+// Players nobody has linked notify no one.
+func (s *notificationService) Notify(ctx context.Context, n *models.Notification) (int, error) {
+	if err := ValidateNotification(n); err != nil {
+		return 0, err
+	}
+	tag, err := s.pg.Exec(ctx, `
+		INSERT INTO notifications (forum_user_id, player_guid, kind, title, body, subject, value, match_id)
+		SELECT u.forum_user_id, $2, $3, $4, $5, $6, $7, $8
+		FROM (
+			SELECT $1::int AS forum_user_id WHERE $1::int > 0
+			UNION
+			SELECT forum_user_id FROM player_identities
+			WHERE $1::int <= 0 AND player_guid = $2 AND verified
+		) u
+	`, n.ForumUserID, n.PlayerGUID, n.Kind, n.Title, n.Body, n.Subject, n.Value, n.MatchID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to store notification: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// List returns a page of a user's inbox, newest first, starting below the
+// ID before (zero for the first page)
+func (s *notificationService) List(ctx context.Context, forumUserID int, unreadOnly bool, before int64, limit int) (*models.NotificationList, error) {
+	rows, err := s.pg.Query(ctx, `
+		SELECT `+notificationColumns+` FROM notifications
+		WHERE forum_user_id = $1
+		  AND ($2 = 0 OR id < $2)
+		  AND (NOT $3 OR read_at IS NULL)
+		ORDER BY id DESC
+		LIMIT $4
+	`, forumUserID, before, unreadOnly, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	defer rows.Close()
+
+	list := &models.NotificationList{Notifications: []models.Notification{}}
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, err
+		}
+		list.Notifications = append(list.Notifications, *n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	list.Notifications, list.Cursor = pageNotifications(list.Notifications, limit)
+
+	if list.Unread, err = s.Unread(ctx, forumUserID); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Unread counts a user's unread notifications
+func (s *notificationService) Unread(ctx context.Context, forumUserID int) (int, error) {
+	var unread int
+	if err := s.pg.QueryRow(ctx, `
+		SELECT count(*) FROM notifications WHERE forum_user_id = $1 AND read_at IS NULL
+	`, forumUserID).Scan(&unread); err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return unread, nil
+}
+
+// MarkRead marks notifications of a user read and returns how many were
+// unread. IDs of other users' notifications are ignored.
+func (s *notificationService) MarkRead(ctx context.Context, forumUserID int, req *models.NotificationReadRequest) (int, error) {
+	if err := ValidateNotificationRead(req); err != nil {
+		return 0, err
+	}
+	tag, err := s.pg.Exec(ctx, `
+		UPDATE notifications SET read_at = NOW()
+		WHERE forum_user_id = $1 AND read_at IS NULL AND ($2 OR id = ANY($3))
+	`, forumUserID, req.All, req.IDs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// Prune drops notifications created before `before`, read or not
+func (s *notificationService) Prune(ctx context.Context, before time.Time) (int, error) {
+	tag, err := s.pg.Exec(ctx, `DELETE FROM notifications WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune notifications: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,71 @@
+package logic
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestValidateNotification(t *testing.T) {
+	tests := []struct {
+		name  string
+		n     models.Notification
+		valid bool
+	}{
+		{"To User", models.Notification{ForumUserID: 7, Kind: models.NotifyAchievement, Title: "Sharpshooter"}, true},
+		{"To Player", models.Notification{PlayerGUID: " guid-1 ", Kind: models.NotifyRecord, Title: "Server record"}, true},
+		{"Nobody", models.Notification{Kind: models.NotifyChallenge, Title: "Done"}, false},
+		{"Unknown Kind", models.Notification{ForumUserID: 7, Kind: "mention", Title: "Hi"}, false},
+		{"No Title", models.Notification{ForumUserID: 7, Kind: models.NotifyRivalry, Title: "  "}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := tt.n
+			err := ValidateNotification(&n)
+			if tt.valid && err != nil {
+				t.Errorf("rejected: %v", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidNotification) {
+				t.Errorf("err = %v, want ErrInvalidNotification", err)
+			}
+		})
+	}
+
+	n := models.Notification{ForumUserID: 7, Kind: models.NotifyRivalry, Title: strings.Repeat("é", 250)}
+	if err := ValidateNotification(&n); err != nil {
+		t.Fatal(err)
+	}
+	if got := len([]rune(n.Title)); got != maxNotificationTitle {
+		t.Errorf("title cut to %d characters, want %d", got, maxNotificationTitle)
+	}
+}
+
+func TestValidateNotificationRead(t *testing.T) {
+	if err := ValidateNotificationRead(&models.NotificationReadRequest{All: true}); err != nil {
+		t.Errorf("all rejected: %v", err)
+	}
+	if err := ValidateNotificationRead(&models.NotificationReadRequest{IDs: []int64{1, 2}}); err != nil {
+		t.Errorf("ids rejected: %v", err)
+	}
+	if err := ValidateNotificationRead(&models.NotificationReadRequest{}); !errors.Is(err, ErrInvalidNotification) {
+		t.Errorf("empty request: err = %v", err)
+	}
+	if err := ValidateNotificationRead(&models.NotificationReadRequest{IDs: make([]int64, 101)}); !errors.Is(err, ErrInvalidNotification) {
+		t.Errorf("101 ids: err = %v", err)
+	}
+}
+
+func TestPageNotifications(t *testing.T) {
+	rows := []models.Notification{{ID: 9}, {ID: 7}, {ID: 4}}
+
+	page, cursor := pageNotifications(rows, 2)
+	if len(page) != 2 || cursor != 7 {
+		t.Errorf("page of 2 = %d rows, cursor %d; want 2 rows, cursor 7", len(page), cursor)
+	}
+	page, cursor = pageNotifications(rows, 3)
+	if len(page) != 3 || cursor != 0 {
+		t.Errorf("last page = %d rows, cursor %d; want 3 rows, cursor 0", len(page), cursor)
+	}
+}
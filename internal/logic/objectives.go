@@ -0,0 +1,157 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// Bomb objective statuses sent with objective_update, lowercased
+var (
+	plantStatuses    = map[string]bool{"planted": true, "plant": true, "armed": true}
+	detonateStatuses = map[string]bool{"exploded": true, "detonated": true, "destroyed": true}
+	defuseStatuses   = map[string]bool{"defused": true, "defuse": true, "disarmed": true}
+)
+
+// objectiveEvent is a match_start, round_start or objective event of a map.
+// objective is the objective name the worker stores in actor_weapon.
+type objectiveEvent struct {
+	matchID   string
+	eventType string
+	objective string
+	status    string
+	team      string
+	ts        time.Time
+}
+
+// GetMapObjectives reports objective timing on a map over the last `days`
+// days: how long rounds take to their first objective, how often bomb plants
+// detonate or are defused, and how often each objective is captured
+func (s *serverStatsService) GetMapObjectives(ctx context.Context, mapName string, days int) (*models.MapObjectiveStats, error) {
+	tenantID := TenantFromContext(ctx)
+	since := time.Now().UTC().AddDate(0, 0, -days)
+	rows, err := s.ch.Query(ctx, `
+		SELECT
+			toString(match_id),
+			event_type,
+			actor_weapon,
+			lower(JSONExtractString(raw_json, 'objective_status')),
+			actor_team,
+			timestamp
+		FROM mohaa_stats.raw_events
+		WHERE map_name = ? AND timestamp >= ?
+		  AND event_type IN ('match_start', 'round_start', 'objective_update', 'objective_capture')
+		  AND (? = '' OR tenant_id = ?)`+RoundPhaseFilter(ctx, "")+`
+		ORDER BY match_id, timestamp
+	`, mapName, since, tenantID, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query objective events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []objectiveEvent
+	for rows.Next() {
+		var e objectiveEvent
+		if err := rows.Scan(&e.matchID, &e.eventType, &e.objective, &e.status, &e.team, &e.ts); err != nil {
+			return nil, fmt.Errorf("failed to scan objective events: %w", err)
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan objective events: %w", err)
+	}
+
+	stats := objectiveStats(events)
+	stats.MapName = mapName
+	stats.Days = days
+	return stats, nil
+}
+
+// objectiveStats summarises a map's objective events, ordered by match and
+// timestamp. A round starts at match_start or round_start; its first
+// objective is the first capture, plant or detonation after that.
+func objectiveStats(events []objectiveEvent) *models.MapObjectiveStats {
+	stats := &models.MapObjectiveStats{Objectives: []models.ObjectiveCaptures{}}
+	captures := make(map[string]*models.ObjectiveCaptures)
+
+	var match string
+	var roundStart time.Time
+	var inRound, reached bool
+	var totalTime float64
+	for _, e := range events {
+		if e.matchID != match {
+			match = e.matchID
+			inRound = false
+			stats.Matches++
+		}
+
+		switch e.eventType {
+		case "match_start", "round_start":
+			// A round_start right after match_start opens the same round
+			if inRound && !reached && e.ts.Sub(roundStart) < time.Second {
+				continue
+			}
+			roundStart, inRound, reached = e.ts, true, false
+			stats.Rounds++
+			continue
+		case "objective_capture":
+			if e.objective != "" {
+				c, ok := captures[e.objective]
+				if !ok {
+					c = &models.ObjectiveCaptures{Objective: e.objective}
+					captures[e.objective] = c
+				}
+				c.Captures++
+				switch e.team {
+				case "allies":
+					c.AlliesCaptures++
+				case "axis":
+					c.AxisCaptures++
+				}
+			}
+		default:
+			switch {
+			case plantStatuses[e.status]:
+				stats.Plants++
+			case detonateStatuses[e.status]:
+				stats.Detonations++
+			case defuseStatuses[e.status]:
+				stats.Defuses++
+				continue
+			default:
+				continue
+			}
+		}
+
+		if inRound && !reached {
+			reached = true
+			stats.RoundsWithObjective++
+			totalTime += e.ts.Sub(roundStart).Seconds()
+		}
+	}
+
+	if stats.RoundsWithObjective > 0 {
+		stats.AvgTimeToFirstObjective = totalTime / float64(stats.RoundsWithObjective)
+	}
+	if stats.Plants > 0 {
+		stats.PlantSuccessRate = float64(stats.Detonations) / float64(stats.Plants) * 100
+		stats.DefuseRate = float64(stats.Defuses) / float64(stats.Plants) * 100
+	}
+
+	for _, c := range captures {
+		if stats.Matches > 0 {
+			c.CapturesPerMatch = float64(c.Captures) / float64(stats.Matches)
+		}
+		stats.Objectives = append(stats.Objectives, *c)
+	}
+	sort.Slice(stats.Objectives, func(i, j int) bool {
+		if stats.Objectives[i].Captures != stats.Objectives[j].Captures {
+			return stats.Objectives[i].Captures > stats.Objectives[j].Captures
+		}
+		return stats.Objectives[i].Objective < stats.Objectives[j].Objective
+	})
+	return stats
+}
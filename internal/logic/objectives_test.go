@@ -0,0 +1,48 @@
+package logic
+
+import (
+	"testing"
+	"time"
+)
+
+func TestObjectiveStats(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(s int) time.Time { return start.Add(time.Duration(s) * time.Second) }
+
+	events := []objectiveEvent{
+		{matchID: "m1", eventType: "match_start", ts: at(0)},
+		{matchID: "m1", eventType: "round_start", ts: at(0)},
+		{matchID: "m1", eventType: "objective_update", status: "planted", ts: at(60)},
+		{matchID: "m1", eventType: "objective_update", status: "exploded", ts: at(100)},
+		{matchID: "m1", eventType: "objective_capture", objective: "radar", team: "allies", ts: at(100)},
+		{matchID: "m1", eventType: "round_start", ts: at(200)},
+		{matchID: "m1", eventType: "objective_update", status: "planted", ts: at(320)},
+		{matchID: "m1", eventType: "objective_update", status: "defused", ts: at(340)},
+		// Progress updates are not objectives
+		{matchID: "m2", eventType: "round_start", ts: at(1000)},
+		{matchID: "m2", eventType: "objective_update", status: "progress", ts: at(1010)},
+		{matchID: "m2", eventType: "objective_capture", objective: "radar", team: "axis", ts: at(1030)},
+		{matchID: "m2", eventType: "objective_capture", objective: "bridge", team: "axis", ts: at(1050)},
+	}
+
+	stats := objectiveStats(events)
+	if stats.Matches != 2 || stats.Rounds != 3 || stats.RoundsWithObjective != 3 {
+		t.Fatalf("matches/rounds = %d/%d/%d, want 2/3/3", stats.Matches, stats.Rounds, stats.RoundsWithObjective)
+	}
+	if stats.AvgTimeToFirstObjective != 70 {
+		t.Errorf("avg time to first objective = %v, want 70", stats.AvgTimeToFirstObjective)
+	}
+	if stats.Plants != 2 || stats.Detonations != 1 || stats.Defuses != 1 || stats.PlantSuccessRate != 50 || stats.DefuseRate != 50 {
+		t.Errorf("bomb stats = %+v", stats)
+	}
+	if len(stats.Objectives) != 2 {
+		t.Fatalf("objectives = %+v, want radar and bridge", stats.Objectives)
+	}
+	if radar := stats.Objectives[0]; radar.Objective != "radar" || radar.Captures != 2 || radar.CapturesPerMatch != 1 || radar.AlliesCaptures != 1 || radar.AxisCaptures != 1 {
+		t.Errorf("radar = %+v", radar)
+	}
+
+	if empty := objectiveStats(nil); empty.Rounds != 0 || empty.Objectives == nil {
+		t.Errorf("no events = %+v", empty)
+	}
+}
@@ -0,0 +1,153 @@
+package logic
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidPeriod is returned for a period, tz, from or to that cannot be read
+var ErrInvalidPeriod = errors.New("invalid period")
+
+// Period is the span [From, To) a request asked for, with the time zone its
+// days and weeks are counted in. A zero From or To leaves that end open.
+type Period struct {
+	From     time.Time
+	To       time.Time
+	Location *time.Location
+}
+
+// rollingPeriods are the windows ending now, regardless of calendar
+var rollingPeriods = map[string]int{"7d": 7, "30d": 30, "365d": 365}
+
+// ParsePeriod reads the period query parameters shared by the leaderboard,
+// activity and digest endpoints:
+//
+//   - period: all (or ""), day, week (Monday to Sunday), month or year for the
+//     calendar period containing now, or 7d, 30d or 365d for a window ending now
+//   - tz: the IANA zone (e.g. "Europe/Berlin") calendar periods and dates are
+//     counted in; UTC when empty
+//   - from, to: RFC 3339 times or YYYY-MM-DD dates in tz, replacing period.
+//     A date as to includes that whole day.
+func ParsePeriod(period, tz, from, to string, now time.Time) (Period, error) {
+	p := Period{Location: time.UTC}
+	if tz = strings.TrimSpace(tz); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil || tz == "Local" {
+			return p, fmt.Errorf("%w: unknown time zone %q", ErrInvalidPeriod, tz)
+		}
+		p.Location = loc
+	}
+
+	if from != "" || to != "" {
+		var err error
+		if p.From, err = parsePeriodTime(from, p.Location, false); err != nil {
+			return p, fmt.Errorf("%w: from: %v", ErrInvalidPeriod, err)
+		}
+		if p.To, err = parsePeriodTime(to, p.Location, true); err != nil {
+			return p, fmt.Errorf("%w: to: %v", ErrInvalidPeriod, err)
+		}
+		if !p.From.IsZero() && !p.To.IsZero() && !p.From.Before(p.To) {
+			return p, fmt.Errorf("%w: from must be before to", ErrInvalidPeriod)
+		}
+		return p, nil
+	}
+
+	now = now.In(p.Location)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, p.Location)
+	switch strings.ToLower(strings.TrimSpace(period)) {
+	case "", "all":
+	case "day":
+		p.From = today
+	case "week":
+		p.From = today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+	case "month":
+		p.From = today.AddDate(0, 0, 1-today.Day())
+	case "year":
+		p.From = time.Date(now.Year(), 1, 1, 0, 0, 0, 0, p.Location)
+	default:
+		days, ok := rollingPeriods[period]
+		if !ok {
+			return p, fmt.Errorf("%w: unknown period %q (want all, day, week, month, year, 7d, 30d or 365d)", ErrInvalidPeriod, period)
+		}
+		p.From = now.AddDate(0, 0, -days)
+	}
+	return p, nil
+}
+
+// parsePeriodTime reads an RFC 3339 time or a date at midnight in loc (the
+// next midnight when end is set); empty is the zero time
+func parsePeriodTime(s string, loc *time.Location, end bool) (time.Time, error) {
+	if s = strings.TrimSpace(s); s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, loc); err == nil {
+		if end {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// IsAll reports whether the period is open at both ends
+func (p Period) IsAll() bool {
+	return p.From.IsZero() && p.To.IsZero()
+}
+
+// TimeZone returns the zone name for ClickHouse's time zone arguments
+func (p Period) TimeZone() string {
+	if p.Location == nil {
+		return "UTC"
+	}
+	return p.Location.String()
+}
+
+// TimeFilter returns the WHERE fragment (with a leading AND, "" for all time)
+// restricting a DateTime column to the period, and its arguments
+func (p Period) TimeFilter(column string) (string, []any) {
+	var where string
+	var args []any
+	if !p.From.IsZero() {
+		where += fmt.Sprintf(" AND %s >= ?", column)
+		args = append(args, p.From.UTC())
+	}
+	if !p.To.IsZero() {
+		where += fmt.Sprintf(" AND %s < ?", column)
+		args = append(args, p.To.UTC())
+	}
+	return where, args
+}
+
+// DayFilter is TimeFilter for the Date column of a daily aggregate table.
+// Those days are UTC days, so the period is widened to whole days: the
+// local dates it covers are matched against them.
+func (p Period) DayFilter(column string) (string, []any) {
+	var where string
+	var args []any
+	if !p.From.IsZero() {
+		where += fmt.Sprintf(" AND %s >= toDate(?)", column)
+		args = append(args, p.localDate(p.From, false))
+	}
+	if !p.To.IsZero() {
+		where += fmt.Sprintf(" AND %s < toDate(?)", column)
+		args = append(args, p.localDate(p.To, true))
+	}
+	return where, args
+}
+
+// localDate returns the date of t in the period's zone, rounded up to the
+// next date when ceil is set and t is not midnight
+func (p Period) localDate(t time.Time, ceil bool) string {
+	loc := p.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	t = t.In(loc)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	if ceil && t.After(day) {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day.Format("2006-01-02")
+}
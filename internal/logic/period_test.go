@@ -0,0 +1,82 @@
+package logic
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParsePeriod(t *testing.T) {
+	// Monday 00:30 in Berlin, still Sunday in UTC
+	now := time.Date(2026, 10, 11, 22, 30, 0, 0, time.UTC)
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no time zone database:", err)
+	}
+
+	tests := []struct {
+		name                 string
+		period, tz, from, to string
+		wantFrom, wantTo     time.Time
+	}{
+		{"all", "", "", "", "", time.Time{}, time.Time{}},
+		{"utc week", "week", "", "", "", time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC), time.Time{}},
+		{"local week", "week", "Europe/Berlin", "", "", time.Date(2026, 10, 12, 0, 0, 0, 0, berlin), time.Time{}},
+		{"local day", "day", "Europe/Berlin", "", "", time.Date(2026, 10, 12, 0, 0, 0, 0, berlin), time.Time{}},
+		{"utc month", "month", "", "", "", time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), time.Time{}},
+		{"year", "year", "", "", "", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.Time{}},
+		{"rolling", "7d", "Europe/Berlin", "", "", now.AddDate(0, 0, -7), time.Time{}},
+		{"dates", "week", "Europe/Berlin", "2026-10-01", "2026-10-03",
+			time.Date(2026, 10, 1, 0, 0, 0, 0, berlin), time.Date(2026, 10, 4, 0, 0, 0, 0, berlin)},
+		{"times", "", "", "2026-10-01T12:00:00Z", "", time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC), time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := ParsePeriod(tt.period, tt.tz, tt.from, tt.to, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !p.From.Equal(tt.wantFrom) || !p.To.Equal(tt.wantTo) {
+				t.Errorf("range = %v - %v, want %v - %v", p.From, p.To, tt.wantFrom, tt.wantTo)
+			}
+		})
+	}
+
+	for _, bad := range [][4]string{
+		{"fortnight", "", "", ""},
+		{"week", "Mars/Olympus", "", ""},
+		{"week", "Local", "", ""},
+		{"", "", "yesterday", ""},
+		{"", "", "2026-10-05", "2026-10-01"},
+	} {
+		if _, err := ParsePeriod(bad[0], bad[1], bad[2], bad[3], now); !errors.Is(err, ErrInvalidPeriod) {
+			t.Errorf("ParsePeriod(%q) error = %v, want ErrInvalidPeriod", bad, err)
+		}
+	}
+}
+
+func TestPeriodFilters(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no time zone database:", err)
+	}
+	p := Period{
+		From:     time.Date(2026, 10, 12, 0, 0, 0, 0, berlin),
+		To:       time.Date(2026, 10, 14, 6, 0, 0, 0, berlin),
+		Location: berlin,
+	}
+
+	where, args := p.DayFilter("day")
+	if where != " AND day >= toDate(?) AND day < toDate(?)" || !reflect.DeepEqual(args, []any{"2026-10-12", "2026-10-15"}) {
+		t.Errorf("DayFilter = %q %v", where, args)
+	}
+	where, args = p.TimeFilter("timestamp")
+	if where != " AND timestamp >= ? AND timestamp < ?" ||
+		!reflect.DeepEqual(args, []any{time.Date(2026, 10, 11, 22, 0, 0, 0, time.UTC), time.Date(2026, 10, 14, 4, 0, 0, 0, time.UTC)}) {
+		t.Errorf("TimeFilter = %q %v", where, args)
+	}
+	if where, args := (Period{}).DayFilter("day"); where != "" || args != nil {
+		t.Errorf("all time DayFilter = %q %v", where, args)
+	}
+}
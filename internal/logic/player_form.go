@@ -0,0 +1,107 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openmohaa/stats-api/internal/models"
+	"github.com/openmohaa/stats-api/internal/statmath"
+)
+
+// formMatches is how many of the player's latest matches make up their form
+const formMatches = 10
+
+// formMinEngagements is the kills plus deaths each side of the form
+// comparison needs before a difference can be called significant; below it
+// the normal approximation of the z-test does not hold
+const formMinEngagements = 30
+
+// fillFormStats compares the player's last formMatches matches with the
+// matches before them
+func (s *playerStatsService) fillFormStats(ctx context.Context, guid string, out *models.FormStats) error {
+	query := `
+		SELECT
+			toUInt64(countIf(rn <= ?)) AS recent_matches,
+			sumIf(kills, rn <= ?) AS recent_kills,
+			sumIf(deaths, rn <= ?) AS recent_deaths,
+			sum(kills) AS total_kills,
+			sum(deaths) AS total_deaths
+		FROM (
+			SELECT
+				kills,
+				deaths,
+				row_number() OVER (ORDER BY last_seen DESC) AS rn
+			FROM (
+				SELECT
+					countIf(event_type IN ('player_kill', 'bot_killed') AND actor_id = ?) AS kills,
+					countIf(event_type IN ('player_kill', 'bot_killed') AND target_id = ?) AS deaths,
+					max(timestamp) AS last_seen
+				FROM mohaa_stats.raw_events
+				WHERE (actor_id = ? OR target_id = ?) AND match_id != ''` + RoundPhaseFilter(ctx, "") + `
+				GROUP BY match_id
+			)
+		)`
+	var matches, recentKills, recentDeaths, totalKills, totalDeaths uint64
+	if err := s.ch.QueryRow(ctx, query,
+		formMatches, formMatches, formMatches,
+		guid, guid, guid, guid,
+	).Scan(&matches, &recentKills, &recentDeaths, &totalKills, &totalDeaths); err != nil {
+		return fmt.Errorf("failed to query form: %w", err)
+	}
+	*out = formStats(int(matches), recentKills, recentDeaths, totalKills-recentKills, totalDeaths-recentDeaths)
+	return nil
+}
+
+// formStats tests the recent kill share against the baseline's
+func formStats(matches int, recentKills, recentDeaths, baseKills, baseDeaths uint64) models.FormStats {
+	form := models.FormStats{
+		Matches:      matches,
+		RecentKills:  recentKills,
+		RecentDeaths: recentDeaths,
+		RecentKD:     kdRatio(recentKills, recentDeaths),
+		BaselineKD:   kdRatio(baseKills, baseDeaths),
+		State:        "steady",
+		PValue:       1,
+	}
+	recent, base := recentKills+recentDeaths, baseKills+baseDeaths
+	if recent == 0 || base == 0 {
+		return form
+	}
+
+	form.ZScore = statmath.TwoProportionZ(recentKills, recent, baseKills, base)
+	form.PValue = statmath.TwoSidedP(form.ZScore)
+	switch {
+	case form.ZScore > 0:
+		form.State = "hot"
+	case form.ZScore < 0:
+		form.State = "cold"
+	}
+	form.Significant = form.PValue < 0.05 && recent >= formMinEngagements && base >= formMinEngagements
+	return form
+}
+
+func kdRatio(kills, deaths uint64) float64 {
+	if deaths == 0 {
+		return float64(kills)
+	}
+	return float64(kills) / float64(deaths)
+}
+
+// rateCI is the 95% Wilson interval of successes out of trials in percent,
+// or nil without trials
+func rateCI(successes, trials uint64) *models.ConfidenceInterval {
+	if trials == 0 {
+		return nil
+	}
+	i := statmath.Wilson(successes, trials, statmath.Z95).Scale(100)
+	return &models.ConfidenceInterval{Low: i.Low, High: i.High}
+}
+
+// kdCI is the 95% interval of a K/D, or nil without deaths
+func kdCI(kills, deaths uint64) *models.ConfidenceInterval {
+	i, ok := statmath.RatioInterval(kills, deaths, statmath.Z95)
+	if !ok {
+		return nil
+	}
+	return &models.ConfidenceInterval{Low: i.Low, High: i.High}
+}
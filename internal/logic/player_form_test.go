@@ -0,0 +1,37 @@
+package logic
+
+import "testing"
+
+func TestFormStats(t *testing.T) {
+	tests := []struct {
+		name             string
+		recentK, recentD uint64
+		baseK, baseD     uint64
+		state            string
+		significant      bool
+	}{
+		{name: "Hot Streak", recentK: 60, recentD: 20, baseK: 200, baseD: 200, state: "hot", significant: true},
+		{name: "Cold Streak", recentK: 20, recentD: 60, baseK: 200, baseD: 200, state: "cold", significant: true},
+		{name: "Noise", recentK: 22, recentD: 18, baseK: 200, baseD: 200, state: "hot"},
+		// 9 kills to 1 death would be p < 0.05, but 10 engagements are too few
+		{name: "Small Sample", recentK: 9, recentD: 1, baseK: 200, baseD: 200, state: "hot"},
+		{name: "No Baseline", recentK: 30, recentD: 10, state: "steady"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := formStats(10, tt.recentK, tt.recentD, tt.baseK, tt.baseD)
+			if form.State != tt.state || form.Significant != tt.significant {
+				t.Errorf("state = %s, significant = %v (z %.2f, p %.4f); want %s, %v",
+					form.State, form.Significant, form.ZScore, form.PValue, tt.state, tt.significant)
+			}
+		})
+	}
+
+	form := formStats(10, 60, 20, 200, 200)
+	if form.RecentKD != 3 || form.BaselineKD != 1 {
+		t.Errorf("K/D = %v recent, %v baseline; want 3, 1", form.RecentKD, form.BaselineKD)
+	}
+	if form.PValue >= 0.05 {
+		t.Errorf("p = %v, want < 0.05", form.PValue)
+	}
+}
@@ -0,0 +1,491 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// sectionCacheTTL is how long a computed section of a player's stats is reused
+const sectionCacheTTL = 30 * time.Second
+
+// ErrUnknownSection is returned for a section name ParseStatSections does not know
+var ErrUnknownSection = errors.New("unknown stats section")
+
+// PlayerStatsSections holds the sections of a player's stats that were asked
+// for; the others are left zero
+type PlayerStatsSections struct {
+	models.DeepStats
+	Maps          []models.PlayerMapStats
+	Performance   []models.PerformancePoint
+	RecentMatches []models.RecentMatch
+}
+
+// statSection computes one section into out and copies it between results.
+// Sections that are not critical swallow their errors and come back empty.
+type statSection struct {
+	fill  func(s *playerStatsService, ctx context.Context, guid string, out *PlayerStatsSections) error
+	merge func(dst, src *PlayerStatsSections)
+}
+
+var combatSection = statSection{
+	fill: func(s *playerStatsService, ctx context.Context, guid string, out *PlayerStatsSections) error {
+		if err := s.fillCombatStats(ctx, guid, &out.Combat); err != nil {
+			return fmt.Errorf("combat stats: %w", err)
+		}
+		return nil
+	},
+	merge: func(dst, src *PlayerStatsSections) { dst.Combat = src.Combat },
+}
+
+var statSections = map[string]statSection{
+	models.SectionCombat: combatSection,
+	models.SectionWeapons: {
+		fill: func(s *playerStatsService, ctx context.Context, guid string, out *PlayerStatsSections) error {
+			if err := s.fillWeaponStats(ctx, guid, &out.Weapons); err != nil {
+				return fmt.Errorf("weapon stats: %w", err)
+			}
+			return nil
+		},
+		merge: func(dst, src *PlayerStatsSections) { dst.Weapons = src.Weapons },
+	},
+	models.SectionMovement: {
+		fill: func(s *playerStatsService, ctx context.Context, guid string, out *PlayerStatsSections) error {
+			if err := s.fillMovementStats(ctx, guid, &out.Movement); err != nil {
+				return fmt.Errorf("movement stats: %w", err)
+			}
+			return nil
+		},
+		merge: func(dst, src *PlayerStatsSections) { dst.Movement = src.Movement },
+	},
+	models.SectionAccuracy: {
+		fill: func(s *playerStatsService, ctx context.Context, guid string, out *PlayerStatsSections) error {
+			if err := s.fillAccuracyStats(ctx, guid, &out.Accuracy); err != nil {
+				return fmt.Errorf("accuracy stats: %w", err)
+			}
+			return nil
+		},
+		merge: func(dst, src *PlayerStatsSections) { dst.Accuracy = src.Accuracy },
+	},
+	models.SectionSession: {
+		fill: func(s *playerStatsService, ctx context.Context, guid string, out *PlayerStatsSections) error {
+			if err := s.fillSessionStats(ctx, guid, &out.Session); err != nil {
+				return fmt.Errorf("session stats: %w", err)
+			}
+			return nil
+		},
+		merge: func(dst, src *PlayerStatsSections) { dst.Session = src.Session },
+	},
+	models.SectionStance: {
+		// Stance percentages are of the combat section's kills
+		fill: func(s *playerStatsService, ctx context.Context, guid string, out *PlayerStatsSections) error {
+			combat, err := s.section(ctx, guid, models.SectionCombat, combatSection)
+			if err != nil {
+				return nil
+			}
+			if err := s.fillStanceStats(ctx, guid, &out.Stance, combat.Combat.Kills); err != nil {
+				out.Stance = models.StanceStats{}
+			}
+			return nil
+		},
+		merge: func(dst, src *PlayerStatsSections) { dst.Stance = src.Stance },
+	},
+	models.SectionRivals: {
+		fill: func(s *playerStatsService, ctx context.Context, guid string, out *PlayerStatsSections) error {
+			if err := s.fillRivalStats(ctx, guid, &out.Rivals); err != nil {
+				out.Rivals = models.RivalStats{}
+			}
+			return nil
+		},
+		merge: func(dst, src *PlayerStatsSections) { dst.Rivals = src.Rivals },
+	},
+	models.SectionInteraction: {
+		fill: func(s *playerStatsService, ctx context.Context, guid string, out *PlayerStatsSections) error {
+			if err := s.fillInteractionStats(ctx, guid, &out.Interaction); err != nil {
+				out.Interaction = models.InteractionStats{}
+			}
+			return nil
+		},
+		merge: func(dst, src *PlayerStatsSections) { dst.Interaction = src.Interaction },
+	},
+	models.SectionDeathCauses: {
+		fill: func(s *playerStatsService, ctx context.Context, guid string, out *PlayerStatsSections) error {
+			if err := s.fillDeathCauses(ctx, guid, &out.DeathsByCause); err != nil {
+				out.DeathsByCause = []models.DeathCauseStat{}
+			}
+			return nil
+		},
+		merge: func(dst, src *PlayerStatsSections) { dst.DeathsByCause = src.DeathsByCause },
+	},
+	models.SectionSurvival: {
+		fill: func(s *playerStatsService, ctx context.Context, guid string, out *PlayerStatsSections) error {
+			if err := s.fillSurvivalStats(ctx, guid, &out.Survival); err != nil {
+				out.Survival = models.SurvivalStats{}
+			}
+			return nil
+		},
+		merge: func(dst, src *PlayerStatsSections) { dst.Survival = src.Survival },
+	},
+	models.SectionForm: {
+		fill: func(s *playerStatsService, ctx context.Context, guid string, out *PlayerStatsSections) error {
+			if err := s.fillFormStats(ctx, guid, &out.Form); err != nil {
+				out.Form = models.FormStats{}
+			}
+			return nil
+		},
+		merge: func(dst, src *PlayerStatsSections) { dst.Form = src.Form },
+	},
+	models.SectionMaps: {
+		fill: func(s *playerStatsService, ctx context.Context, guid string, out *PlayerStatsSections) error {
+			s.fillTopMaps(ctx, guid, &out.Maps)
+			return nil
+		},
+		merge: func(dst, src *PlayerStatsSections) { dst.Maps = src.Maps },
+	},
+	models.SectionPerformance: {
+		fill: func(s *playerStatsService, ctx context.Context, guid string, out *PlayerStatsSections) error {
+			s.fillPerformance(ctx, guid, &out.Performance)
+			return nil
+		},
+		merge: func(dst, src *PlayerStatsSections) { dst.Performance = src.Performance },
+	},
+	models.SectionRecentMatches: {
+		fill: func(s *playerStatsService, ctx context.Context, guid string, out *PlayerStatsSections) error {
+			s.fillRecentMatches(ctx, guid, &out.RecentMatches)
+			return nil
+		},
+		merge: func(dst, src *PlayerStatsSections) { dst.RecentMatches = src.RecentMatches },
+	},
+}
+
+// DeepStatSections lists the sections of models.DeepStats
+func DeepStatSections() []string {
+	return []string{
+		models.SectionCombat, models.SectionWeapons, models.SectionMovement, models.SectionAccuracy,
+		models.SectionSession, models.SectionStance, models.SectionRivals, models.SectionInteraction,
+		models.SectionDeathCauses, models.SectionSurvival, models.SectionForm,
+	}
+}
+
+// AllStatSections lists every section, as served by /stats/player/{guid}
+func AllStatSections() []string {
+	return append(DeepStatSections(), models.SectionMaps, models.SectionPerformance, models.SectionRecentMatches)
+}
+
+// ParseStatSections reads a comma-separated ?sections= list. An empty list
+// returns nil, for the caller's default.
+func ParseStatSections(s string) ([]string, error) {
+	var sections []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		if _, ok := statSections[name]; !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownSection, name)
+		}
+		seen[name] = true
+		sections = append(sections, name)
+	}
+	return sections, nil
+}
+
+// GetStatsSections computes only the given sections of a player's stats,
+// side by side, reusing sections computed in the last sectionCacheTTL
+func (s *playerStatsService) GetStatsSections(ctx context.Context, guid string, sections []string) (*PlayerStatsSections, error) {
+	for _, name := range sections {
+		if _, ok := statSections[name]; !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownSection, name)
+		}
+	}
+
+	stats := &PlayerStatsSections{}
+	var mu sync.Mutex
+
+	g, ctx := errgroup.WithContext(ctx)
+	for _, name := range sections {
+		sec := statSections[name]
+		g.Go(func() error {
+			part, err := s.section(ctx, guid, name, sec)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			sec.merge(stats, part)
+			mu.Unlock()
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// section returns one computed section, from the cache when it is fresh
+func (s *playerStatsService) section(ctx context.Context, guid, name string, sec statSection) (*PlayerStatsSections, error) {
+	key := name + ":" + guid
+	if RoundPhaseFilter(ctx, "") == "" {
+		key += ":all_phases"
+	}
+	return s.cache.get(key, func() (*PlayerStatsSections, error) {
+		part := &PlayerStatsSections{}
+		if err := sec.fill(s, ctx, guid, part); err != nil {
+			return nil, err
+		}
+		return part, nil
+	})
+}
+
+// GetProfile returns the flat profile of /stats/player/{guid} built from the
+// given sections; fields of other sections are zero. Cosmetics are left to
+// the caller.
+func (s *playerStatsService) GetProfile(ctx context.Context, guid string, sections []string) (*models.PlayerStats, error) {
+	stats, err := s.GetStatsSections(ctx, guid, sections)
+	if err != nil {
+		return nil, err
+	}
+
+	player := &models.PlayerStats{
+		GUID:       guid,
+		Name:       "Unknown Soldier",
+		PlayerName: "Unknown Soldier",
+
+		// Combat
+		Kills:       stats.Combat.Kills,
+		Deaths:      stats.Combat.Deaths,
+		KDRatio:     stats.Combat.KDRatio,
+		Headshots:   stats.Combat.Headshots,
+		Accuracy:    stats.Accuracy.Overall,
+		DamageDealt: stats.Combat.DamageDealt,
+		DamageTaken: stats.Combat.DamageTaken,
+		Suicides:    stats.Combat.Suicides,
+		TeamKills:   stats.Combat.TeamKills,
+		BashKills:   stats.Combat.BashKills,
+
+		// Body Parts
+		TorsoKills: stats.Combat.TorsoKills,
+		LimbKills:  stats.Combat.LimbKills,
+
+		// Session
+		MatchesPlayed:   stats.Session.MatchesPlayed,
+		MatchesWon:      stats.Session.Wins,
+		WinRate:         stats.Session.WinRate,
+		PlaytimeSeconds: stats.Session.PlaytimeHours * 3600,
+
+		// Movement
+		DistanceMeters: stats.Movement.TotalDistanceKm * 1000, // Return meters
+		Jumps:          stats.Movement.JumpCount,
+
+		// Stance
+		StandingKills:  stats.Stance.StandingKills,
+		CrouchingKills: stats.Stance.CrouchKills,
+		ProneKills:     stats.Stance.ProneKills,
+
+		// Lists
+		Weapons:       stats.Weapons,
+		Maps:          nonNil(stats.Maps),
+		Performance:   nonNil(stats.Performance),
+		RecentMatches: nonNil(stats.RecentMatches),
+		Achievements:  []string{},
+	}
+
+	// Try to get name (most recent)
+	var name string
+	if err := s.ch.QueryRow(ctx, "SELECT argMax(actor_name, timestamp) FROM mohaa_stats.raw_events WHERE actor_id = ?", guid).Scan(&name); err == nil && name != "" {
+		player.Name = name
+		player.PlayerName = name
+	}
+	return player, nil
+}
+
+// nonNil returns an empty slice for nil, so lists encode as []
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+// sectionCache keeps computed sections for a short while. Concurrent
+// requests for a section being computed wait for it instead of running the
+// same queries; failures are not kept.
+type sectionCache struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	entries   map[string]*sectionEntry
+	lastSweep time.Time
+}
+
+type sectionEntry struct {
+	done    chan struct{}
+	stats   *PlayerStatsSections
+	err     error
+	expires time.Time // zero while computing
+}
+
+func newSectionCache(ttl time.Duration) *sectionCache {
+	return &sectionCache{ttl: ttl, entries: make(map[string]*sectionEntry)}
+}
+
+func (c *sectionCache) get(key string, compute func() (*PlayerStatsSections, error)) (*PlayerStatsSections, error) {
+	now := time.Now()
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok && (e.expires.IsZero() || now.Before(e.expires)) {
+		c.mu.Unlock()
+		<-e.done
+		return e.stats, e.err
+	}
+	if now.Sub(c.lastSweep) >= c.ttl {
+		for k, e := range c.entries {
+			if !e.expires.IsZero() && !now.Before(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		c.lastSweep = now
+	}
+	e := &sectionEntry{done: make(chan struct{})}
+	c.entries[key] = e
+	c.mu.Unlock()
+
+	e.stats, e.err = compute()
+
+	c.mu.Lock()
+	if e.err != nil {
+		if c.entries[key] == e {
+			delete(c.entries, key)
+		}
+	} else {
+		e.expires = time.Now().Add(c.ttl)
+	}
+	c.mu.Unlock()
+	close(e.done)
+	return e.stats, e.err
+}
+
+// fillPerformance reads the player's kills and deaths in their last 20 matches
+func (s *playerStatsService) fillPerformance(ctx context.Context, guid string, out *[]models.PerformancePoint) {
+	*out = make([]models.PerformancePoint, 0)
+	rows, err := s.ch.Query(ctx, `
+		SELECT
+			toString(match_id) as match_id,
+			countIf(event_type IN ('player_kill', 'bot_killed') AND actor_id = ?) as kills,
+			countIf(event_type IN ('player_kill', 'bot_killed') AND target_id = ?) as deaths,
+			min(timestamp) as played_at
+		FROM mohaa_stats.raw_events
+		WHERE match_id IN (
+			SELECT match_id FROM mohaa_stats.raw_events
+			WHERE actor_id = ? OR target_id = ?
+			GROUP BY match_id
+			ORDER BY max(timestamp) DESC
+			LIMIT 20
+		)
+		GROUP BY match_id
+		ORDER BY played_at ASC
+	`, guid, guid, guid, guid)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var mid string
+		var k, d uint64
+		var t time.Time
+		if err := rows.Scan(&mid, &k, &d, &t); err == nil {
+			kd := float64(k)
+			if d > 0 {
+				kd = float64(k) / float64(d)
+			}
+			*out = append(*out, models.PerformancePoint{
+				MatchID:  mid,
+				Kills:    k,
+				Deaths:   d,
+				KD:       kd,
+				PlayedAt: t.Unix(),
+			})
+		}
+	}
+}
+
+// fillTopMaps reads the player's five most played maps
+func (s *playerStatsService) fillTopMaps(ctx context.Context, guid string, out *[]models.PlayerMapStats) {
+	*out = make([]models.PlayerMapStats, 0)
+	rows, err := s.ch.Query(ctx, `
+		SELECT
+			map_name,
+			countIf(event_type IN ('player_kill', 'bot_killed') AND actor_id = ?) as kills,
+			countIf(event_type IN ('player_kill', 'bot_killed') AND target_id = ?) as deaths,
+			count(DISTINCT match_id) as matches,
+			0 as wins
+		FROM mohaa_stats.raw_events
+		WHERE (actor_id = ? OR target_id = ?) AND map_name != ''
+		GROUP BY map_name
+		ORDER BY matches DESC
+		LIMIT 5
+	`, guid, guid, guid, guid)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		var k, d, m, w uint64
+		if err := rows.Scan(&name, &k, &d, &m, &w); err == nil {
+			*out = append(*out, models.PlayerMapStats{
+				MapName:       name,
+				Kills:         k,
+				Deaths:        d,
+				MatchesPlayed: m,
+				MatchesWon:    w,
+			})
+		}
+	}
+}
+
+// fillRecentMatches reads the player's last 10 matches
+func (s *playerStatsService) fillRecentMatches(ctx context.Context, guid string, out *[]models.RecentMatch) {
+	*out = make([]models.RecentMatch, 0)
+	rows, err := s.ch.Query(ctx, `
+		SELECT
+			toString(match_id) as match_id,
+			map_name,
+			countIf(event_type IN ('player_kill', 'bot_killed') AND actor_id = ?) as kills,
+			countIf(event_type IN ('player_kill', 'bot_killed') AND target_id = ?) as deaths,
+			min(timestamp) as started
+		FROM mohaa_stats.raw_events
+		WHERE actor_id = ? OR target_id = ?
+		GROUP BY match_id, map_name
+		ORDER BY started DESC
+		LIMIT 10
+	`, guid, guid, guid, guid)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var mid, mn string
+		var k, d uint64
+		var t time.Time
+		if err := rows.Scan(&mid, &mn, &k, &d, &t); err == nil {
+			*out = append(*out, models.RecentMatch{
+				MatchID: mid,
+				MapName: mn,
+				Kills:   k,
+				Deaths:  d,
+				Date:    t.Unix(),
+			})
+		}
+	}
+}
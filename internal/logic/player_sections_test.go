@@ -0,0 +1,85 @@
+package logic
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestParseStatSections(t *testing.T) {
+	got, err := ParseStatSections(" Combat,maps,,performance,combat ")
+	if err != nil {
+		t.Fatalf("ParseStatSections: %v", err)
+	}
+	if want := []string{models.SectionCombat, models.SectionMaps, models.SectionPerformance}; !reflect.DeepEqual(got, want) {
+		t.Errorf("sections = %v, want %v", got, want)
+	}
+
+	if got, err := ParseStatSections(""); got != nil || err != nil {
+		t.Errorf("empty list = %v, %v; want nil for the default", got, err)
+	}
+	if _, err := ParseStatSections("combat,everything"); !errors.Is(err, ErrUnknownSection) {
+		t.Errorf("unknown section err = %v, want ErrUnknownSection", err)
+	}
+
+	for _, name := range AllStatSections() {
+		if _, ok := statSections[name]; !ok {
+			t.Errorf("section %q has no implementation", name)
+		}
+	}
+}
+
+func TestSectionCacheCoalescesAndExpires(t *testing.T) {
+	c := newSectionCache(50 * time.Millisecond)
+	var calls atomic.Int32
+	release := make(chan struct{})
+	compute := func() (*PlayerStatsSections, error) {
+		calls.Add(1)
+		<-release
+		return &PlayerStatsSections{}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.get("combat:p1", compute); err != nil {
+				t.Errorf("get: %v", err)
+			}
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Errorf("computed %d times for concurrent requests, want 1", n)
+	}
+
+	c.get("combat:p1", compute)
+	if n := calls.Load(); n != 1 {
+		t.Errorf("fresh section recomputed (%d calls)", n)
+	}
+	time.Sleep(60 * time.Millisecond)
+	c.get("combat:p1", compute)
+	if n := calls.Load(); n != 2 {
+		t.Errorf("expired section not recomputed (%d calls)", n)
+	}
+}
+
+func TestSectionCacheDropsFailures(t *testing.T) {
+	c := newSectionCache(time.Minute)
+	boom := errors.New("clickhouse down")
+	if _, err := c.get("weapons:p1", func() (*PlayerStatsSections, error) { return nil, boom }); !errors.Is(err, boom) {
+		t.Fatalf("err = %v, want %v", err, boom)
+	}
+	got, err := c.get("weapons:p1", func() (*PlayerStatsSections, error) { return &PlayerStatsSections{}, nil })
+	if err != nil || got == nil {
+		t.Errorf("retry after a failure = %v, %v; want a fresh computation", got, err)
+	}
+}
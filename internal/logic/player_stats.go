@@ -6,83 +6,44 @@ import (
 
 	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
 	"github.com/openmohaa/stats-api/internal/models"
-	"golang.org/x/sync/errgroup"
 )
 
 type playerStatsService struct {
-	ch driver.Conn
+	ch    driver.Conn
+	cache *sectionCache
 }
 
 func NewPlayerStatsService(ch driver.Conn) PlayerStatsService {
-	return &playerStatsService{ch: ch}
+	return &playerStatsService{ch: ch, cache: newSectionCache(sectionCacheTTL)}
 }
 
 // GetDeepStats fetches all categories for a player
 func (s *playerStatsService) GetDeepStats(ctx context.Context, guid string) (*models.DeepStats, error) {
-	stats := &models.DeepStats{}
-
-	g, ctx := errgroup.WithContext(ctx)
-
-	// Combat stats first, then Stance stats which depend on Combat.Kills
-	g.Go(func() error {
-		if err := s.fillCombatStats(ctx, guid, &stats.Combat); err != nil {
-			return fmt.Errorf("combat stats: %w", err)
-		}
-		if err := s.fillStanceStats(ctx, guid, &stats.Stance, stats.Combat.Kills); err != nil {
-			stats.Stance = models.StanceStats{}
-		}
-		return nil
-	})
-
-	g.Go(func() error {
-		if err := s.fillWeaponStats(ctx, guid, &stats.Weapons); err != nil {
-			return fmt.Errorf("weapon stats: %w", err)
-		}
-		return nil
-	})
-
-	g.Go(func() error {
-		if err := s.fillMovementStats(ctx, guid, &stats.Movement); err != nil {
-			return fmt.Errorf("movement stats: %w", err)
-		}
-		return nil
-	})
-
-	g.Go(func() error {
-		if err := s.fillAccuracyStats(ctx, guid, &stats.Accuracy); err != nil {
-			return fmt.Errorf("accuracy stats: %w", err)
-		}
-		return nil
-	})
-
-	g.Go(func() error {
-		if err := s.fillSessionStats(ctx, guid, &stats.Session); err != nil {
-			return fmt.Errorf("session stats: %w", err)
-		}
-		return nil
-	})
-
-	g.Go(func() error {
-		if err := s.fillRivalStats(ctx, guid, &stats.Rivals); err != nil {
-			// Non-critical, log only? For now just return empty
-			stats.Rivals = models.RivalStats{}
-		}
-		return nil
-	})
-
-	g.Go(func() error {
-		if err := s.fillInteractionStats(ctx, guid, &stats.Interaction); err != nil {
-			// Log or ignore
-			stats.Interaction = models.InteractionStats{}
-		}
-		return nil
-	})
-
-	if err := g.Wait(); err != nil {
+	stats, err := s.GetStatsSections(ctx, guid, DeepStatSections())
+	if err != nil {
 		return nil, err
 	}
+	return &stats.DeepStats, nil
+}
 
-	return stats, nil
+// fillSurvivalStats reads the lifetimes the worker stores on the player's
+// deaths by pairing them with their spawns
+func (s *playerStatsService) fillSurvivalStats(ctx context.Context, guid string, out *models.SurvivalStats) error {
+	query := `
+		SELECT
+			sumIf(sample_weight, actor_id = ? AND event_type IN ('player_spawn', 'player_respawn')) AS spawns,
+			countIf(target_id = ? AND target_lifetime > 0) AS lives,
+			ifNotFinite(avgIf(target_lifetime, target_id = ? AND target_lifetime > 0), 0) AS avg_lifetime,
+			toFloat64(maxIf(target_lifetime, target_id = ?)) AS longest_life,
+			ifNotFinite(avgIf(target_engage_delay, target_id = ? AND target_lifetime > 0), 0) AS avg_engage
+		FROM mohaa_stats.raw_events
+		WHERE (actor_id = ? OR target_id = ?)` + RoundPhaseFilter(ctx, "")
+	if err := s.ch.QueryRow(ctx, query, guid, guid, guid, guid, guid, guid, guid).Scan(
+		&out.Spawns, &out.Lives, &out.AvgLifetime, &out.LongestLife, &out.AvgTimeToEngage,
+	); err != nil {
+		return fmt.Errorf("failed to query survival stats: %w", err)
+	}
+	return nil
 }
 
 func (s *playerStatsService) fillCombatStats(ctx context.Context, guid string, out *models.CombatStats) error {
@@ -102,8 +63,8 @@ func (s *playerStatsService) fillCombatStats(ctx context.Context, guid string, o
 			countIf(event_type IN ('player_kill', 'bot_killed') AND actor_id = ? AND JSONExtractString(raw_json, 'mod') = 'bash') as bash_kills,
 			countIf(event_type IN ('player_kill', 'bot_killed') AND actor_id = ? AND JSONExtractString(raw_json, 'mod') IN ('grenade', 'explosion')) as grenade_kills,
 			countIf(event_type = 'grenade_throw' AND actor_id = ?) as grenades_thrown,
-			sumIf(damage, event_type = 'damage' AND target_id = ?) as damage_dealt,
-			sumIf(damage, event_type = 'damage' AND actor_id = ?) as damage_taken
+			sumIf(damage * sample_weight, event_type = 'damage' AND target_id = ?) as damage_dealt,
+			sumIf(damage * sample_weight, event_type = 'damage' AND actor_id = ?) as damage_taken
 		FROM mohaa_stats.raw_events
 		WHERE (actor_id = ? OR target_id = ?)
 	`
@@ -135,6 +96,8 @@ func (s *playerStatsService) fillCombatStats(ctx context.Context, guid string, o
 	if out.Kills > 0 {
 		out.HeadshotPercent = (float64(out.Headshots) / float64(out.Kills)) * 100
 	}
+	out.KDRatioCI = kdCI(out.Kills, out.Deaths)
+	out.HeadshotPercentCI = rateCI(out.Headshots, out.Kills)
 
 	// Compute kill streaks and multi-kills from raw events
 	if err := s.fillStreakAndMultikillStats(ctx, guid, out); err != nil {
@@ -297,9 +260,9 @@ func (s *playerStatsService) fillWeaponStats(ctx context.Context, guid string, o
 			countIf(event_type = 'player_kill') as player_kills,
 			countIf(event_type = 'bot_killed') as bot_kills,
 			countIf(hitloc IN ('head', 'helmet')) as headshots,
-			countIf(event_type = 'weapon_fire') as shots,
-			countIf(event_type = 'weapon_hit') as hits,
-			sumIf(damage, event_type = 'damage' AND actor_id = ?) as damage
+			sumIf(sample_weight, event_type = 'weapon_fire') as shots,
+			sumIf(sample_weight, event_type = 'weapon_hit') as hits,
+			sumIf(damage * sample_weight, event_type = 'damage' AND actor_id = ?) as damage
 		FROM mohaa_stats.raw_events
 		WHERE actor_id = ? AND actor_weapon != ''
 		GROUP BY actor_weapon
@@ -325,18 +288,16 @@ func (s *playerStatsService) fillWeaponStats(ctx context.Context, guid string, o
 }
 
 func (s *playerStatsService) fillMovementStats(ctx context.Context, guid string, out *models.MovementStats) error {
-	// Distance event stores walked/sprinted/swam/driven in raw_json
+	// Distance events carry walked/sprinted/swam/driven; movement_events_all
+	// covers both raw_events and the narrow movement table.
 	// Convert game units to kilometers (divide by 100000)
 	query := `
 		SELECT 
-			(sumIf(JSONExtractFloat(raw_json, 'walked'), event_type = 'distance') + 
-			 sumIf(JSONExtractFloat(raw_json, 'sprinted'), event_type = 'distance') + 
-			 sumIf(JSONExtractFloat(raw_json, 'swam'), event_type = 'distance') + 
-			 sumIf(JSONExtractFloat(raw_json, 'driven'), event_type = 'distance')) / 100000.0 as km,
-			countIf(event_type = 'jump') as jumps,
-			countIf(event_type = 'crouch') as crouches,
-			countIf(event_type = 'prone') as prones
-		FROM mohaa_stats.raw_events
+			sumIf((walked + sprinted + swam + driven) * sample_weight, event_type = 'distance') / 100000.0 as km,
+			sumIf(sample_weight, event_type = 'jump') as jumps,
+			sumIf(sample_weight, event_type = 'crouch') as crouches,
+			sumIf(sample_weight, event_type = 'prone') as prones
+		FROM mohaa_stats.movement_events_all
 		WHERE actor_id = ?
 	`
 
@@ -357,8 +318,8 @@ func (s *playerStatsService) fillAccuracyStats(ctx context.Context, guid string,
 
 	query := `
 		SELECT 
-			countIf(event_type = 'weapon_fire') as shots,
-			countIf(event_type = 'weapon_hit') as hits,
+			sumIf(sample_weight, event_type = 'weapon_fire') as shots,
+			sumIf(sample_weight, event_type = 'weapon_hit') as hits,
 			countIf(event_type IN ('player_kill', 'bot_killed') AND hitloc IN ('head', 'helmet')) as headshots,
 			sumIf(distance, event_type IN ('player_kill', 'bot_killed')) / NULLIF(countIf(event_type IN ('player_kill', 'bot_killed')), 0) as avg_dist
 		FROM mohaa_stats.raw_events
@@ -377,6 +338,8 @@ func (s *playerStatsService) fillAccuracyStats(ctx context.Context, guid string,
 	if avgDist != nil {
 		out.AvgDistance = *avgDist
 	}
+	out.OverallCI = rateCI(hits, shots)
+	out.HeadHitPctCI = rateCI(headshots, hits)
 
 	return nil
 }
@@ -391,7 +354,7 @@ func (s *playerStatsService) fillSessionStats(ctx context.Context, guid string,
 	// Count wins using aggregation table
 	winsQuery := `
 		SELECT sum(matches_won)
-		FROM mohaa_stats.player_stats_daily
+		FROM ` + PlayerStatsTable(ctx) + `
 		WHERE player_id = ?
 	`
 	if err := s.ch.QueryRow(ctx, winsQuery, guid).Scan(&out.Wins); err != nil {
@@ -644,3 +607,88 @@ func (s *playerStatsService) GetPlayerStatsByMap(ctx context.Context, guid strin
 
 	return stats, nil
 }
+
+// pacingPhases names the thirds of a match
+var pacingPhases = []string{"early", "mid", "late"}
+
+// GetPlayerPacing returns the player's kills and deaths in the first, middle
+// and last third of their matches
+func (s *playerStatsService) GetPlayerPacing(ctx context.Context, guid string) (*models.PlayerPacing, error) {
+	rows, err := s.ch.Query(ctx, `
+		SELECT
+			toUInt8(least(floor((toUnixTimestamp64Milli(e.timestamp) - b.started) / (b.ended - b.started) * 3), 2)) AS third,
+			countIf(e.event_type IN ('player_kill', 'bot_killed') AND e.actor_id = ?) AS kills,
+			countIf(e.event_type IN ('death', 'player_kill') AND e.target_id = ?) AS deaths,
+			countIf(e.event_type IN ('player_kill', 'bot_killed') AND e.actor_id = ? AND e.hitloc IN ('head', 'helmet')) AS headshots,
+			uniqExact(e.match_id) AS matches
+		FROM mohaa_stats.raw_events e
+		INNER JOIN (
+			SELECT
+				match_id,
+				toUnixTimestamp64Milli(if(countIf(event_type = 'match_start') > 0, minIf(timestamp, event_type = 'match_start'), min(timestamp))) AS started,
+				toUnixTimestamp64Milli(if(countIf(event_type = 'match_end') > 0, maxIf(timestamp, event_type = 'match_end'), max(timestamp))) AS ended
+			FROM mohaa_stats.raw_events
+			WHERE match_id IN (
+				SELECT DISTINCT match_id FROM mohaa_stats.raw_events WHERE actor_id = ? OR target_id = ?
+			)
+			GROUP BY match_id
+			HAVING ended > started
+		) b ON e.match_id = b.match_id
+		WHERE e.event_type IN ('player_kill', 'bot_killed', 'death')
+		  AND (e.actor_id = ? OR e.target_id = ?)
+		  AND toUnixTimestamp64Milli(e.timestamp) BETWEEN b.started AND b.ended`+RoundPhaseFilter(ctx, "e")+`
+		GROUP BY third
+		ORDER BY third
+	`, guid, guid, guid, guid, guid, guid, guid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pacing: %w", err)
+	}
+	defer rows.Close()
+
+	pacing := &models.PlayerPacing{GUID: guid, Phases: make([]models.PacingPhase, len(pacingPhases))}
+	for i, phase := range pacingPhases {
+		pacing.Phases[i].Phase = phase
+	}
+	for rows.Next() {
+		var third uint8
+		var p models.PacingPhase
+		if err := rows.Scan(&third, &p.Kills, &p.Deaths, &p.Headshots, &p.Matches); err != nil {
+			return nil, fmt.Errorf("failed to scan pacing: %w", err)
+		}
+		if int(third) >= len(pacingPhases) {
+			continue
+		}
+		p.Phase = pacingPhases[third]
+		pacing.Phases[third] = p
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan pacing: %w", err)
+	}
+
+	finishPacing(pacing)
+	return pacing, nil
+}
+
+// finishPacing fills in the K/D ratios and the trend
+func finishPacing(p *models.PlayerPacing) {
+	for i := range p.Phases {
+		phase := &p.Phases[i]
+		if phase.Deaths > 0 {
+			phase.KDRatio = float64(phase.Kills) / float64(phase.Deaths)
+		} else {
+			phase.KDRatio = float64(phase.Kills)
+		}
+	}
+
+	early, late := p.Phases[0].KDRatio, p.Phases[len(p.Phases)-1].KDRatio
+	switch {
+	case early == 0 && late == 0:
+		p.Trend = "steady"
+	case late > early*1.2:
+		p.Trend = "strong_finisher"
+	case late < early*0.8:
+		p.Trend = "fast_starter"
+	default:
+		p.Trend = "steady"
+	}
+}
@@ -151,3 +151,37 @@ func TestGetPlayerStatsByMap(t *testing.T) {
 		})
 	}
 }
+
+func TestFinishPacing(t *testing.T) {
+	phases := func(early, late [2]uint64) []models.PacingPhase {
+		return []models.PacingPhase{
+			{Phase: "early", Kills: early[0], Deaths: early[1]},
+			{Phase: "mid", Kills: 1, Deaths: 1},
+			{Phase: "late", Kills: late[0], Deaths: late[1]},
+		}
+	}
+	tests := []struct {
+		name        string
+		early, late [2]uint64
+		trend       string
+	}{
+		{"no data", [2]uint64{0, 0}, [2]uint64{0, 0}, "steady"},
+		{"even", [2]uint64{10, 10}, [2]uint64{11, 10}, "steady"},
+		{"finisher", [2]uint64{5, 10}, [2]uint64{10, 5}, "strong_finisher"},
+		{"starter", [2]uint64{10, 5}, [2]uint64{5, 10}, "fast_starter"},
+		{"deathless late", [2]uint64{2, 2}, [2]uint64{3, 0}, "strong_finisher"},
+	}
+	for _, tt := range tests {
+		p := &models.PlayerPacing{Phases: phases(tt.early, tt.late)}
+		finishPacing(p)
+		if p.Trend != tt.trend {
+			t.Errorf("%s: trend = %s, want %s", tt.name, p.Trend, tt.trend)
+		}
+	}
+
+	p := &models.PlayerPacing{Phases: phases([2]uint64{6, 4}, [2]uint64{0, 0})}
+	finishPacing(p)
+	if p.Phases[0].KDRatio != 1.5 {
+		t.Errorf("early K/D = %v, want 1.5", p.Phases[0].KDRatio)
+	}
+}
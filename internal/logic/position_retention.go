@@ -0,0 +1,67 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+)
+
+// rawJSONPositions matches the position and view angle fields of a stored
+// event's raw_json; rawJSONStripped zeroes them, keeping the JSON valid
+const (
+	rawJSONPositions = `"((?:pos|attacker|victim)_[xyz]|(?:attacker|victim|aim)_(?:pitch|yaw))":\s*-?[0-9][0-9.eE+-]*`
+	rawJSONStripped  = `"\1":0`
+)
+
+type positionRetentionService struct {
+	ch driver.Conn
+}
+
+func NewPositionRetentionService(ch driver.Conn) PositionRetentionService {
+	return &positionRetentionService{ch: ch}
+}
+
+// StripPositions removes the precise positions of the events before a time:
+// position and view angle columns and raw_json fields are zeroed in
+// raw_events and movement_events, and the rows of positions are deleted.
+// position_cells, fed from positions as they are inserted, keeps the 50-unit
+// grid counts heatmaps read. Rows already stripped are not rewritten again,
+// so each nightly run only touches the day that aged out.
+func (s *positionRetentionService) StripPositions(ctx context.Context, before time.Time) error {
+	// Wait for each mutation, so a run ends when its work is done and the
+	// next one does not queue behind it
+	syncCtx := clickhouse.Context(ctx, clickhouse.WithSettings(clickhouse.Settings{"mutations_sync": 2}))
+
+	if err := s.ch.Exec(syncCtx, `
+		ALTER TABLE mohaa_stats.raw_events
+		UPDATE
+			actor_pos_x = 0, actor_pos_y = 0, actor_pos_z = 0, actor_pitch = 0, actor_yaw = 0,
+			target_pos_x = 0, target_pos_y = 0, target_pos_z = 0,
+			raw_json = replaceRegexpAll(raw_json, ?, ?)
+		WHERE _partition_date < toDate(toDateTime(?))
+			AND (actor_pos_x != 0 OR actor_pos_y != 0 OR actor_pos_z != 0
+				OR actor_pitch != 0 OR actor_yaw != 0
+				OR target_pos_x != 0 OR target_pos_y != 0 OR target_pos_z != 0)
+	`, rawJSONPositions, rawJSONStripped, before.Unix()); err != nil {
+		return fmt.Errorf("failed to strip raw_events positions: %w", err)
+	}
+
+	if err := s.ch.Exec(syncCtx, `
+		ALTER TABLE mohaa_stats.movement_events
+		UPDATE actor_pos_x = 0, actor_pos_y = 0, actor_pos_z = 0
+		WHERE timestamp < toDateTime(?) AND (actor_pos_x != 0 OR actor_pos_y != 0 OR actor_pos_z != 0)
+	`, before.Unix()); err != nil {
+		return fmt.Errorf("failed to strip movement_events positions: %w", err)
+	}
+
+	if err := s.ch.Exec(syncCtx, `
+		ALTER TABLE mohaa_stats.positions
+		DELETE WHERE timestamp < toDateTime(?)
+	`, before.Unix()); err != nil {
+		return fmt.Errorf("failed to delete old positions: %w", err)
+	}
+	return nil
+}
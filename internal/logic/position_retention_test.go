@@ -0,0 +1,21 @@
+package logic
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRawJSONPositions(t *testing.T) {
+	// ClickHouse's re2 takes the same pattern; \1 is Go's ${1}
+	re := regexp.MustCompile(rawJSONPositions)
+	replacement := strings.ReplaceAll(rawJSONStripped, `\1`, `${1}`)
+
+	in := `{"type":"player_kill","attacker_guid":"g1","attacker_x":1024.5,"attacker_y":-88,"attacker_pitch":-1.5e1,` +
+		`"victim_x": 12.25,"victim_yaw":170,"pos_z":3,"aim_pitch":0.5,"weapon":"kar98","distance":512.75}`
+	want := `{"type":"player_kill","attacker_guid":"g1","attacker_x":0,"attacker_y":0,"attacker_pitch":0,` +
+		`"victim_x":0,"victim_yaw":0,"pos_z":0,"aim_pitch":0,"weapon":"kar98","distance":512.75}`
+	if got := re.ReplaceAllString(in, replacement); got != want {
+		t.Errorf("stripped raw_json\n got %s\nwant %s", got, want)
+	}
+}
@@ -0,0 +1,188 @@
+package logic
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// MatchLine is a player's kills and deaths in one match
+type MatchLine struct {
+	Kills  int
+	Deaths int
+}
+
+// Forecast is a model's expectation for a player's next match, with the
+// chance it gives itself of a hit (see PredictionHit)
+type Forecast struct {
+	Kills      int
+	Deaths     int
+	Confidence float64
+}
+
+// PredictionModel scores a player's next match from their recent matches,
+// newest first. A player without matches gets the newcomer line.
+type PredictionModel interface {
+	Name() string
+	Predict(recent []MatchLine) Forecast
+}
+
+// newcomerKills and newcomerDeaths are forecast for players with no matches
+const (
+	newcomerKills  = 10
+	newcomerDeaths = 15
+)
+
+// predictionModels is the model registry. PREDICTION_MODEL picks the one
+// whose forecasts are served; PREDICTION_SHADOW_MODELS are scored alongside
+// it without being shown.
+var predictionModels = map[string]PredictionModel{}
+
+func registerPredictionModel(m PredictionModel) {
+	predictionModels[m.Name()] = m
+}
+
+func init() {
+	registerPredictionModel(kdHeuristicModel{})
+	registerPredictionModel(recentMeanModel{})
+	registerPredictionModel(ewmaModel{alpha: 0.4})
+}
+
+// PredictionModelNames lists the registered models
+func PredictionModelNames() []string {
+	names := make([]string, 0, len(predictionModels))
+	for name := range predictionModels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PredictionModelConfig is the served model and the shadow models
+type PredictionModelConfig struct {
+	Primary string
+	Shadow  []string
+}
+
+// ParsePredictionModelConfig reads the primary model ("" is kd_heuristic) and
+// the comma-separated shadow models, which must all be registered. The
+// primary model is dropped from the shadow list.
+func ParsePredictionModelConfig(primary, shadow string) (PredictionModelConfig, error) {
+	cfg := PredictionModelConfig{Primary: strings.TrimSpace(primary)}
+	if cfg.Primary == "" {
+		cfg.Primary = kdHeuristicModel{}.Name()
+	}
+	if _, ok := predictionModels[cfg.Primary]; !ok {
+		return cfg, fmt.Errorf("unknown prediction model %q (want one of %s)", cfg.Primary, strings.Join(PredictionModelNames(), ", "))
+	}
+	for _, name := range splitTerms(shadow) {
+		if _, ok := predictionModels[name]; !ok {
+			return cfg, fmt.Errorf("unknown shadow prediction model %q (want one of %s)", name, strings.Join(PredictionModelNames(), ", "))
+		}
+		if name != cfg.Primary {
+			cfg.Shadow = append(cfg.Shadow, name)
+		}
+	}
+	return cfg, nil
+}
+
+// PredictionHit reports whether a kills forecast was close enough to count:
+// within a quarter of the forecast, and never stricter than two kills
+func PredictionHit(predicted, actual int) bool {
+	tolerance := math.Max(2, 0.25*float64(predicted))
+	return math.Abs(float64(actual-predicted)) <= tolerance
+}
+
+// kdHeuristicModel is the original forecast: 15 deaths a match, and kills at
+// the player's recent K/D over those deaths, at least 10
+type kdHeuristicModel struct{}
+
+func (kdHeuristicModel) Name() string { return "kd_heuristic" }
+
+func (kdHeuristicModel) Predict(recent []MatchLine) Forecast {
+	return Forecast{
+		Kills:      int(math.Max(newcomerKills, recentKD(recent)*newcomerDeaths)),
+		Deaths:     newcomerDeaths,
+		Confidence: 0.75,
+	}
+}
+
+// recentMeanModel forecasts the player's average over their recent matches
+type recentMeanModel struct{}
+
+func (recentMeanModel) Name() string { return "recent_mean" }
+
+func (recentMeanModel) Predict(recent []MatchLine) Forecast {
+	if len(recent) == 0 {
+		return newcomerForecast()
+	}
+	var kills, deaths float64
+	for _, m := range recent {
+		kills += float64(m.Kills)
+		deaths += float64(m.Deaths)
+	}
+	n := float64(len(recent))
+	return backtested(recent, int(math.Round(kills/n)), int(math.Round(deaths/n)))
+}
+
+// ewmaModel weighs recent matches more: each match counts alpha, and the
+// matches before it the remaining share
+type ewmaModel struct {
+	alpha float64
+}
+
+func (ewmaModel) Name() string { return "ewma" }
+
+func (m ewmaModel) Predict(recent []MatchLine) Forecast {
+	if len(recent) == 0 {
+		return newcomerForecast()
+	}
+	oldest := recent[len(recent)-1]
+	kills, deaths := float64(oldest.Kills), float64(oldest.Deaths)
+	for i := len(recent) - 2; i >= 0; i-- {
+		kills = m.alpha*float64(recent[i].Kills) + (1-m.alpha)*kills
+		deaths = m.alpha*float64(recent[i].Deaths) + (1-m.alpha)*deaths
+	}
+	return backtested(recent, int(math.Round(kills)), int(math.Round(deaths)))
+}
+
+func newcomerForecast() Forecast {
+	return Forecast{Kills: newcomerKills, Deaths: newcomerDeaths, Confidence: 0.5}
+}
+
+// backtested sets the confidence to how often the kills forecast would have
+// hit the recent matches, pulled towards even odds while they are few
+func backtested(recent []MatchLine, kills, deaths int) Forecast {
+	hits := 0
+	for _, m := range recent {
+		if PredictionHit(kills, m.Kills) {
+			hits++
+		}
+	}
+	return Forecast{
+		Kills:      kills,
+		Deaths:     deaths,
+		Confidence: float64(hits+1) / float64(len(recent)+2),
+	}
+}
+
+// recentKD is the mean of the per-match K/D ratios, a deathless match
+// counting its kills
+func recentKD(recent []MatchLine) float64 {
+	if len(recent) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, m := range recent {
+		sum += matchKD(m)
+	}
+	return sum / float64(len(recent))
+}
+
+func matchKD(m MatchLine) float64 {
+	if m.Deaths == 0 {
+		return float64(m.Kills)
+	}
+	return float64(m.Kills) / float64(m.Deaths)
+}
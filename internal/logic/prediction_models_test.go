@@ -0,0 +1,68 @@
+package logic
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPredictionModels(t *testing.T) {
+	// Newest first
+	recent := []MatchLine{{Kills: 20, Deaths: 10}, {Kills: 10, Deaths: 10}, {Kills: 12, Deaths: 8}}
+
+	tests := []struct {
+		model  string
+		recent []MatchLine
+		want   Forecast
+	}{
+		{model: "kd_heuristic", recent: nil, want: Forecast{Kills: 10, Deaths: 15, Confidence: 0.75}},
+		// Mean K/D (2 + 1 + 1.5) / 3 = 1.5 over 15 deaths
+		{model: "kd_heuristic", recent: recent, want: Forecast{Kills: 22, Deaths: 15, Confidence: 0.75}},
+		{model: "recent_mean", recent: nil, want: Forecast{Kills: 10, Deaths: 15, Confidence: 0.5}},
+		// 14 kills hits 12 only: (1 + 1) / (3 + 2)
+		{model: "recent_mean", recent: recent, want: Forecast{Kills: 14, Deaths: 9, Confidence: 0.4}},
+		// 12 -> 11.2 -> 14.72 kills; 8 -> 8.8 -> 9.28 deaths; hits 12 only
+		{model: "ewma", recent: recent, want: Forecast{Kills: 15, Deaths: 9, Confidence: 0.4}},
+	}
+	for _, tt := range tests {
+		got := predictionModels[tt.model].Predict(tt.recent)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s.Predict(%v) = %+v, want %+v", tt.model, tt.recent, got, tt.want)
+		}
+	}
+}
+
+func TestPredictionHit(t *testing.T) {
+	tests := []struct {
+		predicted, actual int
+		want              bool
+	}{
+		{predicted: 4, actual: 6, want: true},
+		{predicted: 4, actual: 7, want: false},
+		{predicted: 20, actual: 15, want: true},
+		{predicted: 20, actual: 26, want: false},
+		{predicted: 0, actual: 2, want: true},
+	}
+	for _, tt := range tests {
+		if got := PredictionHit(tt.predicted, tt.actual); got != tt.want {
+			t.Errorf("PredictionHit(%d, %d) = %v, want %v", tt.predicted, tt.actual, got, tt.want)
+		}
+	}
+}
+
+func TestParsePredictionModelConfig(t *testing.T) {
+	cfg, err := ParsePredictionModelConfig("", " ewma, kd_heuristic ,recent_mean")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := PredictionModelConfig{Primary: "kd_heuristic", Shadow: []string{"ewma", "recent_mean"}}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("cfg = %+v, want %+v", cfg, want)
+	}
+
+	if _, err := ParsePredictionModelConfig("oracle", ""); err == nil {
+		t.Error("unknown primary model accepted")
+	}
+	if _, err := ParsePredictionModelConfig("ewma", "ewma,oracle"); err == nil {
+		t.Error("unknown shadow model accepted")
+	}
+}
@@ -2,70 +2,88 @@ package logic
 
 import (
 	"context"
-	"math"
+	"fmt"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
 	"github.com/openmohaa/stats-api/internal/models"
 )
 
+// predictionResolveWindow is how long a forecast waits for the player's next
+// match; players who stay away longer leave it pending
+const predictionResolveWindow = 14 * 24 * time.Hour
+
+// predictionResolveBatch caps the pending forecasts one resolver run checks
+const predictionResolveBatch = 500
+
 type predictionService struct {
 	ch driver.Conn
+	pg PgPool
+
+	mu     sync.RWMutex
+	models PredictionModelConfig
 }
 
-func NewPredictionService(ch driver.Conn) PredictionService {
-	return &predictionService{ch: ch}
+func NewPredictionService(ch driver.Conn, pg PgPool, cfg PredictionModelConfig) PredictionService {
+	s := &predictionService{ch: ch, pg: pg}
+	s.SetModels(cfg)
+	return s
 }
 
+// SetModels replaces the served and shadow models, e.g. after a config reload
+func (s *predictionService) SetModels(cfg PredictionModelConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.models = cfg
+}
+
+func (s *predictionService) modelConfig() PredictionModelConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.models
+}
+
+// GetPlayerPredictions forecasts the player's next match with the primary
+// model and records it, with the shadow models' forecasts, for scoring. When
+// only the recording fails the forecast is returned along with the error.
 func (s *predictionService) GetPlayerPredictions(ctx context.Context, guid string) (*models.PlayerPredictions, error) {
 	pred := &models.PlayerPredictions{
 		GUID:        guid,
 		LastUpdated: time.Now(),
 	}
 
-	// Fetch recent performance (last 10 matches)
-	rows, err := s.ch.Query(ctx, `
-		SELECT 
-			kills / nullIf(deaths, 0) as kd
-		FROM mohaa_stats.raw_events
-		WHERE actor_id = ? AND event_type IN ('player_kill', 'bot_killed')
-		GROUP BY match_id, kills, deaths, timestamp
-		ORDER BY max(timestamp) DESC
-		LIMIT 10
-	`, guid)
-	if err == nil {
-		defer rows.Close()
-		var sumKD float64
-		for rows.Next() {
-			var kd float64
-			if err := rows.Scan(&kd); err == nil {
-				pred.RecentPerformance = append(pred.RecentPerformance, kd)
-				sumKD += kd
-			}
-		}
+	recent, err := s.recentMatches(ctx, guid)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range recent {
+		pred.RecentPerformance = append(pred.RecentPerformance, matchKD(m))
+	}
+	if len(recent) > 0 {
+		pred.ExpectedKD = recentKD(recent)
 
-		if len(pred.RecentPerformance) > 0 {
-			pred.ExpectedKD = sumKD / float64(len(pred.RecentPerformance))
-			
-			// Simple trend analysis
-			if len(pred.RecentPerformance) >= 3 {
-				latest := pred.RecentPerformance[0]
-				avg := pred.ExpectedKD
-				if latest > avg*1.1 {
-					pred.Trend = "improving"
-				} else if latest < avg*0.9 {
-					pred.Trend = "declining"
-				} else {
-					pred.Trend = "stable"
-				}
+		// Simple trend analysis
+		if len(recent) >= 3 {
+			latest := pred.RecentPerformance[0]
+			avg := pred.ExpectedKD
+			if latest > avg*1.1 {
+				pred.Trend = "improving"
+			} else if latest < avg*0.9 {
+				pred.Trend = "declining"
+			} else {
+				pred.Trend = "stable"
 			}
 		}
 	}
 
-	// Predicted stats for next match (simple heuristic)
-	pred.PredictedKills = int(math.Max(10, pred.ExpectedKD*15))
-	pred.PredictedDeaths = 15
-	pred.Confidence = 0.75 // Default visibility
+	cfg := s.modelConfig()
+	forecast := predictionModels[cfg.Primary].Predict(recent)
+	pred.Model = cfg.Primary
+	pred.PredictedKills = forecast.Kills
+	pred.PredictedDeaths = forecast.Deaths
+	pred.Confidence = forecast.Confidence
 
 	// Rival Analysis
 	rivalRows, err := s.ch.Query(ctx, `
@@ -86,16 +104,298 @@ func (s *predictionService) GetPlayerPredictions(ctx context.Context, guid strin
 			var kills int
 			if err := rivalRows.Scan(&r.OpponentGUID, &r.OpponentName, &kills); err == nil {
 				r.WinProb = 0.5 + (0.05 * float64(kills))
-				if r.WinProb > 0.95 { r.WinProb = 0.95 }
+				if r.WinProb > 0.95 {
+					r.WinProb = 0.95
+				}
 				r.Nemesis = kills > 20
 				pred.RivalAnalysis = append(pred.RivalAnalysis, r)
 			}
 		}
 	}
 
+	if err := s.record(ctx, guid, cfg, forecast, recent); err != nil {
+		return pred, err
+	}
 	return pred, nil
 }
 
+// recentMatches returns the player's kills and deaths in their last 10
+// matches, newest first
+func (s *predictionService) recentMatches(ctx context.Context, guid string) ([]MatchLine, error) {
+	rows, err := s.ch.Query(ctx, `
+		SELECT
+			countIf(event_type IN ('player_kill', 'bot_killed') AND actor_id = ?) AS kills,
+			countIf(event_type IN ('death', 'player_kill') AND target_id = ?) AS deaths
+		FROM mohaa_stats.raw_events
+		WHERE (actor_id = ? OR target_id = ?) AND match_id != ''
+		GROUP BY match_id
+		ORDER BY max(timestamp) DESC
+		LIMIT 10
+	`, guid, guid, guid, guid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recent matches: %w", err)
+	}
+	defer rows.Close()
+
+	var recent []MatchLine
+	for rows.Next() {
+		var kills, deaths uint64
+		if err := rows.Scan(&kills, &deaths); err != nil {
+			return nil, fmt.Errorf("failed to scan recent matches: %w", err)
+		}
+		recent = append(recent, MatchLine{Kills: int(kills), Deaths: int(deaths)})
+	}
+	return recent, rows.Err()
+}
+
+// record stores the primary forecast and the shadow models' forecasts as the
+// player's pending predictions, replacing any earlier ones
+func (s *predictionService) record(ctx context.Context, guid string, cfg PredictionModelConfig, primary Forecast, recent []MatchLine) error {
+	forecasts := map[string]Forecast{cfg.Primary: primary}
+	for _, name := range cfg.Shadow {
+		forecasts[name] = predictionModels[name].Predict(recent)
+	}
+	for name, f := range forecasts {
+		if _, err := s.pg.Exec(ctx, `
+			INSERT INTO predictions (model, shadow, player_guid, predicted_kills, predicted_deaths, confidence)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (model, player_guid) WHERE resolved_at IS NULL DO UPDATE SET
+				shadow = EXCLUDED.shadow,
+				predicted_at = NOW(),
+				predicted_kills = EXCLUDED.predicted_kills,
+				predicted_deaths = EXCLUDED.predicted_deaths,
+				confidence = EXCLUDED.confidence,
+				requests = predictions.requests + 1
+		`, name, name != cfg.Primary, guid, f.Kills, f.Deaths, f.Confidence); err != nil {
+			return fmt.Errorf("failed to record %s prediction: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// pendingPrediction is a forecast waiting for the player's next match
+type pendingPrediction struct {
+	id          int64
+	guid        string
+	predictedAt time.Time
+	kills       int
+}
+
+// ResolvePredictions scores pending forecasts against the first match each
+// player started and finished after the forecast, and returns how many were
+// resolved
+func (s *predictionService) ResolvePredictions(ctx context.Context) (int, error) {
+	rows, err := s.pg.Query(ctx, `
+		SELECT id, player_guid, predicted_at, predicted_kills
+		FROM predictions
+		WHERE resolved_at IS NULL AND predicted_at >= $1
+		ORDER BY predicted_at
+		LIMIT $2
+	`, time.Now().Add(-predictionResolveWindow), predictionResolveBatch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list pending predictions: %w", err)
+	}
+	var pending []pendingPrediction
+	for rows.Next() {
+		var p pendingPrediction
+		if err := rows.Scan(&p.id, &p.guid, &p.predictedAt, &p.kills); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan pending predictions: %w", err)
+		}
+		pending = append(pending, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to list pending predictions: %w", err)
+	}
+
+	// The models of one request share a player and time, and so a match
+	type matchKey struct {
+		guid string
+		at   time.Time
+	}
+	matches := make(map[matchKey]*predictionMatch)
+	resolved := 0
+	for _, p := range pending {
+		key := matchKey{p.guid, p.predictedAt}
+		m, ok := matches[key]
+		if !ok {
+			if m, err = s.nextMatch(ctx, p.guid, p.predictedAt); err != nil {
+				return resolved, err
+			}
+			matches[key] = m
+		}
+		if m == nil {
+			continue
+		}
+		if _, err := s.pg.Exec(ctx, `
+			UPDATE predictions
+			SET match_id = $2, actual_kills = $3, actual_deaths = $4, hit = $5, resolved_at = NOW()
+			WHERE id = $1
+		`, p.id, m.matchID, m.kills, m.deaths, PredictionHit(p.kills, m.kills)); err != nil {
+			return resolved, fmt.Errorf("failed to resolve prediction: %w", err)
+		}
+		resolved++
+	}
+	return resolved, nil
+}
+
+// predictionMatch is the match a forecast is scored against
+type predictionMatch struct {
+	matchID string
+	kills   int
+	deaths  int
+}
+
+// nextMatch finds the first match the player took part in that started after
+// since and has ended, or nil while there is none
+func (s *predictionService) nextMatch(ctx context.Context, guid string, since time.Time) (*predictionMatch, error) {
+	rows, err := s.ch.Query(ctx, `
+		SELECT match_id, kills, deaths
+		FROM (
+			SELECT
+				match_id,
+				min(timestamp) AS started,
+				countIf(event_type = 'match_end') AS ends,
+				countIf(event_type IN ('player_kill', 'bot_killed') AND actor_id = ?) AS kills,
+				countIf(event_type IN ('death', 'player_kill') AND target_id = ?) AS deaths
+			FROM mohaa_stats.raw_events
+			WHERE match_id IN (
+				SELECT DISTINCT match_id FROM mohaa_stats.raw_events
+				WHERE (actor_id = ? OR target_id = ?) AND timestamp >= ? AND match_id != ''
+			)
+			GROUP BY match_id
+		)
+		WHERE ends > 0 AND started >= ?
+		ORDER BY started
+		LIMIT 1
+	`, guid, guid, guid, guid, since, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query next match: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+	var m predictionMatch
+	var kills, deaths uint64
+	if err := rows.Scan(&m.matchID, &kills, &deaths); err != nil {
+		return nil, fmt.Errorf("failed to scan next match: %w", err)
+	}
+	m.kills, m.deaths = int(kills), int(deaths)
+	return &m, nil
+}
+
+// GetPredictionAccuracy scores each model's forecasts of the last days:
+// errors, bias, hit rate, Brier score and calibration by confidence decile
+func (s *predictionService) GetPredictionAccuracy(ctx context.Context, days int) (*models.PredictionAccuracy, error) {
+	since := time.Now().AddDate(0, 0, -days)
+	cfg := s.modelConfig()
+
+	byModel := make(map[string]*models.PredictionModelAccuracy)
+	entry := func(name string) *models.PredictionModelAccuracy {
+		if a, ok := byModel[name]; ok {
+			return a
+		}
+		a := &models.PredictionModelAccuracy{Model: name, Primary: name == cfg.Primary, Calibration: []models.PredictionCalibration{}}
+		for _, shadow := range cfg.Shadow {
+			a.Shadow = a.Shadow || shadow == name
+		}
+		byModel[name] = a
+		return a
+	}
+	entry(cfg.Primary)
+	for _, name := range cfg.Shadow {
+		entry(name)
+	}
+
+	rows, err := s.pg.Query(ctx, `
+		SELECT
+			model,
+			COUNT(*) FILTER (WHERE resolved_at IS NOT NULL),
+			COUNT(*) FILTER (WHERE resolved_at IS NULL),
+			COALESCE(AVG(ABS(actual_kills - predicted_kills)), 0)::float8,
+			COALESCE(AVG(ABS(actual_deaths - predicted_deaths)), 0)::float8,
+			COALESCE(AVG(predicted_kills - actual_kills), 0)::float8,
+			COALESCE(AVG(hit::int), 0)::float8,
+			COALESCE(AVG(confidence) FILTER (WHERE resolved_at IS NOT NULL), 0)::float8,
+			COALESCE(AVG(POWER(confidence - hit::int, 2)), 0)::float8
+		FROM predictions
+		WHERE predicted_at >= $1
+		GROUP BY model
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query prediction accuracy: %w", err)
+	}
+	for rows.Next() {
+		var name string
+		var a models.PredictionModelAccuracy
+		if err := rows.Scan(&name, &a.Resolved, &a.Pending, &a.KillsMAE, &a.DeathsMAE,
+			&a.KillsBias, &a.HitRate, &a.MeanConfidence, &a.BrierScore); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan prediction accuracy: %w", err)
+		}
+		e := entry(name)
+		a.Model, a.Primary, a.Shadow, a.Calibration = e.Model, e.Primary, e.Shadow, e.Calibration
+		*e = a
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query prediction accuracy: %w", err)
+	}
+
+	rows, err = s.pg.Query(ctx, `
+		SELECT
+			model,
+			LEAST(FLOOR(confidence * 10), 9)::int AS decile,
+			COUNT(*),
+			AVG(confidence)::float8,
+			AVG(hit::int)::float8
+		FROM predictions
+		WHERE resolved_at IS NOT NULL AND predicted_at >= $1
+		GROUP BY model, decile
+		ORDER BY model, decile
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query prediction calibration: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		var decile int
+		var c models.PredictionCalibration
+		if err := rows.Scan(&name, &decile, &c.Predictions, &c.MeanConfidence, &c.HitRate); err != nil {
+			return nil, fmt.Errorf("failed to scan prediction calibration: %w", err)
+		}
+		c.MinConfidence = float64(decile) / 10
+		e := entry(name)
+		e.Calibration = append(e.Calibration, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query prediction calibration: %w", err)
+	}
+
+	acc := &models.PredictionAccuracy{Days: days, Models: make([]models.PredictionModelAccuracy, 0, len(byModel))}
+	for _, a := range byModel {
+		acc.Models = append(acc.Models, *a)
+	}
+	// Primary first, then shadows, then retired models
+	sort.Slice(acc.Models, func(i, j int) bool {
+		a, b := acc.Models[i], acc.Models[j]
+		if a.Primary != b.Primary {
+			return a.Primary
+		}
+		if a.Shadow != b.Shadow {
+			return a.Shadow
+		}
+		return a.Model < b.Model
+	})
+	return acc, nil
+}
+
+// GetMatchPredictions is not tracked by GetPredictionAccuracy: it has no
+// model yet and returns a placeholder
 func (s *predictionService) GetMatchPredictions(ctx context.Context, matchID string) (*models.MatchPredictions, error) {
 	// For upcoming matches, matchID might be a placeholder or lobby ID
 	// For this MVP, we provide a placeholder response
@@ -0,0 +1,85 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+type privacyService struct {
+	pg PgPool
+}
+
+func NewPrivacyService(pg PgPool) PrivacyService {
+	return &privacyService{pg: pg}
+}
+
+// Get returns a forum account's privacy preferences; an account that never
+// set them gets the defaults, everything visible
+func (s *privacyService) Get(ctx context.Context, forumUserID int) (*models.PlayerPrivacy, error) {
+	var p models.PlayerPrivacy
+	err := s.pg.QueryRow(ctx, `
+		SELECT hide_profile, hide_from_leaderboards, anonymize_name, updated_at
+		FROM player_privacy WHERE forum_user_id = $1
+	`, forumUserID).Scan(&p.HideProfile, &p.HideFromLeaderboards, &p.AnonymizeName, &p.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &p, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read privacy settings: %w", err)
+	}
+	return &p, nil
+}
+
+// Set stores a forum account's privacy preferences and fills in UpdatedAt
+func (s *privacyService) Set(ctx context.Context, forumUserID int, p *models.PlayerPrivacy) error {
+	err := s.pg.QueryRow(ctx, `
+		INSERT INTO player_privacy (forum_user_id, hide_profile, hide_from_leaderboards, anonymize_name, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (forum_user_id) DO UPDATE SET
+			hide_profile = EXCLUDED.hide_profile,
+			hide_from_leaderboards = EXCLUDED.hide_from_leaderboards,
+			anonymize_name = EXCLUDED.anonymize_name,
+			updated_at = EXCLUDED.updated_at
+		RETURNING updated_at
+	`, forumUserID, p.HideProfile, p.HideFromLeaderboards, p.AnonymizeName).Scan(&p.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save privacy settings: %w", err)
+	}
+	return nil
+}
+
+// Visibility returns the preferences that apply to each of guids, through
+// the forum account the GUID is verified for. GUIDs without a verified
+// account or with everything visible are left out.
+func (s *privacyService) Visibility(ctx context.Context, guids []string) (map[string]models.PlayerPrivacy, error) {
+	visibility := make(map[string]models.PlayerPrivacy)
+	if len(guids) == 0 {
+		return visibility, nil
+	}
+	rows, err := s.pg.Query(ctx, `
+		SELECT i.player_guid, p.hide_profile, p.hide_from_leaderboards, p.anonymize_name, p.updated_at
+		FROM player_identities i
+		JOIN player_privacy p ON p.forum_user_id = i.forum_user_id
+		WHERE i.player_guid = ANY($1) AND i.verified
+			AND (p.hide_profile OR p.hide_from_leaderboards OR p.anonymize_name)
+	`, guids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read privacy settings: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var guid string
+		var p models.PlayerPrivacy
+		if err := rows.Scan(&guid, &p.HideProfile, &p.HideFromLeaderboards, &p.AnonymizeName, &p.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to read privacy settings: %w", err)
+		}
+		visibility[guid] = p
+	}
+	return visibility, rows.Err()
+}
@@ -51,7 +51,7 @@ func BuildStatsQuery(req DynamicQueryRequest) (string, []interface{}, error) {
 	case "headshots":
 		selectClause = "countIf(event_type IN ('player_kill', 'bot_killed') AND hitloc IN ('head', 'helmet'))"
 	case "accuracy": // Simplified accuracy (hits/shots) - careful with zero division
-		selectClause = "sumIf(1, event_type='weapon_hit') / max(1, sumIf(1, event_type='weapon_fire')) * 100"
+		selectClause = "sumIf(sample_weight, event_type='weapon_hit') / max(1, sumIf(sample_weight, event_type='weapon_fire')) * 100"
 	case "kdr":
 		// For global KDR: kills/kills = 1 (not useful)
 		// This metric is more meaningful for player-specific queries
@@ -0,0 +1,190 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// ErrQueryNotAllowed wraps every reason a sandbox query is rejected before it
+// reaches ClickHouse
+var ErrQueryNotAllowed = errors.New("query not allowed")
+
+// sandboxTables are the aggregate tables and views /admin/query may read.
+// raw_events, the position tables and v_match_summary (computed from
+// raw_events) are left out: they are large enough for a careless query to
+// hurt ingest.
+var sandboxTables = map[string]bool{
+	"player_stats_daily":        true,
+	"player_server_stats_daily": true,
+	"hitloc_stats_daily":        true,
+	"weapon_stats_mv":           true,
+	"map_stats_mv":              true,
+	"kill_heatmap_mv":           true,
+	"leaderboard_global":        true,
+	"player_name_history":       true,
+	"player_pings":              true,
+	"server_performance":        true,
+	"voided_matches":            true,
+	"v_player_daily":            true,
+	"v_weapon_daily":            true,
+}
+
+// SandboxTables lists the tables a sandbox query may read
+func SandboxTables() []string {
+	tables := make([]string, 0, len(sandboxTables))
+	for table := range sandboxTables {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+var (
+	sandboxStringLiteral = regexp.MustCompile(`'(?:[^'\\]|\\.|'')*'`)
+	sandboxForbiddenWord = regexp.MustCompile(`\b(insert|alter|drop|create|truncate|rename|attach|detach|optimize|system|kill|grant|revoke|set|settings|format|into|outfile|delete|update|exchange|use|show|describe|explain)\b`)
+	sandboxForbiddenFunc = regexp.MustCompile(`\b(url|file|remote|remotesecure|cluster|clusterallreplicas|s3|s3cluster|gcs|azureblobstorage|hdfs|mysql|postgresql|mongodb|redis|sqlite|jdbc|odbc|executable|input|merge|dictionary|dictget\w*|dicthas|joinget\w*|getsetting)\s*\(`)
+	sandboxTableRef      = regexp.MustCompile(`\b(array\s+)?(from|join|in)\s+([a-z_][a-z0-9_.]*|\()(\s*\()?`)
+	sandboxCommaJoin     = regexp.MustCompile(`\bfrom\s+[a-z_][a-z0-9_.]*(\s+(as\s+)?[a-z_][a-z0-9_]*)?\s*,`)
+	sandboxCTEName       = regexp.MustCompile(`\b([a-z_][a-z0-9_]*)\s+as\s*\(`)
+)
+
+// ValidateSandboxQuery accepts a single read-only SELECT (or WITH ... SELECT)
+// whose FROM, JOIN and IN targets are all sandbox tables or its own CTEs
+func ValidateSandboxQuery(sql string) error {
+	sql = strings.TrimSpace(sql)
+	sql = strings.TrimSpace(strings.TrimSuffix(sql, ";"))
+	if sql == "" {
+		return fmt.Errorf("%w: empty query", ErrQueryNotAllowed)
+	}
+	if strings.Contains(sql, ";") {
+		return fmt.Errorf("%w: only one statement is allowed", ErrQueryNotAllowed)
+	}
+	if strings.Contains(sql, "--") || strings.Contains(sql, "/*") || strings.Contains(sql, "#") {
+		return fmt.Errorf("%w: comments are not allowed", ErrQueryNotAllowed)
+	}
+
+	// Judge keywords and identifiers, not the contents of string literals
+	masked := strings.ToLower(sandboxStringLiteral.ReplaceAllString(sql, "''"))
+	if strings.Count(masked, "'")%2 != 0 {
+		return fmt.Errorf("%w: unterminated string literal", ErrQueryNotAllowed)
+	}
+	if strings.ContainsAny(masked, "\"`") {
+		return fmt.Errorf("%w: quoted identifiers are not allowed", ErrQueryNotAllowed)
+	}
+	if !strings.HasPrefix(masked, "select") && !strings.HasPrefix(masked, "with") {
+		return fmt.Errorf("%w: must start with SELECT or WITH", ErrQueryNotAllowed)
+	}
+	if m := sandboxForbiddenWord.FindString(masked); m != "" {
+		return fmt.Errorf("%w: %s is not allowed", ErrQueryNotAllowed, strings.ToUpper(m))
+	}
+	if m := sandboxForbiddenFunc.FindStringSubmatch(masked); m != nil {
+		return fmt.Errorf("%w: function %s is not allowed", ErrQueryNotAllowed, m[1])
+	}
+	if sandboxCommaJoin.MatchString(masked) {
+		return fmt.Errorf("%w: use JOIN instead of comma-separated tables", ErrQueryNotAllowed)
+	}
+
+	ctes := map[string]bool{}
+	for _, m := range sandboxCTEName.FindAllStringSubmatch(masked, -1) {
+		ctes[m[1]] = true
+	}
+	for _, m := range sandboxTableRef.FindAllStringSubmatch(masked, -1) {
+		arrayJoin, keyword, target, call := m[1] != "", m[2], m[3], m[4] != ""
+		if arrayJoin || target == "(" {
+			continue
+		}
+		if call {
+			if keyword == "in" {
+				continue // IN tuple(...) and the like
+			}
+			return fmt.Errorf("%w: table function %s is not allowed", ErrQueryNotAllowed, target)
+		}
+		table := strings.TrimPrefix(target, "mohaa_stats.")
+		if ctes[table] && !strings.Contains(target, ".") {
+			continue
+		}
+		if !sandboxTables[table] {
+			return fmt.Errorf("%w: table %s is not in the sandbox", ErrQueryNotAllowed, target)
+		}
+	}
+	return nil
+}
+
+type querySandboxService struct {
+	ch      driver.Conn
+	maxRows int
+	timeout time.Duration
+}
+
+// NewQuerySandboxService returns the /admin/query runner. Results are cut at
+// maxRows and queries are stopped by ClickHouse after timeout.
+func NewQuerySandboxService(ch driver.Conn, maxRows int, timeout time.Duration) QuerySandboxService {
+	return &querySandboxService{ch: ch, maxRows: maxRows, timeout: timeout}
+}
+
+// Run validates sql and executes it read-only with row and time limits
+func (s *querySandboxService) Run(ctx context.Context, sql string) (*models.SandboxQueryResult, error) {
+	if err := ValidateSandboxQuery(sql); err != nil {
+		return nil, err
+	}
+	sql = strings.TrimSuffix(strings.TrimSpace(sql), ";")
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout+time.Second)
+	defer cancel()
+	// readonly=2 still lets these settings apply but rejects any write
+	ctx = clickhouse.Context(ctx, clickhouse.WithSettings(clickhouse.Settings{
+		"readonly":             2,
+		"max_execution_time":   int(s.timeout.Seconds()),
+		"max_result_rows":      s.maxRows + 1,
+		"result_overflow_mode": "break",
+	}))
+
+	started := time.Now()
+	rows, err := s.ch.Query(ctx, fmt.Sprintf("SELECT * FROM (%s) LIMIT %d", sql, s.maxRows+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to run sandbox query: %w", err)
+	}
+	defer rows.Close()
+
+	result := &models.SandboxQueryResult{
+		Columns: rows.Columns(),
+		Rows:    [][]interface{}{},
+		MaxRows: s.maxRows,
+	}
+	types := rows.ColumnTypes()
+	for rows.Next() {
+		if len(result.Rows) == s.maxRows {
+			result.Truncated = true
+			break
+		}
+		dest := make([]interface{}, len(types))
+		for i, t := range types {
+			dest[i] = reflect.New(t.ScanType()).Interface()
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan sandbox query: %w", err)
+		}
+		row := make([]interface{}, len(dest))
+		for i, d := range dest {
+			row[i] = reflect.ValueOf(d).Elem().Interface()
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to run sandbox query: %w", err)
+	}
+	result.RowCount = len(result.Rows)
+	result.ElapsedMS = time.Since(started).Milliseconds()
+	return result, nil
+}
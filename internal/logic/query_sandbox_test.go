@@ -0,0 +1,45 @@
+package logic
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateSandboxQuery(t *testing.T) {
+	allowed := []string{
+		"SELECT count() FROM player_stats_daily",
+		"select player_id, sum(kills) from mohaa_stats.player_stats_daily group by player_id order by 2 desc limit 10;",
+		"WITH top AS (SELECT player_id FROM player_stats_daily) SELECT * FROM top JOIN player_name_history n ON n.player_id = top.player_id",
+		"SELECT * FROM (SELECT map_name FROM map_stats_mv) WHERE map_name IN ('obj/obj_team2', 'drop table')",
+		"SELECT weapon, arr FROM weapon_stats_mv ARRAY JOIN [1, 2] AS arr",
+	}
+	for _, sql := range allowed {
+		if err := ValidateSandboxQuery(sql); err != nil {
+			t.Errorf("ValidateSandboxQuery(%q) = %v, want nil", sql, err)
+		}
+	}
+
+	rejected := []string{
+		"",
+		"DROP TABLE player_stats_daily",
+		"SELECT 1; DROP TABLE player_stats_daily",
+		"SELECT * FROM raw_events",
+		"SELECT * FROM system.tables",
+		"SELECT * FROM other_db.player_stats_daily",
+		"SELECT * FROM url('http://example.com', CSV)",
+		"SELECT * FROM player_stats_daily, raw_events",
+		"SELECT * FROM player_stats_daily WHERE player_id IN raw_events",
+		"SELECT * FROM player_stats_daily -- comment",
+		"SELECT * FROM player_stats_daily SETTINGS max_threads = 64",
+		"SELECT * FROM player_stats_daily INTO OUTFILE '/tmp/x'",
+		"SELECT dictGet('d', 'x', 1) FROM player_stats_daily",
+		"SELECT * FROM \"raw_events\"",
+		"SELECT 'unterminated FROM player_stats_daily",
+		"INSERT INTO player_stats_daily SELECT * FROM player_stats_daily",
+	}
+	for _, sql := range rejected {
+		if err := ValidateSandboxQuery(sql); !errors.Is(err, ErrQueryNotAllowed) {
+			t.Errorf("ValidateSandboxQuery(%q) = %v, want ErrQueryNotAllowed", sql, err)
+		}
+	}
+}
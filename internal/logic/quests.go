@@ -0,0 +1,153 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// QuestWindow is how long a new player has to finish a quest chain
+const QuestWindow = 14 * 24 * time.Hour
+
+// ErrNotEnrolled is returned for a player who is not on a quest chain
+var ErrNotEnrolled = errors.New("not enrolled in quest chain")
+
+// QuestChain returns a chain's steps in order
+func (s *challengesService) QuestChain(ctx context.Context, chain string) ([]models.Challenge, error) {
+	return s.queryChallenges(ctx, `
+		SELECT c.code, c.title, c.description, c.metric, c.weapons, c.map_prefix, c.target, TRUE
+		FROM quest_steps c
+		WHERE c.chain = $1
+		ORDER BY c.position
+	`, chain)
+}
+
+// Enroll puts a GUID on a quest chain if it is new to the network: ClickHouse
+// has no stats for it before today. It reports whether the GUID is enrolled,
+// now or from before.
+func (s *challengesService) Enroll(ctx context.Context, guid, chain string, now time.Time) (bool, error) {
+	var enrolled bool
+	if err := s.pg.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM quest_enrollments WHERE player_guid = $1 AND chain = $2)`,
+		guid, chain).Scan(&enrolled); err != nil {
+		return false, fmt.Errorf("failed to read quest enrollment: %w", err)
+	}
+	if enrolled {
+		return true, nil
+	}
+
+	var days uint64
+	if err := s.ch.QueryRow(ctx, `
+		SELECT count() FROM `+PlayerStatsTable(ctx)+`
+		WHERE player_id = ? AND day < toDate(?)
+	`, guid, now.UTC()).Scan(&days); err != nil {
+		return false, fmt.Errorf("failed to read player history: %w", err)
+	}
+	if days > 0 {
+		return false, nil
+	}
+
+	if _, err := s.pg.Exec(ctx, `
+		INSERT INTO quest_enrollments (player_guid, chain, enrolled_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (player_guid, chain) DO NOTHING
+	`, guid, chain, now); err != nil {
+		return false, fmt.Errorf("failed to enroll player: %w", err)
+	}
+	return true, nil
+}
+
+// Enrolled returns the GUIDs still working on a chain within QuestWindow
+func (s *challengesService) Enrolled(ctx context.Context, chain string, now time.Time) ([]string, error) {
+	rows, err := s.pg.Query(ctx, `
+		SELECT player_guid FROM quest_enrollments
+		WHERE chain = $1 AND completed_at IS NULL AND enrolled_at > $2
+	`, chain, now.Add(-QuestWindow))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read quest enrollments: %w", err)
+	}
+	defer rows.Close()
+
+	var guids []string
+	for rows.Next() {
+		var guid string
+		if err := rows.Scan(&guid); err != nil {
+			return nil, fmt.Errorf("failed to read quest enrollments: %w", err)
+		}
+		guids = append(guids, guid)
+	}
+	return guids, rows.Err()
+}
+
+// CompleteQuestStep records a finished step and, when it was the last one,
+// the finished chain. It reports whether the chain is now complete.
+func (s *challengesService) CompleteQuestStep(ctx context.Context, guid, chain, code string, at time.Time) (bool, error) {
+	tag, err := s.pg.Exec(ctx, `
+		INSERT INTO quest_step_completions (player_guid, chain, code, completed_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (player_guid, chain, code) DO NOTHING
+	`, guid, chain, code, at)
+	if err != nil {
+		return false, fmt.Errorf("failed to store quest step: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	tag, err = s.pg.Exec(ctx, `
+		UPDATE quest_enrollments SET completed_at = $3
+		WHERE player_guid = $1 AND chain = $2 AND completed_at IS NULL
+			AND NOT EXISTS (
+				SELECT 1 FROM quest_steps q
+				WHERE q.chain = $2 AND q.code NOT IN (
+					SELECT code FROM quest_step_completions WHERE player_guid = $1 AND chain = $2
+				)
+			)
+	`, guid, chain, at)
+	if err != nil {
+		return false, fmt.Errorf("failed to complete quest chain: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// QuestEnrollment returns a player's enrollment on a chain and the steps
+// they finished
+func (s *challengesService) QuestEnrollment(ctx context.Context, guid, chain string) (*models.PlayerQuest, map[string]time.Time, error) {
+	q := &models.PlayerQuest{PlayerGUID: guid, Chain: chain}
+	err := s.pg.QueryRow(ctx, `
+		SELECT enrolled_at, completed_at FROM quest_enrollments
+		WHERE player_guid = $1 AND chain = $2
+	`, guid, chain).Scan(&q.EnrolledAt, &q.CompletedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, ErrNotEnrolled
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read quest enrollment: %w", err)
+	}
+	q.ExpiresAt = q.EnrolledAt.Add(QuestWindow)
+
+	rows, err := s.pg.Query(ctx, `
+		SELECT code, completed_at FROM quest_step_completions
+		WHERE player_guid = $1 AND chain = $2
+	`, guid, chain)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read quest steps: %w", err)
+	}
+	defer rows.Close()
+
+	done := make(map[string]time.Time)
+	for rows.Next() {
+		var code string
+		var at time.Time
+		if err := rows.Scan(&code, &at); err != nil {
+			return nil, nil, fmt.Errorf("failed to read quest steps: %w", err)
+		}
+		done[code] = at
+	}
+	return q, done, rows.Err()
+}
@@ -0,0 +1,265 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+const (
+	// ratingStart is every player's first rating, and the rating the
+	// adjusted kills and deaths are weighed against
+	ratingStart = 1500
+	// ratingK is the most one kill moves the killer and victim. Kills are
+	// far more frequent than matches, so it is small.
+	ratingK = 8
+	// AdjustedKDMinEngagements is the kills plus deaths a player needs to
+	// appear on the adjusted K/D leaderboard
+	AdjustedKDMinEngagements = 100
+)
+
+type ratingService struct {
+	pg PgPool
+}
+
+func NewRatingService(pg PgPool) RatingService {
+	return &ratingService{pg: pg}
+}
+
+// ratedKill is a player kill between two players
+type ratedKill struct {
+	killer, victim         string
+	killerName, victimName string
+}
+
+// ratingChange is what one batch adds to a player's rating row
+type ratingChange struct {
+	name           string
+	delta          float64
+	kills, deaths  int64
+	adjustedKills  float64
+	adjustedDeaths float64
+}
+
+// ratedKills picks the kills that count: between two players, neither a
+// suicide nor a team kill
+func ratedKills(events []*models.RawEvent) []ratedKill {
+	var kills []ratedKill
+	for _, e := range events {
+		if e.Type != models.EventPlayerKill || e.AttackerGUID == "" || e.VictimGUID == "" || e.AttackerGUID == e.VictimGUID {
+			continue
+		}
+		if e.AttackerTeam != "" && e.AttackerTeam == e.VictimTeam && e.AttackerTeam != "freeforall" {
+			continue
+		}
+		kills = append(kills, ratedKill{
+			killer: e.AttackerGUID, victim: e.VictimGUID,
+			killerName: e.AttackerName, victimName: e.VictimName,
+		})
+	}
+	return kills
+}
+
+// rateKills applies the kills in order, each an Elo game the killer won,
+// weighing the adjusted kill and death by the ratings before it. ratings
+// holds the players' current ratings (missing ones start at ratingStart) and
+// is updated in place.
+func rateKills(ratings map[string]float64, kills []ratedKill) map[string]*ratingChange {
+	changes := make(map[string]*ratingChange)
+	change := func(guid, name string) *ratingChange {
+		c, ok := changes[guid]
+		if !ok {
+			c = &ratingChange{}
+			changes[guid] = c
+		}
+		if name != "" {
+			c.name = name
+		}
+		return c
+	}
+	rating := func(guid string) float64 {
+		if r, ok := ratings[guid]; ok {
+			return r
+		}
+		return ratingStart
+	}
+
+	for _, k := range kills {
+		killerRating, victimRating := rating(k.killer), rating(k.victim)
+		expected := 1 / (1 + math.Pow(10, (victimRating-killerRating)/400))
+		delta := ratingK * (1 - expected)
+
+		killer := change(k.killer, k.killerName)
+		killer.delta += delta
+		killer.kills++
+		killer.adjustedKills += victimRating / ratingStart
+
+		victim := change(k.victim, k.victimName)
+		victim.delta -= delta
+		victim.deaths++
+		victim.adjustedDeaths += ratingStart / killerRating
+
+		ratings[k.killer] = killerRating + delta
+		ratings[k.victim] = victimRating - delta
+	}
+	return changes
+}
+
+// ApplyKills rates the player kills of a batch of events. Ratings are read
+// once per batch and the changes added to whatever is stored, so batches of
+// other workers in between are not lost, only rated from slightly older
+// ratings.
+func (s *ratingService) ApplyKills(ctx context.Context, events []*models.RawEvent) error {
+	kills := ratedKills(events)
+	if len(kills) == 0 {
+		return nil
+	}
+
+	guids := make([]string, 0, 2*len(kills))
+	seen := make(map[string]bool)
+	for _, k := range kills {
+		for _, guid := range []string{k.killer, k.victim} {
+			if !seen[guid] {
+				seen[guid] = true
+				guids = append(guids, guid)
+			}
+		}
+	}
+	rows, err := s.pg.Query(ctx, `
+		SELECT player_guid, rating FROM player_ratings WHERE player_guid = ANY($1)
+	`, guids)
+	if err != nil {
+		return fmt.Errorf("failed to read ratings: %w", err)
+	}
+	ratings := make(map[string]float64, len(guids))
+	for rows.Next() {
+		var guid string
+		var rating float64
+		if err := rows.Scan(&guid, &rating); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan ratings: %w", err)
+		}
+		ratings[guid] = rating
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read ratings: %w", err)
+	}
+
+	changes := rateKills(ratings, kills)
+	names := make([]string, 0, len(changes))
+	deltas := make([]float64, 0, len(changes))
+	killCounts := make([]int64, 0, len(changes))
+	deathCounts := make([]int64, 0, len(changes))
+	adjustedKills := make([]float64, 0, len(changes))
+	adjustedDeaths := make([]float64, 0, len(changes))
+	players := make([]string, 0, len(changes))
+	for guid, c := range changes {
+		players = append(players, guid)
+		names = append(names, c.name)
+		deltas = append(deltas, c.delta)
+		killCounts = append(killCounts, c.kills)
+		deathCounts = append(deathCounts, c.deaths)
+		adjustedKills = append(adjustedKills, c.adjustedKills)
+		adjustedDeaths = append(adjustedDeaths, c.adjustedDeaths)
+	}
+
+	if _, err := s.pg.Exec(ctx, `
+		INSERT INTO player_ratings (player_guid, player_name, rating, kills, deaths, adjusted_kills, adjusted_deaths)
+		SELECT g, n, $8 + d, k, dd, ak, ad
+		FROM unnest($1::text[], $2::text[], $3::float8[], $4::bigint[], $5::bigint[], $6::float8[], $7::float8[])
+			AS u(g, n, d, k, dd, ak, ad)
+		ON CONFLICT (player_guid) DO UPDATE SET
+			player_name = COALESCE(NULLIF(EXCLUDED.player_name, ''), player_ratings.player_name),
+			rating = player_ratings.rating + EXCLUDED.rating - $8,
+			kills = player_ratings.kills + EXCLUDED.kills,
+			deaths = player_ratings.deaths + EXCLUDED.deaths,
+			adjusted_kills = player_ratings.adjusted_kills + EXCLUDED.adjusted_kills,
+			adjusted_deaths = player_ratings.adjusted_deaths + EXCLUDED.adjusted_deaths,
+			updated_at = NOW()
+	`, players, names, deltas, killCounts, deathCounts, adjustedKills, adjustedDeaths, float64(ratingStart)); err != nil {
+		return fmt.Errorf("failed to store ratings: %w", err)
+	}
+	return nil
+}
+
+// Get returns a player's rating, or nil for players without rated kills or
+// deaths
+func (s *ratingService) Get(ctx context.Context, guid string) (*models.PlayerRating, error) {
+	var r models.PlayerRating
+	err := s.pg.QueryRow(ctx, `
+		SELECT rating, kills, deaths, adjusted_kills, adjusted_deaths, updated_at
+		FROM player_ratings
+		WHERE player_guid = $1
+	`, guid).Scan(&r.Rating, &r.Kills, &r.Deaths, &r.AdjustedKills, &r.AdjustedDeaths, &r.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rating: %w", err)
+	}
+	r.AdjustedKD = adjustedKD(r.AdjustedKills, r.AdjustedDeaths)
+	return &r, nil
+}
+
+// Rank returns a player's position by rating, or nil for unrated players.
+// Tied players share a position.
+func (s *ratingService) Rank(ctx context.Context, guid string) (*models.PlayerRank, error) {
+	var r models.PlayerRank
+	err := s.pg.QueryRow(ctx, `
+		SELECT (SELECT count(*) FROM player_ratings WHERE rating > p.rating) + 1,
+		       (SELECT count(*) FROM player_ratings),
+		       p.rating
+		FROM player_ratings p
+		WHERE p.player_guid = $1
+	`, guid).Scan(&r.Position, &r.Of, &r.Rating)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rating rank: %w", err)
+	}
+	return &r, nil
+}
+
+// AdjustedKDLeaderboard ranks the players with at least
+// AdjustedKDMinEngagements rated kills and deaths by adjusted K/D
+func (s *ratingService) AdjustedKDLeaderboard(ctx context.Context, limit int) ([]models.AdjustedKDEntry, error) {
+	rows, err := s.pg.Query(ctx, `
+		SELECT player_guid, player_name, rating, kills, deaths, adjusted_kills, adjusted_deaths
+		FROM player_ratings
+		WHERE kills + deaths >= $1
+		ORDER BY CASE WHEN adjusted_deaths > 0 THEN adjusted_kills / adjusted_deaths ELSE adjusted_kills END DESC
+		LIMIT $2
+	`, AdjustedKDMinEngagements, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query adjusted K/D leaderboard: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]models.AdjustedKDEntry, 0, limit)
+	for rows.Next() {
+		var e models.AdjustedKDEntry
+		var adjustedKills, adjustedDeaths float64
+		if err := rows.Scan(&e.PlayerID, &e.PlayerName, &e.Rating, &e.Kills, &e.Deaths, &adjustedKills, &adjustedDeaths); err != nil {
+			return nil, fmt.Errorf("failed to scan adjusted K/D leaderboard: %w", err)
+		}
+		e.Rank = len(entries) + 1
+		e.KDRatio = kdRatio(uint64(e.Kills), uint64(e.Deaths))
+		e.AdjustedKD = adjustedKD(adjustedKills, adjustedDeaths)
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func adjustedKD(kills, deaths float64) float64 {
+	if deaths == 0 {
+		return kills
+	}
+	return kills / deaths
+}
@@ -0,0 +1,49 @@
+package logic
+
+import (
+	"math"
+	"testing"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestRatedKills(t *testing.T) {
+	events := []*models.RawEvent{
+		{Type: models.EventPlayerKill, AttackerGUID: "a", VictimGUID: "b", AttackerTeam: "allies", VictimTeam: "axis"},
+		{Type: models.EventPlayerKill, AttackerGUID: "a", VictimGUID: "c", AttackerTeam: "allies", VictimTeam: "allies"},
+		{Type: models.EventPlayerKill, AttackerGUID: "a", VictimGUID: "a"},
+		{Type: models.EventPlayerKill, AttackerGUID: "a", VictimGUID: "c", AttackerTeam: "freeforall", VictimTeam: "freeforall"},
+		{Type: models.EventBotKilled, AttackerGUID: "a", VictimGUID: "bot"},
+		{Type: models.EventPlayerKill, AttackerGUID: "", VictimGUID: "b"},
+	}
+	kills := ratedKills(events)
+	if len(kills) != 2 || kills[0].victim != "b" || kills[1].victim != "c" {
+		t.Errorf("rated kills = %+v, want the kills of b and c in free-for-all", kills)
+	}
+}
+
+func TestRateKills(t *testing.T) {
+	ratings := map[string]float64{"strong": 1700}
+	changes := rateKills(ratings, []ratedKill{
+		{killer: "new", victim: "strong"},
+		{killer: "strong", victim: "new"},
+	})
+	newcomer, strong := changes["new"], changes["strong"]
+
+	// The upset moves 8 * (1 - 0.24) = 6.08, the expected kill back only 2.03
+	if math.Abs(ratings["new"]-1504.05) > 0.01 || math.Abs(ratings["strong"]-1695.95) > 0.01 {
+		t.Errorf("ratings = %v, want new 1504.05 and strong 1695.95", ratings)
+	}
+	if newcomer.kills != 1 || newcomer.deaths != 1 || newcomer.delta+strong.delta != 0 {
+		t.Errorf("changes = %+v, %+v; want one kill and death each, summing to zero", newcomer, strong)
+	}
+
+	// Weighed by the ratings before each kill: 1700 for the newcomer's kill,
+	// 1693.92 for their death
+	if math.Abs(newcomer.adjustedKills-1700.0/1500) > 1e-9 || math.Abs(newcomer.adjustedDeaths-0.8855) > 0.0001 {
+		t.Errorf("newcomer adjusted kills %v, deaths %v; want 1.1333, 0.8855", newcomer.adjustedKills, newcomer.adjustedDeaths)
+	}
+	if math.Abs(strong.adjustedDeaths-1) > 1e-9 || math.Abs(strong.adjustedKills-1.0041) > 0.0001 {
+		t.Errorf("strong adjusted kills %v, deaths %v; want 1.0041, 1", strong.adjustedKills, strong.adjustedDeaths)
+	}
+}
@@ -0,0 +1,277 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+var (
+	// ErrInvalidReport is returned for a report or triage that cannot be stored
+	ErrInvalidReport = errors.New("invalid player report")
+	// ErrReportNotFound is returned for an unknown report ID
+	ErrReportNotFound = errors.New("player report not found")
+	// ErrReportExists is returned when the user already has an open report
+	// against the player for the same reason
+	ErrReportExists = errors.New("player already reported for this reason")
+	// ErrTooManyReports is returned when a user already has maxOpenReports
+	// reports waiting for triage
+	ErrTooManyReports = errors.New("too many open reports")
+)
+
+const (
+	// maxOpenReports is how many open reports a user may have
+	maxOpenReports = 20
+	// maxReportDetails bounds the details of a report, in characters
+	maxReportDetails = 1000
+)
+
+// ValidateReport checks a report before it is filed and trims its fields.
+// The match is optional; when given the player must have played in it.
+func ValidateReport(req *models.PlayerReportRequest) error {
+	req.PlayerGUID = strings.TrimSpace(req.PlayerGUID)
+	req.PlayerName = strings.TrimSpace(req.PlayerName)
+	req.Details = strings.TrimSpace(req.Details)
+	req.MatchID = strings.ToLower(strings.TrimSpace(req.MatchID))
+
+	if req.PlayerGUID == "" || len(req.PlayerGUID) > 64 || req.PlayerGUID == "world" {
+		return fmt.Errorf("%w: player_guid must be a player GUID", ErrInvalidReport)
+	}
+	if utf8.RuneCountInString(req.PlayerName) > 64 {
+		return fmt.Errorf("%w: player_name is at most 64 characters", ErrInvalidReport)
+	}
+	switch req.Reason {
+	case models.ReportCheating, models.ReportAbusiveName, models.ReportAbusiveChat, models.ReportGriefing, models.ReportOther:
+	default:
+		return fmt.Errorf("%w: reason must be cheating, abusive_name, abusive_chat, griefing or other", ErrInvalidReport)
+	}
+	if req.Reason == models.ReportOther && req.Details == "" {
+		return fmt.Errorf("%w: details are required for reason other", ErrInvalidReport)
+	}
+	if utf8.RuneCountInString(req.Details) > maxReportDetails {
+		return fmt.Errorf("%w: details are at most %d characters", ErrInvalidReport, maxReportDetails)
+	}
+	if req.MatchID != "" {
+		if _, err := uuid.Parse(req.MatchID); err != nil {
+			return fmt.Errorf("%w: match_id must be a match UUID", ErrInvalidReport)
+		}
+	}
+	return nil
+}
+
+// ValidateReportTriage checks a triage before it is applied and trims its
+// fields
+func ValidateReportTriage(req *models.ReportTriageRequest) error {
+	req.Note = strings.TrimSpace(req.Note)
+	switch req.Status {
+	case models.ReportOpen, models.ReportReviewing, models.ReportActioned, models.ReportDismissed:
+	default:
+		return fmt.Errorf("%w: status must be open, reviewing, actioned or dismissed", ErrInvalidReport)
+	}
+	if req.AnticheatFlag != nil {
+		flag := strings.TrimSpace(*req.AnticheatFlag)
+		if len(flag) > 128 {
+			return fmt.Errorf("%w: anticheat_flag is at most 128 characters", ErrInvalidReport)
+		}
+		req.AnticheatFlag = &flag
+	}
+	return nil
+}
+
+type reportService struct {
+	pg PgPool
+	ch driver.Conn
+}
+
+func NewReportService(pg PgPool, ch driver.Conn) ReportService {
+	return &reportService{pg: pg, ch: ch}
+}
+
+const reportColumns = `id, player_guid, player_name, reason, details, match_id, reported_by, status,
+	anticheat_flag, review_note, reviewed_by, reviewed_at, created_at`
+
+func scanReport(row pgx.Row, extra ...any) (*models.PlayerReport, error) {
+	r := &models.PlayerReport{}
+	dest := []any{&r.ID, &r.PlayerGUID, &r.PlayerName, &r.Reason, &r.Details, &r.MatchID, &r.ReportedBy, &r.Status,
+		&r.AnticheatFlag, &r.ReviewNote, &r.ReviewedBy, &r.ReviewedAt, &r.CreatedAt}
+	err := row.Scan(append(dest, extra...)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrReportNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read player report: %w", err)
+	}
+	return r, nil
+}
+
+// Submit files a user's report. With a match, the player must have played
+// in it, and their name there is used when none is given.
+func (s *reportService) Submit(ctx context.Context, forumUserID int, req *models.PlayerReportRequest) (*models.PlayerReport, error) {
+	if err := ValidateReport(req); err != nil {
+		return nil, err
+	}
+
+	if req.MatchID != "" {
+		var events, played uint64
+		var name string
+		if err := s.ch.QueryRow(ctx, `
+			SELECT count(), countIf(actor_id = ? OR target_id = ?), anyIf(actor_name, actor_id = ? AND actor_name != '')
+			FROM mohaa_stats.raw_events WHERE match_id = toUUID(?)
+		`, req.PlayerGUID, req.PlayerGUID, req.PlayerGUID, req.MatchID).Scan(&events, &played, &name); err != nil {
+			return nil, fmt.Errorf("failed to read match: %w", err)
+		}
+		if events == 0 {
+			return nil, ErrMatchNotFound
+		}
+		if played == 0 {
+			return nil, fmt.Errorf("%w: the player did not play in that match", ErrInvalidReport)
+		}
+		if req.PlayerName == "" {
+			req.PlayerName = name
+		}
+	}
+
+	// As with match media, the insert checks the open report limit itself
+	r, err := scanReport(s.pg.QueryRow(ctx, `
+		INSERT INTO player_reports (player_guid, player_name, reason, details, match_id, reported_by)
+		SELECT $1, $2, $3, $4, $5, $6
+		WHERE (SELECT count(*) FROM player_reports WHERE reported_by = $6 AND status IN ('open', 'reviewing')) < $7
+		ON CONFLICT (reported_by, player_guid, reason) WHERE status IN ('open', 'reviewing') DO NOTHING
+		RETURNING `+reportColumns,
+		req.PlayerGUID, req.PlayerName, req.Reason, req.Details, req.MatchID, forumUserID, maxOpenReports))
+	if !errors.Is(err, ErrReportNotFound) {
+		return r, err
+	}
+
+	// Nothing inserted: tell a duplicate from too many open reports
+	var exists bool
+	if err := s.pg.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM player_reports
+			WHERE reported_by = $1 AND player_guid = $2 AND reason = $3 AND status IN ('open', 'reviewing')
+		)
+	`, forumUserID, req.PlayerGUID, req.Reason).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to read player reports: %w", err)
+	}
+	if exists {
+		return nil, ErrReportExists
+	}
+	return nil, ErrTooManyReports
+}
+
+// ForUser returns the reports a user filed, newest first
+func (s *reportService) ForUser(ctx context.Context, forumUserID int) ([]models.PlayerReport, error) {
+	rows, err := s.pg.Query(ctx, `
+		SELECT `+reportColumns+` FROM player_reports
+		WHERE reported_by = $1
+		ORDER BY id DESC
+		LIMIT 100
+	`, forumUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list player reports: %w", err)
+	}
+	defer rows.Close()
+
+	reports := []models.PlayerReport{}
+	for rows.Next() {
+		r, err := scanReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		// Triage is for admins; reporters see the outcome
+		r.ReviewNote, r.ReviewedBy, r.AnticheatFlag = "", "", ""
+		reports = append(reports, *r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list player reports: %w", err)
+	}
+	return reports, nil
+}
+
+// Queue returns the reports matching a filter with the open report count of
+// each player; open reports oldest first so the queue is worked in order,
+// the rest newest first
+func (s *reportService) Queue(ctx context.Context, f models.ReportFilter) ([]models.PlayerReport, error) {
+	order := "DESC"
+	if f.Status == models.ReportOpen {
+		order = "ASC"
+	}
+	rows, err := s.pg.Query(ctx, `
+		SELECT `+reportColumns+`,
+			(SELECT count(*) FROM player_reports o
+			 WHERE o.player_guid = r.player_guid AND o.status IN ('open', 'reviewing'))
+		FROM player_reports r
+		WHERE ($1 = '' OR status = $1)
+		  AND ($2 = '' OR player_guid = $2)
+		  AND ($3 = '' OR anticheat_flag = $3)
+		ORDER BY id `+order+`
+		LIMIT $4
+	`, f.Status, f.PlayerGUID, f.AnticheatFlag, f.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list player reports: %w", err)
+	}
+	defer rows.Close()
+
+	reports := []models.PlayerReport{}
+	for rows.Next() {
+		var open int
+		r, err := scanReport(rows, &open)
+		if err != nil {
+			return nil, err
+		}
+		r.PlayerOpenReports = open
+		reports = append(reports, *r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list player reports: %w", err)
+	}
+	return reports, nil
+}
+
+// Get returns a report
+func (s *reportService) Get(ctx context.Context, id int64) (*models.PlayerReport, error) {
+	return scanReport(s.pg.QueryRow(ctx, `
+		SELECT `+reportColumns+` FROM player_reports WHERE id = $1
+	`, id))
+}
+
+// Triage moves a report to a status, notes why and links or unlinks its
+// anticheat flag. Reopening a report is refused while the reporter has
+// another open report against the player for the same reason.
+func (s *reportService) Triage(ctx context.Context, id int64, req *models.ReportTriageRequest, reviewer string) (*models.PlayerReport, error) {
+	if err := ValidateReportTriage(req); err != nil {
+		return nil, err
+	}
+	r, err := scanReport(s.pg.QueryRow(ctx, `
+		UPDATE player_reports r
+		SET status = $2,
+			review_note = COALESCE(NULLIF($3, ''), review_note),
+			anticheat_flag = COALESCE($4, anticheat_flag),
+			reviewed_by = $5,
+			reviewed_at = NOW()
+		WHERE id = $1
+		  AND ($2 NOT IN ('open', 'reviewing') OR r.status IN ('open', 'reviewing') OR NOT EXISTS (
+			SELECT 1 FROM player_reports o
+			WHERE o.reported_by = r.reported_by AND o.player_guid = r.player_guid AND o.reason = r.reason
+			  AND o.id <> r.id AND o.status IN ('open', 'reviewing')
+		  ))
+		RETURNING `+reportColumns,
+		id, req.Status, req.Note, req.AnticheatFlag, reviewer))
+	if !errors.Is(err, ErrReportNotFound) {
+		return r, err
+	}
+
+	// Nothing updated: tell an unknown report from a refused reopen
+	if _, err := s.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("%w: the reporter has another open report like it", ErrReportExists)
+}
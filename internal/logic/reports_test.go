@@ -0,0 +1,67 @@
+package logic
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestValidateReport(t *testing.T) {
+	const matchID = "6F1C2A4E-8B3D-4C5E-9F60-7A8B9C0D1E2F"
+	tests := []struct {
+		name  string
+		req   models.PlayerReportRequest
+		valid bool
+	}{
+		{"Cheating", models.PlayerReportRequest{PlayerGUID: "guid-1", Reason: models.ReportCheating}, true},
+		{"With Match", models.PlayerReportRequest{PlayerGUID: " guid-1 ", Reason: models.ReportAbusiveName, MatchID: matchID}, true},
+		{"Other With Details", models.PlayerReportRequest{PlayerGUID: "guid-1", Reason: models.ReportOther, Details: "spawn camping with a glitch"}, true},
+		{"No Player", models.PlayerReportRequest{Reason: models.ReportCheating}, false},
+		{"World", models.PlayerReportRequest{PlayerGUID: "world", Reason: models.ReportGriefing}, false},
+		{"Unknown Reason", models.PlayerReportRequest{PlayerGUID: "guid-1", Reason: "camping"}, false},
+		{"Other Without Details", models.PlayerReportRequest{PlayerGUID: "guid-1", Reason: models.ReportOther}, false},
+		{"Long Details", models.PlayerReportRequest{PlayerGUID: "guid-1", Reason: models.ReportCheating, Details: strings.Repeat("x", 1001)}, false},
+		{"Bad Match", models.PlayerReportRequest{PlayerGUID: "guid-1", Reason: models.ReportCheating, MatchID: "last-night"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			err := ValidateReport(&req)
+			if tt.valid && err != nil {
+				t.Errorf("rejected: %v", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidReport) {
+				t.Errorf("err = %v, want ErrInvalidReport", err)
+			}
+		})
+	}
+
+	req := models.PlayerReportRequest{PlayerGUID: " guid-1 ", Reason: models.ReportCheating, MatchID: " " + matchID + " "}
+	if err := ValidateReport(&req); err != nil {
+		t.Fatal(err)
+	}
+	if req.PlayerGUID != "guid-1" || req.MatchID != strings.ToLower(matchID) {
+		t.Errorf("normalized to %q, %q", req.PlayerGUID, req.MatchID)
+	}
+}
+
+func TestValidateReportTriage(t *testing.T) {
+	flag := "  ac-4412 "
+	req := models.ReportTriageRequest{Status: models.ReportActioned, Note: " banned ", AnticheatFlag: &flag}
+	if err := ValidateReportTriage(&req); err != nil {
+		t.Fatal(err)
+	}
+	if req.Note != "banned" || *req.AnticheatFlag != "ac-4412" {
+		t.Errorf("normalized to %q, %q", req.Note, *req.AnticheatFlag)
+	}
+
+	if err := ValidateReportTriage(&models.ReportTriageRequest{Status: "closed"}); !errors.Is(err, ErrInvalidReport) {
+		t.Errorf("unknown status: err = %v", err)
+	}
+	long := strings.Repeat("f", 129)
+	if err := ValidateReportTriage(&models.ReportTriageRequest{Status: models.ReportReviewing, AnticheatFlag: &long}); !errors.Is(err, ErrInvalidReport) {
+		t.Errorf("long flag: err = %v", err)
+	}
+}
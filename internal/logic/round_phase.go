@@ -0,0 +1,24 @@
+package logic
+
+import "context"
+
+type allPhasesKey struct{}
+
+// WithAllRoundPhases makes raw event queries on ctx include events from
+// warmup and intermission, which are left out by default
+func WithAllRoundPhases(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allPhasesKey{}, true)
+}
+
+// RoundPhaseFilter returns a WHERE fragment keeping in-round events of the
+// table with the given alias ("" for none), or "" if ctx asks for all phases.
+// The player aggregates never count other phases.
+func RoundPhaseFilter(ctx context.Context, alias string) string {
+	if all, _ := ctx.Value(allPhasesKey{}).(bool); all {
+		return ""
+	}
+	if alias != "" {
+		alias += "."
+	}
+	return " AND " + alias + "round_phase = 'round'"
+}
@@ -0,0 +1,182 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// Server browser sort orders
+const (
+	BrowserSortPlayers = "players"
+	BrowserSortName    = "name"
+	BrowserSortMap     = "map"
+	BrowserSortPing    = "ping"
+)
+
+// ValidBrowserSort reports whether s is a server browser sort order
+func ValidBrowserSort(s string) bool {
+	switch s {
+	case BrowserSortPlayers, BrowserSortName, BrowserSortMap, BrowserSortPing:
+		return true
+	}
+	return false
+}
+
+// browserPingWindow is how far back player pings count towards the ping hint
+const browserPingWindow = time.Hour
+
+// GetServerBrowser lists the tenant's active servers for in-game browsers and
+// launchers: the registry in Postgres gives names and addresses, the
+// live_servers hash the heartbeats keep gives players, map and gametype, and
+// heartbeat pings give the ping hint. Servers without a live heartbeat entry
+// are left out unless the filter includes offline servers.
+func (s *ServerTrackingService) GetServerBrowser(ctx context.Context, filter models.ServerBrowserFilter) ([]models.BrowserServer, error) {
+	rows, err := s.pg.Query(ctx, `
+		SELECT id::text, name, COALESCE(NULLIF(ip_address, ''), address, ''), COALESCE(port, 0),
+		       COALESCE(region, ''), COALESCE(max_players, 32), COALESCE(is_official, false)
+		FROM servers
+		WHERE is_active AND ($1 = '' OR tenant_id::text = $1)
+	`, TenantFromContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get servers: %w", err)
+	}
+	defer rows.Close()
+
+	var servers []models.BrowserServer
+	var serverIDs []string
+	for rows.Next() {
+		var srv models.BrowserServer
+		if err := rows.Scan(&srv.ID, &srv.Name, &srv.Host, &srv.Port, &srv.Region, &srv.MaxPlayers, &srv.Official); err != nil {
+			return nil, fmt.Errorf("failed to scan server: %w", err)
+		}
+		srv.Address = srv.Host
+		if srv.Port > 0 {
+			srv.Address = fmt.Sprintf("%s:%d", srv.Host, srv.Port)
+		}
+		servers = append(servers, srv)
+		serverIDs = append(serverIDs, srv.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get servers: %w", err)
+	}
+	if len(servers) == 0 {
+		return []models.BrowserServer{}, nil
+	}
+
+	live, err := s.redis.HGetAll(ctx, "live_servers")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read live servers: %w", err)
+	}
+	// Written by the worker next to live_servers, unix seconds per server
+	seen, _ := s.redis.HGetAll(ctx, "live_servers_seen")
+
+	for i := range servers {
+		srv := &servers[i]
+		data, ok := live[srv.ID]
+		if !ok || data == "" {
+			continue
+		}
+		var status models.ServerOverview
+		parseServerLiveData(data, &status)
+		srv.Online = true
+		srv.Players, srv.Map, srv.Gametype = status.CurrentPlayers, status.CurrentMap, status.Gametype
+		if unix, err := strconv.ParseInt(seen[srv.ID], 10, 64); err == nil {
+			t := time.Unix(unix, 0).UTC()
+			srv.LastHeartbeat = &t
+		}
+	}
+
+	pings, err := s.medianPings(ctx, serverIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range servers {
+		servers[i].MedianPing = pings[servers[i].ID]
+	}
+
+	return filterBrowserServers(servers, filter), nil
+}
+
+// medianPings returns the median player ping per server over browserPingWindow
+func (s *ServerTrackingService) medianPings(ctx context.Context, serverIDs []string) (map[string]float64, error) {
+	rows, err := s.ch.Query(ctx, `
+		SELECT server_id, quantileTDigest(0.5)(ping) AS median
+		FROM mohaa_stats.player_pings
+		WHERE server_id IN ? AND timestamp >= ?
+		GROUP BY server_id
+	`, serverIDs, time.Now().UTC().Add(-browserPingWindow))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query server pings: %w", err)
+	}
+	defer rows.Close()
+
+	pings := make(map[string]float64)
+	for rows.Next() {
+		var serverID string
+		var median float32
+		if err := rows.Scan(&serverID, &median); err != nil {
+			return nil, fmt.Errorf("failed to scan server pings: %w", err)
+		}
+		pings[serverID] = float64(median)
+	}
+	return pings, rows.Err()
+}
+
+// filterBrowserServers applies the browser filter and sort order. Ties, and
+// servers without a ping hint when sorting by ping, fall back to the most
+// players and then the name.
+func filterBrowserServers(servers []models.BrowserServer, f models.ServerBrowserFilter) []models.BrowserServer {
+	search := strings.ToLower(f.Search)
+	out := []models.BrowserServer{}
+	for _, srv := range servers {
+		switch {
+		case !srv.Online && !f.IncludeOffline,
+			f.Map != "" && !strings.EqualFold(srv.Map, f.Map),
+			f.Gametype != "" && !strings.EqualFold(srv.Gametype, f.Gametype),
+			f.Region != "" && !strings.EqualFold(srv.Region, f.Region),
+			search != "" && !strings.Contains(strings.ToLower(srv.Name), search),
+			f.NotEmpty && srv.Players == 0,
+			f.NotFull && srv.MaxPlayers > 0 && srv.Players >= srv.MaxPlayers:
+			continue
+		}
+		out = append(out, srv)
+	}
+
+	byPlayers := func(a, b models.BrowserServer) bool {
+		if a.Players != b.Players {
+			return a.Players > b.Players
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Online != b.Online {
+			return a.Online
+		}
+		switch f.Sort {
+		case BrowserSortName:
+			if an, bn := strings.ToLower(a.Name), strings.ToLower(b.Name); an != bn {
+				return an < bn
+			}
+		case BrowserSortMap:
+			if a.Map != b.Map {
+				return a.Map < b.Map
+			}
+		case BrowserSortPing:
+			if (a.MedianPing > 0) != (b.MedianPing > 0) {
+				return a.MedianPing > 0
+			}
+			if a.MedianPing != b.MedianPing {
+				return a.MedianPing < b.MedianPing
+			}
+		}
+		return byPlayers(a, b)
+	})
+	return out
+}
@@ -0,0 +1,48 @@
+package logic
+
+import (
+	"testing"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestFilterBrowserServers(t *testing.T) {
+	servers := []models.BrowserServer{
+		{ID: "a", Name: "Alpha DM", Online: true, Players: 4, MaxPlayers: 16, Map: "mohdm6", Gametype: "dm", Region: "eu", MedianPing: 80},
+		{ID: "b", Name: "bravo obj", Online: true, Players: 16, MaxPlayers: 16, Map: "obj_team2", Gametype: "obj", Region: "us", MedianPing: 40},
+		{ID: "c", Name: "Charlie", Online: true, Players: 0, MaxPlayers: 32, Map: "mohdm1", Gametype: "dm", Region: "eu"},
+		{ID: "d", Name: "Delta", Players: 0, MaxPlayers: 32},
+	}
+	ids := func(list []models.BrowserServer) string {
+		s := ""
+		for _, srv := range list {
+			s += srv.ID
+		}
+		return s
+	}
+
+	tests := []struct {
+		name   string
+		filter models.ServerBrowserFilter
+		want   string
+	}{
+		{"default sorts online by players", models.ServerBrowserFilter{}, "bac"},
+		{"offline last", models.ServerBrowserFilter{IncludeOffline: true}, "bacd"},
+		{"gametype", models.ServerBrowserFilter{Gametype: "DM"}, "ac"},
+		{"map", models.ServerBrowserFilter{Map: "obj_team2"}, "b"},
+		{"region", models.ServerBrowserFilter{Region: "eu"}, "ac"},
+		{"search", models.ServerBrowserFilter{Search: "BRAVO"}, "b"},
+		{"not empty", models.ServerBrowserFilter{NotEmpty: true}, "ba"},
+		{"not full", models.ServerBrowserFilter{NotFull: true}, "ac"},
+		{"by name", models.ServerBrowserFilter{Sort: BrowserSortName}, "abc"},
+		{"by map", models.ServerBrowserFilter{Sort: BrowserSortMap}, "cab"},
+		{"by ping, unknown last", models.ServerBrowserFilter{Sort: BrowserSortPing}, "bac"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ids(filterBrowserServers(servers, tt.filter)); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
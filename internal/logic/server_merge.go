@@ -0,0 +1,226 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+var (
+	// ErrUnknownServer is returned when a server ID matches no server
+	ErrUnknownServer = errors.New("unknown server")
+	// ErrInvalidServerMerge is returned for a merge of a server into itself
+	// or across tenants
+	ErrInvalidServerMerge = errors.New("invalid server merge")
+)
+
+// serverMergeRewrites are the ClickHouse tables whose server_id is not part
+// of the sorting key, so a mutation can rewrite it in place. Mutations do not
+// fire materialized views, so nothing is counted twice.
+var serverMergeRewrites = []string{
+	"raw_events",
+	"movement_events",
+	"positions",
+}
+
+// serverMergeCopies are the ClickHouse tables sorted by server_id. Their rows
+// are copied under the new ID, where the summing and replacing engines fold
+// them into the new server's rows, and then deleted under the old one.
+var serverMergeCopies = []string{
+	"player_server_stats_daily",
+	"player_sessions",
+	"player_pings",
+	"server_performance",
+}
+
+// serverMergePostgres are the Postgres tables keyed by server ID.
+// server_report_settings stays with the old server, whose owner set it.
+var serverMergePostgres = []string{
+	"match_states",
+	"player_milestones",
+	"player_highlights",
+}
+
+type serverMergeService struct {
+	ch driver.Conn
+	pg PgPool
+}
+
+func NewServerMergeService(ch driver.Conn, pg PgPool) ServerMergeService {
+	return &serverMergeService{ch: ch, pg: pg}
+}
+
+// Plan counts the rows a merge would move, without changing anything
+func (s *serverMergeService) Plan(ctx context.Context, req models.ServerMergeRequest) (*models.ServerMerge, error) {
+	if err := s.checkServers(ctx, req.FromServerID, req.ToServerID); err != nil {
+		return nil, err
+	}
+	merge := &models.ServerMerge{
+		FromServerID: req.FromServerID,
+		ToServerID:   req.ToServerID,
+		Reason:       req.Reason,
+		DryRun:       true,
+		Tables:       []models.ServerMergeTable{},
+	}
+	add := func(store, table string, rows uint64) {
+		merge.Tables = append(merge.Tables, models.ServerMergeTable{Store: store, Table: table, Rows: rows})
+		merge.Rows += rows
+	}
+
+	for _, table := range append(append([]string{}, serverMergeRewrites...), serverMergeCopies...) {
+		var rows uint64
+		if err := s.ch.QueryRow(ctx, `
+			SELECT count() FROM mohaa_stats.`+table+` WHERE server_id = ?
+		`, req.FromServerID).Scan(&rows); err != nil {
+			return nil, fmt.Errorf("failed to count %s rows: %w", table, err)
+		}
+		add("clickhouse", table, rows)
+	}
+	for _, table := range serverMergePostgres {
+		var rows int64
+		if err := s.pg.QueryRow(ctx,
+			"SELECT COUNT(*) FROM "+table+" WHERE server_id = $1", req.FromServerID,
+		).Scan(&rows); err != nil {
+			return nil, fmt.Errorf("failed to count %s rows: %w", table, err)
+		}
+		add("postgres", table, uint64(rows))
+	}
+	return merge, nil
+}
+
+// Merge re-attributes the old server's history to the new one and records
+// the merge. Tables are merged one by one and each mutation is waited for, so
+// a failure names the table it stopped at; run a dry run before retrying, as
+// copied aggregate rows would be copied again. Events still in insert
+// buffers are not moved, so the old server should stop sending first.
+func (s *serverMergeService) Merge(ctx context.Context, req models.ServerMergeRequest, requestedBy string) (*models.ServerMerge, error) {
+	merge, err := s.Plan(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	merge.DryRun = false
+	merge.RequestedBy = requestedBy
+	from, to := req.FromServerID, req.ToServerID
+
+	syncCtx := clickhouse.Context(ctx, clickhouse.WithSettings(clickhouse.Settings{"mutations_sync": 2}))
+	for _, table := range serverMergeRewrites {
+		if err := s.ch.Exec(syncCtx, `
+			ALTER TABLE mohaa_stats.`+table+`
+			UPDATE server_id = ? WHERE server_id = ?
+		`, to, from); err != nil {
+			return nil, fmt.Errorf("failed to move %s rows: %w", table, err)
+		}
+	}
+	for _, table := range serverMergeCopies {
+		if err := s.ch.Exec(ctx, `
+			INSERT INTO mohaa_stats.`+table+`
+			SELECT * REPLACE (? AS server_id)
+			FROM mohaa_stats.`+table+`
+			WHERE server_id = ?
+		`, to, from); err != nil {
+			return nil, fmt.Errorf("failed to copy %s rows: %w", table, err)
+		}
+		if err := s.ch.Exec(syncCtx, `
+			ALTER TABLE mohaa_stats.`+table+`
+			DELETE WHERE server_id = ?
+		`, from); err != nil {
+			return nil, fmt.Errorf("failed to delete old %s rows: %w", table, err)
+		}
+	}
+
+	// One statement, so the Postgres side moves and is recorded together. A
+	// period both servers have a highlight for keeps the new server's.
+	if err := s.pg.QueryRow(ctx, `
+		WITH states AS (
+			UPDATE match_states SET server_id = $2 WHERE server_id = $1
+		), milestones AS (
+			UPDATE player_milestones SET server_id = $2 WHERE server_id = $1
+		), highlights AS (
+			UPDATE player_highlights h SET server_id = $2
+			WHERE h.server_id = $1 AND NOT EXISTS (
+				SELECT 1 FROM player_highlights n
+				WHERE n.period = h.period AND n.period_start = h.period_start
+				  AND n.tenant_id = h.tenant_id AND n.server_id = $2
+			)
+		), shadowed AS (
+			DELETE FROM player_highlights h
+			WHERE h.server_id = $1 AND EXISTS (
+				SELECT 1 FROM player_highlights n
+				WHERE n.period = h.period AND n.period_start = h.period_start
+				  AND n.tenant_id = h.tenant_id AND n.server_id = $2
+			)
+		)
+		INSERT INTO server_merges (from_server_id, to_server_id, reason, requested_by, tables, rows_moved)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id::text, created_at
+	`, from, to, req.Reason, requestedBy, merge.Tables, int64(merge.Rows)).Scan(&merge.ID, &merge.CreatedAt); err != nil {
+		return nil, fmt.Errorf("failed to merge server records: %w", err)
+	}
+	return merge, nil
+}
+
+// History lists the recorded merges, newest first. Tenants only see merges
+// of their own servers.
+func (s *serverMergeService) History(ctx context.Context, limit int) ([]models.ServerMerge, error) {
+	tenantID := TenantFromContext(ctx)
+	rows, err := s.pg.Query(ctx, `
+		SELECT m.id::text, m.from_server_id, m.to_server_id, m.reason, m.requested_by,
+		       m.tables, m.rows_moved, m.created_at
+		FROM server_merges m
+		LEFT JOIN servers s ON s.id::text = m.to_server_id
+		WHERE $1 = '' OR COALESCE(s.tenant_id::text, '') = $1
+		ORDER BY m.created_at DESC
+		LIMIT $2
+	`, tenantID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list server merges: %w", err)
+	}
+	defer rows.Close()
+
+	merges := make([]models.ServerMerge, 0)
+	for rows.Next() {
+		var m models.ServerMerge
+		var moved int64
+		if err := rows.Scan(&m.ID, &m.FromServerID, &m.ToServerID, &m.Reason, &m.RequestedBy,
+			&m.Tables, &moved, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan server merges: %w", err)
+		}
+		m.Rows = uint64(moved)
+		merges = append(merges, m)
+	}
+	return merges, rows.Err()
+}
+
+// checkServers requires two different servers of one tenant, which must be
+// the caller's when it is a tenant
+func (s *serverMergeService) checkServers(ctx context.Context, from, to string) error {
+	if from == "" || to == "" || from == to {
+		return fmt.Errorf("%w: from_server_id and to_server_id must be two different servers", ErrInvalidServerMerge)
+	}
+	tenants := make([]string, 2)
+	for i, id := range []string{from, to} {
+		err := s.pg.QueryRow(ctx,
+			"SELECT COALESCE(tenant_id::text, '') FROM servers WHERE id::text = $1", id,
+		).Scan(&tenants[i])
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%w: %s", ErrUnknownServer, id)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to look up server: %w", err)
+		}
+	}
+	if tenants[0] != tenants[1] {
+		return fmt.Errorf("%w: the servers belong to different tenants", ErrInvalidServerMerge)
+	}
+	if caller := TenantFromContext(ctx); caller != "" && caller != tenants[0] {
+		// Another tenant's servers are as good as missing
+		return fmt.Errorf("%w: %s", ErrUnknownServer, from)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// serverTenants answers the server lookups of checkServers
+type serverTenants struct {
+	MockPgPool
+	tenants map[string]string
+}
+
+type scanRow func(dest ...any) error
+
+func (f scanRow) Scan(dest ...any) error { return f(dest...) }
+
+func (s *serverTenants) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return scanRow(func(dest ...any) error {
+		tenant, ok := s.tenants[args[0].(string)]
+		if !ok {
+			return pgx.ErrNoRows
+		}
+		*dest[0].(*string) = tenant
+		return nil
+	})
+}
+
+func TestCheckServerMerge(t *testing.T) {
+	s := &serverMergeService{pg: &serverTenants{tenants: map[string]string{
+		"old": "", "new": "", "t-old": "t1", "t-new": "t1", "other": "t2",
+	}}}
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		from, to string
+		want     error
+	}{
+		{name: "Same Server", ctx: ctx, from: "old", to: "old", want: ErrInvalidServerMerge},
+		{name: "Missing ID", ctx: ctx, from: "old", want: ErrInvalidServerMerge},
+		{name: "Unknown Server", ctx: ctx, from: "old", to: "gone", want: ErrUnknownServer},
+		{name: "Across Tenants", ctx: ctx, from: "t-old", to: "other", want: ErrInvalidServerMerge},
+		{name: "Default Community", ctx: ctx, from: "old", to: "new"},
+		{name: "Own Tenant", ctx: WithTenant(ctx, "t1"), from: "t-old", to: "t-new"},
+		{name: "Other Tenant", ctx: WithTenant(ctx, "t2"), from: "t-old", to: "t-new", want: ErrUnknownServer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.checkServers(tt.ctx, tt.from, tt.to)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
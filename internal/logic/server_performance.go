@@ -0,0 +1,90 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// laggingSQL matches samples whose frames took longer than the sv_fps budget
+const laggingSQL = `sv_fps > 0 AND frame_time > 1000 / sv_fps`
+
+// performanceInterval picks the series resolution for a time range so charts
+// get a few hundred points at most
+func performanceInterval(hours int) time.Duration {
+	switch {
+	case hours <= 6:
+		return time.Minute
+	case hours <= 48:
+		return 5 * time.Minute
+	case hours <= 14*24:
+		return time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// GetServerPerformance reports a server's frame rate, frame times and CPU
+// usage over the last `hours` hours, overall and per interval
+func (s *ServerTrackingService) GetServerPerformance(ctx context.Context, serverID string, hours int) (*models.ServerPerformanceReport, error) {
+	since := time.Now().UTC().Add(-time.Duration(hours) * time.Hour)
+	interval := performanceInterval(hours)
+	report := &models.ServerPerformanceReport{
+		ServerID:     serverID,
+		Hours:        hours,
+		IntervalSecs: int(interval.Seconds()),
+		Series:       []models.ServerPerformancePoint{},
+	}
+
+	if err := s.ch.QueryRow(ctx, `
+		SELECT
+			count() AS samples,
+			ifNotFinite(avgIf(sv_fps, sv_fps > 0), 0) AS avg_fps,
+			toFloat64(minIf(sv_fps, sv_fps > 0)) AS min_fps,
+			ifNotFinite(avgIf(frame_time, frame_time > 0), 0) AS avg_frame,
+			ifNotFinite(quantileTDigestIf(0.95)(frame_time, frame_time > 0), 0) AS p95_frame,
+			toFloat64(max(frame_time)) AS max_frame,
+			ifNotFinite(avgIf(cpu_usage, cpu_usage > 0), 0) AS avg_cpu,
+			countIf(`+laggingSQL+`) AS lagging
+		FROM mohaa_stats.server_performance
+		WHERE server_id = ? AND timestamp >= ?
+	`, serverID, since).Scan(
+		&report.Samples, &report.AvgFPS, &report.MinFPS, &report.AvgFrameTime,
+		&report.P95FrameTime, &report.MaxFrameTime, &report.AvgCPU, &report.LaggingSamples,
+	); err != nil {
+		return nil, fmt.Errorf("failed to query server performance: %w", err)
+	}
+
+	rows, err := s.ch.Query(ctx, `
+		SELECT
+			toStartOfInterval(timestamp, toIntervalSecond(?)) AS ts,
+			count() AS samples,
+			ifNotFinite(avgIf(sv_fps, sv_fps > 0), 0) AS avg_fps,
+			ifNotFinite(avgIf(frame_time, frame_time > 0), 0) AS avg_frame,
+			toFloat64(max(frame_time)) AS max_frame,
+			ifNotFinite(avgIf(cpu_usage, cpu_usage > 0), 0) AS avg_cpu,
+			avg(player_count) AS avg_players,
+			countIf(`+laggingSQL+`) AS lagging
+		FROM mohaa_stats.server_performance
+		WHERE server_id = ? AND timestamp >= ?
+		GROUP BY ts
+		ORDER BY ts
+	`, report.IntervalSecs, serverID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query server performance series: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p models.ServerPerformancePoint
+		if err := rows.Scan(&p.Timestamp, &p.Samples, &p.AvgFPS, &p.AvgFrameTime, &p.MaxFrameTime, &p.AvgCPU, &p.AvgPlayers, &p.LaggingSamples); err != nil {
+			return nil, fmt.Errorf("failed to scan server performance series: %w", err)
+		}
+		report.Series = append(report.Series, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan server performance series: %w", err)
+	}
+	return report, nil
+}
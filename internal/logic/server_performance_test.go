@@ -0,0 +1,28 @@
+package logic
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPerformanceInterval(t *testing.T) {
+	cases := []struct {
+		hours int
+		want  time.Duration
+	}{
+		{1, time.Minute},
+		{6, time.Minute},
+		{24, 5 * time.Minute},
+		{7 * 24, time.Hour},
+		{30 * 24, 24 * time.Hour},
+	}
+	for _, c := range cases {
+		if got := performanceInterval(c.hours); got != c.want {
+			t.Errorf("performanceInterval(%d) = %v, want %v", c.hours, got, c.want)
+		}
+		// A few hundred points at most
+		if points := time.Duration(c.hours) * time.Hour / performanceInterval(c.hours); points > 600 {
+			t.Errorf("%d hours gives %d points", c.hours, points)
+		}
+	}
+}
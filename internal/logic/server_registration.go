@@ -0,0 +1,294 @@
+package logic
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+var (
+	// ErrInvalidInvite is returned for an invite request that cannot be stored
+	ErrInvalidInvite = errors.New("invalid invite")
+	// ErrInviteNotUsable is returned for an invite code that is unknown,
+	// revoked, expired or used up
+	ErrInviteNotUsable = errors.New("invite code is unknown, revoked, expired or used up")
+	// ErrAddressRegistered is returned when a server already uses the address
+	ErrAddressRegistered = errors.New("address already registered")
+	// ErrUnknownRegistration is returned when there is no pending registration
+	// with the ID (in the caller's tenant)
+	ErrUnknownRegistration = errors.New("no pending registration")
+	// ErrUnknownInvite is returned when there is no invite with the ID
+	ErrUnknownInvite = errors.New("unknown invite")
+	// ErrNotReviewer is returned when a registration is reviewed without an
+	// admin server's token
+	ErrNotReviewer = errors.New("only admin servers review registrations")
+)
+
+// maxInviteUses bounds how many servers one invite can register
+const maxInviteUses = 100
+
+type serverRegistrationService struct {
+	pg PgPool
+}
+
+func NewServerRegistrationService(pg PgPool) ServerRegistrationService {
+	return &serverRegistrationService{pg: pg}
+}
+
+// hashSecret matches the SHA256 hex the API stores for server tokens
+func hashSecret(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// newInviteCode returns a random code that is easy to read out and type,
+// e.g. "K7QF-3MZD-ANX2-PL9T"
+func newInviteCode() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	s := base32.StdEncoding.EncodeToString(b) // 16 characters
+	return s[0:4] + "-" + s[4:8] + "-" + s[8:12] + "-" + s[12:16], nil
+}
+
+// normalizeInviteCode accepts codes in any case and with or without dashes
+func normalizeInviteCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	if len(code) != 16 {
+		return code
+	}
+	return code[0:4] + "-" + code[4:8] + "-" + code[8:12] + "-" + code[12:16]
+}
+
+// CreateInvite issues an invite for the caller's tenant; the returned invite
+// carries the code, which is not stored and cannot be read back
+func (s *serverRegistrationService) CreateInvite(ctx context.Context, req models.CreateServerInviteRequest, createdBy string) (*models.ServerInvite, error) {
+	if req.MaxUses == 0 {
+		req.MaxUses = 1
+	}
+	if req.MaxUses < 0 || req.MaxUses > maxInviteUses {
+		return nil, fmt.Errorf("%w: max_uses must be 1-%d", ErrInvalidInvite, maxInviteUses)
+	}
+	if req.ExpiresInHours < 0 {
+		return nil, fmt.Errorf("%w: expires_in_hours must not be negative", ErrInvalidInvite)
+	}
+	code, err := newInviteCode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate invite code: %w", err)
+	}
+
+	invite := &models.ServerInvite{
+		Code:      code,
+		TenantID:  TenantFromContext(ctx),
+		Note:      req.Note,
+		MaxUses:   req.MaxUses,
+		CreatedBy: createdBy,
+	}
+	if req.ExpiresInHours > 0 {
+		t := time.Now().UTC().Add(time.Duration(req.ExpiresInHours) * time.Hour)
+		invite.ExpiresAt = &t
+	}
+	var tenantID *string
+	if invite.TenantID != "" {
+		tenantID = &invite.TenantID
+	}
+	if err := s.pg.QueryRow(ctx, `
+		INSERT INTO server_invites (code_hash, tenant_id, note, max_uses, expires_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id::text, created_at
+	`, hashSecret(code), tenantID, req.Note, req.MaxUses, invite.ExpiresAt, createdBy).Scan(&invite.ID, &invite.CreatedAt); err != nil {
+		return nil, fmt.Errorf("failed to store invite: %w", err)
+	}
+	return invite, nil
+}
+
+// ListInvites returns the caller's tenant's invites, newest first
+func (s *serverRegistrationService) ListInvites(ctx context.Context) ([]models.ServerInvite, error) {
+	rows, err := s.pg.Query(ctx, `
+		SELECT id::text, COALESCE(tenant_id::text, ''), note, max_uses, uses, expires_at, revoked_at, created_by, created_at
+		FROM server_invites
+		WHERE $1 = '' OR tenant_id::text = $1
+		ORDER BY created_at DESC
+	`, TenantFromContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read invites: %w", err)
+	}
+	defer rows.Close()
+
+	invites := []models.ServerInvite{}
+	for rows.Next() {
+		var i models.ServerInvite
+		if err := rows.Scan(&i.ID, &i.TenantID, &i.Note, &i.MaxUses, &i.Uses, &i.ExpiresAt, &i.RevokedAt, &i.CreatedBy, &i.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to read invites: %w", err)
+		}
+		invites = append(invites, i)
+	}
+	return invites, rows.Err()
+}
+
+// RevokeInvite stops an invite from registering more servers. Servers it
+// already registered keep their state.
+func (s *serverRegistrationService) RevokeInvite(ctx context.Context, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return ErrUnknownInvite
+	}
+	tag, err := s.pg.Exec(ctx, `
+		UPDATE server_invites SET revoked_at = COALESCE(revoked_at, NOW())
+		WHERE id = $1 AND ($2 = '' OR tenant_id::text = $2)
+	`, id, TenantFromContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to revoke invite: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrUnknownInvite
+	}
+	return nil
+}
+
+// Register uses up one use of the invite and stores the server as pending in
+// the invite's tenant. The token is returned right away; it authenticates
+// ingest once an admin approves the server. An address that is already
+// registered does not use up the invite.
+func (s *serverRegistrationService) Register(ctx context.Context, req models.RegisterServerRequest, port int) (*models.RegisterServerResponse, error) {
+	resp := &models.RegisterServerResponse{
+		ServerID: uuid.New().String(),
+		Token:    uuid.New().String(),
+		Status:   models.ServerPending,
+	}
+	err := s.pg.QueryRow(ctx, `
+		WITH invite AS (
+			UPDATE server_invites SET uses = uses + 1
+			WHERE code_hash = $1 AND revoked_at IS NULL AND uses < max_uses
+				AND (expires_at IS NULL OR expires_at > NOW())
+				AND NOT EXISTS (SELECT 1 FROM servers WHERE ip_address = $4 AND port = $5)
+			RETURNING id, tenant_id
+		)
+		INSERT INTO servers (id, name, ip_address, port, token, region, description,
+			is_active, approval_status, invite_id, tenant_id)
+		SELECT $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), false, 'pending', invite.id, invite.tenant_id
+		FROM invite
+		RETURNING id::text
+	`, hashSecret(normalizeInviteCode(req.InviteCode)), resp.ServerID, req.Name, req.IPAddress, port,
+		hashSecret(resp.Token), req.Region, req.Description).Scan(&resp.ServerID)
+	if err == nil {
+		return resp, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to register server: %w", err)
+	}
+
+	var taken bool
+	if err := s.pg.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM servers WHERE ip_address = $1 AND port = $2)
+	`, req.IPAddress, port).Scan(&taken); err != nil {
+		return nil, fmt.Errorf("failed to register server: %w", err)
+	}
+	if taken {
+		return nil, ErrAddressRegistered
+	}
+	return nil, ErrInviteNotUsable
+}
+
+// Status returns the registration of the server the token belongs to, in
+// any approval state, so a pending server can poll for approval
+func (s *serverRegistrationService) Status(ctx context.Context, token string) (*models.ServerRegistration, error) {
+	rows, err := s.pg.Query(ctx, registrationSelect+` WHERE s.token = $1`, hashSecret(token))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registration: %w", err)
+	}
+	regs, err := scanRegistrations(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(regs) == 0 {
+		return nil, ErrUnknownRegistration
+	}
+	return &regs[0], nil
+}
+
+// ListPending returns the caller's tenant's servers awaiting approval,
+// oldest first
+func (s *serverRegistrationService) ListPending(ctx context.Context) ([]models.ServerRegistration, error) {
+	rows, err := s.pg.Query(ctx, registrationSelect+`
+		WHERE s.approval_status = 'pending' AND ($1 = '' OR s.tenant_id::text = $1)
+		ORDER BY s.created_at
+	`, TenantFromContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pending servers: %w", err)
+	}
+	return scanRegistrations(rows)
+}
+
+// Review approves a pending server, activating its token, or rejects it. The
+// reviewer must be an admin server; one of a tenant only reviews that
+// tenant's registrations.
+func (s *serverRegistrationService) Review(ctx context.Context, serverID string, approve bool, reviewer string) (*models.ServerRegistration, error) {
+	if !AdminFromContext(ctx) {
+		return nil, ErrNotReviewer
+	}
+	if _, err := uuid.Parse(serverID); err != nil {
+		return nil, ErrUnknownRegistration
+	}
+	status := models.ServerRejected
+	if approve {
+		status = models.ServerApproved
+	}
+	tag, err := s.pg.Exec(ctx, `
+		UPDATE servers
+		SET approval_status = $2, is_active = $3, reviewed_at = NOW(), reviewed_by = $4
+		WHERE id = $1 AND approval_status = 'pending' AND ($5 = '' OR tenant_id::text = $5)
+	`, serverID, status, approve, reviewer, TenantFromContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to review server: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, ErrUnknownRegistration
+	}
+
+	rows, err := s.pg.Query(ctx, registrationSelect+` WHERE s.id = $1`, serverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registration: %w", err)
+	}
+	regs, err := scanRegistrations(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(regs) == 0 {
+		return nil, ErrUnknownRegistration
+	}
+	return &regs[0], nil
+}
+
+const registrationSelect = `
+	SELECT s.id::text, s.name, COALESCE(s.ip_address, ''), COALESCE(s.port, 0), COALESCE(s.region, ''),
+		COALESCE(s.description, ''), COALESCE(s.tenant_id::text, ''), COALESCE(s.invite_id::text, ''),
+		COALESCE(i.note, ''), s.approval_status, s.created_at, s.reviewed_at, COALESCE(s.reviewed_by, '')
+	FROM servers s
+	LEFT JOIN server_invites i ON i.id = s.invite_id`
+
+func scanRegistrations(rows pgx.Rows) ([]models.ServerRegistration, error) {
+	defer rows.Close()
+	regs := []models.ServerRegistration{}
+	for rows.Next() {
+		var r models.ServerRegistration
+		if err := rows.Scan(&r.ID, &r.Name, &r.IPAddress, &r.Port, &r.Region, &r.Description, &r.TenantID,
+			&r.InviteID, &r.InviteNote, &r.Status, &r.CreatedAt, &r.ReviewedAt, &r.ReviewedBy); err != nil {
+			return nil, fmt.Errorf("failed to read registration: %w", err)
+		}
+		regs = append(regs, r)
+	}
+	return regs, rows.Err()
+}
@@ -0,0 +1,37 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestInviteCodeRoundTrip(t *testing.T) {
+	code, err := newInviteCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(code) != 19 || code[4] != '-' || code[9] != '-' || code[14] != '-' {
+		t.Fatalf("unexpected code format %q", code)
+	}
+	if got := normalizeInviteCode(code); got != code {
+		t.Errorf("normalizeInviteCode(%q) = %q", code, got)
+	}
+
+	for _, typed := range []string{"k7qf-3mzd-anx2-pl9t", " K7QF3MZDANX2PL9T ", "K7QF 3MZD ANX2 PL9T"} {
+		if got := normalizeInviteCode(typed); got != "K7QF-3MZD-ANX2-PL9T" {
+			t.Errorf("normalizeInviteCode(%q) = %q", typed, got)
+		}
+	}
+	if got := normalizeInviteCode("short"); got != "SHORT" {
+		t.Errorf("normalizeInviteCode(short) = %q", got)
+	}
+}
+
+func TestReviewRequiresAdmin(t *testing.T) {
+	svc := NewServerRegistrationService(nil)
+	ctx := WithTenant(context.Background(), "")
+	if _, err := svc.Review(ctx, "8c0f3b0e-6a55-4a8e-9d3f-2b1f3f7c9a10", true, "srv"); !errors.Is(err, ErrNotReviewer) {
+		t.Errorf("Review without admin: err = %v, want ErrNotReviewer", err)
+	}
+}
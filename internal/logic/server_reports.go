@@ -0,0 +1,331 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/openmohaa/stats-api/internal/models"
+	"github.com/openmohaa/stats-api/internal/notify"
+)
+
+var (
+	// ErrInvalidReportSettings is returned for report settings that cannot
+	// be delivered to
+	ErrInvalidReportSettings = errors.New("invalid report settings")
+	// ErrReportSettingsNotFound is returned for a server without settings
+	ErrReportSettingsNotFound = errors.New("report settings not found")
+)
+
+const (
+	serverReportTopPlayers = 5
+	serverReportHours      = 3
+	serverReportMaps       = 5
+	serverReportMatches    = 3
+)
+
+type serverReportService struct {
+	ch           driver.Conn
+	pg           PgPool
+	emailEnabled bool
+}
+
+// NewServerReportService stores where reports go and builds them. Settings
+// with an email address are refused unless emailEnabled (SMTP configured).
+func NewServerReportService(ch driver.Conn, pg PgPool, emailEnabled bool) ServerReportService {
+	return &serverReportService{ch: ch, pg: pg, emailEnabled: emailEnabled}
+}
+
+const reportSettingsColumns = `server_id::text, enabled, webhook_url, email, last_sent_week, updated_at`
+
+func scanReportSettings(row pgx.Row) (*models.ServerReportSettings, error) {
+	var st models.ServerReportSettings
+	err := row.Scan(&st.ServerID, &st.Enabled, &st.WebhookURL, &st.Email, &st.LastSentWeek, &st.UpdatedAt)
+	return &st, err
+}
+
+// GetSettings returns where a server's reports go
+func (s *serverReportService) GetSettings(ctx context.Context, serverID string) (*models.ServerReportSettings, error) {
+	st, err := scanReportSettings(s.pg.QueryRow(ctx,
+		`SELECT `+reportSettingsColumns+` FROM server_report_settings WHERE server_id = $1`, serverID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrReportSettingsNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report settings: %w", err)
+	}
+	return st, nil
+}
+
+// SetSettings validates and stores where a server's reports go, keeping the
+// week last sent
+func (s *serverReportService) SetSettings(ctx context.Context, st *models.ServerReportSettings) error {
+	if err := s.validate(st); err != nil {
+		return err
+	}
+	saved, err := scanReportSettings(s.pg.QueryRow(ctx, `
+		INSERT INTO server_report_settings (server_id, enabled, webhook_url, email)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (server_id) DO UPDATE SET
+			enabled = EXCLUDED.enabled,
+			webhook_url = EXCLUDED.webhook_url,
+			email = EXCLUDED.email,
+			updated_at = NOW()
+		RETURNING `+reportSettingsColumns,
+		st.ServerID, st.Enabled, st.WebhookURL, st.Email))
+	if err != nil {
+		return fmt.Errorf("failed to save report settings: %w", err)
+	}
+	*st = *saved
+	return nil
+}
+
+func (s *serverReportService) validate(st *models.ServerReportSettings) error {
+	if st.WebhookURL == "" && st.Email == "" {
+		return fmt.Errorf("%w: set webhook_url, email or both", ErrInvalidReportSettings)
+	}
+	if st.WebhookURL != "" {
+		if err := notify.ValidateWebhookURL(st.WebhookURL); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidReportSettings, err)
+		}
+	}
+	if st.Email != "" {
+		if !s.emailEnabled {
+			return fmt.Errorf("%w: email delivery is not configured on this API", ErrInvalidReportSettings)
+		}
+		if err := notify.ValidateEmail(st.Email); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidReportSettings, err)
+		}
+	}
+	return nil
+}
+
+// DeleteSettings stops a server's reports
+func (s *serverReportService) DeleteSettings(ctx context.Context, serverID string) error {
+	tag, err := s.pg.Exec(ctx, `DELETE FROM server_report_settings WHERE server_id = $1`, serverID)
+	if err != nil {
+		return fmt.Errorf("failed to delete report settings: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrReportSettingsNotFound
+	}
+	return nil
+}
+
+// Due lists the enabled settings whose report of the week starting
+// weekStart is not sent yet
+func (s *serverReportService) Due(ctx context.Context, weekStart time.Time) ([]models.ServerReportSettings, error) {
+	rows, err := s.pg.Query(ctx, `
+		SELECT `+reportSettingsColumns+`
+		FROM server_report_settings
+		WHERE enabled AND (last_sent_week IS NULL OR last_sent_week < $1)
+		ORDER BY server_id
+	`, weekStart.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read due reports: %w", err)
+	}
+	defer rows.Close()
+	var due []models.ServerReportSettings
+	for rows.Next() {
+		st, err := scanReportSettings(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read due reports: %w", err)
+		}
+		due = append(due, *st)
+	}
+	return due, rows.Err()
+}
+
+// MarkSent records the report of the week starting weekStart as sent
+func (s *serverReportService) MarkSent(ctx context.Context, serverID string, weekStart time.Time) error {
+	if _, err := s.pg.Exec(ctx,
+		`UPDATE server_report_settings SET last_sent_week = $2 WHERE server_id = $1`,
+		serverID, weekStart.Format("2006-01-02")); err != nil {
+		return fmt.Errorf("failed to mark report sent: %w", err)
+	}
+	return nil
+}
+
+// Build reads a server's week starting weekStart (a UTC Monday): totals, top
+// players by kills, the busiest hours by players, the maps played and the
+// matches with the most kills
+func (s *serverReportService) Build(ctx context.Context, serverID string, weekStart time.Time) (*models.ServerReport, error) {
+	start, end := weekStart, weekStart.AddDate(0, 0, 7)
+	r := &models.ServerReport{
+		ServerID:       serverID,
+		WeekStart:      start,
+		TopPlayers:     []models.ServerReportPlayer{},
+		BusiestHours:   []models.ServerReportHour{},
+		Maps:           []models.ServerReportMap{},
+		NotableMatches: []models.ServerReportMatch{},
+	}
+	_ = s.pg.QueryRow(ctx, `SELECT name FROM servers WHERE id = $1`, serverID).Scan(&r.ServerName)
+
+	var matches, players, kills uint64
+	if err := s.ch.QueryRow(ctx, `
+		SELECT
+			uniq(match_id),
+			uniqIf(actor_id, actor_id != '' AND actor_id != 'world'),
+			countIf(event_type IN ('player_kill', 'bot_killed'))
+		FROM mohaa_stats.raw_events
+		WHERE server_id = ? AND timestamp >= ? AND timestamp < ?
+	`, serverID, start, end).Scan(&matches, &players, &kills); err != nil {
+		return nil, fmt.Errorf("failed to read server week: %w", err)
+	}
+	r.Matches, r.Players, r.Kills = int64(matches), int64(players), int64(kills)
+
+	if err := s.topPlayers(ctx, r, start, end); err != nil {
+		return nil, err
+	}
+	if err := s.busiestHours(ctx, r, start, end); err != nil {
+		return nil, err
+	}
+	if err := s.maps(ctx, r, start, end); err != nil {
+		return nil, err
+	}
+	if err := s.notableMatches(ctx, r, start, end); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (s *serverReportService) topPlayers(ctx context.Context, r *models.ServerReport, start, end time.Time) error {
+	rows, err := s.ch.Query(ctx, `
+		SELECT
+			player_id,
+			anyLast(player_name),
+			toInt64(sum(kills)) AS k,
+			toInt64(sum(deaths)) AS d,
+			toInt64(sum(headshots))
+		FROM mohaa_stats.player_server_stats_daily
+		WHERE server_id = ? AND player_id != '' AND day >= ? AND day < ?
+		GROUP BY player_id
+		HAVING k > 0
+		ORDER BY k DESC, d ASC, player_id
+		LIMIT ?
+	`, r.ServerID, start, end, serverReportTopPlayers)
+	if err != nil {
+		return fmt.Errorf("failed to read report players: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p models.ServerReportPlayer
+		if err := rows.Scan(&p.PlayerGUID, &p.PlayerName, &p.Kills, &p.Deaths, &p.Headshots); err != nil {
+			return fmt.Errorf("failed to read report players: %w", err)
+		}
+		p.KDRatio = float64(p.Kills)
+		if p.Deaths > 0 {
+			p.KDRatio = float64(p.Kills) / float64(p.Deaths)
+		}
+		r.TopPlayers = append(r.TopPlayers, p)
+	}
+	return rows.Err()
+}
+
+func (s *serverReportService) busiestHours(ctx context.Context, r *models.ServerReport, start, end time.Time) error {
+	rows, err := s.ch.Query(ctx, `
+		SELECT
+			toInt32(toDayOfWeek(timestamp)) AS day,
+			toInt32(toHour(timestamp)) AS hour,
+			toInt64(uniq(actor_id)) AS players
+		FROM mohaa_stats.raw_events
+		WHERE server_id = ? AND timestamp >= ? AND timestamp < ?
+		  AND actor_id != '' AND actor_id != 'world'
+		GROUP BY day, hour
+		ORDER BY players DESC, day, hour
+		LIMIT ?
+	`, r.ServerID, start, end, serverReportHours)
+	if err != nil {
+		return fmt.Errorf("failed to read report hours: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var day, hour int32
+		var h models.ServerReportHour
+		if err := rows.Scan(&day, &hour, &h.Players); err != nil {
+			return fmt.Errorf("failed to read report hours: %w", err)
+		}
+		h.Day, h.Hour = int(day), int(hour)
+		r.BusiestHours = append(r.BusiestHours, h)
+	}
+	return rows.Err()
+}
+
+func (s *serverReportService) maps(ctx context.Context, r *models.ServerReport, start, end time.Time) error {
+	rows, err := s.ch.Query(ctx, `
+		SELECT
+			map_name,
+			toInt64(uniq(match_id)) AS matches,
+			toInt64(countIf(event_type IN ('player_kill', 'bot_killed'))) AS kills
+		FROM mohaa_stats.raw_events
+		WHERE server_id = ? AND timestamp >= ? AND timestamp < ? AND map_name != ''
+		GROUP BY map_name
+		ORDER BY matches DESC, kills DESC, map_name
+		LIMIT ?
+	`, r.ServerID, start, end, serverReportMaps)
+	if err != nil {
+		return fmt.Errorf("failed to read report maps: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var m models.ServerReportMap
+		if err := rows.Scan(&m.MapName, &m.Matches, &m.Kills); err != nil {
+			return fmt.Errorf("failed to read report maps: %w", err)
+		}
+		r.Maps = append(r.Maps, m)
+	}
+	return rows.Err()
+}
+
+func (s *serverReportService) notableMatches(ctx context.Context, r *models.ServerReport, start, end time.Time) error {
+	rows, err := s.ch.Query(ctx, `
+		SELECT
+			toString(match_id),
+			any(map_name),
+			min(timestamp),
+			toInt64(uniqIf(actor_id, actor_id != '' AND actor_id != 'world')),
+			toInt64(countIf(event_type IN ('player_kill', 'bot_killed'))) AS kills
+		FROM mohaa_stats.raw_events
+		WHERE server_id = ? AND timestamp >= ? AND timestamp < ?
+		GROUP BY match_id
+		HAVING kills > 0
+		ORDER BY kills DESC
+		LIMIT ?
+	`, r.ServerID, start, end, serverReportMatches)
+	if err != nil {
+		return fmt.Errorf("failed to read report matches: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var m models.ServerReportMatch
+		if err := rows.Scan(&m.MatchID, &m.MapName, &m.StartedAt, &m.Players, &m.Kills); err != nil {
+			return fmt.Errorf("failed to read report matches: %w", err)
+		}
+		r.NotableMatches = append(r.NotableMatches, m)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read report matches: %w", err)
+	}
+	if len(r.NotableMatches) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(r.NotableMatches))
+	for i, m := range r.NotableMatches {
+		ids[i] = m.MatchID
+	}
+	watched, err := watchedMatches(ctx, s.ch, "", start, ids)
+	if err != nil {
+		return err
+	}
+	for i, m := range r.NotableMatches {
+		if w := watched[m.MatchID]; w != nil {
+			r.NotableMatches[i].PeakSpectators = w.PeakSpectators
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package logic
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+)
+
+// Tables filtered on their server column when a request is scoped to a set
+// of servers
+var serverScopedTables = []string{
+	"raw_events",
+	"movement_events",
+	"positions",
+	"player_pings",
+	"server_performance",
+	"match_spectators",
+	"player_sessions",
+	"player_server_stats_daily",
+}
+
+// Aggregates without a server or tenant column. A scoped request reads
+// nothing from them; PlayerStatsTable and PositionCellsTable point it at
+// tables it can filter.
+var serverlessTables = []string{
+	"player_stats_daily",
+	"leaderboard_global",
+	"position_cells",
+}
+
+type serverScopeKey struct{}
+
+// WithServerScope limits every ClickHouse read made with ctx to the given
+// servers of a tenant, for routes whose queries do not filter on one. Rows of
+// other servers are dropped by ClickHouse (additional_table_filters), so
+// subqueries and views are covered too. It replaces any query settings already
+// on ctx, so it belongs in middleware, before handlers add their own.
+func WithServerScope(ctx context.Context, tenantID string, serverIDs []string) context.Context {
+	ctx = context.WithValue(ctx, serverScopeKey{}, true)
+	return clickhouse.Context(ctx, clickhouse.WithSettings(clickhouse.Settings{
+		"additional_table_filters": serverScopeFilters(tenantID, serverIDs),
+	}))
+}
+
+// ServerScoped reports whether WithServerScope limited ctx
+func ServerScoped(ctx context.Context) bool {
+	scoped, _ := ctx.Value(serverScopeKey{}).(bool)
+	return scoped
+}
+
+// PlayerStatsTable is the daily player aggregate to read under ctx: the
+// per-server one when the request is scoped to servers, since the global one
+// cannot be filtered. Both share a column layout.
+func PlayerStatsTable(ctx context.Context) string {
+	if ServerScoped(ctx) {
+		return "mohaa_stats.player_server_stats_daily"
+	}
+	return "mohaa_stats.player_stats_daily"
+}
+
+// PositionCellsTable is the heatmap grid to read under ctx. A request scoped to
+// servers counts the cells from positions instead, so its heatmaps only cover
+// the positions still kept (POSITION_RETENTION_DAYS).
+func PositionCellsTable(ctx context.Context) string {
+	if ServerScoped(ctx) {
+		return `(
+			SELECT toDate(timestamp) AS day, map_name, event_type, role, player_id,
+				toInt32(round(pos_x / 50)) AS cell_x,
+				toInt32(round(pos_y / 50)) AS cell_y,
+				sample_weight AS samples
+			FROM mohaa_stats.positions
+		)`
+	}
+	return "mohaa_stats.position_cells"
+}
+
+// serverScopeFilters renders the additional_table_filters map, in ClickHouse's
+// escaped Map text format: {'mohaa_stats.table':'filter',...}
+func serverScopeFilters(tenantID string, serverIDs []string) string {
+	servers := "0"
+	if len(serverIDs) > 0 {
+		ids := append([]string(nil), serverIDs...)
+		sort.Strings(ids)
+		for i, id := range ids {
+			ids[i] = sqlQuote(id)
+		}
+		servers = "server_id IN (" + strings.Join(ids, ",") + ")"
+	}
+
+	filters := make([]string, 0, len(serverScopedTables)+len(serverlessTables)+1)
+	for _, table := range serverScopedTables {
+		filters = append(filters, mapEntry("mohaa_stats."+table, servers))
+	}
+	filters = append(filters, mapEntry("mohaa_stats.hitloc_stats_daily", "tenant_id = "+sqlQuote(tenantID)))
+	for _, table := range serverlessTables {
+		filters = append(filters, mapEntry("mohaa_stats."+table, "0"))
+	}
+	return "{" + strings.Join(filters, ",") + "}"
+}
+
+// sqlQuote quotes s as a ClickHouse string literal
+func sqlQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+func mapEntry(key, value string) string {
+	return sqlQuote(key) + ":" + sqlQuote(value)
+}
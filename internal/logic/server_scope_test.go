@@ -0,0 +1,40 @@
+package logic
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestServerScopeFilters(t *testing.T) {
+	got := serverScopeFilters("t-1", []string{"srv-2", "srv-1"})
+	for _, want := range []string{
+		`'mohaa_stats.raw_events':'server_id IN (\'srv-1\',\'srv-2\')'`,
+		`'mohaa_stats.hitloc_stats_daily':'tenant_id = \'t-1\''`,
+		`'mohaa_stats.player_stats_daily':'0'`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("filters %s missing %s", got, want)
+		}
+	}
+
+	// A tenant without servers sees nothing rather than everything
+	if got := serverScopeFilters("t-1", nil); !strings.Contains(got, `'mohaa_stats.raw_events':'0'`) {
+		t.Errorf("filters without servers = %s", got)
+	}
+
+	// Quotes in an id cannot end the filter's string literal
+	if got := serverScopeFilters("t-1", []string{`x') OR 1 --`}); !strings.Contains(got, `server_id IN (\'x\\\') OR 1 --\')`) {
+		t.Errorf("filters with a quoted id = %s", got)
+	}
+}
+
+func TestPlayerStatsTable(t *testing.T) {
+	ctx := context.Background()
+	if got := PlayerStatsTable(ctx); got != "mohaa_stats.player_stats_daily" {
+		t.Errorf("unscoped table = %s", got)
+	}
+	if got := PlayerStatsTable(WithServerScope(ctx, "t-1", []string{"srv-1"})); got != "mohaa_stats.player_server_stats_daily" {
+		t.Errorf("scoped table = %s", got)
+	}
+}
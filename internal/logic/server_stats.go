@@ -16,18 +16,22 @@ func NewServerStatsService(ch driver.Conn) ServerStatsService {
 }
 
 // GlobalActivity returns a heatmap of activity (Day of Week vs Hour of Day)
-func (s *serverStatsService) GetGlobalActivity(ctx context.Context) ([]map[string]interface{}, error) {
-	// Remove time filter to show all activity data (test data may have future dates)
+// over the period, with days and hours in the period's time zone
+func (s *serverStatsService) GetGlobalActivity(ctx context.Context, period Period) ([]map[string]interface{}, error) {
+	// All time unless asked otherwise (test data may have future dates)
+	periodExpr, periodArgs := period.TimeFilter("timestamp")
 	query := `
 		SELECT 
-			toDayOfWeek(toDateTime(timestamp)) as day_idx, -- 1=Mon, 7=Sun
-			toHour(toDateTime(timestamp)) as hour,
+			toDayOfWeek(toTimeZone(timestamp, ?)) as day_idx, -- 1=Mon, 7=Sun
+			toHour(toTimeZone(timestamp, ?)) as hour,
 			count() as intensity
 		FROM mohaa_stats.raw_events
+		WHERE 1 = 1` + periodExpr + `
 		GROUP BY day_idx, hour
 		ORDER BY day_idx, hour
 	`
-	rows, err := s.ch.Query(ctx, query)
+	args := append([]any{period.TimeZone(), period.TimeZone()}, periodArgs...)
+	rows, err := s.ch.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -112,16 +116,20 @@ func (s *serverStatsService) GetMapPopularity(ctx context.Context) ([]models.Map
 func (s *serverStatsService) GetGlobalStats(ctx context.Context) (map[string]interface{}, error) {
 	var totalKills, totalMatches, activePlayers, serverCount uint64
 
+	// Tenant requests are scoped to their servers, which only the per-server
+	// aggregate can filter
+	daily := PlayerStatsTable(ctx)
+
 	// Total Kills from aggregated daily stats
-	s.ch.QueryRow(ctx, "SELECT sum(kills) FROM mohaa_stats.player_stats_daily").Scan(&totalKills)
+	s.ch.QueryRow(ctx, "SELECT sum(kills) FROM "+daily).Scan(&totalKills)
 
 	// Total Matches (unique match IDs from raw events for accuracy)
 	s.ch.QueryRow(ctx, "SELECT uniq(match_id) FROM mohaa_stats.raw_events").Scan(&totalMatches)
 
 	// Active Players (last 24 hours)
-	if err := s.ch.QueryRow(ctx, "SELECT uniq(player_id) FROM mohaa_stats.player_stats_daily WHERE day >= today() - 1 AND player_id != ''").Scan(&activePlayers); err != nil {
+	if err := s.ch.QueryRow(ctx, "SELECT uniq(player_id) FROM "+daily+" WHERE day >= today() - 1 AND player_id != ''").Scan(&activePlayers); err != nil {
 		// Fallback to all-time if no recent activity (to show something in dev)
-		s.ch.QueryRow(ctx, "SELECT uniq(player_id) FROM mohaa_stats.player_stats_daily WHERE player_id != ''").Scan(&activePlayers)
+		s.ch.QueryRow(ctx, "SELECT uniq(player_id) FROM "+daily+" WHERE player_id != ''").Scan(&activePlayers)
 	}
 
 	// Server Count
@@ -132,16 +140,14 @@ func (s *serverStatsService) GetGlobalStats(ctx context.Context) (map[string]int
 	s.ch.QueryRow(ctx, `
 		SELECT
 			sum(shots_hit) / nullif(sum(shots_fired), 0) * 100
-		FROM mohaa_stats.player_stats_daily
-	`).Scan(&avgAccuracy)
+		FROM `+daily).Scan(&avgAccuracy)
 
 	// Average KD
 	var avgKD float64
 	s.ch.QueryRow(ctx, `
 		SELECT
 			sum(kills) / nullif(sum(deaths), 0)
-		FROM mohaa_stats.player_stats_daily
-	`).Scan(&avgKD)
+		FROM `+daily).Scan(&avgKD)
 
 	return map[string]interface{}{
 		"total_kills":         totalKills,
@@ -169,9 +175,9 @@ func (s *serverStatsService) GetServerPulse(ctx context.Context) (*models.Server
 	}
 
 	// 2. Total Lead Poured (all weapon hits)
-	// Using a simple count for now, optimized
+	// Weighted, so sampled hits count for the ones not stored
 	s.ch.QueryRow(ctx, `
-		SELECT count() FROM raw_events 
+		SELECT sum(sample_weight) FROM raw_events
 		WHERE event_type = 'weapon_hit' AND timestamp >= now() - INTERVAL 24 HOUR
 	`).Scan(&pulse.TotalLeadPoured)
 
@@ -2,25 +2,29 @@ package logic
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/openmohaa/stats-api/internal/db"
 	"github.com/openmohaa/stats-api/internal/models"
-	"github.com/redis/go-redis/v9"
 )
 
 // ServerTrackingService provides comprehensive server monitoring
 type ServerTrackingService struct {
 	ch    driver.Conn
 	pg    PgPool
-	redis RedisClient
+	redis db.LiveStateStore
 }
 
-func NewServerTrackingService(ch driver.Conn, pg *pgxpool.Pool, redis *redis.Client) *ServerTrackingService {
-	return &ServerTrackingService{ch: ch, pg: pg, redis: redis}
+func NewServerTrackingService(ch driver.Conn, pg *pgxpool.Pool, state db.LiveStateStore) *ServerTrackingService {
+	return &ServerTrackingService{ch: ch, pg: pg, redis: state}
 }
 
 // =============================================================================
@@ -31,13 +35,14 @@ func NewServerTrackingService(ch driver.Conn, pg *pgxpool.Pool, redis *redis.Cli
 
 // GetServerList returns all servers with live status
 func (s *ServerTrackingService) GetServerList(ctx context.Context) ([]models.ServerOverview, error) {
-	// Get registered servers from PostgreSQL
+	// Get registered servers from PostgreSQL (only the caller's tenant, if any)
 	rows, err := s.pg.Query(ctx, `
 		SELECT id, name, COALESCE(ip_address, address, ''), COALESCE(port, 0), COALESCE(region, ''), 
 		       total_matches, total_players, COALESCE(last_seen, created_at), is_active
 		FROM servers
+		WHERE $1 = '' OR tenant_id::text = $1
 		ORDER BY total_players DESC
-	`)
+	`, TenantFromContext(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get servers: %w", err)
 	}
@@ -73,7 +78,7 @@ func (s *ServerTrackingService) GetServerList(ctx context.Context) ([]models.Ser
 	}
 
 	// 1. Batch Redis: Get live data for all servers at once
-	liveServerMap, err := s.redis.HGetAll(ctx, "live_servers").Result()
+	liveServerMap, err := s.redis.HGetAll(ctx, "live_servers")
 	if err != nil {
 		fmt.Printf("[DEBUG] Redis HGetAll error: %v\n", err)
 	}
@@ -151,7 +156,7 @@ func (s *ServerTrackingService) GetServerGlobalStats(ctx context.Context) (*mode
 	`).Scan(&stats.TotalServers, &stats.OnlineServers)
 
 	// Get current players from Redis
-	liveServers, _ := s.redis.HGetAll(ctx, "live_servers").Result()
+	liveServers, _ := s.redis.HGetAll(ctx, "live_servers")
 	for _, data := range liveServers {
 		var players int
 		fmt.Sscanf(data, "players:%d", &players)
@@ -209,7 +214,7 @@ func (s *ServerTrackingService) GetServerDetail(ctx context.Context, serverID st
 	detail.DisplayName = fmt.Sprintf("%s:%d", detail.Name, detail.Port)
 
 	// Check live status
-	liveData, err := s.redis.HGet(ctx, "live_servers", serverID).Result()
+	liveData, err := s.redis.HGet(ctx, "live_servers", serverID)
 	if err == nil && liveData != "" {
 		detail.IsOnline = true
 		parseServerLiveData(liveData, nil) // Could parse current map/players
@@ -390,37 +395,78 @@ func (s *ServerTrackingService) GetServerPeakHours(ctx context.Context, serverID
 // ServerTopPlayer represents a top player on a specific server
 
 
-// GetServerTopPlayers returns top players for a specific server
-func (s *ServerTrackingService) GetServerTopPlayers(ctx context.Context, serverID string, limit int) ([]models.ServerTopPlayer, error) {
+// ErrUnsupportedServerStat is returned for leaderboard stats the per-server
+// aggregate does not record (objectives and playtime)
+var ErrUnsupportedServerStat = errors.New("stat is not tracked per server")
+
+// GetServerTopPlayers returns top players for a specific server ranked by any
+// stat from the global leaderboard whitelist that the per-server aggregate
+// records, optionally limited to a period.
+func (s *ServerTrackingService) GetServerTopPlayers(ctx context.Context, serverID, stat string, period Period, limit int) ([]models.ServerTopPlayer, error) {
 	if limit <= 0 {
 		limit = 25
 	}
+	if stat == "objectives" || stat == "playtime" {
+		return nil, ErrUnsupportedServerStat
+	}
 
-	// Deaths are counted from kill events where player is target_id
-	query := `
-		WITH deaths_cte AS (
-			SELECT target_id, count() as death_count
-			FROM raw_events
-			WHERE server_id = ? AND event_type IN ('player_kill', 'bot_killed') AND target_id != ''
-			GROUP BY target_id
+	orderExpr, havingExpr := LeaderboardStatExpr(stat)
+	periodExpr, periodArgs := period.DayFilter("day")
+	whereExpr := "server_id = ? AND player_id != ''" + periodExpr
+
+	// Aggregates keep their column names so the shared stat expressions resolve
+	query := fmt.Sprintf(`
+		SELECT player_id, name, kills, deaths, headshots, matches_played, last_seen,
+			toFloat64(ifNull(%s, 0)) AS value
+		FROM (
+			SELECT 
+				player_id,
+				argMax(player_name, last_active) AS name,
+				sum(kills) AS kills,
+				sum(bot_kills) AS bot_kills,
+				sum(deaths) AS deaths,
+				sum(headshots) AS headshots,
+				sum(shots_fired) AS shots_fired,
+				sum(shots_hit) AS shots_hit,
+				sum(total_damage) AS total_damage,
+				sum(bash_kills) AS bash_kills,
+				sum(grenade_kills) AS grenade_kills,
+				sum(roadkills) AS roadkills,
+				sum(telefrags) AS telefrags,
+				sum(crushed) AS crushed,
+				sum(teamkills) AS teamkills,
+				sum(suicides) AS suicides,
+				sum(reloads) AS reloads,
+				sum(weapon_swaps) AS weapon_swaps,
+				sum(no_ammo) AS no_ammo,
+				sum(distance_units) AS distance_units,
+				sum(sprinted) AS sprinted,
+				sum(swam) AS swam,
+				sum(driven) AS driven,
+				sum(jumps) AS jumps,
+				sum(crouch_events) AS crouch_events,
+				sum(prone_events) AS prone_events,
+				sum(ladders) AS ladders,
+				sum(health_picked) AS health_picked,
+				sum(ammo_picked) AS ammo_picked,
+				sum(armor_picked) AS armor_picked,
+				sum(items_picked) AS items_picked,
+				sum(matches_won) AS matches_won,
+				sum(ffa_wins) AS ffa_wins,
+				uniqExactMerge(matches_played) AS matches_played,
+				sum(games_finished) AS games_finished,
+				max(last_active) AS last_seen
+			FROM mohaa_stats.player_server_stats_daily
+			WHERE %s
+			GROUP BY player_id
+			HAVING %s
 		)
-		SELECT 
-			a.actor_id,
-			any(a.actor_name) as name,
-			countIf(a.event_type IN ('player_kill', 'bot_killed')) as kills,
-			ifNull(max(d.death_count), 0) as deaths,
-			countIf(a.event_type IN ('player_kill', 'bot_killed') AND a.hitloc IN ('head', 'helmet')) as headshots,
-			uniq(a.match_id) as sessions,
-			max(a.timestamp) as last_seen
-		FROM raw_events a
-		LEFT JOIN deaths_cte d ON a.actor_id = d.target_id
-		WHERE a.server_id = ? AND a.actor_id != ''
-		GROUP BY a.actor_id
-		ORDER BY kills DESC
+		ORDER BY value DESC
 		LIMIT ?
-	`
+	`, orderExpr, whereExpr, havingExpr)
 
-	rows, err := s.ch.Query(ctx, query, serverID, serverID, limit)
+	args := append([]any{serverID}, periodArgs...)
+	rows, err := s.ch.Query(ctx, query, append(args, limit)...)
 	if err != nil {
 		return nil, fmt.Errorf("top players query: %w", err)
 	}
@@ -430,11 +476,16 @@ func (s *ServerTrackingService) GetServerTopPlayers(ctx context.Context, serverI
 	rank := 1
 	for rows.Next() {
 		var p models.ServerTopPlayer
+		var kills, deaths, headshots, sessions uint64
 		var lastSeen time.Time
-		if err := rows.Scan(&p.GUID, &p.Name, &p.Kills, &p.Deaths, &p.Headshots, &p.Sessions, &lastSeen); err != nil {
+		if err := rows.Scan(&p.GUID, &p.Name, &kills, &deaths, &headshots, &sessions, &lastSeen, &p.Value); err != nil {
 			continue
 		}
 		p.Rank = rank
+		p.Kills = int64(kills)
+		p.Deaths = int64(deaths)
+		p.Headshots = int64(headshots)
+		p.Sessions = int64(sessions)
 		p.LastSeen = lastSeen.Format("2006-01-02 15:04")
 		if p.Deaths > 0 {
 			p.KDRatio = float64(p.Kills) / float64(p.Deaths)
@@ -608,27 +659,27 @@ func (s *ServerTrackingService) GetServerRecentMatches(ctx context.Context, serv
 // ActivityTimelinePoint represents activity at a point in time
 
 
-// GetServerActivityTimeline returns hourly activity for the last N days
-func (s *ServerTrackingService) GetServerActivityTimeline(ctx context.Context, serverID string, days int) ([]models.ActivityTimelinePoint, error) {
-	if days <= 0 {
-		days = 7
-	}
+// GetServerActivityTimeline returns hourly activity over the period, with
+// hours and timestamps in the period's time zone
+func (s *ServerTrackingService) GetServerActivityTimeline(ctx context.Context, serverID string, period Period) ([]models.ActivityTimelinePoint, error) {
+	periodExpr, periodArgs := period.TimeFilter("timestamp")
 
 	// Note: deaths = kills for global timeline stats (each kill = one death)
 	query := `
 		SELECT 
-			toStartOfHour(timestamp) as ts,
+			toStartOfHour(timestamp, ?) as ts,
 			countIf(event_type IN ('player_kill', 'bot_killed')) as kills,
 			countIf(event_type IN ('player_kill', 'bot_killed')) as deaths,
 			uniq(actor_id) as players,
 			countIf(event_type = 'match_start') as match_starts
 		FROM raw_events
-		WHERE server_id = ? AND timestamp > now() - INTERVAL ? DAY
+		WHERE server_id = ?` + periodExpr + `
 		GROUP BY ts
 		ORDER BY ts
 	`
 
-	rows, err := s.ch.Query(ctx, query, serverID, days)
+	args := append([]any{period.TimeZone(), serverID}, periodArgs...)
+	rows, err := s.ch.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("activity timeline query: %w", err)
 	}
@@ -641,7 +692,7 @@ func (s *ServerTrackingService) GetServerActivityTimeline(ctx context.Context, s
 		if err := rows.Scan(&ts, &p.Kills, &p.Deaths, &p.Players, &p.MatchStarts); err != nil {
 			continue
 		}
-		p.Timestamp = ts.Format(time.RFC3339)
+		p.Timestamp = ts.In(period.Location).Format(time.RFC3339)
 		points = append(points, p)
 	}
 
@@ -687,40 +738,92 @@ type TeamScores struct {
 	Axis   int `json:"axis"`
 }
 
-// GetLiveServerStatus returns real-time status for a server
+// GetLiveServerStatus returns real-time status for a server: its live match,
+// and the players on the match's scoreboard, which the worker keeps from the
+// roster v2 heartbeats carry
 func (s *ServerTrackingService) GetLiveServerStatus(ctx context.Context, serverID string) (*models.ServerLiveStatusResponse, error) {
-	status := &models.ServerLiveStatusResponse{}
+	status := &models.ServerLiveStatusResponse{Players: []models.RosterEntry{}}
 
 	// Get server info from Postgres
-	var name string
-	var maxPlayers int
 	s.pg.QueryRow(ctx, `
-		SELECT name, max_players FROM servers WHERE id = $1
-	`, serverID).Scan(&name, &maxPlayers)
-	
-	status.MaxPlayers = maxPlayers
-
-	// Get live data from Redis
-	matchData, err := s.redis.HGet(ctx, "live_matches", serverID).Result()
-	if err != nil || matchData == "" {
-		status.IsOnline = false
-		return status, nil
+		SELECT COALESCE(max_players, 32) FROM servers WHERE id = $1
+	`, serverID).Scan(&status.MaxPlayers)
+
+	// Between matches the heartbeat-fed server entry still shows it online
+	if data, err := s.redis.HGet(ctx, "live_servers", serverID); err == nil && data != "" {
+		var srv models.ServerOverview
+		parseServerLiveData(data, &srv)
+		status.IsOnline = true
+		status.CurrentMap, status.Gametype, status.CurrentPlayers = srv.CurrentMap, srv.Gametype, srv.CurrentPlayers
 	}
 
+	match, err := s.liveMatchForServer(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+	if match == nil {
+		return status, nil
+	}
 	status.IsOnline = true
-	// Parse match data (JSON format expected)
-	// This would need proper JSON parsing in production
-	// For now, assuming matchData contains some info
+	status.MatchID = match.MatchID
+	status.CurrentMap, status.Gametype = match.MapName, match.Gametype
+	status.AlliesScore, status.AxisScore, status.RoundNumber = match.AlliesScore, match.AxisScore, match.RoundNumber
+	status.CurrentPlayers = match.PlayerCount
 
-	// Get current players from Redis
-	playerData, _ := s.redis.HGetAll(ctx, "match:"+serverID+":players").Result()
-	
-	status.CurrentPlayers = len(playerData)
-	status.LastUpdate = time.Now().Format(time.RFC3339)
+	board, err := s.redis.HGetAll(ctx, "match:"+match.MatchID+":scoreboard")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scoreboard: %w", err)
+	}
+	for _, data := range board {
+		var entry models.RosterEntry
+		if json.Unmarshal([]byte(data), &entry) == nil {
+			status.Players = append(status.Players, entry)
+		}
+	}
+	sortScoreboard(status.Players)
+	if len(status.Players) > 0 {
+		status.CurrentPlayers = len(status.Players)
+	}
 
+	status.LastUpdate = time.Now().Format(time.RFC3339)
+	if seen, err := s.redis.HGet(ctx, "live_matches_seen", match.MatchID); err == nil {
+		if unix, err := strconv.ParseInt(seen, 10, 64); err == nil {
+			status.LastUpdate = time.Unix(unix, 0).UTC().Format(time.RFC3339)
+		}
+	}
 	return status, nil
 }
 
+// liveMatchForServer returns the server's most recently started live match,
+// or nil when it has none
+func (s *ServerTrackingService) liveMatchForServer(ctx context.Context, serverID string) (*models.LiveMatch, error) {
+	matches, err := s.redis.HGetAll(ctx, "live_matches")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read live matches: %w", err)
+	}
+	var latest *models.LiveMatch
+	for _, data := range matches {
+		var m models.LiveMatch
+		if json.Unmarshal([]byte(data), &m) != nil || m.ServerID != serverID {
+			continue
+		}
+		if latest == nil || m.StartedAt.After(latest.StartedAt) {
+			latest = &m
+		}
+	}
+	return latest, nil
+}
+
+// sortScoreboard orders players by score, then name
+func sortScoreboard(players []models.RosterEntry) {
+	sort.Slice(players, func(i, j int) bool {
+		if players[i].Score != players[j].Score {
+			return players[i].Score > players[j].Score
+		}
+		return players[i].Name < players[j].Name
+	})
+}
+
 // =============================================================================
 // SERVER RANKINGS
 // =============================================================================
@@ -842,15 +945,30 @@ func (s *ServerTrackingService) RemoveServerFavorite(ctx context.Context, userID
 
 // GetUserFavoriteServers returns user's favorite servers
 func (s *ServerTrackingService) GetUserFavoriteServers(ctx context.Context, userID int) ([]models.ServerOverview, error) {
+	return s.favoriteServers(ctx, `f.user_id = $1`, userID)
+}
+
+// GetPlayerFavoriteServers returns the favorite servers of the forum user a
+// player GUID is verified for, none when it is not linked
+func (s *ServerTrackingService) GetPlayerFavoriteServers(ctx context.Context, guid string) ([]models.ServerOverview, error) {
+	return s.favoriteServers(ctx, `f.user_id = (
+		SELECT forum_user_id FROM player_identities
+		WHERE player_guid = $1 AND verified
+		ORDER BY last_seen DESC LIMIT 1
+	)`, guid)
+}
+
+// favoriteServers returns the favorites matching where, with live status
+func (s *ServerTrackingService) favoriteServers(ctx context.Context, where string, arg any) ([]models.ServerOverview, error) {
 	rows, err := s.pg.Query(ctx, `
 		SELECT s.id, s.name, s.address, s.port, s.region, s.max_players,
 		       s.total_matches, s.total_players, s.last_seen, s.is_active,
 		       f.nickname, f.created_at
 		FROM server_favorites f
 		JOIN servers s ON f.server_id = s.id
-		WHERE f.user_id = $1
+		WHERE `+where+`
 		ORDER BY f.created_at DESC
-	`, userID)
+	`, arg)
 	if err != nil {
 		return nil, err
 	}
@@ -875,8 +993,24 @@ func (s *ServerTrackingService) GetUserFavoriteServers(ctx context.Context, user
 		} else {
 			srv.DisplayName = fmt.Sprintf("%s:%d", srv.Name, srv.Port)
 		}
+		srv.IsOnline = isActive && time.Since(srv.LastSeen) < 5*time.Minute
 		servers = append(servers, srv)
 	}
+	if len(servers) == 0 {
+		return servers, nil
+	}
+
+	// Live status as the server list shows it; favorites still list without it
+	live, err := s.redis.HGetAll(ctx, "live_servers")
+	if err != nil {
+		return servers, nil
+	}
+	for i := range servers {
+		if data, ok := live[servers[i].ID]; ok && data != "" {
+			servers[i].IsOnline = true
+			parseServerLiveData(data, &servers[i])
+		}
+	}
 	return servers, nil
 }
 
@@ -1005,6 +1139,111 @@ func (s *ServerTrackingService) GetServerHistoricalPlayers(ctx context.Context,
 	return players, totalCount, nil
 }
 
+// =============================================================================
+// PER-SERVER PLAYER PROFILE
+// =============================================================================
+
+// GetServerPlayerProfile returns a player's stats scoped to a single server,
+// including their rank among everyone who has played there
+func (s *ServerTrackingService) GetServerPlayerProfile(ctx context.Context, serverID, guid string) (*models.ServerPlayerProfile, error) {
+	profile := &models.ServerPlayerProfile{ServerID: serverID, GUID: guid}
+
+	var firstSeen, lastSeen time.Time
+	err := s.ch.QueryRow(ctx, `
+		SELECT 
+			argMaxIf(actor_name, timestamp, actor_id = ?) as name,
+			min(timestamp) as first_seen,
+			max(timestamp) as last_seen,
+			uniq(match_id) as sessions,
+			countIf(event_type IN ('player_kill', 'bot_killed') AND actor_id = ?) as kills,
+			countIf(event_type IN ('player_kill', 'bot_killed') AND target_id = ?) as deaths,
+			countIf(event_type IN ('player_kill', 'bot_killed') AND actor_id = ? AND hitloc IN ('head', 'helmet')) as headshots,
+			arrayElement(topKIf(1)(actor_weapon, event_type IN ('player_kill', 'bot_killed') AND actor_id = ? AND actor_weapon != ''), 1) as fav_weapon,
+			arrayElement(topKIf(1)(map_name, map_name != ''), 1) as fav_map
+		FROM raw_events
+		WHERE server_id = ? AND (actor_id = ? OR target_id = ?)
+	`, guid, guid, guid, guid, guid, serverID, guid, guid).Scan(
+		&profile.Name, &firstSeen, &lastSeen, &profile.Sessions,
+		&profile.Kills, &profile.Deaths, &profile.Headshots,
+		&profile.FavoriteWeapon, &profile.FavoriteMap,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("server player profile query: %w", err)
+	}
+	if profile.Sessions == 0 {
+		return nil, fmt.Errorf("player %s not found on server %s", guid, serverID)
+	}
+
+	profile.FirstSeen = firstSeen.Format("2006-01-02")
+	profile.LastSeen = lastSeen.Format("2006-01-02 15:04")
+	if profile.Deaths > 0 {
+		profile.KDRatio = float64(profile.Kills) / float64(profile.Deaths)
+	} else {
+		profile.KDRatio = float64(profile.Kills)
+	}
+	if profile.Kills > 0 {
+		profile.HSPercent = float64(profile.Headshots) / float64(profile.Kills) * 100
+	}
+
+	// Playtime: time between first and last event per match on this server
+	s.ch.QueryRow(ctx, `
+		SELECT sum(duration) / 3600.0 as hours
+		FROM (
+			SELECT match_id, toUnixTimestamp(max(timestamp)) - toUnixTimestamp(min(timestamp)) as duration
+			FROM raw_events
+			WHERE server_id = ? AND actor_id = ?
+			GROUP BY match_id
+		)
+	`, serverID, guid).Scan(&profile.PlaytimeHours)
+
+	// Kill and K/D rank among all players on this server
+	s.ch.QueryRow(ctx, `
+		SELECT 
+			countIf(kills > ?) + 1 as kill_rank,
+			countIf(kd > ?) + 1 as kd_rank
+		FROM (
+			SELECT 
+				player_id,
+				sum(k) as kills,
+				sum(d) as deaths,
+				if(deaths > 0, kills / deaths, toFloat64(kills)) as kd
+			FROM (
+				SELECT actor_id as player_id, toUInt64(1) as k, toUInt64(0) as d
+				FROM raw_events
+				WHERE server_id = ? AND event_type IN ('player_kill', 'bot_killed') AND actor_id != ''
+				UNION ALL
+				SELECT target_id as player_id, toUInt64(0) as k, toUInt64(1) as d
+				FROM raw_events
+				WHERE server_id = ? AND event_type IN ('player_kill', 'bot_killed') AND target_id != ''
+			)
+			GROUP BY player_id
+		)
+	`, profile.Kills, profile.KDRatio, serverID, serverID).Scan(&profile.Ranks.Kills, &profile.Ranks.KDRatio)
+
+	// Playtime rank, and total players for percentile context
+	s.ch.QueryRow(ctx, `
+		SELECT 
+			countIf(hours > ?) + 1 as playtime_rank,
+			count() as total_players
+		FROM (
+			SELECT actor_id, sum(duration) / 3600.0 as hours
+			FROM (
+				SELECT actor_id, match_id, toUnixTimestamp(max(timestamp)) - toUnixTimestamp(min(timestamp)) as duration
+				FROM raw_events
+				WHERE server_id = ? AND actor_id != ''
+				GROUP BY actor_id, match_id
+			)
+			GROUP BY actor_id
+		)
+	`, profile.PlaytimeHours, serverID).Scan(&profile.Ranks.Playtime, &profile.Ranks.TotalPlayers)
+
+	if profile.Ranks.TotalPlayers > 0 && profile.Ranks.Kills > 0 {
+		profile.Ranks.Percentile = float64(profile.Ranks.Kills) / float64(profile.Ranks.TotalPlayers) * 100
+	}
+
+	return profile, nil
+}
+
 // =============================================================================
 // MAP ROTATION ANALYSIS
 // =============================================================================
@@ -2,6 +2,7 @@ package logic
 
 import (
 	"context"
+	"errors"
 	"testing"
 )
 
@@ -29,3 +30,18 @@ func TestGetServerMapRotation_Performance(t *testing.T) {
 		t.Errorf("Expected %d Query calls (Optimized), got %d", expectedCalls, mockCH.QueryCalls)
 	}
 }
+
+func TestGetServerTopPlayers_UnsupportedStat(t *testing.T) {
+	mockCH := &MockConn{}
+	svc := NewServerTrackingService(mockCH, nil, nil)
+
+	for _, stat := range []string{"objectives", "playtime"} {
+		_, err := svc.GetServerTopPlayers(context.Background(), "server1", stat, Period{}, 10)
+		if !errors.Is(err, ErrUnsupportedServerStat) {
+			t.Errorf("stat %q: err = %v, want ErrUnsupportedServerStat", stat, err)
+		}
+	}
+	if mockCH.QueryCalls != 0 {
+		t.Errorf("Expected no queries, got %d", mockCH.QueryCalls)
+	}
+}
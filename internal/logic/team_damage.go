@@ -0,0 +1,116 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// GetServerGriefingReport ranks the players of a server by team kills and by
+// damage dealt to teammates over the last `days` days
+func (s *ServerTrackingService) GetServerGriefingReport(ctx context.Context, serverID string, days, limit int) (*models.GriefingReport, error) {
+	since := time.Now().UTC().AddDate(0, 0, -days)
+	players, err := s.teamDamage(ctx, serverID, "", since)
+	if err != nil {
+		return nil, err
+	}
+
+	killers, damagers := rankGriefers(players, limit)
+	return &models.GriefingReport{
+		ServerID:        serverID,
+		Days:            days,
+		Since:           since,
+		TopTeamkillers:  killers,
+		TopTeamDamagers: damagers,
+	}, nil
+}
+
+// GetPlayerTeamDamage returns a player's friendly fire over the last `days`
+// days, across all servers
+func (s *ServerTrackingService) GetPlayerTeamDamage(ctx context.Context, playerID string, days int) (*models.TeamDamageStats, error) {
+	since := time.Now().UTC().AddDate(0, 0, -days)
+	players, err := s.teamDamage(ctx, "", playerID, since)
+	if err != nil {
+		return nil, err
+	}
+	if len(players) == 0 {
+		return &models.TeamDamageStats{PlayerID: playerID}, nil
+	}
+	return &players[0], nil
+}
+
+// teamDamage sums friendly fire per attacker since a time, optionally for one
+// server and/or one player. Damage counts only when both players were on the
+// same side (allies/axis), so free-for-all matches are ignored; damage
+// events carry teams from this release on.
+func (s *ServerTrackingService) teamDamage(ctx context.Context, serverID, playerID string, since time.Time) ([]models.TeamDamageStats, error) {
+	tenantID := TenantFromContext(ctx)
+	rows, err := s.ch.Query(ctx, `
+		SELECT
+			actor_id,
+			argMax(actor_name, timestamp) AS name,
+			sumIf(sample_weight, event_type = 'player_teamkill') AS teamkills,
+			sumIf(damage * sample_weight, event_type = 'damage' AND same_team) AS team_damage,
+			uniqExact(match_id) AS matches
+		FROM (
+			SELECT actor_id, actor_name, timestamp, event_type, damage, sample_weight, match_id,
+				actor_team IN ('allies', 'axis') AND actor_team = target_team AND actor_id != target_id AS same_team
+			FROM mohaa_stats.raw_events
+			WHERE timestamp >= ?
+			  AND event_type IN ('player_teamkill', 'damage')
+			  AND actor_id != '' AND actor_id != 'world'
+			  AND (? = '' OR tenant_id = ?)
+			  AND (? = '' OR server_id = ?)
+			  AND (? = '' OR actor_id = ?)
+		)
+		GROUP BY actor_id
+		HAVING teamkills > 0 OR team_damage > 0
+	`, since, tenantID, tenantID, serverID, serverID, playerID, playerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query team damage: %w", err)
+	}
+	defer rows.Close()
+
+	var players []models.TeamDamageStats
+	for rows.Next() {
+		var p models.TeamDamageStats
+		if err := rows.Scan(&p.PlayerID, &p.PlayerName, &p.Teamkills, &p.TeamDamage, &p.Matches); err != nil {
+			return nil, fmt.Errorf("failed to scan team damage: %w", err)
+		}
+		players = append(players, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan team damage: %w", err)
+	}
+	return players, nil
+}
+
+// rankGriefers returns the top `limit` players by team kills and by team
+// damage, leaving out players with none of the ranked stat
+func rankGriefers(players []models.TeamDamageStats, limit int) (killers, damagers []models.TeamDamageStats) {
+	top := func(key func(p models.TeamDamageStats) uint64) []models.TeamDamageStats {
+		ranked := make([]models.TeamDamageStats, 0, len(players))
+		for _, p := range players {
+			if key(p) > 0 {
+				ranked = append(ranked, p)
+			}
+		}
+		sort.SliceStable(ranked, func(i, j int) bool {
+			if key(ranked[i]) != key(ranked[j]) {
+				return key(ranked[i]) > key(ranked[j])
+			}
+			return ranked[i].PlayerID < ranked[j].PlayerID
+		})
+		if limit > 0 && len(ranked) > limit {
+			ranked = ranked[:limit]
+		}
+		return ranked
+	}
+
+	killers = top(func(p models.TeamDamageStats) uint64 { return p.Teamkills })
+	damagers = top(func(p models.TeamDamageStats) uint64 { return p.TeamDamage })
+	return killers, damagers
+}
@@ -0,0 +1,30 @@
+package logic
+
+import (
+	"testing"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestRankGriefers(t *testing.T) {
+	players := []models.TeamDamageStats{
+		{PlayerID: "a", Teamkills: 1, TeamDamage: 900},
+		{PlayerID: "b", Teamkills: 6, TeamDamage: 100},
+		{PlayerID: "c", Teamkills: 0, TeamDamage: 400},
+		{PlayerID: "d", Teamkills: 6, TeamDamage: 0},
+	}
+
+	killers, damagers := rankGriefers(players, 2)
+
+	if len(killers) != 2 || killers[0].PlayerID != "b" || killers[1].PlayerID != "d" {
+		t.Errorf("killers = %+v, want b, d (ties by player ID)", killers)
+	}
+	if len(damagers) != 2 || damagers[0].PlayerID != "a" || damagers[1].PlayerID != "c" {
+		t.Errorf("damagers = %+v, want a, c", damagers)
+	}
+
+	killers, damagers = rankGriefers(nil, 10)
+	if killers == nil || damagers == nil {
+		t.Error("empty report should have non-nil lists")
+	}
+}
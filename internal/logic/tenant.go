@@ -0,0 +1,113 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// ErrUnknownTenant is returned when an API key does not match an active tenant
+var ErrUnknownTenant = errors.New("unknown tenant")
+
+type tenantKey struct{}
+
+// WithTenant scopes ctx to a tenant. An empty id means the default community,
+// which sees everything (single-tenant installs never set one).
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant set by WithTenant, or ""
+func TenantFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(tenantKey{}).(string)
+	return id
+}
+
+type sandboxKey struct{}
+
+// WithSandbox marks ctx as authenticated by a sandbox server, whose events are
+// stored apart from the live data
+func WithSandbox(ctx context.Context, sandbox bool) context.Context {
+	return context.WithValue(ctx, sandboxKey{}, sandbox)
+}
+
+// SandboxFromContext reports whether WithSandbox marked ctx
+func SandboxFromContext(ctx context.Context) bool {
+	sandbox, _ := ctx.Value(sandboxKey{}).(bool)
+	return sandbox
+}
+
+type adminKey struct{}
+
+// WithAdmin marks ctx as authenticated by an admin server's token
+func WithAdmin(ctx context.Context, admin bool) context.Context {
+	return context.WithValue(ctx, adminKey{}, admin)
+}
+
+// AdminFromContext reports whether WithAdmin marked ctx
+func AdminFromContext(ctx context.Context) bool {
+	admin, _ := ctx.Value(adminKey{}).(bool)
+	return admin
+}
+
+type tenantService struct {
+	pg PgPool
+}
+
+func NewTenantService(pg PgPool) TenantService {
+	return &tenantService{pg: pg}
+}
+
+// ResolveAPIKey looks up an active tenant by the SHA256 hash of its API key
+func (s *tenantService) ResolveAPIKey(ctx context.Context, keyHash string) (*models.Tenant, error) {
+	var t models.Tenant
+	err := s.pg.QueryRow(ctx, `
+		SELECT id::text, slug, name, is_active, created_at
+		FROM tenants
+		WHERE api_key = $1 AND is_active = true
+	`, keyHash).Scan(&t.ID, &t.Slug, &t.Name, &t.IsActive, &t.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrUnknownTenant
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve tenant: %w", err)
+	}
+	return &t, nil
+}
+
+// ServerIDs lists the servers owned by a tenant
+func (s *tenantService) ServerIDs(ctx context.Context, tenantID string) ([]string, error) {
+	rows, err := s.pg.Query(ctx, "SELECT id::text FROM servers WHERE tenant_id = $1", tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenant servers: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Create adds a tenant whose API key hashes to keyHash
+func (s *tenantService) Create(ctx context.Context, slug, name, keyHash string) (*models.Tenant, error) {
+	t := models.Tenant{Slug: slug, Name: name, IsActive: true}
+	err := s.pg.QueryRow(ctx, `
+		INSERT INTO tenants (slug, name, api_key)
+		VALUES ($1, $2, $3)
+		RETURNING id::text, created_at
+	`, slug, name, keyHash).Scan(&t.ID, &t.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tenant: %w", err)
+	}
+	return &t, nil
+}
@@ -0,0 +1,138 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+const (
+	// notableMatchKills is the fewest kills that make a match notable
+	notableMatchKills = 15
+	// notableMatchLimit caps the notable matches on a timeline: the player's best
+	notableMatchLimit = 10
+)
+
+type timelineService struct {
+	ch driver.Conn
+	pg PgPool
+}
+
+func NewTimelineService(ch driver.Conn, pg PgPool) TimelineService {
+	return &timelineService{ch: ch, pg: pg}
+}
+
+// GetPlayerTimeline merges a player's milestones, unlocked achievements (via
+// the SMF account linked to the GUID) and best matches, newest first
+func (s *timelineService) GetPlayerTimeline(ctx context.Context, guid string, limit int) (*models.PlayerTimeline, error) {
+	var entries []models.TimelineEntry
+	for _, source := range []func(context.Context, string, int) ([]models.TimelineEntry, error){
+		s.milestoneEntries, s.achievementEntries, s.matchEntries,
+	} {
+		e, err := source(ctx, guid, limit)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e...)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Timestamp.After(entries[j].Timestamp) })
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	if entries == nil {
+		entries = []models.TimelineEntry{}
+	}
+	return &models.PlayerTimeline{PlayerGUID: guid, Entries: entries}, nil
+}
+
+func (s *timelineService) milestoneEntries(ctx context.Context, guid string, limit int) ([]models.TimelineEntry, error) {
+	rows, err := s.pg.Query(ctx, `
+		SELECT milestone, title, value, match_id, achieved_at
+		FROM player_milestones
+		WHERE player_guid = $1
+		ORDER BY achieved_at DESC
+		LIMIT $2
+	`, guid, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read milestones: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []models.TimelineEntry
+	for rows.Next() {
+		e := models.TimelineEntry{Type: models.TimelineMilestone}
+		if err := rows.Scan(&e.Key, &e.Title, &e.Value, &e.MatchID, &e.Timestamp); err != nil {
+			return nil, fmt.Errorf("failed to read milestones: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func (s *timelineService) achievementEntries(ctx context.Context, guid string, limit int) ([]models.TimelineEntry, error) {
+	rows, err := s.pg.Query(ctx, `
+		SELECT a.achievement_code, a.achievement_name, a.description, a.points,
+			COALESCE(pa.match_id, ''), pa.unlocked_at
+		FROM player_guid_registry r
+		JOIN mohaa_player_achievements pa ON pa.smf_member_id = r.smf_member_id
+		JOIN mohaa_achievements a ON a.achievement_id = pa.achievement_id
+		WHERE r.player_guid = $1 AND pa.unlocked AND pa.unlocked_at IS NOT NULL
+		ORDER BY pa.unlocked_at DESC
+		LIMIT $2
+	`, guid, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read achievements: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []models.TimelineEntry
+	for rows.Next() {
+		e := models.TimelineEntry{Type: models.TimelineAchievement}
+		if err := rows.Scan(&e.Key, &e.Title, &e.Description, &e.Value, &e.MatchID, &e.Timestamp); err != nil {
+			return nil, fmt.Errorf("failed to read achievements: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// matchEntries returns the player's best matches by kills
+func (s *timelineService) matchEntries(ctx context.Context, guid string, limit int) ([]models.TimelineEntry, error) {
+	rows, err := s.ch.Query(ctx, `
+		SELECT
+			toString(match_id) AS match_id,
+			anyLast(map_name) AS map_name,
+			max(timestamp) AS ended,
+			toInt64(countIf(actor_id = ?)) AS kills,
+			toInt64(countIf(target_id = ?)) AS deaths
+		FROM mohaa_stats.raw_events
+		WHERE event_type = 'player_kill' AND (actor_id = ? OR target_id = ?)
+			AND match_id NOT IN (SELECT match_id FROM mohaa_stats.voided_matches FINAL WHERE voided = 1)
+		GROUP BY match_id
+		HAVING kills >= ?
+		ORDER BY kills DESC, ended DESC
+		LIMIT ?
+	`, guid, guid, guid, guid, notableMatchKills, min(limit, notableMatchLimit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notable matches: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []models.TimelineEntry
+	for rows.Next() {
+		var deaths int64
+		e := models.TimelineEntry{Type: models.TimelineMatch}
+		if err := rows.Scan(&e.MatchID, &e.MapName, &e.Timestamp, &e.Value, &deaths); err != nil {
+			return nil, fmt.Errorf("failed to read notable matches: %w", err)
+		}
+		e.Title = fmt.Sprintf("%d kills on %s", e.Value, e.MapName)
+		e.Description = fmt.Sprintf("%d kills, %d deaths", e.Value, deaths)
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
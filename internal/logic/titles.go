@@ -0,0 +1,391 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+var (
+	// ErrUnknownTitle is returned for a title code without a definition
+	ErrUnknownTitle = errors.New("unknown title")
+	// ErrInvalidTitle is returned for a definition that cannot be stored
+	ErrInvalidTitle = errors.New("invalid title")
+	// ErrTitleNotHeld is returned when none of the player's GUIDs hold the title
+	ErrTitleNotHeld = errors.New("title not held")
+	// ErrBadgeLimit is returned when equipping more than models.MaxEquippedBadges
+	ErrBadgeLimit = errors.New("too many badges equipped")
+)
+
+// TitleRuleStats maps the career stats a title rule can use to their
+// expressions over mohaa_stats.player_stats_daily
+var TitleRuleStats = map[string]string{
+	"kills":         "sum(kills)",
+	"headshots":     "sum(headshots)",
+	"wins":          "sum(matches_won)",
+	"matches":       "uniqExactMerge(matches_played)",
+	"bash_kills":    "sum(bash_kills)",
+	"grenade_kills": "sum(grenade_kills)",
+	"roadkills":     "sum(roadkills)",
+	"telefrags":     "sum(telefrags)",
+}
+
+// codePattern is the form of title and challenge codes
+var codePattern = regexp.MustCompile(`^[a-z0-9_]{1,64}$`)
+
+// ValidateTitle checks a definition before it is stored
+func ValidateTitle(t *models.Title) error {
+	if !codePattern.MatchString(t.Code) {
+		return fmt.Errorf("%w: code must be 1-64 lowercase letters, digits or underscores", ErrInvalidTitle)
+	}
+	if t.Name == "" || len(t.Name) > 100 {
+		return fmt.Errorf("%w: name must be 1-100 characters", ErrInvalidTitle)
+	}
+	if t.Kind != models.TitleKindTitle && t.Kind != models.TitleKindBadge {
+		return fmt.Errorf("%w: kind must be title or badge", ErrInvalidTitle)
+	}
+	if t.RuleStat != "" {
+		if _, ok := TitleRuleStats[t.RuleStat]; !ok {
+			return fmt.Errorf("%w: unknown rule stat %q", ErrInvalidTitle, t.RuleStat)
+		}
+		if t.RuleMin <= 0 {
+			return fmt.Errorf("%w: rule_min must be positive", ErrInvalidTitle)
+		}
+	}
+	return nil
+}
+
+type titlesService struct {
+	ch driver.Conn
+	pg PgPool
+}
+
+func NewTitlesService(ch driver.Conn, pg PgPool) TitlesService {
+	return &titlesService{ch: ch, pg: pg}
+}
+
+// List returns every title and badge definition
+func (s *titlesService) List(ctx context.Context) ([]models.Title, error) {
+	rows, err := s.pg.Query(ctx, `
+		SELECT code, name, description, kind, COALESCE(rule_stat, ''), rule_min
+		FROM titles
+		ORDER BY kind DESC, rule_stat NULLS LAST, rule_min, code
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read titles: %w", err)
+	}
+	defer rows.Close()
+
+	titles := []models.Title{}
+	for rows.Next() {
+		var t models.Title
+		if err := rows.Scan(&t.Code, &t.Name, &t.Description, &t.Kind, &t.RuleStat, &t.RuleMin); err != nil {
+			return nil, fmt.Errorf("failed to read titles: %w", err)
+		}
+		titles = append(titles, t)
+	}
+	return titles, rows.Err()
+}
+
+// Define creates or replaces a definition. Grants already made under an
+// older rule are kept.
+func (s *titlesService) Define(ctx context.Context, t *models.Title) error {
+	if err := ValidateTitle(t); err != nil {
+		return err
+	}
+	var ruleStat *string
+	if t.RuleStat != "" {
+		ruleStat = &t.RuleStat
+	}
+	if _, err := s.pg.Exec(ctx, `
+		INSERT INTO titles (code, name, description, kind, rule_stat, rule_min)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (code) DO UPDATE SET
+			name = EXCLUDED.name, description = EXCLUDED.description, kind = EXCLUDED.kind,
+			rule_stat = EXCLUDED.rule_stat, rule_min = EXCLUDED.rule_min
+	`, t.Code, t.Name, t.Description, t.Kind, ruleStat, t.RuleMin); err != nil {
+		return fmt.Errorf("failed to store title: %w", err)
+	}
+	return nil
+}
+
+// PlayerTitles returns the titles and badges a GUID holds
+func (s *titlesService) PlayerTitles(ctx context.Context, guid string) ([]models.PlayerTitle, error) {
+	return s.queryPlayerTitles(ctx, `
+		SELECT t.code, t.name, t.description, t.kind, COALESCE(t.rule_stat, ''), t.rule_min,
+			pt.source, pt.granted_at, pt.equipped
+		FROM player_titles pt
+		JOIN titles t ON t.code = pt.title_code
+		WHERE pt.player_guid = $1
+		ORDER BY t.kind DESC, pt.granted_at
+	`, guid)
+}
+
+// UserTitles returns the titles and badges held by any verified GUID of a
+// forum account, each once
+func (s *titlesService) UserTitles(ctx context.Context, forumUserID int) ([]models.PlayerTitle, error) {
+	return s.queryPlayerTitles(ctx, `
+		SELECT t.code, t.name, t.description, t.kind, COALESCE(t.rule_stat, ''), t.rule_min,
+			min(pt.source), min(pt.granted_at), bool_or(pt.equipped)
+		FROM player_titles pt
+		JOIN titles t ON t.code = pt.title_code
+		WHERE pt.player_guid IN (`+userGUIDs+`)
+		GROUP BY t.code
+		ORDER BY t.kind DESC, min(pt.granted_at)
+	`, forumUserID)
+}
+
+// userGUIDs selects the verified GUIDs of forum user $1
+const userGUIDs = `SELECT player_guid FROM player_identities WHERE forum_user_id = $1 AND verified`
+
+func (s *titlesService) queryPlayerTitles(ctx context.Context, query string, arg any) ([]models.PlayerTitle, error) {
+	rows, err := s.pg.Query(ctx, query, arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read player titles: %w", err)
+	}
+	defer rows.Close()
+
+	titles := []models.PlayerTitle{}
+	for rows.Next() {
+		var t models.PlayerTitle
+		if err := rows.Scan(&t.Code, &t.Name, &t.Description, &t.Kind, &t.RuleStat, &t.RuleMin,
+			&t.Source, &t.GrantedAt, &t.Equipped); err != nil {
+			return nil, fmt.Errorf("failed to read player titles: %w", err)
+		}
+		titles = append(titles, t)
+	}
+	return titles, rows.Err()
+}
+
+// Grant gives a GUID a title on behalf of an admin; granting one it holds is
+// a no-op
+func (s *titlesService) Grant(ctx context.Context, guid, code, grantedBy string) error {
+	tag, err := s.pg.Exec(ctx, `
+		INSERT INTO player_titles (player_guid, title_code, source, granted_by)
+		SELECT $1, code, 'admin', $3 FROM titles WHERE code = $2
+		ON CONFLICT (player_guid, title_code) DO NOTHING
+	`, guid, code, grantedBy)
+	if err != nil {
+		return fmt.Errorf("failed to grant title: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		if _, err := s.title(ctx, code); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Revoke takes a title from a GUID. Rule titles come back on the next sweep
+// while the player still qualifies.
+func (s *titlesService) Revoke(ctx context.Context, guid, code string) error {
+	tag, err := s.pg.Exec(ctx, `DELETE FROM player_titles WHERE player_guid = $1 AND title_code = $2`, guid, code)
+	if err != nil {
+		return fmt.Errorf("failed to revoke title: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrTitleNotHeld
+	}
+	return nil
+}
+
+func (s *titlesService) title(ctx context.Context, code string) (*models.Title, error) {
+	var t models.Title
+	err := s.pg.QueryRow(ctx, `
+		SELECT code, name, description, kind, COALESCE(rule_stat, ''), rule_min
+		FROM titles WHERE code = $1
+	`, code).Scan(&t.Code, &t.Name, &t.Description, &t.Kind, &t.RuleStat, &t.RuleMin)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrUnknownTitle
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read title: %w", err)
+	}
+	return &t, nil
+}
+
+// Equip shows a title or badge on every verified GUID of a forum account
+// that holds it. A title replaces the equipped one; badges stack up to
+// models.MaxEquippedBadges.
+func (s *titlesService) Equip(ctx context.Context, forumUserID int, code string) error {
+	t, err := s.title(ctx, code)
+	if err != nil {
+		return err
+	}
+
+	var held bool
+	var badges int
+	if err := s.pg.QueryRow(ctx, `
+		SELECT
+			COALESCE(bool_or(pt.title_code = $2), FALSE),
+			COALESCE(max(n.equipped), 0)
+		FROM player_titles pt
+		LEFT JOIN LATERAL (
+			SELECT count(*) AS equipped
+			FROM player_titles e JOIN titles et ON et.code = e.title_code
+			WHERE e.player_guid = pt.player_guid AND e.equipped AND et.kind = 'badge' AND e.title_code != $2
+		) n ON TRUE
+		WHERE pt.player_guid IN (`+userGUIDs+`) AND pt.title_code = $2
+	`, forumUserID, code).Scan(&held, &badges); err != nil {
+		return fmt.Errorf("failed to read equipped titles: %w", err)
+	}
+	if !held {
+		return ErrTitleNotHeld
+	}
+
+	if t.Kind == models.TitleKindBadge {
+		if badges >= models.MaxEquippedBadges {
+			return ErrBadgeLimit
+		}
+		_, err = s.pg.Exec(ctx, `
+			UPDATE player_titles SET equipped = TRUE
+			WHERE player_guid IN (`+userGUIDs+`) AND title_code = $2
+		`, forumUserID, code)
+	} else {
+		_, err = s.pg.Exec(ctx, `
+			UPDATE player_titles pt SET equipped = (pt.title_code = $2)
+			FROM titles t
+			WHERE t.code = pt.title_code AND t.kind = 'title'
+				AND pt.player_guid IN (SELECT player_guid FROM player_titles WHERE title_code = $2)
+				AND pt.player_guid IN (`+userGUIDs+`)
+		`, forumUserID, code)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to equip title: %w", err)
+	}
+	return nil
+}
+
+// Unequip hides a title or badge on every verified GUID of a forum account
+func (s *titlesService) Unequip(ctx context.Context, forumUserID int, code string) error {
+	tag, err := s.pg.Exec(ctx, `
+		UPDATE player_titles SET equipped = FALSE
+		WHERE player_guid IN (`+userGUIDs+`) AND title_code = $2
+	`, forumUserID, code)
+	if err != nil {
+		return fmt.Errorf("failed to unequip title: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrTitleNotHeld
+	}
+	return nil
+}
+
+// Equipped returns what each of the GUIDs has equipped; GUIDs with nothing
+// equipped are left out
+func (s *titlesService) Equipped(ctx context.Context, guids []string) (map[string]*models.PlayerCosmetics, error) {
+	cosmetics := make(map[string]*models.PlayerCosmetics)
+	if len(guids) == 0 {
+		return cosmetics, nil
+	}
+	rows, err := s.pg.Query(ctx, `
+		SELECT pt.player_guid, t.code, t.name, t.kind
+		FROM player_titles pt
+		JOIN titles t ON t.code = pt.title_code
+		WHERE pt.equipped AND pt.player_guid = ANY($1)
+		ORDER BY pt.granted_at
+	`, guids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read equipped titles: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var guid string
+		var kind models.TitleKind
+		var t models.EquippedTitle
+		if err := rows.Scan(&guid, &t.Code, &t.Name, &kind); err != nil {
+			return nil, fmt.Errorf("failed to read equipped titles: %w", err)
+		}
+		c := cosmetics[guid]
+		if c == nil {
+			c = &models.PlayerCosmetics{}
+			cosmetics[guid] = c
+		}
+		if kind == models.TitleKindTitle {
+			c.Title = &t
+		} else if len(c.Badges) < models.MaxEquippedBadges {
+			c.Badges = append(c.Badges, t)
+		}
+	}
+	return cosmetics, rows.Err()
+}
+
+// GrantByRules grants every rule title to the players whose career stat has
+// reached the rule's minimum. It returns the number of new grants.
+func (s *titlesService) GrantByRules(ctx context.Context) (int64, error) {
+	titles, err := s.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	// One ClickHouse pass per stat, lowest minimum first
+	byStat := make(map[string][]models.Title)
+	for _, t := range titles {
+		if _, ok := TitleRuleStats[t.RuleStat]; ok && t.RuleMin > 0 {
+			byStat[t.RuleStat] = append(byStat[t.RuleStat], t)
+		}
+	}
+
+	var granted int64
+	for stat, rules := range byStat {
+		sort.Slice(rules, func(i, j int) bool { return rules[i].RuleMin < rules[j].RuleMin })
+		n, err := s.grantStat(ctx, stat, rules)
+		if err != nil {
+			return granted, err
+		}
+		granted += n
+	}
+	return granted, nil
+}
+
+func (s *titlesService) grantStat(ctx context.Context, stat string, rules []models.Title) (int64, error) {
+	rows, err := s.ch.Query(ctx, fmt.Sprintf(`
+		SELECT player_id, toInt64(%s) AS value
+		FROM mohaa_stats.player_stats_daily
+		WHERE player_id != '' AND player_id != 'world'
+		GROUP BY player_id
+		HAVING value >= ?
+	`, TitleRuleStats[stat]), rules[0].RuleMin)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s for title rules: %w", stat, err)
+	}
+	defer rows.Close()
+
+	var guids, codes []string
+	for rows.Next() {
+		var guid string
+		var value int64
+		if err := rows.Scan(&guid, &value); err != nil {
+			return 0, fmt.Errorf("failed to read %s for title rules: %w", stat, err)
+		}
+		for _, r := range rules {
+			if value < r.RuleMin {
+				break
+			}
+			guids, codes = append(guids, guid), append(codes, r.Code)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read %s for title rules: %w", stat, err)
+	}
+	if len(guids) == 0 {
+		return 0, nil
+	}
+
+	tag, err := s.pg.Exec(ctx, `
+		INSERT INTO player_titles (player_guid, title_code, source)
+		SELECT guid, code, 'rule' FROM unnest($1::text[], $2::text[]) AS g(guid, code)
+		ON CONFLICT (player_guid, title_code) DO NOTHING
+	`, guids, codes)
+	if err != nil {
+		return 0, fmt.Errorf("failed to grant %s titles: %w", stat, err)
+	}
+	return tag.RowsAffected(), nil
+}
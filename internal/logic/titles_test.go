@@ -0,0 +1,43 @@
+package logic
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestValidateTitle(t *testing.T) {
+	valid := models.Title{Code: "sharpshooter", Name: "Sharpshooter", Kind: models.TitleKindTitle, RuleStat: "headshots", RuleMin: 1000}
+	if err := ValidateTitle(&valid); err != nil {
+		t.Fatalf("valid rule title rejected: %v", err)
+	}
+	admin := models.Title{Code: "founder", Name: "Founder", Kind: models.TitleKindBadge}
+	if err := ValidateTitle(&admin); err != nil {
+		t.Fatalf("admin badge rejected: %v", err)
+	}
+
+	tests := map[string]func(*models.Title){
+		"bad code":     func(t *models.Title) { t.Code = "Sharp Shooter" },
+		"empty name":   func(t *models.Title) { t.Name = "" },
+		"unknown kind": func(t *models.Title) { t.Kind = "medal" },
+		"unknown stat": func(t *models.Title) { t.RuleStat = "kills); DROP TABLE titles; --" },
+		"no minimum":   func(t *models.Title) { t.RuleMin = 0 },
+	}
+	for name, mutate := range tests {
+		title := valid
+		mutate(&title)
+		if err := ValidateTitle(&title); !errors.Is(err, ErrInvalidTitle) {
+			t.Errorf("%s: err = %v, want ErrInvalidTitle", name, err)
+		}
+	}
+}
+
+func TestTitleRuleStatsAreAggregates(t *testing.T) {
+	// Every seeded rule stat must be known, or its titles are never granted
+	for _, stat := range []string{"kills", "headshots", "wins", "bash_kills", "grenade_kills", "roadkills", "matches"} {
+		if _, ok := TitleRuleStats[stat]; !ok {
+			t.Errorf("seeded rule stat %q has no expression", stat)
+		}
+	}
+}
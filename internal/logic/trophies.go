@@ -0,0 +1,239 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+type trophiesService struct {
+	ch     driver.Conn
+	pg     PgPool
+	titles TitlesService
+}
+
+func NewTrophiesService(ch driver.Conn, pg PgPool, titles TitlesService) TrophiesService {
+	return &trophiesService{ch: ch, pg: pg, titles: titles}
+}
+
+// GetTrophyCase reads a player's achievements (via the SMF account linked to
+// the GUID), titles and badges, milestones, player of the day/week picks and
+// tournament results side by side
+func (s *trophiesService) GetTrophyCase(ctx context.Context, guid string) (*models.TrophyCase, error) {
+	tc := &models.TrophyCase{PlayerGUID: guid, GeneratedAt: time.Now().UTC()}
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() (err error) {
+		tc.Achievements, err = s.achievements(ctx, guid)
+		return err
+	})
+	g.Go(func() (err error) {
+		tc.Badges, err = s.titles.PlayerTitles(ctx, guid)
+		return err
+	})
+	g.Go(func() (err error) {
+		tc.Records, err = s.records(ctx, guid)
+		return err
+	})
+	g.Go(func() (err error) {
+		tc.Placements, err = s.placements(ctx, guid)
+		return err
+	})
+	g.Go(func() (err error) {
+		tc.Tournaments, err = s.tournaments(ctx, guid)
+		return err
+	})
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	if tc.Badges == nil {
+		tc.Badges = []models.PlayerTitle{}
+	}
+	return tc, nil
+}
+
+func (s *trophiesService) achievements(ctx context.Context, guid string) ([]models.UnlockedAchievement, error) {
+	rows, err := s.pg.Query(ctx, `
+		SELECT a.achievement_code, a.achievement_name, a.description, a.points,
+			COALESCE(a.tier::text, ''), COALESCE(a.icon_url, ''), pa.unlocked_at
+		FROM player_guid_registry r
+		JOIN mohaa_player_achievements pa ON pa.smf_member_id = r.smf_member_id
+		JOIN mohaa_achievements a ON a.achievement_id = pa.achievement_id
+		WHERE r.player_guid = $1 AND pa.unlocked AND pa.unlocked_at IS NOT NULL
+		ORDER BY pa.unlocked_at DESC
+	`, guid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read achievements: %w", err)
+	}
+	defer rows.Close()
+
+	achievements := []models.UnlockedAchievement{}
+	for rows.Next() {
+		var a models.UnlockedAchievement
+		if err := rows.Scan(&a.Slug, &a.Name, &a.Description, &a.Points, &a.Tier, &a.Icon, &a.UnlockedAt); err != nil {
+			return nil, fmt.Errorf("failed to read achievements: %w", err)
+		}
+		achievements = append(achievements, a)
+	}
+	return achievements, rows.Err()
+}
+
+func (s *trophiesService) records(ctx context.Context, guid string) ([]models.PlayerMilestone, error) {
+	rows, err := s.pg.Query(ctx, `
+		SELECT milestone, title, value, match_id, server_id, achieved_at
+		FROM player_milestones
+		WHERE player_guid = $1
+		ORDER BY achieved_at DESC
+	`, guid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read milestones: %w", err)
+	}
+	defer rows.Close()
+
+	records := []models.PlayerMilestone{}
+	for rows.Next() {
+		m := models.PlayerMilestone{PlayerGUID: guid}
+		if err := rows.Scan(&m.Milestone, &m.Title, &m.Value, &m.MatchID, &m.ServerID, &m.AchievedAt); err != nil {
+			return nil, fmt.Errorf("failed to read milestones: %w", err)
+		}
+		records = append(records, m)
+	}
+	return records, rows.Err()
+}
+
+// placements returns the player's picks as player of the day or week, on
+// servers and network-wide, within the request's tenant
+func (s *trophiesService) placements(ctx context.Context, guid string) ([]models.PlayerHighlight, error) {
+	rows, err := s.pg.Query(ctx, `
+		SELECT period, period_start, server_id, player_guid, player_name, score,
+			kills, deaths, headshots, matches_played, matches_won, computed_at
+		FROM player_highlights
+		WHERE player_guid = $1 AND tenant_id = $2
+		ORDER BY period_start DESC, period, server_id
+	`, guid, TenantFromContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read player highlights: %w", err)
+	}
+	defer rows.Close()
+
+	placements := []models.PlayerHighlight{}
+	for rows.Next() {
+		var h models.PlayerHighlight
+		if err := rows.Scan(&h.Period, &h.PeriodStart, &h.ServerID, &h.PlayerGUID, &h.PlayerName, &h.Score,
+			&h.Kills, &h.Deaths, &h.Headshots, &h.MatchesPlayed, &h.MatchesWon, &h.ComputedAt); err != nil {
+			return nil, fmt.Errorf("failed to read player highlights: %w", err)
+		}
+		placements = append(placements, h)
+	}
+	return placements, rows.Err()
+}
+
+// tournamentMatch is the player's line in one tournament match
+type tournamentMatch struct {
+	tournamentID string
+	name         string
+	won          bool
+	kills        int64
+	deaths       int64
+	ended        time.Time
+}
+
+// tournaments returns the player's results in tournament matches: the match
+// lifecycle in Postgres ties matches to tournaments, ClickHouse has the play
+func (s *trophiesService) tournaments(ctx context.Context, guid string) ([]models.TournamentResult, error) {
+	rows, err := s.pg.Query(ctx, `
+		SELECT ms.match_id, ms.tournament_id, COALESCE(t.name, '')
+		FROM match_states ms
+		LEFT JOIN tournaments t ON t.id::text = ms.tournament_id
+		WHERE ms.tournament_id != '' AND ms.state IN ('ended', 'finalized')
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tournament matches: %w", err)
+	}
+	defer rows.Close()
+
+	byMatch := make(map[string]tournamentMatch)
+	var matchIDs []string
+	for rows.Next() {
+		var matchID string
+		var m tournamentMatch
+		if err := rows.Scan(&matchID, &m.tournamentID, &m.name); err != nil {
+			return nil, fmt.Errorf("failed to read tournament matches: %w", err)
+		}
+		byMatch[matchID] = m
+		matchIDs = append(matchIDs, matchID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read tournament matches: %w", err)
+	}
+	if len(matchIDs) == 0 {
+		return []models.TournamentResult{}, nil
+	}
+
+	chRows, err := s.ch.Query(ctx, `
+		SELECT
+			toString(match_id) AS match_id,
+			max(event_type = 'match_outcome' AND actor_id = ? AND match_outcome = 1) AS won,
+			toInt64(countIf(event_type = 'player_kill' AND actor_id = ?)) AS kills,
+			toInt64(countIf(event_type = 'player_kill' AND target_id = ?)) AS deaths,
+			max(timestamp) AS ended
+		FROM mohaa_stats.raw_events
+		WHERE toString(match_id) IN ? AND (actor_id = ? OR target_id = ?)
+		GROUP BY match_id
+	`, guid, guid, guid, matchIDs, guid, guid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tournament results: %w", err)
+	}
+	defer chRows.Close()
+
+	var played []tournamentMatch
+	for chRows.Next() {
+		var matchID string
+		var won uint8
+		var kills, deaths int64
+		var ended time.Time
+		if err := chRows.Scan(&matchID, &won, &kills, &deaths, &ended); err != nil {
+			return nil, fmt.Errorf("failed to read tournament results: %w", err)
+		}
+		m := byMatch[matchID]
+		m.won, m.kills, m.deaths, m.ended = won == 1, kills, deaths, ended
+		played = append(played, m)
+	}
+	if err := chRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read tournament results: %w", err)
+	}
+	return tallyTournaments(played), nil
+}
+
+// tallyTournaments sums match lines per tournament, most recent first
+func tallyTournaments(matches []tournamentMatch) []models.TournamentResult {
+	index := make(map[string]int)
+	results := []models.TournamentResult{}
+	for _, m := range matches {
+		i, ok := index[m.tournamentID]
+		if !ok {
+			i = len(results)
+			index[m.tournamentID] = i
+			results = append(results, models.TournamentResult{TournamentID: m.tournamentID, Name: m.name})
+		}
+		r := &results[i]
+		r.Matches++
+		if m.won {
+			r.Wins++
+		}
+		r.Kills += m.kills
+		r.Deaths += m.deaths
+		if m.ended.After(r.LastPlayed) {
+			r.LastPlayed = m.ended
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].LastPlayed.After(results[j].LastPlayed) })
+	return results
+}
@@ -0,0 +1,32 @@
+package logic
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTallyTournaments(t *testing.T) {
+	day := time.Date(2026, 5, 1, 20, 0, 0, 0, time.UTC)
+	results := tallyTournaments([]tournamentMatch{
+		{tournamentID: "spring", name: "Spring Cup", won: true, kills: 20, deaths: 5, ended: day},
+		{tournamentID: "summer", name: "Summer Cup", kills: 8, deaths: 12, ended: day.AddDate(0, 2, 0)},
+		{tournamentID: "spring", name: "Spring Cup", kills: 10, deaths: 11, ended: day.Add(time.Hour)},
+	})
+	if len(results) != 2 {
+		t.Fatalf("got %d tournaments, want 2", len(results))
+	}
+	if results[0].TournamentID != "summer" {
+		t.Errorf("first tournament = %s, want the most recent (summer)", results[0].TournamentID)
+	}
+	spring := results[1]
+	if spring.Matches != 2 || spring.Wins != 1 || spring.Kills != 30 || spring.Deaths != 16 {
+		t.Errorf("spring = %+v, want 2 matches, 1 win, 30 kills, 16 deaths", spring)
+	}
+	if !spring.LastPlayed.Equal(day.Add(time.Hour)) {
+		t.Errorf("spring last played %v, want %v", spring.LastPlayed, day.Add(time.Hour))
+	}
+
+	if empty := tallyTournaments(nil); empty == nil || len(empty) != 0 {
+		t.Errorf("no matches = %v, want an empty list", empty)
+	}
+}
@@ -0,0 +1,172 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// turretEventTypes are the events read to pair turret sessions: entering and
+// leaving a turret, kills made from it or ending it, and match ends
+const turretEventTypes = `'turret_enter', 'turret_exit', 'player_kill', 'bot_killed', 'match_end'`
+
+// turretEvent is one event of a turret session. weapon is the turret for
+// turret_enter/turret_exit and the killer's weapon for kills.
+type turretEvent struct {
+	matchID   string
+	eventType string
+	actorID   string
+	targetID  string
+	weapon    string
+	ts        time.Time
+}
+
+// turretUsage pairs each player's turret_enter with their turret_exit to total
+// the uptime of every turret. A session also ends when the player is killed
+// or the match ends; sessions still open at the last event of a match end
+// there. Kills count for the turret the killer was on, or for their weapon
+// when it is a turret. Events must be ordered by match and timestamp.
+func turretUsage(events []turretEvent) []models.TurretUsage {
+	type session struct {
+		turret string
+		start  time.Time
+	}
+
+	byTurret := make(map[string]*models.TurretUsage)
+	turret := func(name string) *models.TurretUsage {
+		u, ok := byTurret[name]
+		if !ok {
+			u = &models.TurretUsage{Turret: name}
+			byTurret[name] = u
+		}
+		return u
+	}
+
+	open := make(map[string]session)
+	end := func(player string, at time.Time) {
+		if s, ok := open[player]; ok {
+			if at.After(s.start) {
+				turret(s.turret).UptimeSeconds += at.Sub(s.start).Seconds()
+			}
+			delete(open, player)
+		}
+	}
+	endAll := func(at time.Time) {
+		for player := range open {
+			end(player, at)
+		}
+	}
+
+	var match string
+	var last time.Time
+	for _, e := range events {
+		if e.matchID != match {
+			endAll(last)
+			match = e.matchID
+		}
+		last = e.ts
+
+		switch e.eventType {
+		case "turret_enter":
+			end(e.actorID, e.ts)
+			open[e.actorID] = session{turret: e.weapon, start: e.ts}
+			turret(e.weapon).Uses++
+		case "turret_exit":
+			end(e.actorID, e.ts)
+		case "match_end":
+			endAll(e.ts)
+		default:
+			if s, ok := open[e.actorID]; ok {
+				turret(s.turret).Kills++
+			} else if strings.Contains(strings.ToLower(e.weapon), "turret") {
+				turret(e.weapon).Kills++
+			}
+			end(e.targetID, e.ts)
+		}
+	}
+	endAll(last)
+
+	usage := make([]models.TurretUsage, 0, len(byTurret))
+	for _, u := range byTurret {
+		usage = append(usage, *u)
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].UptimeSeconds != usage[j].UptimeSeconds {
+			return usage[i].UptimeSeconds > usage[j].UptimeSeconds
+		}
+		if usage[i].Kills != usage[j].Kills {
+			return usage[i].Kills > usage[j].Kills
+		}
+		return usage[i].Turret < usage[j].Turret
+	})
+	return usage
+}
+
+// scanTurretEvents reads match_id, event_type, actor_id, target_id,
+// actor_weapon and timestamp rows
+func scanTurretEvents(rows driver.Rows) ([]turretEvent, error) {
+	defer rows.Close()
+
+	var events []turretEvent
+	for rows.Next() {
+		var e turretEvent
+		if err := rows.Scan(&e.matchID, &e.eventType, &e.actorID, &e.targetID, &e.weapon, &e.ts); err != nil {
+			return nil, fmt.Errorf("failed to scan turret events: %w", err)
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan turret events: %w", err)
+	}
+	return events, nil
+}
+
+// getTurrets reports the uptime and kills of each turret used in a match.
+// Matches on maps without mounted guns have none.
+func (s *matchReportService) getTurrets(ctx context.Context, matchID string) ([]models.TurretUsage, error) {
+	rows, err := s.ch.Query(ctx, `
+		SELECT toString(match_id), event_type, actor_id, target_id, actor_weapon, timestamp
+		FROM mohaa_stats.raw_events
+		WHERE match_id = toUUID(?) AND event_type IN (`+turretEventTypes+`)
+		ORDER BY timestamp ASC
+	`, matchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query turret events: %w", err)
+	}
+	events, err := scanTurretEvents(rows)
+	if err != nil {
+		return nil, err
+	}
+	return turretUsage(events), nil
+}
+
+// playerTurrets reports the uptime and kills of each turret a player manned,
+// read from the matches where they entered a turret or killed with one. The
+// weapons of the player's killers are dropped so their turret kills don't
+// count for the player.
+func (s *advancedStatsService) playerTurrets(ctx context.Context, guid string) ([]models.TurretUsage, error) {
+	rows, err := s.ch.Query(ctx, `
+		SELECT toString(match_id), event_type, actor_id, target_id, if(actor_id = ?, actor_weapon, ''), timestamp
+		FROM mohaa_stats.raw_events
+		WHERE event_type IN (`+turretEventTypes+`)
+		  AND (actor_id = ? OR target_id = ?)
+		  AND match_id IN (
+			SELECT match_id FROM mohaa_stats.raw_events
+			WHERE actor_id = ? AND (event_type = 'turret_enter' OR (event_type IN ('player_kill', 'bot_killed') AND actor_weapon LIKE '%turret%'))
+		  )
+		ORDER BY match_id, timestamp
+	`, guid, guid, guid, guid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query turret events: %w", err)
+	}
+	events, err := scanTurretEvents(rows)
+	if err != nil {
+		return nil, err
+	}
+	return turretUsage(events), nil
+}
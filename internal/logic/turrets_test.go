@@ -0,0 +1,47 @@
+package logic
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTurretUsage(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(s int) time.Time { return start.Add(time.Duration(s) * time.Second) }
+
+	events := []turretEvent{
+		{matchID: "m1", eventType: "turret_enter", actorID: "a", weapon: "mg42_bunker", ts: at(0)},
+		{matchID: "m1", eventType: "player_kill", actorID: "a", targetID: "x", weapon: "MG42", ts: at(10)},
+		{matchID: "m1", eventType: "turret_exit", actorID: "a", ts: at(30)},
+		// Killed on the turret: the session ends with the death
+		{matchID: "m1", eventType: "turret_enter", actorID: "b", weapon: "mg42_bunker", ts: at(40)},
+		{matchID: "m1", eventType: "player_kill", actorID: "x", targetID: "b", weapon: "kar98", ts: at(50)},
+		// A turret kill without turret_enter counts under the weapon
+		{matchID: "m1", eventType: "player_kill", actorID: "c", targetID: "x", weapon: "flak_turret", ts: at(55)},
+		// Still on the turret at match_end
+		{matchID: "m1", eventType: "turret_enter", actorID: "a", weapon: "mg42_bridge", ts: at(60)},
+		{matchID: "m1", eventType: "match_end", ts: at(65)},
+		// No match_end: the session ends at the last event of the match
+		{matchID: "m2", eventType: "turret_enter", actorID: "a", weapon: "mg42_bridge", ts: at(100)},
+		{matchID: "m2", eventType: "player_kill", actorID: "a", targetID: "y", weapon: "MG42", ts: at(103)},
+	}
+
+	usage := turretUsage(events)
+	if len(usage) != 3 {
+		t.Fatalf("usage = %+v, want 3 turrets", usage)
+	}
+	bunker, bridge, flak := usage[0], usage[1], usage[2]
+	if bunker.Turret != "mg42_bunker" || bunker.Uses != 2 || bunker.UptimeSeconds != 40 || bunker.Kills != 1 {
+		t.Errorf("bunker = %+v, want 2 uses, 40s, 1 kill", bunker)
+	}
+	if bridge.Turret != "mg42_bridge" || bridge.Uses != 2 || bridge.UptimeSeconds != 8 || bridge.Kills != 1 {
+		t.Errorf("bridge = %+v, want 2 uses, 8s, 1 kill", bridge)
+	}
+	if flak.Turret != "flak_turret" || flak.Uses != 0 || flak.Kills != 1 {
+		t.Errorf("flak = %+v, want 1 kill", flak)
+	}
+
+	if got := turretUsage([]turretEvent{{matchID: "m1", eventType: "player_kill", actorID: "a", weapon: "kar98"}}); len(got) != 0 {
+		t.Errorf("match without turrets reported %+v", got)
+	}
+}
@@ -0,0 +1,129 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// vehicleKillTypes are the kill events stored with the players' vehicles
+const vehicleKillTypes = `'player_kill', 'bot_killed', 'player_bash', 'player_roadkill', 'player_teamkill'`
+
+// vehicleKillSQL matches kills made from a vehicle. Roadkills count even when
+// the driver's vehicle_enter was missed.
+const vehicleKillSQL = `(event_type IN ('player_kill', 'bot_killed', 'player_bash', 'player_roadkill') AND (vehicle != '' OR event_type = 'player_roadkill'))`
+
+// driverSeatSQL matches the seats that drive the vehicle; kills from any
+// other seat are passenger kills. Vehicles without seat information count as
+// driven.
+const driverSeatSQL = `lower(vehicle_seat) IN ('', '0', 'driver', 'pilot')`
+
+// GetVehicleLeaderboard ranks players by kills made from one vehicle type
+func (s *advancedStatsService) GetVehicleLeaderboard(ctx context.Context, vehicle string, limit int) ([]models.VehicleLeaderboardEntry, error) {
+	tenantID := TenantFromContext(ctx)
+	rows, err := s.ch.Query(ctx, `
+		SELECT
+			player_id,
+			argMax(name, ts) AS player_name,
+			sum(kills) AS kills,
+			sum(roadkills) AS roadkills,
+			sum(passenger_kills) AS passenger_kills,
+			sum(uses) AS uses,
+			sum(deaths) AS deaths
+		FROM (
+			SELECT
+				actor_id AS player_id,
+				actor_name AS name,
+				timestamp AS ts,
+				toUInt64(`+vehicleKillSQL+`) AS kills,
+				toUInt64(event_type = 'player_roadkill') AS roadkills,
+				toUInt64(`+vehicleKillSQL+` AND NOT `+driverSeatSQL+`) AS passenger_kills,
+				toUInt64(event_type = 'vehicle_enter') AS uses,
+				toUInt64(0) AS deaths
+			FROM mohaa_stats.raw_events
+			WHERE vehicle = ? AND actor_id != '' AND actor_id != 'world'
+			  AND (? = '' OR tenant_id = ?)
+			UNION ALL
+			SELECT target_id, target_name, timestamp, 0, 0, 0, 0, 1
+			FROM mohaa_stats.raw_events
+			WHERE target_vehicle = ? AND target_id != '' AND event_type IN (`+vehicleKillTypes+`)
+			  AND (? = '' OR tenant_id = ?)
+		)
+		GROUP BY player_id
+		HAVING kills > 0 OR uses > 0
+		ORDER BY kills DESC, uses DESC
+		LIMIT ?
+	`, vehicle, tenantID, tenantID, vehicle, tenantID, tenantID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query vehicle leaderboard: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.VehicleLeaderboardEntry{}
+	for rows.Next() {
+		var e models.VehicleLeaderboardEntry
+		if err := rows.Scan(&e.PlayerID, &e.PlayerName, &e.Kills, &e.Roadkills, &e.PassengerKills, &e.Uses, &e.Deaths); err != nil {
+			return nil, fmt.Errorf("failed to scan vehicle leaderboard: %w", err)
+		}
+		e.Rank = len(entries) + 1
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// GetServerVehicleUsage reports how each vehicle type was used on a server
+// over the last `days` days
+func (s *ServerTrackingService) GetServerVehicleUsage(ctx context.Context, serverID string, days int) (*models.ServerVehicleReport, error) {
+	since := time.Now().UTC().AddDate(0, 0, -days)
+	rows, err := s.ch.Query(ctx, `
+		SELECT
+			v,
+			sum(uses) AS uses,
+			sum(users) AS users,
+			sum(kills) AS kills,
+			sum(roadkills) AS roadkills,
+			sum(passenger_kills) AS passenger_kills,
+			sum(crashes) AS crashes,
+			sum(deaths) AS deaths
+		FROM (
+			SELECT
+				vehicle AS v,
+				countIf(event_type = 'vehicle_enter') AS uses,
+				uniqExactIf(actor_id, event_type = 'vehicle_enter') AS users,
+				countIf(`+vehicleKillSQL+`) AS kills,
+				countIf(event_type = 'player_roadkill') AS roadkills,
+				countIf(`+vehicleKillSQL+` AND NOT `+driverSeatSQL+`) AS passenger_kills,
+				countIf(event_type = 'vehicle_crash') AS crashes,
+				toUInt64(0) AS deaths
+			FROM mohaa_stats.raw_events
+			WHERE server_id = ? AND timestamp >= ? AND vehicle != ''
+			GROUP BY v
+			UNION ALL
+			SELECT target_vehicle, 0, 0, 0, 0, 0, 0, count()
+			FROM mohaa_stats.raw_events
+			WHERE server_id = ? AND timestamp >= ? AND target_vehicle != '' AND event_type IN (`+vehicleKillTypes+`)
+			GROUP BY target_vehicle
+		)
+		GROUP BY v
+		ORDER BY uses DESC, kills DESC
+	`, serverID, since, serverID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query vehicle usage: %w", err)
+	}
+	defer rows.Close()
+
+	report := &models.ServerVehicleReport{ServerID: serverID, Days: days, Vehicles: []models.VehicleUsage{}}
+	for rows.Next() {
+		var u models.VehicleUsage
+		if err := rows.Scan(&u.Vehicle, &u.Uses, &u.Users, &u.Kills, &u.Roadkills, &u.PassengerKills, &u.Crashes, &u.Deaths); err != nil {
+			return nil, fmt.Errorf("failed to scan vehicle usage: %w", err)
+		}
+		report.Vehicles = append(report.Vehicles, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan vehicle usage: %w", err)
+	}
+	return report, nil
+}
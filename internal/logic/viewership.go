@@ -0,0 +1,204 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// spectatorSample is one row of match_spectators: a heartbeat's count
+// (delta 0) or a spectator joining (+1) or leaving (-1)
+type spectatorSample struct {
+	at        time.Time
+	spectator string
+	count     int
+	delta     int
+}
+
+// viewershipInterval picks the timeline resolution for a match that was
+// watched for span
+func viewershipInterval(span time.Duration) time.Duration {
+	switch {
+	case span <= 90*time.Minute:
+		return time.Minute
+	case span <= 6*time.Hour:
+		return 5 * time.Minute
+	}
+	return 15 * time.Minute
+}
+
+// foldViewership turns a match's samples, oldest first, into its timeline.
+// Heartbeat counts are taken as they are and joins and leaves are ignored
+// once a match has any; otherwise the spectators that joined and have not
+// left are counted, each once. The average is over the intervals.
+func foldViewership(samples []spectatorSample) *models.MatchViewership {
+	v := &models.MatchViewership{Series: []models.ViewershipPoint{}}
+	if len(samples) == 0 {
+		return v
+	}
+	interval := viewershipInterval(samples[len(samples)-1].at.Sub(samples[0].at))
+	v.IntervalSecs = int(interval.Seconds())
+
+	v.Source = models.ViewershipEvents
+	for _, s := range samples {
+		if s.delta == 0 {
+			v.Source = models.ViewershipHeartbeat
+			break
+		}
+	}
+
+	watching := map[string]bool{}
+	joined := map[string]bool{}
+	for _, s := range samples {
+		var now int
+		if v.Source == models.ViewershipHeartbeat {
+			if s.delta != 0 {
+				continue
+			}
+			now = s.count
+		} else {
+			if s.delta > 0 {
+				watching[s.spectator] = true
+				joined[s.spectator] = true
+			} else {
+				delete(watching, s.spectator)
+			}
+			now = len(watching)
+		}
+
+		bucket := s.at.UTC().Truncate(interval)
+		if n := len(v.Series); n == 0 || !v.Series[n-1].Timestamp.Equal(bucket) {
+			v.Series = append(v.Series, models.ViewershipPoint{Timestamp: bucket})
+		}
+		p := &v.Series[len(v.Series)-1]
+		p.Spectators = now
+		p.Peak = max(p.Peak, now)
+		v.PeakSpectators = max(v.PeakSpectators, now)
+	}
+	total := 0
+	for _, p := range v.Series {
+		total += p.Spectators
+	}
+	if len(v.Series) > 0 {
+		v.AvgSpectators = float64(total) / float64(len(v.Series))
+	}
+	v.UniqueViewers = len(joined)
+	return v
+}
+
+type viewershipService struct {
+	ch driver.Conn
+}
+
+func NewViewershipService(ch driver.Conn) ViewershipService {
+	return &viewershipService{ch: ch}
+}
+
+// Match returns how many watched a match over time
+func (s *viewershipService) Match(ctx context.Context, matchID string) (*models.MatchViewership, error) {
+	tenantID := TenantFromContext(ctx)
+	rows, err := s.ch.Query(ctx, `
+		SELECT timestamp, spectator_id, toInt64(spectators), toInt64(delta)
+		FROM mohaa_stats.match_spectators
+		WHERE match_id = toUUIDOrZero(?)
+		  AND (? = '' OR tenant_id = ?)
+		ORDER BY timestamp
+	`, matchID, tenantID, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query match spectators: %w", err)
+	}
+	defer rows.Close()
+
+	var samples []spectatorSample
+	for rows.Next() {
+		var sample spectatorSample
+		var count, delta int64
+		if err := rows.Scan(&sample.at, &sample.spectator, &count, &delta); err != nil {
+			return nil, fmt.Errorf("failed to scan match spectators: %w", err)
+		}
+		sample.count, sample.delta = int(count), int(delta)
+		samples = append(samples, sample)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan match spectators: %w", err)
+	}
+	v := foldViewership(samples)
+	v.MatchID = matchID
+	return v, nil
+}
+
+// Featured returns the most watched matches of the last `hours` hours, by
+// peak spectators, live ones included
+func (s *viewershipService) Featured(ctx context.Context, hours, limit int) ([]models.FeaturedMatch, error) {
+	since := time.Now().UTC().Add(-time.Duration(hours) * time.Hour)
+	matches, err := watchedMatches(ctx, s.ch, TenantFromContext(ctx), since, nil)
+	if err != nil {
+		return nil, err
+	}
+	featured := make([]models.FeaturedMatch, 0, len(matches))
+	for _, m := range matches {
+		if m.PeakSpectators > 0 {
+			featured = append(featured, *m)
+		}
+	}
+	sort.Slice(featured, func(i, j int) bool {
+		if featured[i].PeakSpectators != featured[j].PeakSpectators {
+			return featured[i].PeakSpectators > featured[j].PeakSpectators
+		}
+		return featured[i].AvgSpectators > featured[j].AvgSpectators
+	})
+	if len(featured) > limit {
+		featured = featured[:limit]
+	}
+	return featured, nil
+}
+
+// watchedMatches folds the spectator samples since `since` per match,
+// optionally only for matchIDs, keyed by match ID
+func watchedMatches(ctx context.Context, ch driver.Conn, tenantID string, since time.Time, matchIDs []string) (map[string]*models.FeaturedMatch, error) {
+	rows, err := ch.Query(ctx, `
+		SELECT toString(match_id), server_id, map_name, timestamp, spectator_id, toInt64(spectators), toInt64(delta)
+		FROM mohaa_stats.match_spectators
+		WHERE timestamp >= ?
+		  AND (? = '' OR tenant_id = ?)
+		  AND (? = 0 OR has(?, toString(match_id)))
+		ORDER BY match_id, timestamp
+	`, since, tenantID, tenantID, len(matchIDs), matchIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query match spectators: %w", err)
+	}
+	defer rows.Close()
+
+	matches := map[string]*models.FeaturedMatch{}
+	samples := map[string][]spectatorSample{}
+	for rows.Next() {
+		var matchID, serverID, mapName string
+		var sample spectatorSample
+		var count, delta int64
+		if err := rows.Scan(&matchID, &serverID, &mapName, &sample.at, &sample.spectator, &count, &delta); err != nil {
+			return nil, fmt.Errorf("failed to scan match spectators: %w", err)
+		}
+		sample.count, sample.delta = int(count), int(delta)
+		m := matches[matchID]
+		if m == nil {
+			m = &models.FeaturedMatch{MatchID: matchID, ServerID: serverID, MapName: mapName, FirstSeen: sample.at}
+			matches[matchID] = m
+		}
+		m.LastSeen = sample.at
+		samples[matchID] = append(samples[matchID], sample)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan match spectators: %w", err)
+	}
+
+	for matchID, m := range matches {
+		v := foldViewership(samples[matchID])
+		m.PeakSpectators, m.AvgSpectators, m.UniqueViewers = v.PeakSpectators, v.AvgSpectators, v.UniqueViewers
+	}
+	return matches, nil
+}
@@ -0,0 +1,88 @@
+package logic
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestViewershipInterval(t *testing.T) {
+	tests := []struct {
+		span time.Duration
+		want time.Duration
+	}{
+		{0, time.Minute},
+		{90 * time.Minute, time.Minute},
+		{2 * time.Hour, 5 * time.Minute},
+		{6 * time.Hour, 5 * time.Minute},
+		{12 * time.Hour, 15 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := viewershipInterval(tt.span); got != tt.want {
+			t.Errorf("viewershipInterval(%v) = %v, want %v", tt.span, got, tt.want)
+		}
+	}
+}
+
+func TestFoldViewership(t *testing.T) {
+	start := time.Date(2026, 10, 12, 20, 0, 0, 0, time.UTC)
+	at := func(secs int) time.Time { return start.Add(time.Duration(secs) * time.Second) }
+
+	t.Run("Empty", func(t *testing.T) {
+		v := foldViewership(nil)
+		if v.Source != "" || v.PeakSpectators != 0 || v.Series == nil || len(v.Series) != 0 {
+			t.Errorf("unexpected viewership %+v", v)
+		}
+	})
+
+	t.Run("Join And Leave", func(t *testing.T) {
+		v := foldViewership([]spectatorSample{
+			{at: at(0), spectator: "a", delta: 1},
+			{at: at(10), spectator: "b", delta: 1},
+			{at: at(20), spectator: "a", delta: 1}, // joined twice
+			{at: at(70), spectator: "c", delta: 1},
+			{at: at(80), spectator: "a", delta: -1},
+			{at: at(90), spectator: "b", delta: -1},
+			{at: at(130), spectator: "c", delta: -1},
+		})
+		if v.Source != models.ViewershipEvents || v.IntervalSecs != 60 {
+			t.Fatalf("source %q, interval %d", v.Source, v.IntervalSecs)
+		}
+		want := []models.ViewershipPoint{
+			{Timestamp: at(0), Spectators: 2, Peak: 2},
+			{Timestamp: at(60), Spectators: 1, Peak: 3},
+			{Timestamp: at(120), Spectators: 0, Peak: 0},
+		}
+		if len(v.Series) != len(want) {
+			t.Fatalf("series = %+v", v.Series)
+		}
+		for i := range want {
+			if v.Series[i] != want[i] {
+				t.Errorf("series[%d] = %+v, want %+v", i, v.Series[i], want[i])
+			}
+		}
+		if v.PeakSpectators != 3 || v.AvgSpectators != 1 || v.UniqueViewers != 3 {
+			t.Errorf("peak %d, avg %v, unique %d", v.PeakSpectators, v.AvgSpectators, v.UniqueViewers)
+		}
+	})
+
+	t.Run("Heartbeat Counts Win", func(t *testing.T) {
+		v := foldViewership([]spectatorSample{
+			{at: at(0), spectator: "a", delta: 1},
+			{at: at(5), count: 4},
+			{at: at(35), count: 6},
+			{at: at(65), count: 0},
+			{at: at(70), spectator: "b", delta: 1},
+		})
+		if v.Source != models.ViewershipHeartbeat {
+			t.Fatalf("source = %q", v.Source)
+		}
+		if len(v.Series) != 2 || v.Series[0].Spectators != 6 || v.Series[0].Peak != 6 || v.Series[1].Spectators != 0 {
+			t.Errorf("series = %+v", v.Series)
+		}
+		if v.PeakSpectators != 6 || v.AvgSpectators != 3 || v.UniqueViewers != 0 {
+			t.Errorf("peak %d, avg %v, unique %d", v.PeakSpectators, v.AvgSpectators, v.UniqueViewers)
+		}
+	})
+}
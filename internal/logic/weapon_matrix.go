@@ -0,0 +1,149 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// matrixHitlocs orders the hit location columns from head to feet. Locations
+// not listed are appended alphabetically.
+var matrixHitlocs = []string{
+	"head", "helmet", "neck",
+	"torso_upper", "torso_mid", "torso_lower", "pelvis",
+	"left_arm_upper", "right_arm_upper", "left_arm_lower", "right_arm_lower", "left_hand", "right_hand",
+	"left_leg_upper", "right_leg_upper", "left_leg_lower", "right_leg_lower", "left_foot", "right_foot",
+	"general", "unknown",
+}
+
+// matrixStances are the stance columns; the SQL folds stance aliases into these
+var matrixStances = []string{"stand", "crouch", "prone", "unknown"}
+
+// weaponKillCell is the kill count of one weapon/hitloc/stance combination
+type weaponKillCell struct {
+	weapon, hitloc, stance string
+	kills                  uint64
+}
+
+// GetWeaponKillMatrix returns the weapon x hitloc and weapon x stance kill
+// matrices of the network, or of one player if playerID is set, for the
+// `limit` weapons with the most kills
+func (s *serverStatsService) GetWeaponKillMatrix(ctx context.Context, playerID string, limit int) (*models.WeaponKillMatrix, error) {
+	tenantID := TenantFromContext(ctx)
+	rows, err := s.ch.Query(ctx, `
+		SELECT
+			actor_weapon,
+			if(hitloc = '', 'unknown', hitloc) AS loc,
+			multiIf(
+				actor_stance IN ('stand', 'standing'), 'stand',
+				actor_stance IN ('crouch', 'crouching'), 'crouch',
+				actor_stance = 'prone', 'prone',
+				'unknown'
+			) AS stance,
+			count() AS kills
+		FROM mohaa_stats.raw_events
+		WHERE event_type IN ('player_kill', 'bot_killed') AND actor_weapon != ''
+		  AND (? = '' OR tenant_id = ?)
+		  AND (? = '' OR actor_id = ?)`+RoundPhaseFilter(ctx, "")+`
+		GROUP BY actor_weapon, loc, stance
+	`, tenantID, tenantID, playerID, playerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query weapon kill matrix: %w", err)
+	}
+	defer rows.Close()
+
+	var cells []weaponKillCell
+	for rows.Next() {
+		var c weaponKillCell
+		if err := rows.Scan(&c.weapon, &c.hitloc, &c.stance, &c.kills); err != nil {
+			return nil, fmt.Errorf("failed to scan weapon kill matrix: %w", err)
+		}
+		cells = append(cells, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan weapon kill matrix: %w", err)
+	}
+
+	matrix := buildWeaponKillMatrix(cells, limit)
+	matrix.PlayerID = playerID
+	return matrix, nil
+}
+
+// buildWeaponKillMatrix lays out kill counts as dense grids, keeping the
+// `limit` weapons with the most kills (all of them if limit <= 0)
+func buildWeaponKillMatrix(cells []weaponKillCell, limit int) *models.WeaponKillMatrix {
+	kills := make(map[string]uint64)
+	seenLocs := make(map[string]bool)
+	for _, c := range cells {
+		kills[c.weapon] += c.kills
+		seenLocs[c.hitloc] = true
+	}
+
+	weapons := make([]string, 0, len(kills))
+	for weapon := range kills {
+		weapons = append(weapons, weapon)
+	}
+	sort.Slice(weapons, func(i, j int) bool {
+		if kills[weapons[i]] != kills[weapons[j]] {
+			return kills[weapons[i]] > kills[weapons[j]]
+		}
+		return weapons[i] < weapons[j]
+	})
+	if limit > 0 && len(weapons) > limit {
+		weapons = weapons[:limit]
+	}
+
+	// Known locations in body order, then any others, skipping empty columns
+	hitlocs := make([]string, 0, len(seenLocs))
+	var extra []string
+	for _, loc := range matrixHitlocs {
+		if seenLocs[loc] {
+			hitlocs = append(hitlocs, loc)
+			delete(seenLocs, loc)
+		}
+	}
+	for loc := range seenLocs {
+		extra = append(extra, loc)
+	}
+	sort.Strings(extra)
+	hitlocs = append(hitlocs, extra...)
+
+	row := make(map[string]int, len(weapons))
+	for i, weapon := range weapons {
+		row[weapon] = i
+	}
+	col := make(map[string]int, len(hitlocs))
+	for i, loc := range hitlocs {
+		col[loc] = i
+	}
+	stanceCol := make(map[string]int, len(matrixStances))
+	for i, stance := range matrixStances {
+		stanceCol[stance] = i
+	}
+
+	matrix := &models.WeaponKillMatrix{
+		Weapons:  weapons,
+		Hitlocs:  hitlocs,
+		Stances:  matrixStances,
+		ByHitloc: make([][]uint64, len(weapons)),
+		ByStance: make([][]uint64, len(weapons)),
+		Kills:    make([]uint64, len(weapons)),
+	}
+	for i := range weapons {
+		matrix.ByHitloc[i] = make([]uint64, len(hitlocs))
+		matrix.ByStance[i] = make([]uint64, len(matrixStances))
+	}
+	for _, c := range cells {
+		i, ok := row[c.weapon]
+		if !ok {
+			continue
+		}
+		matrix.ByHitloc[i][col[c.hitloc]] += c.kills
+		matrix.ByStance[i][stanceCol[c.stance]] += c.kills
+		matrix.Kills[i] += c.kills
+		matrix.Total += c.kills
+	}
+	return matrix
+}
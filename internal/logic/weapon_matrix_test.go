@@ -0,0 +1,39 @@
+package logic
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuildWeaponKillMatrix(t *testing.T) {
+	cells := []weaponKillCell{
+		{"kar98", "head", "prone", 5},
+		{"kar98", "torso_upper", "stand", 2},
+		{"thompson", "torso_upper", "stand", 10},
+		{"thompson", "left_foot", "crouch", 1},
+		{"thompson", "mystery", "unknown", 1},
+		{"colt45", "head", "stand", 1},
+	}
+
+	m := buildWeaponKillMatrix(cells, 2)
+
+	if want := []string{"thompson", "kar98"}; !reflect.DeepEqual(m.Weapons, want) {
+		t.Fatalf("weapons = %v, want %v", m.Weapons, want)
+	}
+	if want := []string{"head", "torso_upper", "left_foot", "mystery"}; !reflect.DeepEqual(m.Hitlocs, want) {
+		t.Fatalf("hitlocs = %v, want body order then unknown locations", m.Hitlocs)
+	}
+	if want := [][]uint64{{0, 10, 1, 1}, {5, 2, 0, 0}}; !reflect.DeepEqual(m.ByHitloc, want) {
+		t.Errorf("by_hitloc = %v, want %v", m.ByHitloc, want)
+	}
+	if want := [][]uint64{{10, 1, 0, 1}, {2, 0, 5, 0}}; !reflect.DeepEqual(m.ByStance, want) {
+		t.Errorf("by_stance = %v, want %v", m.ByStance, want)
+	}
+	if want := []uint64{12, 7}; !reflect.DeepEqual(m.Kills, want) || m.Total != 19 {
+		t.Errorf("kills = %v total %d, want %v total 19", m.Kills, m.Total, want)
+	}
+
+	if empty := buildWeaponKillMatrix(nil, 20); len(empty.Weapons) != 0 || empty.Hitlocs == nil || empty.ByHitloc == nil {
+		t.Errorf("empty matrix = %+v, want no weapons and non-nil grids", empty)
+	}
+}
@@ -195,43 +195,96 @@ type WeaponProgress struct {
 
 // VehicleStats represents vehicle-related statistics
 type VehicleStats struct {
-	VehicleUses   int64         `json:"vehicle_uses"`
-	VehicleKills  int64         `json:"vehicle_kills"`
-	VehicleDeaths int64         `json:"vehicle_deaths"`
-	TotalDriven   float64       `json:"total_driven_km"`
-	VehicleTypes  []VehicleType `json:"vehicle_types"`
-	TurretStats   TurretStats   `json:"turret_stats"`
+	VehicleUses    int64         `json:"vehicle_uses"`
+	VehicleKills   int64         `json:"vehicle_kills"`
+	DriverKills    int64         `json:"driver_kills"`
+	PassengerKills int64         `json:"passenger_kills"`
+	VehicleDeaths  int64         `json:"vehicle_deaths"`
+	TotalDriven    float64       `json:"total_driven_km"`
+	VehicleTypes   []VehicleType `json:"vehicle_types"`
+	TurretStats    TurretStats   `json:"turret_stats"`
 }
 
 type VehicleType struct {
-	VehicleName string  `json:"vehicle_name"`
-	Uses        int64   `json:"uses"`
-	Kills       int64   `json:"kills"`
-	Deaths      int64   `json:"deaths"`
-	DistanceKm  float64 `json:"distance_km"`
+	VehicleName    string  `json:"vehicle_name"`
+	Uses           int64   `json:"uses"`
+	Kills          int64   `json:"kills"`
+	PassengerKills int64   `json:"passenger_kills"`
+	Deaths         int64   `json:"deaths"`
+	DistanceKm     float64 `json:"distance_km"`
+}
+
+// VehicleLeaderboardEntry ranks a player in one vehicle type. Kills include
+// roadkills and kills from passenger seats.
+type VehicleLeaderboardEntry struct {
+	Rank           int    `json:"rank"`
+	PlayerID       string `json:"player_id"`
+	PlayerName     string `json:"player_name"`
+	NameFlagged    bool   `json:"name_flagged,omitempty"`
+	Kills          uint64 `json:"kills"`
+	Roadkills      uint64 `json:"roadkills"`
+	PassengerKills uint64 `json:"passenger_kills"`
+	Uses           uint64 `json:"uses"`
+	Deaths         uint64 `json:"deaths"` // killed while in the vehicle
+}
+
+// VehicleUsage is how one vehicle type was used on a server
+type VehicleUsage struct {
+	Vehicle        string `json:"vehicle"`
+	Uses           uint64 `json:"uses"`
+	Users          uint64 `json:"users"`
+	Kills          uint64 `json:"kills"`
+	Roadkills      uint64 `json:"roadkills"`
+	PassengerKills uint64 `json:"passenger_kills"`
+	Crashes        uint64 `json:"crashes"`
+	Deaths         uint64 `json:"deaths"`
+}
+
+// ServerVehicleReport lists a server's vehicle types by use
+type ServerVehicleReport struct {
+	ServerID string         `json:"server_id"`
+	Days     int            `json:"days"`
+	Vehicles []VehicleUsage `json:"vehicles"`
 }
 
 type TurretStats struct {
-	TurretUses   int64 `json:"turret_uses"`
-	TurretKills  int64 `json:"turret_kills"`
-	TurretDeaths int64 `json:"turret_deaths"`
+	TurretUses    int64         `json:"turret_uses"`
+	TurretKills   int64         `json:"turret_kills"`
+	TurretDeaths  int64         `json:"turret_deaths"`
+	UptimeSeconds float64       `json:"uptime_seconds"`
+	Turrets       []TurretUsage `json:"turrets"`
+}
+
+// TurretUsage is how long one mounted gun was manned and the kills made from
+// it. Kills count when the killer was on the turret or used a turret weapon.
+type TurretUsage struct {
+	Turret        string  `json:"turret"`
+	Uses          int64   `json:"uses"`
+	UptimeSeconds float64 `json:"uptime_seconds"`
+	Kills         int64   `json:"kills"`
 }
 
 // =============================================================================
 // GAME FLOW STATS
 // =============================================================================
 
-// GameFlowStats represents round/objective/team statistics
+// GameFlowStats represents round/objective/team statistics. Opening rounds
+// are each match's first round, the pistol-round analog; comeback rounds are
+// rounds won while the player's team trailed going in.
 type GameFlowStats struct {
-	RoundsPlayed     int64           `json:"rounds_played"`
-	RoundsWon        int64           `json:"rounds_won"`
-	RoundsLost       int64           `json:"rounds_lost"`
-	RoundWinRate     float64         `json:"round_win_rate"`
-	ObjectivesTotal  int64           `json:"objectives_total"`
-	ObjectivesByType []ObjectiveStat `json:"objectives_by_type"`
-	FirstBloods      int64           `json:"first_bloods"`
-	ClutchWins       int64           `json:"clutch_wins"`
-	TeamStats        TeamStats       `json:"team_stats"`
+	RoundsPlayed        int64           `json:"rounds_played"`
+	RoundsWon           int64           `json:"rounds_won"`
+	RoundsLost          int64           `json:"rounds_lost"`
+	RoundWinRate        float64         `json:"round_win_rate"`
+	OpeningRoundsPlayed int64           `json:"opening_rounds_played"`
+	OpeningRoundsWon    int64           `json:"opening_rounds_won"`
+	OpeningRoundWinRate float64         `json:"opening_round_win_rate"`
+	ComebackRounds      int64           `json:"comeback_rounds"`
+	ObjectivesTotal     int64           `json:"objectives_total"`
+	ObjectivesByType    []ObjectiveStat `json:"objectives_by_type"`
+	FirstBloods         int64           `json:"first_bloods"`
+	ClutchWins          int64           `json:"clutch_wins"`
+	TeamStats           TeamStats       `json:"team_stats"`
 }
 
 type ObjectiveStat struct {
@@ -284,11 +337,12 @@ type BotTypeStat struct {
 }
 
 type StatLeaderboardEntry struct {
-	Rank       int     `json:"rank"`
-	PlayerID   string  `json:"player_id"`
-	PlayerName string  `json:"player_name"`
-	Value      float64 `json:"value"`
-	Secondary  float64 `json:"secondary,omitempty"`
+	Rank        int     `json:"rank"`
+	PlayerID    string  `json:"player_id"`
+	PlayerName  string  `json:"player_name"`
+	NameFlagged bool    `json:"name_flagged,omitempty"`
+	Value       float64 `json:"value"`
+	Secondary   float64 `json:"secondary,omitempty"`
 }
 
 type WarRoomDataResponse struct {
@@ -335,10 +389,11 @@ type PeakLeaderboardResponse struct {
 }
 
 type PeakLeaderboardEntry struct {
-	Rank       int     `json:"rank"`
-	PlayerID   string  `json:"player_id"`
-	PlayerName string  `json:"player_name"`
-	Kills      int64   `json:"kills"`
-	Deaths     int64   `json:"deaths"`
-	KD         float64 `json:"kd"`
+	Rank        int     `json:"rank"`
+	PlayerID    string  `json:"player_id"`
+	PlayerName  string  `json:"player_name"`
+	NameFlagged bool    `json:"name_flagged,omitempty"`
+	Kills       int64   `json:"kills"`
+	Deaths      int64   `json:"deaths"`
+	KD          float64 `json:"kd"`
 }
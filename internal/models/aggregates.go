@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// AggregateDrift describes a single player/day whose materialized aggregate
+// no longer matches what raw_events says it should be
+type AggregateDrift struct {
+	PlayerID  string    `json:"player_id"`
+	Day       time.Time `json:"day"`
+	Stat      string    `json:"stat"`
+	Aggregate uint64    `json:"aggregate"`
+	Raw       uint64    `json:"raw"`
+	DriftPct  float64   `json:"drift_pct"`
+}
+
+// AggregateCheckReport is the result of comparing a sample of player
+// aggregates against a recomputation from raw_events
+type AggregateCheckReport struct {
+	Table          string           `json:"table"`
+	CheckedAt      time.Time        `json:"checked_at"`
+	Days           int              `json:"days"`
+	PlayersSampled int              `json:"players_sampled"`
+	RowsCompared   int              `json:"rows_compared"`
+	Tolerance      float64          `json:"tolerance"`
+	Drifted        []AggregateDrift `json:"drifted"`
+	Healthy        bool             `json:"healthy"`
+}
+
+// AggregateRebuildResult summarises a full rebuild of an aggregate table
+type AggregateRebuildResult struct {
+	Table    string   `json:"table"`
+	Views    []string `json:"views"`
+	Duration string   `json:"duration"`
+}
+
+// AggregateRebuildRequest is the body of POST /admin/aggregates/rebuild/targeted.
+// Every day on which one of the matches was played or one of the players had
+// events is rebuilt.
+type AggregateRebuildRequest struct {
+	MatchIDs  []string `json:"match_ids,omitempty"`
+	PlayerIDs []string `json:"player_ids,omitempty"`
+	Reason    string   `json:"reason,omitempty"`
+}
+
+// AggregateRebuildStatus is where a targeted rebuild job is
+type AggregateRebuildStatus string
+
+const (
+	AggregateRebuildQueued  AggregateRebuildStatus = "queued"
+	AggregateRebuildRunning AggregateRebuildStatus = "running"
+	AggregateRebuildDone    AggregateRebuildStatus = "done"
+	AggregateRebuildFailed  AggregateRebuildStatus = "failed"
+)
+
+// AggregateRebuildJob tracks a targeted rebuild of the player aggregates and
+// the leaderboard snapshot. Progress counts rebuilt table-days.
+type AggregateRebuildJob struct {
+	ID              string                  `json:"id"`
+	Status          AggregateRebuildStatus  `json:"status"`
+	Request         AggregateRebuildRequest `json:"request"`
+	Tables          []string                `json:"tables"`
+	Days            int                     `json:"days"`
+	Total           int                     `json:"total"`
+	Done            int                     `json:"done"`
+	Progress        float64                 `json:"progress"`
+	Current         string                  `json:"current,omitempty"`
+	LeaderboardRows int                     `json:"leaderboard_rows"`
+	Error           string                  `json:"error,omitempty"`
+	CreatedAt       time.Time               `json:"created_at"`
+	StartedAt       *time.Time              `json:"started_at,omitempty"`
+	FinishedAt      *time.Time              `json:"finished_at,omitempty"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// Alert is an operator alert rule whose condition holds, for one subject:
+// a server for per-server rules, empty for global ones
+type Alert struct {
+	Rule        string     `json:"rule"` // ingest_lag, queue_depth, server_offline, clickhouse_errors
+	Key         string     `json:"key,omitempty"`
+	Subject     string     `json:"subject,omitempty"` // server name
+	Message     string     `json:"message"`
+	Value       float64    `json:"value"`
+	Threshold   float64    `json:"threshold"`
+	FiringSince time.Time  `json:"firing_since"`
+	Silenced    bool       `json:"silenced"`
+	SilenceID   string     `json:"silence_id,omitempty"`
+	EvaluatedAt time.Time  `json:"evaluated_at"`
+	ResolvedAt  *time.Time `json:"resolved_at,omitempty"`
+}
+
+// AlertRule is a configured rule and its threshold; disabled rules have a
+// zero threshold
+type AlertRule struct {
+	Name      string  `json:"name"`
+	Enabled   bool    `json:"enabled"`
+	Threshold float64 `json:"threshold"`
+	Unit      string  `json:"unit"`
+}
+
+// AlertSilence mutes the notifications of one rule until Until, for one key
+// or, when Key is empty, all of them
+type AlertSilence struct {
+	ID        string    `json:"id"`
+	Rule      string    `json:"rule"`
+	Key       string    `json:"key,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	Until     time.Time `json:"until"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AlertState is the response of /admin/alerts
+type AlertState struct {
+	EvaluatedAt *time.Time     `json:"evaluated_at"`
+	Rules       []AlertRule    `json:"rules"`
+	Firing      []Alert        `json:"firing"`
+	Silences    []AlertSilence `json:"silences"`
+}
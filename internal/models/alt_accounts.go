@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// PlayerConnection is a player connecting from a hashed client IP
+type PlayerConnection struct {
+	IPHash     string
+	PlayerGUID string
+	ServerID   string
+	At         time.Time
+}
+
+// AltAccount is a GUID that connected from the same client IPs as another.
+// SharedHashes counts the IP hashes they have in common; Connections and
+// the times are the alt's own on those hashes.
+type AltAccount struct {
+	PlayerGUID   string    `json:"player_guid"`
+	PlayerName   string    `json:"player_name,omitempty"`
+	SharedHashes int       `json:"shared_hashes"`
+	Connections  int       `json:"connections"`
+	FirstSeen    time.Time `json:"first_seen"`
+	LastSeen     time.Time `json:"last_seen"`
+	Banned       bool      `json:"banned"`
+}
+
+// BanEvasionSuspect is a GUID still playing from the client IPs of a banned
+// player
+type BanEvasionSuspect struct {
+	BannedGUID  string     `json:"banned_guid"`
+	BanReason   string     `json:"ban_reason,omitempty"`
+	BannedUntil *time.Time `json:"banned_until,omitempty"` // nil for permanent bans
+	AltAccount
+}
@@ -0,0 +1,18 @@
+package models
+
+// AnalyticsView describes one of the stable ClickHouse views for BI tools
+type AnalyticsView struct {
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	Grain       string                `json:"grain"`     // columns that identify a row
+	Available   bool                  `json:"available"` // false until its migration has run
+	Columns     []AnalyticsViewColumn `json:"columns"`
+}
+
+// AnalyticsViewColumn is a documented view column; Type is the ClickHouse type
+// as deployed
+type AnalyticsViewColumn struct {
+	Name        string `json:"name"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description"`
+}
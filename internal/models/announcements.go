@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// Announcement types a game server can print in chat
+const (
+	AnnouncementAchievement = "achievement_unlock"
+	AnnouncementRecord      = "record_break"
+	AnnouncementRankUp      = "rank_up"
+)
+
+// Announcement is a message for the game server running the match. Message is
+// ready to print; the other fields let mods format their own.
+type Announcement struct {
+	ID          int64     `json:"id"` // increasing per match
+	Type        string    `json:"type"`
+	MatchID     string    `json:"match_id"`
+	ServerID    string    `json:"server_id"`
+	PlayerGUID  string    `json:"player_guid,omitempty"`
+	PlayerSMFID int64     `json:"player_smf_id,omitempty"`
+	PlayerName  string    `json:"player_name,omitempty"`
+	Title       string    `json:"title"`           // achievement, record or rank name
+	Value       int64     `json:"value,omitempty"` // streak length or kill count
+	Message     string    `json:"message"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// AnnouncementBatch is a poll result. Pass Cursor as `after` on the next poll
+// to acknowledge these announcements.
+type AnnouncementBatch struct {
+	MatchID       string         `json:"match_id"`
+	Announcements []Announcement `json:"announcements"`
+	Cursor        int64          `json:"cursor"`
+}
+
+// KillfeedContext enriches a killfeed line. Counts cover the events the API
+// has processed, so the kill being reported is usually not in them yet.
+type KillfeedContext struct {
+	MatchID         string     `json:"match_id,omitempty"`
+	AttackerGUID    string     `json:"attacker_guid"`
+	VictimGUID      string     `json:"victim_guid,omitempty"`
+	AttackerStreak  int64      `json:"attacker_streak"` // kills since the attacker's last death this match
+	VictimStreak    int64      `json:"victim_streak"`   // streak the kill ends
+	HeadToHead      HeadToHead `json:"head_to_head"`
+	AttackerKills   int64      `json:"attacker_kills"`
+	Rank            string     `json:"rank,omitempty"`
+	NextRank        string     `json:"next_rank,omitempty"`
+	KillsToNextRank int64      `json:"kills_to_next_rank,omitempty"`
+}
+
+// HeadToHead counts kills between two players across matches
+type HeadToHead struct {
+	AttackerKills int64 `json:"attacker_kills"`
+	VictimKills   int64 `json:"victim_kills"`
+}
@@ -33,11 +33,36 @@ type RegisterServerRequest struct {
 	Name      string     `json:"name"`
 	IPAddress string     `json:"ip_address"`
 	Port      FlexString `json:"port"`
+
+	// Self-registration: a community invite code registers the server as
+	// pending until an admin approves it
+	InviteCode  string `json:"invite_code,omitempty"`
+	Region      string `json:"region,omitempty"`
+	Description string `json:"description,omitempty"`
 }
 
 type RegisterServerResponse struct {
 	ServerID string `json:"server_id"`
 	Token    string `json:"token"`
+	Status   string `json:"status,omitempty"` // approval status for self-registered servers
+}
+
+// IngestRejection explains why one event in a batch was not accepted. Line
+// is the 1-based line of the event in newline-delimited v1 batches.
+type IngestRejection struct {
+	Index int    `json:"index"`
+	Line  int    `json:"line,omitempty"`
+	Error string `json:"error"`
+}
+
+// IngestResponse is returned by the v2 ingest endpoint. Accepted events are
+// queued; dropped events were valid but the queue was full and may be retried.
+type IngestResponse struct {
+	Status   string            `json:"status"`
+	Version  int               `json:"version"`
+	Accepted int               `json:"accepted"`
+	Dropped  int               `json:"dropped"`
+	Rejected []IngestRejection `json:"rejected"`
 }
 
 type DeviceAuthRequest struct {
@@ -0,0 +1,77 @@
+package models
+
+import "time"
+
+// Challenge metrics: what a challenge counts
+const (
+	ChallengeKills     = "kills"
+	ChallengeHeadshots = "headshots"
+	ChallengeBashKills = "bash_kills"
+	ChallengeRoadkills = "roadkills"
+	ChallengeWins      = "wins"
+	ChallengeMatches   = "matches" // finished matches, won or not
+	ChallengeWeapons   = "weapons" // distinct weapons killed with
+)
+
+// QuestChainOnboarding is the quest chain new players are enrolled in
+const QuestChainOnboarding = "onboarding"
+
+// Challenge is an objective counted per player. Weapons are lowercase
+// fragments of weapon names (any weapon when empty); MapPrefix limits it to
+// maps of a game type such as "dm" or "obj".
+type Challenge struct {
+	Code        string   `json:"code"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Metric      string   `json:"metric"`
+	Weapons     []string `json:"weapons,omitempty"`
+	MapPrefix   string   `json:"map_prefix,omitempty"`
+	Target      int64    `json:"target"`
+	Enabled     bool     `json:"enabled"`
+}
+
+// WeeklyChallenges are the challenges running in one week
+type WeeklyChallenges struct {
+	WeekStart  time.Time   `json:"week_start"`
+	EndsAt     time.Time   `json:"ends_at"`
+	Challenges []Challenge `json:"challenges"`
+}
+
+// ChallengeProgress is a player's progress on one challenge
+type ChallengeProgress struct {
+	Challenge
+	Progress    int64      `json:"progress"`
+	Completed   bool       `json:"completed"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// PlayerChallenges is a player's progress on the week's challenges
+type PlayerChallenges struct {
+	PlayerGUID string              `json:"player_guid"`
+	WeekStart  time.Time           `json:"week_start"`
+	EndsAt     time.Time           `json:"ends_at"`
+	Challenges []ChallengeProgress `json:"challenges"`
+}
+
+// ChallengeCompletion is a player finishing a challenge
+type ChallengeCompletion struct {
+	WeekStart   time.Time `json:"week_start"`
+	Code        string    `json:"code"`
+	Title       string    `json:"title"`
+	PlayerGUID  string    `json:"player_guid"`
+	PlayerName  string    `json:"player_name"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// PlayerQuest is a player's progress on a quest chain. Steps are counted side
+// by side; CurrentStep is the index of the first unfinished one, or
+// len(Steps) once the chain is complete.
+type PlayerQuest struct {
+	PlayerGUID  string              `json:"player_guid"`
+	Chain       string              `json:"chain"`
+	EnrolledAt  time.Time           `json:"enrolled_at"`
+	ExpiresAt   time.Time           `json:"expires_at"`
+	CompletedAt *time.Time          `json:"completed_at,omitempty"`
+	CurrentStep int                 `json:"current_step"`
+	Steps       []ChallengeProgress `json:"steps"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// ContentFilterMode is what the content filter does with offensive names and
+// chat on the way out
+type ContentFilterMode string
+
+const (
+	ContentFilterOff  ContentFilterMode = "off"
+	ContentFilterFlag ContentFilterMode = "flag" // served as is, marked as flagged
+	ContentFilterMask ContentFilterMode = "mask" // offensive words starred out
+)
+
+// ContentOverrideAction is an admin decision for one player's name
+type ContentOverrideAction string
+
+const (
+	ContentOverrideAllow ContentOverrideAction = "allow"
+	ContentOverrideBlock ContentOverrideAction = "block"
+)
+
+// ContentFilterOverride exempts a player's name from the filter or always
+// filters it
+type ContentFilterOverride struct {
+	PlayerGUID string                `json:"player_guid"`
+	Action     ContentOverrideAction `json:"action"`
+	Note       string                `json:"note,omitempty"`
+	CreatedBy  string                `json:"created_by,omitempty"`
+	CreatedAt  time.Time             `json:"created_at"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// MatchDemo is a demo recording of a match kept in a demo archive
+type MatchDemo struct {
+	ID           int64     `json:"id"`
+	MatchID      string    `json:"match_id"`
+	ServerID     string    `json:"server_id"`
+	Filename     string    `json:"filename"`
+	Checksum     string    `json:"checksum"` // SHA-256, lowercase hex
+	SizeBytes    int64     `json:"size_bytes,omitempty"`
+	DownloadURL  string    `json:"download_url"`
+	RegisteredBy string    `json:"registered_by"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// MatchDemoRequest registers a demo recording of one of the server's matches
+type MatchDemoRequest struct {
+	MatchID     string `json:"match_id"`
+	Filename    string `json:"filename"`
+	Checksum    string `json:"checksum"`
+	SizeBytes   int64  `json:"size_bytes,omitempty"`
+	DownloadURL string `json:"download_url"`
+}
+
+// DemoMatch is a match with demo recordings available
+type DemoMatch struct {
+	MatchID    string    `json:"match_id"`
+	ServerID   string    `json:"server_id"`
+	MapName    string    `json:"map_name"`
+	StartedAt  time.Time `json:"started_at"`
+	Demos      int       `json:"demos"`
+	LastDemoAt time.Time `json:"last_demo_at"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// DiscussionSubject is what a forum discussion thread is about
+type DiscussionSubject string
+
+const (
+	DiscussMatch  DiscussionSubject = "match"
+	DiscussPlayer DiscussionSubject = "player"
+)
+
+// DiscussionThread is the SMF topic where a match or player is discussed
+type DiscussionThread struct {
+	SubjectType DiscussionSubject `json:"subject_type"`
+	SubjectID   string            `json:"subject_id"` // match ID or player GUID
+	TopicID     int               `json:"topic_id"`
+	URL         string            `json:"url"`
+	// Posts is the topic's post count as last read from the forum
+	Posts          int        `json:"posts"`
+	LinkedBy       string     `json:"linked_by,omitempty"` // empty when created for a featured match
+	CreatedAt      time.Time  `json:"created_at"`
+	PostsCheckedAt *time.Time `json:"posts_checked_at,omitempty"`
+}
+
+// DiscussionLinkRequest is the body of PUT /admin/discussions/{type}/{id}
+type DiscussionLinkRequest struct {
+	TopicID int    `json:"topic_id"`
+	URL     string `json:"url"`
+}
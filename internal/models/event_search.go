@@ -0,0 +1,54 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EventSearch filters stored events for the admin event explorer. Empty
+// fields match everything; PlayerID matches the actor or the target.
+// Sandbox searches the events of sandbox servers instead of raw_events.
+type EventSearch struct {
+	Sandbox  bool
+	ServerID string
+	MatchID  string
+	PlayerID string
+	Types    []string
+	From     time.Time
+	To       time.Time
+	Limit    int
+	Offset   int
+}
+
+// StoredEvent is a raw_events row as stored, raw JSON included
+type StoredEvent struct {
+	Timestamp    time.Time       `json:"timestamp"`
+	MatchID      string          `json:"match_id"`
+	ServerID     string          `json:"server_id"`
+	TenantID     string          `json:"tenant_id,omitempty"`
+	MapName      string          `json:"map_name"`
+	EventType    string          `json:"event_type"`
+	RoundPhase   string          `json:"round_phase"`
+	SampleWeight uint16          `json:"sample_weight"`
+	ActorID      string          `json:"actor_id,omitempty"`
+	ActorName    string          `json:"actor_name,omitempty"`
+	ActorTeam    string          `json:"actor_team,omitempty"`
+	ActorWeapon  string          `json:"actor_weapon,omitempty"`
+	TargetID     string          `json:"target_id,omitempty"`
+	TargetName   string          `json:"target_name,omitempty"`
+	TargetTeam   string          `json:"target_team,omitempty"`
+	Damage       uint32          `json:"damage,omitempty"`
+	Hitloc       string          `json:"hitloc,omitempty"`
+	RawJSON      json.RawMessage `json:"raw_json,omitempty"` // empty for types stored without it
+}
+
+// EventSearchResult is one page of /admin/events/search, newest first
+type EventSearchResult struct {
+	Events  []StoredEvent `json:"events"`
+	From    time.Time     `json:"from,omitempty"`
+	To      time.Time     `json:"to,omitempty"`
+	Limit   int           `json:"limit"`
+	Offset  int           `json:"offset"`
+	HasMore bool          `json:"has_more"`
+	Sandbox bool          `json:"sandbox,omitempty"`
+}
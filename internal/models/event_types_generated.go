@@ -57,6 +57,8 @@ const (
 	EventPlayerUseObjectStart EventType = "player_use_object_start"
 	EventPlayerUseObjectFinish EventType = "player_use_object_finish"
 	EventPlayerSpectate EventType = "player_spectate"
+	EventSpectatorJoin EventType = "spectator_join"
+	EventSpectatorLeave EventType = "spectator_leave"
 	EventPlayerFreeze EventType = "player_freeze"
 	EventChat EventType = "chat"
 	EventItemPickup EventType = "item_pickup"
@@ -108,6 +110,7 @@ const (
 	EventPlayerAuth EventType = "player_auth"
 	EventAccuracySummary EventType = "accuracy_summary"
 	EventMatchOutcome EventType = "match_outcome"
+	EventRoundOutcome EventType = "round_outcome"
 )
 
 // EventTypeAliases maps non-canonical event types to their canonical form.
@@ -1,6 +1,9 @@
 package models
 
 import (
+	"encoding/json"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,14 +23,24 @@ const (
 
 // MatchSummary provides a summary of a match
 type MatchSummary struct {
-	ID          string    `json:"id"`
-	Map         string    `json:"map"`
-	ServerID    string    `json:"server_id"`
-	ServerName  string    `json:"server_name"`
-	StartTime   time.Time `json:"start_time"`
-	Duration    float64   `json:"duration"`
-	PlayerCount uint64    `json:"player_count"`
-	Kills       uint64    `json:"kills"`
+	ID          string     `json:"id"`
+	Map         string     `json:"map"`
+	ServerID    string     `json:"server_id"`
+	ServerName  string     `json:"server_name"`
+	StartTime   time.Time  `json:"start_time"`
+	Duration    float64    `json:"duration"`
+	PlayerCount uint64     `json:"player_count"`
+	Kills       uint64     `json:"kills"`
+	State       MatchState `json:"state,omitempty"`
+	Demos       int        `json:"demos,omitempty"` // demo recordings registered
+}
+
+// BusEvent is an accepted event as mirrored onto the event bus. The event
+// is as the server sent it, less its server token.
+type BusEvent struct {
+	TenantID   string    `json:"tenant_id,omitempty"`
+	ReceivedAt time.Time `json:"received_at"`
+	Event      *RawEvent `json:"event"`
 }
 
 // RawEvent is the incoming event from game servers
@@ -37,6 +50,8 @@ type RawEvent struct {
 	SessionID   string    `json:"session_id"`
 	ServerID    string    `json:"server_id"`
 	ServerToken string    `json:"server_token"`
+	TenantID    string    `json:"-"` // set from the authenticated server, never the payload
+	Sandbox     bool      `json:"-"` // set from the authenticated server, never the payload
 	Timestamp   float64   `json:"timestamp"`
 	MapName     string    `json:"map_name,omitempty"`
 
@@ -188,12 +203,28 @@ type RawEvent struct {
 	Reason   string `json:"reason,omitempty"`    // Disconnect/kick/freeze reason
 	IdleTime int    `json:"idle_time,omitempty"` // Inactivity time in seconds
 
+	// ClientIPHash is the hex SHA-256 of the client's IP under a salt the
+	// network's servers share (connect), sent by servers that opt in to alt
+	// account detection. The pool takes it off before the event is stored.
+	ClientIPHash string `json:"client_ip_hash,omitempty"`
+
 	// Server Info
 	Version  string `json:"version,omitempty"`  // Server version
 	Protocol string `json:"protocol,omitempty"` // Network protocol version
 
 	// Server Metrics
-	CPUUsage float32 `json:"cpu_usage,omitempty"`
+	CPUUsage  float32 `json:"cpu_usage,omitempty"`
+	SvFPS     float32 `json:"sv_fps,omitempty"`     // Server frame rate (heartbeat)
+	FrameTime float32 `json:"frame_time,omitempty"` // Milliseconds the last server frames took (heartbeat)
+	// SpectatorCount is how many clients are watching the match (heartbeat),
+	// nil when not reported. Servers without it send spectator_join and
+	// spectator_leave instead.
+	SpectatorCount *int `json:"spectator_count,omitempty"`
+	// Pings maps each connected player's GUID to their ping in ms (heartbeat)
+	Pings map[string]int `json:"pings,omitempty"`
+	// Roster lists the connected players (heartbeat v2). Servers that send it
+	// keep the live scoreboard; older servers leave it out.
+	Roster []RosterEntry `json:"roster,omitempty"`
 
 	// Server Commands
 	Command  string `json:"command,omitempty"`  // Console command
@@ -248,6 +279,7 @@ type ClickHouseEvent struct {
 	Timestamp time.Time
 	MatchID   uuid.UUID
 	ServerID  string
+	TenantID  string
 	MapName   string
 	EventType string
 
@@ -283,6 +315,21 @@ type ClickHouseEvent struct {
 	Distance    float32
 	RoundNumber uint16
 
+	// Events this row stands for when its type is sampled (1 otherwise)
+	SampleWeight uint16
+	// Match phase at ingest: round, warmup or intermission
+	RoundPhase string
+
+	// Vehicle the actor was in and their seat, and the target's vehicle
+	Vehicle       string
+	VehicleSeat   string
+	TargetVehicle string
+
+	// On death events: seconds the target lived since spawning and from
+	// spawning to their first engagement (0 when their spawn wasn't seen)
+	TargetLifetime    float32
+	TargetEngageDelay float32
+
 	// Raw JSON for debugging
 	RawJSON string
 }
@@ -327,6 +374,43 @@ type HeatmapPoint struct {
 	Count int     `json:"count"`
 }
 
+// RosterEntry is one connected player in a v2 heartbeat roster, and one row
+// of a match's live scoreboard
+type RosterEntry struct {
+	GUID        string `json:"guid"`
+	Name        string `json:"name"`
+	NameFlagged bool   `json:"name_flagged,omitempty"`
+	Team        string `json:"team,omitempty"`
+	Score       int    `json:"score"`
+	Ping        int    `json:"ping"`
+}
+
+// UnmarshalJSON accepts numbers sent as strings, like RawEvent does
+func (r *RosterEntry) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		GUID  FlexString `json:"guid"`
+		Name  FlexString `json:"name"`
+		Team  FlexString `json:"team"`
+		Score FlexString `json:"score"`
+		Ping  FlexString `json:"ping"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	r.GUID, r.Name, r.Team = raw.GUID.String(), raw.Name.String(), raw.Team.String()
+	r.Score, r.Ping = flexInt(raw.Score), flexInt(raw.Ping)
+	return nil
+}
+
+// flexInt parses an integer leniently ("12", "12.0"); anything else is 0
+func flexInt(s FlexString) int {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s.String()), 64)
+	if err != nil {
+		return 0
+	}
+	return int(f)
+}
+
 // LiveMatch for real-time match display
 type LiveMatch struct {
 	MatchID      string    `json:"match_id"`
@@ -341,4 +425,7 @@ type LiveMatch struct {
 	RoundNumber  int       `json:"round_number"`
 	StartedAt    time.Time `json:"started_at"`
 	TournamentID string    `json:"tournament_id,omitempty"`
+	// Spectators is how many are watching now, from the last heartbeat
+	Spectators     int `json:"spectators"`
+	PeakSpectators int `json:"peak_spectators"`
 }
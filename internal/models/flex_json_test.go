@@ -52,3 +52,30 @@ func TestFlexUnmarshal_NativeTypes(t *testing.T) {
 		t.Errorf("Damage = %f, want 112.487", e.Damage)
 	}
 }
+
+func TestFlexUnmarshal_Roster(t *testing.T) {
+	input := `{"type": "heartbeat", "player_count": "2", "roster": [
+		{"guid": "guid-a", "name": "Alice", "team": "allies", "score": "12", "ping": "45.000"},
+		{"guid": 7, "name": "Bob", "team": "axis", "score": -3, "ping": 80}
+	]}`
+
+	var e RawEvent
+	if err := json.Unmarshal([]byte(input), &e); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+	want := []RosterEntry{
+		{GUID: "guid-a", Name: "Alice", Team: "allies", Score: 12, Ping: 45},
+		{GUID: "7", Name: "Bob", Team: "axis", Score: -3, Ping: 80},
+	}
+	if len(e.Roster) != len(want) {
+		t.Fatalf("Roster = %+v, want %+v", e.Roster, want)
+	}
+	for i := range want {
+		if e.Roster[i] != want[i] {
+			t.Errorf("Roster[%d] = %+v, want %+v", i, e.Roster[i], want[i])
+		}
+	}
+	if e.PlayerCount != 2 {
+		t.Errorf("PlayerCount = %d, want 2", e.PlayerCount)
+	}
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// HighlightPeriod is the span a player of the period is picked for
+type HighlightPeriod string
+
+const (
+	HighlightDay  HighlightPeriod = "day"
+	HighlightWeek HighlightPeriod = "week" // ISO weeks, Monday to Sunday (UTC)
+)
+
+// PlayerHighlight is the player of the day or week on one server, or across
+// the network when ServerID is empty
+type PlayerHighlight struct {
+	Period        HighlightPeriod `json:"period"`
+	PeriodStart   time.Time       `json:"period_start"`
+	ServerID      string          `json:"server_id,omitempty"`
+	TenantID      string          `json:"-"`
+	PlayerGUID    string          `json:"player_guid"`
+	PlayerName    string          `json:"player_name"`
+	NameFlagged   bool            `json:"name_flagged,omitempty"`
+	Score         float64         `json:"score"`
+	Kills         int64           `json:"kills"`
+	Deaths        int64           `json:"deaths"`
+	Headshots     int64           `json:"headshots"`
+	MatchesPlayed int64           `json:"matches_played"`
+	MatchesWon    int64           `json:"matches_won"`
+	ComputedAt    time.Time       `json:"computed_at"`
+}
+
+// PlayerHighlightsResponse lists picks, newest period first
+type PlayerHighlightsResponse struct {
+	Period     HighlightPeriod   `json:"period"`
+	ServerID   string            `json:"server_id,omitempty"`
+	Highlights []PlayerHighlight `json:"highlights"`
+}
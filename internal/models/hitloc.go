@@ -0,0 +1,26 @@
+package models
+
+// BodyPartStats counts hits, damage and kills landing on one hit location
+type BodyPartStats struct {
+	Hitloc   string  `json:"hitloc"`
+	Hits     uint64  `json:"hits"`
+	Damage   uint64  `json:"damage"`
+	Kills    uint64  `json:"kills"`
+	HitShare float64 `json:"hit_share"` // % of all hits in the list
+}
+
+// BodyHeatmap is a player's hit location breakdown, both for hits the player
+// landed on opponents (Dealt) and hits the player received (Taken). Parts
+// follow body order from head to feet.
+type BodyHeatmap struct {
+	GUID  string          `json:"guid"`
+	Dealt []BodyPartStats `json:"dealt"`
+	Taken []BodyPartStats `json:"taken"`
+}
+
+// WeaponBodyPart is a weapon's hits on one opponent body part. Accuracy is
+// hits on the part per shot fired with the weapon, in percent.
+type WeaponBodyPart struct {
+	BodyPartStats
+	Accuracy float64 `json:"accuracy"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// JobRunStatus is where a scheduled job run is
+type JobRunStatus string
+
+const (
+	JobRunRunning   JobRunStatus = "running"
+	JobRunSucceeded JobRunStatus = "succeeded"
+	JobRunFailed    JobRunStatus = "failed"
+)
+
+// JobRun is one run of a scheduled job. Trigger is "schedule" or "manual";
+// Instance is the API instance that ran it.
+type JobRun struct {
+	ID         int64        `json:"id"`
+	Job        string       `json:"job"`
+	Trigger    string       `json:"trigger"`
+	Instance   string       `json:"instance"`
+	Status     JobRunStatus `json:"status"`
+	Error      string       `json:"error,omitempty"`
+	StartedAt  time.Time    `json:"started_at"`
+	FinishedAt *time.Time   `json:"finished_at,omitempty"`
+	DurationMs int64        `json:"duration_ms,omitempty"`
+}
+
+// JobInfo describes a registered job. LockedBy is the instance running it
+// right now, on any API instance.
+type JobInfo struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Schedule    string     `json:"schedule"`
+	Timeout     string     `json:"timeout"`
+	NextRun     *time.Time `json:"next_run,omitempty"`
+	LockedBy    string     `json:"locked_by,omitempty"`
+	LastRun     *JobRun    `json:"last_run,omitempty"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// LauncherHome is everything the openmohaa launcher's home screen shows for
+// a player, in one response. Parts that fail to load are left empty.
+type LauncherHome struct {
+	GUID    string      `json:"guid"`
+	Name    string      `json:"name"`
+	Rank    *PlayerRank `json:"rank"` // nil until the player has rated kills
+	Kills   uint64      `json:"kills"`
+	Deaths  uint64      `json:"deaths"`
+	KDRatio float64     `json:"kd_ratio"`
+
+	RecentMatches []RecentMatch `json:"recent_matches"`
+
+	Challenges      []LauncherChallenge `json:"challenges"`
+	ChallengesEndAt time.Time           `json:"challenges_end_at"`
+
+	// FavoriteServers are the favorites of the forum account the GUID is
+	// verified for
+	FavoriteServers []LauncherServer `json:"favorite_servers"`
+}
+
+// LauncherChallenge is a week's challenge and the player's progress on it
+type LauncherChallenge struct {
+	Code      string `json:"code"`
+	Title     string `json:"title"`
+	Progress  int64  `json:"progress"`
+	Target    int64  `json:"target"`
+	Completed bool   `json:"completed"`
+}
+
+// LauncherServer is a favorite server and its live status
+type LauncherServer struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Address    string `json:"address"`
+	Port       int    `json:"port"`
+	Online     bool   `json:"online"`
+	Players    int    `json:"players"`
+	MaxPlayers int    `json:"max_players"`
+	Map        string `json:"map,omitempty"`
+	Gametype   string `json:"gametype,omitempty"`
+}
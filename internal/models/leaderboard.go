@@ -2,10 +2,12 @@ package models
 
 // LeaderboardEntry for leaderboard display with ALL stats
 type LeaderboardEntry struct {
-	Rank       int         `json:"rank"`
-	PlayerID   string      `json:"player_id"`
-	PlayerName string      `json:"player_name"`
-	Value      interface{} `json:"value,omitempty"` // For AG Grid dynamic stat column
+	Rank       int    `json:"rank"`
+	PlayerID   string `json:"player_id"`
+	PlayerName string `json:"player_name"`
+	// NameFlagged marks a name the content filter found offensive
+	NameFlagged bool        `json:"name_flagged,omitempty"`
+	Value       interface{} `json:"value,omitempty"` // For AG Grid dynamic stat column
 
 	// Combat Stats
 	Kills      uint64  `json:"kills"`       // Player kills only (competitive)
@@ -57,6 +59,9 @@ type LeaderboardEntry struct {
 	Objectives    uint64 `json:"objectives"`
 	GamesFinished uint64 `json:"games"`
 	Playtime      uint64 `json:"playtime_seconds"`
+
+	// Equipped title and badges
+	Cosmetics *PlayerCosmetics `json:"cosmetics,omitempty"`
 }
 
 type LeaderboardCard struct {
@@ -79,3 +84,28 @@ type LeaderboardDashboard struct {
 	GameFlow map[string]LeaderboardCard `json:"game_flow"`
 	Niche    map[string]LeaderboardCard `json:"niche"`
 }
+
+// LeaderboardMove is one player's rank movement between two leaderboard
+// snapshots. Players new to or dropped from the snapshot have no previous or
+// current rank and move from or to the rank after its last.
+type LeaderboardMove struct {
+	PlayerID      string  `json:"player_id"`
+	PlayerName    string  `json:"player_name"`
+	NameFlagged   bool    `json:"name_flagged,omitempty"`
+	Rank          *int    `json:"rank"`
+	PreviousRank  *int    `json:"previous_rank"`
+	Change        int     `json:"change"` // positive = climbed
+	Value         float64 `json:"value"`
+	PreviousValue float64 `json:"previous_value"`
+}
+
+// LeaderboardChanges lists the biggest climbers and fallers of a leaderboard
+// between the snapshots taken on From and To
+type LeaderboardChanges struct {
+	Stat     string            `json:"stat"`
+	Period   string            `json:"period"`
+	From     string            `json:"from,omitempty"` // YYYY-MM-DD, empty without snapshots
+	To       string            `json:"to,omitempty"`
+	Climbers []LeaderboardMove `json:"climbers"`
+	Fallers  []LeaderboardMove `json:"fallers"`
+}
@@ -0,0 +1,83 @@
+package models
+
+import "time"
+
+// VetoAction is what a step of a map veto does with its map
+type VetoAction string
+
+const (
+	// VetoBan removes the map from the pool
+	VetoBan VetoAction = "ban"
+	// VetoPick chooses the map to be played
+	VetoPick VetoAction = "pick"
+	// VetoDecider is the map left over, played without a picker
+	VetoDecider VetoAction = "decider"
+)
+
+// Veto teams: a step is taken by team "a" or team "b" of the series
+const (
+	VetoTeamA = "a"
+	VetoTeamB = "b"
+)
+
+// MapVeto is the pick/ban sequence of a scheduled tournament series. The
+// series ID is the scheduled match in the tournament bracket, not a game's
+// match ID; played maps link to their game via MatchID.
+type MapVeto struct {
+	TournamentID string        `json:"tournament_id"`
+	SeriesID     string        `json:"series_id"`
+	TeamA        string        `json:"team_a"`
+	TeamB        string        `json:"team_b"`
+	Steps        []MapVetoStep `json:"steps"`
+	RecordedBy   string        `json:"recorded_by,omitempty"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+}
+
+// MapVetoStep is one ban, pick or decider of a veto, in order
+type MapVetoStep struct {
+	Step   int        `json:"step"`
+	Team   string     `json:"team,omitempty"` // a or b; empty for the decider
+	Action VetoAction `json:"action"`
+	Map    string     `json:"map"`
+	// MatchID is the game the picked map was played in, once played
+	MatchID string `json:"match_id,omitempty"`
+	// TeamASide is the side team a played the map on: allies or axis
+	TeamASide string `json:"team_a_side,omitempty"`
+	// Winner is the team that won the map (a, b or draw), from the match's
+	// signed result. Empty until the match is finalized.
+	Winner string `json:"winner,omitempty"`
+}
+
+// MapVetoRequest is the body of PUT /admin/tournaments/{id}/vetoes/{seriesId}
+type MapVetoRequest struct {
+	TeamA string        `json:"team_a"`
+	TeamB string        `json:"team_b"`
+	Steps []MapVetoStep `json:"steps"` // step and winner are ignored
+}
+
+// VetoMapStats is how one map fared in a tournament's vetoes
+type VetoMapStats struct {
+	Map       string `json:"map"`
+	Bans      int    `json:"bans"`
+	FirstBans int    `json:"first_bans"` // bans as a series' first step
+	Picks     int    `json:"picks"`
+	Deciders  int    `json:"deciders"`
+	// Decided counts the picks and deciders whose match has a result
+	Decided int `json:"decided"`
+	// PickerWins counts the decided picks won by the team that picked them
+	PickerWins    int     `json:"picker_wins"`
+	PickerWinRate float64 `json:"picker_win_rate"`
+}
+
+// VetoAnalytics summarizes the vetoes of a tournament
+type VetoAnalytics struct {
+	TournamentID string `json:"tournament_id"`
+	Series       int    `json:"series"`
+	// Maps is sorted by bans, most banned first
+	Maps []VetoMapStats `json:"maps"`
+	// DecidedPicks and PickerWins are over all maps: how often picking a
+	// map won it
+	DecidedPicks  int     `json:"decided_picks"`
+	PickerWins    int     `json:"picker_wins"`
+	PickerWinRate float64 `json:"picker_win_rate"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// MatchCard is the share preview of a match: the OpenGraph and Twitter card
+// metadata chat apps and forums unfurl a posted match link with
+type MatchCard struct {
+	MatchID     string `json:"match_id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+	Image       string `json:"image"`
+	ImageWidth  int    `json:"image_width"`
+	ImageHeight int    `json:"image_height"`
+	// Meta holds the tags to put in the page head, keyed by property or name
+	// (og:title, twitter:card, ...)
+	Meta map[string]string `json:"meta"`
+}
+
+// MatchCardStats is what a match card shows
+type MatchCardStats struct {
+	MatchID     string
+	ServerID    string
+	MapName     string
+	Gametype    string
+	AlliesScore int
+	AxisScore   int
+	Players     int
+	Kills       int
+	StartedAt   time.Time
+	Duration    time.Duration
+	TopPlayers  []MatchCardPlayer
+}
+
+// MatchCardPlayer is one of a match's top fraggers
+type MatchCardPlayer struct {
+	GUID        string
+	Name        string
+	NameFlagged bool
+	Kills       int
+	Deaths      int
+}
@@ -0,0 +1,100 @@
+package models
+
+import "time"
+
+// DisputeStatus is where a match dispute is
+type DisputeStatus string
+
+const (
+	// DisputeOpen: evidence is being gathered
+	DisputeOpen DisputeStatus = "open"
+	// DisputeCorrected: closed by a correction of the match's result
+	DisputeCorrected DisputeStatus = "corrected"
+	// DisputeRejected: closed with the result left as it was
+	DisputeRejected DisputeStatus = "rejected"
+)
+
+// Winners a correction may set
+const (
+	CorrectionWinnerAllies = "allies"
+	CorrectionWinnerAxis   = "axis"
+	CorrectionWinnerDraw   = "draw"
+)
+
+// MatchDispute is a tournament admin's challenge of a match's result
+type MatchDispute struct {
+	ID           int64         `json:"id"`
+	MatchID      string        `json:"match_id"`
+	TournamentID string        `json:"tournament_id"`
+	Status       DisputeStatus `json:"status"`
+	Reason       string        `json:"reason"`
+	OpenedBy     string        `json:"opened_by,omitempty"`
+	Resolution   string        `json:"resolution,omitempty"`
+	ResolvedBy   string        `json:"resolved_by,omitempty"`
+	CreatedAt    time.Time     `json:"created_at"`
+	ResolvedAt   *time.Time    `json:"resolved_at,omitempty"`
+
+	Notes []DisputeNote `json:"notes"`
+	// Correction is the correction that closed the dispute, if any
+	Correction *MatchCorrection `json:"correction,omitempty"`
+}
+
+// DisputeNote is a piece of evidence attached to a dispute
+type DisputeNote struct {
+	ID          int64     `json:"id"`
+	DisputeID   int64     `json:"dispute_id"`
+	Note        string    `json:"note"`
+	EvidenceURL string    `json:"evidence_url,omitempty"`
+	AddedBy     string    `json:"added_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// MatchCorrection is a manual score of a match that supersedes the one
+// derived from its events
+type MatchCorrection struct {
+	ID          int64     `json:"id"`
+	MatchID     string    `json:"match_id"`
+	DisputeID   *int64    `json:"dispute_id,omitempty"`
+	AlliesScore int       `json:"allies_score"`
+	AxisScore   int       `json:"axis_score"`
+	Winner      string    `json:"winner"` // allies, axis or draw
+	Reason      string    `json:"reason"`
+	CorrectedBy string    `json:"corrected_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// DisputeOpenRequest is the body of POST /admin/matches/{matchId}/disputes
+type DisputeOpenRequest struct {
+	Reason string `json:"reason"`
+}
+
+// DisputeNoteRequest is the body of POST /admin/disputes/{id}/notes
+type DisputeNoteRequest struct {
+	Note        string `json:"note"`
+	EvidenceURL string `json:"evidence_url"`
+}
+
+// DisputeRejectRequest is the body of POST /admin/disputes/{id}/reject
+type DisputeRejectRequest struct {
+	Resolution string `json:"resolution"`
+}
+
+// MatchCorrectionRequest is the body of POST /admin/matches/{matchId}/correction
+type MatchCorrectionRequest struct {
+	AlliesScore int    `json:"allies_score"`
+	AxisScore   int    `json:"axis_score"`
+	Winner      string `json:"winner"`
+	Reason      string `json:"reason"`
+	// DisputeID closes this open dispute of the match as corrected
+	DisputeID *int64 `json:"dispute_id,omitempty"`
+}
+
+// MatchCorrectionResult reports what applying a correction changed
+type MatchCorrectionResult struct {
+	Correction *MatchCorrection `json:"correction"`
+	// Outcomes is how many match_outcome events were derived again
+	Outcomes int `json:"outcomes"`
+	// Resigned is set when the match's signed result was signed again
+	Resigned   bool                 `json:"resigned"`
+	RebuildJob *AggregateRebuildJob `json:"rebuild_job,omitempty"`
+}
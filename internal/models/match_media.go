@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// MediaKind is what a match attachment links to
+type MediaKind string
+
+const (
+	MediaScreenshot MediaKind = "screenshot"
+	MediaClip       MediaKind = "clip"
+)
+
+// MediaStatus is where a match attachment is in moderation
+type MediaStatus string
+
+const (
+	MediaPending  MediaStatus = "pending"
+	MediaApproved MediaStatus = "approved"
+	MediaRejected MediaStatus = "rejected"
+)
+
+// MatchMedia is a screenshot or clip a user attached to a match
+type MatchMedia struct {
+	ID          int64       `json:"id"`
+	MatchID     string      `json:"match_id"`
+	Kind        MediaKind   `json:"kind"`
+	URL         string      `json:"url"`
+	Caption     string      `json:"caption,omitempty"`
+	SubmittedBy int         `json:"submitted_by"` // SMF forum user ID
+	Status      MediaStatus `json:"status"`
+	ReviewNote  string      `json:"review_note,omitempty"`
+	ReviewedBy  string      `json:"reviewed_by,omitempty"`
+	ReviewedAt  *time.Time  `json:"reviewed_at,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+// MatchMediaRequest is the body of POST /users/me/media
+type MatchMediaRequest struct {
+	MatchID string    `json:"match_id"`
+	Kind    MediaKind `json:"kind"`
+	URL     string    `json:"url"`
+	Caption string    `json:"caption,omitempty"`
+}
+
+// MediaReviewRequest is the body of POST /admin/media/{id}/reject
+type MediaReviewRequest struct {
+	Note string `json:"note,omitempty"`
+}
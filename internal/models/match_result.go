@@ -0,0 +1,59 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// MatchResultAlgorithm is the signature algorithm of signed match results
+const MatchResultAlgorithm = "ed25519"
+
+// TournamentMatchResult is the result document of a finalized tournament
+// match. It is signed in canonical JSON: keys sorted, no insignificant
+// whitespace, times in UTC.
+type TournamentMatchResult struct {
+	MatchID      string              `json:"match_id"`
+	TournamentID string              `json:"tournament_id"`
+	ServerID     string              `json:"server_id"`
+	MapName      string              `json:"map_name"`
+	Gametype     string              `json:"gametype"`
+	AlliesScore  int                 `json:"allies_score"`
+	AxisScore    int                 `json:"axis_score"`
+	Winner       string              `json:"winner"`
+	StartedAt    time.Time           `json:"started_at"`
+	EndedAt      time.Time           `json:"ended_at"`
+	FinalizedAt  time.Time           `json:"finalized_at"`
+	FinalizedBy  string              `json:"finalized_by"`
+	Players      []MatchResultPlayer `json:"players"`
+	// Correction is the manual score that replaced the derived one, if any
+	Correction *MatchCorrection `json:"correction,omitempty"`
+}
+
+// MatchResultPlayer is one player's line of a signed match result
+type MatchResultPlayer struct {
+	GUID   string `json:"guid"`
+	Name   string `json:"name"`
+	Team   string `json:"team"`
+	Kills  int    `json:"kills"`
+	Deaths int    `json:"deaths"`
+	Won    bool   `json:"won"`
+}
+
+// SignedMatchResult is a stored result and its signature. Payload is the
+// exact signed bytes; verify Signature over them with PublicKey.
+type SignedMatchResult struct {
+	Result    json.RawMessage `json:"result"`
+	Payload   []byte          `json:"payload"` // base64
+	Signature []byte          `json:"signature"`
+	Algorithm string          `json:"algorithm"`
+	KeyID     string          `json:"key_id"`
+	PublicKey []byte          `json:"public_key"`
+	SignedAt  time.Time       `json:"signed_at"`
+}
+
+// ResultSigningKey is the public half of the key match results are signed with
+type ResultSigningKey struct {
+	Algorithm string `json:"algorithm"`
+	KeyID     string `json:"key_id"`
+	PublicKey []byte `json:"public_key"` // base64
+}
@@ -0,0 +1,123 @@
+package models
+
+import "time"
+
+// MatchState is where a match is in its lifecycle. Game events move a match
+// from pending to live to ended; admins finalize, void or reopen it.
+type MatchState string
+
+const (
+	// MatchStatePending: events seen, but no match_start yet
+	MatchStatePending MatchState = "pending"
+	// MatchStateLive: between match_start and match_end
+	MatchStateLive MatchState = "live"
+	// MatchStateEnded: match_end received (or synthesized for a dead server)
+	MatchStateEnded MatchState = "ended"
+	// MatchStateFinalized: results confirmed by an admin. Tournament matches
+	// are locked from here on.
+	MatchStateFinalized MatchState = "finalized"
+	// MatchStateVoided: excluded from stats by an admin
+	MatchStateVoided MatchState = "voided"
+)
+
+// matchTransitions lists the states each state may move to
+var matchTransitions = map[MatchState][]MatchState{
+	MatchStatePending:   {MatchStateLive, MatchStateEnded, MatchStateVoided},
+	MatchStateLive:      {MatchStateEnded, MatchStateVoided},
+	MatchStateEnded:     {MatchStateFinalized, MatchStateVoided},
+	MatchStateFinalized: {MatchStateEnded, MatchStateVoided}, // reopen
+	MatchStateVoided:    {MatchStateEnded},                   // restore
+}
+
+// Valid reports whether s is a known state
+func (s MatchState) Valid() bool {
+	_, ok := matchTransitions[s]
+	return ok
+}
+
+// CanTransition reports whether a match may move from s to next
+func (s MatchState) CanTransition(next MatchState) bool {
+	for _, allowed := range matchTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsRecompute reports whether derived results of a match in this state may
+// be rebuilt. Live matches are still changing and finalized ones are settled.
+func (s MatchState) AllowsRecompute() bool {
+	return s == MatchStateEnded || s == MatchStateVoided
+}
+
+// MatchStateForEvent returns the state a game event moves its match to.
+// Events other than match_start and match_end only create pending matches.
+func MatchStateForEvent(t EventType) MatchState {
+	switch t {
+	case EventMatchStart:
+		return MatchStateLive
+	case EventMatchEnd:
+		return MatchStateEnded
+	default:
+		return MatchStatePending
+	}
+}
+
+// MatchStateRecord is the stored lifecycle of one match
+type MatchStateRecord struct {
+	MatchID      string     `json:"match_id"`
+	ServerID     string     `json:"server_id"`
+	TournamentID string     `json:"tournament_id,omitempty"`
+	State        MatchState `json:"state"`
+	Reason       string     `json:"reason,omitempty"`
+	UpdatedBy    string     `json:"updated_by,omitempty"`
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	EndedAt      *time.Time `json:"ended_at,omitempty"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// Locked reports whether the match's results may no longer change: a
+// finalized tournament match stays as it is, even for admins
+func (r *MatchStateRecord) Locked() bool {
+	return r.TournamentID != "" && r.State == MatchStateFinalized
+}
+
+// MatchStateChange is the body of PUT /admin/matches/{matchId}/state
+type MatchStateChange struct {
+	State  MatchState `json:"state"`
+	Reason string     `json:"reason"`
+}
+
+// MatchVoidRequest is the body of POST /admin/matches/{matchId}/void
+type MatchVoidRequest struct {
+	Reason string `json:"reason"`
+}
+
+// MatchVoidResult reports what voiding a match changed
+type MatchVoidResult struct {
+	Match               *MatchStateRecord `json:"match"`
+	AchievementsRevoked int64             `json:"achievements_revoked"`
+	AggregatesRebuilt   []string          `json:"aggregates_rebuilt,omitempty"`
+	// RebuildJob is the targeted rebuild started for the match, if any
+	RebuildJob *AggregateRebuildJob `json:"rebuild_job,omitempty"`
+	// Note says when the aggregates still count the match
+	Note string `json:"note,omitempty"`
+}
+
+// MatchRecomputeResult reports what recomputing a match changed
+type MatchRecomputeResult struct {
+	MatchID              string     `json:"match_id"`
+	State                MatchState `json:"state"`
+	Events               int        `json:"events"`
+	Players              int        `json:"players"`
+	WinningTeam          string     `json:"winning_team,omitempty"`
+	Outcomes             int        `json:"outcomes"`
+	AchievementsRevoked  int64      `json:"achievements_revoked"`
+	AchievementsReplayed bool       `json:"achievements_replayed"`
+	AggregatesRebuilt    []string   `json:"aggregates_rebuilt,omitempty"`
+	// RebuildJob is the targeted rebuild started for the match, if any
+	RebuildJob *AggregateRebuildJob `json:"rebuild_job,omitempty"`
+	// Note says when the aggregates still count the old outcomes
+	Note string `json:"note,omitempty"`
+}
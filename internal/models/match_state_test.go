@@ -0,0 +1,44 @@
+package models
+
+import "testing"
+
+func TestMatchStateTransitions(t *testing.T) {
+	tests := []struct {
+		from, to MatchState
+		want     bool
+	}{
+		{MatchStatePending, MatchStateLive, true},
+		{MatchStateLive, MatchStateEnded, true},
+		{MatchStateEnded, MatchStateFinalized, true},
+		{MatchStateFinalized, MatchStateEnded, true},
+		{MatchStateVoided, MatchStateEnded, true},
+		{MatchStateLive, MatchStatePending, false},
+		{MatchStateLive, MatchStateFinalized, false},
+		{MatchStateEnded, MatchStateLive, false},
+		{MatchStateVoided, MatchStateFinalized, false},
+		{"bogus", MatchStateEnded, false},
+	}
+	for _, tt := range tests {
+		if got := tt.from.CanTransition(tt.to); got != tt.want {
+			t.Errorf("%s -> %s = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestMatchStateLocking(t *testing.T) {
+	if MatchStateLive.AllowsRecompute() || MatchStateFinalized.AllowsRecompute() {
+		t.Error("live and finalized matches must not be recomputed")
+	}
+	if !MatchStateEnded.AllowsRecompute() {
+		t.Error("ended matches must be recomputable")
+	}
+
+	finalized := &MatchStateRecord{State: MatchStateFinalized}
+	if finalized.Locked() {
+		t.Error("finalized non-tournament match is locked")
+	}
+	finalized.TournamentID = "cup-1"
+	if !finalized.Locked() {
+		t.Error("finalized tournament match is not locked")
+	}
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// NotificationKind is what a notification is about
+type NotificationKind string
+
+const (
+	NotifyAchievement NotificationKind = "achievement"
+	NotifyRecord      NotificationKind = "record"
+	NotifyRivalry     NotificationKind = "rivalry"
+	NotifyChallenge   NotificationKind = "challenge"
+)
+
+// Notification is an entry in a forum user's inbox. Subject is what it is
+// about: the achievement slug, challenge code, record name or rival's GUID;
+// Value the points, streak or kill count that goes with it.
+type Notification struct {
+	ID          int64            `json:"id"`
+	ForumUserID int              `json:"forum_user_id"`
+	PlayerGUID  string           `json:"player_guid,omitempty"`
+	Kind        NotificationKind `json:"kind"`
+	Title       string           `json:"title"`
+	Body        string           `json:"body,omitempty"`
+	Subject     string           `json:"subject,omitempty"`
+	Value       int64            `json:"value,omitempty"`
+	MatchID     string           `json:"match_id,omitempty"`
+	ReadAt      *time.Time       `json:"read_at,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
+
+// NotificationList is a page of a user's inbox, newest first. Unread counts
+// the whole inbox for the bell badge; Cursor is the before= of the next page,
+// zero on the last one.
+type NotificationList struct {
+	Notifications []Notification `json:"notifications"`
+	Unread        int            `json:"unread"`
+	Cursor        int64          `json:"cursor,omitempty"`
+}
+
+// NotificationReadRequest marks notifications read: the IDs given, or the
+// whole inbox with All
+type NotificationReadRequest struct {
+	IDs []int64 `json:"ids,omitempty"`
+	All bool    `json:"all,omitempty"`
+}
@@ -1,5 +1,24 @@
 package models
 
+// Player stats sections, selectable with ?sections= on /stats/player/{guid}.
+// The first eleven are the parts of DeepStats.
+const (
+	SectionCombat        = "combat"
+	SectionWeapons       = "weapons"
+	SectionMovement      = "movement"
+	SectionAccuracy      = "accuracy"
+	SectionSession       = "session"
+	SectionStance        = "stance"
+	SectionRivals        = "rivals"
+	SectionInteraction   = "interaction"
+	SectionDeathCauses   = "deaths_by_cause"
+	SectionSurvival      = "survival"
+	SectionForm          = "form"
+	SectionMaps          = "maps"
+	SectionPerformance   = "performance"
+	SectionRecentMatches = "recent_matches"
+)
+
 // DeepStats represents the massive aggregated stats object
 type DeepStats struct {
 	Combat      CombatStats         `json:"combat"`
@@ -10,6 +29,43 @@ type DeepStats struct {
 	Rivals      RivalStats          `json:"rivals"`
 	Stance      StanceStats         `json:"stance"`
 	Interaction InteractionStats    `json:"interaction"`
+	// DeathsByCause splits the player's deaths by what killed them
+	DeathsByCause []DeathCauseStat `json:"deaths_by_cause"`
+	Survival      SurvivalStats    `json:"survival"`
+	Form          FormStats        `json:"form"`
+}
+
+// ConfidenceInterval is the 95% interval of a rate, in the rate's own unit
+// (percent for percentages). Omitted when there is nothing to estimate from.
+type ConfidenceInterval struct {
+	Low  float64 `json:"low"`
+	High float64 `json:"high"`
+}
+
+// FormStats compares the player's kill share (kills over kills and deaths)
+// in their last few matches with the rest of their matches. Hot or cold says
+// which way the recent matches lean; Significant is set when a two-proportion
+// z-test puts the difference beyond noise (p < 0.05).
+type FormStats struct {
+	Matches      int     `json:"matches"`
+	RecentKills  uint64  `json:"recent_kills"`
+	RecentDeaths uint64  `json:"recent_deaths"`
+	RecentKD     float64 `json:"recent_kd"`
+	BaselineKD   float64 `json:"baseline_kd"` // the matches before the recent ones
+	State        string  `json:"state"`       // "hot", "cold" or "steady"
+	ZScore       float64 `json:"z_score"`
+	PValue       float64 `json:"p_value"`
+	Significant  bool    `json:"significant"`
+}
+
+// SurvivalStats is how long the player's spawns last. Lives are the deaths
+// whose spawn the worker saw; times are in seconds.
+type SurvivalStats struct {
+	Spawns          uint64  `json:"spawns"`
+	Lives           uint64  `json:"lives"`
+	AvgLifetime     float64 `json:"avg_lifetime"`
+	LongestLife     float64 `json:"longest_life"`
+	AvgTimeToEngage float64 `json:"avg_time_to_engage"` // spawn to first shot, hit or kill
 }
 
 type RivalStats struct {
@@ -60,6 +116,10 @@ type CombatStats struct {
 	DamageDealt     uint64  `json:"damage_dealt"`
 	DamageTaken     uint64  `json:"damage_taken"`
 
+	// Wilson intervals of the K/D (from the kill share) and headshot percent
+	KDRatioCI         *ConfidenceInterval `json:"kd_ratio_ci,omitempty"`
+	HeadshotPercentCI *ConfidenceInterval `json:"headshot_percent_ci,omitempty"`
+
 	// Kill Streak Stats (consecutive kills without dying)
 	BestKillstreak uint64 `json:"best_killstreak"`
 	Streaks5       uint64 `json:"streaks_5"`  // Times achieved 5+ kill streak
@@ -102,6 +162,9 @@ type AccuracyStats struct {
 	Overall     float64 `json:"overall"`
 	HeadHitPct  float64 `json:"head_hit_pct"`
 	AvgDistance float64 `json:"avg_distance"`
+	// Wilson intervals of the percentages above
+	OverallCI    *ConfidenceInterval `json:"overall_ci,omitempty"`
+	HeadHitPctCI *ConfidenceInterval `json:"head_hit_pct_ci,omitempty"`
 }
 
 type SessionStats struct {
@@ -118,6 +181,51 @@ type InteractionStats struct {
 	TurretUses   uint64       `json:"turret_uses"`
 }
 
+// DeathCauseStat counts deaths from one cause: combat, explosives, falling,
+// drowning, crushed, telefrag, suicide or environment (other world damage)
+type DeathCauseStat struct {
+	Cause  string  `json:"cause"`
+	Deaths uint64  `json:"deaths"`
+	Share  float64 `json:"share"` // % of all deaths
+}
+
+// MapEnvironmentDeaths counts a map's deaths from the environment (falling,
+// drowning, crushed, telefrag and other world damage) rather than players
+type MapEnvironmentDeaths struct {
+	MapName           string            `json:"map_name"`
+	Deaths            uint64            `json:"deaths"`
+	EnvironmentDeaths uint64            `json:"environment_deaths"`
+	EnvironmentShare  float64           `json:"environment_share"` // % of the map's deaths
+	Causes            map[string]uint64 `json:"causes"`            // environmental causes only
+}
+
+// MapObjectiveStats is objective timing on an objective map over the last
+// Days days. Plant, detonation and defuse counts come from objective_update
+// statuses.
+type MapObjectiveStats struct {
+	MapName                 string              `json:"map_name"`
+	Days                    int                 `json:"days"`
+	Matches                 uint64              `json:"matches"`
+	Rounds                  uint64              `json:"rounds"`
+	RoundsWithObjective     uint64              `json:"rounds_with_objective"`
+	AvgTimeToFirstObjective float64             `json:"avg_time_to_first_objective"` // seconds from round start
+	Plants                  uint64              `json:"plants"`
+	Detonations             uint64              `json:"detonations"`
+	Defuses                 uint64              `json:"defuses"`
+	PlantSuccessRate        float64             `json:"plant_success_rate"` // % of plants that detonated
+	DefuseRate              float64             `json:"defuse_rate"`        // % of plants that were defused
+	Objectives              []ObjectiveCaptures `json:"objectives"`
+}
+
+// ObjectiveCaptures is how often one objective of a map was captured
+type ObjectiveCaptures struct {
+	Objective        string  `json:"objective"`
+	Captures         uint64  `json:"captures"`
+	CapturesPerMatch float64 `json:"captures_per_match"`
+	AlliesCaptures   uint64  `json:"allies_captures"`
+	AxisCaptures     uint64  `json:"axis_captures"`
+}
+
 type PickupStat struct {
 	ItemName string `json:"item_name"`
 	Count    uint64 `json:"count"`
@@ -165,6 +273,8 @@ type PlayerStats struct {
 	Performance   []PerformancePoint  `json:"performance"`
 	RecentMatches []RecentMatch       `json:"recent_matches"`
 	Achievements  []string            `json:"achievements"`
+	Cosmetics     *PlayerCosmetics    `json:"cosmetics,omitempty"`
+	Rating        *PlayerRating       `json:"rating,omitempty"`
 }
 
 type PlayerStatsResponse struct {
@@ -220,3 +330,24 @@ type WeaponStats struct {
 	ShotsHit   uint64  `json:"shots_hit"`
 	Accuracy   float64 `json:"accuracy"`
 }
+
+// PacingPhase is a player's combat in one third of their matches
+type PacingPhase struct {
+	Phase     string  `json:"phase"` // early, mid or late
+	Kills     uint64  `json:"kills"`
+	Deaths    uint64  `json:"deaths"`
+	Headshots uint64  `json:"headshots"`
+	Matches   uint64  `json:"matches"`
+	KDRatio   float64 `json:"kd_ratio"`
+}
+
+// PlayerPacing shows how a player performs as matches progress. Each kill and
+// death is placed in a third of its match, measured from match_start to
+// match_end (or the match's first and last events).
+type PlayerPacing struct {
+	GUID   string        `json:"guid"`
+	Phases []PacingPhase `json:"phases"`
+	// Trend is "fast_starter" or "strong_finisher" when the late K/D differs
+	// from the early K/D by more than 20%, otherwise "steady"
+	Trend string `json:"trend"`
+}
@@ -4,15 +4,16 @@ import "time"
 
 // PlayerPredictions represents AI-driven performance forecasts
 type PlayerPredictions struct {
-	GUID              string             `json:"guid"`
-	ExpectedKD        float64            `json:"expected_kd"`
-	Trend             string             `json:"trend"` // "improving", "declining", "stable"
-	Confidence        float64            `json:"confidence"`
-	RecentPerformance []float64          `json:"recent_performance"`
-	PredictedKills    int                `json:"predicted_kills"`
-	PredictedDeaths   int                `json:"predicted_deaths"`
-	RivalAnalysis     []RivalPrediction  `json:"rival_analysis"`
-	LastUpdated       time.Time          `json:"last_updated"`
+	GUID              string            `json:"guid"`
+	ExpectedKD        float64           `json:"expected_kd"`
+	Trend             string            `json:"trend"` // "improving", "declining", "stable"
+	Confidence        float64           `json:"confidence"`
+	RecentPerformance []float64         `json:"recent_performance"`
+	PredictedKills    int               `json:"predicted_kills"`
+	PredictedDeaths   int               `json:"predicted_deaths"`
+	RivalAnalysis     []RivalPrediction `json:"rival_analysis"`
+	Model             string            `json:"model"` // scoring model of the forecast
+	LastUpdated       time.Time         `json:"last_updated"`
 }
 
 // RivalPrediction analyzes potential outcome against a specific opponent
@@ -25,10 +26,45 @@ type RivalPrediction struct {
 
 // MatchPredictions forecasts the outcome of an ongoing or upcoming match
 type MatchPredictions struct {
-	MatchID        string            `json:"match_id"`
-	AlliesWinProb  float64           `json:"allies_win_prob"`
-	AxisWinProb    float64           `json:"axis_win_prob"`
-	ExpectedWinner string            `json:"expected_winner"`
-	KeyPlayers     []string          `json:"key_players"`
-	Factors        []string          `json:"factors"`
+	MatchID        string   `json:"match_id"`
+	AlliesWinProb  float64  `json:"allies_win_prob"`
+	AxisWinProb    float64  `json:"axis_win_prob"`
+	ExpectedWinner string   `json:"expected_winner"`
+	KeyPlayers     []string `json:"key_players"`
+	Factors        []string `json:"factors"`
+}
+
+// PredictionAccuracy compares the forecasts of each scoring model with the
+// matches the players went on to play
+type PredictionAccuracy struct {
+	Days   int                       `json:"days"`
+	Models []PredictionModelAccuracy `json:"models"`
+}
+
+// PredictionModelAccuracy is one model's track record. Errors are in kills
+// and deaths per match; bias is predicted minus actual kills, so a positive
+// bias overrates players. A hit is a kills forecast within the tolerance of
+// logic.PredictionHit, and confidence is the model's stated hit chance.
+type PredictionModelAccuracy struct {
+	Model          string                  `json:"model"`
+	Primary        bool                    `json:"primary"`
+	Shadow         bool                    `json:"shadow"`
+	Resolved       int64                   `json:"resolved"`
+	Pending        int64                   `json:"pending"`
+	KillsMAE       float64                 `json:"kills_mae"`
+	DeathsMAE      float64                 `json:"deaths_mae"`
+	KillsBias      float64                 `json:"kills_bias"`
+	HitRate        float64                 `json:"hit_rate"`
+	MeanConfidence float64                 `json:"mean_confidence"`
+	BrierScore     float64                 `json:"brier_score"`
+	Calibration    []PredictionCalibration `json:"calibration"`
+}
+
+// PredictionCalibration is one confidence decile: a well calibrated model
+// hits about as often as it claimed to
+type PredictionCalibration struct {
+	MinConfidence  float64 `json:"min_confidence"`
+	Predictions    int64   `json:"predictions"`
+	MeanConfidence float64 `json:"mean_confidence"`
+	HitRate        float64 `json:"hit_rate"`
 }
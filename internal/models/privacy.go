@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// AnonymousName replaces the name of a player who asked to be anonymized
+const AnonymousName = "Anonymous Soldier"
+
+// PlayerPrivacy is the privacy preferences of a forum account. They apply to
+// every GUID verified for it.
+type PlayerPrivacy struct {
+	// HideProfile answers the player's profile routes with 404
+	HideProfile bool `json:"hide_profile"`
+	// HideFromLeaderboards leaves the player out of ranked lists
+	HideFromLeaderboards bool `json:"hide_from_leaderboards"`
+	// AnonymizeName shows AnonymousName instead of the player's name
+	AnonymizeName bool      `json:"anonymize_name"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
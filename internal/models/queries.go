@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// SlowQuery is a single ClickHouse call that exceeded the slow query threshold
+type SlowQuery struct {
+	Name       string    `json:"name"`
+	Query      string    `json:"query"`
+	Args       []string  `json:"args,omitempty"`
+	DurationMs float64   `json:"duration_ms"`
+	Error      string    `json:"error,omitempty"`
+	At         time.Time `json:"at"`
+}
+
+// QueryStat aggregates timings for every call made under one query name
+type QueryStat struct {
+	Name      string  `json:"name"`
+	Count     uint64  `json:"count"`
+	SlowCount uint64  `json:"slow_count"`
+	Errors    uint64  `json:"errors"`
+	TotalMs   float64 `json:"total_ms"`
+	AvgMs     float64 `json:"avg_ms"`
+	MaxMs     float64 `json:"max_ms"`
+}
+
+// SlowQueryReport is the response of /admin/queries/slow
+type SlowQueryReport struct {
+	ThresholdMs float64     `json:"threshold_ms"`
+	Slow        []SlowQuery `json:"slow"`
+	Registry    []QueryStat `json:"registry"`
+}
@@ -0,0 +1,16 @@
+package models
+
+// SandboxQueryRequest is the body of POST /admin/query
+type SandboxQueryRequest struct {
+	SQL string `json:"sql"`
+}
+
+// SandboxQueryResult is the output of a sandboxed read-only query
+type SandboxQueryResult struct {
+	Columns   []string        `json:"columns"`
+	Rows      [][]interface{} `json:"rows"`
+	RowCount  int             `json:"row_count"`
+	MaxRows   int             `json:"max_rows"`
+	Truncated bool            `json:"truncated"` // more rows than max_rows matched
+	ElapsedMS int64           `json:"elapsed_ms"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// PlayerRating is a player's Elo rating and their opponent-adjusted K/D.
+// Adjusted kills weigh each kill by the victim's rating and adjusted deaths
+// each death by the inverse of the killer's, relative to the starting rating,
+// so kills of strong players and deaths to weak ones count more.
+type PlayerRating struct {
+	Rating         float64   `json:"rating"`
+	Kills          int64     `json:"kills"`
+	Deaths         int64     `json:"deaths"`
+	AdjustedKills  float64   `json:"adjusted_kills"`
+	AdjustedDeaths float64   `json:"adjusted_deaths"`
+	AdjustedKD     float64   `json:"adjusted_kd"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// PlayerRank is a player's position among all rated players, by rating
+type PlayerRank struct {
+	Position int     `json:"position"`
+	Of       int     `json:"of"`
+	Rating   float64 `json:"rating"`
+}
+
+// AdjustedKDEntry is a row of the adjusted K/D leaderboard
+type AdjustedKDEntry struct {
+	Rank        int     `json:"rank"`
+	PlayerID    string  `json:"player_id"`
+	PlayerName  string  `json:"player_name"`
+	NameFlagged bool    `json:"name_flagged,omitempty"`
+	Rating      float64 `json:"rating"`
+	Kills       int64   `json:"kills"`
+	Deaths      int64   `json:"deaths"`
+	KDRatio     float64 `json:"kd_ratio"`
+	AdjustedKD  float64 `json:"adjusted_kd"`
+}
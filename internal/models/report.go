@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// ReportReason is why a player is reported
+type ReportReason string
+
+const (
+	ReportCheating    ReportReason = "cheating"
+	ReportAbusiveName ReportReason = "abusive_name"
+	ReportAbusiveChat ReportReason = "abusive_chat"
+	ReportGriefing    ReportReason = "griefing"
+	ReportOther       ReportReason = "other"
+)
+
+// ReportStatus is where a report is in triage; actioned and dismissed
+// reports are closed
+type ReportStatus string
+
+const (
+	ReportOpen      ReportStatus = "open"
+	ReportReviewing ReportStatus = "reviewing"
+	ReportActioned  ReportStatus = "actioned"
+	ReportDismissed ReportStatus = "dismissed"
+)
+
+// PlayerReport is an abuse report a user filed against a player.
+// AnticheatFlag is the ID of the anticheat flag an admin linked it to.
+// PlayerOpenReports, filled in the admin queue, counts the open reports
+// against the same player.
+type PlayerReport struct {
+	ID                int64        `json:"id"`
+	PlayerGUID        string       `json:"player_guid"`
+	PlayerName        string       `json:"player_name,omitempty"`
+	Reason            ReportReason `json:"reason"`
+	Details           string       `json:"details,omitempty"`
+	MatchID           string       `json:"match_id,omitempty"`
+	ReportedBy        int          `json:"reported_by"` // SMF forum user ID
+	Status            ReportStatus `json:"status"`
+	AnticheatFlag     string       `json:"anticheat_flag,omitempty"`
+	ReviewNote        string       `json:"review_note,omitempty"`
+	ReviewedBy        string       `json:"reviewed_by,omitempty"`
+	ReviewedAt        *time.Time   `json:"reviewed_at,omitempty"`
+	CreatedAt         time.Time    `json:"created_at"`
+	PlayerOpenReports int          `json:"player_open_reports,omitempty"`
+}
+
+// PlayerReportRequest is the body of POST /reports
+type PlayerReportRequest struct {
+	PlayerGUID string       `json:"player_guid"`
+	PlayerName string       `json:"player_name,omitempty"`
+	Reason     ReportReason `json:"reason"`
+	Details    string       `json:"details,omitempty"`
+	MatchID    string       `json:"match_id,omitempty"`
+}
+
+// ReportTriageRequest is the body of POST /admin/reports/{id}/triage. An
+// empty Note keeps the current one; a nil AnticheatFlag keeps the linked
+// flag and an empty one unlinks it.
+type ReportTriageRequest struct {
+	Status        ReportStatus `json:"status"`
+	Note          string       `json:"note,omitempty"`
+	AnticheatFlag *string      `json:"anticheat_flag,omitempty"`
+}
+
+// ReportFilter selects reports in the admin queue; empty fields match all
+type ReportFilter struct {
+	Status        ReportStatus
+	PlayerGUID    string
+	AnticheatFlag string
+	Limit         int
+}
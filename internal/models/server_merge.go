@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// ServerMergeRequest moves a server's history to the server that replaced it
+type ServerMergeRequest struct {
+	FromServerID string `json:"from_server_id"`
+	ToServerID   string `json:"to_server_id"`
+	Reason       string `json:"reason"`
+	DryRun       bool   `json:"dry_run"`
+}
+
+// ServerMerge reports a merge of server history: on a dry run what would
+// move, otherwise what moved. Executed merges are kept as an audit trail.
+type ServerMerge struct {
+	ID           string             `json:"id,omitempty"` // empty on dry runs
+	FromServerID string             `json:"from_server_id"`
+	ToServerID   string             `json:"to_server_id"`
+	Reason       string             `json:"reason,omitempty"`
+	RequestedBy  string             `json:"requested_by,omitempty"` // server ID of the admin token
+	DryRun       bool               `json:"dry_run"`
+	Tables       []ServerMergeTable `json:"tables"`
+	Rows         uint64             `json:"rows"`
+	CreatedAt    time.Time          `json:"created_at"`
+}
+
+// ServerMergeTable counts the rows of one table attributed to the old server
+type ServerMergeTable struct {
+	Store string `json:"store"` // clickhouse or postgres
+	Table string `json:"table"`
+	Rows  uint64 `json:"rows"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// Approval states of a server
+const (
+	ServerPending  = "pending"
+	ServerApproved = "approved"
+	ServerRejected = "rejected"
+)
+
+// ServerInvite is a community-issued code a new host registers its server
+// with. Code is only set in the response that creates the invite.
+type ServerInvite struct {
+	ID        string     `json:"id"`
+	Code      string     `json:"code,omitempty"`
+	TenantID  string     `json:"tenant_id,omitempty"`
+	Note      string     `json:"note"`
+	MaxUses   int        `json:"max_uses"`
+	Uses      int        `json:"uses"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedBy string     `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// CreateServerInviteRequest issues an invite; zero values mean one use and
+// no expiry
+type CreateServerInviteRequest struct {
+	Note           string `json:"note"`
+	MaxUses        int    `json:"max_uses"`
+	ExpiresInHours int    `json:"expires_in_hours"`
+}
+
+// ServerRegistration is a self-registered server as admins review it
+type ServerRegistration struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	IPAddress   string     `json:"ip_address"`
+	Port        int        `json:"port"`
+	Region      string     `json:"region,omitempty"`
+	Description string     `json:"description,omitempty"`
+	TenantID    string     `json:"tenant_id,omitempty"`
+	InviteID    string     `json:"invite_id,omitempty"`
+	InviteNote  string     `json:"invite_note,omitempty"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ReviewedAt  *time.Time `json:"reviewed_at,omitempty"`
+	ReviewedBy  string     `json:"reviewed_by,omitempty"`
+}
@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// ServerReportSettings is where a server's weekly report is delivered. At
+// least one of WebhookURL and Email is set.
+type ServerReportSettings struct {
+	ServerID     string     `json:"server_id"`
+	Enabled      bool       `json:"enabled"`
+	WebhookURL   string     `json:"webhook_url,omitempty"`
+	Email        string     `json:"email,omitempty"`
+	LastSentWeek *time.Time `json:"last_sent_week,omitempty"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// ServerReport is a server's week: who played best, when it was busy, what
+// was played and the matches worth a look
+type ServerReport struct {
+	ServerID       string               `json:"server_id"`
+	ServerName     string               `json:"server_name"`
+	WeekStart      time.Time            `json:"week_start"`
+	Matches        int64                `json:"matches"`
+	Players        int64                `json:"players"`
+	Kills          int64                `json:"kills"`
+	TopPlayers     []ServerReportPlayer `json:"top_players"`
+	BusiestHours   []ServerReportHour   `json:"busiest_hours"`
+	Maps           []ServerReportMap    `json:"maps"`
+	NotableMatches []ServerReportMatch  `json:"notable_matches"`
+}
+
+// ServerReportPlayer is one of the week's top players on the server
+type ServerReportPlayer struct {
+	PlayerGUID string  `json:"player_guid"`
+	PlayerName string  `json:"player_name"`
+	Kills      int64   `json:"kills"`
+	Deaths     int64   `json:"deaths"`
+	Headshots  int64   `json:"headshots"`
+	KDRatio    float64 `json:"kd_ratio"`
+}
+
+// ServerReportHour is one of the busiest hours of the week (UTC); Day is
+// 1 for Monday to 7 for Sunday
+type ServerReportHour struct {
+	Day     int   `json:"day"`
+	Hour    int   `json:"hour"`
+	Players int64 `json:"players"`
+}
+
+// ServerReportMap is a map played during the week
+type ServerReportMap struct {
+	MapName string `json:"map_name"`
+	Matches int64  `json:"matches"`
+	Kills   int64  `json:"kills"`
+}
+
+// ServerReportMatch is one of the week's bloodiest matches
+type ServerReportMatch struct {
+	MatchID   string    `json:"match_id"`
+	MapName   string    `json:"map_name"`
+	StartedAt time.Time `json:"started_at"`
+	Players   int64     `json:"players"`
+	Kills     int64     `json:"kills"`
+	// PeakSpectators is the most clients watching at once, 0 when unknown
+	PeakSpectators int `json:"peak_spectators"`
+}
@@ -176,17 +176,19 @@ type PeakInfo struct {
 
 // ServerTopPlayer represents a top player on a specific server
 type ServerTopPlayer struct {
-	Rank       int     `json:"rank"`
-	GUID       string  `json:"guid"`
-	Name       string  `json:"name"`
-	Kills      int64   `json:"kills"`
-	Deaths     int64   `json:"deaths"`
-	KDRatio    float64 `json:"kd_ratio"`
-	Headshots  int64   `json:"headshots"`
-	HSPercent  float64 `json:"hs_percent"`
-	TimePlayed float64 `json:"time_played_hours"`
-	LastSeen   string  `json:"last_seen"`
-	Sessions   int64   `json:"sessions"`
+	Rank        int     `json:"rank"`
+	GUID        string  `json:"guid"`
+	Name        string  `json:"name"`
+	NameFlagged bool    `json:"name_flagged,omitempty"`
+	Kills       int64   `json:"kills"`
+	Deaths      int64   `json:"deaths"`
+	KDRatio     float64 `json:"kd_ratio"`
+	Headshots   int64   `json:"headshots"`
+	HSPercent   float64 `json:"hs_percent"`
+	TimePlayed  float64 `json:"time_played_hours"`
+	LastSeen    string  `json:"last_seen"`
+	Sessions    int64   `json:"sessions"`
+	Value       float64 `json:"value"` // Value of the requested ranking stat
 }
 
 // ServerMapStats represents map usage on a server
@@ -241,13 +243,21 @@ type ServerPulse struct {
 	ActivePlayers    int64   `json:"active_players"`     // Currently online (approx)
 }
 
+// ServerLiveStatusResponse is a server's current match. Players is the live
+// scoreboard, best score first; it stays empty for servers whose heartbeats
+// carry no roster.
 type ServerLiveStatusResponse struct {
-	IsOnline       bool   `json:"is_online"`
-	CurrentMap     string `json:"current_map"`
-	CurrentPlayers int    `json:"current_players"`
-	MaxPlayers     int    `json:"max_players"`
-	Gametype       string `json:"gametype"`
-	LastUpdate     string `json:"last_update"`
+	IsOnline       bool          `json:"is_online"`
+	MatchID        string        `json:"match_id,omitempty"`
+	CurrentMap     string        `json:"current_map"`
+	CurrentPlayers int           `json:"current_players"`
+	MaxPlayers     int           `json:"max_players"`
+	Gametype       string        `json:"gametype"`
+	AlliesScore    int           `json:"allies_score"`
+	AxisScore      int           `json:"axis_score"`
+	RoundNumber    int           `json:"round_number"`
+	Players        []RosterEntry `json:"players"`
+	LastUpdate     string        `json:"last_update"`
 }
 
 type ServerCountryStatsResponse struct {
@@ -263,3 +273,174 @@ type ServerMapRotationResponse struct {
 	AvgDuration   float64 `json:"avg_duration_mins"`
 	Popularity    float64 `json:"popularity_pct"`
 }
+
+// ServerPlayerProfile represents a single player's stats scoped to one server
+type ServerPlayerProfile struct {
+	ServerID       string            `json:"server_id"`
+	GUID           string            `json:"guid"`
+	Name           string            `json:"name"`
+	FirstSeen      string            `json:"first_seen"`
+	LastSeen       string            `json:"last_seen"`
+	Sessions       int64             `json:"sessions"`
+	Kills          int64             `json:"kills"`
+	Deaths         int64             `json:"deaths"`
+	KDRatio        float64           `json:"kd_ratio"`
+	Headshots      int64             `json:"headshots"`
+	HSPercent      float64           `json:"hs_percent"`
+	PlaytimeHours  float64           `json:"playtime_hours"`
+	FavoriteWeapon string            `json:"favorite_weapon"`
+	FavoriteMap    string            `json:"favorite_map"`
+	Ranks          ServerPlayerRanks `json:"ranks"`
+}
+
+// ServerPlayerRanks holds a player's position among all players on a server
+type ServerPlayerRanks struct {
+	Kills        int64   `json:"kills"`
+	KDRatio      int64   `json:"kd_ratio"`
+	Playtime     int64   `json:"playtime"`
+	TotalPlayers int64   `json:"total_players"`
+	Percentile   float64 `json:"percentile"` // Kills rank as top-N percent
+}
+
+// ServerIngestLag reports how far behind a server's gameplay data is compared
+// to its heartbeat. A server can keep heartbeating while its events stop.
+type ServerIngestLag struct {
+	ServerID            string     `json:"server_id"`
+	Name                string     `json:"name"`
+	LastEventAt         *time.Time `json:"last_event_at"`
+	LastHeartbeatAt     *time.Time `json:"last_heartbeat_at"`
+	IngestLagSeconds    float64    `json:"ingest_lag_seconds"`
+	HeartbeatAgeSeconds float64    `json:"heartbeat_age_seconds"`
+	Stalled             bool       `json:"stalled"`
+}
+
+// IngestHealth is the response of /admin/ingest/health
+type IngestHealth struct {
+	Status           string            `json:"status"` // ok, degraded
+	ThresholdSeconds float64           `json:"threshold_seconds"`
+	CheckedAt        time.Time         `json:"checked_at"`
+	Stalled          int               `json:"stalled"`
+	Servers          []ServerIngestLag `json:"servers"`
+}
+
+// TeamDamageStats is one player's friendly fire: team kills and damage dealt
+// to teammates
+type TeamDamageStats struct {
+	PlayerID   string `json:"player_id"`
+	PlayerName string `json:"player_name"`
+	Teamkills  uint64 `json:"teamkills"`
+	TeamDamage uint64 `json:"team_damage"`
+	Matches    uint64 `json:"matches"`
+}
+
+// GriefingReport lists a server's worst team-killers and team-damagers
+type GriefingReport struct {
+	ServerID        string            `json:"server_id"`
+	Days            int               `json:"days"`
+	Since           time.Time         `json:"since"`
+	TopTeamkillers  []TeamDamageStats `json:"top_teamkillers"`
+	TopTeamDamagers []TeamDamageStats `json:"top_team_damagers"`
+}
+
+// ServerLatencyReport is the distribution of player pings on a server, from
+// the pings heartbeats carry
+type ServerLatencyReport struct {
+	ServerID string          `json:"server_id"`
+	Days     int             `json:"days"`
+	Samples  uint64          `json:"samples"`
+	Players  uint64          `json:"players"`
+	AvgPing  float64         `json:"avg_ping"`
+	P50      float64         `json:"p50"`
+	P90      float64         `json:"p90"`
+	P99      float64         `json:"p99"`
+	Buckets  []LatencyBucket `json:"buckets"`
+}
+
+// LatencyBucket counts ping samples from MinMs up to MaxMs (unbounded when 0)
+type LatencyBucket struct {
+	MinMs   int     `json:"min_ms"`
+	MaxMs   int     `json:"max_ms,omitempty"`
+	Samples uint64  `json:"samples"`
+	Share   float64 `json:"share"` // % of samples
+}
+
+// PlayerPingHistory is a player's daily ping across all servers
+type PlayerPingHistory struct {
+	PlayerID string      `json:"player_id"`
+	Days     int         `json:"days"`
+	AvgPing  float64     `json:"avg_ping"`
+	History  []DailyPing `json:"history"`
+}
+
+// DailyPing is a player's ping on one day
+type DailyPing struct {
+	Day     time.Time `json:"day"`
+	Samples uint64    `json:"samples"`
+	AvgPing float64   `json:"avg_ping"`
+	MinPing uint16    `json:"min_ping"`
+	MaxPing uint16    `json:"max_ping"`
+}
+
+// ServerPerformanceReport is a server's health from the performance fields
+// of its heartbeats. A lagging sample is one whose frame time exceeded the
+// frame budget of 1000/sv_fps ms.
+type ServerPerformanceReport struct {
+	ServerID       string                   `json:"server_id"`
+	Hours          int                      `json:"hours"`
+	IntervalSecs   int                      `json:"interval_seconds"`
+	Samples        uint64                   `json:"samples"`
+	AvgFPS         float64                  `json:"avg_sv_fps"`
+	MinFPS         float64                  `json:"min_sv_fps"`
+	AvgFrameTime   float64                  `json:"avg_frame_time"`
+	P95FrameTime   float64                  `json:"p95_frame_time"`
+	MaxFrameTime   float64                  `json:"max_frame_time"`
+	AvgCPU         float64                  `json:"avg_cpu_usage"`
+	LaggingSamples uint64                   `json:"lagging_samples"`
+	Series         []ServerPerformancePoint `json:"series"`
+}
+
+// ServerPerformancePoint is a server's health over one interval
+type ServerPerformancePoint struct {
+	Timestamp      time.Time `json:"timestamp"`
+	Samples        uint64    `json:"samples"`
+	AvgFPS         float64   `json:"avg_sv_fps"`
+	AvgFrameTime   float64   `json:"avg_frame_time"`
+	MaxFrameTime   float64   `json:"max_frame_time"`
+	AvgCPU         float64   `json:"avg_cpu_usage"`
+	AvgPlayers     float64   `json:"avg_players"`
+	LaggingSamples uint64    `json:"lagging_samples"`
+}
+
+// BrowserServer is one entry of the in-game server browser: what a launcher
+// needs to list a server and connect to it. MedianPing is the median ping of
+// the server's players over the last hour, a hint of how well connected it
+// is until the client measures its own ping.
+type BrowserServer struct {
+	ID            string     `json:"id"`
+	Name          string     `json:"name"`
+	Address       string     `json:"address"` // host:port to connect to
+	Host          string     `json:"host"`
+	Port          int        `json:"port"`
+	Online        bool       `json:"online"`
+	Players       int        `json:"players"`
+	MaxPlayers    int        `json:"max_players"`
+	Map           string     `json:"map"`
+	Gametype      string     `json:"gametype"`
+	Region        string     `json:"region,omitempty"`
+	Official      bool       `json:"official"`
+	MedianPing    float64    `json:"median_ping,omitempty"`
+	LastHeartbeat *time.Time `json:"last_heartbeat,omitempty"`
+}
+
+// ServerBrowserFilter narrows and orders the server browser. Sort is one of
+// players (default), name, map or ping.
+type ServerBrowserFilter struct {
+	Map            string
+	Gametype       string
+	Region         string
+	Search         string // substring of the name, case-insensitive
+	NotEmpty       bool
+	NotFull        bool
+	IncludeOffline bool
+	Sort           string
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Tenant is one community hosted on a shared API instance
+type Tenant struct {
+	ID        string    `json:"id"`
+	Slug      string    `json:"slug"`
+	Name      string    `json:"name"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+}
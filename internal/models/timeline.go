@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// PlayerMilestone is a personal career moment, e.g. the 10,000th kill
+type PlayerMilestone struct {
+	PlayerGUID string    `json:"player_guid"`
+	Milestone  string    `json:"milestone"` // kills:10000, wins:100, first_headshot:<weapon>
+	Title      string    `json:"title"`
+	Value      int64     `json:"value"`
+	MatchID    string    `json:"match_id,omitempty"`
+	ServerID   string    `json:"server_id,omitempty"`
+	AchievedAt time.Time `json:"achieved_at"`
+}
+
+// Timeline entry types
+const (
+	TimelineMilestone   = "milestone"
+	TimelineAchievement = "achievement"
+	TimelineMatch       = "match"
+)
+
+// TimelineEntry is one moment on a player's timeline
+type TimelineEntry struct {
+	Type        string    `json:"type"`
+	Timestamp   time.Time `json:"timestamp"`
+	Title       string    `json:"title"`
+	Description string    `json:"description,omitempty"`
+	Key         string    `json:"key,omitempty"` // milestone or achievement code
+	Value       int64     `json:"value,omitempty"`
+	MatchID     string    `json:"match_id,omitempty"`
+	MapName     string    `json:"map_name,omitempty"`
+}
+
+// PlayerTimeline merges milestones, achievements and notable matches, newest first
+type PlayerTimeline struct {
+	PlayerGUID string          `json:"player_guid"`
+	Entries    []TimelineEntry `json:"entries"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// TitleKind separates titles (one equipped) from badges (a few equipped)
+type TitleKind string
+
+const (
+	TitleKindTitle TitleKind = "title"
+	TitleKindBadge TitleKind = "badge"
+)
+
+// MaxEquippedBadges is how many badges a player can show at once
+const MaxEquippedBadges = 3
+
+// Title is a title or badge definition. Definitions with a RuleStat are
+// granted to every player whose career stat reaches RuleMin; the rest only
+// by admins.
+type Title struct {
+	Code        string    `json:"code"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Kind        TitleKind `json:"kind"`
+	RuleStat    string    `json:"rule_stat,omitempty"`
+	RuleMin     int64     `json:"rule_min,omitempty"`
+}
+
+// PlayerTitle is a title or badge a player holds
+type PlayerTitle struct {
+	Title
+	Source    string    `json:"source"` // rule or admin
+	GrantedAt time.Time `json:"granted_at"`
+	Equipped  bool      `json:"equipped"`
+}
+
+// EquippedTitle is the short form shown on profile and leaderboard rows
+type EquippedTitle struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// PlayerCosmetics is what a player has equipped
+type PlayerCosmetics struct {
+	Title  *EquippedTitle  `json:"title,omitempty"`
+	Badges []EquippedTitle `json:"badges,omitempty"`
+}
+
+// TitleGrantRequest is the body of an admin grant
+type TitleGrantRequest struct {
+	PlayerGUID string `json:"player_guid"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// TournamentResult is a player's record in one tournament's matches
+type TournamentResult struct {
+	TournamentID string    `json:"tournament_id"`
+	Name         string    `json:"name,omitempty"`
+	Matches      int64     `json:"matches"`
+	Wins         int64     `json:"wins"`
+	Kills        int64     `json:"kills"`
+	Deaths       int64     `json:"deaths"`
+	LastPlayed   time.Time `json:"last_played"`
+}
+
+// TrophyCase gathers everything a player has earned for the profile trophy
+// page. Placements are the player's picks as player of the day or week;
+// records are their personal milestones.
+type TrophyCase struct {
+	PlayerGUID   string                `json:"player_guid"`
+	Achievements []UnlockedAchievement `json:"achievements"`
+	Badges       []PlayerTitle         `json:"badges"` // titles and badges
+	Records      []PlayerMilestone     `json:"records"`
+	Placements   []PlayerHighlight     `json:"placements"`
+	Tournaments  []TournamentResult    `json:"tournaments"`
+	GeneratedAt  time.Time             `json:"generated_at"`
+}
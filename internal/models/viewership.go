@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// Viewership sources: where a match's spectator counts came from
+const (
+	// ViewershipHeartbeat: spectator_count reported by heartbeats
+	ViewershipHeartbeat = "heartbeat"
+	// ViewershipEvents: spectator_join and spectator_leave replayed
+	ViewershipEvents = "events"
+)
+
+// MatchViewership is how many watched a match over time
+type MatchViewership struct {
+	MatchID        string  `json:"match_id"`
+	Source         string  `json:"source,omitempty"` // heartbeat or events; empty without samples
+	IntervalSecs   int     `json:"interval_seconds"`
+	PeakSpectators int     `json:"peak_spectators"`
+	AvgSpectators  float64 `json:"avg_spectators"`
+	// UniqueViewers counts the distinct spectators that joined; heartbeat
+	// counts do not say who watched, so it is 0 for them
+	UniqueViewers int               `json:"unique_viewers"`
+	Series        []ViewershipPoint `json:"series"`
+}
+
+// ViewershipPoint is a match's spectators over one interval
+type ViewershipPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	// Spectators is the count at the end of the interval, Peak its highest
+	Spectators int `json:"spectators"`
+	Peak       int `json:"peak"`
+}
+
+// FeaturedMatch is one of the most watched recent matches
+type FeaturedMatch struct {
+	MatchID        string    `json:"match_id"`
+	ServerID       string    `json:"server_id"`
+	MapName        string    `json:"map_name"`
+	FirstSeen      time.Time `json:"first_seen"`
+	LastSeen       time.Time `json:"last_seen"`
+	PeakSpectators int       `json:"peak_spectators"`
+	AvgSpectators  float64   `json:"avg_spectators"`
+	UniqueViewers  int       `json:"unique_viewers"`
+}
@@ -0,0 +1,15 @@
+package models
+
+// WeaponKillMatrix counts kills per weapon broken down by the victim's hit
+// location and by the killer's stance, for heatmap grids. Rows follow Weapons
+// (most kills first); columns follow Hitlocs and Stances.
+type WeaponKillMatrix struct {
+	PlayerID string     `json:"player_id,omitempty"`
+	Weapons  []string   `json:"weapons"`
+	Hitlocs  []string   `json:"hitlocs"`
+	Stances  []string   `json:"stances"`
+	ByHitloc [][]uint64 `json:"by_hitloc"`
+	ByStance [][]uint64 `json:"by_stance"`
+	Kills    []uint64   `json:"kills"` // per weapon
+	Total    uint64     `json:"total"`
+}
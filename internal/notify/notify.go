@@ -0,0 +1,150 @@
+// Package notify delivers messages to the people running servers: JSON posts
+// to webhooks (Discord-compatible through a "content" line) and plain text
+// email over SMTP
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrEmailDisabled is returned when sending email without an SMTP server
+var ErrEmailDisabled = errors.New("email delivery is not configured")
+
+// sendTimeout bounds one delivery
+const sendTimeout = 10 * time.Second
+
+// Webhook posts JSON payloads
+type Webhook struct {
+	client *http.Client
+}
+
+func NewWebhook() *Webhook {
+	return &Webhook{client: &http.Client{Timeout: sendTimeout}}
+}
+
+// Send posts payload as JSON to url and fails on non-2xx answers
+func (w *Webhook) Send(ctx context.Context, url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
+
+// SMTPConfig is the mail server email goes through. An empty Addr disables
+// email; Username empty sends without authentication.
+type SMTPConfig struct {
+	Addr     string // host:port
+	From     string
+	Username string
+	Password string
+}
+
+// Mailer sends plain text email
+type Mailer struct {
+	cfg SMTPConfig
+	// send is smtp.SendMail, replaced in tests
+	send func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+func NewMailer(cfg SMTPConfig) *Mailer {
+	return &Mailer{cfg: cfg, send: smtp.SendMail}
+}
+
+// Enabled reports whether an SMTP server is configured
+func (m *Mailer) Enabled() bool {
+	return m != nil && m.cfg.Addr != ""
+}
+
+// Send mails subject and body to one address. The SMTP exchange cannot be
+// cancelled, so ctx is only checked before it starts.
+func (m *Mailer) Send(ctx context.Context, to, subject, body string) error {
+	if !m.Enabled() {
+		return ErrEmailDisabled
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := ValidateEmail(to); err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		host, _, _ := net.SplitHostPort(m.cfg.Addr)
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, host)
+	}
+	return m.send(m.cfg.Addr, auth, m.cfg.From, []string{to}, message(m.cfg.From, to, subject, body))
+}
+
+// message builds a UTF-8 plain text email
+func message(from, to, subject, body string) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mimeHeader(subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n"))
+	return b.Bytes()
+}
+
+// mimeHeader encodes a header value that is not plain ASCII, and drops line
+// breaks so a value cannot add headers
+func mimeHeader(s string) string {
+	s = strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+	for _, r := range s {
+		if r > 127 {
+			return mime.QEncoding.Encode("utf-8", s)
+		}
+	}
+	return s
+}
+
+// ValidateEmail accepts a bare address like "owner@example.com"
+func ValidateEmail(addr string) error {
+	parsed, err := mail.ParseAddress(addr)
+	if err != nil || parsed.Address != addr {
+		return fmt.Errorf("invalid email address %q", addr)
+	}
+	return nil
+}
+
+// ValidateWebhookURL accepts absolute http and https URLs
+func ValidateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid webhook URL %q", raw)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+)
+
+func TestWebhookSend(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("content type = %q", r.Header.Get("Content-Type"))
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		if got["content"] == "reject" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	w := NewWebhook()
+	if err := w.Send(context.Background(), srv.URL, map[string]string{"content": "hello"}); err != nil {
+		t.Fatal(err)
+	}
+	if got["content"] != "hello" {
+		t.Errorf("posted %v", got)
+	}
+	if err := w.Send(context.Background(), srv.URL, map[string]string{"content": "reject"}); err == nil {
+		t.Error("a 400 answer was not an error")
+	}
+}
+
+func TestMailerSend(t *testing.T) {
+	if err := NewMailer(SMTPConfig{}).Send(context.Background(), "owner@example.com", "s", "b"); !errors.Is(err, ErrEmailDisabled) {
+		t.Errorf("unconfigured mailer: err = %v", err)
+	}
+
+	m := NewMailer(SMTPConfig{Addr: "mail.example.com:587", From: "stats@example.com", Username: "u", Password: "p"})
+	var addr string
+	var to []string
+	var msg string
+	var auth smtp.Auth
+	m.send = func(a string, au smtp.Auth, from string, t []string, b []byte) error {
+		addr, auth, to, msg = a, au, t, string(b)
+		return nil
+	}
+	if err := m.Send(context.Background(), "owner@example.com", "Wochenbericht für\r\nBcc: x", "line one\nline two"); err != nil {
+		t.Fatal(err)
+	}
+	if addr != "mail.example.com:587" || auth == nil || len(to) != 1 || to[0] != "owner@example.com" {
+		t.Errorf("sent to %s %v (auth %v)", addr, to, auth)
+	}
+	if strings.Contains(msg, "\r\nBcc:") {
+		t.Error("subject added a header")
+	}
+	if !strings.Contains(msg, "Subject: =?utf-8?q?") || !strings.HasSuffix(msg, "\r\n\r\nline one\r\nline two") {
+		t.Errorf("message:\n%s", msg)
+	}
+
+	if err := m.Send(context.Background(), "Owner <owner@example.com>", "s", "b"); err == nil {
+		t.Error("a named address was accepted")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, u := range []string{"https://discord.com/api/webhooks/1/x", "http://10.0.0.1:8080/hook"} {
+		if err := ValidateWebhookURL(u); err != nil {
+			t.Errorf("%s: %v", u, err)
+		}
+	}
+	for _, u := range []string{"", "discord.com/hook", "ftp://example.com/x", "https://"} {
+		if err := ValidateWebhookURL(u); err == nil {
+			t.Errorf("%q accepted", u)
+		}
+	}
+	if err := ValidateEmail("owner@example.com"); err != nil {
+		t.Error(err)
+	}
+	for _, a := range []string{"", "owner", "a@b.com, c@d.com"} {
+		if err := ValidateEmail(a); err == nil {
+			t.Errorf("%q accepted", a)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Package sanitize cleans player-supplied text (names, chat) before it is
+// stored or served: MOHAA color codes, invalid UTF-8, control and invisible
+// formatting characters, runs of whitespace and over-long values.
+package sanitize
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxNameLength is the longest name kept, in characters. Name columns in
+// Postgres are VARCHAR(64).
+const MaxNameLength = 64
+
+// MaxTextLength is the longest free text (chat, messages) kept, in characters
+const MaxTextLength = 512
+
+// StripColorCodes removes the game's ^0-^9 color codes and leaves everything
+// else, including carets that do not start a code
+func StripColorCodes(s string) string {
+	// If no caret, return original string (no allocation)
+	if !strings.Contains(s, "^") {
+		return s
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(s))
+
+	n := len(s)
+	for i := 0; i < n; i++ {
+		// Check for color code format ^[0-9]
+		if s[i] == '^' && i+1 < n && s[i+1] >= '0' && s[i+1] <= '9' {
+			i++ // Skip next char too (the digit)
+			continue
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+// Name cleans a player name: color codes, invalid UTF-8 and control or
+// invisible formatting characters are dropped, whitespace runs become one
+// space, and the result is trimmed and cut to MaxNameLength characters.
+// A name with nothing printable left is "".
+func Name(s string) string {
+	return clean(s, MaxNameLength)
+}
+
+// Text cleans free text like Name does, cut to max characters (MaxTextLength
+// when max is not positive)
+func Text(s string, max int) string {
+	if max <= 0 {
+		max = MaxTextLength
+	}
+	return clean(s, max)
+}
+
+func clean(s string, max int) string {
+	if isClean(s, max) {
+		return s
+	}
+
+	s = StripColorCodes(strings.ToValidUTF8(s, ""))
+	var sb strings.Builder
+	sb.Grow(len(s))
+	count := 0
+	space := false
+	for _, r := range s {
+		switch {
+		case unicode.IsSpace(r):
+			space = count > 0
+			continue
+		case unicode.IsControl(r), unicode.Is(unicode.Cf, r), r == utf8.RuneError:
+			continue
+		}
+		if space {
+			if count+1 >= max {
+				break
+			}
+			sb.WriteByte(' ')
+			count++
+			space = false
+		}
+		if count >= max {
+			break
+		}
+		sb.WriteRune(r)
+		count++
+	}
+	return sb.String()
+}
+
+// isClean reports whether s is already what clean would return, so the
+// common case does not allocate
+func isClean(s string, max int) bool {
+	if len(s) > max || strings.Contains(s, "^") {
+		return false
+	}
+	prevSpace := true // no leading space
+	for _, r := range s {
+		if r == utf8.RuneError || unicode.IsControl(r) || unicode.Is(unicode.Cf, r) {
+			return false
+		}
+		space := unicode.IsSpace(r)
+		if space && (prevSpace || r != ' ') {
+			return false
+		}
+		prevSpace = space
+	}
+	return !prevSpace || s == ""
+}
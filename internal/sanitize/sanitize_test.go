@@ -0,0 +1,76 @@
+package sanitize
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestName(t *testing.T) {
+	tests := []struct {
+		name, input, want string
+	}{
+		{"clean", "Sgt. Rock", "Sgt. Rock"},
+		{"clan tag colors", "^1[TAG]^7 Player^0", "[TAG] Player"},
+		{"trailing color only", "Rambo^7", "Rambo"},
+		{"lonely carets kept", "^^Smile^", "^^Smile^"},
+		{"non-code caret", "a^b", "a^b"},
+		{"nul byte", "Play\x00er", "Player"},
+		{"escape sequence", "\x1b[31mRed", "[31mRed"},
+		{"invalid UTF-8", "\xff\xfeNa\xc3me", "Name"},
+		{"Latin-1 bytes", "Jos\xe9", "Jos"},
+		{"accents kept", "José Müller", "José Müller"},
+		{"cyrillic kept", "Иван", "Иван"},
+		{"zero-width space", "Pla\u200byer", "Player"},
+		{"right-to-left override", "\u202eremyalP", "remyalP"},
+		{"byte order mark", "\ufeffPlayer", "Player"},
+		{"tabs and newlines", "a\tb\n\nc", "a b c"},
+		{"padding", "   Sniper   ", "Sniper"},
+		{"colors around spaces", " ^1 ^2 Name ^3 ", "Name"},
+		{"only colors", "^1^2^3", ""},
+		{"only control characters", "\x01\x02", ""},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Name(tt.input); got != tt.want {
+				t.Errorf("Name(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNameLength(t *testing.T) {
+	long := strings.Repeat("é", 100)
+	got := Name(long)
+	if n := utf8.RuneCountInString(got); n != MaxNameLength {
+		t.Errorf("Name kept %d characters, want %d", n, MaxNameLength)
+	}
+	if !utf8.ValidString(got) {
+		t.Error("cut through a character")
+	}
+
+	// A cut never leaves a trailing space
+	spaced := strings.Repeat("a", MaxNameLength-1) + " b"
+	if got := Name(spaced); got != strings.Repeat("a", MaxNameLength-1) {
+		t.Errorf("Name(%q) = %q", spaced, got)
+	}
+}
+
+func TestText(t *testing.T) {
+	if got := Text("^2gg\r\nwp\x07", 0); got != "gg wp" {
+		t.Errorf("Text() = %q, want %q", got, "gg wp")
+	}
+	if got := Text("abcdef", 3); got != "abc" {
+		t.Errorf("Text() = %q, want %q", got, "abc")
+	}
+}
+
+func TestStripColorCodes(t *testing.T) {
+	if got := StripColorCodes("^1Player^2 ^3Name^0"); got != "Player Name" {
+		t.Errorf("StripColorCodes() = %q", got)
+	}
+	if got := StripColorCodes("^^^1Name"); got != "^^Name" {
+		t.Errorf("StripColorCodes() = %q", got)
+	}
+}
@@ -0,0 +1,112 @@
+// Package smf talks to the API of the SMF forum the stats are shown on:
+// creating discussion topics and reading how many posts they have
+package smf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// requestTimeout bounds one call to the forum
+const requestTimeout = 10 * time.Second
+
+// maxTopicsPerRequest bounds the IDs of one Topics call
+const maxTopicsPerRequest = 100
+
+// Topic is a forum discussion topic
+type Topic struct {
+	ID    int    `json:"topic_id"`
+	URL   string `json:"url"`
+	Posts int    `json:"posts"` // the first post included
+}
+
+// Client calls the forum API at BaseURL with an API key:
+//
+//	POST {base}/topics {"board", "subject", "body"} creates a topic
+//	GET  {base}/topics?ids=1,2,3 returns the topics that exist
+//
+// both answering with Topic objects.
+type Client struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+func NewClient(baseURL, apiKey string) *Client {
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		apiKey:  apiKey,
+		client:  &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// CreateTopic starts a topic on a board
+func (c *Client) CreateTopic(ctx context.Context, board int, subject, body string) (*Topic, error) {
+	payload, err := json.Marshal(map[string]any{"board": board, "subject": subject, "body": body})
+	if err != nil {
+		return nil, err
+	}
+	var topic Topic
+	if err := c.do(ctx, http.MethodPost, c.baseURL+"/topics", payload, &topic); err != nil {
+		return nil, err
+	}
+	if topic.ID == 0 {
+		return nil, fmt.Errorf("forum answered without a topic_id")
+	}
+	return &topic, nil
+}
+
+// Topics returns the topics of ids that still exist, fetched
+// maxTopicsPerRequest at a time
+func (c *Client) Topics(ctx context.Context, ids []int) ([]Topic, error) {
+	var topics []Topic
+	for start := 0; start < len(ids); start += maxTopicsPerRequest {
+		end := min(start+maxTopicsPerRequest, len(ids))
+		parts := make([]string, 0, end-start)
+		for _, id := range ids[start:end] {
+			parts = append(parts, strconv.Itoa(id))
+		}
+		var page []Topic
+		if err := c.do(ctx, http.MethodGet, c.baseURL+"/topics?ids="+url.QueryEscape(strings.Join(parts, ",")), nil, &page); err != nil {
+			return nil, err
+		}
+		topics = append(topics, page...)
+	}
+	return topics, nil
+}
+
+func (c *Client) do(ctx context.Context, method, target string, body []byte, out any) error {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("forum answered %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to read forum answer: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package smf
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCreateTopic(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/topics" {
+			t.Errorf("%s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("authorization = %q", r.Header.Get("Authorization"))
+		}
+		var got map[string]any
+		json.NewDecoder(r.Body).Decode(&got)
+		if got["board"] != float64(4) || got["subject"] != "Final" {
+			t.Errorf("posted %v", got)
+		}
+		json.NewEncoder(w).Encode(Topic{ID: 77, URL: "https://forum.example.com/index.php?topic=77.0", Posts: 1})
+	}))
+	defer srv.Close()
+
+	topic, err := NewClient(srv.URL+"/api/", "secret").CreateTopic(context.Background(), 4, "Final", "body")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if topic.ID != 77 || topic.Posts != 1 {
+		t.Errorf("topic = %+v", topic)
+	}
+}
+
+func TestTopics(t *testing.T) {
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids := r.URL.Query().Get("ids")
+		requests = append(requests, ids)
+		var topics []Topic
+		for _, id := range strings.Split(ids, ",") {
+			if id == "3" {
+				topics = append(topics, Topic{ID: 3, Posts: 12})
+			}
+		}
+		json.NewEncoder(w).Encode(topics)
+	}))
+	defer srv.Close()
+
+	ids := make([]int, maxTopicsPerRequest+1)
+	for i := range ids {
+		ids[i] = i + 1
+	}
+	topics, err := NewClient(srv.URL, "").Topics(context.Background(), ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 2 || requests[1] != "101" {
+		t.Errorf("requests = %d, last %q", len(requests), requests[len(requests)-1])
+	}
+	if len(topics) != 1 || topics[0].Posts != 12 {
+		t.Errorf("topics = %+v", topics)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+	if _, err := NewClient(failing.URL, "").Topics(context.Background(), []int{1}); err == nil {
+		t.Error("a 502 answer was not an error")
+	}
+}
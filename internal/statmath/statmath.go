@@ -0,0 +1,79 @@
+// Package statmath holds the small statistics behind the confidence
+// intervals and significance flags of player stats
+package statmath
+
+import "math"
+
+// Z95 is the normal quantile of a two-sided 95% interval
+const Z95 = 1.959963984540054
+
+// Interval is a confidence interval
+type Interval struct {
+	Low  float64
+	High float64
+}
+
+// Scale multiplies both bounds, e.g. by 100 for percentages
+func (i Interval) Scale(f float64) Interval {
+	return Interval{Low: i.Low * f, High: i.High * f}
+}
+
+// Wilson is the Wilson score interval of a proportion of successes out of
+// trials at normal quantile z. Unlike the plain p ± z·se interval it stays
+// within [0, 1] and is not degenerate at 0 or all successes, which matters
+// for the small samples of new players. Successes above trials count as
+// trials; no trials give [0, 1].
+func Wilson(successes, trials uint64, z float64) Interval {
+	if trials == 0 {
+		return Interval{Low: 0, High: 1}
+	}
+	if successes > trials {
+		successes = trials
+	}
+	n := float64(trials)
+	p := float64(successes) / n
+	z2 := z * z
+	center := (p + z2/(2*n)) / (1 + z2/n)
+	margin := z / (1 + z2/n) * math.Sqrt(p*(1-p)/n+z2/(4*n*n))
+	return Interval{
+		Low:  math.Max(0, center-margin),
+		High: math.Min(1, center+margin),
+	}
+}
+
+// RatioInterval is an interval of the ratio a/b, like kills to deaths, from
+// the Wilson interval of a's share of a+b. It treats each of the a+b events
+// as an independent trial. ok is false when b is zero, as the upper bound is
+// then unbounded.
+func RatioInterval(a, b uint64, z float64) (Interval, bool) {
+	if b == 0 {
+		return Interval{}, false
+	}
+	share := Wilson(a, a+b, z)
+	return Interval{
+		Low:  share.Low / (1 - share.Low),
+		High: share.High / (1 - share.High),
+	}, true
+}
+
+// TwoProportionZ is the pooled z statistic of x1/n1 against x2/n2, positive
+// when the first proportion is higher. It is 0 when either sample is empty
+// or the pooled proportion leaves no variance.
+func TwoProportionZ(x1, n1, x2, n2 uint64) float64 {
+	if n1 == 0 || n2 == 0 {
+		return 0
+	}
+	p1 := float64(x1) / float64(n1)
+	p2 := float64(x2) / float64(n2)
+	pooled := float64(x1+x2) / float64(n1+n2)
+	se := math.Sqrt(pooled * (1 - pooled) * (1/float64(n1) + 1/float64(n2)))
+	if se == 0 {
+		return 0
+	}
+	return (p1 - p2) / se
+}
+
+// TwoSidedP is the two-sided p-value of a standard normal statistic
+func TwoSidedP(z float64) float64 {
+	return math.Erfc(math.Abs(z) / math.Sqrt2)
+}
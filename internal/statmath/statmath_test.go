@@ -0,0 +1,63 @@
+package statmath
+
+import (
+	"math"
+	"testing"
+)
+
+func near(a, b float64) bool { return math.Abs(a-b) < 1e-4 }
+
+func TestWilson(t *testing.T) {
+	tests := []struct {
+		name              string
+		successes, trials uint64
+		want              Interval
+	}{
+		{name: "No Trials", successes: 0, trials: 0, want: Interval{0, 1}},
+		{name: "Half", successes: 50, trials: 100, want: Interval{0.4038, 0.5962}},
+		{name: "None", successes: 0, trials: 10, want: Interval{0, 0.2775}},
+		{name: "All", successes: 10, trials: 10, want: Interval{0.7225, 1}},
+		{name: "Over", successes: 12, trials: 10, want: Interval{0.7225, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Wilson(tt.successes, tt.trials, Z95)
+			if !near(got.Low, tt.want.Low) || !near(got.High, tt.want.High) {
+				t.Errorf("Wilson(%d, %d) = %+v, want %+v", tt.successes, tt.trials, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRatioInterval(t *testing.T) {
+	got, ok := RatioInterval(50, 50, Z95)
+	if !ok || !near(got.Low, 0.6774) || !near(got.High, 1.4763) {
+		t.Errorf("RatioInterval(50, 50) = %+v, %v", got, ok)
+	}
+	if got.Low >= 1 || got.High <= 1 {
+		t.Errorf("interval %+v does not contain the ratio 1", got)
+	}
+	if _, ok := RatioInterval(5, 0, Z95); ok {
+		t.Error("ratio over zero deaths has an upper bound")
+	}
+}
+
+func TestTwoProportionZ(t *testing.T) {
+	// 60/100 against 40/100: pooled 0.5, se sqrt(0.5*0.5*0.02)
+	z := TwoProportionZ(60, 100, 40, 100)
+	if !near(z, 2.8284) {
+		t.Errorf("z = %v, want 2.8284", z)
+	}
+	if p := TwoSidedP(z); !near(p, 0.00468) {
+		t.Errorf("p = %v, want 0.00468", p)
+	}
+	if z := TwoProportionZ(40, 100, 60, 100); !near(z, -2.8284) {
+		t.Errorf("reversed z = %v, want -2.8284", z)
+	}
+	if z := TwoProportionZ(5, 5, 10, 10); z != 0 {
+		t.Errorf("z without variance = %v, want 0", z)
+	}
+	if p := TwoSidedP(0); p != 1 {
+		t.Errorf("p(0) = %v, want 1", p)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"strconv"
@@ -14,11 +15,28 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/openmohaa/stats-api/internal/db"
 	"github.com/openmohaa/stats-api/internal/models"
-	"github.com/redis/go-redis/v9"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"go.uber.org/zap"
 )
 
+// achievementQueueSize bounds the batches waiting for the achievement worker
+const achievementQueueSize = 256
+
+var (
+	achievementQueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "mohaa_achievement_queue_depth",
+		Help: "Event batches waiting for the achievement worker",
+	})
+
+	achievementEventsDropped = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "mohaa_achievement_events_dropped_total",
+		Help: "Events skipped by achievement processing because its queue was full",
+	})
+)
+
 // DBStore abstracts the database operations
 type DBStore interface {
 	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
@@ -36,33 +54,33 @@ type StatStore interface {
 	Del(ctx context.Context, keys ...string) error
 }
 
-// RedisStatStore implements StatStore using Redis
-type RedisStatStore struct {
-	client *redis.Client
+// LiveStateStatStore implements StatStore on top of the live state backend
+type LiveStateStatStore struct {
+	store db.LiveStateStore
 }
 
-func (s *RedisStatStore) Incr(ctx context.Context, key string) (int64, error) {
-	return s.client.Incr(ctx, key).Result()
+func (s *LiveStateStatStore) Incr(ctx context.Context, key string) (int64, error) {
+	return s.store.Incr(ctx, key)
 }
 
-func (s *RedisStatStore) IncrByFloat(ctx context.Context, key string, value float64) (float64, error) {
-	return s.client.IncrByFloat(ctx, key, value).Result()
+func (s *LiveStateStatStore) IncrByFloat(ctx context.Context, key string, value float64) (float64, error) {
+	return s.store.IncrByFloat(ctx, key, value)
 }
 
-func (s *RedisStatStore) Get(ctx context.Context, key string) (string, error) {
-	return s.client.Get(ctx, key).Result()
+func (s *LiveStateStatStore) Get(ctx context.Context, key string) (string, error) {
+	return s.store.Get(ctx, key)
 }
 
-func (s *RedisStatStore) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
-	return s.client.Set(ctx, key, value, expiration).Err()
+func (s *LiveStateStatStore) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	return s.store.Set(ctx, key, value, expiration)
 }
 
-func (s *RedisStatStore) Publish(ctx context.Context, channel string, message interface{}) error {
-	return s.client.Publish(ctx, channel, message).Err()
+func (s *LiveStateStatStore) Publish(ctx context.Context, channel string, message interface{}) error {
+	return s.store.Publish(ctx, channel, message)
 }
 
-func (s *RedisStatStore) Del(ctx context.Context, keys ...string) error {
-	return s.client.Del(ctx, keys...).Err()
+func (s *LiveStateStatStore) Del(ctx context.Context, keys ...string) error {
+	return s.store.Del(ctx, keys...)
 }
 
 // AchievementWorker processes events and unlocks achievements
@@ -75,10 +93,35 @@ type AchievementWorker struct {
 	mu              sync.RWMutex
 	ctx             context.Context
 	cancel          context.CancelFunc
+
+	// Batches from Enqueue, consumed in order by a single goroutine
+	queue   chan []*models.RawEvent
+	stop    chan struct{}
+	done    chan struct{}
+	started atomic.Bool
+
+	// Unlocks found while processing a batch, written together by flushUnlocks
+	pendingMu sync.Mutex
+	pending   []pendingUnlock
+
+	// announcer queues new unlocks for the match's game server; nil disables
+	announcer *Announcer
+	// inbox tells the forum user about new unlocks; nil disables
+	inbox *Inbox
+}
+
+// pendingUnlock is an achievement reached by a player, not yet written
+type pendingUnlock struct {
+	smfID     int
+	slug      string
+	timestamp time.Time
+	matchID   string
+	serverID  string
 }
 
 // AchievementDefinition holds criteria for unlocking
 type AchievementDefinition struct {
+	ID          int
 	Slug        string
 	Category    string
 	Tier        string
@@ -99,6 +142,9 @@ func NewAchievementWorker(db DBStore, ch driver.Conn, statStore StatStore, logge
 		achievementDefs: make(map[string]*AchievementDefinition),
 		ctx:             ctx,
 		cancel:          cancel,
+		queue:           make(chan []*models.RawEvent, achievementQueueSize),
+		stop:            make(chan struct{}),
+		done:            make(chan struct{}),
 	}
 
 	// Load achievement definitions from database
@@ -109,21 +155,80 @@ func NewAchievementWorker(db DBStore, ch driver.Conn, statStore StatStore, logge
 	return worker
 }
 
-// Start begins the achievement worker
+// Start begins consuming enqueued batches
 func (w *AchievementWorker) Start() {
+	w.started.Store(true)
+	go w.run()
 	w.logger.Info("Achievement Worker started")
 }
 
-// Stop gracefully stops the worker
+// Stop processes the batches already queued, then stops the worker
 func (w *AchievementWorker) Stop() {
+	if w.started.Load() {
+		close(w.stop)
+		<-w.done
+	}
 	w.cancel()
 	w.logger.Info("Achievement Worker stopped")
 }
 
+// Enqueue hands a batch to the achievement worker without blocking. Batches
+// are processed in the order they are enqueued; when the queue is full the
+// batch is dropped so achievement checks never hold up ingestion.
+func (w *AchievementWorker) Enqueue(events []*models.RawEvent) bool {
+	if len(events) == 0 {
+		return true
+	}
+	select {
+	case <-w.stop:
+		return false
+	default:
+	}
+	select {
+	case w.queue <- events:
+		achievementQueueDepth.Set(float64(len(w.queue)))
+		return true
+	default:
+		achievementEventsDropped.Add(float64(len(events)))
+		w.logger.Warnw("Achievement queue full, dropping batch", "batchSize", len(events))
+		return false
+	}
+}
+
+// run consumes the queue until Stop, then drains what is left
+func (w *AchievementWorker) run() {
+	defer close(w.done)
+	for {
+		select {
+		case events := <-w.queue:
+			w.processQueued(events)
+		case <-w.stop:
+			for {
+				select {
+				case events := <-w.queue:
+					w.processQueued(events)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (w *AchievementWorker) processQueued(events []*models.RawEvent) {
+	achievementQueueDepth.Set(float64(len(w.queue)))
+	defer func() {
+		if r := recover(); r != nil {
+			w.logger.Errorw("Achievement worker panic", "error", r, "batchSize", len(events))
+		}
+	}()
+	w.ProcessBatch(events)
+}
+
 // loadAchievementDefinitions loads all achievements from database
 func (w *AchievementWorker) loadAchievementDefinitions() error {
 	query := `
-		SELECT achievement_code, category, tier, points, requirement_value::text, achievement_name
+		SELECT achievement_id, achievement_code, category, tier, points, requirement_value::text, achievement_name
 		FROM mohaa_achievements
 	`
 
@@ -133,13 +238,12 @@ func (w *AchievementWorker) loadAchievementDefinitions() error {
 	}
 	defer rows.Close()
 
-	w.mu.Lock()
-	defer w.mu.Unlock()
-
-	count := 0
+	// Build a fresh map so a reload also drops deleted achievements
+	defs := make(map[string]*AchievementDefinition)
 	for rows.Next() {
 		def := &AchievementDefinition{}
 		err := rows.Scan(
+			&def.ID,
 			&def.Slug,
 			&def.Category,
 			&def.Tier,
@@ -152,16 +256,29 @@ func (w *AchievementWorker) loadAchievementDefinitions() error {
 			continue
 		}
 
-		w.achievementDefs[def.Slug] = def
-		count++
+		defs[def.Slug] = def
 	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read achievements: %w", err)
+	}
+
+	w.mu.Lock()
+	w.achievementDefs = defs
+	w.mu.Unlock()
 
-	w.logger.Infow("Loaded achievement definitions", "count", count)
+	w.logger.Infow("Loaded achievement definitions", "count", len(defs))
 	return nil
 }
 
 // ProcessEvent checks if an event triggers any achievements
 func (w *AchievementWorker) ProcessEvent(event *models.RawEvent) {
+	w.checkEvent(event)
+	w.flushUnlocks()
+}
+
+// checkEvent runs the achievement checks for one event; unlocks are queued
+// for flushUnlocks
+func (w *AchievementWorker) checkEvent(event *models.RawEvent) {
 	// Determine Actor ID based on event type
 	actorSMFID := w.getActorSMFID(event)
 
@@ -176,21 +293,21 @@ func (w *AchievementWorker) ProcessEvent(event *models.RawEvent) {
 		w.checkStreak(victimSMFID, event)
 	}
 
-	w.logger.Infow("Processing achievement event",
+	w.logger.Debugw("Processing achievement event",
 		"type", event.Type,
 		"actorSMFID", actorSMFID,
 		"timestamp", event.Timestamp,
 	)
 
 	if actorSMFID == 0 {
-		w.logger.Infow("Skipping achievement check - no authenticated player", "type", event.Type)
+		w.logger.Debugw("Skipping achievement check - no authenticated player", "type", event.Type)
 		return // Only process for authenticated players
 	}
 
 	// Check different event types
 	switch event.Type {
 	case models.EventPlayerKill:
-		w.logger.Infow("Checking combat achievements", "smfID", actorSMFID)
+		w.logger.Debugw("Checking combat achievements", "smfID", actorSMFID)
 		w.checkCombatAchievements(actorSMFID, event)
 		w.checkStreak(actorSMFID, event)                    // Check streak increment
 		w.checkMultikillAchievement(int(actorSMFID), event) // Check multi-kill window
@@ -227,7 +344,7 @@ func (w *AchievementWorker) getActorSMFID(event *models.RawEvent) int64 {
 
 // checkCombatAchievements checks for combat-related achievements
 func (w *AchievementWorker) checkCombatAchievements(smfID int64, event *models.RawEvent) {
-	w.logger.Infow("[ACHIEVEMENT] checkCombatAchievements called", "smfID", smfID)
+	w.logger.Debugw("[ACHIEVEMENT] checkCombatAchievements called", "smfID", smfID)
 	// Get player's total kills
 	totalKills := w.incrementPlayerStat(int(smfID), "total_kills")
 
@@ -236,12 +353,12 @@ func (w *AchievementWorker) checkCombatAchievements(smfID int64, event *models.R
 		w.incrementPlayerStat(int(smfID), "vehicle_kills")
 	}
 
-	w.logger.Infow("Player kill stats",
+	w.logger.Debugw("Player kill stats",
 		"smfID", smfID,
 		"totalKills", totalKills,
 	)
 
-	serverID := 0
+	serverID := event.ServerID
 	ts := time.Unix(int64(event.Timestamp), 0)
 
 	// Check milestone achievements (Lifetime Kills)
@@ -254,30 +371,30 @@ func (w *AchievementWorker) checkCombatAchievements(smfID int64, event *models.R
 		"killer_diamond":  10000,
 	}
 
-	w.logger.Infow("Checking milestones", "totalKills", totalKills, "milestoneCount", len(milestones))
+	w.logger.Debugw("Checking milestones", "totalKills", totalKills, "milestoneCount", len(milestones))
 
 	for slug, threshold := range milestones {
 		w.logger.Debugw("Checking milestone", "slug", slug, "threshold", threshold, "totalKills", totalKills, "passes", totalKills >= threshold)
 		if totalKills >= threshold {
-			w.logger.Infow("Achievement milestone reached!",
+			w.logger.Debugw("Achievement milestone reached!",
 				"slug", slug,
 				"threshold", threshold,
 				"totalKills", totalKills,
 				"smfID", smfID,
 			)
-			w.unlockAchievement(int(smfID), slug, serverID, ts)
+			w.unlockAchievement(int(smfID), slug, serverID, ts, event.MatchID)
 		}
 	}
 
 	// Check weapon-specific achievements
 	if event.Weapon != "" {
-		w.checkWeaponMasteryAchievement(int(smfID), event.Weapon, serverID, ts)
+		w.checkWeaponMasteryAchievement(int(smfID), event.Weapon, serverID, ts, event.MatchID)
 	}
 }
 
 // checkStreak checks/updates kill streaks
 func (w *AchievementWorker) checkStreak(smfID int64, event *models.RawEvent) {
-	serverID := 0
+	serverID := event.ServerID
 	ts := time.Unix(int64(event.Timestamp), 0)
 
 	// Determine guid to use for Redis key
@@ -324,7 +441,7 @@ func (w *AchievementWorker) checkStreak(smfID int64, event *models.RawEvent) {
 				// We need SMFID to unlock. If we came here from ProcessEvent with smfID != 0, great.
 				// If not (e.g. unauthenticated), we can't unlock (database constraint).
 				if smfID > 0 {
-					w.unlockAchievement(int(smfID), slug, serverID, ts)
+					w.unlockAchievement(int(smfID), slug, serverID, ts, event.MatchID)
 				}
 			}
 		}
@@ -335,7 +452,7 @@ func (w *AchievementWorker) checkStreak(smfID int64, event *models.RawEvent) {
 func (w *AchievementWorker) checkHeadshotAchievements(smfID int64, event *models.RawEvent) {
 	totalHeadshots := w.incrementPlayerStat(int(smfID), "total_headshots")
 
-	serverID := 0
+	serverID := event.ServerID
 	ts := time.Unix(int64(event.Timestamp), 0)
 
 	// Updated to match DB slugs and thresholds
@@ -348,7 +465,7 @@ func (w *AchievementWorker) checkHeadshotAchievements(smfID int64, event *models
 
 	for slug, threshold := range milestones {
 		if totalHeadshots == threshold {
-			w.unlockAchievement(int(smfID), slug, serverID, ts)
+			w.unlockAchievement(int(smfID), slug, serverID, ts, event.MatchID)
 		}
 	}
 }
@@ -361,7 +478,7 @@ func (w *AchievementWorker) checkMovementAchievements(smfID int64, event *models
 	// Convert to kilometers
 	distanceKM := totalDistance / 1000.0
 
-	serverID := 0
+	serverID := event.ServerID
 	ts := time.Unix(int64(event.Timestamp), 0)
 
 	// Updated to match DB slugs (meters vs km handled by logic)
@@ -374,7 +491,7 @@ func (w *AchievementWorker) checkMovementAchievements(smfID int64, event *models
 
 	for slug, threshold := range milestones {
 		if distanceKM >= threshold && distanceKM < threshold+0.1 {
-			w.unlockAchievement(int(smfID), slug, serverID, ts)
+			w.unlockAchievement(int(smfID), slug, serverID, ts, event.MatchID)
 		}
 	}
 }
@@ -383,7 +500,7 @@ func (w *AchievementWorker) checkMovementAchievements(smfID int64, event *models
 func (w *AchievementWorker) checkVehicleAchievements(smfID int64, event *models.RawEvent) {
 	vehicleKills := w.getPlayerStat(int(smfID), "vehicle_kills")
 
-	serverID := 0
+	serverID := event.ServerID
 	ts := time.Unix(int64(event.Timestamp), 0)
 
 	// Updated to match DB slugs
@@ -398,14 +515,14 @@ func (w *AchievementWorker) checkVehicleAchievements(smfID int64, event *models.
 
 	for slug, threshold := range milestones {
 		if vehicleKills == threshold {
-			w.unlockAchievement(int(smfID), slug, serverID, ts)
+			w.unlockAchievement(int(smfID), slug, serverID, ts, event.MatchID)
 		}
 	}
 }
 
 // checkSurvivalAchievements checks survival and healing achievements
 func (w *AchievementWorker) checkSurvivalAchievements(smfID int64, event *models.RawEvent) {
-	serverID := 0
+	serverID := event.ServerID
 	ts := time.Unix(int64(event.Timestamp), 0)
 
 	if event.Type == models.EventHealthPickup {
@@ -422,7 +539,7 @@ func (w *AchievementWorker) checkSurvivalAchievements(smfID int64, event *models
 
 		for slug, threshold := range milestones {
 			if healthPickups == threshold {
-				w.unlockAchievement(int(smfID), slug, serverID, ts)
+				w.unlockAchievement(int(smfID), slug, serverID, ts, event.MatchID)
 			}
 		}
 	}
@@ -437,7 +554,7 @@ func (w *AchievementWorker) checkObjectiveAchievements(smfID int64, event *model
 		totalObjectives = w.getPlayerStat(int(smfID), "objectives_completed")
 	}
 
-	serverID := 0
+	serverID := event.ServerID
 	ts := time.Unix(int64(event.Timestamp), 0)
 
 	// Updated to match DB slugs
@@ -451,7 +568,7 @@ func (w *AchievementWorker) checkObjectiveAchievements(smfID int64, event *model
 
 	for slug, threshold := range milestones {
 		if totalObjectives == threshold {
-			w.unlockAchievement(int(smfID), slug, serverID, ts)
+			w.unlockAchievement(int(smfID), slug, serverID, ts, event.MatchID)
 		}
 	}
 }
@@ -460,7 +577,7 @@ func (w *AchievementWorker) checkObjectiveAchievements(smfID int64, event *model
 func (w *AchievementWorker) checkTeamplayAchievements(smfID int64, event *models.RawEvent) {
 	totalWins := w.incrementPlayerStat(int(smfID), "total_wins")
 
-	serverID := 0
+	serverID := event.ServerID
 	ts := time.Unix(int64(event.Timestamp), 0)
 
 	// Updated to match DB slugs
@@ -474,20 +591,20 @@ func (w *AchievementWorker) checkTeamplayAchievements(smfID int64, event *models
 
 	for slug, threshold := range milestones {
 		if totalWins == threshold {
-			w.unlockAchievement(int(smfID), slug, serverID, ts)
+			w.unlockAchievement(int(smfID), slug, serverID, ts, event.MatchID)
 		}
 	}
 }
 
 // Helper functions
 
-func (w *AchievementWorker) checkWeaponMasteryAchievement(smfID int, weapon string, serverID int, ts time.Time) {
+func (w *AchievementWorker) checkWeaponMasteryAchievement(smfID int, weapon string, serverID string, ts time.Time, matchID string) {
 	weaponKills := w.getWeaponKills(smfID, weapon)
 
 	// Example: 100 kills with Kar98k unlocks "Sniper Master"
 	// Mapped to kar98k_elite (Gold, 500 kills) in DB
 	if weapon == "kar98k" && weaponKills == 500 {
-		w.unlockAchievement(smfID, "kar98k_elite", serverID, ts)
+		w.unlockAchievement(smfID, "kar98k_elite", serverID, ts, matchID)
 	}
 }
 
@@ -501,7 +618,7 @@ func (w *AchievementWorker) checkMultikillAchievement(smfID int, event *models.R
 		return
 	}
 
-	serverID := 0
+	serverID := event.ServerID
 	ts := time.Unix(int64(event.Timestamp), 0)
 
 	// Use a Redis key with TTL for multi-kill window tracking.
@@ -531,7 +648,7 @@ func (w *AchievementWorker) checkMultikillAchievement(smfID int, event *models.R
 
 	for slug, threshold := range multikillMilestones {
 		if killCount == threshold && smfID > 0 {
-			w.unlockAchievement(smfID, slug, serverID, ts)
+			w.unlockAchievement(smfID, slug, serverID, ts, event.MatchID)
 			w.logger.Infow("Multi-kill achievement unlocked!",
 				"slug", slug,
 				"killCount", killCount,
@@ -613,7 +730,7 @@ func (w *AchievementWorker) fetchFromDB(smfID int, statName string) int {
 	case "total_headshots":
 		query = `SELECT count() FROM mohaa_stats.raw_events WHERE actor_smf_id = ? AND event_type IN ('player_kill', 'bot_killed') AND hitloc = 'head'`
 	case "total_distance":
-		query = `SELECT SUM(walked + sprinted + swam + driven) FROM mohaa_stats.raw_events WHERE player_smf_id = ? AND event_type = 'distance'`
+		query = `SELECT toUInt64(sumIf((walked + sprinted + swam + driven) * sample_weight, event_type = 'distance')) FROM mohaa_stats.movement_events_all WHERE actor_smf_id = ?`
 	case "vehicle_kills":
 		query = `SELECT count() FROM mohaa_stats.raw_events WHERE actor_smf_id = ? AND event_type IN ('player_kill', 'bot_killed') AND inflictor LIKE '%vehicle%'`
 	case "health_pickups":
@@ -666,82 +783,111 @@ func (w *AchievementWorker) getWeaponKills(smfID int, weapon string) int {
 	return int(count)
 }
 
-// unlockAchievement records an achievement unlock
-func (w *AchievementWorker) unlockAchievement(smfID int, slug string, serverID int, timestamp time.Time) {
-	// Get achievement ID from code
-	var achievementID int
-	getIDQuery := `
-		SELECT achievement_id FROM mohaa_achievements WHERE achievement_code = $1
-	`
-	err := w.db.QueryRow(w.ctx, getIDQuery, slug).Scan(&achievementID)
-	if err != nil {
-		w.logger.Errorw("Achievement code not found in database",
-			"slug", slug,
-			"error", err,
-		)
-		return
-	}
+// unlockAchievement records that a player reached an achievement. The unlock
+// is written with the rest of the batch by flushUnlocks.
+func (w *AchievementWorker) unlockAchievement(smfID int, slug string, serverID string, timestamp time.Time, matchID string) {
+	w.pendingMu.Lock()
+	w.pending = append(w.pending, pendingUnlock{smfID: smfID, slug: slug, timestamp: timestamp, matchID: matchID, serverID: serverID})
+	w.pendingMu.Unlock()
+}
 
-	// Check if already unlocked
-	var exists bool
-	checkQuery := `
-		SELECT EXISTS(
-			SELECT 1 FROM mohaa_player_achievements
-			WHERE smf_member_id = $1 AND achievement_id = $2 AND unlocked = true
-		)
-	`
-	err = w.db.QueryRow(w.ctx, checkQuery, smfID, achievementID).Scan(&exists)
-	if err != nil {
-		w.logger.Errorw("Error checking existing achievement", "error", err)
+// flushUnlocks writes the pending unlocks in one statement and notifies the
+// players whose achievement was not unlocked before
+func (w *AchievementWorker) flushUnlocks() {
+	w.pendingMu.Lock()
+	pending := w.pending
+	w.pending = nil
+	w.pendingMu.Unlock()
+	if len(pending) == 0 {
 		return
 	}
-	if exists {
-		w.logger.Debugw("Achievement already unlocked", "slug", slug, "smfID", smfID)
-		return // Already unlocked
+
+	type unlockKey struct {
+		smfID         int
+		achievementID int
 	}
 
-	// Get achievement details
+	// A reload swaps in a new map and never changes the old one, so the map
+	// taken under the lock stays valid after it is released
 	w.mu.RLock()
-	def, exists := w.achievementDefs[slug]
+	achievementDefs := w.achievementDefs
 	w.mu.RUnlock()
 
-	if !exists {
-		w.logger.Errorw("Achievement definition not found in memory", "slug", slug)
+	// Milestone checks re-report reached thresholds on every event; keep the
+	// first report of each (player, achievement)
+	seen := make(map[unlockKey]pendingUnlock, len(pending))
+	defs := make(map[int]*AchievementDefinition)
+	var smfIDs, achievementIDs []int32
+	var timestamps []time.Time
+	var matchIDs []string
+	for _, u := range pending {
+		def, ok := achievementDefs[u.slug]
+		if !ok {
+			w.logger.Errorw("Achievement definition not found in memory", "slug", u.slug)
+			continue
+		}
+		key := unlockKey{u.smfID, def.ID}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = u
+		defs[def.ID] = def
+		smfIDs = append(smfIDs, int32(u.smfID))
+		achievementIDs = append(achievementIDs, int32(def.ID))
+		timestamps = append(timestamps, u.timestamp)
+		matchIDs = append(matchIDs, u.matchID)
+	}
+	if len(smfIDs) == 0 {
 		return
 	}
 
-	// Update or insert player achievement record
-	insertQuery := `
+	// Rows already unlocked are left alone and not returned, so only new
+	// unlocks are notified
+	rows, err := w.db.Query(w.ctx, `
 		INSERT INTO mohaa_player_achievements
-		(smf_member_id, achievement_id, target, unlocked, unlocked_at, progress)
-		VALUES ($1, $2, $3, true, $4, $3)
-		ON CONFLICT (smf_member_id, achievement_id) 
-		DO UPDATE SET unlocked = true, unlocked_at = $4, progress = EXCLUDED.target
-	`
-
-	_, err = w.db.Exec(w.ctx, insertQuery, smfID, achievementID, 100, timestamp)
+		(smf_member_id, achievement_id, target, unlocked, unlocked_at, progress, match_id)
+		SELECT u.smf_member_id, u.achievement_id, 100, true, u.unlocked_at, 100, NULLIF(u.match_id, '')
+		FROM unnest($1::int[], $2::int[], $3::timestamp[], $4::text[]) AS u(smf_member_id, achievement_id, unlocked_at, match_id)
+		ON CONFLICT (smf_member_id, achievement_id)
+		DO UPDATE SET unlocked = true, unlocked_at = EXCLUDED.unlocked_at, progress = EXCLUDED.target, match_id = EXCLUDED.match_id
+		WHERE mohaa_player_achievements.unlocked IS NOT TRUE
+		RETURNING smf_member_id, achievement_id
+	`, smfIDs, achievementIDs, timestamps, matchIDs)
 	if err != nil {
-		w.logger.Errorw("Failed to insert achievement unlock",
-			"slug", slug,
-			"smfID", smfID,
-			"achievementID", achievementID,
-			"error", err,
-		)
+		w.logger.Errorw("Failed to insert achievement unlocks", "count", len(smfIDs), "error", err)
 		return
 	}
+	defer rows.Close()
 
-	// Note: Player achievement points can be calculated via SUM query
-	// No need to maintain separate counter
+	for rows.Next() {
+		var smfID, achievementID int
+		if err := rows.Scan(&smfID, &achievementID); err != nil {
+			w.logger.Errorw("Failed to scan achievement unlock", "error", err)
+			continue
+		}
+		def := defs[achievementID]
+		if def == nil {
+			continue
+		}
 
-	w.logger.Infow("🏆 Achievement unlocked!",
-		"slug", slug,
-		"smfID", smfID,
-		"points", def.Points,
-		"description", def.Description,
-	)
+		// Note: Player achievement points can be calculated via SUM query
+		// No need to maintain separate counter
 
-	// Send notification to player
-	w.notifyPlayer(smfID, slug, def)
+		w.logger.Infow("🏆 Achievement unlocked!",
+			"slug", def.Slug,
+			"smfID", smfID,
+			"points", def.Points,
+			"description", def.Description,
+		)
+
+		// Send notification to player
+		w.notifyPlayer(smfID, def.Slug, def)
+		w.announceUnlock(seen[unlockKey{smfID, achievementID}], def)
+		w.inboxUnlock(seen[unlockKey{smfID, achievementID}], def)
+	}
+	if err := rows.Err(); err != nil {
+		w.logger.Errorw("Failed to read achievement unlocks", "error", err)
+	}
 }
 
 // notifyPlayer sends achievement notification (placeholder)
@@ -771,11 +917,43 @@ func (w *AchievementWorker) notifyPlayer(smfID int, slug string, def *Achievemen
 	w.logger.Debugw("Achievement notification published", "smfID", smfID, "slug", slug)
 }
 
-// ProcessBatch processes multiple events in batch
+// announceUnlock queues an unlock for the game server running its match
+func (w *AchievementWorker) announceUnlock(u pendingUnlock, def *AchievementDefinition) {
+	if w.announcer == nil || u.matchID == "" || u.serverID == "" {
+		return
+	}
+	err := w.announcer.Push(w.ctx, models.Announcement{
+		Type:        models.AnnouncementAchievement,
+		MatchID:     u.matchID,
+		ServerID:    u.serverID,
+		PlayerSMFID: int64(u.smfID),
+		Title:       def.Slug,
+		Value:       int64(def.Points),
+		Message:     fmt.Sprintf("Achievement unlocked: %s (%d points)", def.Description, def.Points),
+	})
+	if err != nil {
+		w.logger.Warnw("Failed to queue achievement announcement", "smfID", u.smfID, "slug", def.Slug, "error", err)
+	}
+}
+
+// inboxUnlock puts an unlock in the forum user's notifications
+func (w *AchievementWorker) inboxUnlock(u pendingUnlock, def *AchievementDefinition) {
+	w.inbox.Push(models.Notification{
+		ForumUserID: u.smfID,
+		Kind:        models.NotifyAchievement,
+		Title:       fmt.Sprintf("Achievement unlocked: %s", def.Description),
+		Subject:     def.Slug,
+		Value:       int64(def.Points),
+		MatchID:     u.matchID,
+	})
+}
+
+// ProcessBatch checks a batch of events in order and writes their unlocks together
 func (w *AchievementWorker) ProcessBatch(events []*models.RawEvent) {
 	for _, event := range events {
-		w.ProcessEvent(event)
+		w.checkEvent(event)
 	}
+	w.flushUnlocks()
 }
 
 // ReloadDefinitions reloads achievement definitions from database
@@ -4,9 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/jackc/pgx/v5"
 	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/models"
 )
 
 type mockConn struct {
@@ -142,3 +146,102 @@ func TestNotifyPlayer(t *testing.T) {
 		t.Errorf("expected points %d, got %v", def.Points, payload["points"])
 	}
 }
+
+// recordingDBStore records Query calls and returns no rows
+type recordingDBStore struct {
+	MockDBStore
+	queries [][]any
+}
+
+func (m *recordingDBStore) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	m.queries = append(m.queries, args)
+	return &MockPGXRows{}, nil
+}
+
+func TestFlushUnlocksBatchesAndDedupes(t *testing.T) {
+	store := &recordingDBStore{}
+	worker := &AchievementWorker{
+		db:     store,
+		logger: zap.NewNop().Sugar(),
+		ctx:    context.Background(),
+		achievementDefs: map[string]*AchievementDefinition{
+			"killer_bronze": {ID: 1, Slug: "killer_bronze"},
+			"killer_silver": {ID: 2, Slug: "killer_silver"},
+		},
+	}
+
+	ts := time.Unix(1700000000, 0)
+	worker.unlockAchievement(7, "killer_bronze", "", ts, "")
+	worker.unlockAchievement(7, "killer_bronze", "", ts.Add(time.Second), "")
+	worker.unlockAchievement(7, "killer_silver", "", ts, "")
+	worker.unlockAchievement(8, "killer_bronze", "", ts, "")
+	worker.unlockAchievement(8, "unknown", "", ts, "")
+	worker.flushUnlocks()
+
+	if len(store.queries) != 1 {
+		t.Fatalf("expected 1 statement, got %d", len(store.queries))
+	}
+	smfIDs := store.queries[0][0].([]int32)
+	achievementIDs := store.queries[0][1].([]int32)
+	if len(smfIDs) != 3 || len(achievementIDs) != 3 {
+		t.Fatalf("expected 3 unlocks, got %v / %v", smfIDs, achievementIDs)
+	}
+
+	// Nothing pending, nothing written
+	worker.flushUnlocks()
+	if len(store.queries) != 1 {
+		t.Errorf("empty flush wrote %d statements", len(store.queries)-1)
+	}
+}
+
+// TestFlushUnlocksDuringReload flushes while definitions are reloaded; run
+// with -race
+func TestFlushUnlocksDuringReload(t *testing.T) {
+	worker := &AchievementWorker{
+		db:              &recordingDBStore{},
+		logger:          zap.NewNop().Sugar(),
+		ctx:             context.Background(),
+		achievementDefs: map[string]*AchievementDefinition{"killer_bronze": {ID: 1, Slug: "killer_bronze"}},
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			defs := map[string]*AchievementDefinition{"killer_bronze": {ID: 1, Slug: "killer_bronze"}}
+			worker.mu.Lock()
+			worker.achievementDefs = defs
+			worker.mu.Unlock()
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		worker.unlockAchievement(7, "killer_bronze", "", time.Now(), "")
+		worker.flushUnlocks()
+	}
+	<-done
+}
+
+func TestAchievementQueue(t *testing.T) {
+	worker := NewAchievementWorker(&MockDBStore{}, &MockClickHouseConn{}, NewMockStatStore(), zap.NewNop().Sugar())
+
+	// Not started: the queue fills up, then batches are dropped
+	event := []*models.RawEvent{{Type: models.EventChat}}
+	for i := 0; i < achievementQueueSize; i++ {
+		if !worker.Enqueue(event) {
+			t.Fatalf("batch %d rejected before the queue was full", i)
+		}
+	}
+	if worker.Enqueue(event) {
+		t.Error("expected a full queue to drop the batch")
+	}
+
+	// Stop drains what was queued
+	worker.Start()
+	worker.Stop()
+	if n := len(worker.queue); n != 0 {
+		t.Errorf("%d batches left after Stop", n)
+	}
+	if worker.Enqueue(event) {
+		t.Error("expected a stopped worker to refuse batches")
+	}
+}
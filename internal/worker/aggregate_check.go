@@ -0,0 +1,125 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/logic"
+)
+
+var (
+	aggregateDriftRows = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mohaa_aggregate_drift_rows",
+		Help: "Sampled aggregate rows that drifted from raw_events beyond tolerance in the last check",
+	}, []string{"table"})
+
+	aggregateCheckLastRun = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mohaa_aggregate_check_last_run_timestamp_seconds",
+		Help: "Unix time of the last completed aggregate consistency check",
+	}, []string{"table"})
+)
+
+// AggregateCheckConfig controls the nightly aggregate consistency check
+type AggregateCheckConfig struct {
+	Hour       int // UTC hour to run at; negative disables the job
+	Days       int
+	SampleSize int
+	Tolerance  float64
+	Tables     []string
+}
+
+// AggregateChecker periodically compares MV-fed aggregates with raw_events
+type AggregateChecker struct {
+	svc    logic.AggregateService
+	config AggregateCheckConfig
+	logger *zap.SugaredLogger
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewAggregateChecker(svc logic.AggregateService, cfg AggregateCheckConfig, logger *zap.Logger) *AggregateChecker {
+	if len(cfg.Tables) == 0 {
+		cfg.Tables = []string{"player_stats_daily", "player_server_stats_daily"}
+	}
+	return &AggregateChecker{
+		svc:    svc,
+		config: cfg,
+		logger: logger.Sugar(),
+		done:   make(chan struct{}),
+	}
+}
+
+// Start schedules the check once a day at the configured UTC hour
+func (c *AggregateChecker) Start(ctx context.Context) {
+	if c.config.Hour < 0 {
+		close(c.done)
+		c.logger.Info("Aggregate consistency check disabled")
+		return
+	}
+
+	ctx, c.cancel = context.WithCancel(ctx)
+	go func() {
+		defer close(c.done)
+		for {
+			wait := time.Until(nextDailyRun(time.Now().UTC(), c.config.Hour))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+				c.RunOnce(ctx)
+			}
+		}
+	}()
+}
+
+// Stop cancels the schedule and waits for an in-flight check to finish
+func (c *AggregateChecker) Stop() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	<-c.done
+}
+
+// RunOnce checks every configured table and logs any drift found
+func (c *AggregateChecker) RunOnce(ctx context.Context) {
+	for _, table := range c.config.Tables {
+		report, err := c.svc.CheckConsistency(ctx, table, c.config.Days, c.config.SampleSize, c.config.Tolerance)
+		if err != nil {
+			c.logger.Errorw("Aggregate consistency check failed", "table", table, "error", err)
+			continue
+		}
+
+		aggregateDriftRows.WithLabelValues(table).Set(float64(len(report.Drifted)))
+		aggregateCheckLastRun.WithLabelValues(table).Set(float64(report.CheckedAt.Unix()))
+
+		if report.Healthy {
+			c.logger.Infow("Aggregate consistency check passed",
+				"table", table,
+				"players", report.PlayersSampled,
+				"rows", report.RowsCompared,
+			)
+			continue
+		}
+
+		c.logger.Warnw("Aggregate drift detected, consider POST /api/v1/admin/aggregates/rebuild",
+			"table", table,
+			"players", report.PlayersSampled,
+			"rows", report.RowsCompared,
+			"drifted", len(report.Drifted),
+			"tolerance", report.Tolerance,
+		)
+	}
+}
+
+// nextDailyRun returns the next time strictly after now at hour:00 UTC
+func nextDailyRun(now time.Time, hour int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, time.UTC)
+	if !next.After(now) {
+		next = next.Add(24 * time.Hour)
+	}
+	return next
+}
@@ -0,0 +1,227 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+var aggregateRebuildProgress = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "mohaa_aggregate_rebuild_progress",
+	Help: "Fraction of table-days rebuilt by the current or last targeted aggregate rebuild",
+})
+
+// maxRebuildJobs is how many finished jobs are kept for polling
+const maxRebuildJobs = 20
+
+var (
+	// ErrRebuildRunning is returned by Submit while another rebuild runs
+	ErrRebuildRunning = errors.New("an aggregate rebuild is already running")
+	// ErrEmptyRebuild is returned by Submit for a request naming no match or player
+	ErrEmptyRebuild = errors.New("no matches or players to rebuild for")
+)
+
+// AggregateRebuilder runs targeted aggregate rebuilds in the background: the
+// days touched by voided matches or banned players are rebuilt table by table,
+// then the leaderboard snapshot is refreshed. One job runs at a time.
+type AggregateRebuilder struct {
+	svc    logic.AggregateService
+	purger *CachePurger
+	logger *zap.SugaredLogger
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	jobs    []*models.AggregateRebuildJob // oldest first
+	running bool
+}
+
+func NewAggregateRebuilder(ctx context.Context, svc logic.AggregateService, logger *zap.Logger) *AggregateRebuilder {
+	ctx, cancel := context.WithCancel(ctx)
+	return &AggregateRebuilder{
+		svc:    svc,
+		logger: logger.Sugar(),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// SetCachePurger makes finished rebuilds purge the leaderboards and the
+// rebuilt players' profiles
+func (b *AggregateRebuilder) SetCachePurger(p *CachePurger) {
+	b.purger = p
+}
+
+// Submit queues a rebuild and starts it. The returned job is a copy; poll Job
+// for progress.
+func (b *AggregateRebuilder) Submit(req models.AggregateRebuildRequest) (*models.AggregateRebuildJob, error) {
+	if len(req.MatchIDs) == 0 && len(req.PlayerIDs) == 0 {
+		return nil, ErrEmptyRebuild
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.running {
+		return nil, ErrRebuildRunning
+	}
+	job := &models.AggregateRebuildJob{
+		ID:        uuid.NewString(),
+		Status:    models.AggregateRebuildQueued,
+		Request:   req,
+		Tables:    logic.PlayerAggregateTables(),
+		CreatedAt: time.Now().UTC(),
+	}
+	b.jobs = append(b.jobs, job)
+	if len(b.jobs) > maxRebuildJobs {
+		b.jobs = b.jobs[len(b.jobs)-maxRebuildJobs:]
+	}
+	b.running = true
+
+	b.wg.Add(1)
+	go b.run(job.ID)
+	return copyRebuildJob(job), nil
+}
+
+// Job returns a copy of a job, or nil if it is unknown or was dropped
+func (b *AggregateRebuilder) Job(id string) *models.AggregateRebuildJob {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, job := range b.jobs {
+		if job.ID == id {
+			return copyRebuildJob(job)
+		}
+	}
+	return nil
+}
+
+// Jobs returns copies of the kept jobs, newest first
+func (b *AggregateRebuilder) Jobs() []*models.AggregateRebuildJob {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	jobs := make([]*models.AggregateRebuildJob, 0, len(b.jobs))
+	for i := len(b.jobs) - 1; i >= 0; i-- {
+		jobs = append(jobs, copyRebuildJob(b.jobs[i]))
+	}
+	return jobs
+}
+
+// Stop cancels a running job and waits for it to return
+func (b *AggregateRebuilder) Stop() {
+	b.cancel()
+	b.wg.Wait()
+}
+
+func (b *AggregateRebuilder) run(id string) {
+	defer b.wg.Done()
+
+	var req models.AggregateRebuildRequest
+	var tables []string
+	b.update(id, func(job *models.AggregateRebuildJob) {
+		now := time.Now().UTC()
+		job.Status = models.AggregateRebuildRunning
+		job.StartedAt = &now
+		req, tables = job.Request, job.Tables
+	})
+	aggregateRebuildProgress.Set(0)
+	b.logger.Warnw("Targeted aggregate rebuild started", "job", id, "matches", len(req.MatchIDs), "players", len(req.PlayerIDs), "reason", req.Reason)
+
+	err := b.rebuild(id, req, tables)
+
+	b.mu.Lock()
+	b.running = false
+	b.mu.Unlock()
+	b.update(id, func(job *models.AggregateRebuildJob) {
+		now := time.Now().UTC()
+		job.FinishedAt = &now
+		job.Current = ""
+		job.Status = models.AggregateRebuildDone
+		if err != nil {
+			job.Status = models.AggregateRebuildFailed
+			job.Error = err.Error()
+		}
+	})
+	if err != nil {
+		b.logger.Errorw("Targeted aggregate rebuild failed", "job", id, "error", err)
+		return
+	}
+	b.logger.Infow("Targeted aggregate rebuild finished", "job", id)
+}
+
+func (b *AggregateRebuilder) rebuild(id string, req models.AggregateRebuildRequest, tables []string) error {
+	ctx := b.ctx
+	days, err := b.svc.AffectedDays(ctx, req.MatchIDs, req.PlayerIDs)
+	if err != nil {
+		return err
+	}
+	exclude, err := b.svc.Exclusions(ctx)
+	if err != nil {
+		return err
+	}
+	total := len(days) * len(tables)
+	b.update(id, func(job *models.AggregateRebuildJob) {
+		job.Days = len(days)
+		job.Total = total
+	})
+
+	done := 0
+	for _, table := range tables {
+		for _, day := range days {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			b.update(id, func(job *models.AggregateRebuildJob) {
+				job.Current = table + " " + day.Format("2006-01-02")
+			})
+			if err := b.svc.RebuildDay(ctx, table, day, exclude); err != nil {
+				return err
+			}
+			done++
+			aggregateRebuildProgress.Set(float64(done) / float64(total))
+			b.update(id, func(job *models.AggregateRebuildJob) {
+				job.Done = done
+				job.Progress = float64(done) / float64(total)
+			})
+		}
+	}
+
+	b.update(id, func(job *models.AggregateRebuildJob) { job.Current = "leaderboard_global" })
+	ranked, err := b.svc.RefreshLeaderboard(ctx, exclude)
+	if err != nil {
+		return err
+	}
+	b.purger.Invalidate("leaderboard_refresh", req.PlayerIDs, true)
+	aggregateRebuildProgress.Set(1)
+	b.update(id, func(job *models.AggregateRebuildJob) {
+		job.LeaderboardRows = ranked
+		job.Progress = 1
+	})
+	return nil
+}
+
+// update applies fn to a job under the lock
+func (b *AggregateRebuilder) update(id string, fn func(job *models.AggregateRebuildJob)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, job := range b.jobs {
+		if job.ID == id {
+			fn(job)
+			return
+		}
+	}
+}
+
+func copyRebuildJob(job *models.AggregateRebuildJob) *models.AggregateRebuildJob {
+	c := *job
+	c.Tables = append([]string(nil), job.Tables...)
+	return &c
+}
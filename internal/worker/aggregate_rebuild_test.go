@@ -0,0 +1,104 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// fakeAggregates records targeted rebuilds. RebuildDay blocks on release when set.
+type fakeAggregates struct {
+	logic.AggregateService
+	days    []time.Time
+	rebuilt []string
+	release chan struct{}
+}
+
+func (f *fakeAggregates) AffectedDays(ctx context.Context, matchIDs, playerIDs []string) ([]time.Time, error) {
+	return f.days, nil
+}
+
+func (f *fakeAggregates) Exclusions(ctx context.Context) (*logic.AggregateExclusions, error) {
+	return &logic.AggregateExclusions{Matches: []string{"voided"}}, nil
+}
+
+func (f *fakeAggregates) RebuildDay(ctx context.Context, table string, day time.Time, exclude *logic.AggregateExclusions) error {
+	if f.release != nil {
+		<-f.release
+	}
+	f.rebuilt = append(f.rebuilt, table+" "+day.Format("2006-01-02"))
+	return nil
+}
+
+func (f *fakeAggregates) RefreshLeaderboard(ctx context.Context, exclude *logic.AggregateExclusions) (int, error) {
+	return 42, nil
+}
+
+func waitForRebuild(t *testing.T, b *AggregateRebuilder, id string) *models.AggregateRebuildJob {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if job := b.Job(id); job.FinishedAt != nil {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("rebuild did not finish")
+	return nil
+}
+
+func TestAggregateRebuilder(t *testing.T) {
+	svc := &fakeAggregates{
+		days: []time.Time{
+			time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+		},
+		release: make(chan struct{}),
+	}
+	b := NewAggregateRebuilder(context.Background(), svc, zap.NewNop())
+	defer b.Stop()
+
+	if _, err := b.Submit(models.AggregateRebuildRequest{}); !errors.Is(err, ErrEmptyRebuild) {
+		t.Errorf("empty request: err = %v, want ErrEmptyRebuild", err)
+	}
+
+	job, err := b.Submit(models.AggregateRebuildRequest{MatchIDs: []string{"m1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Submit(models.AggregateRebuildRequest{PlayerIDs: []string{"p1"}}); !errors.Is(err, ErrRebuildRunning) {
+		t.Errorf("second job: err = %v, want ErrRebuildRunning", err)
+	}
+	close(svc.release)
+
+	done := waitForRebuild(t, b, job.ID)
+	if done.Status != models.AggregateRebuildDone || done.Error != "" {
+		t.Fatalf("status = %s (%s), want done", done.Status, done.Error)
+	}
+	tables := len(logic.PlayerAggregateTables())
+	if done.Days != 2 || done.Total != 2*tables || done.Done != done.Total || done.Progress != 1 {
+		t.Errorf("progress = %d days, %d/%d (%v), want 2 days, %d/%d", done.Days, done.Done, done.Total, done.Progress, 2*tables, 2*tables)
+	}
+	if len(svc.rebuilt) != 2*tables {
+		t.Errorf("rebuilt %v, want every table for both days", svc.rebuilt)
+	}
+	if done.LeaderboardRows != 42 {
+		t.Errorf("leaderboard rows = %d, want 42", done.LeaderboardRows)
+	}
+
+	// The finished job no longer blocks new ones
+	next, err := b.Submit(models.AggregateRebuildRequest{PlayerIDs: []string{"p1"}})
+	if err != nil {
+		t.Fatalf("job after a finished one: %v", err)
+	}
+	waitForRebuild(t, b, next.ID)
+	if jobs := b.Jobs(); len(jobs) != 2 || jobs[0].ID != next.ID {
+		t.Errorf("jobs not listed newest first: %+v", jobs)
+	}
+}
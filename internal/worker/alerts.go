@@ -0,0 +1,397 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/models"
+	"github.com/openmohaa/stats-api/internal/notify"
+)
+
+var (
+	alertsFiring = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mohaa_alerts_firing",
+		Help: "Operator alerts currently firing by rule",
+	}, []string{"rule"})
+
+	alertNotifications = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "mohaa_alert_notifications_total",
+		Help: "Operator alert webhook posts by result (sent, failed, silenced)",
+	}, []string{"result"})
+)
+
+// Alert rule names
+const (
+	AlertIngestLag        = "ingest_lag"
+	AlertQueueDepth       = "queue_depth"
+	AlertServerOffline    = "server_offline"
+	AlertClickHouseErrors = "clickhouse_errors"
+)
+
+var alertRuleNames = []string{AlertIngestLag, AlertQueueDepth, AlertServerOffline, AlertClickHouseErrors}
+
+var (
+	// ErrUnknownAlertRule is returned when silencing a rule that does not exist
+	ErrUnknownAlertRule = errors.New("unknown alert rule")
+	// ErrSilenceNotFound is returned when lifting an unknown silence
+	ErrSilenceNotFound = errors.New("silence not found")
+)
+
+// AlertConfig sets the operator alert rules. A zero threshold disables its
+// rule; an empty WebhookURL keeps alerts to the state endpoint and metrics.
+type AlertConfig struct {
+	WebhookURL string
+	// Interval is how often rules are evaluated, one minute when zero
+	Interval time.Duration
+	// IngestLag fires for a server still heartbeating whose gameplay events
+	// stopped this long ago
+	IngestLag time.Duration
+	// QueueDepth fires when the worker queues hold this many events
+	QueueDepth int
+	// ServerOffline fires for an active server without a heartbeat this long
+	ServerOffline time.Duration
+	// ClickHouseErrors fires when this many batch inserts fail within one
+	// evaluation interval
+	ClickHouseErrors int
+}
+
+func (c AlertConfig) interval() time.Duration {
+	if c.Interval <= 0 {
+		return time.Minute
+	}
+	return c.Interval
+}
+
+// AlertSources are what the rules read
+type AlertSources struct {
+	Ingest        IngestHealthSource
+	QueueDepth    func() int
+	FailedBatches func() uint64
+}
+
+// AlertPost is the JSON posted to the alert webhook when an alert fires or
+// resolves. Content is a readable line so chat webhooks (Discord) can show
+// it as is.
+type AlertPost struct {
+	Event   string       `json:"event"` // alert_firing or alert_resolved
+	Content string       `json:"content"`
+	Alert   models.Alert `json:"alert"`
+}
+
+// AlertEngine evaluates the operator alert rules on an interval, tracks which
+// alerts fire and posts each transition to a webhook unless a silence
+// matches. State and silences live in memory, per API instance.
+type AlertEngine struct {
+	config  atomic.Pointer[AlertConfig]
+	sources AlertSources
+	webhook *notify.Webhook
+	logger  *zap.SugaredLogger
+
+	mu          sync.Mutex
+	firing      map[string]*models.Alert // by rule and key
+	silences    map[string]*models.AlertSilence
+	evaluatedAt *time.Time
+	lastFailed  uint64
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewAlertEngine(cfg AlertConfig, sources AlertSources, logger *zap.Logger) *AlertEngine {
+	e := &AlertEngine{
+		sources:  sources,
+		webhook:  notify.NewWebhook(),
+		logger:   logger.Sugar(),
+		firing:   make(map[string]*models.Alert),
+		silences: make(map[string]*models.AlertSilence),
+		done:     make(chan struct{}),
+	}
+	if sources.FailedBatches != nil {
+		e.lastFailed = sources.FailedBatches()
+	}
+	e.SetConfig(cfg)
+	return e
+}
+
+// SetConfig replaces the rules from the next evaluation on. Alerts of rules
+// it disables resolve then.
+func (e *AlertEngine) SetConfig(cfg AlertConfig) {
+	e.config.Store(&cfg)
+}
+
+// Start evaluates every configured interval
+func (e *AlertEngine) Start(ctx context.Context) {
+	ctx, e.cancel = context.WithCancel(ctx)
+	go func() {
+		defer close(e.done)
+		for {
+			timer := time.NewTimer(e.config.Load().interval())
+			select {
+			case now := <-timer.C:
+				e.Evaluate(ctx, now)
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			}
+		}
+	}()
+}
+
+func (e *AlertEngine) Stop() {
+	if e.cancel != nil {
+		e.cancel()
+		<-e.done
+	}
+}
+
+// Evaluate checks every rule once and notifies the alerts that started or
+// stopped firing. A rule whose source fails keeps its alerts as they were.
+func (e *AlertEngine) Evaluate(ctx context.Context, now time.Time) {
+	cfg := *e.config.Load()
+	current, evaluated := e.conditions(ctx, cfg)
+
+	e.mu.Lock()
+	var posts []*AlertPost
+	for key, alert := range current {
+		alert.EvaluatedAt = now
+		if prev, ok := e.firing[key]; ok {
+			alert.FiringSince = prev.FiringSince
+			e.firing[key] = alert
+			continue
+		}
+		alert.FiringSince = now
+		e.firing[key] = alert
+		posts = append(posts, e.post("alert_firing", alert, now))
+	}
+	for key, alert := range e.firing {
+		if _, ok := current[key]; ok || !evaluated[alert.Rule] {
+			continue
+		}
+		delete(e.firing, key)
+		resolved := *alert
+		resolved.EvaluatedAt, resolved.ResolvedAt = now, &now
+		posts = append(posts, e.post("alert_resolved", &resolved, now))
+	}
+	counts := make(map[string]int)
+	for _, alert := range e.firing {
+		counts[alert.Rule]++
+	}
+	e.evaluatedAt = &now
+	e.mu.Unlock()
+
+	for _, rule := range alertRuleNames {
+		alertsFiring.WithLabelValues(rule).Set(float64(counts[rule]))
+	}
+	for _, post := range posts {
+		e.send(ctx, cfg.WebhookURL, post)
+	}
+}
+
+// conditions returns the alerts whose condition holds now by rule and key,
+// and the rules that were evaluated; disabled rules count as evaluated so
+// their alerts resolve
+func (e *AlertEngine) conditions(ctx context.Context, cfg AlertConfig) (map[string]*models.Alert, map[string]bool) {
+	current := make(map[string]*models.Alert)
+	evaluated := map[string]bool{
+		AlertIngestLag:        true,
+		AlertQueueDepth:       true,
+		AlertServerOffline:    true,
+		AlertClickHouseErrors: true,
+	}
+	add := func(a *models.Alert) {
+		current[a.Rule+"/"+a.Key] = a
+	}
+
+	if (cfg.IngestLag > 0 || cfg.ServerOffline > 0) && e.sources.Ingest != nil {
+		threshold := cfg.IngestLag
+		if threshold <= 0 {
+			threshold = cfg.ServerOffline
+		}
+		health, err := e.sources.Ingest.GetIngestHealth(ctx, threshold)
+		if err != nil {
+			e.logger.Warnw("Alert rules could not read ingest health", "error", err)
+			evaluated[AlertIngestLag], evaluated[AlertServerOffline] = false, false
+		} else {
+			for _, srv := range health.Servers {
+				if cfg.IngestLag > 0 && srv.Stalled {
+					lag := time.Duration(srv.IngestLagSeconds) * time.Second
+					add(&models.Alert{
+						Rule: AlertIngestLag, Key: srv.ServerID, Subject: srv.Name,
+						Message: fmt.Sprintf("%s: no gameplay events for %s while heartbeating (threshold %s)",
+							sanitizeName(srv.Name), lag.Round(time.Second), cfg.IngestLag),
+						Value: srv.IngestLagSeconds, Threshold: cfg.IngestLag.Seconds(),
+					})
+				}
+				if cfg.ServerOffline > 0 && srv.LastHeartbeatAt != nil && srv.HeartbeatAgeSeconds > cfg.ServerOffline.Seconds() {
+					age := time.Duration(srv.HeartbeatAgeSeconds) * time.Second
+					add(&models.Alert{
+						Rule: AlertServerOffline, Key: srv.ServerID, Subject: srv.Name,
+						Message: fmt.Sprintf("%s: no heartbeat for %s (threshold %s)",
+							sanitizeName(srv.Name), age.Round(time.Second), cfg.ServerOffline),
+						Value: srv.HeartbeatAgeSeconds, Threshold: cfg.ServerOffline.Seconds(),
+					})
+				}
+			}
+		}
+	}
+
+	if cfg.QueueDepth > 0 && e.sources.QueueDepth != nil {
+		if depth := e.sources.QueueDepth(); depth >= cfg.QueueDepth {
+			add(&models.Alert{
+				Rule:    AlertQueueDepth,
+				Message: fmt.Sprintf("Worker queues hold %d events (threshold %d)", depth, cfg.QueueDepth),
+				Value:   float64(depth), Threshold: float64(cfg.QueueDepth),
+			})
+		}
+	}
+
+	if e.sources.FailedBatches != nil {
+		failed := e.sources.FailedBatches()
+		e.mu.Lock()
+		delta := failed - e.lastFailed
+		e.lastFailed = failed
+		e.mu.Unlock()
+		if cfg.ClickHouseErrors > 0 && delta >= uint64(cfg.ClickHouseErrors) {
+			add(&models.Alert{
+				Rule: AlertClickHouseErrors,
+				Message: fmt.Sprintf("%d batch inserts failed in the last %s (threshold %d)",
+					delta, cfg.interval(), cfg.ClickHouseErrors),
+				Value: float64(delta), Threshold: float64(cfg.ClickHouseErrors),
+			})
+		}
+	}
+	return current, evaluated
+}
+
+// post builds the webhook post of a transition, marking the alert silenced
+// when a silence matches it. Callers hold mu.
+func (e *AlertEngine) post(event string, alert *models.Alert, now time.Time) *AlertPost {
+	alert.Silenced, alert.SilenceID = false, ""
+	if s := e.silence(alert, now); s != nil {
+		alert.Silenced, alert.SilenceID = true, s.ID
+	}
+	content := alert.Message
+	if event == "alert_resolved" {
+		content = "Resolved: " + content
+	}
+	return &AlertPost{Event: event, Content: "[" + alert.Rule + "] " + content, Alert: *alert}
+}
+
+// silence returns the silence matching an alert, dropping expired ones.
+// Callers hold mu.
+func (e *AlertEngine) silence(alert *models.Alert, now time.Time) *models.AlertSilence {
+	var match *models.AlertSilence
+	for id, s := range e.silences {
+		if !now.Before(s.Until) {
+			delete(e.silences, id)
+			continue
+		}
+		if s.Rule == alert.Rule && (s.Key == "" || s.Key == alert.Key) {
+			match = s
+		}
+	}
+	return match
+}
+
+func (e *AlertEngine) send(ctx context.Context, url string, post *AlertPost) {
+	switch {
+	case post.Alert.Silenced:
+		alertNotifications.WithLabelValues("silenced").Inc()
+		e.logger.Infow("Alert silenced", "event", post.Event, "rule", post.Alert.Rule, "key", post.Alert.Key, "silence", post.Alert.SilenceID)
+		return
+	case url == "":
+		e.logger.Warnw("Alert", "event", post.Event, "rule", post.Alert.Rule, "key", post.Alert.Key, "message", post.Alert.Message)
+		return
+	}
+	if err := e.webhook.Send(ctx, url, post); err != nil {
+		alertNotifications.WithLabelValues("failed").Inc()
+		e.logger.Warnw("Alert webhook failed", "rule", post.Alert.Rule, "key", post.Alert.Key, "error", err)
+		return
+	}
+	alertNotifications.WithLabelValues("sent").Inc()
+}
+
+// State returns the configured rules, the firing alerts and the active
+// silences
+func (e *AlertEngine) State(now time.Time) *models.AlertState {
+	cfg := *e.config.Load()
+	state := &models.AlertState{
+		Rules: []models.AlertRule{
+			{Name: AlertIngestLag, Enabled: cfg.IngestLag > 0, Threshold: cfg.IngestLag.Seconds(), Unit: "seconds"},
+			{Name: AlertQueueDepth, Enabled: cfg.QueueDepth > 0, Threshold: float64(cfg.QueueDepth), Unit: "events"},
+			{Name: AlertServerOffline, Enabled: cfg.ServerOffline > 0, Threshold: cfg.ServerOffline.Seconds(), Unit: "seconds"},
+			{Name: AlertClickHouseErrors, Enabled: cfg.ClickHouseErrors > 0, Threshold: float64(cfg.ClickHouseErrors), Unit: "failed batches"},
+		},
+		Firing:   []models.Alert{},
+		Silences: []models.AlertSilence{},
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	state.EvaluatedAt = e.evaluatedAt
+	for _, alert := range e.firing {
+		a := *alert
+		a.Silenced, a.SilenceID = false, ""
+		if s := e.silence(&a, now); s != nil {
+			a.Silenced, a.SilenceID = true, s.ID
+		}
+		state.Firing = append(state.Firing, a)
+	}
+	for _, s := range e.silences {
+		state.Silences = append(state.Silences, *s)
+	}
+	sort.Slice(state.Firing, func(i, j int) bool {
+		if state.Firing[i].Rule != state.Firing[j].Rule {
+			return state.Firing[i].Rule < state.Firing[j].Rule
+		}
+		return state.Firing[i].Key < state.Firing[j].Key
+	})
+	sort.Slice(state.Silences, func(i, j int) bool {
+		return state.Silences[i].Until.Before(state.Silences[j].Until)
+	})
+	return state
+}
+
+// Silence mutes rule (for key, or all keys when empty) for duration
+func (e *AlertEngine) Silence(rule, key, reason string, duration time.Duration, now time.Time) (*models.AlertSilence, error) {
+	known := false
+	for _, name := range alertRuleNames {
+		known = known || name == rule
+	}
+	if !known {
+		return nil, ErrUnknownAlertRule
+	}
+	s := &models.AlertSilence{
+		ID:        uuid.NewString(),
+		Rule:      rule,
+		Key:       key,
+		Reason:    reason,
+		Until:     now.Add(duration),
+		CreatedAt: now,
+	}
+	e.mu.Lock()
+	e.silences[s.ID] = s
+	e.mu.Unlock()
+	return s, nil
+}
+
+// Unsilence lifts a silence before it expires
+func (e *AlertEngine) Unsilence(id string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.silences[id]; !ok {
+		return ErrSilenceNotFound
+	}
+	delete(e.silences, id)
+	return nil
+}
@@ -0,0 +1,133 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+type fakeIngestHealth struct {
+	health *models.IngestHealth
+	err    error
+}
+
+func (f *fakeIngestHealth) GetIngestHealth(ctx context.Context, threshold time.Duration) (*models.IngestHealth, error) {
+	return f.health, f.err
+}
+
+func TestAlertEngine(t *testing.T) {
+	var mu sync.Mutex
+	var posts []AlertPost
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p AlertPost
+		json.NewDecoder(r.Body).Decode(&p)
+		mu.Lock()
+		posts = append(posts, p)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+	received := func() []AlertPost {
+		mu.Lock()
+		defer mu.Unlock()
+		out := posts
+		posts = nil
+		return out
+	}
+
+	beat := time.Now()
+	ingest := &fakeIngestHealth{health: &models.IngestHealth{Servers: []models.ServerIngestLag{
+		{ServerID: "s1", Name: "^1Omaha", Stalled: true, IngestLagSeconds: 1200, LastHeartbeatAt: &beat, HeartbeatAgeSeconds: 30},
+		{ServerID: "s2", Name: "Dust", LastHeartbeatAt: &beat, HeartbeatAgeSeconds: 3600},
+	}}}
+	depth := 10
+	var failed uint64
+	e := NewAlertEngine(AlertConfig{
+		WebhookURL:       srv.URL,
+		IngestLag:        10 * time.Minute,
+		QueueDepth:       100,
+		ServerOffline:    30 * time.Minute,
+		ClickHouseErrors: 3,
+	}, AlertSources{
+		Ingest:        ingest,
+		QueueDepth:    func() int { return depth },
+		FailedBatches: func() uint64 { return failed },
+	}, zap.NewNop())
+	ctx := context.Background()
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	failed = 3
+	e.Evaluate(ctx, now)
+	got := received()
+	if len(got) != 3 {
+		t.Fatalf("posts = %+v, want ingest lag, offline and ClickHouse errors", got)
+	}
+	state := e.State(now)
+	if len(state.Firing) != 3 || state.Firing[0].Rule != AlertClickHouseErrors || state.Firing[1].Key != "s1" {
+		t.Fatalf("firing = %+v", state.Firing)
+	}
+	if want := "Omaha: no gameplay events for 20m0s while heartbeating (threshold 10m0s)"; state.Firing[1].Message != want {
+		t.Errorf("message = %q, want %q", state.Firing[1].Message, want)
+	}
+
+	// Still firing: no new posts, and FiringSince is kept. Errors stopped,
+	// so that alert resolves.
+	later := now.Add(time.Minute)
+	e.Evaluate(ctx, later)
+	got = received()
+	if len(got) != 1 || got[0].Event != "alert_resolved" || got[0].Alert.Rule != AlertClickHouseErrors {
+		t.Fatalf("posts = %+v, want the ClickHouse alert resolved", got)
+	}
+	if f := e.State(later).Firing; len(f) != 2 || !f[0].FiringSince.Equal(now) {
+		t.Errorf("firing = %+v", f)
+	}
+
+	// A failing source keeps its alerts
+	ingest.err = errors.New("clickhouse down")
+	e.Evaluate(ctx, later.Add(time.Minute))
+	if got := received(); len(got) != 0 || len(e.State(later).Firing) != 2 {
+		t.Errorf("source error changed alerts: posts %+v", got)
+	}
+	ingest.err = nil
+
+	// Silenced alerts fire without posting
+	s, err := e.Silence(AlertQueueDepth, "", "load test", time.Hour, later)
+	if err != nil {
+		t.Fatal(err)
+	}
+	depth = 500
+	e.Evaluate(ctx, later.Add(2*time.Minute))
+	if got := received(); len(got) != 0 {
+		t.Errorf("silenced alert posted: %+v", got)
+	}
+	state = e.State(later.Add(2 * time.Minute))
+	if len(state.Silences) != 1 || state.Firing[1].Rule != AlertQueueDepth || state.Firing[1].SilenceID != s.ID {
+		t.Errorf("state = %+v", state)
+	}
+
+	if err := e.Unsilence(s.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Unsilence(s.ID); !errors.Is(err, ErrSilenceNotFound) {
+		t.Errorf("second unsilence: %v", err)
+	}
+	if _, err := e.Silence("cpu", "", "", time.Hour, later); !errors.Is(err, ErrUnknownAlertRule) {
+		t.Errorf("unknown rule: %v", err)
+	}
+
+	// Disabling a rule resolves its alerts
+	e.SetConfig(AlertConfig{WebhookURL: srv.URL, IngestLag: 10 * time.Minute, ServerOffline: 30 * time.Minute})
+	e.Evaluate(ctx, later.Add(3*time.Minute))
+	if got := received(); len(got) != 1 || got[0].Alert.Rule != AlertQueueDepth || !strings.HasPrefix(got[0].Content, "[queue_depth] Resolved: ") {
+		t.Errorf("posts = %+v, want the queue alert resolved", got)
+	}
+}
@@ -0,0 +1,294 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/db"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+var announcementsQueued = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "mohaa_announcements_total",
+	Help: "In-game announcements queued for game servers by type",
+}, []string{"type"})
+
+// announceStreakMin is the shortest kill streak checked against the server record
+const announceStreakMin = 5
+
+// killRanks are the ranks announced when a player's kill counter
+// (player:<guid>:kills) reaches the threshold. The counter expires with
+// RedisTTLConfig.PlayerCounters, so a player back from a long break climbs
+// the ranks again.
+var killRanks = map[int64]string{
+	100:   "Corporal",
+	250:   "Sergeant",
+	500:   "Staff Sergeant",
+	1000:  "Lieutenant",
+	2500:  "Captain",
+	5000:  "Major",
+	10000: "Colonel",
+	25000: "General",
+}
+
+// Announcer queues announcements for the game server running a match, which
+// polls them from /integrations/announce to print in chat. Each match has a
+// hash announce:<match_id> of JSON announcements keyed by an increasing ID;
+// polls acknowledge what they have seen, and whatever is left expires with the
+// match keys.
+//
+// It also tracks kill streaks per match from the event stream and announces a
+// streak that beats the server's best (server:<id>:streak_record).
+type Announcer struct {
+	store  db.LiveStateStore
+	ttl    atomic.Int64 // time.Duration
+	inbox  *Inbox       // tells the record holder; nil disables
+	logger *zap.SugaredLogger
+
+	mu      sync.Mutex
+	matches *matchTracker[*matchStreaks]
+}
+
+type matchStreaks struct {
+	streaks map[string]int
+	best    int // longest streak already checked against the record
+}
+
+// streakCandidate is a streak to check against the server record
+type streakCandidate struct {
+	event  *models.RawEvent
+	streak int
+}
+
+func NewAnnouncer(store db.LiveStateStore, ttl time.Duration, logger *zap.Logger) *Announcer {
+	a := &Announcer{
+		store:   store,
+		logger:  logger.Sugar(),
+		matches: newMatchTracker[*matchStreaks](),
+	}
+	a.SetTTL(ttl)
+	return a
+}
+
+// SetTTL sets how long unacknowledged announcements are kept
+func (a *Announcer) SetTTL(ttl time.Duration) {
+	if ttl == 0 {
+		ttl = 12 * time.Hour
+	}
+	a.ttl.Store(int64(ttl))
+}
+
+// SetInbox sends broken records to the record holder's notifications. Call
+// it before events are observed.
+func (a *Announcer) SetInbox(inbox *Inbox) {
+	a.inbox = inbox
+}
+
+func announceKey(matchID string) string {
+	return "announce:" + matchID
+}
+
+// Push queues an announcement for its match. ID and CreatedAt are assigned here.
+func (a *Announcer) Push(ctx context.Context, ann models.Announcement) error {
+	if a == nil || ann.MatchID == "" {
+		return nil
+	}
+	key := announceKey(ann.MatchID)
+	id, err := a.store.Incr(ctx, key+":seq")
+	if err != nil {
+		return fmt.Errorf("failed to number announcement: %w", err)
+	}
+	ann.ID = id
+	if ann.CreatedAt.IsZero() {
+		ann.CreatedAt = time.Now().UTC()
+	}
+	data, err := json.Marshal(ann)
+	if err != nil {
+		return err
+	}
+	if err := a.store.HSet(ctx, key, strconv.FormatInt(id, 10), string(data)); err != nil {
+		return fmt.Errorf("failed to queue announcement: %w", err)
+	}
+	if ttl := time.Duration(a.ttl.Load()); ttl > 0 {
+		a.store.Expire(ctx, key, ttl)
+		a.store.Expire(ctx, key+":seq", ttl)
+	}
+	announcementsQueued.WithLabelValues(ann.Type).Inc()
+	return nil
+}
+
+// Fetch returns the announcements of a match with an ID above after, oldest
+// first, and deletes those at or below it: a server that polls with the last
+// cursor it received has printed them. Announcements of other servers are
+// never returned.
+func (a *Announcer) Fetch(ctx context.Context, matchID, serverID string, after int64) (*models.AnnouncementBatch, error) {
+	batch := &models.AnnouncementBatch{MatchID: matchID, Announcements: []models.Announcement{}, Cursor: after}
+	key := announceKey(matchID)
+	all, err := a.store.HGetAll(ctx, key)
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		return nil, err
+	}
+
+	var acked []string
+	for field, data := range all {
+		var ann models.Announcement
+		if err := json.Unmarshal([]byte(data), &ann); err != nil || ann.ServerID != serverID {
+			continue
+		}
+		if ann.ID <= after {
+			acked = append(acked, field)
+			continue
+		}
+		batch.Announcements = append(batch.Announcements, ann)
+	}
+	if len(acked) > 0 {
+		if err := a.store.HDel(ctx, key, acked...); err != nil {
+			a.logger.Warnw("Failed to delete acknowledged announcements", "match", matchID, "error", err)
+		}
+	}
+
+	sort.Slice(batch.Announcements, func(i, j int) bool {
+		return batch.Announcements[i].ID < batch.Announcements[j].ID
+	})
+	if n := len(batch.Announcements); n > 0 {
+		batch.Cursor = batch.Announcements[n-1].ID
+	}
+	return batch, nil
+}
+
+// Observe follows kill streaks and checks a new match-best streak against the
+// server record in the background
+func (a *Announcer) Observe(event *models.RawEvent, now time.Time) {
+	if c := a.observe(event, now); c != nil {
+		go a.checkRecord(c)
+	}
+}
+
+// observe returns a streak to check against the server record, if any
+func (a *Announcer) observe(event *models.RawEvent, now time.Time) *streakCandidate {
+	if a == nil || event.MatchID == "" {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.matches.sweep(now)
+
+	switch event.Type {
+	case models.EventMatchEnd:
+		a.matches.end(event.MatchID)
+		return nil
+	case models.EventPlayerKill, models.EventPlayerTeamkill, models.EventPlayerBash, models.EventPlayerRoadkill,
+		models.EventPlayerCrushed, models.EventPlayerTelefragged, models.EventPlayerSuicide, models.EventDeath:
+	default:
+		return nil
+	}
+
+	m := a.matches.get(event.MatchID, now, func() *matchStreaks {
+		return &matchStreaks{streaks: make(map[string]int), best: announceStreakMin - 1}
+	})
+
+	delete(m.streaks, event.VictimGUID)
+	if event.Type == models.EventPlayerSuicide {
+		delete(m.streaks, event.AttackerGUID)
+		delete(m.streaks, event.PlayerGUID)
+		return nil
+	}
+	if event.Type != models.EventPlayerKill || event.AttackerGUID == "" || event.AttackerGUID == "world" ||
+		event.AttackerGUID == event.VictimGUID {
+		return nil
+	}
+
+	m.streaks[event.AttackerGUID]++
+	streak := m.streaks[event.AttackerGUID]
+	if streak <= m.best {
+		return nil
+	}
+	m.best = streak
+	return &streakCandidate{event: event, streak: streak}
+}
+
+// checkRecord stores and announces a streak longer than the server record
+func (a *Announcer) checkRecord(c *streakCandidate) {
+	event := c.event
+	if event.ServerID == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	key := "server:" + event.ServerID + ":streak_record"
+	record := 0
+	if val, err := a.store.Get(ctx, key); err == nil {
+		record, _ = strconv.Atoi(val)
+	} else if !errors.Is(err, db.ErrNotFound) {
+		a.logger.Warnw("Failed to read streak record", "server", event.ServerID, "error", err)
+		return
+	}
+	if c.streak <= record {
+		return
+	}
+	if err := a.store.Set(ctx, key, c.streak, 0); err != nil {
+		a.logger.Warnw("Failed to store streak record", "server", event.ServerID, "error", err)
+		return
+	}
+
+	name := sanitizeName(event.AttackerName)
+	err := a.Push(ctx, models.Announcement{
+		Type:        models.AnnouncementRecord,
+		MatchID:     event.MatchID,
+		ServerID:    event.ServerID,
+		PlayerGUID:  event.AttackerGUID,
+		PlayerSMFID: event.AttackerSMFID,
+		PlayerName:  name,
+		Title:       "Server kill streak record",
+		Value:       int64(c.streak),
+		Message:     fmt.Sprintf("%s set a new server record: %d kills in a row!", name, c.streak),
+	})
+	if err != nil {
+		a.logger.Warnw("Failed to queue record announcement", "match", event.MatchID, "error", err)
+	}
+	a.inbox.Push(models.Notification{
+		PlayerGUID: event.AttackerGUID,
+		Kind:       models.NotifyRecord,
+		Title:      fmt.Sprintf("New server record: %d kills in a row", c.streak),
+		Body:       fmt.Sprintf("The previous record on this server was %d.", record),
+		Subject:    "streak",
+		Value:      int64(c.streak),
+		MatchID:    event.MatchID,
+	})
+}
+
+// announceRankUp queues a rank-up when a kill counter reaches a rank threshold
+func (a *Announcer) announceRankUp(ctx context.Context, event *models.RawEvent, kills int64) {
+	rank, ok := killRanks[kills]
+	if a == nil || !ok {
+		return
+	}
+	name := sanitizeName(event.AttackerName)
+	err := a.Push(ctx, models.Announcement{
+		Type:        models.AnnouncementRankUp,
+		MatchID:     event.MatchID,
+		ServerID:    event.ServerID,
+		PlayerGUID:  event.AttackerGUID,
+		PlayerSMFID: event.AttackerSMFID,
+		PlayerName:  name,
+		Title:       rank,
+		Value:       kills,
+		Message:     fmt.Sprintf("%s has been promoted to %s (%d kills)", name, rank, kills),
+	})
+	if err != nil {
+		a.logger.Warnw("Failed to queue rank-up announcement", "match", event.MatchID, "error", err)
+	}
+}
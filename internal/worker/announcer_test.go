@@ -0,0 +1,118 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/db"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestAnnouncerQueue(t *testing.T) {
+	ctx := context.Background()
+	a := NewAnnouncer(db.NewMemoryLiveState(), time.Hour, zap.NewNop())
+
+	for _, ann := range []models.Announcement{
+		{Type: models.AnnouncementRankUp, MatchID: "m1", ServerID: "srv", Title: "Corporal"},
+		{Type: models.AnnouncementRankUp, MatchID: "m1", ServerID: "other", Title: "Sergeant"},
+		{Type: models.AnnouncementAchievement, MatchID: "m1", ServerID: "srv", Title: "killer_bronze"},
+	} {
+		if err := a.Push(ctx, ann); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	batch, err := a.Fetch(ctx, "m1", "srv", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batch.Announcements) != 2 || batch.Announcements[0].ID != 1 || batch.Announcements[1].ID != 3 {
+		t.Fatalf("announcements = %+v, want IDs 1 and 3 for srv", batch.Announcements)
+	}
+	if batch.Cursor != 3 {
+		t.Errorf("cursor = %d, want 3", batch.Cursor)
+	}
+
+	// Polling with the cursor acknowledges and deletes them
+	batch, err = a.Fetch(ctx, "m1", "srv", batch.Cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batch.Announcements) != 0 || batch.Cursor != 3 {
+		t.Errorf("after ack: %+v", batch)
+	}
+	if again, _ := a.Fetch(ctx, "m1", "srv", 0); len(again.Announcements) != 0 {
+		t.Errorf("acknowledged announcements returned again: %+v", again.Announcements)
+	}
+	if other, _ := a.Fetch(ctx, "m1", "other", 0); len(other.Announcements) != 1 {
+		t.Errorf("other server's announcement = %+v, want kept", other.Announcements)
+	}
+
+	if empty, err := a.Fetch(ctx, "none", "srv", 0); err != nil || len(empty.Announcements) != 0 {
+		t.Errorf("unknown match = %+v, %v", empty, err)
+	}
+}
+
+func TestAnnouncerStreaks(t *testing.T) {
+	a := NewAnnouncer(db.NewMemoryLiveState(), time.Hour, zap.NewNop())
+	now := time.Now()
+	kill := func(attacker, victim string) *streakCandidate {
+		return a.observe(&models.RawEvent{Type: models.EventPlayerKill, MatchID: "m1", ServerID: "srv", AttackerGUID: attacker, VictimGUID: victim}, now)
+	}
+
+	for i := 1; i < announceStreakMin; i++ {
+		if c := kill("p1", "p2"); c != nil {
+			t.Fatalf("candidate at streak %d", i)
+		}
+	}
+	c := kill("p1", "p2")
+	if c == nil || c.streak != announceStreakMin {
+		t.Fatalf("candidate = %+v, want streak %d", c, announceStreakMin)
+	}
+
+	// Dying resets the streak; matching the match best is not a candidate
+	kill("p2", "p1")
+	for i := 1; i <= announceStreakMin; i++ {
+		if c := kill("p1", "p3"); c != nil {
+			t.Fatalf("candidate at streak %d, not above the match best", i)
+		}
+	}
+	if c := kill("p1", "p3"); c == nil || c.streak != announceStreakMin+1 {
+		t.Errorf("candidate = %+v, want streak %d", c, announceStreakMin+1)
+	}
+
+	var nilAnnouncer *Announcer
+	nilAnnouncer.Observe(&models.RawEvent{Type: models.EventPlayerKill, MatchID: "m1"}, now)
+}
+
+func TestAnnouncerRecord(t *testing.T) {
+	ctx := context.Background()
+	store := db.NewMemoryLiveState()
+	a := NewAnnouncer(store, time.Hour, zap.NewNop())
+	event := &models.RawEvent{Type: models.EventPlayerKill, MatchID: "m1", ServerID: "srv", AttackerGUID: "p1", AttackerName: "Ace"}
+
+	store.Set(ctx, "server:srv:streak_record", 6, 0)
+	a.checkRecord(&streakCandidate{event: event, streak: 6})
+	if batch, _ := a.Fetch(ctx, "m1", "srv", 0); len(batch.Announcements) != 0 {
+		t.Fatalf("announced a streak equal to the record: %+v", batch.Announcements)
+	}
+
+	a.checkRecord(&streakCandidate{event: event, streak: 7})
+	batch, _ := a.Fetch(ctx, "m1", "srv", 0)
+	if len(batch.Announcements) != 1 || batch.Announcements[0].Type != models.AnnouncementRecord || batch.Announcements[0].Value != 7 {
+		t.Fatalf("record announcements = %+v", batch.Announcements)
+	}
+	if val, _ := store.Get(ctx, "server:srv:streak_record"); val != "7" {
+		t.Errorf("stored record = %q, want 7", val)
+	}
+
+	a.announceRankUp(ctx, event, 99)
+	a.announceRankUp(ctx, event, 100)
+	batch, _ = a.Fetch(ctx, "m1", "srv", batch.Cursor)
+	if len(batch.Announcements) != 1 || batch.Announcements[0].Title != "Corporal" {
+		t.Errorf("rank-ups = %+v, want one Corporal", batch.Announcements)
+	}
+}
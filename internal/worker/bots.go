@@ -0,0 +1,54 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/openmohaa/stats-api/internal/db"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// botGUIDsKey is the set of GUIDs seen on events with a bot_id. Bots stay out
+// of live rosters, player_names, match outcomes and claims; their events
+// (bot_killed included) are still stored for PvE stats.
+const botGUIDsKey = "bot_guids"
+
+// isBotEvent reports whether the event's player is a bot
+func isBotEvent(event *models.RawEvent) bool {
+	return event.BotID != ""
+}
+
+// tagBot adds the event's player to the bot set when the event says it is one,
+// and reports whether it did
+func (p *Pool) tagBot(ctx context.Context, w db.LiveStateWriter, event *models.RawEvent) bool {
+	if !isBotEvent(event) {
+		return false
+	}
+	if event.PlayerGUID != "" {
+		w.SAdd(ctx, botGUIDsKey, event.PlayerGUID)
+		expireKey(ctx, w, botGUIDsKey, p.ttl().PlayerCounters)
+	}
+	return true
+}
+
+// isBot reports whether the event's player is a bot, by the event itself or
+// an earlier one that tagged its GUID
+func (p *Pool) isBot(ctx context.Context, event *models.RawEvent) bool {
+	if isBotEvent(event) {
+		return true
+	}
+	if event.PlayerGUID == "" {
+		return false
+	}
+	bot, _ := p.config.LiveState.SIsMember(ctx, botGUIDsKey, event.PlayerGUID)
+	return bot
+}
+
+// knownBots returns the tagged bot GUIDs
+func (p *Pool) knownBots(ctx context.Context) map[string]bool {
+	members, _ := p.config.LiveState.SMembers(ctx, botGUIDsKey)
+	bots := make(map[string]bool, len(members))
+	for _, guid := range members {
+		bots[guid] = true
+	}
+	return bots
+}
@@ -0,0 +1,47 @@
+package worker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openmohaa/stats-api/internal/db"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestBotsStayOutOfLiveState(t *testing.T) {
+	ctx := context.Background()
+	store := db.NewMemoryLiveState()
+	p := &Pool{config: PoolConfig{LiveState: store, RedisTTL: RedisTTLConfig{}.withDefaults()}}
+
+	p.handleConnect(ctx, &models.RawEvent{Type: models.EventConnect, MatchID: "m1", PlayerGUID: "bot1", PlayerName: "Bot", BotID: "3"})
+	p.handleTeamChange(ctx, &models.RawEvent{Type: models.EventTeamJoin, MatchID: "m1", PlayerGUID: "bot1", NewTeam: "axis", BotID: "3"})
+	p.handleConnect(ctx, &models.RawEvent{Type: models.EventConnect, MatchID: "m1", PlayerGUID: "human", PlayerName: "Alice"})
+
+	players, _ := store.SMembers(ctx, "match:m1:players")
+	if len(players) != 1 || players[0] != "human" {
+		t.Errorf("players = %v, want [human]", players)
+	}
+	if _, err := store.HGet(ctx, "player_names", "bot1"); err == nil {
+		t.Error("bot name indexed")
+	}
+	if _, err := store.HGet(ctx, "match:m1:teams", "bot1"); err == nil {
+		t.Error("bot team recorded")
+	}
+
+	// Later events without a bot_id are still recognized by GUID
+	if !p.isBot(ctx, &models.RawEvent{PlayerGUID: "bot1"}) {
+		t.Error("tagged bot not recognized")
+	}
+	if p.isBot(ctx, &models.RawEvent{PlayerGUID: "human"}) {
+		t.Error("human recognized as bot")
+	}
+
+	p.updateScoreboard(ctx, &models.RawEvent{Type: models.EventHeartbeat, MatchID: "m1", Roster: []models.RosterEntry{
+		{GUID: "human", Name: "Alice", Team: "allies"},
+		{GUID: "bot1", Name: "Bot", Team: "axis"},
+	}})
+	board, _ := store.HGetAll(ctx, scoreboardKey("m1"))
+	if len(board) != 1 || board["human"] == "" {
+		t.Errorf("scoreboard = %v, want only the human", board)
+	}
+}
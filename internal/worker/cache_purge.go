@@ -0,0 +1,257 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+var cachePurgesSent = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "mohaa_cache_purges_total",
+	Help: "Cache purge notifications by result (sent, failed)",
+}, []string{"result"})
+
+// cachePurgeTimeout bounds one purge delivery
+const cachePurgeTimeout = 10 * time.Second
+
+// Purge tags. Frontends map them to their own cache keys or CDN surrogate keys.
+const (
+	PurgeTagLeaderboards = "leaderboards"
+	purgeTagPlayerPrefix = "player:"
+)
+
+// CachePurgeConfig lists the URLs notified when cached pages go stale. Changes
+// are collected for Delay before being sent, so a match's events are stored
+// and several matches ending together cost one notification. No URLs
+// disables purging.
+type CachePurgeConfig struct {
+	URLs  []string
+	Delay time.Duration
+}
+
+// ParsePurgeURLs splits a comma-separated URL list
+func ParsePurgeURLs(s string) []string {
+	var urls []string
+	for _, u := range strings.Split(s, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// CachePurge is the JSON posted to each purge URL
+type CachePurge struct {
+	Event     string    `json:"event"` // always "cache_purge"
+	Reasons   []string  `json:"reasons"`
+	Tags      []string  `json:"tags"`    // "leaderboards", "player:<guid>"
+	Players   []string  `json:"players"` // GUIDs whose profiles changed
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// CachePurger tells the SMF frontend (or a CDN) which cached pages changed:
+// player profiles when a match they fought in ends, and the leaderboards with
+// them or when the leaderboard snapshot is rebuilt. Players who joined a match
+// without a kill or death are left alone.
+type CachePurger struct {
+	config atomic.Pointer[CachePurgeConfig]
+	client *http.Client
+	logger *zap.SugaredLogger
+
+	mu      sync.Mutex
+	matches *matchTracker[*matchFighters]
+	pending map[string]bool // tags
+	reasons map[string]bool
+	since   time.Time // oldest pending change
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+type matchFighters struct {
+	players map[string]bool
+}
+
+func NewCachePurger(cfg CachePurgeConfig, logger *zap.Logger) *CachePurger {
+	p := &CachePurger{
+		client:  &http.Client{Timeout: cachePurgeTimeout},
+		logger:  logger.Sugar(),
+		matches: newMatchTracker[*matchFighters](),
+		pending: make(map[string]bool),
+		reasons: make(map[string]bool),
+		done:    make(chan struct{}),
+	}
+	p.SetConfig(cfg)
+	return p
+}
+
+// SetConfig replaces the URLs and delay; pending changes are kept
+func (p *CachePurger) SetConfig(cfg CachePurgeConfig) {
+	if cfg.Delay <= 0 {
+		cfg.Delay = 30 * time.Second
+	}
+	p.config.Store(&cfg)
+}
+
+// Observe notes the players fighting in a match and marks their profiles
+// stale when it ends
+func (p *CachePurger) Observe(event *models.RawEvent, now time.Time) {
+	if p == nil || event.MatchID == "" || len(p.config.Load().URLs) == 0 {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.matches.sweep(now)
+
+	switch event.Type {
+	case models.EventMatchEnd:
+		m, ok := p.matches.end(event.MatchID)
+		if !ok || len(m.players) == 0 {
+			return
+		}
+		players := make([]string, 0, len(m.players))
+		for guid := range m.players {
+			players = append(players, guid)
+		}
+		p.mark(now, "match_end", players, true)
+
+	case models.EventPlayerKill, models.EventBotKilled, models.EventPlayerTeamkill, models.EventPlayerSuicide:
+		m := p.matches.get(event.MatchID, now, func() *matchFighters {
+			return &matchFighters{players: make(map[string]bool)}
+		})
+		for _, guid := range []string{event.AttackerGUID, event.VictimGUID, event.PlayerGUID} {
+			if guid != "" && guid != "world" {
+				m.players[guid] = true
+			}
+		}
+	}
+}
+
+// Invalidate marks profiles (and optionally the leaderboards) stale outside
+// the event stream, e.g. after an aggregate rebuild or a voided match
+func (p *CachePurger) Invalidate(reason string, players []string, leaderboards bool) {
+	if p == nil || len(p.config.Load().URLs) == 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.mark(time.Now(), reason, players, leaderboards)
+}
+
+// mark adds tags to the pending purge; the caller holds mu
+func (p *CachePurger) mark(now time.Time, reason string, players []string, leaderboards bool) {
+	if len(p.pending) == 0 {
+		p.since = now
+	}
+	p.reasons[reason] = true
+	if leaderboards {
+		p.pending[PurgeTagLeaderboards] = true
+	}
+	for _, guid := range players {
+		p.pending[purgeTagPlayerPrefix+guid] = true
+	}
+}
+
+// take returns the pending purge once its oldest change is Delay old
+func (p *CachePurger) take(now time.Time) *CachePurge {
+	delay := p.config.Load().Delay
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.pending) == 0 || now.Sub(p.since) < delay {
+		return nil
+	}
+
+	purge := &CachePurge{Event: "cache_purge", Timestamp: now.UTC(), Players: []string{}}
+	for tag := range p.pending {
+		purge.Tags = append(purge.Tags, tag)
+		if guid, ok := strings.CutPrefix(tag, purgeTagPlayerPrefix); ok {
+			purge.Players = append(purge.Players, guid)
+		}
+	}
+	for reason := range p.reasons {
+		purge.Reasons = append(purge.Reasons, reason)
+	}
+	sort.Strings(purge.Tags)
+	sort.Strings(purge.Players)
+	sort.Strings(purge.Reasons)
+
+	p.pending = make(map[string]bool)
+	p.reasons = make(map[string]bool)
+	return purge
+}
+
+// Start sends due purges in the background until Stop
+func (p *CachePurger) Start(ctx context.Context) {
+	ctx, p.cancel = context.WithCancel(ctx)
+	go func() {
+		defer close(p.done)
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case now := <-ticker.C:
+				if purge := p.take(now); purge != nil {
+					p.send(ctx, purge)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (p *CachePurger) Stop() {
+	if p.cancel != nil {
+		p.cancel()
+		<-p.done
+	}
+}
+
+func (p *CachePurger) send(ctx context.Context, purge *CachePurge) {
+	body, err := json.Marshal(purge)
+	if err != nil {
+		return
+	}
+	for _, url := range p.config.Load().URLs {
+		reqCtx, cancel := context.WithTimeout(ctx, cachePurgeTimeout)
+		req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			cancel()
+			cachePurgesSent.WithLabelValues("failed").Inc()
+			p.logger.Warnw("Invalid cache purge URL", "url", url, "error", err)
+			continue
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := p.client.Do(req)
+		cancel()
+		if err != nil {
+			cachePurgesSent.WithLabelValues("failed").Inc()
+			p.logger.Warnw("Cache purge failed", "url", url, "error", err)
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			cachePurgesSent.WithLabelValues("failed").Inc()
+			p.logger.Warnw("Cache purge rejected", "url", url, "status", resp.StatusCode)
+			continue
+		}
+		cachePurgesSent.WithLabelValues("sent").Inc()
+	}
+	p.logger.Debugw("Cache purge sent", "tags", len(purge.Tags), "reasons", purge.Reasons)
+}
@@ -0,0 +1,91 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestCachePurgerMatchEnd(t *testing.T) {
+	p := NewCachePurger(CachePurgeConfig{URLs: []string{"http://purge"}, Delay: time.Minute}, zap.NewNop())
+	now := time.Now()
+
+	p.Observe(&models.RawEvent{Type: models.EventConnect, MatchID: "m1", PlayerGUID: "idle"}, now)
+	p.Observe(&models.RawEvent{Type: models.EventPlayerKill, MatchID: "m1", AttackerGUID: "a", VictimGUID: "b"}, now)
+	p.Observe(&models.RawEvent{Type: models.EventPlayerSuicide, MatchID: "m1", PlayerGUID: "c", AttackerGUID: "world"}, now)
+	if p.take(now.Add(time.Hour)) != nil {
+		t.Fatal("purge before match_end")
+	}
+
+	p.Observe(&models.RawEvent{Type: models.EventMatchEnd, MatchID: "m1"}, now)
+	if p.take(now.Add(30*time.Second)) != nil {
+		t.Fatal("purge sent before the delay")
+	}
+	purge := p.take(now.Add(time.Minute))
+	if purge == nil {
+		t.Fatal("no purge after the delay")
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(purge.Players, want) {
+		t.Errorf("players = %v, want %v", purge.Players, want)
+	}
+	if want := []string{"leaderboards", "player:a", "player:b", "player:c"}; !reflect.DeepEqual(purge.Tags, want) {
+		t.Errorf("tags = %v, want %v", purge.Tags, want)
+	}
+	if p.take(now.Add(2*time.Minute)) != nil {
+		t.Error("purge sent twice")
+	}
+
+	// A match without fights changes no profile
+	p.Observe(&models.RawEvent{Type: models.EventConnect, MatchID: "m2", PlayerGUID: "idle"}, now)
+	p.Observe(&models.RawEvent{Type: models.EventMatchEnd, MatchID: "m2"}, now)
+	if p.take(now.Add(time.Hour)) != nil {
+		t.Error("purge for a match without kills")
+	}
+
+	var nilPurger *CachePurger
+	nilPurger.Observe(&models.RawEvent{Type: models.EventMatchEnd, MatchID: "m1"}, now)
+	nilPurger.Invalidate("test", nil, true)
+}
+
+func TestCachePurgerDisabled(t *testing.T) {
+	p := NewCachePurger(CachePurgeConfig{}, zap.NewNop())
+	p.Observe(&models.RawEvent{Type: models.EventPlayerKill, MatchID: "m1", AttackerGUID: "a"}, time.Now())
+	p.Observe(&models.RawEvent{Type: models.EventMatchEnd, MatchID: "m1"}, time.Now())
+	p.Invalidate("leaderboard_refresh", nil, true)
+	if p.take(time.Now().Add(time.Hour)) != nil {
+		t.Error("purge without URLs")
+	}
+}
+
+func TestCachePurgerSend(t *testing.T) {
+	received := make(chan CachePurge, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var purge CachePurge
+		if err := json.NewDecoder(r.Body).Decode(&purge); err != nil {
+			t.Errorf("decode purge: %v", err)
+		}
+		received <- purge
+	}))
+	defer srv.Close()
+
+	p := NewCachePurger(CachePurgeConfig{URLs: []string{srv.URL}, Delay: time.Millisecond}, zap.NewNop())
+	p.Invalidate("leaderboard_refresh", []string{"p1"}, true)
+	purge := p.take(time.Now().Add(time.Second))
+	if purge == nil {
+		t.Fatal("no purge")
+	}
+	p.send(context.Background(), purge)
+
+	got := <-received
+	if got.Event != "cache_purge" || !reflect.DeepEqual(got.Reasons, []string{"leaderboard_refresh"}) || !reflect.DeepEqual(got.Players, []string{"p1"}) {
+		t.Errorf("received %+v", got)
+	}
+}
@@ -0,0 +1,353 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/db"
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+var challengesCompleted = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "mohaa_challenges_completed_total",
+	Help: "Weekly challenge completions by webhook result (stored, sent, failed)",
+}, []string{"result"})
+
+const (
+	// challengeRefreshInterval is how often the week's challenges are reloaded,
+	// so a new week or an edited challenge is picked up within a minute
+	challengeRefreshInterval = time.Minute
+	// challengeKeyTTL keeps progress counters a day past the end of their week
+	challengeKeyTTL = 8 * 24 * time.Hour
+	// challengeWebhookTimeout bounds one completion post
+	challengeWebhookTimeout = 10 * time.Second
+)
+
+// ChallengeCompletedPost is the JSON posted to the challenges webhook. Content
+// is a readable summary so chat webhooks (Discord) can show it as is.
+type ChallengeCompletedPost struct {
+	Event      string                     `json:"event"` // always "challenge_completed"
+	Content    string                     `json:"content"`
+	Completion models.ChallengeCompletion `json:"completion"`
+}
+
+// weekChallenges are the challenges running in one week
+type weekChallenges struct {
+	week       time.Time
+	challenges []models.Challenge
+}
+
+// challengeStep is one event counting towards one player's challenge or
+// quest step. For distinct-weapon steps cmd counts kills with the weapon under
+// distinct, and the progress key only moves on the first one.
+type challengeStep struct {
+	key       string
+	distinct  string
+	ttl       time.Duration
+	week      time.Time
+	chain     string // quest chain; empty for weekly challenges
+	challenge *models.Challenge
+	guid      string
+	name      string
+	event     *models.RawEvent
+	cmd       *db.IntResult
+}
+
+// ChallengeEngine rotates the weekly challenges in and tells the worker which
+// events count towards them and towards the onboarding quest chain of new
+// players. Progress lives in Redis counters incremented in the worker's
+// pipeline; a counter reaching its target stores the completion, and weekly
+// completions are posted to the webhook when one is set.
+type ChallengeEngine struct {
+	svc     logic.ChallengesService
+	perWeek int
+	current atomic.Pointer[weekChallenges]
+	quest   atomic.Pointer[questChain]
+	webhook atomic.Pointer[string]
+	inbox   *Inbox
+	client  *http.Client
+	logger  *zap.SugaredLogger
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	// GUIDs recently checked for enrollment, so veterans reconnecting are
+	// not looked up in ClickHouse each time
+	mu        sync.Mutex
+	checked   map[string]time.Time
+	lastSweep time.Time
+}
+
+func NewChallengeEngine(svc logic.ChallengesService, perWeek int, webhookURL string, logger *zap.Logger) *ChallengeEngine {
+	e := &ChallengeEngine{
+		svc:     svc,
+		perWeek: perWeek,
+		client:  &http.Client{Timeout: challengeWebhookTimeout},
+		logger:  logger.Sugar(),
+		done:    make(chan struct{}),
+		checked: make(map[string]time.Time),
+	}
+	e.SetWebhookURL(webhookURL)
+	return e
+}
+
+// SetWebhookURL replaces the webhook; empty disables posting
+func (e *ChallengeEngine) SetWebhookURL(url string) {
+	e.webhook.Store(&url)
+}
+
+// SetInbox sends weekly completions to the player's notifications. Call it
+// before Start.
+func (e *ChallengeEngine) SetInbox(inbox *Inbox) {
+	e.inbox = inbox
+}
+
+// Start loads the week's challenges right away, then every challengeRefreshInterval
+func (e *ChallengeEngine) Start(ctx context.Context) {
+	ctx, e.cancel = context.WithCancel(ctx)
+	go func() {
+		defer close(e.done)
+		e.Refresh(ctx, time.Now())
+
+		ticker := time.NewTicker(challengeRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				e.Refresh(ctx, now)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (e *ChallengeEngine) Stop() {
+	if e.cancel != nil {
+		e.cancel()
+		<-e.done
+	}
+}
+
+// Refresh rotates in the challenges of the week containing now, if not done
+// yet, and makes them the ones counted
+func (e *ChallengeEngine) Refresh(ctx context.Context, now time.Time) {
+	week := logic.ChallengeWeek(now)
+	challenges, err := e.svc.Rotate(ctx, week, e.perWeek)
+	if err != nil {
+		e.logger.Warnw("Failed to load weekly challenges", "week", week.Format("2006-01-02"), "error", err)
+		return
+	}
+	if prev := e.current.Load(); prev == nil || !prev.week.Equal(week) {
+		e.logger.Infow("Weekly challenges loaded", "week", week.Format("2006-01-02"), "challenges", len(challenges))
+	}
+	e.current.Store(&weekChallenges{week: week, challenges: challenges})
+	e.refreshQuest(ctx, now)
+}
+
+// Current returns the challenges being counted and their week
+func (e *ChallengeEngine) Current() (time.Time, []models.Challenge) {
+	if e == nil {
+		return time.Time{}, nil
+	}
+	cur := e.current.Load()
+	if cur == nil {
+		return time.Time{}, nil
+	}
+	return cur.week, cur.challenges
+}
+
+// steps returns the challenge and quest counters an event increments
+func (e *ChallengeEngine) steps(event *models.RawEvent) []challengeStep {
+	week, challenges := e.Current()
+	var steps []challengeStep
+	for i := range challenges {
+		c := &challenges[i]
+		if guid, name, ok := challengeCredit(c, event); ok {
+			step := newChallengeStep(challengeKey(week, c.Code, guid), challengeKeyTTL, c, guid, name, event)
+			step.week = week
+			steps = append(steps, step)
+		}
+	}
+	return append(steps, e.questSteps(event)...)
+}
+
+func newChallengeStep(key string, ttl time.Duration, c *models.Challenge, guid, name string, event *models.RawEvent) challengeStep {
+	step := challengeStep{key: key, ttl: ttl, challenge: c, guid: guid, name: name, event: event}
+	if c.Metric == models.ChallengeWeapons {
+		step.distinct = key + ":" + strings.ToLower(strings.TrimSpace(event.Weapon))
+	}
+	return step
+}
+
+// challengeCredit returns the player an event counts for on a challenge
+func challengeCredit(c *models.Challenge, event *models.RawEvent) (guid, name string, ok bool) {
+	if c.MapPrefix != "" && !strings.HasPrefix(strings.ToLower(event.MapName), c.MapPrefix) {
+		return "", "", false
+	}
+
+	switch c.Metric {
+	case models.ChallengeWins, models.ChallengeMatches:
+		if event.Type != models.EventMatchOutcome || event.PlayerGUID == "" {
+			return "", "", false
+		}
+		if c.Metric == models.ChallengeWins && event.MatchOutcome != 1 {
+			return "", "", false
+		}
+		return event.PlayerGUID, event.PlayerName, true
+	case models.ChallengeKills, models.ChallengeHeadshots, models.ChallengeWeapons:
+		if event.Type != models.EventPlayerKill {
+			return "", "", false
+		}
+		if c.Metric == models.ChallengeWeapons && strings.TrimSpace(event.Weapon) == "" {
+			return "", "", false
+		}
+		if c.Metric == models.ChallengeHeadshots && event.Hitloc != "head" && event.Hitloc != "helmet" {
+			return "", "", false
+		}
+	case models.ChallengeBashKills:
+		if event.Type != models.EventPlayerBash {
+			return "", "", false
+		}
+	case models.ChallengeRoadkills:
+		if event.Type != models.EventPlayerRoadkill {
+			return "", "", false
+		}
+	default:
+		return "", "", false
+	}
+
+	if event.AttackerGUID == "" || event.AttackerGUID == "world" || event.AttackerGUID == event.VictimGUID {
+		return "", "", false
+	}
+	if len(c.Weapons) > 0 {
+		weapon := strings.ToLower(event.Weapon)
+		matched := false
+		for _, w := range c.Weapons {
+			if strings.Contains(weapon, w) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return "", "", false
+		}
+	}
+	return event.AttackerGUID, event.AttackerName, true
+}
+
+func challengeKey(week time.Time, code, guid string) string {
+	return "challenge:" + week.Format("2006-01-02") + ":" + code + ":" + guid
+}
+
+// complete stores a completion and posts it, off the worker's path
+func (e *ChallengeEngine) complete(step challengeStep, at time.Time) {
+	if step.chain != "" {
+		e.completeQuestStep(step, at)
+		return
+	}
+	c := &models.ChallengeCompletion{
+		WeekStart:   step.week,
+		Code:        step.challenge.Code,
+		Title:       step.challenge.Title,
+		PlayerGUID:  step.guid,
+		PlayerName:  step.name,
+		CompletedAt: at,
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), challengeWebhookTimeout)
+		defer cancel()
+
+		stored, err := e.svc.Complete(ctx, c)
+		if err != nil {
+			e.logger.Errorw("Failed to store challenge completion", "challenge", c.Code, "player", c.PlayerGUID, "error", err)
+			return
+		}
+		if !stored {
+			return
+		}
+		challengesCompleted.WithLabelValues("stored").Inc()
+		e.logger.Infow("Challenge completed", "challenge", c.Code, "player", c.PlayerGUID)
+		e.inbox.Push(models.Notification{
+			PlayerGUID: c.PlayerGUID,
+			Kind:       models.NotifyChallenge,
+			Title:      fmt.Sprintf("Weekly challenge completed: %s", c.Title),
+			Subject:    c.Code,
+			MatchID:    step.event.MatchID,
+		})
+
+		if url := *e.webhook.Load(); url != "" {
+			e.send(ctx, url, c)
+		}
+	}()
+}
+
+func (e *ChallengeEngine) send(ctx context.Context, url string, c *models.ChallengeCompletion) {
+	body, err := json.Marshal(ChallengeCompletedPost{
+		Event:      "challenge_completed",
+		Content:    fmt.Sprintf("%s completed the weekly challenge %s", sanitizeName(c.PlayerName), c.Title),
+		Completion: *c,
+	})
+	if err != nil {
+		return
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		challengesCompleted.WithLabelValues("failed").Inc()
+		e.logger.Warnw("Invalid challenges webhook", "error", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		challengesCompleted.WithLabelValues("failed").Inc()
+		e.logger.Warnw("Challenges webhook failed", "challenge", c.Code, "player", c.PlayerGUID, "error", err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		challengesCompleted.WithLabelValues("failed").Inc()
+		e.logger.Warnw("Challenges webhook rejected", "challenge", c.Code, "player", c.PlayerGUID, "status", resp.StatusCode)
+		return
+	}
+	challengesCompleted.WithLabelValues("sent").Inc()
+}
+
+// ReadChallengeProgress reads a player's counters for the week's challenges,
+// in the order given
+func ReadChallengeProgress(ctx context.Context, store db.LiveStateStore, week time.Time, challenges []models.Challenge, guid string) ([]int64, error) {
+	keys := make([]string, len(challenges))
+	for i, c := range challenges {
+		keys[i] = challengeKey(week, c.Code, guid)
+	}
+	return readCounters(ctx, store, keys)
+}
+
+// readCounters reads counters in one round trip; missing ones are 0
+func readCounters(ctx context.Context, store db.LiveStateStore, keys []string) ([]int64, error) {
+	pipe := store.Pipeline()
+	results := make([]*db.StringResult, len(keys))
+	for i, key := range keys {
+		results[i] = pipe.Get(ctx, key)
+	}
+	if err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	counts := make([]int64, len(keys))
+	for i, r := range results {
+		counts[i] = intResult(r)
+	}
+	return counts, nil
+}
@@ -0,0 +1,95 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/db"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestChallengeCredit(t *testing.T) {
+	smg := &models.Challenge{Code: "smg_dm", Metric: models.ChallengeKills, Weapons: []string{"thompson", "mp40"}, MapPrefix: "dm", Target: 200}
+	kill := func(weapon, mapName string) *models.RawEvent {
+		return &models.RawEvent{Type: models.EventPlayerKill, AttackerGUID: "a", AttackerName: "Able", VictimGUID: "v", Weapon: weapon, MapName: mapName}
+	}
+
+	if guid, name, ok := challengeCredit(smg, kill("Thompson", "dm/mohdm1")); !ok || guid != "a" || name != "Able" {
+		t.Errorf("SMG kill on a DM map = %q %q %v", guid, name, ok)
+	}
+	if _, _, ok := challengeCredit(smg, kill("Kar98", "dm/mohdm1")); ok {
+		t.Error("rifle kill counted for an SMG challenge")
+	}
+	if _, _, ok := challengeCredit(smg, kill("MP40", "obj/obj_team2")); ok {
+		t.Error("objective map counted for a DM challenge")
+	}
+	world := kill("MP40", "dm/mohdm1")
+	world.AttackerGUID = "world"
+	if _, _, ok := challengeCredit(smg, world); ok {
+		t.Error("world kill counted")
+	}
+
+	headshots := &models.Challenge{Metric: models.ChallengeHeadshots, Target: 10}
+	body := kill("Kar98", "dm/mohdm2")
+	body.Hitloc = "torso_upper"
+	if _, _, ok := challengeCredit(headshots, body); ok {
+		t.Error("body shot counted as headshot")
+	}
+	body.Hitloc = "helmet"
+	if _, _, ok := challengeCredit(headshots, body); !ok {
+		t.Error("helmet shot not counted as headshot")
+	}
+
+	wins := &models.Challenge{Metric: models.ChallengeWins, MapPrefix: "obj", Target: 10}
+	outcome := &models.RawEvent{Type: models.EventMatchOutcome, PlayerGUID: "p", MatchOutcome: 1, MapName: "obj/obj_team1"}
+	if guid, _, ok := challengeCredit(wins, outcome); !ok || guid != "p" {
+		t.Errorf("objective win = %q %v", guid, ok)
+	}
+	outcome.MatchOutcome = 0
+	if _, _, ok := challengeCredit(wins, outcome); ok {
+		t.Error("loss counted as a win")
+	}
+	if _, _, ok := challengeCredit(&models.Challenge{Metric: models.ChallengeMatches, Target: 1}, outcome); !ok {
+		t.Error("finished match not counted")
+	}
+}
+
+func TestChallengeProgress(t *testing.T) {
+	ctx := context.Background()
+	store := db.NewMemoryLiveState()
+	week := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	challenges := []models.Challenge{
+		{Code: "nades", Metric: models.ChallengeKills, Weapons: []string{"grenade"}, Target: 2},
+		{Code: "regular", Metric: models.ChallengeMatches, Target: 5},
+	}
+
+	e := NewChallengeEngine(nil, 3, "", zap.NewNop())
+	e.current.Store(&weekChallenges{week: week, challenges: challenges})
+
+	event := &models.RawEvent{Type: models.EventPlayerKill, AttackerGUID: "a", VictimGUID: "v", Weapon: "M2 Frag Grenade"}
+	steps := e.steps(event)
+	if len(steps) != 1 || steps[0].key != "challenge:2026-10-12:nades:a" {
+		t.Fatalf("steps = %+v", steps)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := store.Incr(ctx, steps[0].key); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	progress, err := ReadChallengeProgress(ctx, store, week, challenges, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progress[0] != 3 || progress[1] != 0 {
+		t.Errorf("progress = %v, want [3 0]", progress)
+	}
+
+	var nilEngine *ChallengeEngine
+	if steps := nilEngine.steps(event); steps != nil {
+		t.Errorf("nil engine steps = %+v", steps)
+	}
+}
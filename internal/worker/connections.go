@@ -0,0 +1,122 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+var connectionHashes = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "mohaa_connection_ip_hashes_total",
+	Help: "Hashed client IPs received with connect events by result (recorded, invalid, dropped)",
+}, []string{"result"})
+
+const (
+	// connectionFlushInterval is how often collected connections are written
+	connectionFlushInterval = 30 * time.Second
+	// connectionBufferSize bounds the connections held between flushes;
+	// more are dropped until the next flush
+	connectionBufferSize = 10000
+)
+
+// ConnectionTracker collects the hashed client IPs servers send with connect
+// events and writes them for alt account detection every
+// connectionFlushInterval. Hashes that are not a hex SHA-256 are dropped, so
+// a server sending raw addresses by mistake leaves no trace of them.
+type ConnectionTracker struct {
+	svc    logic.AltAccountService
+	logger *zap.SugaredLogger
+
+	mu      sync.Mutex
+	pending []models.PlayerConnection
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewConnectionTracker(svc logic.AltAccountService, logger *zap.Logger) *ConnectionTracker {
+	return &ConnectionTracker{
+		svc:    svc,
+		logger: logger.Sugar(),
+		done:   make(chan struct{}),
+	}
+}
+
+// Observe collects the IP hash of a player's connect event
+func (t *ConnectionTracker) Observe(event *models.RawEvent, now time.Time) {
+	if t == nil || event.Type != models.EventConnect || event.PlayerGUID == "" {
+		return
+	}
+	hash, ok := logic.NormalizeIPHash(event.ClientIPHash)
+	if !ok {
+		connectionHashes.WithLabelValues("invalid").Inc()
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.pending) >= connectionBufferSize {
+		connectionHashes.WithLabelValues("dropped").Inc()
+		return
+	}
+	t.pending = append(t.pending, models.PlayerConnection{
+		IPHash:     hash,
+		PlayerGUID: event.PlayerGUID,
+		ServerID:   event.ServerID,
+		At:         now.UTC(),
+	})
+}
+
+// Start writes collected connections in the background until Stop, which
+// writes what is left
+func (t *ConnectionTracker) Start(ctx context.Context) {
+	ctx, t.cancel = context.WithCancel(ctx)
+	go func() {
+		defer close(t.done)
+		ticker := time.NewTicker(connectionFlushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				t.flush(ctx)
+			case <-ctx.Done():
+				flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				t.flush(flushCtx)
+				cancel()
+				return
+			}
+		}
+	}()
+}
+
+func (t *ConnectionTracker) Stop() {
+	if t.cancel != nil {
+		t.cancel()
+		<-t.done
+	}
+}
+
+// flush writes the collected connections; a failed write drops them, the
+// next connects of the same players record them again
+func (t *ConnectionTracker) flush(ctx context.Context) {
+	t.mu.Lock()
+	pending := t.pending
+	t.pending = nil
+	t.mu.Unlock()
+	if len(pending) == 0 {
+		return
+	}
+	if err := t.svc.Record(ctx, pending); err != nil {
+		t.logger.Warnw("Failed to record player connections", "connections", len(pending), "error", err)
+		return
+	}
+	connectionHashes.WithLabelValues("recorded").Add(float64(len(pending)))
+}
@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// fakeAltAccounts records the connections written
+type fakeAltAccounts struct {
+	logic.AltAccountService
+	recorded [][]models.PlayerConnection
+}
+
+func (f *fakeAltAccounts) Record(_ context.Context, conns []models.PlayerConnection) error {
+	f.recorded = append(f.recorded, conns)
+	return nil
+}
+
+func TestConnectionTracker(t *testing.T) {
+	svc := &fakeAltAccounts{}
+	tracker := NewConnectionTracker(svc, zap.NewNop())
+	now := time.Now()
+	hash := strings.Repeat("0f", 32)
+
+	connect := func(guid, ipHash string) *models.RawEvent {
+		return &models.RawEvent{Type: models.EventConnect, ServerID: "srv", PlayerGUID: guid, ClientIPHash: ipHash}
+	}
+	tracker.Observe(connect("a", strings.ToUpper(hash)), now)
+	tracker.Observe(connect("b", "203.0.113.7"), now) // a raw address is refused
+	tracker.Observe(connect("", hash), now)
+	tracker.Observe(&models.RawEvent{Type: models.EventDisconnect, PlayerGUID: "a", ClientIPHash: hash}, now)
+
+	tracker.flush(context.Background())
+	if len(svc.recorded) != 1 || len(svc.recorded[0]) != 1 {
+		t.Fatalf("recorded = %+v, want the one valid connect", svc.recorded)
+	}
+	c := svc.recorded[0][0]
+	if c.IPHash != hash || c.PlayerGUID != "a" || c.ServerID != "srv" {
+		t.Errorf("connection = %+v", c)
+	}
+
+	// Nothing pending, nothing written
+	tracker.flush(context.Background())
+	if len(svc.recorded) != 1 {
+		t.Errorf("empty flush wrote %d batches", len(svc.recorded)-1)
+	}
+
+	var nilTracker *ConnectionTracker
+	nilTracker.Observe(connect("a", hash), now)
+}
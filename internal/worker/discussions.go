@@ -0,0 +1,167 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/i18n"
+	"github.com/openmohaa/stats-api/internal/jobs"
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+	"github.com/openmohaa/stats-api/internal/smf"
+)
+
+const (
+	// discussionFeatured is how many of the day's most watched matches get
+	// a forum thread
+	discussionFeatured = 5
+	// discussionRefreshWindow is how long after it was linked a thread's
+	// post count is kept up to date
+	discussionRefreshWindow = 30 * 24 * time.Hour
+)
+
+// DiscussionConfig is where featured match threads are started. Matches
+// need MinSpectators at peak to be featured; MatchPageURL, with {id} for the
+// match ID, is linked from the first post.
+type DiscussionConfig struct {
+	Board         int
+	MinSpectators int
+	MatchPageURL  string
+}
+
+// DiscussionSync starts an SMF forum thread for each featured match, the
+// most watched of the last day, and keeps the post counts of recent threads
+// up to date for the "discuss (12)" links.
+type DiscussionSync struct {
+	svc        logic.DiscussionService
+	viewership logic.ViewershipService
+	forum      *smf.Client
+	cfg        DiscussionConfig
+	locale     atomic.Pointer[string]
+	logger     *zap.SugaredLogger
+}
+
+func NewDiscussionSync(svc logic.DiscussionService, viewership logic.ViewershipService, forum *smf.Client, cfg DiscussionConfig, logger *zap.Logger) *DiscussionSync {
+	d := &DiscussionSync{
+		svc:        svc,
+		viewership: viewership,
+		forum:      forum,
+		cfg:        cfg,
+		logger:     logger.Sugar(),
+	}
+	d.SetLocale(i18n.DefaultLocale)
+	return d
+}
+
+// SetLocale sets the language of the threads started
+func (d *DiscussionSync) SetLocale(locale string) {
+	d.locale.Store(&locale)
+}
+
+// Job is the sync as a scheduled job
+func (d *DiscussionSync) Job() jobs.Job {
+	return jobs.Job{
+		Name:        "smf_discussions",
+		Description: "Starts forum threads for featured matches and refreshes the post counts of recent threads",
+		Schedule:    "@every 10m",
+		Run:         d.RunOnce,
+	}
+}
+
+// RunOnce starts the missing threads, then refreshes the post counts
+func (d *DiscussionSync) RunOnce(ctx context.Context) error {
+	created, err := d.startThreads(ctx)
+	if err != nil {
+		return err
+	}
+	refreshed, err := d.refreshPosts(ctx)
+	if err != nil {
+		return err
+	}
+	if created > 0 || refreshed > 0 {
+		d.logger.Infow("Discussion threads synced", "created", created, "refreshed", refreshed)
+	}
+	return nil
+}
+
+// startThreads creates a thread for each featured match without one. A
+// match whose thread cannot be created is tried again on the next run.
+func (d *DiscussionSync) startThreads(ctx context.Context) (int, error) {
+	featured, err := d.viewership.Featured(ctx, 24, discussionFeatured)
+	if err != nil {
+		return 0, err
+	}
+	byID := make(map[string]models.FeaturedMatch, len(featured))
+	var ids []string
+	for _, m := range featured {
+		if m.PeakSpectators >= d.cfg.MinSpectators {
+			byID[m.MatchID] = m
+			ids = append(ids, m.MatchID)
+		}
+	}
+	missing, err := d.svc.Missing(ctx, models.DiscussMatch, ids)
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for _, id := range missing {
+		subject, body := d.renderThread(byID[id])
+		topic, err := d.forum.CreateTopic(ctx, d.cfg.Board, subject, body)
+		if err != nil {
+			d.logger.Warnw("Failed to start match thread", "match", id, "error", err)
+			continue
+		}
+		link := &models.DiscussionLinkRequest{TopicID: topic.ID, URL: topic.URL}
+		if _, err := d.svc.Link(ctx, models.DiscussMatch, id, link, ""); err != nil {
+			return created, err
+		}
+		created++
+	}
+	return created, nil
+}
+
+// refreshPosts reads the post counts of the threads linked in the last
+// discussionRefreshWindow
+func (d *DiscussionSync) refreshPosts(ctx context.Context) (int, error) {
+	threads, err := d.svc.Recent(ctx, time.Now().Add(-discussionRefreshWindow))
+	if err != nil || len(threads) == 0 {
+		return 0, err
+	}
+	seen := make(map[int]bool, len(threads))
+	ids := make([]int, 0, len(threads))
+	for _, t := range threads {
+		if !seen[t.TopicID] {
+			seen[t.TopicID] = true
+			ids = append(ids, t.TopicID)
+		}
+	}
+	topics, err := d.forum.Topics(ctx, ids)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read forum topics: %w", err)
+	}
+	posts := make(map[int]int, len(topics))
+	for _, t := range topics {
+		posts[t.ID] = t.Posts
+	}
+	return len(posts), d.svc.SetPosts(ctx, posts)
+}
+
+// renderThread writes the subject and first post of a match's thread
+func (d *DiscussionSync) renderThread(m models.FeaturedMatch) (subject, body string) {
+	c := i18n.Default()
+	locale := *d.locale.Load()
+	subject = c.Sprintf(locale, "discussion.match.subject", "%s – %s UTC",
+		m.MapName, m.FirstSeen.UTC().Format("2006-01-02 15:04"))
+	body = c.Sprintf(locale, "discussion.match.body", "%d spectators watched this match on %s at its peak.",
+		m.PeakSpectators, m.MapName)
+	if d.cfg.MatchPageURL != "" {
+		body += "\n\n" + strings.ReplaceAll(d.cfg.MatchPageURL, "{id}", m.MatchID)
+	}
+	return subject, body
+}
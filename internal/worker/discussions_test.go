@@ -0,0 +1,128 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+	"github.com/openmohaa/stats-api/internal/smf"
+)
+
+type fakeViewership struct {
+	logic.ViewershipService
+	featured []models.FeaturedMatch
+}
+
+func (f *fakeViewership) Featured(ctx context.Context, hours, limit int) ([]models.FeaturedMatch, error) {
+	return f.featured, nil
+}
+
+type fakeDiscussions struct {
+	logic.DiscussionService
+	threads map[string]models.DiscussionThread
+	posts   map[int]int
+}
+
+func (f *fakeDiscussions) Missing(ctx context.Context, subject models.DiscussionSubject, ids []string) ([]string, error) {
+	var missing []string
+	for _, id := range ids {
+		if _, ok := f.threads[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	return missing, nil
+}
+
+func (f *fakeDiscussions) Link(ctx context.Context, subject models.DiscussionSubject, id string, req *models.DiscussionLinkRequest, actor string) (*models.DiscussionThread, error) {
+	t := models.DiscussionThread{SubjectType: subject, SubjectID: id, TopicID: req.TopicID, URL: req.URL, CreatedAt: time.Now()}
+	f.threads[id] = t
+	return &t, nil
+}
+
+func (f *fakeDiscussions) Recent(ctx context.Context, since time.Time) ([]models.DiscussionThread, error) {
+	var threads []models.DiscussionThread
+	for _, t := range f.threads {
+		threads = append(threads, t)
+	}
+	return threads, nil
+}
+
+func (f *fakeDiscussions) SetPosts(ctx context.Context, posts map[int]int) error {
+	f.posts = posts
+	return nil
+}
+
+func TestDiscussionSync(t *testing.T) {
+	var subjects, bodies []string
+	forum := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			var post map[string]any
+			json.NewDecoder(r.Body).Decode(&post)
+			subjects = append(subjects, post["subject"].(string))
+			bodies = append(bodies, post["body"].(string))
+			json.NewEncoder(w).Encode(smf.Topic{ID: 100 + len(subjects), Posts: 1})
+			return
+		}
+		var topics []smf.Topic
+		for _, id := range strings.Split(r.URL.Query().Get("ids"), ",") {
+			switch id {
+			case "7":
+				topics = append(topics, smf.Topic{ID: 7, Posts: 12})
+			case "101":
+				topics = append(topics, smf.Topic{ID: 101, Posts: 1})
+			}
+		}
+		json.NewEncoder(w).Encode(topics)
+	}))
+	defer forum.Close()
+
+	started := time.Date(2026, 10, 11, 19, 30, 0, 0, time.UTC)
+	viewership := &fakeViewership{featured: []models.FeaturedMatch{
+		{MatchID: "big", MapName: "obj/obj_team2", FirstSeen: started, PeakSpectators: 40},
+		{MatchID: "old", MapName: "dm/mohdm6", FirstSeen: started, PeakSpectators: 20},
+		{MatchID: "small", MapName: "dm/mohdm1", FirstSeen: started, PeakSpectators: 2},
+	}}
+	discussions := &fakeDiscussions{threads: map[string]models.DiscussionThread{
+		"old": {SubjectType: models.DiscussMatch, SubjectID: "old", TopicID: 7},
+	}}
+	sync := NewDiscussionSync(discussions, viewership, smf.NewClient(forum.URL, ""), DiscussionConfig{
+		Board:         4,
+		MinSpectators: 3,
+		MatchPageURL:  "https://forum.example.com/match?id={id}",
+	}, zap.NewNop())
+
+	if err := sync.RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(subjects) != 1 || subjects[0] != "obj/obj_team2 – 2026-10-11 19:30 UTC" {
+		t.Fatalf("threads started: %q", subjects)
+	}
+	if !strings.Contains(bodies[0], "40 spectators") || !strings.HasSuffix(bodies[0], "https://forum.example.com/match?id=big") {
+		t.Errorf("body = %q", bodies[0])
+	}
+	if discussions.threads["big"].TopicID != 101 || discussions.threads["big"].LinkedBy != "" {
+		t.Errorf("thread = %+v", discussions.threads["big"])
+	}
+	if _, ok := discussions.threads["small"]; ok {
+		t.Error("thread started for a match below the spectator minimum")
+	}
+	if discussions.posts[7] != 12 || discussions.posts[101] != 1 {
+		t.Errorf("posts = %v", discussions.posts)
+	}
+
+	// Threads are started once
+	if err := sync.RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(subjects) != 1 {
+		t.Errorf("%d threads started after a second run", len(subjects))
+	}
+}
@@ -0,0 +1,119 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/bus"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+var (
+	busPublished = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "mohaa_event_bus_published_total",
+		Help: "Accepted events mirrored onto the event bus",
+	})
+	busDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "mohaa_event_bus_dropped_total",
+		Help: "Events not mirrored onto the event bus, by reason (full, error)",
+	}, []string{"reason"})
+)
+
+// eventBusBuffer is how many events wait for the bus before new ones are
+// dropped; the bus never holds ingestion up
+const eventBusBuffer = 10000
+
+// EventBus mirrors every accepted event onto a message bus, on the subject
+// <prefix>.<event type>, for consumers outside the API. Delivery is best
+// effort: events are dropped while the bus is slow or down.
+type EventBus struct {
+	pub    bus.Publisher
+	prefix string
+	logger *zap.SugaredLogger
+	queue  chan busMessage
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+type busMessage struct {
+	subject string
+	data    []byte
+}
+
+func NewEventBus(pub bus.Publisher, prefix string, logger *zap.Logger) *EventBus {
+	return &EventBus{
+		pub:    pub,
+		prefix: prefix,
+		logger: logger.Sugar(),
+		queue:  make(chan busMessage, eventBusBuffer),
+		done:   make(chan struct{}),
+	}
+}
+
+// busEvent is the message of an event, without the server's token
+func busEvent(event *models.RawEvent, now time.Time) ([]byte, error) {
+	copied := *event
+	copied.ServerToken = ""
+	return json.Marshal(models.BusEvent{TenantID: event.TenantID, ReceivedAt: now.UTC(), Event: &copied})
+}
+
+// Observe queues the event for the bus, dropping it when the queue is full
+func (b *EventBus) Observe(event *models.RawEvent, now time.Time) {
+	if b == nil {
+		return
+	}
+	data, err := busEvent(event, now)
+	if err != nil {
+		busDropped.WithLabelValues("error").Inc()
+		return
+	}
+	select {
+	case b.queue <- busMessage{subject: b.prefix + "." + string(event.Type), data: data}:
+	default:
+		busDropped.WithLabelValues("full").Inc()
+	}
+}
+
+// Start publishes queued events until Stop
+func (b *EventBus) Start(ctx context.Context) {
+	ctx, b.cancel = context.WithCancel(ctx)
+	go func() {
+		defer close(b.done)
+		failing := false
+		for {
+			select {
+			case msg := <-b.queue:
+				err := b.pub.Publish(ctx, msg.subject, msg.data)
+				if err != nil {
+					busDropped.WithLabelValues("error").Inc()
+					// Logged once per outage, not per event
+					if !failing {
+						b.logger.Warnw("Event bus unavailable, dropping events", "error", err)
+					}
+				} else {
+					busPublished.Inc()
+					if failing {
+						b.logger.Infow("Event bus available again")
+					}
+				}
+				failing = err != nil
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops publishing, dropping what is queued, and closes the bus
+func (b *EventBus) Stop() {
+	if b.cancel != nil {
+		b.cancel()
+		<-b.done
+	}
+	b.pub.Close()
+}
@@ -0,0 +1,58 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+type recordingBus struct {
+	subjects chan string
+	payloads chan []byte
+}
+
+func (b *recordingBus) Publish(ctx context.Context, subject string, data []byte) error {
+	b.subjects <- subject
+	b.payloads <- data
+	return nil
+}
+
+func (b *recordingBus) Close() error { return nil }
+
+func TestEventBusMirrorsEvents(t *testing.T) {
+	pub := &recordingBus{subjects: make(chan string, 1), payloads: make(chan []byte, 1)}
+	eb := NewEventBus(pub, "mohaa.events", zap.NewNop())
+	eb.Start(context.Background())
+	defer eb.Stop()
+
+	eb.Observe(&models.RawEvent{
+		Type:        models.EventPlayerKill,
+		ServerID:    "srv-1",
+		ServerToken: "secret",
+		TenantID:    "tenant-a",
+	}, time.Now())
+
+	if subject := <-pub.subjects; subject != "mohaa.events.player_kill" {
+		t.Errorf("subject = %q", subject)
+	}
+	data := <-pub.payloads
+	if strings.Contains(string(data), "secret") {
+		t.Errorf("server token mirrored: %s", data)
+	}
+	var msg models.BusEvent
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.TenantID != "tenant-a" || msg.Event == nil || msg.Event.ServerID != "srv-1" {
+		t.Errorf("message = %s", data)
+	}
+
+	var off *EventBus
+	off.Observe(&models.RawEvent{Type: models.EventPlayerKill}, time.Now())
+}
@@ -0,0 +1,111 @@
+package worker
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var faultsInjected = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "mohaa_faults_injected_total",
+	Help: "Faults injected into the worker pool by kind (drop_batch, clickhouse_delay, redis_pipeline)",
+}, []string{"kind"})
+
+// ErrInjectedFault is the error of a batch or pipeline failed on purpose
+var ErrInjectedFault = errors.New("injected fault")
+
+// maxFaultDelay caps the injected ClickHouse delay so a worker cannot be
+// parked for good
+const maxFaultDelay = time.Minute
+
+// FaultConfig is what the pool is made to suffer, to watch load shedding,
+// failed batch handling and the live state breaker work. The zero value
+// injects nothing.
+type FaultConfig struct {
+	// DropBatchPercent fails this share of batches before their ClickHouse
+	// insert, as an insert error would
+	DropBatchPercent int
+	// ClickHouseDelay holds each batch this long before its insert, so the
+	// queues back up
+	ClickHouseDelay time.Duration
+	// RedisDown fails every Redis side effect pipeline, opening the breaker
+	RedisDown bool
+}
+
+// Validate reports a config the pool cannot run with
+func (c FaultConfig) Validate() error {
+	if c.DropBatchPercent < 0 || c.DropBatchPercent > 100 {
+		return fmt.Errorf("drop_batch_percent must be between 0 and 100")
+	}
+	if c.ClickHouseDelay < 0 || c.ClickHouseDelay > maxFaultDelay {
+		return fmt.Errorf("clickhouse_delay must be between 0 and %s", maxFaultDelay)
+	}
+	return nil
+}
+
+// Faults injects failures into the worker pool for testing. It exists only
+// in test builds (the chaos build tag) or when an operator enables it
+// outside production; see FaultInjectionAllowed.
+type Faults struct {
+	config atomic.Pointer[FaultConfig]
+}
+
+func NewFaults() *Faults {
+	f := &Faults{}
+	f.Set(FaultConfig{})
+	return f
+}
+
+// Set replaces the injected faults from the next batch on
+func (f *Faults) Set(cfg FaultConfig) {
+	f.config.Store(&cfg)
+}
+
+// Config returns the faults being injected
+func (f *Faults) Config() FaultConfig {
+	return *f.config.Load()
+}
+
+// FaultInjectionAllowed reports whether faults may be injected: always in
+// chaos builds, otherwise when enabled and env is not production
+func FaultInjectionAllowed(enabled bool, env string) bool {
+	return ChaosBuild || (enabled && env != "production")
+}
+
+// dropBatch reports whether to fail the next batch
+func (f *Faults) dropBatch() bool {
+	if f == nil {
+		return false
+	}
+	percent := f.config.Load().DropBatchPercent
+	if percent <= 0 || rand.IntN(100) >= percent {
+		return false
+	}
+	faultsInjected.WithLabelValues("drop_batch").Inc()
+	return true
+}
+
+// delayClickHouse sleeps for the injected ClickHouse delay
+func (f *Faults) delayClickHouse() {
+	if f == nil {
+		return
+	}
+	if delay := f.config.Load().ClickHouseDelay; delay > 0 {
+		faultsInjected.WithLabelValues("clickhouse_delay").Inc()
+		time.Sleep(delay)
+	}
+}
+
+// redisDown reports whether to fail the next Redis pipeline
+func (f *Faults) redisDown() bool {
+	if f == nil || !f.config.Load().RedisDown {
+		return false
+	}
+	faultsInjected.WithLabelValues("redis_pipeline").Inc()
+	return true
+}
@@ -0,0 +1,7 @@
+//go:build !chaos
+
+package worker
+
+// ChaosBuild is set by the chaos build tag: fault injection is available
+// whatever the configuration
+const ChaosBuild = false
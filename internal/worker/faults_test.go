@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestFaults(t *testing.T) {
+	faults := NewFaults()
+	p := &Pool{
+		config:    PoolConfig{ShardByMatch: true, Faults: faults},
+		logger:    zap.NewNop().Sugar(),
+		liveState: newLiveStateBreaker(LiveStateBreakerConfig{Threshold: 1, BufferSize: 10}),
+	}
+
+	// Redis pipelines fail and every batch is dropped before ClickHouse,
+	// which the pool never reaches
+	faults.Set(FaultConfig{DropBatchPercent: 100, RedisDown: true})
+	err := p.processBatch(jobsOf(models.EventMatchStart, models.EventPlayerKill))
+	if !errors.Is(err, ErrInjectedFault) {
+		t.Fatalf("err = %v, want an injected fault", err)
+	}
+	if status := p.LiveStateStatus(); !status.Degraded || status.Buffered != 1 {
+		t.Errorf("live state = %+v, want degraded holding match_start", status)
+	}
+
+	var nilFaults *Faults
+	if nilFaults.dropBatch() || nilFaults.redisDown() {
+		t.Error("nil faults injected a fault")
+	}
+	faults.Set(FaultConfig{})
+	if faults.dropBatch() || faults.redisDown() {
+		t.Error("cleared faults injected a fault")
+	}
+}
+
+func TestFaultConfigValidate(t *testing.T) {
+	for _, cfg := range []FaultConfig{
+		{DropBatchPercent: -1},
+		{DropBatchPercent: 101},
+		{ClickHouseDelay: -time.Second},
+		{ClickHouseDelay: 2 * time.Minute},
+	} {
+		if cfg.Validate() == nil {
+			t.Errorf("%+v accepted", cfg)
+		}
+	}
+	if err := (FaultConfig{DropBatchPercent: 50, ClickHouseDelay: time.Second, RedisDown: true}).Validate(); err != nil {
+		t.Error(err)
+	}
+
+	if FaultInjectionAllowed(true, "production") != ChaosBuild {
+		t.Error("production allowed fault injection outside a chaos build")
+	}
+	if !FaultInjectionAllowed(true, "staging") || FaultInjectionAllowed(false, "staging") != ChaosBuild {
+		t.Error("FAULT_INJECTION not honoured outside production")
+	}
+}
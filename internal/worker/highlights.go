@@ -0,0 +1,194 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/i18n"
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+var highlightsComputed = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "mohaa_player_highlights_total",
+	Help: "Player of the day/week computations by result (computed, failed)",
+}, []string{"result"})
+
+const (
+	// highlightsInterval is how often the scheduler looks for finished periods
+	highlightsInterval = time.Hour
+	// highlightsGrace lets late events of a period arrive before it is picked
+	highlightsGrace = time.Hour
+	// highlightsWebhookTimeout bounds one webhook delivery
+	highlightsWebhookTimeout = 10 * time.Second
+)
+
+// PlayerHighlightsPost is the JSON posted to the highlights webhook. Content is
+// a readable summary so chat webhooks (Discord) can show it as is.
+type PlayerHighlightsPost struct {
+	Event       string                   `json:"event"` // always "player_highlights"
+	Content     string                   `json:"content"`
+	Period      models.HighlightPeriod   `json:"period"`
+	PeriodStart time.Time                `json:"period_start"`
+	Highlights  []models.PlayerHighlight `json:"highlights"`
+}
+
+// HighlightsScheduler picks the players of each finished day and week once,
+// an hour after the period ends, and posts the default tenant's picks to a
+// webhook when one is set
+type HighlightsScheduler struct {
+	svc     logic.HighlightsService
+	webhook atomic.Pointer[string]
+	locale  atomic.Pointer[string]
+	client  *http.Client
+	logger  *zap.SugaredLogger
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+func NewHighlightsScheduler(svc logic.HighlightsService, webhookURL string, logger *zap.Logger) *HighlightsScheduler {
+	s := &HighlightsScheduler{
+		svc:    svc,
+		client: &http.Client{Timeout: highlightsWebhookTimeout},
+		logger: logger.Sugar(),
+		done:   make(chan struct{}),
+	}
+	s.SetWebhookURL(webhookURL)
+	s.SetLocale(i18n.DefaultLocale)
+	return s
+}
+
+// SetLocale sets the language of the webhook post text
+func (s *HighlightsScheduler) SetLocale(locale string) {
+	s.locale.Store(&locale)
+}
+
+// SetWebhookURL replaces the webhook; empty disables posting
+func (s *HighlightsScheduler) SetWebhookURL(url string) {
+	s.webhook.Store(&url)
+}
+
+// Start checks right away, then every highlightsInterval
+func (s *HighlightsScheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+	go func() {
+		defer close(s.done)
+		s.RunOnce(ctx, time.Now())
+
+		ticker := time.NewTicker(highlightsInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				s.RunOnce(ctx, now)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (s *HighlightsScheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+		<-s.done
+	}
+}
+
+// RunOnce computes the last finished day and week if they have no picks yet
+func (s *HighlightsScheduler) RunOnce(ctx context.Context, now time.Time) {
+	for _, period := range []models.HighlightPeriod{models.HighlightDay, models.HighlightWeek} {
+		start := lastFinishedPeriod(period, now)
+		done, err := s.svc.Computed(ctx, period, start)
+		if err != nil {
+			s.logger.Warnw("Failed to check player highlights", "period", period, "error", err)
+			continue
+		}
+		if done {
+			continue
+		}
+
+		picks, err := s.svc.Compute(ctx, period, start)
+		if err != nil {
+			highlightsComputed.WithLabelValues("failed").Inc()
+			s.logger.Errorw("Failed to compute player highlights", "period", period, "start", start, "error", err)
+			continue
+		}
+		highlightsComputed.WithLabelValues("computed").Inc()
+		s.logger.Infow("Player highlights computed", "period", period, "start", start.Format("2006-01-02"), "picks", len(picks))
+
+		if url := *s.webhook.Load(); url != "" {
+			if post := highlightsPost(*s.locale.Load(), period, start, picks); post != nil {
+				s.send(ctx, url, post)
+			}
+		}
+	}
+}
+
+// lastFinishedPeriod returns the start of the latest period that ended at
+// least highlightsGrace before now
+func lastFinishedPeriod(period models.HighlightPeriod, now time.Time) time.Time {
+	current := logic.HighlightPeriodStart(period, now.Add(-highlightsGrace))
+	if period == models.HighlightWeek {
+		return current.AddDate(0, 0, -7)
+	}
+	return current.AddDate(0, 0, -1)
+}
+
+// highlightsPost builds the webhook post from the default tenant's picks, its
+// text in locale; nil when there is no network-wide pick
+func highlightsPost(locale string, period models.HighlightPeriod, start time.Time, picks []models.PlayerHighlight) *PlayerHighlightsPost {
+	post := &PlayerHighlightsPost{Event: "player_highlights", Period: period, PeriodStart: start}
+	var network *models.PlayerHighlight
+	for i, p := range picks {
+		if p.TenantID != "" {
+			continue
+		}
+		post.Highlights = append(post.Highlights, p)
+		if p.ServerID == "" {
+			network = &picks[i]
+		}
+	}
+	if network == nil {
+		return nil
+	}
+	post.Content = i18n.Default().Sprintf(locale, "digest.player_highlight."+string(period),
+		"Player of the "+string(period)+" (%s): %s, score %.1f (%d kills, %d deaths, %d headshots)",
+		start.Format("2006-01-02"), sanitizeName(network.PlayerName), network.Score,
+		network.Kills, network.Deaths, network.Headshots)
+	return post
+}
+
+func (s *HighlightsScheduler) send(ctx context.Context, url string, post *PlayerHighlightsPost) {
+	body, err := json.Marshal(post)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, highlightsWebhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		s.logger.Warnw("Invalid highlights webhook", "error", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		s.logger.Warnw("Highlights webhook failed", "period", post.Period, "error", err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		s.logger.Warnw("Highlights webhook rejected", "period", post.Period, "status", resp.StatusCode)
+	}
+}
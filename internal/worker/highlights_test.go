@@ -0,0 +1,47 @@
+package worker
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestLastFinishedPeriod(t *testing.T) {
+	// Tuesday 00:30: yesterday is still within the grace period
+	now := time.Date(2026, 10, 13, 0, 30, 0, 0, time.UTC)
+	if got, want := lastFinishedPeriod(models.HighlightDay, now), time.Date(2026, 10, 11, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("day = %v, want %v", got, want)
+	}
+	if got, want := lastFinishedPeriod(models.HighlightDay, now.Add(time.Hour)), time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("day after grace = %v, want %v", got, want)
+	}
+	if got, want := lastFinishedPeriod(models.HighlightWeek, now), time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("week = %v, want %v", got, want)
+	}
+}
+
+func TestHighlightsPost(t *testing.T) {
+	start := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	picks := []models.PlayerHighlight{
+		{PlayerGUID: "a", PlayerName: "Alpha", Score: 40, Kills: 38},
+		{ServerID: "s1", PlayerGUID: "b", PlayerName: "Bravo"},
+		{TenantID: "clan", PlayerGUID: "d", PlayerName: "Delta"},
+	}
+	post := highlightsPost("en", models.HighlightDay, start, picks)
+	if post == nil || len(post.Highlights) != 2 {
+		t.Fatalf("post = %+v, want the default tenant's two picks", post)
+	}
+	if want := "Player of the day (2026-10-12): Alpha, score 40.0 (38 kills, 0 deaths, 0 headshots)"; post.Content != want {
+		t.Errorf("content = %q", post.Content)
+	}
+
+	if post := highlightsPost("de", models.HighlightWeek, start, picks); !strings.HasPrefix(post.Content, "Spieler der Woche (2026-10-12): Alpha") {
+		t.Errorf("de content = %q", post.Content)
+	}
+
+	if highlightsPost("en", models.HighlightDay, start, picks[1:]) != nil {
+		t.Error("post without a network-wide pick")
+	}
+}
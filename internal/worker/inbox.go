@@ -0,0 +1,138 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+var notificationsStored = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "mohaa_notifications_total",
+	Help: "Notifications stored in forum users' inboxes by kind",
+}, []string{"kind"})
+
+const (
+	// inboxTimeout bounds storing one notification
+	inboxTimeout = 5 * time.Second
+	// rivalryKills is how often a player must kill the same opponent in one
+	// match for both to be told about their rivalry
+	rivalryKills = 5
+)
+
+// Inbox stores notifications for the forum users who linked the players
+// involved: achievement unlocks, broken server records and completed weekly
+// challenges are pushed to it, and it follows kills per match to tell two
+// players when one keeps killing the other. Matches are forgotten on
+// match_end or after matchIdle without events.
+type Inbox struct {
+	svc    logic.NotificationService
+	logger *zap.SugaredLogger
+
+	mu      sync.Mutex
+	matches *matchTracker[*matchRivalries]
+}
+
+type matchRivalries struct {
+	kills map[rivalPair]int
+}
+
+// rivalPair is a killer and the opponent they killed
+type rivalPair struct {
+	attacker, victim string
+}
+
+func NewInbox(svc logic.NotificationService, logger *zap.Logger) *Inbox {
+	return &Inbox{
+		svc:     svc,
+		logger:  logger.Sugar(),
+		matches: newMatchTracker[*matchRivalries](),
+	}
+}
+
+// Push stores a notification in the background
+func (i *Inbox) Push(n models.Notification) {
+	if i == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), inboxTimeout)
+		defer cancel()
+		i.store(ctx, &n)
+	}()
+}
+
+func (i *Inbox) store(ctx context.Context, n *models.Notification) {
+	count, err := i.svc.Notify(ctx, n)
+	if err != nil {
+		i.logger.Warnw("Failed to store notification", "kind", n.Kind, "player", n.PlayerGUID, "user", n.ForumUserID, "error", err)
+		return
+	}
+	if count > 0 {
+		notificationsStored.WithLabelValues(string(n.Kind)).Add(float64(count))
+	}
+}
+
+// Observe counts a kill towards its rivalry and notifies both players in the
+// background when it reaches rivalryKills
+func (i *Inbox) Observe(event *models.RawEvent, now time.Time) {
+	for _, n := range i.observe(event, now) {
+		i.Push(n)
+	}
+}
+
+// observe returns the notifications an event triggers, if any
+func (i *Inbox) observe(event *models.RawEvent, now time.Time) []models.Notification {
+	if i == nil || event.MatchID == "" {
+		return nil
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.matches.sweep(now)
+
+	if event.Type == models.EventMatchEnd {
+		i.matches.end(event.MatchID)
+		return nil
+	}
+	if event.Type != models.EventPlayerKill || event.AttackerGUID == "" || event.AttackerGUID == "world" ||
+		event.VictimGUID == "" || event.AttackerGUID == event.VictimGUID {
+		return nil
+	}
+
+	m := i.matches.get(event.MatchID, now, func() *matchRivalries {
+		return &matchRivalries{kills: make(map[rivalPair]int)}
+	})
+	pair := rivalPair{event.AttackerGUID, event.VictimGUID}
+	m.kills[pair]++
+	if m.kills[pair] != rivalryKills {
+		return nil
+	}
+
+	attacker, victim := sanitizeName(event.AttackerName), sanitizeName(event.VictimName)
+	return []models.Notification{
+		{
+			PlayerGUID: event.AttackerGUID,
+			Kind:       models.NotifyRivalry,
+			Title:      fmt.Sprintf("You have killed %s %d times this match", victim, rivalryKills),
+			Subject:    event.VictimGUID,
+			Value:      rivalryKills,
+			MatchID:    event.MatchID,
+		},
+		{
+			PlayerGUID: event.VictimGUID,
+			Kind:       models.NotifyRivalry,
+			Title:      fmt.Sprintf("%s has killed you %d times this match", attacker, rivalryKills),
+			Subject:    event.AttackerGUID,
+			Value:      rivalryKills,
+			MatchID:    event.MatchID,
+		},
+	}
+}
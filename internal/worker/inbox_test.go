@@ -0,0 +1,92 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// fakeNotifications records the notifications stored
+type fakeNotifications struct {
+	logic.NotificationService
+	mu    sync.Mutex
+	added []models.Notification
+}
+
+func (f *fakeNotifications) Notify(_ context.Context, n *models.Notification) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.added = append(f.added, *n)
+	return 1, nil
+}
+
+func rivalKill(matchID, attacker, victim string) *models.RawEvent {
+	return &models.RawEvent{
+		Type: models.EventPlayerKill, MatchID: matchID,
+		AttackerGUID: attacker, AttackerName: "Hunter", VictimGUID: victim, VictimName: "Prey",
+	}
+}
+
+func TestInboxRivalry(t *testing.T) {
+	inbox := NewInbox(&fakeNotifications{}, zap.NewNop())
+	now := time.Now()
+
+	for i := 1; i < rivalryKills; i++ {
+		if got := inbox.observe(rivalKill("m1", "a", "b"), now); got != nil {
+			t.Fatalf("notified after %d kills, want none below %d", i, rivalryKills)
+		}
+	}
+	got := inbox.observe(rivalKill("m1", "a", "b"), now)
+	if len(got) != 2 {
+		t.Fatalf("notifications at %d kills = %+v, want one per player", rivalryKills, got)
+	}
+	if got[0].PlayerGUID != "a" || got[0].Subject != "b" || got[1].PlayerGUID != "b" || got[1].Subject != "a" {
+		t.Errorf("rivalry notifications = %+v", got)
+	}
+	for _, n := range got {
+		if n.Kind != models.NotifyRivalry || n.MatchID != "m1" || n.Value != rivalryKills {
+			t.Errorf("notification = %+v", n)
+		}
+	}
+	if again := inbox.observe(rivalKill("m1", "a", "b"), now); again != nil {
+		t.Error("rivalry notified twice in one match")
+	}
+	if other := inbox.observe(rivalKill("m1", "b", "a"), now); other != nil {
+		t.Error("kills counted for the wrong direction")
+	}
+
+	// match_end forgets the match
+	inbox.observe(&models.RawEvent{Type: models.EventMatchEnd, MatchID: "m1"}, now)
+	if inbox.matches.len() != 0 {
+		t.Error("match kept after match_end")
+	}
+	// Suicides and world kills are no rivalry
+	for i := 0; i < rivalryKills; i++ {
+		if got := inbox.observe(rivalKill("m2", "a", "a"), now); got != nil {
+			t.Fatal("suicide notified as a rivalry")
+		}
+		if got := inbox.observe(rivalKill("m2", "world", "a"), now); got != nil {
+			t.Fatal("world kill notified as a rivalry")
+		}
+	}
+
+	var nilInbox *Inbox
+	nilInbox.Observe(rivalKill("m1", "a", "b"), now)
+	nilInbox.Push(models.Notification{})
+}
+
+func TestInboxStore(t *testing.T) {
+	svc := &fakeNotifications{}
+	inbox := NewInbox(svc, zap.NewNop())
+
+	inbox.store(context.Background(), &models.Notification{ForumUserID: 7, Kind: models.NotifyAchievement, Title: "Sharpshooter"})
+	if len(svc.added) != 1 || svc.added[0].ForumUserID != 7 {
+		t.Errorf("stored = %+v", svc.added)
+	}
+}
@@ -0,0 +1,103 @@
+package worker
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+var (
+	ingestLagSeconds = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mohaa_ingest_lag_seconds",
+		Help: "Seconds since the newest non-heartbeat event received from each active server",
+	}, []string{"server_id", "server_name"})
+
+	ingestStalledServers = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "mohaa_ingest_stalled_servers",
+		Help: "Servers still heartbeating whose event stream has stopped",
+	})
+)
+
+// IngestHealthSource computes per-server ingest lag
+type IngestHealthSource interface {
+	GetIngestHealth(ctx context.Context, stallThreshold time.Duration) (*models.IngestHealth, error)
+}
+
+// IngestLagReporter refreshes the ingest lag gauges on a fixed interval
+type IngestLagReporter struct {
+	source    IngestHealthSource
+	interval  time.Duration
+	threshold atomic.Int64
+	logger    *zap.SugaredLogger
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+func NewIngestLagReporter(source IngestHealthSource, interval, threshold time.Duration, logger *zap.Logger) *IngestLagReporter {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	r := &IngestLagReporter{
+		source:   source,
+		interval: interval,
+		logger:   logger.Sugar(),
+		done:     make(chan struct{}),
+	}
+	r.threshold.Store(int64(threshold))
+	return r
+}
+
+// SetThreshold changes the stall threshold used from the next refresh
+func (r *IngestLagReporter) SetThreshold(threshold time.Duration) {
+	r.threshold.Store(int64(threshold))
+}
+
+func (r *IngestLagReporter) Start(ctx context.Context) {
+	ctx, r.cancel = context.WithCancel(ctx)
+	go func() {
+		defer close(r.done)
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		r.refresh(ctx)
+		for {
+			select {
+			case <-ticker.C:
+				r.refresh(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (r *IngestLagReporter) Stop() {
+	if r.cancel != nil {
+		r.cancel()
+		<-r.done
+	}
+}
+
+func (r *IngestLagReporter) refresh(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, r.interval)
+	defer cancel()
+
+	health, err := r.source.GetIngestHealth(ctx, time.Duration(r.threshold.Load()))
+	if err != nil {
+		r.logger.Warnw("Failed to refresh ingest lag", "error", err)
+		return
+	}
+
+	// Reset so deactivated servers don't keep exporting a stale lag
+	ingestLagSeconds.Reset()
+	for _, srv := range health.Servers {
+		ingestLagSeconds.WithLabelValues(srv.ServerID, srv.Name).Set(srv.IngestLagSeconds)
+	}
+	ingestStalledServers.Set(float64(health.Stalled))
+}
@@ -0,0 +1,594 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/db"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+var (
+	streamPublished = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "mohaa_ingest_stream_published_total",
+		Help: "Events added to the shared ingest stream by this API node",
+	})
+	streamPublishErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "mohaa_ingest_stream_publish_errors_total",
+		Help: "Failed writes of event batches to the ingest stream, retried",
+	})
+	streamConsumed = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "mohaa_ingest_stream_consumed_total",
+		Help: "Events read from the ingest stream and queued on this worker node",
+	})
+	streamPartitionsOwned = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "mohaa_ingest_stream_partitions_owned",
+		Help: "Ingest stream partitions this worker node consumes",
+	})
+)
+
+const (
+	// ingestStreamGroup is the consumer group every worker node reads in
+	ingestStreamGroup = "ingest-workers"
+	// ingestConsumersKey holds each consuming node's last heartbeat
+	ingestConsumersKey = "ingest:consumers"
+
+	// streamPublishBuffer is how many events an API node holds for the
+	// stream before refusing more, as a full worker pool queue would
+	streamPublishBuffer = 10000
+	streamPublishBatch  = 500
+	streamReadCount     = 500
+	streamReadBlock     = 2 * time.Second
+	streamRetryDelay    = time.Second
+	// streamAckInterval is how often stored entries are acknowledged
+	streamAckInterval = time.Second
+)
+
+// IngestStreamConfig describes the shared ingest stream of a split
+// deployment. It is partitioned by server so one worker node sees all of a
+// server's events, in order, as the pool's per-match state needs.
+type IngestStreamConfig struct {
+	// Stream is the key prefix; partition n is the Redis stream Stream:n
+	Stream     string
+	Partitions int
+	// MaxLen caps each partition, approximately; older entries are trimmed
+	// whether consumed or not. 0 keeps everything.
+	MaxLen int64
+}
+
+func (c IngestStreamConfig) key(partition int) string {
+	return c.Stream + ":" + strconv.Itoa(partition)
+}
+
+// partition maps an event to its partition by server, or by match for
+// events without one
+func (c IngestStreamConfig) partition(event *models.RawEvent) int {
+	key := event.ServerID
+	if key == "" {
+		key = event.MatchID
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(c.Partitions))
+}
+
+// streamMessage is one event as stored in the stream. Tenant and sandbox are
+// not part of the event's JSON, which never takes them from a payload.
+func streamMessage(event *models.RawEvent) (map[string]any, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	sandbox := "0"
+	if event.Sandbox {
+		sandbox = "1"
+	}
+	return map[string]any{"event": data, "tenant": event.TenantID, "sandbox": sandbox}, nil
+}
+
+func parseStreamMessage(values map[string]any) (*models.RawEvent, error) {
+	data, _ := values["event"].(string)
+	var event models.RawEvent
+	if err := json.Unmarshal([]byte(data), &event); err != nil {
+		return nil, err
+	}
+	event.TenantID, _ = values["tenant"].(string)
+	event.Sandbox = values["sandbox"] == "1"
+	return &event, nil
+}
+
+type streamEntry struct {
+	partition int
+	values    map[string]any
+}
+
+// StreamPublisher queues ingested events on the shared stream instead of a
+// local worker pool, for API nodes that only accept and validate events.
+// Events are buffered and written in pipelined batches; a failed write is
+// retried until it succeeds or the publisher stops.
+type StreamPublisher struct {
+	client  *redis.Client
+	config  IngestStreamConfig
+	logger  *zap.SugaredLogger
+	entries chan streamEntry
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+func NewStreamPublisher(client *redis.Client, cfg IngestStreamConfig, logger *zap.Logger) *StreamPublisher {
+	return &StreamPublisher{
+		client:  client,
+		config:  cfg,
+		logger:  logger.Sugar(),
+		entries: make(chan streamEntry, streamPublishBuffer),
+		done:    make(chan struct{}),
+	}
+}
+
+// Enqueue buffers the event for the stream, reporting false when the buffer
+// is full
+func (p *StreamPublisher) Enqueue(event *models.RawEvent) bool {
+	values, err := streamMessage(event)
+	if err != nil {
+		p.logger.Warnw("Failed to encode event for the ingest stream", "type", event.Type, "error", err)
+		return false
+	}
+	select {
+	case p.entries <- streamEntry{partition: p.config.partition(event), values: values}:
+		return true
+	default:
+		eventsLoadShed.Inc()
+		return false
+	}
+}
+
+// QueueDepth is the number of events not yet written to the stream
+func (p *StreamPublisher) QueueDepth() int {
+	return len(p.entries)
+}
+
+// Start writes buffered events until Stop
+func (p *StreamPublisher) Start(ctx context.Context) {
+	ctx, p.cancel = context.WithCancel(ctx)
+	go func() {
+		defer close(p.done)
+		for {
+			select {
+			case entry := <-p.entries:
+				p.publish(ctx, p.collect(entry))
+			case <-ctx.Done():
+				select {
+				case entry := <-p.entries:
+					p.drain(p.collect(entry))
+				default:
+				}
+				return
+			}
+		}
+	}()
+}
+
+// Stop writes what is still buffered, giving up after a few seconds
+func (p *StreamPublisher) Stop() {
+	if p.cancel != nil {
+		p.cancel()
+		<-p.done
+	}
+}
+
+// collect adds whatever else is buffered to first, up to a batch
+func (p *StreamPublisher) collect(first streamEntry) []streamEntry {
+	batch := []streamEntry{first}
+	for len(batch) < streamPublishBatch {
+		select {
+		case entry := <-p.entries:
+			batch = append(batch, entry)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+// publish writes a batch, retrying until it succeeds or ctx ends
+func (p *StreamPublisher) publish(ctx context.Context, batch []streamEntry) {
+	for {
+		err := p.write(ctx, batch)
+		if err == nil {
+			return
+		}
+		streamPublishErrors.Inc()
+		if ctx.Err() == nil {
+			p.logger.Warnw("Failed to write events to the ingest stream, retrying", "events", len(batch), "error", err)
+			select {
+			case <-time.After(streamRetryDelay):
+				continue
+			case <-ctx.Done():
+			}
+		}
+		// Stopping: one last try with a deadline of its own
+		p.drain(batch)
+		return
+	}
+}
+
+// drain writes batch and whatever is still buffered on shutdown, giving up
+// after a few seconds
+func (p *StreamPublisher) drain(batch []streamEntry) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for len(batch) > 0 {
+		if err := p.write(ctx, batch); err != nil {
+			p.logger.Errorw("Dropped events on shutdown, ingest stream unwritable", "events", len(batch)+len(p.entries), "error", err)
+			return
+		}
+		batch = nil
+		select {
+		case entry := <-p.entries:
+			batch = p.collect(entry)
+		default:
+		}
+	}
+}
+
+func (p *StreamPublisher) write(ctx context.Context, batch []streamEntry) error {
+	pipe := p.client.Pipeline()
+	for _, entry := range batch {
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: p.config.key(entry.partition),
+			MaxLen: p.config.MaxLen,
+			Approx: p.config.MaxLen > 0,
+			Values: entry.values,
+		})
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+	streamPublished.Add(float64(len(batch)))
+	return nil
+}
+
+// StoredEventQueue queues events and reports when each one is stored
+type StoredEventQueue interface {
+	EnqueueStored(event *models.RawEvent, stored func()) bool
+}
+
+// StreamConsumer feeds events from the shared stream to this node's worker
+// pool. Worker nodes share the partitions out between them: each owns a fair
+// share under a db.Lock, renewed at a third of its TTL, and gives surplus
+// partitions back when more nodes join. Entries are acknowledged once the
+// pool has stored them in ClickHouse, so those of a node that dies, or of a
+// failed batch, are read again by the partition's next owner: delivery is at
+// least once.
+type StreamConsumer struct {
+	client   *redis.Client
+	locks    db.LiveStateStore
+	queue    StoredEventQueue
+	config   IngestStreamConfig
+	leaseTTL time.Duration
+	logger   *zap.SugaredLogger
+
+	mu     sync.Mutex
+	owned  map[int]*partitionReader
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+type partitionReader struct {
+	lock   *db.Lock
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewStreamConsumer(client *redis.Client, locks db.LiveStateStore, queue StoredEventQueue, cfg IngestStreamConfig, leaseTTL time.Duration, logger *zap.Logger) *StreamConsumer {
+	return &StreamConsumer{
+		client:   client,
+		locks:    locks,
+		queue:    queue,
+		config:   cfg,
+		leaseTTL: leaseTTL,
+		logger:   logger.Sugar(),
+		owned:    make(map[int]*partitionReader),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start creates the consumer groups, then balances partitions right away
+// and every third of the lease TTL until Stop
+func (c *StreamConsumer) Start(ctx context.Context) error {
+	for n := 0; n < c.config.Partitions; n++ {
+		err := c.client.XGroupCreateMkStream(ctx, c.config.key(n), ingestStreamGroup, "0").Err()
+		if err != nil && !redis.HasErrorPrefix(err, "BUSYGROUP") {
+			return fmt.Errorf("failed to create ingest consumer group: %w", err)
+		}
+	}
+
+	ctx, c.cancel = context.WithCancel(ctx)
+	go func() {
+		defer close(c.done)
+		c.balance(ctx)
+
+		ticker := time.NewTicker(c.leaseTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.balance(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop stops reading and gives every partition back
+func (c *StreamConsumer) Stop() {
+	if c.cancel == nil {
+		return
+	}
+	c.cancel()
+	<-c.done
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	owned := make([]int, 0, len(c.owned))
+	for n := range c.owned {
+		owned = append(owned, n)
+	}
+	c.releaseAll(ctx, owned)
+	c.locks.HDel(ctx, ingestConsumersKey, db.InstanceID)
+}
+
+// Partitions lists the partitions this node consumes
+func (c *StreamConsumer) Partitions() []int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	owned := make([]int, 0, len(c.owned))
+	for n := range c.owned {
+		owned = append(owned, n)
+	}
+	sort.Ints(owned)
+	return owned
+}
+
+// balance renews the partitions this node owns and takes or gives back
+// partitions until it owns its fair share
+func (c *StreamConsumer) balance(ctx context.Context) {
+	nodes, err := c.heartbeat(ctx)
+	if err != nil {
+		c.logger.Warnw("Failed to register ingest consumer", "error", err)
+		return
+	}
+	share := fairShare(c.config.Partitions, nodes)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer func() { streamPartitionsOwned.Set(float64(len(c.owned))) }()
+
+	for n, r := range c.owned {
+		if ok, err := r.lock.Refresh(ctx); err != nil || !ok {
+			c.logger.Warnw("Lost ingest stream partition", "partition", n, "error", err)
+			c.stopReader(n)
+		}
+	}
+	var surplus []int
+	for n := c.config.Partitions - 1; n >= 0 && len(c.owned)-len(surplus) > share; n-- {
+		if _, ok := c.owned[n]; ok {
+			surplus = append(surplus, n)
+		}
+	}
+	c.releaseAll(ctx, surplus)
+	for n := 0; n < c.config.Partitions && len(c.owned) < share; n++ {
+		if _, ok := c.owned[n]; ok {
+			continue
+		}
+		lock := db.NewLock(c.locks, "ingest:partition:"+strconv.Itoa(n), db.InstanceID, c.leaseTTL)
+		if ok, err := lock.TryAcquire(ctx); err != nil || !ok {
+			continue
+		}
+		c.startReader(ctx, n, lock)
+	}
+}
+
+// heartbeat records this node as consuming and returns how many nodes are,
+// forgetting nodes silent for two lease TTLs
+func (c *StreamConsumer) heartbeat(ctx context.Context) (int, error) {
+	now := time.Now()
+	if err := c.locks.HSet(ctx, ingestConsumersKey, db.InstanceID, now.Unix()); err != nil {
+		return 0, err
+	}
+	beats, err := c.locks.HGetAll(ctx, ingestConsumersKey)
+	if err != nil {
+		return 0, err
+	}
+	cutoff := now.Add(-2 * c.leaseTTL).Unix()
+	nodes := 0
+	for instance, ts := range beats {
+		if unix, err := strconv.ParseInt(ts, 10, 64); err == nil && unix >= cutoff {
+			nodes++
+			continue
+		}
+		c.locks.HDel(ctx, ingestConsumersKey, instance)
+	}
+	return nodes, nil
+}
+
+// fairShare is the most partitions one of nodes consuming nodes takes
+func fairShare(partitions, nodes int) int {
+	if nodes < 1 {
+		nodes = 1
+	}
+	return (partitions + nodes - 1) / nodes
+}
+
+// startReader starts consuming partition n. Caller holds mu.
+func (c *StreamConsumer) startReader(ctx context.Context, n int, lock *db.Lock) {
+	ctx, cancel := context.WithCancel(ctx)
+	r := &partitionReader{lock: lock, cancel: cancel, done: make(chan struct{})}
+	c.owned[n] = r
+	go func() {
+		defer close(r.done)
+		c.read(ctx, n)
+	}()
+	c.logger.Infow("Consuming ingest stream partition", "partition", n)
+}
+
+// stopReader stops consuming partition n. Caller holds mu.
+func (c *StreamConsumer) stopReader(n int) {
+	r := c.owned[n]
+	r.cancel()
+	<-r.done
+	delete(c.owned, n)
+}
+
+// releaseAll stops consuming the partitions and frees their locks. Readers
+// are cancelled together, as each may sit in a blocking read. Caller holds
+// mu.
+func (c *StreamConsumer) releaseAll(ctx context.Context, partitions []int) {
+	for _, n := range partitions {
+		c.owned[n].cancel()
+	}
+	for _, n := range partitions {
+		lock := c.owned[n].lock
+		c.stopReader(n)
+		if err := lock.Release(ctx); err != nil {
+			c.logger.Warnw("Failed to release ingest stream partition", "partition", n, "error", err)
+		}
+	}
+}
+
+// streamAcks collects the IDs of stored entries until they are acknowledged
+type streamAcks struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+func (a *streamAcks) add(id string) {
+	a.mu.Lock()
+	a.ids = append(a.ids, id)
+	a.mu.Unlock()
+}
+
+func (a *streamAcks) take() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	ids := a.ids
+	a.ids = nil
+	return ids
+}
+
+// acknowledge acknowledges stored entries of partition n every
+// streamAckInterval, and once more when ctx ends. Entries stored after that
+// stay pending for the partition's next reader.
+func (c *StreamConsumer) acknowledge(ctx context.Context, n int, acks *streamAcks) {
+	key := c.config.key(n)
+	flush := func() {
+		ids := acks.take()
+		if len(ids) == 0 {
+			return
+		}
+		// Acknowledged even while stopping
+		ackCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := c.client.XAck(ackCtx, key, ingestStreamGroup, ids...).Err(); err != nil {
+			c.logger.Warnw("Failed to acknowledge ingest stream entries", "partition", n, "error", err)
+		}
+	}
+
+	ticker := time.NewTicker(streamAckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			flush()
+		case <-ctx.Done():
+			flush()
+			return
+		}
+	}
+}
+
+// read queues a partition's events on the pool. The partition is read as
+// one consumer whichever node owns it, so a new owner starts with the
+// entries its predecessor read and did not acknowledge.
+func (c *StreamConsumer) read(ctx context.Context, n int) {
+	acks := &streamAcks{}
+	acked := make(chan struct{})
+	go func() {
+		defer close(acked)
+		c.acknowledge(ctx, n, acks)
+	}()
+	defer func() { <-acked }()
+
+	key := c.config.key(n)
+	consumer := "partition-" + strconv.Itoa(n)
+	// "0" reads the consumer's pending entries, then after the last one
+	// read; ">" reads new ones
+	id := "0"
+	for ctx.Err() == nil {
+		streams, err := c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    ingestStreamGroup,
+			Consumer: consumer,
+			Streams:  []string{key, id},
+			Count:    streamReadCount,
+			Block:    streamReadBlock,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.logger.Warnw("Failed to read ingest stream", "partition", n, "error", err)
+			select {
+			case <-time.After(streamRetryDelay):
+			case <-ctx.Done():
+			}
+			continue
+		}
+
+		var messages []redis.XMessage
+		if len(streams) > 0 {
+			messages = streams[0].Messages
+		}
+		if id != ">" {
+			if len(messages) == 0 {
+				id = ">"
+				continue
+			}
+			id = messages[len(messages)-1].ID
+		}
+
+		for _, msg := range messages {
+			event, err := parseStreamMessage(msg.Values)
+			if err != nil {
+				// Never readable; acknowledged so it is not read again
+				c.logger.Warnw("Dropped unreadable ingest stream entry", "partition", n, "id", msg.ID, "error", err)
+				acks.add(msg.ID)
+				continue
+			}
+			msgID := msg.ID
+			if !c.queue.EnqueueStored(event, func() { acks.add(msgID) }) {
+				// The pool is stopping; the rest stays pending
+				break
+			}
+			streamConsumed.Inc()
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestIngestStreamPartition(t *testing.T) {
+	cfg := IngestStreamConfig{Stream: "ingest:events", Partitions: 8}
+	a := &models.RawEvent{ServerID: "srv-1", MatchID: "m1"}
+	b := &models.RawEvent{ServerID: "srv-1", MatchID: "m2"}
+	if cfg.partition(a) != cfg.partition(b) {
+		t.Error("events of one server landed on different partitions")
+	}
+	noServer := &models.RawEvent{MatchID: "m1"}
+	if p := cfg.partition(noServer); p < 0 || p >= cfg.Partitions {
+		t.Errorf("partition %d out of range", p)
+	}
+	if key := cfg.key(3); key != "ingest:events:3" {
+		t.Errorf("key = %q", key)
+	}
+}
+
+func TestStreamMessageRoundTrip(t *testing.T) {
+	event := &models.RawEvent{
+		Type:     models.EventPlayerKill,
+		ServerID: "srv-1",
+		TenantID: "tenant-a",
+		Sandbox:  true,
+	}
+	values, err := streamMessage(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Redis hands every field back as a string
+	read := make(map[string]any, len(values))
+	for k, v := range values {
+		switch v := v.(type) {
+		case []byte:
+			read[k] = string(v)
+		default:
+			read[k] = v
+		}
+	}
+	got, err := parseStreamMessage(read)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Type != event.Type || got.ServerID != "srv-1" || got.TenantID != "tenant-a" || !got.Sandbox {
+		t.Errorf("round trip = %+v", got)
+	}
+	if _, err := parseStreamMessage(map[string]any{"event": "{"}); err == nil {
+		t.Error("parsed a broken entry")
+	}
+}
+
+func TestFairShare(t *testing.T) {
+	tests := []struct{ partitions, nodes, want int }{
+		{16, 1, 16},
+		{16, 3, 6},
+		{16, 4, 4},
+		{2, 5, 1},
+		{16, 0, 16},
+	}
+	for _, tt := range tests {
+		if got := fairShare(tt.partitions, tt.nodes); got != tt.want {
+			t.Errorf("fairShare(%d, %d) = %d, want %d", tt.partitions, tt.nodes, got, tt.want)
+		}
+	}
+}
+
+func TestPoolReportsStoredJobs(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		failTable  string
+		wantStored int
+	}{
+		{"Stored", "", 2},
+		// Entries of a failed batch stay unacknowledged, to be read again
+		{"Insert Failed", "mohaa_stats.raw_events", 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			faults := NewFaults()
+			faults.Set(FaultConfig{RedisDown: true})
+			p := &Pool{
+				config:    PoolConfig{ClickHouse: &insertRecorder{failTable: tt.failTable}, ShardByMatch: true, Faults: faults, BatchSize: 10, FlushInterval: time.Hour},
+				jobQueue:  make(chan Job, 10),
+				logger:    zap.NewNop().Sugar(),
+				liveState: newLiveStateBreaker(LiveStateBreakerConfig{Threshold: 1, BufferSize: 10}),
+			}
+			p.ctx, p.cancel = context.WithCancel(context.Background())
+			defer p.cancel()
+
+			stored := 0
+			for _, job := range jobsOf(models.EventJump, models.EventJump) {
+				job.stored = func() { stored++ }
+				p.jobQueue <- job
+			}
+			close(p.jobQueue)
+			p.wg.Add(1)
+			p.worker(0)
+
+			if stored != tt.wantStored {
+				t.Errorf("stored = %d, want %d", stored, tt.wantStored)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var clickhouseInserts = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "mohaa_clickhouse_inserts_total",
+	Help: "Event batches sent to ClickHouse by table and insert mode",
+}, []string{"table", "mode"})
+
+// InsertMode selects how small batches reach ClickHouse. Every direct insert
+// creates a part, so at a 1s flush interval a quiet server produces a stream
+// of tiny parts for the merges to catch up with.
+type InsertMode string
+
+const (
+	// InsertDirect sends every batch as its own insert
+	InsertDirect InsertMode = "direct"
+	// InsertAsync lets the server collect small inserts (async_insert) and
+	// waits until they are written, so errors still fail the batch
+	InsertAsync InsertMode = "async"
+	// InsertBuffer writes small batches to the <table>_buffer Buffer tables
+	// (migration 009), which flush to the real table in the background
+	InsertBuffer InsertMode = "buffer"
+)
+
+// ParseInsertMode validates a CLICKHOUSE_INSERT_MODE value; empty means direct
+func ParseInsertMode(s string) (InsertMode, error) {
+	switch mode := InsertMode(s); mode {
+	case "", InsertDirect:
+		return InsertDirect, nil
+	case InsertAsync, InsertBuffer:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("insert mode %q: want direct, async or buffer", s)
+	}
+}
+
+// insertTarget returns the context and table for inserting rows into table.
+// Batches of at least SmallBatch rows (busy servers) always go direct: they
+// already make reasonably sized parts, and skipping the buffer keeps them
+// visible immediately.
+func (p *Pool) insertTarget(ctx context.Context, table string, rows int) (context.Context, string) {
+	mode := p.config.InsertMode
+	if mode == "" || rows >= p.config.SmallBatch {
+		mode = InsertDirect
+	}
+	clickhouseInserts.WithLabelValues(table, string(mode)).Inc()
+
+	switch mode {
+	case InsertAsync:
+		return clickhouse.Context(ctx, clickhouse.WithSettings(clickhouse.Settings{
+			"async_insert":          1,
+			"wait_for_async_insert": 1,
+		})), table
+	case InsertBuffer:
+		return ctx, table + "_buffer"
+	default:
+		return ctx, table
+	}
+}
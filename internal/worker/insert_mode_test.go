@@ -0,0 +1,42 @@
+package worker
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseInsertMode(t *testing.T) {
+	for _, s := range []string{"", "direct", "async", "buffer"} {
+		if _, err := ParseInsertMode(s); err != nil {
+			t.Errorf("ParseInsertMode(%q) = %v", s, err)
+		}
+	}
+	if _, err := ParseInsertMode("kafka"); err == nil {
+		t.Error("expected error for unknown mode")
+	}
+}
+
+func TestInsertTarget(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      InsertMode
+		rows      int
+		wantTable string
+	}{
+		{name: "Direct", mode: InsertDirect, rows: 10, wantTable: "mohaa_stats.raw_events"},
+		{name: "Unset", mode: "", rows: 10, wantTable: "mohaa_stats.raw_events"},
+		{name: "Small Batch Buffered", mode: InsertBuffer, rows: 10, wantTable: "mohaa_stats.raw_events_buffer"},
+		{name: "Large Batch Direct", mode: InsertBuffer, rows: 200, wantTable: "mohaa_stats.raw_events"},
+		{name: "Async Keeps Table", mode: InsertAsync, rows: 10, wantTable: "mohaa_stats.raw_events"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Pool{config: PoolConfig{InsertMode: tt.mode, SmallBatch: 200}}
+			_, table := p.insertTarget(context.Background(), "mohaa_stats.raw_events", tt.rows)
+			if table != tt.wantTable {
+				t.Errorf("table = %q, want %q", table, tt.wantTable)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package worker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+var duplicateKills = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "mohaa_duplicate_kills_collapsed_total",
+	Help: "Kill events dropped as duplicates of a kill just seen, by event type",
+}, []string{"event_type"})
+
+// killDedupeRetention is how long a kill is remembered after it arrived, so
+// a duplicate held up in a server's send queue is still recognised
+const killDedupeRetention = time.Minute
+
+// killKey is one death as the duplicates share it
+type killKey struct {
+	typ      models.EventType
+	match    string
+	attacker string
+	victim   string
+}
+
+// seenKill is the event time of the last kill of a key, and when it arrived
+type seenKill struct {
+	clock   float64
+	arrived time.Time
+}
+
+// killDedupe collapses the duplicate kill events lag-compensated hits make
+// some servers send for one death: the same kind of kill of the same victim
+// by the same attacker in one match, within window of each other by event
+// time. Nobody dies twice that fast, as a respawn lies between two deaths.
+type killDedupe struct {
+	window    float64 // seconds
+	mu        sync.Mutex
+	seen      map[killKey]seenKill
+	lastSweep time.Time
+}
+
+// newKillDedupe returns a dedupe over window, or nil (keeping every kill)
+// for a window of zero or less
+func newKillDedupe(window time.Duration) *killDedupe {
+	if window <= 0 {
+		return nil
+	}
+	return &killDedupe{window: window.Seconds(), seen: make(map[killKey]seenKill), lastSweep: time.Now()}
+}
+
+// duplicate reports whether the event repeats a kill seen within the window,
+// counting it when it does; other events are never duplicates
+func (d *killDedupe) duplicate(event *models.RawEvent, now time.Time) bool {
+	if d == nil || event.MatchID == "" || !deathEvents[event.Type] {
+		return false
+	}
+	victim := event.VictimGUID
+	if victim == "" {
+		victim = event.PlayerGUID
+	}
+	if victim == "" {
+		return false
+	}
+	key := killKey{typ: event.Type, match: event.MatchID, attacker: event.AttackerGUID, victim: victim}
+	clock := eventClock(event, now)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.sweep(now)
+
+	if last, ok := d.seen[key]; ok && clock-last.clock <= d.window && last.clock-clock <= d.window {
+		duplicateKills.WithLabelValues(string(event.Type)).Inc()
+		return true
+	}
+	d.seen[key] = seenKill{clock: clock, arrived: now}
+	return false
+}
+
+// sweep forgets kills older than killDedupeRetention, at most once per
+// retention period
+func (d *killDedupe) sweep(now time.Time) {
+	if now.Sub(d.lastSweep) < killDedupeRetention {
+		return
+	}
+	d.lastSweep = now
+	for key, k := range d.seen {
+		if now.Sub(k.arrived) > killDedupeRetention {
+			delete(d.seen, key)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestKillDedupe(t *testing.T) {
+	d := newKillDedupe(500 * time.Millisecond)
+	now := time.Now()
+	kill := func(typ models.EventType, attacker, victim string, ts float64) *models.RawEvent {
+		return &models.RawEvent{Type: typ, MatchID: "m1", AttackerGUID: attacker, VictimGUID: victim, Timestamp: ts}
+	}
+
+	tests := []struct {
+		name  string
+		event *models.RawEvent
+		want  bool
+	}{
+		{name: "First Kill", event: kill(models.EventPlayerKill, "a", "b", 100), want: false},
+		{name: "Repeat", event: kill(models.EventPlayerKill, "a", "b", 100.02), want: true},
+		{name: "Repeat Out Of Order", event: kill(models.EventPlayerKill, "a", "b", 99.7), want: true},
+		{name: "Other Victim", event: kill(models.EventPlayerKill, "a", "c", 100.02), want: false},
+		{name: "Other Kind", event: kill(models.EventPlayerBash, "a", "b", 100.02), want: false},
+		{name: "Next Life", event: kill(models.EventPlayerKill, "a", "b", 112), want: false},
+		{name: "Not A Kill", event: kill(models.EventDamage, "a", "b", 112), want: false},
+		{name: "Suicide", event: &models.RawEvent{Type: models.EventPlayerSuicide, MatchID: "m1", PlayerGUID: "a", Timestamp: 120}, want: false},
+		{name: "Suicide Repeat", event: &models.RawEvent{Type: models.EventPlayerSuicide, MatchID: "m1", PlayerGUID: "a", Timestamp: 120.1}, want: true},
+	}
+	for _, tt := range tests {
+		if got := d.duplicate(tt.event, now); got != tt.want {
+			t.Errorf("%s: duplicate = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Remembered for killDedupeRetention after arriving
+	late := kill(models.EventPlayerKill, "a", "b", 112.1)
+	if !d.duplicate(late, now.Add(killDedupeRetention/2)) {
+		t.Error("delayed repeat not collapsed")
+	}
+	if d.duplicate(kill(models.EventPlayerKill, "a", "b", 112.2), now.Add(3*killDedupeRetention)) {
+		t.Error("repeat collapsed after the kill was forgotten")
+	}
+
+	off := newKillDedupe(0)
+	if off.duplicate(kill(models.EventPlayerKill, "a", "b", 100), now) || off.duplicate(kill(models.EventPlayerKill, "a", "b", 100), now) {
+		t.Error("disabled dedupe collapsed a kill")
+	}
+}
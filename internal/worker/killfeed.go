@@ -0,0 +1,128 @@
+package worker
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/openmohaa/stats-api/internal/db"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// Killfeed keys, kept by the side effects so /integrations/killfeed reads
+// live state only:
+//
+//	match:<id>:streak:<guid>  kills since the player's last death in the match
+//	h2h:<killer>:<victim>     kills of one player on another (PlayerCounters TTL)
+func streakKey(matchID, guid string) string {
+	return "match:" + matchID + ":streak:" + guid
+}
+
+func headToHeadKey(killer, victim string) string {
+	return "h2h:" + killer + ":" + victim
+}
+
+// trackKillfeed queues the streak and head-to-head updates of a kill, team
+// kill or suicide
+func (p *Pool) trackKillfeed(ctx context.Context, pipe db.LiveStatePipeline, event *models.RawEvent) {
+	if event.MatchID == "" {
+		return
+	}
+	switch event.Type {
+	case models.EventPlayerSuicide:
+		for _, guid := range []string{event.PlayerGUID, event.AttackerGUID} {
+			if guid != "" && guid != "world" {
+				pipe.Del(ctx, streakKey(event.MatchID, guid))
+			}
+		}
+		return
+	case models.EventPlayerTeamkill:
+		if event.VictimGUID != "" {
+			pipe.Del(ctx, streakKey(event.MatchID, event.VictimGUID))
+		}
+		return
+	}
+
+	if event.VictimGUID != "" {
+		pipe.Del(ctx, streakKey(event.MatchID, event.VictimGUID))
+	}
+	if event.AttackerGUID == "" || event.AttackerGUID == "world" || event.AttackerGUID == event.VictimGUID {
+		return
+	}
+	key := streakKey(event.MatchID, event.AttackerGUID)
+	pipe.Incr(ctx, key)
+	expireKey(ctx, pipe, key, p.ttl().MatchKeys)
+	if event.VictimGUID != "" {
+		key = headToHeadKey(event.AttackerGUID, event.VictimGUID)
+		pipe.Incr(ctx, key)
+		expireKey(ctx, pipe, key, p.ttl().PlayerCounters)
+	}
+}
+
+// KillRank returns the kill rank (see killRanks) reached with kills, the next
+// one and the kills still needed for it. Both names are empty outside the
+// ranks: no rank yet, or no rank above.
+func KillRank(kills int64) (rank, next string, remaining int64) {
+	var rankAt, nextAt int64
+	for threshold, name := range killRanks {
+		if threshold <= kills && threshold > rankAt {
+			rank, rankAt = name, threshold
+		}
+		if threshold > kills && (nextAt == 0 || threshold < nextAt) {
+			next, nextAt = name, threshold
+		}
+	}
+	if next != "" {
+		remaining = nextAt - kills
+	}
+	return rank, next, remaining
+}
+
+// ReadKillfeed returns the killfeed context of a kill in one live state round
+// trip. The counts cover the events processed so far; a kill still waiting in
+// the ingest queue is not included.
+func ReadKillfeed(ctx context.Context, store db.LiveStateStore, matchID, attacker, victim string) (*models.KillfeedContext, error) {
+	pipe := store.Pipeline()
+	kills := pipe.Get(ctx, "player:"+attacker+":kills")
+	var attackerStreak, victimStreak, won, lost *db.StringResult
+	if matchID != "" {
+		attackerStreak = pipe.Get(ctx, streakKey(matchID, attacker))
+		if victim != "" {
+			victimStreak = pipe.Get(ctx, streakKey(matchID, victim))
+		}
+	}
+	if victim != "" {
+		won = pipe.Get(ctx, headToHeadKey(attacker, victim))
+		lost = pipe.Get(ctx, headToHeadKey(victim, attacker))
+	}
+	if err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	kc := &models.KillfeedContext{
+		MatchID:        matchID,
+		AttackerGUID:   attacker,
+		VictimGUID:     victim,
+		AttackerKills:  intResult(kills),
+		AttackerStreak: intResult(attackerStreak),
+		VictimStreak:   intResult(victimStreak),
+		HeadToHead: models.HeadToHead{
+			AttackerKills: intResult(won),
+			VictimKills:   intResult(lost),
+		},
+	}
+	kc.Rank, kc.NextRank, kc.KillsToNextRank = KillRank(kc.AttackerKills)
+	return kc, nil
+}
+
+// intResult reads a pipelined counter; missing or unreadable keys count as zero
+func intResult(r *db.StringResult) int64 {
+	if r == nil {
+		return 0
+	}
+	val, err := r.Result()
+	if err != nil {
+		return 0
+	}
+	n, _ := strconv.ParseInt(val, 10, 64)
+	return n
+}
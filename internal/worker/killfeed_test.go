@@ -0,0 +1,73 @@
+package worker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openmohaa/stats-api/internal/db"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestKillfeedTracking(t *testing.T) {
+	ctx := context.Background()
+	store := db.NewMemoryLiveState()
+	p := &Pool{config: PoolConfig{LiveState: store, RedisTTL: RedisTTLConfig{}.withDefaults()}}
+	apply := func(events ...*models.RawEvent) {
+		pipe := store.Pipeline()
+		for _, e := range events {
+			p.trackKillfeed(ctx, pipe, e)
+		}
+		if err := pipe.Exec(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	kill := func(attacker, victim string) *models.RawEvent {
+		return &models.RawEvent{Type: models.EventPlayerKill, MatchID: "m1", AttackerGUID: attacker, VictimGUID: victim}
+	}
+
+	apply(kill("a", "b"), kill("a", "b"), kill("a", "c"), kill("b", "a"), kill("b", "a"))
+	store.Set(ctx, "player:b:kills", 98, 0)
+
+	kc, err := ReadKillfeed(ctx, store, "m1", "b", "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kc.AttackerStreak != 2 || kc.VictimStreak != 0 {
+		t.Errorf("streaks = %d/%d, want 2/0", kc.AttackerStreak, kc.VictimStreak)
+	}
+	if kc.HeadToHead.AttackerKills != 2 || kc.HeadToHead.VictimKills != 2 {
+		t.Errorf("head to head = %+v, want 2-2", kc.HeadToHead)
+	}
+	if kc.Rank != "" || kc.NextRank != "Corporal" || kc.KillsToNextRank != 2 {
+		t.Errorf("rank = %q -> %q in %d", kc.Rank, kc.NextRank, kc.KillsToNextRank)
+	}
+
+	// A suicide ends the streak; other matches have their own
+	apply(&models.RawEvent{Type: models.EventPlayerSuicide, MatchID: "m1", PlayerGUID: "b"})
+	if kc, _ := ReadKillfeed(ctx, store, "m1", "b", ""); kc.AttackerStreak != 0 {
+		t.Errorf("streak after suicide = %d", kc.AttackerStreak)
+	}
+	if kc, _ := ReadKillfeed(ctx, store, "m2", "a", "b"); kc.AttackerStreak != 0 || kc.HeadToHead.AttackerKills != 2 {
+		t.Errorf("other match = %+v", kc)
+	}
+}
+
+func TestKillRank(t *testing.T) {
+	cases := []struct {
+		kills     int64
+		rank      string
+		next      string
+		remaining int64
+	}{
+		{0, "", "Corporal", 100},
+		{100, "Corporal", "Sergeant", 150},
+		{4999, "Captain", "Major", 1},
+		{30000, "General", "", 0},
+	}
+	for _, c := range cases {
+		rank, next, remaining := KillRank(c.kills)
+		if rank != c.rank || next != c.next || remaining != c.remaining {
+			t.Errorf("KillRank(%d) = %q, %q, %d; want %q, %q, %d", c.kills, rank, next, remaining, c.rank, c.next, c.remaining)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+var leaderboardSnapshotsTaken = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "mohaa_leaderboard_snapshots_total",
+	Help: "Daily leaderboard snapshots by result (taken, failed)",
+}, []string{"result"})
+
+// LeaderboardSnapshotScheduler stores the leaderboard standings of each
+// finished UTC day once, on the highlights schedule, for the rank changes
+// endpoint
+type LeaderboardSnapshotScheduler struct {
+	svc    logic.LeaderboardSnapshotService
+	logger *zap.SugaredLogger
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewLeaderboardSnapshotScheduler(svc logic.LeaderboardSnapshotService, logger *zap.Logger) *LeaderboardSnapshotScheduler {
+	return &LeaderboardSnapshotScheduler{
+		svc:    svc,
+		logger: logger.Sugar(),
+		done:   make(chan struct{}),
+	}
+}
+
+// Start checks right away, then every highlightsInterval
+func (s *LeaderboardSnapshotScheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+	go func() {
+		defer close(s.done)
+		s.RunOnce(ctx, time.Now())
+
+		ticker := time.NewTicker(highlightsInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				s.RunOnce(ctx, now)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (s *LeaderboardSnapshotScheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+		<-s.done
+	}
+}
+
+// RunOnce snapshots the last finished day if it has no snapshots yet
+func (s *LeaderboardSnapshotScheduler) RunOnce(ctx context.Context, now time.Time) {
+	day := lastFinishedPeriod(models.HighlightDay, now)
+	taken, err := s.svc.Taken(ctx, day)
+	if err != nil {
+		s.logger.Warnw("Failed to check leaderboard snapshots", "error", err)
+		return
+	}
+	if taken {
+		return
+	}
+	if err := s.svc.Snapshot(ctx, day); err != nil {
+		leaderboardSnapshotsTaken.WithLabelValues("failed").Inc()
+		s.logger.Errorw("Failed to snapshot leaderboards", "day", day.Format("2006-01-02"), "error", err)
+		return
+	}
+	leaderboardSnapshotsTaken.WithLabelValues("taken").Inc()
+	s.logger.Infow("Leaderboards snapshotted", "day", day.Format("2006-01-02"))
+}
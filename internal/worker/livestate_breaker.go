@@ -0,0 +1,184 @@
+package worker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+var (
+	liveStateCircuitOpen = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "mohaa_live_state_circuit_open",
+		Help: "1 while the live state circuit breaker is open and Redis side effects are skipped",
+	})
+
+	liveStateBuffered = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "mohaa_live_state_buffered_events",
+		Help: "Critical live state updates held locally until Redis recovers",
+	})
+
+	liveStateDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "mohaa_live_state_dropped_total",
+		Help: "Live state updates lost during a Redis outage by reason",
+	}, []string{"reason"})
+)
+
+// LiveStateBreakerConfig tunes how the pool rides out Redis outages.
+// Zero values use the defaults applied in NewPool.
+type LiveStateBreakerConfig struct {
+	// Threshold consecutive failed batches open the circuit
+	Threshold int
+	// Cooldown is how long the circuit stays open before a batch probes Redis
+	Cooldown time.Duration
+	// BufferSize caps the critical updates kept while the circuit is open
+	BufferSize int
+}
+
+func (c LiveStateBreakerConfig) withDefaults() LiveStateBreakerConfig {
+	if c.Threshold <= 0 {
+		c.Threshold = 5
+	}
+	if c.Cooldown <= 0 {
+		c.Cooldown = 10 * time.Second
+	}
+	if c.BufferSize <= 0 {
+		c.BufferSize = 10000
+	}
+	return c
+}
+
+// criticalLiveState lists the events whose Redis updates make up the live
+// match view (who is playing, on which team, whether the match is running).
+// They are buffered during an outage and replayed in order on recovery.
+// Counters and chat claims are dropped instead: replaying increments late
+// would double count after a partially applied pipeline.
+var criticalLiveState = map[models.EventType]bool{
+	models.EventMatchStart:     true,
+	models.EventMatchEnd:       true,
+	models.EventHeartbeat:      true,
+	models.EventConnect:        true,
+	models.EventDisconnect:     true,
+	models.EventTeamJoin:       true,
+	models.EventPlayerSpawn:    true,
+	models.EventTeamWin:        true,
+	models.EventSpectatorJoin:  true,
+	models.EventSpectatorLeave: true,
+}
+
+// LiveStateStatus reports whether side effects run degraded
+type LiveStateStatus struct {
+	Degraded bool       `json:"degraded"`
+	Since    *time.Time `json:"since,omitempty"`
+	Failures int        `json:"consecutive_failures"`
+	Buffered int        `json:"buffered"`
+	Dropped  int64      `json:"dropped"`
+}
+
+// liveStateBreaker is a circuit breaker in front of the pool's Redis side
+// effects. Once open, batches skip Redis until the cooldown ends; the next
+// batch then probes it, closing the circuit on success.
+type liveStateBreaker struct {
+	cfg LiveStateBreakerConfig
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time // zero while closed; reset by each failed probe
+	since    time.Time // when the circuit opened
+	probing  bool
+	buffer   []*models.RawEvent
+	dropped  int64
+}
+
+func newLiveStateBreaker(cfg LiveStateBreakerConfig) *liveStateBreaker {
+	return &liveStateBreaker{cfg: cfg.withDefaults()}
+}
+
+// allow reports whether a batch may talk to Redis. While open it lets a single
+// probe through per cooldown.
+func (b *liveStateBreaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.openedAt.IsZero() {
+		return true
+	}
+	if b.probing || now.Sub(b.openedAt) < b.cfg.Cooldown {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// failure records a failed batch and reports whether it opened the circuit
+func (b *liveStateBreaker) failure(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if !b.openedAt.IsZero() {
+		// Failed probe: wait another cooldown
+		b.openedAt = now
+		b.probing = false
+		return false
+	}
+	if b.failures < b.cfg.Threshold {
+		return false
+	}
+	b.openedAt, b.since = now, now
+	liveStateCircuitOpen.Set(1)
+	return true
+}
+
+// success records a working batch and hands back the buffered updates to
+// replay. closed reports whether it closed an open circuit.
+func (b *liveStateBreaker) success() (replay []*models.RawEvent, closed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	closed = !b.openedAt.IsZero()
+	b.openedAt = time.Time{}
+	b.probing = false
+	replay, b.buffer = b.buffer, nil
+	liveStateCircuitOpen.Set(0)
+	liveStateBuffered.Set(0)
+	return replay, closed
+}
+
+// hold keeps the critical updates of a batch that could not reach Redis and
+// counts the rest as dropped. When the buffer is full new updates are dropped;
+// the oldest carry the match starts the later ones depend on.
+func (b *liveStateBreaker) hold(batch []Job) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, job := range batch {
+		switch {
+		case !criticalLiveState[job.Event.Type]:
+			liveStateDropped.WithLabelValues("non_critical").Inc()
+			b.dropped++
+		case len(b.buffer) >= b.cfg.BufferSize:
+			liveStateDropped.WithLabelValues("buffer_full").Inc()
+			b.dropped++
+		default:
+			b.buffer = append(b.buffer, job.Event)
+		}
+	}
+	liveStateBuffered.Set(float64(len(b.buffer)))
+}
+
+func (b *liveStateBreaker) status() LiveStateStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := LiveStateStatus{
+		Degraded: !b.openedAt.IsZero(),
+		Failures: b.failures,
+		Buffered: len(b.buffer),
+		Dropped:  b.dropped,
+	}
+	if s.Degraded {
+		since := b.since
+		s.Since = &since
+	}
+	return s
+}
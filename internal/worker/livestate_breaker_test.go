@@ -0,0 +1,82 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func jobsOf(types ...models.EventType) []Job {
+	jobs := make([]Job, len(types))
+	for i, t := range types {
+		jobs[i] = Job{Event: &models.RawEvent{Type: t}}
+	}
+	return jobs
+}
+
+func TestLiveStateBreaker(t *testing.T) {
+	b := newLiveStateBreaker(LiveStateBreakerConfig{Threshold: 2, Cooldown: time.Minute, BufferSize: 2})
+	now := time.Now()
+
+	if !b.allow(now) {
+		t.Fatal("closed circuit rejected a batch")
+	}
+	if b.failure(now) {
+		t.Fatal("circuit opened below the threshold")
+	}
+	if !b.failure(now) || !b.status().Degraded {
+		t.Fatal("circuit did not open at the threshold")
+	}
+
+	if b.allow(now.Add(30 * time.Second)) {
+		t.Error("open circuit allowed a batch during the cooldown")
+	}
+	if !b.allow(now.Add(time.Minute)) {
+		t.Fatal("no probe after the cooldown")
+	}
+	if b.allow(now.Add(time.Minute)) {
+		t.Error("second probe allowed while the first is running")
+	}
+
+	// A failed probe waits another cooldown
+	b.failure(now.Add(time.Minute))
+	if b.allow(now.Add(90 * time.Second)) {
+		t.Error("probe allowed right after a failed probe")
+	}
+	if !b.allow(now.Add(2 * time.Minute)) {
+		t.Fatal("no probe after the second cooldown")
+	}
+
+	if _, closed := b.success(); !closed {
+		t.Error("successful probe did not report closing the circuit")
+	}
+	if status := b.status(); status.Degraded || status.Since != nil {
+		t.Errorf("still degraded after recovery: %+v", status)
+	}
+}
+
+func TestLiveStateBreakerHold(t *testing.T) {
+	b := newLiveStateBreaker(LiveStateBreakerConfig{Threshold: 1, BufferSize: 2})
+
+	b.hold(jobsOf(models.EventMatchStart, models.EventPlayerKill, models.EventConnect, models.EventTeamJoin))
+	status := b.status()
+	if status.Buffered != 2 {
+		t.Errorf("buffered = %d, want 2", status.Buffered)
+	}
+	// The kill is not critical and team_join overflows the buffer
+	if status.Dropped != 2 {
+		t.Errorf("dropped = %d, want 2", status.Dropped)
+	}
+
+	replay, closed := b.success()
+	if closed {
+		t.Error("success reported closing a circuit that never opened")
+	}
+	if len(replay) != 2 || replay[0].Type != models.EventMatchStart || replay[1].Type != models.EventConnect {
+		t.Errorf("replay = %v, want match_start then connect in order", replay)
+	}
+	if b.status().Buffered != 0 {
+		t.Error("buffer not emptied by replay")
+	}
+}
@@ -0,0 +1,53 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// winnerKey holds the team a team_win event declared, read back at match_end
+func winnerKey(matchID string) string {
+	return "match:" + matchID + ":winner"
+}
+
+// ffaOutcomes decodes a live free-for-all scoreboard (GUID -> RosterEntry
+// JSON) and gives its top scorer the win. Unreadable entries are skipped.
+func ffaOutcomes(board map[string]string) map[string]uint8 {
+	entries := make(map[string]models.RosterEntry, len(board))
+	for guid, data := range board {
+		var entry models.RosterEntry
+		if json.Unmarshal([]byte(data), &entry) == nil {
+			entries[guid] = entry
+		}
+	}
+	return logic.FFAOutcomes(entries)
+}
+
+// finalWinner reads what the match recorded while live (team_win, heartbeat
+// scores) and decides the winner for a match_end event. Scores the match_end
+// carries replace the heartbeat ones.
+func (p *Pool) finalWinner(ctx context.Context, event *models.RawEvent, live *models.LiveMatch) string {
+	recorded, _ := p.config.LiveState.HGet(ctx, winnerKey(event.MatchID), "team")
+
+	allies, axis := logic.EventScores(event)
+	if allies == 0 && axis == 0 && live != nil {
+		allies, axis = live.AlliesScore, live.AxisScore
+	}
+	return logic.MatchWinner(logic.EventWinner(event), logic.NormalizeTeam(recorded), allies, axis)
+}
+
+// liveMatch returns the match's live state, or nil when there is none
+func (p *Pool) liveMatch(ctx context.Context, matchID string) *models.LiveMatch {
+	data, err := p.config.LiveState.HGet(ctx, "live_matches", matchID)
+	if err != nil {
+		return nil
+	}
+	var lm models.LiveMatch
+	if json.Unmarshal([]byte(data), &lm) != nil {
+		return nil
+	}
+	return &lm
+}
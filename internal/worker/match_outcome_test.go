@@ -0,0 +1,81 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/openmohaa/stats-api/internal/db"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestLiveWinnerPipeline(t *testing.T) {
+	ctx := context.Background()
+	store := db.NewMemoryLiveState()
+	p := &Pool{config: PoolConfig{LiveState: store, RedisTTL: RedisTTLConfig{}.withDefaults()}}
+
+	startMatch := func(id string, allies, axis int) {
+		data, _ := json.Marshal(models.LiveMatch{MatchID: id, AlliesScore: allies, AxisScore: axis})
+		store.HSet(ctx, "live_matches", id, data)
+	}
+	end := func(id string) string {
+		event := &models.RawEvent{Type: models.EventMatchEnd, MatchID: id}
+		return p.finalWinner(ctx, event, p.liveMatch(ctx, id))
+	}
+
+	// team_win is recorded and read back at match_end, with its scores
+	startMatch("m1", 0, 0)
+	p.handleTeamWin(ctx, &models.RawEvent{Type: models.EventTeamWin, MatchID: "m1", WinningTeam: "american", AlliedScore: 5, AxisScore: 3})
+	if got := end("m1"); got != "allies" {
+		t.Errorf("m1 winner = %q, want allies", got)
+	}
+	if live := p.liveMatch(ctx, "m1"); live == nil || live.AlliesScore != 5 || live.AxisScore != 3 {
+		t.Errorf("m1 live scores = %+v, want 5-3", live)
+	}
+
+	// Without team_win the heartbeat scores decide
+	startMatch("m2", 2, 6)
+	if got := end("m2"); got != "axis" {
+		t.Errorf("m2 winner = %q, want axis", got)
+	}
+
+	// The match_end event's own winner wins over both
+	startMatch("m3", 9, 0)
+	p.handleTeamWin(ctx, &models.RawEvent{Type: models.EventTeamWin, MatchID: "m3", WinningTeam: "allies"})
+	event := &models.RawEvent{Type: models.EventMatchEnd, MatchID: "m3", Winner: "axis"}
+	if got := p.finalWinner(ctx, event, p.liveMatch(ctx, "m3")); got != "axis" {
+		t.Errorf("m3 winner = %q, want axis", got)
+	}
+}
+
+func TestFFAOutcomes(t *testing.T) {
+	entry := func(team string, score int) string {
+		data, _ := json.Marshal(models.RosterEntry{Team: team, Score: score})
+		return string(data)
+	}
+
+	got := ffaOutcomes(map[string]string{
+		"a": entry("freeforall", 12),
+		"b": entry("freeforall", 30),
+		"c": entry("", 5),
+		"d": entry("spectator", 99),
+		"e": "not json",
+	})
+	want := map[string]uint8{"a": 0, "b": 1, "c": 0}
+	if len(got) != len(want) {
+		t.Fatalf("ffaOutcomes() = %v, want %v", got, want)
+	}
+	for guid, outcome := range want {
+		if got[guid] != outcome {
+			t.Errorf("outcome of %s = %d, want %d", guid, got[guid], outcome)
+		}
+	}
+
+	// A shared top score is a draw
+	for guid, outcome := range ffaOutcomes(map[string]string{"a": entry("", 7), "b": entry("", 7), "c": entry("", 1)}) {
+		if outcome != 0 {
+			t.Errorf("tied outcome of %s = %d, want 0", guid, outcome)
+		}
+	}
+
+}
@@ -0,0 +1,167 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/db"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// reconciledMatchReason marks the match_end events the reconciler synthesizes
+const reconciledMatchReason = "reconciled"
+
+var matchesReconciled = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "mohaa_live_matches_reconciled_total",
+	Help: "Live matches ended by the reconciler after their server stopped sending heartbeats",
+})
+
+// EventQueue accepts events for processing (the worker pool)
+type EventQueue interface {
+	Enqueue(event *models.RawEvent) bool
+}
+
+// ReconcilerConfig controls the live match reconciler
+type ReconcilerConfig struct {
+	Interval time.Duration
+	// StaleAfter is how long a live match may go without a heartbeat before
+	// it is ended. Keep it below the janitor's LiveIdleTTL, which drops stale
+	// matches without ending them.
+	StaleAfter time.Duration
+}
+
+// MatchReconciler ends live matches whose server died or lost its match_end,
+// e.g. across an API restart. It queues a synthetic match_end so the match
+// takes the normal path: outcomes are recorded and live state is cleared.
+type MatchReconciler struct {
+	state  db.LiveStateStore
+	queue  EventQueue
+	config ReconcilerConfig
+	leader *db.Leader
+	logger *zap.SugaredLogger
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewMatchReconciler(state db.LiveStateStore, queue EventQueue, cfg ReconcilerConfig, logger *zap.Logger) *MatchReconciler {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Minute
+	}
+	if cfg.StaleAfter <= 0 {
+		cfg.StaleAfter = 5 * time.Minute
+	}
+	return &MatchReconciler{
+		state:  state,
+		queue:  queue,
+		config: cfg,
+		logger: logger.Sugar(),
+		done:   make(chan struct{}),
+	}
+}
+
+// SetLeader makes only the instance leading l reconcile, so replicas do not
+// each queue a match_end for the same stale match. Call before Start.
+func (r *MatchReconciler) SetLeader(l *db.Leader) {
+	r.leader = l
+}
+
+// Start reconciles once right away, then every Interval
+func (r *MatchReconciler) Start(ctx context.Context) {
+	ctx, r.cancel = context.WithCancel(ctx)
+	go func() {
+		defer close(r.done)
+		r.RunOnce(ctx)
+
+		ticker := time.NewTicker(r.config.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				r.RunOnce(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (r *MatchReconciler) Stop() {
+	if r.cancel != nil {
+		r.cancel()
+		<-r.done
+	}
+}
+
+// RunOnce ends every live match without a heartbeat for StaleAfter and
+// returns how many it queued. It does nothing on an instance that does not
+// lead.
+func (r *MatchReconciler) RunOnce(ctx context.Context) int {
+	if r.leader != nil && !r.leader.IsLeader() {
+		return 0
+	}
+	live, err := r.state.HGetAll(ctx, "live_matches")
+	if err != nil {
+		r.logger.Warnw("Reconciler failed to read live matches", "error", err)
+		return 0
+	}
+	seen, err := r.state.HGetAll(ctx, liveMatchesSeenKey)
+	if err != nil {
+		r.logger.Warnw("Reconciler failed to read heartbeat times", "error", err)
+		return 0
+	}
+
+	now := time.Now()
+	cutoff := now.Add(-r.config.StaleAfter)
+	ended := 0
+
+	for matchID, data := range live {
+		var match models.LiveMatch
+		if err := json.Unmarshal([]byte(data), &match); err != nil {
+			continue
+		}
+		lastSeen := match.StartedAt
+		if ts, ok := seen[matchID]; ok {
+			if unix, err := strconv.ParseInt(ts, 10, 64); err == nil {
+				lastSeen = time.Unix(unix, 0)
+			}
+		}
+		if lastSeen.IsZero() || !lastSeen.Before(cutoff) {
+			continue
+		}
+
+		if !r.queue.Enqueue(r.matchEnd(ctx, matchID, match, lastSeen)) {
+			continue
+		}
+		// Not due again for another StaleAfter, in case the match_end is
+		// still queued on the next run
+		r.state.HSet(ctx, liveMatchesSeenKey, matchID, now.Unix())
+		matchesReconciled.Inc()
+		ended++
+		r.logger.Infow("Ended stale live match", "match", matchID, "server", match.ServerID, "lastHeartbeat", lastSeen)
+	}
+	return ended
+}
+
+// matchEnd builds the synthetic match_end, dated at the last heartbeat and
+// carrying the winner if a team_win arrived
+func (r *MatchReconciler) matchEnd(ctx context.Context, matchID string, match models.LiveMatch, lastSeen time.Time) *models.RawEvent {
+	winner, _ := r.state.HGet(ctx, "match:"+matchID+":winner", "team")
+	return &models.RawEvent{
+		Type:        models.EventMatchEnd,
+		MatchID:     matchID,
+		ServerID:    match.ServerID,
+		MapName:     match.MapName,
+		Gametype:    match.Gametype,
+		Timestamp:   float64(lastSeen.Unix()),
+		AlliesScore: match.AlliesScore,
+		AxisScore:   match.AxisScore,
+		WinningTeam: winner,
+		Reason:      reconciledMatchReason,
+	}
+}
@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/db"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+type recordingQueue struct {
+	events []*models.RawEvent
+}
+
+func (q *recordingQueue) Enqueue(event *models.RawEvent) bool {
+	q.events = append(q.events, event)
+	return true
+}
+
+func TestMatchReconciler(t *testing.T) {
+	ctx := context.Background()
+	state := db.NewMemoryLiveState()
+	now := time.Now()
+
+	addMatch := func(id string, started time.Time, lastSeen *time.Time) {
+		data, _ := json.Marshal(models.LiveMatch{MatchID: id, ServerID: "srv-" + id, MapName: "mohdm6", StartedAt: started, AxisScore: 3})
+		state.HSet(ctx, "live_matches", id, data)
+		if lastSeen != nil {
+			state.HSet(ctx, liveMatchesSeenKey, id, lastSeen.Unix())
+		}
+	}
+	old := now.Add(-time.Hour)
+	recent := now.Add(-time.Minute)
+	addMatch("stale", old, &old)
+	addMatch("alive", old, &recent)
+	addMatch("untracked", old, nil) // no heartbeat recorded, falls back to started_at
+	state.HSet(ctx, "match:stale:winner", "team", "axis")
+
+	queue := &recordingQueue{}
+	r := NewMatchReconciler(state, queue, ReconcilerConfig{StaleAfter: 5 * time.Minute}, zap.NewNop())
+
+	if n := r.RunOnce(ctx); n != 2 {
+		t.Fatalf("ended %d matches, want 2", n)
+	}
+	ended := make(map[string]*models.RawEvent)
+	for _, e := range queue.events {
+		if e.Type != models.EventMatchEnd || e.Reason != reconciledMatchReason {
+			t.Errorf("queued %s (%q), want a reconciled match_end", e.Type, e.Reason)
+		}
+		ended[e.MatchID] = e
+	}
+	if ended["alive"] != nil {
+		t.Error("ended a match with a recent heartbeat")
+	}
+	stale := ended["stale"]
+	if stale == nil || ended["untracked"] == nil {
+		t.Fatalf("ended %v, want stale and untracked", ended)
+	}
+	if stale.ServerID != "srv-stale" || stale.WinningTeam != "axis" || stale.AxisScore != 3 {
+		t.Errorf("match_end = %+v, want server, winner and score from live state", stale)
+	}
+	if stale.Timestamp != float64(old.Unix()) {
+		t.Errorf("match_end dated %v, want the last heartbeat %d", stale.Timestamp, old.Unix())
+	}
+
+	// Queued matches are not ended again while their match_end is pending
+	if n := r.RunOnce(ctx); n != 0 {
+		t.Errorf("second run ended %d matches, want 0", n)
+	}
+}
@@ -0,0 +1,66 @@
+package worker
+
+import "time"
+
+// matchIdle is how long a match without events keeps its tracked state
+// before it is forgotten (matches that never sent match_end)
+const matchIdle = time.Hour
+
+// matchTracker holds the per-match state of an observer that follows a
+// match's events. Matches are forgotten when the observer ends them, on
+// match_end, or after matchIdle without events. It does no locking of its
+// own; observers call it under their lock.
+type matchTracker[T any] struct {
+	matches   map[string]*trackedMatch[T]
+	lastSweep time.Time
+}
+
+type trackedMatch[T any] struct {
+	state T
+	seen  time.Time
+}
+
+func newMatchTracker[T any]() *matchTracker[T] {
+	return &matchTracker[T]{matches: make(map[string]*trackedMatch[T]), lastSweep: time.Now()}
+}
+
+// get returns a match's state, made by create the first time the match is
+// seen, and marks the match seen at now
+func (t *matchTracker[T]) get(matchID string, now time.Time, create func() T) T {
+	m, ok := t.matches[matchID]
+	if !ok {
+		m = &trackedMatch[T]{state: create()}
+		t.matches[matchID] = m
+	}
+	m.seen = now
+	return m.state
+}
+
+// end forgets a match and returns its state, if it was tracked
+func (t *matchTracker[T]) end(matchID string) (T, bool) {
+	m, ok := t.matches[matchID]
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	delete(t.matches, matchID)
+	return m.state, true
+}
+
+// len is the number of matches tracked
+func (t *matchTracker[T]) len() int {
+	return len(t.matches)
+}
+
+// sweep forgets idle matches, at most once per matchIdle
+func (t *matchTracker[T]) sweep(now time.Time) {
+	if now.Sub(t.lastSweep) < matchIdle {
+		return
+	}
+	t.lastSweep = now
+	for id, m := range t.matches {
+		if now.Sub(m.seen) > matchIdle {
+			delete(t.matches, id)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package worker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMatchTracker(t *testing.T) {
+	tr := newMatchTracker[*int]()
+	now := time.Now()
+	created := 0
+	create := func() *int {
+		created++
+		return new(int)
+	}
+
+	*tr.get("a", now, create)++
+	*tr.get("a", now, create)++
+	tr.get("b", now, create)
+	if created != 2 || *tr.get("a", now, create) != 2 {
+		t.Fatalf("created %d states, a = %d", created, *tr.get("a", now, create))
+	}
+
+	if state, ok := tr.end("a"); !ok || *state != 2 {
+		t.Errorf("end(a) = %v, %v", state, ok)
+	}
+	if _, ok := tr.end("a"); ok {
+		t.Error("ended match still tracked")
+	}
+
+	// A match seen lately outlives the sweep, an idle one does not
+	later := now.Add(matchIdle + time.Minute)
+	tr.get("c", later, create)
+	tr.sweep(later)
+	if tr.len() != 1 {
+		t.Errorf("%d matches after the sweep, want only c", tr.len())
+	}
+}
@@ -0,0 +1,107 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// killMilestones and winMilestones are the career counts worth a timeline entry
+var (
+	killMilestones = map[int64]string{
+		1:      "First kill",
+		100:    "100th kill",
+		1000:   "1,000th kill",
+		5000:   "5,000th kill",
+		10000:  "10,000th kill",
+		25000:  "25,000th kill",
+		50000:  "50,000th kill",
+		100000: "100,000th kill",
+	}
+	winMilestones = map[int64]string{
+		1:    "First win",
+		10:   "10th win",
+		50:   "50th win",
+		100:  "100th win",
+		500:  "500th win",
+		1000: "1,000th win",
+	}
+)
+
+// eventTime is when an event happened, or now for game-relative timestamps
+func eventTime(event *models.RawEvent) time.Time {
+	if event.Timestamp >= minValidUnixTimestamp {
+		return time.Unix(int64(event.Timestamp), 0).UTC()
+	}
+	return time.Now().UTC()
+}
+
+// counterMilestone returns the milestone a counter reached, if any
+func counterMilestone(kind string, titles map[int64]string, guid string, count int64, event *models.RawEvent) *models.PlayerMilestone {
+	title, ok := titles[count]
+	if !ok {
+		return nil
+	}
+	return &models.PlayerMilestone{
+		PlayerGUID: guid,
+		Milestone:  fmt.Sprintf("%s:%d", kind, count),
+		Title:      title,
+		Value:      count,
+		MatchID:    event.MatchID,
+		ServerID:   event.ServerID,
+		AchievedAt: eventTime(event),
+	}
+}
+
+// firstHeadshotMilestone is the milestone of a player's first headshot with a weapon
+func firstHeadshotMilestone(guid, weapon string, event *models.RawEvent) *models.PlayerMilestone {
+	return &models.PlayerMilestone{
+		PlayerGUID: guid,
+		Milestone:  "first_headshot:" + weapon,
+		Title:      "First headshot with the " + weapon,
+		Value:      1,
+		MatchID:    event.MatchID,
+		ServerID:   event.ServerID,
+		AchievedAt: eventTime(event),
+	}
+}
+
+// headshotWeapon normalizes the weapon a headshot counter is kept for; empty
+// when the event names none
+func headshotWeapon(event *models.RawEvent) string {
+	return strings.ToLower(strings.TrimSpace(event.Weapon))
+}
+
+// recordMilestones stores milestones; ones a player already has are ignored
+func (p *Pool) recordMilestones(ctx context.Context, milestones []*models.PlayerMilestone) {
+	if len(milestones) == 0 || p.config.Postgres == nil {
+		return
+	}
+	guids := make([]string, len(milestones))
+	keys := make([]string, len(milestones))
+	titles := make([]string, len(milestones))
+	values := make([]int64, len(milestones))
+	matches := make([]string, len(milestones))
+	servers := make([]string, len(milestones))
+	times := make([]time.Time, len(milestones))
+	for i, m := range milestones {
+		guids[i], keys[i], titles[i], values[i] = m.PlayerGUID, m.Milestone, m.Title, m.Value
+		matches[i], servers[i], times[i] = m.MatchID, m.ServerID, m.AchievedAt
+	}
+
+	tag, err := p.config.Postgres.Exec(ctx, `
+		INSERT INTO player_milestones (player_guid, milestone, title, value, match_id, server_id, achieved_at)
+		SELECT * FROM unnest($1::text[], $2::text[], $3::text[], $4::bigint[], $5::text[], $6::text[], $7::timestamptz[])
+		ON CONFLICT (player_guid, milestone) DO NOTHING
+	`, guids, keys, titles, values, matches, servers, times)
+	if err != nil {
+		p.logger.Errorw("Failed to store milestones", "count", len(milestones), "error", err)
+		return
+	}
+	if tag.RowsAffected() > 0 {
+		p.logger.Debugw("Milestones reached", "count", tag.RowsAffected())
+	}
+}
@@ -0,0 +1,46 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestCounterMilestone(t *testing.T) {
+	event := &models.RawEvent{Type: models.EventPlayerKill, MatchID: "m1", ServerID: "srv", Timestamp: 1760000000}
+
+	m := counterMilestone("kills", killMilestones, "p1", 10000, event)
+	if m == nil {
+		t.Fatal("no milestone at 10,000 kills")
+	}
+	if m.Milestone != "kills:10000" || m.Title != "10,000th kill" || m.MatchID != "m1" || m.ServerID != "srv" {
+		t.Errorf("milestone = %+v", m)
+	}
+	if !m.AchievedAt.Equal(time.Unix(1760000000, 0)) {
+		t.Errorf("achieved at %v, want the event time", m.AchievedAt)
+	}
+	if m := counterMilestone("kills", killMilestones, "p1", 9999, event); m != nil {
+		t.Errorf("milestone at 9,999 kills: %+v", m)
+	}
+	if m := counterMilestone("wins", winMilestones, "p1", 100, event); m == nil || m.Milestone != "wins:100" {
+		t.Errorf("100th win = %+v", m)
+	}
+
+	// Game-relative timestamps fall back to now
+	relative := &models.RawEvent{Type: models.EventPlayerKill, Timestamp: 120}
+	if m := counterMilestone("kills", killMilestones, "p1", 1, relative); time.Since(m.AchievedAt) > time.Minute {
+		t.Errorf("achieved at %v for a game-relative timestamp", m.AchievedAt)
+	}
+}
+
+func TestFirstHeadshotMilestone(t *testing.T) {
+	event := &models.RawEvent{Type: models.EventPlayerKill, Weapon: " KAR98 ", Hitloc: "head"}
+	weapon := headshotWeapon(event)
+	if weapon != "kar98" {
+		t.Fatalf("weapon = %q", weapon)
+	}
+	if m := firstHeadshotMilestone("p1", weapon, event); m.Milestone != "first_headshot:kar98" || m.Value != 1 {
+		t.Errorf("milestone = %+v", m)
+	}
+}
@@ -0,0 +1,115 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// movementEvents only carry a player, a position and distance counters. With
+// PoolConfig.MovementTable they go to the narrow movement_events table.
+var movementEvents = map[models.EventType]bool{
+	models.EventJump:           true,
+	models.EventLand:           true,
+	models.EventCrouch:         true,
+	models.EventProne:          true,
+	models.EventPlayerStand:    true,
+	models.EventDistance:       true,
+	models.EventPlayerMovement: true,
+	models.EventLadderMount:    true,
+	models.EventLadderDismount: true,
+}
+
+// rawJSONReadBy lists event types whose raw_json stats queries still read
+// fields from, so it cannot be omitted while they are stored in raw_events
+var rawJSONReadBy = map[models.EventType]bool{
+	models.EventDistance:        true, // walked/sprinted/swam/driven
+	models.EventLadderDismount:  true, // height_climbed
+	models.EventLand:            true, // fall_damage
+	models.EventDeath:           true, // mod
+	models.EventPlayerKill:      true, // mod, inflictor, actor_x/y, weapon
+	models.EventBotKilled:       true, // mod, actor_x/y
+	models.EventPlayerSuicide:   true, // mod, inflictor
+	models.EventObjectiveUpdate: true, // objective_type, objective_status
+	models.EventMatchStart:      true, // gametype, server_id, player_count, maxclients
+	models.EventMatchEnd:        true, // allies_score, axis_score
+	models.EventRoundOutcome:    true, // allies_score, axis_score
+	models.EventHeartbeat:       true, // allies_score, axis_score, player_count, pings, sv_fps, frame_time, spectator_count
+	models.EventTeamJoin:        true, // new_team, for match recomputes
+	models.EventPlayerSpawn:     true, // player_team, for match recomputes
+	models.EventTeamWin:         true, // winning_team, for match recomputes
+}
+
+// ParseRawJSONOmit parses a comma-separated list of event types stored
+// without raw_json. Types whose raw_json feeds stats are refused unless the
+// movement table takes them out of raw_events.
+func ParseRawJSONOmit(spec string, movementTable bool) (map[models.EventType]bool, error) {
+	omit := make(map[models.EventType]bool)
+	for _, name := range strings.Split(spec, ",") {
+		eventType := models.EventType(strings.TrimSpace(name))
+		if eventType == "" {
+			continue
+		}
+		if rawJSONReadBy[eventType] && !(movementTable && movementEvents[eventType]) {
+			return nil, fmt.Errorf("raw_json of %s events is read by stats queries and cannot be omitted", eventType)
+		}
+		omit[eventType] = true
+	}
+	return omit, nil
+}
+
+// narrowEvent reports whether a job is written to movement_events
+func (p *Pool) narrowEvent(event *models.RawEvent) bool {
+	return p.config.MovementTable && movementEvents[event.Type]
+}
+
+// insertMovementEvents writes movement jobs to the narrow table
+func (p *Pool) insertMovementEvents(ctx context.Context, batch []Job) error {
+	ctx, table := p.insertTarget(ctx, "mohaa_stats.movement_events", len(batch))
+	chBatch, err := p.config.ClickHouse.PrepareBatch(ctx, `
+		INSERT INTO `+table+` (
+			timestamp, match_id, server_id, tenant_id, map_name, event_type,
+			actor_id, actor_name, actor_smf_id, actor_pos_x, actor_pos_y, actor_pos_z, actor_stance,
+			walked, sprinted, swam, driven, distance, fall_height, sample_weight, round_phase
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	for _, job := range batch {
+		event := job.Event
+		chEvent := p.convertToClickHouseEvent(event, "", job.Timestamp)
+
+		err := chBatch.Append(
+			chEvent.Timestamp,
+			chEvent.MatchID,
+			chEvent.ServerID,
+			chEvent.TenantID,
+			chEvent.MapName,
+			chEvent.EventType,
+			chEvent.ActorID,
+			chEvent.ActorName,
+			chEvent.ActorSMFID,
+			chEvent.ActorPosX,
+			chEvent.ActorPosY,
+			chEvent.ActorPosZ,
+			event.PlayerStance,
+			event.Walked,
+			event.Sprinted,
+			event.Swam,
+			event.Driven,
+			chEvent.Distance,
+			event.FallHeight,
+			max(job.SampleWeight, 1),
+			jobRoundPhase(job),
+		)
+		if err != nil {
+			p.logger.Warnw("Failed to append movement event to batch", "error", err, "event_type", event.Type)
+		}
+	}
+
+	return chBatch.Send()
+}
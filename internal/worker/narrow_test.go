@@ -0,0 +1,56 @@
+package worker
+
+import (
+	"testing"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestParseRawJSONOmit(t *testing.T) {
+	tests := []struct {
+		name          string
+		spec          string
+		movementTable bool
+		want          []models.EventType
+		wantErr       bool
+	}{
+		{name: "Empty", spec: ""},
+		{name: "Low Value Types", spec: "weapon_fire, weapon_ready", want: []models.EventType{"weapon_fire", "weapon_ready"}},
+		{name: "Read By Stats", spec: "player_kill", wantErr: true},
+		{name: "Distance In Raw Events", spec: "distance", wantErr: true},
+		{name: "Distance In Movement Table", spec: "distance", movementTable: true, want: []models.EventType{"distance"}},
+		{name: "Kills Even With Movement Table", spec: "player_kill", movementTable: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRawJSONOmit(tt.spec, tt.movementTable)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for _, et := range tt.want {
+				if !got[et] {
+					t.Errorf("%s not omitted", et)
+				}
+			}
+		})
+	}
+}
+
+func TestNarrowEvent(t *testing.T) {
+	p := &Pool{config: PoolConfig{MovementTable: true}}
+	if !p.narrowEvent(&models.RawEvent{Type: models.EventJump}) {
+		t.Error("jump should take the narrow path")
+	}
+	if p.narrowEvent(&models.RawEvent{Type: models.EventWeaponFire}) {
+		t.Error("weapon_fire should stay in raw_events")
+	}
+
+	p.config.MovementTable = false
+	if p.narrowEvent(&models.RawEvent{Type: models.EventJump}) {
+		t.Error("narrow path must be opt-in")
+	}
+}
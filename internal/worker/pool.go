@@ -10,8 +10,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
@@ -19,9 +21,10 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
-	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 
+	"github.com/openmohaa/stats-api/internal/db"
+	"github.com/openmohaa/stats-api/internal/logic"
 	"github.com/openmohaa/stats-api/internal/models"
 )
 
@@ -74,6 +77,11 @@ var (
 		Name: "mohaa_events_load_shed_total",
 		Help: "Total number of events dropped due to load shedding",
 	})
+
+	sandboxEvents = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "mohaa_sandbox_events_total",
+		Help: "Events from sandbox servers stored in raw_events_sandbox",
+	})
 )
 
 // Job represents a unit of work for the worker pool
@@ -81,6 +89,19 @@ type Job struct {
 	Event     *models.RawEvent
 	RawJSON   string
 	Timestamp time.Time
+	// SampleWeight is how many events this one stands for (1 unless sampled)
+	SampleWeight uint16
+	// RoundPhase is the phase the event's match was in (see roundPhases)
+	RoundPhase string
+	// Vehicle and VehicleSeat are where the actor sat, TargetVehicle is the
+	// target's vehicle (see vehicleSeats)
+	Vehicle       string
+	VehicleSeat   string
+	TargetVehicle string
+	// TargetLife is the victim's life on death events (see spawnLives)
+	TargetLife spawnLife
+	// stored, if set, is called once the job's batch is in ClickHouse
+	stored func()
 }
 
 // PoolConfig configures the worker pool
@@ -91,19 +112,105 @@ type PoolConfig struct {
 	FlushInterval time.Duration
 	ClickHouse    driver.Conn
 	Postgres      *pgxpool.Pool
-	Redis         *redis.Client
+	LiveState     db.LiveStateStore
 	Logger        *zap.Logger
+	RedisTTL      RedisTTLConfig
+	// Sampler thins high-volume event types before they are queued; nil stores everything
+	Sampler *Sampler
+	// MovementTable writes movement events to the narrow movement_events table
+	MovementTable bool
+	// OmitRawJSON lists event types stored with an empty raw_json (see ParseRawJSONOmit)
+	OmitRawJSON map[models.EventType]bool
+	// InsertMode routes batches smaller than SmallBatch rows through async
+	// inserts or Buffer tables; larger batches are always inserted directly
+	InsertMode InsertMode
+	SmallBatch int
+	// KillDedupeWindow collapses repeated kill events of one death that
+	// arrive within it of each other (by event time); zero keeps them all
+	KillDedupeWindow time.Duration
+	// ShardByMatch gives each worker its own queue and routes every event of a
+	// match to the same worker, so streak and round logic sees them in order.
+	// Side effects then run inline instead of in a goroutine.
+	ShardByMatch bool
+	// LiveStateBreaker decides when Redis side effects are skipped and how many
+	// critical updates are kept for replay
+	LiveStateBreaker LiveStateBreakerConfig
+	// MatchStates tracks match lifecycles from match_start/match_end; nil disables
+	MatchStates logic.MatchStateService
+	// Ratings updates player ratings and adjusted K/D from player kills;
+	// nil disables
+	Ratings logic.RatingService
+	// TeamkillAlerts posts a webhook when a player's team kills in a match
+	// reach a threshold; nil disables
+	TeamkillAlerts *TeamkillAlerter
+	// CachePurges notifies frontends when a match changes player profiles;
+	// nil disables
+	CachePurges *CachePurger
+	// Announcer queues achievement unlocks, record breaks and rank-ups for
+	// game servers to print in chat; nil disables
+	Announcer *Announcer
+	// Inbox stores notifications for linked players: achievement unlocks from
+	// the pool and rivalries from the event stream; nil disables
+	Inbox *Inbox
+	// Connections records the hashed client IPs of connect events for alt
+	// account detection; nil drops them
+	Connections *ConnectionTracker
+	// Challenges counts progress on the weekly challenges and new players'
+	// quest chain; nil disables
+	Challenges *ChallengeEngine
+	// Profiles rebuilds the precomputed profiles of players in new events;
+	// nil disables
+	Profiles *ProfileCache
+	// EventBus mirrors accepted events onto a message bus; nil disables
+	EventBus *EventBus
+	// Faults injects batch, ClickHouse and Redis failures for testing; nil
+	// injects nothing
+	Faults *Faults
+}
+
+// RedisTTLConfig sets expiry policies for Redis keys written by the pool.
+// Zero values use the defaults applied in NewPool; negative disables expiry.
+type RedisTTLConfig struct {
+	// MatchKeys covers match:<id>:teams|players|winner
+	MatchKeys time.Duration
+	// PlayerCounters is a sliding idle expiry for player:<guid>:kills|headshots|achievements
+	// and bot_guids.
+	// Unlocked achievements are persisted in Postgres so an expired set is not re-granted.
+	PlayerCounters time.Duration
+	// Claims covers identity_claim:<code>:verified
+	Claims time.Duration
+}
+
+func (c RedisTTLConfig) withDefaults() RedisTTLConfig {
+	if c.MatchKeys == 0 {
+		c.MatchKeys = 12 * time.Hour
+	}
+	if c.PlayerCounters == 0 {
+		c.PlayerCounters = 90 * 24 * time.Hour
+	}
+	if c.Claims == 0 {
+		c.Claims = 10 * time.Minute
+	}
+	return c
 }
 
 // Pool manages a pool of workers for async event processing
 type Pool struct {
 	config            PoolConfig
+	redisTTL          atomic.Pointer[RedisTTLConfig] // replaced on config reload
 	jobQueue          chan Job
+	shards            []chan Job // per-worker queues when ShardByMatch is set
 	wg                sync.WaitGroup
 	ctx               context.Context
 	cancel            context.CancelFunc
 	logger            *zap.SugaredLogger
 	achievementWorker *AchievementWorker
+	liveState         *liveStateBreaker
+	roundPhases       *roundPhases
+	vehicleSeats      *vehicleSeats
+	spawnLives        *spawnLives
+	killDedupe        *killDedupe
+	failedBatches     atomic.Uint64 // batches whose insert failed, for alerting
 }
 
 // NewPool creates a new worker pool
@@ -120,16 +227,32 @@ func NewPool(cfg PoolConfig) *Pool {
 	if cfg.FlushInterval <= 0 {
 		cfg.FlushInterval = time.Second
 	}
+	cfg.RedisTTL = cfg.RedisTTL.withDefaults()
 
 	pool := &Pool{
-		config:   cfg,
-		jobQueue: make(chan Job, cfg.QueueSize),
-		logger:   cfg.Logger.Sugar(),
+		config:       cfg,
+		jobQueue:     make(chan Job, cfg.QueueSize),
+		logger:       cfg.Logger.Sugar(),
+		liveState:    newLiveStateBreaker(cfg.LiveStateBreaker),
+		roundPhases:  newRoundPhases(),
+		vehicleSeats: newVehicleSeats(),
+		spawnLives:   newSpawnLives(),
+		killDedupe:   newKillDedupe(cfg.KillDedupeWindow),
+	}
+	if cfg.ShardByMatch {
+		// Split the queue capacity between the shards
+		shardSize := max(cfg.QueueSize/cfg.WorkerCount, 1)
+		pool.shards = make([]chan Job, cfg.WorkerCount)
+		for i := range pool.shards {
+			pool.shards[i] = make(chan Job, shardSize)
+		}
 	}
 
 	// Initialize Achievement Worker with both Postgres and ClickHouse
-	statStore := &RedisStatStore{client: cfg.Redis}
+	statStore := &LiveStateStatStore{store: cfg.LiveState}
 	pool.achievementWorker = NewAchievementWorker(cfg.Postgres, cfg.ClickHouse, statStore, cfg.Logger.Sugar())
+	pool.achievementWorker.announcer = cfg.Announcer
+	pool.achievementWorker.inbox = cfg.Inbox
 	pool.achievementWorker.Start()
 
 	return pool
@@ -151,6 +274,7 @@ func (p *Pool) Start(ctx context.Context) {
 		"workers", p.config.WorkerCount,
 		"queueSize", p.config.QueueSize,
 		"batchSize", p.config.BatchSize,
+		"shardByMatch", len(p.shards) > 0,
 	)
 }
 
@@ -158,27 +282,130 @@ func (p *Pool) Start(ctx context.Context) {
 func (p *Pool) Stop() {
 	p.logger.Info("Stopping worker pool...")
 
-	// Stop achievement worker
-	if p.achievementWorker != nil {
-		p.achievementWorker.Stop()
-	}
-
 	p.cancel()
 	close(p.jobQueue)
+	for _, shard := range p.shards {
+		close(shard)
+	}
 	p.wg.Wait()
+
+	// Stop achievement worker once the final batches have been queued
+	if p.achievementWorker != nil {
+		p.achievementWorker.Stop()
+	}
 	p.logger.Info("Worker pool stopped")
 }
 
+// SetRedisTTL swaps the key expiry policy used by every worker from the next
+// write on. Keys already written keep their current expiry.
+func (p *Pool) SetRedisTTL(ttl RedisTTLConfig) {
+	ttl = ttl.withDefaults()
+	p.redisTTL.Store(&ttl)
+	if p.config.Announcer != nil {
+		p.config.Announcer.SetTTL(ttl.MatchKeys)
+	}
+}
+
+func (p *Pool) ttl() RedisTTLConfig {
+	if ttl := p.redisTTL.Load(); ttl != nil {
+		return *ttl
+	}
+	return p.config.RedisTTL
+}
+
+// ReplayAchievements runs stored events through the achievement checks again,
+// e.g. for a recomputed match. Returns false if the queue is full.
+func (p *Pool) ReplayAchievements(events []*models.RawEvent) bool {
+	if p.achievementWorker == nil {
+		return false
+	}
+	return p.achievementWorker.Enqueue(events)
+}
+
+// ReloadAchievements re-reads achievement definitions from Postgres
+func (p *Pool) ReloadAchievements() error {
+	if p.achievementWorker == nil {
+		return nil
+	}
+	return p.achievementWorker.ReloadDefinitions()
+}
+
 // Enqueue adds a job to the queue. Blocks if queue is full (no load shedding).
 func (p *Pool) Enqueue(event *models.RawEvent) bool {
+	return p.EnqueueStored(event, nil)
+}
+
+// EnqueueStored is Enqueue, calling stored once the event's batch is in
+// ClickHouse, or right away for an event accepted without being stored (a
+// repeated kill or one sampled out). stored is not called for an event whose
+// batch failed.
+func (p *Pool) EnqueueStored(event *models.RawEvent, stored func()) bool {
+	sanitizeEvent(event)
+	applyRoster(event)
+	// Hashed client IPs only feed alt account detection; they are never
+	// stored or mirrored with the event
+	if event.ClientIPHash != "" {
+		if !event.Sandbox {
+			p.config.Connections.Observe(event, time.Now())
+		}
+		event.ClientIPHash = ""
+	}
 	rawJSON, _ := json.Marshal(event)
 
+	// Sandbox events are stored as sent and move nothing else: no observers,
+	// no sampling, no match tracking
+	if event.Sandbox {
+		return p.send(event, Job{Event: event, RawJSON: string(rawJSON), Timestamp: time.Now(), SampleWeight: 1, stored: stored})
+	}
+
+	// A repeat of a kill just seen is accepted and dropped before anything
+	// counts it
+	if p.killDedupe.duplicate(event, time.Now()) {
+		if stored != nil {
+			stored()
+		}
+		return true
+	}
+
+	// Tagged before sampling so dropped events still move their match along
+	phase := p.roundPhases.tag(event, time.Now())
+	p.config.TeamkillAlerts.Observe(event, time.Now())
+	p.config.CachePurges.Observe(event, time.Now())
+	p.config.Announcer.Observe(event, time.Now())
+	p.config.Inbox.Observe(event, time.Now())
+	p.config.Challenges.Observe(event, time.Now())
+	p.config.Profiles.Observe(event, time.Now())
+	p.config.EventBus.Observe(event, time.Now())
+	vehicle, targetVehicle := p.vehicleSeats.track(event, time.Now())
+	targetLife := p.spawnLives.track(event, time.Now())
+
+	weight, keep := p.config.Sampler.Sample(event.Type, rawJSON)
+	if !keep {
+		// Accepted, just not stored; the kept events carry its weight
+		if stored != nil {
+			stored()
+		}
+		return true
+	}
+
 	job := Job{
-		Event:     event,
-		RawJSON:   string(rawJSON),
-		Timestamp: time.Now(),
+		Event:         event,
+		RawJSON:       string(rawJSON),
+		Timestamp:     time.Now(),
+		SampleWeight:  weight,
+		RoundPhase:    phase,
+		Vehicle:       vehicle.vehicle,
+		VehicleSeat:   vehicle.seat,
+		TargetVehicle: targetVehicle,
+		TargetLife:    targetLife,
+		stored:        stored,
 	}
+	return p.send(event, job)
+}
 
+// send queues a job on its event's queue, blocking while it is full. It
+// reports false once the pool is stopping.
+func (p *Pool) send(event *models.RawEvent, job Job) bool {
 	// Protect against sending on closed channel
 	defer func() {
 		if r := recover(); r != nil {
@@ -186,9 +413,13 @@ func (p *Pool) Enqueue(event *models.RawEvent) bool {
 		}
 	}()
 
+	queue, shard := p.queueFor(event)
 	select {
-	case p.jobQueue <- job:
+	case queue <- job:
 		eventsIngested.Inc()
+		if shard >= 0 {
+			shardEventsIngested.WithLabelValues(strconv.Itoa(shard)).Inc()
+		}
 		return true
 	case <-p.ctx.Done():
 		p.logger.Warn("Worker pool context canceled, dropping event")
@@ -199,7 +430,55 @@ func (p *Pool) Enqueue(event *models.RawEvent) bool {
 
 // QueueDepth returns current queue size
 func (p *Pool) QueueDepth() int {
-	return len(p.jobQueue)
+	depth := len(p.jobQueue)
+	for _, shard := range p.shards {
+		depth += len(shard)
+	}
+	return depth
+}
+
+// FailedBatches counts the batches whose processing failed since start,
+// mostly ClickHouse insert errors
+func (p *Pool) FailedBatches() uint64 {
+	return p.failedBatches.Load()
+}
+
+// PoolStats is a point-in-time view of the pool's queues
+type PoolStats struct {
+	Workers       int   `json:"workers"`
+	QueueDepth    int   `json:"queue_depth"`
+	QueueCapacity int   `json:"queue_capacity"`
+	ShardDepths   []int `json:"shard_depths,omitempty"`
+	// AchievementQueue counts batches waiting for the achievement worker
+	AchievementQueue int             `json:"achievement_queue"`
+	LiveState        LiveStateStatus `json:"live_state"`
+}
+
+// Stats returns the current queue depths
+func (p *Pool) Stats() PoolStats {
+	stats := PoolStats{
+		Workers:       p.config.WorkerCount,
+		QueueDepth:    p.QueueDepth(),
+		QueueCapacity: cap(p.jobQueue),
+	}
+	if len(p.shards) > 0 {
+		stats.QueueCapacity = 0
+		stats.ShardDepths = make([]int, len(p.shards))
+		for i, shard := range p.shards {
+			stats.ShardDepths[i] = len(shard)
+			stats.QueueCapacity += cap(shard)
+		}
+	}
+	if p.achievementWorker != nil {
+		stats.AchievementQueue = len(p.achievementWorker.queue)
+	}
+	stats.LiveState = p.LiveStateStatus()
+	return stats
+}
+
+// LiveStateStatus reports whether Redis side effects are running degraded
+func (p *Pool) LiveStateStatus() LiveStateStatus {
+	return p.liveState.status()
 }
 
 // worker processes jobs from the queue in batches
@@ -208,17 +487,18 @@ func (p *Pool) worker(id int) {
 
 	p.logger.Infow("Worker started", "worker", id)
 
+	queue := p.workerQueue(id)
 	batch := make([]Job, 0, p.config.BatchSize)
 	ticker := time.NewTicker(p.config.FlushInterval)
 	defer ticker.Stop()
 
 	flush := func() {
 		if len(batch) == 0 {
-			p.logger.Infow("Flush called with empty batch", "worker", id)
+			p.logger.Debugw("Flush called with empty batch", "worker", id)
 			return
 		}
 
-		p.logger.Infow("Flushing batch", "worker", id, "batchSize", len(batch))
+		p.logger.Debugw("Flushing batch", "worker", id, "batchSize", len(batch))
 
 		start := time.Now()
 		if err := p.processBatch(batch); err != nil {
@@ -228,9 +508,15 @@ func (p *Pool) worker(id int) {
 				"error", err,
 			)
 			eventsFailed.Add(float64(len(batch)))
+			p.failedBatches.Add(1)
 		} else {
-			p.logger.Infow("Batch processed successfully", "worker", id, "batchSize", len(batch), "duration", time.Since(start))
+			p.logger.Debugw("Batch processed successfully", "worker", id, "batchSize", len(batch), "duration", time.Since(start))
 			eventsProcessed.Add(float64(len(batch)))
+			for _, job := range batch {
+				if job.stored != nil {
+					job.stored()
+				}
+			}
 		}
 		batchInsertDuration.Observe(time.Since(start).Seconds())
 
@@ -239,7 +525,7 @@ func (p *Pool) worker(id int) {
 
 	for {
 		select {
-		case job, ok := <-p.jobQueue:
+		case job, ok := <-queue:
 			if !ok {
 				// Channel closed, flush remaining
 				p.logger.Infow("Job queue closed, flushing remaining batch", "worker", id)
@@ -247,15 +533,15 @@ func (p *Pool) worker(id int) {
 				return
 			}
 
-			p.logger.Infow("Received job", "worker", id, "eventType", job.Event.Type)
+			p.logger.Debugw("Received job", "worker", id, "eventType", job.Event.Type)
 			batch = append(batch, job)
 			if len(batch) >= p.config.BatchSize {
-				p.logger.Infow("Batch size reached, flushing", "worker", id, "batchSize", len(batch))
+				p.logger.Debugw("Batch size reached, flushing", "worker", id, "batchSize", len(batch))
 				flush()
 			}
 
 		case <-ticker.C:
-			p.logger.Infow("Ticker fired", "worker", id, "batchSize", len(batch))
+			p.logger.Debugw("Ticker fired", "worker", id, "batchSize", len(batch))
 			flush()
 
 		case <-p.ctx.Done():
@@ -272,17 +558,126 @@ func (p *Pool) processBatch(batch []Job) error {
 		return nil
 	}
 
-	// Prepare ClickHouse batch insert
 	ctx := context.Background()
 
+	// Sandbox events only get stored, in their own table. Losing them must
+	// not cost the live events of the batch, so a failure is only logged.
+	batch, sandbox := splitSandbox(batch)
+	if len(sandbox) > 0 {
+		if err := p.insertRawEvents(ctx, "mohaa_stats.raw_events_sandbox", sandbox); err != nil {
+			p.logger.Errorw("Failed to send sandbox batch to ClickHouse", "error", err, "batchSize", len(sandbox))
+			eventsFailed.Add(float64(len(sandbox)))
+		} else {
+			sandboxEvents.Add(float64(len(sandbox)))
+		}
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+
+	// Movement events take the narrow path when enabled
+	wide := batch
+	var narrow []Job
+	if p.config.MovementTable {
+		wide = make([]Job, 0, len(batch))
+		for _, job := range batch {
+			if p.narrowEvent(job.Event) {
+				narrow = append(narrow, job)
+			} else {
+				wide = append(wide, job)
+			}
+		}
+	}
+
+	// Process side effects in batch (Redis state updates)
+	if p.config.ShardByMatch {
+		// Inline so the next batch of this shard cannot overtake it
+		p.processBatchSideEffects(ctx, batch)
+	} else {
+		// Must copy batch because the slice is reused in the worker loop
+		batchCopy := make([]Job, len(batch))
+		copy(batchCopy, batch)
+		go p.processBatchSideEffects(ctx, batchCopy)
+	}
+
+	// Send batches to ClickHouse FIRST
+	if p.config.Faults.dropBatch() {
+		return fmt.Errorf("dropping batch: %w", ErrInjectedFault)
+	}
+	p.config.Faults.delayClickHouse()
+	if len(wide) > 0 {
+		if err := p.insertRawEvents(ctx, "mohaa_stats.raw_events", wide); err != nil {
+			p.logger.Errorw("Failed to send batch to ClickHouse", "error", err, "batchSize", len(wide))
+			return err
+		}
+	}
+	if len(narrow) > 0 {
+		if err := p.insertMovementEvents(ctx, narrow); err != nil {
+			p.logger.Errorw("Failed to send movement batch to ClickHouse", "error", err, "batchSize", len(narrow))
+			return err
+		}
+	}
+
+	events := make([]*models.RawEvent, len(batch))
+	for i, job := range batch {
+		events[i] = job.Event
+	}
+
+	// Match lifecycles follow the stored events. A failure leaves states
+	// behind but must not fail the batch, which is already in ClickHouse.
+	if p.config.MatchStates != nil {
+		if err := p.config.MatchStates.ApplyEvents(ctx, events); err != nil {
+			p.logger.Warnw("Failed to update match states", "error", err)
+		}
+	}
+	// Ratings likewise follow the stored kills
+	if p.config.Ratings != nil {
+		if err := p.config.Ratings.ApplyKills(ctx, events); err != nil {
+			p.logger.Warnw("Failed to update player ratings", "error", err)
+		}
+	}
+
+	// THEN queue achievements (after data is in ClickHouse). The achievement
+	// worker processes batches in order, so a shard's events stay ordered.
+	if p.achievementWorker != nil {
+		p.achievementWorker.Enqueue(events)
+	}
+
+	return nil
+}
+
+// splitSandbox separates the jobs of sandbox servers from the rest
+func splitSandbox(batch []Job) (live, sandbox []Job) {
+	for _, job := range batch {
+		if job.Event.Sandbox {
+			sandbox = append(sandbox, job)
+		}
+	}
+	if len(sandbox) == 0 {
+		return batch, nil
+	}
+	live = make([]Job, 0, len(batch)-len(sandbox))
+	for _, job := range batch {
+		if !job.Event.Sandbox {
+			live = append(live, job)
+		}
+	}
+	return live, sandbox
+}
+
+// insertRawEvents writes jobs to raw_events, or a table of the same shape
+func (p *Pool) insertRawEvents(ctx context.Context, table string, batch []Job) error {
+	ctx, table = p.insertTarget(ctx, table, len(batch))
 	chBatch, err := p.config.ClickHouse.PrepareBatch(ctx, `
-		INSERT INTO mohaa_stats.raw_events (
-			timestamp, match_id, server_id, map_name, event_type,
+		INSERT INTO `+table+` (
+			timestamp, match_id, server_id, tenant_id, map_name, event_type,
 			actor_id, actor_name, actor_team, actor_weapon,
 			actor_pos_x, actor_pos_y, actor_pos_z, actor_pitch, actor_yaw, actor_stance,
 			target_id, target_name, target_team,
 			target_pos_x, target_pos_y, target_pos_z, target_stance,
-			damage, hitloc, distance, raw_json, actor_smf_id, target_smf_id, match_outcome, round_number
+			damage, hitloc, distance, raw_json, actor_smf_id, target_smf_id, match_outcome, round_number,
+			sample_weight, round_phase, vehicle, vehicle_seat, target_vehicle,
+			target_lifetime, target_engage_delay
 		)
 	`)
 	if err != nil {
@@ -294,11 +689,25 @@ func (p *Pool) processBatch(batch []Job) error {
 
 		// Convert to ClickHouse event, using job receipt time as fallback for game-relative timestamps
 		chEvent := p.convertToClickHouseEvent(event, job.RawJSON, job.Timestamp)
+		chEvent.SampleWeight = max(job.SampleWeight, 1)
+		chEvent.RoundPhase = jobRoundPhase(job)
+		if job.Vehicle != "" {
+			chEvent.Vehicle, chEvent.VehicleSeat = job.Vehicle, job.VehicleSeat
+		}
+		if job.TargetVehicle != "" {
+			chEvent.TargetVehicle = job.TargetVehicle
+		}
+		chEvent.TargetLifetime = job.TargetLife.lifetime
+		chEvent.TargetEngageDelay = job.TargetLife.engageDelay
+		if p.config.OmitRawJSON[event.Type] {
+			chEvent.RawJSON = ""
+		}
 
 		err := chBatch.Append(
 			chEvent.Timestamp,
 			chEvent.MatchID,
 			chEvent.ServerID,
+			chEvent.TenantID,
 			chEvent.MapName,
 			chEvent.EventType,
 			chEvent.ActorID,
@@ -326,91 +735,151 @@ func (p *Pool) processBatch(batch []Job) error {
 			chEvent.TargetSMFID,
 			chEvent.MatchOutcome,
 			chEvent.RoundNumber,
+			chEvent.SampleWeight,
+			chEvent.RoundPhase,
+			chEvent.Vehicle,
+			chEvent.VehicleSeat,
+			chEvent.TargetVehicle,
+			chEvent.TargetLifetime,
+			chEvent.TargetEngageDelay,
 		)
 		if err != nil {
 			p.logger.Warnw("Failed to append event to batch", "error", err, "event_type", event.Type)
 			continue
 		}
-
-		// Process side effects (Redis state updates)
-		// Batch processed later to optimize goroutines and I/O
 	}
 
-	// Process side effects in batch (Redis state updates)
-	// Must copy batch because the slice is reused in the worker loop
-	batchCopy := make([]Job, len(batch))
-	copy(batchCopy, batch)
-	go p.processBatchSideEffects(ctx, batchCopy)
+	return chBatch.Send()
+}
 
-	// Send batch to ClickHouse FIRST
-	err = chBatch.Send()
-	if err != nil {
-		p.logger.Errorw("Failed to send batch to ClickHouse", "error", err, "batchSize", len(batch))
-		return err
+// processBatchSideEffects processes side effects for a batch of events.
+// While Redis is failing the circuit breaker skips it, holding the critical
+// live state updates to replay once a batch gets through again.
+func (p *Pool) processBatchSideEffects(ctx context.Context, batch []Job) {
+	if len(batch) == 0 {
+		return
+	}
+	if !p.liveState.allow(time.Now()) {
+		p.liveState.hold(batch)
+		return
 	}
 
-	// THEN process achievements (after data is in ClickHouse)
-	for _, job := range batch {
-		event := job.Event
-		if p.achievementWorker != nil {
-			p.logger.Infow("Calling achievement worker", "event_type", event.Type, "attacker_smf_id", event.AttackerSMFID)
-			go func(evt *models.RawEvent) {
-				defer func() {
-					if r := recover(); r != nil {
-						p.logger.Errorw("Achievement worker panic", "error", r, "event_type", evt.Type)
-					}
-				}()
-				p.achievementWorker.ProcessEvent(evt)
-			}(event)
+	if err := p.applySideEffects(ctx, batch); err != nil {
+		p.liveState.hold(batch)
+		if p.liveState.failure(time.Now()) {
+			p.logger.Errorw("Redis unavailable, live state degraded", "error", err)
+		} else {
+			p.logger.Debugw("Redis pipeline failed", "error", err)
 		}
+		return
 	}
 
-	return nil
+	replay, closed := p.liveState.success()
+	if closed {
+		p.logger.Infow("Redis recovered, replaying live state", "events", len(replay))
+	}
+	if len(replay) == 0 {
+		return
+	}
+	jobs := make([]Job, len(replay))
+	for i, event := range replay {
+		jobs[i] = Job{Event: event}
+	}
+	if err := p.applySideEffects(ctx, jobs); err != nil {
+		p.liveState.hold(jobs)
+		p.liveState.failure(time.Now())
+	}
 }
 
-// processBatchSideEffects processes side effects for a batch of events
-func (p *Pool) processBatchSideEffects(ctx context.Context, batch []Job) {
-	if len(batch) == 0 {
-		return
+// applySideEffects runs the Redis side effects of a batch. It returns the
+// first pipeline error, before any later phase ran.
+func (p *Pool) applySideEffects(ctx context.Context, batch []Job) error {
+	if p.config.Faults.redisDown() {
+		return fmt.Errorf("redis pipeline: %w", ErrInjectedFault)
 	}
 
 	// Phase 1: Segregation & Pipelining
-	pipe := p.config.Redis.Pipeline()
+	pipe := p.config.LiveState.Pipeline()
 
 	// Track what we need to check after pipeline execution
 	type killCheck struct {
-		guid string
-		cmd  *redis.IntCmd
+		event *models.RawEvent
+		guid  string
+		cmd   *db.IntResult
 	}
 	type headshotCheck struct {
 		guid string
-		cmd  *redis.IntCmd
+		cmd  *db.IntResult
+	}
+
+	// Counters whose values may reach a milestone
+	type milestoneCheck struct {
+		event  *models.RawEvent
+		guid   string
+		weapon string // first headshot with a weapon when set, wins otherwise
+		cmd    *db.IntResult
 	}
 
 	var killChecks []killCheck
 	var headshotChecks []headshotCheck
+	var milestoneChecks []milestoneCheck
+	var challengeSteps []challengeStep
 	var deferredEvents []*models.RawEvent
 
 	for _, job := range batch {
 		event := job.Event
 
+		for _, step := range p.config.Challenges.steps(event) {
+			counter := step.key
+			if step.distinct != "" {
+				counter = step.distinct
+			}
+			step.cmd = pipe.Incr(ctx, counter)
+			expireKey(ctx, pipe, counter, step.ttl)
+			challengeSteps = append(challengeSteps, step)
+		}
+
 		switch event.Type {
 		case models.EventPlayerKill:
 			if event.AttackerGUID != "" && event.AttackerGUID != "world" {
 				key := "player:" + event.AttackerGUID + ":kills"
 				cmd := pipe.Incr(ctx, key)
-				killChecks = append(killChecks, killCheck{guid: event.AttackerGUID, cmd: cmd})
+				expireKey(ctx, pipe, key, p.ttl().PlayerCounters)
+				killChecks = append(killChecks, killCheck{event: event, guid: event.AttackerGUID, cmd: cmd})
 				// Also count headshots (derived from hitloc)
 				if event.Hitloc == "head" || event.Hitloc == "helmet" {
 					hsKey := "player:" + event.AttackerGUID + ":headshots"
 					hsCmd := pipe.Incr(ctx, hsKey)
+					expireKey(ctx, pipe, hsKey, p.ttl().PlayerCounters)
 					headshotChecks = append(headshotChecks, headshotCheck{guid: event.AttackerGUID, cmd: hsCmd})
+					if weapon := headshotWeapon(event); weapon != "" {
+						wKey := hsKey + ":" + weapon
+						wCmd := pipe.Incr(ctx, wKey)
+						expireKey(ctx, pipe, wKey, p.ttl().PlayerCounters)
+						milestoneChecks = append(milestoneChecks, milestoneCheck{event: event, guid: event.AttackerGUID, weapon: weapon, cmd: wCmd})
+					}
 				}
 			}
+			p.trackKillfeed(ctx, pipe, event)
+		case models.EventPlayerTeamkill, models.EventPlayerSuicide:
+			p.trackKillfeed(ctx, pipe, event)
+			deferredEvents = append(deferredEvents, event)
+		case models.EventMatchOutcome:
+			if event.MatchOutcome == 1 && event.PlayerGUID != "" {
+				key := "player:" + event.PlayerGUID + ":wins"
+				cmd := pipe.Incr(ctx, key)
+				expireKey(ctx, pipe, key, p.ttl().PlayerCounters)
+				milestoneChecks = append(milestoneChecks, milestoneCheck{event: event, guid: event.PlayerGUID, cmd: cmd})
+			}
+			deferredEvents = append(deferredEvents, event)
 		case models.EventConnect:
+			if p.tagBot(ctx, pipe, event) {
+				break
+			}
 			if event.PlayerGUID != "" {
 				pipe.HSet(ctx, "player_names", event.PlayerGUID, event.PlayerName)
 				pipe.SAdd(ctx, "match:"+event.MatchID+":players", event.PlayerGUID)
+				expireKey(ctx, pipe, "match:"+event.MatchID+":players", p.ttl().MatchKeys)
 				if event.PlayerSMFID > 0 {
 					pipe.HSet(ctx, "player_smfids", event.PlayerGUID, event.PlayerSMFID)
 				}
@@ -418,14 +887,17 @@ func (p *Pool) processBatchSideEffects(ctx context.Context, batch []Job) {
 		case models.EventDisconnect:
 			if event.PlayerGUID != "" {
 				pipe.SRem(ctx, "match:"+event.MatchID+":players", event.PlayerGUID)
+				pipe.HDel(ctx, scoreboardKey(event.MatchID), event.PlayerGUID)
 			}
 		case models.EventTeamJoin:
-			if event.PlayerGUID != "" && event.NewTeam != "" {
+			if event.PlayerGUID != "" && event.NewTeam != "" && !p.tagBot(ctx, pipe, event) {
 				pipe.HSet(ctx, "match:"+event.MatchID+":teams", event.PlayerGUID, event.NewTeam)
+				expireKey(ctx, pipe, "match:"+event.MatchID+":teams", p.ttl().MatchKeys)
 			}
 		case models.EventPlayerSpawn:
-			if event.PlayerGUID != "" && event.PlayerTeam != "" {
+			if event.PlayerGUID != "" && event.PlayerTeam != "" && !p.tagBot(ctx, pipe, event) {
 				pipe.HSet(ctx, "match:"+event.MatchID+":teams", event.PlayerGUID, event.PlayerTeam)
+				expireKey(ctx, pipe, "match:"+event.MatchID+":teams", p.ttl().MatchKeys)
 			}
 		case models.EventMatchStart, models.EventMatchEnd, models.EventHeartbeat, models.EventChat, models.EventTeamWin:
 			deferredEvents = append(deferredEvents, event)
@@ -435,24 +907,69 @@ func (p *Pool) processBatchSideEffects(ctx context.Context, batch []Job) {
 	}
 
 	// Execute pipeline
-	_, err := pipe.Exec(ctx)
-	if err != nil && err != redis.Nil {
-		p.logger.Errorw("Redis pipeline failed", "error", err)
+	if err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	// Milestones reached by the counters above
+	var milestones []*models.PlayerMilestone
+	for _, check := range killChecks {
+		if val, err := check.cmd.Result(); err == nil {
+			if m := counterMilestone("kills", killMilestones, check.guid, val, check.event); m != nil {
+				milestones = append(milestones, m)
+			}
+		}
+	}
+	for _, check := range milestoneChecks {
+		val, err := check.cmd.Result()
+		if err != nil {
+			continue
+		}
+		if check.weapon != "" {
+			if val == 1 {
+				milestones = append(milestones, firstHeadshotMilestone(check.guid, check.weapon, check.event))
+			}
+		} else if m := counterMilestone("wins", winMilestones, check.guid, val, check.event); m != nil {
+			milestones = append(milestones, m)
+		}
+	}
+	p.recordMilestones(ctx, milestones)
+
+	// Challenges and quest steps whose counter just reached the target
+	for _, step := range challengeSteps {
+		val, err := step.cmd.Result()
+		if err != nil {
+			continue
+		}
+		if step.distinct != "" {
+			// Only a weapon's first kill moves a distinct-weapon count
+			if val != 1 {
+				continue
+			}
+			if val, err = p.config.LiveState.Incr(ctx, step.key); err != nil {
+				continue
+			}
+			expireKey(ctx, p.config.LiveState, step.key, step.ttl)
+		}
+		if val == step.challenge.Target {
+			p.config.Challenges.complete(step, eventTime(step.event))
+		}
 	}
 
 	// Phase 2: Achievement Verification
 	type potentialUnlock struct {
 		guid          string
 		achievementID string
-		sIsMemberCmd  *redis.BoolCmd
+		sIsMemberCmd  *db.BoolResult
 	}
 	var potentialUnlocks []potentialUnlock
 
-	verifyPipe := p.config.Redis.Pipeline()
+	verifyPipe := p.config.LiveState.Pipeline()
 
 	for _, check := range killChecks {
 		val, err := check.cmd.Result()
 		if err == nil {
+			p.config.Announcer.announceRankUp(ctx, check.event, val)
 			if achievementID, ok := killThresholds[val]; ok {
 				key := "player:" + check.guid + ":achievements"
 				cmd := verifyPipe.SIsMember(ctx, key, achievementID)
@@ -481,8 +998,8 @@ func (p *Pool) processBatchSideEffects(ctx context.Context, batch []Job) {
 	}
 
 	if len(potentialUnlocks) > 0 {
-		_, err := verifyPipe.Exec(ctx)
-		if err != nil && err != redis.Nil {
+		err := verifyPipe.Exec(ctx)
+		if err != nil {
 			p.logger.Errorw("Redis verification pipeline failed", "error", err)
 		}
 	}
@@ -532,13 +1049,14 @@ func (p *Pool) processBatchSideEffects(ctx context.Context, batch []Job) {
 		}
 
 		// 2. Mark as unlocked in Redis
-		persistPipe := p.config.Redis.Pipeline()
+		persistPipe := p.config.LiveState.Pipeline()
 		for _, unlock := range newUnlocks {
 			key := "player:" + unlock.guid + ":achievements"
 			persistPipe.SAdd(ctx, key, unlock.achievementID)
+			expireKey(ctx, persistPipe, key, p.ttl().PlayerCounters)
 		}
-		_, err = persistPipe.Exec(ctx)
-		if err != nil && err != redis.Nil {
+		err = persistPipe.Exec(ctx)
+		if err != nil {
 			p.logger.Errorw("Redis persistence pipeline failed", "error", err)
 		}
 	}
@@ -547,6 +1065,7 @@ func (p *Pool) processBatchSideEffects(ctx context.Context, batch []Job) {
 	for _, event := range deferredEvents {
 		p.processEventSideEffects(ctx, event)
 	}
+	return nil
 }
 
 // minValidUnixTimestamp is 2020-01-01 00:00:00 UTC in seconds.
@@ -582,6 +1101,7 @@ func (p *Pool) convertToClickHouseEvent(event *models.RawEvent, rawJSON string,
 		Timestamp:    ts,
 		MatchID:      matchID,
 		ServerID:     event.ServerID,
+		TenantID:     event.TenantID,
 		MapName:      event.MapName,
 		EventType:    string(event.Type),
 		Damage:       uint32(event.Damage),
@@ -623,11 +1143,13 @@ func (p *Pool) convertToClickHouseEvent(event *models.RawEvent, rawJSON string,
 		ch.ActorName = sanitizeName(event.AttackerName)
 		ch.ActorSMFID = event.AttackerSMFID
 		ch.ActorWeapon = event.Weapon
+		ch.ActorTeam = event.AttackerTeam
 		ch.ActorStance = event.AttackerStance // If available
 
 		ch.TargetID = event.VictimGUID
 		ch.TargetName = sanitizeName(event.VictimName)
 		ch.TargetSMFID = event.VictimSMFID
+		ch.TargetTeam = event.VictimTeam // Team damage needs both sides
 		ch.TargetStance = event.VictimStance
 
 		ch.Damage = uint32(event.Damage)
@@ -656,7 +1178,7 @@ func (p *Pool) convertToClickHouseEvent(event *models.RawEvent, rawJSON string,
 		ch.ActorStance = event.PlayerStance
 		ch.TargetStance = event.TargetStance
 
-	case models.EventMatchOutcome:
+	case models.EventMatchOutcome, models.EventRoundOutcome:
 		ch.ActorID = event.PlayerGUID
 		ch.ActorName = sanitizeName(event.PlayerName)
 		ch.ActorSMFID = event.PlayerSMFID
@@ -675,12 +1197,30 @@ func (p *Pool) convertToClickHouseEvent(event *models.RawEvent, rawJSON string,
 		// Actually raw_json has it, but lets put it in ActorWeapon for now
 		ch.ActorWeapon = event.Objective
 
-	case models.EventVehicleEnter, models.EventVehicleExit, models.EventVehicleCrash:
+	case models.EventVehicleEnter, models.EventVehicleExit, models.EventVehicleCrash, models.EventVehicleChange:
 		ch.ActorID = event.PlayerGUID
 		ch.ActorName = sanitizeName(event.PlayerName)
 		ch.ActorSMFID = event.PlayerSMFID
-		ch.TargetID = event.Entity // Store vehicle entity name here
-		ch.Hitloc = event.Seat     // Reuse Hitloc for Seat
+		ch.ActorTeam = event.PlayerTeam
+		ch.ActorPosX = event.PosX
+		ch.ActorPosY = event.PosY
+		ch.ActorPosZ = event.PosZ
+		v := eventVehicle(event)
+		ch.Vehicle, ch.VehicleSeat = v.vehicle, v.seat
+
+	case models.EventTurretEnter, models.EventTurretExit:
+		ch.ActorID = event.PlayerGUID
+		ch.ActorName = sanitizeName(event.PlayerName)
+		ch.ActorSMFID = event.PlayerSMFID
+		ch.ActorTeam = event.PlayerTeam
+		ch.ActorPosX = event.PosX
+		ch.ActorPosY = event.PosY
+		ch.ActorPosZ = event.PosZ
+		// The mounted gun, so uptime and kills can be split per turret
+		ch.ActorWeapon = event.Turret
+		if ch.ActorWeapon == "" {
+			ch.ActorWeapon = event.Entity
+		}
 
 	default:
 		// Generic player event (Movement, Interaction, Items, etc.)
@@ -722,6 +1262,10 @@ func (p *Pool) processEventSideEffects(ctx context.Context, event *models.RawEve
 		p.handleSpawn(ctx, event)
 	case models.EventTeamWin:
 		p.handleTeamWin(ctx, event)
+	case models.EventRoundEnd:
+		p.handleRoundEnd(ctx, event)
+	case models.EventSpectatorJoin, models.EventSpectatorLeave:
+		p.handleSpectator(ctx, event)
 	}
 }
 
@@ -737,143 +1281,130 @@ func (p *Pool) handleMatchStart(ctx context.Context, event *models.RawEvent) {
 	}
 
 	data, _ := json.Marshal(liveMatch)
-	p.config.Redis.HSet(ctx, "live_matches", event.MatchID, data)
-	p.config.Redis.SAdd(ctx, "active_match_ids", event.MatchID)
+	p.config.LiveState.HSet(ctx, "live_matches", event.MatchID, data)
+	p.config.LiveState.SAdd(ctx, "active_match_ids", event.MatchID)
+	p.config.LiveState.HSet(ctx, liveMatchesSeenKey, event.MatchID, time.Now().Unix())
 
 	// Clear any stale team data for this match
-	p.config.Redis.Del(ctx, "match:"+event.MatchID+":teams")
+	p.config.LiveState.Del(ctx, "match:"+event.MatchID+":teams")
 
 	// Update server status
 	p.updateServerStatus(ctx, event)
 }
 
-// handleMatchEnd removes from live matches, triggers tournament advancement
+// handleMatchEnd synthesizes each player's match_outcome, then removes the
+// match from live state. The winner comes from the match_end event, else the
+// team_win recorded during the match, else the final team scores.
 func (p *Pool) handleMatchEnd(ctx context.Context, event *models.RawEvent) {
-	// Retrieve winning team from live match cache if not in event
-	winningTeam := event.WinningTeam
-	if winningTeam == "" {
-		data, err := p.config.Redis.HGet(ctx, "live_matches", event.MatchID).Bytes()
-		if err == nil {
-			var liveMatch models.LiveMatch
-			if err := json.Unmarshal(data, &liveMatch); err == nil {
-				// We might store winning team in liveMatch structure if we update it on team_win
-				// But for now, let's assume event.WinningTeam is populated or we rely on team_win event
-			}
-		}
-	}
+	live := p.liveMatch(ctx, event.MatchID)
+	winningTeam := p.finalWinner(ctx, event, live)
 
 	// Synthesize Match Outcome Events
 	// Get all players and their teams
-	teams, err := p.config.Redis.HGetAll(ctx, "match:"+event.MatchID+":teams").Result()
+	teams, err := p.config.LiveState.HGetAll(ctx, "match:"+event.MatchID+":teams")
 	if err == nil {
 		// Get Gametype from LiveMatch to pass to event
 		var gametype string
-		if data, err := p.config.Redis.HGet(ctx, "live_matches", event.MatchID).Bytes(); err == nil {
-			var lm models.LiveMatch
-			if json.Unmarshal(data, &lm) == nil {
-				gametype = lm.Gametype
-			}
+		if live != nil {
+			gametype = live.Gametype
 		}
-
-		// Prepare pipeline for SMF ID and Name lookups
-		pipe := p.config.Redis.Pipeline()
-		smfLookups := make(map[string]*redis.StringCmd)
-		nameLookups := make(map[string]*redis.StringCmd)
-		for guid := range teams {
-			smfLookups[guid] = pipe.HGet(ctx, "player_smfids", guid)
-			nameLookups[guid] = pipe.HGet(ctx, "player_names", guid)
+		if gametype == "" {
+			gametype = event.Gametype
 		}
-		pipe.Exec(ctx)
-
-		for guid, team := range teams {
-			outcome := 0 // Loss
-			if team == winningTeam {
-				outcome = 1 // Win
-			}
-
-			// Get SMFID and Name from lookup result
-			var smfid int64
-			if cmd, ok := smfLookups[guid]; ok {
-				if val, err := cmd.Result(); err == nil {
-					fmt.Sscanf(val, "%d", &smfid)
-				}
-			}
-			playerName := ""
-			if cmd, ok := nameLookups[guid]; ok {
-				playerName, _ = cmd.Result()
-			}
-
-			// Create Outcome Event
-			go func(playerGUID, playerTeam, name string, won int, gType string, pid int64) {
-				outcomeEvent := &models.RawEvent{
-					Type:         models.EventMatchOutcome,
-					MatchID:      event.MatchID,
-					ServerID:     event.ServerID,
-					MapName:      event.MapName,
-					Timestamp:    float64(time.Now().Unix()),
-					PlayerGUID:   playerGUID,
-					PlayerName:   name,
-					PlayerTeam:   playerTeam,
-					Gametype:     gType,
-					MatchOutcome: uint8(won), // 1 = win, 0 = loss
-					PlayerSMFID:  pid,
-				}
-				p.Enqueue(outcomeEvent)
-			}(guid, team, playerName, outcome, gametype, smfid)
+		outcomes := logic.TeamOutcomes(teams, winningTeam)
+		if logic.IsFFAGametype(gametype) {
+			// Free-for-all: the top scorer on the final scoreboard wins
+			board, _ := p.config.LiveState.HGetAll(ctx, scoreboardKey(event.MatchID))
+			outcomes = ffaOutcomes(board)
 		}
+
+		p.enqueueOutcomes(ctx, &models.RawEvent{
+			Type:     models.EventMatchOutcome,
+			MatchID:  event.MatchID,
+			ServerID: event.ServerID,
+			MapName:  event.MapName,
+			Gametype: gametype,
+		}, outcomes, teams)
 	}
 
-	p.config.Redis.HDel(ctx, "live_matches", event.MatchID)
-	p.config.Redis.SRem(ctx, "active_match_ids", event.MatchID)
+	p.config.LiveState.HDel(ctx, "live_matches", event.MatchID)
+	p.config.LiveState.SRem(ctx, "active_match_ids", event.MatchID)
+	p.config.LiveState.HDel(ctx, liveMatchesSeenKey, event.MatchID)
 	// Cleanup team data
-	p.config.Redis.Del(ctx, "match:"+event.MatchID+":teams")
-	p.config.Redis.Del(ctx, "match:"+event.MatchID+":players")
+	p.config.LiveState.Del(ctx, "match:"+event.MatchID+":teams")
+	p.config.LiveState.Del(ctx, "match:"+event.MatchID+":players")
+	p.config.LiveState.Del(ctx, scoreboardKey(event.MatchID))
+	p.config.LiveState.Del(ctx, winnerKey(event.MatchID))
+	p.config.LiveState.Del(ctx, spectatorsKey(event.MatchID))
 
 	// Tournament bracket advancement is handled by SMF plugin
 	// See: smf-plugins/mohaa_tournaments/ for bracket management
 }
 
-// handleTeamWin records the winner in Redis so match_end can pick it up
+// handleTeamWin records the winner, and the final scores when the event has
+// them, so match_end can pick them up
 func (p *Pool) handleTeamWin(ctx context.Context, event *models.RawEvent) {
-	// Update live match with winner
-	// We need to extend LiveMatch struct or just store it in a side key
-	// distinct key for winner?
-	p.config.Redis.HSet(ctx, "match:"+event.MatchID+":winner", "team", event.WinningTeam)
+	if winner := logic.EventWinner(event); winner != "" {
+		p.config.LiveState.HSet(ctx, winnerKey(event.MatchID), "team", winner)
+		expireKey(ctx, p.config.LiveState, winnerKey(event.MatchID), p.ttl().MatchKeys)
+	}
+
+	allies, axis := logic.EventScores(event)
+	if allies == 0 && axis == 0 {
+		return
+	}
+	if live := p.liveMatch(ctx, event.MatchID); live != nil {
+		live.AlliesScore, live.AxisScore = allies, axis
+		data, _ := json.Marshal(live)
+		p.config.LiveState.HSet(ctx, "live_matches", event.MatchID, data)
+	}
 }
 
 // handleTeamChange updates player team in Redis
 func (p *Pool) handleTeamChange(ctx context.Context, event *models.RawEvent) {
-	if event.PlayerGUID == "" || event.NewTeam == "" {
+	if event.PlayerGUID == "" || event.NewTeam == "" || p.tagBot(ctx, p.config.LiveState, event) {
 		return
 	}
-	p.config.Redis.HSet(ctx, "match:"+event.MatchID+":teams", event.PlayerGUID, event.NewTeam)
+	p.config.LiveState.HSet(ctx, "match:"+event.MatchID+":teams", event.PlayerGUID, event.NewTeam)
+	expireKey(ctx, p.config.LiveState, "match:"+event.MatchID+":teams", p.ttl().MatchKeys)
 }
 
 // handleSpawn also ensures team is set (backup for team_change)
 func (p *Pool) handleSpawn(ctx context.Context, event *models.RawEvent) {
-	if event.PlayerGUID == "" || event.PlayerTeam == "" {
+	if event.PlayerGUID == "" || event.PlayerTeam == "" || p.tagBot(ctx, p.config.LiveState, event) {
 		return
 	}
-	p.config.Redis.HSet(ctx, "match:"+event.MatchID+":teams", event.PlayerGUID, event.PlayerTeam)
+	p.config.LiveState.HSet(ctx, "match:"+event.MatchID+":teams", event.PlayerGUID, event.PlayerTeam)
+	expireKey(ctx, p.config.LiveState, "match:"+event.MatchID+":teams", p.ttl().MatchKeys)
 }
 
 // handleHeartbeat updates live match state and server status
 func (p *Pool) handleHeartbeat(ctx context.Context, event *models.RawEvent) {
 	// Update live match data
-	data, err := p.config.Redis.HGet(ctx, "live_matches", event.MatchID).Bytes()
+	data, err := p.config.LiveState.HGet(ctx, "live_matches", event.MatchID)
 	if err == nil {
 		var liveMatch models.LiveMatch
-		if json.Unmarshal(data, &liveMatch) == nil {
-			liveMatch.AlliesScore = event.AlliesScore
-			liveMatch.AxisScore = event.AxisScore
+		if json.Unmarshal([]byte(data), &liveMatch) == nil {
+			// Heartbeats without scores keep the last known ones
+			if allies, axis := logic.EventScores(event); allies != 0 || axis != 0 {
+				liveMatch.AlliesScore, liveMatch.AxisScore = allies, axis
+			}
 			liveMatch.PlayerCount = event.PlayerCount
-			liveMatch.RoundNumber = event.RoundNumber
+			if event.RoundNumber > 0 {
+				liveMatch.RoundNumber = event.RoundNumber
+			}
+			liveMatch.Spectators = p.liveSpectators(ctx, event)
+			liveMatch.PeakSpectators = max(liveMatch.PeakSpectators, liveMatch.Spectators)
 
 			newData, _ := json.Marshal(liveMatch)
-			p.config.Redis.HSet(ctx, "live_matches", event.MatchID, newData)
+			p.config.LiveState.HSet(ctx, "live_matches", event.MatchID, newData)
+			p.config.LiveState.HSet(ctx, liveMatchesSeenKey, event.MatchID, time.Now().Unix())
 		}
 	}
 
+	// Heartbeat v2: the roster replaces the live scoreboard
+	p.updateScoreboard(ctx, event)
+
 	// Update server status (Redis + DB)
 	p.updateServerStatus(ctx, event)
 }
@@ -886,7 +1417,8 @@ func (p *Pool) handleKill(ctx context.Context, event *models.RawEvent) {
 
 	// Increment kill counter
 	key := "player:" + event.AttackerGUID + ":kills"
-	newCount, _ := p.config.Redis.Incr(ctx, key).Result()
+	newCount, _ := p.config.LiveState.Incr(ctx, key)
+	expireKey(ctx, p.config.LiveState, key, p.ttl().PlayerCounters)
 
 	// Check achievement thresholds
 	p.checkKillAchievements(ctx, event.AttackerGUID, newCount)
@@ -909,26 +1441,28 @@ func (p *Pool) handleHeadshot(ctx context.Context, event *models.RawEvent) {
 	}
 
 	key := "player:" + guid + ":headshots"
-	newCount, _ := p.config.Redis.Incr(ctx, key).Result()
+	newCount, _ := p.config.LiveState.Incr(ctx, key)
+	expireKey(ctx, p.config.LiveState, key, p.ttl().PlayerCounters)
 
 	p.checkHeadshotAchievements(ctx, guid, newCount)
 }
 
 // handleConnect updates player alias tracking
 func (p *Pool) handleConnect(ctx context.Context, event *models.RawEvent) {
-	if event.PlayerGUID == "" {
+	if event.PlayerGUID == "" || p.tagBot(ctx, p.config.LiveState, event) {
 		return
 	}
 
 	// Update last known name
-	p.config.Redis.HSet(ctx, "player_names", event.PlayerGUID, event.PlayerName)
+	p.config.LiveState.HSet(ctx, "player_names", event.PlayerGUID, event.PlayerName)
 
 	// Track player online status
-	p.config.Redis.SAdd(ctx, "match:"+event.MatchID+":players", event.PlayerGUID)
+	p.config.LiveState.SAdd(ctx, "match:"+event.MatchID+":players", event.PlayerGUID)
+	expireKey(ctx, p.config.LiveState, "match:"+event.MatchID+":players", p.ttl().MatchKeys)
 
 	// Track player SMF ID if available
 	if event.PlayerSMFID > 0 {
-		p.config.Redis.HSet(ctx, "player_smfids", event.PlayerGUID, event.PlayerSMFID)
+		p.config.LiveState.HSet(ctx, "player_smfids", event.PlayerGUID, event.PlayerSMFID)
 	}
 }
 
@@ -938,24 +1472,29 @@ func (p *Pool) handleDisconnect(ctx context.Context, event *models.RawEvent) {
 		return
 	}
 
-	p.config.Redis.SRem(ctx, "match:"+event.MatchID+":players", event.PlayerGUID)
+	p.config.LiveState.SRem(ctx, "match:"+event.MatchID+":players", event.PlayerGUID)
+	p.config.LiveState.HDel(ctx, scoreboardKey(event.MatchID), event.PlayerGUID)
 }
 
 // handleChat checks for claim codes
 func (p *Pool) handleChat(ctx context.Context, event *models.RawEvent) {
 	// Check if message is a claim code (format: !claim MOH-XXXX)
 	msg := event.Message
+	if p.isBot(ctx, event) {
+		return
+	}
 	if len(msg) > 7 && msg[:7] == "!claim " {
 		code := msg[7:]
 		// Verify claim code exists in pending claims
 		claimKey := "identity_claim:" + code
-		userIDStr, err := p.config.Redis.Get(ctx, claimKey).Result()
+		userIDStr, err := p.config.LiveState.Get(ctx, claimKey)
 		if err == nil && userIDStr != "" {
 			// Mark claim as verified with player GUID
-			p.config.Redis.HSet(ctx, claimKey+":verified",
+			p.config.LiveState.HSet(ctx, claimKey+":verified",
 				"player_guid", event.PlayerGUID,
 				"verified_at", time.Unix(int64(event.Timestamp), 0).Format(time.RFC3339),
 			)
+			expireKey(ctx, p.config.LiveState, claimKey+":verified", p.ttl().Claims)
 			p.config.Logger.Sugar().Infow("Claim code verified", "code", code, "guid", event.PlayerGUID)
 		}
 	}
@@ -979,12 +1518,13 @@ func (p *Pool) checkHeadshotAchievements(ctx context.Context, playerGUID string,
 func (p *Pool) grantAchievement(ctx context.Context, playerGUID, achievementID string) {
 	// Check if already unlocked
 	key := "player:" + playerGUID + ":achievements"
-	if p.config.Redis.SIsMember(ctx, key, achievementID).Val() {
+	if isMember, _ := p.config.LiveState.SIsMember(ctx, key, achievementID); isMember {
 		return
 	}
 
 	// Mark as unlocked
-	p.config.Redis.SAdd(ctx, key, achievementID)
+	p.config.LiveState.SAdd(ctx, key, achievementID)
+	expireKey(ctx, p.config.LiveState, key, p.ttl().PlayerCounters)
 
 	// Insert into Postgres
 	_, err := p.config.Postgres.Exec(ctx, `
@@ -1007,7 +1547,8 @@ func (p *Pool) reportQueueDepth() {
 	for {
 		select {
 		case <-ticker.C:
-			queueDepth.Set(float64(len(p.jobQueue)))
+			queueDepth.Set(float64(p.QueueDepth()))
+			p.reportShardDepth()
 		case <-p.ctx.Done():
 			return
 		}
@@ -1016,25 +1557,11 @@ func (p *Pool) reportQueueDepth() {
 
 // Helper functions
 
-func sanitizeName(s string) string {
-	// If no caret, return original string (no allocation)
-	if !strings.Contains(s, "^") {
-		return s
-	}
-
-	var sb strings.Builder
-	sb.Grow(len(s))
-
-	n := len(s)
-	for i := 0; i < n; i++ {
-		// Check for color code format ^[0-9]
-		if s[i] == '^' && i+1 < n && s[i+1] >= '0' && s[i+1] <= '9' {
-			i++ // Skip next char too (the digit)
-			continue
-		}
-		sb.WriteByte(s[i])
+// expireKey (re)applies ttl to key; non-positive ttls leave the key persistent
+func expireKey(ctx context.Context, c db.LiveStateWriter, key string, ttl time.Duration) {
+	if ttl > 0 {
+		c.Expire(ctx, key, ttl)
 	}
-	return sb.String()
 }
 
 func parseOrGenerateUUID(s string) uuid.UUID {
@@ -1056,7 +1583,8 @@ func (p *Pool) updateServerStatus(ctx context.Context, event *models.RawEvent) {
 	statusStr := fmt.Sprintf("players:%d,map:%s,gametype:%s",
 		event.PlayerCount, event.MapName, event.Gametype)
 
-	p.config.Redis.HSet(ctx, "live_servers", event.ServerID, statusStr)
+	p.config.LiveState.HSet(ctx, "live_servers", event.ServerID, statusStr)
+	p.config.LiveState.HSet(ctx, liveServersSeenKey, event.ServerID, time.Now().Unix())
 	// Set expiration handling if needed? Redis Key itself doesn't expire, field doesn't expire.
 	// Logic relies on IsOnline = true if entry exists AND LastSeen logic in Postgres which server_tracking uses
 	// Actually server_tracking lines 155 checks if liveData != "" then sets IsOnline=true.
@@ -0,0 +1,93 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// benchEvents is a mix resembling a live match: mostly fire and movement,
+// some damage and kills
+func benchEvents(n int) []*models.RawEvent {
+	events := make([]*models.RawEvent, n)
+	now := float64(time.Now().Unix())
+	for i := range events {
+		switch i % 10 {
+		case 0:
+			events[i] = &models.RawEvent{
+				Type: models.EventPlayerKill, MatchID: "4f1c2a9e-7a1b-4c7e-9a55-0c8d7b3f2e11", MapName: "obj/obj_team2",
+				AttackerGUID: fmt.Sprintf("guid-%d", i%32), AttackerName: "^1Killer", AttackerX: 100, AttackerY: 200,
+				VictimGUID: fmt.Sprintf("guid-%d", (i+1)%32), VictimName: "^2Victim", VictimX: 150, VictimY: 250,
+				Weapon: "Kar98K", Hitloc: "head", Timestamp: now,
+			}
+		case 1, 2:
+			events[i] = &models.RawEvent{
+				Type: models.EventDamage, MatchID: "4f1c2a9e-7a1b-4c7e-9a55-0c8d7b3f2e11", MapName: "obj/obj_team2",
+				AttackerGUID: "guid-1", VictimGUID: "guid-2", Damage: 35, Weapon: "MP40", Timestamp: now,
+			}
+		case 3, 4, 5, 6:
+			events[i] = &models.RawEvent{
+				Type: models.EventWeaponFire, MatchID: "4f1c2a9e-7a1b-4c7e-9a55-0c8d7b3f2e11", MapName: "obj/obj_team2",
+				PlayerGUID: "guid-3", PlayerName: "Shooter", Weapon: "MP40", PosX: 10, PosY: 20, PosZ: 30, Timestamp: now,
+			}
+		default:
+			events[i] = &models.RawEvent{
+				Type: models.EventJump, MatchID: "4f1c2a9e-7a1b-4c7e-9a55-0c8d7b3f2e11", MapName: "obj/obj_team2",
+				PlayerGUID: "guid-4", PlayerName: "Jumper", PosX: 1, PosY: 2, PosZ: 3, Timestamp: now,
+			}
+		}
+	}
+	return events
+}
+
+func benchJobs(n int) []Job {
+	events := benchEvents(n)
+	jobs := make([]Job, n)
+	for i, event := range events {
+		raw, _ := json.Marshal(event)
+		jobs[i] = Job{Event: event, RawJSON: string(raw), Timestamp: time.Now(), SampleWeight: 1}
+	}
+	return jobs
+}
+
+func BenchmarkConvertToClickHouseEvent(b *testing.B) {
+	p := &Pool{}
+	jobs := benchJobs(100)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		job := jobs[i%len(jobs)]
+		p.convertToClickHouseEvent(job.Event, job.RawJSON, job.Timestamp)
+	}
+}
+
+// BenchmarkInsertRawEvents measures building a ClickHouse batch (conversion
+// and Append) against a no-op connection, i.e. the CPU cost per flush
+func BenchmarkInsertRawEvents(b *testing.B) {
+	for _, size := range []int{100, 1000} {
+		b.Run(fmt.Sprintf("batch=%d", size), func(b *testing.B) {
+			p := &Pool{
+				config: PoolConfig{ClickHouse: &MockClickHouseConn{}},
+				logger: zap.NewNop().Sugar(),
+			}
+			jobs := benchJobs(size)
+			ctx := context.Background()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := p.insertRawEvents(ctx, "mohaa_stats.raw_events", jobs); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*size), "ns/event")
+		})
+	}
+}
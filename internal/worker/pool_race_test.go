@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/openmohaa/stats-api/internal/db"
 	"github.com/openmohaa/stats-api/internal/models"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
@@ -28,14 +29,15 @@ func TestPool_RaceCondition(t *testing.T) {
 		FlushInterval: 10 * time.Millisecond,
 		ClickHouse:    &MockClickHouseConn{},
 		// Postgres left nil, hope we don't hit it
-		Redis:  rdb,
-		Logger: logger,
+		LiveState: db.NewRedisLiveState(rdb),
+		Logger:    logger,
 	}
 
 	p := &Pool{
-		config:   cfg,
-		jobQueue: make(chan Job, cfg.QueueSize),
-		logger:   cfg.Logger.Sugar(),
+		config:    cfg,
+		jobQueue:  make(chan Job, cfg.QueueSize),
+		logger:    cfg.Logger.Sugar(),
+		liveState: newLiveStateBreaker(cfg.LiveStateBreaker),
 	}
 
 	// Manually init achievement worker with mocks to avoid panic if called
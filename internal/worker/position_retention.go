@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/jobs"
+	"github.com/openmohaa/stats-api/internal/logic"
+)
+
+// PositionMinimizer strips the precise positions of events older than the
+// retention (POSITION_RETENTION_DAYS) every night, keeping the grid cells
+// heatmaps are drawn from. Where a player stood months ago is not needed for
+// any stat, and is the bulk of what a position-heavy event stores. A
+// retention of 0 keeps every position.
+type PositionMinimizer struct {
+	svc       logic.PositionRetentionService
+	retention atomic.Int64
+	logger    *zap.SugaredLogger
+}
+
+func NewPositionMinimizer(svc logic.PositionRetentionService, retentionDays int, logger *zap.Logger) *PositionMinimizer {
+	m := &PositionMinimizer{
+		svc:    svc,
+		logger: logger.Sugar(),
+	}
+	m.SetRetentionDays(retentionDays)
+	return m
+}
+
+// SetRetentionDays changes the retention used from the next run
+func (m *PositionMinimizer) SetRetentionDays(days int) {
+	m.retention.Store(int64(days))
+}
+
+// Job is the nightly strip as a scheduled job. Mutations over a month of
+// events take a while, so it gets more time than the default.
+func (m *PositionMinimizer) Job() jobs.Job {
+	return jobs.Job{
+		Name:        "position_retention",
+		Description: "Strips precise positions of events older than the retention, keeping heatmap grid cells",
+		Schedule:    "30 3 * * *",
+		Timeout:     4 * time.Hour,
+		Run:         m.RunOnce,
+	}
+}
+
+// RunOnce strips the positions of the days before the retention
+func (m *PositionMinimizer) RunOnce(ctx context.Context) error {
+	retention := int(m.retention.Load())
+	if retention <= 0 {
+		return nil
+	}
+	before := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -retention)
+	start := time.Now()
+	if err := m.svc.StripPositions(ctx, before); err != nil {
+		return err
+	}
+	m.logger.Infow("Old positions stripped", "before", before.Format(time.DateOnly), "duration", time.Since(start))
+	return nil
+}
@@ -0,0 +1,58 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/jobs"
+	"github.com/openmohaa/stats-api/internal/logic"
+)
+
+var predictionsResolved = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "mohaa_predictions_resolved_total",
+	Help: "Player forecasts scored against the match the player played next",
+})
+
+// PredictionResolver scores pending player forecasts once the players have
+// finished their next match. It runs every five minutes on the job
+// scheduler; accuracy is read over days, so the delay does not matter.
+type PredictionResolver struct {
+	svc    logic.PredictionService
+	logger *zap.SugaredLogger
+}
+
+func NewPredictionResolver(svc logic.PredictionService, logger *zap.Logger) *PredictionResolver {
+	return &PredictionResolver{
+		svc:    svc,
+		logger: logger.Sugar(),
+	}
+}
+
+// Job is the resolver as a scheduled job
+func (r *PredictionResolver) Job() jobs.Job {
+	return jobs.Job{
+		Name:        "prediction_resolve",
+		Description: "Scores pending player forecasts against the match played next",
+		Schedule:    "@every 5m",
+		Timeout:     5 * time.Minute,
+		Run:         r.RunOnce,
+	}
+}
+
+// RunOnce scores the pending forecasts whose next match has ended
+func (r *PredictionResolver) RunOnce(ctx context.Context) error {
+	resolved, err := r.svc.ResolvePredictions(ctx)
+	predictionsResolved.Add(float64(resolved))
+	if err != nil {
+		return fmt.Errorf("resolved %d predictions before failing: %w", resolved, err)
+	}
+	if resolved > 0 {
+		r.logger.Debugw("Predictions resolved", "resolved", resolved)
+	}
+	return nil
+}
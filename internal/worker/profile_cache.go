@@ -0,0 +1,187 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/db"
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+var profileCacheResults = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "mohaa_profile_cache_total",
+	Help: "Precomputed player profiles by result (hit, miss, refreshed, failed)",
+}, []string{"result"})
+
+// profileRefreshTimeout bounds the rebuild of one profile
+const profileRefreshTimeout = 30 * time.Second
+
+// ProfileCacheConfig sets how long a stored profile is served and how long
+// after a player's events it is rebuilt
+type ProfileCacheConfig struct {
+	TTL      time.Duration
+	Debounce time.Duration
+}
+
+// ProfileCache keeps each player's full profile (the /stats/player/{guid}
+// response without cosmetics) as one JSON value in live state, so a profile
+// page during peak traffic is a single GET. A player's events schedule a
+// rebuild Debounce after the first of them, so a busy match costs one rebuild
+// per player rather than one per kill. Profiles of idle players expire after
+// TTL and are rebuilt by the next request.
+type ProfileCache struct {
+	stats  logic.PlayerStatsService
+	store  db.LiveStateStore
+	config atomic.Pointer[ProfileCacheConfig]
+	logger *zap.SugaredLogger
+
+	mu      sync.Mutex
+	pending map[string]time.Time // GUID -> when its rebuild is due
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewProfileCache(stats logic.PlayerStatsService, store db.LiveStateStore, cfg ProfileCacheConfig, logger *zap.Logger) *ProfileCache {
+	c := &ProfileCache{
+		stats:   stats,
+		store:   store,
+		logger:  logger.Sugar(),
+		pending: make(map[string]time.Time),
+		done:    make(chan struct{}),
+	}
+	c.SetConfig(cfg)
+	return c
+}
+
+// SetConfig replaces the TTL and debounce; scheduled rebuilds keep their time
+func (c *ProfileCache) SetConfig(cfg ProfileCacheConfig) {
+	c.config.Store(&cfg)
+}
+
+func profileKey(guid string) string {
+	return "profile:" + guid
+}
+
+// Observe schedules a rebuild of the profiles of the players in an event
+func (c *ProfileCache) Observe(event *models.RawEvent, now time.Time) {
+	if c == nil {
+		return
+	}
+	due := now.Add(c.config.Load().Debounce)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, guid := range []string{event.PlayerGUID, event.AttackerGUID, event.VictimGUID, event.TargetGUID} {
+		if guid == "" || guid == "world" {
+			continue
+		}
+		if _, ok := c.pending[guid]; !ok {
+			c.pending[guid] = due
+		}
+	}
+}
+
+// take returns the GUIDs whose rebuild is due
+func (c *ProfileCache) take(now time.Time) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var guids []string
+	for guid, due := range c.pending {
+		if !now.Before(due) {
+			guids = append(guids, guid)
+			delete(c.pending, guid)
+		}
+	}
+	return guids
+}
+
+// Start rebuilds due profiles in the background until Stop
+func (c *ProfileCache) Start(ctx context.Context) {
+	ctx, c.cancel = context.WithCancel(ctx)
+	go func() {
+		defer close(c.done)
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case now := <-ticker.C:
+				for _, guid := range c.take(now) {
+					if ctx.Err() != nil {
+						return
+					}
+					c.refresh(ctx, guid)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (c *ProfileCache) Stop() {
+	if c.cancel != nil {
+		c.cancel()
+		<-c.done
+	}
+}
+
+func (c *ProfileCache) refresh(ctx context.Context, guid string) {
+	ctx, cancel := context.WithTimeout(ctx, profileRefreshTimeout)
+	defer cancel()
+
+	player, err := c.stats.GetProfile(ctx, guid, logic.AllStatSections())
+	if err != nil {
+		profileCacheResults.WithLabelValues("failed").Inc()
+		c.logger.Warnw("Failed to rebuild player profile", "player", guid, "error", err)
+		return
+	}
+	c.Store(ctx, player)
+	profileCacheResults.WithLabelValues("refreshed").Inc()
+}
+
+// Read returns a stored profile, or nil when there is none
+func (c *ProfileCache) Read(ctx context.Context, guid string) *models.PlayerStats {
+	if c == nil {
+		return nil
+	}
+	data, err := c.store.Get(ctx, profileKey(guid))
+	if err != nil {
+		if !errors.Is(err, db.ErrNotFound) {
+			c.logger.Warnw("Failed to read player profile", "player", guid, "error", err)
+		}
+		profileCacheResults.WithLabelValues("miss").Inc()
+		return nil
+	}
+	var player models.PlayerStats
+	if err := json.Unmarshal([]byte(data), &player); err != nil {
+		profileCacheResults.WithLabelValues("miss").Inc()
+		return nil
+	}
+	profileCacheResults.WithLabelValues("hit").Inc()
+	return &player
+}
+
+// Store saves a full profile for TTL
+func (c *ProfileCache) Store(ctx context.Context, player *models.PlayerStats) {
+	if c == nil {
+		return
+	}
+	data, err := json.Marshal(player)
+	if err != nil {
+		return
+	}
+	if err := c.store.Set(ctx, profileKey(player.GUID), data, c.config.Load().TTL); err != nil {
+		c.logger.Warnw("Failed to store player profile", "player", player.GUID, "error", err)
+	}
+}
@@ -0,0 +1,60 @@
+package worker
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/db"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestProfileCacheDebounce(t *testing.T) {
+	c := NewProfileCache(nil, db.NewMemoryLiveState(), ProfileCacheConfig{TTL: time.Minute, Debounce: 30 * time.Second}, zap.NewNop())
+	now := time.Now()
+
+	c.Observe(&models.RawEvent{Type: models.EventPlayerKill, AttackerGUID: "a", VictimGUID: "b"}, now)
+	c.Observe(&models.RawEvent{Type: models.EventPlayerKill, AttackerGUID: "a", VictimGUID: "world"}, now.Add(20*time.Second))
+	c.Observe(&models.RawEvent{Type: models.EventPlayerSpawn, PlayerGUID: "c"}, now.Add(20*time.Second))
+
+	if got := c.take(now.Add(29 * time.Second)); len(got) != 0 {
+		t.Fatalf("rebuilt %v before the debounce", got)
+	}
+	// Later events do not push a scheduled rebuild back
+	got := c.take(now.Add(30 * time.Second))
+	sort.Strings(got)
+	if want := []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("due = %v, want %v", got, want)
+	}
+	if got := c.take(now.Add(50 * time.Second)); !reflect.DeepEqual(got, []string{"c"}) {
+		t.Errorf("due = %v, want [c]", got)
+	}
+	if got := c.take(now.Add(time.Hour)); len(got) != 0 {
+		t.Errorf("rebuilt %v twice", got)
+	}
+}
+
+func TestProfileCacheReadStore(t *testing.T) {
+	ctx := context.Background()
+	c := NewProfileCache(nil, db.NewMemoryLiveState(), ProfileCacheConfig{TTL: time.Minute}, zap.NewNop())
+
+	if c.Read(ctx, "p1") != nil {
+		t.Fatal("profile read before it was stored")
+	}
+	c.Store(ctx, &models.PlayerStats{GUID: "p1", Name: "Sgt. Baker", Kills: 42})
+	got := c.Read(ctx, "p1")
+	if got == nil || got.Name != "Sgt. Baker" || got.Kills != 42 {
+		t.Errorf("read = %+v, want the stored profile", got)
+	}
+
+	var disabled *ProfileCache
+	disabled.Observe(&models.RawEvent{PlayerGUID: "p1"}, time.Now())
+	disabled.Store(ctx, got)
+	if disabled.Read(ctx, "p1") != nil {
+		t.Error("nil cache returned a profile")
+	}
+}
@@ -0,0 +1,178 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/openmohaa/stats-api/internal/db"
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+var questsProgress = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "mohaa_quests_total",
+	Help: "New player quest chain events (enrolled, step, completed)",
+}, []string{"event"})
+
+const (
+	// questKeyTTL keeps quest counters a day past the quest window
+	questKeyTTL = logic.QuestWindow + 24*time.Hour
+	// questRecheck is how long a GUID found not to be new is not looked up again
+	questRecheck = 6 * time.Hour
+	// questEnrollTimeout bounds one enrollment check
+	questEnrollTimeout = 10 * time.Second
+)
+
+// questChain is the onboarding chain and the GUIDs working on it
+type questChain struct {
+	steps    []models.Challenge
+	enrolled map[string]bool
+}
+
+// refreshQuest reloads the onboarding chain and its enrolled GUIDs
+func (e *ChallengeEngine) refreshQuest(ctx context.Context, now time.Time) {
+	steps, err := e.svc.QuestChain(ctx, models.QuestChainOnboarding)
+	if err != nil {
+		e.logger.Warnw("Failed to load quest chain", "chain", models.QuestChainOnboarding, "error", err)
+		return
+	}
+	guids, err := e.svc.Enrolled(ctx, models.QuestChainOnboarding, now)
+	if err != nil {
+		e.logger.Warnw("Failed to load quest enrollments", "chain", models.QuestChainOnboarding, "error", err)
+		return
+	}
+	enrolled := make(map[string]bool, len(guids))
+	for _, guid := range guids {
+		enrolled[guid] = true
+	}
+	e.quest.Store(&questChain{steps: steps, enrolled: enrolled})
+}
+
+// Observe enrolls players connecting for the first time in the onboarding
+// chain. The ClickHouse check runs off the ingest path.
+func (e *ChallengeEngine) Observe(event *models.RawEvent, now time.Time) {
+	if guid := e.enrollCandidate(event, now); guid != "" {
+		go e.enroll(guid, now)
+	}
+}
+
+// enrollCandidate returns a connecting GUID that may need enrolling
+func (e *ChallengeEngine) enrollCandidate(event *models.RawEvent, now time.Time) string {
+	if e == nil || event.Type != models.EventConnect || event.PlayerGUID == "" {
+		return ""
+	}
+	q := e.quest.Load()
+	if q == nil || len(q.steps) == 0 || q.enrolled[event.PlayerGUID] {
+		return ""
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if now.Sub(e.lastSweep) >= questRecheck {
+		for guid, at := range e.checked {
+			if now.Sub(at) >= questRecheck {
+				delete(e.checked, guid)
+			}
+		}
+		e.lastSweep = now
+	}
+	if _, ok := e.checked[event.PlayerGUID]; ok {
+		return ""
+	}
+	e.checked[event.PlayerGUID] = now
+	return event.PlayerGUID
+}
+
+func (e *ChallengeEngine) enroll(guid string, now time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), questEnrollTimeout)
+	defer cancel()
+
+	enrolled, err := e.svc.Enroll(ctx, guid, models.QuestChainOnboarding, now)
+	if err != nil {
+		e.logger.Warnw("Failed to check quest enrollment", "player", guid, "error", err)
+		e.mu.Lock()
+		delete(e.checked, guid)
+		e.mu.Unlock()
+		return
+	}
+	if !enrolled {
+		return
+	}
+
+	// Count from this connect on; other instances pick it up on refresh
+	for {
+		q := e.quest.Load()
+		if q.enrolled[guid] {
+			return
+		}
+		next := &questChain{steps: q.steps, enrolled: make(map[string]bool, len(q.enrolled)+1)}
+		for g := range q.enrolled {
+			next.enrolled[g] = true
+		}
+		next.enrolled[guid] = true
+		if e.quest.CompareAndSwap(q, next) {
+			break
+		}
+	}
+	questsProgress.WithLabelValues("enrolled").Inc()
+	e.logger.Infow("Player enrolled in quest chain", "player", guid, "chain", models.QuestChainOnboarding)
+}
+
+// questSteps returns the quest counters an event increments
+func (e *ChallengeEngine) questSteps(event *models.RawEvent) []challengeStep {
+	if e == nil {
+		return nil
+	}
+	q := e.quest.Load()
+	if q == nil || len(q.enrolled) == 0 {
+		return nil
+	}
+	var steps []challengeStep
+	for i := range q.steps {
+		c := &q.steps[i]
+		guid, name, ok := challengeCredit(c, event)
+		if !ok || !q.enrolled[guid] {
+			continue
+		}
+		step := newChallengeStep(questKey(models.QuestChainOnboarding, c.Code, guid), questKeyTTL, c, guid, name, event)
+		step.chain = models.QuestChainOnboarding
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+func questKey(chain, code, guid string) string {
+	return "quest:" + chain + ":" + code + ":" + guid
+}
+
+// completeQuestStep stores a finished step, off the worker's path; the chain
+// is finished with its last step
+func (e *ChallengeEngine) completeQuestStep(step challengeStep, at time.Time) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), questEnrollTimeout)
+		defer cancel()
+
+		chainDone, err := e.svc.CompleteQuestStep(ctx, step.guid, step.chain, step.challenge.Code, at)
+		if err != nil {
+			e.logger.Errorw("Failed to store quest step", "chain", step.chain, "step", step.challenge.Code, "player", step.guid, "error", err)
+			return
+		}
+		questsProgress.WithLabelValues("step").Inc()
+		if chainDone {
+			questsProgress.WithLabelValues("completed").Inc()
+			e.logger.Infow("Quest chain completed", "chain", step.chain, "player", step.guid)
+		}
+	}()
+}
+
+// ReadQuestProgress reads a player's counters for a chain's steps, in order
+func ReadQuestProgress(ctx context.Context, store db.LiveStateStore, chain string, steps []models.Challenge, guid string) ([]int64, error) {
+	keys := make([]string, len(steps))
+	for i, c := range steps {
+		keys[i] = questKey(chain, c.Code, guid)
+	}
+	return readCounters(ctx, store, keys)
+}
@@ -0,0 +1,83 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// enrollingChallenges enrolls every GUID it is asked about
+type enrollingChallenges struct {
+	logic.ChallengesService
+	calls int
+}
+
+func (f *enrollingChallenges) Enroll(ctx context.Context, guid, chain string, now time.Time) (bool, error) {
+	f.calls++
+	return true, nil
+}
+
+func TestQuestEnrollment(t *testing.T) {
+	svc := &enrollingChallenges{}
+	e := NewChallengeEngine(svc, 3, "", zap.NewNop())
+	e.quest.Store(&questChain{
+		steps:    []models.Challenge{{Code: "first_kills", Metric: models.ChallengeKills, Target: 10}},
+		enrolled: map[string]bool{},
+	})
+	now := time.Now()
+	connect := &models.RawEvent{Type: models.EventConnect, PlayerGUID: "new"}
+
+	if guid := e.enrollCandidate(connect, now); guid != "new" {
+		t.Fatalf("candidate = %q", guid)
+	}
+	if guid := e.enrollCandidate(connect, now.Add(time.Minute)); guid != "" {
+		t.Errorf("rechecked %q within %v", guid, questRecheck)
+	}
+	if guid := e.enrollCandidate(&models.RawEvent{Type: models.EventPlayerKill, PlayerGUID: "new"}, now); guid != "" {
+		t.Error("non-connect event is a candidate")
+	}
+
+	kill := &models.RawEvent{Type: models.EventPlayerKill, AttackerGUID: "new", VictimGUID: "v", Weapon: "MP40"}
+	if steps := e.questSteps(kill); len(steps) != 0 {
+		t.Fatalf("counted before enrollment: %+v", steps)
+	}
+
+	e.enroll("new", now)
+	if svc.calls != 1 {
+		t.Errorf("Enroll called %d times", svc.calls)
+	}
+	steps := e.questSteps(kill)
+	if len(steps) != 1 || steps[0].key != "quest:onboarding:first_kills:new" || steps[0].chain != models.QuestChainOnboarding {
+		t.Fatalf("steps = %+v", steps)
+	}
+	if steps[0].ttl != questKeyTTL {
+		t.Errorf("ttl = %v", steps[0].ttl)
+	}
+	if guid := e.enrollCandidate(&models.RawEvent{Type: models.EventConnect, PlayerGUID: "new"}, now.Add(questRecheck)); guid != "" {
+		t.Error("enrolled GUID is a candidate again")
+	}
+}
+
+func TestDistinctWeaponStep(t *testing.T) {
+	weapons := &models.Challenge{Code: "try_weapons", Metric: models.ChallengeWeapons, Target: 3}
+	kill := &models.RawEvent{Type: models.EventPlayerKill, AttackerGUID: "a", VictimGUID: "v", Weapon: " Thompson "}
+
+	guid, name, ok := challengeCredit(weapons, kill)
+	if !ok {
+		t.Fatal("kill not credited")
+	}
+	step := newChallengeStep(questKey("onboarding", weapons.Code, guid), questKeyTTL, weapons, guid, name, kill)
+	if step.distinct != "quest:onboarding:try_weapons:a:thompson" {
+		t.Errorf("distinct = %q", step.distinct)
+	}
+
+	kill.Weapon = ""
+	if _, _, ok := challengeCredit(weapons, kill); ok {
+		t.Error("kill without a weapon credited")
+	}
+}
@@ -0,0 +1,297 @@
+package worker
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/db"
+)
+
+// Hashes of id -> unix time of the last write, used to age out live entries
+const (
+	liveMatchesSeenKey = "live_matches_seen"
+	liveServersSeenKey = "live_servers_seen"
+)
+
+var (
+	redisKeysByPrefix = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mohaa_redis_keys",
+		Help: "Number of Redis keys by key pattern",
+	}, []string{"prefix"})
+
+	redisMemoryByPrefix = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mohaa_redis_memory_bytes_estimate",
+		Help: "Estimated Redis memory by key pattern (sampled MEMORY USAGE x key count)",
+	}, []string{"prefix"})
+
+	redisUsedMemory = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "mohaa_redis_used_memory_bytes",
+		Help: "Redis used_memory as reported by INFO",
+	})
+
+	redisJanitorRemoved = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "mohaa_redis_janitor_removed_total",
+		Help: "Stale live entries removed by the Redis janitor",
+	}, []string{"kind"})
+)
+
+// JanitorConfig controls the periodic Redis cleanup
+type JanitorConfig struct {
+	Interval time.Duration
+	// LiveIdleTTL is how long a live match/server may go without a write
+	// before it is treated as abandoned (server crashed, match_end lost)
+	LiveIdleTTL time.Duration
+	// ScanLimit caps how many keys one memory report walks
+	ScanLimit int
+	// SamplePerPrefix is how many keys per pattern get a MEMORY USAGE call
+	SamplePerPrefix int
+}
+
+// RedisJanitor removes stale live-state entries and, when the store is
+// Redis-backed, reports memory by prefix
+type RedisJanitor struct {
+	state  db.LiveStateStore
+	redis  *redis.Client
+	config JanitorConfig
+	// idleTTL mirrors config.LiveIdleTTL and is replaced on config reload
+	idleTTL atomic.Int64
+	logger  *zap.SugaredLogger
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+func NewRedisJanitor(state db.LiveStateStore, cfg JanitorConfig, logger *zap.Logger) *RedisJanitor {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 5 * time.Minute
+	}
+	if cfg.LiveIdleTTL <= 0 {
+		cfg.LiveIdleTTL = 15 * time.Minute
+	}
+	if cfg.ScanLimit <= 0 {
+		cfg.ScanLimit = 100000
+	}
+	if cfg.SamplePerPrefix <= 0 {
+		cfg.SamplePerPrefix = 20
+	}
+	client, _ := db.RedisClientOf(state)
+	j := &RedisJanitor{
+		state:  state,
+		redis:  client,
+		config: cfg,
+		logger: logger.Sugar(),
+		done:   make(chan struct{}),
+	}
+	j.idleTTL.Store(int64(cfg.LiveIdleTTL))
+	return j
+}
+
+// SetLiveIdleTTL changes how long live entries may idle, from the next sweep
+func (j *RedisJanitor) SetLiveIdleTTL(ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = 15 * time.Minute
+	}
+	j.idleTTL.Store(int64(ttl))
+}
+
+func (j *RedisJanitor) Start(ctx context.Context) {
+	ctx, j.cancel = context.WithCancel(ctx)
+	go func() {
+		defer close(j.done)
+		ticker := time.NewTicker(j.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				j.RunOnce(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (j *RedisJanitor) Stop() {
+	if j.cancel != nil {
+		j.cancel()
+		<-j.done
+	}
+}
+
+// RunOnce performs a single sweep and memory report
+func (j *RedisJanitor) RunOnce(ctx context.Context) {
+	cutoff := time.Now().Add(-time.Duration(j.idleTTL.Load())).Unix()
+
+	if n := j.sweepLive(ctx, "live_matches", liveMatchesSeenKey, cutoff, j.dropMatch); n > 0 {
+		redisJanitorRemoved.WithLabelValues("live_match").Add(float64(n))
+		j.logger.Infow("Removed stale live matches", "count", n)
+	}
+	if n := j.sweepLive(ctx, "live_servers", liveServersSeenKey, cutoff, nil); n > 0 {
+		redisJanitorRemoved.WithLabelValues("live_server").Add(float64(n))
+		j.logger.Infow("Removed stale live servers", "count", n)
+	}
+
+	// Match ids whose live entry is already gone
+	if ids, err := j.state.SMembers(ctx, "active_match_ids"); err == nil {
+		for _, id := range ids {
+			if exists, _ := j.state.HExists(ctx, "live_matches", id); !exists {
+				j.state.SRem(ctx, "active_match_ids", id)
+				redisJanitorRemoved.WithLabelValues("active_match_id").Inc()
+			}
+		}
+	}
+
+	if mem, ok := j.state.(*db.MemoryLiveState); ok {
+		if n := mem.PurgeExpired(); n > 0 {
+			redisJanitorRemoved.WithLabelValues("expired_key").Add(float64(n))
+		}
+		return
+	}
+	if j.redis != nil {
+		j.reportMemory(ctx)
+	}
+}
+
+// sweepLive removes fields of liveKey whose last write (tracked in seenKey) is
+// older than cutoff. Entries without a seen timestamp predate tracking and
+// get one now, so they age out on a later sweep instead of immediately.
+func (j *RedisJanitor) sweepLive(ctx context.Context, liveKey, seenKey string, cutoff int64, drop func(context.Context, db.LiveStatePipeline, string)) int {
+	live, err := j.state.HKeys(ctx, liveKey)
+	if err != nil {
+		j.logger.Warnw("Janitor failed to read live entries", "key", liveKey, "error", err)
+		return 0
+	}
+	seen, err := j.state.HGetAll(ctx, seenKey)
+	if err != nil {
+		j.logger.Warnw("Janitor failed to read seen times", "key", seenKey, "error", err)
+		return 0
+	}
+
+	now := time.Now().Unix()
+	removed := 0
+	pipe := j.state.Pipeline()
+	liveSet := make(map[string]struct{}, len(live))
+
+	for _, id := range live {
+		liveSet[id] = struct{}{}
+		ts, ok := seen[id]
+		if !ok {
+			pipe.HSet(ctx, seenKey, id, now)
+			continue
+		}
+		if last, err := strconv.ParseInt(ts, 10, 64); err == nil && last < cutoff {
+			pipe.HDel(ctx, liveKey, id)
+			pipe.HDel(ctx, seenKey, id)
+			if drop != nil {
+				drop(ctx, pipe, id)
+			}
+			removed++
+		}
+	}
+	for id := range seen {
+		if _, ok := liveSet[id]; !ok {
+			pipe.HDel(ctx, seenKey, id)
+		}
+	}
+
+	if err := pipe.Exec(ctx); err != nil {
+		j.logger.Warnw("Janitor sweep pipeline failed", "key", liveKey, "error", err)
+		return 0
+	}
+	return removed
+}
+
+func (j *RedisJanitor) dropMatch(ctx context.Context, pipe db.LiveStatePipeline, matchID string) {
+	pipe.SRem(ctx, "active_match_ids", matchID)
+	pipe.Del(ctx,
+		"match:"+matchID+":teams",
+		"match:"+matchID+":players",
+		"match:"+matchID+":winner",
+		scoreboardKey(matchID),
+	)
+}
+
+// reportMemory walks the keyspace (up to ScanLimit keys) and exports key
+// counts and estimated memory per key pattern
+func (j *RedisJanitor) reportMemory(ctx context.Context) {
+	if info, err := j.redis.Info(ctx, "memory").Result(); err == nil {
+		if v, ok := parseInfoField(info, "used_memory"); ok {
+			redisUsedMemory.Set(v)
+		}
+	}
+
+	counts := make(map[string]int)
+	samples := make(map[string][]string)
+	scanned := 0
+
+	var cursor uint64
+	for scanned < j.config.ScanLimit {
+		keys, next, err := j.redis.Scan(ctx, cursor, "*", 1000).Result()
+		if err != nil {
+			j.logger.Warnw("Janitor key scan failed", "error", err)
+			return
+		}
+		for _, key := range keys {
+			prefix := redisKeyPattern(key)
+			counts[prefix]++
+			if len(samples[prefix]) < j.config.SamplePerPrefix {
+				samples[prefix] = append(samples[prefix], key)
+			}
+		}
+		scanned += len(keys)
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+
+	redisKeysByPrefix.Reset()
+	redisMemoryByPrefix.Reset()
+	for prefix, count := range counts {
+		redisKeysByPrefix.WithLabelValues(prefix).Set(float64(count))
+
+		var total int64
+		var sampled int
+		for _, key := range samples[prefix] {
+			if n, err := j.redis.MemoryUsage(ctx, key).Result(); err == nil {
+				total += n
+				sampled++
+			}
+		}
+		if sampled > 0 {
+			redisMemoryByPrefix.WithLabelValues(prefix).Set(float64(total) / float64(sampled) * float64(count))
+		}
+	}
+}
+
+// redisKeyPattern collapses ids out of a key so metrics stay low-cardinality:
+// "player:<guid>:kills" -> "player:*:kills", "claim:<code>" -> "claim:*"
+func redisKeyPattern(key string) string {
+	parts := strings.Split(key, ":")
+	switch len(parts) {
+	case 1:
+		return key
+	case 2:
+		return parts[0] + ":*"
+	default:
+		return parts[0] + ":*:" + parts[len(parts)-1]
+	}
+}
+
+func parseInfoField(info, field string) (float64, bool) {
+	for _, line := range strings.Split(info, "\n") {
+		if v, ok := strings.CutPrefix(strings.TrimSpace(line), field+":"); ok {
+			f, err := strconv.ParseFloat(v, 64)
+			return f, err == nil
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,115 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/db"
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// roundWinner decides which team won a round: the team the round_end names,
+// else the team whose score went up more since the last known scores. It
+// returns "" when neither tells.
+func roundWinner(event *models.RawEvent, live *models.LiveMatch) string {
+	if winner := logic.EventWinner(event); winner == string(models.TeamAllies) || winner == string(models.TeamAxis) {
+		return winner
+	}
+	allies, axis := logic.EventScores(event)
+	if live == nil || (allies == 0 && axis == 0) {
+		return ""
+	}
+	alliesGain, axisGain := allies-live.AlliesScore, axis-live.AxisScore
+	switch {
+	case alliesGain > axisGain:
+		return string(models.TeamAllies)
+	case axisGain > alliesGain:
+		return string(models.TeamAxis)
+	}
+	return ""
+}
+
+// handleRoundEnd synthesizes each team player's round_outcome and moves the
+// live match on to the next round. Rounds without a known winner get no
+// outcomes rather than a loss for everyone.
+func (p *Pool) handleRoundEnd(ctx context.Context, event *models.RawEvent) {
+	live := p.liveMatch(ctx, event.MatchID)
+	if live == nil {
+		return
+	}
+
+	round := event.RoundNumber
+	if round == 0 {
+		round = live.RoundNumber
+	}
+	winner := roundWinner(event, live)
+
+	// Scores after the round; rounds that do not send them count one win
+	allies, axis := logic.EventScores(event)
+	if allies == 0 && axis == 0 {
+		allies, axis = live.AlliesScore, live.AxisScore
+		switch winner {
+		case string(models.TeamAllies):
+			allies++
+		case string(models.TeamAxis):
+			axis++
+		}
+	}
+
+	if winner != "" && !logic.IsFFAGametype(live.Gametype) {
+		teams, err := p.config.LiveState.HGetAll(ctx, "match:"+event.MatchID+":teams")
+		if err == nil {
+			p.enqueueOutcomes(ctx, &models.RawEvent{
+				Type:        models.EventRoundOutcome,
+				MatchID:     event.MatchID,
+				ServerID:    event.ServerID,
+				MapName:     event.MapName,
+				Gametype:    live.Gametype,
+				RoundNumber: round,
+				AlliesScore: allies,
+				AxisScore:   axis,
+			}, logic.TeamOutcomes(teams, winner), teams)
+		}
+	}
+
+	live.RoundNumber = round + 1
+	live.AlliesScore, live.AxisScore = allies, axis
+	data, _ := json.Marshal(live)
+	p.config.LiveState.HSet(ctx, "live_matches", event.MatchID, data)
+}
+
+// enqueueOutcomes queues one copy of the outcome event template per player,
+// filled with the player's name, SMF ID, team and outcome (1 = win, 0 = loss).
+// Bots get no outcome.
+func (p *Pool) enqueueOutcomes(ctx context.Context, template *models.RawEvent, outcomes map[string]uint8, teams map[string]string) {
+	for guid := range p.knownBots(ctx) {
+		delete(outcomes, guid)
+	}
+
+	// Prepare pipeline for SMF ID and Name lookups
+	pipe := p.config.LiveState.Pipeline()
+	smfLookups := make(map[string]*db.StringResult)
+	nameLookups := make(map[string]*db.StringResult)
+	for guid := range outcomes {
+		smfLookups[guid] = pipe.HGet(ctx, "player_smfids", guid)
+		nameLookups[guid] = pipe.HGet(ctx, "player_names", guid)
+	}
+	pipe.Exec(ctx)
+
+	for guid, outcome := range outcomes {
+		outcomeEvent := *template
+		outcomeEvent.Timestamp = float64(time.Now().Unix())
+		outcomeEvent.PlayerGUID = guid
+		outcomeEvent.PlayerTeam = logic.NormalizeTeam(teams[guid])
+		outcomeEvent.MatchOutcome = outcome
+		if val, err := smfLookups[guid].Result(); err == nil {
+			fmt.Sscanf(val, "%d", &outcomeEvent.PlayerSMFID)
+		}
+		outcomeEvent.PlayerName, _ = nameLookups[guid].Result()
+
+		go p.Enqueue(&outcomeEvent)
+	}
+}
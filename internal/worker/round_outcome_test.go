@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/openmohaa/stats-api/internal/db"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestRoundWinner(t *testing.T) {
+	live := &models.LiveMatch{AlliesScore: 2, AxisScore: 3}
+	tests := []struct {
+		name  string
+		event models.RawEvent
+		live  *models.LiveMatch
+		want  string
+	}{
+		{"round_end names the winner", models.RawEvent{WinningTeam: "German", AlliesScore: 3, AxisScore: 3}, live, "axis"},
+		{"allies score went up", models.RawEvent{AlliedScore: 3, AxisScore: 3}, live, "allies"},
+		{"axis score went up", models.RawEvent{AlliesScore: 2, AxisScore: 4}, live, "axis"},
+		{"scores unchanged", models.RawEvent{AlliesScore: 2, AxisScore: 3}, live, ""},
+		{"no scores", models.RawEvent{}, live, ""},
+		{"no live match", models.RawEvent{AlliesScore: 1}, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := roundWinner(&tt.event, tt.live); got != tt.want {
+				t.Errorf("roundWinner() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleRoundEndAdvancesLiveMatch(t *testing.T) {
+	ctx := context.Background()
+	store := db.NewMemoryLiveState()
+	p := &Pool{config: PoolConfig{LiveState: store, RedisTTL: RedisTTLConfig{}.withDefaults()}}
+
+	data, _ := json.Marshal(models.LiveMatch{MatchID: "m1", Gametype: "obj", RoundNumber: 1})
+	store.HSet(ctx, "live_matches", "m1", data)
+
+	// A round_end without scores counts one win for the team it names
+	p.handleRoundEnd(ctx, &models.RawEvent{Type: models.EventRoundEnd, MatchID: "m1", WinningTeam: "allies"})
+	live := p.liveMatch(ctx, "m1")
+	if live.RoundNumber != 2 || live.AlliesScore != 1 || live.AxisScore != 0 {
+		t.Fatalf("after round 1: %+v, want round 2 at 1-0", live)
+	}
+
+	// Sent scores replace the counted ones
+	p.handleRoundEnd(ctx, &models.RawEvent{Type: models.EventRoundEnd, MatchID: "m1", AlliesScore: 1, AxisScore: 1})
+	live = p.liveMatch(ctx, "m1")
+	if live.RoundNumber != 3 || live.AlliesScore != 1 || live.AxisScore != 1 {
+		t.Fatalf("after round 2: %+v, want round 3 at 1-1", live)
+	}
+}
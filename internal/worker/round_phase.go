@@ -0,0 +1,86 @@
+package worker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// Round phases stored in raw_events.round_phase and movement_events.round_phase.
+// The player aggregates only count events tagged PhaseRound.
+const (
+	PhaseRound        = "round"
+	PhaseWarmup       = "warmup"
+	PhaseIntermission = "intermission"
+)
+
+// phaseBoundaries are always tagged PhaseRound: they mark the phases instead
+// of happening in one, and match_outcome and round_outcome must always reach
+// the aggregates
+var phaseBoundaries = map[models.EventType]bool{
+	models.EventMatchStart:        true,
+	models.EventMatchEnd:          true,
+	models.EventMatchOutcome:      true,
+	models.EventRoundOutcome:      true,
+	models.EventRoundStart:        true,
+	models.EventRoundEnd:          true,
+	models.EventWarmupStart:       true,
+	models.EventWarmupEnd:         true,
+	models.EventIntermissionStart: true,
+}
+
+// roundPhases tags events with the phase their match was in when they
+// arrived, following warmup_start/warmup_end, round_start/round_end and
+// intermission_start. Matches without phase events stay in PhaseRound, as do
+// all matches after a restart until their next phase event.
+type roundPhases struct {
+	mu      sync.Mutex
+	matches *matchTracker[*matchPhase]
+}
+
+type matchPhase struct {
+	phase string
+}
+
+func newRoundPhases() *roundPhases {
+	return &roundPhases{matches: newMatchTracker[*matchPhase]()}
+}
+
+// tag advances the event's match and returns the phase to store with it
+func (r *roundPhases) tag(event *models.RawEvent, now time.Time) string {
+	if r == nil || event.MatchID == "" {
+		return PhaseRound
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.matches.sweep(now)
+
+	m := r.matches.get(event.MatchID, now, func() *matchPhase { return &matchPhase{phase: PhaseRound} })
+
+	switch event.Type {
+	case models.EventWarmupStart:
+		m.phase = PhaseWarmup
+	case models.EventWarmupEnd, models.EventRoundStart, models.EventMatchStart:
+		m.phase = PhaseRound
+	case models.EventRoundEnd, models.EventIntermissionStart:
+		m.phase = PhaseIntermission
+	case models.EventMatchEnd:
+		r.matches.end(event.MatchID)
+	}
+
+	if phaseBoundaries[event.Type] {
+		return PhaseRound
+	}
+	return m.phase
+}
+
+// jobRoundPhase returns the phase stored with a job; jobs queued without one
+// (replays, tests) count as in-round
+func jobRoundPhase(job Job) string {
+	if job.RoundPhase == "" {
+		return PhaseRound
+	}
+	return job.RoundPhase
+}
@@ -0,0 +1,58 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestRoundPhases(t *testing.T) {
+	r := newRoundPhases()
+	now := time.Now()
+
+	steps := []struct {
+		event models.EventType
+		want  string
+	}{
+		{models.EventPlayerKill, PhaseRound}, // no phase events yet
+		{models.EventWarmupStart, PhaseRound},
+		{models.EventPlayerKill, PhaseWarmup},
+		{models.EventWarmupEnd, PhaseRound},
+		{models.EventRoundStart, PhaseRound},
+		{models.EventPlayerKill, PhaseRound},
+		{models.EventRoundEnd, PhaseRound},
+		{models.EventPlayerKill, PhaseIntermission},
+		{models.EventRoundStart, PhaseRound},
+		{models.EventPlayerKill, PhaseRound},
+		{models.EventIntermissionStart, PhaseRound},
+		{models.EventWeaponFire, PhaseIntermission},
+		{models.EventMatchOutcome, PhaseRound},
+		{models.EventMatchEnd, PhaseRound},
+	}
+	for i, step := range steps {
+		if got := r.tag(&models.RawEvent{Type: step.event, MatchID: "m1"}, now); got != step.want {
+			t.Errorf("step %d (%s): phase = %s, want %s", i, step.event, got, step.want)
+		}
+	}
+	if r.matches.len() != 0 {
+		t.Errorf("match_end left %d matches tracked", r.matches.len())
+	}
+
+	// Matches are tracked separately
+	r.tag(&models.RawEvent{Type: models.EventWarmupStart, MatchID: "a"}, now)
+	if got := r.tag(&models.RawEvent{Type: models.EventPlayerKill, MatchID: "b"}, now); got != PhaseRound {
+		t.Errorf("other match phase = %s, want round", got)
+	}
+
+	// Idle matches are forgotten
+	later := now.Add(2 * matchIdle)
+	if got := r.tag(&models.RawEvent{Type: models.EventPlayerKill, MatchID: "a"}, later); got != PhaseRound {
+		t.Errorf("idle match phase = %s, want round", got)
+	}
+
+	var nilTracker *roundPhases
+	if got := nilTracker.tag(&models.RawEvent{Type: models.EventPlayerKill, MatchID: "m"}, now); got != PhaseRound {
+		t.Errorf("nil tracker phase = %s, want round", got)
+	}
+}
@@ -0,0 +1,151 @@
+package worker
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+var eventsSampledOut = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "mohaa_events_sampled_out_total",
+	Help: "Events not stored because their type is sampled",
+}, []string{"event_type"})
+
+// unsampledEvents drive live match state, kill/death accounting or
+// achievements that need every occurrence, so they are always stored
+var unsampledEvents = map[models.EventType]bool{
+	models.EventGameInit:          true,
+	models.EventGameStart:         true,
+	models.EventGameEnd:           true,
+	models.EventMatchStart:        true,
+	models.EventMatchEnd:          true,
+	models.EventMatchOutcome:      true,
+	models.EventRoundOutcome:      true,
+	models.EventRoundStart:        true,
+	models.EventRoundEnd:          true,
+	models.EventHeartbeat:         true,
+	models.EventConnect:           true,
+	models.EventDisconnect:        true,
+	models.EventPlayerSpawn:       true,
+	models.EventTeamJoin:          true,
+	models.EventTeamWin:           true,
+	models.EventSpectatorJoin:     true,
+	models.EventSpectatorLeave:    true,
+	models.EventChat:              true,
+	models.EventPlayerAuth:        true,
+	models.EventPlayerKill:        true,
+	models.EventDeath:             true,
+	models.EventBotKilled:         true,
+	models.EventPlayerBash:        true,
+	models.EventPlayerRoadkill:    true,
+	models.EventPlayerTeamkill:    true,
+	models.EventPlayerSuicide:     true,
+	models.EventPlayerCrushed:     true,
+	models.EventPlayerTelefragged: true,
+}
+
+// Sampler stores only a fraction of high-volume event types. A type sampled
+// at rate r keeps about one event in round(1/r); each kept event carries that
+// factor as its sample weight so aggregates can scale counts back up.
+type Sampler struct {
+	weights atomic.Pointer[map[models.EventType]uint16]
+}
+
+// NewSampler builds a sampler from a rate spec such as
+// "weapon_fire=0.1,player_jump=0.25". An empty spec stores everything.
+func NewSampler(spec string) (*Sampler, error) {
+	s := &Sampler{}
+	if err := s.SetRates(spec); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// SetRates replaces the sampling rates from the next event on. On error the
+// current rates are kept.
+func (s *Sampler) SetRates(spec string) error {
+	weights, err := ParseSampleRates(spec)
+	if err != nil {
+		return err
+	}
+	s.weights.Store(&weights)
+	return nil
+}
+
+// Weights returns the active sample weight per sampled event type
+func (s *Sampler) Weights() map[models.EventType]uint16 {
+	current := s.weights.Load()
+	if current == nil {
+		return nil
+	}
+	out := make(map[models.EventType]uint16, len(*current))
+	for k, v := range *current {
+		out[k] = v
+	}
+	return out
+}
+
+// Sample reports whether to store an event and the weight to record on it.
+// The decision hashes the event's JSON so a retried batch keeps the same events.
+func (s *Sampler) Sample(eventType models.EventType, rawJSON []byte) (uint16, bool) {
+	if s == nil {
+		return 1, true
+	}
+	current := s.weights.Load()
+	if current == nil {
+		return 1, true
+	}
+	weight, ok := (*current)[eventType]
+	if !ok || weight <= 1 {
+		return 1, true
+	}
+
+	h := fnv.New32a()
+	h.Write(rawJSON)
+	if h.Sum32()%uint32(weight) != 0 {
+		eventsSampledOut.WithLabelValues(string(eventType)).Inc()
+		return 0, false
+	}
+	return weight, true
+}
+
+// ParseSampleRates converts "type=rate" pairs into sample weights. Rates must
+// be in (0, 1]; they are rounded to the nearest one-in-N so every kept event
+// stands for a whole number of events.
+func ParseSampleRates(spec string) (map[models.EventType]uint16, error) {
+	weights := make(map[models.EventType]uint16)
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("sample rate %q: want type=rate", pair)
+		}
+		eventType := models.EventType(strings.TrimSpace(name))
+		if unsampledEvents[eventType] {
+			return nil, fmt.Errorf("sample rate %q: %s events cannot be sampled", pair, eventType)
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || rate <= 0 || rate > 1 {
+			return nil, fmt.Errorf("sample rate %q: rate must be in (0, 1]", pair)
+		}
+		weight := math.Round(1 / rate)
+		if weight > math.MaxUint16 {
+			return nil, fmt.Errorf("sample rate %q: rate below 1/%d", pair, math.MaxUint16)
+		}
+		if weight > 1 {
+			weights[eventType] = uint16(weight)
+		}
+	}
+	return weights, nil
+}
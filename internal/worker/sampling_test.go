@@ -0,0 +1,86 @@
+package worker
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestParseSampleRates(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    map[models.EventType]uint16
+		wantErr bool
+	}{
+		{name: "Empty", spec: "", want: map[models.EventType]uint16{}},
+		{name: "Rounded", spec: "weapon_fire=0.1, jump=0.3", want: map[models.EventType]uint16{"weapon_fire": 10, "jump": 3}},
+		{name: "Full Rate Dropped", spec: "weapon_fire=1", want: map[models.EventType]uint16{}},
+		{name: "Missing Rate", spec: "weapon_fire", wantErr: true},
+		{name: "Out Of Range", spec: "weapon_fire=1.5", wantErr: true},
+		{name: "Zero", spec: "weapon_fire=0", wantErr: true},
+		{name: "Kills Protected", spec: "player_kill=0.5", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSampleRates(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSamplerKeepsWeightedShare(t *testing.T) {
+	s, err := NewSampler("weapon_fire=0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kept, total := 0, 0
+	for i := 0; i < 10000; i++ {
+		raw := []byte(fmt.Sprintf(`{"type":"weapon_fire","timestamp":%d}`, i))
+		weight, keep := s.Sample(models.EventWeaponFire, raw)
+		if keep {
+			if weight != 10 {
+				t.Fatalf("weight = %d, want 10", weight)
+			}
+			kept++
+			total += int(weight)
+		}
+		// Same payload, same decision
+		if _, again := s.Sample(models.EventWeaponFire, raw); again != keep {
+			t.Fatal("sampling is not deterministic")
+		}
+	}
+	if total < 9000 || total > 11000 {
+		t.Errorf("weighted total = %d from %d kept, want about 10000", total, kept)
+	}
+
+	if weight, keep := s.Sample(models.EventPlayerKill, []byte(`{}`)); !keep || weight != 1 {
+		t.Errorf("unsampled type: weight %d keep %v", weight, keep)
+	}
+}
+
+func TestSamplerSetRatesKeepsOldOnError(t *testing.T) {
+	s, _ := NewSampler("weapon_fire=0.5")
+	if err := s.SetRates("weapon_fire=2"); err == nil {
+		t.Fatal("expected error")
+	}
+	if got := s.Weights()[models.EventWeaponFire]; got != 2 {
+		t.Errorf("weight = %d, want 2", got)
+	}
+
+	var nilSampler *Sampler
+	if _, keep := nilSampler.Sample(models.EventWeaponFire, nil); !keep {
+		t.Error("nil sampler must keep everything")
+	}
+}
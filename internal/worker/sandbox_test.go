@@ -0,0 +1,75 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// insertRecorder records the table of each batch prepared, failing those
+// into failTable
+type insertRecorder struct {
+	MockClickHouseConn
+	tables    []string
+	failTable string
+}
+
+func (r *insertRecorder) PrepareBatch(ctx context.Context, query string, opts ...driver.PrepareBatchOption) (driver.Batch, error) {
+	fields := strings.Fields(query)
+	if fields[2] == r.failTable {
+		return nil, errors.New("insert failed")
+	}
+	r.tables = append(r.tables, fields[2])
+	return &MockBatch{}, nil
+}
+
+func TestSandboxBatch(t *testing.T) {
+	ch := &insertRecorder{}
+	// No live state, match states or achievements: touching them would panic
+	p := &Pool{config: PoolConfig{ClickHouse: ch, ShardByMatch: true}, logger: zap.NewNop().Sugar()}
+
+	batch := jobsOf(models.EventMatchStart, models.EventPlayerKill)
+	for _, job := range batch {
+		job.Event.Sandbox = true
+	}
+	if err := p.processBatch(batch); err != nil {
+		t.Fatal(err)
+	}
+	if len(ch.tables) != 1 || ch.tables[0] != "mohaa_stats.raw_events_sandbox" {
+		t.Errorf("inserted into %v, want raw_events_sandbox only", ch.tables)
+	}
+
+	mixed := jobsOf(models.EventPlayerKill, models.EventDamage, models.EventJump)
+	mixed[1].Event.Sandbox = true
+	live, sandbox := splitSandbox(mixed)
+	if len(live) != 2 || len(sandbox) != 1 || sandbox[0].Event.Type != models.EventDamage || live[1].Event.Type != models.EventJump {
+		t.Errorf("live = %v, sandbox = %v", live, sandbox)
+	}
+}
+
+func TestSandboxFailureKeepsLiveEvents(t *testing.T) {
+	ch := &insertRecorder{failTable: "mohaa_stats.raw_events_sandbox"}
+	// Live state is kept out of the way by failing its pipelines
+	faults := NewFaults()
+	faults.Set(FaultConfig{RedisDown: true})
+	p := &Pool{
+		config:    PoolConfig{ClickHouse: ch, ShardByMatch: true, Faults: faults},
+		logger:    zap.NewNop().Sugar(),
+		liveState: newLiveStateBreaker(LiveStateBreakerConfig{Threshold: 1, BufferSize: 10}),
+	}
+
+	batch := jobsOf(models.EventJump, models.EventJump)
+	batch[0].Event.Sandbox = true
+	if err := p.processBatch(batch); err != nil {
+		t.Fatal(err)
+	}
+	if len(ch.tables) != 1 || ch.tables[0] != "mohaa_stats.raw_events" {
+		t.Errorf("inserted into %v, want raw_events", ch.tables)
+	}
+}
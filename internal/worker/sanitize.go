@@ -0,0 +1,30 @@
+package worker
+
+import (
+	"github.com/openmohaa/stats-api/internal/models"
+	"github.com/openmohaa/stats-api/internal/sanitize"
+)
+
+// sanitizeName cleans a player name for storage
+func sanitizeName(s string) string {
+	return sanitize.Name(s)
+}
+
+// sanitizeEvent cleans the player-supplied text of an event before anything
+// stores or indexes it, raw_json included
+func sanitizeEvent(event *models.RawEvent) {
+	for _, name := range []*string{
+		&event.PlayerName, &event.AttackerName, &event.VictimName,
+		&event.TargetName, &event.Name,
+	} {
+		if *name != "" {
+			*name = sanitize.Name(*name)
+		}
+	}
+	if event.Message != "" {
+		event.Message = sanitize.Text(event.Message, 0)
+	}
+	for i := range event.Roster {
+		event.Roster[i].Name = sanitize.Name(event.Roster[i].Name)
+	}
+}
@@ -2,6 +2,8 @@ package worker
 
 import (
 	"testing"
+
+	"github.com/openmohaa/stats-api/internal/models"
 )
 
 func TestSanitizeName(t *testing.T) {
@@ -32,6 +34,27 @@ func TestSanitizeName(t *testing.T) {
 	}
 }
 
+func TestSanitizeEvent(t *testing.T) {
+	event := &models.RawEvent{
+		Type:         models.EventPlayerKill,
+		AttackerName: "^1Killer\x00",
+		VictimName:   "Vic\xfftim ",
+		Message:      "^2gg\r\nwp",
+		Roster:       []models.RosterEntry{{Name: "\u202e^3Row"}},
+	}
+	sanitizeEvent(event)
+
+	if event.AttackerName != "Killer" || event.VictimName != "Victim" {
+		t.Errorf("names = %q, %q", event.AttackerName, event.VictimName)
+	}
+	if event.Message != "gg wp" {
+		t.Errorf("message = %q", event.Message)
+	}
+	if event.Roster[0].Name != "Row" {
+		t.Errorf("roster name = %q", event.Roster[0].Name)
+	}
+}
+
 func BenchmarkSanitizeName(b *testing.B) {
 	input := "^1Player^2Name^3With^4Colors"
 	b.ResetTimer()
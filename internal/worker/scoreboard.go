@@ -0,0 +1,73 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// scoreboardKey holds a match's live scoreboard: GUID -> RosterEntry JSON,
+// replaced by every heartbeat that carries a roster
+func scoreboardKey(matchID string) string {
+	return "match:" + matchID + ":scoreboard"
+}
+
+// applyRoster fills the heartbeat fields a v2 roster makes redundant when the
+// server left them out, so player_pings and the live player count keep
+// working for servers that only send the roster
+func applyRoster(event *models.RawEvent) {
+	if event.Type != models.EventHeartbeat || len(event.Roster) == 0 {
+		return
+	}
+	if event.PlayerCount == 0 {
+		event.PlayerCount = len(event.Roster)
+	}
+	if len(event.Pings) == 0 {
+		event.Pings = make(map[string]int, len(event.Roster))
+		for _, entry := range event.Roster {
+			if entry.GUID != "" {
+				event.Pings[entry.GUID] = entry.Ping
+			}
+		}
+	}
+}
+
+// updateScoreboard replaces the match's scoreboard and connected player set
+// with the heartbeat roster, and records the roster's names and teams. Tagged
+// bots are left out.
+func (p *Pool) updateScoreboard(ctx context.Context, event *models.RawEvent) {
+	if event.MatchID == "" || len(event.Roster) == 0 {
+		return
+	}
+	bots := p.knownBots(ctx)
+	boardKey := scoreboardKey(event.MatchID)
+	playersKey := "match:" + event.MatchID + ":players"
+	teamsKey := "match:" + event.MatchID + ":teams"
+
+	pipe := p.config.LiveState.Pipeline()
+	pipe.Del(ctx, boardKey, playersKey)
+	for _, entry := range event.Roster {
+		if entry.GUID == "" || bots[entry.GUID] {
+			continue
+		}
+		data, err := json.Marshal(entry)
+		if err != nil {
+			continue
+		}
+		pipe.HSet(ctx, boardKey, entry.GUID, data)
+		pipe.SAdd(ctx, playersKey, entry.GUID)
+		if entry.Name != "" {
+			pipe.HSet(ctx, "player_names", entry.GUID, entry.Name)
+		}
+		if entry.Team != "" {
+			pipe.HSet(ctx, teamsKey, entry.GUID, entry.Team)
+		}
+	}
+	expireKey(ctx, pipe, boardKey, p.ttl().MatchKeys)
+	expireKey(ctx, pipe, playersKey, p.ttl().MatchKeys)
+	expireKey(ctx, pipe, teamsKey, p.ttl().MatchKeys)
+	if err := pipe.Exec(ctx); err != nil {
+		p.logger.Warnw("Failed to update live scoreboard", "match_id", event.MatchID, "error", err)
+	}
+}
@@ -0,0 +1,75 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"testing"
+
+	"github.com/openmohaa/stats-api/internal/db"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestApplyRoster(t *testing.T) {
+	event := &models.RawEvent{Type: models.EventHeartbeat, Roster: []models.RosterEntry{
+		{GUID: "a", Ping: 40},
+		{GUID: "b", Ping: 90},
+	}}
+	applyRoster(event)
+	if event.PlayerCount != 2 {
+		t.Errorf("PlayerCount = %d, want 2", event.PlayerCount)
+	}
+	if len(event.Pings) != 2 || event.Pings["a"] != 40 || event.Pings["b"] != 90 {
+		t.Errorf("Pings = %v", event.Pings)
+	}
+
+	// What the server sent wins over the roster
+	event = &models.RawEvent{Type: models.EventHeartbeat, PlayerCount: 5, Pings: map[string]int{"a": 10},
+		Roster: []models.RosterEntry{{GUID: "a", Ping: 40}}}
+	applyRoster(event)
+	if event.PlayerCount != 5 || event.Pings["a"] != 10 {
+		t.Errorf("server-sent fields overwritten: %d %v", event.PlayerCount, event.Pings)
+	}
+}
+
+func TestUpdateScoreboard(t *testing.T) {
+	ctx := context.Background()
+	store := db.NewMemoryLiveState()
+	p := &Pool{config: PoolConfig{LiveState: store, RedisTTL: RedisTTLConfig{}.withDefaults()}}
+
+	heartbeat := func(roster ...models.RosterEntry) {
+		p.updateScoreboard(ctx, &models.RawEvent{Type: models.EventHeartbeat, MatchID: "m1", Roster: roster})
+	}
+	heartbeat(
+		models.RosterEntry{GUID: "a", Name: "Alice", Team: "allies", Score: 3, Ping: 40},
+		models.RosterEntry{GUID: "b", Name: "Bob", Team: "axis", Score: 1, Ping: 90},
+	)
+	heartbeat(
+		models.RosterEntry{GUID: "a", Name: "Alice", Team: "allies", Score: 5, Ping: 45},
+		models.RosterEntry{GUID: "c", Name: "Carol", Team: "axis", Score: 0, Ping: 60},
+	)
+
+	board, err := store.HGetAll(ctx, scoreboardKey("m1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(board) != 2 {
+		t.Fatalf("scoreboard = %v, want the latest roster only", board)
+	}
+	var alice models.RosterEntry
+	if err := json.Unmarshal([]byte(board["a"]), &alice); err != nil || alice.Score != 5 || alice.Ping != 45 {
+		t.Errorf("alice = %+v (%v)", alice, err)
+	}
+
+	players, _ := store.SMembers(ctx, "match:m1:players")
+	sort.Strings(players)
+	if len(players) != 2 || players[0] != "a" || players[1] != "c" {
+		t.Errorf("players = %v, want [a c]", players)
+	}
+	if team, _ := store.HGet(ctx, "match:m1:teams", "c"); team != "axis" {
+		t.Errorf("team of c = %q, want axis", team)
+	}
+	if name, _ := store.HGet(ctx, "player_names", "c"); name != "Carol" {
+		t.Errorf("name of c = %q, want Carol", name)
+	}
+}
@@ -0,0 +1,216 @@
+package worker
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/i18n"
+	"github.com/openmohaa/stats-api/internal/logic"
+	"github.com/openmohaa/stats-api/internal/models"
+	"github.com/openmohaa/stats-api/internal/notify"
+)
+
+var serverReportsSent = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "mohaa_server_reports_total",
+	Help: "Weekly server report deliveries by channel and result (webhook/email, sent/failed)",
+}, []string{"channel", "result"})
+
+// serverReportContentLimit is the longest content chat webhooks (Discord)
+// accept
+const serverReportContentLimit = 2000
+
+// ServerReportPost is the JSON posted to a server's report webhook. Content is
+// the report as text so chat webhooks can show it as is.
+type ServerReportPost struct {
+	Event   string               `json:"event"` // always "server_report"
+	Content string               `json:"content"`
+	Report  *models.ServerReport `json:"report"`
+}
+
+// ServerReportScheduler sends each server that asked for one the report of
+// every finished week once, an hour after the week ends, to its webhook
+// and email address
+type ServerReportScheduler struct {
+	svc     logic.ServerReportService
+	webhook *notify.Webhook
+	mailer  *notify.Mailer
+	locale  atomic.Pointer[string]
+	logger  *zap.SugaredLogger
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+func NewServerReportScheduler(svc logic.ServerReportService, mailer *notify.Mailer, logger *zap.Logger) *ServerReportScheduler {
+	s := &ServerReportScheduler{
+		svc:     svc,
+		webhook: notify.NewWebhook(),
+		mailer:  mailer,
+		logger:  logger.Sugar(),
+		done:    make(chan struct{}),
+	}
+	s.SetLocale(i18n.DefaultLocale)
+	return s
+}
+
+// SetLocale sets the language of the report text
+func (s *ServerReportScheduler) SetLocale(locale string) {
+	s.locale.Store(&locale)
+}
+
+// Start checks right away, then every highlightsInterval
+func (s *ServerReportScheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+	go func() {
+		defer close(s.done)
+		s.RunOnce(ctx, time.Now())
+
+		ticker := time.NewTicker(highlightsInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				s.RunOnce(ctx, now)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (s *ServerReportScheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+		<-s.done
+	}
+}
+
+// RunOnce sends the last finished week's report to every server it is due
+// for. A report counts as sent once one of its channels took it; a server
+// whose channels all failed is retried on the next run.
+func (s *ServerReportScheduler) RunOnce(ctx context.Context, now time.Time) {
+	week := lastFinishedPeriod(models.HighlightWeek, now)
+	due, err := s.svc.Due(ctx, week)
+	if err != nil {
+		s.logger.Warnw("Failed to read due server reports", "error", err)
+		return
+	}
+
+	locale := *s.locale.Load()
+	for _, settings := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		report, err := s.svc.Build(ctx, settings.ServerID, week)
+		if err != nil {
+			s.logger.Errorw("Failed to build server report", "server", settings.ServerID, "error", err)
+			continue
+		}
+		subject, text := RenderServerReport(locale, report)
+
+		sent := false
+		if settings.WebhookURL != "" {
+			post := &ServerReportPost{Event: "server_report", Content: truncate(text, serverReportContentLimit), Report: report}
+			sent = s.deliver("webhook", settings.ServerID, s.webhook.Send(ctx, settings.WebhookURL, post)) || sent
+		}
+		if settings.Email != "" {
+			sent = s.deliver("email", settings.ServerID, s.mailer.Send(ctx, settings.Email, subject, text)) || sent
+		}
+		if !sent {
+			continue
+		}
+		if err := s.svc.MarkSent(ctx, settings.ServerID, week); err != nil {
+			s.logger.Errorw("Failed to mark server report sent", "server", settings.ServerID, "error", err)
+		}
+	}
+}
+
+// deliver counts and logs one delivery, reporting whether it succeeded
+func (s *ServerReportScheduler) deliver(channel, serverID string, err error) bool {
+	if err != nil {
+		serverReportsSent.WithLabelValues(channel, "failed").Inc()
+		s.logger.Warnw("Server report delivery failed", "channel", channel, "server", serverID, "error", err)
+		return false
+	}
+	serverReportsSent.WithLabelValues(channel, "sent").Inc()
+	return true
+}
+
+// RenderServerReport writes a report as a subject line and a plain text body
+// in locale
+func RenderServerReport(locale string, r *models.ServerReport) (subject, text string) {
+	c := i18n.Default()
+	name := r.ServerName
+	if name == "" {
+		name = r.ServerID
+	}
+	subject = c.Sprintf(locale, "digest.server_report.subject", "Weekly report for %s, week of %s",
+		sanitizeName(name), r.WeekStart.Format("2006-01-02"))
+
+	var b strings.Builder
+	line := func(s string) {
+		b.WriteString(s)
+		b.WriteByte('\n')
+	}
+	line(subject)
+	line(c.Sprintf(locale, "digest.server_report.summary", "%d matches · %d players · %d kills",
+		r.Matches, r.Players, r.Kills))
+	if r.Matches == 0 {
+		line(c.T(locale, "digest.server_report.empty", "No matches were played this week."))
+		return subject, strings.TrimSuffix(b.String(), "\n")
+	}
+
+	if len(r.TopPlayers) > 0 {
+		line("")
+		line(c.T(locale, "digest.server_report.players", "Top players"))
+		for i, p := range r.TopPlayers {
+			line(c.Sprintf(locale, "digest.server_report.player", "%d. %s – %d kills, %d deaths, K/D %.2f",
+				i+1, sanitizeName(p.PlayerName), p.Kills, p.Deaths, p.KDRatio))
+		}
+	}
+	if len(r.BusiestHours) > 0 {
+		line("")
+		line(c.T(locale, "digest.server_report.hours", "Busiest hours (UTC)"))
+		for _, h := range r.BusiestHours {
+			at := r.WeekStart.AddDate(0, 0, h.Day-1).Add(time.Duration(h.Hour) * time.Hour)
+			line(c.Sprintf(locale, "digest.server_report.hour", "%s – %d players",
+				at.Format("2006-01-02 15:04"), h.Players))
+		}
+	}
+	if len(r.Maps) > 0 {
+		line("")
+		line(c.T(locale, "digest.server_report.maps", "Maps"))
+		for _, m := range r.Maps {
+			line(c.Sprintf(locale, "digest.server_report.map", "%s – %d matches, %d kills", m.MapName, m.Matches, m.Kills))
+		}
+	}
+	if len(r.NotableMatches) > 0 {
+		line("")
+		line(c.T(locale, "digest.server_report.matches", "Notable matches"))
+		for _, m := range r.NotableMatches {
+			startedAt := m.StartedAt.UTC().Format("2006-01-02 15:04")
+			if m.PeakSpectators > 0 {
+				line(c.Sprintf(locale, "digest.server_report.match_watched", "%s on %s – %d players, %d kills, %d spectators at peak",
+					m.MapName, startedAt, m.Players, m.Kills, m.PeakSpectators))
+				continue
+			}
+			line(c.Sprintf(locale, "digest.server_report.match", "%s on %s – %d players, %d kills",
+				m.MapName, startedAt, m.Players, m.Kills))
+		}
+	}
+	return subject, strings.TrimSuffix(b.String(), "\n")
+}
+
+// truncate cuts s to at most limit runes, marking the cut with an ellipsis
+func truncate(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit-1]) + "…"
+}
@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestRenderServerReport(t *testing.T) {
+	week := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+	report := &models.ServerReport{
+		ServerID:   "srv",
+		ServerName: "^1Omaha Beach",
+		WeekStart:  week,
+		Matches:    12, Players: 40, Kills: 900,
+		TopPlayers: []models.ServerReportPlayer{
+			{PlayerGUID: "a", PlayerName: "^2Sarge", Kills: 120, Deaths: 60, KDRatio: 2},
+		},
+		BusiestHours: []models.ServerReportHour{{Day: 5, Hour: 20, Players: 18}},
+		Maps:         []models.ServerReportMap{{MapName: "obj/obj_team2", Matches: 7, Kills: 600}},
+		NotableMatches: []models.ServerReportMatch{
+			{MatchID: "m", MapName: "dm/mohdm1", StartedAt: week.Add(30 * time.Hour), Players: 16, Kills: 150},
+			{MatchID: "n", MapName: "obj/obj_team2", StartedAt: week.Add(52 * time.Hour), Players: 20, Kills: 140, PeakSpectators: 25},
+		},
+	}
+
+	subject, text := RenderServerReport("en", report)
+	if subject != "Weekly report for Omaha Beach, week of 2026-10-05" {
+		t.Errorf("subject = %q", subject)
+	}
+	for _, want := range []string{
+		"12 matches · 40 players · 900 kills",
+		"1. Sarge – 120 kills, 60 deaths, K/D 2.00",
+		"2026-10-09 20:00 – 18 players",
+		"obj/obj_team2 – 7 matches, 600 kills",
+		"dm/mohdm1 on 2026-10-06 06:00 – 16 players, 150 kills\n",
+		"obj/obj_team2 on 2026-10-07 04:00 – 20 players, 140 kills, 25 spectators at peak",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text lacks %q:\n%s", want, text)
+		}
+	}
+
+	if _, text := RenderServerReport("de", report); !strings.Contains(text, "Beste Spieler") {
+		t.Errorf("German text:\n%s", text)
+	}
+
+	_, text = RenderServerReport("en", &models.ServerReport{ServerID: "srv", WeekStart: week})
+	if !strings.HasSuffix(text, "No matches were played this week.") || strings.Contains(text, "Top players") {
+		t.Errorf("empty week:\n%s", text)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := truncate("äbcdef", 4); got != "äbc…" {
+		t.Errorf("truncate = %q", got)
+	}
+	if got := truncate("abc", 4); got != "abc" {
+		t.Errorf("truncate = %q", got)
+	}
+}
@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"hash/fnv"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+var (
+	shardQueueDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mohaa_worker_shard_queue_depth",
+		Help: "Current depth of each worker shard queue (ShardByMatch only)",
+	}, []string{"shard"})
+
+	shardEventsIngested = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "mohaa_worker_shard_events_total",
+		Help: "Events queued per worker shard (ShardByMatch only)",
+	}, []string{"shard"})
+)
+
+// shardKey is what an event is sharded on: its match, or its server for
+// events sent outside a match
+func shardKey(event *models.RawEvent) string {
+	if event.MatchID != "" {
+		return event.MatchID
+	}
+	return event.ServerID
+}
+
+// shardFor maps an event to a worker. Every event of a match lands on the same
+// worker, which processes them in arrival order.
+func shardFor(event *models.RawEvent, shards int) int {
+	h := fnv.New32a()
+	h.Write([]byte(shardKey(event)))
+	return int(h.Sum32() % uint32(shards))
+}
+
+// queueFor returns the channel an event is enqueued on
+func (p *Pool) queueFor(event *models.RawEvent) (chan Job, int) {
+	if len(p.shards) == 0 {
+		return p.jobQueue, -1
+	}
+	shard := shardFor(event, len(p.shards))
+	return p.shards[shard], shard
+}
+
+// workerQueue returns the channel worker id consumes
+func (p *Pool) workerQueue(id int) chan Job {
+	if len(p.shards) == 0 {
+		return p.jobQueue
+	}
+	return p.shards[id]
+}
+
+func (p *Pool) reportShardDepth() {
+	for i, shard := range p.shards {
+		shardQueueDepth.WithLabelValues(strconv.Itoa(i)).Set(float64(len(shard)))
+	}
+}
@@ -0,0 +1,68 @@
+package worker
+
+import (
+	"fmt"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestShardFor(t *testing.T) {
+	event := &models.RawEvent{MatchID: "match-1", ServerID: "server-1"}
+	want := shardFor(event, 8)
+	for i := 0; i < 10; i++ {
+		if got := shardFor(&models.RawEvent{MatchID: "match-1", ServerID: fmt.Sprintf("other-%d", i)}, 8); got != want {
+			t.Fatalf("same match sharded to %d and %d", want, got)
+		}
+	}
+
+	// Without a match, events follow their server
+	a := shardFor(&models.RawEvent{ServerID: "server-1", Type: models.EventHeartbeat}, 8)
+	b := shardFor(&models.RawEvent{ServerID: "server-1", Type: models.EventConnect}, 8)
+	if a != b {
+		t.Errorf("matchless events of one server sharded to %d and %d", a, b)
+	}
+
+	// Matches spread over the shards
+	seen := make(map[int]bool)
+	for i := 0; i < 100; i++ {
+		seen[shardFor(&models.RawEvent{MatchID: fmt.Sprintf("match-%d", i)}, 8)] = true
+	}
+	if len(seen) < 4 {
+		t.Errorf("100 matches used only %d of 8 shards", len(seen))
+	}
+}
+
+func TestShardedEnqueueKeepsMatchOrder(t *testing.T) {
+	p := &Pool{
+		config: PoolConfig{WorkerCount: 4, ShardByMatch: true},
+		logger: zap.NewNop().Sugar(),
+	}
+	p.shards = make([]chan Job, 4)
+	for i := range p.shards {
+		p.shards[i] = make(chan Job, 100)
+	}
+
+	for i := 0; i < 20; i++ {
+		queue, shard := p.queueFor(&models.RawEvent{MatchID: "match-1", RoundNumber: i})
+		if shard < 0 {
+			t.Fatal("sharded pool returned the shared queue")
+		}
+		queue <- Job{Event: &models.RawEvent{MatchID: "match-1", RoundNumber: i}}
+	}
+
+	if depth := p.QueueDepth(); depth != 20 {
+		t.Errorf("QueueDepth = %d, want 20", depth)
+	}
+
+	_, shard := p.queueFor(&models.RawEvent{MatchID: "match-1"})
+	queue := p.workerQueue(shard)
+	for i := 0; i < 20; i++ {
+		job := <-queue
+		if job.Event.RoundNumber != i {
+			t.Fatalf("job %d has round %d", i, job.Event.RoundNumber)
+		}
+	}
+}
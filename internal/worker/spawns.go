@@ -0,0 +1,121 @@
+package worker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// spawnLife is how long a player lived since spawning, and how long it took
+// them to first engage, in seconds
+type spawnLife struct {
+	lifetime, engageDelay float32
+}
+
+// spawnLives pairs every player_spawn with the player's death, so deaths can
+// be stored with the victim's lifetime and their time to first engagement:
+// firing, hitting, getting hit or killing. Lives are timed with the event
+// timestamps, falling back to ingest time for events without one. Matches are
+// forgotten on match_end or after matchIdle without events.
+type spawnLives struct {
+	mu      sync.Mutex
+	matches *matchTracker[*matchLives]
+}
+
+type matchLives struct {
+	players map[string]*life
+}
+
+// life is one spawn of a player; engaged is zero until they engage
+type life struct {
+	spawned, engaged float64
+}
+
+// deathEvents end the victim's life
+var deathEvents = map[models.EventType]bool{
+	models.EventPlayerKill:        true,
+	models.EventBotKilled:         true,
+	models.EventPlayerBash:        true,
+	models.EventPlayerRoadkill:    true,
+	models.EventPlayerTeamkill:    true,
+	models.EventPlayerSuicide:     true,
+	models.EventPlayerCrushed:     true,
+	models.EventPlayerTelefragged: true,
+	models.EventDeath:             true,
+}
+
+func newSpawnLives() *spawnLives {
+	return &spawnLives{matches: newMatchTracker[*matchLives]()}
+}
+
+// eventClock is the event's time in seconds. Game timestamps are either Unix
+// time or level time, both fine for durations within a match.
+func eventClock(event *models.RawEvent, now time.Time) float64 {
+	if event.Timestamp > 0 {
+		return event.Timestamp
+	}
+	return float64(now.UnixNano()) / 1e9
+}
+
+// track advances the event's match and returns the victim's life when the
+// event is a death of a player whose spawn was seen
+func (s *spawnLives) track(event *models.RawEvent, now time.Time) spawnLife {
+	if s == nil || event.MatchID == "" {
+		return spawnLife{}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.matches.sweep(now)
+
+	if event.Type == models.EventMatchEnd {
+		s.matches.end(event.MatchID)
+		return spawnLife{}
+	}
+
+	m := s.matches.get(event.MatchID, now, func() *matchLives {
+		return &matchLives{players: make(map[string]*life)}
+	})
+	clock := eventClock(event, now)
+
+	engage := func(guid string) {
+		if l, ok := m.players[guid]; ok && l.engaged == 0 {
+			l.engaged = clock
+		}
+	}
+
+	switch {
+	case event.Type == models.EventPlayerSpawn || event.Type == models.EventPlayerRespawn:
+		if event.PlayerGUID != "" {
+			m.players[event.PlayerGUID] = &life{spawned: clock}
+		}
+	case event.Type == models.EventWeaponFire || event.Type == models.EventWeaponHit:
+		engage(event.PlayerGUID)
+	case event.Type == models.EventDamage || event.Type == models.EventPlayerPain:
+		engage(event.AttackerGUID)
+		engage(event.VictimGUID)
+	case deathEvents[event.Type]:
+		engage(event.AttackerGUID)
+		victim := event.VictimGUID
+		if victim == "" {
+			victim = event.PlayerGUID
+		}
+		l, ok := m.players[victim]
+		if !ok {
+			return spawnLife{}
+		}
+		delete(m.players, victim)
+		if clock <= l.spawned {
+			return spawnLife{}
+		}
+		lived := spawnLife{lifetime: float32(clock - l.spawned), engageDelay: float32(clock - l.spawned)}
+		if l.engaged != 0 {
+			lived.engageDelay = float32(l.engaged - l.spawned)
+		}
+		return lived
+	case event.Type == models.EventDisconnect:
+		delete(m.players, event.PlayerGUID)
+	}
+	return spawnLife{}
+}
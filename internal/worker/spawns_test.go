@@ -0,0 +1,53 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestSpawnLives(t *testing.T) {
+	s := newSpawnLives()
+	now := time.Now()
+	ev := func(e models.RawEvent) *models.RawEvent {
+		e.MatchID = "m1"
+		return &e
+	}
+
+	s.track(ev(models.RawEvent{Type: models.EventPlayerSpawn, PlayerGUID: "a", Timestamp: 100}), now)
+	s.track(ev(models.RawEvent{Type: models.EventPlayerSpawn, PlayerGUID: "b", Timestamp: 102}), now)
+	s.track(ev(models.RawEvent{Type: models.EventWeaponFire, PlayerGUID: "a", Timestamp: 105}), now)
+	s.track(ev(models.RawEvent{Type: models.EventWeaponFire, PlayerGUID: "a", Timestamp: 107}), now)
+
+	got := s.track(ev(models.RawEvent{Type: models.EventPlayerKill, AttackerGUID: "b", VictimGUID: "a", Timestamp: 130}), now)
+	if got != (spawnLife{lifetime: 30, engageDelay: 5}) {
+		t.Errorf("a's life = %+v, want 30s lived, engaged after 5s", got)
+	}
+	// The kill engaged b
+	got = s.track(ev(models.RawEvent{Type: models.EventPlayerSuicide, PlayerGUID: "b", Timestamp: 142}), now)
+	if got != (spawnLife{lifetime: 40, engageDelay: 28}) {
+		t.Errorf("b's life = %+v, want 40s lived, engaged after 28s", got)
+	}
+
+	// Dead players have no life until they spawn again
+	if got := s.track(ev(models.RawEvent{Type: models.EventPlayerKill, AttackerGUID: "x", VictimGUID: "a", Timestamp: 150}), now); got != (spawnLife{}) {
+		t.Errorf("death without spawn = %+v", got)
+	}
+	// Never engaged: the whole life
+	s.track(ev(models.RawEvent{Type: models.EventPlayerSpawn, PlayerGUID: "a", Timestamp: 160}), now)
+	if got := s.track(ev(models.RawEvent{Type: models.EventPlayerKill, AttackerGUID: "x", VictimGUID: "a", Timestamp: 170}), now); got != (spawnLife{lifetime: 10, engageDelay: 10}) {
+		t.Errorf("life without engaging = %+v", got)
+	}
+
+	s.track(ev(models.RawEvent{Type: models.EventPlayerSpawn, PlayerGUID: "a", Timestamp: 180}), now)
+	s.track(ev(models.RawEvent{Type: models.EventMatchEnd}), now)
+	if got := s.track(ev(models.RawEvent{Type: models.EventPlayerKill, AttackerGUID: "x", VictimGUID: "a", Timestamp: 190}), now); got != (spawnLife{}) {
+		t.Errorf("life survived match_end: %+v", got)
+	}
+
+	var none *spawnLives
+	if got := none.track(ev(models.RawEvent{Type: models.EventPlayerKill}), now); got != (spawnLife{}) {
+		t.Error("nil tracker returned a life")
+	}
+}
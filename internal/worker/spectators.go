@@ -0,0 +1,42 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// spectatorsKey holds the GUIDs watching a match, from spectator_join and
+// spectator_leave
+func spectatorsKey(matchID string) string {
+	return "match:" + matchID + ":spectators"
+}
+
+// handleSpectator adds or removes a match's spectator. Joining twice or
+// leaving without joining leaves the count as it was.
+func (p *Pool) handleSpectator(ctx context.Context, event *models.RawEvent) {
+	if event.PlayerGUID == "" {
+		return
+	}
+	key := spectatorsKey(event.MatchID)
+	if event.Type == models.EventSpectatorLeave {
+		p.config.LiveState.SRem(ctx, key, event.PlayerGUID)
+		return
+	}
+	p.config.LiveState.SAdd(ctx, key, event.PlayerGUID)
+	expireKey(ctx, p.config.LiveState, key, p.ttl().MatchKeys)
+}
+
+// liveSpectators is how many are watching a match at a heartbeat: the
+// heartbeat's spectator_count, else the spectators that joined and have not
+// left
+func (p *Pool) liveSpectators(ctx context.Context, event *models.RawEvent) int {
+	if event.SpectatorCount != nil {
+		return max(*event.SpectatorCount, 0)
+	}
+	members, err := p.config.LiveState.SMembers(ctx, spectatorsKey(event.MatchID))
+	if err != nil {
+		return 0
+	}
+	return len(members)
+}
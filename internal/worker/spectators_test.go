@@ -0,0 +1,48 @@
+package worker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openmohaa/stats-api/internal/db"
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestLiveSpectators(t *testing.T) {
+	ctx := context.Background()
+	store := db.NewMemoryLiveState()
+	p := &Pool{config: PoolConfig{LiveState: store, RedisTTL: RedisTTLConfig{}.withDefaults()}}
+
+	for _, e := range []models.RawEvent{
+		{Type: models.EventSpectatorJoin, PlayerGUID: "a"},
+		{Type: models.EventSpectatorJoin, PlayerGUID: "b"},
+		{Type: models.EventSpectatorJoin, PlayerGUID: "a"},  // joined twice
+		{Type: models.EventSpectatorLeave, PlayerGUID: "c"}, // never joined
+		{Type: models.EventSpectatorJoin, PlayerGUID: "c"},
+		{Type: models.EventSpectatorLeave, PlayerGUID: "b"},
+		{Type: models.EventSpectatorJoin},
+	} {
+		e.MatchID = "m1"
+		p.handleSpectator(ctx, &e)
+	}
+
+	heartbeat := &models.RawEvent{Type: models.EventHeartbeat, MatchID: "m1"}
+	if got := p.liveSpectators(ctx, heartbeat); got != 2 {
+		t.Errorf("spectators from events = %d, want 2", got)
+	}
+
+	// A reported count wins, even 0
+	count := 0
+	heartbeat.SpectatorCount = &count
+	if got := p.liveSpectators(ctx, heartbeat); got != 0 {
+		t.Errorf("spectators from count = %d, want 0", got)
+	}
+	count = -3
+	if got := p.liveSpectators(ctx, heartbeat); got != 0 {
+		t.Errorf("negative count = %d, want 0", got)
+	}
+
+	if got := p.liveSpectators(ctx, &models.RawEvent{Type: models.EventHeartbeat, MatchID: "m2"}); got != 0 {
+		t.Errorf("other match spectators = %d, want 0", got)
+	}
+}
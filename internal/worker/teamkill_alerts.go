@@ -0,0 +1,171 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+var teamkillAlertsSent = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "mohaa_teamkill_alerts_total",
+	Help: "Team-kill threshold webhook alerts by result (sent, failed)",
+}, []string{"result"})
+
+// teamkillAlertTimeout bounds one webhook delivery
+const teamkillAlertTimeout = 10 * time.Second
+
+// TeamkillAlertConfig sets when a player's team kills in one match trigger a
+// webhook alert. A zero Threshold or empty WebhookURL disables alerts.
+type TeamkillAlertConfig struct {
+	Threshold  int
+	WebhookURL string
+}
+
+func (c TeamkillAlertConfig) enabled() bool {
+	return c.Threshold > 0 && c.WebhookURL != ""
+}
+
+// TeamkillAlert is the JSON posted to the webhook. Content is a readable
+// summary so chat webhooks (Discord) can show it as is.
+type TeamkillAlert struct {
+	Event      string    `json:"event"` // always "teamkill_threshold"
+	Content    string    `json:"content"`
+	MatchID    string    `json:"match_id"`
+	ServerID   string    `json:"server_id"`
+	MapName    string    `json:"map_name,omitempty"`
+	PlayerGUID string    `json:"player_guid"`
+	PlayerName string    `json:"player_name"`
+	Teamkills  int       `json:"teamkills"`
+	Threshold  int       `json:"threshold"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// TeamkillAlerter counts team kills per player and match as events arrive and
+// posts one alert per player and match when the count reaches the threshold.
+// Matches are forgotten on match_end or after an hour without events.
+type TeamkillAlerter struct {
+	config atomic.Pointer[TeamkillAlertConfig]
+	client *http.Client
+	logger *zap.SugaredLogger
+
+	mu      sync.Mutex
+	matches *matchTracker[*matchTeamkills]
+}
+
+type matchTeamkills struct {
+	counts  map[string]int
+	alerted map[string]bool
+}
+
+func NewTeamkillAlerter(cfg TeamkillAlertConfig, logger *zap.Logger) *TeamkillAlerter {
+	a := &TeamkillAlerter{
+		client:  &http.Client{Timeout: teamkillAlertTimeout},
+		logger:  logger.Sugar(),
+		matches: newMatchTracker[*matchTeamkills](),
+	}
+	a.SetConfig(cfg)
+	return a
+}
+
+// SetConfig replaces the threshold and webhook from the next team kill on.
+// Counts so far are kept.
+func (a *TeamkillAlerter) SetConfig(cfg TeamkillAlertConfig) {
+	a.config.Store(&cfg)
+}
+
+// Observe counts a team kill and sends an alert in the background if it
+// crosses the threshold
+func (a *TeamkillAlerter) Observe(event *models.RawEvent, now time.Time) {
+	if alert := a.observe(event, now); alert != nil {
+		url := a.config.Load().WebhookURL
+		go a.send(url, alert)
+	}
+}
+
+// observe returns the alert to send for an event, if any
+func (a *TeamkillAlerter) observe(event *models.RawEvent, now time.Time) *TeamkillAlert {
+	if a == nil || event.MatchID == "" {
+		return nil
+	}
+	cfg := *a.config.Load()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.matches.sweep(now)
+
+	if event.Type == models.EventMatchEnd {
+		a.matches.end(event.MatchID)
+		return nil
+	}
+	if event.Type != models.EventPlayerTeamkill || event.AttackerGUID == "" || !cfg.enabled() {
+		return nil
+	}
+
+	m := a.matches.get(event.MatchID, now, func() *matchTeamkills {
+		return &matchTeamkills{counts: make(map[string]int), alerted: make(map[string]bool)}
+	})
+	m.counts[event.AttackerGUID]++
+
+	count := m.counts[event.AttackerGUID]
+	if count < cfg.Threshold || m.alerted[event.AttackerGUID] {
+		return nil
+	}
+	m.alerted[event.AttackerGUID] = true
+
+	name := sanitizeName(event.AttackerName)
+	return &TeamkillAlert{
+		Event:      "teamkill_threshold",
+		Content:    fmt.Sprintf("%s has %d team kills this match on %s (%s), threshold %d", name, count, event.ServerID, event.MapName, cfg.Threshold),
+		MatchID:    event.MatchID,
+		ServerID:   event.ServerID,
+		MapName:    event.MapName,
+		PlayerGUID: event.AttackerGUID,
+		PlayerName: name,
+		Teamkills:  count,
+		Threshold:  cfg.Threshold,
+		Timestamp:  now.UTC(),
+	}
+}
+
+func (a *TeamkillAlerter) send(url string, alert *TeamkillAlert) {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), teamkillAlertTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		teamkillAlertsSent.WithLabelValues("failed").Inc()
+		a.logger.Warnw("Invalid team-kill webhook", "error", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		teamkillAlertsSent.WithLabelValues("failed").Inc()
+		a.logger.Warnw("Team-kill webhook failed", "player", alert.PlayerGUID, "match", alert.MatchID, "error", err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		teamkillAlertsSent.WithLabelValues("failed").Inc()
+		a.logger.Warnw("Team-kill webhook rejected", "player", alert.PlayerGUID, "match", alert.MatchID, "status", resp.StatusCode)
+		return
+	}
+	teamkillAlertsSent.WithLabelValues("sent").Inc()
+	a.logger.Infow("Team-kill alert sent", "player", alert.PlayerGUID, "match", alert.MatchID, "teamkills", alert.Teamkills)
+}
@@ -0,0 +1,79 @@
+package worker
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func teamkill(matchID, guid string) *models.RawEvent {
+	return &models.RawEvent{Type: models.EventPlayerTeamkill, MatchID: matchID, ServerID: "srv", AttackerGUID: guid, AttackerName: "Griefer"}
+}
+
+func TestTeamkillAlerterThreshold(t *testing.T) {
+	a := NewTeamkillAlerter(TeamkillAlertConfig{Threshold: 3, WebhookURL: "http://hook"}, zap.NewNop())
+	now := time.Now()
+
+	for i := 1; i <= 2; i++ {
+		if alert := a.observe(teamkill("m1", "p1"), now); alert != nil {
+			t.Fatalf("alert after %d team kills, want none below threshold", i)
+		}
+	}
+	alert := a.observe(teamkill("m1", "p1"), now)
+	if alert == nil || alert.Teamkills != 3 || alert.PlayerGUID != "p1" || alert.MatchID != "m1" {
+		t.Fatalf("alert at threshold = %+v", alert)
+	}
+	if again := a.observe(teamkill("m1", "p1"), now); again != nil {
+		t.Error("second alert for the same player and match")
+	}
+	if other := a.observe(teamkill("m1", "p2"), now); other != nil {
+		t.Error("counts leaked between players")
+	}
+
+	// match_end forgets the match, so a new one with the same ID starts over
+	a.observe(&models.RawEvent{Type: models.EventMatchEnd, MatchID: "m1"}, now)
+	if got := a.observe(teamkill("m1", "p1"), now); got != nil {
+		t.Error("alert right after match_end, want counts reset")
+	}
+
+	a.SetConfig(TeamkillAlertConfig{Threshold: 3})
+	for i := 0; i < 5; i++ {
+		if got := a.observe(teamkill("m2", "p1"), now); got != nil {
+			t.Fatal("alert without a webhook URL")
+		}
+	}
+
+	var nilAlerter *TeamkillAlerter
+	nilAlerter.Observe(teamkill("m1", "p1"), now)
+}
+
+func TestTeamkillAlerterSend(t *testing.T) {
+	received := make(chan TeamkillAlert, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert TeamkillAlert
+		if err := json.NewDecoder(r.Body).Decode(&alert); err != nil {
+			t.Errorf("decode webhook body: %v", err)
+		}
+		received <- alert
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	a := NewTeamkillAlerter(TeamkillAlertConfig{Threshold: 1, WebhookURL: srv.URL}, zap.NewNop())
+	a.Observe(teamkill("m1", "p1"), time.Now())
+
+	select {
+	case alert := <-received:
+		if alert.Event != "teamkill_threshold" || alert.Content == "" || alert.Threshold != 1 {
+			t.Errorf("webhook body = %+v", alert)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook not called")
+	}
+}
@@ -0,0 +1,56 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	"github.com/openmohaa/stats-api/internal/jobs"
+	"github.com/openmohaa/stats-api/internal/logic"
+)
+
+var titlesGranted = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "mohaa_titles_granted_total",
+	Help: "Titles and badges granted by rule",
+})
+
+// TitleRuleSweeper grants rule titles and badges to the players who have
+// reached them. It runs hourly on the job scheduler; career stats move slowly
+// enough that an hour's delay is not noticed.
+type TitleRuleSweeper struct {
+	svc    logic.TitlesService
+	logger *zap.SugaredLogger
+}
+
+func NewTitleRuleSweeper(svc logic.TitlesService, logger *zap.Logger) *TitleRuleSweeper {
+	return &TitleRuleSweeper{
+		svc:    svc,
+		logger: logger.Sugar(),
+	}
+}
+
+// Job is the sweep as a scheduled job
+func (s *TitleRuleSweeper) Job() jobs.Job {
+	return jobs.Job{
+		Name:        "title_rules",
+		Description: "Grants rule titles and badges to players who reached them",
+		Schedule:    "@hourly",
+		Run:         s.RunOnce,
+	}
+}
+
+// RunOnce grants every rule title players qualify for and do not hold yet
+func (s *TitleRuleSweeper) RunOnce(ctx context.Context) error {
+	granted, err := s.svc.GrantByRules(ctx)
+	titlesGranted.Add(float64(granted))
+	if err != nil {
+		return fmt.Errorf("granted %d rule titles before failing: %w", granted, err)
+	}
+	if granted > 0 {
+		s.logger.Infow("Rule titles granted", "granted", granted)
+	}
+	return nil
+}
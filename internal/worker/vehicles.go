@@ -0,0 +1,101 @@
+package worker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// vehicleSeat is the vehicle a player occupies and their seat in it
+type vehicleSeat struct {
+	vehicle, seat string
+}
+
+// vehicleSeats follows who sits in which vehicle from vehicle_enter,
+// vehicle_change and vehicle_exit, so kills can be stored with the killer's
+// vehicle and seat and the victim's vehicle. Players leave their vehicle when
+// they die, respawn or disconnect; matches are forgotten on match_end or
+// after matchIdle without events.
+type vehicleSeats struct {
+	mu      sync.Mutex
+	matches *matchTracker[*matchVehicles]
+}
+
+type matchVehicles struct {
+	players map[string]vehicleSeat
+}
+
+// vehicleKillEvents are the kills tagged with the players' vehicles
+var vehicleKillEvents = map[models.EventType]bool{
+	models.EventPlayerKill:     true,
+	models.EventBotKilled:      true,
+	models.EventPlayerBash:     true,
+	models.EventPlayerRoadkill: true,
+	models.EventPlayerTeamkill: true,
+}
+
+func newVehicleSeats() *vehicleSeats {
+	return &vehicleSeats{matches: newMatchTracker[*matchVehicles]()}
+}
+
+// eventVehicle returns the vehicle and seat named by a vehicle event
+func eventVehicle(event *models.RawEvent) vehicleSeat {
+	v := vehicleSeat{vehicle: event.Vehicle, seat: event.Seat}
+	if event.Type == models.EventVehicleChange && event.ToVehicle != "" {
+		v.vehicle = event.ToVehicle
+	}
+	if v.vehicle == "" {
+		v.vehicle = event.Entity
+	}
+	if v.seat == "" {
+		v.seat = event.Position
+	}
+	return v
+}
+
+// track advances the event's match and returns the vehicle the actor was in
+// and the target's vehicle
+func (v *vehicleSeats) track(event *models.RawEvent, now time.Time) (actor vehicleSeat, target string) {
+	if v == nil || event.MatchID == "" {
+		return vehicleSeat{}, ""
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.matches.sweep(now)
+
+	if event.Type == models.EventMatchEnd {
+		v.matches.end(event.MatchID)
+		return vehicleSeat{}, ""
+	}
+
+	m := v.matches.get(event.MatchID, now, func() *matchVehicles {
+		return &matchVehicles{players: make(map[string]vehicleSeat)}
+	})
+
+	switch {
+	case event.Type == models.EventVehicleEnter || event.Type == models.EventVehicleChange:
+		actor = eventVehicle(event)
+		if event.PlayerGUID != "" {
+			m.players[event.PlayerGUID] = actor
+		}
+	case event.Type == models.EventVehicleExit || event.Type == models.EventVehicleCrash:
+		actor = eventVehicle(event)
+		if current, ok := m.players[event.PlayerGUID]; ok {
+			actor = current
+		}
+		if event.Type == models.EventVehicleExit {
+			delete(m.players, event.PlayerGUID)
+		}
+	case vehicleKillEvents[event.Type]:
+		actor = m.players[event.AttackerGUID]
+		target = m.players[event.VictimGUID].vehicle
+		delete(m.players, event.VictimGUID)
+	case event.Type == models.EventPlayerSpawn || event.Type == models.EventDisconnect ||
+		event.Type == models.EventPlayerSuicide || event.Type == models.EventDeath:
+		delete(m.players, event.PlayerGUID)
+		delete(m.players, event.VictimGUID)
+	}
+	return actor, target
+}
@@ -0,0 +1,49 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+func TestVehicleSeats(t *testing.T) {
+	v := newVehicleSeats()
+	now := time.Now()
+	ev := func(e models.RawEvent) *models.RawEvent {
+		e.MatchID = "m1"
+		return &e
+	}
+
+	if actor, _ := v.track(ev(models.RawEvent{Type: models.EventVehicleEnter, PlayerGUID: "driver", Vehicle: "jeep", Seat: "driver"}), now); actor.vehicle != "jeep" {
+		t.Fatalf("vehicle_enter tagged %+v, want jeep", actor)
+	}
+	v.track(ev(models.RawEvent{Type: models.EventVehicleEnter, PlayerGUID: "gunner", Entity: "jeep", Position: "gunner"}), now)
+	v.track(ev(models.RawEvent{Type: models.EventVehicleEnter, PlayerGUID: "tanker", Vehicle: "tank"}), now)
+
+	actor, target := v.track(ev(models.RawEvent{Type: models.EventPlayerKill, AttackerGUID: "gunner", VictimGUID: "tanker"}), now)
+	if actor != (vehicleSeat{"jeep", "gunner"}) || target != "tank" {
+		t.Errorf("passenger kill tagged %+v / %q, want jeep gunner / tank", actor, target)
+	}
+	// The victim left the tank when they died
+	if _, target := v.track(ev(models.RawEvent{Type: models.EventPlayerKill, AttackerGUID: "driver", VictimGUID: "tanker"}), now); target != "" {
+		t.Errorf("dead player still in %q", target)
+	}
+
+	if actor, _ := v.track(ev(models.RawEvent{Type: models.EventVehicleExit, PlayerGUID: "driver"}), now); actor.vehicle != "jeep" || actor.seat != "driver" {
+		t.Errorf("vehicle_exit tagged %+v, want the tracked jeep seat", actor)
+	}
+	if actor, _ := v.track(ev(models.RawEvent{Type: models.EventPlayerKill, AttackerGUID: "driver", VictimGUID: "x"}), now); actor.vehicle != "" {
+		t.Errorf("kill after vehicle_exit tagged %+v, want on foot", actor)
+	}
+
+	v.track(ev(models.RawEvent{Type: models.EventMatchEnd}), now)
+	if actor, _ := v.track(ev(models.RawEvent{Type: models.EventPlayerKill, AttackerGUID: "gunner", VictimGUID: "x"}), now); actor.vehicle != "" {
+		t.Errorf("seat survived match_end: %+v", actor)
+	}
+
+	var none *vehicleSeats
+	if actor, target := none.track(ev(models.RawEvent{Type: models.EventPlayerKill}), now); actor.vehicle != "" || target != "" {
+		t.Error("nil tracker tagged a vehicle")
+	}
+}
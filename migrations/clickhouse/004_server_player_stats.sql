@@ -0,0 +1,183 @@
+-- Migration: Server-scoped player aggregates
+-- Mirrors player_stats_daily with server_id as an extra dimension so per-server
+-- leaderboards can rank by any of the global leaderboard stats.
+
+-- Step 1: Target table (same shape as player_stats_daily + server_id)
+CREATE TABLE IF NOT EXISTS mohaa_stats.player_server_stats_daily
+(
+    day DateTime,
+    server_id String,
+    player_id String,
+    player_name SimpleAggregateFunction(anyLast, String),
+
+    kills UInt64,
+    deaths UInt64,
+    headshots UInt64,
+    shots_fired UInt64,
+    shots_hit UInt64,
+    total_damage UInt64,
+    bot_kills UInt64,
+
+    bash_kills UInt64,
+    grenade_kills UInt64,
+    roadkills UInt64,
+    telefrags UInt64,
+    crushed UInt64,
+    teamkills UInt64,
+    suicides UInt64,
+
+    reloads UInt64,
+    weapon_swaps UInt64,
+    no_ammo UInt64,
+
+    distance_units Float64,
+    sprinted Float64,
+    swam Float64,
+    driven Float64,
+    jumps UInt64,
+    crouch_events UInt64,
+    prone_events UInt64,
+    ladders UInt64,
+
+    health_picked UInt64,
+    ammo_picked UInt64,
+    armor_picked UInt64,
+    items_picked UInt64,
+
+    matches_played AggregateFunction(uniqExact, UUID),
+    matches_won UInt64,
+    games_finished UInt64,
+
+    last_active SimpleAggregateFunction(max, DateTime64(3))
+)
+ENGINE = SummingMergeTree()
+PARTITION BY toYYYYMM(day)
+ORDER BY (server_id, player_id, day);
+
+-- Step 2: Actor view (same expressions as mv_feed_actor_stats in 003)
+CREATE MATERIALIZED VIEW IF NOT EXISTS mohaa_stats.mv_feed_actor_server_stats TO mohaa_stats.player_server_stats_daily
+AS SELECT
+    toStartOfDay(timestamp) AS day,
+    server_id,
+    actor_id AS player_id,
+    argMax(actor_name, if(actor_name != '', toUnixTimestamp64Nano(timestamp), 0)) AS player_name,
+
+    countIf(event_type = 'player_kill') AS kills,
+    0 AS deaths,
+    countIf(event_type = 'player_kill' AND hitloc IN ('head', 'helmet')) AS headshots,
+    countIf(event_type = 'weapon_fire') AS shots_fired,
+    countIf(event_type = 'weapon_hit') AS shots_hit,
+    sumIf(damage, event_type = 'damage') AS total_damage,
+    countIf(event_type = 'bot_killed') AS bot_kills,
+
+    countIf(event_type = 'player_bash') AS bash_kills,
+    countIf(
+        (event_type = 'grenade_explode') OR 
+        (event_type = 'player_kill' AND actor_weapon IN ('grenade', 'm2_grenade', 'stielhandgranate', 'nebelhandgranate'))
+    ) AS grenade_kills,
+    countIf(event_type = 'player_roadkill') AS roadkills,
+    countIf(event_type = 'player_telefragged') AS telefrags,
+    countIf(event_type = 'player_crushed') AS crushed,
+    countIf(event_type = 'player_teamkill') AS teamkills,
+    countIf(event_type = 'player_suicide') AS suicides,
+
+    countIf(event_type = 'reload') AS reloads,
+    countIf(event_type = 'weapon_change') AS weapon_swaps,
+    countIf(event_type = 'weapon_no_ammo') AS no_ammo,
+
+    sum(JSONExtractFloat(raw_json, 'walked')) + sum(JSONExtractFloat(raw_json, 'sprinted')) + sum(JSONExtractFloat(raw_json, 'swam')) + sum(JSONExtractFloat(raw_json, 'driven')) AS distance_units,
+    sum(JSONExtractFloat(raw_json, 'sprinted')) AS sprinted,
+    sum(JSONExtractFloat(raw_json, 'swam')) AS swam,
+    sum(JSONExtractFloat(raw_json, 'driven')) AS driven,
+    countIf(event_type = 'jump') AS jumps,
+    countIf(event_type = 'crouch') AS crouch_events,
+    countIf(event_type = 'prone') AS prone_events,
+    countIf(event_type = 'ladder_mount') AS ladders,
+
+    countIf(event_type = 'health_pickup') AS health_picked,
+    countIf(event_type = 'ammo_pickup') AS ammo_picked,
+    countIf(event_type = 'armor_pickup') AS armor_picked,
+    countIf(event_type = 'item_pickup') AS items_picked,
+
+    uniqExactState(match_id) AS matches_played,
+    countIf((event_type = 'match_outcome') AND (match_outcome = 1)) AS matches_won,
+    countIf((event_type = 'match_outcome')) AS games_finished,
+
+    max(timestamp) AS last_active
+FROM mohaa_stats.raw_events
+WHERE actor_id != '' AND actor_id != 'world' AND server_id != ''
+GROUP BY day, server_id, actor_id;
+
+-- Step 3: Target view (deaths)
+CREATE MATERIALIZED VIEW IF NOT EXISTS mohaa_stats.mv_feed_target_server_stats TO mohaa_stats.player_server_stats_daily
+AS SELECT
+    toStartOfDay(timestamp) AS day,
+    server_id,
+    target_id AS player_id,
+    argMax(target_name, if(target_name != '', toUnixTimestamp64Nano(timestamp), 0)) AS player_name,
+    count() AS deaths,
+    uniqExactState(match_id) AS matches_played,
+    max(timestamp) AS last_active
+FROM mohaa_stats.raw_events
+WHERE event_type = 'player_kill' AND target_id != '' AND target_id != 'world' AND server_id != ''
+GROUP BY day, server_id, target_id;
+
+-- Step 4: Backfill from existing raw events
+INSERT INTO mohaa_stats.player_server_stats_daily
+SELECT
+    toStartOfDay(timestamp) AS day,
+    server_id,
+    actor_id AS player_id,
+    argMax(actor_name, if(actor_name != '', toUnixTimestamp64Nano(timestamp), 0)) AS player_name,
+    countIf(event_type = 'player_kill') AS kills,
+    0 AS deaths,
+    countIf(event_type = 'player_kill' AND hitloc IN ('head', 'helmet')) AS headshots,
+    countIf(event_type = 'weapon_fire') AS shots_fired,
+    countIf(event_type = 'weapon_hit') AS shots_hit,
+    sumIf(damage, event_type = 'damage') AS total_damage,
+    countIf(event_type = 'bot_killed') AS bot_kills,
+    countIf(event_type = 'player_bash') AS bash_kills,
+    countIf(
+        (event_type = 'grenade_explode') OR 
+        (event_type = 'player_kill' AND actor_weapon IN ('grenade', 'm2_grenade', 'stielhandgranate', 'nebelhandgranate'))
+    ) AS grenade_kills,
+    countIf(event_type = 'player_roadkill') AS roadkills,
+    countIf(event_type = 'player_telefragged') AS telefrags,
+    countIf(event_type = 'player_crushed') AS crushed,
+    countIf(event_type = 'player_teamkill') AS teamkills,
+    countIf(event_type = 'player_suicide') AS suicides,
+    countIf(event_type = 'reload') AS reloads,
+    countIf(event_type = 'weapon_change') AS weapon_swaps,
+    countIf(event_type = 'weapon_no_ammo') AS no_ammo,
+    sum(JSONExtractFloat(raw_json, 'walked')) + sum(JSONExtractFloat(raw_json, 'sprinted')) + sum(JSONExtractFloat(raw_json, 'swam')) + sum(JSONExtractFloat(raw_json, 'driven')) AS distance_units,
+    sum(JSONExtractFloat(raw_json, 'sprinted')) AS sprinted,
+    sum(JSONExtractFloat(raw_json, 'swam')) AS swam,
+    sum(JSONExtractFloat(raw_json, 'driven')) AS driven,
+    countIf(event_type = 'jump') AS jumps,
+    countIf(event_type = 'crouch') AS crouch_events,
+    countIf(event_type = 'prone') AS prone_events,
+    countIf(event_type = 'ladder_mount') AS ladders,
+    countIf(event_type = 'health_pickup') AS health_picked,
+    countIf(event_type = 'ammo_pickup') AS ammo_picked,
+    countIf(event_type = 'armor_pickup') AS armor_picked,
+    countIf(event_type = 'item_pickup') AS items_picked,
+    uniqExactState(match_id) AS matches_played,
+    countIf((event_type = 'match_outcome') AND (match_outcome = 1)) AS matches_won,
+    countIf((event_type = 'match_outcome')) AS games_finished,
+    max(timestamp) AS last_active
+FROM mohaa_stats.raw_events
+WHERE actor_id != '' AND actor_id != 'world' AND server_id != ''
+GROUP BY day, server_id, actor_id;
+
+INSERT INTO mohaa_stats.player_server_stats_daily (day, server_id, player_id, player_name, deaths, matches_played, last_active)
+SELECT
+    toStartOfDay(timestamp) AS day,
+    server_id,
+    target_id AS player_id,
+    argMax(target_name, if(target_name != '', toUnixTimestamp64Nano(timestamp), 0)) AS player_name,
+    count() AS deaths,
+    uniqExactState(match_id) AS matches_played,
+    max(timestamp) AS last_active
+FROM mohaa_stats.raw_events
+WHERE event_type = 'player_kill' AND target_id != '' AND target_id != 'world' AND server_id != ''
+GROUP BY day, server_id, target_id;
@@ -0,0 +1,6 @@
+-- Migration: Tenant tag on raw events
+-- Stamped at ingest from the authenticated server's tenant ('' = default
+-- community). Player aggregates are scoped through player_server_stats_daily,
+-- whose server_id already maps to exactly one tenant.
+
+ALTER TABLE mohaa_stats.raw_events ADD COLUMN IF NOT EXISTS tenant_id LowCardinality(String) DEFAULT '' AFTER server_id;
@@ -0,0 +1,156 @@
+-- Migration: Event sampling
+-- High-volume event types can be stored at a sampling rate (EVENT_SAMPLE_RATES).
+-- Each stored row records how many events it stands for in sample_weight, and
+-- the player and weapon aggregates sum that weight instead of counting rows so
+-- totals stay unbiased. Unsampled rows have weight 1, so existing data and
+-- aggregates are unchanged.
+
+ALTER TABLE mohaa_stats.raw_events ADD COLUMN IF NOT EXISTS sample_weight UInt16 DEFAULT 1;
+
+-- Step 1: Global actor view (expressions from 003, weighted)
+DROP VIEW IF EXISTS mohaa_stats.mv_feed_actor_stats;
+
+CREATE MATERIALIZED VIEW mohaa_stats.mv_feed_actor_stats TO mohaa_stats.player_stats_daily
+AS SELECT
+    toStartOfDay(timestamp) AS day,
+    actor_id AS player_id,
+    argMax(actor_name, if(actor_name != '', toUnixTimestamp64Nano(timestamp), 0)) AS player_name,
+
+    sumIf(sample_weight, event_type = 'player_kill') AS kills,
+    0 AS deaths,
+    sumIf(sample_weight, event_type = 'player_kill' AND hitloc IN ('head', 'helmet')) AS headshots,
+    sumIf(sample_weight, event_type = 'weapon_fire') AS shots_fired,
+    sumIf(sample_weight, event_type = 'weapon_hit') AS shots_hit,
+    sumIf(damage * sample_weight, event_type = 'damage') AS total_damage,
+    sumIf(sample_weight, event_type = 'bot_killed') AS bot_kills,
+
+    sumIf(sample_weight, event_type = 'player_bash') AS bash_kills,
+    sumIf(sample_weight,
+        (event_type = 'grenade_explode') OR 
+        (event_type = 'player_kill' AND actor_weapon IN ('grenade', 'm2_grenade', 'stielhandgranate', 'nebelhandgranate'))
+    ) AS grenade_kills,
+    sumIf(sample_weight, event_type = 'player_roadkill') AS roadkills,
+    sumIf(sample_weight, event_type = 'player_telefragged') AS telefrags,
+    sumIf(sample_weight, event_type = 'player_crushed') AS crushed,
+    sumIf(sample_weight, event_type = 'player_teamkill') AS teamkills,
+    sumIf(sample_weight, event_type = 'player_suicide') AS suicides,
+
+    sumIf(sample_weight, event_type = 'reload') AS reloads,
+    sumIf(sample_weight, event_type = 'weapon_change') AS weapon_swaps,
+    sumIf(sample_weight, event_type = 'weapon_no_ammo') AS no_ammo,
+
+    sum(JSONExtractFloat(raw_json, 'walked') * sample_weight) + sum(JSONExtractFloat(raw_json, 'sprinted') * sample_weight) + sum(JSONExtractFloat(raw_json, 'swam') * sample_weight) + sum(JSONExtractFloat(raw_json, 'driven') * sample_weight) AS distance_units,
+    sum(JSONExtractFloat(raw_json, 'sprinted') * sample_weight) AS sprinted,
+    sum(JSONExtractFloat(raw_json, 'swam') * sample_weight) AS swam,
+    sum(JSONExtractFloat(raw_json, 'driven') * sample_weight) AS driven,
+    sumIf(sample_weight, event_type = 'jump') AS jumps,
+    sumIf(sample_weight, event_type = 'crouch') AS crouch_events,
+    sumIf(sample_weight, event_type = 'prone') AS prone_events,
+    sumIf(sample_weight, event_type = 'ladder_mount') AS ladders,
+
+    sumIf(sample_weight, event_type = 'health_pickup') AS health_picked,
+    sumIf(sample_weight, event_type = 'ammo_pickup') AS ammo_picked,
+    sumIf(sample_weight, event_type = 'armor_pickup') AS armor_picked,
+    sumIf(sample_weight, event_type = 'item_pickup') AS items_picked,
+
+    uniqExactState(match_id) AS matches_played,
+    sumIf(sample_weight, (event_type = 'match_outcome') AND (match_outcome = 1)) AS matches_won,
+    sumIf(sample_weight, (event_type = 'match_outcome')) AS games_finished,
+
+    max(timestamp) AS last_active
+FROM mohaa_stats.raw_events
+WHERE actor_id != '' AND actor_id != 'world'
+GROUP BY day, actor_id;
+
+-- Step 2: Server actor view (expressions from 004, weighted)
+DROP VIEW IF EXISTS mohaa_stats.mv_feed_actor_server_stats;
+
+CREATE MATERIALIZED VIEW mohaa_stats.mv_feed_actor_server_stats TO mohaa_stats.player_server_stats_daily
+AS SELECT
+    toStartOfDay(timestamp) AS day,
+    server_id,
+    actor_id AS player_id,
+    argMax(actor_name, if(actor_name != '', toUnixTimestamp64Nano(timestamp), 0)) AS player_name,
+
+    sumIf(sample_weight, event_type = 'player_kill') AS kills,
+    0 AS deaths,
+    sumIf(sample_weight, event_type = 'player_kill' AND hitloc IN ('head', 'helmet')) AS headshots,
+    sumIf(sample_weight, event_type = 'weapon_fire') AS shots_fired,
+    sumIf(sample_weight, event_type = 'weapon_hit') AS shots_hit,
+    sumIf(damage * sample_weight, event_type = 'damage') AS total_damage,
+    sumIf(sample_weight, event_type = 'bot_killed') AS bot_kills,
+
+    sumIf(sample_weight, event_type = 'player_bash') AS bash_kills,
+    sumIf(sample_weight,
+        (event_type = 'grenade_explode') OR 
+        (event_type = 'player_kill' AND actor_weapon IN ('grenade', 'm2_grenade', 'stielhandgranate', 'nebelhandgranate'))
+    ) AS grenade_kills,
+    sumIf(sample_weight, event_type = 'player_roadkill') AS roadkills,
+    sumIf(sample_weight, event_type = 'player_telefragged') AS telefrags,
+    sumIf(sample_weight, event_type = 'player_crushed') AS crushed,
+    sumIf(sample_weight, event_type = 'player_teamkill') AS teamkills,
+    sumIf(sample_weight, event_type = 'player_suicide') AS suicides,
+
+    sumIf(sample_weight, event_type = 'reload') AS reloads,
+    sumIf(sample_weight, event_type = 'weapon_change') AS weapon_swaps,
+    sumIf(sample_weight, event_type = 'weapon_no_ammo') AS no_ammo,
+
+    sum(JSONExtractFloat(raw_json, 'walked') * sample_weight) + sum(JSONExtractFloat(raw_json, 'sprinted') * sample_weight) + sum(JSONExtractFloat(raw_json, 'swam') * sample_weight) + sum(JSONExtractFloat(raw_json, 'driven') * sample_weight) AS distance_units,
+    sum(JSONExtractFloat(raw_json, 'sprinted') * sample_weight) AS sprinted,
+    sum(JSONExtractFloat(raw_json, 'swam') * sample_weight) AS swam,
+    sum(JSONExtractFloat(raw_json, 'driven') * sample_weight) AS driven,
+    sumIf(sample_weight, event_type = 'jump') AS jumps,
+    sumIf(sample_weight, event_type = 'crouch') AS crouch_events,
+    sumIf(sample_weight, event_type = 'prone') AS prone_events,
+    sumIf(sample_weight, event_type = 'ladder_mount') AS ladders,
+
+    sumIf(sample_weight, event_type = 'health_pickup') AS health_picked,
+    sumIf(sample_weight, event_type = 'ammo_pickup') AS ammo_picked,
+    sumIf(sample_weight, event_type = 'armor_pickup') AS armor_picked,
+    sumIf(sample_weight, event_type = 'item_pickup') AS items_picked,
+
+    uniqExactState(match_id) AS matches_played,
+    sumIf(sample_weight, (event_type = 'match_outcome') AND (match_outcome = 1)) AS matches_won,
+    sumIf(sample_weight, (event_type = 'match_outcome')) AS games_finished,
+
+    max(timestamp) AS last_active
+FROM mohaa_stats.raw_events
+WHERE actor_id != '' AND actor_id != 'world' AND server_id != ''
+GROUP BY day, server_id, actor_id;
+
+-- Step 3: Weapon view. It owns its storage, so recreating it empties the
+-- table, so it is refilled from raw events.
+DROP VIEW IF EXISTS mohaa_stats.weapon_stats_mv;
+
+CREATE MATERIALIZED VIEW mohaa_stats.weapon_stats_mv
+ENGINE = SummingMergeTree()
+PARTITION BY toYYYYMM(day)
+ORDER BY (actor_weapon, actor_id, day)
+AS SELECT
+    toStartOfDay(timestamp) AS day,
+    actor_weapon,
+    actor_id,
+    argMax(actor_name, if(actor_name != '', toUnixTimestamp64Nano(timestamp), 0)) AS actor_name,
+    sumIf(sample_weight, event_type = 'player_kill') AS kills,
+    sumIf(sample_weight, event_type = 'player_kill' AND hitloc IN ('head', 'helmet')) AS headshots,
+    sumIf(sample_weight, event_type = 'weapon_fire') AS shots_fired,
+    sumIf(sample_weight, event_type = 'weapon_hit') AS shots_hit
+FROM mohaa_stats.raw_events
+WHERE actor_weapon != '' AND actor_id != '' AND actor_id != 'world'
+GROUP BY day, actor_weapon, actor_id;
+
+INSERT INTO mohaa_stats.weapon_stats_mv
+SELECT
+    toStartOfDay(timestamp) AS day,
+    actor_weapon,
+    actor_id,
+    argMax(actor_name, if(actor_name != '', toUnixTimestamp64Nano(timestamp), 0)) AS actor_name,
+    sumIf(sample_weight, event_type = 'player_kill') AS kills,
+    sumIf(sample_weight, event_type = 'player_kill' AND hitloc IN ('head', 'helmet')) AS headshots,
+    sumIf(sample_weight, event_type = 'weapon_fire') AS shots_fired,
+    sumIf(sample_weight, event_type = 'weapon_hit') AS shots_hit
+FROM mohaa_stats.raw_events
+WHERE actor_weapon != '' AND actor_id != '' AND actor_id != 'world'
+GROUP BY day, actor_weapon, actor_id;
+
+-- Target views count player_kill rows only, and kills are never sampled
@@ -0,0 +1,104 @@
+-- Migration: Narrow table for movement events
+-- With MOVEMENT_TABLE=true the worker writes jump/crouch/prone/ladder/distance
+-- events here instead of raw_events. They only carry a player, a position and
+-- distance counters, so the typed columns replace raw_json and the 20-odd
+-- combat columns that were always empty for them.
+
+CREATE TABLE IF NOT EXISTS mohaa_stats.movement_events
+(
+    timestamp DateTime64(3) CODEC(DoubleDelta, ZSTD(1)),
+    match_id UUID,
+    server_id String CODEC(ZSTD(1)),
+    tenant_id LowCardinality(String) DEFAULT '',
+    map_name LowCardinality(String),
+    event_type LowCardinality(String),
+
+    actor_id String CODEC(ZSTD(1)),
+    actor_name String CODEC(ZSTD(1)),
+    actor_smf_id UInt64 DEFAULT 0,
+    actor_pos_x Float32 CODEC(Gorilla, ZSTD(1)),
+    actor_pos_y Float32 CODEC(Gorilla, ZSTD(1)),
+    actor_pos_z Float32 CODEC(Gorilla, ZSTD(1)),
+    actor_stance LowCardinality(String) DEFAULT '',
+
+    walked Float32 CODEC(Gorilla, ZSTD(1)),
+    sprinted Float32 CODEC(Gorilla, ZSTD(1)),
+    swam Float32 CODEC(Gorilla, ZSTD(1)),
+    driven Float32 CODEC(Gorilla, ZSTD(1)),
+    distance Float32 CODEC(Gorilla, ZSTD(1)),
+    fall_height Float32 CODEC(Gorilla, ZSTD(1)),
+
+    sample_weight UInt16 DEFAULT 1
+)
+ENGINE = MergeTree()
+PARTITION BY toYYYYMM(timestamp)
+ORDER BY (actor_id, event_type, timestamp)
+TTL toDate(timestamp) + INTERVAL 2 YEAR;
+
+-- Movement rows from both tables in one shape. Readers use this so stats stay
+-- complete whether or not the narrow path is enabled, and across the switch.
+CREATE VIEW IF NOT EXISTS mohaa_stats.movement_events_all AS
+SELECT
+    timestamp, match_id, server_id, tenant_id, map_name, event_type,
+    actor_id, actor_name, actor_smf_id, actor_pos_x, actor_pos_y, actor_pos_z, actor_stance,
+    toFloat32(JSONExtractFloat(raw_json, 'walked')) AS walked,
+    toFloat32(JSONExtractFloat(raw_json, 'sprinted')) AS sprinted,
+    toFloat32(JSONExtractFloat(raw_json, 'swam')) AS swam,
+    toFloat32(JSONExtractFloat(raw_json, 'driven')) AS driven,
+    distance,
+    toFloat32(JSONExtractFloat(raw_json, 'fall_height')) AS fall_height,
+    sample_weight
+FROM mohaa_stats.raw_events
+WHERE event_type IN ('jump', 'land', 'crouch', 'prone', 'player_stand', 'distance', 'player_movement', 'ladder_mount', 'ladder_dismount')
+UNION ALL
+SELECT
+    timestamp, match_id, server_id, tenant_id, map_name, event_type,
+    actor_id, actor_name, actor_smf_id, actor_pos_x, actor_pos_y, actor_pos_z, actor_stance,
+    walked, sprinted, swam, driven, distance, fall_height,
+    sample_weight
+FROM mohaa_stats.movement_events;
+
+-- Feed the player aggregates from the narrow table (movement columns of the
+-- actor views in 006, every other stat sums to 0)
+CREATE MATERIALIZED VIEW IF NOT EXISTS mohaa_stats.mv_feed_movement_stats TO mohaa_stats.player_stats_daily
+AS SELECT
+    toStartOfDay(timestamp) AS day,
+    actor_id AS player_id,
+    argMax(actor_name, if(actor_name != '', toUnixTimestamp64Nano(timestamp), 0)) AS player_name,
+
+    sum((walked + sprinted + swam + driven) * sample_weight) AS distance_units,
+    sum(sprinted * sample_weight) AS sprinted,
+    sum(swam * sample_weight) AS swam,
+    sum(driven * sample_weight) AS driven,
+    sumIf(sample_weight, event_type = 'jump') AS jumps,
+    sumIf(sample_weight, event_type = 'crouch') AS crouch_events,
+    sumIf(sample_weight, event_type = 'prone') AS prone_events,
+    sumIf(sample_weight, event_type = 'ladder_mount') AS ladders,
+
+    uniqExactState(match_id) AS matches_played,
+    max(timestamp) AS last_active
+FROM mohaa_stats.movement_events
+WHERE actor_id != '' AND actor_id != 'world'
+GROUP BY day, actor_id;
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS mohaa_stats.mv_feed_movement_server_stats TO mohaa_stats.player_server_stats_daily
+AS SELECT
+    toStartOfDay(timestamp) AS day,
+    server_id,
+    actor_id AS player_id,
+    argMax(actor_name, if(actor_name != '', toUnixTimestamp64Nano(timestamp), 0)) AS player_name,
+
+    sum((walked + sprinted + swam + driven) * sample_weight) AS distance_units,
+    sum(sprinted * sample_weight) AS sprinted,
+    sum(swam * sample_weight) AS swam,
+    sum(driven * sample_weight) AS driven,
+    sumIf(sample_weight, event_type = 'jump') AS jumps,
+    sumIf(sample_weight, event_type = 'crouch') AS crouch_events,
+    sumIf(sample_weight, event_type = 'prone') AS prone_events,
+    sumIf(sample_weight, event_type = 'ladder_mount') AS ladders,
+
+    uniqExactState(match_id) AS matches_played,
+    max(timestamp) AS last_active
+FROM mohaa_stats.movement_events
+WHERE actor_id != '' AND actor_id != 'world' AND server_id != ''
+GROUP BY day, server_id, actor_id;
@@ -0,0 +1,94 @@
+-- Migration: Dedicated table for position samples
+-- Heatmaps group positions into grid cells per map. Against raw_events that
+-- scans every column family sorted by event type and actor, and the heatmap
+-- scans compete with the stats queries for the same parts. positions keeps one
+-- row per (event, player) sorted by map and grid cell so a heatmap reads a
+-- contiguous range of a small table.
+
+CREATE TABLE IF NOT EXISTS mohaa_stats.positions
+(
+    timestamp DateTime64(3) CODEC(DoubleDelta, ZSTD(1)),
+    match_id UUID,
+    server_id String CODEC(ZSTD(1)),
+    tenant_id LowCardinality(String) DEFAULT '',
+    map_name LowCardinality(String),
+    event_type LowCardinality(String),
+
+    -- 'actor' for the player who did something (killer, shooter, mover),
+    -- 'target' for the player it was done to (victim)
+    role LowCardinality(String),
+    player_id String CODEC(ZSTD(1)),
+
+    pos_x Float32 CODEC(Gorilla, ZSTD(1)),
+    pos_y Float32 CODEC(Gorilla, ZSTD(1)),
+    pos_z Float32 CODEC(Gorilla, ZSTD(1)),
+
+    -- 50-unit grid cell, the resolution of the map heatmaps
+    cell_x Int32 MATERIALIZED toInt32(round(pos_x / 50)),
+    cell_y Int32 MATERIALIZED toInt32(round(pos_y / 50)),
+
+    sample_weight UInt16 DEFAULT 1,
+
+    INDEX idx_player player_id TYPE bloom_filter(0.01) GRANULARITY 4,
+    INDEX idx_match match_id TYPE bloom_filter(0.01) GRANULARITY 4
+)
+ENGINE = MergeTree()
+PARTITION BY toYYYYMM(timestamp)
+ORDER BY (map_name, event_type, role, cell_x, cell_y, timestamp)
+TTL toDate(timestamp) + INTERVAL 2 YEAR;
+
+-- Actor positions: kills (killer), weapon fire/reload/change and generic
+-- player events
+CREATE MATERIALIZED VIEW IF NOT EXISTS mohaa_stats.mv_feed_positions_actor TO mohaa_stats.positions
+AS SELECT
+    timestamp, match_id, server_id, tenant_id, map_name, event_type,
+    'actor' AS role,
+    actor_id AS player_id,
+    actor_pos_x AS pos_x, actor_pos_y AS pos_y, actor_pos_z AS pos_z,
+    sample_weight
+FROM mohaa_stats.raw_events
+WHERE actor_id != '' AND actor_id != 'world' AND (actor_pos_x != 0 OR actor_pos_y != 0);
+
+-- Target positions: victims of kills
+CREATE MATERIALIZED VIEW IF NOT EXISTS mohaa_stats.mv_feed_positions_target TO mohaa_stats.positions
+AS SELECT
+    timestamp, match_id, server_id, tenant_id, map_name, event_type,
+    'target' AS role,
+    target_id AS player_id,
+    target_pos_x AS pos_x, target_pos_y AS pos_y, target_pos_z AS pos_z,
+    sample_weight
+FROM mohaa_stats.raw_events
+WHERE target_id != '' AND (target_pos_x != 0 OR target_pos_y != 0);
+
+-- Movement events written to the narrow table (MOVEMENT_TABLE=true)
+CREATE MATERIALIZED VIEW IF NOT EXISTS mohaa_stats.mv_feed_positions_movement TO mohaa_stats.positions
+AS SELECT
+    timestamp, match_id, server_id, tenant_id, map_name, event_type,
+    'actor' AS role,
+    actor_id AS player_id,
+    actor_pos_x AS pos_x, actor_pos_y AS pos_y, actor_pos_z AS pos_z,
+    sample_weight
+FROM mohaa_stats.movement_events
+WHERE actor_id != '' AND actor_id != 'world' AND (actor_pos_x != 0 OR actor_pos_y != 0);
+
+-- Backfill from the events already stored
+INSERT INTO mohaa_stats.positions (timestamp, match_id, server_id, tenant_id, map_name, event_type, role, player_id, pos_x, pos_y, pos_z, sample_weight)
+SELECT
+    timestamp, match_id, server_id, tenant_id, map_name, event_type,
+    'actor', actor_id, actor_pos_x, actor_pos_y, actor_pos_z, sample_weight
+FROM mohaa_stats.raw_events
+WHERE actor_id != '' AND actor_id != 'world' AND (actor_pos_x != 0 OR actor_pos_y != 0);
+
+INSERT INTO mohaa_stats.positions (timestamp, match_id, server_id, tenant_id, map_name, event_type, role, player_id, pos_x, pos_y, pos_z, sample_weight)
+SELECT
+    timestamp, match_id, server_id, tenant_id, map_name, event_type,
+    'target', target_id, target_pos_x, target_pos_y, target_pos_z, sample_weight
+FROM mohaa_stats.raw_events
+WHERE target_id != '' AND (target_pos_x != 0 OR target_pos_y != 0);
+
+INSERT INTO mohaa_stats.positions (timestamp, match_id, server_id, tenant_id, map_name, event_type, role, player_id, pos_x, pos_y, pos_z, sample_weight)
+SELECT
+    timestamp, match_id, server_id, tenant_id, map_name, event_type,
+    'actor', actor_id, actor_pos_x, actor_pos_y, actor_pos_z, sample_weight
+FROM mohaa_stats.movement_events
+WHERE actor_id != '' AND actor_id != 'world' AND (actor_pos_x != 0 OR actor_pos_y != 0);
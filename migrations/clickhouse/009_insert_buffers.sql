@@ -0,0 +1,14 @@
+-- Migration: Buffer tables in front of the event tables
+-- With CLICKHOUSE_INSERT_MODE=buffer the worker sends small batches here.
+-- ClickHouse keeps them in memory and flushes to the destination table (and
+-- through its materialized views) once any max threshold or all min thresholds
+-- are reached, so a quiet server no longer creates a part per flush interval.
+-- Rows still in a buffer are lost if ClickHouse is killed.
+--
+-- Buffer(db, table, layers, min_time, max_time, min_rows, max_rows, min_bytes, max_bytes)
+
+CREATE TABLE IF NOT EXISTS mohaa_stats.raw_events_buffer AS mohaa_stats.raw_events
+ENGINE = Buffer(mohaa_stats, raw_events, 4, 10, 60, 10000, 500000, 10000000, 100000000);
+
+CREATE TABLE IF NOT EXISTS mohaa_stats.movement_events_buffer AS mohaa_stats.movement_events
+ENGINE = Buffer(mohaa_stats, movement_events, 4, 10, 60, 10000, 500000, 10000000, 100000000);
@@ -0,0 +1,14 @@
+-- Migration: Voided matches
+-- Matches an admin voided (POST /admin/matches/{id}/void). Their events stay in
+-- raw_events so a void can be undone, but aggregate rebuilds skip them.
+-- Restoring a match writes a newer row with voided = 0.
+
+CREATE TABLE IF NOT EXISTS mohaa_stats.voided_matches
+(
+    match_id UUID,
+    voided UInt8,
+    reason String DEFAULT '',
+    changed_at DateTime64(3) DEFAULT now64(3)
+)
+ENGINE = ReplacingMergeTree(changed_at)
+ORDER BY match_id;
@@ -0,0 +1,244 @@
+-- Migration: Round phase tagging
+-- The worker tags each event with the phase its match was in when it arrived:
+-- round, warmup (warmup_start .. warmup_end) or intermission (round_end or
+-- intermission_start .. the next round_start). The player aggregates only
+-- count in-round events, raw event queries include the others on request.
+-- Existing rows default to round, so their aggregates are unchanged.
+
+ALTER TABLE mohaa_stats.raw_events ADD COLUMN IF NOT EXISTS round_phase LowCardinality(String) DEFAULT 'round';
+ALTER TABLE mohaa_stats.movement_events ADD COLUMN IF NOT EXISTS round_phase LowCardinality(String) DEFAULT 'round';
+
+-- Buffer tables copy their destination's columns when created. Dropping a
+-- buffer flushes it first.
+DROP TABLE IF EXISTS mohaa_stats.raw_events_buffer;
+CREATE TABLE mohaa_stats.raw_events_buffer AS mohaa_stats.raw_events
+ENGINE = Buffer(mohaa_stats, raw_events, 4, 10, 60, 10000, 500000, 10000000, 100000000);
+
+DROP TABLE IF EXISTS mohaa_stats.movement_events_buffer;
+CREATE TABLE mohaa_stats.movement_events_buffer AS mohaa_stats.movement_events
+ENGINE = Buffer(mohaa_stats, movement_events, 4, 10, 60, 10000, 500000, 10000000, 100000000);
+
+-- Step 1: Global actor view (expressions from 006)
+DROP VIEW IF EXISTS mohaa_stats.mv_feed_actor_stats;
+
+CREATE MATERIALIZED VIEW mohaa_stats.mv_feed_actor_stats TO mohaa_stats.player_stats_daily
+AS SELECT
+    toStartOfDay(timestamp) AS day,
+    actor_id AS player_id,
+    argMax(actor_name, if(actor_name != '', toUnixTimestamp64Nano(timestamp), 0)) AS player_name,
+
+    sumIf(sample_weight, event_type = 'player_kill') AS kills,
+    0 AS deaths,
+    sumIf(sample_weight, event_type = 'player_kill' AND hitloc IN ('head', 'helmet')) AS headshots,
+    sumIf(sample_weight, event_type = 'weapon_fire') AS shots_fired,
+    sumIf(sample_weight, event_type = 'weapon_hit') AS shots_hit,
+    sumIf(damage * sample_weight, event_type = 'damage') AS total_damage,
+    sumIf(sample_weight, event_type = 'bot_killed') AS bot_kills,
+
+    sumIf(sample_weight, event_type = 'player_bash') AS bash_kills,
+    sumIf(sample_weight,
+        (event_type = 'grenade_explode') OR 
+        (event_type = 'player_kill' AND actor_weapon IN ('grenade', 'm2_grenade', 'stielhandgranate', 'nebelhandgranate'))
+    ) AS grenade_kills,
+    sumIf(sample_weight, event_type = 'player_roadkill') AS roadkills,
+    sumIf(sample_weight, event_type = 'player_telefragged') AS telefrags,
+    sumIf(sample_weight, event_type = 'player_crushed') AS crushed,
+    sumIf(sample_weight, event_type = 'player_teamkill') AS teamkills,
+    sumIf(sample_weight, event_type = 'player_suicide') AS suicides,
+
+    sumIf(sample_weight, event_type = 'reload') AS reloads,
+    sumIf(sample_weight, event_type = 'weapon_change') AS weapon_swaps,
+    sumIf(sample_weight, event_type = 'weapon_no_ammo') AS no_ammo,
+
+    sum(JSONExtractFloat(raw_json, 'walked') * sample_weight) + sum(JSONExtractFloat(raw_json, 'sprinted') * sample_weight) + sum(JSONExtractFloat(raw_json, 'swam') * sample_weight) + sum(JSONExtractFloat(raw_json, 'driven') * sample_weight) AS distance_units,
+    sum(JSONExtractFloat(raw_json, 'sprinted') * sample_weight) AS sprinted,
+    sum(JSONExtractFloat(raw_json, 'swam') * sample_weight) AS swam,
+    sum(JSONExtractFloat(raw_json, 'driven') * sample_weight) AS driven,
+    sumIf(sample_weight, event_type = 'jump') AS jumps,
+    sumIf(sample_weight, event_type = 'crouch') AS crouch_events,
+    sumIf(sample_weight, event_type = 'prone') AS prone_events,
+    sumIf(sample_weight, event_type = 'ladder_mount') AS ladders,
+
+    sumIf(sample_weight, event_type = 'health_pickup') AS health_picked,
+    sumIf(sample_weight, event_type = 'ammo_pickup') AS ammo_picked,
+    sumIf(sample_weight, event_type = 'armor_pickup') AS armor_picked,
+    sumIf(sample_weight, event_type = 'item_pickup') AS items_picked,
+
+    uniqExactState(match_id) AS matches_played,
+    sumIf(sample_weight, (event_type = 'match_outcome') AND (match_outcome = 1)) AS matches_won,
+    sumIf(sample_weight, (event_type = 'match_outcome')) AS games_finished,
+
+    max(timestamp) AS last_active
+FROM mohaa_stats.raw_events
+WHERE actor_id != '' AND actor_id != 'world' AND round_phase = 'round'
+GROUP BY day, actor_id;
+
+-- Step 2: Global target view (expressions from 001)
+DROP VIEW IF EXISTS mohaa_stats.mv_feed_target_stats;
+
+CREATE MATERIALIZED VIEW mohaa_stats.mv_feed_target_stats TO mohaa_stats.player_stats_daily
+AS SELECT
+    toStartOfDay(timestamp) AS day,
+    target_id AS player_id,
+    argMax(target_name, if(target_name != '', toUnixTimestamp64Nano(timestamp), 0)) AS player_name,
+    
+    0 AS kills,
+    count() AS deaths, -- Target of a 'player_kill' event IS the death
+    0 AS headshots,
+    0 AS shots_fired,
+    0 AS shots_hit,
+    0 AS total_damage,
+    
+    0 AS bash_kills,
+    0 AS grenade_kills,
+    0 AS roadkills,
+    0 AS telefrags,
+    0 AS crushed,
+    0 AS teamkills,
+    0 AS suicides,
+    
+    0 AS reloads,
+    0 AS weapon_swaps,
+    0 AS no_ammo,
+    
+    0 AS distance_units,
+    0 AS sprinted,
+    0 AS swam,
+    0 AS driven,
+    0 AS jumps,
+    0 AS crouch_events,
+    0 AS prone_events,
+    0 AS ladders,
+    
+    0 AS health_picked,
+    0 AS ammo_picked,
+    0 AS armor_picked,
+    0 AS items_picked,
+    
+    uniqExactState(match_id) AS matches_played, -- Being killed counts as playing!
+    0 AS matches_won,
+    0 AS games_finished,
+    
+    max(timestamp) AS last_active
+FROM mohaa_stats.raw_events
+WHERE event_type = 'player_kill' AND target_id != '' AND target_id != 'world' AND round_phase = 'round'
+GROUP BY day, target_id;
+
+-- Step 3: Server actor view (expressions from 006)
+DROP VIEW IF EXISTS mohaa_stats.mv_feed_actor_server_stats;
+
+CREATE MATERIALIZED VIEW mohaa_stats.mv_feed_actor_server_stats TO mohaa_stats.player_server_stats_daily
+AS SELECT
+    toStartOfDay(timestamp) AS day,
+    server_id,
+    actor_id AS player_id,
+    argMax(actor_name, if(actor_name != '', toUnixTimestamp64Nano(timestamp), 0)) AS player_name,
+
+    sumIf(sample_weight, event_type = 'player_kill') AS kills,
+    0 AS deaths,
+    sumIf(sample_weight, event_type = 'player_kill' AND hitloc IN ('head', 'helmet')) AS headshots,
+    sumIf(sample_weight, event_type = 'weapon_fire') AS shots_fired,
+    sumIf(sample_weight, event_type = 'weapon_hit') AS shots_hit,
+    sumIf(damage * sample_weight, event_type = 'damage') AS total_damage,
+    sumIf(sample_weight, event_type = 'bot_killed') AS bot_kills,
+
+    sumIf(sample_weight, event_type = 'player_bash') AS bash_kills,
+    sumIf(sample_weight,
+        (event_type = 'grenade_explode') OR 
+        (event_type = 'player_kill' AND actor_weapon IN ('grenade', 'm2_grenade', 'stielhandgranate', 'nebelhandgranate'))
+    ) AS grenade_kills,
+    sumIf(sample_weight, event_type = 'player_roadkill') AS roadkills,
+    sumIf(sample_weight, event_type = 'player_telefragged') AS telefrags,
+    sumIf(sample_weight, event_type = 'player_crushed') AS crushed,
+    sumIf(sample_weight, event_type = 'player_teamkill') AS teamkills,
+    sumIf(sample_weight, event_type = 'player_suicide') AS suicides,
+
+    sumIf(sample_weight, event_type = 'reload') AS reloads,
+    sumIf(sample_weight, event_type = 'weapon_change') AS weapon_swaps,
+    sumIf(sample_weight, event_type = 'weapon_no_ammo') AS no_ammo,
+
+    sum(JSONExtractFloat(raw_json, 'walked') * sample_weight) + sum(JSONExtractFloat(raw_json, 'sprinted') * sample_weight) + sum(JSONExtractFloat(raw_json, 'swam') * sample_weight) + sum(JSONExtractFloat(raw_json, 'driven') * sample_weight) AS distance_units,
+    sum(JSONExtractFloat(raw_json, 'sprinted') * sample_weight) AS sprinted,
+    sum(JSONExtractFloat(raw_json, 'swam') * sample_weight) AS swam,
+    sum(JSONExtractFloat(raw_json, 'driven') * sample_weight) AS driven,
+    sumIf(sample_weight, event_type = 'jump') AS jumps,
+    sumIf(sample_weight, event_type = 'crouch') AS crouch_events,
+    sumIf(sample_weight, event_type = 'prone') AS prone_events,
+    sumIf(sample_weight, event_type = 'ladder_mount') AS ladders,
+
+    sumIf(sample_weight, event_type = 'health_pickup') AS health_picked,
+    sumIf(sample_weight, event_type = 'ammo_pickup') AS ammo_picked,
+    sumIf(sample_weight, event_type = 'armor_pickup') AS armor_picked,
+    sumIf(sample_weight, event_type = 'item_pickup') AS items_picked,
+
+    uniqExactState(match_id) AS matches_played,
+    sumIf(sample_weight, (event_type = 'match_outcome') AND (match_outcome = 1)) AS matches_won,
+    sumIf(sample_weight, (event_type = 'match_outcome')) AS games_finished,
+
+    max(timestamp) AS last_active
+FROM mohaa_stats.raw_events
+WHERE actor_id != '' AND actor_id != 'world' AND server_id != '' AND round_phase = 'round'
+GROUP BY day, server_id, actor_id;
+
+-- Step 4: Server target view (expressions from 004)
+DROP VIEW IF EXISTS mohaa_stats.mv_feed_target_server_stats;
+
+CREATE MATERIALIZED VIEW mohaa_stats.mv_feed_target_server_stats TO mohaa_stats.player_server_stats_daily
+AS SELECT
+    toStartOfDay(timestamp) AS day,
+    server_id,
+    target_id AS player_id,
+    argMax(target_name, if(target_name != '', toUnixTimestamp64Nano(timestamp), 0)) AS player_name,
+    count() AS deaths,
+    uniqExactState(match_id) AS matches_played,
+    max(timestamp) AS last_active
+FROM mohaa_stats.raw_events
+WHERE event_type = 'player_kill' AND target_id != '' AND target_id != 'world' AND server_id != '' AND round_phase = 'round'
+GROUP BY day, server_id, target_id;
+
+-- Step 5: Movement views (expressions from 007)
+DROP VIEW IF EXISTS mohaa_stats.mv_feed_movement_stats;
+
+CREATE MATERIALIZED VIEW mohaa_stats.mv_feed_movement_stats TO mohaa_stats.player_stats_daily
+AS SELECT
+    toStartOfDay(timestamp) AS day,
+    actor_id AS player_id,
+    argMax(actor_name, if(actor_name != '', toUnixTimestamp64Nano(timestamp), 0)) AS player_name,
+
+    sum((walked + sprinted + swam + driven) * sample_weight) AS distance_units,
+    sum(sprinted * sample_weight) AS sprinted,
+    sum(swam * sample_weight) AS swam,
+    sum(driven * sample_weight) AS driven,
+    sumIf(sample_weight, event_type = 'jump') AS jumps,
+    sumIf(sample_weight, event_type = 'crouch') AS crouch_events,
+    sumIf(sample_weight, event_type = 'prone') AS prone_events,
+    sumIf(sample_weight, event_type = 'ladder_mount') AS ladders,
+
+    uniqExactState(match_id) AS matches_played,
+    max(timestamp) AS last_active
+FROM mohaa_stats.movement_events
+WHERE actor_id != '' AND actor_id != 'world' AND round_phase = 'round'
+GROUP BY day, actor_id;
+DROP VIEW IF EXISTS mohaa_stats.mv_feed_movement_server_stats;
+
+CREATE MATERIALIZED VIEW mohaa_stats.mv_feed_movement_server_stats TO mohaa_stats.player_server_stats_daily
+AS SELECT
+    toStartOfDay(timestamp) AS day,
+    server_id,
+    actor_id AS player_id,
+    argMax(actor_name, if(actor_name != '', toUnixTimestamp64Nano(timestamp), 0)) AS player_name,
+
+    sum((walked + sprinted + swam + driven) * sample_weight) AS distance_units,
+    sum(sprinted * sample_weight) AS sprinted,
+    sum(swam * sample_weight) AS swam,
+    sum(driven * sample_weight) AS driven,
+    sumIf(sample_weight, event_type = 'jump') AS jumps,
+    sumIf(sample_weight, event_type = 'crouch') AS crouch_events,
+    sumIf(sample_weight, event_type = 'prone') AS prone_events,
+    sumIf(sample_weight, event_type = 'ladder_mount') AS ladders,
+
+    uniqExactState(match_id) AS matches_played,
+    max(timestamp) AS last_active
+FROM mohaa_stats.movement_events
+WHERE actor_id != '' AND actor_id != 'world' AND server_id != '' AND round_phase = 'round'
+GROUP BY day, server_id, actor_id;
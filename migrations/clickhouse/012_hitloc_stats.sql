@@ -0,0 +1,90 @@
+-- Migration: Per-hit-location aggregates
+-- Hits, damage and kills per player, weapon and body part, both as the
+-- attacker (dealt) and as the victim (taken). Feeds the two-sided body
+-- heatmap and the per-body-part accuracy of weapon detail. Like the player
+-- aggregates, only in-round events are counted.
+
+-- Step 1: Target table
+CREATE TABLE IF NOT EXISTS mohaa_stats.hitloc_stats_daily
+(
+    day DateTime,
+    tenant_id LowCardinality(String),
+    player_id String,
+    direction LowCardinality(String), -- 'dealt' (player is the attacker) or 'taken'
+    weapon LowCardinality(String),
+    hitloc LowCardinality(String),
+
+    hits UInt64,
+    damage UInt64,
+    kills UInt64
+)
+ENGINE = SummingMergeTree()
+PARTITION BY toYYYYMM(day)
+ORDER BY (player_id, direction, weapon, hitloc, tenant_id, day);
+
+-- Step 2: Attacker view
+CREATE MATERIALIZED VIEW IF NOT EXISTS mohaa_stats.mv_feed_hitloc_dealt TO mohaa_stats.hitloc_stats_daily
+AS SELECT
+    toStartOfDay(timestamp) AS day,
+    tenant_id,
+    actor_id AS player_id,
+    'dealt' AS direction,
+    actor_weapon AS weapon,
+    hitloc,
+    sumIf(sample_weight, event_type = 'weapon_hit') AS hits,
+    sumIf(damage * sample_weight, event_type = 'damage') AS damage,
+    sumIf(sample_weight, event_type = 'player_kill') AS kills
+FROM mohaa_stats.raw_events
+WHERE event_type IN ('weapon_hit', 'damage', 'player_kill')
+  AND hitloc != '' AND actor_id != '' AND actor_id != 'world' AND round_phase = 'round'
+GROUP BY day, tenant_id, actor_id, actor_weapon, hitloc;
+
+-- Step 3: Victim view
+CREATE MATERIALIZED VIEW IF NOT EXISTS mohaa_stats.mv_feed_hitloc_taken TO mohaa_stats.hitloc_stats_daily
+AS SELECT
+    toStartOfDay(timestamp) AS day,
+    tenant_id,
+    target_id AS player_id,
+    'taken' AS direction,
+    actor_weapon AS weapon,
+    hitloc,
+    sumIf(sample_weight, event_type = 'weapon_hit') AS hits,
+    sumIf(damage * sample_weight, event_type = 'damage') AS damage,
+    sumIf(sample_weight, event_type = 'player_kill') AS kills
+FROM mohaa_stats.raw_events
+WHERE event_type IN ('weapon_hit', 'damage', 'player_kill')
+  AND hitloc != '' AND target_id != '' AND target_id != 'world' AND round_phase = 'round'
+GROUP BY day, tenant_id, target_id, actor_weapon, hitloc;
+
+-- Step 4: Backfill from existing events
+INSERT INTO mohaa_stats.hitloc_stats_daily
+SELECT
+    toStartOfDay(timestamp) AS day,
+    tenant_id,
+    actor_id AS player_id,
+    'dealt' AS direction,
+    actor_weapon AS weapon,
+    hitloc,
+    sumIf(sample_weight, event_type = 'weapon_hit') AS hits,
+    sumIf(damage * sample_weight, event_type = 'damage') AS damage,
+    sumIf(sample_weight, event_type = 'player_kill') AS kills
+FROM mohaa_stats.raw_events
+WHERE event_type IN ('weapon_hit', 'damage', 'player_kill')
+  AND hitloc != '' AND actor_id != '' AND actor_id != 'world' AND round_phase = 'round'
+GROUP BY day, tenant_id, actor_id, actor_weapon, hitloc;
+
+INSERT INTO mohaa_stats.hitloc_stats_daily
+SELECT
+    toStartOfDay(timestamp) AS day,
+    tenant_id,
+    target_id AS player_id,
+    'taken' AS direction,
+    actor_weapon AS weapon,
+    hitloc,
+    sumIf(sample_weight, event_type = 'weapon_hit') AS hits,
+    sumIf(damage * sample_weight, event_type = 'damage') AS damage,
+    sumIf(sample_weight, event_type = 'player_kill') AS kills
+FROM mohaa_stats.raw_events
+WHERE event_type IN ('weapon_hit', 'damage', 'player_kill')
+  AND hitloc != '' AND target_id != '' AND target_id != 'world' AND round_phase = 'round'
+GROUP BY day, tenant_id, target_id, actor_weapon, hitloc;
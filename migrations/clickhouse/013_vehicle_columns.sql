@@ -0,0 +1,23 @@
+-- Migration: Dedicated vehicle columns
+-- Vehicle events used to store the vehicle in target_id and the seat in
+-- hitloc. They now have their own columns, and kills are stored with the
+-- vehicle and seat the killer was in and the victim's vehicle, tracked by
+-- the worker from vehicle_enter/vehicle_exit.
+
+ALTER TABLE mohaa_stats.raw_events ADD COLUMN IF NOT EXISTS vehicle LowCardinality(String) DEFAULT '';
+ALTER TABLE mohaa_stats.raw_events ADD COLUMN IF NOT EXISTS vehicle_seat LowCardinality(String) DEFAULT '';
+ALTER TABLE mohaa_stats.raw_events ADD COLUMN IF NOT EXISTS target_vehicle LowCardinality(String) DEFAULT '';
+
+-- Move existing vehicle events into the new columns. Kills from before this
+-- migration keep an empty vehicle.
+ALTER TABLE mohaa_stats.raw_events
+UPDATE
+    vehicle = if(JSONExtractString(raw_json, 'vehicle') != '', JSONExtractString(raw_json, 'vehicle'), target_id),
+    vehicle_seat = hitloc
+WHERE event_type IN ('vehicle_enter', 'vehicle_exit', 'vehicle_crash');
+
+-- Buffer tables copy their destination's columns when created. Dropping a
+-- buffer flushes it first.
+DROP TABLE IF EXISTS mohaa_stats.raw_events_buffer;
+CREATE TABLE mohaa_stats.raw_events_buffer AS mohaa_stats.raw_events
+ENGINE = Buffer(mohaa_stats, raw_events, 4, 10, 60, 10000, 500000, 10000000, 100000000);
@@ -0,0 +1,14 @@
+-- Migration: Lifetime of each spawn
+-- The worker pairs every player_spawn with the player's death and stores on
+-- the death event how long the victim lived and how long it took them to
+-- first fire, hit, get hit or kill. Deaths whose spawn wasn't seen, and all
+-- deaths from before this migration, keep 0.
+
+ALTER TABLE mohaa_stats.raw_events ADD COLUMN IF NOT EXISTS target_lifetime Float32 DEFAULT 0 CODEC(Gorilla, ZSTD(1));
+ALTER TABLE mohaa_stats.raw_events ADD COLUMN IF NOT EXISTS target_engage_delay Float32 DEFAULT 0 CODEC(Gorilla, ZSTD(1));
+
+-- Buffer tables copy their destination's columns when created. Dropping a
+-- buffer flushes it first.
+DROP TABLE IF EXISTS mohaa_stats.raw_events_buffer;
+CREATE TABLE mohaa_stats.raw_events_buffer AS mohaa_stats.raw_events
+ENGINE = Buffer(mohaa_stats, raw_events, 4, 10, 60, 10000, 500000, 10000000, 100000000);
@@ -0,0 +1,31 @@
+-- Migration: Player ping samples
+-- Heartbeats carry each connected player's ping as a "pings" object of GUID
+-- to milliseconds. The view below unfolds it into one row per player and
+-- heartbeat, kept for 90 days, for server latency distributions and players'
+-- ping history.
+
+CREATE TABLE IF NOT EXISTS mohaa_stats.player_pings
+(
+    timestamp DateTime64(3) CODEC(DoubleDelta, ZSTD(1)),
+    tenant_id LowCardinality(String) DEFAULT '',
+    server_id String CODEC(ZSTD(1)),
+    match_id UUID,
+    map_name LowCardinality(String),
+    player_id String CODEC(ZSTD(1)),
+    ping UInt16,
+
+    INDEX idx_player player_id TYPE bloom_filter(0.01) GRANULARITY 4
+)
+ENGINE = MergeTree()
+PARTITION BY toYYYYMM(timestamp)
+ORDER BY (server_id, timestamp, player_id)
+TTL toDate(timestamp) + INTERVAL 90 DAY;
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS mohaa_stats.mv_feed_player_pings TO mohaa_stats.player_pings
+AS SELECT
+    timestamp, tenant_id, server_id, match_id, map_name,
+    sample.1 AS player_id,
+    toUInt16(least(greatest(sample.2, 0), 65535)) AS ping
+FROM mohaa_stats.raw_events
+ARRAY JOIN JSONExtractKeysAndValues(raw_json, 'pings', 'Int64') AS sample
+WHERE event_type = 'heartbeat' AND sample.1 != '';
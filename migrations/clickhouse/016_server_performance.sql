@@ -0,0 +1,33 @@
+-- Migration: Server performance samples
+-- Heartbeats may carry the server's frame rate (sv_fps), how long its recent
+-- frames took (frame_time, ms) and CPU usage. One row per heartbeat that
+-- reports any of them, kept for 90 days, so hosts can line lag complaints up
+-- with server health.
+
+CREATE TABLE IF NOT EXISTS mohaa_stats.server_performance
+(
+    timestamp DateTime64(3) CODEC(DoubleDelta, ZSTD(1)),
+    tenant_id LowCardinality(String) DEFAULT '',
+    server_id String CODEC(ZSTD(1)),
+    match_id UUID,
+    map_name LowCardinality(String),
+    player_count UInt16,
+    sv_fps Float32 CODEC(Gorilla, ZSTD(1)),
+    frame_time Float32 CODEC(Gorilla, ZSTD(1)),
+    cpu_usage Float32 CODEC(Gorilla, ZSTD(1))
+)
+ENGINE = MergeTree()
+PARTITION BY toYYYYMM(timestamp)
+ORDER BY (server_id, timestamp)
+TTL toDate(timestamp) + INTERVAL 90 DAY;
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS mohaa_stats.mv_feed_server_performance TO mohaa_stats.server_performance
+AS SELECT
+    timestamp, tenant_id, server_id, match_id, map_name,
+    toUInt16(least(greatest(JSONExtractInt(raw_json, 'player_count'), JSONExtractInt(raw_json, 'players'), 0), 65535)) AS player_count,
+    toFloat32(JSONExtractFloat(raw_json, 'sv_fps')) AS sv_fps,
+    toFloat32(JSONExtractFloat(raw_json, 'frame_time')) AS frame_time,
+    toFloat32(JSONExtractFloat(raw_json, 'cpu_usage')) AS cpu_usage
+FROM mohaa_stats.raw_events
+WHERE event_type = 'heartbeat'
+  AND (JSONExtractFloat(raw_json, 'sv_fps') > 0 OR JSONExtractFloat(raw_json, 'frame_time') > 0 OR JSONExtractFloat(raw_json, 'cpu_usage') > 0);
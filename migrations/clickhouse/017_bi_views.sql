@@ -0,0 +1,65 @@
+-- Migration: Analytical views for BI tools
+-- Stable, read-only views for Grafana, Metabase and similar tools. They keep
+-- their column names and meanings when the tables underneath change, so
+-- dashboards should query these rather than raw_events or the aggregate
+-- tables. GET /api/v1/admin/views lists them with column descriptions.
+-- Columns are only ever added, a breaking change gets a new view name.
+
+-- One row per player and day, in-round events only (see 011_round_phase)
+CREATE OR REPLACE VIEW mohaa_stats.v_player_daily AS
+SELECT
+    toDate(day) AS day,
+    player_id,
+    anyLast(player_name) AS player_name,
+    sum(kills) AS kills,
+    sum(deaths) AS deaths,
+    sum(headshots) AS headshots,
+    sum(bot_kills) AS bot_kills,
+    sum(teamkills) AS teamkills,
+    sum(suicides) AS suicides,
+    sum(shots_fired) AS shots_fired,
+    sum(shots_hit) AS shots_hit,
+    sum(total_damage) AS damage,
+    uniqExactMerge(matches_played) AS matches_played,
+    sum(matches_won) AS matches_won,
+    round(kills / greatest(deaths, 1), 2) AS kd_ratio,
+    round(100 * shots_hit / greatest(shots_fired, 1), 1) AS accuracy_pct,
+    max(last_active) AS last_active
+FROM mohaa_stats.player_stats_daily
+WHERE player_id != '' AND player_id != 'world'
+GROUP BY day, player_id;
+
+-- One row per match, computed from raw_events on read. Filter on started_at
+-- or server_id in dashboards, as an unfiltered scan reads every stored event.
+CREATE OR REPLACE VIEW mohaa_stats.v_match_summary AS
+SELECT
+    match_id,
+    any(tenant_id) AS tenant_id,
+    any(server_id) AS server_id,
+    anyLast(map_name) AS map_name,
+    min(timestamp) AS started_at,
+    max(timestamp) AS ended_at,
+    dateDiff('second', min(timestamp), max(timestamp)) AS duration_seconds,
+    uniqExactIf(actor_id, actor_id != '' AND actor_id != 'world') AS players,
+    sumIf(sample_weight, event_type IN ('player_kill', 'bot_killed')) AS kills,
+    sumIf(sample_weight, event_type IN ('player_kill', 'bot_killed') AND hitloc IN ('head', 'helmet')) AS headshots,
+    max(round_number) AS rounds,
+    countIf(event_type = 'match_end') > 0 AS finished,
+    match_id IN (SELECT match_id FROM mohaa_stats.voided_matches FINAL WHERE voided = 1) AS voided
+FROM mohaa_stats.raw_events
+WHERE event_type != 'heartbeat'
+GROUP BY match_id;
+
+-- One row per weapon and day
+CREATE OR REPLACE VIEW mohaa_stats.v_weapon_daily AS
+SELECT
+    toDate(day) AS day,
+    actor_weapon AS weapon,
+    sum(kills) AS kills,
+    sum(headshots) AS headshots,
+    sum(shots_fired) AS shots_fired,
+    sum(shots_hit) AS shots_hit,
+    uniqExact(actor_id) AS players,
+    round(100 * shots_hit / greatest(shots_fired, 1), 1) AS accuracy_pct
+FROM mohaa_stats.weapon_stats_mv
+GROUP BY day, weapon;
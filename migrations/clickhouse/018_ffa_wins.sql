@@ -0,0 +1,64 @@
+-- Migration: Free-for-all wins
+-- match_outcome events store the match's gametype in actor_weapon. The
+-- worker synthesizes outcomes for free-for-all matches (gametype 'dm' or
+-- 'ffa') from the final scoreboard, where the top scorer wins. ffa_wins
+-- counts those wins apart from matches_won so the ffa_wins and team_wins
+-- leaderboards rank different things. Like matches_won, only in-round events
+-- are counted.
+
+-- Step 1: Columns
+ALTER TABLE mohaa_stats.player_stats_daily ADD COLUMN IF NOT EXISTS ffa_wins UInt64 DEFAULT 0 AFTER matches_won;
+ALTER TABLE mohaa_stats.player_server_stats_daily ADD COLUMN IF NOT EXISTS ffa_wins UInt64 DEFAULT 0 AFTER matches_won;
+
+-- Step 2: Views. The other player stats views leave ffa_wins at its default
+-- of 0, which the SummingMergeTree adds up with these rows.
+CREATE MATERIALIZED VIEW IF NOT EXISTS mohaa_stats.mv_feed_ffa_wins TO mohaa_stats.player_stats_daily
+AS SELECT
+    toStartOfDay(timestamp) AS day,
+    actor_id AS player_id,
+    argMax(actor_name, if(actor_name != '', toUnixTimestamp64Nano(timestamp), 0)) AS player_name,
+    sum(sample_weight) AS ffa_wins,
+    max(timestamp) AS last_active
+FROM mohaa_stats.raw_events
+WHERE event_type = 'match_outcome' AND match_outcome = 1 AND lower(actor_weapon) IN ('dm', 'ffa')
+  AND actor_id != '' AND actor_id != 'world' AND round_phase = 'round'
+GROUP BY day, actor_id;
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS mohaa_stats.mv_feed_ffa_server_wins TO mohaa_stats.player_server_stats_daily
+AS SELECT
+    toStartOfDay(timestamp) AS day,
+    server_id,
+    actor_id AS player_id,
+    argMax(actor_name, if(actor_name != '', toUnixTimestamp64Nano(timestamp), 0)) AS player_name,
+    sum(sample_weight) AS ffa_wins,
+    max(timestamp) AS last_active
+FROM mohaa_stats.raw_events
+WHERE event_type = 'match_outcome' AND match_outcome = 1 AND lower(actor_weapon) IN ('dm', 'ffa')
+  AND actor_id != '' AND actor_id != 'world' AND server_id != '' AND round_phase = 'round'
+GROUP BY day, server_id, actor_id;
+
+-- Step 3: Backfill from existing events
+INSERT INTO mohaa_stats.player_stats_daily (day, player_id, player_name, ffa_wins, last_active)
+SELECT
+    toStartOfDay(timestamp) AS day,
+    actor_id AS player_id,
+    argMax(actor_name, if(actor_name != '', toUnixTimestamp64Nano(timestamp), 0)) AS player_name,
+    sum(sample_weight) AS ffa_wins,
+    max(timestamp) AS last_active
+FROM mohaa_stats.raw_events
+WHERE event_type = 'match_outcome' AND match_outcome = 1 AND lower(actor_weapon) IN ('dm', 'ffa')
+  AND actor_id != '' AND actor_id != 'world' AND round_phase = 'round'
+GROUP BY day, actor_id;
+
+INSERT INTO mohaa_stats.player_server_stats_daily (day, server_id, player_id, player_name, ffa_wins, last_active)
+SELECT
+    toStartOfDay(timestamp) AS day,
+    server_id,
+    actor_id AS player_id,
+    argMax(actor_name, if(actor_name != '', toUnixTimestamp64Nano(timestamp), 0)) AS player_name,
+    sum(sample_weight) AS ffa_wins,
+    max(timestamp) AS last_active
+FROM mohaa_stats.raw_events
+WHERE event_type = 'match_outcome' AND match_outcome = 1 AND lower(actor_weapon) IN ('dm', 'ffa')
+  AND actor_id != '' AND actor_id != 'world' AND server_id != '' AND round_phase = 'round'
+GROUP BY day, server_id, actor_id;
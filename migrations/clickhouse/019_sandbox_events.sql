@@ -0,0 +1,13 @@
+-- Migration: Sandbox events
+-- Events from sandbox servers (servers.sandbox in Postgres) land here instead
+-- of raw_events. No materialized view reads this table, so they stay out of
+-- every aggregate and leaderboard. Kept for 30 days.
+
+CREATE TABLE IF NOT EXISTS mohaa_stats.raw_events_sandbox AS mohaa_stats.raw_events
+ENGINE = MergeTree()
+PARTITION BY toYYYYMMDD(timestamp)
+ORDER BY (server_id, timestamp)
+TTL toDate(timestamp) + INTERVAL 30 DAY;
+
+CREATE TABLE IF NOT EXISTS mohaa_stats.raw_events_sandbox_buffer AS mohaa_stats.raw_events_sandbox
+ENGINE = Buffer(mohaa_stats, raw_events_sandbox, 1, 10, 60, 10000, 500000, 10000000, 100000000);
@@ -0,0 +1,42 @@
+-- Migration: Binned position cells
+-- Map and player heatmaps only need how often something happened in each
+-- 50-unit grid cell, not where exactly. position_cells keeps those counts per
+-- day and player, so the precise positions of old events can be stripped
+-- (POSITION_RETENTION_DAYS) without the heatmaps losing their history.
+
+CREATE TABLE IF NOT EXISTS mohaa_stats.position_cells
+(
+    day Date,
+    map_name LowCardinality(String),
+    event_type LowCardinality(String),
+    role LowCardinality(String),
+    player_id String CODEC(ZSTD(1)),
+    cell_x Int32,
+    cell_y Int32,
+    samples UInt64,
+
+    INDEX idx_player player_id TYPE bloom_filter(0.01) GRANULARITY 4
+)
+ENGINE = SummingMergeTree(samples)
+PARTITION BY toYYYYMM(day)
+ORDER BY (map_name, event_type, role, cell_x, cell_y, player_id, day)
+TTL day + INTERVAL 2 YEAR;
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS mohaa_stats.mv_feed_position_cells TO mohaa_stats.position_cells
+AS SELECT
+    toDate(timestamp) AS day,
+    map_name, event_type, role, player_id,
+    toInt32(round(pos_x / 50)) AS cell_x,
+    toInt32(round(pos_y / 50)) AS cell_y,
+    sum(sample_weight) AS samples
+FROM mohaa_stats.positions
+GROUP BY day, map_name, event_type, role, player_id, cell_x, cell_y;
+
+-- Backfill from the positions already stored
+INSERT INTO mohaa_stats.position_cells (day, map_name, event_type, role, player_id, cell_x, cell_y, samples)
+SELECT
+    toDate(timestamp) AS day,
+    map_name, event_type, role, player_id, cell_x, cell_y,
+    sum(sample_weight)
+FROM mohaa_stats.positions
+GROUP BY day, map_name, event_type, role, player_id, cell_x, cell_y;
@@ -0,0 +1,32 @@
+-- Migration: Match spectator samples
+-- Servers report who watches a match either as a spectator_count on each
+-- heartbeat or as spectator_join / spectator_leave events. Both land here:
+-- heartbeat rows carry the count (delta 0), join and leave rows the
+-- spectator's GUID and +1 or -1. Viewership timelines use the counts when a
+-- match has any and replay the joins and leaves otherwise. Kept for 180 days.
+
+CREATE TABLE IF NOT EXISTS mohaa_stats.match_spectators
+(
+    timestamp DateTime64(3) CODEC(DoubleDelta, ZSTD(1)),
+    tenant_id LowCardinality(String) DEFAULT '',
+    server_id String CODEC(ZSTD(1)),
+    match_id UUID,
+    map_name LowCardinality(String),
+    spectator_id String CODEC(ZSTD(1)),
+    spectators UInt16,
+    delta Int8
+)
+ENGINE = MergeTree()
+PARTITION BY toYYYYMM(timestamp)
+ORDER BY (match_id, timestamp)
+TTL toDate(timestamp) + INTERVAL 180 DAY;
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS mohaa_stats.mv_feed_match_spectators TO mohaa_stats.match_spectators
+AS SELECT
+    timestamp, tenant_id, server_id, match_id, map_name,
+    if(event_type = 'heartbeat', '', actor_id) AS spectator_id,
+    toUInt16(least(greatest(JSONExtractInt(raw_json, 'spectator_count'), 0), 65535)) AS spectators,
+    toInt8(multiIf(event_type = 'spectator_join', 1, event_type = 'spectator_leave', -1, 0)) AS delta
+FROM mohaa_stats.raw_events
+WHERE (event_type = 'heartbeat' AND JSONHas(raw_json, 'spectator_count'))
+   OR (event_type IN ('spectator_join', 'spectator_leave') AND actor_id != '');
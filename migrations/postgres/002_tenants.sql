@@ -0,0 +1,18 @@
+-- ============================================================================
+-- TENANTS
+-- ============================================================================
+-- One API instance can host several isolated communities. Each tenant owns its
+-- servers; reads made with a tenant API key only see those servers' data.
+-- Servers without a tenant belong to the instance's default community.
+
+CREATE TABLE IF NOT EXISTS tenants (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    slug VARCHAR(64) UNIQUE NOT NULL,
+    name VARCHAR(128) NOT NULL,
+    api_key VARCHAR(256) UNIQUE NOT NULL, -- SHA256 of the tenant API key
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE servers ADD COLUMN IF NOT EXISTS tenant_id UUID REFERENCES tenants(id) ON DELETE RESTRICT;
+CREATE INDEX IF NOT EXISTS idx_servers_tenant ON servers(tenant_id);
@@ -0,0 +1,36 @@
+-- ============================================================================
+-- MATCH LIFECYCLE
+-- ============================================================================
+-- One row per match, moved through pending -> live -> ended by game events and
+-- to finalized / voided by admins (see models.MatchState for the allowed
+-- transitions). Match stats themselves stay in ClickHouse.
+
+CREATE TABLE IF NOT EXISTS match_states (
+    match_id VARCHAR(64) PRIMARY KEY,
+    server_id VARCHAR(64) NOT NULL DEFAULT '',
+    tournament_id VARCHAR(64) NOT NULL DEFAULT '',
+    state VARCHAR(16) NOT NULL DEFAULT 'pending'
+        CHECK (state IN ('pending', 'live', 'ended', 'finalized', 'voided')),
+    reason TEXT NOT NULL DEFAULT '',
+    updated_by VARCHAR(64) NOT NULL DEFAULT '',
+    started_at TIMESTAMPTZ,
+    ended_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_match_states_state ON match_states(state);
+CREATE INDEX IF NOT EXISTS idx_match_states_tournament ON match_states(tournament_id) WHERE tournament_id != '';
+
+-- Admin transitions, for auditing
+CREATE TABLE IF NOT EXISTS match_state_history (
+    id BIGSERIAL PRIMARY KEY,
+    match_id VARCHAR(64) NOT NULL REFERENCES match_states(match_id) ON DELETE CASCADE,
+    from_state VARCHAR(16) NOT NULL,
+    to_state VARCHAR(16) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    changed_by VARCHAR(64) NOT NULL DEFAULT '',
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_match_state_history_match ON match_state_history(match_id, changed_at);
@@ -0,0 +1,8 @@
+-- ============================================================================
+-- ACHIEVEMENT UNLOCK MATCH
+-- ============================================================================
+-- The match an achievement was unlocked in, so voiding or recomputing a match
+-- can revoke what it granted. NULL for unlocks recorded before this column.
+
+ALTER TABLE mohaa_player_achievements ADD COLUMN IF NOT EXISTS match_id VARCHAR(64);
+CREATE INDEX IF NOT EXISTS idx_player_achievements_match ON mohaa_player_achievements(match_id) WHERE match_id IS NOT NULL;
@@ -0,0 +1,26 @@
+-- ============================================================================
+-- PLAYER OF THE DAY / WEEK
+-- ============================================================================
+-- One winner per period, server and tenant, computed by the highlights
+-- scheduler once the period is over (see logic.HighlightScore). server_id ''
+-- is the network-wide pick across the tenant's servers. Rows are kept as the
+-- history behind /stats/highlights/potd.
+
+CREATE TABLE IF NOT EXISTS player_highlights (
+    period VARCHAR(8) NOT NULL CHECK (period IN ('day', 'week')),
+    period_start DATE NOT NULL,
+    tenant_id VARCHAR(64) NOT NULL DEFAULT '',
+    server_id VARCHAR(64) NOT NULL DEFAULT '',
+    player_guid VARCHAR(64) NOT NULL,
+    player_name TEXT NOT NULL DEFAULT '',
+    score DOUBLE PRECISION NOT NULL,
+    kills BIGINT NOT NULL DEFAULT 0,
+    deaths BIGINT NOT NULL DEFAULT 0,
+    headshots BIGINT NOT NULL DEFAULT 0,
+    matches_played BIGINT NOT NULL DEFAULT 0,
+    matches_won BIGINT NOT NULL DEFAULT 0,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (period, period_start, tenant_id, server_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_player_highlights_player ON player_highlights(player_guid);
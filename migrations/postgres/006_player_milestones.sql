@@ -0,0 +1,21 @@
+-- ============================================================================
+-- PERSONAL MILESTONES
+-- ============================================================================
+-- Career moments detected by the worker from live counters: the Nth kill, the
+-- Nth win, the first headshot with each weapon. Counters expire with
+-- REDIS_PLAYER_COUNTER_TTL, so a milestone can be detected twice; the unique
+-- key keeps the first. Listed on /stats/player/{guid}/timeline.
+
+CREATE TABLE IF NOT EXISTS player_milestones (
+    id BIGSERIAL PRIMARY KEY,
+    player_guid VARCHAR(64) NOT NULL,
+    milestone VARCHAR(100) NOT NULL, -- kills:10000, wins:100, first_headshot:kar98
+    title TEXT NOT NULL,
+    value BIGINT NOT NULL DEFAULT 0,
+    match_id VARCHAR(64) NOT NULL DEFAULT '',
+    server_id VARCHAR(64) NOT NULL DEFAULT '',
+    achieved_at TIMESTAMPTZ NOT NULL,
+    UNIQUE (player_guid, milestone)
+);
+
+CREATE INDEX IF NOT EXISTS idx_player_milestones_player ON player_milestones(player_guid, achieved_at DESC);
@@ -0,0 +1,41 @@
+-- ============================================================================
+-- TITLES AND BADGES
+-- ============================================================================
+-- Cosmetic flair shown next to a player's name on profiles and leaderboards,
+-- separate from achievements. A definition with a rule (a career stat and its
+-- minimum) is granted by the hourly rule sweep; one without is admin-granted.
+-- Players equip one title and up to three badges.
+
+CREATE TABLE IF NOT EXISTS titles (
+    code VARCHAR(64) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('title', 'badge')),
+    rule_stat VARCHAR(32),  -- kills, headshots, wins, ... (see logic.TitleRuleStats)
+    rule_min BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS player_titles (
+    player_guid VARCHAR(64) NOT NULL,
+    title_code VARCHAR(64) NOT NULL REFERENCES titles(code) ON DELETE CASCADE,
+    source VARCHAR(10) NOT NULL CHECK (source IN ('rule', 'admin')),
+    granted_by VARCHAR(64) NOT NULL DEFAULT '',
+    granted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    equipped BOOLEAN NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (player_guid, title_code)
+);
+
+CREATE INDEX IF NOT EXISTS idx_player_titles_equipped ON player_titles(player_guid) WHERE equipped;
+
+INSERT INTO titles (code, name, description, kind, rule_stat, rule_min) VALUES
+    ('veteran', 'Veteran', '1,000 career kills', 'title', 'kills', 1000),
+    ('warlord', 'Warlord', '10,000 career kills', 'title', 'kills', 10000),
+    ('marksman', 'Marksman', '500 career headshots', 'title', 'headshots', 500),
+    ('victor', 'Victor', '100 matches won', 'title', 'wins', 100),
+    ('bayonet', 'Bayonet', '50 bash kills', 'badge', 'bash_kills', 50),
+    ('grenadier', 'Grenadier', '250 grenade kills', 'badge', 'grenade_kills', 250),
+    ('road_rage', 'Road Rage', '25 roadkills', 'badge', 'roadkills', 25),
+    ('marathon', 'Marathon', '500 matches played', 'badge', 'matches', 500),
+    ('founder', 'Founder', 'Granted by the admins', 'badge', NULL, 0)
+ON CONFLICT (code) DO NOTHING;
@@ -0,0 +1,46 @@
+-- ============================================================================
+-- WEEKLY CHALLENGES
+-- ============================================================================
+-- challenges is the pool of objectives ("200 SMG kills on DM maps"). Each
+-- Monday (UTC) CHALLENGES_PER_WEEK enabled ones are rotated in, preferring
+-- ones that did not run the week before. Progress is counted per player in
+-- Redis by the worker; completions are kept here.
+
+CREATE TABLE IF NOT EXISTS challenges (
+    code VARCHAR(64) PRIMARY KEY,
+    title VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    metric VARCHAR(32) NOT NULL,              -- kills, headshots, bash_kills, roadkills, wins, matches
+    weapons TEXT[] NOT NULL DEFAULT '{}',     -- weapon name fragments; any weapon when empty
+    map_prefix VARCHAR(32) NOT NULL DEFAULT '', -- dm, obj, lib...; any map when empty
+    target BIGINT NOT NULL CHECK (target > 0),
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS weekly_challenges (
+    week_start DATE NOT NULL,
+    code VARCHAR(64) NOT NULL REFERENCES challenges(code) ON DELETE CASCADE,
+    PRIMARY KEY (week_start, code)
+);
+
+CREATE TABLE IF NOT EXISTS challenge_completions (
+    week_start DATE NOT NULL,
+    code VARCHAR(64) NOT NULL,
+    player_guid VARCHAR(64) NOT NULL,
+    player_name VARCHAR(100) NOT NULL DEFAULT '',
+    completed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (week_start, code, player_guid)
+);
+
+CREATE INDEX IF NOT EXISTS idx_challenge_completions_player ON challenge_completions(player_guid, week_start DESC);
+
+INSERT INTO challenges (code, title, description, metric, weapons, map_prefix, target) VALUES
+    ('smg_dm', 'Spray and Pray', '200 SMG kills on DM maps', 'kills', '{thompson,mp40,sten,ppsh}', 'dm', 200),
+    ('rifle_headshots', 'Steady Aim', '100 rifle headshots', 'headshots', '{kar98,m1 garand,springfield,mosin,enfield,svt}', '', 100),
+    ('obj_wins', 'Mission Accomplished', 'Win 10 objective matches', 'wins', '{}', 'obj', 10),
+    ('nades', 'Fire in the Hole', '50 grenade kills', 'kills', '{grenade}', '', 50),
+    ('bayonet', 'Cold Steel', '25 bash kills', 'bash_kills', '{}', '', 25),
+    ('regular', 'Regular', 'Finish 20 matches', 'matches', '{}', '', 20),
+    ('sniper', 'Overwatch', '150 sniper rifle kills', 'kills', '{sniper}', '', 150)
+ON CONFLICT (code) DO NOTHING;
@@ -0,0 +1,46 @@
+-- ============================================================================
+-- QUEST CHAINS
+-- ============================================================================
+-- Ordered objectives for new players, counted by the challenge engine. A GUID
+-- is enrolled on its first connect when ClickHouse has no earlier stats for
+-- it, and has 14 days to finish the chain. Steps are counted side by side;
+-- the chain shows the first unfinished one as current.
+
+CREATE TABLE IF NOT EXISTS quest_steps (
+    chain VARCHAR(32) NOT NULL,
+    position SMALLINT NOT NULL,
+    code VARCHAR(64) NOT NULL,
+    title VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    metric VARCHAR(32) NOT NULL,              -- as challenges.metric, plus weapons (distinct weapons killed with)
+    weapons TEXT[] NOT NULL DEFAULT '{}',
+    map_prefix VARCHAR(32) NOT NULL DEFAULT '',
+    target BIGINT NOT NULL CHECK (target > 0),
+    PRIMARY KEY (chain, code),
+    UNIQUE (chain, position)
+);
+
+CREATE TABLE IF NOT EXISTS quest_enrollments (
+    player_guid VARCHAR(64) NOT NULL,
+    chain VARCHAR(32) NOT NULL,
+    enrolled_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ,
+    PRIMARY KEY (player_guid, chain)
+);
+
+CREATE INDEX IF NOT EXISTS idx_quest_enrollments_active ON quest_enrollments(chain, enrolled_at) WHERE completed_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS quest_step_completions (
+    player_guid VARCHAR(64) NOT NULL,
+    chain VARCHAR(32) NOT NULL,
+    code VARCHAR(64) NOT NULL,
+    completed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (player_guid, chain, code)
+);
+
+INSERT INTO quest_steps (chain, position, code, title, description, metric, target) VALUES
+    ('onboarding', 1, 'play_matches', 'Boots on the Ground', 'Play 3 matches', 'matches', 3),
+    ('onboarding', 2, 'first_kills', 'First Blood', 'Get 10 kills', 'kills', 10),
+    ('onboarding', 3, 'try_weapons', 'Armory Tour', 'Get kills with 3 different weapons', 'weapons', 3),
+    ('onboarding', 4, 'win_round', 'Taste of Victory', 'Win a round', 'wins', 1)
+ON CONFLICT (chain, code) DO NOTHING;
@@ -0,0 +1,29 @@
+-- ============================================================================
+-- SERVER SELF-REGISTRATION
+-- ============================================================================
+-- A community admin issues an invite code; a new host registers its server
+-- with the code and gets a token straight away, but the server stays pending
+-- (inactive, so the token does not authenticate ingest) until an admin
+-- approves it. Codes are stored as SHA256 hashes like server tokens.
+
+CREATE TABLE IF NOT EXISTS server_invites (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    code_hash VARCHAR(64) UNIQUE NOT NULL,
+    tenant_id UUID REFERENCES tenants(id) ON DELETE CASCADE, -- registered servers join this tenant
+    note VARCHAR(255) NOT NULL DEFAULT '',
+    max_uses INTEGER NOT NULL DEFAULT 1 CHECK (max_uses > 0),
+    uses INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_by VARCHAR(64) NOT NULL DEFAULT '', -- server ID of the admin that issued it
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Servers inserted before self-registration are approved
+ALTER TABLE servers ADD COLUMN IF NOT EXISTS approval_status VARCHAR(16) NOT NULL DEFAULT 'approved'
+    CHECK (approval_status IN ('pending', 'approved', 'rejected'));
+ALTER TABLE servers ADD COLUMN IF NOT EXISTS invite_id UUID REFERENCES server_invites(id) ON DELETE SET NULL;
+ALTER TABLE servers ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMPTZ;
+ALTER TABLE servers ADD COLUMN IF NOT EXISTS reviewed_by VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_servers_pending ON servers(created_at) WHERE approval_status = 'pending';
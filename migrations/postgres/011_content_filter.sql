@@ -0,0 +1,14 @@
+-- ============================================================================
+-- CONTENT FILTER OVERRIDES
+-- ============================================================================
+-- Admin decisions that beat the configured name filter for one player:
+-- 'allow' serves the name as is (false positives, staff using a protected
+-- name), 'block' always treats it as offensive.
+
+CREATE TABLE IF NOT EXISTS content_filter_overrides (
+    player_guid VARCHAR(64) PRIMARY KEY,
+    action VARCHAR(8) NOT NULL CHECK (action IN ('allow', 'block')),
+    note VARCHAR(255) NOT NULL DEFAULT '',
+    created_by VARCHAR(64) NOT NULL DEFAULT '', -- server ID of the admin that set it
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,20 @@
+-- ============================================================================
+-- LEADERBOARD SNAPSHOTS
+-- ============================================================================
+-- The top of the all-time leaderboards of a few stats as they stood at the
+-- end of each UTC day, per tenant ('' = network-wide), taken by the snapshot
+-- scheduler. /stats/leaderboard/{stat}/changes diffs two of them into rank
+-- movements. Rows older than logic.LeaderboardSnapshotRetention are pruned.
+
+CREATE TABLE IF NOT EXISTS leaderboard_snapshots (
+    stat VARCHAR(32) NOT NULL,
+    taken_on DATE NOT NULL,
+    tenant_id VARCHAR(64) NOT NULL DEFAULT '',
+    player_guid VARCHAR(64) NOT NULL,
+    player_name TEXT NOT NULL DEFAULT '',
+    rank INTEGER NOT NULL,
+    value DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (stat, tenant_id, taken_on, player_guid)
+);
+
+CREATE INDEX IF NOT EXISTS idx_leaderboard_snapshots_taken_on ON leaderboard_snapshots(taken_on);
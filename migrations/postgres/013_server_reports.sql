@@ -0,0 +1,18 @@
+-- ============================================================================
+-- WEEKLY SERVER REPORTS
+-- ============================================================================
+-- Where a server's owner wants the weekly report (top players, busiest
+-- times, maps, notable matches) delivered: a webhook, an email address or
+-- both. Set with the server token under /api/v1/integrations/report. The
+-- report scheduler sends each ISO week (Monday to Sunday, UTC) once and
+-- records it in last_sent_week.
+
+CREATE TABLE IF NOT EXISTS server_report_settings (
+    server_id UUID PRIMARY KEY REFERENCES servers(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    webhook_url TEXT NOT NULL DEFAULT '',
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    last_sent_week DATE,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (webhook_url <> '' OR email <> '')
+);
@@ -0,0 +1,12 @@
+-- ============================================================================
+-- SANDBOX SERVERS
+-- ============================================================================
+-- A sandbox server's token ingests events like any other, but they are
+-- stored in ClickHouse's raw_events_sandbox instead of raw_events: they never
+-- reach the aggregates, leaderboards, live state or achievements, and can be
+-- read back with GET /api/v1/admin/events/search. The token is refused on
+-- every other authenticated route (admin, system, integrations). Mod developers
+-- get one to test scripts against the live API. Toggle with
+-- `statsctl token -server <name> -sandbox on|off`.
+
+ALTER TABLE servers ADD COLUMN IF NOT EXISTS sandbox BOOLEAN NOT NULL DEFAULT false;
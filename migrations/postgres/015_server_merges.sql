@@ -0,0 +1,20 @@
+-- ============================================================================
+-- SERVER MERGES
+-- ============================================================================
+-- Audit trail of POST /api/v1/admin/servers/merge: a community moved a server
+-- to a new box or ID, and its events, aggregates and match records were
+-- re-attributed from from_server_id to to_server_id. tables holds the rows
+-- moved per table (models.ServerMergeTable). Dry runs are not recorded.
+
+CREATE TABLE IF NOT EXISTS server_merges (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    from_server_id VARCHAR(64) NOT NULL,
+    to_server_id VARCHAR(64) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    requested_by VARCHAR(64) NOT NULL DEFAULT '', -- server ID of the admin token
+    tables JSONB NOT NULL DEFAULT '[]',
+    rows_moved BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_server_merges_created ON server_merges(created_at DESC);
@@ -0,0 +1,31 @@
+-- ============================================================================
+-- PREDICTIONS
+-- ============================================================================
+-- Forecasts served by /api/v1/stats/player/{guid}/predictions, one row per
+-- scoring model: the primary model whose forecast was served and the shadow
+-- models scored alongside it (PREDICTION_MODEL, PREDICTION_SHADOW_MODELS).
+-- A player has at most one pending forecast per model; asking again before
+-- they play refreshes it. The prediction resolver fills in the actual kills
+-- and deaths of the first match the player finishes afterwards, and
+-- /api/v1/stats/predict/accuracy aggregates the resolved rows.
+
+CREATE TABLE IF NOT EXISTS predictions (
+    id BIGSERIAL PRIMARY KEY,
+    model VARCHAR(32) NOT NULL,
+    shadow BOOLEAN NOT NULL DEFAULT false,
+    player_guid VARCHAR(64) NOT NULL,
+    predicted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    predicted_kills INTEGER NOT NULL,
+    predicted_deaths INTEGER NOT NULL,
+    confidence DOUBLE PRECISION NOT NULL,
+    requests INTEGER NOT NULL DEFAULT 1,
+    match_id VARCHAR(64),
+    actual_kills INTEGER,
+    actual_deaths INTEGER,
+    hit BOOLEAN,
+    resolved_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_predictions_pending
+    ON predictions(model, player_guid) WHERE resolved_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_predictions_predicted_at ON predictions(predicted_at);
@@ -0,0 +1,22 @@
+-- ============================================================================
+-- PLAYER RATINGS
+-- ============================================================================
+-- An Elo rating per player, updated by the worker pool with every player kill
+-- between two players (bots, suicides and team kills are left out), and the
+-- opponent-adjusted kills and deaths kept alongside it: each kill weighs the
+-- victim's rating over the starting rating, each death the starting rating
+-- over the killer's, both as they stood at the time. Their ratio is the
+-- adjusted K/D of profiles and /api/v1/stats/leaderboard/adjusted-kd.
+
+CREATE TABLE IF NOT EXISTS player_ratings (
+    player_guid VARCHAR(64) PRIMARY KEY,
+    player_name TEXT NOT NULL DEFAULT '',
+    rating DOUBLE PRECISION NOT NULL DEFAULT 1500,
+    kills BIGINT NOT NULL DEFAULT 0,
+    deaths BIGINT NOT NULL DEFAULT 0,
+    adjusted_kills DOUBLE PRECISION NOT NULL DEFAULT 0,
+    adjusted_deaths DOUBLE PRECISION NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_player_ratings_engagements ON player_ratings((kills + deaths));
@@ -0,0 +1,22 @@
+-- ============================================================================
+-- JOB RUNS
+-- ============================================================================
+-- Run history of the internal job scheduler (internal/jobs), one row per run
+-- of a periodic job on whichever API instance took its lock, scheduled or
+-- started from POST /api/v1/admin/jobs/{name}/run. Runs stay "running" until
+-- they finish; ones whose instance died are marked failed by the daily
+-- job_runs_prune job, which also drops runs older than 30 days.
+
+CREATE TABLE IF NOT EXISTS job_runs (
+    id BIGSERIAL PRIMARY KEY,
+    job VARCHAR(64) NOT NULL,
+    trigger VARCHAR(16) NOT NULL DEFAULT 'schedule', -- schedule, manual
+    instance VARCHAR(128) NOT NULL DEFAULT '',
+    status VARCHAR(16) NOT NULL DEFAULT 'running', -- running, succeeded, failed
+    error TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_runs_job_started ON job_runs(job, started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_job_runs_running ON job_runs(started_at) WHERE status = 'running';
@@ -0,0 +1,7 @@
+-- ============================================================================
+-- PLAYER RATING RANK
+-- ============================================================================
+-- A player's rank is the count of players rated above them, as the launcher
+-- home screen (/api/v1/integrations/launcher/home/{guid}) shows it.
+
+CREATE INDEX IF NOT EXISTS idx_player_ratings_rating ON player_ratings(rating);
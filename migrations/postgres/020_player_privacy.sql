@@ -0,0 +1,16 @@
+-- ============================================================================
+-- PLAYER PRIVACY
+-- ============================================================================
+-- Privacy preferences of a forum account, set with PUT
+-- /api/v1/users/me/privacy and applied to every GUID verified for it in
+-- player_identities: hide_profile answers the player's profile routes with
+-- 404, hide_from_leaderboards leaves them out of ranked lists and
+-- anonymize_name shows "Anonymous Soldier" instead of their name.
+
+CREATE TABLE IF NOT EXISTS player_privacy (
+    forum_user_id INTEGER PRIMARY KEY,
+    hide_profile BOOLEAN NOT NULL DEFAULT FALSE,
+    hide_from_leaderboards BOOLEAN NOT NULL DEFAULT FALSE,
+    anonymize_name BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,21 @@
+-- ============================================================================
+-- SIGNED TOURNAMENT MATCH RESULTS
+-- ============================================================================
+-- The result document of a finalized tournament match, signed with the
+-- API's ed25519 key (RESULT_SIGNING_KEY) and served as stored from
+-- /api/v1/tournaments/{id}/matches/{mid}/result. payload holds the exact
+-- signed bytes, so league operators can check the signature against
+-- public_key. A result is only signed again when a correction (migration
+-- 022) changes it.
+
+CREATE TABLE IF NOT EXISTS tournament_match_results (
+    match_id VARCHAR(64) PRIMARY KEY,
+    tournament_id VARCHAR(64) NOT NULL,
+    payload BYTEA NOT NULL,
+    signature BYTEA NOT NULL,
+    key_id VARCHAR(16) NOT NULL,
+    public_key BYTEA NOT NULL,
+    signed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_tournament_match_results_tournament ON tournament_match_results(tournament_id);
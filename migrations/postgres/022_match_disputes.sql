@@ -0,0 +1,52 @@
+-- ============================================================================
+-- MATCH DISPUTES AND CORRECTIONS
+-- ============================================================================
+-- Tournament admins open a dispute on a match, attach evidence notes to it
+-- and close it by rejecting it or by applying a score correction. The latest
+-- correction of a match supersedes its derived result: its match_outcome
+-- events are derived again with the corrected winner (also when the match is
+-- recomputed later) and its signed result is signed again.
+
+CREATE TABLE IF NOT EXISTS match_disputes (
+    id BIGSERIAL PRIMARY KEY,
+    match_id VARCHAR(64) NOT NULL REFERENCES match_states(match_id) ON DELETE CASCADE,
+    tournament_id VARCHAR(64) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'open'
+        CHECK (status IN ('open', 'corrected', 'rejected')),
+    reason TEXT NOT NULL,
+    opened_by VARCHAR(64) NOT NULL DEFAULT '',
+    resolution TEXT NOT NULL DEFAULT '',
+    resolved_by VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_match_disputes_match ON match_disputes(match_id);
+-- One open dispute per match
+CREATE UNIQUE INDEX IF NOT EXISTS idx_match_disputes_open ON match_disputes(match_id) WHERE status = 'open';
+
+CREATE TABLE IF NOT EXISTS match_dispute_notes (
+    id BIGSERIAL PRIMARY KEY,
+    dispute_id BIGINT NOT NULL REFERENCES match_disputes(id) ON DELETE CASCADE,
+    note TEXT NOT NULL,
+    evidence_url TEXT NOT NULL DEFAULT '',
+    added_by VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_match_dispute_notes_dispute ON match_dispute_notes(dispute_id);
+
+-- Every correction is kept; the latest one applies
+CREATE TABLE IF NOT EXISTS match_corrections (
+    id BIGSERIAL PRIMARY KEY,
+    match_id VARCHAR(64) NOT NULL REFERENCES match_states(match_id) ON DELETE CASCADE,
+    dispute_id BIGINT REFERENCES match_disputes(id) ON DELETE SET NULL,
+    allies_score INTEGER NOT NULL,
+    axis_score INTEGER NOT NULL,
+    winner VARCHAR(16) NOT NULL CHECK (winner IN ('allies', 'axis', 'draw')),
+    reason TEXT NOT NULL,
+    corrected_by VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_match_corrections_match ON match_corrections(match_id, id DESC);
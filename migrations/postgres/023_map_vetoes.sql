@@ -0,0 +1,21 @@
+-- ============================================================================
+-- MAP VETOES
+-- ============================================================================
+-- The pick/ban sequence of a scheduled tournament series, recorded by a
+-- tournament admin. series_id is the scheduled match of the bracket (kept
+-- in SMF), not a game's match ID. steps is the ordered JSON array of
+-- {step, team, action, map, match_id, team_a_side}; picks and the decider
+-- link to the game they were played in once recorded again after the
+-- series, and the winner of each is read from tournament_match_results.
+
+CREATE TABLE IF NOT EXISTS map_vetoes (
+    tournament_id VARCHAR(64) NOT NULL,
+    series_id VARCHAR(64) NOT NULL,
+    team_a VARCHAR(64) NOT NULL,
+    team_b VARCHAR(64) NOT NULL,
+    steps JSONB NOT NULL DEFAULT '[]',
+    recorded_by VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tournament_id, series_id)
+);
@@ -0,0 +1,27 @@
+-- ============================================================================
+-- MATCH DEMOS
+-- ============================================================================
+-- Demo recordings of matches kept in community demo archives. The server
+-- that played a match registers each recording with its SHA-256 checksum
+-- and where to download it; registering the same file again updates its
+-- name and URL. map_name and started_at are copied from the match when
+-- registered so matches with demos can be listed without ClickHouse.
+
+CREATE TABLE IF NOT EXISTS match_demos (
+    id BIGSERIAL PRIMARY KEY,
+    match_id VARCHAR(64) NOT NULL,
+    server_id VARCHAR(64) NOT NULL,
+    map_name VARCHAR(64) NOT NULL DEFAULT '',
+    started_at TIMESTAMPTZ NOT NULL,
+    filename VARCHAR(255) NOT NULL,
+    checksum CHAR(64) NOT NULL,
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    download_url TEXT NOT NULL,
+    registered_by VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (match_id, checksum)
+);
+
+CREATE INDEX IF NOT EXISTS idx_match_demos_started ON match_demos(started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_match_demos_server ON match_demos(server_id);
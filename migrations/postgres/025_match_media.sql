@@ -0,0 +1,27 @@
+-- ============================================================================
+-- MATCH MEDIA
+-- ============================================================================
+-- Screenshots and clip links that signed-in users attach to matches. Each
+-- waits in the moderation queue (pending) until an admin approves or
+-- rejects it; only approved media show on match pages. submitted_by is the
+-- SMF forum user ID.
+
+CREATE TABLE IF NOT EXISTS match_media (
+    id BIGSERIAL PRIMARY KEY,
+    match_id VARCHAR(64) NOT NULL,
+    kind VARCHAR(16) NOT NULL CHECK (kind IN ('screenshot', 'clip')),
+    url TEXT NOT NULL,
+    caption VARCHAR(200) NOT NULL DEFAULT '',
+    submitted_by INTEGER NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'approved', 'rejected')),
+    review_note TEXT NOT NULL DEFAULT '',
+    reviewed_by VARCHAR(64) NOT NULL DEFAULT '',
+    reviewed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (match_id, url)
+);
+
+CREATE INDEX IF NOT EXISTS idx_match_media_match ON match_media(match_id, status);
+CREATE INDEX IF NOT EXISTS idx_match_media_queue ON match_media(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_match_media_user ON match_media(submitted_by, status);
@@ -0,0 +1,22 @@
+-- ============================================================================
+-- DISCUSSION THREADS
+-- ============================================================================
+-- The SMF forum topic where a match or a player profile is discussed.
+-- Threads of featured matches are created through the SMF API by the
+-- smf_discussions job, others linked by an admin. posts is the topic's post
+-- count as last read from the forum, refreshed by the same job.
+
+CREATE TABLE IF NOT EXISTS discussion_threads (
+    subject_type VARCHAR(16) NOT NULL CHECK (subject_type IN ('match', 'player')),
+    subject_id VARCHAR(64) NOT NULL,
+    topic_id INTEGER NOT NULL,
+    url TEXT NOT NULL DEFAULT '',
+    posts INTEGER NOT NULL DEFAULT 0,
+    linked_by VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    posts_checked_at TIMESTAMPTZ,
+    PRIMARY KEY (subject_type, subject_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_discussion_threads_topic ON discussion_threads(topic_id);
+CREATE INDEX IF NOT EXISTS idx_discussion_threads_created ON discussion_threads(created_at DESC);
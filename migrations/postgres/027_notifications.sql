@@ -0,0 +1,27 @@
+-- ============================================================================
+-- NOTIFICATIONS
+-- ============================================================================
+-- The inbox behind the bell icon in the profile header: achievement unlocks,
+-- broken server records, rivalries and weekly challenge completions of the
+-- players an SMF forum user has verified. Rows are written by the worker as
+-- the events happen; read_at is set when the user marks them read, and rows
+-- older than 90 days are pruned by a daily job.
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id BIGSERIAL PRIMARY KEY,
+    forum_user_id INTEGER NOT NULL,
+    player_guid VARCHAR(64) NOT NULL DEFAULT '',
+    kind VARCHAR(16) NOT NULL
+        CHECK (kind IN ('achievement', 'record', 'rivalry', 'challenge')),
+    title VARCHAR(200) NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    subject VARCHAR(64) NOT NULL DEFAULT '',
+    value BIGINT NOT NULL DEFAULT 0,
+    match_id VARCHAR(64) NOT NULL DEFAULT '',
+    read_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications(forum_user_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications(forum_user_id) WHERE read_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_notifications_created ON notifications(created_at);
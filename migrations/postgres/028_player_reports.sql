@@ -0,0 +1,34 @@
+-- ============================================================================
+-- PLAYER REPORTS
+-- ============================================================================
+-- Abuse reports signed-in users file against a player: cheating, an abusive
+-- name or chat, griefing. Each is open until an admin triages it, moving it
+-- to reviewing and closing it as actioned or dismissed. anticheat_flag is
+-- the ID of the matching flag in the anticheat tooling, so a report and the
+-- detection behind it can be looked at together. reported_by is the SMF
+-- forum user ID.
+
+CREATE TABLE IF NOT EXISTS player_reports (
+    id BIGSERIAL PRIMARY KEY,
+    player_guid VARCHAR(64) NOT NULL,
+    player_name VARCHAR(64) NOT NULL DEFAULT '',
+    reason VARCHAR(16) NOT NULL
+        CHECK (reason IN ('cheating', 'abusive_name', 'abusive_chat', 'griefing', 'other')),
+    details TEXT NOT NULL DEFAULT '',
+    match_id VARCHAR(64) NOT NULL DEFAULT '',
+    reported_by INTEGER NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'open'
+        CHECK (status IN ('open', 'reviewing', 'actioned', 'dismissed')),
+    anticheat_flag VARCHAR(128) NOT NULL DEFAULT '',
+    review_note TEXT NOT NULL DEFAULT '',
+    reviewed_by VARCHAR(64) NOT NULL DEFAULT '',
+    reviewed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- One open report per reporter, player and reason
+CREATE UNIQUE INDEX IF NOT EXISTS idx_player_reports_open
+    ON player_reports(reported_by, player_guid, reason) WHERE status IN ('open', 'reviewing');
+CREATE INDEX IF NOT EXISTS idx_player_reports_queue ON player_reports(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_player_reports_player ON player_reports(player_guid, status);
+CREATE INDEX IF NOT EXISTS idx_player_reports_flag ON player_reports(anticheat_flag) WHERE anticheat_flag <> '';
@@ -0,0 +1,22 @@
+-- ============================================================================
+-- PLAYER IP HASHES
+-- ============================================================================
+-- Hashed client IPs of the players connecting to servers that opt in, for
+-- spotting alt accounts of banned players. Servers hash the IP themselves
+-- (hex SHA-256 under a salt shared across the network), so the API never
+-- sees a raw address; hashes are not stored with the events. A GUID and a
+-- hash seen together get one row, counting the connections; rows unseen for
+-- CONNECTION_RETENTION_DAYS are pruned daily.
+
+CREATE TABLE IF NOT EXISTS player_ip_hashes (
+    ip_hash CHAR(64) NOT NULL,
+    player_guid VARCHAR(64) NOT NULL,
+    last_server_id VARCHAR(64) NOT NULL DEFAULT '',
+    connections INTEGER NOT NULL DEFAULT 1,
+    first_seen TIMESTAMPTZ NOT NULL,
+    last_seen TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (ip_hash, player_guid)
+);
+
+CREATE INDEX IF NOT EXISTS idx_player_ip_hashes_guid ON player_ip_hashes(player_guid);
+CREATE INDEX IF NOT EXISTS idx_player_ip_hashes_last_seen ON player_ip_hashes(last_seen);
@@ -0,0 +1,10 @@
+-- ============================================================================
+-- ADMIN SERVERS
+-- ============================================================================
+-- Only tokens of servers marked admin reach /api/v1/admin and /api/v1/system:
+-- rebuilds, config reloads, the SQL sandbox, server approval, identity
+-- reports and the like. Game servers keep ingesting with their own tokens.
+-- An admin server of a tenant only reviews that tenant's registrations.
+-- Grant with `statsctl token -server <name> -admin on|off`.
+
+ALTER TABLE servers ADD COLUMN IF NOT EXISTS admin BOOLEAN NOT NULL DEFAULT false;
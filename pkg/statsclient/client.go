@@ -0,0 +1,340 @@
+// Package statsclient is the Go client of the stats API, for the SMF bridge,
+// statsctl and other Go tools. Reads are cached for a short time and shared
+// between concurrent callers; failed calls are retried with backoff.
+//
+//	client, err := statsclient.New(statsclient.Config{BaseURL: "https://stats.example.com"})
+//	player, err := client.Player(ctx, guid)
+//	if statsclient.IsNotFound(err) { ... }
+package statsclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	defaultTimeout  = 10 * time.Second
+	defaultRetries  = 2
+	retryBaseDelay  = 200 * time.Millisecond
+	retryMaxDelay   = 5 * time.Second
+	maxCacheEntries = 1024
+)
+
+// Config configures a Client
+type Config struct {
+	// BaseURL is the API root without the /api/v1 prefix, e.g.
+	// https://stats.example.com
+	BaseURL string
+	// APIKey scopes reads to a tenant (X-API-Key)
+	APIKey string
+	// ServerToken authenticates ingestion (X-Server-Token)
+	ServerToken string
+	// HTTPClient defaults to a client with a 10s timeout
+	HTTPClient *http.Client
+	// Retries of a call that failed with a network error, 429 or 5xx.
+	// 0 means 2; negative disables retries.
+	Retries int
+	// CacheTTL keeps read responses this long. 0 disables the cache.
+	CacheTTL time.Duration
+	// UserAgent is sent with every request
+	UserAgent string
+}
+
+// Client calls the stats API. It is safe for concurrent use.
+type Client struct {
+	base    string
+	cfg     Config
+	http    *http.Client
+	retries int
+
+	inflight singleflight.Group
+	mu       sync.Mutex
+	cache    map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	body    []byte
+	expires time.Time
+}
+
+// APIError is a response with an error status
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("stats API returned %d: %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is a 404 from the API
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// New returns a client of the API at cfg.BaseURL
+func New(cfg Config) (*Client, error) {
+	base, err := url.Parse(strings.TrimRight(cfg.BaseURL, "/"))
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q", cfg.BaseURL)
+	}
+	c := &Client{base: base.String(), cfg: cfg, http: cfg.HTTPClient, retries: cfg.Retries, cache: make(map[string]cacheEntry)}
+	if c.http == nil {
+		c.http = &http.Client{Timeout: defaultTimeout}
+	}
+	if c.retries == 0 {
+		c.retries = defaultRetries
+	}
+	if c.retries < 0 {
+		c.retries = 0
+	}
+	if c.cfg.UserAgent == "" {
+		c.cfg.UserAgent = "statsclient"
+	}
+	return c, nil
+}
+
+// Player returns the full profile of a player
+func (c *Client) Player(ctx context.Context, guid string) (*PlayerStats, error) {
+	var resp struct {
+		Player PlayerStats `json:"player"`
+	}
+	if err := c.Get(ctx, "/api/v1/stats/player/"+url.PathEscape(guid), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Player, nil
+}
+
+// Member returns the stats of the player linked to an SMF member
+func (c *Client) Member(ctx context.Context, memberID int) (*DeepStats, error) {
+	var stats DeepStats
+	if err := c.Get(ctx, "/api/v1/stats/member/"+strconv.Itoa(memberID), nil, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// Match returns a match's summary and scoreboard
+func (c *Client) Match(ctx context.Context, matchID string) (*MatchDetails, error) {
+	var match MatchDetails
+	if err := c.Get(ctx, "/api/v1/stats/match/"+url.PathEscape(matchID), nil, &match); err != nil {
+		return nil, err
+	}
+	return &match, nil
+}
+
+// Leaderboard returns a page of a stat's leaderboard
+func (c *Client) Leaderboard(ctx context.Context, q LeaderboardQuery) (*Leaderboard, error) {
+	stat := q.Stat
+	if stat == "" {
+		stat = "kills"
+	}
+	query := url.Values{}
+	if q.Limit > 0 {
+		query.Set("limit", strconv.Itoa(q.Limit))
+	}
+	if q.Page > 0 {
+		query.Set("page", strconv.Itoa(q.Page))
+	}
+	if q.Period != "" {
+		query.Set("period", q.Period)
+	}
+	var board Leaderboard
+	if err := c.Get(ctx, "/api/v1/stats/leaderboard/"+url.PathEscape(stat), query, &board); err != nil {
+		return nil, err
+	}
+	return &board, nil
+}
+
+// Ingest posts events with the strict v2 ingestion, authenticated with
+// Config.ServerToken. Events the API rejects are listed in the response.
+func (c *Client) Ingest(ctx context.Context, events []RawEvent) (*IngestResponse, error) {
+	if c.cfg.ServerToken == "" {
+		return nil, errors.New("ingest needs Config.ServerToken")
+	}
+	body, err := json.Marshal(events)
+	if err != nil {
+		return nil, err
+	}
+	var resp IngestResponse
+	if err := c.do(ctx, http.MethodPost, c.resolve("/api/v2/ingest/events", nil), body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Get decodes the JSON response of any GET route into out, for routes
+// without a typed method. path includes the /api/v1 prefix.
+func (c *Client) Get(ctx context.Context, path string, query url.Values, out any) error {
+	u := c.resolve(path, query)
+	if body, ok := c.cached(u); ok {
+		return json.Unmarshal(body, out)
+	}
+	// Concurrent reads of one URL share a request; the cache takes the rest
+	v, err, _ := c.inflight.Do(u, func() (any, error) {
+		var raw json.RawMessage
+		if err := c.do(ctx, http.MethodGet, u, nil, &raw); err != nil {
+			return nil, err
+		}
+		c.store(u, raw)
+		return []byte(raw), nil
+	})
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(v.([]byte), out)
+}
+
+// Invalidate drops the cached responses
+func (c *Client) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.cache)
+}
+
+func (c *Client) resolve(path string, query url.Values) string {
+	u := c.base + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+func (c *Client) cached(u string) ([]byte, bool) {
+	if c.cfg.CacheTTL <= 0 {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.cache[u]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.body, true
+}
+
+func (c *Client) store(u string, body []byte) {
+	if c.cfg.CacheTTL <= 0 {
+		return
+	}
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.cache) >= maxCacheEntries {
+		for key, entry := range c.cache {
+			if now.After(entry.expires) {
+				delete(c.cache, key)
+			}
+		}
+		// Still full of live entries: leave them be
+		if len(c.cache) >= maxCacheEntries {
+			return
+		}
+	}
+	c.cache[u] = cacheEntry{body: body, expires: now.Add(c.cfg.CacheTTL)}
+}
+
+// do sends the request, retrying network errors, 429 and 5xx with
+// exponential backoff, and decodes the JSON response into out. A POST is
+// only retried on 429, when the API has not processed it.
+func (c *Client) do(ctx context.Context, method, u string, body []byte, out any) error {
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		retryAfter, err := c.once(ctx, method, u, body, out)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if attempt >= c.retries || !retryable(method, err) {
+			return lastErr
+		}
+
+		delay := min(retryBaseDelay<<attempt, retryMaxDelay)
+		delay = delay/2 + time.Duration(rand.Int63n(int64(delay)/2+1))
+		if retryAfter > delay {
+			delay = min(retryAfter, retryMaxDelay)
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return lastErr
+		}
+	}
+}
+
+func retryable(method string, err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		// Network error; a POST may have been processed
+		return method == http.MethodGet
+	}
+	if apiErr.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return method == http.MethodGet && apiErr.StatusCode >= 500
+}
+
+// once sends one request and returns the response's Retry-After
+func (c *Client) once(ctx context.Context, method, u string, body []byte, out any) (time.Duration, error) {
+	var rd io.Reader
+	if body != nil {
+		rd = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, rd)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.cfg.UserAgent)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.cfg.APIKey != "" {
+		req.Header.Set("X-API-Key", c.cfg.APIKey)
+	}
+	if method == http.MethodPost && c.cfg.ServerToken != "" {
+		req.Header.Set("X-Server-Token", c.cfg.ServerToken)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		var e struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(data, &e) != nil || e.Error == "" {
+			e.Error = http.StatusText(resp.StatusCode)
+		}
+		retryAfter := time.Duration(0)
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+			retryAfter = time.Duration(secs) * time.Second
+		}
+		return retryAfter, &APIError{StatusCode: resp.StatusCode, Message: e.Error}
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return 0, fmt.Errorf("failed to decode %s response: %w", req.URL.Path, err)
+	}
+	return 0, nil
+}
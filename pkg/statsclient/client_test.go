@@ -0,0 +1,109 @@
+package statsclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPlayerRetriesAndCaches(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/api/v1/stats/player/a%2Fb" {
+			t.Errorf("path %s", r.URL.EscapedPath())
+		}
+		if r.Header.Get("X-API-Key") != "key" {
+			t.Errorf("X-API-Key %q", r.Header.Get("X-API-Key"))
+		}
+		// The first call fails, as an API node restarting would
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		io.WriteString(w, `{"player":{"guid":"a/b","kills":12}}`)
+	}))
+	defer srv.Close()
+
+	c, err := New(Config{BaseURL: srv.URL + "/", APIKey: "key", CacheTTL: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		player, err := c.Player(context.Background(), "a/b")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if player.GUID != "a/b" || player.Kills != 12 {
+			t.Errorf("player %+v", player)
+		}
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("%d requests, want 2 (one retry, then cached)", got)
+	}
+
+	c.Invalidate()
+	c.Player(context.Background(), "a/b")
+	if got := calls.Load(); got != 3 {
+		t.Errorf("%d requests after Invalidate, want 3", got)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		switch r.URL.Path {
+		case "/api/v1/stats/match/missing":
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"error":"Match not found"}`)
+		case "/api/v2/ingest/events":
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	c, _ := New(Config{BaseURL: srv.URL, ServerToken: "token"})
+	_, err := c.Match(context.Background(), "missing")
+	if !IsNotFound(err) || err.(*APIError).Message != "Match not found" {
+		t.Errorf("Match error %v", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("404 requested %d times, want once", got)
+	}
+
+	// A failed POST may have been processed, so it is not retried
+	calls.Store(0)
+	if _, err := c.Ingest(context.Background(), []RawEvent{{Type: "match_start"}}); err == nil {
+		t.Error("Ingest succeeded on a 500")
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("failed ingest sent %d times, want once", got)
+	}
+
+	if _, err := New(Config{BaseURL: "stats.example.com"}); err == nil {
+		t.Error("accepted a base URL without a scheme")
+	}
+}
+
+func TestLeaderboardQuery(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/stats/leaderboard/kills" || r.URL.RawQuery != "limit=10&period=week" {
+			t.Errorf("requested %s", r.URL)
+		}
+		io.WriteString(w, `{"players":[{"rank":1,"player_id":"g1","kills":30}],"total":1,"page":1,"stat":"kills"}`)
+	}))
+	defer srv.Close()
+
+	c, _ := New(Config{BaseURL: srv.URL, Retries: -1})
+	board, err := c.Leaderboard(context.Background(), LeaderboardQuery{Limit: 10, Period: "week"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(board.Players) != 1 || board.Players[0].Kills != 30 || board.Total != 1 {
+		t.Errorf("leaderboard %+v", board)
+	}
+}
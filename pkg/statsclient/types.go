@@ -0,0 +1,70 @@
+package statsclient
+
+import (
+	"time"
+
+	"github.com/openmohaa/stats-api/internal/models"
+)
+
+// The response types are the API's own models, aliased so the client cannot
+// drift from what the handlers send
+
+type (
+	PlayerStats       = models.PlayerStats
+	PlayerWeaponStats = models.PlayerWeaponStats
+	PlayerMapStats    = models.PlayerMapStats
+	PerformancePoint  = models.PerformancePoint
+	RecentMatch       = models.RecentMatch
+	PlayerCosmetics   = models.PlayerCosmetics
+	PlayerRating      = models.PlayerRating
+	DeepStats         = models.DeepStats
+	LeaderboardEntry  = models.LeaderboardEntry
+	MatchState        = models.MatchState
+	RawEvent          = models.RawEvent
+	EventType         = models.EventType
+	IngestResponse    = models.IngestResponse
+	IngestRejection   = models.IngestRejection
+)
+
+// MatchDetails is GET /stats/match/{matchId}
+type MatchDetails struct {
+	MatchID    string        `json:"match_id"`
+	Summary    MatchSummary  `json:"summary"`
+	Scoreboard []MatchPlayer `json:"scoreboard"`
+	// State is empty when the match is not tracked
+	State MatchState `json:"state,omitempty"`
+}
+
+type MatchSummary struct {
+	MapName       string    `json:"map_name"`
+	StartedAt     time.Time `json:"started_at"`
+	EndedAt       time.Time `json:"ended_at"`
+	TotalKills    uint64    `json:"total_kills"`
+	UniquePlayers uint64    `json:"unique_players"`
+}
+
+type MatchPlayer struct {
+	PlayerID   string `json:"player_id"`
+	PlayerName string `json:"player_name"`
+	Kills      uint64 `json:"kills"`
+	Deaths     uint64 `json:"deaths"`
+	Headshots  uint64 `json:"headshots"`
+}
+
+// LeaderboardQuery selects a leaderboard page. Zero values take the API's
+// defaults: kills, 25 per page, page 1, all time.
+type LeaderboardQuery struct {
+	Stat   string
+	Limit  int
+	Page   int
+	Period string // day, week, month, year or all
+}
+
+// Leaderboard is GET /stats/leaderboard/{stat}
+type Leaderboard struct {
+	Players  []LeaderboardEntry `json:"players"`
+	Total    int64              `json:"total"`
+	Page     int                `json:"page"`
+	Stat     string             `json:"stat"`
+	StatName string             `json:"stat_name"`
+}
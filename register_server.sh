@@ -13,8 +13,11 @@ echo "  Port: $SERVER_PORT"
 echo "----------------------------------------"
 
 # Register
+# Without an admin server token (OPM_ADMIN_TOKEN) the server is registered
+# pending, and its token only works once an admin approves it
 RESPONSE=$(curl -s -X POST "$API_URL" \
   -H "Content-Type: application/json" \
+  ${OPM_ADMIN_TOKEN:+-H "X-Server-Token: $OPM_ADMIN_TOKEN"} \
   -d "{
     \"name\": \"$SERVER_NAME\",
     \"ip_address\": \"$SERVER_IP\",
@@ -37,6 +40,8 @@ try:
         print(f'set opm_server_id \"{data[\"server_id\"]}\"')
         print(f'set opm_server_token \"{data[\"token\"]}\"')
         print('')
+        if data.get('status') == 'pending':
+            print('The token works once an admin approves the server.')
     else:
         print('\nError: unexpected response format')
         print(data)
@@ -5,9 +5,10 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"strings"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
+
+	"github.com/openmohaa/stats-api/internal/chart"
 )
 
 func main() {
@@ -67,7 +68,6 @@ func generateMapPopularity(ctx context.Context, conn clickhouse.Conn) {
 
 	var labels []string
 	var values []uint64
-	var maxVal uint64
 
 	for rows.Next() {
 		var label string
@@ -77,9 +77,6 @@ func generateMapPopularity(ctx context.Context, conn clickhouse.Conn) {
 		}
 		labels = append(labels, label)
 		values = append(values, val)
-		if val > maxVal {
-			maxVal = val
-		}
 	}
 
 	if len(labels) == 0 {
@@ -87,7 +84,7 @@ func generateMapPopularity(ctx context.Context, conn clickhouse.Conn) {
 		return
 	}
 
-	svg := generateBarChartSVG("Map Popularity (Matches)", labels, values, maxVal, "#4a90e2")
+	svg := chart.BarChart{Title: "Map Popularity (Matches)", Labels: labels, Values: values, Color: "#4a90e2"}.SVG()
 	saveChart("map_popularity.svg", svg)
 }
 
@@ -109,7 +106,6 @@ func generateWeaponUsage(ctx context.Context, conn clickhouse.Conn) {
 
 	var labels []string
 	var values []uint64
-	var maxVal uint64
 
 	for rows.Next() {
 		var label string
@@ -119,9 +115,6 @@ func generateWeaponUsage(ctx context.Context, conn clickhouse.Conn) {
 		}
 		labels = append(labels, label)
 		values = append(values, val)
-		if val > maxVal {
-			maxVal = val
-		}
 	}
 
 	if len(labels) == 0 {
@@ -129,7 +122,7 @@ func generateWeaponUsage(ctx context.Context, conn clickhouse.Conn) {
 		return
 	}
 
-	svg := generateBarChartSVG("Top Weapons (Kills)", labels, values, maxVal, "#e74c3c")
+	svg := chart.BarChart{Title: "Top Weapons (Kills)", Labels: labels, Values: values, Color: "#e74c3c"}.SVG()
 	saveChart("weapon_usage.svg", svg)
 }
 
@@ -146,44 +139,3 @@ func saveChart(filename string, svg string) {
 
 	fmt.Printf("Chart generated: web/static/img/%s\n", filename)
 }
-
-func generateBarChartSVG(title string, labels []string, values []uint64, maxVal uint64, color string) string {
-	width := 600
-	height := 400
-	padding := 50
-	barWidth := (width - 2*padding) / len(labels)
-	maxBarHeight := height - 2*padding
-
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf(`<svg width="%d" height="%d" viewBox="0 0 %d %d" xmlns="http://www.w3.org/2000/svg">`, width, height, width, height))
-	
-	// Background
-	sb.WriteString(`<rect width="100%" height="100%" fill="#1a1a1a" />`)
-	
-	// Title
-	sb.WriteString(fmt.Sprintf(`<text x="%d" y="30" fill="white" font-family="Arial" font-size="20" text-anchor="middle">%s</text>`, width/2, title))
-
-	for i, val := range values {
-		barHeight := 0
-		if maxVal > 0 {
-			barHeight = int((val * uint64(maxBarHeight)) / maxVal)
-		}
-		x := padding + i*barWidth
-		y := height - padding - barHeight
-		
-		// Bar
-		sb.WriteString(fmt.Sprintf(`<rect x="%d" y="%d" width="%d" height="%d" fill="%s" rx="4" />`, x+5, y, barWidth-10, barHeight, color))
-		
-		// Label (rotated)
-		sb.WriteString(fmt.Sprintf(`<text x="%d" y="%d" fill="white" font-family="Arial" font-size="12" text-anchor="end" transform="rotate(-45 %d %d)">%s</text>`, x+barWidth/2, height-padding+20, x+barWidth/2, height-padding+20, labels[i]))
-		
-		// Value on top
-		sb.WriteString(fmt.Sprintf(`<text x="%d" y="%d" fill="white" font-family="Arial" font-size="10" text-anchor="middle">%d</text>`, x+barWidth/2, y-5, val))
-	}
-
-	// X-axis
-	sb.WriteString(fmt.Sprintf(`<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="white" stroke-width="2" />`, padding, height-padding, width-padding, height-padding))
-
-	sb.WriteString(`</svg>`)
-	return sb.String()
-}